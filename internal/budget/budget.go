@@ -0,0 +1,111 @@
+// Package budget compares a trip's spending against its budgets and
+// projects the spend at the end of the trip.
+package budget
+
+import (
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// WarnAt is the share of a budget at which spending is flagged as close
+// to the limit.
+const WarnAt = 0.8
+
+// Level grades spending against a limit.
+type Level int
+
+const (
+	OK Level = iota
+	Warning
+	Over
+)
+
+// Line is the spending against one budget.
+type Line struct {
+	Category string // empty for the trip total
+	Limit    float64
+	Spent    float64
+}
+
+// Ratio is the share of the limit spent.
+func (l Line) Ratio() float64 {
+	if l.Limit <= 0 {
+		return 0
+	}
+	return l.Spent / l.Limit
+}
+
+// Level grades the line.
+func (l Line) Level() Level {
+	switch r := l.Ratio(); {
+	case r > 1:
+		return Over
+	case r >= WarnAt:
+		return Warning
+	}
+	return OK
+}
+
+// Report summarises spending in a single currency.
+type Report struct {
+	Currency string
+	// Total is the trip budget; its Limit is zero when none is set.
+	Total Line
+	// Categories holds the category budgets, in models.Categories order.
+	Categories []Line
+	// Unconverted counts expenses left out of the totals because they
+	// could not be converted into Currency.
+	Unconverted int
+
+	// Projected is the expected spend at the end of the trip at the
+	// average daily spend so far. It is only set when HasProjection is.
+	Projected     float64
+	DailyBurn     float64
+	HasProjection bool
+}
+
+// Converter converts amount between currencies.
+type Converter func(amount float64, from, to string) (float64, error)
+
+// Compute builds the report for trip in currency, converting expenses with
+// convert, which may be nil to count only expenses already in currency.
+func Compute(trip *models.Trip, expenses []*models.Expense, currency string, convert Converter, now time.Time) Report {
+	r := Report{Currency: currency, Total: Line{Limit: trip.Budget}}
+	spent := map[string]float64{}
+	for _, x := range expenses {
+		amount, ok := amountIn(x, currency, convert)
+		if !ok {
+			r.Unconverted++
+			continue
+		}
+		r.Total.Spent += amount
+		spent[x.Category] += amount
+	}
+	for _, c := range models.Categories {
+		if limit := trip.CategoryBudgets[c]; limit > 0 {
+			r.Categories = append(r.Categories, Line{Category: c, Limit: limit, Spent: spent[c]})
+		}
+	}
+
+	if trip.EndDate != nil {
+		total := models.CalendarDays(trip.StartDate, *trip.EndDate)
+		elapsed := models.CalendarDays(trip.StartDate, now)
+		switch {
+		case elapsed >= total:
+			r.Projected, r.HasProjection = r.Total.Spent, true
+			r.DailyBurn = r.Total.Spent / float64(total)
+		case elapsed > 0:
+			r.DailyBurn = r.Total.Spent / float64(elapsed)
+			r.Projected, r.HasProjection = r.DailyBurn*float64(total), true
+		}
+	}
+	return r
+}
+
+// amountIn returns the amount of x in currency, at its locked rate or
+// converting with convert, or false when it cannot be converted.
+func amountIn(x *models.Expense, currency string, convert Converter) (float64, bool) {
+	amount, err := x.In(currency, convert)
+	return amount, err == nil
+}
@@ -0,0 +1,206 @@
+package budget
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// lengthExponent is how spending grows with a trip's length: by less than
+// in proportion, as getting there and back costs the same however long
+// the stay.
+const lengthExponent = 0.8
+
+// singleSpread is the range either side of an estimate drawn from a
+// single trip, as a share of it, for want of others to tell by.
+const singleSpread = 0.25
+
+// Estimate is an amount expected to be spent, within a range of Low to
+// High.
+type Estimate struct {
+	Low, Expected, High float64
+}
+
+// PerDay is the estimate spread over days.
+func (e Estimate) PerDay(days int) Estimate {
+	if days <= 0 {
+		return Estimate{}
+	}
+	d := float64(days)
+	return Estimate{Low: e.Low / d, Expected: e.Expected / d, High: e.High / d}
+}
+
+// CategoryForecast is the estimate of what is spent in one category.
+type CategoryForecast struct {
+	Category string
+	Estimate
+}
+
+// Forecast is the budget suggested for a trip from what past trips
+// spent.
+type Forecast struct {
+	Currency string
+	Days     int
+	// Trips are the past trips it is drawn from. Similar is set when they
+	// went where the trip goes, the same places or countries; otherwise
+	// they are every past trip with expenses.
+	Trips   []*models.Trip
+	Similar bool
+	Total   Estimate
+	// Categories holds the estimates of the categories spent in, in
+	// models.Categories order, then by name.
+	Categories []CategoryForecast
+}
+
+// pastTrip is what a past trip spent, by category, with how alike its
+// destinations are to the trip's.
+type pastTrip struct {
+	trip   *models.Trip
+	days   int
+	spent  map[string]float64
+	weight float64
+}
+
+// Suggest forecasts what a trip of days days to locations costs in
+// currency, from the expenses of past trips at their locked rates or
+// converted with convert, which may be nil. Trips alike count for more:
+// those going to the same places most, those to the same countries less.
+// Only trips over by now and with expenses count, as those still under
+// way have spent but part of what they will; it reports false when there
+// are none, or days is not positive.
+func Suggest(locations []string, days int, past []*models.Trip, expenses []*models.Expense, currency string, convert Converter,
+	now time.Time) (Forecast, bool) {
+	f := Forecast{Currency: currency, Days: days}
+	if days <= 0 {
+		return f, false
+	}
+	byTrip := map[string][]*models.Expense{}
+	for _, x := range expenses {
+		byTrip[x.TripID] = append(byTrip[x.TripID], x)
+	}
+	names, countries := destinations(locations)
+	var all, alike []pastTrip
+	for _, t := range past {
+		if t.EndDate == nil || !t.EndDate.Before(now) {
+			continue
+		}
+		p := pastTrip{trip: t, days: models.CalendarDays(t.StartDate, *t.EndDate), spent: map[string]float64{}, weight: 1}
+		for _, x := range byTrip[t.ID] {
+			if amount, ok := amountIn(x, currency, convert); ok {
+				p.spent[x.Category] += amount
+			}
+		}
+		if p.days == 0 || len(p.spent) == 0 {
+			continue
+		}
+		all = append(all, p)
+		tn, tc := destinations(t.Locations)
+		switch {
+		case overlaps(names, tn):
+			p.weight = 3
+		case overlaps(countries, tc):
+			p.weight = 1
+		default:
+			continue
+		}
+		alike = append(alike, p)
+	}
+	trips := alike
+	f.Similar = len(alike) > 0
+	if !f.Similar {
+		trips = all
+	}
+	if len(trips) == 0 {
+		return f, false
+	}
+	var categories []string
+	totals := make([]float64, len(trips))
+	for i, p := range trips {
+		f.Trips = append(f.Trips, p.trip)
+		for c, amount := range p.spent {
+			totals[i] += scale(amount, p.days, days)
+			if !slices.Contains(categories, c) {
+				categories = append(categories, c)
+			}
+		}
+	}
+	slices.SortFunc(categories, compareCategories)
+	f.Total = estimate(trips, totals)
+	for _, c := range categories {
+		amounts := make([]float64, len(trips))
+		for i, p := range trips {
+			amounts[i] = scale(p.spent[c], p.days, days)
+		}
+		if e := estimate(trips, amounts); e.Expected > 0 {
+			f.Categories = append(f.Categories, CategoryForecast{Category: c, Estimate: e})
+		}
+	}
+	return f, true
+}
+
+// scale turns what was spent over from days into what is spent over to.
+func scale(amount float64, from, to int) float64 {
+	return amount * math.Pow(float64(to)/float64(from), lengthExponent)
+}
+
+// estimate is the mean of amounts, one per trip, weighted as the trips
+// are, give or take their standard deviation.
+func estimate(trips []pastTrip, amounts []float64) Estimate {
+	var sum, weights float64
+	for i, p := range trips {
+		sum += p.weight * amounts[i]
+		weights += p.weight
+	}
+	mean := sum / weights
+	spread := singleSpread * mean
+	if len(trips) > 1 {
+		var variance float64
+		for i, p := range trips {
+			variance += p.weight * (amounts[i] - mean) * (amounts[i] - mean)
+		}
+		spread = math.Sqrt(variance / weights)
+	}
+	return Estimate{Low: max(mean-spread, 0), Expected: mean, High: mean + spread}
+}
+
+// destinations resolves locations to the places and countries they name,
+// folded for comparing.
+func destinations(locations []string) (names, countries []string) {
+	for _, l := range locations {
+		names = append(names, strings.ToLower(strings.TrimSpace(l)))
+		if p, ok := places.Lookup(l); ok {
+			countries = append(countries, p.Country)
+		} else if code, ok := places.CountryCode(l); ok {
+			countries = append(countries, code)
+		}
+	}
+	return names, countries
+}
+
+func overlaps(a, b []string) bool {
+	for _, v := range a {
+		if v != "" && slices.Contains(b, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareCategories orders categories as models.Categories does, with
+// those it does not list after, by name.
+func compareCategories(a, b string) int {
+	i, j := slices.Index(models.Categories, a), slices.Index(models.Categories, b)
+	switch {
+	case i >= 0 && j >= 0:
+		return i - j
+	case i >= 0:
+		return -1
+	case j >= 0:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
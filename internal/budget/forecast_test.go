@@ -0,0 +1,44 @@
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+func TestSuggestLeavesOutUnfinishedTrips(t *testing.T) {
+	now := time.Date(2025, 6, 15, 12, 0, 0, 0, time.UTC)
+	trip := func(title string, start time.Time, days int, spent float64) (*models.Trip, *models.Expense) {
+		tr := models.NewTrip(title, []string{"Lisbon"}, start)
+		end := start.AddDate(0, 0, days-1)
+		tr.EndDate = &end
+		return tr, &models.Expense{TripID: tr.ID, Amount: spent, Currency: "EUR", Category: "Food"}
+	}
+	done, doneSpent := trip("May", time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), 10, 1000)
+	// Two days into a ten-day trip, and one not started.
+	going, goingSpent := trip("June", time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC), 10, 150)
+	ahead, aheadSpent := trip("July", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), 10, 40)
+	open := models.NewTrip("Open", []string{"Lisbon"}, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	f, ok := Suggest([]string{"Lisbon"}, 10, []*models.Trip{done, going, ahead, open},
+		[]*models.Expense{doneSpent, goingSpent, aheadSpent}, "EUR", nil, now)
+	if !ok {
+		t.Fatal("no forecast")
+	}
+	if len(f.Trips) != 1 || f.Trips[0] != done {
+		var titles []string
+		for _, tr := range f.Trips {
+			titles = append(titles, tr.Title)
+		}
+		t.Errorf("drawn from %q, want only the finished trip", titles)
+	}
+	if f.Total.Expected != 1000 || !f.Similar {
+		t.Errorf("expected %.2f, similar %v, want 1000.00 from a similar trip", f.Total.Expected, f.Similar)
+	}
+
+	if _, ok := Suggest([]string{"Lisbon"}, 10, []*models.Trip{going, ahead}, []*models.Expense{goingSpent, aheadSpent},
+		"EUR", nil, now); ok {
+		t.Error("suggested a budget from unfinished trips alone")
+	}
+}
@@ -0,0 +1,135 @@
+package budget
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Day is the spending of one day of a trip against its per diem.
+type Day struct {
+	Date      time.Time
+	Spent     float64
+	Allowance float64
+	// Cumulative is what is left of the allowances of the trip's days up
+	// to this one once their spending is taken off; below zero the trip
+	// is over.
+	Cumulative float64
+}
+
+// Balance is what is left of the day's allowance, below zero when the
+// day went over it.
+func (d Day) Balance() float64 { return d.Allowance - d.Spent }
+
+// PerDiem compares a trip's spending day by day with its daily allowance.
+type PerDiem struct {
+	Currency  string
+	Allowance float64
+	// Days holds the trip's days from its first, through its last or
+	// today, whichever comes first.
+	Days []Day
+	// Unconverted counts expenses left out because they could not be
+	// converted into Currency, Outside those dated on none of Days.
+	Unconverted int
+	Outside     int
+}
+
+// Balance is what is left of the allowances of every day so far, below
+// zero when the trip went over them.
+func (p PerDiem) Balance() float64 {
+	if len(p.Days) == 0 {
+		return 0
+	}
+	return p.Days[len(p.Days)-1].Cumulative
+}
+
+// ComputePerDiem compares the spending of each day of trip with its per
+// diem in currency, converting with convert, which may be nil to count
+// only expenses already in currency. Days start to count on the trip's
+// first day and stop on its last or on the calendar day of now, which
+// should be read where the trip is.
+func ComputePerDiem(trip *models.Trip, expenses []*models.Expense, currency string, convert Converter, now time.Time) PerDiem {
+	p := PerDiem{Currency: currency, Allowance: trip.PerDiem}
+	days := models.CalendarDays(trip.StartDate, now)
+	if trip.EndDate != nil {
+		days = min(days, models.CalendarDays(trip.StartDate, *trip.EndDate))
+	}
+	index := make(map[string]int, days)
+	y, m, d := trip.StartDate.Date()
+	for i := range days {
+		date := time.Date(y, m, d+i, 0, 0, 0, 0, time.Local)
+		index[date.Format(models.DateLayout)] = i
+		p.Days = append(p.Days, Day{Date: date, Allowance: trip.PerDiem})
+	}
+	for _, x := range expenses {
+		i, ok := index[x.Timestamp.Format(models.DateLayout)]
+		if !ok {
+			p.Outside++
+			continue
+		}
+		amount, ok := amountIn(x, currency, convert)
+		if !ok {
+			p.Unconverted++
+			continue
+		}
+		p.Days[i].Spent += amount
+	}
+	var cumulative float64
+	for i := range p.Days {
+		cumulative += p.Days[i].Balance()
+		p.Days[i].Cumulative = cumulative
+	}
+	return p
+}
+
+// WriteCSV writes the per diem as CSV: one row per day with its spending,
+// allowance, balance and the balance so far.
+func (p PerDiem) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	amount := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	records := [][]string{{"date", "spent", "allowance", "balance", "cumulative", "currency"}}
+	for _, d := range p.Days {
+		records = append(records, []string{d.Date.Format(models.DateLayout), amount(d.Spent), amount(d.Allowance),
+			amount(d.Balance()), amount(d.Cumulative), p.Currency})
+	}
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteMarkdown writes the per diem as a Markdown document titled title:
+// a table of the days, with dates in layout.
+func (p PerDiem) WriteMarkdown(w io.Writer, title, layout string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "Per diem of %.2f %s a day; balances above zero are under it.\n\n", p.Allowance, p.Currency)
+	b.WriteString("| Date | Spent | Balance | So far |\n")
+	b.WriteString("| --- | ---: | ---: | ---: |\n")
+	for _, d := range p.Days {
+		fmt.Fprintf(&b, "| %s | %.2f | %+.2f | %+.2f |\n", d.Date.Format(layout), d.Spent, d.Balance(), d.Cumulative)
+	}
+	fmt.Fprintf(&b, "\n%s after %d days.\n", Standing(p.Balance(), p.Currency), len(p.Days))
+	if p.Outside > 0 {
+		fmt.Fprintf(&b, "\n%d expenses dated outside the trip's days are not counted.\n", p.Outside)
+	}
+	if p.Unconverted > 0 {
+		fmt.Fprintf(&b, "\n%d expenses in other currencies could not be converted and are not counted.\n", p.Unconverted)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Standing says how a balance of a per diem stands, as "12.50 EUR under"
+// or "3.00 EUR over".
+func Standing(balance float64, currency string) string {
+	if balance < 0 {
+		return fmt.Sprintf("%.2f %s over", -balance, currency)
+	}
+	return fmt.Sprintf("%.2f %s under", balance, currency)
+}
@@ -0,0 +1,185 @@
+// Package chart draws charts as text with block characters: horizontal
+// bars, sparklines and histograms, for the terminal and for plain-text and
+// Markdown reports. It lays the charts out without styling them, leaving
+// colour to the caller.
+package chart
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// eighths are the blocks filling the last cell of a horizontal bar, from
+// one to seven eighths of it.
+var eighths = []rune("▏▎▍▌▋▊▉")
+
+// sparks are the blocks of a sparkline or a histogram column, from one to
+// eight eighths of a cell high.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// Bar draws value as a horizontal bar scaled so that peak fills width
+// cells, to an eighth of a cell. Values above zero always show at least a
+// sliver.
+func Bar(value, peak float64, width int) string {
+	if value <= 0 || peak <= 0 || width <= 0 {
+		return ""
+	}
+	n := max(int(math.Round(min(value/peak, 1)*float64(width*8))), 1)
+	bar := strings.Repeat("█", n/8)
+	if n%8 > 0 {
+		bar += string(eighths[n%8-1])
+	}
+	return bar
+}
+
+// Sparkline draws values between lo and hi as a line of blocks, one per
+// value, from ▁ at lo to █ at hi.
+func Sparkline(values []float64, lo, hi float64) string {
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v-lo)/(hi-lo)*float64(len(sparks)-1) + 0.5)
+		}
+		b.WriteRune(sparks[max(0, min(i, len(sparks)-1))])
+	}
+	return b.String()
+}
+
+// Row is one labelled value of a bar chart.
+type Row struct {
+	Label string
+	Value float64
+}
+
+// Line is a row of a bar chart laid out: its label and bar padded so that
+// the rows line up, and its value formatted.
+type Line struct {
+	Label, Bar, Value string
+}
+
+// Bars lays rows out as a bar chart, the bars scaled so that the largest
+// value fills width cells.
+func Bars(rows []Row, width int, format func(float64) string) []Line {
+	var peak float64
+	labelWidth := 0
+	for _, r := range rows {
+		peak = max(peak, r.Value)
+		labelWidth = max(labelWidth, ansi.StringWidth(r.Label))
+	}
+	lines := make([]Line, len(rows))
+	for i, r := range rows {
+		bar := Bar(r.Value, peak, width)
+		lines[i] = Line{
+			Label: pad(r.Label, labelWidth),
+			Bar:   pad(bar, width),
+			Value: format(r.Value),
+		}
+	}
+	return lines
+}
+
+// Histogram draws a column per value, scaled to a round number at the top
+// of its axis.
+type Histogram struct {
+	Values []float64
+	// Height is how many rows the columns are tall; 4 if not set.
+	Height int
+	// Width is how many cells wide each column is; 1 if not set.
+	Width int
+	// Format labels the axis with its top and zero; the values as they
+	// are if not set.
+	Format func(float64) string
+	// First and Last label the first and the last column under the axis,
+	// Last only if both fit.
+	First, Last string
+	// Paint styles the columns; they are left plain if not set.
+	Paint func(string) string
+}
+
+// Lines renders the histogram from the top down, the axis labels padded
+// so that the columns line up.
+func (h Histogram) Lines() []string {
+	height := h.Height
+	if height <= 0 {
+		height = 4
+	}
+	width := max(h.Width, 1)
+	format := h.Format
+	if format == nil {
+		format = func(v float64) string { return fmt.Sprintf("%g", v) }
+	}
+	paint := h.Paint
+	if paint == nil {
+		paint = func(s string) string { return s }
+	}
+	var peak float64
+	for _, v := range h.Values {
+		peak = max(peak, v)
+	}
+	top := RoundUp(peak)
+	topLabel, zeroLabel := format(top), format(0)
+	axisWidth := max(ansi.StringWidth(topLabel), ansi.StringWidth(zeroLabel))
+
+	// Each value fills its column in eighths of a cell, bottom up.
+	levels := make([]int, len(h.Values))
+	for i, v := range h.Values {
+		if v > 0 && top > 0 {
+			levels[i] = max(int(math.Round(v/top*float64(height*8))), 1)
+		}
+	}
+	lines := make([]string, 0, height+2)
+	for row := height - 1; row >= 0; row-- {
+		var cols strings.Builder
+		for _, level := range levels {
+			fill := min(max(level-row*8, 0), 8)
+			cell := " "
+			if fill > 0 {
+				cell = string(sparks[fill-1])
+			}
+			cols.WriteString(strings.Repeat(cell, width))
+		}
+		label, tick := "", "│"
+		if row == height-1 {
+			label, tick = topLabel, "┤"
+		}
+		lines = append(lines, padLeft(label, axisWidth)+" "+tick+paint(strings.TrimRight(cols.String(), " ")))
+	}
+	lines = append(lines, padLeft(zeroLabel, axisWidth)+" └"+strings.Repeat("─", len(h.Values)*width))
+	if h.First != "" {
+		under := h.First
+		if gap := len(h.Values)*width - ansi.StringWidth(h.First) - ansi.StringWidth(h.Last); h.Last != "" && gap > 0 {
+			under += strings.Repeat(" ", gap) + h.Last
+		}
+		lines = append(lines, strings.Repeat(" ", axisWidth+2)+under)
+	}
+	return lines
+}
+
+// RoundUp rounds v up to a round number for the top of an axis: 1, 2, 2.5
+// or 5 times a power of ten.
+func RoundUp(v float64) float64 {
+	if v <= 0 {
+		return 0
+	}
+	scale := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 2.5, 5} {
+		if m*scale >= v {
+			return m * scale
+		}
+	}
+	return 10 * scale
+}
+
+// pad fills s with spaces on the right to width cells.
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-ansi.StringWidth(s), 0))
+}
+
+// padLeft fills s with spaces on the left to width cells.
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", max(width-ansi.StringWidth(s), 0)) + s
+}
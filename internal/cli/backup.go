@@ -0,0 +1,400 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+	"github.com/girdharshubham/nomadic/pkg/offsite"
+)
+
+// dataDirOnly is the PersistentPreRunE of commands that work on the files
+// of the data directory rather than an open store.
+func dataDirOnly(a *app) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := a.loadConfig(); err != nil {
+			return err
+		}
+		if err := a.resolveDataDir(); err != nil {
+			return err
+		}
+		a.startLog()
+		return nil
+	}
+}
+
+// backupPassphraseEnv names the variable holding the passphrase of sealed
+// backups, for backing up without a terminal.
+const backupPassphraseEnv = "NOMADIC_BACKUP_PASSPHRASE"
+
+func newBackupCmd(a *app) *cobra.Command {
+	var encrypt, remote bool
+	cmd := &cobra.Command{
+		Use:   "backup [file or directory]",
+		Short: "Save the data directory into a compressed archive",
+		Long: `Save the database, attached files and caches into a compressed archive.
+
+The archive is written into the current directory, or the directory given,
+as nomadic-backup-<date>-<time>.tar.gz; give a file name to choose it.
+With --encrypt it is sealed with a passphrase, read from
+$` + backupPassphraseEnv + ` or like those of the encryption command. An
+encrypted database stays encrypted inside any backup. Linked attachments
+are backed up as links, not the originals.
+
+With --remote the archive is sealed and uploaded to backup_remote instead:
+an S3-compatible bucket (s3://bucket/prefix, with backup_endpoint and
+backup_region for services other than Amazon's, and the keys in
+$NOMADIC_S3_ACCESS_KEY_ID and $NOMADIC_S3_SECRET_ACCESS_KEY) or a WebDAV
+directory (its https URL, with $NOMADIC_WEBDAV_USER and
+$NOMADIC_WEBDAV_PASSWORD). Only the newest backup_keep backups are kept
+there. "nomadic backup list" lists them and "nomadic restore --remote"
+restores one.
+
+nomadic also backs up the database on its own before upgrading it to a
+new version's format, and the data directory before a restore, keeping
+the last ` + fmt.Sprint(storage.KeepBackups) + ` of these in its backups directory.`,
+		Example: `  nomadic backup
+  nomadic backup --encrypt ~/Backups
+  nomadic restore ~/Backups/nomadic-backup-20250406-091500.tar.gz.enc
+  nomadic config set backup_remote s3://my-bucket/nomadic
+  nomadic backup --remote`,
+		Args:              cobra.MaximumNArgs(1),
+		PersistentPreRunE: dataDirOnly(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if remote {
+				if len(args) > 0 {
+					return errors.New("--remote uploads to backup_remote; name no file")
+				}
+				return a.backupRemote(cmd)
+			}
+			passphrase := ""
+			if encrypt {
+				var err error
+				if passphrase, err = backupPassphrase(cmd, true); err != nil {
+					return err
+				}
+			}
+			path := backupPath(args, encrypt, time.Now())
+			// The .tmp suffix keeps an archive written into the data
+			// directory out of itself.
+			tmp := path + ".tmp"
+			f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+			if err != nil {
+				return err
+			}
+			m, err := storage.Backup(cmd.Context(), a.dataDir, f, passphrase)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err == nil {
+				err = os.Rename(tmp, path)
+			}
+			if err != nil {
+				os.Remove(tmp)
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiBackup(path, m, encrypt, ""))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Backed up %d files (%s) to %s\n", len(m.Files), byteSize(m.Size()), path)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&encrypt, "encrypt", false, "seal the archive with a passphrase")
+	cmd.Flags().BoolVar(&remote, "remote", false, "upload a sealed archive to backup_remote")
+	cmd.AddCommand(newBackupListCmd(a))
+	return cmd
+}
+
+// backupPassphrase reads the passphrase sealing a backup, or fresh set, a
+// new one.
+func backupPassphrase(cmd *cobra.Command, fresh bool) (string, error) {
+	if p, ok := os.LookupEnv(backupPassphraseEnv); ok && p != "" {
+		return p, nil
+	}
+	in := bufio.NewReader(cmd.InOrStdin())
+	if fresh {
+		return newPassphrase(in)
+	}
+	return readSecret(in, "Backup passphrase: ")
+}
+
+// backupRemote seals a backup, uploads it to backup_remote and removes
+// the backups there beyond backup_keep.
+func (a *app) backupRemote(cmd *cobra.Command) error {
+	ctx := cmd.Context()
+	remote, err := a.offsite()
+	if err != nil {
+		return err
+	}
+	passphrase, err := backupPassphrase(cmd, true)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "nomadic-backup-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	m, err := storage.Backup(ctx, a.dataDir, f, passphrase)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	name := filepath.Base(backupPath(nil, true, time.Now()))
+	if err := remote.Put(ctx, name, f); err != nil {
+		return err
+	}
+	keep, _ := strconv.Atoi(a.cfg.BackupKeep)
+	removed, err := pruneRemote(ctx, remote, keep)
+	if err != nil {
+		return err
+	}
+	path := remote.String() + "/" + name
+	if a.json() {
+		out := apiBackup(path, m, true, "")
+		out.Removed = removed
+		return printJSON(cmd, out)
+	}
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Backed up %d files (%s) to %s\n", len(m.Files), byteSize(m.Size()), path)
+	if len(removed) > 0 {
+		fmt.Fprintf(out, "Removed %s beyond the newest %d\n", plural(len(removed), "old backup", "old backups"), keep)
+	}
+	return nil
+}
+
+// remoteBackups lists the backups in remote, oldest first.
+func remoteBackups(ctx context.Context, remote offsite.Store) ([]offsite.File, error) {
+	files, err := remote.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var backups []offsite.File
+	for _, f := range files {
+		// Names start with their time, so they sort by it.
+		if strings.HasPrefix(f.Name, "nomadic-backup-") && strings.HasSuffix(f.Name, storage.SealedBackupExt) {
+			backups = append(backups, f)
+		}
+	}
+	return backups, nil
+}
+
+// pruneRemote removes all but the newest keep backups in remote and
+// returns the names of those removed.
+func pruneRemote(ctx context.Context, remote offsite.Store, keep int) ([]string, error) {
+	backups, err := remoteBackups(ctx, remote)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, f := range backups[:max(len(backups)-keep, 0)] {
+		if err := remote.Delete(ctx, f.Name); err != nil {
+			return removed, err
+		}
+		removed = append(removed, f.Name)
+	}
+	return removed, nil
+}
+
+func newBackupListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the backups in backup_remote",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, err := a.offsite()
+			if err != nil {
+				return err
+			}
+			backups, err := remoteBackups(cmd.Context(), remote)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.RemoteBackup, len(backups))
+				for i, f := range backups {
+					out[i] = api.RemoteBackup{Name: f.Name, Size: f.Size, Modified: f.Modified}
+				}
+				return printJSON(cmd, out)
+			}
+			if len(backups) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "No backups in %s yet; upload one with `nomadic backup --remote`\n", remote)
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSIZE\tUPLOADED")
+			for _, f := range backups {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", f.Name, byteSize(f.Size), f.Modified.Local().Format("2006-01-02 15:04"))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// backupPath is where a backup goes: the file named by args, or a file
+// named after now in the directory named by args or the current one.
+func backupPath(args []string, sealed bool, now time.Time) string {
+	dir := "."
+	if len(args) == 1 {
+		if info, err := os.Stat(args[0]); err != nil || !info.IsDir() {
+			if !strings.HasSuffix(args[0], string(filepath.Separator)) {
+				return args[0]
+			}
+		}
+		dir = args[0]
+	}
+	ext := storage.BackupExt
+	if sealed {
+		ext = storage.SealedBackupExt
+	}
+	return filepath.Join(dir, "nomadic-backup-"+now.Format("20060102-150405")+ext)
+}
+
+func newRestoreCmd(a *app) *cobra.Command {
+	var remote bool
+	cmd := &cobra.Command{
+		Use:   "restore <archive>",
+		Short: "Replace the data directory with a backup",
+		Long: `Replace the data in the data directory with a backup made by "nomadic backup"
+or taken automatically into the backups directory.
+
+Every file in the archive is checked against the checksums of its manifest
+before anything changes, so a damaged backup leaves your data as it was.
+The data it replaces is backed up first into the backups directory of the
+data directory. The git repository of sync is kept; run "nomadic sync"
+afterwards to commit the restored data. A backup taken before a migration
+holds only the database and leaves attached files in place. The links of
+linked attachments are not restored, as a link in an archive could point
+anywhere; link the originals again with nomadic journal attach --link.
+
+With --remote the archive is downloaded from backup_remote: the backup
+named, as nomadic backup list shows them, or else the newest.`,
+		Example: `  nomadic restore nomadic-backup-20250406-091500.tar.gz
+  nomadic restore ~/.local/share/nomadic/backups/pre-migration-v17-20250406-091500.tar.gz
+  nomadic restore --remote`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if remote {
+				return cobra.MaximumNArgs(1)(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		PersistentPreRunE: dataDirOnly(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			path := ""
+			if len(args) == 1 {
+				path = args[0]
+			}
+			if remote {
+				downloaded, name, err := a.downloadBackup(ctx, path)
+				if err != nil {
+					return err
+				}
+				defer os.Remove(downloaded)
+				path = name
+				args = []string{downloaded}
+			}
+			m, saved, err := storage.Restore(ctx, a.dataDir, args[0], "")
+			sealed := errors.Is(err, storage.ErrBackupSealed)
+			if sealed {
+				passphrase, perr := backupPassphrase(cmd, false)
+				if perr != nil {
+					return perr
+				}
+				m, saved, err = storage.Restore(ctx, a.dataDir, args[0], passphrase)
+			}
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiBackup(path, m, sealed, saved))
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Restored %d files (%s) backed up %s\n", len(m.Files), byteSize(m.Size()), m.Created.Local().Format("2006-01-02 15:04"))
+			if saved != "" {
+				fmt.Fprintln(out, "The data it replaced is backed up in", saved)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&remote, "remote", false, "restore a backup from backup_remote")
+	return cmd
+}
+
+// downloadBackup downloads the backup name from backup_remote, or the
+// newest when name is empty, into a temporary file. It returns the file
+// and where the backup came from.
+func (a *app) downloadBackup(ctx context.Context, name string) (string, string, error) {
+	remote, err := a.offsite()
+	if err != nil {
+		return "", "", err
+	}
+	if name == "" {
+		backups, err := remoteBackups(ctx, remote)
+		if err != nil {
+			return "", "", err
+		}
+		if len(backups) == 0 {
+			return "", "", fmt.Errorf("no backups in %s", remote)
+		}
+		name = backups[len(backups)-1].Name
+	}
+	body, err := remote.Get(ctx, name)
+	if err != nil {
+		return "", "", err
+	}
+	defer body.Close()
+	f, err := os.CreateTemp("", "nomadic-restore-*")
+	if err != nil {
+		return "", "", err
+	}
+	_, err = io.Copy(f, body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", "", fmt.Errorf("download %s: %w", name, err)
+	}
+	return f.Name(), remote.String() + "/" + name, nil
+}
+
+func apiBackup(path string, m *storage.BackupManifest, sealed bool, saved string) api.Backup {
+	return api.Backup{
+		Path:          path,
+		Created:       m.Created,
+		SchemaVersion: m.SchemaVersion,
+		Encrypted:     sealed,
+		DatabaseOnly:  m.DatabaseOnly,
+		Files:         len(m.Files),
+		Size:          m.Size(),
+		Replaced:      saved,
+	}
+}
+
+// byteSize renders a byte count for people.
+func byteSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/budget"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+func newTripBudgetCmd(a *app) *cobra.Command {
+	var (
+		trip       string
+		total      float64
+		currency   string
+		categories []string
+		perDiem    float64
+	)
+	cmd := &cobra.Command{
+		Use:   "budget",
+		Short: "Set a trip's budgets and show spending against them",
+		Long: `Set a trip's budgets and show spending against them.
+
+Without flags the current budget report is shown. --category sets a limit
+for one expense category as category=amount; an amount of 0 removes it.
+--per-diem sets a fixed daily allowance, as for work travel, against
+which each day's spending is shown by nomadic expense report --per-diem.
+Expenses in other currencies are converted with cached exchange rates.`,
+		Example: `  nomadic trip budget --trip tokyo
+  nomadic trip budget --trip tokyo --total 1500 --currency EUR --category food=300 --category lodging=600
+  nomadic trip budget --trip berlin --per-diem 80 --currency EUR`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			f := cmd.Flags()
+			if f.Changed("total") || f.Changed("currency") || f.Changed("category") || f.Changed("per-diem") {
+				if err := applyBudgetFlags(t, f.Changed("total"), total, currency, categories); err != nil {
+					return err
+				}
+				if f.Changed("per-diem") {
+					if perDiem < 0 {
+						return errors.New("--per-diem must not be negative")
+					}
+					t.PerDiem = perDiem
+				}
+				if err := a.store.SaveTrip(ctx, t); err != nil {
+					return err
+				}
+			}
+			expenses, err := a.store.ListExpensesByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+
+			cur := t.BudgetCurrency
+			if cur == "" {
+				cur = a.cfg.HomeCurrency
+			}
+			var convert budget.Converter
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			if rates, err := a.rates().Latest(ctx, cur); err == nil {
+				convert = rates.Convert
+			}
+			r := budget.Compute(t, expenses, cur, convert, time.Now())
+			var perDiem *budget.PerDiem
+			if t.PerDiem > 0 {
+				at, err := a.tripMoment(ctx, t, "", "", "")
+				if err != nil {
+					return err
+				}
+				p := budget.ComputePerDiem(t, expenses, cur, convert, at.ts)
+				perDiem = &p
+			}
+			if a.json() {
+				out := apiBudget(t, r)
+				if perDiem != nil {
+					p := apiPerDiem(t, *perDiem)
+					out.PerDiem = &p
+				}
+				return printJSON(cmd, out)
+			}
+			printBudget(cmd, t, r)
+			if perDiem != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Per diem: %.2f %s a day; %s after %s\n", perDiem.Allowance, perDiem.Currency,
+					budget.Standing(perDiem.Balance(), perDiem.Currency), plural(len(perDiem.Days), "day", "days"))
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.Float64Var(&total, "total", 0, "total trip budget; 0 removes it")
+	f.StringVar(&currency, "currency", "", "currency of the budgets (default: home_currency)")
+	f.StringArrayVar(&categories, "category", nil, "category budget as category=amount; repeatable")
+	f.Float64Var(&perDiem, "per-diem", 0, "daily allowance, in the budget currency; 0 removes it")
+	return cmd
+}
+
+func applyBudgetFlags(t *models.Trip, setTotal bool, total float64, currency string, categories []string) error {
+	if setTotal {
+		if total < 0 {
+			return errors.New("--total must not be negative")
+		}
+		t.Budget = total
+	}
+	if currency != "" {
+		cur, err := models.ParseCurrency(currency)
+		if err != nil {
+			return fmt.Errorf("--currency: %w", err)
+		}
+		t.BudgetCurrency = cur
+	}
+	for _, c := range categories {
+		name, amount, ok := strings.Cut(c, "=")
+		if !ok {
+			return fmt.Errorf("--category %q is not category=amount", c)
+		}
+		cat, err := models.ParseCategory(strings.TrimSpace(name))
+		if err != nil {
+			return fmt.Errorf("--category: %w", err)
+		}
+		limit, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+		if err != nil || limit < 0 {
+			return fmt.Errorf("--category %q: amount must be a number of at least zero", c)
+		}
+		if t.CategoryBudgets == nil {
+			t.CategoryBudgets = map[string]float64{}
+		}
+		if limit == 0 {
+			delete(t.CategoryBudgets, cat)
+		} else {
+			t.CategoryBudgets[cat] = limit
+		}
+	}
+	return nil
+}
+
+func printBudget(cmd *cobra.Command, t *models.Trip, r budget.Report) {
+	out := cmd.OutOrStdout()
+	lines := r.Categories
+	if r.Total.Limit > 0 {
+		lines = append([]budget.Line{r.Total}, lines...)
+	}
+	if len(lines) == 0 {
+		fmt.Fprintf(out, "No budget set for %q; spent %.2f %s\n", t.Title, r.Total.Spent, r.Currency)
+	} else {
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "BUDGET\tSPENT\tLIMIT\tUSED\t")
+		for _, l := range lines {
+			name := l.Category
+			if name == "" {
+				name = "total"
+			}
+			status := ""
+			switch l.Level() {
+			case budget.Over:
+				status = fmt.Sprintf("over by %.2f", l.Spent-l.Limit)
+			case budget.Warning:
+				status = "close to the limit"
+			}
+			fmt.Fprintf(w, "%s\t%.2f %s\t%.2f %s\t%.0f%%\t%s\n", name, l.Spent, r.Currency, l.Limit, r.Currency, l.Ratio()*100, status)
+		}
+		w.Flush()
+	}
+	if r.HasProjection {
+		fmt.Fprintf(out, "Projected spend: %.2f %s at %.2f %s a day\n", r.Projected, r.Currency, r.DailyBurn, r.Currency)
+	}
+	if r.Unconverted > 0 {
+		fmt.Fprintf(out, "%d expenses in other currencies could not be converted and are not counted\n", r.Unconverted)
+	}
+}
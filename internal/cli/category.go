@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/theme"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+// categoryUsage describes the --category flags; the categories themselves
+// are only known once the store is open.
+const categoryUsage = "expense category, or a unique prefix of one (see nomadic expense category list)"
+
+func newExpenseCategoryCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "category",
+		Aliases: []string{"categories"},
+		Short:   "Manage the categories expenses are filed under",
+		Long: `Expenses, recurring expenses, categorization rules and category budgets
+all name a category. Besides the built-in ones you can add your own, give
+each an icon and a colour for the TUI, and order them as lists and reports
+show them. Renaming a category renames it everywhere; merging one into
+another moves everything in it there, adding up the budgets. The category
+other, where expenses fitting no other go, cannot be renamed or removed.`,
+	}
+	cmd.AddCommand(newExpenseCategoryListCmd(a), newExpenseCategoryAddCmd(a), newExpenseCategoryEditCmd(a),
+		newExpenseCategoryMergeCmd(a), newExpenseCategoryRemoveCmd(a))
+	return cmd
+}
+
+func newExpenseCategoryListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the expense categories in display order",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			categories, err := a.store.ListCategories(ctx)
+			if err != nil {
+				return err
+			}
+			counts, err := a.store.CountByCategory(ctx)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.Category, len(categories))
+				for i, c := range categories {
+					out[i] = apiCategory(c, counts[c.Name])
+				}
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "#\tNAME\tICON\tCOLOUR\tEXPENSES")
+			for i, c := range categories {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\n", i+1, c.Name, c.Icon, c.Colour, counts[c.Name])
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// checkCategory validates the name and colour of a category being saved.
+func checkCategory(c *models.Category) error {
+	if err := models.CheckCategoryName(c.Name); err != nil {
+		return err
+	}
+	if !theme.ValidColour(c.Colour) {
+		return fmt.Errorf("--colour %q is neither an ANSI number (0-255) nor #rgb/#rrggbb", c.Colour)
+	}
+	return nil
+}
+
+func newExpenseCategoryAddCmd(a *app) *cobra.Command {
+	var (
+		icon, colour string
+		position     int
+	)
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add an expense category",
+		Example: `  nomadic expense category add coffee --icon ☕ --colour "#a0522d"
+  nomadic expense category add visas --icon 🛂 --position 1`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			c := models.NewCategory(args[0], icon, colour)
+			if err := checkCategory(c); err != nil {
+				return err
+			}
+			if err := a.store.SaveCategory(ctx, c); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("position") {
+				if err := a.store.MoveCategory(ctx, c, position-1); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, apiCategory(c, 0))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added the category %s\n", c.Label())
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&icon, "icon", "", "emoji shown before the name")
+	f.StringVar(&colour, "colour", "", "colour of the name in the TUI: an ANSI number (0-255) or #rgb/#rrggbb")
+	f.IntVar(&position, "position", 0, "place in display order, from 1 (default: last)")
+	return cmd
+}
+
+func newExpenseCategoryEditCmd(a *app) *cobra.Command {
+	var (
+		name, icon, colour string
+		position           int
+	)
+	cmd := &cobra.Command{
+		Use:   "edit <category>",
+		Short: "Rename, reorder or restyle an expense category",
+		Long: `Change a category's icon, colour or place in display order, or rename it
+with --name. A new name replaces the old one on every expense, recurring
+expense, rule and category budget; to fold a category into one that
+exists, use merge instead.`,
+		Example: `  nomadic expense category edit activities --name sights
+  nomadic expense category edit food --icon 🍜 --position 1`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			c, err := resolveCategory(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			f := cmd.Flags()
+			old := c.Name
+			if f.Changed("icon") {
+				c.Icon = strings.TrimSpace(icon)
+			}
+			if f.Changed("colour") {
+				c.Colour = strings.TrimSpace(colour)
+			}
+			if err := checkCategory(c); err != nil {
+				return err
+			}
+			if f.Changed("name") && strings.ToLower(strings.TrimSpace(name)) != c.Name {
+				if err := models.CheckCategoryName(name); err != nil {
+					return fmt.Errorf("--name: %w", err)
+				}
+				if err := a.store.RenameCategory(ctx, c, name); err != nil {
+					return err
+				}
+			}
+			if err := a.store.SaveCategory(ctx, c); err != nil {
+				return err
+			}
+			if f.Changed("position") {
+				if err := a.store.MoveCategory(ctx, c, position-1); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				counts, err := a.store.CountByCategory(ctx)
+				if err != nil {
+					return err
+				}
+				return printJSON(cmd, apiCategory(c, counts[c.Name]))
+			}
+			if c.Name != old {
+				fmt.Fprintf(cmd.OutOrStdout(), "Renamed %s to %s\n", old, c.Label())
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Updated %s\n", c.Label())
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&name, "name", "", "new name")
+	f.StringVar(&icon, "icon", "", "emoji shown before the name; empty to clear")
+	f.StringVar(&colour, "colour", "", "colour of the name in the TUI; empty for the theme's")
+	f.IntVar(&position, "position", 0, "place in display order, from 1")
+	return cmd
+}
+
+// mergeCategory moves everything in the category from into the one into
+// and removes from, reporting how many expenses moved.
+func (a *app) mergeCategory(cmd *cobra.Command, from, into *models.Category) error {
+	moved, err := a.store.MergeCategory(cmd.Context(), from, into)
+	if err != nil {
+		return err
+	}
+	if a.json() {
+		return printJSON(cmd, api.CategoryMerge{From: from.Name, Into: into.Name, Moved: moved})
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Merged %s into %s, moving %s\n", from.Name, into.Name, plural(moved, "expense", "expenses"))
+	return nil
+}
+
+func newExpenseCategoryMergeCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "merge <category> <into>",
+		Short: "Move everything in a category to another and remove it",
+		Long: `Move every expense, recurring expense and rule in a category to another,
+add its category budgets on trips and templates to the other's, and
+remove it.`,
+		Example: `  nomadic expense category merge coffee food`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			from, err := resolveCategory(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			into, err := resolveCategory(ctx, a.store, args[1])
+			if err != nil {
+				return err
+			}
+			return a.mergeCategory(cmd, from, into)
+		},
+	}
+}
+
+func newExpenseCategoryRemoveCmd(a *app) *cobra.Command {
+	var into string
+	cmd := &cobra.Command{
+		Use:     "remove <category>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a category, moving what is in it to another",
+		Long: `Remove a category. Its expenses, recurring expenses, rules and budgets
+move to --into, which is other unless given.`,
+		Example: `  nomadic expense category remove coffee
+  nomadic expense category remove sights --into activities`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			from, err := resolveCategory(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if into == "" {
+				return errors.New("--into is required")
+			}
+			to, err := resolveCategory(ctx, a.store, into)
+			if err != nil {
+				return fmt.Errorf("--into: %w", err)
+			}
+			return a.mergeCategory(cmd, from, to)
+		},
+	}
+	cmd.Flags().StringVar(&into, "into", models.CategoryOther, "category to move what is in it to")
+	return cmd
+}
@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/pkg/api"
+	"github.com/girdharshubham/nomadic/pkg/geocode"
+)
+
+func newCheckInCmd(a *app) *cobra.Command {
+	var (
+		trip, note, date string
+		lat, lon         float64
+	)
+	cmd := &cobra.Command{
+		Use:   "checkin <place>",
+		Short: "Check in at a place on the trip in progress",
+		Long: `Record being at a place now, such as a landmark visited, on the trip in
+progress or the one named with --trip. Check-ins show on the days of the
+itinerary in the TUI, where C checks in too.
+
+The position of the place is taken from --lat and --lon, else from the
+places dataset for a city, else, with the geocoder setting on, looked up
+online with OpenStreetMap. A check-in whose position cannot be found is
+kept without one.`,
+		Example: `  nomadic checkin "Osaka Castle"
+  nomadic checkin "Dotonbori" --note "Takoyaki at Kukuru"
+  nomadic checkin "Fushimi Inari" --trip japan --date 2025-04-03 --lat 34.9671 --lon 135.7727`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			f := cmd.Flags()
+			if f.Changed("lat") != f.Changed("lon") {
+				return errors.New("give both --lat and --lon, or neither")
+			}
+			if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+				return fmt.Errorf("%g, %g is not a latitude and longitude", lat, lon)
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			at, err := a.tripMoment(ctx, t, date, "", args[0])
+			if err != nil {
+				return err
+			}
+			c := models.NewCheckIn(t.ID, args[0], at.ts)
+			if c.Place == "" {
+				return errors.New("name the place to check in at")
+			}
+			c.Note, c.TimeZone = note, at.zone
+			if f.Changed("lat") {
+				c.Lat, c.Lon = lat, lon
+				if p, _, ok := places.Nearest(lat, lon); ok {
+					c.TimeZone = p.Timezone
+				}
+			} else if err := a.locate(ctx, c); err != nil {
+				// The check-in is worth keeping without its position.
+				fmt.Fprintf(cmd.ErrOrStderr(), "Position not found: %v\n", err)
+			}
+			c.Timestamp = models.InZone(c.Timestamp, c.TimeZone)
+			if err := a.store.SaveCheckIn(ctx, c); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiCheckIn(c))
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Checked in at %q on %q at %s\n", c.Place, t.Title, c.Timestamp.Format("15:04 MST"))
+			if c.Located() {
+				fmt.Fprintf(out, "Position: %s\n", c.Coordinates())
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.StringVar(&note, "note", "", "a note about the visit")
+	f.StringVar(&date, "date", "", "date, YYYY-MM-DD (default today)")
+	f.Float64Var(&lat, "lat", 0, "latitude of the place, in degrees north")
+	f.Float64Var(&lon, "lon", 0, "longitude of the place, in degrees east")
+	cmd.AddCommand(newCheckInListCmd(a), newCheckInRemoveCmd(a))
+	return cmd
+}
+
+// locate finds the position of a check-in's place and the time zone there,
+// from the places dataset or else the geocoder. The check-in is left
+// without a position when geocoding is off.
+func (a *app) locate(ctx context.Context, c *models.CheckIn) error {
+	if p, ok := places.Lookup(c.Place); ok {
+		c.Lat, c.Lon, c.TimeZone = p.Lat, p.Lon, p.Timezone
+		return nil
+	}
+	g := a.geocoder()
+	if g == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	p, err := g.Search(ctx, c.Place)
+	if errors.Is(err, geocode.ErrNotFound) {
+		return fmt.Errorf("OpenStreetMap knows no place called %q", c.Place)
+	}
+	if err != nil {
+		return err
+	}
+	c.Lat, c.Lon = p.Lat, p.Lon
+	if city, _, ok := places.Nearest(p.Lat, p.Lon); ok {
+		c.TimeZone = city.Timezone
+	}
+	return nil
+}
+
+func newCheckInListCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a trip's check-ins",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			checkIns, err := a.store.ListCheckInsByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.CheckIn, len(checkIns))
+				for i, c := range checkIns {
+					out[i] = apiCheckIn(c)
+				}
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tDATE\tTIME\tPLACE\tPOSITION\tNOTE")
+			for _, c := range checkIns {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", c.ID, a.formatDate(c.Timestamp), c.Timestamp.Format("15:04"),
+					c.Place, c.Coordinates(), c.Note)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+func newCheckInRemoveCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "remove <check-in>",
+		Short: "Remove a check-in, by ID or place",
+		Long: `Remove a check-in, named by its ID or its place. Of several check-ins at
+the same place, the latest is removed.`,
+		Example: `  nomadic checkin remove "Osaka Castle"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			c, err := resolveCheckIn(ctx, a.store, trip, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteCheckIn(ctx, c.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "checkin", ID: c.ID, Name: c.Place})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed the check-in at %q of %s\n", c.Place, a.formatDate(c.Timestamp))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
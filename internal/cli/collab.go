@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/lansync"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// browseWait is how long joining waits for sessions on the network to
+// answer.
+const browseWait = 2 * time.Second
+
+func newCollabCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "collab",
+		Short: "Plan a trip's itinerary and packing list together over the local network (experimental)",
+		Long: `Plan a trip together from two laptops on the same network.
+
+One of you hosts a session for the trip with nomadic collab host, which
+shows a code; the other joins it with nomadic collab join --code. While
+both are running, the itinerary and packing list of the trip are kept in
+step: what either of you adds, changes, checks off or removes, in the TUI
+or from the shell, reaches the other within a second or two. The rest of
+the trip stays on each laptop.
+
+Joining finds the session on the network by multicast DNS, or give the
+host's address when the network does not pass it on. A trip the joiner
+does not have yet is added; --trip shares one of their own instead. When
+both changed the same item meanwhile, the version changed last is kept;
+a change made meanwhile outlives a removal. After five wrong codes the
+host stops listening; host again for a new code.
+
+This is experimental: the session is not encrypted, so use it only on a
+network you trust.`,
+	}
+	cmd.AddCommand(newCollabHostCmd(a), newCollabJoinCmd(a))
+	return cmd
+}
+
+func newCollabHostCmd(a *app) *cobra.Command {
+	var trip, addr string
+	cmd := &cobra.Command{
+		Use:   "host",
+		Short: "Host a session for a trip for a partner to join",
+		Example: `  nomadic collab host --trip japan
+  nomadic collab host --trip japan --addr :4747`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			code, err := lansync.NewCode()
+			if err != nil {
+				return err
+			}
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			defer ln.Close()
+			port := ln.Addr().(*net.TCPAddr).Port
+			device := deviceName()
+			out := cmd.OutOrStdout()
+			go func() {
+				if err := lansync.Advertise(ctx, device, port, t.Title); err != nil {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Not announced on the network (%v); join with this machine's address and port %d\n", err, port)
+				}
+			}()
+			fmt.Fprintf(out, "Hosting %q on port %d\n", t.Title, port)
+			fmt.Fprintf(out, "Join from the other laptop with: nomadic collab join --code %s\n", code)
+			fmt.Fprintln(out, "ctrl+c to stop")
+			for {
+				c, err := lansync.Accept(ctx, ln, code, device, t)
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return err
+				}
+				fmt.Fprintf(out, "%s joined\n", c.Partner)
+				err = c.Run(ctx, a.store, t.ID, collabReporter(out, c.Partner))
+				c.Close()
+				if err != nil {
+					return err
+				}
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Fprintf(out, "%s left; waiting for them to join again\n", c.Partner)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	cmd.Flags().StringVar(&addr, "addr", ":0", "address to listen on, host:port; port 0 picks a free one")
+	return cmd
+}
+
+func newCollabJoinCmd(a *app) *cobra.Command {
+	var trip, code string
+	cmd := &cobra.Command{
+		Use:   "join [address]",
+		Short: "Join a session hosted on the network",
+		Example: `  nomadic collab join --code 3f9a1c07
+  nomadic collab join 192.168.1.20:4747 --code 3f9a1c07 --trip "Japan 2026"`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			out := cmd.OutOrStdout()
+			var addr string
+			if len(args) == 1 {
+				addr = args[0]
+			} else {
+				peers, err := lansync.Browse(ctx, browseWait)
+				if err != nil {
+					return fmt.Errorf("look for sessions: %w", err)
+				}
+				p, err := pickPeer(peers)
+				if err != nil {
+					return err
+				}
+				addr = p.Addr
+			}
+			c, err := lansync.Dial(ctx, addr, code, deviceName())
+			if errors.Is(err, lansync.ErrCode) {
+				return errors.New("the host shows another code; check --code")
+			}
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+			t, err := a.collabTrip(ctx, c.Trip, trip)
+			if err != nil {
+				return err
+			}
+			if t.ID == c.Trip.ID && t.Title != c.Trip.Title {
+				fmt.Fprintf(out, "Sharing %q as %q\n", c.Trip.Title, t.Title)
+			}
+			fmt.Fprintf(out, "Joined %s planning %q; ctrl+c to leave\n", c.Partner, t.Title)
+			if err := c.Run(ctx, a.store, t.ID, collabReporter(out, c.Partner)); err != nil {
+				return err
+			}
+			if ctx.Err() == nil {
+				fmt.Fprintf(out, "%s ended the session\n", c.Partner)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "share this trip of yours (default: the host's, added if you do not have it)")
+	cmd.Flags().StringVar(&code, "code", "", "the code the host shows")
+	cmd.MarkFlagRequired("code")
+	return cmd
+}
+
+// collabTrip is the trip a joiner shares the host's trip as: the one ref
+// names, else their copy of the host's, else a copy added now.
+func (a *app) collabTrip(ctx context.Context, host *models.Trip, ref string) (*models.Trip, error) {
+	if ref != "" {
+		return resolveTrip(ctx, a.store, ref)
+	}
+	t, err := a.store.GetTrip(ctx, host.ID)
+	if err == nil {
+		return t, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	if err := a.store.SaveTrip(ctx, host); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+// pickPeer chooses the session to join of those found: the only one, or
+// at a terminal the one asked for.
+func pickPeer(peers []lansync.Peer) (lansync.Peer, error) {
+	switch {
+	case len(peers) == 0:
+		return lansync.Peer{}, errors.New("no session found on the network; give the host's address")
+	case len(peers) == 1:
+		return peers[0], nil
+	case !interactive():
+		names := ""
+		for _, p := range peers {
+			names += fmt.Sprintf("\n  %s  %q on %s", p.Addr, p.Trip, p.Name)
+		}
+		return lansync.Peer{}, fmt.Errorf("several sessions found; give the address of one:%s", names)
+	}
+	for i, p := range peers {
+		fmt.Fprintf(os.Stderr, "%3d  %q on %s (%s)\n", i+1, p.Trip, p.Name, p.Addr)
+	}
+	for {
+		answer, err := ask(fmt.Sprintf("Which session? [1-%d]", len(peers)))
+		if err != nil {
+			return lansync.Peer{}, err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(peers) {
+			return peers[n-1], nil
+		}
+	}
+}
+
+// collabReporter prints the changes taken from partner as they come.
+func collabReporter(w io.Writer, partner string) func(lansync.Event) {
+	return func(e lansync.Event) {
+		list := "the itinerary"
+		if e.Kind == lansync.KindPacking {
+			list = "the packing list"
+		}
+		switch e.Action {
+		case "added":
+			fmt.Fprintf(w, "%s  %s added %q to %s\n", time.Now().Format("15:04"), partner, e.Title, list)
+		case "removed":
+			fmt.Fprintf(w, "%s  %s removed %q from %s\n", time.Now().Format("15:04"), partner, e.Title, list)
+		default:
+			fmt.Fprintf(w, "%s  %s changed %q in %s\n", time.Now().Format("15:04"), partner, e.Title, list)
+		}
+	}
+}
+
+// deviceName names this machine to a partner.
+func deviceName() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "nomadic"
+	}
+	return name
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/gpx"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/stats"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+func newTripCompareCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "compare <trip> <trip>...",
+		Short: "Compare trips side by side",
+		Long: `Set two or more trips side by side: how long they were, what they cost in
+all and per day, by category, how many journal entries were written and how
+far was traveled, by the journeys logged with nomadic transport and on the
+GPS tracks imported. Money is converted into home_currency with cached
+exchange rates, which makes the last few trips a guide to the next one's
+budget.`,
+		Example: `  nomadic trip compare japan lisbon
+  nomadic trip compare "Japan 2024" "Japan 2025" --output json`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			trips := make([]*models.Trip, len(args))
+			for i, ref := range args {
+				t, err := resolveTrip(ctx, a.store, ref)
+				if err != nil {
+					return err
+				}
+				trips[i] = t
+			}
+			c, err := a.compareTrips(ctx, trips)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTripComparison(c))
+			}
+			return a.printComparison(cmd, c)
+		},
+	}
+}
+
+// compareTrips works out the figures of trips in the home currency,
+// converting with the latest exchange rates when they can be had.
+func (a *app) compareTrips(ctx context.Context, trips []*models.Trip) (stats.Comparison, error) {
+	var (
+		expenses []*models.Expense
+		segments []*models.Segment
+		tracks   []*models.Track
+		entries  = map[string]int{}
+		listed   = map[string]bool{}
+	)
+	for _, t := range trips {
+		if listed[t.ID] {
+			continue
+		}
+		listed[t.ID] = true
+		x, err := a.store.ListExpensesByTrip(ctx, t.ID)
+		if err != nil {
+			return stats.Comparison{}, err
+		}
+		s, err := a.store.ListSegmentsByTrip(ctx, t.ID)
+		if err != nil {
+			return stats.Comparison{}, err
+		}
+		tr, err := a.store.ListTracksByTrip(ctx, t.ID)
+		if err != nil {
+			return stats.Comparison{}, err
+		}
+		if entries[t.ID], err = a.store.CountEntries(ctx, storage.EntryQuery{TripID: t.ID}); err != nil {
+			return stats.Comparison{}, err
+		}
+		expenses, segments, tracks = append(expenses, x...), append(segments, s...), append(tracks, tr...)
+	}
+	home := a.cfg.HomeCurrency
+	var convert stats.Converter
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if rates, err := a.rates().Latest(ctx, home); err == nil {
+		convert = rates.Convert
+	}
+	return stats.Compare(trips, expenses, entries, segments, tracks, home, convert, time.Now()), nil
+}
+
+// printComparison writes c as a table with a column for each trip.
+func (a *app) printComparison(cmd *cobra.Command, c stats.Comparison) error {
+	out := cmd.OutOrStdout()
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	row := func(label string, cell func(f stats.TripFigures) string) {
+		cells := make([]string, len(c.Trips))
+		for i, f := range c.Trips {
+			cells[i] = cell(f)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", label, strings.Join(cells, "\t"))
+	}
+	money := func(v float64) string { return fmt.Sprintf("%.2f %s", v, c.Currency) }
+	row("", func(f stats.TripFigures) string { return f.Trip.Title })
+	row("Dates", func(f stats.TripFigures) string {
+		dates := a.formatDate(f.Trip.StartDate)
+		if f.Trip.EndDate != nil {
+			dates += " → " + a.formatDate(*f.Trip.EndDate)
+		}
+		return dates
+	})
+	row("Days", func(f stats.TripFigures) string { return fmt.Sprint(f.Days) })
+	row("Spend", func(f stats.TripFigures) string { return money(f.Spend) })
+	row("Per day", func(f stats.TripFigures) string { return money(f.PerDay) })
+	for _, name := range c.Categories {
+		row("  "+name, func(f stats.TripFigures) string {
+			if v, ok := f.Categories[name]; ok {
+				return money(v)
+			}
+			return "-"
+		})
+	}
+	row("Entries", func(f stats.TripFigures) string { return fmt.Sprint(f.Entries) })
+	row("Distance", func(f stats.TripFigures) string { return fmt.Sprintf("%.0f km", f.Distance) })
+	row("Tracked", func(f stats.TripFigures) string { return gpx.FormatDistance(f.Tracked) })
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, f := range c.Trips {
+		if f.Unconverted > 0 {
+			fmt.Fprintf(out, "%d expenses on %q in other currencies could not be converted and are not counted\n",
+				f.Unconverted, f.Trip.Title)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// A completer lists what may be typed for a flag or an argument, each
+// with a description after a tab, as cobra completes them.
+type completer func(ctx context.Context, a *app, cmd *cobra.Command) []string
+
+// flagCompleters complete the flags of these names on any command.
+var flagCompleters = map[string]completer{
+	"trip":     completeTrips,
+	"tag":      completeTags,
+	"tags":     completeTags,
+	"category": completeCategories,
+	"into":     completeCategories,
+	"with":     completeCompanions,
+	"paid-by":  completeCompanions,
+	"profile":  completeProfiles,
+	"output": func(context.Context, *app, *cobra.Command) []string {
+		return []string{string(outputText), string(outputJSON)}
+	},
+}
+
+// argCompleters complete the arguments of commands by the placeholders
+// their Use lines give them, such as "archive <trip>".
+var argCompleters = map[string]completer{
+	"<trip>":     completeTrips,
+	"<entry>":    completeEntries,
+	"<category>": completeCategories,
+	"<into>":     completeCategories,
+	"<person>":   completePeople,
+	"<setting>":  completeSettings,
+}
+
+// completing reports whether cmd answers the shell, completing a command
+// line or writing the script that does, which needs no store opened until
+// a completion reads from it.
+func completing(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return cmd.HasParent() && cmd.Parent().Name() == "completion"
+}
+
+// registerCompletions completes the flags and arguments of cmd and the
+// commands under it from the journal, as far as they name trips, tags,
+// categories, people and the like, and the choices of those taking one of
+// a few.
+func (a *app) registerCompletions(cmd *cobra.Command) {
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if c, ok := flagCompleters[f.Name]; ok {
+			cmd.RegisterFlagCompletionFunc(f.Name, a.completion(c))
+		}
+	})
+	if cmd.HasParent() && cmd.ValidArgsFunction == nil && len(cmd.ValidArgs) == 0 {
+		if args := argCompletions(cmd.Use); len(args) > 0 {
+			cmd.ValidArgsFunction = func(cmd *cobra.Command, done []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+				i := len(done)
+				if i >= len(args) {
+					if !strings.HasSuffix(strings.Fields(cmd.Use)[len(args)], "...") {
+						return nil, cobra.ShellCompDirectiveNoFileComp
+					}
+					i = len(args) - 1
+				}
+				if args[i] == nil {
+					return nil, cobra.ShellCompDirectiveDefault
+				}
+				return a.completion(args[i])(cmd, done, toComplete)
+			}
+		}
+	}
+	for _, c := range cmd.Commands() {
+		a.registerCompletions(c)
+	}
+}
+
+// argCompletions reads the completer of each argument of a Use line, nil
+// for those completed as files or not at all. Placeholders of a few
+// choices, as <recurring|anniversary|off>, complete those. It returns none
+// when no argument is completed.
+func argCompletions(use string) []completer {
+	fields := strings.Fields(use)[1:]
+	out := make([]completer, len(fields))
+	found := false
+	for i, f := range fields {
+		f = strings.TrimSuffix(f, "...")
+		if c, ok := argCompleters[f]; ok {
+			out[i], found = c, true
+			continue
+		}
+		if choices := strings.Trim(f, "<>[]"); strings.Contains(choices, "|") {
+			options := strings.Split(choices, "|")
+			out[i], found = func(context.Context, *app, *cobra.Command) []string { return options }, true
+		}
+	}
+	if !found {
+		return nil
+	}
+	return out
+}
+
+// completion wraps c as cobra completes a flag or an argument, keeping
+// what starts with what was typed.
+func (a *app) completion(c completer) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		prefix := strings.ToLower(toComplete)
+		var out []string
+		for _, v := range c(ctx, a, cmd) {
+			if strings.HasPrefix(strings.ToLower(v), prefix) {
+				out = append(out, v)
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completionStore opens the store for a completion without asking for
+// anything: an encrypted one only with the passphrase in the environment,
+// and none where the data directory is yet to be made.
+func (a *app) completionStore(ctx context.Context) *storage.Store {
+	if a.store != nil {
+		return a.store
+	}
+	if a.loadConfig() != nil || a.resolveDataDir() != nil {
+		return nil
+	}
+	if _, err := os.Stat(a.dataDir); err != nil {
+		return nil
+	}
+	store, err := storage.Open(ctx, a.dataDir)
+	if errors.Is(err, storage.ErrEncrypted) {
+		passphrase, ok := os.LookupEnv(passphraseEnv)
+		if !ok {
+			return nil
+		}
+		store, err = storage.OpenEncrypted(ctx, a.dataDir, passphrase)
+	}
+	if err != nil {
+		return nil
+	}
+	a.store = store
+	return store
+}
+
+func completeTrips(ctx context.Context, a *app, cmd *cobra.Command) []string {
+	store := a.completionStore(ctx)
+	if store == nil {
+		return nil
+	}
+	trips, err := store.ListTrips(ctx)
+	if err != nil {
+		return nil
+	}
+	out := make([]string, len(trips))
+	for i, t := range trips {
+		out[i] = t.Title + "\t" + t.StartDate.Format(models.DateLayout) + " " + strings.Join(t.Locations, ", ")
+	}
+	return out
+}
+
+// flagTrip is the trip of the --trip flag of cmd, or nil when none is
+// given or it names none.
+func flagTrip(ctx context.Context, store *storage.Store, cmd *cobra.Command) *models.Trip {
+	f := cmd.Flags().Lookup("trip")
+	if f == nil || f.Value.String() == "" {
+		return nil
+	}
+	t, _ := resolveTrip(ctx, store, f.Value.String())
+	return t
+}
+
+// completeEntries lists the titles of the entries of the trip of --trip,
+// or of every trip.
+func completeEntries(ctx context.Context, a *app, cmd *cobra.Command) []string {
+	store := a.completionStore(ctx)
+	if store == nil {
+		return nil
+	}
+	var (
+		entries []*models.Entry
+		err     error
+	)
+	if t := flagTrip(ctx, store, cmd); t != nil {
+		entries, err = store.ListEntriesByTrip(ctx, t.ID)
+	} else {
+		entries, err = store.ListEntries(ctx)
+	}
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		if e.Title != "" {
+			out = append(out, e.Title+"\t"+e.Timestamp.Format(models.DateLayout))
+		}
+	}
+	return out
+}
+
+func completeTags(ctx context.Context, a *app, cmd *cobra.Command) []string {
+	store := a.completionStore(ctx)
+	if store == nil {
+		return nil
+	}
+	tags, err := store.ListTags(ctx)
+	if err != nil {
+		return nil
+	}
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = t.Name
+	}
+	return out
+}
+
+// completeCategories lists the user's categories, or the built-in ones
+// without any.
+func completeCategories(ctx context.Context, a *app, cmd *cobra.Command) []string {
+	if store := a.completionStore(ctx); store != nil {
+		if categories, err := store.ListCategories(ctx); err == nil && len(categories) > 0 {
+			out := make([]string, len(categories))
+			for i, c := range categories {
+				out[i] = c.Name
+			}
+			return out
+		}
+	}
+	return models.Categories
+}
+
+func completePeople(ctx context.Context, a *app, cmd *cobra.Command) []string {
+	store := a.completionStore(ctx)
+	if store == nil {
+		return nil
+	}
+	people, err := store.ListPeople(ctx)
+	if err != nil {
+		return nil
+	}
+	out := make([]string, len(people))
+	for i, p := range people {
+		out[i] = p.Name
+	}
+	return out
+}
+
+// completeCompanions lists the companions of the trip of --trip, or of
+// every trip.
+func completeCompanions(ctx context.Context, a *app, cmd *cobra.Command) []string {
+	store := a.completionStore(ctx)
+	if store == nil {
+		return nil
+	}
+	trips := []*models.Trip{flagTrip(ctx, store, cmd)}
+	if trips[0] == nil {
+		var err error
+		if trips, err = store.ListTrips(ctx); err != nil {
+			return nil
+		}
+	}
+	var out []string
+	for _, t := range trips {
+		for _, c := range t.Companions {
+			if !slices.Contains(out, c) {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+func completeProfiles(ctx context.Context, a *app, cmd *cobra.Command) []string {
+	cfg, err := a.loadBaseConfig()
+	if err != nil {
+		return nil
+	}
+	return cfg.ProfileNames()
+}
+
+func completeSettings(ctx context.Context, a *app, cmd *cobra.Command) []string {
+	if a.loadConfig() != nil {
+		return nil
+	}
+	return a.cfg.Names()
+}
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newConfigCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "View and change settings",
+		Long: `View and change settings stored in the config file.
+
+Settings: default_currency, home_currency (totals are converted into it),
+home_zone (the time zone of home, such as Europe/Lisbon, which jetlag is
+reckoned from; this machine's by default),
+date_format (e.g. YYYY-MM-DD or DD/MM/YYYY),
+data_dir, theme (dark, light, high-contrast or a custom theme),
+locale (auto, or a language tag such as de or en-GB; the language of the
+TUI and how it writes numbers, amounts and dates), editor,
+image_preview (auto, off, kitty, iterm2 or sixel; how photo attachments are
+previewed in the terminal), auto_sync (off, commit or push; see nomadic sync),
+weather (off or open-meteo; record the weather of new journal entries),
+holidays (bundled, nager or off; flag public holidays in the itinerary,
+from the rules bundled or online from Nager.Date, cached),
+geocoder (off or nominatim; look up the coordinates of check-ins at places
+such as landmarks online, with OpenStreetMap),
+offline (off or on; make no network requests, taking exchange rates,
+weather and holidays from what is cached or bundled, as --offline),
+vim (off or on; vim-style modal input and a : command line in the TUI),
+accessible (off or on; plain text for screen readers in the TUI, as --accessible),
+ascii (auto, off or on; draw the TUI in ASCII only, without emoji, as
+--ascii; auto decides by $NOMADIC_ASCII, else by the terminal and locale),
+read_only (off or on; browse without changing anything in the TUI, for a
+guest, as --read-only),
+daily_entry (on or off; start the day's entry of a trip in progress, with
+its leg, weather and itinerary, on opening its journal in the TUI),
+resume (ask, on or off; offer to reopen the screens the TUI was left on
+when it last closed, reopen them straight away, or start on the home menu),
+journal_storage and journal_dir (database, or markdown to keep journal
+entries as Markdown notes with YAML front matter in journal_dir too, such
+as an Obsidian vault; see nomadic journal notes),
+transcriber (whisper or api; what nomadic journal dictate transcribes with),
+whisper_command and whisper_model (the whisper.cpp binary and model file),
+transcribe_url and transcribe_model (an OpenAI-compatible speech-to-text
+API, with its key in $NOMADIC_TRANSCRIBE_KEY or $OPENAI_API_KEY),
+ocr (tesseract or api; what nomadic expense receipt reads receipts with),
+ocr_command and ocr_language (the tesseract binary and the language of the
+text, such as eng), ocr_url (an OCR.space-compatible API, with its key in
+$NOMADIC_OCR_KEY),
+receipt_inbox (a folder, such as one a scanner app saves into, whose photos
+and PDFs of receipts the TUI expense list offers to turn into expenses),
+serve_address (where nomadic serve listens, 127.0.0.1:8787 by default),
+site_title (the title of the website nomadic publish builds),
+share_tunnel (a command making nomadic share reachable from elsewhere, such
+as "cloudflared tunnel --url {url}"),
+redact (none, strip or mask; what nomadic export and the exports of the TUI
+do with private journal entries and passages, see nomadic journal privacy),
+backup_remote, backup_endpoint, backup_region and backup_keep (where
+nomadic backup --remote uploads and how many backups it keeps there),
+expiry_days (how many days ahead the TUI home screen and nomadic vault
+warn of travel documents running out, 90 by default),
+smtp_server, smtp_user and smtp_from (the host:port of the SMTP server
+nomadic digest --send mails through, who to log in as, with the password
+in $NOMADIC_SMTP_PASSWORD, and the address it comes from), digest_to (the
+addresses it goes to, separated by commas),
+hooks.<event> (a shell command run with the event as JSON on standard
+input when a journal entry is saved, a trip created or an expense added;
+the events are entry_saved, trip_created and expense_added, see nomadic
+hook), trip_fields.<key> (a field of their own for trips, such as a visa
+number or a loyalty programme, by its label; an empty label removes it,
+see nomadic trip fields), and keys.<action> for the TUI keybindings
+(separate several keys with commas; nomadic config list shows every
+action). Press ? in the TUI
+to see the keys of the current screen.
+
+Macros are quick actions recorded in the TUI: press ctrl+o (keys.record),
+do what the macro should, and press it again to name it and give it a key.
+Each is a table of the keys it presses, which text typed, the key
+replaying it and the screen it was recorded on and only replays on; the
+palette replays them too. An empty macros.<name>.keys removes one:
+
+  [macros.lunch]
+  key = "ctrl+l"
+  on = "expenseList"
+  keys = 'n "Lunch" tab tab "food" tab "JPY"'
+
+Custom themes are tables in the config file. Unset colours come from base:
+
+  [themes.sunset]
+  base = "dark"
+  title = "#ff8700"
+  highlight = "214"
+
+With --profile or $NOMADIC_PROFILE the settings are those of the profile,
+and set changes them for the profile only; see nomadic profile.`,
+		Args: cobra.NoArgs,
+		// Settings are readable even when the data directory is not.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.loadConfig()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listConfig(cmd, a)
+		},
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "Show every setting",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return listConfig(cmd, a)
+			},
+		},
+		&cobra.Command{
+			Use:   "get <setting>",
+			Short: "Print one setting",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				v, err := a.cfg.Get(args[0])
+				if err != nil {
+					return err
+				}
+				if a.json() {
+					return printJSON(cmd, api.Setting{Name: args[0], Value: v})
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), v)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:     "set <setting> <value>",
+			Short:   "Change one setting",
+			Example: "  nomadic config set default_currency JPY\n  nomadic config set keys.quit q,ctrl+q\n  nomadic config set trip_fields.insurance \"Travel insurance policy\"",
+			Args:    cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				next := a.cfg
+				if err := next.Set(args[0], args[1]); err != nil {
+					return err
+				}
+				if err := a.saveConfig(next); err != nil {
+					return err
+				}
+				a.cfg = next
+				if a.json() {
+					v, err := a.cfg.Get(args[0])
+					if err != nil {
+						return err
+					}
+					return printJSON(cmd, api.Setting{Name: args[0], Value: v})
+				}
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "path",
+			Short: "Print the config file location",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if a.json() {
+					return printJSON(cmd, api.Config{Path: a.configPath})
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), a.configPath)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "edit",
+			Short: "Open the config file in your editor",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				if _, err := os.Stat(a.configPath); errors.Is(err, fs.ErrNotExist) {
+					if err := config.Save(a.configPath, a.cfg); err != nil {
+						return err
+					}
+				}
+				parts := strings.Fields(a.editor())
+				c := exec.Command(parts[0], append(parts[1:], a.configPath)...)
+				c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+				if err := c.Run(); err != nil {
+					return fmt.Errorf("editor: %w", err)
+				}
+				_, err := config.Load(a.configPath)
+				return err
+			},
+		},
+	)
+	return cmd
+}
+
+func listConfig(cmd *cobra.Command, a *app) error {
+	settings := make([]api.Setting, 0, len(a.cfg.Names()))
+	for _, name := range a.cfg.Names() {
+		v, err := a.cfg.Get(name)
+		if err != nil {
+			return err
+		}
+		settings = append(settings, api.Setting{Name: name, Value: v})
+	}
+	if a.json() {
+		return printJSON(cmd, api.Config{Path: a.configPath, Settings: settings})
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, s := range settings {
+		fmt.Fprintf(w, "%s\t%s\n", s.Name, s.Value)
+	}
+	if p := a.profileName(); p != config.DefaultProfile {
+		fmt.Fprintf(w, "\n# %s, profile %s\n", a.configPath, p)
+	} else {
+		fmt.Fprintf(w, "\n# %s\n", a.configPath)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newConnectivityCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "connectivity",
+		Aliases: []string{"esim", "wifi"},
+		Short:   "Note how you got online in each country: eSIMs, speeds and Wi-Fi spots",
+		Long: `Note how you got online on a leg of a trip, or in a country: the eSIM or SIM
+card used and what it cost, how fast and reliable it was and the Wi-Fi
+spots worth going back to. Each trip adds its own note, so the notes on a
+country build up over the years.
+
+The notes on a country are shown, with its country note, when a trip or
+leg going there is created, and again by nomadic country show.`,
+	}
+	cmd.AddCommand(newConnectivityAddCmd(a), newConnectivityListCmd(a), newConnectivityRemoveCmd(a))
+	return cmd
+}
+
+func newConnectivityAddCmd(a *app) *cobra.Command {
+	var (
+		trip, leg, country, provider, currency, speed, notes string
+		cost                                                 float64
+		wifi                                                 []string
+	)
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Note how you got online on a leg of a trip, or in a country",
+		Long: `Note how you got online on the leg of the trip in progress, or the ones named
+with --trip and --leg, in the country of the leg. With --country alone the
+note is on the country, on no trip.`,
+		Example: `  nomadic connectivity add --provider "Ubigi 10 GB" --cost 18 --currency EUR --speed "5G in cities, patchy in the Alps"
+  nomadic connectivity add --trip japan --leg kyoto --wifi "Blue Bottle Sanjo" --wifi "Kyoto Station lounge"
+  nomadic connectivity add --country PT --provider "Vodafone prepaid" --notes "Shop in the arrivals hall"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if cost < 0 {
+				return errors.New("--cost must not be negative")
+			}
+			c, err := a.connectivityOn(ctx, trip, leg, country)
+			if err != nil {
+				return err
+			}
+			c.Provider, c.Speed, c.Notes = strings.TrimSpace(provider), strings.TrimSpace(speed), strings.TrimSpace(notes)
+			for _, spot := range wifi {
+				if spot = strings.TrimSpace(spot); spot != "" {
+					c.WiFi = append(c.WiFi, spot)
+				}
+			}
+			if c.Provider == "" && c.Speed == "" && c.Notes == "" && len(c.WiFi) == 0 {
+				return errors.New("note something: --provider, --speed, --wifi or --notes")
+			}
+			if cost > 0 {
+				if currency == "" {
+					currency = a.cfg.DefaultCurrency
+				}
+				if c.Currency, err = models.ParseCurrency(currency); err != nil {
+					return fmt.Errorf("--currency: %w", err)
+				}
+				c.Cost = cost
+			}
+			if err := a.store.SaveConnectivity(ctx, c); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiConnectivity(c))
+			}
+			where := places.CountryName(c.Country)
+			if c.Place != "" {
+				where = c.Place + ", " + where
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Noted how you got online in %s (%s)\n", where, c.ID)
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", tripFlagUsage)
+	f.StringVar(&leg, "leg", "", "leg of the trip, by ID or location (default: the leg of today, else the trip's first destination)")
+	f.StringVar(&country, "country", "", "country, by English name or two-letter code (default: the leg's)")
+	f.StringVar(&provider, "provider", "", "eSIM or SIM card used, such as \"Ubigi 10 GB\"")
+	f.Float64Var(&cost, "cost", 0, "what it cost")
+	f.StringVar(&currency, "currency", "", "three-letter currency code of the cost (default from config)")
+	f.StringVar(&speed, "speed", "", "how fast and reliable it was")
+	f.StringArrayVar(&wifi, "wifi", nil, "a Wi-Fi spot worth knowing; repeat for several")
+	f.StringVar(&notes, "notes", "", "anything else worth knowing")
+	return cmd
+}
+
+// connectivityOn starts a connectivity note on the country named by
+// country alone, or else on a leg of a trip: the one legRef names, or the
+// leg of today, or the trip's first destination.
+func (a *app) connectivityOn(ctx context.Context, tripRef, legRef, country string) (*models.Connectivity, error) {
+	if country != "" && tripRef == "" && legRef == "" {
+		code, err := resolveCountry(country)
+		if err != nil {
+			return nil, err
+		}
+		return models.NewConnectivity(code), nil
+	}
+	t, err := resolveTrip(ctx, a.store, tripRef)
+	if err != nil {
+		return nil, err
+	}
+	m, err := a.tripMoment(ctx, t, "", legRef, "")
+	if err != nil {
+		return nil, err
+	}
+	place, legID := "", ""
+	if m.leg != nil {
+		place, legID = m.leg.Location, m.leg.ID
+	} else if len(t.Locations) > 0 {
+		place = t.Locations[0]
+	}
+	code := ""
+	if country != "" {
+		if code, err = resolveCountry(country); err != nil {
+			return nil, err
+		}
+	} else if countries := places.Countries(places.Resolve([]string{place})); len(countries) > 0 {
+		code = countries[0]
+	} else {
+		return nil, fmt.Errorf("the country of %q is not known; name it with --country", place)
+	}
+	c := models.NewConnectivity(code)
+	c.TripID, c.LegID, c.Place = t.ID, legID, place
+	return c, nil
+}
+
+func newConnectivityListCmd(a *app) *cobra.Command {
+	var country string
+	cmd := &cobra.Command{
+		Use:   "list [words]...",
+		Short: "List the connectivity notes, or those mentioning every word",
+		Long: `List the connectivity notes by country, the latest first within each. Words
+narrow them to the notes with every word in their place, provider, speed,
+Wi-Fi spots, notes or the name of their country.`,
+		Example: `  nomadic connectivity list
+  nomadic connectivity list --country japan
+  nomadic connectivity list ubigi 5g`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			notes, err := a.store.ListConnectivity(ctx)
+			if country != "" {
+				code, cerr := resolveCountry(country)
+				if cerr != nil {
+					return cerr
+				}
+				notes, err = a.store.ListConnectivityFor(ctx, []string{code})
+			}
+			if err != nil {
+				return err
+			}
+			notes = searchConnectivity(notes, args)
+			if a.json() {
+				out := make([]api.Connectivity, len(notes))
+				for i, c := range notes {
+					out[i] = apiConnectivity(c)
+				}
+				return printJSON(cmd, out)
+			}
+			if len(notes) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No connectivity notes match.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tCOUNTRY\tPLACE\tNOTED\tPROVIDER\tCOST\tSPEED\tWI-FI")
+			for _, c := range notes {
+				cost := ""
+				if c.Cost > 0 {
+					cost = fmt.Sprintf("%.2f %s", c.Cost, c.Currency)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", c.ID, places.CountryName(c.Country), c.Place,
+					a.formatDate(c.CreatedAt), c.Provider, cost, c.Speed, strings.Join(c.WiFi, ", "))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&country, "country", "", "only the notes on this country, by English name or two-letter code")
+	return cmd
+}
+
+// searchConnectivity keeps the notes with every word in their text or the
+// name of their country.
+func searchConnectivity(notes []*models.Connectivity, words []string) []*models.Connectivity {
+	var out []*models.Connectivity
+	for _, c := range notes {
+		name := strings.ToLower(places.CountryName(c.Country))
+		found := true
+		for _, w := range words {
+			if !c.Mentions(w) && !strings.Contains(name, strings.ToLower(w)) {
+				found = false
+				break
+			}
+		}
+		if found {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func newConnectivityRemoveCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <id>",
+		Aliases: []string{"rm"},
+		Short:   "Delete a connectivity note",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			c, err := a.store.GetConnectivity(ctx, args[0])
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("no connectivity note %q; nomadic connectivity list shows their IDs", args[0])
+			}
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteConnectivity(ctx, c.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "connectivity", ID: c.ID, Name: places.CountryName(c.Country)})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed the connectivity note on %s\n", places.CountryName(c.Country))
+			return nil
+		},
+	}
+}
+
+// printConnectivity shows the connectivity notes on the countries with the
+// given codes, after their country notes.
+func (a *app) printConnectivity(ctx context.Context, w io.Writer, countries []string) error {
+	notes, err := a.store.ListConnectivityFor(ctx, countries)
+	if err != nil || len(notes) == 0 {
+		return err
+	}
+	fmt.Fprintln(w, "\nGetting online:")
+	for _, c := range notes {
+		fmt.Fprintf(w, "  %s\n", connectivityLine(c))
+		if len(c.WiFi) > 0 {
+			fmt.Fprintf(w, "    Wi-Fi: %s\n", strings.Join(c.WiFi, ", "))
+		}
+		if c.Notes != "" {
+			fmt.Fprintf(w, "    %s\n", c.Notes)
+		}
+	}
+	return nil
+}
+
+// connectivityLine names where a note was taken, with its summary, as
+// "Japan, Kyoto (2025-04): Ubigi 10 GB; 18.00 EUR; fast".
+func connectivityLine(c *models.Connectivity) string {
+	line := places.CountryName(c.Country)
+	if c.Place != "" {
+		line += ", " + c.Place
+	}
+	line += " (" + c.CreatedAt.Format("2006-01") + ")"
+	if s := c.Summary(); s != "" {
+		line += ": " + s
+	}
+	return line
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/quick"
+	"github.com/girdharshubham/nomadic/pkg/api"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+func newConvertCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "convert <amount> [from] [to]",
+		Short: "Convert an amount between currencies",
+		Long: `Convert an amount from one currency to another at the latest exchange
+rates, to check a price on the spot. The currency converted from defaults
+to default_currency and the one converted to to home_currency; both take
+a code or sign, such as USD or $.
+
+The rates are those cached in the data directory for up to 12 hours, so
+converting works offline with the rates last fetched, which are then
+dated. In the TUI, c in the list of expenses converts too.`,
+		Example: `  nomadic convert 100 USD JPY
+  nomadic convert €12.50 in gbp
+  nomadic convert 3000 to eur`,
+		Args: cobra.RangeArgs(1, 4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := quick.ParseConversion(strings.Join(args, " "))
+			if err != nil {
+				return fmt.Errorf("%w; write it as in nomadic convert 100 USD JPY", err)
+			}
+			if c.From == "" {
+				c.From = a.cfg.DefaultCurrency
+			}
+			if c.To == "" {
+				c.To = a.cfg.HomeCurrency
+			}
+			// A currency converts into itself without any rates.
+			if strings.EqualFold(c.From, c.To) {
+				if a.json() {
+					return printJSON(cmd, api.Conversion{Amount: c.Amount, From: c.From, Result: c.Amount, To: c.To, Rate: 1})
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%.2f %s = %.2f %s\n", c.Amount, c.From, c.Amount, c.To)
+				return nil
+			}
+			rates, err := a.latestRates(cmd.Context(), c.From)
+			if err != nil {
+				return err
+			}
+			result, err := rates.Convert(c.Amount, c.From, c.To)
+			if err != nil {
+				return err
+			}
+			rate, _ := rates.Convert(1, c.From, c.To)
+			if a.json() {
+				return printJSON(cmd, api.Conversion{Amount: c.Amount, From: c.From, Result: result, To: c.To,
+					Rate: rate, Date: rates.Date, Stale: rates.Stale})
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%.2f %s = %.2f %s\n", c.Amount, c.From, result, c.To)
+			dated := "rates of " + rates.Date
+			if rates.Stale {
+				dated += ", cached as they could not be refreshed"
+			}
+			fmt.Fprintf(out, "1 %s = %s %s • 1 %s = %s %s (%s)\n", c.From, formatRate(rate), c.To,
+				c.To, formatRate(1/rate), c.From, dated)
+			return nil
+		},
+	}
+}
+
+// formatRate writes an exchange rate to six significant digits.
+func formatRate(rate float64) string {
+	return strconv.FormatFloat(rate, 'g', 6, 64)
+}
+
+// latestRates returns the latest exchange rates that convert from, those
+// of home_currency, which the TUI and the daemon keep cached, else those
+// of from.
+func (a *app) latestRates(ctx context.Context, from string) (*currency.Rates, error) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	cache := a.rates()
+	rates, err := cache.Latest(ctx, a.cfg.HomeCurrency)
+	if err == nil {
+		return rates, nil
+	}
+	if other, oerr := cache.Latest(ctx, from); oerr == nil {
+		return other, nil
+	}
+	return nil, fmt.Errorf("no exchange rates could be fetched or found cached: %w", err)
+}
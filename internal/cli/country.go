@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newCountryCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "country",
+		Short: "Keep notes on the visa and entry rules of countries",
+		Long: `Keep a note on each country you travel to: its visa and entry rules, the
+longest stay they allow and tips such as where to buy a SIM card. Notes are
+shown when a trip or leg going to the country is created. Countries go by
+their English name or two-letter ISO code.`,
+	}
+	cmd.AddCommand(newCountryListCmd(a), newCountrySetCmd(a), newCountryShowCmd(a), newCountryRemoveCmd(a))
+	return cmd
+}
+
+// resolveCountry reads a country's English name or two-letter code into
+// the code.
+func resolveCountry(ref string) (string, error) {
+	code, ok := places.CountryCode(ref)
+	if !ok {
+		return "", fmt.Errorf("unknown country %q; name it in English or by its two-letter code, such as JP", ref)
+	}
+	return code, nil
+}
+
+func newCountryListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the countries with notes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			notes, err := a.store.ListCountryNotes(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.CountryNote, len(notes))
+				for i, n := range notes {
+					out[i] = apiCountryNote(n)
+				}
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CODE\tCOUNTRY\tVISA\tMAX STAY")
+			for _, n := range notes {
+				stay := ""
+				if n.MaxStay > 0 {
+					stay = fmt.Sprintf("%d days", n.MaxStay)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", n.Country, places.CountryName(n.Country), n.Visa, stay)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newCountrySetCmd(a *app) *cobra.Command {
+	var (
+		visa, notes string
+		maxStay     int
+	)
+	cmd := &cobra.Command{
+		Use:   "set <country>",
+		Short: "Write or change the note on a country",
+		Long: `Write the note on a country, or change what the given flags set on the one
+it has.`,
+		Example: `  nomadic country set Japan --visa "Visa-free for EU passports" --max-stay 90
+  nomadic country set PT --notes "SIM: Vodafone shop at the airport, 15 EUR for 30 GB"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			code, err := resolveCountry(args[0])
+			if err != nil {
+				return err
+			}
+			n, err := a.store.GetCountryNote(ctx, code)
+			if errors.Is(err, storage.ErrNotFound) {
+				n, err = models.NewCountryNote(code), nil
+			}
+			if err != nil {
+				return err
+			}
+			f := cmd.Flags()
+			if f.Changed("visa") {
+				n.Visa = strings.TrimSpace(visa)
+			}
+			if f.Changed("max-stay") {
+				if maxStay < 0 {
+					return errors.New("--max-stay must not be negative")
+				}
+				n.MaxStay = maxStay
+			}
+			if f.Changed("notes") {
+				n.Notes = strings.TrimSpace(notes)
+			}
+			if err := a.store.SaveCountryNote(ctx, n); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiCountryNote(n))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved the note on %s\n", places.CountryName(n.Country))
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&visa, "visa", "", "visa and entry rules; empty to clear")
+	f.IntVar(&maxStay, "max-stay", 0, "longest stay allowed, in days; 0 to clear")
+	f.StringVar(&notes, "notes", "", "anything else worth knowing, such as SIM tips; empty to clear")
+	return cmd
+}
+
+func newCountryShowCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <country>",
+		Short: "Show the note on a country and how you got online there",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			code, err := resolveCountry(args[0])
+			if err != nil {
+				return err
+			}
+			n, err := a.store.GetCountryNote(ctx, code)
+			if errors.Is(err, storage.ErrNotFound) {
+				n, err = nil, nil
+			}
+			if err != nil {
+				return err
+			}
+			online, err := a.store.ListConnectivityFor(ctx, []string{code})
+			if err != nil {
+				return err
+			}
+			if n == nil && len(online) == 0 {
+				return fmt.Errorf("no note on %s yet; write one with `nomadic country set`", places.CountryName(code))
+			}
+			if a.json() {
+				out := api.CountryNote{Country: code, Name: places.CountryName(code)}
+				if n != nil {
+					out = apiCountryNote(n)
+				}
+				for _, c := range online {
+					out.Connectivity = append(out.Connectivity, apiConnectivity(c))
+				}
+				return printJSON(cmd, out)
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%s (%s)\n", places.CountryName(code), code)
+			if n != nil && n.Visa != "" {
+				fmt.Fprintf(out, "Visa:     %s\n", n.Visa)
+			}
+			if n != nil && n.MaxStay > 0 {
+				fmt.Fprintf(out, "Max stay: %d days\n", n.MaxStay)
+			}
+			if n != nil && n.Notes != "" {
+				fmt.Fprintf(out, "Notes:    %s\n", n.Notes)
+			}
+			return a.printConnectivity(ctx, out, []string{code})
+		},
+	}
+}
+
+func newCountryRemoveCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <country>",
+		Short: "Delete the note on a country",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			code, err := resolveCountry(args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteCountryNote(cmd.Context(), code); err != nil {
+				if errors.Is(err, storage.ErrNotFound) {
+					return fmt.Errorf("no note on %s", places.CountryName(code))
+				}
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "country_note", ID: code, Name: places.CountryName(code)})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed the note on %s\n", places.CountryName(code))
+			return nil
+		},
+	}
+}
+
+// printCountryNotes shows the notes on the countries of locations, and
+// how you got online there before, for a trip or leg just created to go
+// there.
+func (a *app) printCountryNotes(ctx context.Context, w io.Writer, locations []string) error {
+	countries := places.Countries(places.Resolve(locations))
+	notes, err := a.store.ListCountryNotesFor(ctx, countries)
+	if err != nil {
+		return err
+	}
+	if len(notes) > 0 {
+		fmt.Fprintln(w, "\nBefore you go:")
+	}
+	for _, n := range notes {
+		line := places.CountryName(n.Country)
+		if s := n.Summary(); s != "" {
+			line += ": " + s
+		}
+		fmt.Fprintf(w, "  %s\n", line)
+		if n.Notes != "" {
+			fmt.Fprintf(w, "    %s\n", n.Notes)
+		}
+	}
+	return a.printConnectivity(ctx, w, countries)
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/daemon"
+	"github.com/girdharshubham/nomadic/internal/gitsync"
+	"github.com/girdharshubham/nomadic/internal/notify"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// remindedNote is the result of a remind run that showed a reminder; the
+// day of that run is not reminded again, across restarts too.
+const remindedNote = "Reminded to write today's entry"
+
+func newDaemonCmd(a *app) *cobra.Command {
+	var syncEvery, ratesEvery time.Duration
+	var remindAt string
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Sync, refresh exchange rates and remind in the background",
+		Long: `Run in the foreground until stopped, doing on a schedule what would
+otherwise need cron:
+
+  sync     commit the data directory and, with a remote, sync it, every
+           --sync-every and whenever the database has changed and been
+           left alone for half a minute
+  rates    refresh the exchange rates of the home currency every
+           --rates-every, so conversions work offline
+  remind   show a desktop notification once a day after --remind-at while
+           today's journal entry is missing on a trip in progress
+
+Every job runs once at start. The daemon only opens the database briefly,
+so the TUI and other commands can be used meanwhile; reminding about an
+encrypted database needs $` + passphraseEnv + `. It stops on ctrl+c or
+SIGTERM, syncing changes still waiting first. One daemon runs per data
+directory; "nomadic daemon status" shows how its jobs last went.`,
+		Example: `  nomadic daemon
+  nomadic daemon --sync-every 5m --remind-at 21:30
+  nomadic daemon status`,
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: dataDirOnly(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var remind time.Duration
+			if remindAt != "off" {
+				t, err := time.Parse("15:04", remindAt)
+				if err != nil {
+					return fmt.Errorf("--remind-at must be a time of day such as 20:00, or off")
+				}
+				remind = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+			}
+			if syncEvery <= 0 || ratesEvery <= 0 {
+				return errors.New("--sync-every and --rates-every must be greater than zero")
+			}
+			jobs := []daemon.Job{
+				{Name: "sync", Every: syncEvery, Run: a.daemonSync, Quiet: 30 * time.Second,
+					Watch: []string{storage.FileName, storage.FileName + "-wal", storage.EncryptedFileName}},
+				{Name: "rates", Every: ratesEvery, Run: a.daemonRates},
+			}
+			if remindAt != "off" {
+				jobs = append(jobs, daemon.Job{Name: "remind", Every: 10 * time.Minute, Run: a.daemonRemind(remind)})
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			out := cmd.OutOrStdout()
+			logf := func(format string, args ...any) {
+				slog.Info("daemon: " + fmt.Sprintf(format, args...))
+				fmt.Fprintf(out, "%s "+format+"\n", append([]any{time.Now().Format(time.DateTime)}, args...)...)
+			}
+			if err := daemon.Run(ctx, a.dataDir, jobs, logf); err != nil {
+				return err
+			}
+			logf("stopped")
+			return nil
+		},
+	}
+	cmd.Flags().DurationVar(&syncEvery, "sync-every", 15*time.Minute, "how often to sync")
+	cmd.Flags().DurationVar(&ratesEvery, "rates-every", 12*time.Hour, "how often to refresh exchange rates")
+	cmd.Flags().StringVar(&remindAt, "remind-at", "20:00", "remind from this time of day, or off")
+	cmd.AddCommand(newDaemonStatusCmd(a))
+	return cmd
+}
+
+func newDaemonStatusCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the daemon runs and how its jobs last went",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := daemon.ReadStatus(a.dataDir)
+			if errors.Is(err, fs.ErrNotExist) {
+				return errors.New("no daemon has run in this data directory; start one with `nomadic daemon`")
+			}
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			if a.json() {
+				return printJSON(cmd, apiDaemonStatus(s, now))
+			}
+			out := cmd.OutOrStdout()
+			switch running := s.Running(now); {
+			case running:
+				fmt.Fprintf(out, "Running (pid %d) since %s\n", s.PID, s.Started.Format(time.DateTime))
+			case !s.Stopped.IsZero():
+				fmt.Fprintf(out, "Stopped at %s\n", s.Stopped.Format(time.DateTime))
+			default:
+				fmt.Fprintf(out, "Not running: pid %d was last heard from at %s\n", s.PID, s.Heartbeat.Format(time.DateTime))
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "JOB\tEVERY\tLAST RUN\tRESULT\tNEXT RUN")
+			for _, j := range s.Jobs {
+				last, next, result := "never", "", j.Result
+				if !j.LastRun.IsZero() {
+					last = j.LastRun.Format(time.DateTime)
+				}
+				if s.Running(now) {
+					next = j.NextRun.Format(time.DateTime)
+				}
+				if j.Error != "" {
+					result = "error: " + j.Error
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", j.Name, j.Every, last, result, next)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// daemonSync commits the data directory and syncs it when it has a remote.
+func (a *app) daemonSync(ctx context.Context) (string, error) {
+	repo := gitsync.Open(a.dataDir)
+	if !repo.Exists() {
+		return "Not synced; run `nomadic sync init <url>` to start", nil
+	}
+	if err := checkpoint(ctx, a.dataDir); err != nil {
+		return "", err
+	}
+	s, err := repo.Status(ctx)
+	if err != nil {
+		return "", err
+	}
+	if !s.HasRemote {
+		committed, err := repo.Commit(ctx, "Sync nomadic data")
+		if err != nil || !committed {
+			return "Nothing to commit", err
+		}
+		return "Committed local changes", nil
+	}
+	res, err := repo.Sync(ctx, "Sync nomadic data")
+	var conflict *gitsync.ConflictError
+	if errors.As(err, &conflict) {
+		return "", fmt.Errorf("%w; settle it with `nomadic sync merge` or `nomadic sync resolve`", err)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !res.Committed && res.Pulled == 0 && res.Pushed == 0 {
+		return "Already in sync", nil
+	}
+	note := "Synced"
+	if res.Committed {
+		note += ", committing local changes"
+	}
+	return fmt.Sprintf("%s; pulled %d and pushed %d commits", note, res.Pulled, res.Pushed), nil
+}
+
+// daemonRates refreshes the exchange rates of the home currency.
+func (a *app) daemonRates(ctx context.Context) (string, error) {
+	rates, err := a.rates().Latest(ctx, a.cfg.HomeCurrency)
+	if err != nil {
+		return "", err
+	}
+	if rates.Stale {
+		return "", fmt.Errorf("could not refresh the rates of %s; using those of %s", rates.Base, rates.Date)
+	}
+	return fmt.Sprintf("Rates of %s from %s", rates.Base, rates.Date), nil
+}
+
+// daemonRemind returns the remind job, reminding once a day from at past
+// midnight.
+func (a *app) daemonRemind(at time.Duration) daemon.Func {
+	// A daemon restarted the same day does not remind again.
+	var reminded string
+	if s, err := daemon.ReadStatus(a.dataDir); err == nil {
+		if j, ok := s.Job("remind"); ok && j.Result == remindedNote {
+			reminded = j.LastRun.Format(time.DateOnly)
+		}
+	}
+	return func(ctx context.Context) (string, error) {
+		now := time.Now()
+		today := now.Format(time.DateOnly)
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if reminded == today {
+			return remindedNote, nil
+		}
+		if now.Before(midnight.Add(at)) {
+			return "", nil
+		}
+		store, err := storage.Open(ctx, a.dataDir)
+		if errors.Is(err, storage.ErrEncrypted) {
+			passphrase, ok := os.LookupEnv(passphraseEnv)
+			if !ok {
+				return "Set $" + passphraseEnv + " to be reminded about an encrypted database", nil
+			}
+			store, err = storage.OpenEncrypted(ctx, a.dataDir, passphrase)
+		}
+		if err != nil {
+			return "", err
+		}
+		s, err := journalStreak(ctx, store, now)
+		store.Close()
+		if err != nil || !s.Due() {
+			return "", err
+		}
+		if err := notify.Send("nomadic", reminder(s)); err != nil {
+			return "", err
+		}
+		reminded = today
+		return remindedNote, nil
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/demo"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newDemoCmd(a *app) *cobra.Command {
+	var (
+		seed  int64
+		trips int
+		dir   string
+		keep  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "demo",
+		Short: "Explore nomadic on a made-up journal",
+		Long: `Open the interactive interface on a made-up journal, to explore every
+screen before entering anything: a trip in progress with its journal,
+expenses shared with a companion, itinerary, stays and health log, trips
+to come with their packing lists and preparation, trips gone with a year
+of memories, a wishlist and a vault of travel documents.
+
+The journal is made up in a temporary directory, removed again when the
+interface quits unless --keep is given. Your own journal, and its hooks,
+notes and sync, are left alone; settings such as the theme are yours.
+
+The same --seed makes up the same journal, around today. With --dir it is
+made up in that directory, which must be new or empty, and kept there
+without opening the interface, for screenshots and tests:
+nomadic --data-dir <dir> then opens it.`,
+		Example: `  nomadic demo
+  nomadic demo --seed 7 --trips 10
+  nomadic demo --dir /tmp/nomadic-demo && nomadic --data-dir /tmp/nomadic-demo stats`,
+		Args: cobra.NoArgs,
+		// The demo opens a store of its own.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.loadConfig()
+		},
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx := cmd.Context()
+			if trips < 1 {
+				return fmt.Errorf("--trips must be at least 1, not %d", trips)
+			}
+			temporary := dir == ""
+			if temporary {
+				if dir, err = os.MkdirTemp("", "nomadic-demo-"); err != nil {
+					return err
+				}
+				defer func() {
+					if keep {
+						fmt.Fprintf(cmd.ErrOrStderr(), "The demo journal is kept in %s; open it again with nomadic --data-dir %s\n", dir, dir)
+						return
+					}
+					// The store and the log are in the directory.
+					if cerr := a.close(); err == nil {
+						err = cerr
+					}
+					a.store = nil
+					if a.log != nil {
+						a.log.Close()
+						a.log = nil
+					}
+					if rerr := os.RemoveAll(dir); err == nil {
+						err = rerr
+					}
+				}()
+			} else if entries, rerr := os.ReadDir(dir); rerr == nil && len(entries) > 0 {
+				return fmt.Errorf("%s is not empty; a demo journal goes in a directory of its own", dir)
+			}
+			a.demo, a.dataDir, a.tui = true, dir, temporary
+			if err := a.open(ctx, false); err != nil {
+				return err
+			}
+			j := demo.Generate(demo.Options{Seed: seed, Now: time.Now(), Home: a.cfg.HomeCurrency, Trips: trips})
+			report, err := demo.Fill(ctx, a.store, j)
+			if err != nil {
+				return err
+			}
+			if temporary {
+				return a.runTUI(ctx)
+			}
+			if a.json() {
+				return printJSON(cmd, api.Demo{Dir: dir, Seed: seed, Added: report.Added})
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Made up %s, %s and %s in %s\n", plural(report.Added["trip"], "trip", "trips"),
+				plural(report.Added["entry"], "entry", "entries"), plural(report.Added["expense"], "expense", "expenses"), dir)
+			fmt.Fprintf(out, "Open it with: nomadic --data-dir %s\n", dir)
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.Int64Var(&seed, "seed", 1, "make up the journal of this seed; each makes up another")
+	f.IntVar(&trips, "trips", demo.DefaultTrips, "how many trips to make up")
+	f.StringVar(&dir, "dir", "", "make up the journal in this new or empty directory and keep it there, without opening the interface")
+	f.BoolVar(&keep, "keep", false, "keep the temporary directory once the interface quits")
+	return cmd
+}
+
+// demoConfig is cfg for a made-up journal: nothing of it goes to the
+// hooks, Markdown notes or sync of the user's own.
+func demoConfig(cfg config.Config) config.Config {
+	cfg.Hooks = nil
+	cfg.JournalStorage = config.JournalDatabase
+	cfg.AutoSync = config.SyncOff
+	cfg.ReadOnly = config.ReadOnlyOff
+	return cfg
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/transcribe"
+)
+
+func newJournalDictateCmd(a *app) *cobra.Command {
+	var (
+		trip, title, date, location, leg string
+		tags                             []string
+		language, file                   string
+		duration                         time.Duration
+		attach                           bool
+		mood                             int
+		with                             []string
+	)
+	cmd := &cobra.Command{
+		Use:   "dictate",
+		Short: "Speak a journal entry into the microphone",
+		Long: `Record a journal entry from the microphone, transcribe it and save the
+transcript as a new entry, for capturing notes on the move.
+
+Recording stops on Enter, on ctrl+c or after --duration. It uses the first
+of arecord, rec (SoX) or ffmpeg found on the system; --file transcribes a
+recording made before instead, such as a voice memo from a phone.
+
+The transcriber setting picks the speech-to-text backend: whisper (the
+default) runs whisper.cpp on this machine, which needs whisper_model set to
+a downloaded ggml model; api uploads the recording to transcribe_url, an
+OpenAI-compatible transcription API, with the key in $NOMADIC_TRANSCRIBE_KEY
+or $OPENAI_API_KEY.
+
+The entry takes its day, leg and location as nomadic journal new does.
+--attach keeps the recording with it.`,
+		Example: `  nomadic config set whisper_model ~/models/ggml-base.bin
+  nomadic journal dictate --trip tokyo --tags food
+  nomadic journal dictate --duration 2m --attach
+  nomadic journal dictate --file memo.wav --language pt`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := models.CheckRating(mood); err != nil {
+				return fmt.Errorf("--mood: %w", err)
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			people, err := models.ParseWith(splitList(with), t.Companions)
+			if err != nil {
+				return fmt.Errorf("--with: %w", err)
+			}
+			at, err := a.tripMoment(ctx, t, date, leg, strings.TrimSpace(location))
+			if err != nil {
+				return err
+			}
+			// Check the transcriber before anything is said to it.
+			tr, err := a.transcriber()
+			if err != nil {
+				return err
+			}
+			audio := file
+			if audio != "" {
+				if _, err := os.Stat(audio); err != nil {
+					return err
+				}
+			} else {
+				dir, err := os.MkdirTemp("", "nomadic-dictate-")
+				if err != nil {
+					return err
+				}
+				defer os.RemoveAll(dir)
+				audio = filepath.Join(dir, "dictation-"+at.ts.Format("2006-01-02-1504")+".wav")
+				if err := record(cmd, audio, duration); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintln(cmd.ErrOrStderr(), "Transcribing…")
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+			defer cancel()
+			body, err := tr.Transcribe(ctx, audio, language)
+			if err != nil {
+				return err
+			}
+			if body == "" {
+				return errors.New("no speech heard, nothing saved")
+			}
+			e, err := a.writeEntry(cmd, t, at, entryFields{title: title, body: body, tags: tags, location: location, mood: mood,
+				people: people})
+			if err != nil || !attach {
+				return err
+			}
+			att, err := a.store.AttachFile(ctx, e.ID, audio, false)
+			if err != nil {
+				return err
+			}
+			if !a.json() {
+				fmt.Fprintf(cmd.OutOrStdout(), "Attached %s\n", att.Name)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.StringVar(&title, "title", "", "entry title (default: the start of the transcript)")
+	f.StringVar(&date, "date", "", "date, YYYY-MM-DD (default today)")
+	f.StringSliceVar(&tags, "tags", nil, "tags; repeat or separate with commas")
+	f.StringVar(&location, "location", "", "where the entry was spoken, such as a city")
+	f.StringVar(&leg, "leg", "", "leg of the trip, by ID or location (default: the leg of the entry's day)")
+	f.StringVar(&language, "language", "", "language spoken, as a code such as en or pt (default: detected)")
+	f.StringVar(&file, "file", "", "transcribe this recording instead of recording one")
+	f.DurationVar(&duration, "duration", 0, "stop recording after this long, e.g. 90s (default: on Enter)")
+	f.BoolVar(&attach, "attach", false, "attach the recording to the entry")
+	f.IntVar(&mood, "mood", 0, "how the day felt, from 1 (awful) to 5 (great)")
+	f.StringSliceVar(&with, "with", nil, "companions of the trip the entry was written with; repeat or separate with commas")
+	return cmd
+}
+
+// record records from the microphone into path until ctrl+c is pressed,
+// or Enter in a terminal, or duration passes when it is set.
+func record(cmd *cobra.Command, path string, duration time.Duration) error {
+	// ctrl+c ends the recording rather than nomadic.
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	hint := "press ctrl+c to stop"
+	if f, ok := cmd.InOrStdin().(*os.File); ok && isTerminal(f) {
+		hint = "press Enter or ctrl+c to stop"
+		go func() {
+			// The read outlives the recording when nothing is typed, which
+			// only costs this goroutine until nomadic exits.
+			if _, err := bufio.NewReader(f).ReadString('\n'); err == nil {
+				cancel()
+			}
+		}()
+	}
+	if duration > 0 {
+		hint = fmt.Sprintf("stopping after %s; %s", duration, hint)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "🎙️  Recording… %s\n", hint)
+	return transcribe.Record(ctx, path)
+}
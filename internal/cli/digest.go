@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/digest"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/quick"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+func newDigestCmd(a *app) *cobra.Command {
+	var (
+		since, ahead string
+		trip         string
+		format       string
+		file         string
+		send         bool
+		to           []string
+	)
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Sum up the last days' entries, spend and itinerary ahead for the people at home",
+		Long: `Sum up the journal for the people at home in one Markdown or HTML
+document: the entries written over the last days, what was spent then by
+category in home_currency, and the itinerary of the days ahead. Private
+entries and the private passages of the others are left out.
+
+--since is how far back to go, a span such as 7d or 2w counting today, or
+a day such as 2025-04-01 or "last monday"; --ahead is how far ahead to
+look, a span or a day. Both default to a week.
+
+--send mails the digest, as HTML with the Markdown for plain-text readers,
+through the SMTP server set with smtp_server, logging in as smtp_user with
+the password in $NOMADIC_SMTP_PASSWORD, from smtp_from. It goes to --to,
+or else to the addresses of digest_to. Run it weekly from cron to keep
+companions at home up to date.`,
+		Example: `  nomadic digest
+  nomadic digest --since 14d --ahead 3d --trip japan
+  nomadic digest --format html --file digest.html
+  nomadic config set smtp_server smtp.example.com:587
+  nomadic digest --send --to mum@example.com,dad@example.com`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if format != "markdown" && format != "html" {
+				return fmt.Errorf("unsupported --format %q: use markdown or html", format)
+			}
+			now := time.Now()
+			until := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.Local)
+			from, err := a.digestDay("--since", since, "-", until, false)
+			if err != nil {
+				return err
+			}
+			if !from.Before(until) {
+				return fmt.Errorf("--since %s is not in the past", since)
+			}
+			end, err := a.digestDay("--ahead", ahead, "+", until, true)
+			if err != nil {
+				return err
+			}
+			if send {
+				if to = splitList(to); len(to) == 0 {
+					to = splitList([]string{a.cfg.DigestTo})
+				}
+				if err := a.canMail(to); err != nil {
+					return err
+				}
+			}
+
+			j, err := a.digestJournal(ctx, trip, from, until, now)
+			if err != nil {
+				return err
+			}
+			home := a.cfg.HomeCurrency
+			var convert digest.Converter
+			rctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			if rates, err := a.rates().Latest(rctx, home); err == nil {
+				convert = rates.Convert
+			}
+			d := digest.Compile(j, from, until, end, home, convert)
+			layout := a.cfg.Layout()
+
+			if send {
+				var md, html bytes.Buffer
+				if err := digest.Markdown(&md, d, layout); err != nil {
+					return err
+				}
+				if err := digest.HTML(&html, d, layout); err != nil {
+					return err
+				}
+				server := digest.Server{Address: a.cfg.SMTPServer, User: a.cfg.SMTPUser, Password: os.Getenv("NOMADIC_SMTP_PASSWORD")}
+				msg := digest.Message{From: a.cfg.SMTPFrom, To: to, Subject: d.Subject(layout), Markdown: md.String(), HTML: html.String()}
+				if err := server.Send(msg); err != nil {
+					return err
+				}
+				if a.json() {
+					out := apiDigest(d)
+					out.Sent = to
+					return printJSON(cmd, out)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Sent the digest to %s\n", strings.Join(to, ", "))
+				return nil
+			}
+			if a.json() {
+				return printJSON(cmd, apiDigest(d))
+			}
+			return writeOutput(cmd, file, func(w io.Writer) error {
+				if format == "html" {
+					return digest.HTML(w, d, layout)
+				}
+				return digest.Markdown(w, d, layout)
+			})
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&since, "since", "7d", "how far back to go: a span such as 7d or 2w, or a day")
+	f.StringVar(&ahead, "ahead", "7d", "how far ahead to look in the itinerary: a span or a day")
+	f.StringVar(&trip, "trip", "", "sum up only this trip (default: every trip)")
+	f.StringVar(&format, "format", "markdown", "markdown or html")
+	f.StringVar(&file, "file", "", "write to this file instead of standard output")
+	f.BoolVar(&send, "send", false, "mail the digest through the configured SMTP server")
+	f.StringSliceVar(&to, "to", nil, "who to mail the digest to; repeat or separate with commas (default: digest_to)")
+	return cmd
+}
+
+// digestDay reads the value of --since or --ahead: a span such as 7d,
+// counted back ("-") or on ("+") from until, or a day. A day ahead is
+// taken whole, so the digest looks up to the end of it.
+func (a *app) digestDay(flag, v, sign string, until time.Time, whole bool) (time.Time, error) {
+	now := time.Now()
+	if d, err := quick.ParseDay(sign+strings.TrimLeft(v, "+-"), now, until, a.cfg.Layout()); err == nil {
+		return d, nil
+	}
+	d, err := quick.ParseDay(v, now, time.Time{}, a.cfg.Layout())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q: use a span such as 7d or 2w, or a day such as %s", flag, v, a.formatDate(now))
+	}
+	if whole {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d, nil
+}
+
+// canMail checks that a digest can be mailed to to before it is compiled.
+func (a *app) canMail(to []string) error {
+	switch {
+	case a.cfg.Offline == config.OfflineOn:
+		return errors.New("cannot send the digest offline; leave out --send to write it instead")
+	case a.cfg.SMTPServer == "":
+		return errors.New("no SMTP server to send the digest through; set one with nomadic config set smtp_server host:port")
+	case a.cfg.SMTPFrom == "":
+		return errors.New("no address to send the digest from; set one with nomadic config set smtp_from you@example.com")
+	case len(to) == 0:
+		return errors.New("no one to send the digest to; pass --to or set digest_to with nomadic config set")
+	}
+	return nil
+}
+
+// digestJournal loads what a digest from since to until is drawn from, on
+// the trip ref or on every trip: the entries of the period, the expenses
+// and the itinerary of the trips not over by now.
+func (a *app) digestJournal(ctx context.Context, ref string, since, until, now time.Time) (digest.Journal, error) {
+	var (
+		j   digest.Journal
+		err error
+	)
+	q := storage.EntryQuery{From: since, To: until}
+	if ref != "" {
+		t, err := resolveTrip(ctx, a.store, ref)
+		if err != nil {
+			return j, err
+		}
+		j.Trips, q.TripID = []*models.Trip{t}, t.ID
+		if j.Expenses, err = a.store.ListExpensesByTrip(ctx, t.ID); err != nil {
+			return j, err
+		}
+	} else {
+		if j.Trips, err = a.store.ListTrips(ctx); err != nil {
+			return j, err
+		}
+		if j.Expenses, err = a.store.ListExpenses(ctx); err != nil {
+			return j, err
+		}
+	}
+	if j.Entries, err = a.store.ListEntryPage(ctx, q, 0, -1); err != nil {
+		return j, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	for _, t := range j.Trips {
+		if t.EndDate != nil && t.EndDate.Before(today) {
+			continue
+		}
+		items, err := a.store.ListItineraryByTrip(ctx, t.ID)
+		if err != nil {
+			return j, err
+		}
+		j.Itinerary = append(j.Itinerary, items...)
+	}
+	return j, nil
+}
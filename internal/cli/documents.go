@@ -0,0 +1,284 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/viewer"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newDocumentCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "document",
+		Short: "Keep tickets, bookings and other documents with a trip",
+		Long: `Documents are files kept with a trip, such as PDF boarding passes, booking
+confirmations or insurance policies, and optionally with one item of its
+itinerary. Like journal attachments they are copied into the data
+directory, or linked with --link.`,
+	}
+	cmd.AddCommand(newDocumentAddCmd(a), newDocumentListCmd(a), newDocumentOpenCmd(a), newDocumentRemoveCmd(a))
+	return cmd
+}
+
+func newDocumentAddCmd(a *app) *cobra.Command {
+	var (
+		trip, item, title, note string
+		link                    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "add <file>...",
+		Short: "Keep files with a trip or an item of its itinerary",
+		Example: `  nomadic document add --trip japan ~/Downloads/insurance.pdf
+  nomadic document add --item "NH 204" --title "Boarding pass" boarding-pass.pdf
+  nomadic document add --trip lisbon --link --note "Check-in from 3pm" hotel.pdf`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if title != "" && len(args) > 1 {
+				return errors.New("--title names a single file")
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			items, err := a.store.ListItineraryByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			byID := itemsByID(items)
+			var it *models.ItineraryItem
+			if item != "" {
+				if it, err = resolveItineraryItem(t, items, item); err != nil {
+					return err
+				}
+			}
+			added := []api.Document{}
+			for _, path := range args {
+				var itemID string
+				if it != nil {
+					itemID = it.ID
+				}
+				d, err := a.store.AttachDocument(ctx, t.ID, itemID, path, link)
+				if err != nil {
+					return err
+				}
+				if title != "" || note != "" {
+					if title != "" {
+						d.Title = title
+					}
+					d.Note = note
+					if err := a.store.SaveDocument(ctx, d); err != nil {
+						return err
+					}
+				}
+				if a.json() {
+					added = append(added, apiDocument(a.store, d, byID))
+					continue
+				}
+				to := fmt.Sprintf("%q", t.Title)
+				if it != nil {
+					to = fmt.Sprintf("%q of %q", it.Title, t.Title)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Kept %s with %s\n", d.Name, to)
+			}
+			if a.json() {
+				return printJSON(cmd, added)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.StringVar(&item, "item", "", "itinerary item the document is for, by ID or title")
+	f.StringVar(&title, "title", "", "what the document is (default: the file name)")
+	f.StringVar(&note, "note", "", "optional note")
+	f.BoolVar(&link, "link", false, "link to the files instead of copying them")
+	return cmd
+}
+
+func newDocumentListCmd(a *app) *cobra.Command {
+	var trip, item string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a trip's documents",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			items, err := a.store.ListItineraryByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			byID := itemsByID(items)
+			var documents []*models.Document
+			if item != "" {
+				it, err := resolveItineraryItem(t, items, item)
+				if err != nil {
+					return err
+				}
+				documents, err = a.store.ListDocumentsByItem(ctx, it.ID)
+				if err != nil {
+					return err
+				}
+			} else if documents, err = a.store.ListDocumentsByTrip(ctx, t.ID); err != nil {
+				return err
+			}
+			out := make([]api.Document, len(documents))
+			for i, d := range documents {
+				out[i] = apiDocument(a.store, d, byID)
+			}
+			if a.json() {
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTITLE\tITEM\tSIZE\tPATH")
+			for _, d := range out {
+				item := d.Item
+				if item == "" {
+					item = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", d.ID, d.Title, item, d.Size, d.Path)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	cmd.Flags().StringVar(&item, "item", "", "only the documents of this itinerary item, by ID or title")
+	return cmd
+}
+
+func newDocumentOpenCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:     "open <document>",
+		Short:   "Open a document in the default viewer",
+		Example: `  nomadic document open "Boarding pass"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := resolveDocument(cmd.Context(), a.store, trip, args[0])
+			if err != nil {
+				return err
+			}
+			if err := viewer.Open(a.store.DocumentPath(d)); err != nil {
+				return fmt.Errorf("open %s: %w", d.Name, err)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+func newDocumentRemoveCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "remove <document>",
+		Short: "Remove a document from its trip",
+		Long: `Remove a document from its trip and delete its copy in the data
+directory. The original of a linked document is left alone.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			d, err := resolveDocument(ctx, a.store, trip, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteDocument(ctx, d.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "document", ID: d.ID, Name: d.Title})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", d.Title)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+// itemsByID indexes itinerary items by their IDs.
+func itemsByID(items []*models.ItineraryItem) map[string]*models.ItineraryItem {
+	byID := make(map[string]*models.ItineraryItem, len(items))
+	for _, it := range items {
+		byID[it.ID] = it
+	}
+	return byID
+}
+
+// resolveItineraryItem picks an item of trip t's itinerary by its ID, its
+// title, or a unique part of its title, compared without case.
+func resolveItineraryItem(t *models.Trip, items []*models.ItineraryItem, ref string) (*models.ItineraryItem, error) {
+	needle := strings.ToLower(ref)
+	var matches []*models.ItineraryItem
+	for _, it := range items {
+		if it.ID == ref || strings.ToLower(it.Title) == needle {
+			return it, nil
+		}
+		if strings.Contains(strings.ToLower(it.Title), needle) {
+			matches = append(matches, it)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("nothing on the itinerary of %q matches %q", t.Title, ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, it := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", it.Title, it.ID)
+	}
+	return nil, fmt.Errorf("%q matches several itinerary items: %s", ref, strings.Join(names, ", "))
+}
+
+// resolveDocument finds a document by its ID, or else among the documents
+// of the trip tripRef names by its title or file name, or a unique part of
+// either.
+func resolveDocument(ctx context.Context, store *storage.Store, tripRef, ref string) (*models.Document, error) {
+	if d, err := store.GetDocument(ctx, ref); err == nil {
+		return d, nil
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	t, err := resolveTrip(ctx, store, tripRef)
+	if err != nil {
+		return nil, err
+	}
+	documents, err := store.ListDocumentsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.Document
+	for _, d := range documents {
+		if strings.ToLower(d.Title) == needle || strings.ToLower(d.Name) == needle {
+			return d, nil
+		}
+		if strings.Contains(strings.ToLower(d.Title), needle) || strings.Contains(strings.ToLower(d.Name), needle) {
+			matches = append(matches, d)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no document of %q matches %q", t.Title, ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, d := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", d.Title, d.ID)
+	}
+	return nil, fmt.Errorf("%q matches several documents: %s", ref, strings.Join(names, ", "))
+}
@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+// What expense add does with an expense that looks like a duplicate of
+// one already recorded.
+const (
+	duplicateAsk   = "ask"
+	duplicateMerge = "merge"
+	duplicateKeep  = "keep"
+)
+
+// duplicateOf returns the expense x should be merged into, or nil to save
+// x as it is, as chosen by onDuplicate when x looks like a duplicate of an
+// expense already on its trip. Asking needs a terminal.
+func (a *app) duplicateOf(cmd *cobra.Command, x *models.Expense, onDuplicate string) (*models.Expense, error) {
+	switch onDuplicate {
+	case duplicateAsk, duplicateMerge, duplicateKeep:
+	default:
+		return nil, fmt.Errorf("--on-duplicate must be %s, %s or %s", duplicateAsk, duplicateMerge, duplicateKeep)
+	}
+	if onDuplicate == duplicateKeep {
+		return nil, nil
+	}
+	expenses, err := a.store.ListExpensesByTrip(cmd.Context(), x.TripID)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(expenses, func(e *models.Expense) bool { return models.IsLikelyDuplicate(e, x) })
+	if i < 0 {
+		return nil, nil
+	}
+	dup := expenses[i]
+	if onDuplicate == duplicateMerge {
+		return dup, nil
+	}
+	seen := fmt.Sprintf("%.2f %s %q of %s", dup.Amount, dup.Currency, dup.Description, a.formatDate(dup.Timestamp))
+	if !stdinIsTerminal() {
+		return nil, fmt.Errorf("this looks like a duplicate of %s; pass --on-duplicate merge or keep", seen)
+	}
+	in := bufio.NewReader(cmd.InOrStdin())
+	for {
+		fmt.Fprintf(cmd.ErrOrStderr(), "This looks like a duplicate of %s. Merge into it, keep both or cancel? [m/k/c] ", seen)
+		line, err := in.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return nil, fmt.Errorf("read answer: %w", err)
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "m", "merge":
+			return dup, nil
+		case "k", "keep":
+			return nil, nil
+		case "c", "cancel":
+			return nil, errors.New("cancelled; nothing was recorded")
+		}
+	}
+}
+
+// printDuplicates lists the imported rows merged into the expenses they
+// look like duplicates of, or that would be on a dry run.
+func (a *app) printDuplicates(w io.Writer, rows []export.ImportedRow, dryRun bool) {
+	verb := "merged into"
+	if dryRun {
+		verb = "would merge into"
+	}
+	for _, row := range rows {
+		x, d := row.Expense, row.DuplicateOf
+		fmt.Fprintf(w, "line %d: %.2f %s %q looks like a duplicate; %s %q of %s\n", row.Line, x.Amount, x.Currency,
+			x.Description, verb, d.Description, a.formatDate(d.Timestamp))
+	}
+}
+
+func newExpenseDuplicatesCmd(a *app) *cobra.Command {
+	var (
+		trip  string
+		merge bool
+	)
+	cmd := &cobra.Command{
+		Use:   "duplicates",
+		Short: "Find expenses recorded twice, and merge them",
+		Long: `Find expenses that look like duplicates of each other: on the same trip
+and day, for the same amount in the same currency, with a similar
+description.
+
+With --merge each group is merged into the expense recorded first, which
+takes on the notes, tags and merchant of the others; the others go to the
+trash, from which nomadic trash restore brings them back.`,
+		Example: `  nomadic expense duplicates
+  nomadic expense duplicates --trip tokyo --merge`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			var trips []*models.Trip
+			if trip != "" {
+				t, err := resolveTrip(ctx, a.store, trip)
+				if err != nil {
+					return err
+				}
+				trips = []*models.Trip{t}
+			} else {
+				var err error
+				if trips, err = a.store.ListTrips(ctx); err != nil {
+					return err
+				}
+			}
+			var groups []api.DuplicateGroup
+			out := cmd.OutOrStdout()
+			for _, t := range trips {
+				expenses, err := a.store.ListExpensesByTrip(ctx, t.ID)
+				if err != nil {
+					return err
+				}
+				// The expense recorded first is the one kept.
+				slices.SortStableFunc(expenses, func(x, y *models.Expense) int { return x.CreatedAt.Compare(y.CreatedAt) })
+				for _, group := range models.DuplicateGroups(expenses) {
+					kept := group[0]
+					if merge {
+						if err := a.mergeDuplicates(ctx, kept, group[1:]); err != nil {
+							return err
+						}
+					}
+					groups = append(groups, api.DuplicateGroup{Trip: t.Title, Kept: kept.ID, Expenses: apiExpenses(group)})
+					if a.json() {
+						continue
+					}
+					fmt.Fprintf(out, "%s, %s: %.2f %s\n", t.Title, a.formatDate(kept.Timestamp), kept.Amount, kept.Currency)
+					for i, x := range group {
+						mark := "  "
+						if merge && i == 0 {
+							mark = "✓ "
+						}
+						fmt.Fprintf(out, "  %s%s  %s\n", mark, x.ID, x.Description)
+					}
+				}
+			}
+			if a.json() {
+				if groups == nil {
+					groups = []api.DuplicateGroup{}
+				}
+				return printJSON(cmd, api.Duplicates{Merged: merge, Groups: groups})
+			}
+			dups := 0
+			for _, g := range groups {
+				dups += len(g.Expenses) - 1
+			}
+			switch {
+			case len(groups) == 0:
+				fmt.Fprintln(out, "No duplicate expenses")
+			case merge:
+				fmt.Fprintf(out, "Merged %s into %s; nomadic trash restore brings one back\n",
+					plural(dups, "duplicate", "duplicates"), plural(len(groups), "expense", "expenses"))
+			default:
+				fmt.Fprintf(out, "%s of %s; merge them with --merge\n",
+					plural(dups, "duplicate", "duplicates"), plural(len(groups), "expense", "expenses"))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "only this trip (default: all trips)")
+	cmd.Flags().BoolVar(&merge, "merge", false, "merge each group into the expense recorded first")
+	return cmd
+}
+
+// mergeDuplicates folds dups into x and moves them to the trash.
+func (a *app) mergeDuplicates(ctx context.Context, x *models.Expense, dups []*models.Expense) error {
+	changed := false
+	for _, d := range dups {
+		if models.MergeExpense(x, d) {
+			changed = true
+		}
+	}
+	if changed {
+		if err := a.store.SaveExpense(ctx, x); err != nil {
+			return err
+		}
+	}
+	for _, d := range dups {
+		if _, err := a.store.TrashExpense(ctx, d.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plural counts n of something, as "1 trip" or "3 trips".
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
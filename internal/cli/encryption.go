@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+// passphraseEnv names the variable that unlocks an encrypted database
+// without a prompt.
+const passphraseEnv = "NOMADIC_PASSPHRASE"
+
+func newEncryptionCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encryption",
+		Short: "Encrypt the database with a passphrase",
+		Long: `Encrypt the database at rest with a passphrase.
+
+The database is sealed with AES-256-GCM under a key derived from the
+passphrase with PBKDF2-SHA256. While nomadic runs it is decrypted in memory
+only. The TUI asks for the passphrase on startup; other commands prompt for
+it, or read it from $` + passphraseEnv + `.
+
+Passphrases are read from the terminal, or one per line from standard input
+when it is not a terminal. The documents of the vault and their scans are
+encrypted with the database; files attached to journal entries and the
+exchange-rate cache are not. There is no way to recover a
+forgotten passphrase.`,
+		Args: cobra.NoArgs,
+		// These commands work on the database file, not an open store.
+		PersistentPreRunE: dataDirOnly(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return encryptionStatus(cmd, a)
+		},
+	}
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show whether the database is encrypted",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return encryptionStatus(cmd, a)
+			},
+		},
+		&cobra.Command{
+			Use:   "enable",
+			Short: "Encrypt the database",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				in := bufio.NewReader(cmd.InOrStdin())
+				passphrase, err := newPassphrase(in)
+				if err != nil {
+					return err
+				}
+				if err := storage.EnableEncryption(cmd.Context(), a.dataDir, passphrase); err != nil {
+					return err
+				}
+				if a.json() {
+					return encryptionStatus(cmd, a)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "Encrypted the database. Keep your passphrase safe: it cannot be recovered.")
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "disable",
+			Short: "Decrypt the database back into a plain file",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				in := bufio.NewReader(cmd.InOrStdin())
+				current, err := currentPassphrase(in)
+				if err != nil {
+					return err
+				}
+				if err := storage.DisableEncryption(a.dataDir, current); err != nil {
+					return err
+				}
+				if a.json() {
+					return encryptionStatus(cmd, a)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "Decrypted the database.")
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "rotate",
+			Short: "Re-encrypt the database under a new passphrase and key",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				in := bufio.NewReader(cmd.InOrStdin())
+				current, err := currentPassphrase(in)
+				if err != nil {
+					return err
+				}
+				next, err := newPassphrase(in)
+				if err != nil {
+					return err
+				}
+				if err := storage.ChangePassphrase(a.dataDir, current, next); err != nil {
+					return err
+				}
+				if a.json() {
+					return encryptionStatus(cmd, a)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), "Re-encrypted the database with the new passphrase.")
+				return nil
+			},
+		},
+	)
+	return cmd
+}
+
+func encryptionStatus(cmd *cobra.Command, a *app) error {
+	encrypted := storage.IsEncrypted(a.dataDir)
+	if a.json() {
+		return printJSON(cmd, api.Encryption{DataDir: a.dataDir, Encrypted: encrypted})
+	}
+	if encrypted {
+		fmt.Fprintln(cmd.OutOrStdout(), "Encrypted:", a.dataDir)
+	} else {
+		fmt.Fprintln(cmd.OutOrStdout(), "Not encrypted:", a.dataDir)
+	}
+	return nil
+}
+
+// currentPassphrase reads the passphrase the database is encrypted with,
+// from $NOMADIC_PASSPHRASE when set.
+func currentPassphrase(in *bufio.Reader) (string, error) {
+	if p, ok := os.LookupEnv(passphraseEnv); ok {
+		return p, nil
+	}
+	return readSecret(in, "Current passphrase: ")
+}
+
+// newPassphrase reads a new passphrase, twice when typed at a terminal.
+func newPassphrase(in *bufio.Reader) (string, error) {
+	p, err := readSecret(in, "New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", errors.New("the passphrase must not be empty")
+	}
+	if stdinIsTerminal() {
+		again, err := readSecret(in, "Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != p {
+			return "", errors.New("the passphrases do not match")
+		}
+	}
+	return p, nil
+}
+
+// readSecret reads a passphrase from the terminal without echoing it, or a
+// line of in when standard input is not a terminal.
+func readSecret(in *bufio.Reader, prompt string) (string, error) {
+	if stdinIsTerminal() {
+		return promptSecret(prompt)
+	}
+	line, err := in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// promptSecret asks for a passphrase on the terminal.
+func promptSecret(prompt string) (string, error) {
+	if !stdinIsTerminal() {
+		return "", fmt.Errorf("the database is encrypted; set %s or run nomadic in a terminal", passphraseEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return string(b), nil
+}
+
+func stdinIsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
@@ -0,0 +1,370 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/quick"
+)
+
+func newExpenseCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "expense",
+		Short: "Record and list expenses",
+	}
+	cmd.AddCommand(newExpenseAddCmd(a), newExpenseListCmd(a), newExpenseExportCmd(a), newExpenseImportCmd(a),
+		newExpenseStatementCmd(a), newExpenseCategorizeCmd(a), newExpenseRuleCmd(a), newExpenseSettleCmd(a),
+		newExpenseReportCmd(a), newExpenseRecurringCmd(a), newExpenseDuplicatesCmd(a), newExpenseCategoryCmd(a), newExpenseRatesCmd(a),
+		newExpenseReceiptCmd(a), newExpenseReconcileCmd(a))
+	return cmd
+}
+
+func newExpenseAddCmd(a *app) *cobra.Command {
+	var (
+		trip     string
+		amount   float64
+		tax, tip float64
+		currency string
+		category string
+		date     string
+		note     string
+		tags     []string
+		leg      string
+		location string
+		paidBy   string
+		split    string
+		onDup    string
+	)
+	cmd := &cobra.Command{
+		Use:   "add [description]",
+		Short: "Record an expense",
+		Long: `Record an expense.
+
+Without --amount the description is read as the expense typed in one line,
+as it would be said: "14.50 eur lunch ramen yesterday" is 14.50 EUR for food
+on the day before. Flags given still win over what is read.`,
+		Example: `  nomadic expense add "14.50 eur lunch ramen yesterday"
+  nomadic expense add --trip tokyo "taxi to the airport 4200 jpy last monday"
+  nomadic expense add --amount 12.50 --currency EUR --category food "Lunch at the market"
+  nomadic expense add --trip tokyo --amount 1200 --currency JPY --category transport Metro
+  nomadic expense add --amount 58 --tax 4.80 --tip 8 --category food "Dinner at Cervejaria Ramiro"
+  nomadic expense add --trip lisbon --amount 60 --paid-by Ana --split all Dinner
+  nomadic expense add --trip lisbon --amount 30 --split "me=10, Ben" Taxi
+  nomadic expense add --amount 12.50 --category food --on-duplicate merge Lunch`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if !cmd.Flags().Changed("amount") && len(args) == 1 {
+				rules, err := a.store.ListRules(ctx)
+				if err != nil {
+					return err
+				}
+				// A line naming no amount is a description; the check below
+				// asks for --amount.
+				if q, err := quick.ParseExpense(args[0], time.Now(), a.cfg.Layout(), rules); err == nil {
+					amount = q.Amount
+					if !cmd.Flags().Changed("currency") && q.Currency != "" {
+						currency = q.Currency
+					}
+					if !cmd.Flags().Changed("category") {
+						category = q.Category
+					}
+					if !cmd.Flags().Changed("date") && !q.Day.IsZero() {
+						date = q.Day.Format(models.DateLayout)
+					}
+					args[0] = q.Description
+				}
+			}
+			if amount <= 0 {
+				return errors.New("--amount must be greater than zero, or the description name an amount")
+			}
+			if err := models.CheckBreakdown(amount, tax, tip); err != nil {
+				return fmt.Errorf("--tax, --tip: %w", err)
+			}
+			if currency == "" {
+				currency = a.cfg.DefaultCurrency
+			}
+			cur, err := models.ParseCurrency(currency)
+			if err != nil {
+				return fmt.Errorf("--currency: %w", err)
+			}
+			cat, err := models.ParseCategory(category)
+			if err != nil {
+				return fmt.Errorf("--category: %w", err)
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			at, err := a.tripMoment(ctx, t, date, leg, location)
+			if err != nil {
+				return err
+			}
+			description := cat
+			if len(args) == 1 && strings.TrimSpace(args[0]) != "" {
+				description = strings.TrimSpace(args[0])
+			}
+
+			x := models.NewExpense(t.ID, amount, cur, cat, description, at.ts)
+			x.TimeZone = at.zone
+			x.Tax, x.Tip = tax, tip
+			x.Location = location
+			x.Note = note
+			x.Tags = splitList(tags)
+			if at.leg != nil {
+				x.LegID = at.leg.ID
+			}
+			if paidBy != "" {
+				p, err := models.ResolvePerson(paidBy, t.People())
+				if err != nil {
+					return fmt.Errorf("--paid-by: %w", err)
+				}
+				if p != models.Me {
+					x.PaidBy = p
+				}
+			}
+			if x.Shares, err = models.ParseSplit(split, amount, t.People()); err != nil {
+				return fmt.Errorf("--split: %w", err)
+			}
+			dup, err := a.duplicateOf(cmd, x, onDup)
+			if err != nil {
+				return err
+			}
+			if dup != nil {
+				models.MergeExpense(dup, x)
+				if err := a.store.SaveExpense(ctx, dup); err != nil {
+					return err
+				}
+				if a.json() {
+					return printJSON(cmd, apiExpense(dup))
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Merged into %.2f %s %q of %s on %q\n", dup.Amount, dup.Currency,
+					dup.Description, a.formatDate(dup.Timestamp), t.Title)
+				return nil
+			}
+			// Recording offline leaves the rate for nomadic expense rates.
+			locking := a.lockRate(ctx, x)
+			if err := a.store.SaveExpense(ctx, x); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiExpense(x))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Recorded %.2f %s for %s on %q\n", x.Amount, x.Currency, x.Category, t.Title)
+			if locking != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Its exchange rate is not locked in (%v); nomadic expense rates locks it later\n", locking)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.Float64Var(&amount, "amount", 0, "amount spent")
+	f.Float64Var(&tax, "tax", 0, "part of the amount that went on tax")
+	f.Float64Var(&tip, "tip", 0, "part of the amount that went on a tip")
+	f.StringVar(&currency, "currency", "", "three-letter currency code, e.g. EUR (default from config)")
+	f.StringVar(&category, "category", models.CategoryOther, categoryUsage)
+	f.StringVar(&date, "date", "", "date, YYYY-MM-DD (default today)")
+	f.StringVar(&note, "note", "", "optional note")
+	f.StringSliceVar(&tags, "tags", nil, "tags; repeat or separate with commas")
+	f.StringVar(&leg, "leg", "", "leg of the trip, by ID or location (default: the leg of the expense's day)")
+	f.StringVar(&location, "location", "", "city where the money was spent, which sets its time zone")
+	f.StringVar(&paidBy, "paid-by", "", `who paid: "me" or a companion of the trip (default me)`)
+	f.StringVar(&split, "split", "", `who shares it: "all", names sharing equally, or name=amount`)
+	f.StringVar(&onDup, "on-duplicate", duplicateAsk, "when it looks like an expense already recorded: ask, merge into it, or keep both")
+	return cmd
+}
+
+func newExpenseListCmd(a *app) *cobra.Command {
+	var (
+		trip string
+		tags []string
+		leg  string
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a trip's expenses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			expenses, err := a.store.ListExpensesByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			expenses = slices.DeleteFunc(expenses, func(x *models.Expense) bool { return !models.HasTags(x.Tags, tags) })
+			if leg != "" {
+				l, err := resolveLeg(ctx, a.store, t, leg)
+				if err != nil {
+					return err
+				}
+				expenses = slices.DeleteFunc(expenses, func(x *models.Expense) bool { return x.LegID != l.ID })
+			}
+			if a.json() {
+				return printJSON(cmd, apiExpenses(expenses))
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DATE\tCATEGORY\tAMOUNT\tDESCRIPTION\tTAGS")
+			for _, x := range expenses {
+				fmt.Fprintf(w, "%s\t%s\t%.2f %s\t%s\t%s\n", a.formatDate(x.Timestamp), x.Category,
+					x.Amount, x.Currency, x.Description, strings.Join(x.Tags, ", "))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "only expenses with this tag; repeat to require several")
+	cmd.Flags().StringVar(&leg, "leg", "", "only expenses of this leg, by ID or location")
+	return cmd
+}
+
+func newExpenseExportCmd(a *app) *cobra.Command {
+	var trip, output string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export expenses as CSV",
+		Example: `  nomadic expense export > expenses.csv
+  nomadic expense export --trip tokyo --output tokyo.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			var trips []*models.Trip
+			if trip != "" {
+				t, err := resolveTrip(ctx, a.store, trip)
+				if err != nil {
+					return err
+				}
+				trips = []*models.Trip{t}
+			} else {
+				var err error
+				if trips, err = a.store.ListTrips(ctx); err != nil {
+					return err
+				}
+			}
+			out := make([]*export.Trip, 0, len(trips))
+			for _, t := range trips {
+				x, err := export.Load(ctx, a.store, t)
+				if err != nil {
+					return err
+				}
+				out = append(out, x)
+			}
+
+			var w io.Writer = cmd.OutOrStdout()
+			if output != "" && output != "-" {
+				f, err := os.Create(output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			return export.WriteExpensesCSV(w, out)
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "export only this trip (default: all trips)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write to this file instead of standard output")
+	return cmd
+}
+
+func newExpenseImportCmd(a *app) *cobra.Command {
+	var (
+		trip            string
+		mapping         []string
+		dryRun          bool
+		allowDuplicates bool
+	)
+	cmd := &cobra.Command{
+		Use:   "import <file.csv>",
+		Short: "Import expenses from CSV",
+		Long: `Import expenses from a CSV file with a header row.
+
+Columns are matched by name: ` + strings.Join(export.CSVFields, ", ") + `.
+Use --map to read a field from a differently named column. Only date and
+amount are required; currency defaults to the configured default_currency
+and category to other.
+
+Rows go to the trip named in their trip column, or to --trip. A row that
+looks like a duplicate of an existing expense or an earlier row (same trip,
+day, amount and currency, and a similar description) is merged into it,
+adding its note, tags and merchant, unless --allow-duplicates keeps both.`,
+		Example: `  nomadic expense import expenses.csv
+  nomadic expense import --trip tokyo --map date=When --map amount=Cost --map description=What bank.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			m, err := export.ParseMapping(mapping)
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			rows, err := export.ParseExpensesCSV(f, export.ImportOptions{
+				Mapping:         m,
+				DateLayouts:     []string{a.cfg.Layout()},
+				DefaultCurrency: a.cfg.DefaultCurrency,
+			})
+			if err != nil {
+				return err
+			}
+
+			trips := map[string]*models.Trip{}
+			tripFor := func(ref string) (*models.Trip, error) {
+				if trip != "" || ref == "" {
+					ref = trip
+				}
+				if t, ok := trips[ref]; ok {
+					return t, nil
+				}
+				t, err := resolveTrip(ctx, a.store, ref)
+				if err != nil {
+					return nil, err
+				}
+				trips[ref] = t
+				return t, nil
+			}
+			report, err := export.ImportExpenses(ctx, a.store, rows, tripFor, allowDuplicates, dryRun)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiImport(report, dryRun))
+			}
+
+			out := cmd.OutOrStdout()
+			a.printDuplicates(out, report.Duplicates, dryRun)
+			for _, row := range report.Failed {
+				fmt.Fprintf(out, "line %d: %v\n", row.Line, row.Err)
+			}
+			verb := "Imported"
+			if dryRun {
+				verb = "Would import"
+			}
+			fmt.Fprintf(out, "%s %d expenses (%d duplicates merged, %d errors)\n", verb, len(report.Imported),
+				len(report.Duplicates), len(report.Failed))
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "import every row into this trip")
+	f.StringArrayVar(&mapping, "map", nil, "read a field from a named column, as field=Column")
+	f.BoolVar(&dryRun, "dry-run", false, "report what would be imported without saving")
+	f.BoolVar(&allowDuplicates, "allow-duplicates", false, "import rows that look like duplicates too, instead of merging them")
+	return cmd
+}
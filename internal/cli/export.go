@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+func newExportCmd(a *app) *cobra.Command {
+	var (
+		trip   string
+		format string
+		output string
+		redact string
+		all    bool
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export trips with their entries, expenses and itinerary",
+		Long: `Export trips with their entries, expenses and itinerary.
+
+JSON writes a single document to standard output or --output. Markdown,
+PDF, iCal, GeoJSON and KML write one file per trip into the --output
+directory (default: the current directory). The PDF is a printable trip report with a cover
+page, the itinerary, journal entries and expense tables with totals, ready
+to share with travel companions. The iCal (.ics) calendar has an event for
+each itinerary item, in the time zone of its place, with a reminder for
+items that have an alarm set; import it into your calendar app.
+
+GeoJSON and KML map the trip's route for any mapping tool: a point for
+each leg, in order, and a line joining them, a point for each check-in and
+the line of each GPS track imported with nomadic track import, each with
+what is known of it, such as the dates of a leg or the distance of a
+track. Places not in the places dataset are left off the map.
+
+With --all, JSON is instead a complete dump of the data directory: every
+trip with everything recorded on it, the people, categories, templates,
+packing lists, rules, recurring expenses, country notes, wishes and
+achievements, and the files of attachments and documents. What is in the
+trash is left out, as is the vault of travel documents. Read it on another
+device with nomadic import.
+
+--redact strip shares the trips without what is private: it leaves out the
+entries marked private with nomadic journal privacy, and the private
+passages of entries and trip notes, fenced by "::: private" and ":::"
+lines; mask leaves a mark where they were instead. A dump redacted leaves
+out the photos and revisions of private entries too. The redact setting
+is the default, none unless set.`,
+		Example: `  nomadic export > nomadic.json
+  nomadic export --trip tokyo --output tokyo.json
+  nomadic export --format markdown --trip tokyo --output ~/Documents/trips
+  nomadic export --format pdf --trip tokyo --redact strip
+  nomadic export --format ics --trip tokyo --output ~/Calendars
+  nomadic export --format geojson --trip patagonia --output ~/Maps
+  nomadic export --all --output nomadic-dump.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			switch format {
+			case "json", "markdown", "pdf", "ics", "geojson", "kml":
+			default:
+				return fmt.Errorf("unsupported --format %q", format)
+			}
+			if all && (format != "json" || trip != "") {
+				return errors.New("--all dumps everything as JSON; leave out --format and --trip")
+			}
+			r, err := a.redaction(redact)
+			if err != nil {
+				return err
+			}
+			if all {
+				d, err := a.store.Dump(ctx)
+				if err != nil {
+					return err
+				}
+				d = export.RedactDump(d, r)
+				return writeOutput(cmd, output, func(w io.Writer) error { return export.Dump(w, d) })
+			}
+			var trips []*models.Trip
+			if trip != "" {
+				t, err := resolveTrip(ctx, a.store, trip)
+				if err != nil {
+					return err
+				}
+				trips = []*models.Trip{t}
+			} else {
+				var err error
+				if trips, err = a.store.ListTrips(ctx); err != nil {
+					return err
+				}
+			}
+
+			out := make([]*export.Trip, 0, len(trips))
+			for _, t := range trips {
+				x, err := export.Load(ctx, a.store, t)
+				if err != nil {
+					return err
+				}
+				x.FieldLabels = a.cfg.TripFields
+				out = append(out, x.Redact(r))
+			}
+
+			if format != "json" {
+				dir := output
+				if dir == "" || dir == "-" {
+					dir = "."
+				}
+				var (
+					paths []string
+					err   error
+				)
+				switch format {
+				case "markdown":
+					paths, err = export.WriteMarkdownFiles(dir, out, a.cfg.Layout())
+				case "pdf":
+					paths, err = export.WritePDFFiles(dir, out, a.cfg.Layout())
+				case "ics":
+					paths, err = export.WriteICalFiles(dir, out)
+				case "geojson":
+					paths, err = export.WriteGeoJSONFiles(dir, out)
+				case "kml":
+					paths, err = export.WriteKMLFiles(dir, out)
+				}
+				for _, p := range paths {
+					fmt.Fprintf(cmd.OutOrStdout(), "Wrote %s\n", p)
+				}
+				return err
+			}
+
+			return writeOutput(cmd, output, func(w io.Writer) error { return export.JSON(w, out) })
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "export only this trip (default: all trips)")
+	f.StringVar(&format, "format", "json", "output format: json, markdown, pdf, ics, geojson or kml")
+	f.StringVarP(&output, "output", "o", "", "file (json) or directory (the other formats) to write to")
+	f.StringVar(&redact, "redact", "", "none, strip or mask what is private (default: the redact setting)")
+	f.BoolVar(&all, "all", false, "dump everything in the data directory as JSON, for nomadic import")
+	return cmd
+}
+
+// redaction reads the value of a --redact flag, or else the redact
+// setting.
+func (a *app) redaction(flag string) (models.Redaction, error) {
+	if flag == "" {
+		flag = a.cfg.Redact
+	}
+	r, err := models.ParseRedaction(flag)
+	if err != nil {
+		return "", fmt.Errorf("--redact: %w", err)
+	}
+	return r, nil
+}
+
+// writeOutput has write write to the file output, or to standard output
+// when it is empty or "-".
+func writeOutput(cmd *cobra.Command, output string, write func(io.Writer) error) error {
+	if output == "" || output == "-" {
+		return write(cmd.OutOrStdout())
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+func newTripFieldsCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "fields [key=value]...",
+		Short: "List or set a trip's own fields, such as a visa number",
+		Long: `List or set the values of the fields trips have of their own, such as a
+visa number, a travel insurance policy or a loyalty programme. The fields
+are set up in the config, a key with its label:
+
+  nomadic config set trip_fields.visa "Visa number"
+
+Give key=value to set a field; an empty value clears it. The fields are
+shown and edited in the TUI's trip form too, and exported with the trip.`,
+		Example: `  nomadic trip fields --trip japan
+  nomadic trip fields --trip japan visa=JP-88213 insurance="WorldCover 4471"
+  nomadic trip fields --trip japan visa=`,
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			if len(args) > 0 {
+				if err := a.setTripFields(t, args); err != nil {
+					return err
+				}
+				if err := a.store.SaveTrip(ctx, t); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrip(t))
+			}
+			fields := a.cfg.TripFieldList()
+			if len(fields) == 0 && len(t.Fields) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), `No trip fields set up; add one with nomadic config set trip_fields.<key> "<label>"`)
+				return nil
+			}
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			shown := map[string]bool{}
+			for _, f := range fields {
+				shown[f.Key] = true
+				value := t.Fields[f.Key]
+				if value == "" {
+					value = "-"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Key, f.Label, value)
+			}
+			// Values of fields since taken out of the config are kept.
+			for key, value := range t.Fields {
+				if !shown[key] {
+					fmt.Fprintf(tw, "%s\t(no longer set up)\t%s\n", key, value)
+				}
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+// setTripFields sets the trip fields given as key=value pairs, each key
+// one set up in the config.
+func (a *app) setTripFields(t *models.Trip, pairs []string) error {
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("%q is not key=value", pair)
+		}
+		key = strings.TrimSpace(key)
+		if _, ok := a.cfg.TripFields[key]; !ok {
+			return fmt.Errorf("no trip field %q; %s", key, a.tripFieldKeys())
+		}
+		t.SetField(key, value)
+	}
+	return nil
+}
+
+// tripFieldKeys tells which trip fields are set up, for an error message.
+func (a *app) tripFieldKeys() string {
+	fields := a.cfg.TripFieldList()
+	if len(fields) == 0 {
+		return "set one up with nomadic config set trip_fields.<key> \"<label>\""
+	}
+	keys := make([]string, len(fields))
+	for i, f := range fields {
+		keys[i] = f.Key
+	}
+	return "the fields are " + strings.Join(keys, ", ")
+}
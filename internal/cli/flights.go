@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/flights"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newTripFlightsCmd(a *app) *cobra.Command {
+	var (
+		trip, alarm                 string
+		from, to, date, at, arrives string
+		number, ref                 string
+		dryRun                      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "flights [file]",
+		Short: "Add the flights of a booking confirmation, or one by hand, to a trip's itinerary",
+		Long: `Read the flights of a booking confirmation, such as an airline's email
+saved as text or pasted into standard input, and add each to the trip's
+itinerary on its departure day: the flight number and airports as the
+title, the departure city as the place and the booking reference.
+
+Flights are found from their numbers, with the airports, dates and times
+written near them, so most airlines' and travel agents' emails work, as do
+GDS itineraries. Dates without a year are taken in the year nearest the
+trip's start. Flights already in the itinerary are left out. Check what
+would be added with --dry-run.
+
+Add a flight by hand instead with --from and --to, each an airport's IATA
+code or name, such as HND or Haneda, and its --date. The airports' names
+and cities come from the dataset built into nomadic, and the notes give
+the distance flown; look airports up with nomadic places airport.`,
+		Example: `  nomadic trip flights --trip japan confirmation.txt
+  pbpaste | nomadic trip flights --trip japan --alarm 3h --dry-run
+  nomadic trip flights --trip japan --from LIS --to Haneda --date 2026-11-02 --time 13:25 --number "TP 1234" --arrives "+1 09:40"`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			minutes, err := models.ParseAlarm(alarm)
+			if err != nil {
+				return fmt.Errorf("--alarm: %w", err)
+			}
+			byHand := from != "" || to != ""
+			if byHand && len(args) == 1 {
+				return errors.New("either read a confirmation or add a flight with --from and --to, not both")
+			}
+			if !byHand && (date != "" || at != "" || arrives != "" || number != "" || ref != "") {
+				return errors.New("--date, --time, --arrives, --number and --ref need --from and --to")
+			}
+			var text []byte
+			if byHand {
+				// The flight is given by flags.
+			} else if len(args) == 1 {
+				text, err = os.ReadFile(args[0])
+			} else {
+				text, err = io.ReadAll(cmd.InOrStdin())
+			}
+			if err != nil {
+				return err
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			var found []flights.Flight
+			if byHand {
+				f, err := a.flight(from, to, date, at, arrives, number, ref)
+				if err != nil {
+					return err
+				}
+				found = []flights.Flight{f}
+			} else if found, err = flights.Parse(string(text), t.StartDate); err != nil {
+				return err
+			}
+			existing, err := a.store.ListItineraryByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			items, duplicates := flights.Items(t.ID, found, existing)
+			for _, it := range items {
+				it.Alarm = minutes
+				if dryRun {
+					continue
+				}
+				if err := a.store.SaveItineraryItem(ctx, it); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, api.Flights{DryRun: dryRun, Added: apiItineraryItems(items), Duplicates: apiItineraryItems(duplicates)})
+			}
+			out := cmd.OutOrStdout()
+			verb := "Added"
+			if dryRun {
+				verb = "Would add"
+			}
+			for _, it := range items {
+				fmt.Fprintf(out, "%s %s on %s %s\n", verb, it.Title, a.formatDate(it.Day), it.Time)
+			}
+			for _, it := range duplicates {
+				fmt.Fprintf(out, "Already in the itinerary: %s on %s\n", it.Title, a.formatDate(it.Day))
+			}
+			if len(items) > 0 && !dryRun {
+				fmt.Fprintf(out, "Flights added to %q: %d\n", t.Title, len(items))
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.StringVar(&alarm, "alarm", "", "how long before each flight to be reminded in calendar exports, e.g. 3h")
+	f.BoolVar(&dryRun, "dry-run", false, "report what would be added without saving")
+	f.StringVar(&from, "from", "", "departure airport, by IATA code or name, to add a flight by hand")
+	f.StringVar(&to, "to", "", "arrival airport, by IATA code or name")
+	f.StringVar(&date, "date", "", "departure date, YYYY-MM-DD")
+	f.StringVar(&at, "time", "", "departure time, HH:MM")
+	f.StringVar(&arrives, "arrives", "", `arrival time, HH:MM, with "+1" before it for the next day`)
+	f.StringVar(&number, "number", "", `flight number, e.g. "NH 278"`)
+	f.StringVar(&ref, "ref", "", "booking reference")
+	return cmd
+}
+
+// flight makes the flight added by hand with the flags of trip flights.
+func (a *app) flight(from, to, date, at, arrives, number, ref string) (flights.Flight, error) {
+	var f flights.Flight
+	for _, s := range []struct {
+		flag, query string
+		stop        *flights.Stop
+	}{{"--from", from, &f.Departure}, {"--to", to, &f.Arrival}} {
+		if s.query == "" {
+			return f, fmt.Errorf("%s is required for a flight added by hand", s.flag)
+		}
+		ap, err := findAirport(s.query)
+		if err != nil {
+			return f, fmt.Errorf("%s: %w", s.flag, err)
+		}
+		s.stop.Airport, s.stop.Name, s.stop.City = ap.Code, ap.Name, ap.City
+	}
+	if f.Departure.Airport == f.Arrival.Airport {
+		return f, fmt.Errorf("--from and --to are both %s", f.Departure.Airport)
+	}
+	if date == "" {
+		return f, errors.New("--date is required for a flight added by hand")
+	}
+	day, err := a.parseDay("--date", date)
+	if err != nil {
+		return f, err
+	}
+	f.Departure.Day = day
+	if f.Departure.Time, err = parseClock("--time", at); err != nil {
+		return f, err
+	}
+	if arrives != "" {
+		var days int
+		if f.Arrival.Time, days, err = flights.ParseArrival(arrives); err != nil {
+			return f, fmt.Errorf("--arrives: %w", err)
+		}
+		f.Arrival.Day = day.AddDate(0, 0, days)
+	}
+	if number != "" {
+		code, n, ok := flights.ParseDesignator(number)
+		if !ok {
+			return f, fmt.Errorf(`invalid --number %q: use the airline code and number, e.g. "NH 278"`, number)
+		}
+		f.Code, f.Number, f.Airline = code, n, code
+		if name, ok := flights.Airline(code); ok {
+			f.Airline = name
+		}
+	}
+	f.Reference = strings.ToUpper(strings.TrimSpace(ref))
+	return f, nil
+}
+
+// findAirport finds the airport query names, suggesting those it may have
+// meant when it names none or more than one.
+func findAirport(query string) (flights.Airport, error) {
+	if ap, ok := flights.FindAirport(query); ok {
+		return ap, nil
+	}
+	if similar := flights.SearchAirports(query, 5); len(similar) > 0 {
+		names := make([]string, len(similar))
+		for i, s := range similar {
+			names[i] = s.String()
+		}
+		return flights.Airport{}, fmt.Errorf("no single airport called %q; did you mean %s?", query, strings.Join(names, "; "))
+	}
+	return flights.Airport{}, fmt.Errorf("no airport called %q in the dataset", query)
+}
+
+// parseClock normalises the time of day of a flag, such as "9:30", to
+// "09:30". Empty stays empty.
+func parseClock(flag, v string) (string, error) {
+	if v = strings.TrimSpace(v); v == "" {
+		return "", nil
+	}
+	t, err := time.Parse(models.TimeLayout, v)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s %q: use HH:MM", flag, v)
+	}
+	return t.Format(models.TimeLayout), nil
+}
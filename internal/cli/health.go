@@ -0,0 +1,264 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/stats"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+func newHealthCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "health",
+		Aliases: []string{"jetlag"},
+		Short:   "Log sleep, water and jetlag per day of a trip",
+		Long: `Log for each day of a trip the hours slept, the litres of water drunk and
+how jetlagged it felt, from 1 (not at all) to 5 (badly), and see them next
+to the jetlag its time zones make for and the mood of the day's entries.
+
+The jetlag expected follows the body clock: it leaves on the time of
+home_zone (this machine's by default) and catches up with where the trip is
+by about an hour a day after flying east and an hour and a half after
+flying west. Every two hours it is behind make a step of jetlag.`,
+	}
+	cmd.AddCommand(newHealthLogCmd(a), newHealthShowCmd(a), newHealthRemoveCmd(a))
+	return cmd
+}
+
+func newHealthLogCmd(a *app) *cobra.Command {
+	var (
+		trip, date, note string
+		sleep, water     float64
+		jetlag           int
+	)
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Log how a day went for the body",
+		Long: `Log the hours slept, litres drunk, jetlag felt or a note for a day of a trip,
+today by default. What is not given stays as it was logged for the day.`,
+		Example: `  nomadic health log --sleep 6.5 --water 2 --jetlag 4
+  nomadic health log --trip tokyo --date 2026-04-02 --jetlag 2 --note "Woke at 4am"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			f := cmd.Flags()
+			if !f.Changed("sleep") && !f.Changed("water") && !f.Changed("jetlag") && !f.Changed("note") {
+				return errors.New("log at least one of --sleep, --water, --jetlag or --note")
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			at, err := a.tripMoment(ctx, t, date, "", "")
+			if err != nil {
+				return err
+			}
+			day := at.ts.Format(models.DateLayout)
+			h, err := a.store.GetHealthDay(ctx, t.ID, day)
+			if errors.Is(err, storage.ErrNotFound) {
+				h, err = &models.HealthDay{TripID: t.ID, Day: day}, nil
+			}
+			if err != nil {
+				return err
+			}
+			if f.Changed("sleep") {
+				h.Sleep = sleep
+			}
+			if f.Changed("water") {
+				h.Water = water
+			}
+			if f.Changed("jetlag") {
+				h.Jetlag = jetlag
+			}
+			if f.Changed("note") {
+				h.Note = strings.TrimSpace(note)
+			}
+			if err := models.CheckHealth(h.Sleep, h.Water, h.Jetlag); err != nil {
+				return err
+			}
+			if err := a.store.SaveHealthDay(ctx, h); err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printHealth(cmd, t)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged %s of %q: %s\n", a.formatDate(at.ts), t.Title, healthSummary(h))
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", tripFlagUsage)
+	f.StringVar(&date, "date", "", "date, YYYY-MM-DD (default today)")
+	f.Float64Var(&sleep, "sleep", 0, "hours slept the night before")
+	f.Float64Var(&water, "water", 0, "litres of water drunk")
+	f.IntVar(&jetlag, "jetlag", 0, "how jetlagged the day felt, from 1 (not at all) to 5 (badly), or 0 to clear it")
+	f.StringVar(&note, "note", "", "optional note")
+	return cmd
+}
+
+func newHealthShowCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show each day's sleep, water and jetlag next to the jetlag expected and mood",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, err := resolveTrip(cmd.Context(), a.store, trip)
+			if err != nil {
+				return err
+			}
+			return a.printHealth(cmd, t)
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newHealthRemoveCmd(a *app) *cobra.Command {
+	var trip, date string
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Forget what was logged for a day",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			at, err := a.tripMoment(ctx, t, date, "", "")
+			if err != nil {
+				return err
+			}
+			err = a.store.DeleteHealthDay(ctx, t.ID, at.ts.Format(models.DateLayout))
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("nothing is logged for %s of %q", a.formatDate(at.ts), t.Title)
+			}
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printHealth(cmd, t)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Forgot what was logged for %s of %q\n", a.formatDate(at.ts), t.Title)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	cmd.Flags().StringVar(&date, "date", "", "date, YYYY-MM-DD (default today)")
+	return cmd
+}
+
+// tripHealth lines up the days of trip t with what was logged of them, the
+// jetlag expected and their mood.
+func (a *app) tripHealth(ctx context.Context, t *models.Trip) ([]stats.DayHealth, error) {
+	legs, err := a.store.ListLegsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	logged, err := a.store.ListHealthByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := a.store.ListEntriesByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	curve := stats.JetlagCurve(t, legs, models.Zone(a.cfg.HomeZone), time.Now())
+	return stats.Health(curve, logged, stats.MoodTrend(entries)), nil
+}
+
+func (a *app) printHealth(cmd *cobra.Command, t *models.Trip) error {
+	days, err := a.tripHealth(cmd.Context(), t)
+	if err != nil {
+		return err
+	}
+	if a.json() {
+		return printJSON(cmd, apiHealth(t, a.cfg.HomeZone, days))
+	}
+	if len(days) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "%q has not started yet; log its days with `nomadic health log`.\n", t.Title)
+		return nil
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tSLEEP\tWATER\tJETLAG\tEXPECTED\tSHIFT\tMOOD\tNOTE")
+	for _, d := range days {
+		sleep, water, jetlag, note := "-", "-", "-", ""
+		if h := d.Logged; h != nil {
+			if h.Sleep > 0 {
+				sleep = strconv.FormatFloat(h.Sleep, 'f', -1, 64) + " h"
+			}
+			if h.Water > 0 {
+				water = strconv.FormatFloat(h.Water, 'f', -1, 64) + " l"
+			}
+			if h.Jetlag > 0 {
+				jetlag = strconv.Itoa(h.Jetlag)
+			}
+			note = h.Note
+		}
+		expected, shift, mood := "-", "-", "-"
+		if d.Expected > 0 {
+			expected, shift = fmt.Sprintf("%.1f", d.Expected), formatShift(d.Shift)
+		}
+		if d.Mood > 0 {
+			mood = fmt.Sprintf("%.1f", d.Mood)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", a.formatDate(d.Day), sleep, water, jetlag, expected, shift, mood, note)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	sleep, water, jetlag := stats.Averages(days)
+	var averages []string
+	if sleep > 0 {
+		averages = append(averages, fmt.Sprintf("%.1f h of sleep", sleep))
+	}
+	if water > 0 {
+		averages = append(averages, fmt.Sprintf("%.1f l of water", water))
+	}
+	if jetlag > 0 {
+		averages = append(averages, fmt.Sprintf("jetlag %.1f", jetlag))
+	}
+	if len(averages) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "\nOn average: %s\n", strings.Join(averages, ", "))
+	}
+	return nil
+}
+
+// healthSummary describes what is logged for a day in a line.
+func healthSummary(h *models.HealthDay) string {
+	var parts []string
+	if h.Sleep > 0 {
+		parts = append(parts, strconv.FormatFloat(h.Sleep, 'f', -1, 64)+" h of sleep")
+	}
+	if h.Water > 0 {
+		parts = append(parts, strconv.FormatFloat(h.Water, 'f', -1, 64)+" l of water")
+	}
+	if h.Jetlag > 0 {
+		parts = append(parts, fmt.Sprintf("jetlag %d of %d", h.Jetlag, models.MaxRating))
+	}
+	if h.Note != "" {
+		parts = append(parts, strconv.Quote(h.Note))
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatShift writes a time zone shift in hours, such as +8h or -5.5h.
+func formatShift(hours float64) string {
+	if hours == 0 {
+		return "0h"
+	}
+	return fmt.Sprintf("%+gh", hours)
+}
@@ -0,0 +1,360 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+func newHighlightCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "highlight",
+		Aliases: []string{"highlights", "goals"},
+		Short:   "Keep a list of goals and highlights per trip",
+		Long: `Keep a list per trip of the things to do there, its goals, and check them
+off as they happen, which makes them the trip's highlights. Things that
+happened without being planned are added with --done. A highlight can be
+linked to the journal entry that tells of it; exports of the trip list its
+highlights with the entries they link to.`,
+	}
+	cmd.AddCommand(newHighlightListCmd(a), newHighlightAddCmd(a), newHighlightCheckCmd(a, true),
+		newHighlightCheckCmd(a, false), newHighlightLinkCmd(a), newHighlightUnlinkCmd(a),
+		newHighlightMoveCmd(a), newHighlightRemoveCmd(a))
+	return cmd
+}
+
+func newHighlightListCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show a trip's goals and highlights",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			return a.printHighlights(cmd, t)
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newHighlightAddCmd(a *app) *cobra.Command {
+	var (
+		trip, entry string
+		done        bool
+	)
+	cmd := &cobra.Command{
+		Use:   "add <title>...",
+		Short: "Add goals or highlights to a trip",
+		Example: `  nomadic highlight add --trip japan "Climb Mount Fuji" "See the cherry blossoms"
+  nomadic highlight add --trip japan --done --entry "Night in Shinjuku" "Karaoke till dawn"`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			var e *models.Entry
+			if entry != "" {
+				if e, err = a.tripEntry(ctx, t, entry); err != nil {
+					return err
+				}
+			}
+			for _, title := range args {
+				h := models.NewHighlight(t.ID, title)
+				if h.Title == "" {
+					return errors.New("a goal or highlight needs a title")
+				}
+				h.Done = done || e != nil
+				if e != nil {
+					h.EntryID = e.ID
+				}
+				if err := a.store.SaveHighlight(ctx, h); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return a.printHighlights(cmd, t)
+			}
+			kind := plural(len(args), "goal", "goals")
+			if done || e != nil {
+				kind = plural(len(args), "highlight", "highlights")
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %s to %q\n", kind, t.Title)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	cmd.Flags().BoolVar(&done, "done", false, "add highlights that already happened rather than goals")
+	cmd.Flags().StringVar(&entry, "entry", "", "journal entry telling of the highlights, by ID or title; implies --done")
+	return cmd
+}
+
+// newHighlightCheckCmd is "highlight check" when done is set, "highlight
+// uncheck" otherwise.
+func newHighlightCheckCmd(a *app, done bool) *cobra.Command {
+	var trip string
+	use, short, did := "check", "Check goals off as done, making them highlights", "Done"
+	if !done {
+		use, short, did = "uncheck", "Make highlights goals again", "Not done"
+	}
+	cmd := &cobra.Command{
+		Use:   use + " <goal>...",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			highlights, err := a.store.ListHighlightsByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			for _, ref := range args {
+				h, err := resolveHighlight(highlights, ref)
+				if err != nil {
+					return err
+				}
+				h.Done = done
+				if err := a.store.SaveHighlight(ctx, h); err != nil {
+					return err
+				}
+				if !a.json() {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s: %q\n", did, h.Title)
+				}
+			}
+			if a.json() {
+				return a.printHighlights(cmd, t)
+			}
+			n, total := models.HighlightProgress(highlights)
+			fmt.Fprintf(cmd.OutOrStdout(), "%d of %d done\n", n, total)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newHighlightLinkCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "link <highlight> <entry>",
+		Short: "Link a highlight to the journal entry where it happened",
+		Long: `Link a goal or highlight to the journal entry of the trip that tells of it,
+by the entry's ID or title. A goal linked to an entry is checked off.`,
+		Example: `  nomadic highlight link --trip japan fuji "Summit at dawn"`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, h, err := a.resolveHighlight(ctx, trip, args[0])
+			if err != nil {
+				return err
+			}
+			e, err := a.tripEntry(ctx, t, args[1])
+			if err != nil {
+				return err
+			}
+			h.EntryID, h.Done = e.ID, true
+			if err := a.store.SaveHighlight(ctx, h); err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printHighlights(cmd, t)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Linked %q to %s\n", h.Title, a.entryName(e))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newHighlightUnlinkCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "unlink <highlight>",
+		Short: "Unlink a highlight from its journal entry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, h, err := a.resolveHighlight(ctx, trip, args[0])
+			if err != nil {
+				return err
+			}
+			h.EntryID = ""
+			if err := a.store.SaveHighlight(ctx, h); err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printHighlights(cmd, t)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Unlinked %q\n", h.Title)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newHighlightMoveCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:     "move <highlight> <position>",
+		Short:   "Move a goal or highlight to another place in the list",
+		Example: `  nomadic highlight move --trip japan fuji 1`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			var position int
+			if _, err := fmt.Sscan(args[1], &position); err != nil || position < 1 {
+				return fmt.Errorf("position %q: give a place in the list, from 1", args[1])
+			}
+			t, h, err := a.resolveHighlight(ctx, trip, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.MoveHighlight(ctx, h, position-1); err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printHighlights(cmd, t)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved %q to place %d\n", h.Title, h.Position+1)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newHighlightRemoveCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:     "remove <highlight>...",
+		Aliases: []string{"rm"},
+		Short:   "Remove goals or highlights from a trip",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			highlights, err := a.store.ListHighlightsByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			for _, ref := range args {
+				h, err := resolveHighlight(highlights, ref)
+				if err != nil {
+					return err
+				}
+				if err := a.store.DeleteHighlight(ctx, h.ID); err != nil {
+					return err
+				}
+				if !a.json() {
+					fmt.Fprintf(cmd.OutOrStdout(), "Removed %q\n", h.Title)
+				}
+			}
+			if a.json() {
+				return a.printHighlights(cmd, t)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+// resolveHighlight picks the goal or highlight ref of the trip tripRef.
+func (a *app) resolveHighlight(ctx context.Context, tripRef, ref string) (*models.Trip, *models.Highlight, error) {
+	t, err := resolveTrip(ctx, a.store, tripRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	highlights, err := a.store.ListHighlightsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := resolveHighlight(highlights, ref)
+	return t, h, err
+}
+
+// tripEntry picks the journal entry ref of the trip t.
+func (a *app) tripEntry(ctx context.Context, t *models.Trip, ref string) (*models.Entry, error) {
+	e, err := resolveEntry(ctx, a.store, t.ID, ref)
+	if err != nil {
+		return nil, err
+	}
+	if e.TripID != t.ID {
+		return nil, fmt.Errorf("the entry %s is not of %q", e.ID, t.Title)
+	}
+	return e, nil
+}
+
+// entryName names a journal entry by its title and date.
+func (a *app) entryName(e *models.Entry) string {
+	if e.Title == "" {
+		return "the entry of " + a.formatDate(e.Timestamp)
+	}
+	return fmt.Sprintf("%q (%s)", e.Title, a.formatDate(e.Timestamp))
+}
+
+// printHighlights prints a trip's goals and highlights as they now stand.
+func (a *app) printHighlights(cmd *cobra.Command, t *models.Trip) error {
+	ctx := cmd.Context()
+	highlights, err := a.store.ListHighlightsByTrip(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	entries := map[string]*models.Entry{}
+	for _, h := range highlights {
+		if h.EntryID == "" {
+			continue
+		}
+		e, err := a.store.GetEntry(ctx, h.EntryID)
+		if err != nil {
+			return err
+		}
+		entries[e.ID] = e
+	}
+	if a.json() {
+		return printJSON(cmd, apiHighlights(t, highlights, entries))
+	}
+	a.writeHighlights(cmd.OutOrStdout(), t, highlights, entries)
+	return nil
+}
+
+// writeHighlights writes a trip's goals and highlights with how many are
+// done and the journal entries they link to.
+func (a *app) writeHighlights(w io.Writer, t *models.Trip, highlights []*models.Highlight, entries map[string]*models.Entry) {
+	if len(highlights) == 0 {
+		fmt.Fprintf(w, "No goals for %q yet; add them with `nomadic highlight add`.\n", t.Title)
+		return
+	}
+	done, total := models.HighlightProgress(highlights)
+	fmt.Fprintf(w, "%s: %d of %d done\n\n", t.Title, done, total)
+	for _, h := range highlights {
+		box := "[ ]"
+		if h.Done {
+			box = "[x]"
+		}
+		line := "  " + box + " " + h.Title
+		if e := entries[h.EntryID]; e != nil {
+			line += " — " + a.entryName(e)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/hooks"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+// hookLog is the file in the data directory hooks that failed in the TUI,
+// which has no terminal to tell, are written to.
+const hookLog = "hooks.log"
+
+func newHookCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "hook",
+		Aliases: []string{"hooks"},
+		Short:   "Run your own commands when entries, trips and expenses are saved",
+		Long: `Hooks are shell commands set in the [hooks] table of the config file and
+run when their event happens, whether in a command or in the TUI. The
+events are:
+
+  entry_saved    a journal entry was written or edited
+  trip_created   a trip was made
+  expense_added  an expense was recorded
+
+A hook reads the event on its standard input as a JSON document, the
+api.HookEvent schema of package github.com/girdharshubham/nomadic/pkg/api,
+with $NOMADIC_EVENT set to its name. It runs in the data directory, in the
+background, and is stopped after 30 seconds. A hook that fails is
+reported on standard error, or in hooks.log in the data directory when it
+ran in the TUI.`,
+		Example: `  nomadic config set hooks.entry_saved 'jq -r .entry.text > ~/notes/nomadic/$(date +%F).md'
+  nomadic config set hooks.expense_added 'curl -s -H "Content-Type: application/json" -d @- https://example.com/hook'
+  nomadic config set hooks.trip_created ''   # removes the hook`,
+	}
+	cmd.AddCommand(newHookListCmd(a), newHookTestCmd(a))
+	return cmd
+}
+
+func newHookListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Show the command of each event",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if a.json() {
+				settings := make([]api.Setting, 0, len(config.HookEvents))
+				for _, event := range config.HookEvents {
+					settings = append(settings, api.Setting{Name: "hooks." + event, Value: a.cfg.Hooks[event]})
+				}
+				return printJSON(cmd, settings)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, event := range config.HookEvents {
+				command := a.cfg.Hooks[event]
+				if command == "" {
+					command = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\n", event, command)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newHookTestCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "test <event>",
+		Short: "Run the hook of an event on the latest entry, trip or expense",
+		Long: `Run the hook of an event as if it had just happened to the latest journal
+entry, trip or expense of the trip, and wait for it, printing what it
+printed.`,
+		Example:   `  nomadic hook test entry_saved --trip japan`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: config.HookEvents,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			event := args[0]
+			if !a.hooks.Has(event) {
+				return fmt.Errorf("no hook is set for %s; set one with `nomadic config set hooks.%s <command>`", event, event)
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			saved := storage.Saved{Trip: t, Created: true}
+			switch event {
+			case config.HookEntrySaved:
+				entries, err := a.store.ListEntriesByTrip(ctx, t.ID)
+				if err != nil {
+					return err
+				}
+				if len(entries) == 0 {
+					return fmt.Errorf("%q has no journal entries to test with", t.Title)
+				}
+				saved = storage.Saved{Entry: entries[len(entries)-1]}
+			case config.HookExpenseAdded:
+				expenses, err := a.store.ListExpensesByTrip(ctx, t.ID)
+				if err != nil {
+					return err
+				}
+				if len(expenses) == 0 {
+					return fmt.Errorf("%q has no expenses to test with", t.Title)
+				}
+				saved = storage.Saved{Expense: expenses[len(expenses)-1], Created: true}
+			}
+			_, doc, err := a.hookEvent(ctx, saved)
+			if err != nil {
+				return err
+			}
+			out, err := a.hooks.Run(ctx, event, doc)
+			cmd.OutOrStdout().Write(out)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+// watch has the hooks set in the config run on what is saved to store.
+func (a *app) watch(store *storage.Store) {
+	if len(a.cfg.Hooks) == 0 {
+		return
+	}
+	a.hooks = hooks.New(a.cfg.Hooks, a.dataDir, a.hookFailed)
+	store.Observe(func(ctx context.Context, saved storage.Saved) {
+		event, doc, err := a.hookEvent(ctx, saved)
+		if err != nil {
+			a.hookFailed(event, err)
+			return
+		}
+		a.hooks.Fire(event, doc)
+	})
+}
+
+// hookEvent names the event of what was saved and the document its hook
+// reads, or returns an empty name when it is no event or has no hook.
+func (a *app) hookEvent(ctx context.Context, saved storage.Saved) (string, api.HookEvent, error) {
+	doc := api.HookEvent{Time: time.Now(), Created: saved.Created}
+	tripID := ""
+	switch {
+	case saved.Entry != nil:
+		doc.Event, tripID = config.HookEntrySaved, saved.Entry.TripID
+		e := apiEntry(saved.Entry)
+		doc.Entry = &e
+	case saved.Trip != nil && saved.Created:
+		doc.Event = config.HookTripCreated
+		t := apiTrip(saved.Trip)
+		doc.Trip = &t
+	case saved.Expense != nil && saved.Created:
+		doc.Event, tripID = config.HookExpenseAdded, saved.Expense.TripID
+		x := apiExpense(saved.Expense)
+		doc.Expense = &x
+	}
+	if !a.hooks.Has(doc.Event) {
+		return "", doc, nil
+	}
+	if tripID != "" {
+		t, err := a.store.GetTrip(ctx, tripID)
+		if err != nil {
+			return doc.Event, doc, err
+		}
+		trip := apiTrip(t)
+		doc.Trip = &trip
+	}
+	return doc.Event, doc, nil
+}
+
+// hookFailed reports a hook that failed on standard error, or in the TUI
+// in hookLog.
+func (a *app) hookFailed(event string, err error) {
+	slog.Warn("hook failed", "event", event, "err", err)
+	if !a.tui {
+		fmt.Fprintf(os.Stderr, "nomadic: %s hook: %v\n", event, err)
+		return
+	}
+	f, ferr := os.OpenFile(filepath.Join(a.dataDir, hookLog), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if ferr != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "%s %s hook: %v\n", time.Now().Format(time.RFC3339), event, err)
+}
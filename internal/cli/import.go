@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+func newImportCmd(a *app) *cobra.Command {
+	var opts storage.ImportOptions
+	cmd := &cobra.Command{
+		Use:   "import <dump.json>",
+		Short: "Import a dump of nomadic export --all, to move to another device",
+		Long: `Import a dump written by nomadic export --all on this or another device,
+or "-" to read it from standard input.
+
+The dump is merged into the data directory: what it lacks is added, and
+what it has already is kept as it is, or with --overwrite replaced by the
+dump's version. Records are matched by ID, and by name for people,
+categories, templates, packing lists, statement rules and country notes.
+Nothing is deleted, so importing the same dump again adds nothing; into a
+new data directory, importing restores the dump.
+
+The database is backed up into the backups directory first; --dry-run
+counts what would be imported and leaves it as it is.`,
+		Example: `  nomadic export --all --output nomadic-dump.json
+  nomadic --data-dir ~/laptop import nomadic-dump.json --dry-run
+  nomadic --data-dir ~/laptop import nomadic-dump.json --overwrite`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var r io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				r = f
+			}
+			d, err := export.ReadDump(r)
+			if err != nil {
+				return err
+			}
+			report, backup, err := a.store.Import(cmd.Context(), d, opts)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiDumpImport(report, backup, opts))
+			}
+
+			out := cmd.OutOrStdout()
+			var kinds []string
+			for _, counts := range []map[string]int{report.Added, report.Replaced, report.Kept} {
+				for kind := range counts {
+					if !slices.Contains(kinds, kind) {
+						kinds = append(kinds, kind)
+					}
+				}
+			}
+			if len(kinds) == 0 {
+				fmt.Fprintln(out, "The dump is empty")
+				return nil
+			}
+			slices.Sort(kinds)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KIND\tADDED\tREPLACED\tKEPT")
+			for _, kind := range kinds {
+				fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", kind, report.Added[kind], report.Replaced[kind], report.Kept[kind])
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			switch {
+			case opts.DryRun:
+				fmt.Fprintln(out, "Dry run: nothing was imported")
+			case backup != "":
+				fmt.Fprintln(out, "The database as it was is backed up in", backup)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.BoolVar(&opts.Overwrite, "overwrite", false, "replace what the data directory has with the dump's version")
+	f.BoolVar(&opts.DryRun, "dry-run", false, "count what would be imported without importing it")
+	return cmd
+}
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+func newItineraryCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "itinerary",
+		Short: "Time the activities of a trip's itinerary against the plan",
+		Long: `Mark the activities planned on a trip's itinerary as started and finished,
+now or after the fact with --at, and see how long each took against the
+duration planned for it. Times are on the clock of the place, on the day
+the activity is planned.
+
+Activities given a kind, such as museum, hike or meal, in the TUI or a
+template, are summed up by kind across every trip with timing, to tell
+which kinds always run over.`,
+		Example: `  nomadic itinerary list --day 2025-04-03
+  nomadic itinerary start "Fushimi Inari"
+  nomadic itinerary finish "Fushimi Inari" --at 11:55
+  nomadic itinerary timing`,
+	}
+	cmd.AddCommand(newItineraryListCmd(a), newItineraryClockCmd(a, true), newItineraryClockCmd(a, false),
+		newItineraryTimingCmd(a))
+	return cmd
+}
+
+func newItineraryListCmd(a *app) *cobra.Command {
+	var trip, day string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a trip's activities, planned against actual",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			items, err := a.store.ListItineraryByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			if day != "" {
+				d, err := a.parseDay("--day", day)
+				if err != nil {
+					return err
+				}
+				var on []*models.ItineraryItem
+				for _, it := range items {
+					if it.Day.Format(models.DateLayout) == d.Format(models.DateLayout) {
+						on = append(on, it)
+					}
+				}
+				items = on
+			}
+			if a.json() {
+				return printJSON(cmd, apiItineraryItems(items))
+			}
+			if len(items) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Nothing planned on %q\n", t.Title)
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DAY\tTIME\tACTIVITY\tKIND\tPLANNED\tSTARTED\tFINISHED\tTOOK\tOVER")
+			for _, it := range items {
+				planned, took, over := "-", "-", "-"
+				if it.Duration > 0 {
+					planned = models.FormatDuration(it.Duration)
+				}
+				if m, ok := it.Took(); ok {
+					took = models.FormatDuration(m)
+				}
+				if m, ok := it.Overran(); ok {
+					over = signedDuration(m)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", it.Day.Format(models.DateLayout), orDash(it.Time),
+					it.Title, orDash(it.Kind), planned, orDash(it.Started), orDash(it.Finished), took, over)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	cmd.Flags().StringVar(&day, "day", "", "only the activities of this day, YYYY-MM-DD")
+	return cmd
+}
+
+// newItineraryClockCmd makes the start command, or the finish command
+// unless start is set.
+func newItineraryClockCmd(a *app, start bool) *cobra.Command {
+	var trip, at string
+	use, short, verb, done := "finish <item>", "Mark an activity finished, now or at a time", "finished", "Finished"
+	if start {
+		use, short, verb, done = "start <item>", "Mark an activity started, now or at a time", "started", "Started"
+	}
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long: `Mark an activity of the itinerary ` + verb + ` now, on the clock of its place,
+or at the time of day --at gives, to log it after the fact. Without --at
+only an activity planned today can be marked.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			items, err := a.store.ListItineraryByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			it, err := resolveItineraryItem(t, items, args[0])
+			if err != nil {
+				return err
+			}
+			clock := at
+			if clock == "" {
+				legs, err := a.store.ListLegsByTrip(ctx, t.ID)
+				if err != nil {
+					return err
+				}
+				now := models.InZone(time.Now(), places.DayZone(t, legs, it.Place, it.Day))
+				if now.Format(models.DateLayout) != it.Day.Format(models.DateLayout) {
+					return fmt.Errorf("%q is planned on %s, not today; give the time it %s at with --at",
+						it.Title, it.Day.Format(models.DateLayout), verb)
+				}
+				clock = now.Format(models.TimeLayout)
+			} else {
+				parsed, err := time.Parse(models.TimeLayout, at)
+				if err != nil {
+					return fmt.Errorf("--at %q is not a time of day such as 09:30", at)
+				}
+				clock = parsed.Format(models.TimeLayout)
+			}
+			if start {
+				it.Started = clock
+			} else {
+				if it.Started == "" {
+					return fmt.Errorf("%q has not started; start it first, with --at to log when", it.Title)
+				}
+				it.Finished = clock
+			}
+			if err := a.store.SaveItineraryItem(ctx, it); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiItineraryItem(it))
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%s %q at %s\n", done, it.Title, clock)
+			if took, ok := it.Took(); ok {
+				fmt.Fprintf(out, "Took %s", models.FormatDuration(took))
+				if over, ok := it.Overran(); ok {
+					fmt.Fprintf(out, " of %s planned (%s)", models.FormatDuration(it.Duration), signedDuration(over))
+				}
+				fmt.Fprintln(out)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	cmd.Flags().StringVar(&at, "at", "", "time of day it "+verb+" at, HH:MM (default: now)")
+	return cmd
+}
+
+func newItineraryTimingCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "timing",
+		Short: "Sum up by kind how long activities took against the plan",
+		Long: `Sum up the activities that were planned to take a while and were timed, by
+their kind, across every trip or the one named with --trip: how long each
+was planned to take and took on average, how many ran over and how late
+they started. The kinds running over most come first; activities without
+a kind count as "other".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			trips, err := a.store.ListTrips(ctx)
+			if err != nil {
+				return err
+			}
+			if trip != "" {
+				t, err := resolveTrip(ctx, a.store, trip)
+				if err != nil {
+					return err
+				}
+				trips = []*models.Trip{t}
+			}
+			var items []*models.ItineraryItem
+			for _, t := range trips {
+				its, err := a.store.ListItineraryByTrip(ctx, t.ID)
+				if err != nil {
+					return err
+				}
+				items = append(items, its...)
+			}
+			timings := models.TimingByKind(items)
+			if a.json() {
+				return printJSON(cmd, apiKindTimings(timings))
+			}
+			if len(timings) == 0 {
+				return errors.New("no activity has been timed against a planned duration yet")
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KIND\tTIMED\tAVG PLANNED\tAVG TOOK\tAVG OVER\tRAN OVER\tAVG LATE START")
+			for _, k := range timings {
+				late := "-"
+				if k.Scheduled > 0 {
+					late = signedDuration(k.Late / k.Scheduled)
+				}
+				fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%d of %d\t%s\n", k.Kind, k.Items,
+					models.FormatDuration(k.Planned/k.Items), models.FormatDuration(k.Took/k.Items),
+					signedDuration((k.Took-k.Planned)/k.Items), k.Over, k.Items, late)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "only this trip, by ID, title or destination (default: every trip)")
+	return cmd
+}
+
+// signedDuration writes minutes over, or under when negative, as "+45m" or
+// "-10m".
+func signedDuration(minutes int) string {
+	if minutes > 0 {
+		return "+" + models.FormatDuration(minutes)
+	}
+	return models.FormatDuration(minutes)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,532 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/pkg/api"
+	"github.com/girdharshubham/nomadic/pkg/weather"
+)
+
+func newJournalCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "Write and list journal entries",
+	}
+	cmd.AddCommand(newJournalNewCmd(a), newJournalListCmd(a), newJournalOnThisDayCmd(a), newJournalAttachCmd(a), newJournalAttachmentsCmd(a),
+		newJournalWeatherCmd(a), newJournalDictateCmd(a), newJournalPrivacyCmd(a, ""),
+		newJournalPrivacyCmd(a, models.PrivacyPublic), newJournalPrivacyCmd(a, models.PrivacyPrivate),
+		newJournalHistoryCmd(a), newJournalRevertCmd(a), newJournalNotesCmd(a))
+	return cmd
+}
+
+func newJournalNewCmd(a *app) *cobra.Command {
+	var (
+		trip     string
+		title    string
+		date     string
+		tags     []string
+		text     string
+		location string
+		leg      string
+		mood     int
+		with     []string
+		public   bool
+		private  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "new",
+		Short: "Write a journal entry",
+		Long: `Write a journal entry in Markdown.
+
+The body is taken from --text, or read from standard input when it is not a
+terminal. Otherwise the configured editor, or $EDITOR, is opened on an
+empty file.
+
+With the weather setting on, the weather of the entry's day at its location,
+or else at the trip's first known destination, is recorded with it.
+
+The entry is attributed to the leg of the trip being visited on its day,
+or to the leg named with --leg, and takes the leg's location unless
+--location is given. --with names the trip's companions the entry was
+written with. --public publishes the entry with ` + "`nomadic publish`" + `, and
+--private keeps it out of the website and redacted exports; see nomadic
+journal privacy.`,
+		Example: `  nomadic journal new --trip tokyo --title "Tsukiji" --text "Best tuna of my life."
+  nomadic journal new --trip lisbon --with Ana --text "Fado night in Alfama."
+  echo "Rainy day, museums." | nomadic journal new --tags rain,museums`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if err := models.CheckRating(mood); err != nil {
+				return fmt.Errorf("--mood: %w", err)
+			}
+			if public && private {
+				return errors.New("an entry is either --public or --private")
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			people, err := models.ParseWith(splitList(with), t.Companions)
+			if err != nil {
+				return fmt.Errorf("--with: %w", err)
+			}
+			at, err := a.tripMoment(ctx, t, date, leg, strings.TrimSpace(location))
+			if err != nil {
+				return err
+			}
+			body := text
+			if body == "" {
+				if body, err = readBody(cmd.InOrStdin(), a.editor()); err != nil {
+					return err
+				}
+			}
+			if strings.TrimSpace(body) == "" {
+				return errors.New("empty entry, nothing saved")
+			}
+			_, err = a.writeEntry(cmd, t, at, entryFields{title: title, body: body, tags: tags, location: location, mood: mood,
+				people: people, public: public, private: private})
+			return err
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.StringVar(&title, "title", "", "entry title (default: first line of the body)")
+	f.StringVar(&date, "date", "", "date, YYYY-MM-DD (default today)")
+	f.StringSliceVar(&tags, "tags", nil, "tags; repeat or separate with commas")
+	f.StringVar(&text, "text", "", "entry body in Markdown")
+	f.StringVar(&location, "location", "", "where the entry was written, such as a city")
+	f.StringVar(&leg, "leg", "", "leg of the trip, by ID or location (default: the leg of the entry's day)")
+	f.IntVar(&mood, "mood", 0, "how the day felt, from 1 (awful) to 5 (great)")
+	f.StringSliceVar(&with, "with", nil, "companions of the trip the entry was written with; repeat or separate with commas")
+	f.BoolVar(&public, "public", false, "publish the entry on the website of nomadic publish")
+	f.BoolVar(&private, "private", false, "keep the entry out of the website and redacted exports")
+	return cmd
+}
+
+// entryFields are what the journal commands take from their flags for a
+// new entry.
+type entryFields struct {
+	title, body, location string
+	tags, people          []string
+	mood                  int
+	public, private       bool
+}
+
+// writeEntry saves a new entry of trip t at the moment at, recording its
+// weather when that is on, and reports it.
+func (a *app) writeEntry(cmd *cobra.Command, t *models.Trip, at moment, f entryFields) (*models.Entry, error) {
+	ctx := cmd.Context()
+	var err error
+	if f.title == "" {
+		f.title = defaultTitle(f.body, a.formatDate(at.ts))
+	}
+	e := models.NewEntry(t.ID, f.body, at.ts)
+	e.TimeZone = at.zone
+	e.Title = f.title
+	e.Tags = splitList(f.tags)
+	e.Mood = f.mood
+	e.People = f.people
+	e.Public, e.Private = f.public, f.private
+	e.Location = strings.TrimSpace(f.location)
+	if l := at.leg; l != nil {
+		e.LegID = l.ID
+		if e.Location == "" {
+			e.Location = l.Location
+		}
+	}
+	if a.weather() != nil {
+		// The entry is worth keeping without its weather.
+		if e.Weather, err = a.entryWeather(ctx, e, t); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Weather not recorded: %v\n", err)
+		}
+	}
+	if err := a.store.SaveEntry(ctx, e); err != nil {
+		return nil, err
+	}
+	if a.json() {
+		return e, printJSON(cmd, apiEntry(e))
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Saved %q to %q\n", e.Title, t.Title)
+	if p, ok := places.Lookup(e.Location); ok {
+		fmt.Fprintf(cmd.OutOrStdout(), "Location: %s (%s)\n", p, p.Timezone)
+	}
+	if e.Weather != nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "Weather: %s\n", e.Weather.Summary())
+	}
+	if e.Mood > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Mood: %s %d/%d\n", models.MoodFace(e.Mood), e.Mood, models.MaxRating)
+	}
+	if len(e.People) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "With: %s\n", strings.Join(e.People, ", "))
+	}
+	return e, nil
+}
+
+func newJournalListCmd(a *app) *cobra.Command {
+	var (
+		trip string
+		tags []string
+		leg  string
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a trip's journal entries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			entries, err := a.store.ListEntriesByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			entries = slices.DeleteFunc(entries, func(e *models.Entry) bool { return !models.HasTags(e.Tags, tags) })
+			if leg != "" {
+				l, err := resolveLeg(ctx, a.store, t, leg)
+				if err != nil {
+					return err
+				}
+				entries = slices.DeleteFunc(entries, func(e *models.Entry) bool { return e.LegID != l.ID })
+			}
+			if a.json() {
+				return printJSON(cmd, apiEntries(entries))
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DATE\tTITLE\tMOOD\tTAGS")
+			for _, e := range entries {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.formatDate(e.Timestamp), e.Title, models.MoodFace(e.Mood), strings.Join(e.Tags, ", "))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "only entries with this tag; repeat to require several")
+	cmd.Flags().StringVar(&leg, "leg", "", "only entries of this leg, by ID or location")
+	return cmd
+}
+
+func newJournalOnThisDayCmd(a *app) *cobra.Command {
+	var date string
+	cmd := &cobra.Command{
+		Use:   "on-this-day",
+		Short: "Recall the entries written on this day in earlier years",
+		Long: `Recall the journal entries written on today's date in earlier years, the
+most recent first, as the TUI home screen does. Entries of 29 February come
+round on 1 March in other years.`,
+		Example: `  nomadic journal on-this-day
+  nomadic journal on-this-day --date 2026-12-24`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			day := time.Now()
+			if date != "" {
+				var err error
+				if day, err = a.parseDay("--date", date); err != nil {
+					return err
+				}
+			}
+			entries, err := a.store.ListEntriesOnThisDay(ctx, day)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiEntries(entries))
+			}
+			if len(entries) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Nothing was written on %s in earlier years\n", day.Format("2 January"))
+				return nil
+			}
+			trips, err := a.store.ListTrips(ctx)
+			if err != nil {
+				return err
+			}
+			titles := make(map[string]string, len(trips))
+			for _, t := range trips {
+				titles[t.ID] = t.Title
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "YEARS AGO\tDATE\tTRIP\tTITLE")
+			for _, e := range entries {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", e.YearsAgo(day), a.formatDate(e.Timestamp), titles[e.TripID], e.Title)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&date, "date", "", "the day to recall, YYYY-MM-DD (default today)")
+	return cmd
+}
+
+func newJournalAttachCmd(a *app) *cobra.Command {
+	var trip string
+	var link bool
+	cmd := &cobra.Command{
+		Use:   "attach <entry> <file>...",
+		Short: "Attach photos or other files to a journal entry",
+		Long: `Attach files to a journal entry, naming the entry by ID or title.
+
+Files are copied into the data directory so the journal stays complete if
+the originals move. With --link nomadic keeps a symbolic link to the
+original instead, which saves space for large files.`,
+		Example: `  nomadic journal attach Tsukiji ~/Pictures/tuna.jpg
+  nomadic journal attach --trip tokyo --link "Day one" IMG_0001.HEIC IMG_0002.HEIC`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			e, err := resolveEntry(ctx, a.store, trip, args[0])
+			if err != nil {
+				return err
+			}
+			attached := []api.Attachment{}
+			for _, path := range args[1:] {
+				att, err := a.store.AttachFile(ctx, e.ID, path, link)
+				if err != nil {
+					return err
+				}
+				if a.json() {
+					attached = append(attached, apiAttachment(a.store, att))
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Attached %s to %q\n", att.Name, e.Title)
+			}
+			if a.json() {
+				return printJSON(cmd, attached)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	cmd.Flags().BoolVar(&link, "link", false, "link to the files instead of copying them")
+	return cmd
+}
+
+func newJournalAttachmentsCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "attachments <entry>",
+		Short: "List the files attached to a journal entry",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			e, err := resolveEntry(ctx, a.store, trip, args[0])
+			if err != nil {
+				return err
+			}
+			list, err := a.store.ListAttachmentsByEntry(ctx, e.ID)
+			if err != nil {
+				return err
+			}
+			out := make([]api.Attachment, len(list))
+			for i, att := range list {
+				out[i] = apiAttachment(a.store, att)
+			}
+			if a.json() {
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tSIZE\tPATH")
+			for _, att := range out {
+				fmt.Fprintf(w, "%s\t%d\t%s\n", att.Name, att.Size, att.Path)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+func newJournalWeatherCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "weather <entry>",
+		Short: "Record the weather of a journal entry's day",
+		Long: `Look up and record the weather of a journal entry's day at its location, or
+else at the trip's first known destination, replacing any recorded before.
+Past days come from the weather archive, so older entries can be filled in.`,
+		Example: `  nomadic journal weather Tsukiji`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if a.weather() == nil {
+				return errors.New("weather is off; turn it on with `nomadic config set weather open-meteo`")
+			}
+			e, err := resolveEntry(ctx, a.store, trip, args[0])
+			if err != nil {
+				return err
+			}
+			t, err := a.store.GetTrip(ctx, e.TripID)
+			if err != nil {
+				return err
+			}
+			if e.Weather, err = a.entryWeather(ctx, e, t); err != nil {
+				return err
+			}
+			if err := a.store.SaveEntry(ctx, e); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiEntry(e))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", e.Title, e.Weather.Summary())
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+// newJournalPrivacyCmd sets the privacy level of an entry, or shows it.
+// With level set it is the shorthand for that level, nomadic journal
+// public or private.
+func newJournalPrivacyCmd(a *app, level models.Privacy) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "privacy <entry> [public|shared|private]",
+		Short: "Show or set how far a journal entry is shared",
+		Long: `Show or set the privacy level of a journal entry:
+
+  public   ` + "`nomadic publish`" + ` puts it on the website, on a page of its trip
+           listing only the entries published
+  shared   the default: exports and reports have it, and the website has
+           it when its trip is marked public with ` + "`nomadic trip public`" + `
+  private  a personal entry, which ` + "`nomadic publish`" + ` and ` + "`nomadic share`" + `
+           leave out, as do exports with --redact strip; --redact mask
+           keeps only its day
+
+Passages of an entry, or of a trip's notes, are private between a line
+"::: private" and a line ":::", whatever the level of the entry.`,
+		Example: `  nomadic journal privacy Tsukiji
+  nomadic journal privacy Tsukiji private`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			e, err := resolveEntry(ctx, a.store, trip, args[0])
+			if err != nil {
+				return err
+			}
+			p := level
+			if len(args) == 2 {
+				if p, err = models.ParsePrivacy(args[1]); err != nil {
+					return err
+				}
+			}
+			if p != "" && p != e.Privacy() {
+				e.SetPrivacy(p)
+				if err := a.store.SaveEntry(ctx, e); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, apiEntry(e))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Entry %q is %s\n", e.Title, e.Privacy())
+			return nil
+		},
+	}
+	switch level {
+	case models.PrivacyPublic:
+		cmd.Use, cmd.Short, cmd.Example = "public <entry>", "Publish a journal entry on the website of nomadic publish", `  nomadic journal public Tsukiji`
+		cmd.Long = `Mark a journal entry public: ` + "`nomadic publish`" + ` puts it on the website, on a
+page of its trip listing only the entries published. Every entry of a trip
+marked public with ` + "`nomadic trip public`" + ` is published anyway, but those
+private. The same as ` + "`nomadic journal privacy <entry> public`" + `.`
+	case models.PrivacyPrivate:
+		cmd.Use, cmd.Short, cmd.Example = "private <entry>", "Keep a journal entry out of the website and redacted exports", `  nomadic journal private Tsukiji`
+		cmd.Long = `Mark a journal entry private, a personal one: it is never published, and
+exports with --redact leave it out or mask it. The same as
+` + "`nomadic journal privacy <entry> private`" + `; ` + "`shared`" + ` undoes it.`
+	}
+	if level != "" {
+		cmd.Args = cobra.ExactArgs(1)
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+// entryWeather fetches the weather of an entry's day at its location, or
+// else at the first of the trip's destinations in the places dataset.
+func (a *app) entryWeather(ctx context.Context, e *models.Entry, t *models.Trip) (*weather.Day, error) {
+	p, ok := places.First(append([]string{e.Location}, t.Locations...)...)
+	if !ok {
+		return nil, errors.New("neither the entry nor its trip has a place known to `nomadic places`")
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	return a.weather().Daily(ctx, p.Lat, p.Lon, e.Timestamp.In(p.Location()))
+}
+
+// readBody reads the entry body from in when it is piped, or from editor
+// when in is an interactive terminal.
+func readBody(in io.Reader, editor string) (string, error) {
+	if f, ok := in.(*os.File); ok && isTerminal(f) {
+		return editBody(editor)
+	}
+	b, err := io.ReadAll(in)
+	return string(b), err
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// editor returns the editor command from the config, $EDITOR, or vi.
+func (a *app) editor() string {
+	if a.cfg.Editor != "" {
+		return a.cfg.Editor
+	}
+	if e := os.Getenv("EDITOR"); e != "" {
+		return e
+	}
+	return "vi"
+}
+
+// editBody opens editor on a temporary Markdown file and returns what was
+// written.
+func editBody(editor string) (string, error) {
+	tmp, err := os.CreateTemp("", "nomadic-*.md")
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	parts := strings.Fields(editor)
+	c := exec.Command(parts[0], append(parts[1:], tmp.Name())...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("editor: %w", err)
+	}
+	b, err := os.ReadFile(tmp.Name())
+	return string(b), err
+}
+
+// defaultTitle uses the first non-empty line of body, without any Markdown
+// heading marker, falling back to fallback.
+func defaultTitle(body, fallback string) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(line, "# "))
+		if line != "" {
+			if r := []rune(line); len(r) > 60 {
+				line = string(r[:60]) + "…"
+			}
+			return line
+		}
+	}
+	return fallback
+}
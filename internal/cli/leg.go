@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newTripLegCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "leg",
+		Short: "Plan the legs of a multi-destination trip",
+		Long: `A leg is one stop of a trip: a destination with the days spent there and
+how it is reached. Journal entries and expenses are attributed to the leg
+being visited on their day, or to the one named with --leg.`,
+	}
+	cmd.AddCommand(newTripLegAddCmd(a), newTripLegListCmd(a), newTripLegDeleteCmd(a))
+	return cmd
+}
+
+func newTripLegAddCmd(a *app) *cobra.Command {
+	var trip, location, arrive, depart, transport string
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a leg to a trip",
+		Long: `Add a leg to a trip. Its location joins the trip's destinations, and
+journal entries and expenses of its days that belong to no leg yet are
+attributed to it.`,
+		Example: `  nomadic trip leg add --trip japan --location Tokyo --arrive 2025-04-01 --depart 2025-04-05 --transport flight
+  nomadic trip leg add --trip japan --location Kyoto --arrive 2025-04-05 --transport train`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if location = strings.TrimSpace(location); location == "" {
+				return errors.New("--location is required")
+			}
+			if arrive == "" {
+				return errors.New("--arrive is required")
+			}
+			arrival, err := a.parseDay("--arrive", arrive)
+			if err != nil {
+				return err
+			}
+			mode, err := models.ParseTransport(transport)
+			if err != nil {
+				return fmt.Errorf("--transport: %w", err)
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			l := models.NewLeg(t.ID, location, arrival)
+			l.Transport = mode
+			if depart != "" {
+				departure, err := a.parseDay("--depart", depart)
+				if err != nil {
+					return err
+				}
+				if departure.Before(arrival) {
+					return errors.New("--depart must not be before --arrive")
+				}
+				l.Departure = &departure
+			}
+			if err := a.store.SaveLeg(ctx, l); err != nil {
+				return err
+			}
+			attributed, err := a.store.AttributeToLegs(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			if t.AddLocation(location) {
+				if err := a.store.SaveTrip(ctx, t); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, apiLeg(l))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %s to %q (%s)\n", l.Location, t.Title, l.ID)
+			if attributed > 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Journal entries and expenses attributed to it: %d\n", attributed)
+			}
+			return a.printCountryNotes(ctx, cmd.OutOrStdout(), []string{l.Location})
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.StringVar(&location, "location", "", "where the leg goes, such as a city")
+	f.StringVar(&arrive, "arrive", "", "arrival date, YYYY-MM-DD")
+	f.StringVar(&depart, "depart", "", "departure date, YYYY-MM-DD")
+	f.StringVar(&transport, "transport", "", "how the leg is reached: "+strings.Join(models.Transports, ", "))
+	return cmd
+}
+
+func newTripLegListCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a trip's legs in order",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			legs, err := a.store.ListLegsByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiLegs(legs))
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tLOCATION\tARRIVE\tDEPART\tTRANSPORT")
+			for _, l := range legs {
+				departure := "-"
+				if l.Departure != nil {
+					departure = a.formatDate(*l.Departure)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", l.ID, l.Location, a.formatDate(l.Arrival), departure, l.Transport)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+func newTripLegDeleteCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "delete <leg>",
+		Short: "Delete a leg",
+		Long: `Delete a leg, named by ID or location. Journal entries and expenses
+attributed to it are kept without a leg.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			l, err := resolveLeg(ctx, a.store, t, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteLeg(ctx, l.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "leg", ID: l.ID, Name: l.Location})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted leg %s of %q\n", l.Location, t.Title)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
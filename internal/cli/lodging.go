@@ -0,0 +1,353 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newLodgingCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "lodging",
+		Aliases: []string{"lodgings", "stay"},
+		Short:   "Keep the hotels and rentals booked for a trip",
+		Long: `Keep the hotels, hostels and rentals booked for each leg of a trip: where
+they are, the confirmation code, the cost and when to check in and out, in
+the time zone of the place.
+
+The cost is recorded as a lodging expense of the trip on the day of
+check-in, which follows the lodging when it is edited and goes with it when
+it is removed. On the days of a check-in or check-out the list, the trip in
+the TUI and its itinerary tell what is due, as in "Check out of Hotel
+Gracery by 11:00 today".`,
+	}
+	cmd.AddCommand(newLodgingListCmd(a), newLodgingAddCmd(a), newLodgingEditCmd(a), newLodgingRemoveCmd(a))
+	return cmd
+}
+
+func newLodgingListCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a trip's lodgings and what is due today",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			lodgings, err := a.store.ListLodgingsByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiLodgings(lodgings))
+			}
+			return a.writeLodgings(ctx, cmd.OutOrStdout(), t, lodgings)
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+// lodgingFlags are the flags setting the details of a lodging, shared by
+// add and edit.
+type lodgingFlags struct {
+	leg, address, confirmation, currency, notes  string
+	checkIn, checkOut, checkInTime, checkOutTime string
+	cost                                         float64
+}
+
+func (lf *lodgingFlags) register(cmd *cobra.Command, adding bool) {
+	f := cmd.Flags()
+	checkIn, checkOut := "check-in date, YYYY-MM-DD", "check-out date, YYYY-MM-DD"
+	if adding {
+		checkIn += " (default: the leg's arrival)"
+		checkOut += " (default: the leg's departure)"
+	}
+	f.StringVar(&lf.leg, "leg", "", "leg of the trip stayed on, by ID or location (default: the leg of check-in)")
+	f.StringVar(&lf.address, "address", "", "street address")
+	f.StringVar(&lf.confirmation, "confirmation", "", "confirmation code of the booking")
+	f.Float64Var(&lf.cost, "cost", 0, "cost of the whole stay, recorded as an expense; 0 records none")
+	f.StringVar(&lf.currency, "currency", "", "three-letter currency code of the cost, e.g. EUR (default from config)")
+	f.StringVar(&lf.checkIn, "check-in", "", checkIn)
+	f.StringVar(&lf.checkOut, "check-out", "", checkOut)
+	f.StringVar(&lf.checkInTime, "check-in-time", models.DefaultCheckInTime, "time of day the room is ready, HH:MM")
+	f.StringVar(&lf.checkOutTime, "check-out-time", models.DefaultCheckOutTime, "time of day to leave by, HH:MM")
+	f.StringVar(&lf.notes, "notes", "", "notes, such as how to get the keys")
+}
+
+// apply sets on l, a lodging of trip t, what the flags changed. Check-in
+// and check-out keep the day or time of day not given, and both are read
+// in the time zone of the leg or, without one, of the trip that day.
+func (lf *lodgingFlags) apply(ctx context.Context, a *app, cmd *cobra.Command, t *models.Trip, l *models.Lodging) error {
+	changed := cmd.Flags().Changed
+	legs, err := a.store.ListLegsByTrip(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	var leg *models.Leg
+	if lf.leg != "" {
+		if leg, err = resolveLeg(ctx, a.store, t, lf.leg); err != nil {
+			return err
+		}
+	}
+
+	inDay, outDay := l.CheckIn, l.CheckOut
+	inClock, outClock := l.CheckIn.Format(models.TimeLayout), l.CheckOut.Format(models.TimeLayout)
+	if l.CheckIn.IsZero() {
+		inClock, outClock = lf.checkInTime, lf.checkOutTime
+		if leg != nil {
+			inDay = leg.Arrival
+			if leg.Departure != nil {
+				outDay = *leg.Departure
+			}
+		}
+	}
+	if changed("check-in") {
+		if inDay, err = a.parseDay("--check-in", lf.checkIn); err != nil {
+			return err
+		}
+	}
+	if changed("check-out") {
+		if outDay, err = a.parseDay("--check-out", lf.checkOut); err != nil {
+			return err
+		}
+	}
+	if inDay.IsZero() {
+		return errors.New("--check-in is required without a --leg to take it from")
+	}
+	if outDay.IsZero() {
+		return errors.New("--check-out is required without a --leg departing to take it from")
+	}
+	if changed("check-in-time") {
+		inClock = lf.checkInTime
+	}
+	if changed("check-out-time") {
+		outClock = lf.checkOutTime
+	}
+	if leg == nil && l.LegID == "" {
+		leg = models.LegOn(legs, inDay)
+	}
+	if leg != nil {
+		l.LegID = leg.ID
+	}
+
+	at := ""
+	for _, g := range legs {
+		if g.ID == l.LegID {
+			at = g.Location
+		}
+	}
+	l.TimeZone = places.DayZone(t, legs, at, inDay)
+	if l.CheckIn, err = models.LodgingTime(inDay, inClock, l.TimeZone); err != nil {
+		return fmt.Errorf("--check-in-time: %w", err)
+	}
+	if l.CheckOut, err = models.LodgingTime(outDay, outClock, l.TimeZone); err != nil {
+		return fmt.Errorf("--check-out-time: %w", err)
+	}
+
+	if changed("address") {
+		l.Address = strings.TrimSpace(lf.address)
+	}
+	if changed("confirmation") {
+		l.Confirmation = strings.TrimSpace(lf.confirmation)
+	}
+	if changed("notes") {
+		l.Notes = lf.notes
+	}
+	if changed("cost") {
+		l.Cost = lf.cost
+	}
+	if changed("currency") || (l.Cost > 0 && l.Currency == "") {
+		currency := lf.currency
+		if currency == "" {
+			currency = a.cfg.DefaultCurrency
+		}
+		if l.Currency, err = models.ParseCurrency(currency); err != nil {
+			return fmt.Errorf("--currency: %w", err)
+		}
+	}
+	return l.Validate()
+}
+
+func newLodgingAddCmd(a *app) *cobra.Command {
+	var (
+		trip string
+		lf   lodgingFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a hotel or rental to a trip",
+		Long: `Add a hotel or rental to a trip. Check-in and check-out default to the
+arrival and departure of the leg, at 15:00 and 11:00; a cost is recorded as
+a lodging expense on the day of check-in.`,
+		Example: `  nomadic lodging add --trip japan --leg tokyo --cost 84000 --currency JPY "Hotel Gracery"
+  nomadic lodging add --trip lisbon --check-in 2025-06-02 --check-out 2025-06-06 \
+      --check-out-time 10:00 --confirmation HX42QK --address "Rua da Rosa 12" "Alfama flat"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			l := models.NewLodging(t.ID, args[0], time.Time{}, time.Time{})
+			if err := lf.apply(ctx, a, cmd, t, l); err != nil {
+				return err
+			}
+			if err := a.store.SaveLodging(ctx, l); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiLodging(l))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %s to %q (%s): %s\n", l.Name, t.Title, l.ID, a.lodgingStay(l))
+			if l.ExpenseID != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Recorded %.2f %s for lodging\n", l.Cost, l.Currency)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	lf.register(cmd, true)
+	return cmd
+}
+
+func newLodgingEditCmd(a *app) *cobra.Command {
+	var (
+		trip, name string
+		lf         lodgingFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "edit <lodging>",
+		Short: "Change the details of a lodging",
+		Long: `Change the details of a lodging; what is not given stays as it was. Its
+expense follows a new cost, currency or check-in, and a cost of 0 removes
+it.`,
+		Example: `  nomadic lodging edit --trip japan gracery --check-out 2025-04-06
+  nomadic lodging edit --trip japan gracery --cost 0`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, l, err := a.resolveLodging(ctx, trip, args[0])
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("name") {
+				l.Name = strings.TrimSpace(name)
+			}
+			if err := lf.apply(ctx, a, cmd, t, l); err != nil {
+				return err
+			}
+			if err := a.store.SaveLodging(ctx, l); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiLodging(l))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved %s: %s\n", l.Name, a.lodgingStay(l))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	cmd.Flags().StringVar(&name, "name", "", "new name")
+	lf.register(cmd, false)
+	return cmd
+}
+
+func newLodgingRemoveCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:     "remove <lodging>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a lodging with the expense of its cost",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			_, l, err := a.resolveLodging(ctx, trip, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteLodging(ctx, l.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "lodging", ID: l.ID, Name: l.Name})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", l.Name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+// resolveLodging picks the lodging ref of the trip tripRef.
+func (a *app) resolveLodging(ctx context.Context, tripRef, ref string) (*models.Trip, *models.Lodging, error) {
+	t, err := resolveTrip(ctx, a.store, tripRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	lodgings, err := a.store.ListLodgingsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, err := resolveLodging(lodgings, ref)
+	return t, l, err
+}
+
+// lodgingStay tells when a lodging is stayed at, as in "2 nights from
+// 01 Apr 15:00 to 03 Apr 11:00".
+func (a *app) lodgingStay(l *models.Lodging) string {
+	return fmt.Sprintf("%s from %s %s to %s %s", plural(l.Nights(), "night", "nights"),
+		a.formatDate(l.CheckIn), l.CheckIn.Format(models.TimeLayout),
+		a.formatDate(l.CheckOut), l.CheckOut.Format(models.TimeLayout))
+}
+
+// writeLodgings writes a trip's lodgings, then the check-ins and
+// check-outs due today where the trip is.
+func (a *app) writeLodgings(ctx context.Context, w io.Writer, t *models.Trip, lodgings []*models.Lodging) error {
+	if len(lodgings) == 0 {
+		fmt.Fprintf(w, "No lodging for %q yet; add it with `nomadic lodging add`.\n", t.Title)
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tCHECK-IN\tCHECK-OUT\tNIGHTS\tCOST\tCONFIRMATION")
+	for _, l := range lodgings {
+		cost := "-"
+		if l.Cost > 0 {
+			cost = fmt.Sprintf("%.2f %s", l.Cost, l.Currency)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s %s\t%s %s\t%d\t%s\t%s\n", l.ID, l.Name,
+			a.formatDate(l.CheckIn), l.CheckIn.Format(models.TimeLayout),
+			a.formatDate(l.CheckOut), l.CheckOut.Format(models.TimeLayout), l.Nights(), cost, l.Confirmation)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	legs, err := a.store.ListLegsByTrip(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	today := models.InZone(now, places.TripZone(t, legs, "", now))
+	if notices := models.LodgingNotices(lodgings, today); len(notices) > 0 {
+		fmt.Fprintln(w)
+		for _, n := range notices {
+			fmt.Fprintf(w, "%s today\n", n)
+		}
+	}
+	return nil
+}
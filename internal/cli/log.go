@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/girdharshubham/nomadic/internal/logging"
+)
+
+// startLog logs into the data directory from then on, or only into memory
+// while there is none yet, through the default slog logger. A log that
+// cannot be written is reported and left to memory; nomadic runs on.
+func (a *app) startLog() {
+	if a.log != nil {
+		return
+	}
+	dir := a.dataDir
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = ""
+	}
+	l, err := logging.New(dir, a.debug)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "nomadic:", err)
+		l, _ = logging.New("", a.debug)
+	}
+	a.log = l
+	slog.SetDefault(l.Logger)
+	slog.Debug("nomadic started", "pid", os.Getpid(), "data_dir", a.dataDir, "tui", a.tui)
+}
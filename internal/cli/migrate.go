@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newMigrateCmd(a *app) *cobra.Command {
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade the database to this version's schema, or check what that would do",
+		Long: `The database records the version of its schema. Every nomadic command
+brings an older database up to date on its own, one migration at a time,
+after backing it up into the backups directory of the data directory; a
+database from a newer version of nomadic is refused.
+
+Run migrate to upgrade explicitly, with --dry-run to first try the pending
+migrations on a copy of the database in memory and check its integrity,
+leaving the database as it is. "migrate status" lists the pending
+migrations and "migrate rollback" restores the database as it was before
+the last one, to go back to an older version of nomadic.`,
+		Example: `  nomadic migrate status
+  nomadic migrate --dry-run
+  nomadic migrate
+  nomadic migrate rollback`,
+		Args: cobra.NoArgs,
+		// These commands work on the database file, not an open store.
+		PersistentPreRunE: dataDirOnly(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			passphrase, err := a.schemaPassphrase(cmd)
+			if err != nil {
+				return err
+			}
+			version, pending, err := storage.SchemaStatus(ctx, a.dataDir, passphrase)
+			if err != nil {
+				return err
+			}
+			var (
+				applied []storage.Migration
+				backup  string
+			)
+			if dryRun {
+				applied, err = storage.DryRunMigrations(ctx, a.dataDir, passphrase)
+			} else {
+				applied, backup, err = storage.Migrate(ctx, a.dataDir, passphrase)
+			}
+			if a.json() && err == nil {
+				out := apiSchema(version, pending)
+				out.Applied, out.DryRun, out.Backup = apiMigrations(applied), dryRun, backup
+				return printJSON(cmd, out)
+			}
+			w := cmd.OutOrStdout()
+			verb := "Applied"
+			if dryRun {
+				verb = "Applied to a copy"
+			}
+			if len(applied) > 0 {
+				fmt.Fprintf(w, "%s (schema %d to %d):\n", verb, version, applied[len(applied)-1].Version)
+				printMigrations(w, applied)
+			}
+			switch {
+			case err != nil && dryRun && !errors.Is(err, storage.ErrNewerSchema):
+				return fmt.Errorf("the migration fails; the database is left as it was: %w", err)
+			case err != nil:
+				return err
+			case len(pending) == 0:
+				fmt.Fprintf(w, "The database is up to date (schema %d)\n", version)
+			case dryRun:
+				fmt.Fprintln(w, "Every migration applies and the copy passes its integrity checks; the database is left as it was")
+			case backup != "":
+				fmt.Fprintln(w, "The database as it was is backed up in", backup)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "try the migrations on a copy of the database, leaving it as it is")
+	cmd.AddCommand(newMigrateStatusCmd(a), newMigrateRollbackCmd(a))
+	return cmd
+}
+
+func newMigrateStatusCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the schema version of the database and the migrations pending",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			passphrase, err := a.schemaPassphrase(cmd)
+			if err != nil {
+				return err
+			}
+			version, pending, err := storage.SchemaStatus(cmd.Context(), a.dataDir, passphrase)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiSchema(version, pending))
+			}
+			w := cmd.OutOrStdout()
+			fmt.Fprintf(w, "Schema version %d; this version of nomadic knows %d\n", version, storage.LatestVersion())
+			if version > storage.LatestVersion() {
+				fmt.Fprintln(w, "The database is from a newer version of nomadic; upgrade nomadic to open it")
+			}
+			if len(pending) > 0 {
+				fmt.Fprintln(w, "Pending:")
+				printMigrations(w, pending)
+			}
+			return nil
+		},
+	}
+}
+
+func newMigrateRollbackCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rollback",
+		Short: "Restore the database as it was before its last migration",
+		Long: `Restore the database from the backup taken before it was last migrated,
+to use it with the older version of nomadic it came from. Changes made
+since the migration are lost; the database replaced is itself backed up
+first. This version of nomadic migrates the database again the next time
+it opens it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backups, err := storage.MigrationBackups(a.dataDir)
+			if err != nil {
+				return err
+			}
+			if len(backups) == 0 {
+				return errors.New("no backup from before a migration to roll back to")
+			}
+			m, saved, err := storage.Restore(cmd.Context(), a.dataDir, backups[0], "")
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiBackup(backups[0], m, false, saved))
+			}
+			w := cmd.OutOrStdout()
+			fmt.Fprintf(w, "Rolled the database back to schema %d, as it was %s\n", m.SchemaVersion,
+				m.Created.Local().Format("2006-01-02 15:04"))
+			if saved != "" {
+				fmt.Fprintln(w, "The database it replaced is backed up in", saved)
+			}
+			return nil
+		},
+	}
+}
+
+// schemaPassphrase reads the passphrase of an encrypted database from
+// $NOMADIC_PASSPHRASE or a prompt, or returns "" for a plain one.
+func (a *app) schemaPassphrase(cmd *cobra.Command) (string, error) {
+	if !storage.IsEncrypted(a.dataDir) {
+		return "", nil
+	}
+	if p, ok := os.LookupEnv(passphraseEnv); ok {
+		return p, nil
+	}
+	return readSecret(bufio.NewReader(cmd.InOrStdin()), "Passphrase: ")
+}
+
+func printMigrations(w io.Writer, migrations []storage.Migration) {
+	for _, m := range migrations {
+		fmt.Fprintf(w, "  %3d  %s\n", m.Version, m.Name)
+	}
+}
+
+func apiSchema(version int, pending []storage.Migration) api.Schema {
+	return api.Schema{Version: version, Latest: storage.LatestVersion(), Pending: apiMigrations(pending)}
+}
+
+func apiMigrations(migrations []storage.Migration) []api.Migration {
+	out := make([]api.Migration, len(migrations))
+	for i, m := range migrations {
+		out[i] = api.Migration{Version: m.Version, Name: m.Name}
+	}
+	return out
+}
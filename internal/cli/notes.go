@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newJournalNotesCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "notes",
+		Short: "Bring the journal's Markdown notes and its entries together",
+		Long: `With journal_storage = markdown the journal keeps its entries as Markdown
+notes with YAML front matter in journal_dir too, such as a folder of an
+Obsidian vault: a folder per trip, named after it, and a note per entry,
+named by its day and title. The database stays the index of the notes and
+keeps trips, expenses and everything else.
+
+nomadic writes an entry's note whenever the entry is saved, and moves it to
+the .trash folder of journal_dir when the entry is deleted. Each time
+nomadic starts it brings in what changed in the folder since: notes edited
+update their entries, new notes become entries of the trip they name with
+trip: or of the trip whose folder they are in, and notes removed move their
+entries to the trash. This command reports what it brought in.`,
+		Example: `  nomadic config set journal_dir ~/Obsidian/Travel
+  nomadic config set journal_storage markdown
+  nomadic journal notes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if a.notesSync == nil {
+				return fmt.Errorf("journal entries are kept in the database alone; set journal_dir and journal_storage %s to keep them as notes", config.JournalMarkdown)
+			}
+			r := a.notesSync
+			if a.json() {
+				return printJSON(cmd, api.NotesSync{Dir: r.Dir, Imported: r.Imported, Updated: r.Updated,
+					Removed: r.Removed, Written: r.Written, Skipped: orEmpty(r.Skipped)})
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Notes in %s\n", r.Dir)
+			fmt.Fprintf(out, "  %s brought in as new entries\n", plural(r.Imported, "note", "notes"))
+			fmt.Fprintf(out, "  %s updated from notes edited\n", plural(r.Updated, "entry", "entries"))
+			fmt.Fprintf(out, "  %s removed along with their entries or notes\n", plural(r.Removed, "note", "notes"))
+			fmt.Fprintf(out, "  %s written for entries without one\n", plural(r.Written, "note", "notes"))
+			for _, s := range r.Skipped {
+				fmt.Fprintf(out, "  skipped %s\n", s)
+			}
+			return nil
+		},
+	}
+}
+
+// keepNotes has store keep the journal's entries as Markdown notes in
+// journal_dir when journal_storage says so, bringing in what changed there
+// since nomadic last ran.
+func (a *app) keepNotes(ctx context.Context, store *storage.Store) error {
+	if a.cfg.JournalStorage != config.JournalMarkdown {
+		return nil
+	}
+	report, err := store.KeepNotes(ctx, expandHome(a.cfg.JournalDir))
+	if errors.Is(err, storage.ErrNotesEncrypted) {
+		return fmt.Errorf("%w; set journal_storage back to %s", err, config.JournalDatabase)
+	}
+	if err != nil {
+		return err
+	}
+	a.notesSync = report
+	if !a.tui {
+		for _, s := range report.Skipped {
+			fmt.Fprintf(os.Stderr, "nomadic: skipped the note %s\n", s)
+		}
+	}
+	return nil
+}
+
+// expandHome expands a leading ~ in path to the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
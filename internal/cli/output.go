@@ -0,0 +1,853 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/budget"
+	"github.com/girdharshubham/nomadic/internal/daemon"
+	"github.com/girdharshubham/nomadic/internal/digest"
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/flights"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/reconcile"
+	"github.com/girdharshubham/nomadic/internal/report"
+	"github.com/girdharshubham/nomadic/internal/settle"
+	"github.com/girdharshubham/nomadic/internal/stats"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/streak"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+// Values of --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// outputFormat is the value of --output, checked as it is parsed.
+type outputFormat string
+
+func (o *outputFormat) String() string {
+	if *o == "" {
+		return outputText
+	}
+	return string(*o)
+}
+
+func (o *outputFormat) Set(v string) error {
+	if v != outputText && v != outputJSON {
+		return fmt.Errorf("must be %s or %s", outputText, outputJSON)
+	}
+	*o = outputFormat(v)
+	return nil
+}
+
+func (o *outputFormat) Type() string { return "format" }
+
+// json reports whether the command should print a JSON document from
+// package api instead of text.
+func (a *app) json() bool {
+	return a.output == outputJSON
+}
+
+// printJSON writes v as an indented JSON document to the command's output.
+func printJSON(cmd *cobra.Command, v any) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// orEmpty keeps empty lists encoding as [] rather than null.
+func orEmpty[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+func apiTrip(t *models.Trip) api.Trip {
+	out := api.Trip{
+		ID:              t.ID,
+		Title:           t.Title,
+		Locations:       orEmpty(t.Locations),
+		StartDate:       t.StartDate.Format(models.DateLayout),
+		Budget:          t.Budget,
+		BudgetCurrency:  t.BudgetCurrency,
+		CategoryBudgets: t.CategoryBudgets,
+		PerDiem:         t.PerDiem,
+		Notes:           t.Notes,
+		Tags:            orEmpty(t.Tags),
+		Companions:      orEmpty(t.Companions),
+		ArchivedAt:      t.ArchivedAt,
+		Rating:          t.Rating,
+		Public:          t.Public,
+		Yearly:          t.Yearly,
+		Fields:          t.Fields,
+		CreatedAt:       t.CreatedAt,
+		UpdatedAt:       t.UpdatedAt,
+	}
+	if t.EndDate != nil {
+		out.EndDate = t.EndDate.Format(models.DateLayout)
+		out.Days = models.CalendarDays(t.StartDate, *t.EndDate)
+	}
+	return out
+}
+
+func apiOccasions(occasions []models.Occasion) []api.Occasion {
+	out := make([]api.Occasion, len(occasions))
+	for i, o := range occasions {
+		out[i] = api.Occasion{TripID: o.Trip.ID, Title: o.Trip.Title, Kind: o.Trip.Yearly,
+			Day: o.Day.Format(models.DateLayout), In: o.In, Years: o.Years, Text: o.String()}
+	}
+	return out
+}
+
+func apiTrips(trips []*models.Trip) []api.Trip {
+	out := make([]api.Trip, len(trips))
+	for i, t := range trips {
+		out[i] = apiTrip(t)
+	}
+	return out
+}
+
+func apiTrashed(t *models.Trashed) api.Trashed {
+	return api.Trashed{ID: t.ID, Kind: t.Kind, Title: t.Title, TripID: t.TripID, DeletedAt: t.DeletedAt}
+}
+
+func apiTrash(trash []*models.Trashed) []api.Trashed {
+	out := make([]api.Trashed, len(trash))
+	for i, t := range trash {
+		out[i] = apiTrashed(t)
+	}
+	return out
+}
+
+func apiLeg(l *models.Leg) api.Leg {
+	out := api.Leg{
+		ID:        l.ID,
+		TripID:    l.TripID,
+		Location:  l.Location,
+		Arrival:   l.Arrival.Format(models.DateLayout),
+		Transport: l.Transport,
+	}
+	if l.Departure != nil {
+		out.Departure = l.Departure.Format(models.DateLayout)
+	}
+	return out
+}
+
+func apiLegs(legs []*models.Leg) []api.Leg {
+	out := make([]api.Leg, len(legs))
+	for i, l := range legs {
+		out[i] = apiLeg(l)
+	}
+	return out
+}
+
+func apiItineraryItem(it *models.ItineraryItem) api.ItineraryItem {
+	return api.ItineraryItem{
+		ID:         it.ID,
+		TripID:     it.TripID,
+		Day:        it.Day.Format(models.DateLayout),
+		Time:       it.Time,
+		Title:      it.Title,
+		Place:      it.Place,
+		Notes:      it.Notes,
+		BookingRef: it.BookingRef,
+		Alarm:      it.Alarm,
+		Duration:   it.Duration,
+		Kind:       it.Kind,
+		Started:    it.Started,
+		Finished:   it.Finished,
+		Took:       optionalMinutes(it.Took()),
+		Overran:    optionalMinutes(it.Overran()),
+		Position:   it.Position,
+	}
+}
+
+func optionalMinutes(minutes int, ok bool) *int {
+	if !ok {
+		return nil
+	}
+	return &minutes
+}
+
+func apiKindTimings(timings []models.KindTiming) []api.KindTiming {
+	out := make([]api.KindTiming, len(timings))
+	for i, k := range timings {
+		out[i] = api.KindTiming{Kind: k.Kind, Items: k.Items, Planned: k.Planned, Took: k.Took, Over: k.Over,
+			Scheduled: k.Scheduled, Late: k.Late}
+	}
+	return out
+}
+
+func apiItineraryItems(items []*models.ItineraryItem) []api.ItineraryItem {
+	out := make([]api.ItineraryItem, len(items))
+	for i, it := range items {
+		out[i] = apiItineraryItem(it)
+	}
+	return out
+}
+
+func apiExpense(x *models.Expense) api.Expense {
+	out := api.Expense{
+		ID:           x.ID,
+		TripID:       x.TripID,
+		LegID:        x.LegID,
+		Date:         x.Timestamp.Format(models.DateLayout),
+		Timestamp:    x.Timestamp,
+		TimeZone:     x.TimeZone,
+		Amount:       x.Amount,
+		Currency:     x.Currency,
+		Tax:          x.Tax,
+		Tip:          x.Tip,
+		Category:     x.Category,
+		Description:  x.Description,
+		Note:         x.Note,
+		Tags:         orEmpty(x.Tags),
+		Location:     x.Location,
+		PaidBy:       x.PaidBy,
+		Merchant:     x.Merchant,
+		RecurrenceID: x.RecurrenceID,
+		Rate:         x.Rate,
+		RateCurrency: x.RateCurrency,
+	}
+	for _, sh := range x.Shares {
+		out.Shares = append(out.Shares, api.Share{Name: sh.Name, Amount: sh.Amount})
+	}
+	return out
+}
+
+func apiRecurrence(r *models.Recurrence) api.Recurrence {
+	out := api.Recurrence{
+		ID:          r.ID,
+		TripID:      r.TripID,
+		Amount:      r.Amount,
+		Currency:    r.Currency,
+		Category:    r.Category,
+		Description: r.Description,
+		Interval:    r.Interval,
+		StartDate:   r.Start.Format(models.DateLayout),
+		Paused:      r.Paused,
+	}
+	if r.End != nil {
+		out.EndDate = r.End.Format(models.DateLayout)
+	}
+	if !r.Ended() {
+		out.NextDate = r.Next.Format(models.DateLayout)
+	}
+	return out
+}
+
+func apiExpenses(expenses []*models.Expense) []api.Expense {
+	out := make([]api.Expense, len(expenses))
+	for i, x := range expenses {
+		out[i] = apiExpense(x)
+	}
+	return out
+}
+
+func apiEntry(e *models.Entry) api.Entry {
+	return api.Entry{
+		ID:        e.ID,
+		TripID:    e.TripID,
+		LegID:     e.LegID,
+		Title:     e.Title,
+		Date:      e.Timestamp.Format(models.DateLayout),
+		Timestamp: e.Timestamp,
+		TimeZone:  e.TimeZone,
+		Location:  e.Location,
+		Weather:   e.Weather,
+		Mood:      e.Mood,
+		People:    e.People,
+		Public:    e.Public,
+		Private:   e.Private,
+		Tags:      orEmpty(e.Tags),
+		Text:      e.Text,
+	}
+}
+
+func apiRevision(r *models.Revision, inserted, deleted int) api.Revision {
+	return api.Revision{ID: r.ID, EntryID: r.EntryID, Title: r.Title, SavedAt: r.SavedAt, Location: r.Location,
+		Mood: r.Mood, Tags: orEmpty(r.Tags), Text: r.Text, Inserted: inserted, Deleted: deleted}
+}
+
+func apiPerson(p *models.Person) api.Person {
+	return api.Person{ID: p.ID, Name: p.Name, Contact: p.Contact, Notes: p.Notes}
+}
+
+func apiCountryNote(n *models.CountryNote) api.CountryNote {
+	return api.CountryNote{Country: n.Country, Name: places.CountryName(n.Country), Visa: n.Visa, MaxStay: n.MaxStay,
+		Notes: n.Notes}
+}
+
+func apiConnectivity(c *models.Connectivity) api.Connectivity {
+	return api.Connectivity{ID: c.ID, Country: c.Country, Name: places.CountryName(c.Country), TripID: c.TripID,
+		LegID: c.LegID, Place: c.Place, Provider: c.Provider, Cost: c.Cost, Currency: c.Currency, Speed: c.Speed,
+		WiFi: c.WiFi, Notes: c.Notes, CreatedAt: c.CreatedAt}
+}
+
+func apiWish(w *models.Wish) api.Wish {
+	return api.Wish{ID: w.ID, Place: w.Place, Notes: w.Notes, Budget: w.Budget, Currency: w.Currency,
+		Season: models.FormatSeason(w.Season), Priority: w.Priority, TripID: w.TripID}
+}
+
+// apiVaultItem converts a travel document, with its alert if it has one.
+func apiVaultItem(it *models.VaultItem, alert *models.ExpiryAlert) api.VaultItem {
+	out := api.VaultItem{ID: it.ID, Kind: it.Kind, Title: it.Title, Holder: it.Holder, Number: it.Number, Issuer: it.Issuer,
+		Country: it.Country, Issued: it.Issued, Expires: it.Expires, Note: it.Note, Scan: it.ScanName, ScanType: it.ScanType,
+		ScanSize: it.ScanSize, CreatedAt: it.CreatedAt, UpdatedAt: it.UpdatedAt}
+	if alert != nil {
+		out.Alert = &api.ExpiryAlert{InDays: alert.In, Expired: alert.Expired()}
+		if alert.Trip != nil {
+			out.Alert.TripID, out.Alert.Trip = alert.Trip.ID, alert.Trip.Title
+		}
+	}
+	return out
+}
+
+func apiEntries(entries []*models.Entry) []api.Entry {
+	out := make([]api.Entry, len(entries))
+	for i, e := range entries {
+		out[i] = apiEntry(e)
+	}
+	return out
+}
+
+// apiAttachment points at the attached file: its copy in the data
+// directory, or the original a link leads to.
+func apiAttachment(store *storage.Store, att *models.Attachment) api.Attachment {
+	path := store.AttachmentPath(att)
+	if att.Linked {
+		if target, err := os.Readlink(path); err == nil {
+			path = target
+		}
+	}
+	return api.Attachment{ID: att.ID, EntryID: att.EntryID, Name: att.Name, Path: path, Linked: att.Linked, Size: att.Size}
+}
+
+// apiDocument points at a document's file as apiAttachment does, with the
+// title of its itinerary item, if any, among items.
+func apiDocument(store *storage.Store, d *models.Document, items map[string]*models.ItineraryItem) api.Document {
+	path := store.DocumentPath(d)
+	if d.Linked {
+		if target, err := os.Readlink(path); err == nil {
+			path = target
+		}
+	}
+	out := api.Document{ID: d.ID, TripID: d.TripID, ItemID: d.ItemID, ExpenseID: d.ExpenseID, Title: d.Title, Name: d.Name, ContentType: d.ContentType,
+		Path: path, Linked: d.Linked, Size: d.Size, Note: d.Note, CreatedAt: d.CreatedAt}
+	if it := items[d.ItemID]; it != nil {
+		out.Item = it.Title
+	}
+	return out
+}
+
+func apiTrack(t *models.Track) api.Track {
+	return api.Track{
+		ID:        t.ID,
+		TripID:    t.TripID,
+		EntryID:   t.EntryID,
+		Name:      t.Name,
+		Distance:  t.Distance,
+		Ascent:    t.Ascent,
+		Descent:   t.Descent,
+		Duration:  t.Duration().Seconds(),
+		Points:    t.Points,
+		StartedAt: t.StartedAt,
+		EndedAt:   t.EndedAt,
+		Place:     t.Place,
+	}
+}
+
+func apiSegment(seg *models.Segment) api.Segment {
+	out := api.Segment{
+		ID:          seg.ID,
+		TripID:      seg.TripID,
+		Mode:        seg.Mode,
+		Origin:      seg.Origin,
+		Destination: seg.Destination,
+		Distance:    seg.Distance,
+		Date:        seg.Date.Format(models.DateLayout),
+		Notes:       seg.Notes,
+	}
+	if co2, ok := seg.CO2(); ok {
+		out.CO2 = &co2
+	}
+	return out
+}
+
+func apiFootprint(f stats.Footprint) api.Footprint {
+	out := api.Footprint{Segments: f.Segments, Distance: f.Distance, CO2: f.CO2, Unestimated: f.Unestimated,
+		ByMode: make([]api.ModeFootprint, len(f.ByMode))}
+	for i, m := range f.ByMode {
+		out.ByMode[i] = api.ModeFootprint{Mode: m.Mode, Segments: m.Segments, Distance: m.Distance, CO2: m.CO2}
+	}
+	return out
+}
+
+func apiTracks(tracks []*models.Track) []api.Track {
+	out := make([]api.Track, len(tracks))
+	for i, t := range tracks {
+		out[i] = apiTrack(t)
+	}
+	return out
+}
+
+func apiCheckIn(c *models.CheckIn) api.CheckIn {
+	return api.CheckIn{
+		ID:        c.ID,
+		TripID:    c.TripID,
+		Place:     c.Place,
+		Note:      c.Note,
+		Lat:       c.Lat,
+		Lon:       c.Lon,
+		Date:      c.Timestamp.Format(models.DateLayout),
+		Timestamp: c.Timestamp,
+		TimeZone:  c.TimeZone,
+	}
+}
+
+func apiPacking(t *models.Trip, items []*models.PackingItem) api.Packing {
+	packed, total := models.PackingProgress(items)
+	out := api.Packing{TripID: t.ID, Packed: packed, Total: total, Items: make([]api.PackingItem, len(items))}
+	for i, it := range items {
+		out.Items[i] = api.PackingItem{ID: it.ID, Category: it.Category, Name: it.Name, Packed: it.Packed}
+	}
+	return out
+}
+
+// apiPrepTask describes a task to prepare trip t, with the days from today
+// until it is due.
+func apiPrepTask(t *models.Trip, p *models.PrepTask, today time.Time) api.PrepTask {
+	return api.PrepTask{ID: p.ID, TripID: t.ID, TripTitle: t.Title, Title: p.Title,
+		Lead: models.FormatLead(p.Lead), LeadMinutes: p.Lead, Due: p.Due(t).Format(models.DateLayout),
+		In: prepIn(t, p, today), Done: p.Done}
+}
+
+func apiPrepTemplate(t *models.PrepTemplate) api.PrepTemplate {
+	out := api.PrepTemplate{ID: t.ID, Type: t.Type, Tasks: make([]api.PrepTemplateTask, len(t.Tasks))}
+	for i, tt := range t.Tasks {
+		out.Tasks[i] = api.PrepTemplateTask{Title: tt.Title, Lead: models.FormatLead(tt.Lead), LeadMinutes: tt.Lead}
+	}
+	return out
+}
+
+// apiHighlights describes a trip's highlights, titling the linked journal
+// entries by entries, by ID.
+func apiHighlights(t *models.Trip, highlights []*models.Highlight, entries map[string]*models.Entry) api.Highlights {
+	done, total := models.HighlightProgress(highlights)
+	out := api.Highlights{TripID: t.ID, Done: done, Total: total, Highlights: make([]api.Highlight, len(highlights))}
+	for i, h := range highlights {
+		out.Highlights[i] = api.Highlight{ID: h.ID, Title: h.Title, Done: h.Done, EntryID: h.EntryID}
+		if e := entries[h.EntryID]; e != nil {
+			out.Highlights[i].EntryTitle = e.Title
+		}
+	}
+	return out
+}
+
+func apiLodging(l *models.Lodging) api.Lodging {
+	return api.Lodging{
+		ID:           l.ID,
+		TripID:       l.TripID,
+		LegID:        l.LegID,
+		Name:         l.Name,
+		Address:      l.Address,
+		Confirmation: l.Confirmation,
+		Cost:         l.Cost,
+		Currency:     l.Currency,
+		CheckIn:      l.CheckIn,
+		CheckOut:     l.CheckOut,
+		TimeZone:     l.TimeZone,
+		Nights:       l.Nights(),
+		Notes:        l.Notes,
+		ExpenseID:    l.ExpenseID,
+	}
+}
+
+func apiLodgings(lodgings []*models.Lodging) []api.Lodging {
+	out := make([]api.Lodging, len(lodgings))
+	for i, l := range lodgings {
+		out[i] = apiLodging(l)
+	}
+	return out
+}
+
+// apiHealth describes the days of trip t as they went for the body, with
+// the jetlag reckoned from home.
+func apiHealth(t *models.Trip, home string, days []stats.DayHealth) api.Health {
+	out := api.Health{TripID: t.ID, HomeZone: home, Days: make([]api.HealthDay, len(days))}
+	out.AverageSleep, out.AverageWater, out.AverageJetlag = stats.Averages(days)
+	for i, d := range days {
+		day := api.HealthDay{Day: d.Day.Format(models.DateLayout), ExpectedJetlag: d.Expected, Shift: d.Shift, Mood: d.Mood}
+		if h := d.Logged; h != nil {
+			day.Sleep, day.Water, day.Jetlag, day.Note = h.Sleep, h.Water, h.Jetlag, h.Note
+		}
+		out.Days[i] = day
+	}
+	return out
+}
+
+func apiPackingListItems(items []models.PackingListItem) []api.PackingListItem {
+	out := make([]api.PackingListItem, len(items))
+	for i, it := range items {
+		out[i] = api.PackingListItem{Category: it.Category, Name: it.Name}
+	}
+	return out
+}
+
+func apiPackingList(l *models.PackingList) api.PackingList {
+	return api.PackingList{ID: l.ID, Name: l.Name, Items: apiPackingListItems(l.Items)}
+}
+
+func apiTemplate(t *models.Template) api.Template {
+	out := api.Template{
+		ID:              t.ID,
+		Name:            t.Name,
+		Locations:       orEmpty(t.Locations),
+		Days:            t.Days,
+		Budget:          t.Budget,
+		BudgetCurrency:  t.BudgetCurrency,
+		CategoryBudgets: t.CategoryBudgets,
+		Notes:           t.Notes,
+		Tags:            orEmpty(t.Tags),
+		Packing:         apiPackingListItems(t.Packing),
+		Itinerary:       make([]api.TemplateItem, len(t.Itinerary)),
+		Legs:            make([]api.TemplateLeg, len(t.Legs)),
+	}
+	for i, it := range t.Itinerary {
+		out.Itinerary[i] = api.TemplateItem{Day: it.Day, Time: it.Time, Title: it.Title, Place: it.Place, Notes: it.Notes, Alarm: it.Alarm,
+			Duration: it.Duration, Kind: it.Kind}
+	}
+	for i, l := range t.Legs {
+		out.Legs[i] = api.TemplateLeg{Day: l.Day, Days: l.Days, Location: l.Location, Transport: l.Transport}
+	}
+	return out
+}
+
+func apiBudget(t *models.Trip, r budget.Report) api.Budget {
+	line := func(l budget.Line) api.BudgetLine {
+		level := "ok"
+		switch l.Level() {
+		case budget.Warning:
+			level = "warning"
+		case budget.Over:
+			level = "over"
+		}
+		return api.BudgetLine{Category: l.Category, Limit: l.Limit, Spent: l.Spent, Used: l.Ratio(), Level: level}
+	}
+	out := api.Budget{
+		TripID:      t.ID,
+		Currency:    r.Currency,
+		Total:       line(r.Total),
+		Categories:  make([]api.BudgetLine, len(r.Categories)),
+		Unconverted: r.Unconverted,
+	}
+	for i, l := range r.Categories {
+		out.Categories[i] = line(l)
+	}
+	if r.HasProjection {
+		out.Projected, out.DailyBurn = &r.Projected, &r.DailyBurn
+	}
+	return out
+}
+
+func apiPerDiem(t *models.Trip, p budget.PerDiem) api.PerDiem {
+	out := api.PerDiem{TripID: t.ID, Currency: p.Currency, Allowance: p.Allowance, Balance: p.Balance(),
+		Days: make([]api.PerDiemDay, len(p.Days)), Unconverted: p.Unconverted, Outside: p.Outside}
+	for i, d := range p.Days {
+		out.Days[i] = api.PerDiemDay{Date: d.Date.Format(models.DateLayout), Spent: d.Spent, Allowance: d.Allowance,
+			Balance: d.Balance(), Cumulative: d.Cumulative}
+	}
+	return out
+}
+
+func apiExpenseReport(t *models.Trip, r report.Report) api.ExpenseReport {
+	out := api.ExpenseReport{
+		TripID:      t.ID,
+		Currency:    r.Currency,
+		By:          r.By.String(),
+		Rows:        make([]api.ExpenseReportLine, len(r.Rows)),
+		Total:       r.Total,
+		Count:       r.Count,
+		Unconverted: r.Unconverted,
+	}
+	for i, row := range r.Rows {
+		line := api.ExpenseReportLine{Label: row.Label, Total: row.Total, Share: row.Share(r.Total), Count: row.Count}
+		if r.By == report.ByDay {
+			day := row.Day
+			line.Day = &day
+		}
+		out.Rows[i] = line
+	}
+	return out
+}
+
+func apiReconciliation(r reconcile.Reconciliation) api.Reconciliation {
+	out := api.Reconciliation{
+		From:        r.From.Format(models.DateLayout),
+		To:          r.To.Format(models.DateLayout),
+		Currency:    r.Currency,
+		Statement:   r.Statement,
+		Logged:      r.Logged,
+		Gap:         r.Gap(),
+		Balanced:    r.Balanced(),
+		Matched:     make([]api.Match, len(r.Matched)),
+		Missing:     apiExpenses(r.Missing),
+		Unbilled:    apiExpenses(r.Unbilled),
+		Unconverted: r.Unconverted,
+	}
+	for i, m := range r.Matched {
+		out.Matched[i] = api.Match{Charge: apiExpense(m.Charge), Expense: apiExpense(m.Expense)}
+	}
+	return out
+}
+
+func apiSettlement(t *models.Trip, s settle.Settlement) api.Settlement {
+	out := api.Settlement{
+		TripID:      t.ID,
+		Currency:    s.Currency,
+		Balances:    make([]api.Balance, len(s.Balances)),
+		Transfers:   make([]api.Transfer, len(s.Transfers)),
+		Shared:      s.Shared,
+		Unconverted: s.Unconverted,
+	}
+	for i, b := range s.Balances {
+		out.Balances[i] = api.Balance{Name: b.Name, Paid: b.Paid, Share: b.Share, Net: b.Net}
+	}
+	for i, tr := range s.Transfers {
+		out.Transfers[i] = api.Transfer{From: tr.From, To: tr.To, Amount: tr.Amount}
+	}
+	return out
+}
+
+func apiTripComparison(c stats.Comparison) api.TripComparison {
+	out := api.TripComparison{Currency: c.Currency, Trips: make([]api.TripFigures, len(c.Trips))}
+	for i, f := range c.Trips {
+		figures := api.TripFigures{
+			Trip:        api.TripRef{ID: f.Trip.ID, Title: f.Trip.Title, Days: f.Days},
+			Spend:       f.Spend,
+			PerDay:      f.PerDay,
+			Categories:  []api.Amount{},
+			Entries:     f.Entries,
+			DistanceKm:  f.Distance,
+			TrackedM:    f.Tracked,
+			Unconverted: f.Unconverted,
+		}
+		for _, name := range c.Categories {
+			if v, ok := f.Categories[name]; ok {
+				figures.Categories = append(figures.Categories, api.Amount{Label: name, Amount: v})
+			}
+		}
+		out.Trips[i] = figures
+	}
+	return out
+}
+
+func apiStats(s stats.Summary, moods []stats.TripMood, achievements []stats.Achievement, writing stats.Writing) api.Stats {
+	counts := func(list []stats.Amount) []api.Count {
+		out := make([]api.Count, len(list))
+		for i, a := range list {
+			out[i] = api.Count{Name: a.Label, Trips: int(a.Value)}
+		}
+		return out
+	}
+	amounts := func(list []stats.Amount) []api.Amount {
+		out := make([]api.Amount, len(list))
+		for i, a := range list {
+			out[i] = api.Amount{Label: a.Label, Amount: a.Value}
+		}
+		return out
+	}
+	out := api.Stats{
+		Currency:        s.Currency,
+		Trips:           s.Trips,
+		Destinations:    counts(s.Destinations),
+		Countries:       counts(s.Countries),
+		DaysTraveled:    s.DaysTraveled,
+		TotalSpend:      s.TotalSpend,
+		AverageDaily:    s.AverageDaily,
+		SpendByYear:     amounts(s.SpendByYear),
+		SpendByCategory: amounts(s.SpendCategory),
+		Unconverted:     s.Unconverted,
+		Footprint:       apiFootprint(s.Footprint),
+		Moods:           make([]api.TripMood, len(moods)),
+		Writing:         apiWriting(writing),
+		Achievements:    make([]api.Achievement, len(achievements)),
+	}
+	for i, ach := range achievements {
+		out.Achievements[i] = api.Achievement{ID: ach.ID, Title: ach.Title, Goal: ach.Goal, Progress: ach.Progress,
+			Unit: ach.Unit, Unlocked: ach.Unlocked()}
+		if !ach.UnlockedAt.IsZero() {
+			out.Achievements[i].UnlockedAt = &ach.UnlockedAt
+		}
+	}
+	if s.Longest != nil {
+		out.Longest = &api.TripRef{ID: s.Longest.ID, Title: s.Longest.Title, Days: s.LongestDays}
+	}
+	for i, m := range moods {
+		tm := api.TripMood{ID: m.Trip.ID, Title: m.Trip.Title, Rating: m.Trip.Rating, Average: m.Trend.Average(),
+			Days: make([]api.DayMood, len(m.Trend))}
+		for j, d := range m.Trend {
+			tm.Days[j] = api.DayMood{Date: d.Day.Format(models.DateLayout), Mood: d.Mood, Entries: d.Entries}
+		}
+		out.Moods[i] = tm
+	}
+	return out
+}
+
+func apiWriting(w stats.Writing) api.Writing {
+	words := func(list []stats.Amount) []api.Words {
+		out := make([]api.Words, len(list))
+		for i, a := range list {
+			out[i] = api.Words{Label: a.Label, Words: int(a.Value)}
+		}
+		return out
+	}
+	out := api.Writing{Entries: w.Entries, Words: w.Words, AverageWords: w.Average(),
+		ByTrip: words(w.ByTrip), ByMonth: words(w.ByMonth)}
+	if e := w.Longest; e != nil {
+		out.Longest = &api.Words{ID: e.ID, Label: e.Title, Words: w.LongestWords}
+	}
+	return out
+}
+
+func apiSpending(s stats.Spending) api.Spending {
+	periods := func(list []stats.PeriodSpend) []api.PeriodSpend {
+		out := make([]api.PeriodSpend, len(list))
+		for i, p := range list {
+			out[i] = api.PeriodSpend{Period: p.Label, Total: p.Total, Previous: p.Previous, Expenses: p.Count}
+		}
+		return out
+	}
+	amounts := func(list []stats.Amount) []api.Amount {
+		out := make([]api.Amount, len(list))
+		for i, a := range list {
+			out[i] = api.Amount{Label: a.Label, Amount: a.Value}
+		}
+		return out
+	}
+	out := api.Spending{
+		Currency:    s.Currency,
+		Period:      s.Period.String(),
+		Total:       s.Total,
+		Previous:    s.Previous,
+		Expenses:    s.Count,
+		Months:      periods(s.Months),
+		Categories:  amounts(s.Categories),
+		Trips:       amounts(s.Trips),
+		Unconverted: s.Unconverted,
+	}
+	if s.Years != nil {
+		out.Years = periods(s.Years)
+	}
+	return out
+}
+
+func apiDigest(d *digest.Digest) api.Digest {
+	out := api.Digest{
+		Since:       d.Since,
+		Until:       d.Until,
+		Ahead:       d.Ahead,
+		Entries:     make([]api.Entry, len(d.Entries)),
+		Currency:    d.Currency,
+		Total:       d.Total,
+		Expenses:    d.Expenses,
+		Categories:  make([]api.Amount, len(d.Spent)),
+		Unconverted: d.Unconverted,
+		Upcoming:    []api.ItineraryItem{},
+	}
+	for i, e := range d.Entries {
+		out.Entries[i] = apiEntry(e.Entry)
+	}
+	for i, s := range d.Spent {
+		out.Categories[i] = api.Amount{Label: models.CategoryLabel(s.Category), Amount: s.Amount}
+	}
+	for _, u := range d.Upcoming {
+		out.Upcoming = append(out.Upcoming, apiItineraryItems(u.Items)...)
+	}
+	return out
+}
+
+func apiStreak(s streak.Streak) api.Streak {
+	out := api.Streak{Current: s.Current, Longest: s.Longest, WrittenToday: s.WrittenToday, Due: s.Due()}
+	if s.Trip != nil {
+		out.TripID, out.TripTitle = s.Trip.ID, s.Trip.Title
+	}
+	return out
+}
+
+func apiDaemonStatus(s daemon.Status, now time.Time) api.DaemonStatus {
+	out := api.DaemonStatus{Running: s.Running(now), PID: s.PID, Started: s.Started, Heartbeat: s.Heartbeat,
+		Jobs: make([]api.DaemonJob, len(s.Jobs))}
+	if !s.Stopped.IsZero() {
+		out.Stopped = &s.Stopped
+	}
+	for i, j := range s.Jobs {
+		out.Jobs[i] = api.DaemonJob{Name: j.Name, Every: j.Every.String(), Result: j.Result, Error: j.Error}
+		if !j.LastRun.IsZero() {
+			out.Jobs[i].LastRun = &j.LastRun
+		}
+		if !j.NextRun.IsZero() && out.Running {
+			out.Jobs[i].NextRun = &j.NextRun
+		}
+	}
+	return out
+}
+
+func apiRule(r *models.Rule) api.Rule {
+	return api.Rule{ID: r.ID, Match: r.Match, Category: r.Category, TripID: r.TripID, Learned: r.Learned}
+}
+
+func apiRules(rules []*models.Rule) []api.Rule {
+	out := make([]api.Rule, len(rules))
+	for i, r := range rules {
+		out[i] = apiRule(r)
+	}
+	return out
+}
+
+func apiCategory(c *models.Category, expenses int) api.Category {
+	return api.Category{ID: c.ID, Name: c.Name, Icon: c.Icon, Colour: c.Colour, Position: c.Position, Expenses: expenses}
+}
+
+func apiPlace(p places.Place) api.Place {
+	return api.Place{Name: p.Name, Country: p.Country, CountryName: p.CountryName, Lat: p.Lat, Lon: p.Lon, Timezone: p.Timezone}
+}
+
+func apiAirport(ap flights.Airport) api.Airport {
+	return api.Airport{Code: ap.Code, Name: ap.Name, City: ap.City, Country: ap.Country, CountryName: ap.CountryName(),
+		Lat: ap.Lat, Lon: ap.Lon, Timezone: ap.Timezone}
+}
+
+func apiDumpImport(r *storage.ImportReport, backup string, opts storage.ImportOptions) api.DumpImport {
+	return api.DumpImport{DryRun: opts.DryRun, Overwrite: opts.Overwrite, Backup: backup, Added: r.Added,
+		Replaced: r.Replaced, Kept: r.Kept}
+}
+
+func apiImport(r *export.ImportReport, dryRun bool) api.Import {
+	out := api.Import{
+		DryRun:     dryRun,
+		Imported:   make([]api.Expense, len(r.Imported)),
+		Duplicates: make([]api.ImportLine, len(r.Duplicates)),
+		Failed:     make([]api.ImportLine, len(r.Failed)),
+	}
+	for i, row := range r.Imported {
+		out.Imported[i] = apiExpense(row.Expense)
+	}
+	for i, row := range r.Duplicates {
+		x := apiExpense(row.Expense)
+		out.Duplicates[i] = api.ImportLine{Line: row.Line, Expense: &x, DuplicateOf: row.DuplicateOf.ID}
+	}
+	for i, row := range r.Failed {
+		out.Failed[i] = api.ImportLine{Line: row.Line, Error: row.Err.Error()}
+	}
+	return out
+}
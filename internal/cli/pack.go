@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+const tripFlagUsage = "trip ID, title or destination (default: the trip in progress)"
+
+func newPackCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pack",
+		Short: "Keep a packing list per trip",
+		Long: `Keep a checklist of things to pack for each trip, grouped by category.
+
+Items are given as "category: name", or just a name for no category.
+Master lists such as "Essentials" or "Ski gear" are saved from a trip with
+save-list and added to other trips with apply.`,
+	}
+	cmd.AddCommand(newPackListCmd(a), newPackAddCmd(a), newPackCheckCmd(a, true), newPackCheckCmd(a, false),
+		newPackRemoveCmd(a), newPackListsCmd(a), newPackSaveListCmd(a), newPackApplyCmd(a), newPackDeleteListCmd(a))
+	return cmd
+}
+
+func newPackListCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show a trip's packing list and how much is packed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			items, err := a.store.ListPackingByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiPacking(t, items))
+			}
+			printPacking(cmd.OutOrStdout(), t, items)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newPackAddCmd(a *app) *cobra.Command {
+	var trip, category string
+	cmd := &cobra.Command{
+		Use:   "add <item>...",
+		Short: "Add items to a trip's packing list",
+		Example: `  nomadic pack add --trip japan "Documents: passport" "Documents: JR pass"
+  nomadic pack add --trip japan --category Clothes socks "rain jacket"`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			list := make([]models.PackingListItem, len(args))
+			for i, arg := range args {
+				list[i] = models.ParsePackingItem(arg)
+				if list[i].Category == "" {
+					list[i].Category = strings.TrimSpace(category)
+				}
+			}
+			n, err := a.store.AddPackingItems(ctx, t.ID, list)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printPackingJSON(cmd, t)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %d of %d items to %q\n", n, len(list), t.Title)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	cmd.Flags().StringVar(&category, "category", "", "category for items given without one")
+	return cmd
+}
+
+// newPackCheckCmd is "pack check" when packed is set, "pack uncheck" otherwise.
+func newPackCheckCmd(a *app, packed bool) *cobra.Command {
+	var trip string
+	use, short, done := "check", "Mark items as packed", "Packed"
+	if !packed {
+		use, short, done = "uncheck", "Mark items as not packed", "Unpacked"
+	}
+	cmd := &cobra.Command{
+		Use:   use + " <item>...",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			items, err := a.store.ListPackingByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			for _, ref := range args {
+				it, err := resolvePackingItem(items, ref)
+				if err != nil {
+					return err
+				}
+				it.Packed = packed
+				if err := a.store.SavePackingItem(ctx, it); err != nil {
+					return err
+				}
+				if !a.json() {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s %q\n", done, it.Name)
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, apiPacking(t, items))
+			}
+			n, total := models.PackingProgress(items)
+			fmt.Fprintf(cmd.OutOrStdout(), "%d of %d packed\n", n, total)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newPackRemoveCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "remove <item>...",
+		Short: "Remove items from a trip's packing list",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			items, err := a.store.ListPackingByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			for _, ref := range args {
+				it, err := resolvePackingItem(items, ref)
+				if err != nil {
+					return err
+				}
+				if err := a.store.DeletePackingItem(ctx, it.ID); err != nil {
+					return err
+				}
+				if !a.json() {
+					fmt.Fprintf(cmd.OutOrStdout(), "Removed %q\n", it.Name)
+				}
+			}
+			if a.json() {
+				return a.printPackingJSON(cmd, t)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newPackListsCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "lists",
+		Short: "List the master packing lists",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lists, err := a.store.ListPackingLists(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.PackingList, len(lists))
+				for i, l := range lists {
+					out[i] = apiPackingList(l)
+				}
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tITEMS")
+			for _, l := range lists {
+				fmt.Fprintf(w, "%s\t%d\n", l.Name, len(l.Items))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newPackSaveListCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "save-list <name>",
+		Short: "Save a trip's packing list as a master list",
+		Long: `Save a trip's packing list as a master list to add to other trips. Saving
+under the name of an existing list replaces it.`,
+		Example: `  nomadic pack save-list "Ski gear" --trip chamonix`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			items, err := a.store.ListPackingByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			l, err := a.store.SavePackingListAs(ctx, strings.TrimSpace(args[0]), models.PackingListItems(items))
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiPackingList(l))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved packing list %q with %d items\n", l.Name, len(l.Items))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newPackApplyCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "apply <list>",
+		Short: "Add a master list to a trip's packing list",
+		Long:  "Add the items of a master list to a trip's packing list, skipping those it already has.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			l, err := resolvePackingList(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			n, err := a.store.AddPackingItems(ctx, t.ID, l.Items)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printPackingJSON(cmd, t)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %d items from %q to %q\n", n, l.Name, t.Title)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", tripFlagUsage)
+	return cmd
+}
+
+func newPackDeleteListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete-list <list>",
+		Short: "Delete a master packing list",
+		Long:  "Delete a master packing list. Items already added to trips are kept.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			l, err := resolvePackingList(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeletePackingList(ctx, l.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "packing_list", ID: l.ID, Name: l.Name})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted packing list %q\n", l.Name)
+			return nil
+		},
+	}
+}
+
+// printPackingJSON prints the trip's packing list as it now stands.
+func (a *app) printPackingJSON(cmd *cobra.Command, t *models.Trip) error {
+	items, err := a.store.ListPackingByTrip(cmd.Context(), t.ID)
+	if err != nil {
+		return err
+	}
+	return printJSON(cmd, apiPacking(t, items))
+}
+
+// printPacking writes a trip's packing list grouped by category, with the
+// share packed so far.
+func printPacking(w io.Writer, t *models.Trip, items []*models.PackingItem) {
+	if len(items) == 0 {
+		fmt.Fprintf(w, "Nothing to pack for %q yet; add items with `nomadic pack add`.\n", t.Title)
+		return
+	}
+	packed, total := models.PackingProgress(items)
+	fmt.Fprintf(w, "%s: %d of %d packed (%.0f%%)\n", t.Title, packed, total, float64(packed)/float64(total)*100)
+	category := "\x00"
+	for _, it := range items {
+		if it.Category != category {
+			category = it.Category
+			name := category
+			if name == "" {
+				name = models.UncategorizedPacking
+			}
+			fmt.Fprintf(w, "\n%s\n", name)
+		}
+		box := "[ ]"
+		if it.Packed {
+			box = "[x]"
+		}
+		fmt.Fprintf(w, "  %s %s\n", box, it.Name)
+	}
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newPeopleCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "people",
+		Short: "Keep track of the people you travel with",
+		Long: `Keep the contacts and notes of the people you travel with. Everyone named
+as a companion of a trip is added here; expenses are paid by and split
+among them, and journal entries note who they were written with
+(` + "`nomadic journal new --with`" + `). Renaming someone renames them on every
+trip, entry and expense.`,
+	}
+	cmd.AddCommand(newPeopleListCmd(a), newPeopleAddCmd(a), newPeopleEditCmd(a), newPeopleShowCmd(a),
+		newPeopleRemoveCmd(a))
+	return cmd
+}
+
+func newPeopleListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the people you travel with",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			people, err := a.store.ListPeople(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.Person, len(people))
+				for i, p := range people {
+					out[i] = apiPerson(p)
+				}
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tCONTACT\tNOTES")
+			for _, p := range people {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, p.Contact, p.Notes)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newPeopleAddCmd(a *app) *cobra.Command {
+	var contact, notes string
+	cmd := &cobra.Command{
+		Use:     "add <name>",
+		Short:   "Add someone you travel with",
+		Example: `  nomadic people add Ana --contact ana@example.com --notes "Vegetarian"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := models.CheckPersonName(args[0]); err != nil {
+				return err
+			}
+			p := models.NewPerson(args[0])
+			p.Contact, p.Notes = strings.TrimSpace(contact), strings.TrimSpace(notes)
+			if err := a.store.SavePerson(cmd.Context(), p); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiPerson(p))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %s\n", p.Name)
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&contact, "contact", "", "how to reach them, such as a phone number or email")
+	f.StringVar(&notes, "notes", "", "notes about them")
+	return cmd
+}
+
+func newPeopleEditCmd(a *app) *cobra.Command {
+	var name, contact, notes string
+	cmd := &cobra.Command{
+		Use:   "edit <person>",
+		Short: "Rename someone or change their contact and notes",
+		Long: `Change someone's contact or notes, or rename them with --name. A new name
+replaces the old one on every trip, journal entry and expense naming them.`,
+		Example: `  nomadic people edit ana --name "Ana Sousa"
+  nomadic people edit ben --contact "+44 7700 900123"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			p, err := resolvePerson(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			f := cmd.Flags()
+			old := p.Name
+			if f.Changed("name") && strings.TrimSpace(name) != p.Name {
+				if err := models.CheckPersonName(name); err != nil {
+					return fmt.Errorf("--name: %w", err)
+				}
+				if err := a.store.RenamePerson(ctx, p, name); err != nil {
+					return err
+				}
+			}
+			if f.Changed("contact") {
+				p.Contact = strings.TrimSpace(contact)
+			}
+			if f.Changed("notes") {
+				p.Notes = strings.TrimSpace(notes)
+			}
+			if err := a.store.SavePerson(ctx, p); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiPerson(p))
+			}
+			if p.Name != old {
+				fmt.Fprintf(cmd.OutOrStdout(), "Renamed %s to %s\n", old, p.Name)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Updated %s\n", p.Name)
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&name, "name", "", "new name")
+	f.StringVar(&contact, "contact", "", "how to reach them; empty to clear")
+	f.StringVar(&notes, "notes", "", "notes about them; empty to clear")
+	return cmd
+}
+
+func newPeopleShowCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <person>",
+		Short: "Show someone with the trips and entries you shared",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			p, err := resolvePerson(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			trips, err := a.store.ListTripsWith(ctx, p.Name)
+			if err != nil {
+				return err
+			}
+			entries, err := a.store.ListEntriesWith(ctx, p.Name)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := apiPerson(p)
+				out.Trips, out.Entries = apiTrips(trips), apiEntries(entries)
+				return printJSON(cmd, out)
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%s\n", p.Name)
+			if p.Contact != "" {
+				fmt.Fprintf(out, "Contact: %s\n", p.Contact)
+			}
+			if p.Notes != "" {
+				fmt.Fprintf(out, "Notes:   %s\n", p.Notes)
+			}
+			if len(trips) > 0 {
+				fmt.Fprintln(out, "\nTrips:")
+				for _, t := range trips {
+					fmt.Fprintf(out, "  %s  %s\n", a.formatDate(t.StartDate), t.Title)
+				}
+			}
+			if len(entries) > 0 {
+				fmt.Fprintln(out, "\nEntries:")
+				for _, e := range entries {
+					fmt.Fprintf(out, "  %s  %s\n", a.formatDate(e.Timestamp), e.Title)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newPeopleRemoveCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <person>",
+		Short: "Forget someone's contact and notes",
+		Long: `Remove someone from the people you travel with. Trips, journal entries and
+expenses naming them keep the name; remove them from a trip with
+` + "`nomadic trip companions --remove`" + `.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			p, err := resolvePerson(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeletePerson(ctx, p.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "person", ID: p.ID, Name: p.Name})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", p.Name)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/flights"
+	"github.com/girdharshubham/nomadic/internal/gpx"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newPlacesCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "places",
+		Short: "Look up cities in the offline places dataset",
+		Long: `Look up the country, coordinates and time zone of a city, or the city
+nearest to a position, from the dataset built into nomadic. No network
+access is needed.
+
+Trip destinations found in the dataset count towards country statistics,
+and journal entries written at a known location show its local time.
+Airports have a dataset of their own, for flights.`,
+	}
+	cmd.AddCommand(newPlacesLookupCmd(a), newPlacesNearCmd(a), newPlacesAirportCmd(a))
+	return cmd
+}
+
+func newPlacesLookupCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "lookup <name>",
+		Short: "Show the country, coordinates and time zone of a city",
+		Long: `Show the country, coordinates and time zone of a city. Case and accents
+are ignored, and a country name or code after a comma picks between cities
+of the same name.`,
+		Example: `  nomadic places lookup kyoto
+  nomadic places lookup "Victoria, Seychelles"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			p, ok := places.Lookup(args[0])
+			if !ok {
+				if similar := places.Search(args[0], 5); len(similar) > 0 {
+					names := make([]string, len(similar))
+					for i, s := range similar {
+						names[i] = s.String()
+					}
+					return fmt.Errorf("no place called %q; did you mean %s?", args[0], strings.Join(names, "; "))
+				}
+				return fmt.Errorf("no place called %q in the dataset", args[0])
+			}
+			if a.json() {
+				return printJSON(cmd, apiPlace(p))
+			}
+			printPlace(cmd.OutOrStdout(), p)
+			return nil
+		},
+	}
+}
+
+func newPlacesNearCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:     "near <lat> <lon>",
+		Short:   "Find the city nearest to a position",
+		Example: `  nomadic places near 35.0116 135.7681`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lat, err := strconv.ParseFloat(args[0], 64)
+			if err != nil || lat < -90 || lat > 90 {
+				return fmt.Errorf("invalid latitude %q", args[0])
+			}
+			lon, err := strconv.ParseFloat(args[1], 64)
+			if err != nil || lon < -180 || lon > 180 {
+				return fmt.Errorf("invalid longitude %q", args[1])
+			}
+			p, d, ok := places.Nearest(lat, lon)
+			if !ok {
+				return errors.New("no places in the dataset")
+			}
+			if a.json() {
+				out := apiPlace(p)
+				out.Distance = &d
+				return printJSON(cmd, out)
+			}
+			printPlace(cmd.OutOrStdout(), p)
+			fmt.Fprintf(cmd.OutOrStdout(), "Distance: %s\n", gpx.FormatDistance(d))
+			return nil
+		},
+	}
+}
+
+func newPlacesAirportCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "airport <code or name> [<code or name>]",
+		Short: "Show the city, coordinates and time zone of an airport",
+		Long: `Show the city, country, coordinates and time zone of an airport, given by
+its IATA code or its name, or a word of it. With a second airport, show
+both and the great-circle distance of a flight between them.`,
+		Example: `  nomadic places airport HND
+  nomadic places airport haneda lisbon`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var found []flights.Airport
+			for _, arg := range args {
+				ap, err := findAirport(arg)
+				if err != nil {
+					return err
+				}
+				found = append(found, ap)
+			}
+			var km float64
+			if len(found) == 2 {
+				km = found[0].DistanceTo(found[1])
+			}
+			if a.json() {
+				out := make([]api.Airport, len(found))
+				for i, ap := range found {
+					out[i] = apiAirport(ap)
+				}
+				if len(found) == 2 {
+					out[1].Distance = &km
+				}
+				return printJSON(cmd, out)
+			}
+			w := cmd.OutOrStdout()
+			for i, ap := range found {
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				printAirport(w, ap)
+			}
+			if len(found) == 2 {
+				fmt.Fprintf(w, "\nDistance: %.0f km\n", km)
+			}
+			return nil
+		},
+	}
+}
+
+func printAirport(w io.Writer, ap flights.Airport) {
+	fmt.Fprintf(w, "%s\n", ap)
+	fmt.Fprintf(w, "City: %s, %s (%s)\n", ap.City, ap.CountryName(), ap.Country)
+	fmt.Fprintf(w, "Coordinates: %s\n", ap.Coordinates())
+	fmt.Fprintf(w, "Time zone: %s, now %s\n", ap.Timezone, time.Now().In(ap.Location()).Format("15:04 MST"))
+}
+
+func printPlace(w io.Writer, p places.Place) {
+	fmt.Fprintf(w, "%s\n", p)
+	fmt.Fprintf(w, "Country: %s (%s)\n", p.CountryName, p.Country)
+	fmt.Fprintf(w, "Coordinates: %s\n", p.Coordinates())
+	fmt.Fprintf(w, "Time zone: %s, now %s\n", p.Timezone, time.Now().In(p.Location()).Format("15:04 MST"))
+}
@@ -0,0 +1,503 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+// prepTripFlagUsage describes --trip of the prep commands, which default
+// to the next trip rather than the one in progress.
+const prepTripFlagUsage = "trip ID, title or destination (default: the next trip)"
+
+func newPrepCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "prep",
+		Aliases: []string{"prepare", "countdown"},
+		Short:   "Keep a checklist of things to do before a trip leaves",
+		Long: `Keep a checklist per trip of the things to get done before it leaves, each
+due a lead time before departure, such as booking insurance two weeks
+ahead (T-14d) or checking in online the day before (T-24h). Deadlines
+count from the start of the trip's first day.
+
+Templates hold the tasks each type of trip takes: the one of no type is
+for every trip, the others for the trips tagged with their type, such as
+abroad or flight. A new trip is given their tasks, and nomadic prep
+generate adds those a trip lacks, say once tagged. The home screen of the
+TUI lists the deadlines of the next two weeks.`,
+	}
+	cmd.AddCommand(newPrepListCmd(a), newPrepAddCmd(a), newPrepCheckCmd(a, true), newPrepCheckCmd(a, false),
+		newPrepRemoveCmd(a), newPrepGenerateCmd(a), newPrepDueCmd(a), newPrepTemplateCmd(a))
+	return cmd
+}
+
+func newPrepListCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show a trip's checklist with the deadlines",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, err := a.resolvePrepTrip(cmd.Context(), trip)
+			if err != nil {
+				return err
+			}
+			return a.printPrep(cmd, t)
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", prepTripFlagUsage)
+	return cmd
+}
+
+func newPrepAddCmd(a *app) *cobra.Command {
+	var trip, before string
+	cmd := &cobra.Command{
+		Use:   "add <task>",
+		Short: "Add a task to do before a trip leaves",
+		Example: `  nomadic prep add --trip japan --before 14d "Book travel insurance"
+  nomadic prep add --trip japan --before T-24h "Check in online"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			lead, err := models.ParseLead(before)
+			if err != nil {
+				return fmt.Errorf("--before: %w", err)
+			}
+			t, err := a.resolvePrepTrip(ctx, trip)
+			if err != nil {
+				return err
+			}
+			p := models.NewPrepTask(t.ID, args[0], lead)
+			if p.Title == "" {
+				return errors.New("a task needs a title")
+			}
+			if err := a.store.SavePrepTask(ctx, p); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiPrepTask(t, p, time.Now()))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %q to %q, due %s (%s)\n", p.Title, t.Title,
+				a.formatDate(p.Due(t)), models.FormatLead(p.Lead))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", prepTripFlagUsage)
+	cmd.Flags().StringVar(&before, "before", "", "how long before departure it is due, such as 60d, 2w or 24h")
+	cmd.MarkFlagRequired("before")
+	return cmd
+}
+
+// newPrepCheckCmd is "prep check" when done is set, "prep uncheck"
+// otherwise.
+func newPrepCheckCmd(a *app, done bool) *cobra.Command {
+	var trip string
+	use, short, did := "check", "Check tasks off as done", "Done"
+	if !done {
+		use, short, did = "uncheck", "Mark tasks as still to do", "To do"
+	}
+	cmd := &cobra.Command{
+		Use:   use + " <task>...",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, tasks, err := a.prepTasks(ctx, trip)
+			if err != nil {
+				return err
+			}
+			for _, ref := range args {
+				p, err := resolvePrepTask(tasks, ref)
+				if err != nil {
+					return err
+				}
+				p.Done = done
+				if err := a.store.SavePrepTask(ctx, p); err != nil {
+					return err
+				}
+				if !a.json() {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s: %q\n", did, p.Title)
+				}
+			}
+			if a.json() {
+				return a.printPrep(cmd, t)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", prepTripFlagUsage)
+	return cmd
+}
+
+func newPrepRemoveCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:     "remove <task>...",
+		Aliases: []string{"rm"},
+		Short:   "Remove tasks from a trip's checklist",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, tasks, err := a.prepTasks(ctx, trip)
+			if err != nil {
+				return err
+			}
+			for _, ref := range args {
+				p, err := resolvePrepTask(tasks, ref)
+				if err != nil {
+					return err
+				}
+				if err := a.store.DeletePrepTask(ctx, p.ID); err != nil {
+					return err
+				}
+				if !a.json() {
+					fmt.Fprintf(cmd.OutOrStdout(), "Removed %q\n", p.Title)
+				}
+			}
+			if a.json() {
+				return a.printPrep(cmd, t)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", prepTripFlagUsage)
+	return cmd
+}
+
+func newPrepGenerateCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Add the tasks of the templates for a trip's type",
+		Long: `Add to a trip's checklist the tasks of the template for every trip and of
+the templates for the types it is tagged with. Tasks titled like one on the
+checklist already are left out, so generating again adds only what the
+templates gained since.`,
+		Example: `  nomadic prep generate --trip japan`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := a.resolvePrepTrip(ctx, trip)
+			if err != nil {
+				return err
+			}
+			added, err := a.store.AddPrepTasks(ctx, t)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printPrep(cmd, t)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %s to %q\n", plural(len(added), "task", "tasks"), t.Title)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", prepTripFlagUsage)
+	return cmd
+}
+
+func newPrepDueCmd(a *app) *cobra.Command {
+	var days int
+	cmd := &cobra.Command{
+		Use:   "due",
+		Short: "List the deadlines coming up on every trip",
+		Long: `List the tasks still to do before the trips yet to leave that are due in
+the days coming up, and those overdue, soonest first.`,
+		Example: `  nomadic prep due
+  nomadic prep due --days 60`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days < 1 {
+				return errors.New("--days must be at least 1")
+			}
+			ctx := cmd.Context()
+			trips, err := a.store.ListTrips(ctx)
+			if err != nil {
+				return err
+			}
+			tasks, err := a.store.ListPrepTasks(ctx)
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			deadlines := models.PrepDeadlines(trips, tasks, now, days)
+			if a.json() {
+				out := make([]api.PrepTask, len(deadlines))
+				for i, d := range deadlines {
+					out[i] = apiPrepTask(d.Trip, d.Task, now)
+				}
+				return printJSON(cmd, out)
+			}
+			out := cmd.OutOrStdout()
+			if len(deadlines) == 0 {
+				fmt.Fprintf(out, "Nothing is due in the next %s.\n", plural(days, "day", "days"))
+				return nil
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "DUE\tWHEN\tTRIP\tTASK")
+			for _, d := range deadlines {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.formatDate(d.Day), prepWhen(d.In), d.Trip.Title, d.Task.Title)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", 14, "how many days ahead to look, from today")
+	return cmd
+}
+
+func newPrepTemplateCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "template",
+		Aliases: []string{"templates"},
+		Short:   "Manage the tasks each type of trip takes",
+		Long: `Manage the templates of tasks to prepare each type of trip. A template's
+type is a trip tag, or none for the template of every trip; nomadic prep
+generate adds the tasks of those for a trip to its checklist.`,
+	}
+	cmd.AddCommand(newPrepTemplateListCmd(a), newPrepTemplateAddCmd(a), newPrepTemplateRemoveCmd(a))
+	return cmd
+}
+
+func newPrepTemplateListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the templates and their tasks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templates, err := a.store.ListPrepTemplates(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.PrepTemplate, len(templates))
+				for i, t := range templates {
+					out[i] = apiPrepTemplate(t)
+				}
+				return printJSON(cmd, out)
+			}
+			if len(templates) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No templates yet; add a task to one with `nomadic prep template add`.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TYPE\tLEAD\tTASK")
+			for _, t := range templates {
+				for _, tt := range t.Tasks {
+					fmt.Fprintf(w, "%s\t%s\t%s\n", prepType(t.Type), models.FormatLead(tt.Lead), tt.Title)
+				}
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newPrepTemplateAddCmd(a *app) *cobra.Command {
+	var kind, before string
+	cmd := &cobra.Command{
+		Use:   "add <task>",
+		Short: "Add a task to the template of a type of trip",
+		Long: `Add a task to the template of a type of trip, starting the template if
+there is none. A task of the same title is given the new lead time.`,
+		Example: `  nomadic prep template add --type flight --before 24h "Check in online"
+  nomadic prep template add --before 3d "Tell the bank about the trip"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			lead, err := models.ParseLead(before)
+			if err != nil {
+				return fmt.Errorf("--before: %w", err)
+			}
+			title := strings.TrimSpace(args[0])
+			if title == "" {
+				return errors.New("a task needs a title")
+			}
+			t, err := a.prepTemplate(ctx, kind)
+			if err != nil {
+				return err
+			}
+			if t == nil {
+				t = &models.PrepTemplate{Type: kind}
+			}
+			replaced := false
+			for i, tt := range t.Tasks {
+				if strings.EqualFold(tt.Title, title) {
+					t.Tasks[i].Lead, replaced = lead, true
+				}
+			}
+			if !replaced {
+				t.Tasks = append(t.Tasks, models.PrepTemplateTask{Title: title, Lead: lead})
+			}
+			if err := a.store.SavePrepTemplate(ctx, t); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiPrepTemplate(t))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %q (%s) to the template of %s\n", title, models.FormatLead(lead), prepType(t.Type))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kind, "type", "", "type of trip: the tag of the trips it is for (default every trip)")
+	cmd.Flags().StringVar(&before, "before", "", "how long before departure it is due, such as 60d, 2w or 24h")
+	cmd.MarkFlagRequired("before")
+	return cmd
+}
+
+func newPrepTemplateRemoveCmd(a *app) *cobra.Command {
+	var kind string
+	cmd := &cobra.Command{
+		Use:     "remove [task]",
+		Aliases: []string{"rm"},
+		Short:   "Remove a task from a template, or the whole template",
+		Long: `Remove a task from the template of a type of trip, or without one the
+whole template. Tasks added to trips already are kept.`,
+		Example: `  nomadic prep template remove --type flight "Check in online"
+  nomadic prep template remove --type abroad`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := a.prepTemplate(ctx, kind)
+			if err != nil {
+				return err
+			}
+			if t == nil {
+				return fmt.Errorf("no template for %s", prepType(kind))
+			}
+			if len(args) == 0 {
+				if err := a.store.DeletePrepTemplate(ctx, t.ID); err != nil {
+					return err
+				}
+				if a.json() {
+					return printJSON(cmd, api.Deleted{Kind: "prep template", ID: t.ID, Name: t.Type})
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed the template of %s\n", prepType(t.Type))
+				return nil
+			}
+			kept := t.Tasks[:0]
+			for _, tt := range t.Tasks {
+				if !strings.EqualFold(tt.Title, strings.TrimSpace(args[0])) {
+					kept = append(kept, tt)
+				}
+			}
+			if len(kept) == len(t.Tasks) {
+				return fmt.Errorf("the template of %s has no task %q", prepType(t.Type), args[0])
+			}
+			t.Tasks = kept
+			if err := a.store.SavePrepTemplate(ctx, t); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiPrepTemplate(t))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %q from the template of %s\n", args[0], prepType(t.Type))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&kind, "type", "", "type of trip (default the template of every trip)")
+	return cmd
+}
+
+// resolvePrepTrip picks the trip ref, or the next trip to leave without
+// one.
+func (a *app) resolvePrepTrip(ctx context.Context, ref string) (*models.Trip, error) {
+	if ref != "" {
+		return resolveTrip(ctx, a.store, ref)
+	}
+	trips, err := a.store.ListTrips(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t := models.NextTrip(trips, time.Now()); t != nil {
+		return t, nil
+	}
+	return resolveTrip(ctx, a.store, "")
+}
+
+// prepTasks picks the trip tripRef with its checklist.
+func (a *app) prepTasks(ctx context.Context, tripRef string) (*models.Trip, []*models.PrepTask, error) {
+	t, err := a.resolvePrepTrip(ctx, tripRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	tasks, err := a.store.ListPrepTasksByTrip(ctx, t.ID)
+	return t, tasks, err
+}
+
+// prepTemplate finds the template of the type of trip kind, or nil when
+// there is none.
+func (a *app) prepTemplate(ctx context.Context, kind string) (*models.PrepTemplate, error) {
+	templates, err := a.store.ListPrepTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if strings.EqualFold(t.Type, strings.TrimSpace(kind)) {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// printPrep prints a trip's checklist as it now stands.
+func (a *app) printPrep(cmd *cobra.Command, t *models.Trip) error {
+	tasks, err := a.store.ListPrepTasksByTrip(cmd.Context(), t.ID)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if a.json() {
+		out := make([]api.PrepTask, len(tasks))
+		for i, p := range tasks {
+			out[i] = apiPrepTask(t, p, now)
+		}
+		return printJSON(cmd, out)
+	}
+	if len(tasks) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "Nothing to do before %q yet; add tasks with `nomadic prep add` or `nomadic prep generate`.\n", t.Title)
+		return nil
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DUE\tLEAD\tWHEN\tTASK")
+	for _, p := range tasks {
+		when := "done"
+		if !p.Done {
+			when = prepWhen(prepIn(t, p, now))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.formatDate(p.Due(t)), models.FormatLead(p.Lead), when, p.Title)
+	}
+	return w.Flush()
+}
+
+// prepIn counts the days from the day of now until p is due, below zero
+// once overdue.
+func prepIn(t *models.Trip, p *models.PrepTask, now time.Time) int {
+	y, m, d := now.Date()
+	return int(p.Due(t).Sub(time.Date(y, m, d, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
+
+// prepWhen says how soon a task is due, in days from today.
+func prepWhen(in int) string {
+	switch {
+	case in < -1:
+		return fmt.Sprintf("overdue %d days", -in)
+	case in == -1:
+		return "overdue 1 day"
+	}
+	return occasionWhen(in)
+}
+
+// prepType names the type of trip of a template.
+func prepType(kind string) string {
+	if kind == "" {
+		return "every trip"
+	}
+	return kind + " trips"
+}
@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+// profileEnv names the variable choosing the profile when --profile does
+// not.
+const profileEnv = "NOMADIC_PROFILE"
+
+func newProfileCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "profile",
+		Aliases: []string{"profiles"},
+		Short:   "Keep separate journals, such as personal and work travel",
+		Long: `A profile is a journal of its own, with its own trips, entries and
+expenses, so reports never mix them, and settings overriding those of the
+config file. Profiles are [profiles.<name>] tables of the config file:
+
+  [profiles.work]
+  home_currency = "USD"
+  data_dir = "~/work/nomadic"
+
+Choose one with --profile work or $NOMADIC_PROFILE, for commands and the
+TUI alike, or switch in the TUI from Profiles in the command palette.
+Without data_dir a profile keeps its journal beside the default one, in
+$XDG_DATA_HOME/nomadic-<name>. nomadic config set changes the settings of
+the profile chosen.`,
+		Example: `  nomadic profile add work
+  nomadic --profile work config set home_currency USD
+  nomadic --profile work trip add --name "Berlin offsite" --destination Berlin
+  NOMADIC_PROFILE=work nomadic expense report --trip berlin`,
+		Args: cobra.NoArgs,
+		// Profiles are managed without opening any journal.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listProfiles(cmd, a)
+		},
+	}
+	var dataDir string
+	add := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Add a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := a.loadBaseConfig()
+			if err != nil {
+				return err
+			}
+			if err := cfg.AddProfile(args[0]); err != nil {
+				return err
+			}
+			if dataDir != "" {
+				if err := cfg.SetProfile(args[0], "data_dir", dataDir); err != nil {
+					return err
+				}
+			}
+			if err := config.Save(a.configPath, cfg); err != nil {
+				return err
+			}
+			if a.json() {
+				p, err := apiProfile(cfg, args[0], a.profileName())
+				if err != nil {
+					return err
+				}
+				return printJSON(cmd, p)
+			}
+			return nil
+		},
+	}
+	add.Flags().StringVar(&dataDir, "data-dir", "", "directory holding the profile's journal (default $XDG_DATA_HOME/nomadic-<name>)")
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "list",
+			Short: "Show every profile and where it keeps its journal",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return listProfiles(cmd, a)
+			},
+		},
+		add,
+		&cobra.Command{
+			Use:   "remove <name>",
+			Short: "Remove a profile's settings, leaving its journal where it is",
+			Args:  cobra.ExactArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				cfg, err := a.loadBaseConfig()
+				if err != nil {
+					return err
+				}
+				if err := cfg.RemoveProfile(args[0]); err != nil {
+					return err
+				}
+				return config.Save(a.configPath, cfg)
+			},
+		},
+	)
+	return cmd
+}
+
+func listProfiles(cmd *cobra.Command, a *app) error {
+	cfg, err := a.loadBaseConfig()
+	if err != nil {
+		return err
+	}
+	current := a.profileName()
+	var profiles []api.Profile
+	for _, name := range cfg.ProfileNames() {
+		p, err := apiProfile(cfg, name, current)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, p)
+	}
+	if a.json() {
+		return printJSON(cmd, profiles)
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\tPROFILE\tDATA DIR\tOVERRIDES")
+	for _, p := range profiles {
+		mark := ""
+		if p.Current {
+			mark = "*"
+		}
+		overrides := make([]string, 0, len(p.Settings))
+		for _, s := range p.Settings {
+			if s.Name != "data_dir" {
+				overrides = append(overrides, s.Name+"="+s.Value)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mark, p.Name, p.DataDir, strings.Join(overrides, ", "))
+	}
+	return w.Flush()
+}
+
+// apiProfile describes the named profile of cfg, current when it is the
+// one chosen.
+func apiProfile(cfg config.Config, name, current string) (api.Profile, error) {
+	p := api.Profile{Name: name, Current: name == current}
+	settings, err := cfg.Profile(name)
+	if err != nil {
+		return p, err
+	}
+	if p.DataDir, err = profileDir(name, settings); err != nil {
+		return p, err
+	}
+	names := make([]string, 0, len(cfg.Profiles[name]))
+	for setting := range cfg.Profiles[name] {
+		names = append(names, setting)
+	}
+	sort.Strings(names)
+	for _, setting := range names {
+		p.Settings = append(p.Settings, api.Setting{Name: setting, Value: cfg.Profiles[name][setting]})
+	}
+	return p, nil
+}
+
+// profileName is the profile chosen, by --profile or $NOMADIC_PROFILE, or
+// the default one.
+func (a *app) profileName() string {
+	name := a.profile
+	if name == "" {
+		name = os.Getenv(profileEnv)
+	}
+	if name == "" {
+		return config.DefaultProfile
+	}
+	return name
+}
+
+// profileDir is where the named profile keeps its journal with the
+// settings cfg: in their data_dir, or else in the default data directory
+// of the default profile and beside it for the others.
+func profileDir(name string, cfg config.Config) (string, error) {
+	if cfg.DataDir != "" {
+		return cfg.DataDir, nil
+	}
+	if name == "" || name == config.DefaultProfile {
+		return storage.DefaultDir()
+	}
+	return storage.ProfileDir(name)
+}
+
+// saveConfig saves the settings cfg, changed from a.cfg. With a profile
+// chosen only the settings changed are saved, as overrides of the profile.
+func (a *app) saveConfig(cfg config.Config) error {
+	profile := a.profileName()
+	if profile == config.DefaultProfile {
+		return config.Save(a.configPath, cfg)
+	}
+	base, err := a.loadBaseConfig()
+	if err != nil {
+		return err
+	}
+	for _, name := range cfg.Names() {
+		v, err := cfg.Get(name)
+		if err != nil {
+			return err
+		}
+		if old, _ := a.cfg.Get(name); v != old {
+			if err := base.SetProfile(profile, name, v); err != nil {
+				return err
+			}
+		}
+	}
+	return config.Save(a.configPath, base)
+}
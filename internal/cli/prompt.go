@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// interactive reports whether nomadic can ask for what a command was not
+// given rather than fail for it: it reads from a terminal and writes its
+// questions to one. Scripts, pipes and the shell's completion are never
+// asked anything.
+func interactive() bool {
+	return stdinIsTerminal() && term.IsTerminal(int(os.Stderr.Fd()))
+}
+
+// ask puts question on the terminal and returns the answer typed, trimmed.
+func ask(question string) (string, error) {
+	fmt.Fprint(os.Stderr, question+" ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		fmt.Fprintln(os.Stderr)
+		return "", fmt.Errorf("read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// askValue asks for what the flag of usage takes, until something is
+// typed.
+func askValue(flag, usage string) (string, error) {
+	for {
+		v, err := ask(fmt.Sprintf("%s (--%s):", capitalize(usage), flag))
+		if err != nil || v != "" {
+			return v, err
+		}
+	}
+}
+
+// askRequired asks at a terminal for the flags of cmd marked required and
+// not given, before cobra would fail for them. Each is asked again until
+// the answer is one the flag takes.
+func askRequired(cmd *cobra.Command) error {
+	if !interactive() {
+		return nil
+	}
+	var missing []*pflag.Flag
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Changed && slices.Contains(f.Annotations[cobra.BashCompOneRequiredFlag], "true") {
+			missing = append(missing, f)
+		}
+	})
+	for _, f := range missing {
+		for {
+			v, err := askValue(f.Name, f.Usage)
+			if err != nil {
+				return err
+			}
+			if err := cmd.Flags().Set(f.Name, v); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				continue
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// pickTrip asks at the terminal which of trips is meant, by its number in
+// the list or by its title.
+func pickTrip(trips []*models.Trip) (*models.Trip, error) {
+	for i, t := range trips {
+		dates := t.StartDate.Format(models.DateLayout)
+		if t.EndDate != nil {
+			dates += " → " + t.EndDate.Format(models.DateLayout)
+		}
+		fmt.Fprintf(os.Stderr, "%3d  %s  %s\n", i+1, t.Title, dates)
+	}
+	for {
+		answer, err := ask(fmt.Sprintf("Which trip? [1-%d]", len(trips)))
+		if err != nil {
+			return nil, err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(trips) {
+			return trips[n-1], nil
+		}
+		needle := strings.ToLower(answer)
+		var matches []*models.Trip
+		for _, t := range trips {
+			if strings.ToLower(t.Title) == needle {
+				return t, nil
+			}
+			if needle != "" && tripMatches(t, needle) {
+				matches = append(matches, t)
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0], nil
+		}
+	}
+}
+
+// capitalize upper-cases the first letter of s.
+func capitalize(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[n:]
+}
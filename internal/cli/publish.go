@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/publish"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newPublishCmd(a *app) *cobra.Command {
+	var (
+		dir, title, templates, cname string
+		redact                       string
+		trips                        []string
+	)
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Build a static website of the trips and entries marked public",
+		Long: `Build a travel blog out of what is marked public: trips with
+` + "`nomadic trip public`" + `, with all their entries, and single journal entries
+with ` + "`nomadic journal public`" + `. The site has an index of trips by year and by
+country, a page for each trip and each entry with its Markdown rendered,
+and galleries of the photos attached. Expenses and the people traveling
+along are never published. Neither are the entries marked private with
+` + "`nomadic journal privacy`" + `, nor the private passages of the rest, fenced
+by "::: private" and ":::" lines, unless --redact says otherwise: mask
+leaves a mark where they were, and none publishes them.
+
+The site is written to --dir, whose index.html, style.css and trips and
+media directories are replaced; anything else, such as a .git directory,
+is left alone. Pages link to each other relatively, and a .nojekyll file
+is included, so the directory can be pushed as is to a GitHub Pages
+repository or opened from disk. --cname writes the CNAME file of a custom
+domain.
+
+The title of the site is the site_title setting unless --title is given.
+--templates names a directory of files replacing the built-in ones of the
+same name: layout.html wraps every page around the "content" template
+defined by index.html, trip.html or entry.html, and style.css is the style
+sheet. Templates are Go html/template templates.`,
+		Example: `  nomadic publish
+  nomadic publish --dir ~/src/me.github.io --cname travels.example.com
+  nomadic publish --trip "Japan 2025" --title "Japan" --templates ~/blog/templates`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			var selected []*models.Trip
+			for _, ref := range splitList(trips) {
+				t, err := resolveTrip(ctx, a.store, ref)
+				if err != nil {
+					return err
+				}
+				selected = append(selected, t)
+			}
+			if len(trips) == 0 {
+				var err error
+				if selected, err = a.store.ListTrips(ctx); err != nil {
+					return err
+				}
+			}
+			if title == "" {
+				title = a.cfg.SiteTitle
+			}
+			r, err := models.ParseRedaction(redact)
+			if err != nil {
+				return fmt.Errorf("--redact: %w", err)
+			}
+			res, err := publish.Write(ctx, dir, a.store, selected, publish.Options{
+				Title:      title,
+				Templates:  templates,
+				CNAME:      strings.TrimSpace(cname),
+				DateLayout: a.cfg.Layout(),
+				Redact:     r,
+			})
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Site{Dir: dir, Trips: res.Trips, Entries: res.Entries, Photos: res.Photos,
+					Missing: orEmpty(res.Missing)})
+			}
+			out := cmd.OutOrStdout()
+			for _, name := range res.Missing {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Photo %s left out: its file is missing\n", name)
+			}
+			if res.Trips == 0 {
+				fmt.Fprintf(out, "Nothing is public yet; wrote an empty site to %s\n", dir)
+				fmt.Fprintln(out, "Mark trips or entries public with nomadic trip public or nomadic journal public.")
+				return nil
+			}
+			fmt.Fprintf(out, "Published %d trips, %d entries and %d photos to %s\n", res.Trips, res.Entries, res.Photos, dir)
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVarP(&dir, "dir", "d", "site", "directory to write the site to")
+	f.StringVar(&title, "title", "", "title of the site (default: the site_title setting)")
+	f.StringSliceVar(&trips, "trip", nil, "publish only these trips; repeat or separate with commas (default: every trip)")
+	f.StringVar(&templates, "templates", "", "directory of templates replacing the built-in ones")
+	f.StringVar(&redact, "redact", string(models.RedactStrip), "strip, mask or none: what becomes of what is private")
+	f.StringVar(&cname, "cname", "", "custom domain of the GitHub Pages site, written to CNAME")
+	return cmd
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/quick"
+)
+
+func newQuickCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "quick <note>",
+		Short: "Jot a one-line note down in today's journal entry",
+		Long: `Append a note, stamped with the time of day, to today's journal entry of
+the trip in progress, or start the day's entry with it when there is none
+yet, without opening an editor. The words of the note need no quotes.`,
+		Example: `  nomadic quick "amazing ramen at Ichiran"
+  nomadic quick --trip lisbon missed the last tram, walked up to Graça`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			note := strings.TrimSpace(strings.Join(args, " "))
+			if note == "" {
+				return errors.New("empty note, nothing saved")
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			e, created, err := quick.Capture(ctx, a.store, t, note, time.Now(), a.cfg.Layout())
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiEntry(e))
+			}
+			if created {
+				fmt.Fprintf(cmd.OutOrStdout(), "Started %q on %q\n", e.Title, t.Title)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "Added to %q on %q\n", e.Title, t.Title)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
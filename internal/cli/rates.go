@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+// lockRate locks in the rate into the home currency that x's currency had
+// on its day. x is left as it is when the rate cannot be had.
+func (a *app) lockRate(ctx context.Context, x *models.Expense) error {
+	home := a.cfg.HomeCurrency
+	if x.Locked(home) {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	rate, err := currency.RateOn(ctx, a.rates(), x.Currency, home, x.Timestamp)
+	if err != nil {
+		return err
+	}
+	x.Rate, x.RateCurrency = rate, home
+	return nil
+}
+
+func newExpenseRatesCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "rates",
+		Short: "Lock in the exchange rates of expenses' days",
+		Long: `Lock in the exchange rate into home_currency that each expense's currency
+had on the day it was spent, so that totals are converted at what the money
+was worth then rather than at today's rates.
+
+Expenses recorded with nomadic expense add or in the TUI have their rate
+locked in as they are saved, when it can be had; this fetches the rates of
+the others, such as those recorded offline, imported, or recorded before
+home_currency changed. Rates are those the European Central Bank published
+on the day, or on the working day before.`,
+		Example: `  nomadic expense rates
+  nomadic expense rates --trip tokyo --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			var (
+				expenses []*models.Expense
+				err      error
+			)
+			if trip != "" {
+				var t *models.Trip
+				if t, err = resolveTrip(ctx, a.store, trip); err != nil {
+					return err
+				}
+				expenses, err = a.store.ListExpensesByTrip(ctx, t.ID)
+			} else {
+				expenses, err = a.store.ListExpenses(ctx)
+			}
+			if err != nil {
+				return err
+			}
+			home := a.cfg.HomeCurrency
+			out := api.RateLocking{Currency: home, Locked: []api.Expense{}, Failed: []api.RateFailed{}}
+			// Expenses in one currency on one day share a rate, fetched once.
+			type fetched struct {
+				rate float64
+				err  error
+			}
+			rates := map[string]fetched{}
+			for _, x := range expenses {
+				if x.Locked(home) {
+					continue
+				}
+				key := x.Currency + "@" + x.Timestamp.Format(currency.DayLayout)
+				f, ok := rates[key]
+				if !ok {
+					ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+					f.rate, f.err = currency.RateOn(ctx, a.rates(), x.Currency, home, x.Timestamp)
+					cancel()
+					rates[key] = f
+				}
+				if f.err != nil {
+					out.Failed = append(out.Failed, api.RateFailed{Expense: apiExpense(x), Error: f.err.Error()})
+					continue
+				}
+				x.Rate, x.RateCurrency = f.rate, home
+				if err := a.store.SaveExpense(ctx, x); err != nil {
+					return err
+				}
+				out.Locked = append(out.Locked, apiExpense(x))
+			}
+			if a.json() {
+				return printJSON(cmd, out)
+			}
+			w := cmd.OutOrStdout()
+			if len(out.Locked) == 0 && len(out.Failed) == 0 {
+				fmt.Fprintf(w, "Every expense has its rate into %s locked in\n", home)
+				return nil
+			}
+			if len(out.Locked) > 0 {
+				fmt.Fprintf(w, "Locked in the rate into %s of %s\n", home, plural(len(out.Locked), "expense", "expenses"))
+			}
+			for _, f := range out.Failed {
+				fmt.Fprintf(w, "  %s %.2f %s %q: %s\n", f.Expense.Date, f.Expense.Amount, f.Expense.Currency,
+					f.Expense.Description, f.Error)
+			}
+			if len(out.Failed) > 0 {
+				fmt.Fprintf(w, "The rates of %s could not be had; run this again to retry\n", plural(len(out.Failed), "expense", "expenses"))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: every trip)")
+	return cmd
+}
@@ -0,0 +1,349 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/quick"
+)
+
+func newExpenseReceiptCmd(a *app) *cobra.Command {
+	var (
+		trip, currency, merchant, category string
+		description, date, language, onDup string
+		amount                             float64
+		yes                                bool
+	)
+	cmd := &cobra.Command{
+		Use:   "receipt <image>",
+		Short: "Record an expense from a photo of its receipt",
+		Long: `Read a photo or scan of a receipt with OCR and record the expense it is
+for, with the receipt kept as a document of the trip.
+
+The amount, currency and merchant read from the receipt, with its day and
+a category guessed from the merchant, are shown for you to record, correct
+field by field or cancel. Flags given win over what is read; --yes records
+without asking, which is needed when standard input is not a terminal.
+
+The ocr setting picks the OCR backend: tesseract (the default) runs
+Tesseract on this machine, as ocr_command; api uploads the image to
+ocr_url, an OCR.space-compatible API, with the key in $NOMADIC_OCR_KEY.
+ocr_language, or --language, is the language the receipt is printed in,
+such as eng or por.`,
+		Example: `  nomadic expense receipt --trip lisbon ~/Pictures/receipt.jpg
+  nomadic expense receipt --language jpn --currency JPY ramen.png
+  nomadic expense receipt --yes --category food --amount 23.40 scan.pdf`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			image := args[0]
+			if _, err := os.Stat(image); err != nil {
+				return err
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			if !yes && !stdinIsTerminal() {
+				return errors.New("pass --yes to record the expense read from the receipt, with flags correcting what was misread")
+			}
+			reader, err := a.ocrReader()
+			if err != nil {
+				return err
+			}
+			if language == "" {
+				language = a.cfg.OCRLanguage
+			}
+			rules, err := a.store.ListRules(ctx)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.ErrOrStderr(), "Reading the receipt…")
+			readCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+			text, err := reader.Read(readCtx, image, language)
+			cancel()
+			if err != nil {
+				return err
+			}
+			r := quick.ReadReceipt(text, a.cfg.Layout(), rules)
+			d := receiptDraft{Amount: r.Amount, Currency: r.Currency, Merchant: r.Merchant, Category: r.Category}
+			if !r.Day.IsZero() {
+				d.Date = r.Day.Format(models.DateLayout)
+			}
+			f := cmd.Flags()
+			if f.Changed("amount") {
+				d.Amount = amount
+			}
+			if currency != "" {
+				d.Currency = currency
+			}
+			if d.Currency == "" {
+				d.Currency = a.cfg.DefaultCurrency
+			}
+			if merchant != "" {
+				d.Merchant = merchant
+			}
+			if category != "" {
+				d.Category = category
+			}
+			if d.Category == "" {
+				d.Category = models.CategoryOther
+			}
+			if date != "" {
+				d.Date = date
+			}
+			d.Description = description
+			if !yes {
+				if err := a.confirmReceipt(cmd, &d, text); err != nil {
+					return err
+				}
+			}
+
+			x, err := d.expense(t.ID)
+			if err != nil {
+				return err
+			}
+			at, err := a.tripMoment(ctx, t, d.Date, "", "")
+			if err != nil {
+				return err
+			}
+			x.Timestamp, x.TimeZone = at.ts, at.zone
+			if at.leg != nil {
+				x.LegID = at.leg.ID
+			}
+			dup, err := a.duplicateOf(cmd, x, onDup)
+			if err != nil {
+				return err
+			}
+			var locking error
+			if dup != nil {
+				models.MergeExpense(dup, x)
+				x = dup
+			} else {
+				// Recording offline leaves the rate for nomadic expense rates.
+				locking = a.lockRate(ctx, x)
+			}
+			if err := a.store.SaveExpense(ctx, x); err != nil {
+				return err
+			}
+			doc, err := a.store.AttachReceipt(ctx, x, image)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiExpense(x))
+			}
+			verb := "Recorded"
+			if dup != nil {
+				verb = "Merged into"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %.2f %s %q of %s on %q, with its receipt %s\n", verb, x.Amount, x.Currency,
+				x.Description, a.formatDate(x.Timestamp), t.Title, doc.Name)
+			if locking != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Its exchange rate is not locked in (%v); nomadic expense rates locks it later\n", locking)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", tripFlagUsage)
+	f.Float64Var(&amount, "amount", 0, "amount spent (default: the total read from the receipt)")
+	f.StringVar(&currency, "currency", "", "three-letter currency code (default: read from the receipt, or from config)")
+	f.StringVar(&merchant, "merchant", "", "where the money was spent (default: read from the receipt)")
+	f.StringVar(&category, "category", "", categoryUsage+" (default: guessed from the merchant)")
+	f.StringVar(&description, "description", "", "what it was for (default: the merchant)")
+	f.StringVar(&date, "date", "", "date, YYYY-MM-DD (default: read from the receipt, or today)")
+	f.StringVar(&language, "language", "", "language of the receipt, such as eng or por (default: ocr_language)")
+	f.BoolVarP(&yes, "yes", "y", false, "record what was read without asking")
+	f.StringVar(&onDup, "on-duplicate", duplicateAsk, "when it looks like an expense already recorded: ask, merge into it, or keep both")
+	return cmd
+}
+
+// receiptDraft is the expense read from a receipt, awaiting confirmation.
+type receiptDraft struct {
+	Amount      float64
+	Currency    string
+	Merchant    string
+	Category    string
+	Description string
+	// Date is empty for today.
+	Date string
+}
+
+// expense returns the expense of d on trip tripID, dated later.
+func (d receiptDraft) expense(tripID string) (*models.Expense, error) {
+	if d.Amount <= 0 {
+		return nil, errors.New("no total was read from the receipt; pass --amount")
+	}
+	cur, err := models.ParseCurrency(d.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("currency: %w", err)
+	}
+	cat, err := models.ParseCategory(d.Category)
+	if err != nil {
+		return nil, fmt.Errorf("category: %w", err)
+	}
+	description := strings.TrimSpace(d.Description)
+	if description == "" {
+		description = d.Merchant
+	}
+	if description == "" {
+		description = cat
+	}
+	x := models.NewExpense(tripID, d.Amount, cur, cat, description, time.Time{})
+	x.Merchant = d.Merchant
+	return x, nil
+}
+
+// confirmReceipt shows the draft read from a receipt and asks to record
+// it, correct it field by field, or cancel. A receipt whose total was not
+// read goes straight to correcting.
+func (a *app) confirmReceipt(cmd *cobra.Command, d *receiptDraft, text string) error {
+	errw := cmd.ErrOrStderr()
+	if strings.TrimSpace(text) == "" {
+		fmt.Fprintln(errw, "No text was read from the receipt.")
+	}
+	in := bufio.NewReader(cmd.InOrStdin())
+	edit := d.Amount <= 0
+	for {
+		if edit {
+			if err := a.editReceipt(in, errw, d); err != nil {
+				return err
+			}
+		}
+		printReceipt(errw, d)
+		fmt.Fprint(errw, "Record it, edit it or cancel? [y/e/c] ")
+		line, err := readLine(in)
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(line) {
+		case "y", "yes", "r", "record":
+			if d.Amount <= 0 {
+				fmt.Fprintln(errw, "The amount must be greater than zero.")
+				edit = true
+				continue
+			}
+			return nil
+		case "e", "edit":
+			edit = true
+		case "c", "cancel", "n", "no":
+			return errors.New("cancelled; nothing was recorded")
+		default:
+			edit = false
+		}
+	}
+}
+
+func printReceipt(w io.Writer, d *receiptDraft) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	amount := "?"
+	if d.Amount > 0 {
+		amount = strconv.FormatFloat(d.Amount, 'f', 2, 64)
+	}
+	fmt.Fprintf(tw, "  Amount\t%s %s\n", amount, d.Currency)
+	merchant := d.Merchant
+	if merchant == "" {
+		merchant = "-"
+	}
+	fmt.Fprintf(tw, "  Merchant\t%s\n", merchant)
+	fmt.Fprintf(tw, "  Category\t%s\n", d.Category)
+	date := d.Date
+	if date == "" {
+		date = "today"
+	}
+	fmt.Fprintf(tw, "  Date\t%s\n", date)
+	if d.Description != "" {
+		fmt.Fprintf(tw, "  Description\t%s\n", d.Description)
+	}
+	tw.Flush()
+}
+
+// editReceipt asks for each field of d in turn, Enter keeping what it is.
+func (a *app) editReceipt(in *bufio.Reader, w io.Writer, d *receiptDraft) error {
+	ask := func(label, value string) (string, error) {
+		fmt.Fprintf(w, "%s [%s]: ", label, value)
+		line, err := readLine(in)
+		if err != nil || line == "" {
+			return value, err
+		}
+		return line, nil
+	}
+	for {
+		amount := ""
+		if d.Amount > 0 {
+			amount = strconv.FormatFloat(d.Amount, 'f', -1, 64)
+		}
+		v, err := ask("Amount", amount)
+		if err != nil {
+			return err
+		}
+		if n, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", "."), 64); err == nil && n > 0 {
+			d.Amount = n
+			break
+		}
+		fmt.Fprintln(w, "The amount must be a number greater than zero.")
+	}
+	for {
+		v, err := ask("Currency", d.Currency)
+		if err != nil {
+			return err
+		}
+		if cur, err := models.ParseCurrency(v); err == nil {
+			d.Currency = cur
+			break
+		}
+		fmt.Fprintln(w, "The currency must be a three-letter code such as EUR.")
+	}
+	var err error
+	if d.Merchant, err = ask("Merchant", d.Merchant); err != nil {
+		return err
+	}
+	for {
+		v, err := ask("Category", d.Category)
+		if err != nil {
+			return err
+		}
+		if cat, err := models.ParseCategory(v); err == nil {
+			d.Category = cat
+			break
+		}
+		fmt.Fprintf(w, "The category must be one of %s.\n", strings.Join(models.Categories, ", "))
+	}
+	for {
+		v, err := ask("Date", d.Date)
+		if err != nil {
+			return err
+		}
+		if _, err := a.parseDay("date", v); v == "" || err == nil {
+			d.Date = v
+			return nil
+		}
+		form := "YYYY-MM-DD"
+		if a.cfg.DateFormat != form {
+			form += " or " + a.cfg.DateFormat
+		}
+		fmt.Fprintf(w, "The date must be %s.\n", form)
+	}
+}
+
+// readLine reads a line of an answer without its line ending.
+func readLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", fmt.Errorf("read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/reconcile"
+)
+
+func newExpenseReconcileCmd(a *app) *cobra.Command {
+	var (
+		trip     string
+		from, to string
+		total    float64
+		currency string
+		mapping  []string
+		charges  string
+	)
+	cmd := &cobra.Command{
+		Use:   "reconcile [statement]",
+		Short: "Compare the expenses logged with a bank or card statement",
+		Long: `Compare the expenses logged over a stretch of days with a bank or credit
+card statement, exported as OFX, QFX or CSV as for nomadic expense
+statement, to find what was never logged.
+
+Each charge is matched with the expense logged for the same amount within
+three days of it; an expense in another currency matches when converted
+it comes within 3%. The charges left are listed as not logged, and the
+expenses left as not on the statement, such as those paid in cash.
+Expenses a companion paid are left out.
+
+The days are those the statement covers unless --from and --to say. Give
+--total, the total printed on the statement, to compare with it rather
+than with the charges read; without a statement file it is all there is
+to compare with.`,
+		Example: `  nomadic expense reconcile ~/Downloads/march.ofx
+  nomadic expense reconcile card.csv --trip lisbon --charges positive
+  nomadic expense reconcile --from 2026-03-01 --to 2026-03-31 --total 1234.50 --currency EUR`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if len(args) == 0 && total <= 0 {
+				return errors.New("give a statement file, or its total with --total")
+			}
+			opts := reconcile.Options{Total: total}
+			var err error
+			if from != "" {
+				if opts.From, err = a.parseDay("--from", from); err != nil {
+					return err
+				}
+			}
+			if to != "" {
+				if opts.To, err = a.parseDay("--to", to); err != nil {
+					return err
+				}
+			}
+			if currency != "" {
+				if currency, err = models.ParseCurrency(currency); err != nil {
+					return fmt.Errorf("--currency: %w", err)
+				}
+			}
+
+			var expenses []*models.Expense
+			if trip != "" {
+				t, err := resolveTrip(ctx, a.store, trip)
+				if err != nil {
+					return err
+				}
+				if len(args) == 0 && opts.From.IsZero() {
+					opts.From = t.StartDate
+				}
+				if len(args) == 0 && opts.To.IsZero() {
+					opts.To = time.Now()
+					if t.EndDate != nil {
+						opts.To = *t.EndDate
+					}
+				}
+				expenses, err = a.store.ListExpensesByTrip(ctx, t.ID)
+				if err != nil {
+					return err
+				}
+			} else if expenses, err = a.store.ListExpenses(ctx); err != nil {
+				return err
+			}
+			if len(args) == 0 && (opts.From.IsZero() || opts.To.IsZero()) {
+				return errors.New("without a statement file, give the days it covers with --from and --to, or --trip")
+			}
+
+			var chargeList []*models.Expense
+			if len(args) == 1 {
+				if chargeList, err = a.readCharges(args[0], mapping, charges, currency); err != nil {
+					return err
+				}
+			}
+			switch {
+			case currency != "":
+				opts.Currency = currency
+			case len(chargeList) > 0:
+				opts.Currency = chargeList[0].Currency
+			default:
+				opts.Currency = a.cfg.HomeCurrency
+			}
+			opts.Convert = a.converter(ctx, opts.Currency)
+			r := reconcile.Compute(chargeList, expenses, opts)
+			if a.json() {
+				return printJSON(cmd, apiReconciliation(r))
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%s to %s, in %s\n\n", a.formatDate(r.From), a.formatDate(r.To), r.Currency)
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+			fmt.Fprintf(w, "Statement\t%.2f\t\n", r.Statement)
+			fmt.Fprintf(w, "Logged\t%.2f\t\n", r.Logged)
+			fmt.Fprintf(w, "Gap\t%.2f\t\n", r.Gap())
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if len(r.Missing) > 0 {
+				fmt.Fprintf(out, "\nOn the statement, not logged (%d):\n", len(r.Missing))
+				a.printReconciled(out, r.Missing)
+			}
+			if len(r.Unbilled) > 0 {
+				fmt.Fprintf(out, "\nLogged, not on the statement (%d):\n", len(r.Unbilled))
+				a.printReconciled(out, r.Unbilled)
+			}
+			fmt.Fprintln(out)
+			if r.Unconverted > 0 {
+				fmt.Fprintf(out, "%d expenses left out of the total, with no exchange rate into %s\n", r.Unconverted, r.Currency)
+			}
+			if len(chargeList) > 0 {
+				fmt.Fprintf(out, "%d charges matched\n", len(r.Matched))
+			}
+			if r.Balanced() {
+				fmt.Fprintln(out, "Everything is accounted for")
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "compare only this trip's expenses, over its dates when no statement is given")
+	f.StringVar(&from, "from", "", "first day compared, YYYY-MM-DD (default: the statement's first charge)")
+	f.StringVar(&to, "to", "", "last day compared, YYYY-MM-DD (default: the statement's last charge)")
+	f.Float64Var(&total, "total", 0, "the total printed on the statement")
+	f.StringVar(&currency, "currency", "", "the statement's currency (default: its charges', else home_currency)")
+	f.StringArrayVar(&mapping, "map", nil, "read a field from a named CSV column, as field=Column")
+	f.StringVar(&charges, "charges", "auto", "sign of charges in the file: auto, negative or positive")
+	return cmd
+}
+
+// readCharges reads the charges of the statement at path, as nomadic
+// expense statement does, in currency when the file names none.
+func (a *app) readCharges(path string, mapping []string, charges, currency string) ([]*models.Expense, error) {
+	sign, ok := chargeSigns[charges]
+	if !ok {
+		return nil, errors.New("--charges must be auto, negative or positive")
+	}
+	m, err := export.ParseMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+	if currency == "" {
+		currency = a.cfg.DefaultCurrency
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := export.ParseStatement(f, export.StatementOptions{
+		Mapping:         m,
+		DateLayouts:     []string{a.cfg.Layout()},
+		DefaultCurrency: currency,
+		Charges:         sign,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out []*models.Expense
+	for _, row := range st.Rows {
+		if row.Err != nil {
+			return nil, fmt.Errorf("line %d: %w", row.Line, row.Err)
+		}
+		out = append(out, row.Expense)
+	}
+	return out, nil
+}
+
+// converter converts into currency with cached exchange rates, or is nil
+// when there are none.
+func (a *app) converter(ctx context.Context, currency string) reconcile.Converter {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if rates, err := a.rates().Latest(ctx, currency); err == nil {
+		return rates.Convert
+	}
+	return nil
+}
+
+// printReconciled lists expenses or charges left over by a reconciliation.
+func (a *app) printReconciled(out io.Writer, expenses []*models.Expense) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, x := range expenses {
+		fmt.Fprintf(w, "  %s\t%.2f %s\t%s\n", a.formatDate(x.Timestamp), x.Amount, x.Currency, x.Description)
+	}
+	w.Flush()
+}
@@ -0,0 +1,341 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/recurring"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+// recordRecurring records the recurring expenses that fell due since
+// nomadic last ran, noting each on stderr so the output of the command run
+// stays as it is.
+func (a *app) recordRecurring(ctx context.Context) error {
+	recorded, err := recurring.Record(ctx, a.store, time.Now())
+	for _, x := range recorded {
+		fmt.Fprintf(os.Stderr, "Recorded %s: %.2f %s on %s\n", x.Description, x.Amount, x.Currency, a.formatDate(x.Timestamp))
+	}
+	return err
+}
+
+func newExpenseRecurringCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recurring",
+		Short: "Manage expenses that repeat, such as insurance or an eSIM plan",
+		Long: `A recurring expense is recorded by itself each day it falls due, daily,
+weekly, monthly or yearly from its start until its end, if any, on the trip
+it is for or, without --trip, on whichever trip is in progress then. Days
+that fall due with no trip in progress, or while it is paused, are skipped.
+
+Expenses fall due as nomadic runs: the first command or TUI session on or
+after a day records it, back to the last time nomadic ran.`,
+	}
+	cmd.AddCommand(newRecurringAddCmd(a), newRecurringListCmd(a), newRecurringEditCmd(a),
+		newRecurringPauseCmd(a, true), newRecurringPauseCmd(a, false), newRecurringDeleteCmd(a))
+	return cmd
+}
+
+// recurrenceFlags are the flags adding and editing a recurrence share.
+type recurrenceFlags struct {
+	trip, currency, category, interval, start, end string
+	amount                                         float64
+}
+
+func (rf *recurrenceFlags) register(cmd *cobra.Command, adding bool) {
+	f := cmd.Flags()
+	trip := "trip ID, title or destination the expenses go to; \"\" for the trip in progress"
+	if adding {
+		trip = "trip ID, title or destination the expenses go to (default: the trip in progress when due)"
+	}
+	f.StringVar(&rf.trip, "trip", "", trip)
+	f.Float64Var(&rf.amount, "amount", 0, "amount of each expense")
+	f.StringVar(&rf.currency, "currency", "", "three-letter currency code, e.g. EUR (default from config)")
+	f.StringVar(&rf.category, "category", models.CategoryOther, categoryUsage)
+	f.StringVar(&rf.interval, "every", models.IntervalMonthly, "how often it falls due: "+strings.Join(models.Intervals, ", "))
+	f.StringVar(&rf.start, "start", "", "first day it falls due, YYYY-MM-DD (default today)")
+	f.StringVar(&rf.end, "end", "", "last day it may fall due, YYYY-MM-DD (default: never ends)")
+}
+
+// apply sets the fields of r whose flags are given, all of them when
+// changed is nil.
+func (rf *recurrenceFlags) apply(ctx context.Context, a *app, r *models.Recurrence, changed func(string) bool) error {
+	set := func(name string) bool { return changed == nil || changed(name) }
+	if set("amount") {
+		if rf.amount <= 0 {
+			return errors.New("--amount must be greater than zero")
+		}
+		r.Amount = rf.amount
+	}
+	if set("currency") {
+		if rf.currency == "" {
+			rf.currency = a.cfg.DefaultCurrency
+		}
+		cur, err := models.ParseCurrency(rf.currency)
+		if err != nil {
+			return fmt.Errorf("--currency: %w", err)
+		}
+		r.Currency = cur
+	}
+	if set("category") {
+		cat, err := models.ParseCategory(rf.category)
+		if err != nil {
+			return fmt.Errorf("--category: %w", err)
+		}
+		r.Category = cat
+	}
+	if set("every") {
+		interval, err := models.ParseInterval(rf.interval)
+		if err != nil {
+			return fmt.Errorf("--every: %w", err)
+		}
+		r.Interval = interval
+	}
+	if set("start") {
+		r.Start = today()
+		if rf.start != "" {
+			d, err := a.parseDay("--start", rf.start)
+			if err != nil {
+				return err
+			}
+			r.Start = d
+		}
+	}
+	if set("end") {
+		r.End = nil
+		if rf.end != "" {
+			d, err := a.parseDay("--end", rf.end)
+			if err != nil {
+				return err
+			}
+			r.End = &d
+		}
+	}
+	if r.End != nil && r.End.Before(r.Start) {
+		return errors.New("--end must not be before --start")
+	}
+	if set("trip") {
+		r.TripID = ""
+		if rf.trip != "" {
+			t, err := resolveTrip(ctx, a.store, rf.trip)
+			if err != nil {
+				return err
+			}
+			r.TripID = t.ID
+		}
+	}
+	return nil
+}
+
+// today returns the local calendar day at midnight, as dates are parsed.
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func newRecurringAddCmd(a *app) *cobra.Command {
+	var rf recurrenceFlags
+	cmd := &cobra.Command{
+		Use:   "add <description>",
+		Short: "Add a recurring expense",
+		Long: `Add a recurring expense. A start in the past records the days it fell due
+since, on the trips in progress then.`,
+		Example: `  nomadic expense recurring add --amount 15 --currency EUR --every monthly eSIM
+  nomadic expense recurring add --trip japan --amount 4.20 --every daily --start 2025-04-01 "Travel insurance"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			r := models.NewRecurrence(0, "", "", args[0], "", time.Time{})
+			if r.Description == "" {
+				return errors.New("describe the expense")
+			}
+			if err := rf.apply(ctx, a, r, nil); err != nil {
+				return err
+			}
+			r.Next = r.From(r.Start)
+			if err := a.store.SaveRecurrence(ctx, r); err != nil {
+				return err
+			}
+			// A start in the past falls due right away.
+			if err := a.recordRecurring(ctx); err != nil {
+				return err
+			}
+			r, err := a.store.GetRecurrence(ctx, r.ID)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiRecurrence(r))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %.2f %s %s from %s\n", r.Description, r.Amount, r.Currency, r.Interval,
+				a.formatDate(r.Start))
+			return nil
+		},
+	}
+	rf.register(cmd, true)
+	return cmd
+}
+
+func newRecurringListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the recurring expenses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			recurrences, err := a.store.ListRecurrences(ctx)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.Recurrence, len(recurrences))
+				for i, r := range recurrences {
+					out[i] = apiRecurrence(r)
+				}
+				return printJSON(cmd, out)
+			}
+			trips := map[string]string{"": "(in progress)"}
+			if ts, err := a.store.ListTrips(ctx); err == nil {
+				for _, t := range ts {
+					trips[t.ID] = t.Title
+				}
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tDESCRIPTION\tAMOUNT\tEVERY\tTRIP\tNEXT")
+			for _, r := range recurrences {
+				fmt.Fprintf(w, "%s\t%s\t%.2f %s\t%s\t%s\t%s\n", r.ID, r.Description, r.Amount, r.Currency, r.Interval,
+					trips[r.TripID], a.recurrenceNext(r))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// recurrenceNext describes when r next falls due.
+func (a *app) recurrenceNext(r *models.Recurrence) string {
+	switch {
+	case r.Paused:
+		return "paused"
+	case r.Ended():
+		return "ended"
+	}
+	return a.formatDate(r.Next)
+}
+
+func newRecurringEditCmd(a *app) *cobra.Command {
+	var (
+		rf          recurrenceFlags
+		description string
+	)
+	cmd := &cobra.Command{
+		Use:   "edit <recurring expense>",
+		Short: "Change a recurring expense, by ID or description",
+		Long: `Change what the given flags set. The expenses it already recorded are left
+as they are.`,
+		Example: `  nomadic expense recurring edit eSIM --amount 20
+  nomadic expense recurring edit insurance --end 2025-04-14`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			r, err := resolveRecurrence(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := rf.apply(ctx, a, r, cmd.Flags().Changed); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("description") {
+				if r.Description = strings.TrimSpace(description); r.Description == "" {
+					return errors.New("--description must not be empty")
+				}
+			}
+			r.Reschedule(today())
+			if err := a.store.SaveRecurrence(ctx, r); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiRecurrence(r))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved %s; next due: %s\n", r.Description, a.recurrenceNext(r))
+			return nil
+		},
+	}
+	rf.register(cmd, false)
+	cmd.Flags().StringVar(&description, "description", "", "new description")
+	return cmd
+}
+
+func newRecurringPauseCmd(a *app, pause bool) *cobra.Command {
+	use, short := "resume", "Let a paused recurring expense fall due again from today"
+	if pause {
+		use, short = "pause", "Stop recording a recurring expense until resumed"
+	}
+	return &cobra.Command{
+		Use:   use + " <recurring expense>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			r, err := resolveRecurrence(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if pause {
+				r.Paused = true
+			} else {
+				r.Resume(today())
+			}
+			if err := a.store.SaveRecurrence(ctx, r); err != nil {
+				return err
+			}
+			if !pause {
+				if err := a.recordRecurring(ctx); err != nil {
+					return err
+				}
+				if r, err = a.store.GetRecurrence(ctx, r.ID); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, apiRecurrence(r))
+			}
+			if pause {
+				fmt.Fprintf(cmd.OutOrStdout(), "Paused %s\n", r.Description)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Resumed %s; next due: %s\n", r.Description, a.recurrenceNext(r))
+			return nil
+		},
+	}
+}
+
+func newRecurringDeleteCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <recurring expense>",
+		Short: "Delete a recurring expense, keeping the expenses it recorded",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			r, err := resolveRecurrence(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteRecurrence(ctx, r.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "recurrence", ID: r.ID, Name: r.Description})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted the recurring expense %q\n", r.Description)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/notify"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/streak"
+)
+
+func newRemindCmd(a *app) *cobra.Command {
+	var desktop bool
+	cmd := &cobra.Command{
+		Use:   "remind",
+		Short: "Remind you to write today's journal entry",
+		Long: `Remind you to write today's journal entry while a trip is in progress,
+with the journaling streak it keeps going: the consecutive days of the trip
+with at least one entry.
+
+Nothing is printed when today's entry is written or no trip is in
+progress, so the command fits in a shell startup file. With --notify the
+reminder is shown as a desktop notification instead, for example from cron.`,
+		Example: `  echo 'nomadic remind' >> ~/.bashrc
+  0 20 * * * nomadic remind --notify`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := journalStreak(cmd.Context(), a.store, time.Now())
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiStreak(s))
+			}
+			if !s.Due() {
+				return nil
+			}
+			if desktop {
+				return notify.Send("nomadic", reminder(s))
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), reminder(s)+" Run `nomadic journal new`.")
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&desktop, "notify", false, "show a desktop notification instead of printing")
+	return cmd
+}
+
+// journalStreak computes the journaling streak of the trip in progress at
+// now, on the trip's day.
+func journalStreak(ctx context.Context, store *storage.Store, now time.Time) (streak.Streak, error) {
+	trips, err := store.ListTrips(ctx)
+	if err != nil {
+		return streak.Streak{}, err
+	}
+	trip := streak.Active(trips, now)
+	var entries []*models.Entry
+	if trip != nil {
+		if entries, err = store.ListEntryTimes(ctx, storage.EntryQuery{TripID: trip.ID}); err != nil {
+			return streak.Streak{}, err
+		}
+		legs, err := store.ListLegsByTrip(ctx, trip.ID)
+		if err != nil {
+			return streak.Streak{}, err
+		}
+		now = models.InZone(now, places.TripZone(trip, legs, "", now))
+	}
+	return streak.Compute(trip, entries, now), nil
+}
+
+// reminder asks for today's entry of a streak that is due.
+func reminder(s streak.Streak) string {
+	if s.Current > 0 {
+		return fmt.Sprintf("Write today's entry for %q to keep your %d-day streak.", s.Trip.Title, s.Current)
+	}
+	return fmt.Sprintf("No journal entry yet today for %q.", s.Trip.Title)
+}
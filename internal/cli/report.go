@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/budget"
+	"github.com/girdharshubham/nomadic/internal/chart"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/report"
+	"github.com/girdharshubham/nomadic/internal/stats"
+)
+
+func newExpenseReportCmd(a *app) *cobra.Command {
+	var (
+		trip, by, format, file string
+		tags                   []string
+		perDiem                bool
+	)
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Total a trip's expenses by category, day or leg",
+		Long: `Total a trip's expenses by category, day or leg in home_currency, with the
+share of each and a bar chart. Expenses in other currencies are converted
+with cached exchange rates. --format csv or markdown writes the report for
+a spreadsheet or a document instead.
+
+--per-diem reports each day of the trip against its daily allowance
+instead, set with nomadic trip budget --per-diem, in the budget currency:
+what was spent, how far under or over the allowance the day was, and the
+balance of the trip so far.`,
+		Example: `  nomadic expense report --trip lisbon
+  nomadic expense report --trip lisbon --by day --format markdown --file lisbon.md
+  nomadic expense report --trip berlin --per-diem --format csv --file berlin.csv`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			grouping, err := report.ParseGrouping(by)
+			if err != nil {
+				return fmt.Errorf("--by: %w", err)
+			}
+			switch format {
+			case "text", "csv", "markdown":
+			default:
+				return fmt.Errorf("--format: unknown format %q; use text, csv or markdown", format)
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			expenses, err := a.store.ListExpensesByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			expenses = slices.DeleteFunc(expenses, func(x *models.Expense) bool { return !models.HasTags(x.Tags, tags) })
+			legs, err := a.store.ListLegsByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			cur := a.cfg.HomeCurrency
+			if perDiem {
+				if t.PerDiem == 0 {
+					return fmt.Errorf("%q has no per diem; set one with nomadic trip budget --per-diem", t.Title)
+				}
+				if cur = t.BudgetCurrency; cur == "" {
+					cur = a.cfg.HomeCurrency
+				}
+			}
+			var convert report.Converter
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			if rates, err := a.rates().Latest(ctx, cur); err == nil {
+				convert = rates.Convert
+			}
+
+			var (
+				r report.Report
+				p budget.PerDiem
+			)
+			if perDiem {
+				at, err := a.tripMoment(ctx, t, "", "", "")
+				if err != nil {
+					return err
+				}
+				p = budget.ComputePerDiem(t, expenses, cur, budget.Converter(convert), at.ts)
+			} else {
+				r = report.Compute(expenses, legs, grouping, cur, convert)
+			}
+			if a.json() {
+				if perDiem {
+					return printJSON(cmd, apiPerDiem(t, p))
+				}
+				return printJSON(cmd, apiExpenseReport(t, r))
+			}
+
+			var w io.Writer = cmd.OutOrStdout()
+			if file != "" && file != "-" {
+				f, err := os.Create(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			switch {
+			case perDiem && format == "csv":
+				return p.WriteCSV(w)
+			case perDiem && format == "markdown":
+				return p.WriteMarkdown(w, t.Title+" per diem", a.cfg.Layout())
+			case perDiem:
+				return printPerDiem(w, a, p)
+			case format == "csv":
+				return r.WriteCSV(w)
+			case format == "markdown":
+				return r.WriteMarkdown(w, t.Title+" expenses")
+			}
+			return printExpenseReport(w, a, r)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.StringVar(&by, "by", "category", "what to total by: category, day or leg")
+	f.StringVar(&format, "format", "text", "text, csv or markdown")
+	f.StringVar(&file, "file", "", "write to this file instead of standard output")
+	f.StringSliceVar(&tags, "tag", nil, "only expenses with this tag; repeat to require several")
+	f.BoolVar(&perDiem, "per-diem", false, "report each day against the trip's per diem")
+	return cmd
+}
+
+// printExpenseReport writes the report as a table with a bar for each
+// group.
+func printExpenseReport(out io.Writer, a *app, r report.Report) error {
+	if r.Count == 0 && r.Unconverted == 0 {
+		_, err := fmt.Fprintln(out, "No expenses to report on")
+		return err
+	}
+	var peak float64
+	for _, row := range r.Rows {
+		peak = max(peak, row.Total)
+	}
+	if first, days := r.Daily(); len(days) > 1 {
+		h := chart.Histogram{
+			Values: days,
+			Format: func(v float64) string { return fmt.Sprintf("%.0f %s", v, r.Currency) },
+			First:  a.formatDate(first),
+			Last:   a.formatDate(first.AddDate(0, 0, len(days)-1)),
+		}
+		fmt.Fprintln(out, strings.Join(h.Lines(), "\n")+"\n")
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\tTOTAL\tSHARE\tEXPENSES\t\n", strings.ToUpper(r.By.String()))
+	for _, row := range r.Rows {
+		label := row.Label
+		if r.By == report.ByDay {
+			label = a.formatDate(row.Day)
+		}
+		fmt.Fprintf(w, "%s\t%.2f %s\t%.1f%%\t%d\t%s\n", label, row.Total, r.Currency,
+			row.Share(r.Total)*100, row.Count, chart.Bar(row.Total, peak, 30))
+	}
+	fmt.Fprintf(w, "Total\t%.2f %s\t\t%d\t\n", r.Total, r.Currency, r.Count)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if r.Unconverted > 0 {
+		fmt.Fprintf(out, "%d expenses in other currencies could not be converted and are not counted\n", r.Unconverted)
+	}
+	return nil
+}
+
+// printPerDiem writes the per diem as a table of the trip's days, each
+// under or over its allowance and the balance so far.
+func printPerDiem(out io.Writer, a *app, p budget.PerDiem) error {
+	if len(p.Days) == 0 {
+		_, err := fmt.Fprintln(out, "The trip has not started yet")
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATE\tSPENT\tDAY\tSO FAR")
+	for _, d := range p.Days {
+		fmt.Fprintf(w, "%s\t%.2f %s\t%s\t%s\n", a.formatDate(d.Date), d.Spent, p.Currency,
+			budget.Standing(d.Balance(), p.Currency), budget.Standing(d.Cumulative, p.Currency))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Per diem of %.2f %s a day; %s after %s\n", p.Allowance, p.Currency,
+		budget.Standing(p.Balance(), p.Currency), plural(len(p.Days), "day", "days"))
+	if p.Outside > 0 {
+		fmt.Fprintf(out, "%s dated outside the trip's days not counted\n", plural(p.Outside, "expense", "expenses"))
+	}
+	if p.Unconverted > 0 {
+		fmt.Fprintf(out, "%d expenses in other currencies could not be converted and are not counted\n", p.Unconverted)
+	}
+	return nil
+}
+
+func newReportCmd(a *app) *cobra.Command {
+	var period string
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Summarize spending across every trip over a year or month",
+		Long: `Summarize spending across every trip over a period in home_currency: the
+total against the same period a year before, each month with its change
+on the year before, the top categories and the trips spent on. The period
+is a year (2024), a month (2024-03) or all, which totals each year too; it
+defaults to this year. Expenses in other currencies are converted with
+cached exchange rates.
+
+For one trip's expenses by category, day or leg, see nomadic expense report.`,
+		Example: `  nomadic report
+  nomadic report --period 2024
+  nomadic report --period 2024-03
+  nomadic report --period all --output json | jq .years`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			p := stats.Year(time.Now().Year())
+			if period != "" {
+				var err error
+				if p, err = stats.ParsePeriod(period); err != nil {
+					return fmt.Errorf("--period: %w", err)
+				}
+			}
+			trips, err := a.store.ListTrips(ctx)
+			if err != nil {
+				return err
+			}
+			expenses, err := a.store.ListExpenses(ctx)
+			if err != nil {
+				return err
+			}
+			home := a.cfg.HomeCurrency
+			var convert stats.Converter
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+			if rates, err := a.rates().Latest(ctx, home); err == nil {
+				convert = rates.Convert
+			}
+			s := stats.ComputeSpending(trips, expenses, p, home, convert)
+			if a.json() {
+				return printJSON(cmd, apiSpending(s))
+			}
+			return printSpending(cmd.OutOrStdout(), s)
+		},
+	}
+	cmd.Flags().StringVar(&period, "period", "", "a year (2024), a month (2024-03) or all (default this year)")
+	return cmd
+}
+
+// printSpending writes the summary of a period: its total against the
+// year before, then tables of the months, the years, the categories and
+// the trips.
+func printSpending(out io.Writer, s stats.Spending) error {
+	money := func(v float64) string { return fmt.Sprintf("%.2f %s", v, s.Currency) }
+	if s.Count == 0 && s.Unconverted == 0 {
+		if s.Period == stats.AllTime {
+			_, err := fmt.Fprintln(out, "Nothing spent yet")
+			return err
+		}
+		_, err := fmt.Fprintf(out, "Nothing spent in %s\n", s.Period)
+		return err
+	}
+	when := "in " + s.Period.String()
+	if s.Period == stats.AllTime {
+		when = "altogether"
+	}
+	fmt.Fprintf(out, "Spent %s: %s over %s", when, money(s.Total), plural(s.Count, "expense", "expenses"))
+	if s.Period != stats.AllTime {
+		if c := s.FormatChange(); c != "" {
+			fmt.Fprintf(out, ", %s on %s (%s)", c, s.Period.YearBefore(), money(s.Previous))
+		} else {
+			fmt.Fprintf(out, ", nothing in %s", s.Period.YearBefore())
+		}
+	}
+	fmt.Fprintln(out)
+	if s.Unconverted > 0 {
+		fmt.Fprintf(out, "%d expenses in other currencies could not be converted and are not counted\n", s.Unconverted)
+	}
+
+	periods := func(title string, rows []stats.PeriodSpend) {
+		if len(rows) < 2 {
+			return
+		}
+		var peak float64
+		for _, r := range rows {
+			peak = max(peak, r.Total)
+		}
+		fmt.Fprintf(out, "\n%s\n", title)
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, r := range rows {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", r.Label, money(r.Total), r.FormatChange(), chart.Bar(r.Total, peak, 30))
+		}
+		w.Flush()
+	}
+	amounts := func(title string, rows []stats.Amount) {
+		if len(rows) == 0 {
+			return
+		}
+		fmt.Fprintf(out, "\n%s\n", title)
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, r := range rows {
+			fmt.Fprintf(w, "  %s\t%s\t%.1f%%\n", r.Label, money(r.Value), report.Row{Total: r.Value}.Share(s.Total)*100)
+		}
+		w.Flush()
+	}
+	periods("By year", s.Years)
+	if s.Period != stats.AllTime && s.Period.Month == 0 {
+		// A year shows its months side by side too, January to December.
+		totals := make([]float64, len(s.Months))
+		for i, m := range s.Months {
+			totals[i] = m.Total
+		}
+		h := chart.Histogram{Values: totals, Height: 5, Width: 3, First: "Jan", Last: "Dec",
+			Format: func(v float64) string { return fmt.Sprintf("%.0f %s", v, s.Currency) }}
+		fmt.Fprintf(out, "\nMonth by month\n  %s\n", strings.Join(h.Lines(), "\n  "))
+	}
+	periods("By month", s.Months)
+	amounts("Top categories", s.Categories)
+	amounts("By trip", s.Trips)
+	return nil
+}
@@ -0,0 +1,661 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// resolveTrip finds the trip a --trip flag refers to. ref may be a trip
+// ID, its title, or part of its title or a destination, compared without
+// case. An empty ref picks the only trip, or else the one in progress
+// today, the most recently started when several overlap. At a terminal,
+// a ref matching several trips, or none given when no trip is in
+// progress, asks which one is meant.
+func resolveTrip(ctx context.Context, store *storage.Store, ref string) (*models.Trip, error) {
+	trips, err := store.ListTrips(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(trips) == 0 {
+		return nil, fmt.Errorf("no trips yet; create one with `nomadic trip add`")
+	}
+
+	if ref == "" {
+		if len(trips) == 1 {
+			return trips[0], nil
+		}
+		if t := models.TripOn(trips, time.Now()); t != nil {
+			return t, nil
+		}
+		if interactive() {
+			return pickTrip(trips)
+		}
+		return nil, fmt.Errorf("several trips exist and none is in progress; choose one with --trip")
+	}
+
+	needle := strings.ToLower(ref)
+	for _, t := range trips {
+		if t.ID == ref || strings.ToLower(t.Title) == needle {
+			return t, nil
+		}
+	}
+	var matches []*models.Trip
+	for _, t := range trips {
+		if tripMatches(t, needle) {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no trip matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	if interactive() {
+		return pickTrip(matches)
+	}
+	names := make([]string, len(matches))
+	for i, t := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", t.Title, t.ID)
+	}
+	return nil, fmt.Errorf("%q matches several trips: %s", ref, strings.Join(names, ", "))
+}
+
+// resolveEntry picks an entry of the trip named by tripRef by its ID, its
+// title, or a unique part of its title. IDs are accepted from any trip.
+func resolveEntry(ctx context.Context, store *storage.Store, tripRef, ref string) (*models.Entry, error) {
+	if e, err := store.GetEntry(ctx, ref); err == nil {
+		return e, nil
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	t, err := resolveTrip(ctx, store, tripRef)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := store.ListEntriesByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.Entry
+	for _, e := range entries {
+		if strings.ToLower(e.Title) == needle {
+			return e, nil
+		}
+		if strings.Contains(strings.ToLower(e.Title), needle) {
+			matches = append(matches, e)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no entry in %q matches %q", t.Title, ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, e := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", e.Title, e.ID)
+	}
+	return nil, fmt.Errorf("%q matches several entries: %s", ref, strings.Join(names, ", "))
+}
+
+// resolveCheckIn finds a check-in by its ID, or else among the check-ins of
+// the trip tripRef names by its place or a unique part of it; the latest
+// check-in at a place when there are several.
+func resolveCheckIn(ctx context.Context, store *storage.Store, tripRef, ref string) (*models.CheckIn, error) {
+	if c, err := store.GetCheckIn(ctx, ref); err == nil {
+		return c, nil
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	t, err := resolveTrip(ctx, store, tripRef)
+	if err != nil {
+		return nil, err
+	}
+	checkIns, err := store.ListCheckInsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var (
+		exact   *models.CheckIn
+		seen    []string // the places matching
+		matches []*models.CheckIn
+	)
+	for _, c := range checkIns {
+		place := strings.ToLower(c.Place)
+		if place == needle {
+			exact = c
+		}
+		if strings.Contains(place, needle) {
+			if !slices.Contains(seen, place) {
+				seen = append(seen, place)
+			}
+			matches = append(matches, c)
+		}
+	}
+	if exact != nil {
+		return exact, nil
+	}
+	switch len(seen) {
+	case 0:
+		return nil, fmt.Errorf("no check-in in %q matches %q", t.Title, ref)
+	case 1:
+		return matches[len(matches)-1], nil
+	}
+	names := make([]string, len(matches))
+	for i, c := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", c.Place, c.ID)
+	}
+	return nil, fmt.Errorf("%q matches several check-ins: %s", ref, strings.Join(names, ", "))
+}
+
+// resolveExpense finds an expense by its ID, or else among the expenses of
+// the trip tripRef names by its description or a unique part of it.
+func resolveExpense(ctx context.Context, store *storage.Store, tripRef, ref string) (*models.Expense, error) {
+	if x, err := store.GetExpense(ctx, ref); err == nil {
+		return x, nil
+	} else if !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+	t, err := resolveTrip(ctx, store, tripRef)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := store.ListExpensesByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.Expense
+	for _, x := range expenses {
+		if strings.ToLower(x.Description) == needle {
+			return x, nil
+		}
+		if strings.Contains(strings.ToLower(x.Description), needle) {
+			matches = append(matches, x)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no expense in %q matches %q", t.Title, ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, x := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", x.Description, x.ID)
+	}
+	return nil, fmt.Errorf("%q matches several expenses: %s", ref, strings.Join(names, ", "))
+}
+
+// resolveLeg picks a leg of trip t by its ID, its location, or a unique
+// part of its location, compared without case.
+func resolveLeg(ctx context.Context, store *storage.Store, t *models.Trip, ref string) (*models.Leg, error) {
+	legs, err := store.ListLegsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.Leg
+	for _, l := range legs {
+		if l.ID == ref || strings.ToLower(l.Location) == needle {
+			return l, nil
+		}
+		if strings.Contains(strings.ToLower(l.Location), needle) {
+			matches = append(matches, l)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no leg of %q matches %q", t.Title, ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, l := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", l.Location, l.ID)
+	}
+	return nil, fmt.Errorf("%q matches several legs: %s", ref, strings.Join(names, ", "))
+}
+
+// moment is when and where something of a trip happened, such as a
+// journal entry written or money spent.
+type moment struct {
+	ts   time.Time // in zone
+	zone string    // IANA name, empty for the local zone
+	// leg is the leg of the trip, nil when the trip has none then.
+	leg *models.Leg
+}
+
+// tripMoment settles the moment of something of trip t from the --date,
+// --leg and --location flags. It is the day of date at the time of day
+// where the trip is, or now; the leg named by legRef, or else the one
+// visited that day; and the time zone of location, or else of the leg or
+// the trip's destinations. Today is thus the trip's, however far it is
+// from home.
+func (a *app) tripMoment(ctx context.Context, t *models.Trip, date, legRef, location string) (moment, error) {
+	var m moment
+	legs, err := a.store.ListLegsByTrip(ctx, t.ID)
+	if err != nil {
+		return m, err
+	}
+	if legRef != "" {
+		if m.leg, err = resolveLeg(ctx, a.store, t, legRef); err != nil {
+			return m, err
+		}
+	}
+	at := location
+	if at == "" && m.leg != nil {
+		at = m.leg.Location
+	}
+	if date == "" {
+		m.zone = places.TripZone(t, legs, at, time.Now())
+		m.ts = models.InZone(time.Now(), m.zone)
+	} else {
+		d, err := a.parseDay("--date", date)
+		if err != nil {
+			return m, err
+		}
+		m.zone = places.DayZone(t, legs, at, d)
+		// Stamp the current time of day so entries logged the same day
+		// keep their order.
+		now := models.InZone(time.Now(), m.zone)
+		m.ts = time.Date(d.Year(), d.Month(), d.Day(), now.Hour(), now.Minute(), now.Second(), 0, now.Location())
+	}
+	if m.leg == nil {
+		m.leg = models.LegOn(legs, m.ts)
+	}
+	return m, nil
+}
+
+// resolveTemplate finds a template by its ID, its name, or a unique part
+// of its name, compared without case.
+func resolveTemplate(ctx context.Context, store *storage.Store, ref string) (*models.Template, error) {
+	templates, err := store.ListTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.Template
+	for _, t := range templates {
+		if t.ID == ref || strings.ToLower(t.Name) == needle {
+			return t, nil
+		}
+		if strings.Contains(strings.ToLower(t.Name), needle) {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no template matches %q; save one with `nomadic template save`", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, t := range matches {
+		names[i] = t.Name
+	}
+	return nil, fmt.Errorf("%q matches several templates: %s", ref, strings.Join(names, ", "))
+}
+
+// resolveWish finds a wish by its ID or place.
+func resolveWish(ctx context.Context, store *storage.Store, ref string) (*models.Wish, error) {
+	wishes, err := store.ListWishes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.Wish
+	for _, w := range wishes {
+		if w.ID == ref || strings.ToLower(w.Place) == needle {
+			return w, nil
+		}
+		if strings.Contains(strings.ToLower(w.Place), needle) {
+			matches = append(matches, w)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no wish matches %q; add one with `nomadic wish add`", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, w := range matches {
+		names[i] = w.Place
+	}
+	return nil, fmt.Errorf("%q matches several wishes: %s", ref, strings.Join(names, ", "))
+}
+
+// resolveVaultItem finds a travel document in the vault by its ID, its
+// number or its title, or a unique part of its title.
+func resolveVaultItem(ctx context.Context, store *storage.Store, ref string) (*models.VaultItem, error) {
+	items, err := store.ListVault(ctx)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.VaultItem
+	for _, it := range items {
+		if it.ID == ref || strings.EqualFold(it.Number, ref) || strings.ToLower(it.Title) == needle {
+			return it, nil
+		}
+		if strings.Contains(strings.ToLower(it.Title), needle) {
+			matches = append(matches, it)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no document in the vault matches %q; add one with `nomadic vault add`", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, it := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", it.Title, it.ID)
+	}
+	return nil, fmt.Errorf("%q matches several documents in the vault: %s", ref, strings.Join(names, ", "))
+}
+
+// resolveRule finds a categorization rule by its ID or the text it
+// matches.
+func resolveRule(ctx context.Context, store *storage.Store, ref string) (*models.Rule, error) {
+	rules, err := store.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(strings.TrimSpace(ref))
+	for _, r := range rules {
+		if r.ID == ref || r.Match == needle {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("no rule matches %q; list them with `nomadic expense rule list`", ref)
+}
+
+// resolveCategory finds an expense category by its name or a unique
+// prefix of it.
+func resolveCategory(ctx context.Context, store *storage.Store, ref string) (*models.Category, error) {
+	name, err := models.ParseCategory(ref)
+	if err != nil {
+		return nil, err
+	}
+	return store.GetCategoryByName(ctx, name)
+}
+
+// resolveRecurrence finds a recurring expense by its ID, its description,
+// or a unique part of its description, compared without case.
+func resolveRecurrence(ctx context.Context, store *storage.Store, ref string) (*models.Recurrence, error) {
+	recurrences, err := store.ListRecurrences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.Recurrence
+	for _, r := range recurrences {
+		if r.ID == ref || strings.ToLower(r.Description) == needle {
+			return r, nil
+		}
+		if strings.Contains(strings.ToLower(r.Description), needle) {
+			matches = append(matches, r)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no recurring expense matches %q; list them with `nomadic expense recurring list`", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, r := range matches {
+		names[i] = r.Description
+	}
+	return nil, fmt.Errorf("%q matches several recurring expenses: %s", ref, strings.Join(names, ", "))
+}
+
+// resolvePackingList finds a master packing list by its ID, its name, or a
+// unique part of its name, compared without case.
+func resolvePackingList(ctx context.Context, store *storage.Store, ref string) (*models.PackingList, error) {
+	lists, err := store.ListPackingLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.PackingList
+	for _, l := range lists {
+		if l.ID == ref || strings.ToLower(l.Name) == needle {
+			return l, nil
+		}
+		if strings.Contains(strings.ToLower(l.Name), needle) {
+			matches = append(matches, l)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no packing list matches %q; save one with `nomadic pack save-list`", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, l := range matches {
+		names[i] = l.Name
+	}
+	return nil, fmt.Errorf("%q matches several packing lists: %s", ref, strings.Join(names, ", "))
+}
+
+// resolvePackingItem picks an item of a trip's packing list by its ID, its
+// name, or a unique part of its name.
+func resolvePackingItem(items []*models.PackingItem, ref string) (*models.PackingItem, error) {
+	needle := strings.ToLower(ref)
+	var matches []*models.PackingItem
+	for _, it := range items {
+		if it.ID == ref || strings.ToLower(it.Name) == needle {
+			return it, nil
+		}
+		if strings.Contains(strings.ToLower(it.Name), needle) {
+			matches = append(matches, it)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("nothing on the packing list matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, it := range matches {
+		names[i] = it.Name
+	}
+	return nil, fmt.Errorf("%q matches several items: %s", ref, strings.Join(names, ", "))
+}
+
+// resolveHighlight picks a goal or highlight of a trip by its ID, its
+// title, or a unique part of its title.
+func resolveHighlight(highlights []*models.Highlight, ref string) (*models.Highlight, error) {
+	needle := strings.ToLower(ref)
+	var matches []*models.Highlight
+	for _, h := range highlights {
+		if h.ID == ref || strings.ToLower(h.Title) == needle {
+			return h, nil
+		}
+		if strings.Contains(strings.ToLower(h.Title), needle) {
+			matches = append(matches, h)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no goal or highlight matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	titles := make([]string, len(matches))
+	for i, h := range matches {
+		titles[i] = h.Title
+	}
+	return nil, fmt.Errorf("%q matches several highlights: %s", ref, strings.Join(titles, ", "))
+}
+
+// resolvePrepTask picks a task of a trip's checklist by its ID, its
+// title, or a unique part of its title.
+func resolvePrepTask(tasks []*models.PrepTask, ref string) (*models.PrepTask, error) {
+	needle := strings.ToLower(ref)
+	var matches []*models.PrepTask
+	for _, p := range tasks {
+		if p.ID == ref || strings.ToLower(p.Title) == needle {
+			return p, nil
+		}
+		if strings.Contains(strings.ToLower(p.Title), needle) {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no task matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	titles := make([]string, len(matches))
+	for i, p := range matches {
+		titles[i] = p.Title
+	}
+	return nil, fmt.Errorf("%q matches several tasks: %s", ref, strings.Join(titles, ", "))
+}
+
+// resolveLodging picks a lodging of a trip by its ID, its name, or a
+// unique part of its name.
+func resolveLodging(lodgings []*models.Lodging, ref string) (*models.Lodging, error) {
+	needle := strings.ToLower(ref)
+	var matches []*models.Lodging
+	for _, l := range lodgings {
+		if l.ID == ref || strings.ToLower(l.Name) == needle {
+			return l, nil
+		}
+		if strings.Contains(strings.ToLower(l.Name), needle) {
+			matches = append(matches, l)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no lodging matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, l := range matches {
+		names[i] = fmt.Sprintf("%s (%s)", l.Name, l.ID)
+	}
+	return nil, fmt.Errorf("%q matches several lodgings: %s", ref, strings.Join(names, ", "))
+}
+
+func tripMatches(t *models.Trip, needle string) bool {
+	if strings.Contains(strings.ToLower(t.Title), needle) {
+		return true
+	}
+	for _, l := range t.Locations {
+		if strings.Contains(strings.ToLower(l), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDay parses a date flag given as YYYY-MM-DD or in the configured
+// date format.
+func (a *app) parseDay(flag, v string) (time.Time, error) {
+	d, err := time.ParseInLocation(models.DateLayout, v, time.Local)
+	if err == nil {
+		return d, nil
+	}
+	if d, err = time.ParseInLocation(a.cfg.Layout(), v, time.Local); err == nil {
+		return d, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q: use YYYY-MM-DD or %s", flag, v, a.cfg.DateFormat)
+}
+
+// formatDate renders t in the configured date format.
+func (a *app) formatDate(t time.Time) string {
+	return t.Format(a.cfg.Layout())
+}
+
+// splitList splits comma separated values, dropping empty items.
+func splitList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// resolveTrashed finds something in the trash by its ID or title, or part
+// of its title, compared without case.
+func resolveTrashed(ctx context.Context, store *storage.Store, ref string) (*models.Trashed, error) {
+	trash, err := store.ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(ref)
+	var matches []*models.Trashed
+	for _, t := range trash {
+		if t.ID == ref || strings.ToLower(t.Title) == needle {
+			return t, nil
+		}
+		if strings.Contains(strings.ToLower(t.Title), needle) {
+			matches = append(matches, t)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("nothing in the trash matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, t := range matches {
+		names[i] = fmt.Sprintf("%s %q (%s)", t.Kind, t.Title, t.ID)
+	}
+	return nil, fmt.Errorf("%q matches several things in the trash: %s", ref, strings.Join(names, ", "))
+}
+
+// resolvePerson finds a person by ID or name, or by a unique part of their
+// name.
+func resolvePerson(ctx context.Context, store *storage.Store, ref string) (*models.Person, error) {
+	people, err := store.ListPeople(ctx)
+	if err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(strings.TrimSpace(ref))
+	var matches []*models.Person
+	for _, p := range people {
+		if p.ID == ref || strings.ToLower(p.Name) == needle {
+			return p, nil
+		}
+		if strings.Contains(strings.ToLower(p.Name), needle) {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("nobody matches %q; add them with `nomadic people add`", ref)
+	case 1:
+		return matches[0], nil
+	}
+	names := make([]string, len(matches))
+	for i, p := range matches {
+		names[i] = p.Name
+	}
+	return nil, fmt.Errorf("%q matches several people: %s", ref, strings.Join(names, ", "))
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/textdiff"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newJournalHistoryCmd(a *app) *cobra.Command {
+	var (
+		trip string
+		diff bool
+	)
+	cmd := &cobra.Command{
+		Use:   "history <entry>",
+		Short: "List the earlier versions of a journal entry",
+		Long: `List the earlier versions of a journal entry, kept each time it is edited,
+the most recent first, with how many lines each inserted and deleted from
+the version before it. With --diff each is followed by what it changed,
+after what the last edit changed.
+
+Bring an entry back to an earlier version with nomadic journal revert.`,
+		Example: `  nomadic journal history Tsukiji
+  nomadic journal history --diff Tsukiji`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			e, err := resolveEntry(ctx, a.store, trip, args[0])
+			if err != nil {
+				return err
+			}
+			revisions, err := a.store.ListRevisions(ctx, e.ID)
+			if err != nil {
+				return err
+			}
+			// Each version is compared with the one saved before it, and
+			// the entry as it is now with the most recent.
+			versions := append([]*models.Revision{models.CurrentRevision(e)}, revisions...)
+			diffs := make([][]textdiff.Line, len(versions))
+			for i, v := range versions {
+				var old *models.Revision
+				if i+1 < len(versions) {
+					old = versions[i+1]
+				}
+				diffs[i] = textdiff.Lines(old.Document(), v.Document())
+			}
+			if a.json() {
+				out := make([]api.Revision, len(revisions))
+				for i, r := range revisions {
+					inserted, deleted := textdiff.Stat(diffs[i+1])
+					out[i] = apiRevision(r, inserted, deleted)
+				}
+				return printJSON(cmd, out)
+			}
+			w := cmd.OutOrStdout()
+			if len(revisions) == 0 {
+				fmt.Fprintf(w, "%q has not been edited since it was written\n", e.Title)
+				return nil
+			}
+			if diff {
+				for i, v := range versions {
+					id := v.ID
+					if i == 0 {
+						id = "now"
+					}
+					fmt.Fprintf(w, "%s  %s  %s\n", id, a.formatDate(v.SavedAt)+" "+v.SavedAt.Format("15:04"), v.Title)
+					printDiff(w, diffs[i])
+					fmt.Fprintln(w)
+				}
+				return nil
+			}
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "ID\tSAVED\tCHANGES\tTITLE")
+			for i, r := range revisions {
+				inserted, deleted := textdiff.Stat(diffs[i+1])
+				fmt.Fprintf(tw, "%s\t%s\t+%d -%d\t%s\n", r.ID, a.formatDate(r.SavedAt)+" "+r.SavedAt.Format("15:04"),
+					inserted, deleted, r.Title)
+			}
+			return tw.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	cmd.Flags().BoolVar(&diff, "diff", false, "show what each version changed")
+	return cmd
+}
+
+// printDiff writes the changes of a diff with two lines of context,
+// inserted lines marked + and deleted ones -.
+func printDiff(w io.Writer, diff []textdiff.Line) {
+	hunks := textdiff.Hunks(diff, 2)
+	if len(hunks) == 0 {
+		fmt.Fprintln(w, "  (no changes)")
+	}
+	for i, hunk := range hunks {
+		if i > 0 {
+			fmt.Fprintln(w, "  ...")
+		}
+		for _, l := range hunk {
+			mark := " "
+			switch l.Op {
+			case textdiff.Insert:
+				mark = "+"
+			case textdiff.Delete:
+				mark = "-"
+			}
+			fmt.Fprintf(w, "%s %s\n", mark, l.Text)
+		}
+	}
+}
+
+func newJournalRevertCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revert <revision>",
+		Short: "Bring a journal entry back to an earlier version",
+		Long: `Bring a journal entry back to an earlier version, by the ID nomadic journal
+history lists it with. The version it replaces is kept in the history in
+turn, so reverting can be undone by reverting again.`,
+		Example: `  nomadic journal revert 3f9a1c2e7b4d8a60`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			r, err := a.store.GetRevision(ctx, args[0])
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("no revision %q; nomadic journal history lists them", args[0])
+			} else if err != nil {
+				return err
+			}
+			e, err := a.store.RestoreRevision(ctx, r.ID)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiEntry(e))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Reverted %q to its version of %s\n", e.Title,
+				a.formatDate(r.SavedAt)+" "+r.SavedAt.Format("15:04"))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,590 @@
+// Package cli implements the nomadic command line. Run without a
+// subcommand it starts the interactive TUI.
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/hooks"
+	"github.com/girdharshubham/nomadic/internal/logging"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/ui"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+	"github.com/girdharshubham/nomadic/pkg/geocode"
+	"github.com/girdharshubham/nomadic/pkg/holidays"
+	"github.com/girdharshubham/nomadic/pkg/httpclient"
+	"github.com/girdharshubham/nomadic/pkg/ocr"
+	"github.com/girdharshubham/nomadic/pkg/offsite"
+	"github.com/girdharshubham/nomadic/pkg/transcribe"
+	"github.com/girdharshubham/nomadic/pkg/weather"
+)
+
+// app carries state shared by every command.
+type app struct {
+	configPath string
+	dataDir    string // resolved by open
+	output     outputFormat
+	// profile is the profile chosen by --profile or $NOMADIC_PROFILE,
+	// empty for the default one.
+	profile string
+	// switchTo is the profile chosen in the TUI to start it again on.
+	switchTo string
+
+	cfg   config.Config
+	store *storage.Store
+	// firstRun is set when the TUI starts without a data directory, for
+	// the first-run wizard to make one.
+	firstRun bool
+	// tui is set when nomadic runs the TUI rather than a command.
+	tui bool
+	// demo is set by nomadic demo, whose made-up journal is kept apart
+	// from the user's own.
+	demo bool
+	// readOnly is set by --read-only, turning the read_only setting on.
+	readOnly bool
+	// offline is set by --offline, turning the offline setting on.
+	offline bool
+	// http sends the requests of every integration, made on first use.
+	http *httpclient.Transport
+	// debug is set by --debug, for Debug records in the log.
+	debug bool
+	// log is where slog writes, once the data directory is known.
+	log *logging.Logger
+	// hooks runs the hooks of the config on what is saved to store.
+	hooks *hooks.Runner
+	// notesSync is what bringing in the journal's Markdown notes did, when
+	// the store keeps them.
+	notesSync *storage.NotesSync
+}
+
+// Execute runs the command line with os.Args.
+func Execute() error {
+	a := &app{}
+	err := newRootCmd(a).Execute()
+	if storage.Unavailable(err) && a.dataDir != "" {
+		err = fmt.Errorf("%w; check that the disk has room left and that no other program holds %s", err, a.dataDir)
+	}
+	// Before the log starts slog writes to standard error, which has the
+	// error already.
+	if err != nil && a.log != nil {
+		slog.Error("command failed", "err", err)
+	}
+	return err
+}
+
+func newRootCmd(a *app) *cobra.Command {
+	var accessible, ascii bool
+	root := &cobra.Command{
+		Use:   "nomadic",
+		Short: "Nomadic – your travel journal companion",
+		Long: `Nomadic keeps your trips, journal entries and expenses in one place.
+
+Run it without a subcommand to open the interactive interface, or use the
+subcommands below to script it from the shell. With --output json commands
+print JSON documents, described by the schemas in package
+github.com/girdharshubham/nomadic/pkg/api, for jq and other tools.`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if completing(cmd) {
+				return nil
+			}
+			a.tui = !cmd.HasParent()
+			if err := a.start(cmd.Context()); err != nil {
+				return err
+			}
+			return askRequired(cmd)
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			a.autoSync(cmd)
+			return a.close()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// The flags of the TUI win over the settings of every profile.
+			override := func() {
+				if accessible {
+					a.cfg.Accessible = config.AccessibleOn
+				}
+				if ascii {
+					a.cfg.ASCII = config.ASCIIOn
+				}
+			}
+			override()
+			for {
+				if err := a.runTUI(cmd.Context()); err != nil || a.switchTo == "" {
+					return err
+				}
+				// Start again on the profile chosen, from scratch.
+				a.autoSync(cmd)
+				if err := a.close(); err != nil {
+					return err
+				}
+				if a.log != nil {
+					a.log.Close()
+				}
+				*a = app{configPath: a.configPath, output: a.output, profile: a.switchTo, readOnly: a.readOnly, offline: a.offline, debug: a.debug, tui: true}
+				if err := a.start(cmd.Context()); err != nil {
+					return err
+				}
+				override()
+			}
+		},
+	}
+	root.PersistentFlags().StringVar(&a.configPath, "config", "", "config file (default $XDG_CONFIG_HOME/nomadic/config.toml)")
+	root.PersistentFlags().StringVar(&a.dataDir, "data-dir", "", "directory holding the nomadic database (default $XDG_DATA_HOME/nomadic)")
+	root.PersistentFlags().StringVar(&a.profile, "profile", "", "profile whose settings and journal to use, such as work (default $NOMADIC_PROFILE, else the default profile)")
+	root.Flags().BoolVar(&accessible, "accessible", false, "draw the TUI as plain text for screen readers, without colour, emoji or box drawing")
+	root.Flags().BoolVar(&ascii, "ascii", false, "draw the TUI in ASCII only, without emoji, for terminals that draw them at the wrong width")
+	root.Flags().BoolVar(&a.readOnly, "read-only", false, "browse the TUI without changing anything, e.g. to hand it to a guest")
+	root.PersistentFlags().BoolVar(&a.offline, "offline", false, "make no network requests; exchange rates, weather and holidays come from what is cached or bundled")
+	root.PersistentFlags().BoolVar(&a.debug, "debug", false, "log verbosely, into nomadic.log in the data directory and the debug console of the TUI")
+	root.PersistentFlags().Var(&a.output, "output", "output format: text or json; the export commands take a file instead")
+
+	root.AddCommand(
+		newTripCmd(a),
+		newTemplateCmd(a),
+		newExpenseCmd(a),
+		newJournalCmd(a),
+		newDocumentCmd(a),
+		newQuickCmd(a),
+		newPeopleCmd(a),
+		newTrashCmd(a),
+		newTrackCmd(a),
+		newTransportCmd(a),
+		newCheckInCmd(a),
+		newPackCmd(a),
+		newHighlightCmd(a),
+		newPrepCmd(a),
+		newLodgingCmd(a),
+		newItineraryCmd(a),
+		newWishCmd(a),
+		newVaultCmd(a),
+		newHealthCmd(a),
+		newTagsCmd(a),
+		newSearchCmd(a),
+		newPlacesCmd(a),
+		newCountryCmd(a),
+		newConnectivityCmd(a),
+		newStatsCmd(a),
+		newReportCmd(a),
+		newDigestCmd(a),
+		newConvertCmd(a),
+		newRemindCmd(a),
+		newUpcomingCmd(a),
+		newExportCmd(a),
+		newImportCmd(a),
+		newPublishCmd(a),
+		newConfigCmd(a),
+		newProfileCmd(a),
+		newHookCmd(a),
+		newEncryptionCmd(a),
+		newBackupCmd(a),
+		newRestoreCmd(a),
+		newMigrateCmd(a),
+		newSyncCmd(a),
+		newDaemonCmd(a),
+		newServeCmd(a),
+		newShareCmd(a),
+		newCollabCmd(a),
+		newDemoCmd(a),
+	)
+	a.registerCompletions(root)
+	return root
+}
+
+// start opens what the command needs: the store in its data directory, or
+// for the TUI nothing yet when it is encrypted or to be made by the
+// first-run wizard.
+func (a *app) start(ctx context.Context) error {
+	// The TUI asks for the passphrase of an encrypted database itself,
+	// and sets up the data directory on the first run.
+	if a.tui {
+		var err error
+		if a.firstRun, err = a.isFirstRun(); err != nil || a.firstRun {
+			return err
+		}
+	}
+	return a.open(ctx, a.tui)
+}
+
+// runTUI runs the TUI until it quits, for good or to switch to the profile
+// in a.switchTo.
+func (a *app) runTUI(ctx context.Context) error {
+	// A data directory without a repository simply isn't synced.
+	repo, _ := a.repo()
+	rates, forecasts, calendar := a.rates(), a.weather(), a.holidays()
+	opts := ui.Options{
+		Context:  ctx,
+		Sync:     repo,
+		Store:    a.store,
+		Config:   a.cfg,
+		Profile:  a.profileName(),
+		Rates:    rates,
+		Weather:  forecasts,
+		Holidays: calendar,
+		Geocoder: a.geocoder(),
+		Log:      a.log,
+		SetConfig: func(name, value string) error {
+			base, err := a.loadBaseConfig()
+			if err != nil {
+				return err
+			}
+			if err := base.SetProfile(a.profileName(), name, value); err != nil {
+				return err
+			}
+			return config.Save(a.configPath, base)
+		},
+		Unlock: func(passphrase string) (*storage.Store, error) {
+			store, err := storage.OpenEncrypted(ctx, a.dataDir, passphrase)
+			if err == nil {
+				a.watch(store)
+				a.store = store
+				if a.browsing() {
+					return store, store.ReadOnly(ctx)
+				}
+				err = a.keepNotes(ctx, store)
+			}
+			if err == nil {
+				err = a.recordRecurring(ctx)
+			}
+			return store, err
+		},
+		Switch: func(profile string) { a.switchTo = profile },
+	}
+	if reader, err := a.ocrReader(); err == nil {
+		opts.OCR = reader
+	}
+	if a.firstRun {
+		opts.Config.DataDir = a.dataDir
+		opts.Setup = func(cfg config.Config) (*storage.Store, error) {
+			store, err := a.setUp(ctx, cfg)
+			// The caches were placed in the data directory suggested.
+			rates.Path = a.rates().Path
+			if c, ok := forecasts.(*weather.Cache); ok {
+				c.Path = a.weather().(*weather.Cache).Path
+			}
+			if f, ok := calendar.(holidays.Fallback); ok {
+				f.Primary.(*holidays.Cache).Path = a.holidays().(holidays.Fallback).Primary.(*holidays.Cache).Path
+			}
+			return store, err
+		}
+	}
+	model := ui.NewModel(opts)
+	_, err := tea.NewProgram(model).Run()
+	// The TUI shows what fails on its screens in an error screen; a panic
+	// out of them takes it down, with its stack printed.
+	if errors.Is(err, tea.ErrProgramPanic) {
+		err = errors.New("the interface stopped on an unexpected error, printed above")
+		if a.log != nil && a.log.Path() != "" {
+			err = fmt.Errorf("%w; the log is in %s", err, a.log.Path())
+		}
+	}
+	// Deletions can no longer be undone once the TUI exits.
+	if a.store != nil && !a.browsing() {
+		if terr := a.store.EmptyTrash(); err == nil {
+			err = terr
+		}
+	}
+	return err
+}
+
+// loadConfig reads the config file named by --config or the default path,
+// with the settings of the profile chosen.
+func (a *app) loadConfig() error {
+	cfg, err := a.loadBaseConfig()
+	if err != nil {
+		return err
+	}
+	if a.profile == "" {
+		a.profile = os.Getenv(profileEnv)
+	}
+	if a.cfg, err = cfg.Profile(a.profile); err != nil {
+		return fmt.Errorf("config: %w; add it with `nomadic profile add %s`", err, a.profile)
+	}
+	if a.readOnly {
+		a.cfg.ReadOnly = config.ReadOnlyOn
+	}
+	if a.offline {
+		a.cfg.Offline = config.OfflineOn
+	}
+	if a.demo {
+		a.cfg = demoConfig(a.cfg)
+	}
+	return nil
+}
+
+// loadBaseConfig reads the config file named by --config or the default
+// path as it is, the settings of every profile in it.
+func (a *app) loadBaseConfig() (config.Config, error) {
+	if a.configPath == "" {
+		var err error
+		if a.configPath, err = config.DefaultPath(); err != nil {
+			return config.Config{}, err
+		}
+	}
+	return config.Load(a.configPath)
+}
+
+// browsing reports whether nomadic runs the TUI read-only, with writes to
+// the store refused.
+func (a *app) browsing() bool {
+	return a.tui && a.cfg.ReadOnly == config.ReadOnlyOn
+}
+
+// open loads the config and opens the store in the data directory chosen
+// by --data-dir, the config file, or the default, in that order. An
+// encrypted store is unlocked with $NOMADIC_PASSPHRASE or a prompt, unless
+// deferUnlock is set and the variable is not, in which case a.store stays
+// nil for the caller to unlock.
+func (a *app) open(ctx context.Context, deferUnlock bool) error {
+	if err := a.loadConfig(); err != nil {
+		return err
+	}
+	if err := a.resolveDataDir(); err != nil {
+		return err
+	}
+	// The store makes the data directory if need be; make it first to log
+	// its opening there.
+	if err := os.MkdirAll(a.dataDir, 0o700); err == nil {
+		a.startLog()
+	}
+	store, err := storage.Open(ctx, a.dataDir)
+	if errors.Is(err, storage.ErrEncrypted) {
+		passphrase, ok := os.LookupEnv(passphraseEnv)
+		if !ok && deferUnlock {
+			return nil
+		}
+		if !ok {
+			if passphrase, err = promptSecret("Passphrase: "); err != nil {
+				return err
+			}
+		}
+		store, err = storage.OpenEncrypted(ctx, a.dataDir, passphrase)
+	}
+	if err != nil {
+		return err
+	}
+	a.watch(store)
+	a.store = store
+	if a.browsing() {
+		// Notes edited and expenses falling due are brought in next time.
+		return store.ReadOnly(ctx)
+	}
+	if err := a.keepNotes(ctx, store); err != nil {
+		return err
+	}
+	return a.recordRecurring(ctx)
+}
+
+// isFirstRun loads the config and reports whether the data directory is
+// yet to be made.
+func (a *app) isFirstRun() (bool, error) {
+	if err := a.loadConfig(); err != nil {
+		return false, err
+	}
+	if err := a.resolveDataDir(); err != nil {
+		return false, err
+	}
+	_, err := os.Stat(a.dataDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		a.startLog()
+		return true, nil
+	}
+	return false, err
+}
+
+// setUp saves the settings chosen by the first-run wizard and opens the
+// store in their data directory. The data directory is only written to
+// the config file when it is not the default.
+func (a *app) setUp(ctx context.Context, cfg config.Config) (*storage.Store, error) {
+	dir := cfg.DataDir
+	if def, err := profileDir(a.profile, config.Config{}); err == nil && filepath.Clean(dir) == def {
+		cfg.DataDir = ""
+	}
+	if err := a.saveConfig(cfg); err != nil {
+		return nil, err
+	}
+	a.cfg, a.dataDir = cfg, dir
+	store, err := storage.Open(ctx, dir)
+	if errors.Is(err, storage.ErrEncrypted) {
+		return nil, fmt.Errorf("the journal in %s is encrypted; start nomadic again to unlock it", dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	a.watch(store)
+	a.store = store
+	if err := a.keepNotes(ctx, store); err != nil {
+		return nil, err
+	}
+	return store, a.recordRecurring(ctx)
+}
+
+// resolveDataDir settles a.dataDir from --data-dir, the config file, or
+// the default of the profile, in that order.
+func (a *app) resolveDataDir() error {
+	if a.dataDir != "" {
+		return nil
+	}
+	var err error
+	a.dataDir, err = profileDir(a.profile, a.cfg)
+	return err
+}
+
+// client returns an HTTP client for an integration, giving each attempt
+// at a request at most timeout. Every integration's requests go through
+// the one transport, retried when the connection fails them for a moment,
+// kept to the pace the services ask for, and refused in offline mode.
+func (a *app) client(timeout time.Duration) *http.Client {
+	if a.http == nil {
+		a.http = httpclient.New()
+		a.http.Offline = a.cfg.Offline == config.OfflineOn
+		if u, err := url.Parse(geocode.DefaultNominatimURL); err == nil {
+			a.http.Limits = map[string]time.Duration{u.Hostname(): geocode.NominatimInterval}
+		}
+	}
+	return a.http.Client(timeout)
+}
+
+// rates returns the exchange-rate provider, cached in the data directory.
+func (a *app) rates() *currency.Cache {
+	p := currency.NewFrankfurter()
+	p.Client = a.client(10 * time.Second)
+	return currency.NewCache(filepath.Join(a.dataDir, "rates.json"), 12*time.Hour, p)
+}
+
+// weather returns the configured weather provider, cached in the data
+// directory, or nil when weather is off.
+func (a *app) weather() weather.Provider {
+	if a.cfg.Weather != config.WeatherOpenMeteo {
+		return nil
+	}
+	p := weather.NewOpenMeteo()
+	p.Client = a.client(10 * time.Second)
+	return weather.NewCache(filepath.Join(a.dataDir, "weather.json"), 3*time.Hour, p)
+}
+
+// holidays returns the configured calendar of public holidays, or nil when
+// holidays are off. The online one is cached in the data directory and
+// falls back on the bundled rules.
+func (a *app) holidays() holidays.Provider {
+	switch a.cfg.Holidays {
+	case config.HolidaysOff:
+		return nil
+	case config.HolidaysNager:
+		p := holidays.NewNager()
+		p.Client = a.client(10 * time.Second)
+		return holidays.Fallback{
+			Primary:   holidays.NewCache(filepath.Join(a.dataDir, "holidays.json"), 30*24*time.Hour, p),
+			Secondary: holidays.Bundled{},
+		}
+	}
+	return holidays.Bundled{}
+}
+
+// geocoder returns the configured geocoder, or nil when geocoding is off.
+func (a *app) geocoder() geocode.Geocoder {
+	if a.cfg.Geocoder != config.GeocoderNominatim {
+		return nil
+	}
+	g := geocode.NewNominatim()
+	g.Client = a.client(10 * time.Second)
+	return g
+}
+
+// offsite returns the store of backup_remote, with its credentials from
+// the environment.
+func (a *app) offsite() (offsite.Store, error) {
+	if a.cfg.BackupRemote == "" {
+		return nil, errors.New("no remote for backups; set one with `nomadic config set backup_remote s3://bucket/prefix` or the URL of a WebDAV directory")
+	}
+	u, err := url.Parse(a.cfg.BackupRemote)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "s3" {
+		w := offsite.NewWebDAV(a.cfg.BackupRemote, os.Getenv("NOMADIC_WEBDAV_USER"), os.Getenv("NOMADIC_WEBDAV_PASSWORD"))
+		w.Client = a.client(10 * time.Minute)
+		return w, nil
+	}
+	env := func(names ...string) string {
+		for _, name := range names {
+			if v := os.Getenv(name); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	key := env("NOMADIC_S3_ACCESS_KEY_ID", "AWS_ACCESS_KEY_ID")
+	secret := env("NOMADIC_S3_SECRET_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY")
+	if key == "" || secret == "" {
+		return nil, errors.New("set $NOMADIC_S3_ACCESS_KEY_ID and $NOMADIC_S3_SECRET_ACCESS_KEY to the keys of the bucket")
+	}
+	region := a.cfg.BackupRegion
+	if region == "" {
+		region = env("AWS_REGION", "AWS_DEFAULT_REGION")
+	}
+	s3 := offsite.NewS3(a.cfg.BackupEndpoint, region, u.Host, u.Path, key, secret)
+	s3.Client = a.client(10 * time.Minute)
+	return s3, nil
+}
+
+// transcriber returns the configured speech-to-text backend for
+// `nomadic journal dictate`, or why it cannot be used.
+func (a *app) transcriber() (transcribe.Transcriber, error) {
+	if a.cfg.Transcriber == config.TranscriberAPI {
+		key := os.Getenv("NOMADIC_TRANSCRIBE_KEY")
+		if key == "" {
+			key = os.Getenv("OPENAI_API_KEY")
+		}
+		// Servers on this machine or network often need no key.
+		if key == "" && (a.cfg.TranscribeURL == "" || a.cfg.TranscribeURL == transcribe.DefaultAPIURL) {
+			return nil, errors.New("set $NOMADIC_TRANSCRIBE_KEY or $OPENAI_API_KEY to the key of the transcription API")
+		}
+		t := transcribe.NewAPI(a.cfg.TranscribeURL, a.cfg.TranscribeModel, key)
+		t.Client = a.client(2 * time.Minute)
+		return t, nil
+	}
+	if a.cfg.WhisperModel == "" {
+		return nil, errors.New("no whisper model; download one such as ggml-base.bin and `nomadic config set whisper_model <path>`, or use a transcription API with `nomadic config set transcriber api`")
+	}
+	return transcribe.NewWhisper(a.cfg.WhisperCommand, a.cfg.WhisperModel), nil
+}
+
+// ocrReader returns the configured OCR backend for `nomadic expense
+// receipt`, or why it cannot be used.
+func (a *app) ocrReader() (ocr.Reader, error) {
+	if a.cfg.OCR == config.OCRAPI {
+		key := os.Getenv("NOMADIC_OCR_KEY")
+		if key == "" && (a.cfg.OCRURL == "" || a.cfg.OCRURL == ocr.DefaultAPIURL) {
+			return nil, errors.New("set $NOMADIC_OCR_KEY to the key of the OCR API")
+		}
+		r := ocr.NewAPI(a.cfg.OCRURL, key)
+		r.Client = a.client(2 * time.Minute)
+		return r, nil
+	}
+	return ocr.NewTesseract(a.cfg.OCRCommand), nil
+}
+
+func (a *app) close() error {
+	if a.store == nil {
+		return nil
+	}
+	// Hooks started by the last changes get to finish.
+	a.hooks.Wait()
+	return a.store.Close()
+}
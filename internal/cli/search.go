@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newSearchCmd(a *app) *cobra.Command {
+	var limit int
+	var filters strings.Builder
+	for _, f := range storage.Filters {
+		fmt.Fprintf(&filters, "  %-22s %s\n", f.Name+":"+f.Value, f.Usage)
+	}
+	cmd := &cobra.Command{
+		Use:   "search <query>...",
+		Short: "Search journal entries and expenses across every trip",
+		Long: `Search the titles, text and tags of journal entries and the descriptions,
+notes and merchants of expenses for every word of the query, narrowed by
+any of these filters:
+
+` + filters.String() + `
+Quote a value with spaces, as in trip:"new york", and a word with a colon
+to search for it. Entries come best matches first, expenses newest first.`,
+		Example: `  nomadic search ramen trip:japan
+  nomadic search tag:food after:2024-05-01 currency:JPY
+  nomadic search is:entry in:kyoto on:2024-05-03`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			q, err := storage.ParseQuery(strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			entries, err := a.store.SearchEntries(ctx, q, limit)
+			if err != nil {
+				return err
+			}
+			expenses, err := a.store.SearchExpenses(ctx, q, limit)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := api.SearchResults{Entries: []api.Entry{}, Expenses: []api.Expense{}}
+				for _, m := range entries {
+					out.Entries = append(out.Entries, apiEntry(m.Entry))
+				}
+				for _, m := range expenses {
+					out.Expenses = append(out.Expenses, apiExpense(m.Expense))
+				}
+				return printJSON(cmd, out)
+			}
+			if len(entries) == 0 && len(expenses) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "Nothing matches.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KIND\tDATE\tTRIP\tTITLE\tDETAIL")
+			for _, m := range entries {
+				fmt.Fprintf(w, "entry\t%s\t%s\t%s\t%s\n", a.formatDate(m.Entry.Timestamp), m.TripTitle, m.Entry.Title,
+					strings.Join(m.Entry.Tags, ", "))
+			}
+			for _, m := range expenses {
+				x := m.Expense
+				fmt.Fprintf(w, "expense\t%s\t%s\t%s\t%.2f %s %s\n", a.formatDate(x.Timestamp), m.TripTitle, x.Description,
+					x.Amount, x.Currency, x.Category)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().IntVar(&limit, "limit", 50, "most entries, and most expenses, to show")
+	return cmd
+}
@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+// serveTokenEnv names the variable holding the token `nomadic serve`
+// requires, so it stays the same between runs.
+const serveTokenEnv = "NOMADIC_SERVE_TOKEN"
+
+func newServeCmd(a *app) *cobra.Command {
+	var addr, origin, redact string
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve trips, entries, expenses and stats over a read-only HTTP API",
+		Long: `Start a local HTTP server answering GET requests with the JSON documents
+of package github.com/girdharshubham/nomadic/pkg/api, as --output json
+prints them, for building a dashboard on top of the journal. Nothing can
+be changed through it.
+
+  GET /api/trips                 trips; ?archived=true includes archived ones
+  GET /api/trips/{id}            a trip
+  GET /api/trips/{id}/entries    a trip's journal entries
+  GET /api/trips/{id}/expenses   a trip's expenses
+  GET /api/entries               every journal entry; ?trip={id} for one trip's
+  GET /api/entries/{id}          a journal entry
+  GET /api/expenses              every expense; ?trip={id} for one trip's
+  GET /api/expenses/{id}         an expense
+  GET /api/stats                 the statistics of nomadic stats
+
+Lists take ?tag= to keep what carries a tag; repeat it to require several.
+Private entries are left out and the private passages of entries and trip
+notes stripped, unless --redact is mask, which leaves a mark where they
+were, or none, which serves everything.
+Every request must carry the token as "Authorization: Bearer <token>". The
+token is read from $NOMADIC_SERVE_TOKEN, or made up and printed at start.
+Failed requests answer with {"error": "..."}.
+
+The server listens on --addr, or else the serve_address setting, which is
+127.0.0.1:8787 unless set: only this machine can connect. It stops on
+ctrl+c.`,
+		Example: `  nomadic serve
+  NOMADIC_SERVE_TOKEN=s3cret nomadic serve --addr :8787 --allow-origin http://localhost:5173
+  curl -H "Authorization: Bearer s3cret" localhost:8787/api/trips`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if addr == "" {
+				addr = a.cfg.ServeAddress
+			}
+			r, err := models.ParseRedaction(redact)
+			if err != nil {
+				return fmt.Errorf("--redact: %w", err)
+			}
+			token := os.Getenv(serveTokenEnv)
+			generated := token == ""
+			if generated {
+				b := make([]byte, 16)
+				if _, err := rand.Read(b); err != nil {
+					return err
+				}
+				token = hex.EncodeToString(b)
+			}
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			srv := &http.Server{
+				Handler:           newServer(a, token, origin, r),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Serving on http://%s/api/\n", ln.Addr())
+			if generated {
+				fmt.Fprintf(out, "Token: %s (set $%s to keep one)\n", token, serveTokenEnv)
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			errc := make(chan error, 1)
+			go func() { errc <- srv.Serve(ln) }()
+			select {
+			case err := <-errc:
+				return err
+			case <-ctx.Done():
+			}
+			shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return srv.Shutdown(shutdown)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&addr, "addr", "", "address to listen on, host:port (default: the serve_address setting)")
+	f.StringVar(&origin, "allow-origin", "", "origin of a web dashboard allowed to call the API from a browser")
+	f.StringVar(&redact, "redact", string(models.RedactStrip), "strip, mask or none: what becomes of what is private")
+	return cmd
+}
+
+// server answers the requests of `nomadic serve`.
+type server struct {
+	a      *app
+	token  string
+	origin string
+	redact models.Redaction
+}
+
+func newServer(a *app, token, origin string, redact models.Redaction) http.Handler {
+	s := &server{a: a, token: token, origin: origin, redact: redact}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/trips", s.trips)
+	mux.HandleFunc("GET /api/trips/{id}", s.trip)
+	mux.HandleFunc("GET /api/trips/{id}/entries", s.tripEntries)
+	mux.HandleFunc("GET /api/trips/{id}/expenses", s.tripExpenses)
+	mux.HandleFunc("GET /api/entries", s.entries)
+	mux.HandleFunc("GET /api/entries/{id}", s.entry)
+	mux.HandleFunc("GET /api/expenses", s.expenses)
+	mux.HandleFunc("GET /api/expenses/{id}", s.expense)
+	mux.HandleFunc("GET /api/stats", s.stats)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET")
+			writeError(w, http.StatusMethodNotAllowed, errors.New("the API is read-only"))
+			return
+		}
+		writeError(w, http.StatusNotFound, errors.New("no such endpoint; see nomadic serve --help"))
+	})
+	return s.authorize(mux)
+}
+
+// authorize lets through the requests carrying the token, answering
+// browsers' preflight requests from the allowed origin first.
+func (s *server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.origin != "" && r.Header.Get("Origin") == s.origin {
+			w.Header().Set("Access-Control-Allow-Origin", s.origin)
+			w.Header().Set("Vary", "Origin")
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Methods", "GET")
+				w.Header().Set("Access-Control-Allow-Headers", "Authorization")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="nomadic"`)
+			writeError(w, http.StatusUnauthorized, errors.New("missing or wrong token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *server) trips(w http.ResponseWriter, r *http.Request) {
+	trips, err := s.a.store.ListTrips(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	archived := r.URL.Query().Get("archived") == "true"
+	tags := r.URL.Query()["tag"]
+	trips = slices.DeleteFunc(trips, func(t *models.Trip) bool {
+		return !models.HasTags(t.Tags, tags) || t.Archived() && !archived
+	})
+	for i, t := range trips {
+		trips[i] = s.redactTrip(t)
+	}
+	writeJSON(w, apiTrips(trips))
+}
+
+func (s *server) trip(w http.ResponseWriter, r *http.Request) {
+	t, err := s.a.store.GetTrip(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, "trip", err)
+		return
+	}
+	writeJSON(w, apiTrip(s.redactTrip(t)))
+}
+
+// redactTrip returns t with the private passages of its notes redacted.
+func (s *server) redactTrip(t *models.Trip) *models.Trip {
+	if s.redact != models.RedactStrip && s.redact != models.RedactMask {
+		return t
+	}
+	c := *t
+	c.Notes = models.RedactText(t.Notes, s.redact)
+	return &c
+}
+
+func (s *server) tripEntries(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.a.store.GetTrip(r.Context(), r.PathValue("id")); err != nil {
+		writeStoreError(w, "trip", err)
+		return
+	}
+	s.listEntries(w, r, r.PathValue("id"))
+}
+
+func (s *server) tripExpenses(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.a.store.GetTrip(r.Context(), r.PathValue("id")); err != nil {
+		writeStoreError(w, "trip", err)
+		return
+	}
+	s.listExpenses(w, r, r.PathValue("id"))
+}
+
+func (s *server) entries(w http.ResponseWriter, r *http.Request) {
+	s.listEntries(w, r, r.URL.Query().Get("trip"))
+}
+
+func (s *server) listEntries(w http.ResponseWriter, r *http.Request, tripID string) {
+	var (
+		entries []*models.Entry
+		err     error
+	)
+	if tripID != "" {
+		entries, err = s.a.store.ListEntriesByTrip(r.Context(), tripID)
+	} else {
+		entries, err = s.a.store.ListEntries(r.Context())
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	tags := r.URL.Query()["tag"]
+	var out []*models.Entry
+	for _, e := range entries {
+		if e = e.Redact(s.redact); e != nil && models.HasTags(e.Tags, tags) {
+			out = append(out, e)
+		}
+	}
+	writeJSON(w, apiEntries(out))
+}
+
+func (s *server) entry(w http.ResponseWriter, r *http.Request) {
+	e, err := s.a.store.GetEntry(r.Context(), r.PathValue("id"))
+	if err == nil {
+		// A private entry stripped is not there to be had.
+		if e = e.Redact(s.redact); e == nil {
+			err = storage.ErrNotFound
+		}
+	}
+	if err != nil {
+		writeStoreError(w, "entry", err)
+		return
+	}
+	writeJSON(w, apiEntry(e))
+}
+
+func (s *server) expenses(w http.ResponseWriter, r *http.Request) {
+	s.listExpenses(w, r, r.URL.Query().Get("trip"))
+}
+
+func (s *server) listExpenses(w http.ResponseWriter, r *http.Request, tripID string) {
+	var (
+		expenses []*models.Expense
+		err      error
+	)
+	if tripID != "" {
+		expenses, err = s.a.store.ListExpensesByTrip(r.Context(), tripID)
+	} else {
+		expenses, err = s.a.store.ListExpenses(r.Context())
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	tags := r.URL.Query()["tag"]
+	expenses = slices.DeleteFunc(expenses, func(x *models.Expense) bool { return !models.HasTags(x.Tags, tags) })
+	writeJSON(w, apiExpenses(expenses))
+}
+
+func (s *server) expense(w http.ResponseWriter, r *http.Request) {
+	x, err := s.a.store.GetExpense(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, "expense", err)
+		return
+	}
+	writeJSON(w, apiExpense(x))
+}
+
+func (s *server) stats(w http.ResponseWriter, r *http.Request) {
+	summary, moods, achievements, writing, err := s.a.stats(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, apiStats(summary, moods, achievements, writing))
+}
+
+// writeJSON answers with v as a JSON document.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+// writeError answers with status and an api.Error giving err's reason.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(api.Error{Error: err.Error()})
+}
+
+// writeStoreError answers a failed lookup of a kind of record: not found
+// when it does not exist.
+func writeStoreError(w http.ResponseWriter, kind string, err error) {
+	if errors.Is(err, storage.ErrNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no %s with that ID", kind))
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
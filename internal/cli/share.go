@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+func newShareCmd(a *app) *cobra.Command {
+	var (
+		addr, tunnel string
+		redact       string
+		duration     time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "share <trip>",
+		Short: "Show a trip's itinerary and expense split on a temporary web page",
+		Long: `Serve a read-only web page summing up a trip: its route and itinerary,
+what was spent by category and who owes whom. Journal entries are left
+out, and so are the private passages of the trip's notes, fenced by
+"::: private" and ":::" lines, unless --redact is mask, which leaves a mark
+where they were, or none. The page is rendered afresh on every visit, so
+changes made meanwhile show on reload.
+
+The page lives under a secret path made up at start and printed with the
+address: only those given the link can open it. The server listens on
+--addr, 127.0.0.1 on a free port unless given, and stops after --for or on
+ctrl+c. Listen on :0 to show the page to someone on the same network.
+
+--tunnel, or the share_tunnel setting, is a command run alongside the
+server to reach it from elsewhere, with {url} and {port} replaced by the
+server's; it is stopped with the server. Add the printed path to the
+address the tunnel reports.`,
+		Example: `  nomadic share lisbon
+  nomadic share "Japan 2025" --addr :0 --for 15m
+  nomadic share lisbon --tunnel "cloudflared tunnel --url {url}"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if tunnel == "" {
+				tunnel = a.cfg.ShareTunnel
+			}
+			// A tunnel of blanks is none.
+			tunnel = strings.TrimSpace(tunnel)
+			r, err := models.ParseRedaction(redact)
+			if err != nil {
+				return fmt.Errorf("--redact: %w", err)
+			}
+			b := make([]byte, 12)
+			if _, err := rand.Read(b); err != nil {
+				return err
+			}
+			path := "/" + hex.EncodeToString(b) + "/"
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			srv := &http.Server{Handler: a.sharer(t, path, r), ReadHeaderTimeout: 10 * time.Second}
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			ctx, cancel := context.WithTimeout(ctx, duration)
+			defer cancel()
+
+			port := ln.Addr().(*net.TCPAddr).Port
+			local := fmt.Sprintf("http://127.0.0.1:%d", port)
+			if tunnel != "" {
+				r := strings.NewReplacer("{url}", local, "{port}", fmt.Sprint(port))
+				parts := strings.Fields(r.Replace(tunnel))
+				c := exec.CommandContext(ctx, parts[0], parts[1:]...)
+				c.Stdout, c.Stderr = cmd.ErrOrStderr(), cmd.ErrOrStderr()
+				if err := c.Start(); err != nil {
+					ln.Close()
+					return fmt.Errorf("tunnel: %w", err)
+				}
+				defer c.Wait()
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Sharing %q at %s%s\n", t.Title, local, path)
+			if host, _, _ := net.SplitHostPort(addr); host == "" || net.ParseIP(host).IsUnspecified() {
+				fmt.Fprintln(out, "From elsewhere on the network, use this machine's address instead of 127.0.0.1")
+			}
+			if tunnel != "" {
+				fmt.Fprintf(out, "Through the tunnel: add %s to the address it reports\n", path)
+			}
+			fmt.Fprintf(out, "Until %s; ctrl+c to stop\n", time.Now().Add(duration).Format("15:04"))
+
+			errc := make(chan error, 1)
+			go func() { errc <- srv.Serve(ln) }()
+			select {
+			case err := <-errc:
+				return err
+			case <-ctx.Done():
+			}
+			shutdown, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancelShutdown()
+			return srv.Shutdown(shutdown)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&addr, "addr", "127.0.0.1:0", "address to listen on, host:port; port 0 picks a free one")
+	f.DurationVar(&duration, "for", time.Hour, "how long to share the trip for")
+	f.StringVar(&redact, "redact", string(models.RedactStrip), "strip, mask or none: what becomes of what is private")
+	f.StringVar(&tunnel, "tunnel", "", "command making the page reachable from elsewhere (default: the share_tunnel setting)")
+	return cmd
+}
+
+// sharer serves the page of `nomadic share` for t at path, redacted by
+// redact, and nothing else.
+func (a *app) sharer(t *models.Trip, path string, redact models.Redaction) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "the page is read-only", http.StatusMethodNotAllowed)
+			return
+		}
+		// The trip may have been changed or renamed since sharing started.
+		current, err := a.store.GetTrip(r.Context(), t.ID)
+		if err != nil {
+			current = t
+		}
+		trip, err := export.Load(r.Context(), a.store, current)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		trip = trip.Redact(redact)
+		split := a.settle(r.Context(), current, trip.Expenses)
+		var page bytes.Buffer
+		if err := export.HTML(&page, trip, &split, a.cfg.Layout()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Write(page.Bytes())
+	})
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/settle"
+)
+
+func newTripCompanionsCmd(a *app) *cobra.Command {
+	var (
+		trip        string
+		add, remove []string
+	)
+	cmd := &cobra.Command{
+		Use:   "companions",
+		Short: "List or change who travels along and shares expenses",
+		Long: `List or change the companions of a group trip. Expenses can be paid by
+and split among the companions and "me", the traveler keeping the journal;
+nomadic expense settle then works out who owes whom.`,
+		Example: `  nomadic trip companions --trip lisbon --add Ana,Ben
+  nomadic trip companions --trip lisbon --remove Ben`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			if len(add) > 0 || len(remove) > 0 {
+				names := slices.Clone(t.Companions)
+				for _, r := range splitList(remove) {
+					name, err := models.ResolvePerson(r, names)
+					if err != nil {
+						return fmt.Errorf("--remove: %w", err)
+					}
+					names = slices.DeleteFunc(names, func(n string) bool { return n == name })
+				}
+				names, err = models.ParseCompanions(append(names, splitList(add)...))
+				if err != nil {
+					return fmt.Errorf("--add: %w", err)
+				}
+				t.Companions = names
+				if err := a.store.SaveTrip(ctx, t); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, orEmpty(t.Companions))
+			}
+			if len(t.Companions) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "%q has no companions\n", t.Title)
+				return nil
+			}
+			for _, c := range t.Companions {
+				fmt.Fprintln(cmd.OutOrStdout(), c)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.StringSliceVar(&add, "add", nil, "companions to add; repeat or separate with commas")
+	f.StringSliceVar(&remove, "remove", nil, "companions to remove; repeat or separate with commas")
+	return cmd
+}
+
+func newExpenseSettleCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "settle",
+		Short: "Work out who owes whom for a trip's shared expenses",
+		Long: `Work out who owes whom for a trip's shared expenses, with as few
+transfers as possible. Money is converted with cached exchange rates into
+the trip's budget currency, or home_currency when it has none.`,
+		Example: `  nomadic expense settle --trip lisbon
+  nomadic expense settle --trip lisbon --output json | jq .transfers`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			expenses, err := a.store.ListExpensesByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			s := a.settle(ctx, t, expenses)
+			cur := s.Currency
+			if a.json() {
+				return printJSON(cmd, apiSettlement(t, s))
+			}
+
+			out := cmd.OutOrStdout()
+			if s.Shared == 0 && s.Unconverted == 0 {
+				fmt.Fprintf(out, "%q has no shared expenses; split one with nomadic expense add --split\n", t.Title)
+				return nil
+			}
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PERSON\tPAID\tSHARE\tBALANCE")
+			for _, b := range s.Balances {
+				fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%+.2f %s\n", b.Name, b.Paid, b.Share, b.Net, cur)
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if s.Unconverted > 0 {
+				fmt.Fprintf(out, "%d shared expenses in other currencies could not be converted and are not counted\n", s.Unconverted)
+			}
+			fmt.Fprintln(out)
+			if len(s.Transfers) == 0 {
+				fmt.Fprintln(out, "Everyone is settled up")
+				return nil
+			}
+			for _, tr := range s.Transfers {
+				fmt.Fprintf(out, "%s %s\n", transferLine(tr), cur)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+// settle settles the shared expenses of t in its budget currency, or
+// home_currency when it has none, with cached exchange rates.
+func (a *app) settle(ctx context.Context, t *models.Trip, expenses []*models.Expense) settle.Settlement {
+	cur := t.BudgetCurrency
+	if cur == "" {
+		cur = a.cfg.HomeCurrency
+	}
+	var convert settle.Converter
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if rates, err := a.rates().Latest(ctx, cur); err == nil {
+		convert = rates.Convert
+	}
+	return settle.Compute(t.People(), expenses, cur, convert)
+}
+
+// transferLine describes a transfer, speaking to the traveler as "you".
+func transferLine(tr settle.Transfer) string {
+	switch {
+	case tr.From == models.Me:
+		return fmt.Sprintf("You pay %s %.2f", tr.To, tr.Amount)
+	case tr.To == models.Me:
+		return fmt.Sprintf("%s pays you %.2f", tr.From, tr.Amount)
+	}
+	return fmt.Sprintf("%s pays %s %.2f", tr.From, tr.To, tr.Amount)
+}
@@ -0,0 +1,289 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+var chargeSigns = map[string]int{
+	"auto":     export.ChargesAuto,
+	"negative": export.ChargesNegative,
+	"positive": export.ChargesPositive,
+}
+
+func newExpenseStatementCmd(a *app) *cobra.Command {
+	var (
+		trip            string
+		mapping         []string
+		charges         string
+		dryRun          bool
+		allowDuplicates bool
+	)
+	cmd := &cobra.Command{
+		Use:   "statement <file>",
+		Short: "Import the charges of a bank or credit card statement",
+		Long: `Import the charges of a bank or credit card statement exported as OFX,
+QFX or CSV. Credits such as refunds and card payments are left out.
+
+Each charge is categorized by the rule matching its merchant text (see
+nomadic expense rule) and goes to the rule's trip, else to --trip, else to
+the trip in progress on its day. Correcting an imported expense's category,
+with nomadic expense categorize or in the TUI, teaches a rule for its
+merchant.
+
+CSV columns are found by name as for nomadic expense import, also under the
+names banks commonly use such as "Transaction Date" and "Payee". Whether
+charges are negative or positive is guessed from the file unless --charges
+says.`,
+		Example: `  nomadic expense statement ~/Downloads/statement.ofx --dry-run
+  nomadic expense statement --trip lisbon --charges positive --map description=Merchant card.csv`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			sign, ok := chargeSigns[charges]
+			if !ok {
+				return errors.New("--charges must be auto, negative or positive")
+			}
+			m, err := export.ParseMapping(mapping)
+			if err != nil {
+				return err
+			}
+			var fallback *models.Trip
+			if trip != "" {
+				if fallback, err = resolveTrip(ctx, a.store, trip); err != nil {
+					return err
+				}
+			}
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			st, err := export.ParseStatement(f, export.StatementOptions{
+				Mapping:         m,
+				DateLayouts:     []string{a.cfg.Layout()},
+				DefaultCurrency: a.cfg.DefaultCurrency,
+				Charges:         sign,
+			})
+			if err != nil {
+				return err
+			}
+			rules, err := a.store.ListRules(ctx)
+			if err != nil {
+				return err
+			}
+			trips, err := a.store.ListTrips(ctx)
+			if err != nil {
+				return err
+			}
+			categorized := st.Categorize(rules, trips, fallback)
+
+			byID := map[string]*models.Trip{}
+			for _, t := range trips {
+				byID[t.ID] = t
+			}
+			tripFor := func(id string) (*models.Trip, error) {
+				if t, ok := byID[id]; ok {
+					return t, nil
+				}
+				return nil, fmt.Errorf("no trip %s", id)
+			}
+			report, err := export.ImportExpenses(ctx, a.store, st.Rows, tripFor, allowDuplicates, dryRun)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := apiImport(report, dryRun)
+				out.Categorized, out.Credits = categorized, st.Credits
+				return printJSON(cmd, out)
+			}
+
+			out := cmd.OutOrStdout()
+			a.printDuplicates(out, report.Duplicates, dryRun)
+			for _, row := range report.Failed {
+				fmt.Fprintf(out, "line %d: %v\n", row.Line, row.Err)
+			}
+			verb := "Imported"
+			if dryRun {
+				verb = "Would import"
+			}
+			fmt.Fprintf(out, "%s %d charges, %d categorized by rules (%d duplicates merged, %d errors, %d credits left out)\n",
+				verb, len(report.Imported), categorized, len(report.Duplicates), len(report.Failed), st.Credits)
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip for charges no rule assigns to one (default: the trip in progress on their day)")
+	f.StringArrayVar(&mapping, "map", nil, "read a field from a named CSV column, as field=Column")
+	f.StringVar(&charges, "charges", "auto", "sign of charges in the file: auto, negative or positive")
+	f.BoolVar(&dryRun, "dry-run", false, "report what would be imported without saving")
+	f.BoolVar(&allowDuplicates, "allow-duplicates", false, "import charges that look like duplicates too, instead of merging them")
+	return cmd
+}
+
+func newExpenseCategorizeCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "categorize <expense> <category>",
+		Short: "Correct an expense's category",
+		Long: `Correct the category of an expense, named by ID or description. When it
+was imported from a statement, its merchant is remembered so the next
+statement import categorizes the merchant's charges alike.`,
+		Example: `  nomadic expense categorize "ALBERT HEIJN 1234" food`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cat, err := models.ParseCategory(args[1])
+			if err != nil {
+				return err
+			}
+			x, err := resolveExpense(ctx, a.store, trip, args[0])
+			if err != nil {
+				return err
+			}
+			x.Category = cat
+			if err := a.store.SaveExpense(ctx, x); err != nil {
+				return err
+			}
+			var rule *models.Rule
+			if x.Merchant != "" {
+				if rule, err = a.store.LearnRule(ctx, x.Merchant, cat); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, apiExpense(x))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Filed %q under %s\n", x.Description, cat)
+			if rule != nil {
+				fmt.Fprintf(cmd.OutOrStdout(), "Charges from %q will be filed under %s too\n", rule.Match, cat)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+func newExpenseRuleCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rule",
+		Short: "Manage the rules that categorize statement imports",
+		Long: `A rule files the statement charges whose merchant text contains its
+match under a category and, optionally, a trip. When several match, the
+longest match wins. Rules are also learned from corrected categories.`,
+	}
+	cmd.AddCommand(newExpenseRuleAddCmd(a), newExpenseRuleListCmd(a), newExpenseRuleDeleteCmd(a))
+	return cmd
+}
+
+func newExpenseRuleAddCmd(a *app) *cobra.Command {
+	var match, category, trip string
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a categorization rule",
+		Example: `  nomadic expense rule add --match uber --category transport
+  nomadic expense rule add --match "hotel lisboa" --category lodging --trip lisbon`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if strings.TrimSpace(match) == "" {
+				return errors.New("--match is required")
+			}
+			cat, err := models.ParseCategory(category)
+			if err != nil {
+				return fmt.Errorf("--category: %w", err)
+			}
+			r := models.NewRule(match, cat)
+			if existing, err := resolveRule(ctx, a.store, match); err == nil {
+				r.ID, r.CreatedAt = existing.ID, existing.CreatedAt
+			}
+			if trip != "" {
+				t, err := resolveTrip(ctx, a.store, trip)
+				if err != nil {
+					return err
+				}
+				r.TripID = t.ID
+			}
+			if err := a.store.SaveRule(ctx, r); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiRule(r))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Charges from %q will be filed under %s\n", r.Match, r.Category)
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&match, "match", "", "text the merchant must contain, ignoring case")
+	f.StringVar(&category, "category", "", categoryUsage)
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination the charges go to")
+	return cmd
+}
+
+func newExpenseRuleListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the categorization rules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			rules, err := a.store.ListRules(ctx)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiRules(rules))
+			}
+			trips := map[string]string{}
+			if ts, err := a.store.ListTrips(ctx); err == nil {
+				for _, t := range ts {
+					trips[t.ID] = t.Title
+				}
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tMATCH\tCATEGORY\tTRIP\tLEARNED")
+			for _, r := range rules {
+				learned := ""
+				if r.Learned {
+					learned = "yes"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.ID, r.Match, r.Category, trips[r.TripID], learned)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newExpenseRuleDeleteCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <rule>",
+		Short: "Delete a categorization rule, by ID or match",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			r, err := resolveRule(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteRule(ctx, r.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "rule", ID: r.ID, Name: r.Match})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted the rule for %q\n", r.Match)
+			return nil
+		},
+	}
+}
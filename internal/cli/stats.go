@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/stats"
+)
+
+func newStatsCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Show travel statistics across every trip",
+		Long: `Show travel statistics across every trip: destinations and countries
+visited, days traveled, spending by year and category, the distance and
+carbon footprint of the journeys logged with nomadic transport, and how
+each trip felt: its rating and the mood of its entries day by day, the
+words written in the journal by trip and by month, and the achievements
+reached: 10 countries, 5 continents, 100 journal entries and a 30-day
+journaling streak. Money is converted into home_currency with
+cached exchange rates.`,
+		Example: `  nomadic stats
+  nomadic stats --output json | jq .total_spend`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, moods, achievements, writing, err := a.stats(cmd.Context())
+			if err != nil {
+				return err
+			}
+			home := a.cfg.HomeCurrency
+			if a.json() {
+				return printJSON(cmd, apiStats(s, moods, achievements, writing))
+			}
+
+			out := cmd.OutOrStdout()
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Trips\t%d\n", s.Trips)
+			fmt.Fprintf(w, "Destinations\t%d\n", len(s.Destinations))
+			if len(s.Countries) > 0 {
+				fmt.Fprintf(w, "Countries\t%d\n", len(s.Countries))
+			}
+			fmt.Fprintf(w, "Days traveled\t%d\n", s.DaysTraveled)
+			if s.Longest != nil {
+				fmt.Fprintf(w, "Longest trip\t%s (%d days)\n", s.Longest.Title, s.LongestDays)
+			}
+			fmt.Fprintf(w, "Total spend\t%.2f %s\n", s.TotalSpend, home)
+			if s.DaysTraveled > 0 {
+				fmt.Fprintf(w, "Average per day\t%.2f %s\n", s.AverageDaily, home)
+			}
+			if f := s.Footprint; f.Segments > 0 {
+				fmt.Fprintf(w, "Distance\t%.0f km over %d journeys\n", f.Distance, f.Segments)
+				fmt.Fprintf(w, "Carbon footprint\t~%s\n", stats.FormatCO2(f.CO2))
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			if s.Unconverted > 0 {
+				fmt.Fprintf(out, "%d expenses in other currencies could not be converted and are not counted\n", s.Unconverted)
+			}
+
+			section := func(title string, rows []stats.Amount, format func(float64) string) {
+				if len(rows) == 0 {
+					return
+				}
+				fmt.Fprintf(out, "\n%s\n", title)
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				for _, r := range rows {
+					fmt.Fprintf(w, "  %s\t%s\n", r.Label, format(r.Value))
+				}
+				w.Flush()
+			}
+			money := func(v float64) string { return fmt.Sprintf("%.2f %s", v, home) }
+			visits := func(v float64) string {
+				if v == 1 {
+					return "1 trip"
+				}
+				return fmt.Sprintf("%.0f trips", v)
+			}
+			section("Spend by year", s.SpendByYear, money)
+			section("Spend by category", s.SpendCategory, money)
+			section("Most visited", s.Destinations, visits)
+			section("Countries", s.Countries, visits)
+			if len(s.Footprint.ByMode) > 0 {
+				fmt.Fprintf(out, "\nEmissions by transport\n")
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				for _, m := range s.Footprint.ByMode {
+					co2 := "-"
+					if _, ok := models.EmissionFactors[m.Mode]; ok {
+						co2 = stats.FormatCO2(m.CO2)
+					}
+					fmt.Fprintf(w, "  %s\t%.0f km\t%s\n", m.Mode, m.Distance, co2)
+				}
+				w.Flush()
+			}
+			if len(moods) > 0 {
+				fmt.Fprintf(out, "\nMood by trip\n")
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				for _, m := range moods {
+					mood := ""
+					if len(m.Trend) > 0 {
+						mood = fmt.Sprintf("%s %.1f", m.Trend.Sparkline(), m.Trend.Average())
+					}
+					fmt.Fprintf(w, "  %s\t%s\t%s\n", m.Trip.Title, models.Stars(m.Trip.Rating), mood)
+				}
+				w.Flush()
+			}
+			if writing.Words > 0 {
+				fmt.Fprintf(out, "\nWriting\n")
+				w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+				fmt.Fprintf(w, "  Words\t%d in %d entries\n", writing.Words, writing.Entries)
+				fmt.Fprintf(w, "  Average entry\t%.0f words\n", writing.Average())
+				if e := writing.Longest; e != nil {
+					fmt.Fprintf(w, "  Longest entry\t%s, %s (%d words)\n", e.Title, a.formatDate(e.Timestamp), writing.LongestWords)
+				}
+				w.Flush()
+				words := func(v float64) string { return fmt.Sprintf("%.0f words", v) }
+				section("Words by trip", writing.ByTrip, words)
+				section("Words by month", writing.ByMonth, words)
+			}
+			fmt.Fprintf(out, "\nAchievements\n")
+			w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			for _, ach := range achievements {
+				state := fmt.Sprintf("%d/%d %s", ach.Progress, ach.Goal, ach.Unit)
+				switch {
+				case !ach.UnlockedAt.IsZero():
+					state = fmt.Sprintf("%d %s, unlocked %s", ach.Goal, ach.Unit, ach.UnlockedAt.Format(models.DateLayout))
+				case ach.Unlocked():
+					state = fmt.Sprintf("%d %s, unlocked", ach.Goal, ach.Unit)
+				}
+				fmt.Fprintf(w, "  %s %s\t%s\n", ach.Icon, ach.Title, state)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// stats computes the statistics across every trip in the home currency,
+// converting with the latest exchange rates when they can be had, the
+// moods of each trip, the achievements, with when those the TUI announced
+// were unlocked, and what was written in the journal.
+func (a *app) stats(ctx context.Context) (stats.Summary, []stats.TripMood, []stats.Achievement, stats.Writing, error) {
+	trips, err := a.store.ListTrips(ctx)
+	if err != nil {
+		return stats.Summary{}, nil, nil, stats.Writing{}, err
+	}
+	expenses, err := a.store.ListExpenses(ctx)
+	if err != nil {
+		return stats.Summary{}, nil, nil, stats.Writing{}, err
+	}
+	segments, err := a.store.ListSegments(ctx)
+	if err != nil {
+		return stats.Summary{}, nil, nil, stats.Writing{}, err
+	}
+	entries, err := a.store.ListEntries(ctx)
+	if err != nil {
+		return stats.Summary{}, nil, nil, stats.Writing{}, err
+	}
+	unlocked, err := a.store.ListUnlockedAchievements(ctx)
+	if err != nil {
+		return stats.Summary{}, nil, nil, stats.Writing{}, err
+	}
+	var rated []*models.Entry
+	for _, e := range entries {
+		if e.Mood > 0 {
+			rated = append(rated, e)
+		}
+	}
+	achievements := stats.Achievements(trips, entries, time.Now())
+	for i, ach := range achievements {
+		achievements[i].UnlockedAt = unlocked[ach.ID]
+	}
+	home := a.cfg.HomeCurrency
+	var convert stats.Converter
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if rates, err := a.rates().Latest(ctx, home); err == nil {
+		convert = rates.Convert
+	}
+	return stats.Compute(trips, expenses, segments, home, convert, time.Now()), stats.Moods(trips, rated), achievements,
+		stats.ComputeWriting(trips, entries), nil
+}
@@ -0,0 +1,424 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/gitsync"
+	"github.com/girdharshubham/nomadic/internal/oplog"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newSyncCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync the data directory through a git remote",
+		Long: `Keep the data directory in a git repository and sync it with a remote.
+
+Run "nomadic sync init <url>" once to start, then "nomadic sync" to commit
+local changes, bring in changes from other devices and push. Set auto_sync
+to commit (or push) to commit after every save.
+
+The database is a single file, so git cannot merge changes made on two
+devices between syncs. When that happens sync stops and reports a conflict
+without touching your data. Merge both sides with "nomadic sync merge",
+or choose which side to keep with "nomadic sync resolve --keep local" or
+"--keep remote"; the discarded version stays in the git history.`,
+		Example: `  nomadic sync init git@github.com:me/nomadic-data.git
+  nomadic sync
+  nomadic config set auto_sync push`,
+		Args: cobra.NoArgs,
+		// Syncing works on the files; the store is only opened briefly to
+		// flush it to disk.
+		PersistentPreRunE: dataDirOnly(a),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			repo, err := a.repo()
+			if err != nil {
+				return err
+			}
+			if err := checkpoint(ctx, a.dataDir); err != nil {
+				return err
+			}
+			res, err := repo.Sync(ctx, "Sync nomadic data")
+			var conflict *gitsync.ConflictError
+			if errors.As(err, &conflict) {
+				return fmt.Errorf("%w\nthis device and the remote both changed your data; merge both with\n"+
+					"  nomadic sync merge\n"+
+					"or keep one side with\n"+
+					"  nomadic sync resolve --keep local    (this device)\n"+
+					"  nomadic sync resolve --keep remote   (the other device)", err)
+			}
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.SyncResult{Committed: res.Committed, Pulled: res.Pulled, Pushed: res.Pushed})
+			}
+			out := cmd.OutOrStdout()
+			if res.Committed {
+				fmt.Fprintln(out, "Committed local changes")
+			}
+			if res.Pulled > 0 {
+				fmt.Fprintf(out, "Pulled %d commits\n", res.Pulled)
+			}
+			if res.Pushed > 0 {
+				fmt.Fprintf(out, "Pushed %d commits\n", res.Pushed)
+			}
+			if !res.Committed && res.Pulled == 0 && res.Pushed == 0 {
+				fmt.Fprintln(out, "Already in sync")
+			}
+			return nil
+		},
+	}
+	cmd.AddCommand(newSyncInitCmd(a), newSyncStatusCmd(a), newSyncMergeCmd(a), newSyncResolveCmd(a))
+	return cmd
+}
+
+func newSyncInitCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "init [remote-url]",
+		Short: "Put the data directory under git",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			remote := ""
+			if len(args) == 1 {
+				remote = args[0]
+			}
+			if err := checkpoint(ctx, a.dataDir); err != nil {
+				return err
+			}
+			repo := gitsync.Open(a.dataDir)
+			if err := repo.Init(ctx, remote); err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printSyncStatus(cmd, repo)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Syncing", a.dataDir)
+			if remote == "" {
+				fmt.Fprintln(cmd.OutOrStdout(), "Add a remote with `nomadic sync init <url>` to sync with other devices.")
+			}
+			return nil
+		},
+	}
+}
+
+func newSyncStatusCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show what is waiting to be synced",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := a.repo()
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printSyncStatus(cmd, repo)
+			}
+			s, err := repo.Status(cmd.Context())
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintln(out, "Directory:  ", repo.Dir())
+			fmt.Fprintln(out, "Auto sync:  ", a.cfg.AutoSync)
+			if !s.LastCommit.IsZero() {
+				fmt.Fprintln(out, "Last commit:", s.LastCommit.Format(time.DateTime))
+			}
+			switch {
+			case !s.HasRemote:
+				fmt.Fprintln(out, "No remote configured")
+			case s.Diverged():
+				fmt.Fprintf(out, "Diverged: %d local and %d remote commits; run nomadic sync\n", s.Ahead, s.Behind)
+			case s.Ahead > 0:
+				fmt.Fprintf(out, "%d commits to push\n", s.Ahead)
+			case s.Behind > 0:
+				fmt.Fprintf(out, "%d commits to pull\n", s.Behind)
+			}
+			if s.Dirty {
+				fmt.Fprintln(out, "Uncommitted changes")
+			}
+			return nil
+		},
+	}
+}
+
+func newSyncMergeCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "merge",
+		Short: "Merge the changes of other devices with this one's, record by record",
+		Long: `Merge the changes made on other devices with those made on this one, even
+when both changed the database since they last synced.
+
+Each device logs the changes it makes, as operations appended to its own
+file in the oplog directory, which git merges without trouble. Merging
+brings in the logs of the other devices and replays every operation, in
+the order they were made, into the database. Changes to different records,
+or to different fields of one record, all make it; only a field that two
+devices changed differently, or a record one changed and the other
+deleted, is a conflict. The later change to a field wins, and a deletion
+wins over changes; the conflicts are listed so that what was lost can be
+put back. Every device settles them alike, so all end up with the same
+data.
+
+The first merge starts the logs with everything in this device's
+database. Start them on one device, then bring them to the others with a
+plain nomadic sync before changing anything there, so that they all start
+from the same data. From then on sync logs each device's changes before
+committing them. An encrypted database cannot be merged, as the logs
+would keep it in plain text.`,
+		Example: `  nomadic sync merge`,
+		Args:    cobra.NoArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.start(cmd.Context())
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			repo, err := a.repo()
+			if err != nil {
+				return err
+			}
+			if storage.IsEncrypted(a.dataDir) {
+				return errors.New("an encrypted database cannot be merged, as the operation logs would keep it in plain text; " +
+					"keep one side with `nomadic sync resolve`")
+			}
+			log, err := oplog.Open(a.dataDir)
+			if err != nil {
+				return err
+			}
+			res := api.SyncMerge{Device: log.Device(), Conflicts: []api.SyncConflict{}}
+			if res.Recorded, err = log.Record(ctx, a.store); err != nil {
+				return err
+			}
+			since, err := log.Heads()
+			if err != nil {
+				return err
+			}
+			if err := a.store.Checkpoint(ctx); err != nil {
+				return err
+			}
+			// The logs merge as text; of the database, which the merge
+			// writes next, this device's side is kept.
+			if res.Pulled, err = repo.Pull(ctx, "Sync nomadic data", "local"); err != nil {
+				return err
+			}
+			merged, err := log.Merge(ctx, a.store, since)
+			if err != nil {
+				return err
+			}
+			res.Changed, res.Deleted = merged.Changed, merged.Deleted
+			for _, c := range merged.Conflicts {
+				res.Conflicts = append(res.Conflicts, api.SyncConflict{Kind: c.Kind, ID: c.ID, Name: c.Name, Field: c.Field,
+					Kept: c.Kept, Lost: c.Lost, KeptBy: c.KeptBy, LostBy: c.LostBy, At: c.At})
+			}
+			if err := a.store.Checkpoint(ctx); err != nil {
+				return err
+			}
+			if _, err := repo.Commit(ctx, "Merge nomadic data from other devices"); err != nil {
+				return err
+			}
+			if s, err := repo.Status(ctx); err == nil && s.HasRemote && s.Ahead > 0 {
+				if err := repo.Push(ctx); err != nil {
+					return err
+				}
+				res.Pushed = true
+			}
+			if a.json() {
+				return printJSON(cmd, res)
+			}
+			printSyncMerge(cmd.OutOrStdout(), res)
+			return nil
+		},
+	}
+}
+
+// printSyncMerge tells what a sync merge did, with every conflict.
+func printSyncMerge(w io.Writer, res api.SyncMerge) {
+	if res.Recorded > 0 {
+		fmt.Fprintf(w, "Logged %s changed on this device (%s)\n", plural(res.Recorded, "record", "records"), res.Device)
+	}
+	if res.Pulled > 0 {
+		fmt.Fprintf(w, "Pulled %d commits\n", res.Pulled)
+	}
+	if res.Changed > 0 || res.Deleted > 0 {
+		fmt.Fprintf(w, "Merged the other devices' changes: %d changed, %d deleted\n", res.Changed, res.Deleted)
+	}
+	if res.Pushed {
+		fmt.Fprintln(w, "Pushed the merge")
+	}
+	if res.Recorded == 0 && res.Pulled == 0 && res.Changed == 0 && res.Deleted == 0 && !res.Pushed {
+		fmt.Fprintln(w, "Already in sync")
+	}
+	if len(res.Conflicts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s, settled for the later change or the deletion:\n", plural(len(res.Conflicts), "conflict", "conflicts"))
+	for _, c := range res.Conflicts {
+		kind := syncKinds[c.Kind]
+		if kind == "" {
+			kind = c.Kind
+		}
+		what := kind + " " + c.ID
+		if c.Name != "" {
+			what = fmt.Sprintf("%s %q", kind, c.Name)
+		}
+		if c.Field == "" {
+			fmt.Fprintf(w, "  %s: deleted on %s, losing the changes from %s\n", what, c.KeptBy, c.LostBy)
+			continue
+		}
+		fmt.Fprintf(w, "  %s: %s is %s from %s, not %s from %s\n", what, c.Field,
+			conflictValue(c.Kept), c.KeptBy, conflictValue(c.Lost), c.LostBy)
+	}
+}
+
+// syncKinds name the records of the lists of a dump, as conflicts give
+// them.
+var syncKinds = map[string]string{
+	"trips": "trip", "legs": "leg", "entries": "journal entry", "attachments": "attachment", "expenses": "expense",
+	"itinerary": "itinerary item", "tracks": "track", "packing": "packing item", "checkins": "check-in",
+	"segments": "segment", "recurrences": "recurring expense", "revisions": "revision", "documents": "document",
+	"highlights": "highlight", "health": "health day", "lodgings": "lodging", "prep": "prep task",
+	"categories": "category", "people": "person", "templates": "template", "packing_lists": "packing list",
+	"prep_templates": "prep template", "rules": "rule", "country_notes": "country note", "wishes": "wish",
+	"achievements": "achievement", "connectivity": "connectivity note",
+}
+
+// conflictValue shortens the JSON value of a field for the list of
+// conflicts.
+func conflictValue(v json.RawMessage) string {
+	s := string(v)
+	if s == "" || s == "null" {
+		return "unset"
+	}
+	if r := []rune(s); len(r) > 40 {
+		s = string(r[:40]) + "…"
+	}
+	return s
+}
+
+func newSyncResolveCmd(a *app) *cobra.Command {
+	var keep string
+	cmd := &cobra.Command{
+		Use:   "resolve",
+		Short: "Settle a sync conflict by keeping one side",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repo, err := a.repo()
+			if err != nil {
+				return err
+			}
+			if err := repo.Resolve(cmd.Context(), keep); err != nil {
+				return err
+			}
+			if a.json() {
+				return a.printSyncStatus(cmd, repo)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Kept the %s data and pushed it\n", keep)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&keep, "keep", "", "which side to keep: local or remote")
+	cmd.MarkFlagRequired("keep")
+	return cmd
+}
+
+// printSyncStatus prints the repository's status as JSON.
+func (a *app) printSyncStatus(cmd *cobra.Command, repo *gitsync.Repo) error {
+	s, err := repo.Status(cmd.Context())
+	if err != nil {
+		return err
+	}
+	out := api.SyncStatus{Dir: repo.Dir(), AutoSync: a.cfg.AutoSync, HasRemote: s.HasRemote, Ahead: s.Ahead,
+		Behind: s.Behind, Dirty: s.Dirty}
+	if !s.LastCommit.IsZero() {
+		out.LastCommit = &s.LastCommit
+	}
+	return printJSON(cmd, out)
+}
+
+// repo returns the data directory's repository, which must exist.
+func (a *app) repo() (*gitsync.Repo, error) {
+	repo := gitsync.Open(a.dataDir)
+	if !repo.Exists() {
+		return nil, errors.New("the data directory is not synced yet; run `nomadic sync init <url>`")
+	}
+	return repo, nil
+}
+
+// checkpoint flushes a plain database's write-ahead log into the file git
+// commits, logging its changes first once sync merge has started the
+// operation logs. Encrypted databases are always complete on disk, and
+// never logged.
+func checkpoint(ctx context.Context, dir string) error {
+	if storage.IsEncrypted(dir) {
+		return nil
+	}
+	s, err := storage.Open(ctx, dir)
+	if err != nil {
+		return err
+	}
+	err = recordOps(ctx, dir, s)
+	if err == nil {
+		err = s.Checkpoint(ctx)
+	}
+	if err != nil {
+		s.Close()
+		return err
+	}
+	return s.Close()
+}
+
+// recordOps logs the changes made to store in the operation logs of the
+// data directory dir, when it keeps them.
+func recordOps(ctx context.Context, dir string, store *storage.Store) error {
+	if !oplog.Enabled(dir) || storage.IsEncrypted(dir) {
+		return nil
+	}
+	log, err := oplog.Open(dir)
+	if err != nil {
+		return err
+	}
+	_, err = log.Record(ctx, store)
+	return err
+}
+
+// autoSync commits, and with auto_sync = push pushes, the changes a
+// command saved. Failures are reported but do not fail the command.
+func (a *app) autoSync(cmd *cobra.Command) {
+	if a.store == nil || a.store.Changes() == 0 || a.cfg.AutoSync == config.SyncOff {
+		return
+	}
+	repo := gitsync.Open(a.dataDir)
+	if !repo.Exists() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	err := recordOps(ctx, a.dataDir, a.store)
+	if err == nil {
+		err = a.store.Checkpoint(ctx)
+	}
+	if err == nil {
+		_, err = repo.Commit(ctx, "Save changes from "+cmd.CommandPath())
+	}
+	if err == nil && a.cfg.AutoSync == config.SyncPush {
+		err = repo.Push(ctx)
+	}
+	if err != nil {
+		slog.Warn("auto sync failed", "err", err)
+		fmt.Fprintln(os.Stderr, "nomadic: sync:", err)
+	}
+}
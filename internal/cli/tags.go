@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newTagsCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "tags [tag...]",
+		Short: "List tags, or what carries them",
+		Long: `Without arguments, list every tag on trips, journal entries and expenses
+with how often it is used. With tags, list the trips, entries and expenses
+carrying all of them.`,
+		Example: `  nomadic tags
+  nomadic tags food street-food`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			if len(args) == 0 {
+				tags, err := a.store.ListTags(ctx)
+				if err != nil {
+					return err
+				}
+				if a.json() {
+					out := make([]api.Tag, len(tags))
+					for i, t := range tags {
+						out[i] = api.Tag{Name: t.Name, Trips: t.Trips, Entries: t.Entries, Expenses: t.Expenses}
+					}
+					return printJSON(cmd, out)
+				}
+				fmt.Fprintln(w, "TAG\tTRIPS\tENTRIES\tEXPENSES")
+				for _, t := range tags {
+					fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", t.Name, t.Trips, t.Entries, t.Expenses)
+				}
+				return w.Flush()
+			}
+
+			tags := splitList(args)
+			tagged, err := a.store.ListTagged(ctx, tags)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Tagged{Tags: tags, Trips: apiTrips(tagged.Trips),
+					Entries: apiEntries(tagged.Entries), Expenses: apiExpenses(tagged.Expenses)})
+			}
+			fmt.Fprintln(w, "KIND\tDATE\tTITLE\tTAGS")
+			for _, t := range tagged.Trips {
+				fmt.Fprintf(w, "trip\t%s\t%s\t%s\n", a.formatDate(t.StartDate), t.Title, strings.Join(t.Tags, ", "))
+			}
+			for _, e := range tagged.Entries {
+				fmt.Fprintf(w, "entry\t%s\t%s\t%s\n", a.formatDate(e.Timestamp), e.Title, strings.Join(e.Tags, ", "))
+			}
+			for _, x := range tagged.Expenses {
+				fmt.Fprintf(w, "expense\t%s\t%.2f %s %s\t%s\n", a.formatDate(x.Timestamp), x.Amount, x.Currency,
+					x.Description, strings.Join(x.Tags, ", "))
+			}
+			return w.Flush()
+		},
+	}
+}
@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newTemplateCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "Save trips as templates for similar trips",
+		Long: `A template keeps what similar trips share: destinations, length, budget
+and category budgets, notes, tags, a packing list and the itinerary, with
+days counted from the start of the trip. Create a trip from one with
+` + "`nomadic trip add --template <name>`" + `.`,
+	}
+	cmd.AddCommand(newTemplateSaveCmd(a), newTemplateListCmd(a), newTemplateShowCmd(a), newTemplateDeleteCmd(a))
+	return cmd
+}
+
+func newTemplateSaveCmd(a *app) *cobra.Command {
+	var (
+		tripRef, name string
+		packing       []string
+	)
+	cmd := &cobra.Command{
+		Use:   "save",
+		Short: "Save a trip as a template",
+		Long: `Save a trip as a template, including its itinerary and packing list.
+--pack adds items to the template's packing list only, as "category: name"
+or just a name. Saving under the name of an existing template replaces it.`,
+		Example: `  nomadic template save --trip "Ski weekend" --name ski --pack "Gear: goggles,ski pass"`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			trip, err := resolveTrip(ctx, a.store, tripRef)
+			if err != nil {
+				return err
+			}
+			items, err := a.store.ListItineraryByTrip(ctx, trip.ID)
+			if err != nil {
+				return err
+			}
+			if name = strings.TrimSpace(name); name == "" {
+				name = trip.Title
+			}
+			packed, err := a.store.ListPackingByTrip(ctx, trip.ID)
+			if err != nil {
+				return err
+			}
+			legs, err := a.store.ListLegsByTrip(ctx, trip.ID)
+			if err != nil {
+				return err
+			}
+			tpl := models.NewTemplate(name, trip, items, packed, legs)
+			for _, p := range splitList(packing) {
+				tpl.Packing = append(tpl.Packing, models.ParsePackingItem(p))
+			}
+
+			templates, err := a.store.ListTemplates(ctx)
+			if err != nil {
+				return err
+			}
+			verb := "Saved"
+			for _, t := range templates {
+				if strings.EqualFold(t.Name, name) {
+					tpl.ID, tpl.CreatedAt, verb = t.ID, t.CreatedAt, "Updated"
+				}
+			}
+			if err := a.store.SaveTemplate(ctx, tpl); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTemplate(tpl))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s template %q from %q (itinerary items: %d)\n", verb, tpl.Name, trip.Title,
+				len(tpl.Itinerary))
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&tripRef, "trip", "", "trip to save (ID, title or destination)")
+	f.StringVar(&name, "name", "", "template name (default the trip's title)")
+	f.StringSliceVar(&packing, "pack", nil, "extra packing list item as \"category: name\"; repeat or separate with commas")
+	return cmd
+}
+
+func newTemplateListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List templates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templates, err := a.store.ListTemplates(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.Template, len(templates))
+				for i, t := range templates {
+					out[i] = apiTemplate(t)
+				}
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tDAYS\tDESTINATIONS\tBUDGET\tITINERARY\tPACKING")
+			for _, t := range templates {
+				days, budget := "-", "-"
+				if t.Days > 0 {
+					days = fmt.Sprint(t.Days)
+				}
+				if t.Budget > 0 {
+					budget = strings.TrimSpace(fmt.Sprintf("%.2f %s", t.Budget, t.BudgetCurrency))
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\n", t.Name, days, strings.Join(t.Locations, ", "), budget,
+					len(t.Itinerary), len(t.Packing))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newTemplateShowCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show a template",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			t, err := resolveTemplate(cmd.Context(), a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTemplate(t))
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "%s\n\n", t.Name)
+			fmt.Fprintf(out, "Destinations: %s\n", strings.Join(t.Locations, ", "))
+			if t.Days > 0 {
+				fmt.Fprintf(out, "Length:       %d days\n", t.Days)
+			}
+			if t.Budget > 0 {
+				fmt.Fprintf(out, "Budget:       %s\n", strings.TrimSpace(fmt.Sprintf("%.2f %s", t.Budget, t.BudgetCurrency)))
+			}
+			for _, c := range slices.Sorted(maps.Keys(t.CategoryBudgets)) {
+				fmt.Fprintf(out, "  %-11s %.2f\n", c+":", t.CategoryBudgets[c])
+			}
+			if len(t.Tags) > 0 {
+				fmt.Fprintf(out, "Tags:         %s\n", strings.Join(t.Tags, ", "))
+			}
+			if t.Notes != "" {
+				fmt.Fprintf(out, "Notes:        %s\n", t.Notes)
+			}
+			if len(t.Packing) > 0 {
+				fmt.Fprintln(out, "\nPacking list:")
+				for _, p := range t.Packing {
+					if p.Category != "" {
+						fmt.Fprintf(out, "  - %s: %s\n", p.Category, p.Name)
+					} else {
+						fmt.Fprintf(out, "  - %s\n", p.Name)
+					}
+				}
+			}
+			if len(t.Legs) > 0 {
+				fmt.Fprintln(out, "\nLegs:")
+				for _, l := range t.Legs {
+					line := fmt.Sprintf("  Day %-3d %s", l.Day+1, l.Location)
+					if l.Days > 0 {
+						line += fmt.Sprintf(" for %d days", l.Days)
+					}
+					if l.Transport != "" {
+						line += " by " + l.Transport
+					}
+					fmt.Fprintln(out, line)
+				}
+			}
+			if len(t.Itinerary) > 0 {
+				fmt.Fprintln(out, "\nItinerary:")
+				for _, it := range t.Itinerary {
+					line := fmt.Sprintf("  Day %-3d %5s  %s", it.Day+1, it.Time, it.Title)
+					if it.Place != "" {
+						line += " @ " + it.Place
+					}
+					fmt.Fprintln(out, line)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func newTemplateDeleteCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a template",
+		Long:  "Delete a template. Trips created from it are kept.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTemplate(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteTemplate(ctx, t.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "template", ID: t.ID, Name: t.Name})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted template %q\n", t.Name)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/gpx"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newTrackCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "track",
+		Short: "Import GPS tracks and show their statistics",
+	}
+	cmd.AddCommand(newTrackImportCmd(a), newTrackListCmd(a), newTrackDeleteCmd(a))
+	return cmd
+}
+
+func newTrackImportCmd(a *app) *cobra.Command {
+	var trip, entry string
+	cmd := &cobra.Command{
+		Use:   "import <file.gpx>...",
+		Short: "Import GPX tracks into a trip",
+		Long: `Import GPX files recorded by a GPS watch or phone.
+
+Distance, elevation gain and loss, and duration are computed from the track
+points and shown with the trip. With --entry the track is also linked to a
+journal entry, named by ID or title.`,
+		Example: `  nomadic track import --trip patagonia fitz-roy.gpx
+  nomadic track import --entry "Laguna de los Tres" Activity_1234.gpx`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			var (
+				t   *models.Trip
+				e   *models.Entry
+				err error
+			)
+			if entry != "" {
+				if e, err = resolveEntry(ctx, a.store, trip, entry); err != nil {
+					return err
+				}
+				if t, err = a.store.GetTrip(ctx, e.TripID); err != nil {
+					return err
+				}
+			} else if t, err = resolveTrip(ctx, a.store, trip); err != nil {
+				return err
+			}
+			imported := []api.Track{}
+			for _, path := range args {
+				track, err := gpx.ReadTrack(path, t.ID)
+				if err != nil {
+					return err
+				}
+				if e != nil {
+					track.EntryID = e.ID
+				}
+				if err := a.store.SaveTrack(ctx, track); err != nil {
+					return err
+				}
+				if a.json() {
+					imported = append(imported, apiTrack(track))
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Imported %q into %q: %s, ↑%.0f m ↓%.0f m, %s\n", track.Name, t.Title,
+					gpx.FormatDistance(track.Distance), track.Ascent, track.Descent, gpx.FormatDuration(track.Duration()))
+				if track.Place != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), "Starts near %s\n", track.Place)
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, imported)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	cmd.Flags().StringVar(&entry, "entry", "", "link the tracks to this journal entry")
+	return cmd
+}
+
+func newTrackListCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a trip's tracks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			tracks, err := a.store.ListTracksByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTracks(tracks))
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tDATE\tNAME\tDISTANCE\tASCENT\tDESCENT\tDURATION\tPLACE")
+			var distance, ascent, descent float64
+			for _, tr := range tracks {
+				date := ""
+				if tr.StartedAt != nil {
+					date = a.formatDate(*tr.StartedAt)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.0f m\t%.0f m\t%s\t%s\n", tr.ID, date, tr.Name,
+					gpx.FormatDistance(tr.Distance), tr.Ascent, tr.Descent, gpx.FormatDuration(tr.Duration()), tr.Place)
+				distance, ascent, descent = distance+tr.Distance, ascent+tr.Ascent, descent+tr.Descent
+			}
+			if len(tracks) > 1 {
+				fmt.Fprintf(w, "\t\tTotal\t%s\t%.0f m\t%.0f m\t\t\n", gpx.FormatDistance(distance), ascent, descent)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+func newTrackDeleteCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <track-id>",
+		Short: "Delete an imported track",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := a.store.DeleteTrack(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "track", ID: args[0]})
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), "Deleted track", args[0])
+			return nil
+		},
+	}
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/stats"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newTransportCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "transport",
+		Short: "Log the journeys of a trip and estimate their carbon footprint",
+		Long: `A transport segment is one journey of a trip by a single mode, such as a
+flight or a train ride. Each is logged with its distance, or with where it
+goes from and to, whose distance is then estimated from the places dataset.
+
+Emissions are approximate, from per-mode factors in kg of CO₂ equivalent
+per passenger-km: flight 0.246, car 0.168 (the whole car), train 0.035,
+bus 0.027, ferry 0.019, and none for bikes and walking. Totals are shown
+with the trip and by nomadic stats.`,
+	}
+	cmd.AddCommand(newTransportAddCmd(a), newTransportListCmd(a), newTransportDeleteCmd(a))
+	return cmd
+}
+
+func newTransportAddCmd(a *app) *cobra.Command {
+	var (
+		trip, mode, from, to, date, notes string
+		distance                          float64
+	)
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Log a journey",
+		Example: `  nomadic transport add --trip japan --mode flight --from Lisbon --to Tokyo --date 2025-04-01
+  nomadic transport add --mode car --distance 120 --notes "Day trip to Nikko"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if mode == "" {
+				return errors.New("--mode is required")
+			}
+			m, err := models.ParseTransport(mode)
+			if err != nil {
+				return fmt.Errorf("--mode: %w", err)
+			}
+			if distance < 0 {
+				return errors.New("--distance must not be negative")
+			}
+			day := today()
+			if date != "" {
+				if day, err = a.parseDay("--date", date); err != nil {
+					return err
+				}
+			}
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			seg := models.NewSegment(t.ID, m, day)
+			seg.Origin, seg.Destination = strings.TrimSpace(from), strings.TrimSpace(to)
+			seg.Notes, seg.Distance = strings.TrimSpace(notes), distance
+			estimated := false
+			if !cmd.Flags().Changed("distance") {
+				if seg.Distance, estimated = stats.EstimateDistance(m, seg.Origin, seg.Destination); !estimated {
+					return errors.New("give --distance, or --from and --to in the places dataset to estimate it")
+				}
+			}
+			if err := a.store.SaveSegment(ctx, seg); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiSegment(seg))
+			}
+			if seg.Route() == "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Logged %s on %q: %s\n", seg, t.Title, segmentCO2(seg))
+				return nil
+			}
+			about := ""
+			if estimated {
+				about = "about "
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged %s on %q: %s%.0f km, %s\n", seg, t.Title, about, seg.Distance,
+				segmentCO2(seg))
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	f.StringVar(&mode, "mode", "", "how the journey is made: "+strings.Join(models.Transports, ", "))
+	f.StringVar(&from, "from", "", "where the journey starts, such as a city")
+	f.StringVar(&to, "to", "", "where the journey ends")
+	f.Float64Var(&distance, "distance", 0, "distance in km (default: estimated from --from and --to)")
+	f.StringVar(&date, "date", "", "day of the journey, YYYY-MM-DD (default today)")
+	f.StringVar(&notes, "notes", "", "notes, such as a flight number")
+	return cmd
+}
+
+// segmentCO2 formats the estimated emissions of seg, or says there is no
+// estimate for its mode.
+func segmentCO2(seg *models.Segment) string {
+	kg, ok := seg.CO2()
+	if !ok {
+		return "no CO₂ estimate for " + seg.Mode
+	}
+	return "~" + stats.FormatCO2(kg)
+}
+
+func newTransportListCmd(a *app) *cobra.Command {
+	var trip string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List a trip's journeys with their distance and emissions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, trip)
+			if err != nil {
+				return err
+			}
+			segments, err := a.store.ListSegmentsByTrip(ctx, t.ID)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.Segment, len(segments))
+				for i, seg := range segments {
+					out[i] = apiSegment(seg)
+				}
+				return printJSON(cmd, out)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tDATE\tMODE\tROUTE\tDISTANCE\tCO2")
+			for _, seg := range segments {
+				co2 := "-"
+				if kg, ok := seg.CO2(); ok {
+					co2 = stats.FormatCO2(kg)
+				}
+				route := seg.Route()
+				if route == "" {
+					route = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.0f km\t%s\n", seg.ID, a.formatDate(seg.Date), seg.Mode, route,
+					seg.Distance, co2)
+			}
+			if len(segments) > 1 {
+				f := stats.ComputeFootprint(segments)
+				fmt.Fprintf(w, "\t\t\tTotal\t%.0f km\t%s\n", f.Distance, stats.FormatCO2(f.CO2))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVar(&trip, "trip", "", "trip ID, title or destination (default: the trip in progress)")
+	return cmd
+}
+
+func newTransportDeleteCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <segment-id>",
+		Short: "Delete a logged journey",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			seg, err := a.store.GetSegment(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteSegment(ctx, seg.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "segment", ID: seg.ID, Name: seg.String()})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted %s\n", seg)
+			return nil
+		},
+	}
+}
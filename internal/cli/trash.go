@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+func newTrashCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Restore or purge deleted trips, entries and expenses",
+		Long: `Trips, journal entries and expenses deleted in the TUI go to the trash,
+trips with everything recorded on them, until they are restored or purged
+for good. Restoring an entry or expense needs its trip, so restore a
+deleted trip first.`,
+	}
+	cmd.AddCommand(newTrashListCmd(a), newTrashRestoreCmd(a), newTrashPurgeCmd(a), newTrashEmptyCmd(a))
+	return cmd
+}
+
+func newTrashListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List what is in the trash, most recently deleted first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trash, err := a.store.ListTrash(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrash(trash))
+			}
+			if len(trash) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "The trash is empty.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tKIND\tTITLE\tDELETED")
+			for _, t := range trash {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s %s\n", t.ID, t.Kind, t.Title, a.formatDate(t.DeletedAt), t.DeletedAt.Format("15:04"))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newTrashRestoreCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:     "restore <id or title>",
+		Short:   "Take something out of the trash",
+		Example: `  nomadic trash restore Tsukiji`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrashed(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.RestoreTrashed(ctx, t.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrashed(t))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored %s %q\n", t.Kind, t.Title)
+			return nil
+		},
+	}
+}
+
+func newTrashPurgeCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "purge <id or title>",
+		Short: "Delete something in the trash for good",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrashed(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.PurgeTrashed(ctx, t.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrashed(t))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Purged %s %q\n", t.Kind, t.Title)
+			return nil
+		},
+	}
+}
+
+func newTrashEmptyCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "empty",
+		Short: "Delete everything in the trash for good",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			trash, err := a.store.ListTrash(ctx)
+			if err != nil {
+				return err
+			}
+			for _, t := range trash {
+				if err := a.store.PurgeTrashed(ctx, t.ID); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrash(trash))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Purged from the trash: %d\n", len(trash))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,395 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+func newTripCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trip",
+		Short: "Create and list trips",
+	}
+	cmd.AddCommand(newTripAddCmd(a), newTripListCmd(a), newTripCloneCmd(a), newTripBudgetCmd(a), newTripLegCmd(a), newTripCompanionsCmd(a), newTripFlightsCmd(a),
+		newTripArchiveCmd(a, false), newTripArchiveCmd(a, true), newTripRateCmd(a), newTripYearlyCmd(a), newTripPublicCmd(a, false), newTripPublicCmd(a, true),
+		newTripCompareCmd(a), newTripFieldsCmd(a))
+	return cmd
+}
+
+func newTripAddCmd(a *app) *cobra.Command {
+	var (
+		name         string
+		destinations []string
+		start, end   string
+		budget       float64
+		notes        string
+		tags         []string
+		companions   []string
+		templateRef  string
+		fields       []string
+	)
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Create a trip",
+		Example: `  nomadic trip add --name "Japan 2025" --destination Tokyo,Kyoto --start 2025-04-01 --end 2025-04-14
+  nomadic trip add --name "Ski weekend" --destination Chamonix --budget 600 --tags ski,winter
+  nomadic trip add --name "Ski weekend 2" --template ski --start 2026-02-06
+  nomadic trip add --name "India 2026" --destination Delhi --field visa=IN-20931`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if strings.TrimSpace(name) == "" {
+				return errors.New("--name is required")
+			}
+			var tpl *models.Template
+			if templateRef != "" {
+				var err error
+				if tpl, err = resolveTemplate(ctx, a.store, templateRef); err != nil {
+					return err
+				}
+			}
+			locations := splitList(destinations)
+			if len(locations) == 0 && tpl != nil {
+				locations = tpl.Locations
+			}
+			if len(locations) == 0 {
+				return errors.New("at least one --destination is required")
+			}
+			startDate := time.Now()
+			if start != "" {
+				var err error
+				if startDate, err = a.parseDay("--start", start); err != nil {
+					return err
+				}
+			} else {
+				y, m, d := startDate.Date()
+				startDate = time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+			}
+			trip := models.NewTrip(strings.TrimSpace(name), locations, startDate)
+			plan := &models.TripPlan{Trip: trip}
+			if tpl != nil {
+				plan = tpl.NewTrip(trip.Title, startDate)
+				trip = plan.Trip
+				trip.Locations = locations
+			}
+			if end != "" {
+				endDate, err := a.parseDay("--end", end)
+				if err != nil {
+					return err
+				}
+				if endDate.Before(startDate) {
+					return errors.New("--end must not be before --start")
+				}
+				trip.EndDate = &endDate
+			}
+			if budget < 0 {
+				return errors.New("--budget must not be negative")
+			}
+			// Flags given explicitly win over the template.
+			f := cmd.Flags()
+			if tpl == nil || f.Changed("budget") {
+				trip.Budget = budget
+			}
+			if tpl == nil || f.Changed("notes") {
+				trip.Notes = notes
+			}
+			if tpl == nil || f.Changed("tags") {
+				trip.Tags = splitList(tags)
+			}
+			people, err := models.ParseCompanions(companions)
+			if err != nil {
+				return fmt.Errorf("--companions: %w", err)
+			}
+			trip.Companions = people
+			if err := a.setTripFields(trip, fields); err != nil {
+				return fmt.Errorf("--field: %w", err)
+			}
+
+			if err := a.store.SaveTripPlan(ctx, plan); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrip(trip))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created trip %q (%s)\n", trip.Title, trip.ID)
+			for _, l := range trip.Locations {
+				if p, ok := places.Lookup(l); ok {
+					fmt.Fprintf(cmd.OutOrStdout(), "  %s: %s, %s\n", l, p.CountryName, p.Timezone)
+				} else {
+					fmt.Fprintf(cmd.OutOrStdout(), "  %s: not in the places dataset\n", l)
+				}
+			}
+			return a.printCountryNotes(ctx, cmd.OutOrStdout(), trip.Locations)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&name, "name", "", "trip name")
+	f.StringSliceVar(&destinations, "destination", nil, "destination; repeat or separate with commas")
+	f.StringVar(&start, "start", "", "start date, YYYY-MM-DD (default today)")
+	f.StringVar(&end, "end", "", "end date, YYYY-MM-DD")
+	f.Float64Var(&budget, "budget", 0, "total budget")
+	f.StringVar(&notes, "notes", "", "free-form notes")
+	f.StringSliceVar(&tags, "tags", nil, "tags; repeat or separate with commas")
+	f.StringSliceVar(&companions, "companions", nil, "people traveling along, who share expenses; repeat or separate with commas")
+	f.StringVar(&templateRef, "template", "", "start from a template; the other flags override it")
+	f.StringArrayVar(&fields, "field", nil, "set a trip field, key=value; repeat for more (see nomadic trip fields)")
+	return cmd
+}
+
+func newTripCloneCmd(a *app) *cobra.Command {
+	var tripRef, name, start string
+	cmd := &cobra.Command{
+		Use:   "clone",
+		Short: "Copy a trip to new dates",
+		Long: `Copy a trip's destinations, budget, notes, tags and itinerary to a new
+trip starting on --start. The end date and itinerary shift by the same
+number of days. Journal entries, expenses and tracks are not copied.`,
+		Example: `  nomadic trip clone --trip "Ski weekend" --start 2026-02-06 --name "Ski weekend 2"`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if start == "" {
+				return errors.New("--start is required")
+			}
+			startDate, err := a.parseDay("--start", start)
+			if err != nil {
+				return err
+			}
+			trip, err := resolveTrip(ctx, a.store, tripRef)
+			if err != nil {
+				return err
+			}
+			if name = strings.TrimSpace(name); name == "" {
+				name = trip.Title + " (copy)"
+			}
+			clone, err := a.store.CloneTrip(ctx, trip.ID, name, startDate)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrip(clone))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created trip %q (%s) from %q\n", clone.Title, clone.ID, trip.Title)
+			return a.printCountryNotes(ctx, cmd.OutOrStdout(), clone.Locations)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&tripRef, "trip", "", "trip to copy (ID, title or destination)")
+	f.StringVar(&name, "name", "", `name of the new trip (default the original's with " (copy)")`)
+	f.StringVar(&start, "start", "", "start date of the new trip, YYYY-MM-DD")
+	return cmd
+}
+
+func newTripListCmd(a *app) *cobra.Command {
+	var (
+		tags     []string
+		archived bool
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List trips",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			trips, err := a.store.ListTrips(cmd.Context())
+			if err != nil {
+				return err
+			}
+			trips = slices.DeleteFunc(trips, func(t *models.Trip) bool {
+				return !models.HasTags(t.Tags, tags) || t.Archived() && !archived
+			})
+			if a.json() {
+				return printJSON(cmd, apiTrips(trips))
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTITLE\tSTART\tEND\tDESTINATIONS\tTAGS")
+			for _, t := range trips {
+				endDate := "-"
+				if t.EndDate != nil {
+					endDate = a.formatDate(*t.EndDate)
+				}
+				title := t.Title
+				if t.Archived() {
+					title += " (archived)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.ID, title, a.formatDate(t.StartDate),
+					endDate, strings.Join(t.Locations, ", "), strings.Join(t.Tags, ", "))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringSliceVar(&tags, "tag", nil, "only trips with this tag; repeat to require several")
+	cmd.Flags().BoolVar(&archived, "archived", false, "include archived trips")
+	return cmd
+}
+
+// newTripArchiveCmd archives a trip, or with unarchive takes it out of the
+// archive again.
+func newTripArchiveCmd(a *app, unarchive bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive <trip>",
+		Short: "Archive a finished trip, leaving it out of the lists of trips",
+		Long: `Archive a finished trip. Archived trips are left out of the lists of
+trips, in the TUI and of ` + "`nomadic trip list`" + `, but their entries are still
+found by search and they still count in stats and on the map. List them
+with ` + "`nomadic trip list --archived`" + ` and bring one back with
+` + "`nomadic trip unarchive`" + `.`,
+		Example: `  nomadic trip archive "Japan 2025"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			verb := "Archived"
+			if unarchive {
+				t.ArchivedAt, verb = nil, "Unarchived"
+			} else if !t.Archived() {
+				now := time.Now()
+				t.ArchivedAt = &now
+			}
+			if err := a.store.SaveTrip(ctx, t); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrip(t))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s trip %q\n", verb, t.Title)
+			return nil
+		},
+	}
+	if unarchive {
+		cmd.Use, cmd.Short, cmd.Long, cmd.Example = "unarchive <trip>", "Take a trip out of the archive", "", `  nomadic trip unarchive "Japan 2025"`
+	}
+	return cmd
+}
+
+// newTripPublicCmd marks a trip public for the website of nomadic publish,
+// or with private takes it off again.
+func newTripPublicCmd(a *app, private bool) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "public <trip>",
+		Short: "Publish a trip with all its entries on the website of nomadic publish",
+		Long: `Mark a trip public: ` + "`nomadic publish`" + ` puts it on the website with its notes
+and every journal entry but those private. Expenses and the people traveling
+along are never published. To publish only some entries of a trip, mark them public with
+` + "`nomadic journal public`" + ` instead.`,
+		Example: `  nomadic trip public "Japan 2025"`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			t, err := resolveTrip(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			t.Public = !private
+			if err := a.store.SaveTrip(ctx, t); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrip(t))
+			}
+			if private {
+				fmt.Fprintf(cmd.OutOrStdout(), "Trip %q is private; entries marked public are still published\n", t.Title)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Trip %q is public; run nomadic publish to update the website\n", t.Title)
+			return nil
+		},
+	}
+	if private {
+		cmd.Use, cmd.Short, cmd.Long, cmd.Example = "private <trip>", "Take a trip off the website of nomadic publish", "", `  nomadic trip private "Japan 2025"`
+	}
+	return cmd
+}
+
+func newTripYearlyCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "yearly <trip> <recurring|anniversary|off>",
+		Short: "Mark a trip as taken every year, or keep its anniversaries",
+		Long: `Mark a trip recurring, as one taken every year around its dates such as a
+family visit, or keep its anniversaries, such as one year since Patagonia;
+off clears either. Both come up on their day in nomadic upcoming and on
+the TUI home screen. A recurring trip counts from the latest trip of its
+title, so the trip can be cloned to each year's dates.`,
+		Example: `  nomadic trip yearly "Family visit" recurring
+  nomadic trip yearly patagonia anniversary
+  nomadic trip yearly patagonia off`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			yearly, err := models.ParseYearly(args[1])
+			if err != nil {
+				return err
+			}
+			t, err := resolveTrip(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			t.Yearly = yearly
+			if err := a.store.SaveTrip(ctx, t); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrip(t))
+			}
+			switch yearly {
+			case models.YearlyRecurring:
+				fmt.Fprintf(cmd.OutOrStdout(), "%q comes round every %s\n", t.Title, t.StartDate.Format("January 2"))
+			case models.YearlyAnniversary:
+				fmt.Fprintf(cmd.OutOrStdout(), "Keeping the anniversaries of %q every %s\n", t.Title, t.StartDate.Format("January 2"))
+			default:
+				fmt.Fprintf(cmd.OutOrStdout(), "%q no longer comes round every year\n", t.Title)
+			}
+			return nil
+		},
+	}
+}
+
+func newTripRateCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rate <trip> <rating>",
+		Short: "Rate a trip overall, from 1 to 5 stars",
+		Long: `Rate a trip overall, from 1 to 5 stars; 0 removes the rating. The rating
+is shown with the mood of the trip's entries in nomadic stats.`,
+		Example: `  nomadic trip rate "Japan 2025" 5`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			rating, err := strconv.Atoi(args[1])
+			if err == nil {
+				err = models.CheckRating(rating)
+			}
+			if err != nil {
+				return fmt.Errorf("rating %q is not from 1 to %d, or 0 for none", args[1], models.MaxRating)
+			}
+			t, err := resolveTrip(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			t.Rating = rating
+			if err := a.store.SaveTrip(ctx, t); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrip(t))
+			}
+			if rating == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed the rating of %q\n", t.Title)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Rated %q %s\n", t.Title, models.Stars(rating))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+func newUpcomingCmd(a *app) *cobra.Command {
+	var days int
+	cmd := &cobra.Command{
+		Use:   "upcoming",
+		Short: "List the recurring trips and anniversaries coming up",
+		Long: `List the days coming up that trips marked with nomadic trip yearly come
+round: recurring trips due again and the anniversaries of trips kept.
+
+A recurring trip is left out of a year a trip of its title is already
+planned for within a month of its day.`,
+		Example: `  nomadic upcoming
+  nomadic upcoming --days 365`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if days < 1 {
+				return errors.New("--days must be at least 1")
+			}
+			trips, err := a.store.ListTrips(cmd.Context())
+			if err != nil {
+				return err
+			}
+			occasions := models.Occasions(trips, time.Now(), days)
+			if a.json() {
+				return printJSON(cmd, apiOccasions(occasions))
+			}
+			out := cmd.OutOrStdout()
+			if len(occasions) == 0 {
+				fmt.Fprintf(out, "Nothing comes round in the next %s; mark trips with `nomadic trip yearly`.\n", plural(days, "day", "days"))
+				return nil
+			}
+			for _, o := range occasions {
+				fmt.Fprintf(out, "%s  %-12s %s %s\n", a.formatDate(o.Day), occasionWhen(o.In), occasionIcon(o), o)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", 30, "how many days ahead to look, from today")
+	return cmd
+}
+
+// occasionWhen says how soon an occasion is.
+func occasionWhen(in int) string {
+	switch in {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	}
+	return fmt.Sprintf("in %d days", in)
+}
+
+func occasionIcon(o models.Occasion) string {
+	if o.Anniversary() {
+		return "🎉"
+	}
+	return "🔁"
+}
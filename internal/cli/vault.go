@@ -0,0 +1,437 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/viewer"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newVaultCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "vault",
+		Aliases: []string{"papers"},
+		Short:   "Keep passports, visas, insurance and vaccination records",
+		Long: `Keep the travel documents that belong to no one trip: passports, visas,
+insurance policies and vaccination records, with their numbers, the days
+they were issued and run out, and a scan of each if wanted.
+
+Unlike a trip's documents, the vault and its scans live in the database
+itself, so they are encrypted with it by nomadic encryption enable and go
+into backups with it. For the same reason they are left out of exports and
+sync.
+
+The home screen warns of documents that run out within expiry_days, 90 by
+default, or before the end of a trip coming up or in progress; a visa
+given a --country only warns of trips going there.`,
+	}
+	cmd.AddCommand(newVaultListCmd(a), newVaultAddCmd(a), newVaultEditCmd(a), newVaultScanCmd(a), newVaultOpenCmd(a),
+		newVaultRemoveCmd(a))
+	return cmd
+}
+
+// expiryAlerts returns the alerts of the travel documents by their IDs,
+// as the home screen shows them.
+func (a *app) expiryAlerts(ctx context.Context, items []*models.VaultItem) (map[string]*models.ExpiryAlert, error) {
+	trips, err := a.store.ListTrips(ctx)
+	if err != nil {
+		return nil, err
+	}
+	countries := func(t *models.Trip) []string { return places.Countries(places.Resolve(t.Locations)) }
+	alerts := models.ExpiryAlerts(items, trips, countries, time.Now(), a.cfg.ExpiryWindow())
+	out := make(map[string]*models.ExpiryAlert, len(alerts))
+	for i := range alerts {
+		out[alerts[i].Item.ID] = &alerts[i]
+	}
+	return out, nil
+}
+
+func newVaultListCmd(a *app) *cobra.Command {
+	var expiring bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the documents in the vault, those running out first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			items, err := a.store.ListVault(ctx)
+			if err != nil {
+				return err
+			}
+			alerts, err := a.expiryAlerts(ctx, items)
+			if err != nil {
+				return err
+			}
+			if expiring {
+				kept := items[:0]
+				for _, it := range items {
+					if alerts[it.ID] != nil {
+						kept = append(kept, it)
+					}
+				}
+				items = kept
+			}
+			if a.json() {
+				out := make([]api.VaultItem, len(items))
+				for i, it := range items {
+					out[i] = apiVaultItem(it, alerts[it.ID])
+				}
+				return printJSON(cmd, out)
+			}
+			if len(items) == 0 {
+				if expiring {
+					fmt.Fprintln(cmd.OutOrStdout(), "Nothing in the vault runs out soon.")
+				} else {
+					fmt.Fprintln(cmd.OutOrStdout(), "The vault is empty; add a document with `nomadic vault add`.")
+				}
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "KIND\tTITLE\tHOLDER\tNUMBER\tEXPIRES\tSCAN\tALERT")
+			for _, it := range items {
+				holder, number, expires, scan := "-", "-", "-", "-"
+				if it.Holder != "" {
+					holder = it.Holder
+				}
+				if it.Number != "" {
+					number = it.Number
+				}
+				if it.Expires != nil {
+					expires = a.formatDate(*it.Expires)
+				}
+				if it.ScanName != "" {
+					scan = it.ScanName
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", it.Kind, it.Title, holder, number, expires, scan,
+					describeExpiry(alerts[it.ID]))
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().BoolVar(&expiring, "expiring", false, "list only the documents running out soon or before a trip")
+	return cmd
+}
+
+// describeExpiry says when a document runs out, as its alert has it, or
+// "-" without one.
+func describeExpiry(alert *models.ExpiryAlert) string {
+	if alert == nil {
+		return "-"
+	}
+	var when string
+	switch {
+	case alert.Expired():
+		when = fmt.Sprintf("expired %d days ago", -alert.In)
+	case alert.In == 0:
+		when = "expires today"
+	case alert.In == 1:
+		when = "expires tomorrow"
+	default:
+		when = fmt.Sprintf("expires in %d days", alert.In)
+	}
+	if alert.Trip != nil && !alert.Expired() {
+		when += fmt.Sprintf(", before the end of %q", alert.Trip.Title)
+	}
+	return when
+}
+
+// vaultFlags are the flags setting the details of a travel document,
+// shared by add and edit.
+type vaultFlags struct {
+	kind, holder, number, issuer, country, issued, expires, note string
+}
+
+func (vf *vaultFlags) register(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.StringVar(&vf.kind, "kind", models.VaultPassport, "passport, visa, insurance, vaccination or other")
+	f.StringVar(&vf.holder, "holder", "", "companion the document is for (default: you)")
+	f.StringVar(&vf.number, "number", "", "document, visa or policy number")
+	f.StringVar(&vf.issuer, "issuer", "", "country, authority or insurer that issued it")
+	f.StringVar(&vf.country, "country", "", "country a visa is for, by name or ISO code; \"\" for none")
+	f.StringVar(&vf.issued, "issued", "", "day it was issued, YYYY-MM-DD; \"\" for none")
+	f.StringVar(&vf.expires, "expires", "", "day it runs out, YYYY-MM-DD; \"\" for never")
+	f.StringVar(&vf.note, "note", "", "optional note, such as the emergency number of a policy")
+}
+
+// apply sets on it what the flags changed.
+func (vf *vaultFlags) apply(a *app, cmd *cobra.Command, it *models.VaultItem) error {
+	changed := cmd.Flags().Changed
+	var err error
+	if changed("kind") {
+		if it.Kind, err = models.ParseVaultKind(vf.kind); err != nil {
+			return fmt.Errorf("--kind: %w", err)
+		}
+	}
+	if changed("holder") {
+		it.Holder = strings.TrimSpace(vf.holder)
+	}
+	if changed("number") {
+		it.Number = strings.TrimSpace(vf.number)
+	}
+	if changed("issuer") {
+		it.Issuer = strings.TrimSpace(vf.issuer)
+	}
+	if changed("country") {
+		it.Country = ""
+		if v := strings.TrimSpace(vf.country); v != "" {
+			code, ok := places.CountryCode(v)
+			if !ok {
+				return fmt.Errorf("--country: unknown country %q", v)
+			}
+			it.Country = code
+		}
+	}
+	for _, d := range []struct {
+		flag, value string
+		day         **time.Time
+	}{{"issued", vf.issued, &it.Issued}, {"expires", vf.expires, &it.Expires}} {
+		if !changed(d.flag) {
+			continue
+		}
+		*d.day = nil
+		if d.value == "" {
+			continue
+		}
+		t, err := a.parseDay("--"+d.flag, d.value)
+		if err != nil {
+			return err
+		}
+		*d.day = &t
+	}
+	if changed("note") {
+		it.Note = strings.TrimSpace(vf.note)
+	}
+	if it.Issued != nil && it.Expires != nil && it.Expires.Before(*it.Issued) {
+		return errors.New("a document cannot run out before it was issued")
+	}
+	return nil
+}
+
+// attachScan keeps the file at path as the scan of it.
+func (a *app) attachScan(ctx context.Context, it *models.VaultItem, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 {
+		return fmt.Errorf("%s is empty", path)
+	}
+	name := filepath.Base(path)
+	contentType := strings.TrimSuffix(mime.TypeByExtension(strings.ToLower(filepath.Ext(name))), "; charset=utf-8")
+	if err := a.store.SetVaultScan(ctx, it.ID, name, contentType, data); err != nil {
+		return err
+	}
+	it.ScanName, it.ScanType, it.ScanSize = name, contentType, int64(len(data))
+	return nil
+}
+
+func newVaultAddCmd(a *app) *cobra.Command {
+	var (
+		vf   vaultFlags
+		scan string
+	)
+	cmd := &cobra.Command{
+		Use:   "add <title>",
+		Short: "Keep a travel document in the vault",
+		Example: `  nomadic vault add "Passport" --number X1234567 --issuer Germany --expires 2027-02-14 --scan ~/scans/passport.pdf
+  nomadic vault add "Vietnam e-visa" --kind visa --country VN --expires 2026-12-01
+  nomadic vault add "Travel insurance" --kind insurance --number POL-88121 --note "Emergency line +44 20 7946 0000"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			it := models.NewVaultItem(models.VaultPassport, args[0])
+			if it.Title == "" {
+				return errors.New("title is required")
+			}
+			if err := vf.apply(a, cmd, it); err != nil {
+				return err
+			}
+			if err := a.store.SaveVaultItem(ctx, it); err != nil {
+				return err
+			}
+			if scan != "" {
+				if err := a.attachScan(ctx, it, scan); err != nil {
+					return err
+				}
+			}
+			if a.json() {
+				return printJSON(cmd, apiVaultItem(it, nil))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Kept %s in the vault (%s)\n", it.Title, it.ID)
+			return nil
+		},
+	}
+	vf.register(cmd)
+	cmd.Flags().StringVar(&scan, "scan", "", "file to keep as a scan of the document, such as a PDF or photo")
+	return cmd
+}
+
+func newVaultEditCmd(a *app) *cobra.Command {
+	var (
+		title string
+		vf    vaultFlags
+	)
+	cmd := &cobra.Command{
+		Use:     "edit <document>",
+		Short:   "Change a document in the vault",
+		Long:    `Change a document in the vault, named by its ID, number or title; what is not given stays as it was.`,
+		Example: `  nomadic vault edit passport --number Y7654321 --issued 2026-10-01 --expires 2036-09-30`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			it, err := resolveVaultItem(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("title") {
+				if it.Title = strings.TrimSpace(title); it.Title == "" {
+					return errors.New("title is required")
+				}
+			}
+			if err := vf.apply(a, cmd, it); err != nil {
+				return err
+			}
+			if err := a.store.SaveVaultItem(ctx, it); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiVaultItem(it, nil))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved %s\n", it.Title)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&title, "title", "", "new title")
+	vf.register(cmd)
+	return cmd
+}
+
+func newVaultScanCmd(a *app) *cobra.Command {
+	var (
+		out    string
+		remove bool
+	)
+	cmd := &cobra.Command{
+		Use:   "scan <document> [file]",
+		Short: "Keep, save or remove the scan of a document",
+		Long: `Keep file as the scan of a document in the vault, replacing the one kept
+before. The file is read into the database; the original can be deleted.
+
+With --out, save the scan kept to a file instead, and with --remove,
+delete it.`,
+		Example: `  nomadic vault scan passport ~/scans/passport.pdf
+  nomadic vault scan passport --out passport.pdf
+  nomadic vault scan "Vietnam e-visa" --remove`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			modes := 0
+			for _, set := range []bool{len(args) == 2, out != "", remove} {
+				if set {
+					modes++
+				}
+			}
+			if modes != 1 {
+				return errors.New("give one of a file to keep, --out or --remove")
+			}
+			it, err := resolveVaultItem(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			switch {
+			case remove:
+				if err := a.store.SetVaultScan(ctx, it.ID, "", "", nil); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed the scan of %s\n", it.Title)
+			case out != "":
+				data, err := a.store.VaultScan(ctx, it.ID)
+				if errors.Is(err, storage.ErrNotFound) {
+					return fmt.Errorf("no scan is kept with %s", it.Title)
+				}
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(out, data, 0o600); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Saved the scan of %s to %s\n", it.Title, out)
+			default:
+				if err := a.attachScan(ctx, it, args[1]); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Kept %s as the scan of %s\n", it.ScanName, it.Title)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&out, "out", "o", "", "save the scan kept to this file")
+	cmd.Flags().BoolVar(&remove, "remove", false, "delete the scan kept")
+	return cmd
+}
+
+func newVaultOpenCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "open <document>",
+		Short: "Open the scan of a document in the default viewer",
+		Long: `Open the scan of a document in the default viewer. The viewer is handed a
+copy only you can read in the temporary directory, which is left there
+unencrypted until the system clears it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			it, err := resolveVaultItem(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			path, err := a.store.VaultScanFile(ctx, it)
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("no scan is kept with %s; add one with `nomadic vault scan`", it.Title)
+			}
+			if err != nil {
+				return err
+			}
+			if err := viewer.Open(path); err != nil {
+				return fmt.Errorf("open %s: %w", it.ScanName, err)
+			}
+			return nil
+		},
+	}
+}
+
+func newVaultRemoveCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <document>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a document and its scan from the vault",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			it, err := resolveVaultItem(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteVaultItem(ctx, it.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "vault item", ID: it.ID, Name: it.Title})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s from the vault\n", it.Title)
+			return nil
+		},
+	}
+}
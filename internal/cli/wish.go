@@ -0,0 +1,286 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/api"
+)
+
+func newWishCmd(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "wish",
+		Aliases: []string{"wishlist", "someday"},
+		Short:   "Keep a wishlist of places to go some day",
+		Long: `Keep a wishlist of places to go some day, each with notes, a rough budget,
+the months best to go in and a priority of high, medium or low. The list
+puts the most wanted first and tells which are in season now.
+
+When the time comes, promote a wish into a trip: it goes to the place,
+with the notes and budget carried over, and the wish is marked as planned.
+It is back on the list should the trip be deleted.`,
+	}
+	cmd.AddCommand(newWishListCmd(a), newWishAddCmd(a), newWishEditCmd(a), newWishRemoveCmd(a), newWishPromoteCmd(a))
+	return cmd
+}
+
+func newWishListCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the wishlist, most wanted first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			wishes, err := a.store.ListWishes(ctx)
+			if err != nil {
+				return err
+			}
+			if a.json() {
+				out := make([]api.Wish, len(wishes))
+				for i, w := range wishes {
+					out[i] = apiWish(w)
+				}
+				return printJSON(cmd, out)
+			}
+			if len(wishes) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No wishes yet; add one with `nomadic wish add`.")
+				return nil
+			}
+			trips, err := a.store.ListTrips(ctx)
+			if err != nil {
+				return err
+			}
+			titles := make(map[string]string, len(trips))
+			for _, t := range trips {
+				titles[t.ID] = t.Title
+			}
+			now := time.Now().Month()
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "PRIORITY\tPLACE\tSEASON\tBUDGET\tPLANNED")
+			for _, wish := range wishes {
+				season, budget, planned := "-", "-", "-"
+				if len(wish.Season) > 0 {
+					season = models.FormatSeason(wish.Season)
+					if wish.TripID == "" && wish.InSeason(now) {
+						season += " (now)"
+					}
+				}
+				if wish.Budget > 0 {
+					budget = fmt.Sprintf("%.2f %s", wish.Budget, wish.Currency)
+				}
+				if wish.TripID != "" {
+					planned = titles[wish.TripID]
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", wish.Priority, wish.Place, season, budget, planned)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+// wishFlags are the flags setting the details of a wish, shared by add and
+// edit.
+type wishFlags struct {
+	notes, currency, season, priority string
+	budget                            float64
+}
+
+func (wf *wishFlags) register(cmd *cobra.Command) {
+	f := cmd.Flags()
+	f.StringVar(&wf.notes, "notes", "", "notes, such as what to see or who to ask")
+	f.Float64Var(&wf.budget, "budget", 0, "rough budget of the trip; 0 for none")
+	f.StringVar(&wf.currency, "currency", "", "three-letter currency code of the budget, e.g. EUR (default from config)")
+	f.StringVar(&wf.season, "season", "", `months best to go in, such as "apr-may, oct"; "any" for none`)
+	f.StringVar(&wf.priority, "priority", models.PriorityMedium, "high, medium or low")
+}
+
+// apply sets on w what the flags changed.
+func (wf *wishFlags) apply(a *app, cmd *cobra.Command, w *models.Wish) error {
+	changed := cmd.Flags().Changed
+	var err error
+	if changed("notes") {
+		w.Notes = strings.TrimSpace(wf.notes)
+	}
+	if changed("budget") {
+		w.Budget = wf.budget
+	}
+	if changed("currency") || (w.Budget > 0 && w.Currency == "") {
+		currency := wf.currency
+		if currency == "" {
+			currency = a.cfg.DefaultCurrency
+		}
+		if w.Currency, err = models.ParseCurrency(currency); err != nil {
+			return fmt.Errorf("--currency: %w", err)
+		}
+	}
+	if changed("season") {
+		if w.Season, err = models.ParseSeason(wf.season); err != nil {
+			return fmt.Errorf("--season: %w", err)
+		}
+	}
+	if changed("priority") {
+		if w.Priority, err = models.ParsePriority(wf.priority); err != nil {
+			return fmt.Errorf("--priority: %w", err)
+		}
+	}
+	return w.Validate()
+}
+
+func newWishAddCmd(a *app) *cobra.Command {
+	var wf wishFlags
+	cmd := &cobra.Command{
+		Use:   "add <place>",
+		Short: "Add a place to the wishlist",
+		Example: `  nomadic wish add Patagonia --priority high --season nov-mar --budget 4000 --notes "W trek, book refugios early"
+  nomadic wish add "Kyoto, Japan" --season "mar-apr, nov"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := models.NewWish(args[0])
+			if err := wf.apply(a, cmd, w); err != nil {
+				return err
+			}
+			if err := a.store.SaveWish(cmd.Context(), w); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiWish(w))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %s to the wishlist (%s)\n", w.Place, w.ID)
+			return nil
+		},
+	}
+	wf.register(cmd)
+	return cmd
+}
+
+func newWishEditCmd(a *app) *cobra.Command {
+	var (
+		place string
+		wf    wishFlags
+	)
+	cmd := &cobra.Command{
+		Use:     "edit <wish>",
+		Short:   "Change a wish",
+		Long:    `Change a wish, named by its ID or place; what is not given stays as it was.`,
+		Example: `  nomadic wish edit patagonia --priority low --season any`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			w, err := resolveWish(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("place") {
+				w.Place = strings.TrimSpace(place)
+			}
+			if err := wf.apply(a, cmd, w); err != nil {
+				return err
+			}
+			if err := a.store.SaveWish(ctx, w); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiWish(w))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Saved the wish to go to %s\n", w.Place)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&place, "place", "", "new place")
+	wf.register(cmd)
+	return cmd
+}
+
+func newWishRemoveCmd(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <wish>",
+		Aliases: []string{"rm"},
+		Short:   "Take a place off the wishlist",
+		Long:    `Take a place off the wishlist. A trip planned from it is kept.`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			w, err := resolveWish(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := a.store.DeleteWish(ctx, w.ID); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, api.Deleted{Kind: "wish", ID: w.ID, Name: w.Place})
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s from the wishlist\n", w.Place)
+			return nil
+		},
+	}
+}
+
+func newWishPromoteCmd(a *app) *cobra.Command {
+	var name, start, end string
+	cmd := &cobra.Command{
+		Use:   "promote <wish>",
+		Short: "Plan a trip from a wish",
+		Long: `Create a trip to the place of a wish, with its notes and budget, and mark
+the wish as planned by it.`,
+		Example: `  nomadic wish promote patagonia --start 2026-11-20 --end 2026-12-10`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			w, err := resolveWish(ctx, a.store, args[0])
+			if err != nil {
+				return err
+			}
+			if w.TripID != "" {
+				if t, err := a.store.GetTrip(ctx, w.TripID); err == nil {
+					return fmt.Errorf("%s is planned as %q already", w.Place, t.Title)
+				}
+			}
+			startDate := time.Now()
+			if start != "" {
+				if startDate, err = a.parseDay("--start", start); err != nil {
+					return err
+				}
+			} else {
+				y, m, d := startDate.Date()
+				startDate = time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+			}
+			if name = strings.TrimSpace(name); name == "" {
+				name = w.Place
+			}
+			trip := w.NewTrip(name, startDate)
+			if end != "" {
+				endDate, err := a.parseDay("--end", end)
+				if err != nil {
+					return err
+				}
+				if endDate.Before(startDate) {
+					return errors.New("--end must not be before --start")
+				}
+				trip.EndDate = &endDate
+			}
+			if err := a.store.PromoteWish(ctx, w, &models.TripPlan{Trip: trip}); err != nil {
+				return err
+			}
+			if a.json() {
+				return printJSON(cmd, apiTrip(trip))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Created trip %q (%s) from the wish to go to %s\n", trip.Title, trip.ID, w.Place)
+			if len(w.Season) > 0 && !w.InSeason(startDate.Month()) {
+				fmt.Fprintf(cmd.OutOrStdout(), "Note: %s is best in %s\n", w.Place, models.FormatSeason(w.Season))
+			}
+			return a.printCountryNotes(ctx, cmd.OutOrStdout(), trip.Locations)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&name, "name", "", "trip name (default the place)")
+	f.StringVar(&start, "start", "", "start date, YYYY-MM-DD (default today)")
+	f.StringVar(&end, "end", "", "end date, YYYY-MM-DD")
+	return cmd
+}
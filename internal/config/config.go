@@ -0,0 +1,1285 @@
+// Package config loads and saves the user's nomadic settings from
+// ~/.config/nomadic/config.toml.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/girdharshubham/nomadic/internal/i18n"
+	"github.com/girdharshubham/nomadic/internal/theme"
+	"github.com/girdharshubham/nomadic/internal/viewer"
+)
+
+// FileName is the name of the configuration file in the config directory.
+const FileName = "config.toml"
+
+// Config holds every user-tunable setting. Zero values are replaced by
+// Default's when a file is loaded.
+type Config struct {
+	DefaultCurrency string            `toml:"default_currency"`
+	HomeCurrency    string            `toml:"home_currency"`
+	HomeZone        string            `toml:"home_zone"`
+	DateFormat      string            `toml:"date_format"`
+	DataDir         string            `toml:"data_dir"`
+	Theme           string            `toml:"theme"`
+	Locale          string            `toml:"locale"`
+	Editor          string            `toml:"editor"`
+	ImagePreview    string            `toml:"image_preview"`
+	AutoSync        string            `toml:"auto_sync"`
+	Weather         string            `toml:"weather"`
+	Holidays        string            `toml:"holidays"`
+	Geocoder        string            `toml:"geocoder"`
+	Offline         string            `toml:"offline"`
+	Vim             string            `toml:"vim"`
+	Accessible      string            `toml:"accessible"`
+	ASCII           string            `toml:"ascii"`
+	ReadOnly        string            `toml:"read_only"`
+	DailyEntry      string            `toml:"daily_entry"`
+	Resume          string            `toml:"resume"`
+	JournalStorage  string            `toml:"journal_storage"`
+	JournalDir      string            `toml:"journal_dir"`
+	Transcriber     string            `toml:"transcriber"`
+	WhisperCommand  string            `toml:"whisper_command"`
+	WhisperModel    string            `toml:"whisper_model"`
+	TranscribeURL   string            `toml:"transcribe_url"`
+	TranscribeModel string            `toml:"transcribe_model"`
+	OCR             string            `toml:"ocr"`
+	OCRCommand      string            `toml:"ocr_command"`
+	OCRLanguage     string            `toml:"ocr_language"`
+	OCRURL          string            `toml:"ocr_url"`
+	ReceiptInbox    string            `toml:"receipt_inbox"`
+	ServeAddress    string            `toml:"serve_address"`
+	SiteTitle       string            `toml:"site_title"`
+	ShareTunnel     string            `toml:"share_tunnel"`
+	Redact          string            `toml:"redact"`
+	BackupRemote    string            `toml:"backup_remote"`
+	BackupEndpoint  string            `toml:"backup_endpoint"`
+	BackupRegion    string            `toml:"backup_region"`
+	BackupKeep      string            `toml:"backup_keep"`
+	ExpiryDays      string            `toml:"expiry_days"`
+	SMTPServer      string            `toml:"smtp_server"`
+	SMTPUser        string            `toml:"smtp_user"`
+	SMTPFrom        string            `toml:"smtp_from"`
+	DigestTo        string            `toml:"digest_to"`
+	Keys            map[string]string `toml:"keys"`
+
+	// Hooks maps events, one of HookEvents, to shell commands run when they
+	// happen, given the event as JSON on standard input.
+	Hooks map[string]string `toml:"hooks,omitempty"`
+
+	// TripFields maps the keys of the fields of their own that trips have,
+	// such as a visa number or an insurance policy, to their labels.
+	TripFields map[string]string `toml:"trip_fields,omitempty"`
+
+	// Macros are the quick actions recorded in the TUI, in [macros.<name>]
+	// tables, by their names.
+	Macros map[string]Macro `toml:"macros,omitempty"`
+
+	// CustomThemes are palettes defined in [themes.<name>] tables, usable
+	// as the theme setting alongside the built-in ones.
+	CustomThemes map[string]theme.Palette `toml:"themes,omitempty"`
+
+	// Profiles are [profiles.<name>] tables of settings, by the names of
+	// Get and Set, that the profile overrides; see Profile.
+	Profiles map[string]map[string]string `toml:"profiles,omitempty"`
+}
+
+// Values of the auto_sync setting: whether saving commits the data
+// directory to git, and whether the commit is also pushed.
+const (
+	SyncOff    = "off"
+	SyncCommit = "commit"
+	SyncPush   = "push"
+)
+
+// Values of the weather setting: whether new journal entries record the
+// weather of their day and place, and from which provider.
+const (
+	WeatherOff       = "off"
+	WeatherOpenMeteo = "open-meteo"
+)
+
+// Values of the holidays setting: whether the itinerary flags the public
+// holidays of the countries visited, from the rules bundled with nomadic
+// or from the online Nager.Date calendar, cached and falling back on the
+// bundled rules offline.
+const (
+	HolidaysOff     = "off"
+	HolidaysBundled = "bundled"
+	HolidaysNager   = "nager"
+)
+
+// Values of the geocoder setting: whether check-ins at places missing from
+// the offline places dataset, such as landmarks, look up their coordinates
+// online, and from which service.
+const (
+	GeocoderOff       = "off"
+	GeocoderNominatim = "nominatim"
+)
+
+// Values of the offline setting: whether nomadic makes no network requests
+// at all, with exchange rates, weather and holidays taken from what is
+// cached or bundled, for travelling without a connection worth waiting on.
+const (
+	OfflineOff = "off"
+	OfflineOn  = "on"
+)
+
+// Values of the vim setting: whether the TUI takes vim-style modal input,
+// with a normal mode on text inputs and a : command line.
+const (
+	VimOff = "off"
+	VimOn  = "on"
+)
+
+// Values of the accessible setting: whether the TUI draws plain text for
+// screen readers, without colour, emoji or box drawing.
+const (
+	AccessibleOff = "off"
+	AccessibleOn  = "on"
+)
+
+// Values of the ascii setting: whether the TUI draws ASCII only, for
+// terminals that draw emoji at the wrong width, or decides by the terminal.
+const (
+	ASCIIAuto = "auto"
+	ASCIIOff  = "off"
+	ASCIIOn   = "on"
+)
+
+// Values of the read_only setting: whether the TUI only browses, with
+// everything that would change the journal turned off, for handing it to
+// a guest.
+const (
+	ReadOnlyOff = "off"
+	ReadOnlyOn  = "on"
+)
+
+// Values of the daily_entry setting: whether opening the journal of a trip
+// in progress in the TUI starts the day's entry when there is none yet,
+// with its date, leg, location, weather and itinerary filled in.
+const (
+	DailyEntryOff = "off"
+	DailyEntryOn  = "on"
+)
+
+// Values of the resume setting: whether the TUI offers to reopen the
+// screens it was left on when it last closed, reopens them straight away,
+// or always starts on the home menu.
+const (
+	ResumeAsk = "ask"
+	ResumeOn  = "on"
+	ResumeOff = "off"
+)
+
+// Values of the journal_storage setting: whether journal entries are kept
+// in the database alone, or also as Markdown notes with YAML front matter
+// in journal_dir, such as an Obsidian vault, where they can be edited,
+// added and removed too. The database stays the index of the notes and
+// keeps trips, expenses and everything else.
+const (
+	JournalDatabase = "database"
+	JournalMarkdown = "markdown"
+)
+
+// Values of the transcriber setting: what turns speech recorded by
+// `nomadic journal dictate` into text. whisper runs whisper.cpp, as
+// whisper_command with the model at whisper_model; api posts to
+// transcribe_url, an OpenAI-compatible transcription API, with the key in
+// $NOMADIC_TRANSCRIBE_KEY or $OPENAI_API_KEY.
+const (
+	TranscriberWhisper = "whisper"
+	TranscriberAPI     = "api"
+)
+
+// Values of the ocr setting: what reads the receipts of `nomadic expense
+// receipt`. tesseract runs Tesseract, as ocr_command; api posts to ocr_url,
+// an OCR.space-compatible API, with the key in $NOMADIC_OCR_KEY. Both read
+// text in ocr_language, a code such as eng.
+const (
+	OCRTesseract = "tesseract"
+	OCRAPI       = "api"
+)
+
+// Values of the redact setting: what `nomadic export` and the exports of
+// the TUI do with private journal entries and passages unless told
+// otherwise. none keeps them, strip leaves them out and mask leaves a mark
+// where they were.
+const (
+	RedactNone  = "none"
+	RedactStrip = "strip"
+	RedactMask  = "mask"
+)
+
+// Events that run the commands of the [hooks] table: a journal entry
+// written or edited, a trip made and an expense recorded.
+const (
+	HookEntrySaved   = "entry_saved"
+	HookTripCreated  = "trip_created"
+	HookExpenseAdded = "expense_added"
+)
+
+// HookEvents lists the events hooks can be set for.
+var HookEvents = []string{HookEntrySaved, HookTripCreated, HookExpenseAdded}
+
+// DefaultServeAddress is where `nomadic serve` listens unless the
+// serve_address setting says otherwise: this machine only.
+const DefaultServeAddress = "127.0.0.1:8787"
+
+// backup_remote is where `nomadic backup --remote` uploads encrypted
+// archives: an S3-compatible bucket as s3://bucket/prefix, at
+// backup_endpoint in backup_region, with its keys in
+// $NOMADIC_S3_ACCESS_KEY_ID and $NOMADIC_S3_SECRET_ACCESS_KEY (or AWS's
+// variables), or a WebDAV directory as its https URL, with its user and
+// password in $NOMADIC_WEBDAV_USER and $NOMADIC_WEBDAV_PASSWORD. Uploading
+// keeps the newest backup_keep archives there, DefaultBackupKeep unless set.
+const DefaultBackupKeep = "10"
+
+// DefaultExpiryDays is how many days ahead the home screen warns of travel
+// documents in the vault running out, unless expiry_days says.
+const DefaultExpiryDays = "90"
+
+// Default returns the settings used when no config file exists.
+func Default() Config {
+	return Config{
+		DefaultCurrency: "EUR",
+		HomeCurrency:    "EUR",
+		DateFormat:      "YYYY-MM-DD",
+		Theme:           "dark",
+		Locale:          i18n.Auto,
+		ImagePreview:    "auto",
+		AutoSync:        SyncOff,
+		Weather:         WeatherOff,
+		Holidays:        HolidaysBundled,
+		Geocoder:        GeocoderOff,
+		Offline:         OfflineOff,
+		Vim:             VimOff,
+		Accessible:      AccessibleOff,
+		ASCII:           ASCIIAuto,
+		ReadOnly:        ReadOnlyOff,
+		DailyEntry:      DailyEntryOn,
+		Resume:          ResumeAsk,
+		JournalStorage:  JournalDatabase,
+		Transcriber:     TranscriberWhisper,
+		OCR:             OCRTesseract,
+		ServeAddress:    DefaultServeAddress,
+		SiteTitle:       "Travels",
+		Redact:          RedactNone,
+		BackupKeep:      DefaultBackupKeep,
+		ExpiryDays:      DefaultExpiryDays,
+		Keys:            DefaultKeys(),
+	}
+}
+
+// DefaultKeys maps TUI actions to the keys that trigger them. A value may
+// list several keys separated by commas; "space" names the space bar.
+// Screens only respond to the actions that make sense there, so one key
+// may serve several actions on different screens.
+func DefaultKeys() map[string]string {
+	return map[string]string{
+		// Everywhere.
+		"back":    "esc",
+		"theme":   "ctrl+t",
+		"help":    "?,f1",
+		"undo":    "u",
+		"redo":    "ctrl+r",
+		"checkin": "C",
+		"quick":   "N",
+		"palette": "ctrl+p",
+		"debug":   "f12",
+		"record":  "ctrl+o",
+
+		// Moving around lists and the calendar.
+		"up":         "up,k",
+		"down":       "down,j",
+		"left":       "left,h",
+		"right":      "right,l",
+		"select":     "enter",
+		"quit":       "q",
+		"search":     "/",
+		"prev_month": "pgup,[",
+		"next_month": "pgdown,]",
+		"page_up":    "pgup",
+		"page_down":  "pgdown",
+		"first":      "home",
+		"last":       "end",
+		"today":      "t",
+
+		// Acting on the selected item.
+		"new":       "n",
+		"add":       "a",
+		"edit":      "e",
+		"delete":    "d",
+		"filter":    "t",
+		"tags":      "t",
+		"all_trips": "A",
+		"archive":   "z",
+		"restore":   "r",
+		"empty":     "X",
+		"toggle":    "space,x",
+		"mark":      "space",
+		"mark_all":  "a",
+		"move_up":   "shift+up,K",
+		"move_down": "shift+down,J",
+		"open":      "o",
+		"preview":   "p",
+		"link":      "ctrl+l",
+		"save":      "ctrl+s",
+		"sort":      "s",
+		"rate":      "*",
+		"public":    "P",
+		"private":   "V",
+		"yearly":    "Y",
+		"receipt":   "p",
+		"generate":  "g",
+		"flight":    "f",
+
+		// Opening related screens.
+		"attachments": "a",
+		"budget":      "b",
+		"clone":       "c",
+		"convert":     "c",
+		"export":      "x",
+		"import":      "i",
+		"inbox":       "I",
+		"health":      "w",
+		"highlights":  "g",
+		"history":     "H",
+		"legs":        "l",
+		"lists":       "m",
+		"lodging":     "L",
+		"packing":     "p",
+		"prep":        "D",
+		"promote":     "p",
+		"reconcile":   "B",
+		"recurring":   "R",
+		"report":      "r",
+		"save_list":   "s",
+		"settle":      "s",
+		"template":    "s",
+		"timeline":    "T",
+	}
+}
+
+// DefaultPath returns the config file location, honouring $XDG_CONFIG_HOME
+// and falling back to ~/.config/nomadic/config.toml.
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "nomadic", FileName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "nomadic", FileName), nil
+}
+
+// Load reads the config at path, filling anything unset with defaults. A
+// missing file is not an error.
+func Load(path string) (Config, error) {
+	cfg := Default()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("config: %w", err)
+	}
+
+	var file Config
+	if _, err := toml.Decode(string(data), &file); err != nil {
+		return cfg, fmt.Errorf("config: %s: %w", path, err)
+	}
+	cfg.merge(file)
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("config: %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// merge overlays the non-zero settings of other onto c.
+func (c *Config) merge(other Config) {
+	if other.DefaultCurrency != "" {
+		c.DefaultCurrency = other.DefaultCurrency
+	}
+	if other.HomeCurrency != "" {
+		c.HomeCurrency = other.HomeCurrency
+	}
+	if other.HomeZone != "" {
+		c.HomeZone = other.HomeZone
+	}
+	if other.DateFormat != "" {
+		c.DateFormat = other.DateFormat
+	}
+	if other.DataDir != "" {
+		c.DataDir = other.DataDir
+	}
+	if other.Theme != "" {
+		c.Theme = other.Theme
+	}
+	if other.Locale != "" {
+		c.Locale = other.Locale
+	}
+	if other.Editor != "" {
+		c.Editor = other.Editor
+	}
+	if other.ImagePreview != "" {
+		c.ImagePreview = other.ImagePreview
+	}
+	if other.AutoSync != "" {
+		c.AutoSync = other.AutoSync
+	}
+	if other.Weather != "" {
+		c.Weather = other.Weather
+	}
+	if other.Holidays != "" {
+		c.Holidays = other.Holidays
+	}
+	if other.JournalStorage != "" {
+		c.JournalStorage = other.JournalStorage
+	}
+	if other.JournalDir != "" {
+		c.JournalDir = other.JournalDir
+	}
+	if other.Geocoder != "" {
+		c.Geocoder = other.Geocoder
+	}
+	if other.Offline != "" {
+		c.Offline = other.Offline
+	}
+	if other.Vim != "" {
+		c.Vim = other.Vim
+	}
+	if other.Accessible != "" {
+		c.Accessible = other.Accessible
+	}
+	if other.ASCII != "" {
+		c.ASCII = other.ASCII
+	}
+	if other.ReadOnly != "" {
+		c.ReadOnly = other.ReadOnly
+	}
+	if other.DailyEntry != "" {
+		c.DailyEntry = other.DailyEntry
+	}
+	if other.Resume != "" {
+		c.Resume = other.Resume
+	}
+	if other.Transcriber != "" {
+		c.Transcriber = other.Transcriber
+	}
+	if other.WhisperCommand != "" {
+		c.WhisperCommand = other.WhisperCommand
+	}
+	if other.WhisperModel != "" {
+		c.WhisperModel = other.WhisperModel
+	}
+	if other.TranscribeURL != "" {
+		c.TranscribeURL = other.TranscribeURL
+	}
+	if other.TranscribeModel != "" {
+		c.TranscribeModel = other.TranscribeModel
+	}
+	if other.OCR != "" {
+		c.OCR = other.OCR
+	}
+	if other.OCRCommand != "" {
+		c.OCRCommand = other.OCRCommand
+	}
+	if other.OCRLanguage != "" {
+		c.OCRLanguage = other.OCRLanguage
+	}
+	if other.OCRURL != "" {
+		c.OCRURL = other.OCRURL
+	}
+	if other.ReceiptInbox != "" {
+		c.ReceiptInbox = other.ReceiptInbox
+	}
+	if other.ServeAddress != "" {
+		c.ServeAddress = other.ServeAddress
+	}
+	if other.SiteTitle != "" {
+		c.SiteTitle = other.SiteTitle
+	}
+	if other.ShareTunnel != "" {
+		c.ShareTunnel = other.ShareTunnel
+	}
+	if other.Redact != "" {
+		c.Redact = other.Redact
+	}
+	if other.BackupRemote != "" {
+		c.BackupRemote = other.BackupRemote
+	}
+	if other.BackupEndpoint != "" {
+		c.BackupEndpoint = other.BackupEndpoint
+	}
+	if other.BackupRegion != "" {
+		c.BackupRegion = other.BackupRegion
+	}
+	if other.BackupKeep != "" {
+		c.BackupKeep = other.BackupKeep
+	}
+	if other.ExpiryDays != "" {
+		c.ExpiryDays = other.ExpiryDays
+	}
+	if other.SMTPServer != "" {
+		c.SMTPServer = other.SMTPServer
+	}
+	if other.SMTPUser != "" {
+		c.SMTPUser = other.SMTPUser
+	}
+	if other.SMTPFrom != "" {
+		c.SMTPFrom = other.SMTPFrom
+	}
+	if other.DigestTo != "" {
+		c.DigestTo = other.DigestTo
+	}
+	for action, keys := range other.Keys {
+		c.Keys[action] = keys
+	}
+	if len(other.Hooks) > 0 && c.Hooks == nil {
+		c.Hooks = map[string]string{}
+	}
+	for event, command := range other.Hooks {
+		c.Hooks[event] = command
+	}
+	if len(other.TripFields) > 0 && c.TripFields == nil {
+		c.TripFields = map[string]string{}
+	}
+	for key, label := range other.TripFields {
+		c.TripFields[key] = label
+	}
+	if len(other.Macros) > 0 && c.Macros == nil {
+		c.Macros = map[string]Macro{}
+	}
+	for name, m := range other.Macros {
+		c.Macros[name] = m
+	}
+	if len(other.CustomThemes) > 0 && c.CustomThemes == nil {
+		c.CustomThemes = map[string]theme.Palette{}
+	}
+	for name, p := range other.CustomThemes {
+		c.CustomThemes[name] = p
+	}
+	if len(other.Profiles) > 0 && c.Profiles == nil {
+		c.Profiles = map[string]map[string]string{}
+	}
+	for name, p := range other.Profiles {
+		c.Profiles[name] = p
+	}
+}
+
+// Validate reports the first invalid setting, those of the profiles
+// included.
+func (c Config) Validate() error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	return c.validateProfiles()
+}
+
+// validate reports the first invalid setting, leaving out the profiles.
+func (c Config) validate() error {
+	if len(c.DefaultCurrency) != 3 {
+		return fmt.Errorf("default_currency %q is not a three-letter code", c.DefaultCurrency)
+	}
+	if len(c.HomeCurrency) != 3 {
+		return fmt.Errorf("home_currency %q is not a three-letter code", c.HomeCurrency)
+	}
+	if c.HomeZone != "" {
+		if _, err := time.LoadLocation(c.HomeZone); err != nil || c.HomeZone == "Local" {
+			return fmt.Errorf("home_zone %q is not an IANA time zone such as Europe/Lisbon", c.HomeZone)
+		}
+	}
+	if _, err := GoLayout(c.DateFormat); err != nil {
+		return err
+	}
+	for name := range c.CustomThemes {
+		if _, err := theme.Resolve(name, c.CustomThemes); err != nil {
+			return err
+		}
+	}
+	if _, err := theme.Resolve(c.Theme, c.CustomThemes); err != nil {
+		return err
+	}
+	if _, err := i18n.Parse(c.Locale, os.Getenv); err != nil {
+		return err
+	}
+	if _, err := viewer.ParseProtocol(c.ImagePreview); err != nil {
+		return err
+	}
+	switch c.AutoSync {
+	case SyncOff, SyncCommit, SyncPush:
+	default:
+		return fmt.Errorf("auto_sync %q is not one of %s, %s, %s", c.AutoSync, SyncOff, SyncCommit, SyncPush)
+	}
+	switch c.Weather {
+	case WeatherOff, WeatherOpenMeteo:
+	default:
+		return fmt.Errorf("weather %q is not one of %s, %s", c.Weather, WeatherOff, WeatherOpenMeteo)
+	}
+	switch c.Holidays {
+	case HolidaysOff, HolidaysBundled, HolidaysNager:
+	default:
+		return fmt.Errorf("holidays %q is not one of %s, %s, %s", c.Holidays, HolidaysOff, HolidaysBundled, HolidaysNager)
+	}
+	switch c.Geocoder {
+	case GeocoderOff, GeocoderNominatim:
+	default:
+		return fmt.Errorf("geocoder %q is not one of %s, %s", c.Geocoder, GeocoderOff, GeocoderNominatim)
+	}
+	switch c.Offline {
+	case OfflineOff, OfflineOn:
+	default:
+		return fmt.Errorf("offline %q is not one of %s, %s", c.Offline, OfflineOff, OfflineOn)
+	}
+	switch c.Vim {
+	case VimOff, VimOn:
+	default:
+		return fmt.Errorf("vim %q is not one of %s, %s", c.Vim, VimOff, VimOn)
+	}
+	switch c.Accessible {
+	case AccessibleOff, AccessibleOn:
+	default:
+		return fmt.Errorf("accessible %q is not one of %s, %s", c.Accessible, AccessibleOff, AccessibleOn)
+	}
+	switch c.ASCII {
+	case ASCIIAuto, ASCIIOff, ASCIIOn:
+	default:
+		return fmt.Errorf("ascii %q is not one of %s, %s, %s", c.ASCII, ASCIIAuto, ASCIIOff, ASCIIOn)
+	}
+	switch c.ReadOnly {
+	case ReadOnlyOff, ReadOnlyOn:
+	default:
+		return fmt.Errorf("read_only %q is not one of %s, %s", c.ReadOnly, ReadOnlyOff, ReadOnlyOn)
+	}
+	switch c.DailyEntry {
+	case DailyEntryOff, DailyEntryOn:
+	default:
+		return fmt.Errorf("daily_entry %q is not one of %s, %s", c.DailyEntry, DailyEntryOff, DailyEntryOn)
+	}
+	switch c.Resume {
+	case ResumeAsk, ResumeOn, ResumeOff:
+	default:
+		return fmt.Errorf("resume %q is not one of %s, %s, %s", c.Resume, ResumeAsk, ResumeOn, ResumeOff)
+	}
+	switch c.JournalStorage {
+	case JournalDatabase:
+	case JournalMarkdown:
+		if c.JournalDir == "" {
+			return fmt.Errorf("journal_storage %s needs journal_dir, the folder to keep the notes in", JournalMarkdown)
+		}
+	default:
+		return fmt.Errorf("journal_storage %q is not one of %s, %s", c.JournalStorage, JournalDatabase, JournalMarkdown)
+	}
+	switch c.Transcriber {
+	case TranscriberWhisper, TranscriberAPI:
+	default:
+		return fmt.Errorf("transcriber %q is not one of %s, %s", c.Transcriber, TranscriberWhisper, TranscriberAPI)
+	}
+	switch c.OCR {
+	case OCRTesseract, OCRAPI:
+	default:
+		return fmt.Errorf("ocr %q is not one of %s, %s", c.OCR, OCRTesseract, OCRAPI)
+	}
+	switch c.Redact {
+	case RedactNone, RedactStrip, RedactMask:
+	default:
+		return fmt.Errorf("redact %q is not one of %s, %s, %s", c.Redact, RedactNone, RedactStrip, RedactMask)
+	}
+	if _, _, err := net.SplitHostPort(c.ServeAddress); err != nil {
+		return fmt.Errorf("serve_address %q is not a host:port address", c.ServeAddress)
+	}
+	if c.BackupRemote != "" {
+		if u, err := url.Parse(c.BackupRemote); err != nil || u.Host == "" ||
+			u.Scheme != "s3" && u.Scheme != "https" && u.Scheme != "http" {
+			return fmt.Errorf("backup_remote %q is neither s3://bucket/prefix nor the https URL of a WebDAV directory", c.BackupRemote)
+		}
+	}
+	if n, err := strconv.Atoi(c.BackupKeep); err != nil || n < 1 {
+		return fmt.Errorf("backup_keep %q is not a number of backups", c.BackupKeep)
+	}
+	if n, err := strconv.Atoi(c.ExpiryDays); err != nil || n < 1 {
+		return fmt.Errorf("expiry_days %q is not a number of days", c.ExpiryDays)
+	}
+	if c.SMTPServer != "" {
+		if _, _, err := net.SplitHostPort(c.SMTPServer); err != nil {
+			return fmt.Errorf("smtp_server %q is not a host:port address, such as smtp.example.com:587", c.SMTPServer)
+		}
+	}
+	if c.SMTPFrom != "" {
+		if _, err := mail.ParseAddress(c.SMTPFrom); err != nil {
+			return fmt.Errorf("smtp_from %q is not a mail address", c.SMTPFrom)
+		}
+	}
+	if c.DigestTo != "" {
+		if _, err := mail.ParseAddressList(c.DigestTo); err != nil {
+			return fmt.Errorf("digest_to %q is not a list of mail addresses separated by commas", c.DigestTo)
+		}
+	}
+	defaults := DefaultKeys()
+	for action, keys := range c.Keys {
+		if _, ok := defaults[action]; !ok {
+			return fmt.Errorf("keys.%s is not a known action", action)
+		}
+		if strings.TrimSpace(keys) == "" {
+			return fmt.Errorf("keys.%s must name at least one key", action)
+		}
+	}
+	for event := range c.Hooks {
+		if !slices.Contains(HookEvents, event) {
+			return fmt.Errorf("hooks.%s is not one of the events %s", event, strings.Join(HookEvents, ", "))
+		}
+	}
+	for key, label := range c.TripFields {
+		if !validFieldKey(key) {
+			return fmt.Errorf("trip_fields.%s: a field's key is lower-case letters, digits and underscores", key)
+		}
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("trip_fields.%s must have a label", key)
+		}
+	}
+	for name, m := range c.Macros {
+		if !validFieldKey(name) {
+			return fmt.Errorf("macros.%s: a macro's name is lower-case letters, digits and underscores", name)
+		}
+		steps, err := SplitMacro(m.Keys)
+		if err != nil {
+			return fmt.Errorf("macros.%s.keys: %w", name, err)
+		}
+		if len(steps) == 0 {
+			return fmt.Errorf("macros.%s must press at least one key", name)
+		}
+		if strings.Contains(m.Key, ",") {
+			return fmt.Errorf("macros.%s.key must name a single key", name)
+		}
+	}
+	return nil
+}
+
+// Macro is a quick action: keys pressed in the TUI, recorded to press them
+// again with a key of its own or from the palette.
+type Macro struct {
+	// Key replays the macro, or is empty when only the palette does.
+	Key string `toml:"key,omitempty"`
+	// On names the screen the macro was recorded on, which it is only
+	// replayed on, or is empty for any.
+	On string `toml:"on,omitempty"`
+	// Keys are the keys pressed separated by spaces: their names, as in
+	// the [keys] table, and text typed as a quoted string, such as
+	// `n "Ramen" tab "food"`.
+	Keys string `toml:"keys"`
+}
+
+// MacroStep is a step of a macro: a key pressed, or text typed.
+type MacroStep struct {
+	Key  string
+	Text string
+}
+
+// SplitMacro reads the keys of a macro into its steps.
+func SplitMacro(keys string) ([]MacroStep, error) {
+	var steps []MacroStep
+	for rest := strings.TrimSpace(keys); rest != ""; rest = strings.TrimLeft(rest, " ") {
+		if rest[0] != '"' {
+			k, after, _ := strings.Cut(rest, " ")
+			steps = append(steps, MacroStep{Key: k})
+			rest = after
+			continue
+		}
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return nil, fmt.Errorf("unterminated text in %q", rest)
+		}
+		text, _ := strconv.Unquote(quoted)
+		if text != "" {
+			steps = append(steps, MacroStep{Text: text})
+		}
+		rest = rest[len(quoted):]
+	}
+	return steps, nil
+}
+
+// JoinMacro writes the steps of a macro as the keys SplitMacro reads.
+func JoinMacro(steps []MacroStep) string {
+	parts := make([]string, len(steps))
+	for i, st := range steps {
+		if st.Key != "" {
+			parts[i] = st.Key
+		} else {
+			parts[i] = strconv.Quote(st.Text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// MacroNames lists the names of the macros, sorted.
+func (c Config) MacroNames() []string {
+	return slices.Sorted(maps.Keys(c.Macros))
+}
+
+// TripField is a field of their own that trips have, set up in the
+// [trip_fields] table.
+type TripField struct {
+	Key   string
+	Label string
+}
+
+// TripFieldList lists the trip fields by their keys.
+func (c Config) TripFieldList() []TripField {
+	fields := make([]TripField, 0, len(c.TripFields))
+	for key, label := range c.TripFields {
+		fields = append(fields, TripField{Key: key, Label: label})
+	}
+	slices.SortFunc(fields, func(a, b TripField) int { return strings.Compare(a.Key, b.Key) })
+	return fields
+}
+
+// validFieldKey reports whether key names a trip field: lower-case
+// letters, digits and underscores, starting with a letter.
+func validFieldKey(key string) bool {
+	for i, r := range key {
+		if !(r >= 'a' && r <= 'z' || i > 0 && (r >= '0' && r <= '9' || r == '_')) {
+			return false
+		}
+	}
+	return key != ""
+}
+
+// Save writes c to path, creating the directory if needed.
+func Save(path string, c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// KeysFor returns the keys bound to action.
+func (c Config) KeysFor(action string) []string {
+	v, ok := c.Keys[action]
+	if !ok {
+		v = DefaultKeys()[action]
+	}
+	var keys []string
+	for _, k := range strings.Split(v, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// ExpiryWindow is how many days ahead to warn of travel documents running
+// out.
+func (c Config) ExpiryWindow() int {
+	n, err := strconv.Atoi(c.ExpiryDays)
+	if err != nil || n < 1 {
+		n, _ = strconv.Atoi(DefaultExpiryDays)
+	}
+	return n
+}
+
+// Layout returns the Go time layout for the configured date format.
+func (c Config) Layout() string {
+	layout, err := GoLayout(c.DateFormat)
+	if err != nil {
+		return "2006-01-02"
+	}
+	return layout
+}
+
+// GoLayout converts a date format written with YYYY, MM and DD
+// placeholders, such as "DD/MM/YYYY", into a Go time layout.
+func GoLayout(format string) (string, error) {
+	r := strings.NewReplacer("YYYY", "2006", "MM", "01", "DD", "02")
+	layout := r.Replace(format)
+	if !strings.Contains(layout, "2006") || !strings.Contains(layout, "01") || !strings.Contains(layout, "02") {
+		return "", fmt.Errorf("date_format %q must contain YYYY, MM and DD", format)
+	}
+	for _, ch := range strings.NewReplacer("2006", "", "01", "", "02", "").Replace(layout) {
+		if strings.ContainsRune("0123456789", ch) || (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') {
+			return "", fmt.Errorf("date_format %q may only contain YYYY, MM, DD and separators", format)
+		}
+	}
+	return layout, nil
+}
+
+// setting describes one value addressable by `nomadic config get/set`.
+type setting struct {
+	get func(*Config) string
+	set func(*Config, string)
+}
+
+var settings = map[string]setting{
+	"default_currency": {
+		get: func(c *Config) string { return c.DefaultCurrency },
+		set: func(c *Config, v string) { c.DefaultCurrency = strings.ToUpper(v) },
+	},
+	"home_currency": {
+		get: func(c *Config) string { return c.HomeCurrency },
+		set: func(c *Config, v string) { c.HomeCurrency = strings.ToUpper(v) },
+	},
+	"home_zone": {
+		get: func(c *Config) string { return c.HomeZone },
+		set: func(c *Config, v string) { c.HomeZone = v },
+	},
+	"date_format": {
+		get: func(c *Config) string { return c.DateFormat },
+		set: func(c *Config, v string) { c.DateFormat = v },
+	},
+	"data_dir": {
+		get: func(c *Config) string { return c.DataDir },
+		set: func(c *Config, v string) { c.DataDir = v },
+	},
+	"theme": {
+		get: func(c *Config) string { return c.Theme },
+		set: func(c *Config, v string) { c.Theme = v },
+	},
+	"locale": {
+		get: func(c *Config) string { return c.Locale },
+		set: func(c *Config, v string) { c.Locale = v },
+	},
+	"editor": {
+		get: func(c *Config) string { return c.Editor },
+		set: func(c *Config, v string) { c.Editor = v },
+	},
+	"journal_storage": {
+		get: func(c *Config) string { return c.JournalStorage },
+		set: func(c *Config, v string) { c.JournalStorage = strings.ToLower(v) },
+	},
+	"journal_dir": {
+		get: func(c *Config) string { return c.JournalDir },
+		set: func(c *Config, v string) { c.JournalDir = v },
+	},
+	"auto_sync": {
+		get: func(c *Config) string { return c.AutoSync },
+		set: func(c *Config, v string) { c.AutoSync = strings.ToLower(v) },
+	},
+	"weather": {
+		get: func(c *Config) string { return c.Weather },
+		set: func(c *Config, v string) { c.Weather = strings.ToLower(v) },
+	},
+	"holidays": {
+		get: func(c *Config) string { return c.Holidays },
+		set: func(c *Config, v string) { c.Holidays = strings.ToLower(v) },
+	},
+	"geocoder": {
+		get: func(c *Config) string { return c.Geocoder },
+		set: func(c *Config, v string) { c.Geocoder = strings.ToLower(v) },
+	},
+	"offline": {
+		get: func(c *Config) string { return c.Offline },
+		set: func(c *Config, v string) { c.Offline = strings.ToLower(v) },
+	},
+	"vim": {
+		get: func(c *Config) string { return c.Vim },
+		set: func(c *Config, v string) { c.Vim = strings.ToLower(v) },
+	},
+	"accessible": {
+		get: func(c *Config) string { return c.Accessible },
+		set: func(c *Config, v string) { c.Accessible = strings.ToLower(v) },
+	},
+	"ascii": {
+		get: func(c *Config) string { return c.ASCII },
+		set: func(c *Config, v string) { c.ASCII = strings.ToLower(v) },
+	},
+	"read_only": {
+		get: func(c *Config) string { return c.ReadOnly },
+		set: func(c *Config, v string) { c.ReadOnly = strings.ToLower(v) },
+	},
+	"daily_entry": {
+		get: func(c *Config) string { return c.DailyEntry },
+		set: func(c *Config, v string) { c.DailyEntry = strings.ToLower(v) },
+	},
+	"resume": {
+		get: func(c *Config) string { return c.Resume },
+		set: func(c *Config, v string) { c.Resume = strings.ToLower(v) },
+	},
+	"transcriber": {
+		get: func(c *Config) string { return c.Transcriber },
+		set: func(c *Config, v string) { c.Transcriber = strings.ToLower(v) },
+	},
+	"whisper_command": {
+		get: func(c *Config) string { return c.WhisperCommand },
+		set: func(c *Config, v string) { c.WhisperCommand = v },
+	},
+	"whisper_model": {
+		get: func(c *Config) string { return c.WhisperModel },
+		set: func(c *Config, v string) { c.WhisperModel = v },
+	},
+	"transcribe_url": {
+		get: func(c *Config) string { return c.TranscribeURL },
+		set: func(c *Config, v string) { c.TranscribeURL = v },
+	},
+	"transcribe_model": {
+		get: func(c *Config) string { return c.TranscribeModel },
+		set: func(c *Config, v string) { c.TranscribeModel = v },
+	},
+	"ocr": {
+		get: func(c *Config) string { return c.OCR },
+		set: func(c *Config, v string) { c.OCR = strings.ToLower(v) },
+	},
+	"ocr_command": {
+		get: func(c *Config) string { return c.OCRCommand },
+		set: func(c *Config, v string) { c.OCRCommand = v },
+	},
+	"ocr_language": {
+		get: func(c *Config) string { return c.OCRLanguage },
+		set: func(c *Config, v string) { c.OCRLanguage = v },
+	},
+	"ocr_url": {
+		get: func(c *Config) string { return c.OCRURL },
+		set: func(c *Config, v string) { c.OCRURL = v },
+	},
+	"receipt_inbox": {
+		get: func(c *Config) string { return c.ReceiptInbox },
+		set: func(c *Config, v string) { c.ReceiptInbox = v },
+	},
+	"serve_address": {
+		get: func(c *Config) string { return c.ServeAddress },
+		set: func(c *Config, v string) { c.ServeAddress = v },
+	},
+	"site_title": {
+		get: func(c *Config) string { return c.SiteTitle },
+		set: func(c *Config, v string) { c.SiteTitle = v },
+	},
+	"share_tunnel": {
+		get: func(c *Config) string { return c.ShareTunnel },
+		set: func(c *Config, v string) { c.ShareTunnel = v },
+	},
+	"redact": {
+		get: func(c *Config) string { return c.Redact },
+		set: func(c *Config, v string) { c.Redact = strings.ToLower(v) },
+	},
+	"backup_remote": {
+		get: func(c *Config) string { return c.BackupRemote },
+		set: func(c *Config, v string) { c.BackupRemote = v },
+	},
+	"backup_endpoint": {
+		get: func(c *Config) string { return c.BackupEndpoint },
+		set: func(c *Config, v string) { c.BackupEndpoint = v },
+	},
+	"backup_region": {
+		get: func(c *Config) string { return c.BackupRegion },
+		set: func(c *Config, v string) { c.BackupRegion = v },
+	},
+	"backup_keep": {
+		get: func(c *Config) string { return c.BackupKeep },
+		set: func(c *Config, v string) { c.BackupKeep = strings.TrimSpace(v) },
+	},
+	"expiry_days": {
+		get: func(c *Config) string { return c.ExpiryDays },
+		set: func(c *Config, v string) { c.ExpiryDays = strings.TrimSpace(v) },
+	},
+	"smtp_server": {
+		get: func(c *Config) string { return c.SMTPServer },
+		set: func(c *Config, v string) { c.SMTPServer = strings.TrimSpace(v) },
+	},
+	"smtp_user": {
+		get: func(c *Config) string { return c.SMTPUser },
+		set: func(c *Config, v string) { c.SMTPUser = v },
+	},
+	"smtp_from": {
+		get: func(c *Config) string { return c.SMTPFrom },
+		set: func(c *Config, v string) { c.SMTPFrom = strings.TrimSpace(v) },
+	},
+	"digest_to": {
+		get: func(c *Config) string { return c.DigestTo },
+		set: func(c *Config, v string) { c.DigestTo = strings.TrimSpace(v) },
+	},
+	"image_preview": {
+		get: func(c *Config) string { return c.ImagePreview },
+		set: func(c *Config, v string) { c.ImagePreview = strings.ToLower(v) },
+	},
+}
+
+// Names lists every setting name accepted by Get and Set, sorted.
+func (c Config) Names() []string {
+	names := make([]string, 0, len(settings)+len(c.Keys))
+	for name := range settings {
+		names = append(names, name)
+	}
+	for action := range DefaultKeys() {
+		names = append(names, "keys."+action)
+	}
+	for _, event := range HookEvents {
+		names = append(names, "hooks."+event)
+	}
+	for key := range c.TripFields {
+		names = append(names, "trip_fields."+key)
+	}
+	for name := range c.Macros {
+		for _, field := range macroFields {
+			names = append(names, "macros."+name+"."+field)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the value of the named setting.
+func (c Config) Get(name string) (string, error) {
+	if action, ok := strings.CutPrefix(name, "keys."); ok {
+		if _, known := DefaultKeys()[action]; !known {
+			return "", fmt.Errorf("unknown setting %q", name)
+		}
+		return strings.Join(c.KeysFor(action), ","), nil
+	}
+	if event, ok := strings.CutPrefix(name, "hooks."); ok {
+		if !slices.Contains(HookEvents, event) {
+			return "", fmt.Errorf("unknown setting %q", name)
+		}
+		return c.Hooks[event], nil
+	}
+	if key, ok := strings.CutPrefix(name, "trip_fields."); ok {
+		label, ok := c.TripFields[key]
+		if !ok {
+			return "", fmt.Errorf("no trip field %q; add one with nomadic config set %s <label>", key, name)
+		}
+		return label, nil
+	}
+	if macro, ok := strings.CutPrefix(name, "macros."); ok {
+		name, field, _ := strings.Cut(macro, ".")
+		if !slices.Contains(macroFields, field) {
+			return "", fmt.Errorf("unknown setting %q; a macro has %s", "macros."+macro, strings.Join(macroFields, ", "))
+		}
+		return c.Macros[name].field(field), nil
+	}
+	s, ok := settings[name]
+	if !ok {
+		return "", fmt.Errorf("unknown setting %q", name)
+	}
+	return s.get(&c), nil
+}
+
+// macroFields are the settings of each macro, as macros.<name>.<field>.
+var macroFields = []string{"key", "on", "keys"}
+
+func (m Macro) field(name string) string {
+	switch name {
+	case "key":
+		return m.Key
+	case "on":
+		return m.On
+	}
+	return m.Keys
+}
+
+// Set changes the named setting and validates the result.
+func (c *Config) Set(name, value string) error {
+	next := c.clone()
+	if err := next.set(name, value); err != nil {
+		return err
+	}
+	if err := next.Validate(); err != nil {
+		return err
+	}
+	*c = next
+	return nil
+}
+
+// set changes the named setting without validating the result.
+func (c *Config) set(name, value string) error {
+	if action, ok := strings.CutPrefix(name, "keys."); ok {
+		if _, known := DefaultKeys()[action]; !known {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		c.Keys[action] = value
+	} else if event, ok := strings.CutPrefix(name, "hooks."); ok {
+		if !slices.Contains(HookEvents, event) {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		// An empty command removes the hook.
+		if c.Hooks == nil {
+			c.Hooks = map[string]string{}
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			delete(c.Hooks, event)
+		} else {
+			c.Hooks[event] = value
+		}
+	} else if key, ok := strings.CutPrefix(name, "trip_fields."); ok {
+		// An empty label removes the field; what trips hold of it is kept.
+		if c.TripFields == nil {
+			c.TripFields = map[string]string{}
+		}
+		if value = strings.TrimSpace(value); value == "" {
+			delete(c.TripFields, key)
+		} else {
+			c.TripFields[key] = value
+		}
+	} else if macro, ok := strings.CutPrefix(name, "macros."); ok {
+		// Empty keys remove the macro.
+		name, field, _ := strings.Cut(macro, ".")
+		if !slices.Contains(macroFields, field) {
+			return fmt.Errorf("unknown setting %q; a macro has %s", "macros."+macro, strings.Join(macroFields, ", "))
+		}
+		if c.Macros == nil {
+			c.Macros = map[string]Macro{}
+		}
+		m := c.Macros[name]
+		value = strings.TrimSpace(value)
+		switch field {
+		case "key":
+			m.Key = value
+		case "on":
+			m.On = value
+		default:
+			m.Keys = value
+		}
+		if field == "keys" && m.Keys == "" {
+			delete(c.Macros, name)
+		} else {
+			c.Macros[name] = m
+		}
+	} else {
+		s, ok := settings[name]
+		if !ok {
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		s.set(c, value)
+	}
+	return nil
+}
+
+// clone copies c, with maps of its own to change.
+func (c Config) clone() Config {
+	next := c
+	next.Keys = maps.Clone(c.Keys)
+	if next.Keys == nil {
+		next.Keys = map[string]string{}
+	}
+	next.Hooks = maps.Clone(c.Hooks)
+	next.TripFields = maps.Clone(c.TripFields)
+	next.Macros = maps.Clone(c.Macros)
+	if c.Profiles != nil {
+		next.Profiles = make(map[string]map[string]string, len(c.Profiles))
+		for name, p := range c.Profiles {
+			next.Profiles[name] = maps.Clone(p)
+		}
+	}
+	return next
+}
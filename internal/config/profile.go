@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+// DefaultProfile names the settings of the config file as they are, outside
+// every [profiles.<name>] table.
+const DefaultProfile = "default"
+
+var profileName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidProfileName reports why name cannot name a profile, if it cannot.
+func ValidProfileName(name string) error {
+	if name == DefaultProfile || !profileName.MatchString(name) {
+		return fmt.Errorf("profile %q must be lowercase letters, digits, - and _, and not %s", name, DefaultProfile)
+	}
+	return nil
+}
+
+// ProfileNames lists the profiles of c, the default first, then the others
+// by name.
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{DefaultProfile}, names...)
+}
+
+// Profile returns the settings of the named profile: those of c with the
+// profile's overrides applied. A profile keeps a journal apart, so its
+// data_dir is never that of c; left unset, it is empty for the caller to
+// pick the profile's default. The default profile, or an empty name, is c
+// as it is.
+func (c Config) Profile(name string) (Config, error) {
+	if name == "" || name == DefaultProfile {
+		return c, nil
+	}
+	p, err := c.apply(name)
+	if err != nil {
+		return c, err
+	}
+	if err := p.validate(); err != nil {
+		return c, fmt.Errorf("profiles.%s: %w", name, err)
+	}
+	return p, nil
+}
+
+// apply overlays the overrides of the named profile onto c, without
+// validating the result.
+func (c Config) apply(name string) (Config, error) {
+	overrides, ok := c.Profiles[name]
+	if !ok {
+		return c, fmt.Errorf("no profile %q", name)
+	}
+	p := c.clone()
+	p.DataDir = ""
+	settings := make([]string, 0, len(overrides))
+	for setting := range overrides {
+		settings = append(settings, setting)
+	}
+	sort.Strings(settings)
+	for _, setting := range settings {
+		if err := p.set(setting, overrides[setting]); err != nil {
+			return c, fmt.Errorf("profiles.%s: %w", name, err)
+		}
+	}
+	return p, nil
+}
+
+// validateProfiles reports the first profile with an invalid name or
+// setting.
+func (c Config) validateProfiles() error {
+	names := c.ProfileNames()[1:]
+	for _, name := range names {
+		if err := ValidProfileName(name); err != nil {
+			return err
+		}
+		if _, err := c.Profile(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddProfile adds a profile overriding nothing yet.
+func (c *Config) AddProfile(name string) error {
+	if err := ValidProfileName(name); err != nil {
+		return err
+	}
+	if _, ok := c.Profiles[name]; ok {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	next := c.clone()
+	if next.Profiles == nil {
+		next.Profiles = map[string]map[string]string{}
+	}
+	next.Profiles[name] = map[string]string{}
+	*c = next
+	return nil
+}
+
+// RemoveProfile removes the named profile from the settings. The journal
+// in its data directory stays.
+func (c *Config) RemoveProfile(name string) error {
+	if !slices.Contains(c.ProfileNames()[1:], name) {
+		return fmt.Errorf("no profile %q", name)
+	}
+	next := c.clone()
+	delete(next.Profiles, name)
+	*c = next
+	return nil
+}
+
+// SetProfile changes the named setting for a profile only, and validates
+// the profile's settings then. An empty value stops the profile overriding
+// the setting. The default profile's settings are changed with Set.
+func (c *Config) SetProfile(profile, name, value string) error {
+	if profile == "" || profile == DefaultProfile {
+		return c.Set(name, value)
+	}
+	if _, ok := c.Profiles[profile]; !ok {
+		return fmt.Errorf("no profile %q", profile)
+	}
+	if _, err := c.Get(name); err != nil {
+		return err
+	}
+	next := c.clone()
+	if value == "" {
+		delete(next.Profiles[profile], name)
+	} else {
+		next.Profiles[profile][name] = value
+	}
+	if _, err := next.Profile(profile); err != nil {
+		return err
+	}
+	*c = next
+	return nil
+}
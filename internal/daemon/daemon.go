@@ -0,0 +1,220 @@
+// Package daemon runs nomadic's background jobs, such as syncing the data
+// directory and journaling reminders, on their schedules until stopped,
+// and keeps how each last went in a status file in the data directory for
+// `nomadic daemon status`.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// StatusFile is the name of the status file inside the data directory.
+const StatusFile = "daemon.json"
+
+// heartbeat is how often a running daemon rewrites its status, so that a
+// daemon that died can be told from one still running.
+const heartbeat = time.Minute
+
+// tick is how often the daemon looks for jobs due and files changed.
+const tick = time.Second
+
+// ErrRunning is returned by Run when another daemon is already running in
+// the data directory.
+var ErrRunning = errors.New("daemon: already running")
+
+// Func does a job's work, returning a short note of what it did, such as
+// "Pushed 2 commits".
+type Func func(ctx context.Context) (string, error)
+
+// Job is work run when the daemon starts and then every Every.
+type Job struct {
+	Name  string
+	Every time.Duration
+	// Watch names files of the data directory whose changes run the job
+	// too, once they have been left alone for Quiet. With Watch set the
+	// job also runs on shutdown when changes are still waiting for it.
+	Watch []string
+	Quiet time.Duration
+	Run   Func
+}
+
+// JobStatus is how a job last went and when it runs next.
+type JobStatus struct {
+	Name    string        `json:"name"`
+	Every   time.Duration `json:"every"`
+	LastRun time.Time     `json:"last_run,omitzero"`
+	Result  string        `json:"result,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	NextRun time.Time     `json:"next_run,omitzero"`
+}
+
+// Status is what the daemon of a data directory is doing.
+type Status struct {
+	PID     int       `json:"pid"`
+	Started time.Time `json:"started"`
+	// Heartbeat is when the daemon last wrote its status.
+	Heartbeat time.Time `json:"heartbeat"`
+	// Stopped is when the daemon shut down cleanly, if it has.
+	Stopped time.Time   `json:"stopped,omitzero"`
+	Jobs    []JobStatus `json:"jobs"`
+}
+
+// Running reports whether the daemon looks alive at now: it has not
+// stopped and its heartbeat is recent.
+func (s Status) Running(now time.Time) bool {
+	return s.Stopped.IsZero() && now.Sub(s.Heartbeat) < 2*heartbeat
+}
+
+// Job returns the status of the job called name, if the daemon runs it.
+func (s Status) Job(name string) (JobStatus, bool) {
+	for _, j := range s.Jobs {
+		if j.Name == name {
+			return j, true
+		}
+	}
+	return JobStatus{}, false
+}
+
+// ReadStatus reads the status of the daemon of the data directory dir. It
+// returns an error wrapping os.ErrNotExist when no daemon ever ran there.
+func ReadStatus(dir string) (Status, error) {
+	var s Status
+	data, err := os.ReadFile(filepath.Join(dir, StatusFile))
+	if err != nil {
+		return s, fmt.Errorf("daemon: %w", err)
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("daemon: read status: %w", err)
+	}
+	return s, nil
+}
+
+// writeStatus replaces the status file, through a temporary file so that
+// a status read while it is written is never half of one.
+func writeStatus(dir string, s Status) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, StatusFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("daemon: write status: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("daemon: write status: %w", err)
+	}
+	return nil
+}
+
+// job is a Job with its schedule and the state of its watched files.
+type job struct {
+	Job
+	status *JobStatus
+	// seen is the last state of the watched files, and changed when it
+	// last moved on; pending is set while a change waits for the job.
+	seen    string
+	changed time.Time
+	pending bool
+}
+
+// Run runs jobs in the data directory dir until ctx is done, then runs
+// once more the jobs with changes waiting and records that the daemon
+// stopped. Jobs run one at a time, each given ctx. logf, which may be nil,
+// is told of errors and of results other than the one before.
+func Run(ctx context.Context, dir string, jobs []Job, logf func(format string, args ...any)) error {
+	if logf == nil {
+		logf = func(string, ...any) {}
+	}
+	now := time.Now()
+	if s, err := ReadStatus(dir); err == nil && s.Running(now) && s.PID != os.Getpid() {
+		return fmt.Errorf("%w (pid %d)", ErrRunning, s.PID)
+	}
+	status := Status{PID: os.Getpid(), Started: now, Heartbeat: now, Jobs: make([]JobStatus, len(jobs))}
+	js := make([]*job, len(jobs))
+	for i, j := range jobs {
+		status.Jobs[i] = JobStatus{Name: j.Name, Every: j.Every, NextRun: now}
+		js[i] = &job{Job: j, status: &status.Jobs[i], seen: stamp(dir, j.Watch)}
+	}
+	if err := writeStatus(dir, status); err != nil {
+		return err
+	}
+	logf("watching %s; ctrl+c to stop", dir)
+
+	run := func(ctx context.Context, j *job) {
+		start, previous := time.Now(), j.status.Result
+		result, err := j.Run(ctx)
+		// What the job itself changed is not a change for it.
+		j.seen, j.pending = stamp(dir, j.Watch), false
+		j.status.LastRun, j.status.Result, j.status.Error = start, result, ""
+		if err != nil {
+			j.status.Error = err.Error()
+			logf("%s: %v", j.Name, err)
+		} else if result != "" && result != previous {
+			logf("%s: %s", j.Name, result)
+		}
+		j.status.NextRun = start.Add(j.Every)
+		status.Heartbeat = time.Now()
+		if err := writeStatus(dir, status); err != nil {
+			logf("%v", err)
+		}
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		now := time.Now()
+		for _, j := range js {
+			if ctx.Err() != nil {
+				break
+			}
+			if len(j.Watch) > 0 {
+				if s := stamp(dir, j.Watch); s != j.seen {
+					j.seen, j.changed, j.pending = s, now, true
+				}
+			}
+			if !now.Before(j.status.NextRun) || j.pending && now.Sub(j.changed) >= j.Quiet {
+				run(ctx, j)
+			}
+		}
+		if now.Sub(status.Heartbeat) >= heartbeat {
+			status.Heartbeat = now
+			if err := writeStatus(dir, status); err != nil {
+				logf("%v", err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			// Changes saved just before stopping are not left behind.
+			final, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			for _, j := range js {
+				if j.pending {
+					run(final, j)
+				}
+			}
+			status.Stopped = time.Now()
+			status.Heartbeat = status.Stopped
+			return writeStatus(dir, status)
+		case <-ticker.C:
+		}
+	}
+}
+
+// stamp sums up the size and modification time of the files named, so
+// that any change to them changes it.
+func stamp(dir string, files []string) string {
+	var s string
+	for _, name := range files {
+		if fi, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			s += fmt.Sprintf("%s:%d:%d;", name, fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return s
+}
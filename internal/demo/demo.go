@@ -0,0 +1,649 @@
+// Package demo makes up a journal to explore nomadic with before entering
+// anything of one's own: trips gone, in progress and to come, with their
+// journal entries, expenses, itineraries, stays and the rest. The same
+// seed makes up the same journal around the same day, for screenshots and
+// tests.
+package demo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// DefaultTrips is how many trips a journal has unless told otherwise: one
+// in progress, two to come and three gone.
+const DefaultTrips = 6
+
+// Options sets what journal Generate makes up.
+type Options struct {
+	// Seed picks the journal; the same seed makes up the same one.
+	Seed int64
+	// Now is when the journal is made up around: a trip is in progress
+	// then, others are over by then or start after.
+	Now time.Time
+	// Home is the currency budgets are in and expenses have their rates
+	// locked into, when it is one the generator knows; else euros are.
+	Home string
+	// Trips is how many trips to make up, or 0 for DefaultTrips.
+	Trips int
+}
+
+// Journal is a made-up journal: a dump to import, and the travel documents
+// of the vault, which dumps leave out.
+type Journal struct {
+	Dump  *storage.Dump
+	Vault []*models.VaultItem
+}
+
+// Fill adds the journal to store and reports what was added. Made-up trips
+// merge with real ones, so store should be one of its own, such as in a
+// temporary directory.
+func Fill(ctx context.Context, store *storage.Store, j *Journal) (*storage.ImportReport, error) {
+	report, _, err := store.Import(ctx, j.Dump, storage.ImportOptions{})
+	if err != nil {
+		return report, err
+	}
+	for _, it := range j.Vault {
+		if err := store.SaveVaultItem(ctx, it); err != nil {
+			return report, err
+		}
+		report.Added["vault item"]++
+	}
+	return report, nil
+}
+
+// perEuro are how many units of the currencies homes may be in buy a
+// euro, roughly; those of the destinations are added to them.
+var perEuro = map[string]float64{
+	"EUR": 1, "USD": 1.08, "GBP": 0.85, "CHF": 0.94, "AUD": 1.65, "CAD": 1.48, "NZD": 1.8, "INR": 90,
+	"SEK": 11.5, "NOK": 11.7, "DKK": 7.46, "PLN": 4.3, "SGD": 1.45,
+}
+
+func init() {
+	for _, d := range destinations {
+		perEuro[d.currency] = d.perEuro
+	}
+}
+
+// role is where a trip falls around now.
+type role int
+
+const (
+	rolePast role = iota
+	// roleYearAgo is a trip in progress a year before now, for the home
+	// screen to remember.
+	roleYearAgo
+	roleCurrent
+	roleSoon
+	roleLater
+)
+
+// roles are the roles of the trips made up, in order; those beyond are
+// further in the past.
+var roles = []role{roleCurrent, roleSoon, rolePast, roleYearAgo, roleLater}
+
+// generator makes up a journal from its random source.
+type generator struct {
+	rnd   *rand.Rand
+	today time.Time
+	now   time.Time
+	home  string
+	// homeRate is how many units of home buy a euro.
+	homeRate float64
+	titles   map[string]bool
+}
+
+// Generate makes up a journal as opts sets.
+func Generate(opts Options) *Journal {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Trips <= 0 {
+		opts.Trips = DefaultTrips
+	}
+	home := strings.ToUpper(opts.Home)
+	rate, ok := perEuro[home]
+	if !ok {
+		home, rate = "EUR", 1
+	}
+	g := &generator{
+		rnd:      rand.New(rand.NewPCG(uint64(opts.Seed), 0x6e6f6d61646963)),
+		today:    time.Date(opts.Now.Year(), opts.Now.Month(), opts.Now.Day(), 0, 0, 0, 0, time.Local),
+		now:      opts.Now,
+		home:     home,
+		homeRate: rate,
+		titles:   map[string]bool{},
+	}
+	return g.journal(opts.Trips)
+}
+
+func (g *generator) journal(n int) *Journal {
+	d := &storage.Dump{Format: storage.DumpFormat, ExportedAt: g.now}
+	var companions []string
+	for _, p := range people {
+		person := models.NewPerson(p.name)
+		person.ID, person.Contact = g.id(), p.contact
+		person.CreatedAt, person.UpdatedAt = g.today.AddDate(-2, 0, 0), g.today.AddDate(-2, 0, 0)
+		d.People = append(d.People, person)
+		companions = append(companions, p.name)
+	}
+
+	order := g.rnd.Perm(len(destinations))
+	// past is the start of the earliest trip gone so far, for the next
+	// one to end before.
+	past := g.today
+	var current, soon, later *models.Trip
+	for i := range n {
+		r := rolePast
+		if i < len(roles) {
+			r = roles[i]
+		}
+		dest := destinations[order[i%len(order)]]
+		var start time.Time
+		var days int
+		switch r {
+		case roleCurrent:
+			days = 9 + g.rnd.IntN(5)
+			start = g.today.AddDate(0, 0, -2-g.rnd.IntN(3))
+		case roleSoon:
+			days = 6 + g.rnd.IntN(5)
+			start = g.today.AddDate(0, 0, 12+g.rnd.IntN(14))
+		case roleLater:
+			days = 7 + g.rnd.IntN(6)
+			start = g.today.AddDate(0, 0, 70+g.rnd.IntN(50))
+		case roleYearAgo:
+			days = 7 + g.rnd.IntN(6)
+			start = g.today.AddDate(-1, 0, -1-g.rnd.IntN(days-2))
+		default:
+			days = 6 + g.rnd.IntN(9)
+			end := past.AddDate(0, 0, -25-g.rnd.IntN(90))
+			start = end.AddDate(0, 0, 1-days)
+		}
+		if start.Before(past) {
+			past = start
+		}
+		rec := g.trip(dest, r, start, days, companions)
+		// Trips over a year and more ago have been put away.
+		if i >= len(roles) {
+			archived := rec.Trip.EndDate.AddDate(0, 1, 0)
+			rec.Trip.ArchivedAt = &archived
+		}
+		switch r {
+		case roleCurrent:
+			current = rec.Trip
+		case roleSoon:
+			soon = rec.Trip
+		case roleLater:
+			later = rec.Trip
+		}
+		d.Trips = append(d.Trips, rec)
+	}
+
+	for _, w := range wishes {
+		wish := models.NewWish(w.place)
+		wish.ID, wish.Notes, wish.Priority = g.id(), w.notes, w.priority
+		wish.Budget, wish.Currency = g.price(w.budget, g.home, 50), g.home
+		for _, m := range w.season {
+			wish.Season = append(wish.Season, time.Month(m))
+		}
+		wish.CreatedAt, wish.UpdatedAt = g.today.AddDate(0, -g.rnd.IntN(18), 0), g.today
+		d.Wishes = append(d.Wishes, wish)
+	}
+	for _, t := range []*models.Trip{current, soon} {
+		if t == nil {
+			continue
+		}
+		if p, ok := places.First(t.Locations...); ok {
+			note := models.NewCountryNote(p.Country)
+			note.Visa, note.MaxStay = "none needed for a short stay", 90
+			note.Notes = "Carry some cash: small places rarely take cards. Tap water is fine in the cities."
+			note.CreatedAt, note.UpdatedAt = t.CreatedAt, t.CreatedAt
+			d.CountryNotes = append(d.CountryNotes, note)
+		}
+	}
+	// Getting online was noted on the first leg of the trips gone by.
+	providers := []string{"Airalo 5 GB", "Holafly unlimited", "Ubigi 10 GB", "Local prepaid SIM"}
+	for _, rec := range d.Trips {
+		t := rec.Trip
+		if t.EndDate == nil || !t.EndDate.Before(g.today) || len(rec.Legs) == 0 {
+			continue
+		}
+		leg := rec.Legs[0]
+		p, ok := places.First(leg.Location)
+		if !ok {
+			continue
+		}
+		c := models.NewConnectivity(p.Country)
+		c.ID, c.TripID, c.LegID, c.Place = g.id(), t.ID, leg.ID, leg.Location
+		c.Provider = providers[g.rnd.IntN(len(providers))]
+		c.Cost, c.Currency = g.price(12+float64(g.rnd.IntN(20)), g.home, 0), g.home
+		c.Speed = "fast in the cities, patchy out of them"
+		c.WiFi = []string{"the café by the station", "the hotel lobby"}
+		c.CreatedAt, c.UpdatedAt = t.StartDate, t.StartDate
+		d.Connectivity = append(d.Connectivity, c)
+	}
+	return &Journal{Dump: d, Vault: g.vault(later)}
+}
+
+// vault makes up the travel documents: a passport running out before the
+// end of the trip later, when there is one, an insurance policy running
+// out soon and a vaccination record that never does.
+func (g *generator) vault(later *models.Trip) []*models.VaultItem {
+	passport := g.document(models.VaultPassport, "Passport")
+	passport.Number = fmt.Sprintf("X%07d", g.rnd.IntN(10_000_000))
+	issued := g.today.AddDate(-10, 0, 60)
+	expires := issued.AddDate(10, 0, -1)
+	if later != nil {
+		expires = later.StartDate.AddDate(0, 0, 2)
+	}
+	passport.Issued, passport.Expires = &issued, &expires
+
+	insurance := g.document(models.VaultInsurance, "Annual travel insurance")
+	insurance.Issuer, insurance.Number = "World Nomads", fmt.Sprintf("WN-%06d", g.rnd.IntN(1_000_000))
+	from, until := g.today.AddDate(-1, 0, 40), g.today.AddDate(0, 0, 40)
+	insurance.Issued, insurance.Expires = &from, &until
+	insurance.Note = "Emergency line +44 20 7946 0000"
+
+	vaccination := g.document(models.VaultVaccination, "Yellow fever")
+	given := g.today.AddDate(-4, -3, 0)
+	vaccination.Issued, vaccination.Note = &given, "International certificate of vaccination, valid for life"
+	return []*models.VaultItem{passport, insurance, vaccination}
+}
+
+func (g *generator) document(kind, title string) *models.VaultItem {
+	it := models.NewVaultItem(kind, title)
+	it.ID = g.id()
+	it.CreatedAt, it.UpdatedAt = g.today.AddDate(0, -6, 0), g.today.AddDate(0, -6, 0)
+	return it
+}
+
+// trip makes up a trip to dest of days from start with everything
+// recorded on it by now.
+func (g *generator) trip(dest destination, r role, start time.Time, days int, companions []string) *storage.TripRecord {
+	end := start.AddDate(0, 0, days-1)
+	stops := dest.stops[:max(1, min(len(dest.stops), days/3))]
+	var cities []string
+	for _, s := range stops {
+		cities = append(cities, s.city)
+	}
+	t := models.NewTrip(g.title(dest, start), cities, start)
+	t.ID, t.EndDate = g.id(), &end
+	t.CreatedAt = start.AddDate(0, 0, -30-g.rnd.IntN(60))
+	t.UpdatedAt = t.CreatedAt
+	t.Tags = pick(g, dest.tags, 2)
+	t.Budget, t.BudgetCurrency = g.price(dest.daily*float64(days)*1.1, g.home, 50), g.home
+	t.CategoryBudgets = map[string]float64{models.CategoryFood: g.price(dest.daily*float64(days)*0.35, g.home, 10)}
+	if g.rnd.IntN(3) > 0 || r == roleCurrent {
+		t.Companions = pick(g, companions, 1+g.rnd.IntN(2))
+	}
+	if r == rolePast || r == roleYearAgo {
+		t.Rating = 3 + g.rnd.IntN(3)
+	}
+	rec := &storage.TripRecord{Trip: t}
+
+	// Every stop but the first takes an even part of the days, and the
+	// first what is left.
+	share := days / len(stops)
+	arrival := start
+	for i, s := range stops {
+		span := share
+		if i == 0 {
+			span = days - share*(len(stops)-1)
+		}
+		departure := arrival.AddDate(0, 0, span)
+		if i == len(stops)-1 {
+			departure = end
+		}
+		g.leg(rec, dest, s, i, arrival, departure)
+		arrival = departure
+	}
+
+	for _, goal := range dest.goals {
+		h := models.NewHighlight(t.ID, goal)
+		h.ID, h.Position = g.id(), len(rec.Highlights)
+		h.Done = r == rolePast || r == roleYearAgo || r == roleCurrent && g.rnd.IntN(2) == 0
+		h.CreatedAt, h.UpdatedAt = t.CreatedAt, t.CreatedAt
+		rec.Highlights = append(rec.Highlights, h)
+	}
+	switch r {
+	case roleCurrent:
+		g.packing(rec, 1)
+		g.health(rec)
+		g.recurrence(rec, dest)
+	case roleSoon:
+		g.packing(rec, 0.4)
+		g.prep(rec)
+	case roleLater:
+		g.prep(rec)
+	}
+	return rec
+}
+
+// title names a trip to dest by the season it starts in, with the year
+// when another trip has the name already.
+func (g *generator) title(dest destination, start time.Time) string {
+	season := []string{"winter", "spring", "summer", "autumn"}[int(start.Month())%12/3]
+	title := dest.name + " in " + season
+	if g.titles[title] {
+		title += fmt.Sprintf(" %d", start.Year())
+	}
+	g.titles[title] = true
+	return title
+}
+
+// leg makes up the stop s, the ith of the trip, from arrival to departure:
+// getting there, staying there and each day spent there until today.
+func (g *generator) leg(rec *storage.TripRecord, dest destination, s stop, i int, arrival, departure time.Time) {
+	t := rec.Trip
+	place, _ := places.Lookup(s.city)
+	zone := place.Timezone
+	l := models.NewLeg(t.ID, s.city, arrival)
+	l.ID, l.Transport = g.id(), s.transport
+	l.Departure = &departure
+	l.CreatedAt, l.UpdatedAt = t.CreatedAt, t.CreatedAt
+	rec.Legs = append(rec.Legs, l)
+
+	if i > 0 {
+		prev := rec.Legs[i-1]
+		from, _ := places.Lookup(prev.Location)
+		seg := models.NewSegment(t.ID, s.transport, arrival)
+		seg.ID, seg.Origin, seg.Destination = g.id(), prev.Location, s.city
+		km := places.Distance(from.Lat, from.Lon, place.Lat, place.Lon) / 1000
+		if s.transport != models.TransportFlight {
+			// Roads and rails wind.
+			km *= 1.2
+		}
+		seg.Distance = math.Round(km)
+		seg.CreatedAt = arrival
+		rec.Segments = append(rec.Segments, seg)
+
+		it := g.item(rec, arrival, "10:00", strings.ToUpper(s.transport[:1])+s.transport[1:]+" to "+s.city, prev.Location)
+		it.BookingRef = g.code(6)
+		it.Alarm = 60
+	}
+
+	stay := models.NewLodging(t.ID, s.stays[g.rnd.IntN(len(s.stays))], arrival, departure)
+	stay.ID, stay.LegID, stay.Address, stay.TimeZone = g.id(), l.ID, s.city, zone
+	stay.CheckIn, _ = models.LodgingTime(arrival, models.DefaultCheckInTime, zone)
+	stay.CheckOut, _ = models.LodgingTime(departure, models.DefaultCheckOutTime, zone)
+	stay.Confirmation = g.code(8)
+	stay.Cost, stay.Currency = g.price(dest.daily*0.45*float64(stay.Nights()), dest.currency, 0), dest.currency
+	stay.CreatedAt, stay.UpdatedAt = t.CreatedAt, t.CreatedAt
+	if stay.Nights() > 0 {
+		x := stay.CostExpense(nil)
+		x.ID, x.CreatedAt, x.UpdatedAt = g.id(), t.CreatedAt, t.CreatedAt
+		g.lock(x)
+		stay.ExpenseID = x.ID
+		rec.Expenses = append(rec.Expenses, x)
+		rec.Lodgings = append(rec.Lodgings, stay)
+	}
+
+	sights := g.rnd.Perm(len(s.sights))
+	last := departure
+	if i < len(rec.Trip.Locations)-1 {
+		// The day of departure is the next stop's.
+		last = departure.AddDate(0, 0, -1)
+	}
+	for n, day := 0, arrival; !day.After(last); n, day = n+1, day.AddDate(0, 0, 1) {
+		sight := s.sights[sights[n%len(sights)]]
+		food := dest.foods[g.rnd.IntN(len(dest.foods))]
+		visit := g.item(rec, day, "14:00", upperFirst(sight), s.city)
+		visit.Duration, visit.Kind = 150, "sight"
+		dinner := g.item(rec, day, "19:30", "Dinner: "+food, s.city)
+		dinner.Duration, dinner.Kind = 90, "meal"
+		if day.Before(g.today) {
+			g.timed(visit, 20, 75)
+			g.timed(dinner, 10, 30)
+		}
+		if day.After(g.today) {
+			continue
+		}
+		g.expenses(rec, dest, l, zone, day, sight, food)
+		g.checkIn(rec, place, day, sight)
+		if day.Before(g.today) && g.rnd.IntN(10) > 0 {
+			g.entry(rec, dest, l, zone, day, sight, food)
+		}
+	}
+}
+
+// item schedules an activity on the itinerary of day.
+func (g *generator) item(rec *storage.TripRecord, day time.Time, at, title, place string) *models.ItineraryItem {
+	it := models.NewItineraryItem(rec.Trip.ID, day, title)
+	it.ID, it.Time, it.Place = g.id(), at, place
+	it.CreatedAt, it.UpdatedAt = rec.Trip.CreatedAt, rec.Trip.CreatedAt
+	for _, other := range rec.Itinerary {
+		if other.Day.Equal(day) {
+			it.Position++
+		}
+	}
+	rec.Itinerary = append(rec.Itinerary, it)
+	return it
+}
+
+// timed logs when it, on a day gone, started and finished: up to late
+// minutes after its time and over minutes either way of its duration,
+// running over twice as often as not.
+func (g *generator) timed(it *models.ItineraryItem, late, over int) {
+	at, _ := time.Parse(models.TimeLayout, it.Time)
+	start := at.Add(time.Duration(g.rnd.IntN(late+1)) * time.Minute)
+	end := start.Add(time.Duration(it.Duration-over/2+g.rnd.IntN(3*over/2+1)) * time.Minute)
+	it.Started, it.Finished = start.Format(models.TimeLayout), end.Format(models.TimeLayout)
+}
+
+// expenses makes up what was spent on day at leg l: meals, getting
+// around, tickets to sight and now and then something to take home.
+func (g *generator) expenses(rec *storage.TripRecord, dest destination, l *models.Leg, zone string, day time.Time, sight, food string) {
+	spend := func(euros float64, category, description string, hour int) *models.Expense {
+		at := time.Date(day.Year(), day.Month(), day.Day(), hour, g.rnd.IntN(60), 0, 0, models.Zone(zone))
+		x := models.NewExpense(rec.Trip.ID, g.price(euros*dest.daily/100, dest.currency, 0), dest.currency, category, description, at)
+		x.ID, x.LegID, x.Location, x.TimeZone = g.id(), l.ID, l.Location, zone
+		x.CreatedAt, x.UpdatedAt = at, at
+		g.lock(x)
+		rec.Expenses = append(rec.Expenses, x)
+		return x
+	}
+	// Meals are shared with the companions, paid by whoever was quickest.
+	share := func(x *models.Expense) {
+		if len(rec.Trip.Companions) == 0 || g.rnd.IntN(3) == 0 {
+			return
+		}
+		people := rec.Trip.People()
+		if payer := people[g.rnd.IntN(len(people))]; payer != models.Me {
+			x.PaidBy = payer
+		}
+		x.Shares, _ = models.ParseSplit(models.SplitAll, x.Amount, people)
+	}
+
+	lunch := spend(8+g.rnd.Float64()*12, models.CategoryFood, "Lunch", 13)
+	share(lunch)
+	dinner := spend(15+g.rnd.Float64()*25, models.CategoryFood, upperFirst(food), 20)
+	if dest.currency == "MXN" {
+		dinner.Tip = g.price(dinner.Amount/perEuro[dest.currency]*0.1, dest.currency, 0)
+		dinner.Amount += dinner.Tip
+	}
+	share(dinner)
+	if g.rnd.IntN(2) == 0 {
+		spend(2+g.rnd.Float64()*4, models.CategoryTransport, "Metro", 9)
+	} else {
+		share(spend(8+g.rnd.Float64()*12, models.CategoryTransport, "Taxi", 22))
+	}
+	spend(5+g.rnd.Float64()*20, models.CategoryActivities, "Tickets for "+sight, 14)
+	if g.rnd.IntN(4) == 0 {
+		x := spend(6+g.rnd.Float64()*30, models.CategoryShopping, []string{"Souvenirs", "Postcards and stamps", "Sunscreen", "A book"}[g.rnd.IntN(4)], 17)
+		x.Tags = []string{"gifts"}
+	}
+}
+
+// checkIn records being at sight on day, somewhere in the city of place.
+func (g *generator) checkIn(rec *storage.TripRecord, place places.Place, day time.Time, sight string) {
+	at := time.Date(day.Year(), day.Month(), day.Day(), 14, 20+g.rnd.IntN(40), 0, 0, place.Location())
+	c := models.NewCheckIn(rec.Trip.ID, upperFirst(sight), at)
+	c.ID, c.TimeZone, c.CreatedAt = g.id(), place.Timezone, at
+	c.Lat = math.Round((place.Lat+(g.rnd.Float64()-0.5)*0.06)*1e4) / 1e4
+	c.Lon = math.Round((place.Lon+(g.rnd.Float64()-0.5)*0.06)*1e4) / 1e4
+	rec.CheckIns = append(rec.CheckIns, c)
+}
+
+// entry writes up day at leg l in the evening.
+func (g *generator) entry(rec *storage.TripRecord, dest destination, l *models.Leg, zone string, day time.Time, sight, food string) {
+	at := time.Date(day.Year(), day.Month(), day.Day(), 21, g.rnd.IntN(60), 0, 0, models.Zone(zone))
+	var b strings.Builder
+	fmt.Fprintf(&b, oneOf(g, openers)+" ", sight)
+	fmt.Fprintf(&b, oneOf(g, meals)+"\n\n", food)
+	if g.rnd.IntN(3) == 0 {
+		b.WriteString("Remember:\n\n")
+		for _, r := range pick(g, reminders, 2+g.rnd.IntN(2)) {
+			b.WriteString("- " + r + "\n")
+		}
+		b.WriteString("\n")
+	}
+	closer := oneOf(g, closers)
+	if strings.Contains(closer, "%s") {
+		closer = fmt.Sprintf(closer, l.Location)
+	}
+	b.WriteString(closer)
+
+	e := models.NewEntry(rec.Trip.ID, b.String(), at)
+	e.ID, e.LegID, e.Title = g.id(), l.ID, upperFirst(sight)
+	e.TimeZone, e.Location = zone, l.Location
+	// Most days on the road are good ones.
+	e.Mood = oneOf(g, []int{2, 3, 4, 4, 5, 5})
+	e.Tags = pick(g, dest.tags, g.rnd.IntN(3))
+	if len(rec.Trip.Companions) > 0 && g.rnd.IntN(2) == 0 {
+		e.People = pick(g, rec.Trip.Companions, 1+g.rnd.IntN(len(rec.Trip.Companions)))
+	}
+	e.Private = g.rnd.IntN(12) == 0
+	e.CreatedAt, e.UpdatedAt = at, at
+	rec.Entries = append(rec.Entries, e)
+}
+
+// packing makes up the packing list of the trip, with about packed of it
+// packed.
+func (g *generator) packing(rec *storage.TripRecord, packed float64) {
+	for _, p := range packing {
+		it := models.NewPackingItem(rec.Trip.ID, p.category, p.name)
+		it.ID, it.Position = g.id(), len(rec.Packing)
+		it.Packed = g.rnd.Float64() < packed
+		it.CreatedAt, it.UpdatedAt = rec.Trip.CreatedAt, rec.Trip.CreatedAt
+		rec.Packing = append(rec.Packing, it)
+	}
+}
+
+// prep makes up what to do before the trip leaves, most of what is due by
+// today done.
+func (g *generator) prep(rec *storage.TripRecord) {
+	for _, p := range prep {
+		task := models.NewPrepTask(rec.Trip.ID, p.title, p.days*24*60)
+		task.ID = g.id()
+		task.Done = rec.Trip.StartDate.AddDate(0, 0, -p.days).Before(g.today) && g.rnd.IntN(4) > 0
+		task.CreatedAt, task.UpdatedAt = rec.Trip.CreatedAt, rec.Trip.CreatedAt
+		rec.Prep = append(rec.Prep, task)
+	}
+}
+
+// health logs how the days of the trip before today went, the jetlag
+// wearing off.
+func (g *generator) health(rec *storage.TripRecord) {
+	for i, day := 0, rec.Trip.StartDate; day.Before(g.today); i, day = i+1, day.AddDate(0, 0, 1) {
+		rec.Health = append(rec.Health, &models.HealthDay{
+			TripID:    rec.Trip.ID,
+			Day:       day.Format(models.DateLayout),
+			Sleep:     5.5 + float64(g.rnd.IntN(7))/2,
+			Water:     1.5 + float64(g.rnd.IntN(7))/4,
+			Jetlag:    max(1, 4-i),
+			UpdatedAt: day.Add(22 * time.Hour),
+		})
+	}
+}
+
+// recurrence pays for an eSIM every week of the trip, with the weeks due by
+// today recorded.
+func (g *generator) recurrence(rec *storage.TripRecord, dest destination) {
+	t := rec.Trip
+	r := models.NewRecurrence(g.price(12, dest.currency, 0), dest.currency, models.CategoryOther, "eSIM data plan",
+		models.IntervalWeekly, t.StartDate)
+	r.ID, r.TripID, r.End = g.id(), t.ID, t.EndDate
+	r.CreatedAt, r.UpdatedAt = t.CreatedAt, t.CreatedAt
+	for _, day := range r.Due(g.today) {
+		x := r.Expense(t.ID, day, places.DayZone(t, rec.Legs, "", day))
+		x.ID, x.CreatedAt, x.UpdatedAt = g.id(), x.Timestamp, x.Timestamp
+		if l := models.LegOn(rec.Legs, x.Timestamp); l != nil {
+			x.LegID = l.ID
+		}
+		g.lock(x)
+		rec.Expenses = append(rec.Expenses, x)
+	}
+	r.Next = r.From(g.today.AddDate(0, 0, 1))
+	rec.Recurrences = append(rec.Recurrences, r)
+}
+
+// lock locks the rate of x into the home currency.
+func (g *generator) lock(x *models.Expense) {
+	if x.Currency == g.home {
+		return
+	}
+	x.Rate, x.RateCurrency = g.homeRate/perEuro[x.Currency], g.home
+}
+
+// price is about euros in currency, rounded as prices there are: to a
+// multiple of step, or by how small a unit of currency is when step is 0.
+func (g *generator) price(euros float64, currency string, step float64) float64 {
+	v := euros * perEuro[currency]
+	if step == 0 {
+		switch {
+		case perEuro[currency] >= 1000:
+			step = 1000
+		case perEuro[currency] >= 100:
+			step = 10
+		case perEuro[currency] >= 10:
+			step = 1
+		default:
+			step = 0.5
+		}
+	}
+	return math.Max(step, math.Round(v/step)*step)
+}
+
+// id makes up an ID like models.NewID, from the seed.
+func (g *generator) id() string {
+	return fmt.Sprintf("%016x", g.rnd.Uint64())
+}
+
+// code makes up a booking reference of n letters and digits.
+func (g *generator) code(n int) string {
+	const chars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = chars[g.rnd.IntN(len(chars))]
+	}
+	return string(b)
+}
+
+// pick picks up to n of list at random, in the order of list.
+func pick(g *generator, list []string, n int) []string {
+	chosen := g.rnd.Perm(len(list))[:min(n, len(list))]
+	slices.Sort(chosen)
+	var out []string
+	for _, i := range chosen {
+		out = append(out, list[i])
+	}
+	return out
+}
+
+func oneOf[T any](g *generator, list []T) T {
+	return list[g.rnd.IntN(len(list))]
+}
+
+func upperFirst(s string) string {
+	r := []rune(s)
+	return strings.ToUpper(string(r[:1])) + string(r[1:])
+}
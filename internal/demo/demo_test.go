@@ -0,0 +1,65 @@
+package demo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// now is the day the journals of the tests are made up around.
+var now = time.Date(2026, 3, 14, 10, 0, 0, 0, time.Local)
+
+func TestGenerateIsSeeded(t *testing.T) {
+	a := Generate(Options{Seed: 7, Now: now, Home: "EUR"})
+	b := Generate(Options{Seed: 7, Now: now, Home: "EUR"})
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("the same seed made up different journals")
+	}
+	c := Generate(Options{Seed: 8, Now: now, Home: "EUR"})
+	if reflect.DeepEqual(a.Dump.Trips, c.Dump.Trips) {
+		t.Fatal("another seed made up the same trips")
+	}
+}
+
+func TestFill(t *testing.T) {
+	ctx := t.Context()
+	store, err := storage.Open(ctx, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	j := Generate(Options{Seed: 1, Now: now, Home: "USD", Trips: 9})
+	report, err := Fill(ctx, store, j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Added["trip"]; got != 9 {
+		t.Errorf("added %d trips, want 9", got)
+	}
+	trips, err := store.ListTrips(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var current, future int
+	for _, trip := range trips {
+		switch {
+		case trip.InProgress(now):
+			current++
+		case trip.StartDate.After(now):
+			future++
+		}
+		if trip.BudgetCurrency != "USD" {
+			t.Errorf("trip %q has its budget in %s, want USD", trip.Title, trip.BudgetCurrency)
+		}
+	}
+	if current != 1 || future != 2 {
+		t.Errorf("made up %d trips in progress and %d to come, want 1 and 2", current, future)
+	}
+	for _, kind := range []string{"entry", "expense", "itinerary item", "lodging", "vault item"} {
+		if report.Added[kind] == 0 {
+			t.Errorf("made up no %s", kind)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package demo
+
+import "github.com/girdharshubham/nomadic/internal/models"
+
+// destination is a country a made-up trip goes to: the cities it stops at
+// and what there is to do, eat and buy there.
+type destination struct {
+	name string
+	// currency is spent there, perEuro of it to a euro.
+	currency string
+	perEuro  float64
+	// daily is roughly what a day there costs in euros.
+	daily float64
+	stops []stop
+	foods []string
+	// goals are what a trip there sets out to do.
+	goals []string
+	tags  []string
+}
+
+// stop is a city of a destination: how it is reached from the one before,
+// where to stay and what to see.
+type stop struct {
+	city      string
+	transport string
+	stays     []string
+	sights    []string
+}
+
+var destinations = []destination{
+	{
+		name: "Japan", currency: "JPY", perEuro: 162, daily: 140,
+		stops: []stop{
+			{city: "Tokyo", transport: models.TransportFlight, stays: []string{"Hotel Gracery Shinjuku", "Sakura Hostel Asakusa"},
+				sights: []string{"Senso-ji", "Shibuya Crossing", "Meiji Shrine", "teamLab Planets", "Tsukiji Outer Market", "Yanaka Ginza"}},
+			{city: "Kyoto", transport: models.TransportTrain, stays: []string{"Ryokan Yachiyo", "Piece Hostel Sanjo"},
+				sights: []string{"Fushimi Inari", "Kinkaku-ji", "Arashiyama bamboo grove", "Nishiki Market", "Gion at dusk"}},
+			{city: "Osaka", transport: models.TransportTrain, stays: []string{"Cross Hotel Osaka"},
+				sights: []string{"Dotonbori", "Osaka Castle", "Kuromon Market", "Shinsekai"}},
+		},
+		foods: []string{"tonkotsu ramen", "conveyor-belt sushi", "okonomiyaki", "takoyaki", "matcha parfait", "a bento from the station"},
+		goals: []string{"See Fushimi Inari at sunrise", "Eat at a standing sushi bar", "Take the shinkansen", "Stay a night in a ryokan"},
+		tags:  []string{"food", "temples", "city"},
+	},
+	{
+		name: "Portugal", currency: "EUR", perEuro: 1, daily: 95,
+		stops: []stop{
+			{city: "Lisbon", transport: models.TransportFlight, stays: []string{"Memmo Alfama", "Lost Inn Lisbon"},
+				sights: []string{"Castelo de São Jorge", "Tram 28", "Belém Tower", "LX Factory", "Miradouro da Graça"}},
+			{city: "Porto", transport: models.TransportTrain, stays: []string{"Casa do Conto", "Gallery Hostel"},
+				sights: []string{"Ribeira", "Livraria Lello", "Port cellars in Gaia", "Dom Luís I Bridge"}},
+		},
+		foods: []string{"pastéis de nata", "grilled sardines", "a francesinha", "bacalhau à brás", "bifana", "vinho verde"},
+		goals: []string{"Ride Tram 28 end to end", "Hear fado in Alfama", "Taste port in Gaia"},
+		tags:  []string{"food", "coast", "city"},
+	},
+	{
+		name: "Italy", currency: "EUR", perEuro: 1, daily: 120,
+		stops: []stop{
+			{city: "Rome", transport: models.TransportFlight, stays: []string{"Hotel Artemide", "The Beehive"},
+				sights: []string{"the Colosseum", "the Pantheon", "Trastevere", "the Vatican Museums", "Villa Borghese"}},
+			{city: "Florence", transport: models.TransportTrain, stays: []string{"Hotel Spadai"},
+				sights: []string{"the Uffizi", "Piazzale Michelangelo", "the Duomo", "Mercato Centrale"}},
+			{city: "Venice", transport: models.TransportTrain, stays: []string{"Ca' Pisani"},
+				sights: []string{"St Mark's Square", "the Rialto market", "Burano", "the Accademia"}},
+		},
+		foods: []string{"cacio e pepe", "supplì", "a bistecca alla fiorentina", "gelato", "cicchetti", "a spritz"},
+		goals: []string{"Climb the Duomo", "Eat cicchetti in a bacaro", "See the Sistine Chapel"},
+		tags:  []string{"food", "art", "history"},
+	},
+	{
+		name: "Mexico", currency: "MXN", perEuro: 19.5, daily: 70,
+		stops: []stop{
+			{city: "Mexico City", transport: models.TransportFlight, stays: []string{"Casa Pepe", "Hotel Carlota"},
+				sights: []string{"the Zócalo", "Museo Nacional de Antropología", "Coyoacán", "Chapultepec", "Teotihuacán"}},
+			{city: "Oaxaca", transport: models.TransportBus, stays: []string{"Casa Antigua"},
+				sights: []string{"Monte Albán", "Mercado 20 de Noviembre", "Hierve el Agua", "Templo de Santo Domingo"}},
+		},
+		foods: []string{"tacos al pastor", "mole negro", "a tlayuda", "chilaquiles", "churros", "mezcal"},
+		goals: []string{"Climb the Pyramid of the Sun", "Take a cooking class in Oaxaca", "Try every kind of mole"},
+		tags:  []string{"food", "history", "markets"},
+	},
+	{
+		name: "Thailand", currency: "THB", perEuro: 38, daily: 55,
+		stops: []stop{
+			{city: "Bangkok", transport: models.TransportFlight, stays: []string{"Riva Surya", "Lub d Silom"},
+				sights: []string{"Wat Pho", "the Grand Palace", "Chatuchak market", "a longtail boat on the khlongs", "Chinatown at night"}},
+			{city: "Chiang Mai", transport: models.TransportTrain, stays: []string{"Tamarind Village"},
+				sights: []string{"Doi Suthep", "the Sunday walking street", "an elephant sanctuary", "Wat Chedi Luang"}},
+		},
+		foods: []string{"pad kra pao", "khao soi", "mango sticky rice", "som tam", "boat noodles", "a Thai iced tea"},
+		goals: []string{"Take the night train north", "Eat khao soi in Chiang Mai", "Learn to cook a green curry"},
+		tags:  []string{"food", "temples", "markets"},
+	},
+	{
+		name: "Peru", currency: "PEN", perEuro: 4.1, daily: 65,
+		stops: []stop{
+			{city: "Lima", transport: models.TransportFlight, stays: []string{"Casa Republica Barranco"},
+				sights: []string{"Miraflores clifftops", "Barranco", "the Larco Museum", "Mercado de Surquillo"}},
+			{city: "Cusco", transport: models.TransportFlight, stays: []string{"Casa Andina San Blas", "Pariwana Hostel"},
+				sights: []string{"Machu Picchu", "the Sacred Valley", "San Pedro market", "Sacsayhuamán", "Rainbow Mountain"}},
+		},
+		foods: []string{"ceviche", "lomo saltado", "ají de gallina", "a pisco sour", "anticuchos", "picarones"},
+		goals: []string{"Walk to the Sun Gate at Machu Picchu", "Eat ceviche by the sea", "Get used to the altitude"},
+		tags:  []string{"hiking", "food", "mountains"},
+	},
+	{
+		name: "Vietnam", currency: "VND", perEuro: 27500, daily: 45,
+		stops: []stop{
+			{city: "Hanoi", transport: models.TransportFlight, stays: []string{"La Siesta Classic", "Little Charm Hanoi"},
+				sights: []string{"Hoan Kiem Lake", "the Old Quarter", "the Temple of Literature", "Train Street"}},
+			{city: "Ho Chi Minh City", transport: models.TransportFlight, stays: []string{"The Myst Dong Khoi"},
+				sights: []string{"Ben Thanh market", "the War Remnants Museum", "the Cu Chi tunnels", "the Mekong Delta"}},
+		},
+		foods: []string{"bún chả", "phở bò", "bánh mì", "egg coffee", "bánh xèo", "fresh spring rolls"},
+		goals: []string{"Cross the street like a local", "Drink egg coffee in Hanoi", "See the Mekong Delta"},
+		tags:  []string{"food", "city", "markets"},
+	},
+	{
+		name: "Andalusia", currency: "EUR", perEuro: 1, daily: 90,
+		stops: []stop{
+			{city: "Seville", transport: models.TransportFlight, stays: []string{"Hotel Amadeus", "Triana Backpackers"},
+				sights: []string{"the Real Alcázar", "Plaza de España", "the cathedral and the Giralda", "Triana market"}},
+			{city: "Granada", transport: models.TransportBus, stays: []string{"Casa del Capitel Nazarí"},
+				sights: []string{"the Alhambra", "the Albaicín", "Mirador de San Nicolás", "Sacromonte"}},
+		},
+		foods: []string{"salmorejo", "jamón ibérico", "tortillitas de camarones", "churros con chocolate", "free tapas", "a tinto de verano"},
+		goals: []string{"See the Alhambra at opening", "Watch flamenco in Triana", "Eat tapas free with every drink"},
+		tags:  []string{"history", "food", "sun"},
+	},
+}
+
+// people are the companions made up, and how to reach them.
+var people = []struct{ name, contact string }{
+	{"Ana", "ana@example.com"},
+	{"Ben", "+44 7700 900123"},
+	{"Mei", "mei@example.com"},
+}
+
+// wishes are the places of the wishlist.
+var wishes = []struct {
+	place    string
+	notes    string
+	budget   float64
+	season   []int
+	priority string
+}{
+	{"Patagonia", "W trek in Torres del Paine, then El Chaltén.", 3500, []int{12, 1, 2}, models.PriorityHigh},
+	{"Iceland", "Ring road in a campervan; northern lights if lucky.", 2800, []int{9, 10}, models.PriorityMedium},
+	{"Kyrgyzstan", "Horse trek to Song Kul and yurt stays.", 1600, []int{7, 8}, models.PriorityMedium},
+	{"New Zealand", "South Island: Milford Sound and the Routeburn track.", 4200, []int{1, 2, 3}, models.PriorityLow},
+}
+
+// Phrases the text of journal entries is made of: %s is a sight, a food
+// or a city as each says.
+var (
+	openers = []string{
+		"Up early and out to %s before the crowds arrived.",
+		"Slow start today, then an afternoon at %s.",
+		"Rain all morning, so we ducked into %s and stayed for hours.",
+		"Finally made it to %s — worth every minute of the queue.",
+		"Wandered without a plan and ended up at %s.",
+		"Took the long way round to %s, through streets no guidebook mentions.",
+	}
+	meals = []string{
+		"Lunch was **%s**, easily the best so far.",
+		"Tried %s at a tiny counter with six stools.",
+		"Dinner: %s, recommended by the woman at the front desk.",
+		"Shared %s on a bench and watched the city go by.",
+		"Could happily eat %s every day of this trip.",
+	}
+	closers = []string{
+		"Back at the room by ten, feet aching.",
+		"Early night; tomorrow starts early.",
+		"Sat up writing postcards that will arrive after we do.",
+		"Checked the forecast twice and packed for rain anyway.",
+		"Still can't believe we're in %s.",
+	}
+	reminders = []string{
+		"Buy a transit card first thing",
+		"Cash only at most of the small places",
+		"Ask about the sunset tour",
+		"Charge the camera",
+		"Postcards for Mum",
+		"Find the laundrette",
+	}
+)
+
+// packing is the packing list of trips being packed for, by category.
+var packing = []struct{ category, name string }{
+	{"Documents", "Passport"},
+	{"Documents", "Travel insurance"},
+	{"Documents", "Printed bookings"},
+	{"Clothes", "Rain jacket"},
+	{"Clothes", "Walking shoes"},
+	{"Clothes", "Swimsuit"},
+	{"Electronics", "Phone charger"},
+	{"Electronics", "Travel adapter"},
+	{"Electronics", "Power bank"},
+	{"Toiletries", "Sunscreen"},
+	{"Toiletries", "Toothbrush"},
+	{"Health", "First aid kit"},
+}
+
+// prep are the tasks to do before a trip, with how many days before it.
+var prep = []struct {
+	title string
+	days  int
+}{
+	{"Book flights", 60},
+	{"Buy travel insurance", 21},
+	{"Check passport validity", 30},
+	{"Order local currency", 5},
+	{"Check in online", 1},
+}
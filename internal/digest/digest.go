@@ -0,0 +1,144 @@
+// Package digest sums up the journal of the last days for the people at
+// home: the entries written, what was spent and the itinerary of the days
+// ahead, as one Markdown or HTML document to read or mail.
+package digest
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Converter converts amount from one currency to another.
+type Converter func(amount float64, from, to string) (float64, error)
+
+// Journal is what a digest is drawn from: the trips with their entries,
+// expenses and itinerary. The digest picks out what falls in its days.
+type Journal struct {
+	Trips     []*models.Trip
+	Entries   []*models.Entry
+	Expenses  []*models.Expense
+	Itinerary []*models.ItineraryItem
+}
+
+// Digest is what happened from Since to Until, and what is planned from
+// Until to Ahead.
+type Digest struct {
+	Since, Until, Ahead time.Time
+	// Entries are the entries written in the period, oldest first,
+	// private ones left out and private passages stripped: the digest is
+	// for sharing.
+	Entries []Entry
+	// Spent is what was spent in the period in Currency, by category, the
+	// most first; Unconverted counts the expenses no rate was found for.
+	Spent       []Sum
+	Total       float64
+	Currency    string
+	Expenses    int
+	Unconverted int
+	// Upcoming are the days of the itinerary ahead with something planned.
+	Upcoming []Day
+}
+
+// Entry is an entry of the digest with the title of its trip.
+type Entry struct {
+	*models.Entry
+	Trip string
+}
+
+// Sum is what was spent on a category.
+type Sum struct {
+	Category string
+	Amount   float64
+}
+
+// Day is a day of the itinerary ahead on a trip.
+type Day struct {
+	Day   time.Time
+	Trip  string
+	Items []*models.ItineraryItem
+}
+
+// Compile makes the digest of the days from since to until, and of the
+// itinerary from until to ahead, totalling the spend in currency with
+// convert, which may be nil without exchange rates.
+func Compile(j Journal, since, until, ahead time.Time, currency string, convert Converter) *Digest {
+	d := &Digest{Since: since, Until: until, Ahead: ahead, Currency: currency}
+	titles := map[string]string{}
+	for _, t := range j.Trips {
+		titles[t.ID] = t.Title
+	}
+	for _, e := range j.Entries {
+		if e.Timestamp.Before(since) || !e.Timestamp.Before(until) {
+			continue
+		}
+		if e = e.Redact(models.RedactStrip); e != nil {
+			d.Entries = append(d.Entries, Entry{Entry: e, Trip: titles[e.TripID]})
+		}
+	}
+	slices.SortStableFunc(d.Entries, func(a, b Entry) int { return a.Timestamp.Compare(b.Timestamp) })
+
+	byCategory := map[string]float64{}
+	for _, x := range j.Expenses {
+		if x.Timestamp.Before(since) || !x.Timestamp.Before(until) {
+			continue
+		}
+		d.Expenses++
+		v, err := x.In(currency, convert)
+		if err != nil {
+			d.Unconverted++
+			continue
+		}
+		byCategory[x.Category] += v
+		d.Total += v
+	}
+	for c, v := range byCategory {
+		d.Spent = append(d.Spent, Sum{Category: c, Amount: v})
+	}
+	slices.SortFunc(d.Spent, func(a, b Sum) int {
+		return cmp.Or(cmp.Compare(b.Amount, a.Amount), strings.Compare(a.Category, b.Category))
+	})
+
+	// Itinerary days are dates, compared as such.
+	from, to := until.Format(models.DateLayout), ahead.Format(models.DateLayout)
+	for _, it := range j.Itinerary {
+		day := it.Day.Format(models.DateLayout)
+		if day < from || day >= to {
+			continue
+		}
+		i := slices.IndexFunc(d.Upcoming, func(u Day) bool {
+			return u.Day.Format(models.DateLayout) == day && u.Trip == titles[it.TripID]
+		})
+		if i < 0 {
+			d.Upcoming = append(d.Upcoming, Day{Day: it.Day, Trip: titles[it.TripID]})
+			i = len(d.Upcoming) - 1
+		}
+		d.Upcoming[i].Items = append(d.Upcoming[i].Items, it)
+	}
+	slices.SortStableFunc(d.Upcoming, func(a, b Day) int {
+		return cmp.Or(strings.Compare(a.Day.Format(models.DateLayout), b.Day.Format(models.DateLayout)),
+			strings.Compare(a.Trip, b.Trip))
+	})
+	for _, u := range d.Upcoming {
+		slices.SortStableFunc(u.Items, func(a, b *models.ItineraryItem) int {
+			return cmp.Or(strings.Compare(a.Time, b.Time), cmp.Compare(a.Position, b.Position))
+		})
+	}
+	return d
+}
+
+// Empty reports whether nothing was written or spent in the period and
+// nothing is planned ahead.
+func (d *Digest) Empty() bool {
+	return len(d.Entries) == 0 && d.Expenses == 0 && len(d.Upcoming) == 0
+}
+
+// Subject is the subject of the digest mailed, such as "Travel digest,
+// 2025-04-01 to 2025-04-07", with dates in the Go layout dateLayout.
+func (d *Digest) Subject(dateLayout string) string {
+	return fmt.Sprintf("Travel digest, %s to %s", d.Since.Format(dateLayout), d.Until.AddDate(0, 0, -1).Format(dateLayout))
+}
@@ -0,0 +1,148 @@
+package digest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Server is the SMTP server a digest is mailed through. Its port is 465
+// for TLS from the start, or another, such as 587, for STARTTLS when the
+// server offers it. User and Password log in, unless User is empty.
+type Server struct {
+	Address  string
+	User     string
+	Password string
+}
+
+// Message is a digest to mail, as Markdown for plain-text readers and as
+// HTML for the others.
+type Message struct {
+	From     string
+	To       []string
+	Subject  string
+	Markdown string
+	HTML     string
+}
+
+// Send mails m through the server.
+func (s Server) Send(m Message) error {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return fmt.Errorf("digest: from %q: %w", m.From, err)
+	}
+	if len(m.To) == 0 {
+		return errors.New("digest: no one to send the digest to")
+	}
+	var to []string
+	for _, addr := range m.To {
+		a, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("digest: to %q: %w", addr, err)
+		}
+		to = append(to, a.Address)
+	}
+	host, port, err := net.SplitHostPort(s.Address)
+	if err != nil {
+		return fmt.Errorf("digest: smtp server %q is not a host:port address", s.Address)
+	}
+	var auth smtp.Auth
+	if s.User != "" {
+		auth = smtp.PlainAuth("", s.User, s.Password, host)
+	}
+	msg, err := m.build(from.String())
+	if err != nil {
+		return err
+	}
+	if port != "465" {
+		if err := smtp.SendMail(s.Address, auth, from.Address, to, msg); err != nil {
+			return fmt.Errorf("digest: send: %w", err)
+		}
+		return nil
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", s.Address, &tls.Config{ServerName: host})
+	if err != nil {
+		return fmt.Errorf("digest: send: %w", err)
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("digest: send: %w", err)
+	}
+	defer c.Close()
+	if err := deliver(c, auth, from.Address, to, msg); err != nil {
+		return fmt.Errorf("digest: send: %w", err)
+	}
+	return c.Quit()
+}
+
+// deliver sends msg on a connection already secured.
+func deliver(c *smtp.Client, auth smtp.Auth, from string, to []string, msg []byte) error {
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// build writes the message as multipart/alternative, the Markdown first
+// as the plainest.
+func (m Message) build(from string) ([]byte, error) {
+	var boundary [12]byte
+	if _, err := rand.Read(boundary[:]); err != nil {
+		return nil, err
+	}
+	b := "nomadic-" + hex.EncodeToString(boundary[:])
+
+	var buf bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", from)
+	header("To", strings.Join(m.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/alternative; boundary="`+b+`"`)
+	buf.WriteString("\r\n")
+	for _, part := range []struct{ kind, body string }{{"text/plain", m.Markdown}, {"text/html", m.HTML}} {
+		fmt.Fprintf(&buf, "--%s\r\n", b)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=utf-8\r\n", part.kind)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(strings.ReplaceAll(part.body, "\n", "\r\n"))); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	fmt.Fprintf(&buf, "--%s--\r\n", b)
+	return buf.Bytes(), nil
+}
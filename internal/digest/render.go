@@ -0,0 +1,233 @@
+package digest
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Markdown writes the digest as a Markdown document: the entries of the
+// period under their dates, the spend by category and the days ahead.
+// Dates are formatted with the Go layout dateLayout.
+func Markdown(w io.Writer, d *Digest, dateLayout string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n", d.Subject(dateLayout))
+
+	fmt.Fprintf(bw, "\n## Journal\n\n")
+	if len(d.Entries) == 0 {
+		fmt.Fprintln(bw, "Nothing written.")
+	}
+	for _, e := range d.Entries {
+		fmt.Fprintf(bw, "### %s\n\n", entryHeading(e, dateLayout))
+		if line := entryLine(e); line != "" {
+			fmt.Fprintf(bw, "*%s*\n\n", line)
+		}
+		if text := strings.TrimSpace(e.Text); text != "" {
+			fmt.Fprintf(bw, "%s\n\n", demote(text))
+		}
+	}
+
+	fmt.Fprintf(bw, "\n## Spending\n\n")
+	if d.Expenses == 0 {
+		fmt.Fprintln(bw, "Nothing spent.")
+	} else {
+		fmt.Fprintf(bw, "%s in %d %s.\n\n", money(d.Total, d.Currency), d.Expenses, plural(d.Expenses, "expense", "expenses"))
+		fmt.Fprintln(bw, "| Category | Spent |")
+		fmt.Fprintln(bw, "|---|---:|")
+		for _, s := range d.Spent {
+			fmt.Fprintf(bw, "| %s | %s |\n", models.CategoryLabel(s.Category), money(s.Amount, d.Currency))
+		}
+		if d.Unconverted > 0 {
+			fmt.Fprintf(bw, "\n%d %s left out, with no rate into %s.\n", d.Unconverted,
+				plural(d.Unconverted, "expense", "expenses"), d.Currency)
+		}
+	}
+
+	fmt.Fprintf(bw, "\n## Coming up\n\n")
+	if len(d.Upcoming) == 0 {
+		fmt.Fprintln(bw, "Nothing planned.")
+	}
+	for _, u := range d.Upcoming {
+		fmt.Fprintf(bw, "### %s\n\n", dayHeading(u, dateLayout))
+		for _, it := range u.Items {
+			fmt.Fprintf(bw, "- %s\n", itemLine(it))
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+// HTML writes the digest as a self-contained web page, for the body of a
+// mail, with the Markdown of the entries rendered. Dates are formatted
+// with the Go layout dateLayout.
+func HTML(w io.Writer, d *Digest, dateLayout string) error {
+	p := htmlPage{Title: d.Subject(dateLayout)}
+	for _, e := range d.Entries {
+		p.Entries = append(p.Entries, htmlEntry{Heading: entryHeading(e, dateLayout), Line: entryLine(e), Text: export.RenderMarkdown(demote(e.Text))})
+	}
+	if d.Expenses > 0 {
+		p.Total = fmt.Sprintf("%s in %d %s", money(d.Total, d.Currency), d.Expenses, plural(d.Expenses, "expense", "expenses"))
+	}
+	if d.Unconverted > 0 {
+		p.Unconverted = fmt.Sprintf("%d %s left out, with no rate into %s", d.Unconverted,
+			plural(d.Unconverted, "expense", "expenses"), d.Currency)
+	}
+	for _, s := range d.Spent {
+		p.Spent = append(p.Spent, htmlSum{Category: models.CategoryLabel(s.Category), Amount: money(s.Amount, d.Currency)})
+	}
+	for _, u := range d.Upcoming {
+		day := htmlDay{Heading: dayHeading(u, dateLayout)}
+		for _, it := range u.Items {
+			day.Items = append(day.Items, itemLine(it))
+		}
+		p.Upcoming = append(p.Upcoming, day)
+	}
+	return htmlTemplate.Execute(w, p)
+}
+
+type htmlPage struct {
+	Title              string
+	Entries            []htmlEntry
+	Total, Unconverted string
+	Spent              []htmlSum
+	Upcoming           []htmlDay
+}
+
+type htmlEntry struct {
+	Heading, Line string
+	Text          template.HTML
+}
+
+type htmlSum struct {
+	Category, Amount string
+}
+
+type htmlDay struct {
+	Heading string
+	Items   []string
+}
+
+var htmlTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40em; margin: 2em auto; padding: 0 1em; color: #222; line-height: 1.5; }
+h1 { font-size: 1.6em; }
+h2 { border-bottom: 1px solid #ddd; padding-bottom: .2em; margin-top: 1.6em; }
+h3 { margin-bottom: .2em; }
+.line { color: #777; font-style: italic; margin-top: 0; }
+table { border-collapse: collapse; }
+td, th { padding: .2em .8em; border-bottom: 1px solid #eee; text-align: left; }
+td.amount { text-align: right; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<h2>Journal</h2>
+{{range .Entries}}<h3>{{.Heading}}</h3>
+{{if .Line}}<p class="line">{{.Line}}</p>
+{{end}}{{.Text}}
+{{else}}<p>Nothing written.</p>
+{{end}}
+<h2>Spending</h2>
+{{if .Total}}<p>{{.Total}}.</p>
+<table>
+<tr><th>Category</th><th>Spent</th></tr>
+{{range .Spent}}<tr><td>{{.Category}}</td><td class="amount">{{.Amount}}</td></tr>
+{{end}}</table>
+{{if .Unconverted}}<p>{{.Unconverted}}.</p>
+{{end}}{{else}}<p>Nothing spent.</p>
+{{end}}
+<h2>Coming up</h2>
+{{range .Upcoming}}<h3>{{.Heading}}</h3>
+<ul>
+{{range .Items}}<li>{{.}}</li>
+{{end}}</ul>
+{{else}}<p>Nothing planned.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// entryHeading is the date and title of an entry, as "2025-04-03 · Kyoto
+// in the rain".
+func entryHeading(e Entry, dateLayout string) string {
+	heading := models.InZone(e.Timestamp, e.TimeZone).Format(dateLayout)
+	if e.Title != "" {
+		heading += " · " + e.Title
+	}
+	return heading
+}
+
+// entryLine tells the trip, place and mood of an entry.
+func entryLine(e Entry) string {
+	var parts []string
+	if e.Trip != "" {
+		parts = append(parts, e.Trip)
+	}
+	if e.Location != "" {
+		parts = append(parts, e.Location)
+	}
+	if e.Mood > 0 {
+		parts = append(parts, models.MoodFace(e.Mood))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// dayHeading is the date of a day ahead with its trip.
+func dayHeading(u Day, dateLayout string) string {
+	heading := u.Day.Format("Mon ") + u.Day.Format(dateLayout)
+	if u.Trip != "" {
+		heading += " · " + u.Trip
+	}
+	return heading
+}
+
+// itemLine is an activity ahead, as "09:00 Fushimi Inari (Kyoto, 2h30m)".
+func itemLine(it *models.ItineraryItem) string {
+	line := it.Title
+	if it.Time != "" {
+		line = it.Time + " " + line
+	}
+	var details []string
+	if it.Place != "" {
+		details = append(details, it.Place)
+	}
+	if it.Duration > 0 {
+		details = append(details, models.FormatDuration(it.Duration))
+	}
+	if len(details) > 0 {
+		line += " (" + strings.Join(details, ", ") + ")"
+	}
+	return line
+}
+
+// demote pushes the headings of an entry's Markdown below the entry's own.
+func demote(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, "#") {
+			lines[i] = "###" + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func money(v float64, currency string) string {
+	return fmt.Sprintf("%.2f %s", v, currency)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,363 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// CSV fields, in the column order used by WriteExpensesCSV.
+const (
+	FieldDate        = "date"
+	FieldTrip        = "trip"
+	FieldCategory    = "category"
+	FieldDescription = "description"
+	FieldAmount      = "amount"
+	FieldCurrency    = "currency"
+	FieldNote        = "note"
+	FieldTax         = "tax"
+	FieldTip         = "tip"
+)
+
+// CSVFields lists the expense fields understood by the CSV importer.
+var CSVFields = []string{FieldDate, FieldTrip, FieldCategory, FieldDescription, FieldAmount, FieldCurrency, FieldNote,
+	FieldTax, FieldTip}
+
+// WriteExpensesCSV writes every expense of trips as CSV with a header row.
+// Dates use models.DateLayout so files round-trip through the importer.
+func WriteExpensesCSV(w io.Writer, trips []*Trip) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVFields); err != nil {
+		return err
+	}
+	for _, t := range trips {
+		for _, x := range t.Expenses {
+			err := cw.Write([]string{
+				x.Timestamp.Format(models.DateLayout),
+				t.Title,
+				x.Category,
+				x.Description,
+				strconv.FormatFloat(x.Amount, 'f', 2, 64),
+				x.Currency,
+				x.Note,
+				formatPart(x.Tax),
+				formatPart(x.Tip),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ImportOptions controls how CSV rows become expenses.
+type ImportOptions struct {
+	// Mapping maps a field from CSVFields to the header of the column
+	// holding it. Unmapped fields are looked up by their own name.
+	Mapping map[string]string
+	// DateLayouts are tried in order; models.DateLayout is always tried last.
+	DateLayouts []string
+	// DefaultCurrency is used for rows without a currency.
+	DefaultCurrency string
+}
+
+// ImportedRow is one parsed CSV row. Expense has no TripID yet; Trip holds
+// the row's trip column, if any.
+type ImportedRow struct {
+	Line    int
+	Trip    string
+	Expense *models.Expense
+	Err     error
+	// DuplicateOf is the stored expense, or the expense of an earlier row,
+	// that the row is a likely duplicate of.
+	DuplicateOf *models.Expense
+}
+
+// ParseMapping reads "field=Column" pairs, as typed on the command line.
+func ParseMapping(pairs []string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, p := range pairs {
+		field, column, ok := strings.Cut(p, "=")
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !ok || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("column mapping %q is not field=Column", p)
+		}
+		if !isField(field) {
+			return nil, fmt.Errorf("unknown field %q; expected one of %s", field, strings.Join(CSVFields, ", "))
+		}
+		m[field] = strings.TrimSpace(column)
+	}
+	return m, nil
+}
+
+func isField(f string) bool {
+	for _, c := range CSVFields {
+		if c == f {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseExpensesCSV reads expenses from CSV with a header row. Rows that
+// cannot be parsed are returned with Err set rather than failing the file;
+// an error is returned only when the file itself is unusable.
+func ParseExpensesCSV(r io.Reader, opts ImportOptions) ([]ImportedRow, error) {
+	layouts := append(append([]string{}, opts.DateLayouts...), models.DateLayout)
+	var rows []ImportedRow
+	err := readCSV(r, opts.Mapping, nil, func(line int, get func(string) string, err error) {
+		if err != nil {
+			rows = append(rows, ImportedRow{Line: line, Err: err})
+			return
+		}
+		x, err := parseRow(get, layouts, opts.DefaultCurrency)
+		rows = append(rows, ImportedRow{Line: line, Trip: get(FieldTrip), Expense: x, Err: err})
+	})
+	return rows, err
+}
+
+// readCSV reads CSV with a header row and calls row with each record that
+// is not blank. get looks a field of CSVFields up in the record: in the
+// column mapping names, or else the column named like the field or one of
+// its aliases.
+func readCSV(r io.Reader, mapping map[string]string, aliases map[string][]string, row func(line int, get func(field string) string, err error)) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return errors.New("csv: the file is empty")
+	}
+	if err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	index := map[string]int{}
+	for _, f := range CSVFields {
+		if col, ok := mapping[f]; ok {
+			i, ok := columns[strings.ToLower(col)]
+			if !ok {
+				return fmt.Errorf("csv: no column named %q", col)
+			}
+			index[f] = i
+			continue
+		}
+		for _, name := range append([]string{f}, aliases[f]...) {
+			if i, ok := columns[name]; ok {
+				index[f] = i
+				break
+			}
+		}
+	}
+	for _, f := range []string{FieldDate, FieldAmount} {
+		if _, ok := index[f]; !ok {
+			return fmt.Errorf("csv: no %s column; map one with %s=<column>", f, f)
+		}
+	}
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			row(line, nil, err)
+			continue
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+		row(line, func(f string) string {
+			if i, ok := index[f]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}, nil)
+	}
+}
+
+func parseRow(get func(string) string, layouts []string, defaultCurrency string) (*models.Expense, error) {
+	ts, err := parseDate(get(FieldDate), layouts)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(get(FieldAmount))
+	if err != nil {
+		return nil, err
+	}
+	cur := get(FieldCurrency)
+	if cur == "" {
+		cur = defaultCurrency
+	}
+	if cur, err = models.ParseCurrency(cur); err != nil {
+		return nil, err
+	}
+	category := models.CategoryOther
+	if v := get(FieldCategory); v != "" {
+		if category, err = models.ParseCategory(v); err != nil {
+			return nil, err
+		}
+	}
+	description := get(FieldDescription)
+	if description == "" {
+		description = category
+	}
+	x := models.NewExpense("", amount, cur, category, description, ts)
+	x.Note = get(FieldNote)
+	if x.Tax, err = parsePart(FieldTax, get(FieldTax)); err != nil {
+		return nil, err
+	}
+	if x.Tip, err = parsePart(FieldTip, get(FieldTip)); err != nil {
+		return nil, err
+	}
+	if err := models.CheckBreakdown(x.Amount, x.Tax, x.Tip); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func parseDate(v string, layouts []string) (time.Time, error) {
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", v)
+}
+
+// parseAmount accepts amounts such as "1,234.50" or "-12". Refunds and
+// other non-positive amounts are rejected like they are when typed.
+func parseAmount(v string) (float64, error) {
+	n, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("amount %q is not a number greater than zero", v)
+	}
+	return n, nil
+}
+
+// parsePart reads the tax or tip of a row, which may be blank for none.
+func parsePart(field, v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s %q is not a number of zero or more", field, v)
+	}
+	return n, nil
+}
+
+// formatPart writes a tax or tip, blank for none.
+func formatPart(n float64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(n, 'f', 2, 64)
+}
+
+// ImportReport tallies the outcome of ImportExpenses.
+type ImportReport struct {
+	Imported   []ImportedRow
+	Duplicates []ImportedRow
+	Failed     []ImportedRow
+}
+
+// ImportExpenses assigns parsed rows to trips and saves them unless dryRun
+// is set. A row that is a likely duplicate of a stored expense or an
+// earlier row is merged into it instead, unless allowDuplicates is set to
+// keep both. tripFor resolves a row's trip column, which may be empty.
+func ImportExpenses(ctx context.Context, store *storage.Store, rows []ImportedRow, tripFor func(ref string) (*models.Trip, error), allowDuplicates, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{}
+	existing := map[string][]*models.Expense{}
+	legs := map[string][]*models.Leg{}
+	for _, row := range rows {
+		if row.Err != nil {
+			report.Failed = append(report.Failed, row)
+			continue
+		}
+		t, err := tripFor(row.Trip)
+		if err != nil {
+			row.Err = err
+			report.Failed = append(report.Failed, row)
+			continue
+		}
+		row.Expense.TripID = t.ID
+		if _, ok := legs[t.ID]; !ok {
+			if legs[t.ID], err = store.ListLegsByTrip(ctx, t.ID); err != nil {
+				return report, err
+			}
+		}
+		inTripZone(row.Expense, t, legs[t.ID])
+		known, ok := existing[t.ID]
+		if !ok {
+			if known, err = store.ListExpensesByTrip(ctx, t.ID); err != nil {
+				return report, err
+			}
+		}
+		if !allowDuplicates {
+			if row.DuplicateOf = findDuplicate(known, row.Expense); row.DuplicateOf != nil {
+				existing[t.ID] = known
+				report.Duplicates = append(report.Duplicates, row)
+				continue
+			}
+		}
+		existing[t.ID] = append(known, row.Expense)
+		report.Imported = append(report.Imported, row)
+	}
+	if dryRun {
+		return report, nil
+	}
+	imported := map[*models.Expense]bool{}
+	for _, row := range report.Imported {
+		imported[row.Expense] = true
+	}
+	for _, row := range report.Duplicates {
+		// Earlier rows are saved with what their duplicates add below.
+		if models.MergeExpense(row.DuplicateOf, row.Expense) && !imported[row.DuplicateOf] {
+			if err := store.SaveExpense(ctx, row.DuplicateOf); err != nil {
+				return report, err
+			}
+		}
+	}
+	for _, row := range report.Imported {
+		if err := store.SaveExpense(ctx, row.Expense); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// inTripZone moves the date of an imported expense, read in the local
+// zone, to the same day and time of day in the time zone of its trip then.
+func inTripZone(x *models.Expense, t *models.Trip, legs []*models.Leg) {
+	ts := x.Timestamp
+	x.TimeZone = places.DayZone(t, legs, "", ts)
+	x.Timestamp = time.Date(ts.Year(), ts.Month(), ts.Day(), ts.Hour(), ts.Minute(), ts.Second(), 0, models.Zone(x.TimeZone))
+}
+
+// findDuplicate returns the first of expenses that x is a likely duplicate
+// of, or nil.
+func findDuplicate(expenses []*models.Expense, x *models.Expense) *models.Expense {
+	for _, e := range expenses {
+		if models.IsLikelyDuplicate(e, x) {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+// Package export renders trips, with everything recorded against them, as
+// JSON or Markdown documents, PDF reports or a web page to share, and moves
+// expenses in and out of CSV.
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// Trip is a trip together with everything recorded against it.
+type Trip struct {
+	*models.Trip
+	Legs      []*models.Leg           `json:"legs"`
+	Entries   []*models.Entry         `json:"entries"`
+	Expenses  []*models.Expense       `json:"expenses"`
+	Itinerary []*models.ItineraryItem `json:"itinerary"`
+	Tracks    []*models.Track         `json:"tracks"`
+	CheckIns  []*models.CheckIn       `json:"checkins"`
+	Segments  []*models.Segment       `json:"segments"`
+	// Highlights are the trip's goals, with those done first.
+	Highlights []*models.Highlight `json:"highlights"`
+	// Lodgings are where the trip stays, in the order checked into.
+	Lodgings []*models.Lodging `json:"lodgings"`
+	// FieldLabels are the labels of the trip fields set up in the config,
+	// by their keys; a field without one is shown by its key.
+	FieldLabels map[string]string `json:"-"`
+}
+
+// fields lists the trip fields with a value, as label and value, by key.
+func (t *Trip) fields() [][2]string {
+	keys := slices.Sorted(maps.Keys(t.Fields))
+	out := make([][2]string, len(keys))
+	for i, key := range keys {
+		label := t.FieldLabels[key]
+		if label == "" {
+			label = key
+		}
+		out[i] = [2]string{label, t.Fields[key]}
+	}
+	return out
+}
+
+// Load reads everything recorded against t. Empty collections are
+// returned as empty slices so they encode as [] rather than null.
+func Load(ctx context.Context, store *storage.Store, t *models.Trip) (*Trip, error) {
+	legs, err := store.ListLegsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := store.ListEntriesByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := store.ListExpensesByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	itinerary, err := store.ListItineraryByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := store.ListTracksByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	checkIns, err := store.ListCheckInsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := store.ListSegmentsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	highlights, err := store.ListHighlightsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	lodgings, err := store.ListLodgingsByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	if legs == nil {
+		legs = []*models.Leg{}
+	}
+	if entries == nil {
+		entries = []*models.Entry{}
+	}
+	if expenses == nil {
+		expenses = []*models.Expense{}
+	}
+	if itinerary == nil {
+		itinerary = []*models.ItineraryItem{}
+	}
+	if tracks == nil {
+		tracks = []*models.Track{}
+	}
+	if checkIns == nil {
+		checkIns = []*models.CheckIn{}
+	}
+	if segments == nil {
+		segments = []*models.Segment{}
+	}
+	// Those done, the highlights proper, come before the goals left.
+	slices.SortStableFunc(highlights, func(a, b *models.Highlight) int {
+		switch {
+		case a.Done == b.Done:
+			return 0
+		case a.Done:
+			return -1
+		}
+		return 1
+	})
+	if highlights == nil {
+		highlights = []*models.Highlight{}
+	}
+	if lodgings == nil {
+		lodgings = []*models.Lodging{}
+	}
+	return &Trip{Trip: t, Legs: legs, Entries: entries, Expenses: expenses, Itinerary: itinerary, Tracks: tracks,
+		CheckIns: checkIns, Segments: segments, Highlights: highlights, Lodgings: lodgings}, nil
+}
+
+// highlightEntry names the journal entry the highlight h links to by its
+// title and date, or is empty when h links to none.
+func (t *Trip) highlightEntry(h *models.Highlight, date func(time.Time) string) string {
+	for _, e := range t.Entries {
+		if h.EntryID == "" || e.ID != h.EntryID {
+			continue
+		}
+		if e.Title == "" {
+			return date(e.Timestamp)
+		}
+		return e.Title + ", " + date(e.Timestamp)
+	}
+	return ""
+}
+
+// Dump writes a dump of the store as an indented JSON document.
+func Dump(w io.Writer, d *storage.Dump) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// ReadDump reads a dump of a store as Dump writes it.
+func ReadDump(r io.Reader) (*storage.Dump, error) {
+	var d storage.Dump
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return nil, fmt.Errorf("export: read dump: %w", err)
+	}
+	if d.Format == 0 {
+		return nil, errors.New("export: read dump: not a dump of nomadic export --all")
+	}
+	return &d, nil
+}
+
+// JSON writes trips as an indented JSON array.
+func JSON(w io.Writer, trips []*Trip) error {
+	if trips == nil {
+		trips = []*Trip{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(trips)
+}
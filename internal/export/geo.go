@@ -0,0 +1,249 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// geoFeature is one thing drawn on a map of a trip: a stop, a check-in,
+// the route between the stops or a GPS track.
+type geoFeature struct {
+	// kind is stop, checkin, route or track.
+	kind string
+	name string
+	// props are what is known of the feature, in the order shown.
+	props []geoProp
+	// point is the [lon, lat] of a feature at one place; lines are the
+	// lines of one drawn as lines instead.
+	point *[2]float64
+	lines [][][2]float64
+}
+
+// geoProp is a property of a geoFeature and its value, a string or a
+// number.
+type geoProp struct {
+	key   string
+	value any
+}
+
+// geoFeatures lays the trip out for a map: its legs as numbered stops (or
+// its destinations, when it has no legs), the route joining them in
+// order, its check-ins and its GPS tracks. Places not in the places
+// dataset, and check-ins without a position, are left out.
+func geoFeatures(t *Trip) []geoFeature {
+	var (
+		features []geoFeature
+		route    [][2]float64
+	)
+	stop := func(name string, props []geoProp) {
+		p, ok := places.Lookup(name)
+		if !ok {
+			return
+		}
+		at := [2]float64{p.Lon, p.Lat}
+		route = append(route, at)
+		props = append([]geoProp{{"stop", len(route)}, {"place", p.String()}}, props...)
+		features = append(features, geoFeature{kind: "stop", name: name, props: props, point: &at})
+	}
+	for _, l := range t.Legs {
+		props := []geoProp{{"arrival", l.Arrival.Format(models.DateLayout)}}
+		if l.Departure != nil {
+			props = append(props, geoProp{"departure", l.Departure.Format(models.DateLayout)})
+		}
+		if l.Transport != "" {
+			props = append(props, geoProp{"transport", l.Transport})
+		}
+		stop(l.Location, props)
+	}
+	if len(t.Legs) == 0 {
+		for _, loc := range t.Locations {
+			stop(loc, nil)
+		}
+	}
+	if len(route) > 1 {
+		props := []geoProp{{"start", t.StartDate.Format(models.DateLayout)}}
+		if t.EndDate != nil {
+			props = append(props, geoProp{"end", t.EndDate.Format(models.DateLayout)})
+		}
+		features = append(features, geoFeature{kind: "route", name: t.Title, props: props, lines: [][][2]float64{route}})
+	}
+
+	for _, c := range t.CheckIns {
+		at := [2]float64{c.Lon, c.Lat}
+		if c.Lat == 0 && c.Lon == 0 {
+			p, ok := places.Lookup(c.Place)
+			if !ok {
+				continue
+			}
+			at = [2]float64{p.Lon, p.Lat}
+		}
+		props := []geoProp{{"timestamp", c.Timestamp.Format(time.RFC3339)}}
+		if c.TimeZone != "" {
+			props = append(props, geoProp{"time_zone", c.TimeZone})
+		}
+		if c.Note != "" {
+			props = append(props, geoProp{"note", c.Note})
+		}
+		features = append(features, geoFeature{kind: "checkin", name: c.Place, props: props, point: &at})
+	}
+
+	for _, tr := range t.Tracks {
+		if len(tr.Path) == 0 {
+			continue
+		}
+		props := []geoProp{
+			{"distance_m", math.Round(tr.Distance)},
+			{"ascent_m", math.Round(tr.Ascent)},
+			{"descent_m", math.Round(tr.Descent)},
+		}
+		if tr.StartedAt != nil && tr.EndedAt != nil {
+			props = append(props, geoProp{"started_at", tr.StartedAt.Format(time.RFC3339)},
+				geoProp{"ended_at", tr.EndedAt.Format(time.RFC3339)})
+		}
+		if tr.Place != "" {
+			props = append(props, geoProp{"place", tr.Place})
+		}
+		features = append(features, geoFeature{kind: "track", name: tr.Name, props: props, lines: tr.Path})
+	}
+	return features
+}
+
+// GeoJSON writes the trip's route as a GeoJSON (RFC 7946) feature
+// collection for mapping tools: a point for each stop and check-in, a
+// line joining the stops in order and one for each GPS track. Every
+// feature carries its name and what is known of it as properties, and
+// "kind" tells stop, checkin, route and track apart.
+func GeoJSON(w io.Writer, t *Trip) error {
+	type geometry struct {
+		Type        string `json:"type"`
+		Coordinates any    `json:"coordinates"`
+	}
+	type feature struct {
+		Type       string         `json:"type"`
+		Geometry   geometry       `json:"geometry"`
+		Properties map[string]any `json:"properties"`
+	}
+	collection := struct {
+		Type     string    `json:"type"`
+		Name     string    `json:"name"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection", Name: t.Title, Features: []feature{}}
+	for _, f := range geoFeatures(t) {
+		var g geometry
+		switch {
+		case f.point != nil:
+			g = geometry{"Point", f.point}
+		case len(f.lines) == 1:
+			g = geometry{"LineString", f.lines[0]}
+		default:
+			g = geometry{"MultiLineString", f.lines}
+		}
+		props := map[string]any{"name": f.name, "kind": f.kind, "trip": t.Title}
+		for _, p := range f.props {
+			props[p.key] = p.value
+		}
+		collection.Features = append(collection.Features, feature{"Feature", g, props})
+	}
+	return json.NewEncoder(w).Encode(collection)
+}
+
+// WriteGeoJSONFiles writes one GeoJSON file per trip into dir, creating
+// it if needed, and returns the paths written.
+func WriteGeoJSONFiles(dir string, trips []*Trip) ([]string, error) {
+	return writeFiles(dir, trips, ".geojson", GeoJSON)
+}
+
+// KML writes the trip's route as a KML document, for Google Earth and
+// other mapping tools, with the same placemarks as GeoJSON: in a folder
+// the stops and the route joining them, in others the check-ins and the
+// GPS tracks. What is known of each is its extended data.
+func KML(w io.Writer, t *Trip) error {
+	type data struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:"value"`
+	}
+	type lineString struct {
+		Coordinates string `xml:"coordinates"`
+	}
+	type multiGeometry struct {
+		Lines []lineString `xml:"LineString"`
+	}
+	type placemark struct {
+		Name  string         `xml:"name"`
+		Data  []data         `xml:"ExtendedData>Data,omitempty"`
+		Point *lineString    `xml:"Point,omitempty"`
+		Line  *lineString    `xml:"LineString,omitempty"`
+		Multi *multiGeometry `xml:"MultiGeometry,omitempty"`
+	}
+	type folder struct {
+		Name       string      `xml:"name"`
+		Placemarks []placemark `xml:"Placemark"`
+	}
+	doc := struct {
+		XMLName xml.Name `xml:"kml"`
+		NS      string   `xml:"xmlns,attr"`
+		Name    string   `xml:"Document>name"`
+		Folders []folder `xml:"Document>Folder"`
+	}{NS: "http://www.opengis.net/kml/2.2", Name: t.Title}
+	folders := map[string]int{}
+	for _, f := range geoFeatures(t) {
+		pm := placemark{Name: f.name, Data: []data{{"kind", f.kind}}}
+		for _, p := range f.props {
+			pm.Data = append(pm.Data, data{p.key, fmt.Sprint(p.value)})
+		}
+		switch {
+		case f.point != nil:
+			pm.Point = &lineString{kmlCoordinates([][2]float64{*f.point})}
+		case len(f.lines) == 1:
+			pm.Line = &lineString{kmlCoordinates(f.lines[0])}
+		default:
+			pm.Multi = &multiGeometry{}
+			for _, l := range f.lines {
+				pm.Multi.Lines = append(pm.Multi.Lines, lineString{kmlCoordinates(l)})
+			}
+		}
+		name := map[string]string{"stop": "Route", "route": "Route", "checkin": "Check-ins", "track": "Tracks"}[f.kind]
+		i, ok := folders[name]
+		if !ok {
+			i = len(doc.Folders)
+			folders[name] = i
+			doc.Folders = append(doc.Folders, folder{Name: name})
+		}
+		doc.Folders[i].Placemarks = append(doc.Folders[i].Placemarks, pm)
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	enc := xml.NewEncoder(bw)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	bw.WriteString("\n")
+	return bw.Flush()
+}
+
+// WriteKMLFiles writes one KML file per trip into dir, creating it if
+// needed, and returns the paths written.
+func WriteKMLFiles(dir string, trips []*Trip) ([]string, error) {
+	return writeFiles(dir, trips, ".kml", KML)
+}
+
+// kmlCoordinates renders positions as the lon,lat tuples of KML.
+func kmlCoordinates(line [][2]float64) string {
+	var b []byte
+	for i, p := range line {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b = fmt.Appendf(b, "%g,%g", p[0], p[1])
+	}
+	return string(b)
+}
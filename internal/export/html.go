@@ -0,0 +1,172 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/settle"
+)
+
+// HTML writes t as a single self-contained web page summing it up for the
+// people traveling along: the overview, route, highlights and itinerary, what was spent
+// by category and, when split is not nil, who owes whom. Journal entries
+// are left out. Dates are formatted with the Go layout dateLayout.
+func HTML(w io.Writer, t *Trip, split *settle.Settlement, dateLayout string) error {
+	date := func(ts time.Time) string { return ts.Format(dateLayout) }
+	p := htmlPage{Title: t.Title, Destinations: strings.Join(t.Locations, ", "), Notes: t.Notes, Split: split}
+	p.Dates = date(t.StartDate)
+	if t.EndDate != nil {
+		p.Dates += " → " + date(*t.EndDate)
+		p.Days = models.CalendarDays(t.StartDate, *t.EndDate)
+	}
+	for _, l := range t.Legs {
+		leg := htmlLeg{Location: l.Location, Dates: date(l.Arrival), Transport: l.Transport}
+		if l.Departure != nil {
+			leg.Dates += " → " + date(*l.Departure)
+		}
+		p.Route = append(p.Route, leg)
+	}
+	for _, h := range t.Highlights {
+		p.Highlights = append(p.Highlights, htmlHighlight{Title: h.Title, Done: h.Done, Entry: t.highlightEntry(h, date)})
+	}
+	for _, it := range t.Itinerary {
+		d := dateOf(it.Day)
+		if n := len(p.Itinerary); n == 0 || !p.Itinerary[n-1].day.Equal(d) {
+			p.Itinerary = append(p.Itinerary, htmlDay{
+				day:   d,
+				Title: fmt.Sprintf("Day %d — %s %s", models.CalendarDays(t.StartDate, d), d.Format("Mon"), date(d)),
+			})
+		}
+		day := &p.Itinerary[len(p.Itinerary)-1]
+		day.Items = append(day.Items, it)
+	}
+	for _, s := range summarize(t.Expenses) {
+		p.Spending = append(p.Spending, htmlSum{Category: s.category, Amount: fmt.Sprintf("%.2f %s", s.total, s.currency)})
+	}
+	p.Expenses = len(t.Expenses)
+	return htmlTemplate.Execute(w, p)
+}
+
+type htmlPage struct {
+	Title, Dates, Destinations, Notes string
+	Days                              int
+	Route                             []htmlLeg
+	Highlights                        []htmlHighlight
+	Itinerary                         []htmlDay
+	Spending                          []htmlSum
+	Expenses                          int
+	Split                             *settle.Settlement
+}
+
+type htmlLeg struct{ Location, Dates, Transport string }
+
+type htmlHighlight struct {
+	Title, Entry string
+	Done         bool
+}
+
+type htmlDay struct {
+	day   time.Time
+	Title string
+	Items []*models.ItineraryItem
+}
+
+type htmlSum struct{ Category, Amount string }
+
+var htmlTemplate = template.Must(template.New("trip").Funcs(template.FuncMap{
+	"person": func(name string) string {
+		if name == models.Me {
+			return "Me"
+		}
+		return name
+	},
+	"money":  func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"signed": func(v float64) string { return fmt.Sprintf("%+.2f", v) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+:root { color-scheme: light dark; --muted: #777; --line: #ddd; }
+@media (prefers-color-scheme: dark) { :root { --line: #444; } }
+body { font: 16px/1.5 system-ui, sans-serif; max-width: 46rem; margin: 2rem auto; padding: 0 1rem; }
+h1 { margin-bottom: 0; }
+.meta, .muted { color: var(--muted); }
+table { border-collapse: collapse; width: 100%; margin: .5rem 0 1rem; }
+th, td { text-align: left; padding: .3rem .5rem; border-bottom: 1px solid var(--line); }
+td.n, th.n { text-align: right; font-variant-numeric: tabular-nums; }
+li { margin: .2rem 0; }
+ul.highlights { list-style: none; padding-left: 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">{{.Dates}}{{if .Days}} · {{.Days}} days{{end}}{{if .Destinations}} · {{.Destinations}}{{end}}</p>
+{{with .Notes}}<p>{{.}}</p>{{end}}
+{{if .Route}}
+<h2>Route</h2>
+<ol>{{range .Route}}
+<li><strong>{{.Location}}</strong> — {{.Dates}}{{with .Transport}} <span class="muted">by {{.}}</span>{{end}}</li>{{end}}
+</ol>
+{{end}}
+{{if .Highlights}}
+<h2>Highlights</h2>
+<ul class="highlights">{{range .Highlights}}
+<li>{{if .Done}}☑{{else}}☐{{end}} {{.Title}}{{with .Entry}} <span class="muted">— {{.}}</span>{{end}}</li>{{end}}
+</ul>
+{{end}}
+{{if .Itinerary}}
+<h2>Itinerary</h2>
+{{range .Itinerary}}
+<h3>{{.Title}}</h3>
+<ul>{{range .Items}}
+<li>{{with .Time}}<strong>{{.}}</strong> {{end}}{{.Title}}{{with .Place}} — {{.}}{{end}}{{with .BookingRef}} <span class="muted">(booking {{.}})</span>{{end}}{{with .Notes}}<br><span class="muted">{{.}}</span>{{end}}</li>{{end}}
+</ul>
+{{end}}
+{{end}}
+{{if .Spending}}
+<h2>Spending</h2>
+<table>
+<tr><th>Category</th><th class="n">Total</th></tr>{{range .Spending}}
+<tr><td>{{.Category}}</td><td class="n">{{.Amount}}</td></tr>{{end}}
+</table>
+<p class="muted">{{.Expenses}} expenses</p>
+{{end}}
+{{with .Split}}{{if .Shared}}
+<h2>Split</h2>
+<table>
+<tr><th>Person</th><th class="n">Paid</th><th class="n">Share</th><th class="n">Balance</th></tr>{{range .Balances}}
+<tr><td>{{person .Name}}</td><td class="n">{{money .Paid}}</td><td class="n">{{money .Share}}</td><td class="n">{{signed .Net}} {{$.Split.Currency}}</td></tr>{{end}}
+</table>
+{{if .Transfers}}<ul>{{range .Transfers}}
+<li>{{person .From}} pays {{person .To}} <strong>{{money .Amount}} {{$.Split.Currency}}</strong></li>{{end}}
+</ul>{{else}}<p>Everyone is settled up.</p>{{end}}
+{{if .Unconverted}}<p class="muted">{{.Unconverted}} shared expenses in other currencies could not be converted and are not counted.</p>{{end}}
+{{end}}{{end}}
+<p class="muted">Shared from nomadic.</p>
+</body>
+</html>
+`))
+
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// RenderMarkdown renders the Markdown of an entry or notes as HTML, for a
+// page or a mail. Raw HTML in it is left out, so nothing written in the
+// journal runs as script where it is read.
+func RenderMarkdown(text string) template.HTML {
+	var b bytes.Buffer
+	if err := md.Convert([]byte(text), &b); err != nil {
+		return template.HTML(template.HTMLEscapeString(text))
+	}
+	return template.HTML(b.String())
+}
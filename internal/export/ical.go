@@ -0,0 +1,148 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// icalStamp is the layout of UTC date-times in iCalendar.
+const icalStamp = "20060102T150405Z"
+
+// ICal writes the trip's itinerary as an iCalendar (RFC 5545) calendar with
+// one event per item, for importing into a calendar app. Items with a time
+// become hour-long events in the time zone of their place, else of the
+// trip's leg on their day, else of its first known location; items without
+// one become all-day events. Items with an alarm get a reminder that many
+// minutes before they start. Each lodging adds its check-in and its
+// check-out, reminded an hour before.
+func ICal(w io.Writer, t *Trip) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICalLine(bw, name+":"+value)
+	}
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//nomadic//itinerary//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", icalText(t.Title))
+	for _, it := range t.Itinerary {
+		line("BEGIN", "VEVENT")
+		line("UID", it.ID+"@nomadic")
+		line("DTSTAMP", it.UpdatedAt.UTC().Format(icalStamp))
+		if start, ok := itemStart(t, it); ok {
+			line("DTSTART", start.UTC().Format(icalStamp))
+			line("DTEND", start.Add(time.Hour).UTC().Format(icalStamp))
+		} else {
+			day := dateOf(it.Day)
+			line("DTSTART;VALUE=DATE", day.Format("20060102"))
+			line("DTEND;VALUE=DATE", day.AddDate(0, 0, 1).Format("20060102"))
+		}
+		line("SUMMARY", icalText(it.Title))
+		if it.Place != "" {
+			line("LOCATION", icalText(it.Place))
+			if p, ok := places.Lookup(it.Place); ok {
+				line("GEO", fmt.Sprintf("%.6f;%.6f", p.Lat, p.Lon))
+			}
+		}
+		var description []string
+		if it.BookingRef != "" {
+			description = append(description, "Booking: "+it.BookingRef)
+		}
+		if it.Notes != "" {
+			description = append(description, it.Notes)
+		}
+		if len(description) > 0 {
+			line("DESCRIPTION", icalText(strings.Join(description, "\n\n")))
+		}
+		if it.Alarm > 0 {
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", icalText(it.Title))
+			line("TRIGGER", fmt.Sprintf("-PT%dM", it.Alarm))
+			line("END", "VALARM")
+		}
+		line("END", "VEVENT")
+	}
+	for _, l := range t.Lodgings {
+		for _, n := range []models.LodgingNotice{{Lodging: l}, {Lodging: l, CheckOut: true}} {
+			uid := l.ID + "-check-in@nomadic"
+			if n.CheckOut {
+				uid = l.ID + "-check-out@nomadic"
+			}
+			line("BEGIN", "VEVENT")
+			line("UID", uid)
+			line("DTSTAMP", l.UpdatedAt.UTC().Format(icalStamp))
+			line("DTSTART", n.At().UTC().Format(icalStamp))
+			line("DTEND", n.At().Add(30*time.Minute).UTC().Format(icalStamp))
+			line("SUMMARY", icalText(n.String()))
+			if l.Address != "" {
+				line("LOCATION", icalText(l.Address))
+			}
+			if l.Confirmation != "" {
+				line("DESCRIPTION", icalText("Confirmation: "+l.Confirmation))
+			}
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", icalText(n.String()))
+			line("TRIGGER", "-PT1H")
+			line("END", "VALARM")
+			line("END", "VEVENT")
+		}
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// WriteICalFiles writes one iCalendar file per trip into dir, creating it
+// if needed, and returns the paths written.
+func WriteICalFiles(dir string, trips []*Trip) ([]string, error) {
+	return writeFiles(dir, trips, ".ics", ICal)
+}
+
+// itemStart returns when a timed item starts, read in the time zone of the
+// place it is at. It reports false for items without a time.
+func itemStart(t *Trip, it *models.ItineraryItem) (time.Time, bool) {
+	at, err := time.Parse(models.TimeLayout, it.Time)
+	if err != nil {
+		return time.Time{}, false
+	}
+	names := []string{it.Place}
+	if l := models.LegOn(t.Legs, it.Day); l != nil {
+		names = append(names, l.Location)
+	}
+	names = append(names, t.Locations...)
+	loc := time.Local
+	if p, ok := places.First(names...); ok {
+		loc = p.Location()
+	}
+	y, m, d := it.Day.Local().Date()
+	return time.Date(y, m, d, at.Hour(), at.Minute(), 0, 0, loc), true
+}
+
+// icalText escapes a value of an iCalendar text property.
+func icalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeICalLine writes a content line, folded so no line is longer than 75
+// octets as iCalendar requires, without splitting a character.
+func writeICalLine(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space of a continuation counts
+	}
+	w.WriteString(s + "\r\n")
+}
@@ -0,0 +1,281 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Markdown writes t as a Markdown document with an overview, the route
+// of its legs, its highlights, the itinerary, journal entries and an
+// expense summary. Dates are formatted with the Go layout dateLayout.
+func Markdown(w io.Writer, t *Trip, dateLayout string) error {
+	bw := bufio.NewWriter(w)
+	date := func(ts time.Time) string { return ts.Format(dateLayout) }
+
+	fmt.Fprintf(bw, "# %s\n\n", t.Title)
+	dates := date(t.StartDate)
+	if t.EndDate != nil {
+		dates += " → " + date(*t.EndDate)
+	}
+
+	fmt.Fprintf(bw, "## Overview\n\n")
+	if len(t.Locations) > 0 {
+		fmt.Fprintf(bw, "- **Destinations:** %s\n", strings.Join(t.Locations, ", "))
+	}
+	fmt.Fprintf(bw, "- **Dates:** %s\n", dates)
+	if t.EndDate != nil {
+		fmt.Fprintf(bw, "- **Duration:** %d days\n", models.CalendarDays(t.StartDate, *t.EndDate))
+	}
+	if t.Budget > 0 {
+		fmt.Fprintf(bw, "- **Budget:** %.2f\n", t.Budget)
+	}
+	if t.Rating > 0 {
+		fmt.Fprintf(bw, "- **Rating:** %s\n", models.Stars(t.Rating))
+	}
+	if len(t.Tags) > 0 {
+		fmt.Fprintf(bw, "- **Tags:** #%s\n", strings.Join(t.Tags, " #"))
+	}
+	for _, f := range t.fields() {
+		fmt.Fprintf(bw, "- **%s:** %s\n", f[0], f[1])
+	}
+	fmt.Fprintf(bw, "- **Journal entries:** %d\n", len(t.Entries))
+	fmt.Fprintf(bw, "- **Expenses:** %d\n", len(t.Expenses))
+	if t.Notes != "" {
+		fmt.Fprintf(bw, "\n%s\n", t.Notes)
+	}
+
+	if len(t.Legs) > 0 {
+		fmt.Fprintf(bw, "\n## Route\n\n")
+		for i, l := range t.Legs {
+			line := fmt.Sprintf("%d. **%s** — %s", i+1, l.Location, date(l.Arrival))
+			if l.Departure != nil {
+				line += " → " + date(*l.Departure)
+			}
+			if l.Transport != "" {
+				line += " (by " + l.Transport + ")"
+			}
+			fmt.Fprintf(bw, "%s\n", line)
+		}
+	}
+
+	if len(t.Lodgings) > 0 {
+		fmt.Fprintf(bw, "\n## Lodging\n\n")
+		for _, l := range t.Lodgings {
+			line := fmt.Sprintf("- **%s** — %s %s → %s %s, %d nights", l.Name, date(l.CheckIn), l.CheckIn.Format(models.TimeLayout),
+				date(l.CheckOut), l.CheckOut.Format(models.TimeLayout), l.Nights())
+			if l.Address != "" {
+				line += " at " + l.Address
+			}
+			fmt.Fprintf(bw, "%s\n", line)
+		}
+	}
+
+	if len(t.Highlights) > 0 {
+		fmt.Fprintf(bw, "\n## Highlights\n\n")
+		for _, h := range t.Highlights {
+			box := "[ ]"
+			if h.Done {
+				box = "[x]"
+			}
+			line := "- " + box + " " + h.Title
+			if ref := t.highlightEntry(h, date); ref != "" {
+				line += " — _" + ref + "_"
+			}
+			fmt.Fprintf(bw, "%s\n", line)
+		}
+	}
+
+	if len(t.Itinerary) > 0 {
+		fmt.Fprintf(bw, "\n## Itinerary\n")
+		var day time.Time
+		for _, it := range t.Itinerary {
+			if d := dateOf(it.Day); !d.Equal(day) {
+				day = d
+				fmt.Fprintf(bw, "\n### Day %d — %s %s\n\n", models.CalendarDays(t.StartDate, day), day.Format("Mon"), date(day))
+			}
+			line := it.Title
+			if it.Time != "" {
+				line = "**" + it.Time + "** " + line
+			}
+			if it.Place != "" {
+				line += " — " + it.Place
+			}
+			if it.BookingRef != "" {
+				line += " (booking: `" + it.BookingRef + "`)"
+			}
+			fmt.Fprintf(bw, "- %s\n", line)
+			if it.Notes != "" {
+				fmt.Fprintf(bw, "  %s\n", it.Notes)
+			}
+		}
+	}
+
+	if len(t.Entries) > 0 {
+		fmt.Fprintf(bw, "\n## Journal\n")
+		for _, e := range t.Entries {
+			title := e.Title
+			if title == "" {
+				title = date(e.Timestamp)
+			}
+			fmt.Fprintf(bw, "\n### %s\n\n", title)
+			meta := []string{date(e.Timestamp)}
+			if e.Location != "" {
+				meta = append(meta, e.Location)
+			}
+			if e.Weather != nil {
+				meta = append(meta, e.Weather.Summary())
+			}
+			if e.Mood > 0 {
+				meta = append(meta, fmt.Sprintf("mood %s %d/%d", models.MoodFace(e.Mood), e.Mood, models.MaxRating))
+			}
+			if len(e.People) > 0 {
+				meta = append(meta, "with "+strings.Join(e.People, ", "))
+			}
+			if len(e.Tags) > 0 {
+				meta = append(meta, "#"+strings.Join(e.Tags, " #"))
+			}
+			fmt.Fprintf(bw, "_%s_\n", strings.Join(meta, " · "))
+			if text := strings.TrimSpace(e.Text); text != "" {
+				fmt.Fprintf(bw, "\n%s\n", demoteHeadings(text, 3))
+			}
+		}
+	}
+
+	if len(t.Expenses) > 0 {
+		fmt.Fprintf(bw, "\n## Expenses\n\n")
+		fmt.Fprintf(bw, "| Category | Currency | Total |\n|---|---|---:|\n")
+		for _, s := range summarize(t.Expenses) {
+			fmt.Fprintf(bw, "| %s | %s | %.2f |\n", s.category, s.currency, s.total)
+		}
+		fmt.Fprintf(bw, "\n| Date | Category | Description | Amount |\n|---|---|---|---:|\n")
+		for _, x := range t.Expenses {
+			fmt.Fprintf(bw, "| %s | %s | %s | %.2f %s |\n", date(x.Timestamp), x.Category, cell(x.Description), x.Amount, x.Currency)
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteMarkdownFiles writes one Markdown document per trip into dir,
+// creating it if needed, and returns the paths written.
+func WriteMarkdownFiles(dir string, trips []*Trip, dateLayout string) ([]string, error) {
+	return writeFiles(dir, trips, ".md", func(w io.Writer, t *Trip) error {
+		return Markdown(w, t, dateLayout)
+	})
+}
+
+// writeFiles renders each trip into its own file in dir, named after the
+// trip's title with ext, and returns the paths written.
+func writeFiles(dir string, trips []*Trip, ext string, render func(io.Writer, *Trip) error) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	var paths []string
+	used := map[string]bool{}
+	for _, t := range trips {
+		name := Slug(t.Title)
+		if used[name] {
+			name += "-" + t.ID
+		}
+		used[name] = true
+		path := filepath.Join(dir, name+ext)
+		f, err := os.Create(path)
+		if err != nil {
+			return paths, err
+		}
+		err = render(f, t)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Slug turns a title into a file name: lower case letters and digits
+// separated by single dashes.
+func Slug(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	s := strings.TrimSuffix(b.String(), "-")
+	if s == "" {
+		return "trip"
+	}
+	return s
+}
+
+type expenseSum struct {
+	category, currency string
+	total              float64
+}
+
+// summarize totals expenses per category and currency, in category order.
+func summarize(expenses []*models.Expense) []expenseSum {
+	sums := map[[2]string]float64{}
+	for _, x := range expenses {
+		sums[[2]string{x.Category, x.Currency}] += x.Amount
+	}
+	out := make([]expenseSum, 0, len(sums))
+	for k, v := range sums {
+		out = append(out, expenseSum{category: k[0], currency: k[1], total: v})
+	}
+	rank := map[string]int{}
+	for i, c := range models.Categories {
+		rank[c] = i
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].category != out[j].category {
+			return rank[out[i].category] < rank[out[j].category]
+		}
+		return out[i].currency < out[j].currency
+	})
+	return out
+}
+
+// demoteHeadings pushes the ATX headings of an entry down by levels so
+// they nest under the entry's own heading. Fenced code is left alone.
+func demoteHeadings(text string, levels int) string {
+	lines := strings.Split(text, "\n")
+	fenced := false
+	for i, l := range lines {
+		trimmed := strings.TrimLeft(l, " ")
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if !fenced && strings.HasPrefix(l, "#") {
+			lines[i] = strings.Repeat("#", levels) + l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cell escapes a value for use inside a Markdown table.
+func cell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
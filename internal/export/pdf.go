@@ -0,0 +1,532 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// Page geometry of the report, in millimetres.
+const (
+	pdfMargin = 20.0
+	pdfWidth  = 210.0 - 2*pdfMargin // A4
+	pdfLine   = 5.5
+)
+
+var (
+	pdfInk   = [3]int{33, 37, 41}
+	pdfMuted = [3]int{108, 117, 125}
+	pdfShade = [3]int{241, 243, 245}
+)
+
+// PDF writes t as a printable trip report: a cover page with the overview,
+// then the highlights, itinerary, journal entries and expense tables with
+// totals.
+// Dates are formatted with the Go layout dateLayout.
+//
+// Text is set in the Go fonts, which cover Latin, Greek and Cyrillic
+// scripts; emoji are left out and other characters the fonts lack are
+// printed as "?".
+func PDF(w io.Writer, t *Trip, dateLayout string) error {
+	r := newReport(t, dateLayout)
+	r.cover()
+	if len(t.Highlights) > 0 {
+		r.highlights()
+	}
+	if len(t.Itinerary) > 0 {
+		r.itinerary()
+	}
+	if len(t.Entries) > 0 {
+		r.journal()
+	}
+	if len(t.Expenses) > 0 {
+		r.expenses()
+	}
+	return r.pdf.Output(w)
+}
+
+// WritePDFFiles writes one PDF report per trip into dir, creating it if
+// needed, and returns the paths written.
+func WritePDFFiles(dir string, trips []*Trip, dateLayout string) ([]string, error) {
+	return writeFiles(dir, trips, ".pdf", func(w io.Writer, t *Trip) error {
+		return PDF(w, t, dateLayout)
+	})
+}
+
+type report struct {
+	pdf  *fpdf.Fpdf
+	trip *Trip
+	date func(time.Time) string
+}
+
+func newReport(t *Trip, dateLayout string) *report {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.AddUTF8FontFromBytes("Go", "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes("Go", "B", gobold.TTF)
+	pdf.AddUTF8FontFromBytes("Go", "I", goitalic.TTF)
+	pdf.AddUTF8FontFromBytes("GoMono", "", gomono.TTF)
+	pdf.SetTitle(t.Title, true)
+	pdf.SetCreator("nomadic", true)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetY(-pdfMargin + 6)
+		pdf.SetFont("Go", "", 8)
+		pdf.SetTextColor(pdfMuted[0], pdfMuted[1], pdfMuted[2])
+		pdf.CellFormat(pdfWidth/2, 4, pdfText(t.Title), "", 0, "L", false, 0, "")
+		pdf.CellFormat(pdfWidth/2, 4, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	return &report{pdf: pdf, trip: t, date: func(ts time.Time) string { return ts.Format(dateLayout) }}
+}
+
+func (r *report) font(style string, size float64, color [3]int) {
+	r.pdf.SetFont("Go", style, size)
+	r.pdf.SetTextColor(color[0], color[1], color[2])
+}
+
+// section starts a new page headed by title.
+func (r *report) section(title string) {
+	r.pdf.AddPage()
+	r.font("B", 20, pdfInk)
+	r.pdf.CellFormat(pdfWidth, 10, title, "", 1, "L", false, 0, "")
+	r.pdf.SetDrawColor(pdfMuted[0], pdfMuted[1], pdfMuted[2])
+	y := r.pdf.GetY() + 1
+	r.pdf.Line(pdfMargin, y, pdfMargin+pdfWidth, y)
+	r.pdf.Ln(6)
+}
+
+// heading writes a subheading, moving to a new page first when too little
+// of the current one is left for anything to follow it.
+func (r *report) heading(text string, size float64) {
+	_, pageHeight := r.pdf.GetPageSize()
+	if r.pdf.GetY() > pageHeight-pdfMargin-25 {
+		r.pdf.AddPage()
+	}
+	r.font("B", size, pdfInk)
+	r.pdf.MultiCell(pdfWidth, size*0.5, pdfText(text), "", "L", false)
+	r.pdf.Ln(1)
+}
+
+func (r *report) cover() {
+	t := r.trip
+	r.pdf.AddPage()
+	r.pdf.SetY(70)
+	r.font("B", 30, pdfInk)
+	r.pdf.MultiCell(pdfWidth, 13, pdfText(t.Title), "", "C", false)
+	r.pdf.Ln(3)
+	if len(t.Locations) > 0 {
+		r.font("", 14, pdfMuted)
+		r.pdf.MultiCell(pdfWidth, 7, pdfText(strings.Join(t.Locations, " · ")), "", "C", false)
+	}
+	dates := r.date(t.StartDate)
+	if t.EndDate != nil {
+		dates += " – " + r.date(*t.EndDate)
+	}
+	r.font("", 12, pdfMuted)
+	r.pdf.CellFormat(pdfWidth, 7, dates, "", 1, "C", false, 0, "")
+	r.pdf.Ln(14)
+
+	var facts [][2]string
+	if t.EndDate != nil {
+		facts = append(facts, [2]string{"Duration", fmt.Sprintf("%d days", models.CalendarDays(t.StartDate, *t.EndDate))})
+	}
+	if codes := places.Countries(places.Resolve(t.Locations)); len(codes) > 0 {
+		names := make([]string, len(codes))
+		for i, c := range codes {
+			names[i] = places.CountryName(c)
+		}
+		facts = append(facts, [2]string{"Countries", strings.Join(names, ", ")})
+	}
+	if len(t.Legs) > 0 {
+		stops := make([]string, len(t.Legs))
+		for i, l := range t.Legs {
+			stops[i] = l.Location
+		}
+		facts = append(facts, [2]string{"Route", strings.Join(stops, " → ")})
+	}
+	if t.Budget > 0 {
+		facts = append(facts, [2]string{"Budget", strings.TrimSpace(fmt.Sprintf("%.2f %s", t.Budget, t.BudgetCurrency))})
+	}
+	if len(t.Tags) > 0 {
+		facts = append(facts, [2]string{"Tags", "#" + strings.Join(t.Tags, " #")})
+	}
+	facts = append(facts, t.fields()...)
+	facts = append(facts,
+		[2]string{"Itinerary", plural(len(t.Itinerary), "item")},
+		[2]string{"Journal", plural(len(t.Entries), "entry")},
+		[2]string{"Expenses", plural(len(t.Expenses), "expense")},
+	)
+	const labelWidth = 40.0
+	left := pdfMargin + 25
+	for _, f := range facts {
+		r.pdf.SetX(left)
+		r.font("B", 11, pdfMuted)
+		r.pdf.CellFormat(labelWidth, 7, f[0], "", 0, "L", false, 0, "")
+		r.font("", 11, pdfInk)
+		r.pdf.MultiCell(pdfWidth-50-labelWidth, 7, pdfText(f[1]), "", "L", false)
+	}
+
+	if notes := strings.TrimSpace(t.Notes); notes != "" {
+		r.pdf.Ln(10)
+		r.pdf.SetX(left)
+		r.font("I", 11, pdfInk)
+		r.pdf.MultiCell(pdfWidth-50, pdfLine, pdfText(notes), "", "L", false)
+	}
+
+	_, pageHeight := r.pdf.GetPageSize()
+	r.pdf.SetY(pageHeight - pdfMargin - 6)
+	r.font("", 8, pdfMuted)
+	r.pdf.CellFormat(pdfWidth, 4, "Exported from nomadic on "+r.date(time.Now()), "", 0, "C", false, 0, "")
+}
+
+func (r *report) highlights() {
+	r.section("Highlights")
+	const boxWidth = 8.0
+	for _, h := range r.trip.Highlights {
+		box := "[ ]"
+		if h.Done {
+			box = "[x]"
+		}
+		r.font("B", 10, pdfInk)
+		r.pdf.CellFormat(boxWidth, pdfLine, box, "", 0, "L", false, 0, "")
+		r.font("", 10, pdfInk)
+		r.pdf.MultiCell(pdfWidth-boxWidth, pdfLine, pdfText(h.Title), "", "L", false)
+		if ref := r.trip.highlightEntry(h, r.date); ref != "" {
+			r.pdf.SetX(pdfMargin + boxWidth)
+			r.font("I", 9, pdfMuted)
+			r.pdf.MultiCell(pdfWidth-boxWidth, 4.5, pdfText("In the journal: "+ref), "", "L", false)
+		}
+		r.pdf.Ln(1)
+	}
+}
+
+func (r *report) itinerary() {
+	t := r.trip
+	r.section("Itinerary")
+	const timeWidth = 18.0
+	var day time.Time
+	for i, it := range t.Itinerary {
+		if d := dateOf(it.Day); i == 0 || !d.Equal(day) {
+			day = d
+			if i > 0 {
+				r.pdf.Ln(3)
+			}
+			r.heading(fmt.Sprintf("Day %d — %s %s", models.CalendarDays(t.StartDate, day), day.Format("Mon"), r.date(day)), 12)
+		}
+		r.font("B", 10, pdfInk)
+		r.pdf.CellFormat(timeWidth, pdfLine, it.Time, "", 0, "L", false, 0, "")
+		line := it.Title
+		if it.Place != "" {
+			line += " — " + it.Place
+		}
+		r.font("", 10, pdfInk)
+		r.pdf.MultiCell(pdfWidth-timeWidth, pdfLine, pdfText(line), "", "L", false)
+		var details []string
+		if it.BookingRef != "" {
+			details = append(details, "Booking: "+it.BookingRef)
+		}
+		if it.Notes != "" {
+			details = append(details, it.Notes)
+		}
+		if len(details) > 0 {
+			r.pdf.SetX(pdfMargin + timeWidth)
+			r.font("", 9, pdfMuted)
+			r.pdf.MultiCell(pdfWidth-timeWidth, 4.5, pdfText(strings.Join(details, "\n")), "", "L", false)
+		}
+		r.pdf.Ln(1)
+	}
+}
+
+func (r *report) journal() {
+	r.section("Journal")
+	for i, e := range r.trip.Entries {
+		if i > 0 {
+			r.pdf.Ln(6)
+		}
+		title := e.Title
+		if title == "" {
+			title = r.date(e.Timestamp)
+		}
+		r.heading(title, 14)
+		meta := []string{r.date(e.Timestamp)}
+		if e.Location != "" {
+			meta = append(meta, e.Location)
+		}
+		if e.Weather != nil {
+			meta = append(meta, e.Weather.Summary())
+		}
+		if e.Mood > 0 {
+			meta = append(meta, fmt.Sprintf("mood %d/%d", e.Mood, models.MaxRating))
+		}
+		if len(e.People) > 0 {
+			meta = append(meta, "with "+strings.Join(e.People, ", "))
+		}
+		if len(e.Tags) > 0 {
+			meta = append(meta, "#"+strings.Join(e.Tags, " #"))
+		}
+		r.font("I", 9, pdfMuted)
+		r.pdf.MultiCell(pdfWidth, 4.5, pdfText(strings.Join(meta, " · ")), "", "L", false)
+		r.pdf.Ln(2)
+		r.markdown(e.Text)
+	}
+}
+
+var (
+	mdImage  = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink   = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	mdStrong = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+	mdEm     = regexp.MustCompile(`\*([^*\s][^*]*)\*`)
+	mdList   = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+`)
+)
+
+// markdown sets the Markdown text of an entry as plain paragraphs:
+// headings are set in bold, list items and quotes indented and code in a
+// monospaced font, while inline markup is dropped.
+func (r *report) markdown(text string) {
+	var para []string
+	flush := func() {
+		if len(para) == 0 {
+			return
+		}
+		r.font("", 10, pdfInk)
+		r.pdf.MultiCell(pdfWidth, pdfLine, pdfText(inline(strings.Join(para, " "))), "", "L", false)
+		r.pdf.Ln(2)
+		para = nil
+	}
+	fenced := false
+	for _, l := range strings.Split(strings.TrimSpace(text), "\n") {
+		trimmed := strings.TrimSpace(l)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			fenced = !fenced
+			continue
+		}
+		switch {
+		case fenced:
+			r.pdf.SetFont("GoMono", "", 9)
+			r.pdf.SetFillColor(pdfShade[0], pdfShade[1], pdfShade[2])
+			r.pdf.MultiCell(pdfWidth, 4.5, pdfText(strings.ReplaceAll(l, "\t", "    ")), "", "L", true)
+		case trimmed == "":
+			flush()
+		case strings.HasPrefix(trimmed, "#"):
+			flush()
+			r.font("B", 11, pdfInk)
+			r.pdf.MultiCell(pdfWidth, 6, pdfText(inline(strings.TrimSpace(strings.TrimLeft(trimmed, "#")))), "", "L", false)
+			r.pdf.Ln(1)
+		case mdList.MatchString(l):
+			flush()
+			marker := strings.TrimSpace(mdList.FindString(l))
+			if strings.ContainsAny(marker, "-*+") {
+				marker = "•"
+			}
+			indent := 4 + 4*float64(len(l)-len(strings.TrimLeft(l, " \t"))/2)
+			r.font("", 10, pdfInk)
+			r.pdf.SetX(pdfMargin + indent)
+			r.pdf.CellFormat(6, pdfLine, marker, "", 0, "L", false, 0, "")
+			r.pdf.MultiCell(pdfWidth-indent-6, pdfLine, pdfText(inline(mdList.ReplaceAllString(l, ""))), "", "L", false)
+		case strings.HasPrefix(trimmed, ">"):
+			flush()
+			r.font("I", 10, pdfMuted)
+			r.pdf.SetX(pdfMargin + 6)
+			r.pdf.MultiCell(pdfWidth-6, pdfLine, pdfText(inline(strings.TrimSpace(strings.TrimLeft(trimmed, ">")))), "", "L", false)
+		default:
+			para = append(para, trimmed)
+		}
+	}
+	flush()
+}
+
+// inline drops the inline Markdown markup of a line, keeping its text.
+func inline(s string) string {
+	s = mdImage.ReplaceAllString(s, "$1")
+	s = mdLink.ReplaceAllString(s, "$1")
+	s = mdStrong.ReplaceAllString(s, "$2")
+	s = mdEm.ReplaceAllString(s, "$1")
+	return strings.ReplaceAll(s, "`", "")
+}
+
+func (r *report) expenses() {
+	t := r.trip
+	r.section("Expenses")
+
+	r.heading("By category", 12)
+	sums := summarize(t.Expenses)
+	r.table([]column{{"Category", 70, "L"}, {"Currency", 40, "L"}, {"Total", pdfWidth - 110, "R"}}, func(row func(...string)) {
+		for _, s := range sums {
+			row(s.category, s.currency, fmt.Sprintf("%.2f", s.total))
+		}
+	}, func(row func(...string)) {
+		for _, c := range currencyTotals(t.Expenses) {
+			row("Total", c.currency, fmt.Sprintf("%.2f", c.total))
+		}
+	})
+	if t.Budget > 0 {
+		r.font("", 10, pdfInk)
+		line := strings.TrimSpace(fmt.Sprintf("Budget: %.2f %s", t.Budget, t.BudgetCurrency))
+		if totals := currencyTotals(t.Expenses); len(totals) == 1 && (t.BudgetCurrency == "" || totals[0].currency == t.BudgetCurrency) {
+			line += fmt.Sprintf(", %.0f%% spent", 100*totals[0].total/t.Budget)
+		}
+		r.pdf.CellFormat(pdfWidth, pdfLine, line, "", 1, "L", false, 0, "")
+	}
+
+	r.pdf.Ln(6)
+	r.heading("All expenses", 12)
+	cols := []column{{"Date", 28, "L"}, {"Category", 32, "L"}, {"Description", pdfWidth - 95, "L"}, {"Amount", 35, "R"}}
+	r.table(cols, func(row func(...string)) {
+		for _, x := range t.Expenses {
+			row(r.date(x.Timestamp), x.Category, x.Description, fmt.Sprintf("%.2f %s", x.Amount, x.Currency))
+		}
+	}, func(row func(...string)) {
+		for _, c := range currencyTotals(t.Expenses) {
+			row("Total", "", "", fmt.Sprintf("%.2f %s", c.total, c.currency))
+		}
+	})
+}
+
+type column struct {
+	title string
+	width float64
+	align string
+}
+
+// table sets a table of cols with a shaded header and alternate rows. The
+// header repeats at the top of every page the table runs on to, and the
+// rows written by foot are set in bold under a rule.
+func (r *report) table(cols []column, body, foot func(row func(...string))) {
+	const height = 6.5
+	_, pageHeight := r.pdf.GetPageSize()
+	header := func() {
+		r.font("B", 9, pdfInk)
+		r.pdf.SetFillColor(pdfShade[0], pdfShade[1], pdfShade[2])
+		for _, c := range cols {
+			r.pdf.CellFormat(c.width, height, c.title, "", 0, c.align, true, 0, "")
+		}
+		r.pdf.Ln(-1)
+	}
+	header()
+	n := 0
+	write := func(style string) func(...string) {
+		return func(cells ...string) {
+			if r.pdf.GetY()+height > pageHeight-pdfMargin {
+				r.pdf.AddPage()
+				header()
+			}
+			r.font(style, 9, pdfInk)
+			n++
+			fill := style == "" && n%2 == 0
+			r.pdf.SetFillColor(250, 250, 251)
+			for i, c := range cols {
+				r.pdf.CellFormat(c.width, height, r.fit(pdfText(cells[i]), c.width-2), "", 0, c.align, fill, 0, "")
+			}
+			r.pdf.Ln(-1)
+		}
+	}
+	body(write(""))
+	r.pdf.SetDrawColor(pdfMuted[0], pdfMuted[1], pdfMuted[2])
+	y := r.pdf.GetY()
+	r.pdf.Line(pdfMargin, y, pdfMargin+sumWidths(cols), y)
+	foot(write("B"))
+	r.pdf.Ln(2)
+}
+
+// fit shortens s with an ellipsis to fit width in the current font.
+func (r *report) fit(s string, width float64) string {
+	if r.pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && r.pdf.GetStringWidth(string(runes)+"…") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
+
+func sumWidths(cols []column) float64 {
+	var w float64
+	for _, c := range cols {
+		w += c.width
+	}
+	return w
+}
+
+type currencyTotal struct {
+	currency string
+	total    float64
+}
+
+// currencyTotals totals expenses per currency, in currency order.
+func currencyTotals(expenses []*models.Expense) []currencyTotal {
+	sums := map[string]float64{}
+	for _, x := range expenses {
+		sums[x.Currency] += x.Amount
+	}
+	out := make([]currencyTotal, 0, len(sums))
+	for c, v := range sums {
+		out = append(out, currencyTotal{c, v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].currency < out[j].currency })
+	return out
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	if strings.HasSuffix(noun, "y") {
+		return fmt.Sprintf("%d %sies", n, strings.TrimSuffix(noun, "y"))
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// goFont is parsed once to tell which characters the report fonts have.
+var goFont, _ = sfnt.Parse(goregular.TTF)
+
+// pdfText prepares s for the Go fonts: symbols they lack, such as emoji,
+// are dropped and other missing characters replaced with "?".
+func pdfText(s string) string {
+	var buf sfnt.Buffer
+	var b strings.Builder
+	dropped := false
+	for _, r := range s {
+		if r == ' ' && dropped {
+			continue
+		}
+		dropped = false
+		if goFont == nil || r == '\n' || r == '\t' {
+			b.WriteRune(r)
+			continue
+		}
+		if g, err := goFont.GlyphIndex(&buf, r); err == nil && g != 0 {
+			b.WriteRune(r)
+			continue
+		}
+		switch {
+		case unicode.Is(unicode.So, r), unicode.Is(unicode.Sk, r), unicode.Is(unicode.Mn, r),
+			unicode.Is(unicode.Cf, r), unicode.Is(unicode.Variation_Selector, r):
+			// A symbol followed by a space, as in "⛅ 9–18°C", takes the
+			// space with it.
+			dropped = true
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
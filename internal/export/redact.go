@@ -0,0 +1,117 @@
+package export
+
+import (
+	"strings"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// Redact returns t as redaction r shares it: its entries redacted as
+// models.Entry.Redact does them, and the private passages of its notes
+// too. RedactNone returns t itself.
+func (t *Trip) Redact(r models.Redaction) *Trip {
+	if r != models.RedactStrip && r != models.RedactMask {
+		return t
+	}
+	c := *t
+	trip := *t.Trip
+	trip.Notes = models.RedactText(trip.Notes, r)
+	c.Trip = &trip
+	c.Entries = redactEntries(t.Entries, r)
+	return &c
+}
+
+// RedactTrips redacts each of trips, as Trip.Redact does.
+func RedactTrips(trips []*Trip, r models.Redaction) []*Trip {
+	out := make([]*Trip, len(trips))
+	for i, t := range trips {
+		out[i] = t.Redact(r)
+	}
+	return out
+}
+
+func redactEntries(entries []*models.Entry, r models.Redaction) []*models.Entry {
+	out := make([]*models.Entry, 0, len(entries))
+	for _, e := range entries {
+		if e = e.Redact(r); e != nil {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// RedactDump returns d as redaction r shares it. Besides the entries and
+// notes of its trips, as Trip.Redact does them, what would give away a
+// private entry goes too: its photos and their files and the revisions of
+// its text, and links to it when it is stripped. The revisions of other
+// entries have their private passages redacted. RedactNone returns d
+// itself.
+func RedactDump(d *storage.Dump, r models.Redaction) *storage.Dump {
+	if r != models.RedactStrip && r != models.RedactMask {
+		return d
+	}
+	c := *d
+	c.Trips = make([]*storage.TripRecord, len(d.Trips))
+	private := map[string]bool{}
+	for i, rec := range d.Trips {
+		x := *rec
+		if rec.Trip != nil {
+			trip := *rec.Trip
+			trip.Notes = models.RedactText(trip.Notes, r)
+			x.Trip = &trip
+		}
+		for _, e := range rec.Entries {
+			if e.Private {
+				private[e.ID] = true
+			}
+		}
+		x.Entries = redactEntries(rec.Entries, r)
+		x.Attachments = nil
+		for _, a := range rec.Attachments {
+			if !private[a.EntryID] {
+				x.Attachments = append(x.Attachments, a)
+			}
+		}
+		x.Revisions = nil
+		for _, rev := range rec.Revisions {
+			if !private[rev.EntryID] {
+				v := *rev
+				v.Text = models.RedactText(rev.Text, r)
+				x.Revisions = append(x.Revisions, &v)
+			}
+		}
+		if r == models.RedactStrip {
+			x.Highlights = make([]*models.Highlight, len(rec.Highlights))
+			for j, h := range rec.Highlights {
+				if private[h.EntryID] {
+					unlinked := *h
+					unlinked.EntryID = ""
+					h = &unlinked
+				}
+				x.Highlights[j] = h
+			}
+			x.Tracks = make([]*models.Track, len(rec.Tracks))
+			for j, t := range rec.Tracks {
+				if private[t.EntryID] {
+					unlinked := *t
+					unlinked.EntryID = ""
+					t = &unlinked
+				}
+				x.Tracks[j] = t
+			}
+		}
+		c.Trips[i] = &x
+	}
+	if len(private) > 0 && d.Files != nil {
+		c.Files = map[string][]byte{}
+		for path, data := range d.Files {
+			rest, ok := strings.CutPrefix(path, "attachments/")
+			if entry, _, _ := strings.Cut(rest, "/"); ok && private[entry] {
+				continue
+			}
+			c.Files[path] = data
+		}
+	}
+	return &c
+}
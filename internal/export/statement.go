@@ -0,0 +1,264 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Signs of charges on a statement, for StatementOptions.Charges.
+const (
+	ChargesAuto     = 0  // whichever sign most transactions have
+	ChargesNegative = -1 // money out is negative, as in most bank exports
+	ChargesPositive = 1  // money out is positive, as on card statements
+)
+
+// statementAliases are column names bank exports commonly use for the
+// fields of CSVFields.
+var statementAliases = map[string][]string{
+	FieldDate:        {"transaction date", "booking date", "posted date", "posting date", "value date"},
+	FieldDescription: {"merchant", "payee", "name", "details", "transaction description", "narrative"},
+	FieldAmount:      {"transaction amount", "value"},
+}
+
+// StatementOptions controls how statement transactions become expenses.
+type StatementOptions struct {
+	// Mapping maps a field from CSVFields to the header of the CSV column
+	// holding it. Unmapped fields are looked up by their own name and by
+	// the names banks commonly use.
+	Mapping map[string]string
+	// DateLayouts are tried in order on CSV dates; models.DateLayout is
+	// always tried last.
+	DateLayouts []string
+	// DefaultCurrency is used for transactions without a currency.
+	DefaultCurrency string
+	// Charges is the sign of money spent: ChargesAuto, ChargesNegative or
+	// ChargesPositive.
+	Charges int
+}
+
+// Statement is a parsed bank or credit card statement. Rows hold its
+// charges as expenses, their Merchant set to the transaction text; credits
+// such as refunds and card payments are only counted.
+type Statement struct {
+	Rows    []ImportedRow
+	Credits int
+}
+
+// transaction is a statement line before its sign is known.
+type transaction struct {
+	line     int
+	date     time.Time
+	amount   float64
+	currency string
+	text     string
+	memo     string
+	err      error
+}
+
+// ParseStatement reads a statement exported as OFX (or QFX) or as CSV with
+// a header row. Transactions that cannot be parsed are returned with Err
+// set; an error is returned only when the file itself is unusable.
+func ParseStatement(r io.Reader, opts StatementOptions) (*Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var txs []transaction
+	if IsOFX(data) {
+		txs, err = parseOFX(data, opts.DefaultCurrency)
+	} else {
+		txs, err = parseStatementCSV(bytes.NewReader(data), opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sign := float64(opts.Charges)
+	if opts.Charges == ChargesAuto {
+		sign = -1
+		var negative, positive int
+		for _, tx := range txs {
+			switch {
+			case tx.err != nil:
+			case tx.amount < 0:
+				negative++
+			case tx.amount > 0:
+				positive++
+			}
+		}
+		if positive > negative {
+			sign = 1
+		}
+	}
+
+	st := &Statement{}
+	for _, tx := range txs {
+		if tx.err != nil {
+			st.Rows = append(st.Rows, ImportedRow{Line: tx.line, Err: tx.err})
+			continue
+		}
+		amount := tx.amount * sign
+		if amount <= 0 {
+			st.Credits++
+			continue
+		}
+		cur, err := models.ParseCurrency(tx.currency)
+		if err != nil {
+			st.Rows = append(st.Rows, ImportedRow{Line: tx.line, Err: err})
+			continue
+		}
+		text := tx.text
+		if text == "" {
+			text = tx.memo
+		}
+		description := text
+		if description == "" {
+			description = models.CategoryOther
+		}
+		x := models.NewExpense("", math.Round(amount*100)/100, cur, models.CategoryOther, description, tx.date)
+		x.Merchant = text
+		if tx.memo != text {
+			x.Note = tx.memo
+		}
+		st.Rows = append(st.Rows, ImportedRow{Line: tx.line, Expense: x})
+	}
+	return st, nil
+}
+
+// Categorize files the statement's charges: the category and trip of the
+// rule matching each one's merchant, else the fallback trip, else the trip
+// in progress on the day of the charge. Charges no trip takes fail. It
+// returns how many charges a rule matched.
+func (st *Statement) Categorize(rules []*models.Rule, trips []*models.Trip, fallback *models.Trip) int {
+	matched := 0
+	for i := range st.Rows {
+		row := &st.Rows[i]
+		if row.Err != nil {
+			continue
+		}
+		x := row.Expense
+		if rule := models.MatchRule(rules, x.Merchant); rule != nil {
+			x.Category, row.Trip = rule.Category, rule.TripID
+			matched++
+		}
+		switch {
+		case row.Trip != "":
+		case fallback != nil:
+			row.Trip = fallback.ID
+		default:
+			if t := models.TripOn(trips, x.Timestamp); t != nil {
+				row.Trip = t.ID
+			} else {
+				row.Err = fmt.Errorf("no trip on %s for %q", x.Timestamp.Format(models.DateLayout), x.Description)
+			}
+		}
+	}
+	return matched
+}
+
+// IsOFX reports whether data looks like an OFX or QFX file rather than
+// CSV.
+func IsOFX(data []byte) bool {
+	head := bytes.ToUpper(data[:min(len(data), 1024)])
+	return bytes.Contains(head, []byte("OFXHEADER")) || bytes.Contains(head, []byte("<OFX>"))
+}
+
+func parseStatementCSV(r io.Reader, opts StatementOptions) ([]transaction, error) {
+	layouts := append(append([]string{}, opts.DateLayouts...), models.DateLayout)
+	var txs []transaction
+	err := readCSV(r, opts.Mapping, statementAliases, func(line int, get func(string) string, err error) {
+		tx := transaction{line: line, err: err}
+		if err == nil {
+			tx.text, tx.memo = get(FieldDescription), get(FieldNote)
+			if tx.currency = get(FieldCurrency); tx.currency == "" {
+				tx.currency = opts.DefaultCurrency
+			}
+			if tx.date, tx.err = parseDate(get(FieldDate), layouts); tx.err == nil {
+				tx.amount, tx.err = parseSignedAmount(get(FieldAmount))
+			}
+		}
+		txs = append(txs, tx)
+	})
+	return txs, err
+}
+
+// parseSignedAmount accepts amounts such as "-1,234.50", "12" or the
+// accounting "(12.00)" for a negative amount.
+func parseSignedAmount(v string) (float64, error) {
+	s := strings.ReplaceAll(strings.TrimSpace(v), ",", "")
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	if negative {
+		s = s[1 : len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("amount %q is not a number", v)
+	}
+	if negative {
+		n = -n
+	}
+	return n, nil
+}
+
+var (
+	ofxTransaction = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	ofxElement     = regexp.MustCompile(`(?i)<([A-Z0-9.]+)>([^<\r\n]*)`)
+	ofxCurrency    = regexp.MustCompile(`(?i)<CURDEF>\s*([A-Z]{3})`)
+)
+
+// parseOFX reads the transactions of an OFX file, whether SGML (OFX 1),
+// where values need no closing tags, or XML (OFX 2).
+func parseOFX(data []byte, defaultCurrency string) ([]transaction, error) {
+	text := string(data)
+	currency := defaultCurrency
+	if m := ofxCurrency.FindStringSubmatch(text); m != nil {
+		currency = m[1]
+	}
+	matches := ofxTransaction.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("ofx: no transactions found")
+	}
+	var txs []transaction
+	for _, m := range matches {
+		tx := transaction{line: strings.Count(text[:m[0]], "\n") + 1, currency: currency}
+		fields := map[string]string{}
+		for _, el := range ofxElement.FindAllStringSubmatch(text[m[2]:m[3]], -1) {
+			fields[strings.ToUpper(el[1])] = strings.TrimSpace(el[2])
+		}
+		if cur := fields["CURSYM"]; cur != "" {
+			tx.currency = cur
+		}
+		tx.text, tx.memo = ofxText(fields["NAME"]), ofxText(fields["MEMO"])
+		if tx.date, tx.err = parseOFXDate(fields["DTPOSTED"]); tx.err == nil {
+			tx.amount, tx.err = parseSignedAmount(fields["TRNAMT"])
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// parseOFXDate reads the day of an OFX datetime such as
+// 20250401120000.000[-5:EST].
+func parseOFXDate(v string) (time.Time, error) {
+	if len(v) < 8 {
+		return time.Time{}, fmt.Errorf("unrecognised date %q", v)
+	}
+	t, err := time.ParseInLocation("20060102", v[:8], time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unrecognised date %q", v)
+	}
+	return t, nil
+}
+
+// ofxText undoes the escaping of OFX text.
+func ofxText(v string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'").Replace(v)
+}
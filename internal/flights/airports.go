@@ -0,0 +1,166 @@
+package flights
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// Airport is an airport of the dataset.
+type Airport struct {
+	Code     string // IATA code, such as "HND"
+	Name     string // such as "Tokyo Haneda"
+	City     string // the city it serves, as the places dataset names it
+	Country  string // ISO 3166-1 alpha-2 code
+	Lat, Lon float64
+	Timezone string // IANA name, such as "Asia/Tokyo"
+
+	words []string // of the folded name
+}
+
+// String names the airport with its code, as in "Tokyo Haneda (HND)".
+func (a Airport) String() string {
+	return a.Name + " (" + a.Code + ")"
+}
+
+// Coordinates formats the airport's latitude and longitude.
+func (a Airport) Coordinates() string {
+	return places.Place{Lat: a.Lat, Lon: a.Lon}.Coordinates()
+}
+
+// Location is the airport's time zone, or UTC if it cannot be loaded.
+func (a Airport) Location() *time.Location {
+	return places.Place{Timezone: a.Timezone}.Location()
+}
+
+// CountryName is the name of the airport's country.
+func (a Airport) CountryName() string {
+	return places.CountryName(a.Country)
+}
+
+// DistanceTo is the great-circle distance in kilometres from the airport
+// to b, which a flight between them covers.
+func (a Airport) DistanceTo(b Airport) float64 {
+	return places.Distance(a.Lat, a.Lon, b.Lat, b.Lon) / 1000
+}
+
+// AirportByCode returns the airport with the IATA code, such as Tokyo
+// Haneda for "HND".
+func AirportByCode(code string) (Airport, bool) {
+	loadOnce.Do(load)
+	a, ok := airports[strings.ToUpper(strings.TrimSpace(code))]
+	return a, ok
+}
+
+// labelRe matches an airport named with its code, as String writes it.
+var labelRe = regexp.MustCompile(`\(([A-Za-z]{3})\)\s*$`)
+
+// FindAirport returns the airport query names: by its code, as "HND" or
+// String writes it, or by its name or a word of it, as "Haneda", or by its
+// city when that has only one airport, as "Lisbon". A name that fits more
+// than one airport, as "Tokyo" does, finds none.
+func FindAirport(query string) (Airport, bool) {
+	loadOnce.Do(load)
+	query = strings.TrimSpace(query)
+	if m := labelRe.FindStringSubmatch(query); m != nil {
+		query = m[1]
+	}
+	if a, ok := AirportByCode(query); ok {
+		return a, true
+	}
+	key := fold(query)
+	if key == "" {
+		return Airport{}, false
+	}
+	run := " " + strings.Join(words(key), " ") + " "
+	var found []Airport
+	for _, a := range airportList {
+		if fold(a.Name) == key {
+			return a, true
+		}
+		if fold(a.City) == key || strings.Contains(" "+strings.Join(a.words, " ")+" ", run) {
+			found = append(found, a)
+		}
+	}
+	if len(found) != 1 {
+		return Airport{}, false
+	}
+	return found[0], true
+}
+
+// SearchAirports returns up to limit airports matching what was typed of
+// a code, a name or a city: the airport with that code first, then those
+// whose code starts with it, then those with a word of their name starting
+// with it, then those of a city starting with it.
+func SearchAirports(query string, limit int) []Airport {
+	loadOnce.Do(load)
+	key := fold(query)
+	if key == "" {
+		return nil
+	}
+	code := strings.ToUpper(key)
+	var exact, codes, names, cities []Airport
+	for _, a := range airportList {
+		switch {
+		case a.Code == code:
+			exact = append(exact, a)
+		case len(key) < 3 && strings.HasPrefix(a.Code, code):
+			codes = append(codes, a)
+		case strings.HasPrefix(fold(a.Name), key) || containsWords(a.words, words(key)):
+			names = append(names, a)
+		case strings.HasPrefix(fold(a.City), key):
+			cities = append(cities, a)
+		}
+	}
+	out := append(append(append(exact, codes...), names...), cities...)
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// containsWords reports whether of has a run of the words typed, the last
+// of which may be typed in part, so "han" and "haneda" are of "tokyo
+// haneda" but "tok han" is not.
+func containsWords(of, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return false
+	}
+	for i := 0; i+len(prefixes) <= len(of); i++ {
+		ok := true
+		for j, p := range prefixes {
+			w := of[i+j]
+			if j < len(prefixes)-1 && w != p || !strings.HasPrefix(w, p) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// words splits folded text into its words of letters and digits, so
+// "chicago o'hare" has "chicago", "o" and "hare".
+func words(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+}
+
+// fold normalises a name for matching: lower case, without diacritics and
+// surrounding space, so "Galeão" matches "galeao".
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.TrimSpace(s)) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,737 @@
+// Package flights reads flights out of booking confirmations: the text of
+// an airline's or travel agent's email, pasted or saved to a file. It
+// knows the layouts those emails share rather than any one airline's, so a
+// flight is found from its number, with the airports, dates and times
+// written near it. Its airports dataset also finds airports by code or
+// name, with their cities, positions and time zones, for flights added by
+// hand.
+package flights
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+//go:embed airports.csv
+var airportsCSV []byte
+
+//go:embed airlines.csv
+var airlinesCSV []byte
+
+// Flight is a flight of a booking.
+type Flight struct {
+	Airline   string // name, or the code for airlines not in the dataset
+	Code      string // IATA airline code, such as "LH"
+	Number    string // without leading zeros, such as "716"
+	Departure Stop
+	Arrival   Stop
+	Reference string // booking reference, empty when not found
+}
+
+// Stop is the departure or arrival of a flight.
+type Stop struct {
+	Airport string // IATA code, such as "HND"
+	Name    string // name of the airport
+	City    string // city of the airport, empty when not known
+	Day     time.Time
+	Time    string // "15:04", empty when not found
+}
+
+// Designator names the flight, as in "LH 716", or is empty for a flight
+// entered without its number.
+func (f Flight) Designator() string {
+	return strings.TrimSpace(f.Code + " " + f.Number)
+}
+
+// Route is the flight's airports, as in "FRA → HND".
+func (f Flight) Route() string {
+	return f.Departure.Airport + " → " + f.Arrival.Airport
+}
+
+// Distance is the great-circle distance in kilometres between the flight's
+// airports, when both are in the dataset.
+func (f Flight) Distance() (float64, bool) {
+	from, ok := AirportByCode(f.Departure.Airport)
+	if !ok {
+		return 0, false
+	}
+	to, ok := AirportByCode(f.Arrival.Airport)
+	if !ok {
+		return 0, false
+	}
+	return from.DistanceTo(to), true
+}
+
+// String describes the flight on one line, as in
+// "LH 716 FRA → HND 2025-04-15 13:30".
+func (f Flight) String() string {
+	s := strings.TrimSpace(f.Designator()+" "+f.Route()) + " " + f.Departure.Day.Format(models.DateLayout)
+	if f.Departure.Time != "" {
+		s += " " + f.Departure.Time
+	}
+	return s
+}
+
+// Item makes the flight an item of a trip's itinerary, on its departure
+// day and time. Its place is the departure city, from which calendar
+// exports take the time zone of the departure.
+func (f Flight) Item(tripID string) *models.ItineraryItem {
+	title := "Flight " + f.Route()
+	if d := f.Designator(); d != "" {
+		title = "Flight " + d + " " + f.Route()
+	}
+	it := models.NewItineraryItem(tripID, f.Departure.Day, title)
+	it.Time = f.Departure.Time
+	it.Place = f.Departure.City
+	if it.Place == "" {
+		it.Place = f.Departure.Name
+	}
+	it.BookingRef = f.Reference
+	it.Notes = fmt.Sprintf("From %s to %s", f.Departure.label(), f.Arrival.label())
+	if f.Airline != "" {
+		it.Notes = fmt.Sprintf("%s from %s to %s", f.Airline, f.Departure.label(), f.Arrival.label())
+	}
+	if km, ok := f.Distance(); ok {
+		it.Notes += fmt.Sprintf(", %.0f km", km)
+	}
+	if !f.Arrival.Day.IsZero() {
+		arrives := "arrives " + f.Arrival.Day.Format(models.DateLayout)
+		if f.Arrival.Day.Equal(f.Departure.Day) {
+			arrives = "arrives"
+		}
+		if f.Arrival.Time != "" {
+			arrives += " at " + f.Arrival.Time
+		}
+		it.Notes += ", " + arrives
+	}
+	return it
+}
+
+// Items makes flights items of a trip's itinerary, which holds existing.
+// Each goes to the end of its day. Flights already in the itinerary, as an
+// item of the same day, title and booking reference, are returned apart as
+// duplicates.
+func Items(tripID string, flights []Flight, existing []*models.ItineraryItem) (items, duplicates []*models.ItineraryItem) {
+	onDay := func(day time.Time) []*models.ItineraryItem {
+		var out []*models.ItineraryItem
+		for _, it := range slices.Concat(existing, items) {
+			if y, m, d := it.Day.Date(); y == day.Year() && m == day.Month() && d == day.Day() {
+				out = append(out, it)
+			}
+		}
+		return out
+	}
+	for _, f := range flights {
+		it := f.Item(tripID)
+		same := onDay(it.Day)
+		if i := slices.IndexFunc(same, func(o *models.ItineraryItem) bool {
+			return o.Title == it.Title && o.BookingRef == it.BookingRef
+		}); i >= 0 {
+			duplicates = append(duplicates, same[i])
+			continue
+		}
+		it.Position = len(same)
+		items = append(items, it)
+	}
+	return items, duplicates
+}
+
+// label names the stop's airport with its code, as in
+// "Tokyo Haneda (HND)".
+func (s Stop) label() string {
+	if s.Name == "" || s.Name == s.Airport {
+		return s.Airport
+	}
+	return s.Name + " (" + s.Airport + ")"
+}
+
+var (
+	loadOnce    sync.Once
+	airports    map[string]Airport
+	airportList []Airport         // in the dataset's order
+	airlines    map[string]string // name by code
+)
+
+// load parses the embedded dataset. The files are part of the binary, so a
+// malformed one is a programming error.
+func load() {
+	airports, airlines = map[string]Airport{}, map[string]string{}
+	for _, rec := range readCSV(airportsCSV) {
+		lat, err1 := strconv.ParseFloat(rec[4], 64)
+		lon, err2 := strconv.ParseFloat(rec[5], 64)
+		if err1 != nil || err2 != nil {
+			panic(fmt.Sprintf("flights: bad airport %q", rec[0]))
+		}
+		a := Airport{Code: rec[0], Name: rec[1], City: rec[2], Country: rec[3], Lat: lat, Lon: lon, Timezone: rec[6]}
+		a.words = words(fold(a.Name))
+		airports[a.Code] = a
+		airportList = append(airportList, a)
+	}
+	for _, rec := range readCSV(airlinesCSV) {
+		airlines[rec[0]] = rec[1]
+	}
+}
+
+func readCSV(data []byte) [][]string {
+	recs, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil || len(recs) == 0 {
+		panic(fmt.Sprintf("flights: bad embedded dataset: %v", err))
+	}
+	return recs[1:] // header
+}
+
+// Airline returns the name of the airline with the IATA code, such as
+// "Lufthansa" for "LH".
+func Airline(code string) (string, bool) {
+	loadOnce.Do(load)
+	name, ok := airlines[strings.ToUpper(code)]
+	return name, ok
+}
+
+// designatorRe matches a flight number typed on its own, as in "NH 278",
+// "nh278" or "U2-8012".
+var designatorRe = regexp.MustCompile(`^([A-Z][A-Z0-9]|[0-9][A-Z])[ -]?0*(\d{1,4})$`)
+
+// ParseDesignator reads a flight number on its own into its airline code
+// and number, as "NH" and "278" of "NH 278".
+func ParseDesignator(s string) (code, number string, ok bool) {
+	m := designatorRe.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// ParseArrival reads an arrival time as typed for a flight added by hand:
+// "HH:MM" on the day of departure, or after the days it is later, as
+// "+1 07:05".
+func ParseArrival(s string) (clock string, days int, err error) {
+	bad := fmt.Errorf(`arrival %q is not HH:MM or "+1 HH:MM"`, s)
+	clock = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(clock, "+"); ok {
+		n, at, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		if days, err = strconv.Atoi(n); err != nil || days < 0 || days > 3 {
+			return "", 0, bad
+		}
+		clock = strings.TrimSpace(at)
+	}
+	t, err := time.Parse(models.TimeLayout, clock)
+	if err != nil {
+		return "", 0, bad
+	}
+	return t.Format(models.TimeLayout), days, nil
+}
+
+var (
+	// flightRe matches a flight number: an airline code, possibly with a
+	// digit, and up to four digits, as in "LH 716", "LH716", "U2-8012" or
+	// the padded "NH   6" of GDS itineraries.
+	flightRe = regexp.MustCompile(`\b([A-Z][A-Z0-9]|[0-9][A-Z])(?: {1,3}|-)?(\d{1,4})\b`)
+	// airportRe matches an airport code in parentheses after its name, as
+	// in "Frankfurt (FRA)".
+	airportRe = regexp.MustCompile(`\(([A-Z]{3})\)`)
+	// codeRe matches an airport code on its own, as in "ATL 10:50PM", or
+	// a GDS pair of them, as in "FRAHND".
+	codeRe = regexp.MustCompile(`\b([A-Z]{3})([A-Z]{3})?\b`)
+	// timeRe matches a time of day on the 24-hour or 12-hour clock, with
+	// the days it is after the departure day, as in "08:25 +1".
+	timeRe = regexp.MustCompile(`(?i)\b([01]?\d|2[0-3])[:h]([0-5]\d)(?:\s?([ap])\.?m\b\.?)?(?:\s?\+\s?(\d)\b)?`)
+	// gdsTimeRe matches the bare times of GDS itineraries, as in "1330"
+	// or "0825+1".
+	gdsTimeRe = regexp.MustCompile(`\b([01]\d|2[0-3])([0-5]\d)([AP])?(?:\+(\d))?\b`)
+	// referenceRe matches the label of a booking reference.
+	referenceRe = regexp.MustCompile(`(?i)\b(?:booking|confirmation|reservation)\s*(?:reference|ref\b\.?|code|number|no\b\.?|#)|\b(?:record locator|pnr|airline reference)\b`)
+	// codeWordRe matches the code following a reference label, as in
+	// ": ABC123".
+	codeWordRe = regexp.MustCompile(`^[\s:#-]*(?:is[\s:]+)?([A-Z0-9]{5,8})\b`)
+)
+
+const months = `(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?`
+
+var dateRes = []struct {
+	re *regexp.Regexp
+	// parse returns the year, month and day of a match, with year zero
+	// when the date has none.
+	parse func(m []string) (int, int, int)
+}{
+	{ // 2025-04-15
+		regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`),
+		func(m []string) (int, int, int) { return atoi(m[1]), atoi(m[2]), atoi(m[3]) },
+	},
+	{ // 15 April 2025, 15 Apr, 15-Apr-25
+		regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?[ -]` + months + `(?:,? ((?:19|20)\d{2})\b|-(\d{2})\b)?`),
+		func(m []string) (int, int, int) { return year(m[3] + m[4]), month(m[2]), atoi(m[1]) },
+	},
+	{ // 15APR, 15APR25
+		regexp.MustCompile(`\b(\d{2})(JAN|FEB|MAR|APR|MAY|JUN|JUL|AUG|SEP|OCT|NOV|DEC)(\d{2})?\b`),
+		func(m []string) (int, int, int) { return year(m[3]), month(m[2]), atoi(m[1]) },
+	},
+	{ // April 15, 2025; Apr 15
+		regexp.MustCompile(`(?i)\b` + months + ` (\d{1,2})(?:st|nd|rd|th)?\b(?:,? ((?:19|20)\d{2}))?`),
+		func(m []string) (int, int, int) { return year(m[3]), month(m[1]), atoi(m[2]) },
+	},
+	{ // 15.04.2025, 15/04/2025, or 04/15/2025 when the day comes second
+		regexp.MustCompile(`\b(\d{1,2})[./](\d{1,2})[./](\d{4})\b`),
+		func(m []string) (int, int, int) {
+			d, mo := atoi(m[1]), atoi(m[2])
+			if mo > 12 {
+				d, mo = mo, d
+			}
+			return atoi(m[3]), mo, d
+		},
+	},
+}
+
+// ignoredTimes are labels of times near a flight that are not its
+// departure or arrival.
+var ignoredTimes = []string{"boarding", "board by", "check-in", "check in", "gate", "duration", "flight time", "travel time", "layover", "connection"}
+
+// aircraft are makers whose models look like flight numbers, as in
+// "Airbus A350".
+var aircraft = []string{"airbus", "boeing", "embraer", "bombardier", "aircraft", "equipment"}
+
+// Parse reads the flights out of the text of a booking confirmation, in
+// the order they are written. Dates written without a year are taken in
+// the year that puts them nearest to ref, such as the start of the trip
+// they are for. A flight mentioned more than once, as in a summary and its
+// details, is returned once.
+func Parse(text string, ref time.Time) ([]Flight, error) {
+	loadOnce.Do(load)
+	text = strings.NewReplacer("\r\n", "\n", "\r", "\n", " ", " ", " ", " ").Replace(text)
+	var starts []int
+	for _, m := range flightMatches(text) {
+		start := strings.LastIndexByte(text[:m.start], '\n') + 1
+		if len(starts) == 0 || starts[len(starts)-1] != start {
+			starts = append(starts, start)
+		}
+	}
+	if len(starts) == 0 {
+		return nil, errors.New("no flight numbers found")
+	}
+	ref = time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, time.Local)
+	reference := findReference(text)
+
+	var flights []Flight
+	var undated []string
+	for i, start := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		f, ok := parseSegment(text[start:end], text[:start], ref)
+		if !ok {
+			continue
+		}
+		if f.Reference == "" {
+			f.Reference = reference
+		}
+		if f.Departure.Day.IsZero() {
+			undated = append(undated, f.Designator())
+			continue
+		}
+		if j := slices.IndexFunc(flights, func(g Flight) bool { return g.same(f) }); j >= 0 {
+			flights[j].merge(f)
+			continue
+		}
+		flights = append(flights, f)
+	}
+	if len(flights) == 0 {
+		if len(undated) > 0 {
+			return nil, fmt.Errorf("found %s but not the date of the flight", strings.Join(undated, ", "))
+		}
+		return nil, errors.New("no flights with their airports found")
+	}
+	return flights, nil
+}
+
+type flightMatch struct {
+	start, end   int
+	code, number string
+}
+
+// flightMatches finds the flight numbers of text, the first of each line.
+// Airline codes not in the dataset count when the word flight comes just
+// before them.
+func flightMatches(text string) []flightMatch {
+	var out []flightMatch
+	lastLine := -1
+	for _, loc := range flightRe.FindAllStringSubmatchIndex(text, -1) {
+		lineStart := strings.LastIndexByte(text[:loc[0]], '\n') + 1
+		if lineStart == lastLine {
+			continue
+		}
+		code, number := text[loc[2]:loc[3]], strings.TrimLeft(text[loc[4]:loc[5]], "0")
+		if number == "" || followedByDigits(text, loc[5]) {
+			continue // a time, as in "AM 11:55"
+		}
+		before := strings.ToLower(text[lineStart:loc[0]])
+		if strings.Contains(before, "operated by") || containsAny(lastWords(before, 2), aircraft) {
+			continue
+		}
+		_, known := airlines[code]
+		if !known && !strings.HasSuffix(strings.TrimRight(before, " :#."), "flight") &&
+			!strings.HasSuffix(strings.TrimRight(before, " :#."), "flight no") {
+			continue
+		}
+		if (code == "AM" || code == "PM") && strings.TrimSpace(before) != "" {
+			if b := strings.TrimRight(before, " "); b[len(b)-1] >= '0' && b[len(b)-1] <= '9' {
+				continue // a 12-hour time followed by a number
+			}
+		}
+		lastLine = lineStart
+		out = append(out, flightMatch{start: loc[0], end: loc[1], code: code, number: number})
+	}
+	return out
+}
+
+// parseSegment reads a flight out of segment, the lines from the one with
+// its number to the next flight's. before is the text before the segment,
+// whose last date is taken as the flight's when the segment has none.
+func parseSegment(segment, before string, ref time.Time) (Flight, bool) {
+	matches := flightMatches(segment)
+	if len(matches) == 0 {
+		return Flight{}, false
+	}
+	m := matches[0]
+	f := Flight{Code: m.code, Number: m.number, Airline: m.code}
+	if name, ok := airlines[m.code]; ok {
+		f.Airline = name
+	}
+	// Blank out the flight number, whose digits could read as a time.
+	rest := segment[:m.start] + strings.Repeat(" ", m.end-m.start) + segment[m.end:]
+
+	codes := findAirports(rest)
+	if len(codes) < 2 {
+		return Flight{}, false
+	}
+	f.Departure.Airport, f.Departure.Name = codes[0].code, codes[0].name
+	f.Arrival.Airport, f.Arrival.Name = codes[1].code, codes[1].name
+	for _, s := range []*Stop{&f.Departure, &f.Arrival} {
+		if a, ok := AirportByCode(s.Airport); ok {
+			s.Name, s.City = a.Name, a.City
+		}
+	}
+
+	// The departure date is the last before the arrival airport, which
+	// may be above the flight number, and the arrival date the first after
+	// it.
+	dates := findDates(rest, ref)
+	times := findTimes(rest, dates)
+	var departs int
+	for departs < len(dates) && dates[departs].start < codes[1].start {
+		departs++
+	}
+	var arrival *foundDate
+	if departs < len(dates) {
+		arrival = &dates[departs]
+	}
+	if departs > 0 {
+		f.Departure.Day = dates[departs-1].day
+	} else if earlier := findDates(before, ref); len(earlier) > 0 {
+		f.Departure.Day = earlier[len(earlier)-1].day
+	}
+	if len(times) > 0 {
+		f.Departure.Time = times[0].at
+	}
+	if len(times) > 1 {
+		f.Arrival.Time = times[1].at
+	}
+	if f.Departure.Day.IsZero() {
+		return f, true
+	}
+	switch {
+	case arrival != nil && arrivalDate(f.Departure.Day, arrival.day):
+		f.Arrival.Day = arrival.day
+	case len(times) > 1 && times[1].days > 0:
+		f.Arrival.Day = f.Departure.Day.AddDate(0, 0, times[1].days)
+	case len(times) > 1 && !times[1].gds && times[1].at < times[0].at:
+		// Overnight, as written without the days after departure.
+		f.Arrival.Day = f.Departure.Day.AddDate(0, 0, 1)
+	default:
+		f.Arrival.Day = f.Departure.Day
+	}
+	if ref := findReference(segment); ref != "" {
+		f.Reference = ref
+	}
+	return f, true
+}
+
+type foundAirport struct {
+	start      int
+	code, name string
+}
+
+// findAirports returns the airports of text in order: those in
+// parentheses, or failing two of those the known codes written on their
+// own.
+func findAirports(text string) []foundAirport {
+	var out []foundAirport
+	for _, loc := range airportRe.FindAllStringSubmatchIndex(text, -1) {
+		code := text[loc[2]:loc[3]]
+		line := text[strings.LastIndexByte(text[:loc[0]], '\n')+1 : loc[0]]
+		out = appendAirport(out, foundAirport{start: loc[0], code: code, name: airportName(line)})
+	}
+	if len(out) >= 2 {
+		return out
+	}
+	out = out[:0]
+	for _, loc := range codeRe.FindAllStringSubmatchIndex(text, -1) {
+		first := text[loc[2]:loc[3]]
+		if loc[4] >= 0 {
+			second := text[loc[4]:loc[5]]
+			if known(first) && known(second) {
+				out = appendAirport(out, foundAirport{start: loc[0], code: first, name: first})
+				out = appendAirport(out, foundAirport{start: loc[4], code: second, name: second})
+			}
+			continue
+		}
+		// A known code followed by a word in capitals is more likely a
+		// word of a name, as in "LOS ANGELES".
+		next := wordAfter(text, loc[1])
+		if !known(first) || len(next) >= 4 && strings.IndexFunc(next, isLower) < 0 {
+			continue
+		}
+		out = appendAirport(out, foundAirport{start: loc[0], code: first, name: first})
+	}
+	return out
+}
+
+// appendAirport adds a to airports unless it repeats the last one.
+func appendAirport(airports []foundAirport, a foundAirport) []foundAirport {
+	if n := len(airports); n > 0 && airports[n-1].code == a.code {
+		return airports
+	}
+	return append(airports, a)
+}
+
+func known(code string) bool {
+	_, ok := airports[code]
+	return ok
+}
+
+// wordAfter returns the word of letters starting one space after i.
+func wordAfter(text string, i int) string {
+	if i >= len(text) || text[i] != ' ' {
+		return ""
+	}
+	word := text[i+1:]
+	if end := strings.IndexFunc(word, func(r rune) bool { return !('A' <= r && r <= 'Z' || 'a' <= r && r <= 'z') }); end >= 0 {
+		word = word[:end]
+	}
+	return word
+}
+
+func isLower(r rune) bool { return 'a' <= r && r <= 'z' }
+
+// airportName cleans up the name written before an airport code on line:
+// the text after the last label, arrow or time.
+func airportName(line string) string {
+	for _, sep := range []string{":", "→", "–", "—", "->", ">", "|", "\t", "  ", " - "} {
+		if i := strings.LastIndex(line, sep); i >= 0 {
+			line = line[i+len(sep):]
+		}
+	}
+	if loc := timeRe.FindAllStringIndex(line, -1); len(loc) > 0 {
+		line = line[loc[len(loc)-1][1]:]
+	}
+	line = strings.TrimSpace(line)
+	for _, label := range []string{"from", "to", "depart", "departs", "departure", "arrive", "arrives", "arrival"} {
+		if rest, ok := strings.CutPrefix(strings.ToLower(line), label+" "); ok {
+			line = strings.TrimSpace(line[len(line)-len(rest):])
+		}
+	}
+	return line
+}
+
+type foundDate struct {
+	start, end int
+	day        time.Time
+}
+
+// findDates returns the dates of text in order.
+func findDates(text string, ref time.Time) []foundDate {
+	var out []foundDate
+	for _, d := range dateRes {
+		for _, m := range d.re.FindAllStringSubmatchIndex(text, -1) {
+			if slices.ContainsFunc(out, func(f foundDate) bool { return m[0] < f.end && f.start < m[1] }) {
+				continue
+			}
+			groups := make([]string, len(m)/2)
+			for i := range groups {
+				if m[2*i] >= 0 {
+					groups[i] = text[m[2*i]:m[2*i+1]]
+				}
+			}
+			y, mo, day := d.parse(groups)
+			if mo < 1 || mo > 12 || day < 1 || day > 31 {
+				continue
+			}
+			out = append(out, foundDate{start: m[0], end: m[1], day: dayIn(y, mo, day, ref)})
+		}
+	}
+	slices.SortFunc(out, func(a, b foundDate) int { return a.start - b.start })
+	return out
+}
+
+// dayIn returns the date, in the year nearest to ref when y is zero.
+func dayIn(y, mo, d int, ref time.Time) time.Time {
+	if y != 0 {
+		return time.Date(y, time.Month(mo), d, 0, 0, 0, 0, time.Local)
+	}
+	best := time.Time{}
+	for _, y := range []int{ref.Year() - 1, ref.Year(), ref.Year() + 1} {
+		t := time.Date(y, time.Month(mo), d, 0, 0, 0, 0, time.Local)
+		if best.IsZero() || absDuration(t.Sub(ref)) < absDuration(best.Sub(ref)) {
+			best = t
+		}
+	}
+	return best
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+type foundTime struct {
+	at   string // "15:04"
+	days int    // after the departure day, as in "+1"
+	// gds is set for the bare times of GDS itineraries, which always
+	// write the days after departure.
+	gds bool
+}
+
+// findTimes returns the times of day of text in order, leaving out those
+// inside dates and those labeled as something else than a departure or
+// arrival, such as boarding. The bare times of GDS itineraries count when
+// there are no others.
+func findTimes(text string, dates []foundDate) []foundTime {
+	find := func(re *regexp.Regexp) []foundTime {
+		var out []foundTime
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			if slices.ContainsFunc(dates, func(d foundDate) bool { return m[0] < d.end && d.start < m[1] }) {
+				continue
+			}
+			if m[0] > 0 && strings.ContainsAny(text[m[0]-1:m[0]], "./") {
+				continue // part of a date or a number
+			}
+			before := strings.ToLower(text[max(m[0]-20, 0):m[0]])
+			if i := strings.LastIndexAny(before, "\n"); i >= 0 {
+				before = before[i:]
+			}
+			if containsAny(before, ignoredTimes) {
+				continue
+			}
+			h, min := atoi(text[m[2]:m[3]]), atoi(text[m[4]:m[5]])
+			if m[6] >= 0 {
+				pm := strings.EqualFold(text[m[6]:m[7]], "p")
+				if h > 12 || h == 0 {
+					continue
+				}
+				if h == 12 {
+					h = 0
+				}
+				if pm {
+					h += 12
+				}
+			}
+			t := foundTime{at: fmt.Sprintf("%02d:%02d", h, min), gds: re == gdsTimeRe}
+			if m[8] >= 0 {
+				t.days = atoi(text[m[8]:m[9]])
+			}
+			out = append(out, t)
+		}
+		return out
+	}
+	if times := find(timeRe); len(times) > 0 {
+		return times
+	}
+	return find(gdsTimeRe)
+}
+
+// findReference returns the first booking reference of text: a code in
+// capitals after its label, which tells it from a word following one, as
+// in "confirmation number details".
+func findReference(text string) string {
+	for _, loc := range referenceRe.FindAllStringIndex(text, -1) {
+		m := codeWordRe.FindStringSubmatch(text[loc[1]:])
+		if m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// arrivalDate reports whether a date found after the departure day can be
+// the arrival day, rather than a date of something else, such as when the
+// booking was made.
+func arrivalDate(departure, day time.Time) bool {
+	return !day.Before(departure) && !day.After(departure.AddDate(0, 0, 2))
+}
+
+// followedByDigits reports whether text at i continues with a time or a
+// decimal, as in ":55" or ".5".
+func followedByDigits(text string, i int) bool {
+	return i+1 < len(text) && strings.ContainsRune(":.,", rune(text[i])) && '0' <= text[i+1] && text[i+1] <= '9'
+}
+
+// same reports whether f and g are the same flight.
+func (f Flight) same(g Flight) bool {
+	return f.Code == g.Code && f.Number == g.Number && f.Departure.Day.Equal(g.Departure.Day)
+}
+
+// merge fills in what f lacks from g, another mention of the same flight.
+func (f *Flight) merge(g Flight) {
+	fill := func(s *string, v string) {
+		if *s == "" {
+			*s = v
+		}
+	}
+	fill(&f.Reference, g.Reference)
+	fill(&f.Departure.Time, g.Departure.Time)
+	fill(&f.Arrival.Time, g.Arrival.Time)
+	if f.Arrival.Day.Equal(f.Departure.Day) && !g.Arrival.Day.IsZero() {
+		f.Arrival.Day = g.Arrival.Day
+	}
+}
+
+func containsAny(s string, subs []string) bool {
+	return slices.ContainsFunc(subs, func(sub string) bool { return strings.Contains(s, sub) })
+}
+
+// lastWords returns the last n words of s.
+func lastWords(s string, n int) string {
+	words := strings.Fields(s)
+	return strings.Join(words[max(len(words)-n, 0):], " ")
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// year reads a year of four or two digits, or zero for none.
+func year(s string) int {
+	n := atoi(s)
+	if n > 0 && n < 100 {
+		n += 2000
+	}
+	return n
+}
+
+func month(s string) int {
+	return strings.Index("janfebmaraprmayjunjulaugsepoctnovdec", strings.ToLower(s[:3]))/3 + 1
+}
@@ -0,0 +1,115 @@
+// Package fuzzy matches a typed query against names loosely, as the
+// command palette finds trips, entries and actions: the letters of the
+// query need only appear in order, and names where they fall together or
+// start words rank first.
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Scores of a match. Each matched letter scores; letters following the one
+// before or starting a word score more, and letters skipped in between
+// cost a little.
+const (
+	scoreMatch       = 16
+	bonusConsecutive = 12
+	bonusWordStart   = 10
+	bonusFirst       = 8
+	penaltyGap       = 1
+	maxGapPenalty    = 12
+)
+
+// Match reports whether every word of query appears in text, its letters in
+// order regardless of case, with a score ranking better matches higher and
+// the positions, in runes, of the letters matched. An empty query matches
+// anything with a score of zero.
+func Match(query, text string) (score int, positions []int, ok bool) {
+	t := []rune(strings.ToLower(text))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		s, pos, ok := matchWord([]rune(word), t)
+		if !ok {
+			return 0, nil, false
+		}
+		score += s
+		positions = append(positions, pos...)
+	}
+	return score, positions, true
+}
+
+// matchWord finds the letters of q in t and scores them. It takes the
+// first place where q ends, then walks back to the latest start that still
+// holds all of q, so the letters matched lie as close together as the
+// first match allows.
+func matchWord(q, t []rune) (int, []int, bool) {
+	// Forward: the end of the first match.
+	end, i := -1, 0
+	for j := 0; j < len(t) && i < len(q); j++ {
+		if t[j] == q[i] {
+			i++
+			if i == len(q) {
+				end = j
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+	// Backward: the latest start ending there.
+	pos := make([]int, len(q))
+	i = len(q) - 1
+	for j := end; j >= 0 && i >= 0; j-- {
+		if t[j] == q[i] {
+			pos[i] = j
+			i--
+		}
+	}
+	// Letters that fall together are as good as a word start, so prefer
+	// a whole word further on to letters scattered over the first one.
+	if k := wordAt(q, t, pos[0]); k >= 0 {
+		for i := range pos {
+			pos[i] = k + i
+		}
+	}
+
+	score := 0
+	for i, p := range pos {
+		score += scoreMatch
+		switch {
+		case p == 0:
+			score += bonusFirst + bonusWordStart
+		case isWordStart(t, p):
+			score += bonusWordStart
+		}
+		if i > 0 {
+			if gap := p - pos[i-1] - 1; gap == 0 {
+				score += bonusConsecutive
+			} else {
+				score -= min(gap*penaltyGap, maxGapPenalty)
+			}
+		}
+	}
+	return score, pos, true
+}
+
+// wordAt returns where q appears whole at the start of a word of t, from
+// from on, or -1 if it does not.
+func wordAt(q, t []rune, from int) int {
+	for j := from; j+len(q) <= len(t); j++ {
+		if isWordStart(t, j) && string(t[j:j+len(q)]) == string(q) {
+			return j
+		}
+	}
+	return -1
+}
+
+// isWordStart reports whether the letter at p starts a word: it is the
+// first, or follows something other than a letter or digit.
+func isWordStart(t []rune, p int) bool {
+	if p == 0 {
+		return true
+	}
+	prev := t[p-1]
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
@@ -0,0 +1,440 @@
+// Package gitsync keeps the data directory in a git repository and
+// synchronises it with a remote, by running the git command.
+//
+// The database is a binary file git cannot merge, so when both sides have
+// changed it a sync stops with ErrConflict and leaves the choice of which
+// side to keep to the user, or to merge the operation logs of package
+// oplog, which git merges as text, into it.
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Remote is the name of the remote nomadic pushes to and pulls from, and
+// Branch the branch every device commits to.
+const (
+	Remote = "origin"
+	Branch = "main"
+)
+
+// ignored lists files in the data directory that are never committed:
+// SQLite's scratch files, unfinished writes, the exchange-rate cache, the
+// trash of deleted attachments, drafts and where the TUI was left, the
+// automatic backups, the status of the daemon, the lock nomadic processes
+// take on the directory, the log of hooks that failed, the record of the
+// notes of this device's journal_dir and the name of this device in the
+// operation logs.
+var ignored = []string{
+	"*.db-wal",
+	"*.db-shm",
+	"*.tmp",
+	"rates.json",
+	"trash/",
+	"drafts/",
+	"session.json",
+	"backups/",
+	"daemon.json",
+	".nomadic.lock",
+	"hooks.log",
+	"nomadic.log*",
+	"notes.json",
+	"oplog.json",
+}
+
+// ErrConflict is returned by Sync when the local and remote histories
+// both changed the same files and cannot be merged.
+var ErrConflict = errors.New("gitsync: local and remote changes conflict")
+
+// ConflictError names the files changed on both sides.
+type ConflictError struct {
+	Files []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrConflict, strings.Join(e.Files, ", "))
+}
+
+func (e *ConflictError) Unwrap() error { return ErrConflict }
+
+// Repo is a data directory under git.
+type Repo struct {
+	dir string
+	// mu serialises git commands, which fight over the index lock.
+	mu sync.Mutex
+}
+
+// Open returns the repository in dir. It need not exist yet; see Init.
+func Open(dir string) *Repo {
+	return &Repo{dir: dir}
+}
+
+// Dir returns the repository's working directory.
+func (r *Repo) Dir() string { return r.dir }
+
+// Exists reports whether dir is already a git repository.
+func (r *Repo) Exists() bool {
+	_, err := os.Stat(filepath.Join(r.dir, ".git"))
+	return err == nil
+}
+
+// git runs a git command in the repository and returns its trimmed output.
+func (r *Repo) git(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.dir
+	// Never stop to ask for credentials or an editor from the TUI.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_EDITOR=true")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", errors.New("gitsync: git is not installed")
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		sub := args
+		for len(sub) > 2 && sub[0] == "-c" {
+			sub = sub[2:]
+		}
+		return "", fmt.Errorf("gitsync: git %s: %s", sub[0], msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Init turns the data directory into a repository, sets remote as its
+// origin when given, and commits what is already there.
+func (r *Repo) Init(ctx context.Context, remote string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.Exists() {
+		if _, err := r.git(ctx, "init", "--quiet", "--initial-branch", Branch); err != nil {
+			return err
+		}
+	}
+	if err := r.writeIgnore(); err != nil {
+		return err
+	}
+	if remote != "" {
+		if _, err := r.git(ctx, "remote", "get-url", Remote); err == nil {
+			_, err = r.git(ctx, "remote", "set-url", Remote, remote)
+			if err != nil {
+				return err
+			}
+		} else if _, err := r.git(ctx, "remote", "add", Remote, remote); err != nil {
+			return err
+		}
+	}
+	_, err := r.commit(ctx, "Start syncing nomadic data")
+	return err
+}
+
+// writeIgnore adds the ignored patterns missing from .gitignore.
+func (r *Repo) writeIgnore() error {
+	path := filepath.Join(r.dir, ".gitignore")
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("gitsync: %w", err)
+	}
+	have := map[string]bool{}
+	for _, line := range strings.Split(string(existing), "\n") {
+		have[strings.TrimSpace(line)] = true
+	}
+	var missing []string
+	for _, pattern := range ignored {
+		if !have[pattern] {
+			missing = append(missing, pattern)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.Write(existing)
+	if len(existing) > 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		b.WriteString("\n")
+	}
+	for _, pattern := range missing {
+		b.WriteString(pattern + "\n")
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return fmt.Errorf("gitsync: %w", err)
+	}
+	return nil
+}
+
+// Commit records every change in the data directory. It reports whether
+// there was anything to commit.
+func (r *Repo) Commit(ctx context.Context, message string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.commit(ctx, message)
+}
+
+func (r *Repo) commit(ctx context.Context, message string) (bool, error) {
+	// Repositories made by older versions learn the patterns added since.
+	if err := r.writeIgnore(); err != nil {
+		return false, err
+	}
+	if _, err := r.git(ctx, "add", "--all"); err != nil {
+		return false, err
+	}
+	if _, err := r.git(ctx, "diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+	args := append(r.identity(ctx), "commit", "--quiet", "--message", message)
+	if _, err := r.git(ctx, args...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// identity returns the options that commit as nomadic when the user has
+// no git identity configured. Commands that commit start with them.
+func (r *Repo) identity(ctx context.Context) []string {
+	var args []string
+	if name, _ := r.git(ctx, "config", "user.name"); name == "" {
+		args = append(args, "-c", "user.name=nomadic")
+	}
+	if email, _ := r.git(ctx, "config", "user.email"); email == "" {
+		args = append(args, "-c", "user.email=nomadic@localhost")
+	}
+	return args
+}
+
+// Push sends local commits to the remote.
+func (r *Repo) Push(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.push(ctx)
+}
+
+func (r *Repo) push(ctx context.Context) error {
+	branch, err := r.git(ctx, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return err
+	}
+	_, err = r.git(ctx, "push", "--quiet", "--set-upstream", Remote, branch)
+	return err
+}
+
+// Result describes what a Sync did.
+type Result struct {
+	Committed bool // local changes were committed
+	Pulled    int  // remote commits brought in
+	Pushed    int  // local commits sent
+}
+
+// Sync commits local changes, brings in remote commits and pushes. When
+// both sides changed the same files it stops with a *ConflictError and
+// leaves the working tree as it was; see Resolve.
+func (r *Repo) Sync(ctx context.Context, message string) (Result, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var res Result
+	var err error
+	if res.Committed, err = r.commit(ctx, message); err != nil {
+		return res, err
+	}
+	if !r.hasRemote(ctx) {
+		return res, fmt.Errorf("gitsync: no remote; add one with `nomadic sync init <url>`")
+	}
+	if _, err := r.git(ctx, "fetch", "--quiet", Remote); err != nil {
+		return res, err
+	}
+	upstream, err := r.upstream(ctx)
+	if err != nil {
+		return res, err
+	}
+	if upstream != "" {
+		ahead, behind, err := r.aheadBehind(ctx, upstream)
+		if err != nil {
+			return res, err
+		}
+		if behind > 0 {
+			if _, err := r.git(ctx, append(r.identity(ctx), "merge", "--quiet", "--no-edit", "--allow-unrelated-histories", upstream)...); err != nil {
+				files, _ := r.git(ctx, "diff", "--name-only", "--diff-filter=U")
+				r.git(ctx, "merge", "--abort")
+				if files == "" {
+					return res, err
+				}
+				return res, &ConflictError{Files: strings.Split(files, "\n")}
+			}
+			res.Pulled = behind
+		}
+		res.Pushed = ahead
+		if ahead == 0 {
+			return res, nil
+		}
+	} else if res.Pushed, err = r.count(ctx, "HEAD"); err != nil {
+		return res, err
+	}
+	return res, r.push(ctx)
+}
+
+// Resolve settles a conflict reported by Sync by keeping one side's
+// version of every conflicting file: "local" keeps this machine's data and
+// pushes it, "remote" replaces it with the remote's. The discarded side
+// stays reachable in git history.
+func (r *Repo) Resolve(ctx context.Context, keep string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	upstream, err := r.upstream(ctx)
+	if err != nil {
+		return err
+	}
+	if upstream == "" {
+		return errors.New("gitsync: nothing to resolve; the remote has no history yet")
+	}
+	if err := r.merge(ctx, upstream, keep); err != nil {
+		return err
+	}
+	return r.push(ctx)
+}
+
+// Pull commits local changes, fetches the remote and merges its commits,
+// keeping keep's version, "local" or "remote", of the files both sides
+// changed, without pushing. It returns how many commits it brought in.
+func (r *Repo) Pull(ctx context.Context, message, keep string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.commit(ctx, message); err != nil {
+		return 0, err
+	}
+	if !r.hasRemote(ctx) {
+		return 0, fmt.Errorf("gitsync: no remote; add one with `nomadic sync init <url>`")
+	}
+	if _, err := r.git(ctx, "fetch", "--quiet", Remote); err != nil {
+		return 0, err
+	}
+	upstream, err := r.upstream(ctx)
+	if err != nil || upstream == "" {
+		return 0, err
+	}
+	_, behind, err := r.aheadBehind(ctx, upstream)
+	if err != nil || behind == 0 {
+		return 0, err
+	}
+	return behind, r.merge(ctx, upstream, keep)
+}
+
+// merge merges upstream keeping keep's version of conflicting files.
+func (r *Repo) merge(ctx context.Context, upstream, keep string) error {
+	var strategy string
+	switch keep {
+	case "local":
+		strategy = "ours"
+	case "remote":
+		strategy = "theirs"
+	default:
+		return fmt.Errorf("gitsync: keep %q is not local or remote", keep)
+	}
+	_, err := r.git(ctx, append(r.identity(ctx), "merge", "--quiet", "--no-edit", "--allow-unrelated-histories", "--strategy-option", strategy, upstream)...)
+	if err != nil {
+		r.git(ctx, "merge", "--abort")
+		return err
+	}
+	return nil
+}
+
+// Status is the state of the repository as of the last fetch.
+type Status struct {
+	Dirty      bool // uncommitted changes
+	HasRemote  bool
+	Ahead      int // local commits not yet pushed
+	Behind     int // remote commits not yet merged
+	LastCommit time.Time
+}
+
+// Diverged reports whether both sides have commits the other lacks.
+func (s Status) Diverged() bool { return s.Ahead > 0 && s.Behind > 0 }
+
+// Status inspects the repository without contacting the remote.
+func (r *Repo) Status(ctx context.Context) (Status, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var s Status
+	out, err := r.git(ctx, "status", "--porcelain")
+	if err != nil {
+		return s, err
+	}
+	s.Dirty = out != ""
+	if last, err := r.git(ctx, "log", "-1", "--format=%ct"); err == nil && last != "" {
+		if sec, err := strconv.ParseInt(last, 10, 64); err == nil {
+			s.LastCommit = time.Unix(sec, 0)
+		}
+	}
+	if s.HasRemote = r.hasRemote(ctx); !s.HasRemote {
+		return s, nil
+	}
+	upstream, err := r.upstream(ctx)
+	if err != nil {
+		return s, err
+	}
+	if upstream == "" {
+		s.Ahead, err = r.count(ctx, "HEAD")
+		return s, err
+	}
+	s.Ahead, s.Behind, err = r.aheadBehind(ctx, upstream)
+	return s, err
+}
+
+func (r *Repo) hasRemote(ctx context.Context) bool {
+	_, err := r.git(ctx, "remote", "get-url", Remote)
+	return err == nil
+}
+
+// upstream returns the remote-tracking branch for the current branch, or
+// an empty string when the remote does not have it yet.
+func (r *Repo) upstream(ctx context.Context) (string, error) {
+	branch, err := r.git(ctx, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	ref := Remote + "/" + branch
+	if _, err := r.git(ctx, "rev-parse", "--verify", "--quiet", "refs/remotes/"+ref); err != nil {
+		return "", nil
+	}
+	return ref, nil
+}
+
+func (r *Repo) aheadBehind(ctx context.Context, upstream string) (ahead, behind int, err error) {
+	out, err := r.git(ctx, "rev-list", "--left-right", "--count", "HEAD..."+upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("gitsync: unexpected rev-list output %q", out)
+	}
+	ahead, _ = strconv.Atoi(fields[0])
+	behind, _ = strconv.Atoi(fields[1])
+	return ahead, behind, nil
+}
+
+// count returns how many commits rev has, or 0 for an empty repository.
+func (r *Repo) count(ctx context.Context, rev string) (int, error) {
+	if _, err := r.git(ctx, "rev-parse", "--verify", "--quiet", rev); err != nil {
+		return 0, nil
+	}
+	out, err := r.git(ctx, "rev-list", "--count", rev)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(out)
+}
@@ -0,0 +1,267 @@
+// Package gpx reads GPS tracks from GPX files and computes their distance,
+// elevation and duration.
+package gpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// Point is one recorded position.
+type Point struct {
+	Lat, Lon  float64
+	Elevation float64 // metres above sea level
+	HasEle    bool
+	Time      time.Time // zero when not recorded
+}
+
+// Track is the content of a GPX file. Each segment is a continuous
+// recording; gaps between segments do not count towards the distance.
+type Track struct {
+	Name     string
+	Segments [][]Point
+}
+
+// Stats summarises a track.
+type Stats struct {
+	Distance float64 // metres
+	Ascent   float64 // metres
+	Descent  float64 // metres
+	Points   int
+	Start    time.Time // zero when the file has no timestamps
+	End      time.Time
+}
+
+// Duration is the time from the first to the last timestamped point.
+func (s Stats) Duration() time.Duration {
+	if s.Start.IsZero() || s.End.IsZero() {
+		return 0
+	}
+	return s.End.Sub(s.Start)
+}
+
+type gpxFile struct {
+	Metadata struct {
+		Name string `xml:"name"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Segments []struct {
+			Points []gpxPoint `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+	Routes []struct {
+		Name   string     `xml:"name"`
+		Points []gpxPoint `xml:"rtept"`
+	} `xml:"rte"`
+}
+
+type gpxPoint struct {
+	Lat  float64  `xml:"lat,attr"`
+	Lon  float64  `xml:"lon,attr"`
+	Ele  *float64 `xml:"ele"`
+	Time string   `xml:"time"`
+}
+
+func (p gpxPoint) point() Point {
+	pt := Point{Lat: p.Lat, Lon: p.Lon}
+	if p.Ele != nil {
+		pt.Elevation, pt.HasEle = *p.Ele, true
+	}
+	if t, err := time.Parse(time.RFC3339, strings.TrimSpace(p.Time)); err == nil {
+		pt.Time = t
+	}
+	return pt
+}
+
+// Parse reads a GPX document. Tracks and routes are both accepted; all of
+// them are combined into one Track.
+func Parse(r io.Reader) (*Track, error) {
+	var f gpxFile
+	if err := xml.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("gpx: %w", err)
+	}
+	t := &Track{Name: f.Metadata.Name}
+	for _, trk := range f.Tracks {
+		if t.Name == "" {
+			t.Name = trk.Name
+		}
+		for _, seg := range trk.Segments {
+			t.add(seg.Points)
+		}
+	}
+	for _, rte := range f.Routes {
+		if t.Name == "" {
+			t.Name = rte.Name
+		}
+		t.add(rte.Points)
+	}
+	if len(t.Segments) == 0 {
+		return nil, errors.New("gpx: no track points found")
+	}
+	return t, nil
+}
+
+func (t *Track) add(points []gpxPoint) {
+	if len(points) == 0 {
+		return
+	}
+	seg := make([]Point, len(points))
+	for i, p := range points {
+		seg[i] = p.point()
+	}
+	t.Segments = append(t.Segments, seg)
+}
+
+// elevationThreshold is the climb, in metres, that must accumulate before
+// it counts, so GPS altitude jitter on flat ground does not add up.
+const elevationThreshold = 3
+
+// Stats computes the track's distance, elevation change and time span.
+func (t *Track) Stats() Stats {
+	var s Stats
+	for _, seg := range t.Segments {
+		s.Points += len(seg)
+		var ref float64
+		hasRef := false
+		for i, p := range seg {
+			if i > 0 {
+				s.Distance += distance(seg[i-1], p)
+			}
+			if p.HasEle {
+				switch {
+				case !hasRef:
+					ref, hasRef = p.Elevation, true
+				case p.Elevation-ref >= elevationThreshold:
+					s.Ascent += p.Elevation - ref
+					ref = p.Elevation
+				case ref-p.Elevation >= elevationThreshold:
+					s.Descent += ref - p.Elevation
+					ref = p.Elevation
+				}
+			}
+			if !p.Time.IsZero() {
+				if s.Start.IsZero() || p.Time.Before(s.Start) {
+					s.Start = p.Time
+				}
+				if p.Time.After(s.End) {
+					s.End = p.Time
+				}
+			}
+		}
+	}
+	return s
+}
+
+// earthRadius is the mean radius of the Earth in metres.
+const earthRadius = 6371008.8
+
+// distance is the great-circle distance between two points in metres.
+func distance(a, b Point) float64 {
+	lat1, lat2 := a.Lat*math.Pi/180, b.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Lon - a.Lon) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// pathResolution is how far, in metres, a point must be from the last one
+// kept for the route of a track to keep it too.
+const pathResolution = 10
+
+// Path thins the track out into the route kept with it: each segment as a
+// line of [lon, lat] positions, rounded to about a decimetre, leaving out
+// points closer than pathResolution to the one before. The last point of
+// a segment is always kept.
+func (t *Track) Path() [][][2]float64 {
+	var path [][][2]float64
+	for _, seg := range t.Segments {
+		var (
+			line [][2]float64
+			last Point
+		)
+		for i, p := range seg {
+			if i > 0 && i < len(seg)-1 && distance(last, p) < pathResolution {
+				continue
+			}
+			line = append(line, [2]float64{round(p.Lon), round(p.Lat)})
+			last = p
+		}
+		path = append(path, line)
+	}
+	return path
+}
+
+// round rounds a coordinate to six decimals.
+func round(deg float64) float64 {
+	return math.Round(deg*1e6) / 1e6
+}
+
+// nearbyPlace is how far, in metres, the start of a track may be from a
+// city for the track to be named after it.
+const nearbyPlace = 50000
+
+// ReadTrack parses the GPX file at path into a track of the given trip,
+// named after the file when the GPX has no name of its own. The track's
+// place is looked up offline from its first point.
+func ReadTrack(path, tripID string) (*models.Track, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	t, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	s := t.Stats()
+	track := &models.Track{
+		ID:        models.NewID(),
+		TripID:    tripID,
+		Name:      strings.TrimSpace(t.Name),
+		Distance:  s.Distance,
+		Ascent:    s.Ascent,
+		Descent:   s.Descent,
+		Points:    s.Points,
+		Path:      t.Path(),
+		CreatedAt: time.Now(),
+	}
+	if track.Name == "" {
+		track.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if !s.Start.IsZero() {
+		start, end := s.Start.Local(), s.End.Local()
+		track.StartedAt, track.EndedAt = &start, &end
+	}
+	if p, d, ok := places.Nearest(t.Segments[0][0].Lat, t.Segments[0][0].Lon); ok && d <= nearbyPlace {
+		track.Place = p.String()
+	}
+	return track, nil
+}
+
+// FormatDistance renders metres as kilometres, or metres for short tracks.
+func FormatDistance(m float64) string {
+	if m < 1000 {
+		return fmt.Sprintf("%.0f m", m)
+	}
+	return fmt.Sprintf("%.1f km", m/1000)
+}
+
+// FormatDuration renders a duration as hours and minutes, e.g. "3h05".
+func FormatDuration(d time.Duration) string {
+	if d <= 0 {
+		return "—"
+	}
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,128 @@
+// Package hooks runs the commands set in the [hooks] table of the config
+// when nomadic's events happen, such as a journal entry saved, with the
+// event as JSON on their standard input, so that the user's own scripts
+// can pass it on, to a notes app or a webhook.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timeout is how long a hook command may run before it is stopped.
+const Timeout = 30 * time.Second
+
+// EventEnv is the environment variable naming the event to its command,
+// for scripts serving several events.
+const EventEnv = "NOMADIC_EVENT"
+
+// Runner runs the hook commands of events in the background.
+type Runner struct {
+	commands map[string]string
+	dir      string
+	// failed is told of each hook command that failed.
+	failed func(event string, err error)
+	wg     sync.WaitGroup
+}
+
+// New returns a runner of commands, by event, started in the directory
+// dir. failed, when set, is told of each command that failed.
+func New(commands map[string]string, dir string, failed func(event string, err error)) *Runner {
+	return &Runner{commands: commands, dir: dir, failed: failed}
+}
+
+// Has reports whether a command is set for event.
+func (r *Runner) Has(event string) bool {
+	return r != nil && strings.TrimSpace(r.commands[event]) != ""
+}
+
+// Fire starts the command of event, if one is set, with payload as JSON on
+// its standard input, and returns without waiting for it.
+func (r *Runner) Fire(event string, payload any) {
+	if !r.Has(event) {
+		return
+	}
+	input, err := json.Marshal(payload)
+	if err != nil {
+		r.fail(event, err)
+		return
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+		if _, err := r.run(ctx, event, input); err != nil {
+			r.fail(event, err)
+		}
+	}()
+}
+
+// Run runs the command of event with payload as JSON on its standard input
+// and waits for it, returning what it printed.
+func (r *Runner) Run(ctx context.Context, event string, payload any) ([]byte, error) {
+	if !r.Has(event) {
+		return nil, fmt.Errorf("hooks: no command is set for %s", event)
+	}
+	input, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	return r.run(ctx, event, input)
+}
+
+// Wait waits for the commands still running, for up to Timeout.
+func (r *Runner) Wait() {
+	if r == nil {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(Timeout):
+	}
+}
+
+// run runs the command of event through the shell, so that it may be a
+// pipeline, with input on its standard input.
+func (r *Runner) run(ctx context.Context, event string, input []byte) ([]byte, error) {
+	command := r.commands[event]
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), EventEnv+"="+event)
+	cmd.Stdin = bytes.NewReader(input)
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return out.Bytes(), fmt.Errorf("hooks: %s: %w: %s", command, err, msg)
+		}
+		return out.Bytes(), fmt.Errorf("hooks: %s: %w", command, err)
+	}
+	return out.Bytes(), nil
+}
+
+func (r *Runner) fail(event string, err error) {
+	if r.failed != nil {
+		r.failed(event, err)
+	}
+}
@@ -0,0 +1,362 @@
+package i18n
+
+// german translates the TUI into German.
+var german = map[string]string{
+	// Months and weekdays.
+	"January":   "Januar",
+	"February":  "Februar",
+	"March":     "März",
+	"April":     "April",
+	"May":       "Mai",
+	"June":      "Juni",
+	"July":      "Juli",
+	"August":    "August",
+	"September": "September",
+	"October":   "Oktober",
+	"November":  "November",
+	"December":  "Dezember",
+	"Jan":       "Jan",
+	"Feb":       "Feb",
+	"Mar":       "Mär",
+	"Apr":       "Apr",
+	"Jun":       "Jun",
+	"Jul":       "Jul",
+	"Aug":       "Aug",
+	"Sep":       "Sep",
+	"Oct":       "Okt",
+	"Nov":       "Nov",
+	"Dec":       "Dez",
+	"Monday":    "Montag",
+	"Tuesday":   "Dienstag",
+	"Wednesday": "Mittwoch",
+	"Thursday":  "Donnerstag",
+	"Friday":    "Freitag",
+	"Saturday":  "Samstag",
+	"Sunday":    "Sonntag",
+	"Mon":       "Mo",
+	"Tue":       "Di",
+	"Wed":       "Mi",
+	"Thu":       "Do",
+	"Fri":       "Fr",
+	"Sat":       "Sa",
+	"Sun":       "So",
+	"Mo":        "Mo",
+	"Tu":        "Di",
+	"We":        "Mi",
+	"Th":        "Do",
+	"Fr":        "Fr",
+	"Sa":        "Sa",
+	"Su":        "So",
+
+	// Counted things.
+	"archived trip":    "archivierte Reise",
+	"archived trips":   "archivierte Reisen",
+	"attachment":       "Anhang",
+	"attachments":      "Anhänge",
+	"category budget":  "Kategorienbudget",
+	"category budgets": "Kategorienbudgets",
+	"commit":           "Commit",
+	"commits":          "Commits",
+	"country":          "Land",
+	"countries":        "Länder",
+	"credit":           "Guthaben",
+	"credits":          "Guthaben",
+	"day":              "Tag",
+	"days":             "Tage",
+	"document":         "Dokument",
+	"documents":        "Dokumente",
+	"entry":            "Eintrag",
+	"entries":          "Einträge",
+	"expense":          "Ausgabe",
+	"expenses":         "Ausgaben",
+	"charge":           "Abbuchung",
+	"charges":          "Abbuchungen",
+	"flight":           "Flug",
+	"flights":          "Flüge",
+	"item":             "Punkt",
+	"items":            "Punkte",
+	"itinerary item":   "Programmpunkt",
+	"itinerary items":  "Programmpunkte",
+	"journal entry":    "Tagebucheintrag",
+	"journal entries":  "Tagebucheinträge",
+	"journey":          "Fahrt",
+	"journeys":         "Fahrten",
+	"leg":              "Etappe",
+	"legs":             "Etappen",
+	"shared expense":   "geteilte Ausgabe",
+	"shared expenses":  "geteilte Ausgaben",
+	"thing to pack":    "Sache zum Einpacken",
+	"things to pack":   "Sachen zum Einpacken",
+	"trip":             "Reise",
+	"trips":            "Reisen",
+
+	// Screens.
+	"Attachments":           "Anhänge",
+	"Budget":                "Budget",
+	"Calendar":              "Kalender",
+	"Categories":            "Kategorien",
+	"Check in":              "Einchecken",
+	"Companions":            "Mitreisende",
+	"Compare Trips":         "Reisen vergleichen",
+	"Compare trips":         "Reisen vergleichen",
+	"Countries":             "Länder",
+	"Documents":             "Dokumente",
+	"Drafts":                "Entwürfe",
+	"Edit Document":         "Dokument bearbeiten",
+	"Edit %s":               "%s bearbeiten",
+	"Every trip":            "Alle Reisen",
+	"Export":                "Exportieren",
+	"Export CSV":            "CSV exportieren",
+	"Expense categories":    "Ausgabenkategorien",
+	"Expenses":              "Ausgaben",
+	"Go to":                 "Gehe zu",
+	"History":               "Verlauf",
+	"Import CSV":            "CSV importieren",
+	"Import flights":        "Flüge importieren",
+	"Itinerary":             "Reiseplan",
+	"Journal":               "Tagebuch",
+	"Lodging":               "Unterkünfte",
+	"Map":                   "Karte",
+	"New Trip":              "Neue Reise",
+	"New activity":          "Neue Aktivität",
+	"New category":          "Neue Kategorie",
+	"New flight":            "Neuer Flug",
+	"New country note":      "Neue Ländernotiz",
+	"New entry":             "Neuer Eintrag",
+	"New expense":           "Neue Ausgabe",
+	"New leg":               "Neue Etappe",
+	"New lodging":           "Neue Unterkunft",
+	"New person":            "Neue Person",
+	"New recurring expense": "Neue wiederkehrende Ausgabe",
+	"New wish":              "Neuer Wunsch",
+	"Packing":               "Packliste",
+	"People":                "Personen",
+	"Preparation":           "Vorbereitung",
+	"Quit":                  "Beenden",
+	"Receipts inbox":        "Belegeingang",
+	"Reconcile":             "Abgleichen",
+	"Recurring":             "Wiederkehrend",
+	"Report":                "Bericht",
+	"Search":                "Suche",
+	"Settle up":             "Abrechnen",
+	"Stats":                 "Statistik",
+	"Tags":                  "Tags",
+	"Templates":             "Vorlagen",
+	"Trash":                 "Papierkorb",
+	"Trip fields":           "Reisefelder",
+	"Trips":                 "Reisen",
+	"Unlock":                "Entsperren",
+	"Wishlist":              "Wunschliste",
+	"Vault":                 "Tresor",
+	"New document":          "Neues Dokument",
+	"View Journal":          "Tagebuch ansehen",
+	"Welcome":               "Willkommen",
+
+	// The home menu.
+	"Nomadic – Your Travel Journal Companion":                 "Nomadic – Dein Begleiter fürs Reisetagebuch",
+	"%s search journal • %s undo • %s switch theme • %s quit": "%s Tagebuch durchsuchen • %s rückgängig • %s Farbschema wechseln • %s beenden",
+	"Saved trip %q":      "Reise %q gespeichert",
+	"Theme: %s":          "Farbschema: %s",
+	"previous item":      "vorheriger Punkt",
+	"next item":          "nächster Punkt",
+	"open the item":      "Punkt öffnen",
+	"search the journal": "Tagebuch durchsuchen",
+	"Coming up":          "Demnächst",
+	"today":              "heute",
+	"tomorrow":           "morgen",
+	"in %d days":         "in %d Tagen",
+	"Deadlines":          "Fristen",
+	"overdue 1 day":      "seit 1 Tag überfällig",
+	"overdue %d days":    "seit %d Tagen überfällig",
+	"Next trip":          "Nächste Reise",
+	"Trip in progress":   "Laufende Reise",
+	"No trips yet — choose New Trip to plan your first.":      "Noch keine Reisen — wähle Neue Reise, um die erste zu planen.",
+	"Nothing planned — choose New Trip to plan the next one.": "Nichts geplant — wähle Neue Reise, um die nächste zu planen.",
+	"%s to go":       "noch %s",
+	"last day":       "letzter Tag",
+	"Recent entries": "Neueste Einträge",
+	"On this day":    "An diesem Tag",
+	"a year ago":     "vor einem Jahr",
+	"%d years ago":   "vor %d Jahren",
+	"No journal entries yet — write one from View Journal.": "Noch keine Tagebucheinträge — schreibe einen unter Tagebuch ansehen.",
+	"Spending": "Ausgaben",
+	"Shows here for the trip in progress or coming up.": "Erscheint hier für die laufende oder nächste Reise.",
+	"Nothing spent yet of %s":                           "Noch nichts ausgegeben von %s",
+	"Nothing spent yet, and no budget set.":             "Noch nichts ausgegeben und kein Budget festgelegt.",
+	"over by %s":                                        "%s darüber",
+	"close to the limit":                                "nah am Limit",
+	"%s spent, no budget set":                           "%s ausgegeben, kein Budget festgelegt",
+	"%s in other currencies not counted":                "%s in anderen Währungen nicht gezählt",
+	"%s in other currencies, loading exchange rates…":   "%s in anderen Währungen, Wechselkurse werden geladen…",
+
+	// The offer to resume.
+	"Resume":                     "Fortsetzen",
+	"Pick up where you left off": "Dort weitermachen, wo du aufgehört hast",
+	"When nomadic closed on %s at %s, you were at:": "Als nomadic am %s um %s geschlossen wurde, warst du bei:",
+	"enter resume • esc start from home":            "Enter fortsetzen • Esc im Hauptmenü beginnen",
+
+	// The header and footer.
+	"Day %d of %d in %s": "Tag %d von %d in %s",
+	"Day %d in %s":       "Tag %d in %s",
+	"Day %d of %d":       "Tag %d von %d",
+	"%s starts today":    "%s beginnt heute",
+	"%s starts tomorrow": "%s beginnt morgen",
+	"%d days until %s":   "Noch %d Tage bis %s",
+	"%d-day streak":      "%d Tage in Folge",
+	"%d-day streak · write today's entry to keep it": "%d Tage in Folge · schreib den heutigen Eintrag, um dranzubleiben",
+	"No journal entry today":                         "Heute noch kein Tagebucheintrag",
+	"%s help":                                        "%s Hilfe",
+	"Autosave failed: %s":                            "Automatisches Speichern fehlgeschlagen: %s",
+	"Sync failed: %s":                                "Synchronisieren fehlgeschlagen: %s",
+	"Diverged from the remote — run nomadic sync":    "Vom Remote abgewichen — nomadic sync ausführen",
+	"Sync is working again":                          "Synchronisieren klappt wieder",
+
+	// Help.
+	"Keys":       "Tasten",
+	"Everywhere": "Überall",
+	"Change keys with nomadic config set keys.<action> • %s or %s close": "Tasten ändern mit nomadic config set keys.<action> • %s oder %s schließt",
+	"show or hide this help": "diese Hilfe ein- oder ausblenden",
+	"go back":                "zurück",
+	"switch theme":           "Farbschema wechseln",
+	"check in at a place on this trip or the one in progress":              "an einem Ort dieser oder der laufenden Reise einchecken",
+	"jot a note down in today's entry of this trip or the one in progress": "eine Notiz im heutigen Eintrag dieser oder der laufenden Reise festhalten",
+	"go to any trip, entry, expense or action by name":                     "jede Reise, jeden Eintrag, jede Ausgabe oder Aktion nach Namen finden",
+	"quit nomadic": "nomadic beenden",
+	"undo":         "rückgängig",
+	"redo":         "wiederholen",
+
+	// The calendar.
+	"previous day":                       "vorheriger Tag",
+	"next day":                           "nächster Tag",
+	"previous week":                      "vorherige Woche",
+	"next week":                          "nächste Woche",
+	"previous month":                     "vorheriger Monat",
+	"next month":                         "nächster Monat",
+	"go to today":                        "zu heute springen",
+	"choose an entry or trip of the day": "einen Eintrag oder eine Reise des Tages wählen",
+	"open it":                            "öffnen",
+	"highlighted: on a trip • •: journal entry":   "hervorgehoben: auf Reisen • •: Tagebucheintrag",
+	"Nothing on this day.":                        "An diesem Tag ist nichts.",
+	"←/→ day • ↑/↓ week • %s/%s month • %s today": "←/→ Tag • ↑/↓ Woche • %s/%s Monat • %s heute",
+	"On a trip: %s":       "Auf Reisen: %s",
+	"Journal entries: %s": "Tagebucheinträge: %s",
+	"none":                "keine",
+	"tab choose":          "tab wählen",
+	"enter open":          "enter öffnen",
+	"esc back":            "esc zurück",
+
+	// The palette.
+	"new expense, export lisbon, ramen": "neue ausgabe, exportieren lissabon, ramen",
+	"Untitled":                          "Ohne Titel",
+	"archived":                          "archiviert",
+	"New trip":                          "Neue Reise",
+	"Search the journal":                "Tagebuch durchsuchen",
+	"Quick note":                        "Schnellnotiz",
+	"Quick expense":                     "Schnelle Ausgabe",
+	"Switch theme":                      "Farbschema wechseln",
+	"Export trip":                       "Reise exportieren",
+	"Nothing matches.":                  "Keine Treffer.",
+	"%d of %d":                          "%d von %d",
+	"type to find • ↑/↓ move • enter open • esc back": "tippen zum Suchen • ↑/↓ bewegen • enter öffnen • esc zurück",
+	"previous match":   "vorheriger Treffer",
+	"next match":       "nächster Treffer",
+	"open it or do it": "öffnen oder ausführen",
+
+	// Profiles.
+	"Profiles":         "Profile",
+	"Choose a profile": "Profil wählen",
+	"current":          "aktuell",
+	"Already on %s":    "Schon auf %s",
+	"Add another with nomadic profile add work.":                        "Weitere mit nomadic profile add work hinzufügen.",
+	"↑/↓ move • enter switch, starting again on its journal • esc back": "↑/↓ bewegen • enter wechseln und mit seinem Tagebuch neu starten • esc zurück",
+
+	// Bulk edits.
+	"Bulk edit": "Mehrfachbearbeitung",
+
+	// The itinerary.
+	"%s, a public holiday in %s: museums and shops may close and transport be crowded": "%s, ein Feiertag in %s: Museen und Geschäfte können geschlossen und Verkehrsmittel voll sein",
+
+	// Lodging.
+	"Check out of %s by %s today":  "Heute bis %[2]s aus %[1]s auschecken",
+	"Check out of %s by %s":        "Bis %[2]s aus %[1]s auschecken",
+	"Check in at %s from %s today": "Heute ab %[2]s in %[1]s einchecken",
+	"Check in at %s from %s":       "Ab %[2]s in %[1]s einchecken",
+
+	// Wishlist.
+	"Planned":   "Geplant",
+	"high":      "hoch",
+	"medium":    "mittel",
+	"low":       "niedrig",
+	"best %s":   "am besten %s",
+	"in season": "jetzt die beste Zeit",
+
+	// The vault.
+	"expires %s":          "läuft am %s ab",
+	"expires today":       "läuft heute ab",
+	"expires tomorrow":    "läuft morgen ab",
+	"expires in %d days":  "läuft in %d Tagen ab",
+	"expired yesterday":   "gestern abgelaufen",
+	"expired %d days ago": "vor %d Tagen abgelaufen",
+	"before %s ends":      "vor dem Ende von %s",
+	"Number":              "Nummer",
+	"Issuer":              "Aussteller",
+	"For":                 "Für",
+	"Issued":              "Ausgestellt",
+	"Scan":                "Scan",
+	"Note":                "Notiz",
+
+	// Macros.
+	"New macro":         "Neues Makro",
+	"any screen":        "jeder Ansicht",
+	"Nothing recorded":  "Nichts aufgezeichnet",
+	"Stopped the macro": "Makro angehalten",
+	"Saved macro %s":    "Makro %s gespeichert",
+	"Macro %s: %s":      "Makro %s: %s",
+	"Macro %s was recorded on another screen":       "Makro %s wurde in einer anderen Ansicht aufgezeichnet",
+	"⏺ Recording a macro, %s to stop":               "⏺ Makro wird aufgezeichnet, %s zum Beenden",
+	"run macro %s":                                  "Makro %s abspielen",
+	"Run macro %s":                                  "Makro %s abspielen",
+	"Record a macro, or stop recording":             "Makro aufzeichnen oder Aufzeichnung beenden",
+	"record a macro, or stop recording and name it": "Makro aufzeichnen oder beenden und benennen",
+
+	// Date picker.
+	"previous or next day":   "vorheriger oder nächster Tag",
+	"previous or next week":  "vorherige oder nächste Woche",
+	"previous or next month": "vorheriger oder nächster Monat",
+	"alt+←/→ day • alt+↑/↓ week • pgup/pgdown month • such as tomorrow, fri or +3d": "alt+←/→ Tag • alt+↑/↓ Woche • pgup/pgdown Monat • etwa tomorrow, fri oder +3d",
+
+	// Achievements.
+	"Achievements":                  "Erfolge",
+	"Daily spend":                   "Ausgaben pro Tag",
+	"Achievement unlocked: %s — %s": "Erfolg freigeschaltet: %s — %s",
+	"unlocked %s":                   "freigeschaltet am %s",
+	"Globetrotter":                  "Globetrotter",
+	"Continental":                   "Kontinental",
+	"Chronicler":                    "Chronist",
+	"Devoted diarist":               "Treuer Tagebuchschreiber",
+	"continents":                    "Kontinente",
+	"days in a row":                 "Tage am Stück",
+
+	// Itinerary timing.
+	"%q is not today: press %s to log when it started and finished": "%q ist nicht heute: %s drücken, um Beginn und Ende einzutragen",
+	"%q finished at %s: press %s to change when":                    "%q endete um %s: %s drücken, um die Zeiten zu ändern",
+	"Started %q at %s":            "%q um %s begonnen",
+	"Finished %q at %s, after %s": "%q um %s beendet, nach %s",
+	"%s planned":                  "%s geplant",
+	"started at %s":               "begonnen um %s",
+	"took %s":                     "dauerte %s",
+	"took %s of %s":               "dauerte %s von %s",
+	"Planned %s, took %s":         "Geplant %s, gedauert %s",
+	"timed activity":              "gemessene Aktivität",
+	"timed activities":            "gemessene Aktivitäten",
+
+	// Error screen.
+	"Error":                   "Fehler",
+	"Something went wrong":    "Etwas ist schiefgegangen",
+	"Could not %s:":           "Konnte nicht %s:",
+	"read the receipt":        "den Beleg lesen",
+	"save the changes to git": "die Änderungen in Git sichern",
+	"undo or redo":            "rückgängig machen oder wiederholen",
+	"The journal could not be read or written. Check that the disk has room left and that no other program holds the data directory, then try again.": "Das Tagebuch konnte nicht gelesen oder geschrieben werden. Prüfe, ob auf der Festplatte noch Platz ist und kein anderes Programm das Datenverzeichnis belegt, und versuche es erneut.",
+	"The details are in %s, and in the debug console (%s).": "Die Einzelheiten stehen in %s und in der Debug-Konsole (%s).",
+}
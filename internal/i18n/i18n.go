@@ -0,0 +1,163 @@
+// Package i18n translates the TUI into the user's language and writes
+// numbers, amounts and dates the way their locale does. Strings are keyed
+// by their English text, a fmt format, so anything not translated yet
+// falls back to English.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+	"golang.org/x/text/number"
+)
+
+// Auto is the locale setting that follows the environment: $LC_ALL,
+// $LC_MESSAGES, then $LANG.
+const Auto = "auto"
+
+// translations holds every language shipped besides English, keyed by
+// the English text.
+var translations = map[language.Tag]map[string]string{
+	language.German: german,
+}
+
+var messages = func() *catalog.Builder {
+	b := catalog.NewBuilder(catalog.Fallback(language.English))
+	for tag, strs := range translations {
+		for key, s := range strs {
+			b.SetString(tag, key, s)
+		}
+	}
+	return b
+}()
+
+// Locale is a language and region to speak and format in.
+type Locale struct {
+	tag     language.Tag
+	printer *message.Printer
+	// translated is set when the interface has a translation for the
+	// language, so names must be looked up rather than left to Go.
+	translated bool
+}
+
+// English is the locale the TUI speaks until configured otherwise.
+var English = New(language.English)
+
+// New returns the locale of tag.
+func New(tag language.Tag) *Locale {
+	_, _, confidence := messages.Matcher().Match(tag)
+	base, _ := tag.Base()
+	english, _ := language.English.Base()
+	return &Locale{
+		tag:        tag,
+		printer:    message.NewPrinter(tag, message.Catalog(messages)),
+		translated: confidence != language.No && base != english,
+	}
+}
+
+// Parse reads a locale setting: Auto, or a BCP 47 tag such as "de" or
+// "en-GB". POSIX names such as "de_DE.UTF-8" are accepted too, as the
+// environment gives them. Auto reads the environment through getenv and
+// is English when it names nothing usable.
+func Parse(v string, getenv func(string) string) (*Locale, error) {
+	if v == "" || strings.EqualFold(v, Auto) {
+		for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if env := getenv(name); env != "" {
+				if tag, err := parseTag(env); err == nil {
+					return New(tag), nil
+				}
+				break
+			}
+		}
+		return English, nil
+	}
+	tag, err := parseTag(v)
+	if err != nil {
+		return nil, fmt.Errorf("locale %q is not auto or a language tag such as de or en-GB", v)
+	}
+	return New(tag), nil
+}
+
+// parseTag reads a BCP 47 tag or a POSIX locale name. The POSIX C locale
+// means English.
+func parseTag(v string) (language.Tag, error) {
+	v, _, _ = strings.Cut(v, ".")
+	v, _, _ = strings.Cut(v, "@")
+	if v == "C" || v == "POSIX" {
+		return language.English, nil
+	}
+	return language.Parse(strings.ReplaceAll(v, "_", "-"))
+}
+
+// String returns the locale's BCP 47 tag.
+func (l *Locale) String() string {
+	return l.tag.String()
+}
+
+// T translates key, an English fmt format, and fills it in with args.
+// Numbers among args are written the locale's way.
+func (l *Locale) T(key string, args ...any) string {
+	return l.printer.Sprintf(key, args...)
+}
+
+// Number writes v with the given number of decimals, grouping thousands
+// and separating decimals the locale's way.
+func (l *Locale) Number(v float64, decimals int) string {
+	return l.printer.Sprint(number.Decimal(v, number.Scale(decimals)))
+}
+
+// Amount writes an amount of money in a currency, such as "1.234,50 EUR"
+// in German.
+func (l *Locale) Amount(v float64, currency string) string {
+	return l.Number(v, 2) + " " + currency
+}
+
+// Month returns the name of month m.
+func (l *Locale) Month(m time.Month) string {
+	return l.T(m.String())
+}
+
+// Weekday returns the name of day d.
+func (l *Locale) Weekday(d time.Weekday) string {
+	return l.T(d.String())
+}
+
+// names are the layout elements Date translates, longest first so that
+// "January" is not taken for "Jan".
+var names = []string{"January", "Monday", "Jan", "Mon"}
+
+// Date formats t with a Go time layout, naming months and weekdays in the
+// locale's language.
+func (l *Locale) Date(t time.Time, layout string) string {
+	if !l.translated {
+		return t.Format(layout)
+	}
+	var b strings.Builder
+	for layout != "" {
+		at, name := len(layout), ""
+		for _, n := range names {
+			if i := strings.Index(layout, n); i >= 0 && (i < at || i == at && len(n) > len(name)) {
+				at, name = i, n
+			}
+		}
+		// Formatting piece by piece keeps the translated names from being
+		// read as layout elements themselves, as "Montag" holds "Mon".
+		b.WriteString(t.Format(layout[:at]))
+		switch name {
+		case "January":
+			b.WriteString(l.Month(t.Month()))
+		case "Jan":
+			b.WriteString(l.T(t.Format("Jan")))
+		case "Monday":
+			b.WriteString(l.Weekday(t.Weekday()))
+		case "Mon":
+			b.WriteString(l.T(t.Format("Mon")))
+		}
+		layout = layout[at+len(name):]
+	}
+	return b.String()
+}
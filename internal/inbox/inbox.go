@@ -0,0 +1,103 @@
+// Package inbox reads the receipts inbox: a folder, such as one a phone's
+// scanner app saves into, whose photos and PDFs of receipts wait to be
+// turned into expenses or dismissed. Files dealt with are moved into its
+// subfolders, filed or dismissed, rather than deleted, so nothing dropped
+// there is lost.
+package inbox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Subfolders of the inbox the files dealt with are moved into: those
+// turned into expenses, whose receipts are kept with them, and those
+// dismissed.
+const (
+	FiledDir     = "filed"
+	DismissedDir = "dismissed"
+)
+
+// exts are the extensions of the files taken for receipts.
+var exts = []string{".jpg", ".jpeg", ".png", ".heic", ".webp", ".gif", ".tif", ".tiff", ".bmp", ".pdf"}
+
+// Receipt is a receipt waiting in the inbox.
+type Receipt struct {
+	Path     string
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+// IsReceipt reports whether a file of the name is taken for a receipt: an
+// image or a PDF, not hidden, as files still being written by some apps
+// are.
+func IsReceipt(name string) bool {
+	return !strings.HasPrefix(name, ".") && slices.Contains(exts, strings.ToLower(filepath.Ext(name)))
+}
+
+// List returns the receipts waiting in dir, the oldest first. Those in its
+// subfolders have been dealt with already.
+func List(dir string) ([]Receipt, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("inbox: %w", err)
+	}
+	var receipts []Receipt
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !IsReceipt(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			// Moved away since the folder was read.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("inbox: %w", err)
+		}
+		receipts = append(receipts, Receipt{Path: filepath.Join(dir, e.Name()), Name: e.Name(), Size: info.Size(), Modified: info.ModTime()})
+	}
+	slices.SortStableFunc(receipts, func(a, b Receipt) int { return a.Modified.Compare(b.Modified) })
+	return receipts, nil
+}
+
+// File moves the receipt at path, turned into an expense, out of the inbox
+// dir into its filed subfolder, and returns where it went.
+func File(dir, path string) (string, error) {
+	return move(dir, path, FiledDir)
+}
+
+// Dismiss moves the receipt at path out of the inbox dir into its
+// dismissed subfolder, and returns where it went.
+func Dismiss(dir, path string) (string, error) {
+	return move(dir, path, DismissedDir)
+}
+
+// move moves path into the subfolder sub of dir, numbering its name when
+// one of the same name is there already, as "receipt-2.jpg".
+func move(dir, path, sub string) (string, error) {
+	to := filepath.Join(dir, sub)
+	if err := os.MkdirAll(to, 0o700); err != nil {
+		return "", fmt.Errorf("inbox: %w", err)
+	}
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	dst := filepath.Join(to, name)
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(dst); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		dst = filepath.Join(to, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), n, ext))
+	}
+	if err := os.Rename(path, dst); err != nil {
+		return "", fmt.Errorf("inbox: %w", err)
+	}
+	return dst, nil
+}
@@ -0,0 +1,528 @@
+// Package lansync lets two nomadic instances on the same network plan a
+// trip together: one hosts a session for a trip, announced over multicast
+// DNS, the other joins it with the code the host shows, and from then on
+// each sends the other the changes made to the trip's itinerary and
+// packing list, a second or so after they are made.
+//
+// The protocol is a line of JSON per message over TCP. Each side keeps the
+// version of each record it last sent or took from the other, which tells
+// a change it has yet to send from one the other made. A record both sides
+// changed meanwhile is settled by the host, which keeps whichever version
+// was changed last; a change made meanwhile outlives a deletion.
+package lansync
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// tick is how often each side looks for changes to send.
+const tick = time.Second
+
+// handshakeWait is how long either side waits for the other to greet it.
+const handshakeWait = 10 * time.Second
+
+// maxWrongCodes is how many wrong codes a host takes before it stops
+// listening, for the code is short enough to be guessed at given time.
+const maxWrongCodes = 5
+
+// ErrCode is returned when joining with a code the host did not show, and
+// ErrTooManyCodes by Accept once it has been given too many.
+var (
+	ErrCode         = errors.New("lansync: wrong code")
+	ErrTooManyCodes = errors.New("lansync: too many wrong codes; stopped listening")
+)
+
+// Kinds of records shared, as Ref.Kind names them.
+const (
+	KindItinerary = "itinerary"
+	KindPacking   = "packing"
+)
+
+// Ref names a record shared.
+type Ref struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+}
+
+// message is what goes over the connection. A hello opens the session
+// from each side, the joiner's with the code and the host's with the
+// trip; changes follow.
+type message struct {
+	Type      string                  `json:"type"`
+	Device    string                  `json:"device,omitempty"`
+	Code      string                  `json:"code,omitempty"`
+	Trip      *models.Trip            `json:"trip,omitempty"`
+	Itinerary []*models.ItineraryItem `json:"itinerary,omitempty"`
+	Packing   []*models.PackingItem   `json:"packing,omitempty"`
+	Deleted   []Ref                   `json:"deleted,omitempty"`
+	Error     string                  `json:"error,omitempty"`
+}
+
+// Event tells of a change taken from the partner.
+type Event struct {
+	Ref
+	// Title is the title of the itinerary item or the name of the packing
+	// item.
+	Title string
+	// Action is "added", "changed" or "removed".
+	Action string
+}
+
+// NewCode makes up the code a partner joins a session with.
+func NewCode() (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Conn is a session with a partner, greeted and ready to run.
+type Conn struct {
+	// Partner is the device name of the other side.
+	Partner string
+	// Trip is the trip the host shares.
+	Trip *models.Trip
+
+	conn net.Conn
+	dec  *json.Decoder
+	enc  *json.Encoder
+	host bool
+}
+
+// Accept waits on ln for a partner joining with code, turning away those
+// that give another, and greets it as device sharing trip. Partners are
+// greeted side by side, so one that says nothing holds up no other. After
+// maxWrongCodes wrong codes it closes ln and returns ErrTooManyCodes.
+func Accept(ctx context.Context, ln net.Listener, code, device string, trip *models.Trip) (c *Conn, err error) {
+	stop := context.AfterFunc(ctx, func() { ln.Close() })
+	defer stop()
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		pending = map[net.Conn]bool{}
+		joined  *Conn
+		wrong   int
+	)
+	// wake breaks off waiting for the next connection.
+	wake := func() {
+		if d, ok := ln.(deadliner); ok {
+			d.SetDeadline(time.Now())
+		} else {
+			ln.Close()
+		}
+	}
+	defer func() {
+		mu.Lock()
+		for nc := range pending {
+			nc.Close()
+		}
+		mu.Unlock()
+		wg.Wait()
+		if joined != nil && joined != c {
+			joined.Close()
+		}
+		if d, ok := ln.(deadliner); ok {
+			d.SetDeadline(time.Time{})
+		}
+	}()
+	for {
+		nc, err := ln.Accept()
+		mu.Lock()
+		c, tooMany := joined, wrong >= maxWrongCodes
+		mu.Unlock()
+		if (c != nil || tooMany) && err == nil {
+			nc.Close()
+		}
+		switch {
+		case c != nil:
+			return c, nil
+		case tooMany:
+			ln.Close()
+			return nil, ErrTooManyCodes
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		mu.Lock()
+		pending[nc] = true
+		mu.Unlock()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := greet(nc, code, device, trip)
+			mu.Lock()
+			defer mu.Unlock()
+			delete(pending, nc)
+			switch {
+			case errors.Is(err, ErrCode):
+				if wrong++; wrong == maxWrongCodes {
+					wake()
+				}
+			case err != nil:
+			case joined != nil:
+				c.Close()
+			default:
+				joined = c
+				wake()
+			}
+		}()
+	}
+}
+
+// deadliner is a listener whose Accept can be made to give up, as a
+// *net.TCPListener.
+type deadliner interface {
+	SetDeadline(time.Time) error
+}
+
+// greet takes the hello of a partner joining over nc and answers it as
+// device sharing trip, if the partner gives code.
+func greet(nc net.Conn, code, device string, trip *models.Trip) (*Conn, error) {
+	c := newConn(nc, true)
+	nc.SetDeadline(time.Now().Add(handshakeWait))
+	var hello message
+	if err := c.dec.Decode(&hello); err != nil || hello.Type != "hello" {
+		nc.Close()
+		return nil, errors.New("lansync: not greeted")
+	}
+	if subtle.ConstantTimeCompare([]byte(hello.Code), []byte(code)) != 1 {
+		c.enc.Encode(message{Type: "error", Error: ErrCode.Error()})
+		nc.Close()
+		return nil, ErrCode
+	}
+	if err := c.enc.Encode(message{Type: "hello", Device: device, Trip: trip}); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	nc.SetDeadline(time.Time{})
+	c.Partner, c.Trip = hello.Device, trip
+	return c, nil
+}
+
+// Dial joins the session at addr with code as device.
+func Dial(ctx context.Context, addr, code, device string) (*Conn, error) {
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	c := newConn(nc, false)
+	nc.SetDeadline(time.Now().Add(handshakeWait))
+	if err := c.enc.Encode(message{Type: "hello", Device: device, Code: code}); err != nil {
+		nc.Close()
+		return nil, err
+	}
+	var hello message
+	if err := c.dec.Decode(&hello); err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("lansync: greet %s: %w", addr, err)
+	}
+	if hello.Type == "error" {
+		nc.Close()
+		if hello.Error == ErrCode.Error() {
+			return nil, ErrCode
+		}
+		return nil, errors.New(hello.Error)
+	}
+	if hello.Type != "hello" || hello.Trip == nil {
+		nc.Close()
+		return nil, fmt.Errorf("lansync: %s is not a nomadic session", addr)
+	}
+	nc.SetDeadline(time.Time{})
+	c.Partner, c.Trip = hello.Device, hello.Trip
+	return c, nil
+}
+
+func newConn(nc net.Conn, host bool) *Conn {
+	return &Conn{conn: nc, dec: json.NewDecoder(bufio.NewReader(nc)), enc: json.NewEncoder(nc), host: host}
+}
+
+// Close ends the session.
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Run keeps the itinerary and packing list of the trip tripID in store in
+// step with the partner's until ctx is done or the partner leaves, when it
+// returns nil. Each change taken from the partner is told to report.
+func (c *Conn) Run(ctx context.Context, store *storage.Store, tripID string, report func(Event)) error {
+	stop := context.AfterFunc(ctx, func() { c.conn.Close() })
+	defer stop()
+	s := &session{store: store, trip: tripID, sent: map[Ref]string{}, host: c.host}
+
+	incoming := make(chan message)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			var m message
+			if err := c.dec.Decode(&m); err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case incoming <- m:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	t := time.NewTicker(tick)
+	defer t.Stop()
+	for {
+		out, err := s.changes(ctx)
+		if err != nil {
+			return err
+		}
+		if out != nil {
+			if err := c.enc.Encode(out); err != nil {
+				return c.ended(ctx, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errc:
+			return c.ended(ctx, err)
+		case m := <-incoming:
+			if m.Type != "changes" {
+				continue
+			}
+			if err := s.take(ctx, m, report); err != nil {
+				return err
+			}
+		case <-t.C:
+		}
+	}
+}
+
+// ended is what Run returns for err, which broke off the session: nil
+// when it was only the partner leaving or ctx ending it.
+func (c *Conn) ended(ctx context.Context, err error) error {
+	if ctx.Err() != nil || errors.Is(err, net.ErrClosed) || errors.Is(err, io.EOF) {
+		return nil
+	}
+	return fmt.Errorf("lansync: %w", err)
+}
+
+// session is one side's view of what the two sides share.
+type session struct {
+	store *storage.Store
+	trip  string
+	host  bool
+	// sent holds the fingerprint of each record as it was last sent to
+	// or taken from the partner.
+	sent map[Ref]string
+}
+
+// record is a shared record as it is now, with its fingerprint.
+type record struct {
+	item    *models.ItineraryItem
+	packing *models.PackingItem
+	print   string
+}
+
+// updated is when the record was last changed.
+func (r record) updated() time.Time {
+	if r.item != nil {
+		return r.item.UpdatedAt
+	}
+	return r.packing.UpdatedAt
+}
+
+// local reads the trip's shared records as they are now.
+func (s *session) local(ctx context.Context) (map[Ref]record, error) {
+	items, err := s.store.ListItineraryByTrip(ctx, s.trip)
+	if err != nil {
+		return nil, err
+	}
+	packing, err := s.store.ListPackingByTrip(ctx, s.trip)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[Ref]record, len(items)+len(packing))
+	for _, it := range items {
+		out[Ref{KindItinerary, it.ID}] = record{item: it, print: itemPrint(it)}
+	}
+	for _, it := range packing {
+		out[Ref{KindPacking, it.ID}] = record{packing: it, print: packingPrint(it)}
+	}
+	return out, nil
+}
+
+// changes is the message with what changed since it was last sent, or
+// nil when nothing did.
+func (s *session) changes(ctx context.Context) (*message, error) {
+	local, err := s.local(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m := &message{Type: "changes"}
+	for ref, r := range local {
+		if s.sent[ref] == r.print {
+			continue
+		}
+		if r.item != nil {
+			m.Itinerary = append(m.Itinerary, r.item)
+		} else {
+			m.Packing = append(m.Packing, r.packing)
+		}
+		s.sent[ref] = r.print
+	}
+	for ref := range s.sent {
+		if _, ok := local[ref]; !ok {
+			m.Deleted = append(m.Deleted, ref)
+			delete(s.sent, ref)
+		}
+	}
+	if len(m.Itinerary)+len(m.Packing)+len(m.Deleted) == 0 {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// take applies the partner's changes, but for those to records of other
+// trips. A record changed here too since it was last sent is left for the
+// next changes to send instead, unless this is the host and the partner's
+// version is the later; one the same on both sides is left alone.
+func (s *session) take(ctx context.Context, m message, report func(Event)) error {
+	local, err := s.local(ctx)
+	if err != nil {
+		return err
+	}
+	keep := func(ref Ref, print string, theirs time.Time) bool {
+		sent, wasSent := s.sent[ref]
+		mine, ok := local[ref]
+		if ok && mine.print == print {
+			s.sent[ref] = print
+			return true
+		}
+		if !ok {
+			return wasSent // deleted here, yet to be sent
+		}
+		if wasSent && mine.print == sent {
+			return false
+		}
+		return !s.host || !theirs.After(mine.updated())
+	}
+	for _, it := range m.Itinerary {
+		ref := Ref{KindItinerary, it.ID}
+		if keep(ref, itemPrint(it), it.UpdatedAt) {
+			continue
+		}
+		_, existed := local[ref]
+		if elsewhere, err := s.elsewhere(ctx, ref); err != nil {
+			return err
+		} else if elsewhere {
+			continue
+		}
+		it.TripID = s.trip
+		if err := s.store.SaveItineraryItem(ctx, it); err != nil {
+			return err
+		}
+		s.sent[ref] = itemPrint(it)
+		report(Event{Ref: ref, Title: it.Title, Action: action(existed)})
+	}
+	for _, it := range m.Packing {
+		ref := Ref{KindPacking, it.ID}
+		if keep(ref, packingPrint(it), it.UpdatedAt) {
+			continue
+		}
+		_, existed := local[ref]
+		if elsewhere, err := s.elsewhere(ctx, ref); err != nil {
+			return err
+		} else if elsewhere {
+			continue
+		}
+		it.TripID = s.trip
+		if err := s.store.SavePackingItem(ctx, it); err != nil {
+			return err
+		}
+		s.sent[ref] = packingPrint(it)
+		report(Event{Ref: ref, Title: it.Name, Action: action(existed)})
+	}
+	for _, ref := range m.Deleted {
+		mine, ok := local[ref]
+		sent, wasSent := s.sent[ref]
+		delete(s.sent, ref)
+		if !ok || !wasSent || mine.print != sent {
+			continue
+		}
+		var (
+			title string
+			err   error
+		)
+		if mine.item != nil {
+			title = mine.item.Title
+			err = s.store.DeleteItineraryItem(ctx, ref.ID)
+		} else {
+			title = mine.packing.Name
+			err = s.store.DeletePackingItem(ctx, ref.ID)
+		}
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+		report(Event{Ref: ref, Title: title, Action: "removed"})
+	}
+	return nil
+}
+
+// elsewhere reports whether the record ref is on another trip than the
+// one shared, which a partner sending it has no business changing.
+func (s *session) elsewhere(ctx context.Context, ref Ref) (bool, error) {
+	var (
+		trip string
+		err  error
+	)
+	if ref.Kind == KindItinerary {
+		var it *models.ItineraryItem
+		if it, err = s.store.GetItineraryItem(ctx, ref.ID); err == nil {
+			trip = it.TripID
+		}
+	} else {
+		var it *models.PackingItem
+		if it, err = s.store.GetPackingItem(ctx, ref.ID); err == nil {
+			trip = it.TripID
+		}
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil && trip != s.trip, err
+}
+
+func action(existed bool) string {
+	if existed {
+		return "changed"
+	}
+	return "added"
+}
+
+// itemPrint and packingPrint fingerprint what a partner may change of a
+// record: not its trip, which each side knows by its own ID, nor when it
+// was created or saved.
+func itemPrint(it *models.ItineraryItem) string {
+	return fmt.Sprintf("%q %s %q %q %q %q %q %d %d %q %q %q %d", it.ID, it.Day.UTC().Format(time.RFC3339), it.Time, it.Title,
+		it.Place, it.Notes, it.BookingRef, it.Alarm, it.Duration, it.Kind, it.Started, it.Finished, it.Position)
+}
+
+func packingPrint(it *models.PackingItem) string {
+	return fmt.Sprintf("%q %q %q %t %d", it.ID, it.Category, it.Name, it.Packed, it.Position)
+}
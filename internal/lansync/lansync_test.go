@@ -0,0 +1,160 @@
+package lansync
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+func TestTakeLeavesOtherTripsAlone(t *testing.T) {
+	ctx := t.Context()
+	store, err := storage.Open(ctx, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	day := time.Date(2025, 4, 2, 0, 0, 0, 0, time.UTC)
+	shared, other := models.NewTrip("Japan", nil, day), models.NewTrip("Peru", nil, day)
+	for _, tr := range []*models.Trip{shared, other} {
+		if err := store.SaveTrip(ctx, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	item := models.NewItineraryItem(other.ID, day, "Machu Picchu")
+	if err := store.SaveItineraryItem(ctx, item); err != nil {
+		t.Fatal(err)
+	}
+	bag := models.NewPackingItem(other.ID, "Gear", "Boots")
+	if err := store.SavePackingItem(ctx, bag); err != nil {
+		t.Fatal(err)
+	}
+
+	// The partner sends records with the IDs of those on the other trip,
+	// and a new one.
+	stolen := *item
+	stolen.Title, stolen.TripID = "Shibuya", "theirs"
+	swapped := *bag
+	swapped.Name = "Sandals"
+	added := models.NewItineraryItem("theirs", day, "Senso-ji")
+	var events []Event
+	s := &session{store: store, trip: shared.ID, sent: map[Ref]string{}}
+	m := message{Type: "changes", Itinerary: []*models.ItineraryItem{&stolen, added}, Packing: []*models.PackingItem{&swapped}}
+	if err := s.take(ctx, m, func(e Event) { events = append(events, e) }); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := store.GetItineraryItem(ctx, item.ID); err != nil || got.TripID != other.ID || got.Title != item.Title {
+		t.Errorf("the other trip's item is now %+v, %v", got, err)
+	}
+	if got, err := store.GetPackingItem(ctx, bag.ID); err != nil || got.TripID != other.ID || got.Name != bag.Name {
+		t.Errorf("the other trip's packing item is now %+v, %v", got, err)
+	}
+	if got, err := store.GetItineraryItem(ctx, added.ID); err != nil || got.TripID != shared.ID {
+		t.Errorf("the item added is %+v, %v; want it on the trip shared", got, err)
+	}
+	if len(events) != 1 || events[0].ID != added.ID {
+		t.Errorf("events %+v, want only the item added", events)
+	}
+}
+
+// join dials ln and gives code, returning what the host answers.
+func join(t *testing.T, ln net.Listener, code string) message {
+	t.Helper()
+	nc, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nc.Close()
+	nc.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := json.NewEncoder(nc).Encode(message{Type: "hello", Device: "phone", Code: code}); err != nil {
+		t.Fatal(err)
+	}
+	var answer message
+	json.NewDecoder(nc).Decode(&answer)
+	return answer
+}
+
+func TestAcceptIsNotHeldUpBySilentPartners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	silent, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+
+	trip := models.NewTrip("Japan", nil, time.Now())
+	done := make(chan *Conn, 1)
+	go func() {
+		c, err := Accept(t.Context(), ln, "c0de", "laptop", trip)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- c
+	}()
+	start := time.Now()
+	if answer := join(t, ln, "c0de"); answer.Type != "hello" || answer.Trip == nil || answer.Trip.ID != trip.ID {
+		t.Fatalf("answered %+v", answer)
+	}
+	if waited := time.Since(start); waited >= handshakeWait {
+		t.Errorf("greeted after %v, behind the silent partner", waited)
+	}
+	if c := <-done; c == nil || c.Partner != "phone" {
+		t.Errorf("accepted %+v", c)
+	} else {
+		c.Close()
+	}
+
+	// The host waits on the same listener for the partner to join again.
+	go func() {
+		c, err := Accept(t.Context(), ln, "c0de", "laptop", trip)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- c
+	}()
+	if answer := join(t, ln, "c0de"); answer.Type != "hello" {
+		t.Fatalf("answered the partner joining again with %+v", answer)
+	}
+	if c := <-done; c != nil {
+		c.Close()
+	}
+}
+
+func TestAcceptStopsAfterWrongCodes(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	errc := make(chan error, 1)
+	go func() {
+		_, err := Accept(t.Context(), ln, "c0de", "laptop", models.NewTrip("Japan", nil, time.Now()))
+		errc <- err
+	}()
+	for range maxWrongCodes {
+		if answer := join(t, ln, "beef"); answer.Error != ErrCode.Error() {
+			t.Fatalf("answered a wrong code with %+v", answer)
+		}
+	}
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrTooManyCodes) {
+			t.Errorf("Accept returned %v, want ErrTooManyCodes", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("still listening after the wrong codes")
+	}
+	if nc, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+		nc.Close()
+		t.Error("the listener is still open")
+	}
+}
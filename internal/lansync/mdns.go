@@ -0,0 +1,217 @@
+package lansync
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// service is the DNS-SD service type a hosted session is announced as.
+const service = "_nomadic._tcp.local."
+
+// mdnsAddr is where multicast DNS queries are sent and answered.
+var mdnsAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// Peer is a session found on the network.
+type Peer struct {
+	// Name is the host's device name.
+	Name string
+	// Addr is where to join it, host:port.
+	Addr string
+	// Trip is the title of the trip it shares.
+	Trip string
+}
+
+// Advertise answers multicast DNS queries for nomadic sessions with the
+// one hosted at port by device, sharing trip, until ctx is done. Queries
+// are answered directly to whoever asked, as browsing asks from a port of
+// its own.
+func Advertise(ctx context.Context, device string, port int, trip string) error {
+	conn, err := net.ListenMulticastUDP("udp4", nil, mdnsAddr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	instance := instanceName(device)
+	host := dnsmessage.MustNewName(label(device) + ".local.")
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		var p dnsmessage.Parser
+		h, err := p.Start(buf[:n])
+		if err != nil || h.Response {
+			continue
+		}
+		q, err := p.Question()
+		if err != nil || !strings.EqualFold(q.Name.String(), service) || (q.Type != dnsmessage.TypePTR && q.Type != dnsmessage.TypeALL) {
+			continue
+		}
+		reply, err := answer(h.ID, q, instance, host, port, trip)
+		if err != nil {
+			continue
+		}
+		conn.WriteToUDP(reply, from)
+	}
+}
+
+// answer builds the reply to question q naming the session: a pointer to
+// the instance, with where it listens and what it shares alongside.
+func answer(id uint16, q dnsmessage.Question, instance, host dnsmessage.Name, port int, trip string) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, Response: true, Authoritative: true})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(q); err != nil {
+		return nil, err
+	}
+	if err := b.StartAnswers(); err != nil {
+		return nil, err
+	}
+	rh := func(name dnsmessage.Name) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: 120}
+	}
+	if err := b.PTRResource(rh(q.Name), dnsmessage.PTRResource{PTR: instance}); err != nil {
+		return nil, err
+	}
+	if err := b.StartAdditionals(); err != nil {
+		return nil, err
+	}
+	if err := b.SRVResource(rh(instance), dnsmessage.SRVResource{Port: uint16(port), Target: host}); err != nil {
+		return nil, err
+	}
+	if len(trip) > 250 {
+		trip = trip[:250]
+	}
+	if err := b.TXTResource(rh(instance), dnsmessage.TXTResource{TXT: []string{"trip=" + trip}}); err != nil {
+		return nil, err
+	}
+	return b.Finish()
+}
+
+// Browse asks the network for sessions being hosted and returns those
+// that answer within wait.
+func Browse(ctx context.Context, wait time.Duration) ([]Peer, error) {
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: 1})
+	b.StartQuestions()
+	b.Question(dnsmessage.Question{Name: dnsmessage.MustNewName(service), Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET})
+	query, err := b.Finish()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.WriteToUDP(query, mdnsAddr); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(wait)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetReadDeadline(deadline)
+
+	var peers []Peer
+	seen := map[string]bool{}
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		var timeout net.Error
+		if errors.As(err, &timeout) && timeout.Timeout() {
+			return peers, nil
+		}
+		if err != nil {
+			return peers, err
+		}
+		peer, ok := parsePeer(buf[:n], from.IP)
+		if ok && !seen[peer.Addr] {
+			seen[peer.Addr] = true
+			peers = append(peers, peer)
+		}
+	}
+}
+
+// parsePeer reads the session a reply names, at the address it came from.
+func parsePeer(msg []byte, ip net.IP) (Peer, bool) {
+	var p dnsmessage.Parser
+	if h, err := p.Start(msg); err != nil || !h.Response {
+		return Peer{}, false
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return Peer{}, false
+	}
+	var (
+		peer Peer
+		port uint16
+	)
+	read := func(next func() (dnsmessage.ResourceHeader, error), skip func() error) {
+		for {
+			h, err := next()
+			if err != nil {
+				return
+			}
+			switch h.Type {
+			case dnsmessage.TypePTR:
+				r, err := p.PTRResource()
+				if err == nil && strings.EqualFold(h.Name.String(), service) {
+					peer.Name = strings.TrimSuffix(r.PTR.String(), "."+service)
+				}
+			case dnsmessage.TypeSRV:
+				if r, err := p.SRVResource(); err == nil {
+					port = r.Port
+				}
+			case dnsmessage.TypeTXT:
+				if r, err := p.TXTResource(); err == nil {
+					for _, kv := range r.TXT {
+						if v, ok := strings.CutPrefix(kv, "trip="); ok {
+							peer.Trip = v
+						}
+					}
+				}
+			default:
+				skip()
+			}
+		}
+	}
+	read(p.AnswerHeader, p.SkipAnswer)
+	p.SkipAllAuthorities()
+	read(p.AdditionalHeader, p.SkipAdditional)
+	if peer.Name == "" || port == 0 {
+		return Peer{}, false
+	}
+	peer.Addr = net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+	return peer, true
+}
+
+// instanceName is the DNS-SD instance of the session hosted by device.
+func instanceName(device string) dnsmessage.Name {
+	return dnsmessage.MustNewName(label(device) + "." + service)
+}
+
+// label makes name a single DNS label: no dots, at most 63 bytes.
+func label(name string) string {
+	name = strings.ReplaceAll(strings.TrimSpace(name), ".", "-")
+	if name == "" {
+		name = "nomadic"
+	}
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return name
+}
@@ -0,0 +1,286 @@
+// Package logging records what nomadic does with log/slog: into a log file
+// in the data directory, rotated as it grows, and into memory, where the
+// TUI's debug console reads the latest records back.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the log file inside the data directory. Rotated
+// files carry a number after it, nomadic.log.1 the newest.
+const FileName = "nomadic.log"
+
+const (
+	// MaxSize is how large the log file grows before it is rotated.
+	MaxSize = 1 << 20
+	// Keep is how many rotated log files are kept.
+	Keep = 3
+	// RecentSize is how many records are kept in memory.
+	RecentSize = 500
+)
+
+// Record is a record kept in memory.
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Attrs are the record's attributes as key=value pairs.
+	Attrs string
+}
+
+// String writes the record on one line, as the log file does.
+func (r Record) String() string {
+	s := r.Time.Format("15:04:05.000") + " " + fmt.Sprintf("%-5s", r.Level) + " " + r.Message
+	if r.Attrs != "" {
+		s += " " + r.Attrs
+	}
+	return s
+}
+
+// Logger writes records at its level and above to its file, when it has
+// one, and keeps the latest in memory.
+type Logger struct {
+	*slog.Logger
+	level  slog.Level
+	file   *rotatingFile
+	recent *ring
+}
+
+// New returns a logger of records at Info and above, or from Debug on when
+// debug is set, writing to the log file in dir. With dir empty it only
+// keeps records in memory.
+func New(dir string, debug bool) (*Logger, error) {
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	l := &Logger{level: level, recent: &ring{}}
+	var text slog.Handler
+	if dir != "" {
+		f, err := openRotating(filepath.Join(dir, FileName), MaxSize, Keep)
+		if err != nil {
+			return nil, err
+		}
+		l.file = f
+		text = slog.NewTextHandler(f, &slog.HandlerOptions{Level: level})
+	}
+	l.Logger = slog.New(&handler{level: level, text: text, recent: l.recent})
+	return l, nil
+}
+
+// Verbose reports whether the logger records Debug records.
+func (l *Logger) Verbose() bool {
+	return l.level <= slog.LevelDebug
+}
+
+// Path returns the log file, or empty when records are only kept in
+// memory.
+func (l *Logger) Path() string {
+	if l.file == nil {
+		return ""
+	}
+	return l.file.path
+}
+
+// Recent returns the records kept in memory, oldest first.
+func (l *Logger) Recent() []Record {
+	return l.recent.records()
+}
+
+// Close closes the log file.
+func (l *Logger) Close() error {
+	if l.file == nil {
+		return nil
+	}
+	return l.file.Close()
+}
+
+// handler hands records to the text handler of the log file and keeps
+// them in recent.
+type handler struct {
+	level  slog.Level
+	text   slog.Handler
+	recent *ring
+	// attrs are those added with WithAttrs, written as key=value, and
+	// group prefixes the keys of attributes added after WithGroup.
+	attrs string
+	group string
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(&b, h.group, a)
+		return true
+	})
+	h.recent.add(Record{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: strings.TrimSpace(b.String())})
+	if h.text == nil {
+		return nil
+	}
+	return h.text.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	var b strings.Builder
+	b.WriteString(h.attrs)
+	for _, a := range attrs {
+		writeAttr(&b, h.group, a)
+	}
+	c.attrs = b.String()
+	if h.text != nil {
+		c.text = h.text.WithAttrs(attrs)
+	}
+	return &c
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.group = h.group + name + "."
+	if h.text != nil {
+		c.text = h.text.WithGroup(name)
+	}
+	return &c
+}
+
+// writeAttr writes a as " key=value", with the keys of groups prefixed by
+// their names.
+func writeAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			writeAttr(b, prefix, ga)
+		}
+		return
+	}
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	s := v.String()
+	if strings.ContainsAny(s, " =\"") || s == "" {
+		s = fmt.Sprintf("%q", s)
+	}
+	fmt.Fprintf(b, " %s%s=%s", prefix, a.Key, s)
+}
+
+// ring holds the latest RecentSize records.
+type ring struct {
+	mu   sync.Mutex
+	buf  []Record
+	next int
+}
+
+func (r *ring) add(rec Record) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.buf) < RecentSize {
+		r.buf = append(r.buf, rec)
+		return
+	}
+	r.buf[r.next] = rec
+	r.next = (r.next + 1) % RecentSize
+}
+
+func (r *ring) records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Record, 0, len(r.buf))
+	out = append(out, r.buf[r.next:]...)
+	return append(out, r.buf[:r.next]...)
+}
+
+// rotatingFile appends to the file at path, and once it would grow past
+// max moves it to path.1, path.1 to path.2 and so on, keeping keep of them.
+type rotatingFile struct {
+	mu   sync.Mutex
+	path string
+	max  int64
+	keep int
+	f    *os.File
+	size int64
+}
+
+func openRotating(path string, size int64, keep int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, max: size, keep: keep}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("logging: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("logging: %w", err)
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.max {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate moves the file out of the way and starts a new one.
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return fmt.Errorf("logging: %w", err)
+	}
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.keep))
+	for i := r.keep - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.keep > 0 {
+		os.Rename(r.path, r.path+".1")
+	} else {
+		os.Remove(r.path)
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// Attachment is a file, usually a photo, attached to a journal entry.
+// Path is relative to the attachments directory of the data directory.
+type Attachment struct {
+	ID        string    `json:"id"`
+	EntryID   string    `json:"entry_id"`
+	Name      string    `json:"name"` // original file name
+	Path      string    `json:"path"`
+	Linked    bool      `json:"linked,omitempty"` // a symlink rather than a copy
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
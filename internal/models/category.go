@@ -0,0 +1,111 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Category is an expense category. The built-in ones are there from the
+// start; the user adds their own, renames and reorders them, and merges
+// those they no longer want into others.
+type Category struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Icon is an emoji shown before the name, if any.
+	Icon string `json:"icon,omitempty"`
+	// Colour is how the name is drawn in the TUI, an ANSI number (0-255)
+	// or #rgb/#rrggbb, or empty for the theme's.
+	Colour    string    `json:"colour,omitempty"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewCategory creates a category called name.
+func NewCategory(name, icon, colour string) *Category {
+	now := time.Now()
+	return &Category{
+		ID:        NewID(),
+		Name:      strings.ToLower(strings.TrimSpace(name)),
+		Icon:      strings.TrimSpace(icon),
+		Colour:    strings.TrimSpace(colour),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Label is the category's icon and name, as lists show it.
+func (c *Category) Label() string {
+	if c.Icon == "" {
+		return c.Name
+	}
+	return c.Icon + " " + c.Name
+}
+
+// DefaultCategories are the built-in categories, in the order they are
+// first listed.
+var DefaultCategories = []*Category{
+	{Name: CategoryFood, Icon: "🍽️"},
+	{Name: CategoryTransport, Icon: "🚆"},
+	{Name: CategoryLodging, Icon: "🏨"},
+	{Name: CategoryActivities, Icon: "🎟️"},
+	{Name: CategoryShopping, Icon: "🛍️"},
+	{Name: CategoryOther, Icon: "📦"},
+}
+
+// CheckCategoryName rejects names that would not survive being typed in a
+// quick expense or a flag: categories are single words.
+func CheckCategoryName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("a name is required")
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' {
+			return fmt.Errorf("category %q must be one word of letters, digits, - and _", name)
+		}
+	}
+	return nil
+}
+
+// categoriesByName indexes what UseCategories was last given.
+var categoriesByName map[string]*Category
+
+// UseCategories makes list, in order, the categories offered and accepted
+// everywhere: it replaces Categories and what LookupCategory finds. The
+// store calls it on opening and whenever the categories change.
+func UseCategories(list []*Category) {
+	names := make([]string, len(list))
+	byName := make(map[string]*Category, len(list))
+	for i, c := range list {
+		names[i] = c.Name
+		byName[c.Name] = c
+	}
+	Categories, categoriesByName = names, byName
+}
+
+// LookupCategory returns the category called name, or nil when there is
+// none.
+func LookupCategory(name string) *Category {
+	if categoriesByName == nil {
+		for _, c := range DefaultCategories {
+			if c.Name == name {
+				return c
+			}
+		}
+		return nil
+	}
+	return categoriesByName[name]
+}
+
+// CategoryLabel is the icon and name of the category called name, or the
+// name alone when it has no icon or is not a category.
+func CategoryLabel(name string) string {
+	if c := LookupCategory(name); c != nil {
+		return c.Label()
+	}
+	return name
+}
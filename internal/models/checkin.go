@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// CheckIn records being at a place during a trip, at a moment, such as a
+// landmark visited. Check-ins are shown on the days of the itinerary.
+type CheckIn struct {
+	ID     string `json:"id"`
+	TripID string `json:"trip_id"`
+	Place  string `json:"place"`
+	Note   string `json:"note,omitempty"`
+	// Lat and Lon are the place's position, from the places dataset, a
+	// geocoder or given by hand; both are 0 when it is not known.
+	Lat float64 `json:"lat,omitempty"`
+	Lon float64 `json:"lon,omitempty"`
+	// Timestamp is read in TimeZone, the IANA time zone of the place.
+	Timestamp time.Time `json:"timestamp"`
+	TimeZone  string    `json:"time_zone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewCheckIn creates a check-in at place at the moment at.
+func NewCheckIn(tripID, place string, at time.Time) *CheckIn {
+	return &CheckIn{
+		ID:        NewID(),
+		TripID:    tripID,
+		Place:     strings.TrimSpace(place),
+		Timestamp: at,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Located reports whether the check-in's position is known.
+func (c *CheckIn) Located() bool { return c.Lat != 0 || c.Lon != 0 }
+
+// Coordinates formats the position as "34.6873°N 135.5259°E", or "" when
+// it is not known.
+func (c *CheckIn) Coordinates() string {
+	if !c.Located() {
+		return ""
+	}
+	ns, ew := "N", "E"
+	if c.Lat < 0 {
+		ns = "S"
+	}
+	if c.Lon < 0 {
+		ew = "W"
+	}
+	return fmt.Sprintf("%.4f°%s %.4f°%s", math.Abs(c.Lat), ns, math.Abs(c.Lon), ew)
+}
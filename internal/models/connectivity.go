@@ -0,0 +1,71 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Connectivity is how a country was to get online in: the eSIM or SIM
+// card used and what it cost, how fast it was and the Wi-Fi spots worth
+// going back to. It is noted on a leg of a trip, or on the country alone,
+// and shown again when a trip or leg goes there.
+type Connectivity struct {
+	ID string `json:"id"`
+	// Country is the ISO 3166-1 alpha-2 code of the country.
+	Country string `json:"country"`
+	// TripID and LegID are the trip and leg it was noted on, if any, and
+	// Place the city or region there, such as "Kyoto".
+	TripID string `json:"trip_id,omitempty"`
+	LegID  string `json:"leg_id,omitempty"`
+	Place  string `json:"place,omitempty"`
+	// Provider is the eSIM or SIM card used, such as "Ubigi 10 GB".
+	Provider string `json:"provider,omitempty"`
+	// Cost is what it cost, in Currency, or 0 when not noted.
+	Cost     float64 `json:"cost,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+	// Speed notes how fast and reliable it was, such as "5G in cities,
+	// nothing on the Nakasendo".
+	Speed string `json:"speed,omitempty"`
+	// WiFi are the Wi-Fi spots worth knowing, such as "Tokyo Station
+	// lounge".
+	WiFi      []string  `json:"wifi,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewConnectivity creates an empty note on getting online in the country
+// with an ISO 3166-1 alpha-2 code.
+func NewConnectivity(country string) *Connectivity {
+	now := time.Now()
+	return &Connectivity{
+		ID:        NewID(),
+		Country:   strings.ToUpper(strings.TrimSpace(country)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Summary puts the provider, its cost and the speed on one line, as shown
+// when a trip or leg goes to the country.
+func (c *Connectivity) Summary() string {
+	var parts []string
+	if c.Provider != "" {
+		parts = append(parts, c.Provider)
+	}
+	if c.Cost > 0 {
+		parts = append(parts, fmt.Sprintf("%.2f %s", c.Cost, c.Currency))
+	}
+	if c.Speed != "" {
+		parts = append(parts, c.Speed)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Mentions reports whether word is in the place, provider, speed, Wi-Fi
+// spots or notes, compared without case.
+func (c *Connectivity) Mentions(word string) bool {
+	text := strings.Join(append([]string{c.Place, c.Provider, c.Speed, c.Notes}, c.WiFi...), " ")
+	return strings.Contains(strings.ToLower(text), strings.ToLower(word))
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CountryNote is what to know before going to a country, kept for when a
+// trip or leg goes there: its visa and entry rules, how long they allow to
+// stay, and tips such as where to buy a SIM card.
+type CountryNote struct {
+	// Country is the ISO 3166-1 alpha-2 code of the country, one note
+	// each.
+	Country string `json:"country"`
+	Visa    string `json:"visa,omitempty"`
+	// MaxStay is the longest stay the entry rules allow in days, or 0 when
+	// not noted.
+	MaxStay   int       `json:"max_stay,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewCountryNote creates an empty note on the country with an ISO 3166-1
+// alpha-2 code.
+func NewCountryNote(country string) *CountryNote {
+	now := time.Now()
+	return &CountryNote{
+		Country:   strings.ToUpper(strings.TrimSpace(country)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Summary puts the visa rules and the longest stay on one line, as shown
+// when a trip or leg goes to the country.
+func (n *CountryNote) Summary() string {
+	var parts []string
+	if n.Visa != "" {
+		parts = append(parts, "visa: "+n.Visa)
+	}
+	if n.MaxStay > 0 {
+		parts = append(parts, fmt.Sprintf("stay up to %d days", n.MaxStay))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Document is a file kept with a trip, such as a boarding pass, a booking
+// confirmation or an insurance policy, and with one item of its itinerary
+// when ItemID is set, or the receipt of one of its expenses when ExpenseID
+// is. Path is relative to the documents directory of the
+// data directory.
+type Document struct {
+	ID          string    `json:"id"`
+	TripID      string    `json:"trip_id"`
+	ItemID      string    `json:"item_id,omitempty"`
+	ExpenseID   string    `json:"expense_id,omitempty"`
+	Title       string    `json:"title"`
+	Name        string    `json:"name"` // original file name
+	ContentType string    `json:"content_type,omitempty"`
+	Path        string    `json:"path"`
+	Linked      bool      `json:"linked,omitempty"` // a symlink rather than a copy
+	Size        int64     `json:"size"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Kinds of screens a draft is restored into.
+const (
+	DraftEntry     = "entry"
+	DraftTrip      = "trip"
+	DraftExpense   = "expense"
+	DraftItinerary = "itinerary"
+	DraftLeg       = "leg"
+	DraftLodging   = "lodging"
+)
+
+// Draft is unsaved work in the journal editor or a form, saved as it is
+// typed so it survives the terminal closing before the work is saved.
+type Draft struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// Title describes the draft when it is offered back.
+	Title string `json:"title"`
+	IsNew bool   `json:"is_new,omitempty"`
+	// Record is the record being edited, as it was before the edits, or
+	// for new trips the template they are created from.
+	Record json.RawMessage `json:"record,omitempty"`
+	// Values hold what was typed, one per field of the screen.
+	Values  []string  `json:"values"`
+	SavedAt time.Time `json:"saved_at"`
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// IsLikelyDuplicate reports whether a and b look like the same purchase
+// recorded twice: on the same trip and day, for the same amount to the cent
+// in the same currency, with similar descriptions.
+func IsLikelyDuplicate(a, b *Expense) bool {
+	if a.ID != "" && a.ID == b.ID {
+		return false
+	}
+	ay, am, ad := InZone(a.Timestamp, a.TimeZone).Date()
+	by, bm, bd := InZone(b.Timestamp, b.TimeZone).Date()
+	return a.TripID == b.TripID &&
+		ay == by && am == bm && ad == bd &&
+		math.Round(a.Amount*100) == math.Round(b.Amount*100) &&
+		strings.EqualFold(a.Currency, b.Currency) &&
+		similarDescriptions(a, b)
+}
+
+// similarDescriptions reports whether two descriptions name the same
+// thing: alike once case, punctuation and spacing are set aside, one
+// holding the other's words, or sharing most of their words. An expense
+// described only by its category, as one recorded without a description
+// is, is like any other of that category.
+func similarDescriptions(a, b *Expense) bool {
+	if bareDescription(a) || bareDescription(b) {
+		return a.Category == b.Category
+	}
+	da, db := descriptionWords(a.Description), descriptionWords(b.Description)
+	ja, jb := " "+strings.Join(da, " ")+" ", " "+strings.Join(db, " ")+" "
+	if strings.Contains(ja, jb) || strings.Contains(jb, ja) {
+		return true
+	}
+	shared := 0
+	for _, w := range da {
+		if slices.Contains(db, w) {
+			shared++
+		}
+	}
+	return shared*2 >= max(len(da), len(db))
+}
+
+// bareDescription reports whether x is described by no more than its
+// category.
+func bareDescription(x *Expense) bool {
+	words := descriptionWords(x.Description)
+	return len(words) == 0 || len(words) == 1 && words[0] == x.Category
+}
+
+// descriptionWords splits a description into lower-case words, leaving out
+// punctuation.
+func descriptionWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// MergeExpense folds what dup records and x does not into x, as when dup
+// is a duplicate of x about to be dropped: a note, tags, the merchant, the
+// location, the leg, the recurrence, how it was split, its tax and tip and
+// a description saying more than its category. It reports whether x changed.
+func MergeExpense(x, dup *Expense) bool {
+	changed := false
+	fill := func(dst *string, src string) {
+		if *dst == "" && src != "" {
+			*dst, changed = src, true
+		}
+	}
+	if dup.Note != "" && x.Note != "" && !strings.Contains(x.Note, dup.Note) {
+		x.Note, changed = x.Note+"\n"+dup.Note, true
+	}
+	fill(&x.Note, dup.Note)
+	fill(&x.Merchant, dup.Merchant)
+	fill(&x.Location, dup.Location)
+	fill(&x.LegID, dup.LegID)
+	fill(&x.RecurrenceID, dup.RecurrenceID)
+	if bareDescription(x) && !bareDescription(dup) {
+		x.Description, changed = dup.Description, true
+	}
+	if len(x.Shares) == 0 && len(dup.Shares) > 0 {
+		x.Shares, changed = dup.Shares, true
+	}
+	if x.Tax == 0 && x.Tip == 0 && dup.Tax+dup.Tip > 0 && CheckBreakdown(x.Amount, dup.Tax, dup.Tip) == nil {
+		x.Tax, x.Tip, changed = dup.Tax, dup.Tip, true
+	}
+	for _, t := range dup.Tags {
+		if !slices.Contains(x.Tags, t) {
+			x.Tags, changed = append(x.Tags, t), true
+		}
+	}
+	return changed
+}
+
+// DuplicateGroups gathers expenses that are likely duplicates of each
+// other, each group in the order the expenses came in. An expense joins a
+// group when it is a likely duplicate of its first expense.
+func DuplicateGroups(expenses []*Expense) [][]*Expense {
+	var groups [][]*Expense
+	grouped := map[string]bool{}
+	for i, x := range expenses {
+		if grouped[x.ID] {
+			continue
+		}
+		group := []*Expense{x}
+		for _, y := range expenses[i+1:] {
+			if !grouped[y.ID] && IsLikelyDuplicate(x, y) {
+				group = append(group, y)
+				grouped[y.ID] = true
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
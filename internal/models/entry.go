@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/pkg/weather"
+)
+
+// Entry represents a journal entry in a trip. Text holds Markdown.
+type Entry struct {
+	ID     string `json:"id"`
+	TripID string `json:"trip_id"`
+	// LegID is the leg of the trip the entry was written on, if any.
+	LegID string   `json:"leg_id,omitempty"`
+	Title string   `json:"title"`
+	Text  string   `json:"text"`
+	Tags  []string `json:"tags,omitempty"`
+	// Timestamp is read in TimeZone, the IANA time zone of where the entry
+	// was written, so its calendar day is the one of the trip. Entries
+	// from before time zones were recorded have none and are read in the
+	// local zone.
+	Timestamp time.Time `json:"timestamp"`
+	TimeZone  string    `json:"time_zone,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	// Weather is the weather of the entry's day at its location, recorded
+	// when a weather provider is configured.
+	Weather *weather.Day `json:"weather,omitempty"`
+	// Mood is how the day felt, from 1 (awful) to MaxRating (great), or 0
+	// when not rated.
+	Mood int `json:"mood,omitempty"`
+	// People names the trip's companions the entry was written with.
+	People []string `json:"people,omitempty"`
+	// Public marks the entry for the website of nomadic publish, even when
+	// its trip is not public.
+	Public bool `json:"public,omitempty"`
+	// Private marks a personal entry, which redacted exports strip out or
+	// mask; see Privacy and Redact.
+	Private   bool      `json:"private,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewEntry creates a new journal entry
+func NewEntry(tripID, text string, timestamp time.Time) *Entry {
+	now := time.Now()
+	return &Entry{
+		ID:        NewID(),
+		TripID:    tripID,
+		Text:      text,
+		Timestamp: timestamp,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// AppendNote adds note to the end of the entry's text as a line stamped
+// with the time of day at, as quick capture writes them. Notes follow each
+// other line by line, and the text written before them a paragraph apart.
+func (e *Entry) AppendNote(note string, at time.Time) {
+	line := "- **" + at.Format("15:04") + "** " + strings.TrimSpace(note)
+	text := strings.TrimRight(e.Text, "\n\t ")
+	switch {
+	case text == "":
+		e.Text = line
+	case strings.HasPrefix(text[strings.LastIndex(text, "\n")+1:], "- **"):
+		e.Text = text + "\n" + line
+	default:
+		e.Text = text + "\n\n" + line
+	}
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// The built-in expense categories. Other is where expenses go that fit no
+// other category, and cannot be renamed or removed.
+const (
+	CategoryFood       = "food"
+	CategoryTransport  = "transport"
+	CategoryLodging    = "lodging"
+	CategoryActivities = "activities"
+	CategoryShopping   = "shopping"
+	CategoryOther      = "other"
+)
+
+// Categories lists the names of the expense categories in display order:
+// the built-in ones until UseCategories is given the user's.
+var Categories = []string{
+	CategoryFood,
+	CategoryTransport,
+	CategoryLodging,
+	CategoryActivities,
+	CategoryShopping,
+	CategoryOther,
+}
+
+// Expense represents a financial expense during a trip
+type Expense struct {
+	ID     string `json:"id"`
+	TripID string `json:"trip_id"`
+	// LegID is the leg of the trip the money was spent on, if any.
+	LegID    string  `json:"leg_id,omitempty"`
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+	// Tax and Tip are the parts of Amount that went on tax and on a tip,
+	// when the receipt tells; the rest is the base price, Base.
+	Tax         float64  `json:"tax,omitempty"`
+	Tip         float64  `json:"tip,omitempty"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Note        string   `json:"note,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	// Location is the city where the money was spent, if noted; its time
+	// zone is the expense's.
+	Location string `json:"location,omitempty"`
+	// PaidBy is the companion who paid, or empty when Me did.
+	PaidBy string `json:"paid_by,omitempty"`
+	// Shares splits the amount among the people it was spent on. Without
+	// shares the expense is the payer's own.
+	Shares []Share `json:"shares,omitempty"`
+	// Merchant is the transaction text of an expense imported from a bank
+	// statement, or the shop read from its receipt, which categorization
+	// rules match.
+	Merchant string `json:"merchant,omitempty"`
+	// RecurrenceID is the recurrence the expense was recorded from, if
+	// any.
+	RecurrenceID string `json:"recurrence_id,omitempty"`
+	// Rate is how many units of RateCurrency one unit of Currency bought
+	// on the day of the expense, locked in so converted totals stay what
+	// the money was worth then. It is zero while no rate is locked.
+	Rate         float64 `json:"rate,omitempty"`
+	RateCurrency string  `json:"rate_currency,omitempty"`
+	// Timestamp is read in TimeZone, the IANA time zone of where the money
+	// was spent, or the local zone when it has none, like an entry's.
+	Timestamp time.Time `json:"timestamp"`
+	TimeZone  string    `json:"time_zone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// In returns the amount in currency: at the locked rate when there is one
+// into currency, and else converted with convert, which may be nil when no
+// exchange rates could be had.
+func (x *Expense) In(currency string, convert func(amount float64, from, to string) (float64, error)) (float64, error) {
+	switch {
+	case strings.EqualFold(x.Currency, currency):
+		return x.Amount, nil
+	case x.Rate > 0 && strings.EqualFold(x.RateCurrency, currency):
+		return x.Amount * x.Rate, nil
+	case convert == nil:
+		return 0, fmt.Errorf("no exchange rate from %s to %s", x.Currency, currency)
+	}
+	return convert(x.Amount, x.Currency, currency)
+}
+
+// Locked reports whether the expense has a rate locked into currency, or
+// needs none, being in it.
+func (x *Expense) Locked(currency string) bool {
+	return strings.EqualFold(x.Currency, currency) || x.Rate > 0 && strings.EqualFold(x.RateCurrency, currency)
+}
+
+// Base is the amount before tax and tip.
+func (x *Expense) Base() float64 {
+	return x.Amount - x.Tax - x.Tip
+}
+
+// CheckBreakdown checks that tax and tip can be parts of amount: neither
+// negative, and together no more than it.
+func CheckBreakdown(amount, tax, tip float64) error {
+	switch {
+	case tax < 0:
+		return errors.New("tax cannot be negative")
+	case tip < 0:
+		return errors.New("tip cannot be negative")
+	case math.Round((tax+tip)*100) > math.Round(amount*100):
+		return fmt.Errorf("tax and tip come to more than the amount of %.2f", amount)
+	}
+	return nil
+}
+
+// NewExpense creates a new expense record
+func NewExpense(tripID string, amount float64, currency, category, description string, timestamp time.Time) *Expense {
+	now := time.Now()
+	return &Expense{
+		ID:          NewID(),
+		TripID:      tripID,
+		Amount:      amount,
+		Currency:    currency,
+		Category:    category,
+		Description: description,
+		Timestamp:   timestamp,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// ParseCategory resolves v to a known category, accepting any unambiguous
+// prefix so "tr" selects "transport".
+func ParseCategory(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return "", errors.New("category is required")
+	}
+	var match string
+	for _, c := range Categories {
+		if c == v {
+			return c, nil
+		}
+		if strings.HasPrefix(c, v) {
+			if match != "" {
+				return "", fmt.Errorf("%q matches more than one category", v)
+			}
+			match = c
+		}
+	}
+	if match == "" {
+		return "", fmt.Errorf("unknown category %q; choose one of %s", v, strings.Join(Categories, ", "))
+	}
+	return match, nil
+}
+
+// ParseCurrency validates a three-letter ISO 4217 code and returns it upper-cased.
+func ParseCurrency(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if len(v) != 3 {
+		return "", errors.New("enter a three-letter currency code, e.g. EUR")
+	}
+	for _, r := range v {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return "", errors.New("enter a three-letter currency code, e.g. EUR")
+		}
+	}
+	return strings.ToUpper(v), nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// HealthDay is how a day of a trip went for the body: the hours slept the
+// night before, the litres of water drunk and how jetlagged it felt, from
+// 1 (not at all) to MaxRating (badly). Day is in DateLayout, the trip's
+// calendar day; a trip has at most one HealthDay a day. Zero values are
+// what was not logged.
+type HealthDay struct {
+	TripID    string    `json:"trip_id"`
+	Day       string    `json:"day"`
+	Sleep     float64   `json:"sleep,omitempty"`
+	Water     float64   `json:"water,omitempty"`
+	Jetlag    int       `json:"jetlag,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CheckHealth reports whether the hours slept, litres drunk and jetlag of
+// a day are possible.
+func CheckHealth(sleep, water float64, jetlag int) error {
+	if sleep < 0 || sleep > 24 {
+		return fmt.Errorf("sleep %g is not from 0 to 24 hours", sleep)
+	}
+	if water < 0 || water > 20 {
+		return fmt.Errorf("water %g is not from 0 to 20 litres", water)
+	}
+	if jetlag < 0 || jetlag > MaxRating {
+		return fmt.Errorf("jetlag %d is not from 1 (none) to %d (badly), or 0 for none logged", jetlag, MaxRating)
+	}
+	return nil
+}
+
+// Empty reports whether nothing is logged for the day.
+func (h *HealthDay) Empty() bool {
+	return h.Sleep == 0 && h.Water == 0 && h.Jetlag == 0 && h.Note == ""
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Highlight is something to do on a trip, a goal, until it is checked off
+// as done; done, it is a highlight of the trip, something that happened.
+// It may link to the journal entry EntryID that tells of it. Highlights
+// are shown in Position order.
+type Highlight struct {
+	ID        string    `json:"id"`
+	TripID    string    `json:"trip_id"`
+	Title     string    `json:"title"`
+	Done      bool      `json:"done"`
+	EntryID   string    `json:"entry_id,omitempty"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewHighlight creates a goal of a trip, not yet done.
+func NewHighlight(tripID, title string) *Highlight {
+	now := time.Now()
+	return &Highlight{
+		ID:        NewID(),
+		TripID:    tripID,
+		Title:     strings.TrimSpace(title),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// HighlightProgress counts the goals done and all of them.
+func HighlightProgress(highlights []*Highlight) (done, total int) {
+	for _, h := range highlights {
+		if h.Done {
+			done++
+		}
+	}
+	return done, len(highlights)
+}
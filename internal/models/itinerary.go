@@ -0,0 +1,241 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ItineraryItem is one scheduled activity on a day of a trip. Items on the
+// same day are shown in Position order.
+type ItineraryItem struct {
+	ID         string    `json:"id"`
+	TripID     string    `json:"trip_id"`
+	Day        time.Time `json:"day"`
+	Time       string    `json:"time,omitempty"` // "15:04", empty when unscheduled
+	Title      string    `json:"title"`
+	Place      string    `json:"place,omitempty"`
+	Notes      string    `json:"notes,omitempty"`
+	BookingRef string    `json:"booking_ref,omitempty"`
+	Alarm      int       `json:"alarm,omitempty"` // minutes before Time to be reminded, 0 for none
+	// Duration is how many minutes the activity is planned to take, 0
+	// when not planned.
+	Duration int `json:"duration,omitempty"`
+	// Kind is the kind of activity, such as "museum" or "hike", by which
+	// what it took is compared across trips.
+	Kind string `json:"kind,omitempty"`
+	// Started and Finished are when the activity actually started and
+	// finished on its day, as "15:04" in the time zone of the place, empty
+	// until it has. A finish before the start is on the next day.
+	Started   string    `json:"started,omitempty"`
+	Finished  string    `json:"finished,omitempty"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TimeLayout is the layout of ItineraryItem.Time.
+const TimeLayout = "15:04"
+
+// NewItineraryItem creates an activity scheduled on day.
+func NewItineraryItem(tripID string, day time.Time, title string) *ItineraryItem {
+	now := time.Now()
+	return &ItineraryItem{
+		ID:        NewID(),
+		TripID:    tripID,
+		Day:       day,
+		Title:     title,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// alarmUnits are the units of an alarm, in minutes.
+var alarmUnits = map[byte]int{'m': 1, 'h': 60, 'd': 24 * 60, 'w': 7 * 24 * 60}
+
+// ParseAlarm reads how long before an item to be reminded, such as "30m",
+// "2h", "1h30m" or "1d", as minutes. An empty string means no alarm.
+func ParseAlarm(v string) (int, error) {
+	minutes, ok := parseMinutes(v)
+	if !ok {
+		return 0, fmt.Errorf("alarm %q is not like 30m, 2h or 1d", v)
+	}
+	return minutes, nil
+}
+
+// ParseDuration reads how long an item is planned to take, such as "45m",
+// "2h" or "1h30m", as minutes. An empty string means not planned.
+func ParseDuration(v string) (int, error) {
+	minutes, ok := parseMinutes(v)
+	if !ok {
+		return 0, fmt.Errorf("duration %q is not like 45m, 2h or 1h30m", v)
+	}
+	return minutes, nil
+}
+
+func parseMinutes(v string) (int, bool) {
+	s := strings.ToLower(strings.ReplaceAll(v, " ", ""))
+	minutes := 0
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, false
+		}
+		n, _ := strconv.Atoi(s[:i])
+		unit, ok := alarmUnits[s[i]]
+		if !ok {
+			return 0, false
+		}
+		minutes += n * unit
+		s = s[i+1:]
+	}
+	return minutes, true
+}
+
+// FormatAlarm writes minutes as ParseAlarm reads them, such as "1h30m".
+func FormatAlarm(minutes int) string {
+	if minutes <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, unit := range []byte{'d', 'h', 'm'} {
+		if n := minutes / alarmUnits[unit]; n > 0 {
+			fmt.Fprintf(&b, "%d%c", n, unit)
+			minutes -= n * alarmUnits[unit]
+		}
+	}
+	return b.String()
+}
+
+// FormatDuration writes minutes as ParseDuration reads them. Negative
+// minutes, such as an activity finishing early, get a minus sign.
+func FormatDuration(minutes int) string {
+	if minutes < 0 {
+		return "-" + FormatAlarm(-minutes)
+	}
+	if minutes == 0 {
+		return "0m"
+	}
+	return FormatAlarm(minutes)
+}
+
+// Took returns how many minutes the activity took, from Started to
+// Finished, and false until both are known.
+func (it *ItineraryItem) Took() (int, bool) {
+	start, err := time.Parse(TimeLayout, it.Started)
+	if err != nil {
+		return 0, false
+	}
+	end, err := time.Parse(TimeLayout, it.Finished)
+	if err != nil {
+		return 0, false
+	}
+	if end.Before(start) {
+		end = end.Add(24 * time.Hour)
+	}
+	return int(end.Sub(start).Minutes()), true
+}
+
+// Overran returns how many minutes longer than planned the activity took,
+// negative when it took less, and false unless both are known.
+func (it *ItineraryItem) Overran() (int, bool) {
+	took, ok := it.Took()
+	if !ok || it.Duration <= 0 {
+		return 0, false
+	}
+	return took - it.Duration, true
+}
+
+// LateBy returns how many minutes after its Time the activity started,
+// negative when it started early, and false unless both are known. Starts
+// within twelve hours either side of Time count, across midnight too.
+func (it *ItineraryItem) LateBy() (int, bool) {
+	planned, err := time.Parse(TimeLayout, it.Time)
+	if err != nil {
+		return 0, false
+	}
+	start, err := time.Parse(TimeLayout, it.Started)
+	if err != nil {
+		return 0, false
+	}
+	late := int(start.Sub(planned).Minutes())
+	switch {
+	case late > 12*60:
+		late -= 24 * 60
+	case late <= -12*60:
+		late += 24 * 60
+	}
+	return late, true
+}
+
+// KindTiming sums up the activities of a kind that were planned to take a
+// while and timed: how long they were planned to take and took, in minutes,
+// and how many of them ran over.
+type KindTiming struct {
+	Kind    string
+	Items   int
+	Planned int
+	Took    int
+	Over    int
+	// Scheduled is how many of them had a Time, and Late how many minutes
+	// in all they started after it, less those they started early.
+	Scheduled int
+	Late      int
+}
+
+// Ratio is how long the activities took for each minute planned.
+func (k KindTiming) Ratio() float64 {
+	if k.Planned == 0 {
+		return 0
+	}
+	return float64(k.Took) / float64(k.Planned)
+}
+
+// TimingByKind sums up the timed activities of items by their Kind, those
+// without one as "other", those running over most first.
+func TimingByKind(items []*ItineraryItem) []KindTiming {
+	byKind := map[string]*KindTiming{}
+	for _, it := range items {
+		over, ok := it.Overran()
+		if !ok {
+			continue
+		}
+		kind := it.Kind
+		if kind == "" {
+			kind = "other"
+		}
+		k := byKind[kind]
+		if k == nil {
+			k = &KindTiming{Kind: kind}
+			byKind[kind] = k
+		}
+		took, _ := it.Took()
+		k.Items++
+		k.Planned += it.Duration
+		k.Took += took
+		if over > 0 {
+			k.Over++
+		}
+		if late, ok := it.LateBy(); ok {
+			k.Scheduled++
+			k.Late += late
+		}
+	}
+	out := make([]KindTiming, 0, len(byKind))
+	for _, k := range byKind {
+		out = append(out, *k)
+	}
+	slices.SortFunc(out, func(a, b KindTiming) int {
+		return cmp.Or(cmp.Compare(b.Ratio(), a.Ratio()), cmp.Compare(a.Kind, b.Kind))
+	})
+	return out
+}
+
+// NormalizeKind is the Kind an activity described as kind is of: trimmed
+// and lower-case, so "Museum " and "museum" compare alike.
+func NormalizeKind(kind string) string {
+	return strings.ToLower(strings.TrimSpace(kind))
+}
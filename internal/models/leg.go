@@ -0,0 +1,112 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transport modes a leg can be reached by.
+const (
+	TransportFlight = "flight"
+	TransportTrain  = "train"
+	TransportBus    = "bus"
+	TransportCar    = "car"
+	TransportFerry  = "ferry"
+	TransportBike   = "bike"
+	TransportWalk   = "walk"
+	TransportOther  = "other"
+)
+
+// Transports lists the transport modes in display order.
+var Transports = []string{
+	TransportFlight,
+	TransportTrain,
+	TransportBus,
+	TransportCar,
+	TransportFerry,
+	TransportBike,
+	TransportWalk,
+	TransportOther,
+}
+
+// Leg is one stop of a multi-destination trip: a place, the days spent
+// there and how the traveler got there. A trip's legs are ordered by
+// arrival.
+type Leg struct {
+	ID        string     `json:"id"`
+	TripID    string     `json:"trip_id"`
+	Location  string     `json:"location"`
+	Arrival   time.Time  `json:"arrival"`
+	Departure *time.Time `json:"departure,omitempty"`
+	// Transport is how the leg is reached, one of Transports or empty.
+	Transport string    `json:"transport,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewLeg creates a leg of a trip arriving at location on arrival.
+func NewLeg(tripID, location string, arrival time.Time) *Leg {
+	now := time.Now()
+	return &Leg{
+		ID:        NewID(),
+		TripID:    tripID,
+		Location:  location,
+		Arrival:   arrival,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Covers reports whether the traveler is at the leg on the calendar day
+// of t: from its arrival to its departure, both included.
+func (l *Leg) Covers(t time.Time) bool {
+	day := civilDay(t)
+	if day.Before(civilDay(l.Arrival)) {
+		return false
+	}
+	return l.Departure == nil || !day.After(civilDay(*l.Departure))
+}
+
+// LegOn returns the leg being visited on the calendar day of t, or nil.
+// On a travel day, when one leg is left and the next reached, the leg
+// arrived at wins.
+func LegOn(legs []*Leg, t time.Time) *Leg {
+	var on *Leg
+	for _, l := range legs {
+		if l.Covers(t) && (on == nil || !l.Arrival.Before(on.Arrival)) {
+			on = l
+		}
+	}
+	return on
+}
+
+// ParseTransport resolves v to a known transport mode, accepting any
+// unambiguous prefix so "fl" selects "flight". Empty stays empty.
+func ParseTransport(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return "", nil
+	}
+	var match string
+	for _, m := range Transports {
+		if m == v {
+			return m, nil
+		}
+		if strings.HasPrefix(m, v) {
+			if match != "" {
+				return "", fmt.Errorf("%q matches more than one transport", v)
+			}
+			match = m
+		}
+	}
+	if match == "" {
+		return "", fmt.Errorf("unknown transport %q; choose one of %s", v, strings.Join(Transports, ", "))
+	}
+	return match, nil
+}
+
+// civilDay is the calendar day of t as a comparable UTC midnight.
+func civilDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
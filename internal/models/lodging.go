@@ -0,0 +1,147 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The times of day a room is usually ready from and must be left by, for
+// bookings that name none.
+const (
+	DefaultCheckInTime  = "15:00"
+	DefaultCheckOutTime = "11:00"
+)
+
+// Lodging is somewhere to stay on a trip, such as a hotel or a rental,
+// booked from its check-in to its check-out. Its cost is recorded as the
+// expense ExpenseID of the trip, which the store keeps in step with it.
+type Lodging struct {
+	ID     string `json:"id"`
+	TripID string `json:"trip_id"`
+	// LegID is the leg of the trip it is stayed at on, if any.
+	LegID        string  `json:"leg_id,omitempty"`
+	Name         string  `json:"name"`
+	Address      string  `json:"address,omitempty"`
+	Confirmation string  `json:"confirmation,omitempty"`
+	Cost         float64 `json:"cost,omitempty"`
+	Currency     string  `json:"currency,omitempty"`
+	// CheckIn is when the room is ready and CheckOut when it must be
+	// left, both read in TimeZone, the IANA time zone of the place, or
+	// the local zone when it has none.
+	CheckIn   time.Time `json:"check_in"`
+	CheckOut  time.Time `json:"check_out"`
+	TimeZone  string    `json:"time_zone,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+	ExpenseID string    `json:"expense_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewLodging creates a stay of a trip at name from checkIn to checkOut.
+func NewLodging(tripID, name string, checkIn, checkOut time.Time) *Lodging {
+	now := time.Now()
+	return &Lodging{
+		ID:        NewID(),
+		TripID:    tripID,
+		Name:      strings.TrimSpace(name),
+		CheckIn:   checkIn,
+		CheckOut:  checkOut,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate reports what is missing or wrong with the stay.
+func (l *Lodging) Validate() error {
+	switch {
+	case l.Name == "":
+		return errors.New("a lodging needs a name")
+	case !l.CheckOut.After(l.CheckIn):
+		return errors.New("check-out must be after check-in")
+	case l.Cost < 0:
+		return errors.New("cost must not be negative")
+	case l.Cost > 0 && l.Currency == "":
+		return errors.New("a cost needs its currency")
+	}
+	return nil
+}
+
+// Nights counts the nights booked.
+func (l *Lodging) Nights() int {
+	return int(civilDay(l.CheckOut).Sub(civilDay(l.CheckIn)).Hours() / 24)
+}
+
+// LodgingTime is the time clock, as HH:MM, on the calendar day of day in
+// the time zone named zone.
+func LodgingTime(day time.Time, clock, zone string) (time.Time, error) {
+	c, err := time.Parse(TimeLayout, strings.TrimSpace(clock))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use HH:MM, such as 15:00", clock)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), c.Hour(), c.Minute(), 0, 0, Zone(zone)), nil
+}
+
+// CostExpense returns x, or a new expense when x is nil, recording the
+// cost of the stay on its trip and leg, on the day of check-in. The
+// category of an expense already recorded is left as it was filed, and a
+// rate locked in is dropped once the currency or day it was for changes.
+func (l *Lodging) CostExpense(x *Expense) *Expense {
+	if x == nil {
+		category := CategoryLodging
+		if !slices.Contains(Categories, category) {
+			category = CategoryOther
+		}
+		x = NewExpense(l.TripID, 0, l.Currency, category, "", l.CheckIn)
+	}
+	if x.Currency != l.Currency || civilDay(x.Timestamp) != civilDay(l.CheckIn) {
+		x.Rate, x.RateCurrency = 0, ""
+	}
+	x.TripID, x.LegID = l.TripID, l.LegID
+	x.Amount, x.Currency = l.Cost, l.Currency
+	x.Description = l.Name
+	x.Location = l.Address
+	x.Timestamp, x.TimeZone = l.CheckIn, l.TimeZone
+	return x
+}
+
+// LodgingNotice is a check-in or check-out due on a day.
+type LodgingNotice struct {
+	Lodging  *Lodging
+	CheckOut bool
+}
+
+// At is the time of the check-in or check-out.
+func (n LodgingNotice) At() time.Time {
+	if n.CheckOut {
+		return n.Lodging.CheckOut
+	}
+	return n.Lodging.CheckIn
+}
+
+// String tells what is due, as in "Check out of Hotel Gracery by 11:00".
+func (n LodgingNotice) String() string {
+	if n.CheckOut {
+		return fmt.Sprintf("Check out of %s by %s", n.Lodging.Name, n.At().Format(TimeLayout))
+	}
+	return fmt.Sprintf("Check in at %s from %s", n.Lodging.Name, n.At().Format(TimeLayout))
+}
+
+// LodgingNotices returns the check-outs and check-ins due on the calendar
+// day of day, in the order they fall.
+func LodgingNotices(lodgings []*Lodging, day time.Time) []LodgingNotice {
+	var out []LodgingNotice
+	for _, l := range lodgings {
+		if civilDay(l.CheckOut) == civilDay(day) {
+			out = append(out, LodgingNotice{Lodging: l, CheckOut: true})
+		}
+		if civilDay(l.CheckIn) == civilDay(day) {
+			out = append(out, LodgingNotice{Lodging: l})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At().Format(TimeLayout) < out[j].At().Format(TimeLayout) })
+	return out
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// PackingItem is one thing to bring on a trip. Items are grouped by
+// Category and shown in Position order within it.
+type PackingItem struct {
+	ID        string    `json:"id"`
+	TripID    string    `json:"trip_id"`
+	Category  string    `json:"category,omitempty"`
+	Name      string    `json:"name"`
+	Packed    bool      `json:"packed"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewPackingItem creates an unpacked item for a trip.
+func NewPackingItem(tripID, category, name string) *PackingItem {
+	now := time.Now()
+	return &PackingItem{
+		ID:        NewID(),
+		TripID:    tripID,
+		Category:  category,
+		Name:      name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// PackingList is a reusable master list of things to pack, such as
+// "Essentials" or "Ski gear", that can be added to any trip.
+type PackingList struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Items     []PackingListItem `json:"items"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// PackingListItem is an entry of a master list or template, without the
+// per-trip state.
+type PackingListItem struct {
+	Category string `json:"category,omitempty"`
+	Name     string `json:"name"`
+}
+
+// UncategorizedPacking is the heading shown for items without a category.
+const UncategorizedPacking = "Other"
+
+// ParsePackingItem splits "Category: name" into its parts. Without a colon
+// the whole value is the name and the category is empty.
+func ParsePackingItem(v string) PackingListItem {
+	if category, name, ok := strings.Cut(v, ":"); ok && strings.TrimSpace(category) != "" {
+		return PackingListItem{Category: strings.TrimSpace(category), Name: strings.TrimSpace(name)}
+	}
+	return PackingListItem{Name: strings.TrimSpace(v)}
+}
+
+// PackingListItems strips a trip's packing list down to what is worth
+// reusing.
+func PackingListItems(items []*PackingItem) []PackingListItem {
+	out := make([]PackingListItem, len(items))
+	for i, it := range items {
+		out[i] = PackingListItem{Category: it.Category, Name: it.Name}
+	}
+	return out
+}
+
+// PackingFor creates the items of list as unpacked items of the trip
+// tripID, keeping their order within each category.
+func PackingFor(tripID string, list []PackingListItem) []*PackingItem {
+	items := make([]*PackingItem, 0, len(list))
+	positions := make(map[string]int)
+	for _, li := range list {
+		it := NewPackingItem(tripID, li.Category, li.Name)
+		it.Position = positions[li.Category]
+		positions[li.Category]++
+		items = append(items, it)
+	}
+	return items
+}
+
+// PackingProgress counts the packed items and all items.
+func PackingProgress(items []*PackingItem) (packed, total int) {
+	for _, it := range items {
+		if it.Packed {
+			packed++
+		}
+	}
+	return packed, len(items)
+}
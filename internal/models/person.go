@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Person is someone the traveler goes on trips with. Trips list their
+// companions, expenses their payers and shares, and entries who they were
+// written with all by name; a person keeps what a name alone cannot, and
+// renaming one renames them everywhere.
+type Person struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Contact is how to reach the person, such as a phone number or an
+	// email address.
+	Contact   string    `json:"contact,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewPerson creates a person called name.
+func NewPerson(name string) *Person {
+	now := time.Now()
+	return &Person{
+		ID:        NewID(),
+		Name:      strings.TrimSpace(name),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// CheckPersonName rejects the names that would be ambiguous in a split.
+func CheckPersonName(name string) error {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return errors.New("a name is required")
+	case strings.EqualFold(name, Me), strings.EqualFold(name, SplitAll):
+		return fmt.Errorf("%q is reserved and cannot name a companion", name)
+	case strings.ContainsAny(name, ",="):
+		return fmt.Errorf("companion %q must not contain a comma or =", name)
+	}
+	return nil
+}
+
+// ParseWith reads who an entry was written with: names among the trip's
+// companions, found without regard to case.
+func ParseWith(names, companions []string) ([]string, error) {
+	var out []string
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n == "" {
+			continue
+		}
+		if len(companions) == 0 {
+			return nil, fmt.Errorf("%q is not on the trip; add companions to the trip first", n)
+		}
+		person, err := ResolvePerson(n, companions)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := ResolvePerson(person, out); err == nil {
+			return nil, fmt.Errorf("%s is named twice", person)
+		}
+		out = append(out, person)
+	}
+	return out, nil
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PrepTask is something to get done before a trip leaves, such as booking
+// insurance, due a lead time before departure: a task with a Lead of two
+// weeks is due 14 days before the first day of the trip.
+type PrepTask struct {
+	ID     string `json:"id"`
+	TripID string `json:"trip_id"`
+	Title  string `json:"title"`
+	// Lead is how long before the first day of the trip the task is due,
+	// in minutes.
+	Lead      int       `json:"lead"`
+	Done      bool      `json:"done"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewPrepTask creates a task to do lead minutes before trip tripID leaves.
+func NewPrepTask(tripID, title string, lead int) *PrepTask {
+	now := time.Now()
+	return &PrepTask{
+		ID:        NewID(),
+		TripID:    tripID,
+		Title:     strings.TrimSpace(title),
+		Lead:      lead,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Due is the day the task is due by on trip, as a comparable UTC
+// midnight. Departure counts from the start of the trip's first day, so a
+// task a day or 24 hours ahead is due the day before.
+func (p *PrepTask) Due(trip *Trip) time.Time {
+	return civilDay(civilDay(trip.StartDate).Add(-time.Duration(p.Lead) * time.Minute))
+}
+
+// ParseLead reads how long before departure a task is due, as "14d", "2w",
+// "24h" or the same after "T-", such as "T-60d", as minutes.
+func ParseLead(v string) (int, error) {
+	s := strings.TrimSpace(v)
+	if len(s) > 2 && strings.EqualFold(s[:2], "t-") {
+		s = s[2:]
+	}
+	lead, err := ParseAlarm(s)
+	if err != nil || lead <= 0 {
+		return 0, fmt.Errorf("lead time %q is not like 14d, 2w or T-24h", v)
+	}
+	return lead, nil
+}
+
+// FormatLead writes a lead time as a countdown to departure, such as
+// "T-14d" or "T-1d12h".
+func FormatLead(minutes int) string {
+	return "T-" + FormatAlarm(minutes)
+}
+
+// PrepTemplate is the preparation a type of trip takes: the tasks to add
+// to each trip tagged with its Type, such as "flight" or "abroad". The
+// template of no type is for every trip.
+type PrepTemplate struct {
+	ID        string             `json:"id"`
+	Type      string             `json:"type,omitempty"`
+	Tasks     []PrepTemplateTask `json:"tasks"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// PrepTemplateTask is a task of a template, without the per-trip state.
+type PrepTemplateTask struct {
+	Title string `json:"title"`
+	Lead  int    `json:"lead"`
+}
+
+// Applies reports whether the template is for trip: of no type, or of
+// one of the trip's tags.
+func (t *PrepTemplate) Applies(trip *Trip) bool {
+	return t.Type == "" || HasTags(trip.Tags, []string{t.Type})
+}
+
+// PrepTasksFor creates the tasks that the templates applying to trip
+// give it, leaving out those titled like one of existing or each other.
+func PrepTasksFor(trip *Trip, templates []*PrepTemplate, existing []*PrepTask) []*PrepTask {
+	var titles []string
+	for _, p := range existing {
+		titles = append(titles, strings.ToLower(p.Title))
+	}
+	var tasks []*PrepTask
+	for _, t := range templates {
+		if !t.Applies(trip) {
+			continue
+		}
+		for _, tt := range t.Tasks {
+			if title := strings.ToLower(strings.TrimSpace(tt.Title)); title != "" && !slices.Contains(titles, title) {
+				titles = append(titles, title)
+				tasks = append(tasks, NewPrepTask(trip.ID, tt.Title, tt.Lead))
+			}
+		}
+	}
+	return tasks
+}
+
+// PrepDeadline is a task to prepare a trip falling due.
+type PrepDeadline struct {
+	Task *PrepTask
+	Trip *Trip
+	// Day is midnight of the day it is due in UTC, as a comparable
+	// calendar day.
+	Day time.Time
+	// In is how many days from today it is due, 0 for today and below
+	// zero once overdue.
+	In int
+}
+
+// PrepDeadlines lists the tasks not done of trips yet to leave that are
+// due within days from now, or overdue, soonest first.
+func PrepDeadlines(trips []*Trip, tasks []*PrepTask, now time.Time, days int) []PrepDeadline {
+	today := civilDay(now)
+	last := today.AddDate(0, 0, days-1)
+	byID := make(map[string]*Trip, len(trips))
+	for _, t := range trips {
+		byID[t.ID] = t
+	}
+	var out []PrepDeadline
+	for _, p := range tasks {
+		t := byID[p.TripID]
+		if p.Done || t == nil || t.Archived() || !civilDay(t.StartDate).After(today) {
+			continue
+		}
+		if day := p.Due(t); !day.After(last) {
+			out = append(out, PrepDeadline{Task: p, Trip: t, Day: day, In: int(day.Sub(today).Hours() / 24)})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Day.Equal(out[j].Day) {
+			return out[i].Day.Before(out[j].Day)
+		}
+		return out[i].Trip.Title < out[j].Trip.Title
+	})
+	return out
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Privacy is how far a journal entry is shared: public entries go on the
+// website of nomadic publish, shared ones, the default, into exports and
+// reports, and private ones stay in the journal when those are redacted.
+type Privacy string
+
+const (
+	PrivacyPublic  Privacy = "public"
+	PrivacyShared  Privacy = "shared"
+	PrivacyPrivate Privacy = "private"
+)
+
+// Privacies lists the privacy levels, the most open first.
+var Privacies = []Privacy{PrivacyPublic, PrivacyShared, PrivacyPrivate}
+
+// ParsePrivacy reads a privacy level by its name.
+func ParsePrivacy(s string) (Privacy, error) {
+	p := Privacy(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Privacies {
+		if p == known {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("privacy %q is not one of public, shared, private", s)
+}
+
+// Privacy returns the privacy level of the entry.
+func (e *Entry) Privacy() Privacy {
+	switch {
+	case e.Private:
+		return PrivacyPrivate
+	case e.Public:
+		return PrivacyPublic
+	}
+	return PrivacyShared
+}
+
+// SetPrivacy gives the entry the privacy level p.
+func (e *Entry) SetPrivacy(p Privacy) {
+	e.Public, e.Private = p == PrivacyPublic, p == PrivacyPrivate
+}
+
+// Redaction is what exports and the website do with private entries and
+// passages: keep them, strip them out, or mask them, leaving a mark where
+// they were.
+type Redaction string
+
+const (
+	RedactNone  Redaction = "none"
+	RedactStrip Redaction = "strip"
+	RedactMask  Redaction = "mask"
+)
+
+// ParseRedaction reads a redaction mode by its name; empty is RedactNone.
+func ParseRedaction(s string) (Redaction, error) {
+	switch r := Redaction(strings.ToLower(strings.TrimSpace(s))); r {
+	case "", RedactNone:
+		return RedactNone, nil
+	case RedactStrip, RedactMask:
+		return r, nil
+	}
+	return "", fmt.Errorf("redaction %q is not one of none, strip, mask", s)
+}
+
+// A private passage of Markdown text, one or more paragraphs of an entry
+// or of notes, is fenced by a line PrivateOpen and a line PrivateClose:
+//
+//	::: private
+//	What only I should read.
+//	:::
+//
+// A passage left open runs to the end of the text.
+const (
+	PrivateOpen  = "::: private"
+	PrivateClose = ":::"
+)
+
+// Masked stands in for a private passage, or the text of a private entry,
+// that a redaction masks, and MaskedTitle for the title of the entry.
+const (
+	Masked      = "*(private)*"
+	MaskedTitle = "Private entry"
+)
+
+// HasPrivate reports whether text has a private passage.
+func HasPrivate(text string) bool {
+	fence := ""
+	for _, line := range strings.Split(text, "\n") {
+		if fence == "" && isPrivateOpen(line) {
+			return true
+		}
+		fence = fenced(fence, line)
+	}
+	return false
+}
+
+// RedactText strips the private passages out of text, or masks them as
+// Masked. RedactNone leaves text as it is, and the rest of it is kept
+// line for line. A "::: private" line in a fenced code block opens no
+// passage.
+func RedactText(text string, r Redaction) string {
+	if r != RedactStrip && r != RedactMask || !HasPrivate(text) {
+		return text
+	}
+	var out []string
+	blank := func() bool { return len(out) == 0 || strings.TrimSpace(out[len(out)-1]) == "" }
+	fence := ""
+	private, stripped := false, false
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case private:
+			if strings.TrimSpace(line) == PrivateClose {
+				private, stripped = false, r == RedactStrip
+			}
+			continue
+		case fence == "" && isPrivateOpen(line):
+			private = true
+			if r == RedactMask {
+				out = append(out, Masked)
+			}
+			continue
+		case stripped && strings.TrimSpace(line) == "" && blank():
+			// A passage stripped leaves one of the blank lines that set it
+			// apart.
+			stripped = false
+			continue
+		}
+		stripped = false
+		fence = fenced(fence, line)
+		out = append(out, line)
+	}
+	if private && r == RedactStrip || stripped {
+		for len(out) > 0 && blank() {
+			out = out[:len(out)-1]
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func isPrivateOpen(line string) bool {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), ":::")
+	return ok && strings.EqualFold(strings.TrimSpace(rest), "private")
+}
+
+// fenced returns the fence of the code block line is in, "```" or "~~~",
+// or empty outside one, given fence, that of the line before.
+func fenced(fence, line string) string {
+	line = strings.TrimSpace(line)
+	switch {
+	case fence != "":
+		if strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == "" {
+			return ""
+		}
+		return fence
+	case strings.HasPrefix(line, "```"):
+		return "```"
+	case strings.HasPrefix(line, "~~~"):
+		return "~~~"
+	}
+	return ""
+}
+
+// Redact returns the entry as redaction r shares it: nil for a private
+// entry stripped, one left with only its day and a Masked text for a
+// private entry masked, and otherwise a copy with its private passages
+// redacted.
+func (e *Entry) Redact(r Redaction) *Entry {
+	if r != RedactStrip && r != RedactMask {
+		return e
+	}
+	if e.Private {
+		if r == RedactStrip {
+			return nil
+		}
+		return &Entry{ID: e.ID, TripID: e.TripID, LegID: e.LegID, Title: MaskedTitle, Text: Masked,
+			Timestamp: e.Timestamp, TimeZone: e.TimeZone, Private: true, CreatedAt: e.CreatedAt, UpdatedAt: e.UpdatedAt}
+	}
+	c := *e
+	c.Text = RedactText(e.Text, r)
+	return &c
+}
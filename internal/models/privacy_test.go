@@ -0,0 +1,67 @@
+package models
+
+import "testing"
+
+func TestRedactText(t *testing.T) {
+	for _, tt := range []struct {
+		name, text, strip, mask string
+	}{
+		{
+			name:  "nothing private",
+			text:  "One.\n\n\n\nTwo.\n",
+			strip: "One.\n\n\n\nTwo.\n",
+			mask:  "One.\n\n\n\nTwo.\n",
+		},
+		{
+			name:  "a passage between paragraphs",
+			text:  "One.\n\n::: private\nSecret.\n:::\n\nTwo.",
+			strip: "One.\n\nTwo.",
+			mask:  "One.\n\n" + Masked + "\n\nTwo.",
+		},
+		{
+			name:  "blank lines kept elsewhere",
+			text:  "```\na\n\n\nb\n```\n\n\n\nOne.\n\n::: private\nSecret.\n:::\n\nTwo.",
+			strip: "```\na\n\n\nb\n```\n\n\n\nOne.\n\nTwo.",
+			mask:  "```\na\n\n\nb\n```\n\n\n\nOne.\n\n" + Masked + "\n\nTwo.",
+		},
+		{
+			name:  "first and left open",
+			text:  "::: private\nSecret.\n:::\n\nOne.\n\n::: Private\nMore.",
+			strip: "One.",
+			mask:  Masked + "\n\nOne.\n\n" + Masked,
+		},
+		{
+			name:  "markers in a code block",
+			text:  "How to hide a passage:\n\n```markdown\n::: private\nSecret.\n:::\n```",
+			strip: "How to hide a passage:\n\n```markdown\n::: private\nSecret.\n:::\n```",
+			mask:  "How to hide a passage:\n\n```markdown\n::: private\nSecret.\n:::\n```",
+		},
+		{
+			name:  "after a code block",
+			text:  "~~~\n```\n::: private\n~~~\n\n::: private\nSecret.\n:::\n\nOne.",
+			strip: "~~~\n```\n::: private\n~~~\n\nOne.",
+			mask:  "~~~\n```\n::: private\n~~~\n\n" + Masked + "\n\nOne.",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactText(tt.text, RedactStrip); got != tt.strip {
+				t.Errorf("stripped %q, want %q", got, tt.strip)
+			}
+			if got := RedactText(tt.text, RedactMask); got != tt.mask {
+				t.Errorf("masked %q, want %q", got, tt.mask)
+			}
+			if got := RedactText(tt.text, RedactNone); got != tt.text {
+				t.Errorf("left %q, want it as it was", got)
+			}
+		})
+	}
+}
+
+func TestHasPrivateIgnoresCodeBlocks(t *testing.T) {
+	if HasPrivate("```\n::: private\nx\n:::\n```") {
+		t.Error("a marker in a code block opens a passage")
+	}
+	if !HasPrivate("```\ncode\n```\n::: private\nx\n:::") {
+		t.Error("a passage after a code block is not found")
+	}
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxRating is the best mood of a journal entry or rating of a trip.
+// Ratings run from 1 to MaxRating; 0 is no rating.
+const MaxRating = 5
+
+// CheckRating reports whether n is a rating or 0.
+func CheckRating(n int) error {
+	if n < 0 || n > MaxRating {
+		return fmt.Errorf("rating %d is not from 1 to %d, or 0 for none", n, MaxRating)
+	}
+	return nil
+}
+
+// moodFaces shows each mood, from 1 (awful) to 5 (great).
+var moodFaces = [MaxRating]string{"😞", "🙁", "😐", "🙂", "😄"}
+
+// MoodFace is an emoji for mood, or empty when there is none.
+func MoodFace(mood int) string {
+	if mood < 1 || mood > MaxRating {
+		return ""
+	}
+	return moodFaces[mood-1]
+}
+
+// Stars draws rating as filled and empty stars, such as "★★★★☆", or is
+// empty when there is none.
+func Stars(rating int) string {
+	if rating < 1 || rating > MaxRating {
+		return ""
+	}
+	return strings.Repeat("★", rating) + strings.Repeat("☆", MaxRating-rating)
+}
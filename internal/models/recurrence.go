@@ -0,0 +1,166 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Intervals at which a recurring expense falls due.
+const (
+	IntervalDaily   = "daily"
+	IntervalWeekly  = "weekly"
+	IntervalMonthly = "monthly"
+	IntervalYearly  = "yearly"
+)
+
+// Intervals lists the intervals in display order.
+var Intervals = []string{IntervalDaily, IntervalWeekly, IntervalMonthly, IntervalYearly}
+
+// maxOccurrences bounds how far a recurrence is followed, ten years of
+// days, so a bad date cannot loop for long.
+const maxOccurrences = 3660
+
+// Recurrence is an expense that repeats at an interval, such as an eSIM
+// plan renewed each month or travel insurance paid by the week. Each time
+// it falls due while a trip is in progress, an expense is recorded on the
+// trip.
+type Recurrence struct {
+	ID string `json:"id"`
+	// TripID is the trip the expenses go to. When empty they go to
+	// whichever trip is in progress when one falls due.
+	TripID      string  `json:"trip_id,omitempty"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	Category    string  `json:"category"`
+	Description string  `json:"description"`
+	Interval    string  `json:"interval"`
+	// Start is the first day the expense falls due, and End the last day
+	// it may, if any.
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+	// Next is the day the expense next falls due. Days before it have
+	// been recorded already, or passed while the recurrence was paused.
+	Next      time.Time `json:"next"`
+	Paused    bool      `json:"paused,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewRecurrence creates a recurrence falling due every interval from the
+// day start, for any trip in progress.
+func NewRecurrence(amount float64, currency, category, description, interval string, start time.Time) *Recurrence {
+	now := time.Now()
+	return &Recurrence{
+		ID:          NewID(),
+		Amount:      amount,
+		Currency:    currency,
+		Category:    category,
+		Description: strings.TrimSpace(description),
+		Interval:    interval,
+		Start:       start,
+		Next:        start,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// ParseInterval resolves v to a known interval, accepting any unambiguous
+// prefix so "m" selects "monthly".
+func ParseInterval(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	for _, i := range Intervals {
+		if v != "" && strings.HasPrefix(i, v) {
+			return i, nil
+		}
+	}
+	return "", fmt.Errorf("unknown interval %q; choose one of %s", v, strings.Join(Intervals, ", "))
+}
+
+// occurrence returns the nth day the expense falls due, counting the
+// start as 0. Months and years are counted from the start, so a plan
+// renewed on the 31st falls on the last day of shorter months.
+func (r *Recurrence) occurrence(n int) time.Time {
+	s := r.Start
+	switch r.Interval {
+	case IntervalDaily:
+		return s.AddDate(0, 0, n)
+	case IntervalWeekly:
+		return s.AddDate(0, 0, 7*n)
+	case IntervalYearly:
+		n *= 12
+	}
+	first := time.Date(s.Year(), s.Month()+time.Month(n), 1, 0, 0, 0, 0, s.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return time.Date(first.Year(), first.Month(), min(s.Day(), last), s.Hour(), s.Minute(), s.Second(),
+		s.Nanosecond(), s.Location())
+}
+
+// From returns the first day on or after day the expense falls due, or
+// the zero time when it never does again.
+func (r *Recurrence) From(day time.Time) time.Time {
+	for n := range maxOccurrences {
+		d := r.occurrence(n)
+		if r.End != nil && d.After(*r.End) {
+			break
+		}
+		if !d.Before(day) {
+			return d
+		}
+	}
+	return time.Time{}
+}
+
+// Due returns the days from Next up to and including today the expense
+// fell due on, none while paused.
+func (r *Recurrence) Due(today time.Time) []time.Time {
+	if r.Paused || r.Next.IsZero() {
+		return nil
+	}
+	var days []time.Time
+	for n := range maxOccurrences {
+		d := r.occurrence(n)
+		if d.After(today) || r.End != nil && d.After(*r.End) {
+			break
+		}
+		if !d.Before(r.Next) {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// Ended reports whether the expense will not fall due again.
+func (r *Recurrence) Ended() bool {
+	return r.Next.IsZero()
+}
+
+// Expense returns the expense recorded on the trip tripID for the day the
+// recurrence fell due at noon in zone, an IANA time zone name.
+func (r *Recurrence) Expense(tripID string, day time.Time, zone string) *Expense {
+	at := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, Zone(zone))
+	x := NewExpense(tripID, r.Amount, r.Currency, r.Category, r.Description, at)
+	x.TimeZone = zone
+	x.RecurrenceID = r.ID
+	return x
+}
+
+// Reschedule moves Next to the first day the expense falls due on or after
+// the day it next would have, once its dates or interval are edited, or
+// after today when it had ended. Days already recorded stay so.
+func (r *Recurrence) Reschedule(today time.Time) {
+	from := r.Next
+	if from.IsZero() {
+		from = today.AddDate(0, 0, 1)
+	}
+	r.Next = r.From(from)
+}
+
+// Resume lets a paused recurrence fall due again from today, skipping the
+// days that passed while it was paused.
+func (r *Recurrence) Resume(today time.Time) {
+	r.Paused = false
+	if !r.Next.IsZero() && r.Next.Before(today) {
+		r.Next = r.From(today)
+	}
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Revision is an earlier version of a journal entry, kept when the entry
+// was edited: what it said as it was saved at SavedAt.
+type Revision struct {
+	ID       string   `json:"id"`
+	EntryID  string   `json:"entry_id"`
+	Title    string   `json:"title"`
+	Text     string   `json:"text"`
+	Location string   `json:"location,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Mood     int      `json:"mood,omitempty"`
+	// SavedAt is when this version of the entry was saved, before it was
+	// replaced by the next.
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// Apply puts the revision's title, text, location, tags and mood back on
+// e, as restoring it does.
+func (r *Revision) Apply(e *Entry) {
+	e.Title = r.Title
+	e.Text = r.Text
+	e.Location = r.Location
+	e.Tags = append([]string(nil), r.Tags...)
+	e.Mood = r.Mood
+}
+
+// CurrentRevision describes the entry as it is now, in the terms of its
+// revisions, for comparing them with it.
+func CurrentRevision(e *Entry) *Revision {
+	return &Revision{EntryID: e.ID, Title: e.Title, Text: e.Text, Location: e.Location,
+		Tags: e.Tags, Mood: e.Mood, SavedAt: e.UpdatedAt}
+}
+
+// Document is the revision as one text to compare with another: a line
+// each for its title and, when set, location, tags and mood, then a blank
+// line and its text. A nil revision, standing for no version at all, is
+// empty.
+func (r *Revision) Document() string {
+	if r == nil {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Title: " + r.Title + "\n")
+	if r.Location != "" {
+		b.WriteString("Location: " + r.Location + "\n")
+	}
+	if len(r.Tags) > 0 {
+		b.WriteString("Tags: " + strings.Join(r.Tags, ", ") + "\n")
+	}
+	if r.Mood > 0 {
+		fmt.Fprintf(&b, "Mood: %d/%d\n", r.Mood, MaxRating)
+	}
+	if r.Text != "" {
+		b.WriteString("\n" + r.Text)
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Rule categorizes imported statement transactions: a transaction whose
+// merchant text contains Match gets Category and, when set, goes to the
+// trip TripID.
+type Rule struct {
+	ID       string `json:"id"`
+	Match    string `json:"match"`
+	Category string `json:"category"`
+	TripID   string `json:"trip_id,omitempty"`
+	// Learned is set on rules remembered from a correction rather than
+	// added by hand.
+	Learned   bool      `json:"learned,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewRule creates a rule matching merchant texts that contain match.
+func NewRule(match, category string) *Rule {
+	now := time.Now()
+	return &Rule{
+		ID:        NewID(),
+		Match:     strings.ToLower(strings.TrimSpace(match)),
+		Category:  category,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Matches reports whether merchant contains the rule's text, ignoring
+// case.
+func (r *Rule) Matches(merchant string) bool {
+	return r.Match != "" && strings.Contains(strings.ToLower(merchant), r.Match)
+}
+
+// MatchRule returns the rule for merchant, or nil. When several match the
+// longest, most specific one wins.
+func MatchRule(rules []*Rule, merchant string) *Rule {
+	var best *Rule
+	for _, r := range rules {
+		if r.Matches(merchant) && (best == nil || len(r.Match) > len(best.Match)) {
+			best = r
+		}
+	}
+	return best
+}
+
+// MerchantKey reduces a statement's merchant text to the words naming the
+// merchant, dropping the store numbers, card references and places that
+// follow them, so "ALBERT HEIJN 1234 AMSTERDAM" becomes "albert heijn".
+// Rules learned from corrections match on it.
+func MerchantKey(merchant string) string {
+	var words []string
+	for _, w := range strings.FieldsFunc(strings.ToLower(merchant), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '*' || r == '#' || r == '/'
+	}) {
+		if strings.ContainsFunc(w, unicode.IsDigit) {
+			if len(words) > 0 {
+				break
+			}
+			continue
+		}
+		if w = strings.Trim(w, ".,-_:;'\""); w != "" {
+			words = append(words, w)
+		}
+		if len(words) == 2 {
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EmissionFactors are the approximate greenhouse gas emissions of each
+// transport mode, in kilograms of CO₂ equivalent per passenger-kilometre,
+// after the UK government's conversion factors. A car's is for the whole
+// car, however many ride in it; flights include the warming of contrails
+// at altitude. Modes without a factor, such as other, are not estimated.
+var EmissionFactors = map[string]float64{
+	TransportFlight: 0.246,
+	TransportTrain:  0.035,
+	TransportBus:    0.027,
+	TransportCar:    0.168,
+	TransportFerry:  0.019,
+	TransportBike:   0,
+	TransportWalk:   0,
+}
+
+// Segment is one journey of a trip from one place to another by a single
+// transport mode, logged to total the distance covered and the carbon
+// footprint of getting around.
+type Segment struct {
+	ID          string    `json:"id"`
+	TripID      string    `json:"trip_id"`
+	Mode        string    `json:"mode"` // one of Transports
+	Origin      string    `json:"origin,omitempty"`
+	Destination string    `json:"destination,omitempty"`
+	Distance    float64   `json:"distance_km"` // kilometres
+	Date        time.Time `json:"date"`
+	Notes       string    `json:"notes,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewSegment creates a segment of a trip traveled by mode on date.
+func NewSegment(tripID, mode string, date time.Time) *Segment {
+	return &Segment{
+		ID:        NewID(),
+		TripID:    tripID,
+		Mode:      mode,
+		Date:      date,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Route is where the segment goes, as in "Tokyo → Kyoto", or empty when
+// neither end is known.
+func (s *Segment) Route() string {
+	switch {
+	case s.Origin != "" && s.Destination != "":
+		return s.Origin + " → " + s.Destination
+	case s.Origin != "":
+		return "from " + s.Origin
+	case s.Destination != "":
+		return "to " + s.Destination
+	}
+	return ""
+}
+
+// String describes the segment, as in "Tokyo → Kyoto by train", or by its
+// distance when neither end is known.
+func (s *Segment) String() string {
+	if r := s.Route(); r != "" {
+		return r + " by " + s.Mode
+	}
+	return fmt.Sprintf("%.0f km by %s", s.Distance, s.Mode)
+}
+
+// CO2 estimates the segment's emissions in kilograms of CO₂ equivalent.
+// ok is false when its mode has no emission factor.
+func (s *Segment) CO2() (kg float64, ok bool) {
+	f, ok := EmissionFactors[s.Mode]
+	return s.Distance * f, ok
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Kinds of screens a session reopens.
+const (
+	SessionTrips     = "trips"
+	SessionPicker    = "picker"
+	SessionTrip      = "trip"
+	SessionJournal   = "journal"
+	SessionEntry     = "entry"
+	SessionExpenses  = "expenses"
+	SessionItinerary = "itinerary"
+)
+
+// Session is where the TUI was left when it last closed: the screens open
+// over the home menu, from the bottom up, so the next start can offer to
+// reopen them.
+type Session struct {
+	Screens []SessionScreen `json:"screens"`
+	SavedAt time.Time       `json:"saved_at"`
+}
+
+// SessionScreen is one screen of a session.
+type SessionScreen struct {
+	Kind string `json:"kind"`
+	// Title is the screen's, to describe the session when it is offered
+	// back; for trip pickers it also names which one.
+	Title string `json:"title"`
+	// ID is the record the screen shows: a trip, or for SessionEntry an
+	// entry. The lists of trips keep the trip under their cursor here.
+	ID string `json:"id,omitempty"`
+	// Cursor is the row the cursor of a list was on.
+	Cursor int `json:"cursor,omitempty"`
+	// All is set on journals showing every trip's entries, and Sort is
+	// the order they were listed in.
+	All  bool   `json:"all,omitempty"`
+	Sort string `json:"sort,omitempty"`
+	// Day is the day an itinerary was on, as 2006-01-02.
+	Day string `json:"day,omitempty"`
+}
+
+// Equal reports whether s and o left the same screens open.
+func (s *Session) Equal(o *Session) bool {
+	if s == nil || o == nil {
+		return s == o
+	}
+	return slices.Equal(s.Screens, o.Screens)
+}
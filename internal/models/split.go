@@ -0,0 +1,166 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Me names the traveler in expense splits. Travel companions go by the
+// names listed on the trip.
+const Me = "me"
+
+// SplitAll splits an expense equally among everyone on the trip.
+const SplitAll = "all"
+
+// Share is one person's part of a shared expense, in the expense's
+// currency.
+type Share struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// People lists who can pay for and share the trip's expenses: Me first,
+// then the companions.
+func (t *Trip) People() []string {
+	return append([]string{Me}, t.Companions...)
+}
+
+// Payer is who paid the expense, Me unless PaidBy names a companion.
+func (x *Expense) Payer() string {
+	if x.PaidBy == "" {
+		return Me
+	}
+	return x.PaidBy
+}
+
+// Shared reports whether the expense is split among several people or
+// paid for someone else, and so counts towards settling up.
+func (x *Expense) Shared() bool {
+	return len(x.Shares) > 0
+}
+
+// ResolvePerson finds name among people without regard to case.
+func ResolvePerson(name string, people []string) (string, error) {
+	name = strings.TrimSpace(name)
+	for _, p := range people {
+		if strings.EqualFold(p, name) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not on the trip; choose one of %s", name, strings.Join(people, ", "))
+}
+
+// ParseSplit reads how amount is split among people. v is SplitAll, a
+// comma separated list of names sharing it equally, or names with their
+// share as name=amount; names without an amount share what the others
+// leave equally. Empty v means no split.
+func ParseSplit(v string, amount float64, people []string) ([]Share, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	var names []string
+	if strings.EqualFold(v, SplitAll) {
+		names = people
+	} else {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				names = append(names, part)
+			}
+		}
+	}
+
+	total := cents(amount)
+	shares := make([]Share, 0, len(names))
+	fixed := map[int]int64{}
+	var assigned int64
+	seen := map[string]bool{}
+	for _, part := range names {
+		name, value, hasAmount := strings.Cut(part, "=")
+		person, err := ResolvePerson(name, people)
+		if err != nil {
+			return nil, err
+		}
+		if seen[person] {
+			return nil, fmt.Errorf("%s is named twice in the split", person)
+		}
+		seen[person] = true
+		if hasAmount {
+			a, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || a < 0 {
+				return nil, fmt.Errorf("share %q must be an amount of at least zero", part)
+			}
+			fixed[len(shares)] = cents(a)
+			assigned += cents(a)
+		}
+		shares = append(shares, Share{Name: person})
+	}
+
+	rest, open := total-assigned, int64(len(shares)-len(fixed))
+	switch {
+	case rest < 0:
+		return nil, errors.New("the shares add up to more than the amount")
+	case open == 0 && rest != 0:
+		return nil, fmt.Errorf("the shares add up to %.2f, not %.2f", float64(assigned)/100, amount)
+	}
+	// Cents that do not divide evenly go to the first open shares.
+	var n int64
+	for i := range shares {
+		c, ok := fixed[i]
+		if !ok {
+			c = rest / open
+			if n < rest%open {
+				c++
+			}
+			n++
+		}
+		shares[i].Amount = float64(c) / 100
+	}
+	return shares, nil
+}
+
+// FormatSplit renders shares as ParseSplit reads them: the names alone
+// when the amount is split equally, name=amount otherwise.
+func FormatSplit(shares []Share) string {
+	parts := make([]string, len(shares))
+	equal := true
+	for i, s := range shares {
+		if d := cents(s.Amount) - cents(shares[0].Amount); d < -1 || d > 1 {
+			equal = false
+		}
+		parts[i] = s.Name
+	}
+	if equal {
+		return strings.Join(parts, ", ")
+	}
+	for i, s := range shares {
+		parts[i] = s.Name + "=" + strconv.FormatFloat(s.Amount, 'f', -1, 64)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func cents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
+
+// ParseCompanions tidies the names of travel companions, rejecting names
+// that would be ambiguous in a split.
+func ParseCompanions(names []string) ([]string, error) {
+	var out []string
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n == "" {
+			continue
+		}
+		if err := CheckPersonName(n); err != nil {
+			return nil, err
+		}
+		if _, err := ResolvePerson(n, out); err == nil {
+			return nil, fmt.Errorf("%s is named twice", n)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// NormalizeTags cleans up tags as typed: it trims spaces and a leading
+// "#", lowercases them, and drops empty and repeated tags, keeping the
+// first occurrence's position.
+func NormalizeTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(t), "#")))
+		if t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// HasTags reports whether tags include every one of want. Matching
+// ignores case and a leading "#".
+func HasTags(tags, want []string) bool {
+	have := NormalizeTags(tags)
+	for _, w := range NormalizeTags(want) {
+		if !slices.Contains(have, w) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// Template is a reusable trip plan, saved from a trip and used to start
+// similar ones: where to go, how long for, the budget and the skeleton of
+// the itinerary.
+type Template struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Locations []string `json:"locations"`
+	// Days is the length of the trip in calendar days, or zero when the
+	// trip it was saved from had no end date.
+	Days            int                `json:"days,omitempty"`
+	Budget          float64            `json:"budget,omitempty"`
+	BudgetCurrency  string             `json:"budget_currency,omitempty"`
+	CategoryBudgets map[string]float64 `json:"category_budgets,omitempty"`
+	Notes           string             `json:"notes,omitempty"`
+	Tags            []string           `json:"tags,omitempty"`
+	Packing         []PackingListItem  `json:"packing,omitempty"`
+	Itinerary       []TemplateItem     `json:"itinerary,omitempty"`
+	Legs            []TemplateLeg      `json:"legs,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// TemplateItem is an itinerary item of a template, scheduled on a day
+// relative to the start of the trip.
+type TemplateItem struct {
+	Day   int    `json:"day"` // 0 is the first day of the trip
+	Time  string `json:"time,omitempty"`
+	Title string `json:"title"`
+	Place string `json:"place,omitempty"`
+	Notes string `json:"notes,omitempty"`
+	Alarm int    `json:"alarm,omitempty"`
+	// Duration and Kind are planned with the item; when it actually
+	// started and finished is not.
+	Duration int    `json:"duration,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+}
+
+// TemplateLeg is a leg of a template, reached on a day relative to the
+// start of the trip.
+type TemplateLeg struct {
+	Day int `json:"day"` // 0 is the first day of the trip
+	// Days is how many calendar days the leg lasts, or zero when it had no
+	// departure date.
+	Days      int    `json:"days,omitempty"`
+	Location  string `json:"location"`
+	Transport string `json:"transport,omitempty"`
+}
+
+// TripPlan is a new trip together with the itinerary and packing list it
+// starts out with.
+type TripPlan struct {
+	Trip      *Trip
+	Itinerary []*ItineraryItem
+	Packing   []*PackingItem
+	Legs      []*Leg
+}
+
+// NewTemplate captures trip, its itinerary, packing list and legs as a
+// template. Booking references and what has been packed are left out
+// since they belong to the original trip.
+func NewTemplate(name string, trip *Trip, items []*ItineraryItem, packing []*PackingItem, legs []*Leg) *Template {
+	now := time.Now()
+	t := &Template{
+		ID:              NewID(),
+		Name:            name,
+		Locations:       slices.Clone(trip.Locations),
+		Budget:          trip.Budget,
+		BudgetCurrency:  trip.BudgetCurrency,
+		CategoryBudgets: maps.Clone(trip.CategoryBudgets),
+		Notes:           trip.Notes,
+		Tags:            slices.Clone(trip.Tags),
+		Packing:         PackingListItems(packing),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if trip.EndDate != nil {
+		t.Days = CalendarDays(trip.StartDate, *trip.EndDate)
+	}
+	for _, it := range items {
+		t.Itinerary = append(t.Itinerary, TemplateItem{
+			Day:      CalendarDays(trip.StartDate, it.Day) - 1,
+			Time:     it.Time,
+			Title:    it.Title,
+			Place:    it.Place,
+			Notes:    it.Notes,
+			Alarm:    it.Alarm,
+			Duration: it.Duration,
+			Kind:     it.Kind,
+		})
+	}
+	for _, l := range legs {
+		tl := TemplateLeg{Day: CalendarDays(trip.StartDate, l.Arrival) - 1, Location: l.Location, Transport: l.Transport}
+		if l.Departure != nil {
+			tl.Days = CalendarDays(l.Arrival, *l.Departure)
+		}
+		t.Legs = append(t.Legs, tl)
+	}
+	return t
+}
+
+// NewTrip plans a trip from the template starting on start.
+func (t *Template) NewTrip(title string, start time.Time) *TripPlan {
+	trip := NewTrip(title, slices.Clone(t.Locations), start)
+	if end, ok := t.EndDate(start); ok {
+		trip.EndDate = &end
+	}
+	trip.Budget = t.Budget
+	trip.BudgetCurrency = t.BudgetCurrency
+	trip.CategoryBudgets = maps.Clone(t.CategoryBudgets)
+	trip.Notes = t.Notes
+	trip.Tags = slices.Clone(t.Tags)
+	return &TripPlan{
+		Trip:      trip,
+		Itinerary: t.ItineraryFor(trip.ID, start),
+		Packing:   PackingFor(trip.ID, t.Packing),
+		Legs:      t.LegsFor(trip.ID, start),
+	}
+}
+
+// EndDate returns the last day of a trip following the template from
+// start, and false when the template has no fixed length.
+func (t *Template) EndDate(start time.Time) (time.Time, bool) {
+	if t.Days <= 0 {
+		return time.Time{}, false
+	}
+	return start.AddDate(0, 0, t.Days-1), true
+}
+
+// ItineraryFor schedules the template's itinerary on the trip tripID
+// starting on start. Items keep their order within each day.
+func (t *Template) ItineraryFor(tripID string, start time.Time) []*ItineraryItem {
+	items := make([]*ItineraryItem, 0, len(t.Itinerary))
+	positions := make(map[int]int)
+	for _, ti := range t.Itinerary {
+		it := NewItineraryItem(tripID, start.AddDate(0, 0, ti.Day), ti.Title)
+		it.Time, it.Place, it.Notes, it.Alarm = ti.Time, ti.Place, ti.Notes, ti.Alarm
+		it.Duration, it.Kind = ti.Duration, ti.Kind
+		it.Position = positions[ti.Day]
+		positions[ti.Day]++
+		items = append(items, it)
+	}
+	return items
+}
+
+// LegsFor schedules the template's legs on the trip tripID starting on
+// start.
+func (t *Template) LegsFor(tripID string, start time.Time) []*Leg {
+	legs := make([]*Leg, 0, len(t.Legs))
+	for _, tl := range t.Legs {
+		l := NewLeg(tripID, tl.Location, start.AddDate(0, 0, tl.Day))
+		l.Transport = tl.Transport
+		if tl.Days > 0 {
+			departure := l.Arrival.AddDate(0, 0, tl.Days-1)
+			l.Departure = &departure
+		}
+		legs = append(legs, l)
+	}
+	return legs
+}
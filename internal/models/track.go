@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Track is a recorded GPS route, such as a hike, imported from a GPX file.
+// Its statistics are kept with its route, thinned out. A track belongs to a
+// trip and may also be linked to the journal entry that describes it.
+type Track struct {
+	ID        string     `json:"id"`
+	TripID    string     `json:"trip_id"`
+	EntryID   string     `json:"entry_id,omitempty"`
+	Name      string     `json:"name"`
+	Distance  float64    `json:"distance_m"` // metres
+	Ascent    float64    `json:"ascent_m"`   // metres climbed
+	Descent   float64    `json:"descent_m"`  // metres descended
+	Points    int        `json:"points"`
+	StartedAt *time.Time `json:"started_at,omitempty"` // nil when the file has no timestamps
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	// Place is the known city nearest to where the track starts, as in
+	// "Kyoto, Japan", or empty when none is close.
+	Place string `json:"place,omitempty"`
+	// Path is the route, a line of [lon, lat] positions per continuous
+	// recording; empty for tracks imported before routes were kept.
+	Path      [][][2]float64 `json:"path,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Duration is the time between the first and last recorded point, or zero
+// when the track has no timestamps.
+func (t *Track) Duration() time.Duration {
+	if t.StartedAt == nil || t.EndedAt == nil {
+		return 0
+	}
+	return t.EndedAt.Sub(*t.StartedAt)
+}
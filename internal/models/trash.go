@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Kinds of records in the trash.
+const (
+	TrashTrip    = "trip"
+	TrashEntry   = "entry"
+	TrashExpense = "expense"
+)
+
+// Trashed is a deleted trip, journal entry or expense waiting in the
+// trash, with what was deleted along with it, until it is restored or
+// purged for good.
+type Trashed struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// Title names the record: the title of a trip or an entry, the
+	// description of an expense.
+	Title string `json:"title"`
+	// TripID is the trip the record belonged to, or the trip itself.
+	TripID    string    `json:"trip_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// DateLayout is the format calendar dates are entered and displayed in.
+const DateLayout = "2006-01-02"
+
+// Trip represents a travel journey with associated entries and expenses
+type Trip struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Locations []string   `json:"locations"`
+	StartDate time.Time  `json:"start_date"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+	Budget    float64    `json:"budget,omitempty"`
+	// BudgetCurrency is the currency of Budget and CategoryBudgets. When
+	// empty the configured home currency is assumed.
+	BudgetCurrency string `json:"budget_currency,omitempty"`
+	// CategoryBudgets optionally limits spending per expense category.
+	CategoryBudgets map[string]float64 `json:"category_budgets,omitempty"`
+	// PerDiem is the fixed daily allowance of a work trip, in
+	// BudgetCurrency, or 0 without one.
+	PerDiem float64  `json:"per_diem,omitempty"`
+	Notes   string   `json:"notes,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	// Companions names the people traveling along, who can pay for and
+	// share expenses.
+	Companions []string `json:"companions,omitempty"`
+	// ArchivedAt is when the trip was archived: put away once finished,
+	// left out of the lists of trips but still searched and counted.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// Rating is the trip's overall rating once over, from 1 to MaxRating,
+	// or 0 when not rated.
+	Rating int `json:"rating,omitempty"`
+	// Public marks the trip, with all its entries, for the website of
+	// nomadic publish.
+	Public bool `json:"public,omitempty"`
+	// Yearly marks the trip as one that comes round every year, such as a
+	// family visit, or whose anniversaries are kept: YearlyRecurring,
+	// YearlyAnniversary or empty.
+	Yearly string `json:"yearly,omitempty"`
+	// Fields holds the values of the trip fields set up in the config,
+	// such as a visa number, by their keys.
+	Fields    map[string]string `json:"fields,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// CalendarDays counts the calendar days from start to end, both included,
+// or zero when end is before start.
+func CalendarDays(start, end time.Time) int {
+	a := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	if b.Before(a) {
+		return 0
+	}
+	return int(b.Sub(a).Hours()/24) + 1
+}
+
+// NewTrip creates a new trip with the given title and locations
+func NewTrip(title string, locations []string, startDate time.Time) *Trip {
+	now := time.Now()
+	return &Trip{
+		ID:        NewID(),
+		Title:     title,
+		Locations: locations,
+		StartDate: startDate,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Archived reports whether the trip was archived.
+func (t *Trip) Archived() bool { return t.ArchivedAt != nil }
+
+// InProgress reports whether now falls within the trip's dates. A trip
+// without an end date stays in progress once it has started.
+func (t *Trip) InProgress(now time.Time) bool {
+	if now.Before(t.StartDate) {
+		return false
+	}
+	return t.EndDate == nil || !now.After(t.EndDate.AddDate(0, 0, 1))
+}
+
+// TripOn returns the trip in progress at t, the most recently started one
+// when several overlap, or nil.
+func TripOn(trips []*Trip, t time.Time) *Trip {
+	var on *Trip
+	for _, trip := range trips {
+		if trip.InProgress(t) && (on == nil || trip.StartDate.After(on.StartDate)) {
+			on = trip
+		}
+	}
+	return on
+}
+
+// NextTrip returns the trip starting soonest after now, leaving out
+// archived trips, or nil when none is planned.
+func NextTrip(trips []*Trip, now time.Time) *Trip {
+	var next *Trip
+	for _, trip := range trips {
+		if trip.Archived() || !trip.StartDate.After(now) {
+			continue
+		}
+		if next == nil || trip.StartDate.Before(next.StartDate) {
+			next = trip
+		}
+	}
+	return next
+}
+
+// DayOf returns which day of the trip the calendar day of now is, from 1
+// on its first day, and how many days the trip lasts, or 0 for a trip
+// without an end date. now should be read where the trip is, so the day
+// is the trip's.
+func (t *Trip) DayOf(now time.Time) (day, days int) {
+	day = CalendarDays(t.StartDate, now)
+	if t.EndDate != nil {
+		days = CalendarDays(t.StartDate, *t.EndDate)
+	}
+	return day, days
+}
+
+// AddLocation appends name to the trip's destinations unless it is
+// already one of them, and reports whether it did.
+func (t *Trip) AddLocation(name string) bool {
+	for _, l := range t.Locations {
+		if strings.EqualFold(l, name) {
+			return false
+		}
+	}
+	t.Locations = append(t.Locations, name)
+	return true
+}
+
+// SetField sets the trip field key to value, or clears it when value is
+// blank.
+func (t *Trip) SetField(key, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		delete(t.Fields, key)
+		if len(t.Fields) == 0 {
+			t.Fields = nil
+		}
+		return
+	}
+	if t.Fields == nil {
+		t.Fields = map[string]string{}
+	}
+	t.Fields[key] = value
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The kinds of travel documents the vault keeps.
+const (
+	VaultPassport    = "passport"
+	VaultVisa        = "visa"
+	VaultInsurance   = "insurance"
+	VaultVaccination = "vaccination"
+	VaultOther       = "other"
+)
+
+// VaultKinds lists the kinds of travel documents in display order.
+var VaultKinds = []string{VaultPassport, VaultVisa, VaultInsurance, VaultVaccination, VaultOther}
+
+// VaultItem is a travel document kept in the vault, such as a passport, a
+// visa, an insurance policy or a vaccination record: its number and dates,
+// and a scan of it when one is kept. Unlike a trip's documents it belongs
+// to no trip, and lives in the database, so that an encrypted database
+// keeps it encrypted.
+type VaultItem struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	// Holder is the companion the document is for, or empty for Me.
+	Holder string `json:"holder,omitempty"`
+	Number string `json:"number,omitempty"`
+	// Issuer is who issued it: a country, an authority or an insurer.
+	Issuer string `json:"issuer,omitempty"`
+	// Country is the ISO 3166-1 alpha-2 code of the country a visa is
+	// for, if any; it only warns of trips going there.
+	Country string     `json:"country,omitempty"`
+	Issued  *time.Time `json:"issued,omitempty"`
+	Expires *time.Time `json:"expires,omitempty"`
+	Note    string     `json:"note,omitempty"`
+	// ScanName is the file name of the scan kept with it, if any, and
+	// ScanType and ScanSize its content type and size. The scan itself is
+	// read on its own.
+	ScanName  string    `json:"scan_name,omitempty"`
+	ScanType  string    `json:"scan_type,omitempty"`
+	ScanSize  int64     `json:"scan_size,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewVaultItem creates a travel document of kind, titled title.
+func NewVaultItem(kind, title string) *VaultItem {
+	now := time.Now()
+	return &VaultItem{
+		ID:        NewID(),
+		Kind:      kind,
+		Title:     strings.TrimSpace(title),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// ParseVaultKind resolves v to a kind of travel document, accepting any
+// unambiguous prefix so "pass" selects "passport".
+func ParseVaultKind(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" {
+		return "", errors.New("kind is required")
+	}
+	var match string
+	for _, k := range VaultKinds {
+		if k == v {
+			return k, nil
+		}
+		if strings.HasPrefix(k, v) {
+			if match != "" {
+				return "", fmt.Errorf("kind %q is ambiguous", v)
+			}
+			match = k
+		}
+	}
+	if match == "" {
+		return "", fmt.Errorf("unknown kind %q; expected one of %s", v, strings.Join(VaultKinds, ", "))
+	}
+	return match, nil
+}
+
+// VaultIcon is the icon shown for a kind of travel document.
+func VaultIcon(kind string) string {
+	switch kind {
+	case VaultPassport:
+		return "🛂"
+	case VaultVisa:
+		return "🪪"
+	case VaultInsurance:
+		return "🛡️"
+	case VaultVaccination:
+		return "💉"
+	}
+	return "📄"
+}
+
+// ExpiryAlert is a travel document running out.
+type ExpiryAlert struct {
+	Item *VaultItem
+	// Trip is the trip coming up or in progress that the document runs
+	// out before the end of, or nil when it only runs out soon.
+	Trip *Trip
+	// Day is midnight of the day it runs out in UTC, as a comparable
+	// calendar day.
+	Day time.Time
+	// In is how many days from today it runs out, 0 for today and below
+	// zero once it has.
+	In int
+}
+
+// Expired reports whether the document has run out already.
+func (a ExpiryAlert) Expired() bool { return a.In < 0 }
+
+// ExpiryAlerts lists the travel documents that run out within days from
+// now, or have, and those that run out before the end of a trip coming up
+// or in progress, soonest first. countries names the countries a trip goes
+// to, by ISO code, for a visa to warn only of trips to its country.
+func ExpiryAlerts(items []*VaultItem, trips []*Trip, countries func(*Trip) []string, now time.Time, days int) []ExpiryAlert {
+	today := civilDay(now)
+	last := today.AddDate(0, 0, days-1)
+	var out []ExpiryAlert
+	for _, it := range items {
+		if it.Expires == nil {
+			continue
+		}
+		day := civilDay(*it.Expires)
+		alert := ExpiryAlert{Item: it, Day: day, In: int(day.Sub(today).Hours() / 24)}
+		if !day.Before(today) {
+			alert.Trip = tripOutlasting(it, trips, countries, today, day)
+		}
+		if day.After(last) && alert.Trip == nil {
+			continue
+		}
+		out = append(out, alert)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Day.Before(out[j].Day) })
+	return out
+}
+
+// tripOutlasting is the first trip not over by today whose last day comes
+// after day, when it runs out, of those the document is for.
+func tripOutlasting(it *VaultItem, trips []*Trip, countries func(*Trip) []string, today, day time.Time) *Trip {
+	var first *Trip
+	for _, t := range trips {
+		end := civilDay(t.StartDate)
+		if t.EndDate != nil {
+			end = civilDay(*t.EndDate)
+		}
+		if t.Archived() || end.Before(today) || !end.After(day) {
+			continue
+		}
+		if it.Kind == VaultVisa && it.Country != "" && !slices.Contains(countries(t), it.Country) {
+			continue
+		}
+		if first == nil || t.StartDate.Before(first.StartDate) {
+			first = t
+		}
+	}
+	return first
+}
@@ -0,0 +1,175 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The priorities of a wish, most wanted first.
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// Priorities lists the priorities of a wish in the order the wishlist is
+// sorted by.
+var Priorities = []string{PriorityHigh, PriorityMedium, PriorityLow}
+
+// Wish is a place on the wishlist, somewhere to go some day: what is known
+// of it before it becomes a trip.
+type Wish struct {
+	ID string `json:"id"`
+	// Place is where to go: a city, a country or a region.
+	Place string `json:"place"`
+	Notes string `json:"notes,omitempty"`
+	// Budget is roughly what the trip would cost, in Currency.
+	Budget   float64 `json:"budget,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+	// Season is the months best to go in, in calendar order, or empty when
+	// any time will do.
+	Season   []time.Month `json:"season,omitempty"`
+	Priority string       `json:"priority"`
+	// TripID is the trip planned from the wish, once it is promoted.
+	TripID    string    `json:"trip_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewWish creates a wish of medium priority to go to place.
+func NewWish(place string) *Wish {
+	now := time.Now()
+	return &Wish{
+		ID:        NewID(),
+		Place:     strings.TrimSpace(place),
+		Priority:  PriorityMedium,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Validate reports what is missing or wrong with the wish.
+func (w *Wish) Validate() error {
+	switch {
+	case w.Place == "":
+		return errors.New("a wish needs a place")
+	case w.Budget < 0:
+		return errors.New("budget must not be negative")
+	case w.Budget > 0 && w.Currency == "":
+		return errors.New("a budget needs its currency")
+	case !slices.Contains(Priorities, w.Priority):
+		return fmt.Errorf("priority must be one of %s", strings.Join(Priorities, ", "))
+	}
+	return nil
+}
+
+// InSeason reports whether month is one of the best to go in.
+func (w *Wish) InSeason(month time.Month) bool {
+	return slices.Contains(w.Season, month)
+}
+
+// NewTrip starts a trip called title to the place of the wish on start,
+// carrying over its notes and budget.
+func (w *Wish) NewTrip(title string, start time.Time) *Trip {
+	t := NewTrip(title, []string{w.Place}, start)
+	t.Notes = w.Notes
+	t.Budget, t.BudgetCurrency = w.Budget, w.Currency
+	return t
+}
+
+// ParsePriority reads a priority, or the first letter of one.
+func ParsePriority(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	for _, p := range Priorities {
+		if v != "" && strings.HasPrefix(p, v) {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("priority must be one of %s", strings.Join(Priorities, ", "))
+}
+
+// ParseSeason reads the months best to go in, written as English month
+// names or their first three letters, alone or as ranges separated by
+// commas, such as "apr-may, oct" or "nov-feb". "any" or an empty string
+// leave it open.
+func ParseSeason(v string) ([]time.Month, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	if v == "" || v == "any" || v == "all year" {
+		return nil, nil
+	}
+	var in [13]bool
+	for _, part := range strings.Split(v, ",") {
+		from, to, isRange := strings.Cut(strings.ReplaceAll(part, "–", "-"), "-")
+		first, ok := month(from)
+		last := first
+		if ok && isRange {
+			last, ok = month(to)
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown months %q: name them like apr-may, oct", strings.TrimSpace(part))
+		}
+		for m := first; ; m = m%12 + 1 {
+			in[m] = true
+			if m == last {
+				break
+			}
+		}
+	}
+	var season []time.Month
+	for m := time.January; m <= time.December; m++ {
+		if in[m] {
+			season = append(season, m)
+		}
+	}
+	return season, nil
+}
+
+// FormatSeason writes months as ParseSeason reads them, running months
+// joined into ranges, such as "Apr-May, Oct" or "Nov-Feb".
+func FormatSeason(months []time.Month) string {
+	if len(months) == 0 {
+		return ""
+	}
+	if len(months) == 12 {
+		return "all year"
+	}
+	var in [13]bool
+	for _, m := range months {
+		in[m] = true
+	}
+	prev := func(m time.Month) time.Month { return (m+10)%12 + 1 }
+	var runs []string
+	for m := time.January; m <= time.December; m++ {
+		// A run starts on a month whose previous one is out of season.
+		if !in[m] || in[prev(m)] {
+			continue
+		}
+		last := m
+		for in[last%12+1] {
+			last = last%12 + 1
+		}
+		run := m.String()[:3]
+		if last != m {
+			run += "-" + last.String()[:3]
+		}
+		runs = append(runs, run)
+	}
+	return strings.Join(runs, ", ")
+}
+
+// month reads an English month name or its first three letters.
+func month(v string) (time.Month, bool) {
+	v = strings.TrimSpace(v)
+	if len(v) < 3 {
+		return 0, false
+	}
+	for m := time.January; m <= time.December; m++ {
+		if strings.HasPrefix(strings.ToLower(m.String()), v) {
+			return m, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// What a trip's Yearly marks it as.
+const (
+	// YearlyRecurring is a trip taken every year around its dates, such
+	// as a family visit.
+	YearlyRecurring = "recurring"
+	// YearlyAnniversary is a trip whose anniversaries are kept, such as
+	// one year since Patagonia.
+	YearlyAnniversary = "anniversary"
+)
+
+// YearlyKinds are the values of Trip.Yearly other than none.
+var YearlyKinds = []string{YearlyRecurring, YearlyAnniversary}
+
+// ParseYearly resolves v to one of YearlyKinds, or to empty for "off",
+// "none" or nothing, accepting any unambiguous prefix.
+func ParseYearly(v string) (string, error) {
+	v = strings.ToLower(strings.TrimSpace(v))
+	switch v {
+	case "", "off", "none", "no":
+		return "", nil
+	}
+	for _, k := range YearlyKinds {
+		if strings.HasPrefix(k, v) {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not one of %s or off", v, strings.Join(YearlyKinds, ", "))
+}
+
+// Occasion is a day a trip marked Yearly comes round: Years years after
+// it started, on the same date.
+type Occasion struct {
+	Trip *Trip
+	// Day is midnight of the day in UTC, as a comparable calendar day.
+	Day   time.Time
+	Years int
+	// In is how many days from today the day is, 0 for today.
+	In int
+}
+
+// Anniversary reports whether the occasion is an anniversary of the trip
+// rather than the time to take it again.
+func (o Occasion) Anniversary() bool {
+	return o.Trip.Yearly == YearlyAnniversary
+}
+
+// String describes the occasion, such as "1 year since Patagonia" or
+// "Family visit comes round again".
+func (o Occasion) String() string {
+	if o.Anniversary() {
+		years := "1 year"
+		if o.Years > 1 {
+			years = fmt.Sprintf("%d years", o.Years)
+		}
+		return years + " since " + o.Trip.Title
+	}
+	return o.Trip.Title + " comes round again"
+}
+
+// Occasions lists the days in the next days days from the calendar day of
+// now, today included, that trips marked Yearly come round, soonest
+// first. A recurring trip counts from the latest trip of its title, and
+// skips a year a trip of its title is already planned for within a month
+// of the day.
+func Occasions(trips []*Trip, now time.Time, days int) []Occasion {
+	today := civilDay(now)
+	last := today.AddDate(0, 0, days-1)
+	var out []Occasion
+	for _, t := range trips {
+		if t.Yearly == "" || (t.Yearly == YearlyRecurring && laterNamesake(trips, t)) {
+			continue
+		}
+		start := civilDay(t.StartDate)
+		for n := max(today.Year()-start.Year(), 1); ; n++ {
+			day := start.AddDate(n, 0, 0)
+			if day.After(last) {
+				break
+			}
+			if day.Before(today) {
+				continue
+			}
+			if t.Yearly == YearlyRecurring && plannedAround(trips, t, day) {
+				continue
+			}
+			out = append(out, Occasion{Trip: t, Day: day, Years: n, In: int(day.Sub(today).Hours() / 24)})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Day.Equal(out[j].Day) {
+			return out[i].Day.Before(out[j].Day)
+		}
+		return out[i].Trip.Title < out[j].Trip.Title
+	})
+	return out
+}
+
+// laterNamesake reports whether a trip titled as t starts after it.
+func laterNamesake(trips []*Trip, t *Trip) bool {
+	for _, o := range trips {
+		if o != t && strings.EqualFold(o.Title, t.Title) && o.StartDate.After(t.StartDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// plannedAround reports whether a trip titled as t other than t starts
+// within 31 days of day.
+func plannedAround(trips []*Trip, t *Trip, day time.Time) bool {
+	for _, o := range trips {
+		if o == t || !strings.EqualFold(o.Title, t.Title) {
+			continue
+		}
+		if d := civilDay(o.StartDate).Sub(day).Hours() / 24; d >= -31 && d <= 31 {
+			return true
+		}
+	}
+	return false
+}
+
+// YearsAgo reports how many years before the calendar day of now the
+// entry was written on the same date, as on-this-day recalls it, or 0 when
+// it was not. Like Occasions, an entry of 29 February comes round on 1
+// March in other years.
+func (e *Entry) YearsAgo(now time.Time) int {
+	written, today := civilDay(e.Timestamp), civilDay(now)
+	n := today.Year() - written.Year()
+	if n < 1 || !written.AddDate(n, 0, 0).Equal(today) {
+		return 0
+	}
+	return n
+}
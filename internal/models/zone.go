@@ -0,0 +1,30 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+var zones sync.Map // *time.Location by IANA name
+
+// Zone returns the IANA time zone named name, such as "Asia/Tokyo", or the
+// local zone when name is empty or unknown.
+func Zone(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	if loc, ok := zones.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.Local
+	}
+	zones.Store(name, loc)
+	return loc
+}
+
+// InZone returns t as the clock reads it in the time zone named zone.
+func InZone(t time.Time, zone string) time.Time {
+	return t.In(Zone(zone))
+}
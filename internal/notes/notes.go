@@ -0,0 +1,446 @@
+// Package notes reads and writes journal entries as Markdown notes with
+// YAML front matter, the files of a folder such as an Obsidian vault: one
+// folder per trip, named after it, with a note per entry named by its day
+// and title.
+//
+// The front matter is the small part of YAML such notes use: plain and
+// quoted strings, numbers, booleans, lists and, for the weather, one
+// nested map. Fields nomadic does not know, such as those of plugins, are
+// kept as they are written.
+package notes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/weather"
+)
+
+// Ext is the extension of note files.
+const Ext = ".md"
+
+// Note is a journal entry as a note. Trip is the title of its trip, or the
+// trip's ID. Time is zero when the note does not say when it was written.
+type Note struct {
+	ID       string
+	Trip     string
+	Title    string
+	Time     time.Time
+	TimeZone string
+	Location string
+	Mood     int
+	Tags     []string
+	People   []string
+	Weather  *weather.Day
+	Public   bool
+	Private  bool
+	Text     string
+
+	// extra holds the front matter fields nomadic does not know, with the
+	// lines continuing them, to be written back as they were.
+	extra []string
+}
+
+// FromEntry makes the note of e, an entry of the trip called trip, keeping
+// the fields of old, the note written before, that nomadic does not know.
+func FromEntry(e *models.Entry, trip string, old *Note) *Note {
+	n := &Note{
+		ID:       e.ID,
+		Trip:     trip,
+		Title:    e.Title,
+		Time:     e.Timestamp,
+		TimeZone: e.TimeZone,
+		Location: e.Location,
+		Mood:     e.Mood,
+		Tags:     e.Tags,
+		People:   e.People,
+		Weather:  e.Weather,
+		Public:   e.Public,
+		Private:  e.Private,
+		Text:     e.Text,
+	}
+	if old != nil {
+		n.extra = old.extra
+	}
+	return n
+}
+
+// Apply copies what the note says of its entry onto e, leaving what notes
+// do not hold, such as its trip and leg, alone. It reports whether e
+// changed.
+func (n *Note) Apply(e *models.Entry) bool {
+	before := *e
+	e.Title, e.Text, e.Location, e.Mood, e.Public, e.Private = n.Title, n.Text, n.Location, n.Mood, n.Public, n.Private
+	e.Tags, e.People, e.Weather = n.Tags, n.People, n.Weather
+	// Notes tell the time to the second.
+	if !n.Time.IsZero() && !n.Time.Equal(e.Timestamp.Truncate(time.Second)) {
+		e.Timestamp = n.Time
+	}
+	if n.TimeZone != "" {
+		e.TimeZone = n.TimeZone
+	}
+	return !sameEntry(&before, e)
+}
+
+func sameEntry(a, b *models.Entry) bool {
+	return a.Title == b.Title && a.Text == b.Text && a.Location == b.Location && a.Mood == b.Mood &&
+		a.Public == b.Public && a.Private == b.Private && a.Timestamp.Equal(b.Timestamp) && a.TimeZone == b.TimeZone &&
+		strings.Join(a.Tags, "\x00") == strings.Join(b.Tags, "\x00") &&
+		strings.Join(a.People, "\x00") == strings.Join(b.People, "\x00") &&
+		(a.Weather == nil) == (b.Weather == nil) && (a.Weather == nil || *a.Weather == *b.Weather)
+}
+
+// Folder is the folder, relative to the notes' root, of the notes of the
+// trip called trip.
+func Folder(trip string) string {
+	return FileName(trip)
+}
+
+// Path is where, relative to the notes' root, the note of an entry of the
+// trip called trip goes: its trip's folder and a file named by its day
+// and title.
+func Path(trip string, e *models.Entry) string {
+	name := e.Timestamp.Format(models.DateLayout)
+	if title := FileName(e.Title); title != "" {
+		name += " " + title
+	}
+	return filepath.Join(Folder(trip), name+Ext)
+}
+
+// FileName makes s usable as the name of a file or folder on every
+// system, and in Obsidian's links, by replacing the characters they take
+// for something else.
+func FileName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(`/\:*?"<>|#^[]`, r):
+			return ' '
+		case r < ' ':
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 80 {
+		s = strings.TrimSpace(string(r[:80]))
+	}
+	return strings.Trim(s, ". ")
+}
+
+// Marshal writes the note as Markdown with YAML front matter.
+func (n *Note) Marshal() []byte {
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	field := func(key, value string) { fmt.Fprintf(&b, "%s: %s\n", key, value) }
+	list := func(key string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		b.WriteString(key + ":\n")
+		for _, v := range values {
+			b.WriteString("  - " + quote(v) + "\n")
+		}
+	}
+	if n.ID != "" {
+		field("id", n.ID)
+	}
+	field("trip", quote(n.Trip))
+	field("title", quote(n.Title))
+	if !n.Time.IsZero() {
+		field("date", n.Time.Format(time.RFC3339))
+	}
+	if n.TimeZone != "" {
+		field("time_zone", quote(n.TimeZone))
+	}
+	if n.Location != "" {
+		field("location", quote(n.Location))
+	}
+	if n.Mood != 0 {
+		field("mood", strconv.Itoa(n.Mood))
+	}
+	list("tags", n.Tags)
+	list("people", n.People)
+	if w := n.Weather; w != nil {
+		b.WriteString("weather:\n")
+		fmt.Fprintf(&b, "  date: %s\n  code: %d\n  temp_min: %s\n  temp_max: %s\n", w.Date, w.Code, number(w.TempMin), number(w.TempMax))
+		if w.Precipitation != 0 {
+			fmt.Fprintf(&b, "  precipitation: %s\n", number(w.Precipitation))
+		}
+	}
+	if n.Public {
+		field("public", "true")
+	}
+	if n.Private {
+		field("private", "true")
+	}
+	for _, line := range n.extra {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimRight(n.Text, "\n"))
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+// Unmarshal reads a note. A note without front matter is all text.
+func Unmarshal(data []byte) (*Note, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	n := &Note{}
+	front, body, ok := splitFront(text)
+	if !ok {
+		n.Text = strings.TrimSpace(text)
+		return n, nil
+	}
+	n.Text = strings.TrimSpace(body)
+
+	lines := strings.Split(front, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") {
+			return nil, fmt.Errorf("notes: front matter line %q is not a field", line)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		// The lines indented below a field continue it: list items or the
+		// fields of a map.
+		var nested []string
+		for i+1 < len(lines) && (strings.HasPrefix(lines[i+1], " ") || strings.HasPrefix(lines[i+1], "-")) {
+			i++
+			nested = append(nested, lines[i])
+		}
+		if err := n.set(key, value, nested); err != nil {
+			return nil, fmt.Errorf("notes: %s: %w", key, err)
+		}
+	}
+	return n, nil
+}
+
+// set sets the field key of the front matter, written as value on its line
+// and nested on the lines below.
+func (n *Note) set(key, value string, nested []string) error {
+	var err error
+	switch key {
+	case "id":
+		n.ID = scalar(value)
+	case "trip":
+		n.Trip = scalar(value)
+	case "title":
+		n.Title = scalar(value)
+	case "date":
+		n.Time, err = ParseTime(scalar(value))
+	case "time_zone":
+		n.TimeZone = scalar(value)
+	case "location":
+		n.Location = scalar(value)
+	case "mood":
+		if v := scalar(value); v != "" {
+			if n.Mood, err = strconv.Atoi(v); err == nil && (n.Mood < 0 || n.Mood > models.MaxRating) {
+				err = fmt.Errorf("%d is not from 1 to %d", n.Mood, models.MaxRating)
+			}
+		}
+	case "tags", "tag":
+		n.Tags, err = values(value, nested)
+		for i, t := range n.Tags {
+			// Obsidian writes tags with or without their #.
+			n.Tags[i] = strings.TrimPrefix(t, "#")
+		}
+	case "people":
+		n.People, err = values(value, nested)
+	case "weather":
+		n.Weather, err = weatherOf(nested)
+	case "public":
+		n.Public, err = boolean(scalar(value))
+	case "private":
+		n.Private, err = boolean(scalar(value))
+	default:
+		n.extra = append(n.extra, key+": "+value)
+		n.extra = append(n.extra, nested...)
+	}
+	return err
+}
+
+// splitFront splits text into its front matter, between --- lines at its
+// start, and the body after it.
+func splitFront(text string) (front, body string, ok bool) {
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return "", text, false
+	}
+	if strings.HasPrefix(rest, "---\n") || rest == "---" {
+		return "", strings.TrimPrefix(rest, "---"), true
+	}
+	front, body, ok = strings.Cut(rest, "\n---\n")
+	if !ok {
+		if front, ok = strings.CutSuffix(rest, "\n---"); !ok {
+			return "", text, false
+		}
+	}
+	return front, body, true
+}
+
+// ParseTime reads when a note was written: an RFC 3339 timestamp, or a day
+// and time of day, or a day alone, in the local time zone.
+func ParseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", models.DateLayout} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date", s)
+}
+
+// scalar reads a plain, single- or double-quoted YAML string.
+func scalar(v string) string {
+	switch {
+	case len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"':
+		if s, err := strconv.Unquote(v); err == nil {
+			return s
+		}
+		return v[1 : len(v)-1]
+	case len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'':
+		return strings.ReplaceAll(v[1:len(v)-1], "''", "'")
+	}
+	// A comment ends a plain string.
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "~" || v == "null" {
+		return ""
+	}
+	return strings.TrimSpace(v)
+}
+
+// values reads a list: a flow list on the field's line, one value there,
+// or block items on the lines below.
+func values(value string, nested []string) ([]string, error) {
+	var out []string
+	switch {
+	case strings.HasPrefix(value, "["):
+		inner, ok := strings.CutSuffix(value, "]")
+		if !ok {
+			return nil, errors.New("the list is not closed")
+		}
+		for _, v := range splitFlow(inner[1:]) {
+			if v = scalar(strings.TrimSpace(v)); v != "" {
+				out = append(out, v)
+			}
+		}
+	case value != "":
+		for _, v := range strings.Split(scalar(value), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	for _, line := range nested {
+		item, ok := strings.CutPrefix(strings.TrimSpace(line), "-")
+		if !ok {
+			return nil, fmt.Errorf("%q is not a list item", strings.TrimSpace(line))
+		}
+		if v := scalar(strings.TrimSpace(item)); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// splitFlow splits the items of a flow list at the commas outside quotes.
+func splitFlow(s string) []string {
+	var (
+		items []string
+		quote rune
+		start int
+	)
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// weatherOf reads the nested fields of the weather.
+func weatherOf(nested []string) (*weather.Day, error) {
+	if len(nested) == 0 {
+		return nil, nil
+	}
+	w := &weather.Day{}
+	for _, line := range nested {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			return nil, fmt.Errorf("%q is not a field", strings.TrimSpace(line))
+		}
+		value = scalar(strings.TrimSpace(value))
+		var err error
+		switch strings.TrimSpace(key) {
+		case "date":
+			w.Date = value
+		case "code":
+			w.Code, err = strconv.Atoi(value)
+		case "temp_min":
+			w.TempMin, err = strconv.ParseFloat(value, 64)
+		case "temp_max":
+			w.TempMax, err = strconv.ParseFloat(value, 64)
+		case "precipitation":
+			w.Precipitation, err = strconv.ParseFloat(value, 64)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+func boolean(v string) (bool, error) {
+	switch strings.ToLower(v) {
+	case "true", "yes", "on":
+		return true, nil
+	case "false", "no", "off", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q is neither true nor false", v)
+}
+
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// quote writes s as a YAML string, in double quotes when it would
+// otherwise be read as something else.
+func quote(s string) string {
+	if s == "" || s != strings.TrimSpace(s) || strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.ContainsAny(s, "\n\t\\") {
+		return strconv.Quote(s)
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+// Package notify shows desktop notifications with the tools the operating
+// system provides: notify-send on Linux and the BSDs, and osascript on
+// macOS.
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+// Send shows a desktop notification with title and body.
+func Send(title, body string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(body), strconv.Quote(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("notify-send", "--app-name=nomadic", title, body)
+	default:
+		return fmt.Errorf("notify: desktop notifications are not supported on %s", runtime.GOOS)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("notify: %s: %s", cmd.Path, out)
+		}
+		return fmt.Errorf("notify: %w", err)
+	}
+	return nil
+}
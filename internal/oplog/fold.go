@@ -0,0 +1,383 @@
+package oplog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// fields are the fields of a record by their JSON names.
+type fields map[string]json.RawMessage
+
+// volatile are the fields saving a record sets anew, which are not
+// changes of their own: they are synced with the rest but never conflict
+// or make a record differ.
+var volatile = map[string]bool{"updated_at": true}
+
+// null is a field cleared in Op.New.
+var null = json.RawMessage("null")
+
+// key names a record by its Op.Kind and Op.ID.
+type key struct {
+	kind, id string
+}
+
+func (k key) less(o key) bool {
+	if k.kind != o.kind {
+		return k.kind < o.kind
+	}
+	return k.id < o.id
+}
+
+// record is a record of a storage.Dump as the logs have it.
+type record struct {
+	// trip is the ID of the trip it is on, for a record of a TripRecord.
+	trip   string
+	fields fields
+	// by is the operation that last set each field.
+	by map[string]*Op
+}
+
+// keys returns the keys of every record of a and b, sorted.
+func keys(a, b map[key]*record) []key {
+	var out []key
+	for k := range a {
+		out = append(out, k)
+	}
+	for k := range b {
+		if a[k] == nil {
+			out = append(out, k)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].less(out[j]) })
+	return out
+}
+
+// unset reports whether a field is missing or null, which are the same.
+func unset(v json.RawMessage, ok bool) bool {
+	return !ok || bytes.Equal(v, null)
+}
+
+// same reports whether two values of a field, each perhaps missing, are
+// equal.
+func same(a json.RawMessage, aok bool, b json.RawMessage, bok bool) bool {
+	if unset(a, aok) || unset(b, bok) {
+		return unset(a, aok) && unset(b, bok)
+	}
+	return bytes.Equal(a, b)
+}
+
+// sameFields reports whether two records hold the same, volatile fields
+// aside.
+func sameFields(a, b fields) bool {
+	_, new := diff(a, b)
+	return len(new) == 0
+}
+
+// diff returns the fields that differ from a to b, volatile ones aside,
+// with their values in a, where set, and in b, null where cleared.
+func diff(a, b fields) (old, new fields) {
+	for _, f := range fieldNames(a, b) {
+		av, aok := a[f]
+		bv, bok := b[f]
+		if volatile[f] || same(av, aok, bv, bok) {
+			continue
+		}
+		if old == nil {
+			old, new = fields{}, fields{}
+		}
+		if !unset(av, aok) {
+			old[f] = av
+		}
+		new[f] = null
+		if !unset(bv, bok) {
+			new[f] = bv
+		}
+	}
+	return old, new
+}
+
+// fieldNames returns the names of the fields of a and b, sorted.
+func fieldNames(a, b fields) []string {
+	var names []string
+	for f := range a {
+		names = append(names, f)
+	}
+	for f := range b {
+		if _, ok := a[f]; !ok {
+			names = append(names, f)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// name is a record's title or name, when it has one.
+func name(f fields) string {
+	for _, field := range []string{"title", "name", "country"} {
+		var s string
+		if json.Unmarshal(f[field], &s) == nil && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// fold replays ops into the records they add up to. With since set, it
+// also returns the conflicts that involve operations added after it.
+func fold(ops []*Op, since Heads) (map[key]*record, []Conflict) {
+	state := map[key]*record{}
+	deleted := map[key]*Op{}
+	var conflicts []Conflict
+	report := func(c Conflict, a, b *Op) {
+		if since != nil && (since.after(a) || since.after(b)) {
+			conflicts = append(conflicts, c)
+		}
+	}
+	for _, op := range ops {
+		k := key{op.Kind, op.ID}
+		rec := state[k]
+		switch op.Action {
+		case Delete:
+			if rec == nil {
+				continue
+			}
+			// The deletion loses what another device changed unseen.
+			for _, f := range fieldNames(rec.fields, op.Old) {
+				cur, has := rec.fields[f]
+				old, had := op.Old[f]
+				if by := rec.by[f]; !volatile[f] && !same(cur, has, old, had) && by != nil && by.Device != op.Device {
+					report(Conflict{Kind: op.Kind, ID: op.ID, Name: name(rec.fields), KeptBy: op.Device, LostBy: by.Device, At: op.Time}, op, by)
+					break
+				}
+			}
+			delete(state, k)
+			deleted[k] = op
+			continue
+		case Create:
+			// Restoring a record from the trash brings it back.
+			delete(deleted, k)
+		default:
+			if d := deleted[k]; d != nil {
+				if d.Device != op.Device {
+					report(Conflict{Kind: op.Kind, ID: op.ID, Name: name(d.Old), KeptBy: d.Device, LostBy: op.Device, At: d.Time}, d, op)
+				}
+				continue
+			}
+		}
+		if rec == nil {
+			rec = &record{fields: fields{}, by: map[string]*Op{}}
+			state[k] = rec
+		}
+		rec.trip = op.Trip
+		for _, f := range fieldNames(op.New, nil) {
+			v := op.New[f]
+			cur, has := rec.fields[f]
+			if same(cur, has, v, true) {
+				continue
+			}
+			old, had := op.Old[f]
+			if by := rec.by[f]; !volatile[f] && !same(cur, has, old, had) && by != nil && by.Device != op.Device {
+				report(Conflict{Kind: op.Kind, ID: op.ID, Name: name(rec.fields), Field: f, Kept: v, Lost: cur,
+					KeptBy: op.Device, LostBy: by.Device, At: op.Time}, op, by)
+			}
+			if unset(v, true) {
+				delete(rec.fields, f)
+			} else {
+				rec.fields[f] = v
+			}
+			rec.by[f] = op
+		}
+	}
+	prune(state)
+	return state, conflicts
+}
+
+// refs are the fields of records that refer to a record of another kind,
+// and what the schema does to them when it is deleted: delete them along,
+// or clear the field.
+var refs = []struct {
+	kind, field, to string
+	cascade         bool
+}{
+	{"attachments", "entry_id", "entries", true},
+	{"revisions", "entry_id", "entries", true},
+	{"tracks", "entry_id", "entries", false},
+	{"highlights", "entry_id", "entries", false},
+	{"entries", "leg_id", "legs", false},
+	{"expenses", "leg_id", "legs", false},
+	{"lodgings", "leg_id", "legs", false},
+	{"connectivity", "leg_id", "legs", false},
+	{"documents", "item_id", "itinerary", false},
+	{"documents", "expense_id", "expenses", false},
+	{"lodgings", "expense_id", "expenses", false},
+}
+
+// prune does to the records of state what the database would have done on
+// deleting those they refer to, for a device may have added or changed one
+// after another device deleted what it refers to.
+func prune(state map[key]*record) {
+	for _, ref := range refs {
+		for k, rec := range state {
+			var id string
+			if k.kind != ref.kind || json.Unmarshal(rec.fields[ref.field], &id) != nil || id == "" || state[key{ref.to, id}] != nil {
+				continue
+			}
+			if ref.cascade {
+				delete(state, k)
+			} else {
+				delete(rec.fields, ref.field)
+			}
+		}
+	}
+}
+
+// flatten lists the records of a dump by key.
+func flatten(d *storage.Dump) (map[key]*record, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("oplog: %w", err)
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("oplog: %w", err)
+	}
+	out := map[key]*record{}
+	add := func(kind, trip string, raw json.RawMessage) error {
+		var f fields
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return fmt.Errorf("oplog: %s: %w", kind, err)
+		}
+		out[key{kind, keyOf(kind, f)}] = &record{trip: trip, fields: f}
+		return nil
+	}
+	list := func(kind, trip string, raw json.RawMessage) error {
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return fmt.Errorf("oplog: %s: %w", kind, err)
+		}
+		for _, item := range items {
+			if err := add(kind, trip, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for kind, raw := range doc {
+		switch kind {
+		case "format", "schema", "exported_at", "files":
+		case "achievements":
+			var unlocked map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &unlocked); err != nil {
+				return nil, fmt.Errorf("oplog: %s: %w", kind, err)
+			}
+			for id, at := range unlocked {
+				out[key{kind, id}] = &record{fields: fields{"unlocked_at": at}}
+			}
+		case "trips":
+			var trips []map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &trips); err != nil {
+				return nil, fmt.Errorf("oplog: %s: %w", kind, err)
+			}
+			for _, rec := range trips {
+				if err := add(kind, "", rec["trip"]); err != nil {
+					return nil, err
+				}
+				var trip struct {
+					ID string `json:"id"`
+				}
+				json.Unmarshal(rec["trip"], &trip)
+				for child, raw := range rec {
+					if child == "trip" {
+						continue
+					}
+					if err := list(child, trip.ID, raw); err != nil {
+						return nil, err
+					}
+				}
+			}
+		default:
+			if err := list(kind, "", raw); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// keyOf is the key of a record of kind, as storage.DeleteDumped takes it.
+func keyOf(kind string, f fields) string {
+	str := func(field string) string {
+		var s string
+		json.Unmarshal(f[field], &s)
+		return s
+	}
+	switch kind {
+	case "country_notes":
+		return str("country")
+	case "health":
+		return str("trip_id") + " " + str("day")
+	}
+	return str("id")
+}
+
+// unflatten makes a dump of recs, with the trips of those on a trip from
+// all.
+func unflatten(recs, all map[key]*record) (*storage.Dump, error) {
+	doc := map[string]any{"format": storage.DumpFormat}
+	trips := map[string]map[string]any{}
+	var order []string
+	trip := func(id string) map[string]any {
+		t := trips[id]
+		if t == nil {
+			t = map[string]any{}
+			trips[id] = t
+			order = append(order, id)
+		}
+		return t
+	}
+	achievements := map[string]json.RawMessage{}
+	for _, k := range keys(recs, nil) {
+		r := recs[k]
+		switch {
+		case k.kind == "achievements":
+			achievements[k.id] = r.fields["unlocked_at"]
+		case k.kind == "trips":
+			trip(k.id)["trip"] = r.fields
+		case r.trip != "":
+			t := trip(r.trip)
+			list, _ := t[k.kind].([]fields)
+			t[k.kind] = append(list, r.fields)
+		default:
+			list, _ := doc[k.kind].([]fields)
+			doc[k.kind] = append(list, r.fields)
+		}
+	}
+	var list []map[string]any
+	for _, id := range order {
+		t := trips[id]
+		if t["trip"] == nil {
+			// What is on a trip is imported with it.
+			rec := all[key{"trips", id}]
+			if rec == nil {
+				continue
+			}
+			t["trip"] = rec.fields
+		}
+		list = append(list, t)
+	}
+	doc["trips"] = list
+	doc["achievements"] = achievements
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("oplog: %w", err)
+	}
+	d := &storage.Dump{}
+	if err := json.Unmarshal(data, d); err != nil {
+		return nil, fmt.Errorf("oplog: %w", err)
+	}
+	return d, nil
+}
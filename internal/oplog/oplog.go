@@ -0,0 +1,400 @@
+// Package oplog records the changes made to a store as an append-only log
+// of operations per device, kept in the data directory next to the
+// database, and merges the logs of every device back into the store.
+//
+// Each device appends only to its own log, oplog/<device>.jsonl, so git
+// merges the logs of two devices without a conflict however both changed
+// the journal. Merging folds the operations of every log in the order of
+// their clock, field by field: a field changed on one side takes that
+// change, and only a field changed differently on both sides, or a record
+// changed on one and deleted on the other, is a conflict. The later change
+// wins a conflicting field and a deletion wins over changes, so the fold
+// comes out the same on every device and all of them converge.
+package oplog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// Dir is the directory of the logs in the data directory. stateFile names
+// this device; it is not synced, as every device has its own.
+const (
+	Dir       = "oplog"
+	stateFile = "oplog.json"
+)
+
+// Action is what an operation does to its record.
+type Action string
+
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+// Op is a change to a record of a storage.Dump.
+type Op struct {
+	// Time is when the change was recorded, by a clock that never runs
+	// behind the operations already in the logs of the device, so one
+	// made after seeing another is folded after it.
+	Time   time.Time `json:"time"`
+	Device string    `json:"device"`
+	Action Action    `json:"op"`
+	// Kind is the list of storage.Dump or storage.TripRecord the record
+	// is in, such as "entries", and ID its key, as storage.DeleteDumped
+	// takes them.
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	// Trip is the ID of the trip a record of a TripRecord is on.
+	Trip string `json:"trip,omitempty"`
+	// Old and New are the fields changed, by their JSON names, as the
+	// device had them before and after: a field missing from Old was not
+	// set, and one null in New was cleared. A deletion holds the record
+	// as it was in Old.
+	Old fields `json:"old,omitempty"`
+	New fields `json:"new,omitempty"`
+
+	// seq is the line of the operation in its log, from 1.
+	seq int
+}
+
+// Heads counts the operations in the log of each device, to tell those
+// added since.
+type Heads map[string]int
+
+// after reports whether op was added to its log after h was taken.
+func (h Heads) after(op *Op) bool {
+	return op != nil && op.seq > h[op.Device]
+}
+
+// Conflict is a change a merge lost: to a field that two devices changed
+// differently, or to a record one changed and the other deleted.
+type Conflict struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	// Name is the record's title or name, when it has one.
+	Name string `json:"name,omitempty"`
+	// Field is the field changed on both sides, or empty when the record
+	// was deleted on one.
+	Field string `json:"field,omitempty"`
+	// Kept and Lost are the values of Field kept and lost, and KeptBy and
+	// LostBy the devices that set them. Of a deletion, KeptBy deleted the
+	// record and LostBy changed it.
+	Kept   json.RawMessage `json:"kept,omitempty"`
+	Lost   json.RawMessage `json:"lost,omitempty"`
+	KeptBy string          `json:"kept_by"`
+	LostBy string          `json:"lost_by"`
+	// At is when the change kept was made.
+	At time.Time `json:"at"`
+}
+
+// Log is the operation logs of a data directory, written to as one of its
+// devices.
+type Log struct {
+	dir    string
+	device string
+}
+
+// Enabled reports whether the data directory dir keeps operation logs,
+// which the first Record starts.
+func Enabled(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, Dir))
+	return err == nil && info.IsDir()
+}
+
+// Open returns the logs of the data directory dir, naming this device the
+// first time: by its host name and a random suffix, as two devices may
+// share a name.
+func Open(dir string) (*Log, error) {
+	path := filepath.Join(dir, stateFile)
+	var state struct {
+		Device string `json:"device"`
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("oplog: %s: %w", stateFile, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("oplog: %w", err)
+	}
+	if state.Device == "" {
+		state.Device = deviceName()
+		data, err := json.Marshal(state)
+		if err == nil {
+			err = os.WriteFile(path, data, 0o600)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("oplog: %w", err)
+		}
+	}
+	return &Log{dir: dir, device: state.Device}, nil
+}
+
+// deviceName names a new device after the host, such as "laptop-3fa29c".
+func deviceName() string {
+	host, _ := os.Hostname()
+	host, _, _ = strings.Cut(strings.ToLower(host), ".")
+	var b strings.Builder
+	for _, r := range host {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	name := strings.Trim(b.String(), "-")
+	if name == "" {
+		name = "device"
+	}
+	return name + "-" + models.NewID()[:6]
+}
+
+// Device returns the name of this device in the logs.
+func (l *Log) Device() string { return l.device }
+
+// Heads returns how many operations each device's log holds.
+func (l *Log) Heads() (Heads, error) {
+	_, heads, err := l.read()
+	return heads, err
+}
+
+// read returns the operations of every log in the order they are folded
+// in.
+func (l *Log) read() ([]*Op, Heads, error) {
+	paths, err := filepath.Glob(filepath.Join(l.dir, Dir, "*.jsonl"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("oplog: %w", err)
+	}
+	var ops []*Op
+	heads := Heads{}
+	for _, path := range paths {
+		device := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+		n, err := readLog(path, device, &ops)
+		if err != nil {
+			return nil, nil, err
+		}
+		heads[device] = n
+	}
+	sortOps(ops)
+	return ops, heads, nil
+}
+
+// sortOps puts ops in the order they are folded in, whatever order the
+// logs were read in: by time, then device, then their order in the log.
+func sortOps(ops []*Op) {
+	sort.Slice(ops, func(i, j int) bool {
+		a, b := ops[i], ops[j]
+		switch {
+		case !a.Time.Equal(b.Time):
+			return a.Time.Before(b.Time)
+		case a.Device != b.Device:
+			return a.Device < b.Device
+		}
+		return a.seq < b.seq
+	})
+}
+
+// readLog appends the operations of the log at path, of device, to ops and
+// returns how many there were.
+func readLog(path, device string, ops *[]*Op) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("oplog: %w", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	// A line holds a whole record, such as a long journal entry.
+	sc.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	n := 0
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n++
+		op := &Op{}
+		if err := json.Unmarshal(line, op); err != nil {
+			return 0, fmt.Errorf("oplog: %s line %d: %w", filepath.Base(path), n, err)
+		}
+		// The log's name is the device that wrote it.
+		op.Device, op.seq = device, n
+		*ops = append(*ops, op)
+	}
+	if err := sc.Err(); err != nil {
+		return 0, fmt.Errorf("oplog: %s: %w", filepath.Base(path), err)
+	}
+	return n, nil
+}
+
+// Record appends to this device's log the changes made to the store since
+// the logs were last merged into it or recorded, and returns how many
+// records changed. Recording on a data directory without logs starts them
+// with everything in the store.
+func (l *Log) Record(ctx context.Context, s *storage.Store) (int, error) {
+	ops, _, err := l.read()
+	if err != nil {
+		return 0, err
+	}
+	base, _ := fold(ops, nil)
+	d, err := s.DumpRecords(ctx)
+	if err != nil {
+		return 0, err
+	}
+	cur, err := flatten(d)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	if len(ops) > 0 && !now.After(ops[len(ops)-1].Time) {
+		now = ops[len(ops)-1].Time.Add(time.Microsecond)
+	}
+	var out []*Op
+	for _, k := range keys(base, cur) {
+		b, c := base[k], cur[k]
+		op := &Op{Time: now, Kind: k.kind, ID: k.id}
+		switch {
+		case c == nil:
+			op.Action, op.Trip, op.Old = Delete, b.trip, b.fields
+		case b == nil:
+			op.Action, op.Trip, op.New = Create, c.trip, c.fields
+		default:
+			op.Action, op.Trip = Update, c.trip
+			if op.Old, op.New = diff(b.fields, c.fields); len(op.New) == 0 && b.trip == c.trip {
+				continue
+			}
+		}
+		out = append(out, op)
+	}
+	if len(out) == 0 {
+		return 0, nil
+	}
+	return len(out), l.append(out)
+}
+
+// append writes ops at the end of this device's log.
+func (l *Log) append(ops []*Op) error {
+	if err := os.MkdirAll(filepath.Join(l.dir, Dir), 0o700); err != nil {
+		return fmt.Errorf("oplog: %w", err)
+	}
+	var b bytes.Buffer
+	for _, op := range ops {
+		op.Device = l.device
+		line, err := json.Marshal(op)
+		if err != nil {
+			return fmt.Errorf("oplog: %w", err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	f, err := os.OpenFile(filepath.Join(l.dir, Dir, l.device+".jsonl"), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("oplog: %w", err)
+	}
+	if _, err := f.Write(b.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("oplog: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("oplog: %w", err)
+	}
+	return nil
+}
+
+// Result is what a Merge did.
+type Result struct {
+	// Changed counts the records added or changed, and Deleted those
+	// deleted.
+	Changed, Deleted int
+	Conflicts        []Conflict
+}
+
+// Merge folds the operations of every log into the store, so that it holds
+// what they add up to, and returns the conflicts that involve operations
+// added since the heads since were taken. Changes to the store not
+// recorded yet are lost; Record them first.
+func (l *Log) Merge(ctx context.Context, s *storage.Store, since Heads) (*Result, error) {
+	ops, _, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+	want, conflicts := fold(ops, since)
+	d, err := s.DumpRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := flatten(d)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Conflicts: conflicts}
+	changed := map[key]*record{}
+	for k, w := range want {
+		if c := cur[k]; c == nil || c.trip != w.trip || !sameFields(c.fields, w.fields) {
+			changed[k] = w
+		}
+	}
+	if len(changed) > 0 {
+		dump, err := unflatten(changed, want)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := s.Import(ctx, dump, storage.ImportOptions{Overwrite: true}); err != nil {
+			return nil, err
+		}
+		res.Changed = len(changed)
+		// Importing may add records of its own, such as the revision an
+		// entry keeps of the version it replaces, which go with the rest.
+		if d, err = s.DumpRecords(ctx); err != nil {
+			return nil, err
+		}
+		if cur, err = flatten(d); err != nil {
+			return nil, err
+		}
+	}
+	var gone []key
+	for k := range cur {
+		if want[k] == nil {
+			gone = append(gone, k)
+		}
+	}
+	// What is recorded on a trip goes before the trip, which would take it
+	// along, and the trips before what refers to them.
+	rank := func(k key) int {
+		switch {
+		case k.kind == "trips":
+			return 1
+		case cur[k].trip != "":
+			return 0
+		}
+		return 2
+	}
+	sort.Slice(gone, func(i, j int) bool {
+		if ri, rj := rank(gone[i]), rank(gone[j]); ri != rj {
+			return ri < rj
+		}
+		return gone[i].less(gone[j])
+	})
+	for _, k := range gone {
+		if err := s.DeleteDumped(ctx, k.kind, k.id); err != nil {
+			return nil, err
+		}
+	}
+	res.Deleted = len(gone)
+	return res, nil
+}
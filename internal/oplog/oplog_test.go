@@ -0,0 +1,291 @@
+package oplog
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// device is a data directory of its own, with its store and logs.
+type device struct {
+	dir   string
+	store *storage.Store
+	log   *Log
+}
+
+func open(t *testing.T, dir string) *device {
+	t.Helper()
+	s, err := storage.Open(t.Context(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	l, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &device{dir: dir, store: s, log: l}
+}
+
+func (d *device) record(t *testing.T) {
+	t.Helper()
+	if _, err := d.log.Record(t.Context(), d.store); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (d *device) heads(t *testing.T) Heads {
+	t.Helper()
+	h, err := d.log.Heads()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+// receive copies the log of other into the logs of d, as git would.
+func (d *device) receive(t *testing.T, other *device) {
+	t.Helper()
+	name := other.log.Device() + ".jsonl"
+	copyFile(t, filepath.Join(other.dir, Dir, name), filepath.Join(d.dir, Dir, name))
+}
+
+func (d *device) merge(t *testing.T, since Heads) *Result {
+	t.Helper()
+	res, err := d.log.Merge(t.Context(), d.store, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func (d *device) records(t *testing.T) map[key]*record {
+	t.Helper()
+	dump, err := d.store.DumpRecords(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs, err := flatten(dump)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return recs
+}
+
+// differ returns the keys of the records a and b hold differently.
+func differ(a, b map[key]*record) []key {
+	var out []key
+	for _, k := range keys(a, b) {
+		if ra, rb := a[k], b[k]; ra == nil || rb == nil || ra.trip != rb.trip || !sameFields(ra.fields, rb.fields) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	t.Helper()
+	in, err := os.Open(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// pair starts two devices on a trip with two entries: the laptop writes
+// them, and each merges the log of the other, which also brings over the
+// categories every store starts with.
+func pair(t *testing.T) (laptop, phone *device, trip *models.Trip, kept, gone *models.Entry) {
+	t.Helper()
+	ctx := t.Context()
+	laptop, phone = open(t, t.TempDir()), open(t, t.TempDir())
+	start := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	trip = models.NewTrip("Japan", []string{"Tokyo"}, start)
+	trip.Notes = "Cherry blossoms"
+	if err := laptop.store.SaveTrip(ctx, trip); err != nil {
+		t.Fatal(err)
+	}
+	kept = models.NewEntry(trip.ID, "Landed in Tokyo.", start.Add(10*time.Hour))
+	gone = models.NewEntry(trip.ID, "Ramen at midnight.", start.Add(20*time.Hour))
+	for _, e := range []*models.Entry{kept, gone} {
+		if err := laptop.store.SaveEntry(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	laptop.record(t)
+	phone.receive(t, laptop)
+	phone.merge(t, nil)
+	phone.record(t)
+	laptop.receive(t, phone)
+	laptop.merge(t, nil)
+	if d := differ(laptop.records(t), phone.records(t)); len(d) > 0 {
+		t.Fatalf("the phone holds %v other than the laptop wrote them", d)
+	}
+	return laptop, phone, trip, kept, gone
+}
+
+// TestMergeConverges changes the same trip on two devices, partly the
+// same fields, and has each merge the other's log into its own: both end
+// up with the same journal and report the same conflicts.
+func TestMergeConverges(t *testing.T) {
+	ctx := t.Context()
+	laptop, phone, trip, kept, gone := pair(t)
+
+	// The laptop retitles the trip, notes a budget and edits an entry the
+	// phone deletes.
+	lt := *trip
+	lt.Title, lt.Budget = "Japan 2025", 2000
+	if err := laptop.store.SaveTrip(ctx, &lt); err != nil {
+		t.Fatal(err)
+	}
+	le := *gone
+	le.Text = "Ramen at midnight, then karaoke."
+	if err := laptop.store.SaveEntry(ctx, &le); err != nil {
+		t.Fatal(err)
+	}
+	laptop.record(t)
+
+	// The phone retitles the trip too, sets its end and edits the other
+	// entry.
+	pt := *trip
+	end := trip.StartDate.AddDate(0, 0, 13)
+	pt.Title, pt.EndDate = "Nippon", &end
+	if err := phone.store.SaveTrip(ctx, &pt); err != nil {
+		t.Fatal(err)
+	}
+	pe := *kept
+	pe.Title = "Arrival"
+	if err := phone.store.SaveEntry(ctx, &pe); err != nil {
+		t.Fatal(err)
+	}
+	if err := phone.store.DeleteEntry(ctx, gone.ID); err != nil {
+		t.Fatal(err)
+	}
+	phone.record(t)
+
+	laptopSince, phoneSince := laptop.heads(t), phone.heads(t)
+	laptop.receive(t, phone)
+	phone.receive(t, laptop)
+	fromPhone := laptop.merge(t, laptopSince)
+	fromLaptop := phone.merge(t, phoneSince)
+
+	if d := differ(laptop.records(t), phone.records(t)); len(d) > 0 {
+		t.Errorf("the devices hold %v differently after merging", d)
+	}
+	if !reflect.DeepEqual(fromPhone.Conflicts, fromLaptop.Conflicts) {
+		t.Errorf("the devices report different conflicts:\nlaptop %+v\nphone  %+v", fromPhone.Conflicts, fromLaptop.Conflicts)
+	}
+
+	// Fields changed on one side only are all kept; the later title wins.
+	got, err := laptop.store.GetTrip(ctx, trip.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Nippon" || got.Budget != 2000 || got.EndDate == nil || !got.EndDate.Equal(end) || got.Notes != trip.Notes {
+		t.Errorf("merged trip %q, budget %v, end %v, notes %q", got.Title, got.Budget, got.EndDate, got.Notes)
+	}
+	if e, err := laptop.store.GetEntry(ctx, kept.ID); err != nil || e.Title != "Arrival" {
+		t.Errorf("merged entry %+v, %v, want it titled Arrival", e, err)
+	}
+	if _, err := laptop.store.GetEntry(ctx, gone.ID); err == nil {
+		t.Error("the entry deleted on the phone is still on the laptop")
+	}
+
+	laptopName, phoneName := laptop.log.Device(), phone.log.Device()
+	var titled, deleted bool
+	for _, c := range fromPhone.Conflicts {
+		switch {
+		case c.Kind == "trips" && c.Field == "title":
+			titled = string(c.Kept) == `"Nippon"` && string(c.Lost) == `"Japan 2025"` && c.KeptBy == phoneName && c.LostBy == laptopName
+		case c.Kind == "entries" && c.ID == gone.ID && c.Field == "":
+			deleted = c.KeptBy == phoneName && c.LostBy == laptopName
+		default:
+			t.Errorf("unexpected conflict %+v", c)
+		}
+	}
+	if !titled || !deleted {
+		t.Errorf("conflicts %+v, want the title kept from the phone and the entry deleted there", fromPhone.Conflicts)
+	}
+
+	// Merging again changes nothing and reports nothing new.
+	again := laptop.merge(t, laptop.heads(t))
+	if again.Changed != 0 || again.Deleted != 0 || len(again.Conflicts) != 0 {
+		t.Errorf("merging again: %+v", again)
+	}
+}
+
+// TestFoldIsOrderIndependent folds the operations of two logs read in
+// any order into the same records and conflicts.
+func TestFoldIsOrderIndependent(t *testing.T) {
+	at := time.Date(2025, 4, 1, 9, 0, 0, 0, time.UTC)
+	raw := func(v any) json.RawMessage {
+		data, _ := json.Marshal(v)
+		return data
+	}
+	op := func(device string, seq int, minutes int, action Action, id string, old, new fields) *Op {
+		return &Op{Time: at.Add(time.Duration(minutes) * time.Minute), Device: device, seq: seq, Action: action,
+			Kind: "trips", ID: id, Old: old, New: new}
+	}
+	ops := []*Op{
+		op("laptop", 1, 0, Create, "t1", nil, fields{"id": raw("t1"), "title": raw("Japan"), "notes": raw("Tea")}),
+		op("laptop", 2, 0, Create, "t2", nil, fields{"id": raw("t2"), "title": raw("Peru")}),
+		op("laptop", 3, 5, Update, "t1", fields{"title": raw("Japan")}, fields{"title": raw("Japan 2025")}),
+		op("phone", 1, 5, Update, "t1", fields{"title": raw("Japan")}, fields{"title": raw("Nippon")}),
+		op("phone", 2, 6, Update, "t1", fields{"notes": raw("Tea")}, fields{"notes": null}),
+		op("laptop", 4, 7, Update, "t2", fields{"title": raw("Peru")}, fields{"title": raw("Peru 2025")}),
+		op("phone", 3, 8, Delete, "t2", fields{"id": raw("t2"), "title": raw("Peru")}, nil),
+	}
+	since := Heads{"laptop": 2}
+
+	in := slices.Clone(ops)
+	sortOps(in)
+	want, wantConflicts := fold(in, since)
+	if len(wantConflicts) != 2 {
+		t.Fatalf("%d conflicts %+v, want the title of t1 and the deletion of t2", len(wantConflicts), wantConflicts)
+	}
+	if title := string(want[key{"trips", "t1"}].fields["title"]); title != `"Nippon"` {
+		t.Errorf("t1 titled %s, want the later, Nippon", title)
+	}
+	if _, ok := want[key{"trips", "t1"}].fields["notes"]; ok {
+		t.Error("the notes cleared on the phone are still set")
+	}
+	if want[key{"trips", "t2"}] != nil {
+		t.Error("t2 is still there after the phone deleted it")
+	}
+
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range 20 {
+		in := slices.Clone(ops)
+		r.Shuffle(len(in), func(i, j int) { in[i], in[j] = in[j], in[i] })
+		sortOps(in)
+		got, conflicts := fold(in, since)
+		if d := differ(got, want); len(d) > 0 {
+			t.Errorf("shuffle %d: %v folded differently", i, d)
+		}
+		if !reflect.DeepEqual(conflicts, wantConflicts) {
+			t.Errorf("shuffle %d: conflicts %+v, want %+v", i, conflicts, wantConflicts)
+		}
+	}
+}
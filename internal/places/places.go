@@ -0,0 +1,323 @@
+// Package places resolves place names to countries, coordinates and time
+// zones from a small dataset of cities embedded in the binary, so that
+// country statistics and local times work offline.
+package places
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // time zones must resolve without a system database
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/girdharshubham/nomadic/internal/fuzzy"
+)
+
+//go:embed cities.csv
+var citiesCSV []byte
+
+//go:embed countries.csv
+var countriesCSV []byte
+
+// Place is a city of the dataset.
+type Place struct {
+	Name        string
+	Country     string // ISO 3166-1 alpha-2 code
+	CountryName string
+	Lat, Lon    float64
+	Timezone    string // IANA name, such as "Asia/Tokyo"
+}
+
+// String names the place with its country, as in "Kyoto, Japan".
+func (p Place) String() string {
+	return p.Name + ", " + p.CountryName
+}
+
+// Coordinates formats the place's latitude and longitude.
+func (p Place) Coordinates() string {
+	ns, ew := "N", "E"
+	if p.Lat < 0 {
+		ns = "S"
+	}
+	if p.Lon < 0 {
+		ew = "W"
+	}
+	return fmt.Sprintf("%.2f°%s %.2f°%s", math.Abs(p.Lat), ns, math.Abs(p.Lon), ew)
+}
+
+// Location is the place's time zone, or UTC if it cannot be loaded.
+func (p Place) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+type city struct {
+	Place
+	keys []string // folded name and aliases
+}
+
+var (
+	loadOnce   sync.Once
+	cities     []city
+	countries  map[string]string // name by code
+	continents map[string]string // continent by country code
+	codes      []string          // of the countries, in the dataset's order
+	byName     map[string]string // code by folded name
+)
+
+// load parses the embedded dataset. The files are part of the binary, so a
+// malformed one is a programming error.
+func load() {
+	countries, continents, byName = map[string]string{}, map[string]string{}, map[string]string{}
+	for _, rec := range readCSV(countriesCSV) {
+		countries[rec[0]] = rec[1]
+		continents[rec[0]] = rec[2]
+		codes = append(codes, rec[0])
+		byName[fold(rec[1])] = rec[0]
+		byName[fold(rec[0])] = rec[0]
+	}
+	for _, rec := range readCSV(citiesCSV) {
+		lat, err1 := strconv.ParseFloat(rec[2], 64)
+		lon, err2 := strconv.ParseFloat(rec[3], 64)
+		name, ok := countries[rec[1]]
+		if err1 != nil || err2 != nil || !ok {
+			panic(fmt.Sprintf("places: bad city %q", rec[0]))
+		}
+		c := city{Place: Place{Name: rec[0], Country: rec[1], CountryName: name, Lat: lat, Lon: lon, Timezone: rec[4]}}
+		c.keys = append(c.keys, fold(rec[0]))
+		for _, alias := range strings.Split(rec[5], "|") {
+			if alias != "" {
+				c.keys = append(c.keys, fold(alias))
+			}
+		}
+		cities = append(cities, c)
+	}
+}
+
+func readCSV(data []byte) [][]string {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	recs, err := r.ReadAll()
+	if err != nil {
+		panic("places: " + err.Error())
+	}
+	return recs[1:] // header
+}
+
+// fold normalises a name for matching: lower case, without diacritics and
+// surrounding space, so "Zürich" matches "zurich".
+func fold(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.TrimSpace(s)) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// Lookup finds the city called name, or known by it as an alias. The name
+// may be qualified by a country name or code after a comma, as in
+// "Victoria, CA"; otherwise the best known city of that name is chosen.
+func Lookup(name string) (Place, bool) {
+	loadOnce.Do(load)
+	name, country, _ := strings.Cut(name, ",")
+	key := fold(name)
+	code := ""
+	if country = strings.TrimSpace(country); country != "" {
+		var ok bool
+		if code, ok = byName[fold(country)]; !ok {
+			return Place{}, false
+		}
+	}
+	for _, c := range cities {
+		if code != "" && c.Country != code {
+			continue
+		}
+		for _, k := range c.keys {
+			if k == key {
+				return c.Place, true
+			}
+		}
+	}
+	return Place{}, false
+}
+
+// Search returns up to limit cities whose name starts with prefix,
+// followed by those with an alias starting with it.
+func Search(prefix string, limit int) []Place {
+	loadOnce.Do(load)
+	key := fold(prefix)
+	if key == "" {
+		return nil
+	}
+	var names, aliases []Place
+	for _, c := range cities {
+		switch {
+		case strings.HasPrefix(c.keys[0], key):
+			names = append(names, c.Place)
+		case slices.ContainsFunc(c.keys[1:], func(k string) bool { return strings.HasPrefix(k, key) }):
+			aliases = append(aliases, c.Place)
+		}
+	}
+	out := append(names, aliases...)
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// Suggestion is a city or country matching what was typed, as returned by
+// Suggest.
+type Suggestion struct {
+	Name string
+	// Country is the country of a city, or empty for a country itself.
+	Country string
+	// Positions are the runes of Name matched, or none when the match was
+	// on an alias.
+	Positions []int
+	score     int
+}
+
+// Suggest returns up to limit cities and countries whose names, or the
+// aliases of cities, match query loosely as typed into a place field: its
+// letters in order, compared without case or diacritics. Better matches
+// come first, and among equals the shorter names, which more of the query
+// covers.
+func Suggest(query string, limit int) []Suggestion {
+	loadOnce.Do(load)
+	query = fold(query)
+	if query == "" {
+		return nil
+	}
+	var out []Suggestion
+	for _, c := range cities {
+		score, pos, ok := fuzzy.Match(query, c.keys[0])
+		// A match on an alias scores a point less than one on the name,
+		// so names come first among equals.
+		for _, k := range c.keys[1:] {
+			if s, _, found := fuzzy.Match(query, k); found && (!ok || s-1 > score) {
+				score, pos, ok = s-1, nil, true
+			}
+		}
+		if ok {
+			out = append(out, Suggestion{Name: c.Name, Country: c.CountryName, Positions: pos, score: score})
+		}
+	}
+	for _, code := range codes {
+		name := countries[code]
+		if score, pos, ok := fuzzy.Match(query, fold(name)); ok {
+			out = append(out, Suggestion{Name: name, Positions: pos, score: score})
+		}
+	}
+	slices.SortStableFunc(out, func(a, b Suggestion) int {
+		if a.score != b.score {
+			return b.score - a.score
+		}
+		return len(a.Name) - len(b.Name)
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// Nearest returns the city closest to a position and its distance in
+// metres. It reports false only when the dataset is empty.
+func Nearest(lat, lon float64) (Place, float64, bool) {
+	loadOnce.Do(load)
+	best, dist := -1, math.Inf(1)
+	for i, c := range cities {
+		if d := Distance(lat, lon, c.Lat, c.Lon); d < dist {
+			best, dist = i, d
+		}
+	}
+	if best < 0 {
+		return Place{}, 0, false
+	}
+	return cities[best].Place, dist, true
+}
+
+// CountryName returns the English name of the country with an ISO 3166-1
+// alpha-2 code, or the code itself when it is unknown.
+func CountryName(code string) string {
+	loadOnce.Do(load)
+	if name, ok := countries[strings.ToUpper(code)]; ok {
+		return name
+	}
+	return code
+}
+
+// Continent returns the continent of the country with an ISO 3166-1
+// alpha-2 code, such as "Asia" or "South America", or "" when the country
+// is unknown.
+func Continent(code string) string {
+	loadOnce.Do(load)
+	return continents[strings.ToUpper(code)]
+}
+
+// CountryCode resolves a country's English name or ISO 3166-1 alpha-2
+// code, compared without case or diacritics, to its code.
+func CountryCode(name string) (string, bool) {
+	loadOnce.Do(load)
+	code, ok := byName[fold(name)]
+	return code, ok
+}
+
+// Countries returns the distinct countries of the places, in the order
+// they first appear.
+func Countries(ps []Place) []string {
+	var out []string
+	for _, p := range ps {
+		if !slices.Contains(out, p.Country) {
+			out = append(out, p.Country)
+		}
+	}
+	return out
+}
+
+// Resolve looks up each of names, skipping those that are not known.
+func Resolve(names []string) []Place {
+	var out []Place
+	for _, n := range names {
+		if p, ok := Lookup(n); ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// First returns the first of names that is a known place, skipping empty
+// and unknown ones.
+func First(names ...string) (Place, bool) {
+	for _, n := range names {
+		if p, ok := Lookup(n); ok {
+			return p, true
+		}
+	}
+	return Place{}, false
+}
+
+// earthRadius is the mean radius of the Earth in metres.
+const earthRadius = 6371008.8
+
+// Distance is the great-circle distance between two positions in metres.
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	p1, p2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dLat := p2 - p1
+	dLon := (lon2 - lon1) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(p1)*math.Cos(p2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
@@ -0,0 +1,155 @@
+package places
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed land.txt
+var landTXT []byte
+
+// The world map spans these latitudes, leaving out the far Arctic and
+// Antarctica.
+const (
+	MapNorth = 84.0
+	MapSouth = -60.0
+)
+
+// polygon is an outline from land.txt, in degrees.
+type polygon struct {
+	water    bool
+	lon, lat []float64
+}
+
+var (
+	landOnce sync.Once
+	land     []polygon
+)
+
+// loadLand parses the embedded outlines. Like the cities, a malformed
+// file is a programming error.
+func loadLand() {
+	for n, line := range strings.Split(string(landTXT), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, points, ok := strings.Cut(line, ":")
+		if !ok {
+			panic(fmt.Sprintf("places: land.txt:%d: no name", n+1))
+		}
+		p := polygon{water: strings.HasPrefix(name, "-")}
+		for _, pt := range strings.Fields(points) {
+			lon, lat, _ := strings.Cut(pt, ",")
+			x, err1 := strconv.ParseFloat(lon, 64)
+			y, err2 := strconv.ParseFloat(lat, 64)
+			if err1 != nil || err2 != nil {
+				panic(fmt.Sprintf("places: land.txt:%d: bad point %q", n+1, pt))
+			}
+			p.lon, p.lat = append(p.lon, x), append(p.lat, y)
+		}
+		land = append(land, p)
+	}
+}
+
+// contains reports whether a position is inside the polygon, by counting
+// the edges a ray to the east crosses.
+func (p polygon) contains(lat, lon float64) bool {
+	in := false
+	for i, j := 0, len(p.lon)-1; i < len(p.lon); j, i = i, i+1 {
+		if (p.lat[i] > lat) != (p.lat[j] > lat) &&
+			lon < (p.lon[j]-p.lon[i])*(lat-p.lat[i])/(p.lat[j]-p.lat[i])+p.lon[i] {
+			in = !in
+		}
+	}
+	return in
+}
+
+// onLand reports whether a position is on land rather than at sea or on
+// a large lake.
+func onLand(lat, lon float64) bool {
+	landOnce.Do(loadLand)
+	found := false
+	for _, p := range land {
+		if p.contains(lat, lon) {
+			if p.water {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// Map is the world in a grid of cells, in the equirectangular projection
+// between MapNorth and MapSouth. Each cell on land holds the code of its
+// country.
+type Map struct {
+	Cols, Rows int
+	cells      []string
+}
+
+// WorldMap draws the world in cols by rows cells. Land takes the country
+// of the nearest city of the dataset, which puts borders roughly right at
+// the scale of a terminal, and every city's cell is its own country's, so
+// even small countries show.
+func WorldMap(cols, rows int) *Map {
+	loadOnce.Do(load)
+	m := &Map{Cols: cols, Rows: rows, cells: make([]string, cols*rows)}
+	for row := range rows {
+		lat := MapNorth - (float64(row)+0.5)*(MapNorth-MapSouth)/float64(rows)
+		for col := range cols {
+			lon := -180 + (float64(col)+0.5)*360/float64(cols)
+			if onLand(lat, lon) {
+				m.cells[row*cols+col] = nearestCountry(lat, lon)
+			}
+		}
+	}
+	for _, c := range cities {
+		if col, row, ok := m.Cell(c.Lat, c.Lon); ok {
+			m.cells[row*cols+col] = c.Country
+		}
+	}
+	return m
+}
+
+// At returns the country of a cell, or "" at sea.
+func (m *Map) At(col, row int) string {
+	if col < 0 || col >= m.Cols || row < 0 || row >= m.Rows {
+		return ""
+	}
+	return m.cells[row*m.Cols+col]
+}
+
+// Cell returns the cell a position falls in. It reports false for
+// positions beyond MapNorth or MapSouth.
+func (m *Map) Cell(lat, lon float64) (col, row int, ok bool) {
+	if lat > MapNorth || lat < MapSouth {
+		return 0, 0, false
+	}
+	col = int((lon + 180) / 360 * float64(m.Cols))
+	row = int((MapNorth - lat) / (MapNorth - MapSouth) * float64(m.Rows))
+	return min(max(col, 0), m.Cols-1), min(row, m.Rows-1), true
+}
+
+// nearestCountry returns the country of the city closest to a position.
+// Distances are only compared, so a flat approximation does.
+func nearestCountry(lat, lon float64) string {
+	scale := math.Cos(lat * math.Pi / 180)
+	best, dist := "", math.Inf(1)
+	for _, c := range cities {
+		dlon := math.Abs(c.Lon - lon)
+		if dlon > 180 {
+			dlon = 360 - dlon
+		}
+		dx, dy := dlon*scale, c.Lat-lat
+		if d := dx*dx + dy*dy; d < dist {
+			best, dist = c.Country, d
+		}
+	}
+	return best
+}
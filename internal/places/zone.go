@@ -0,0 +1,36 @@
+package places
+
+import (
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// DayZone returns the IANA name of the time zone trip is in on the
+// calendar day of day: that of at, such as where an entry was written, or
+// else of the leg visited that day, or else of the trip's first
+// destination in the dataset. It returns "" when none of them is known,
+// for the local zone.
+func DayZone(trip *models.Trip, legs []*models.Leg, at string, day time.Time) string {
+	if p, ok := DayPlace(trip, legs, at, day); ok {
+		return p.Timezone
+	}
+	return ""
+}
+
+// DayPlace returns the place trip is at on the calendar day of day, found
+// as DayZone finds its zone, and false when none is known.
+func DayPlace(trip *models.Trip, legs []*models.Leg, at string, day time.Time) (Place, bool) {
+	names := []string{at}
+	if l := models.LegOn(legs, day); l != nil {
+		names = append(names, l.Location)
+	}
+	return First(append(names, trip.Locations...)...)
+}
+
+// TripZone is like DayZone for the instant t, which is on the day it is
+// where the trip is: up to a day apart from the day of t where it was
+// read, around the world.
+func TripZone(trip *models.Trip, legs []*models.Leg, at string, t time.Time) string {
+	return DayZone(trip, legs, at, models.InZone(t, DayZone(trip, legs, at, t)))
+}
@@ -0,0 +1,421 @@
+// Package publish renders the trips and journal entries marked public as a
+// static website: an index of trips by year and by country, a page for
+// each trip and entry, and galleries of the photos attached to them. The
+// site links between its pages relatively, so it can be opened from disk
+// or served as is by GitHub Pages.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+//go:embed templates
+var builtin embed.FS
+
+// Options tunes a published site.
+type Options struct {
+	// Title names the site on every page.
+	Title string
+	// Templates is a directory of files replacing the built-in ones of the
+	// same name: layout.html, index.html, trip.html, entry.html and
+	// style.css. Each page is layout.html executing the "content" template
+	// its own file defines.
+	Templates string
+	// CNAME is the custom domain of a GitHub Pages site, written to the
+	// CNAME file when set.
+	CNAME string
+	// DateLayout is the Go layout dates are shown in.
+	DateLayout string
+	// Redact is what becomes of the private entries of public trips and
+	// the private passages of what is published.
+	Redact models.Redaction
+}
+
+// Result counts what a site was built from.
+type Result struct {
+	Trips, Entries, Photos int
+	// Missing names the photos left out because their file is gone, such
+	// as a linked file since moved.
+	Missing []string
+}
+
+// Public reports which of a trip's entries are published: all of them
+// when the trip is public, otherwise only those marked public themselves.
+func Public(t *models.Trip, entries []*models.Entry) []*models.Entry {
+	if t.Public {
+		return entries
+	}
+	var out []*models.Entry
+	for _, e := range entries {
+		if e.Public {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+type site struct {
+	Title     string
+	Trips     []*trip
+	Years     []group
+	Countries []group
+}
+
+// group is the trips of a year or a country, the latest first.
+type group struct {
+	Name  string
+	Trips []*trip
+}
+
+type trip struct {
+	Title, Dates string
+	// Path is where the page is relative to the root of the site, as are
+	// the paths of entries and photos.
+	Path      string
+	Locations []string
+	Countries []string
+	// Notes and highlights are shown only when the whole trip is public.
+	Notes      template.HTML
+	Highlights []highlight
+	Entries    []*entry
+	Photos     []photo
+	Cover      *photo
+	start      time.Time
+}
+
+type entry struct {
+	Title, Date, Location, Mood string
+	Path                        string
+	Tags                        []string
+	Excerpt                     string
+	Body                        template.HTML
+	Photos                      []photo
+	Trip                        *trip
+	Prev, Next                  *entry
+}
+
+// highlight is a goal of the trip done, linked to the page of the entry
+// that tells of it when that is published.
+type highlight struct {
+	Title string
+	Entry *entry
+}
+
+type photo struct {
+	Src, Name string
+}
+
+// page is what layout.html executes with.
+type page struct {
+	Site  *site
+	Title string
+	// Root leads from the page back to the root of the site, as "../../".
+	Root  string
+	Trip  *trip
+	Entry *entry
+}
+
+// Write builds the site of what is public among trips into dir. The
+// index, style sheet and the trips and media directories are replaced;
+// anything else in dir, such as the .git directory of a GitHub Pages
+// repository, is left alone.
+func Write(ctx context.Context, dir string, store *storage.Store, trips []*models.Trip, opts Options) (Result, error) {
+	var res Result
+	pages, err := loadTemplates(opts.Templates)
+	if err != nil {
+		return res, err
+	}
+	for _, sub := range []string{"trips", "media"} {
+		if err := os.RemoveAll(filepath.Join(dir, sub)); err != nil {
+			return res, err
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "media"), 0o755); err != nil {
+		return res, err
+	}
+
+	s := &site{Title: opts.Title}
+	slugs := map[string]bool{}
+	for _, t := range trips {
+		x, err := export.Load(ctx, store, t)
+		if err != nil {
+			return res, err
+		}
+		x = x.Redact(opts.Redact)
+		entries := Public(t, x.Entries)
+		if !t.Public && len(entries) == 0 {
+			continue
+		}
+		tp := &trip{
+			Title:     t.Title,
+			Dates:     dates(t, opts.DateLayout),
+			Path:      "trips/" + unique(slugs, export.Slug(t.Title), t.ID) + "/index.html",
+			Locations: t.Locations,
+			start:     t.StartDate,
+		}
+		for _, c := range places.Countries(places.Resolve(t.Locations)) {
+			tp.Countries = append(tp.Countries, places.CountryName(c))
+		}
+		if t.Public {
+			tp.Notes = export.RenderMarkdown(x.Notes)
+		}
+		names := map[string]bool{}
+		byID := map[string]*entry{}
+		for _, e := range entries {
+			ep := &entry{
+				Title:    e.Title,
+				Date:     e.Timestamp.Format(opts.DateLayout),
+				Location: e.Location,
+				Path:     path.Dir(tp.Path) + "/" + unique(names, e.Timestamp.Format("2006-01-02-")+export.Slug(e.Title), e.ID) + ".html",
+				Tags:     e.Tags,
+				Excerpt:  excerpt(e.Text),
+				Body:     export.RenderMarkdown(e.Text),
+				Trip:     tp,
+			}
+			if e.Mood > 0 {
+				ep.Mood = models.MoodFace(e.Mood)
+			}
+			// A private entry masked shows no more than its day.
+			if !e.Private {
+				if ep.Photos, err = copyPhotos(ctx, dir, store, e, &res); err != nil {
+					return res, err
+				}
+			}
+			if n := len(tp.Entries); n > 0 {
+				ep.Prev, tp.Entries[n-1].Next = tp.Entries[n-1], ep
+			}
+			tp.Entries = append(tp.Entries, ep)
+			tp.Photos = append(tp.Photos, ep.Photos...)
+			byID[e.ID] = ep
+		}
+		if t.Public {
+			for _, h := range x.Highlights {
+				if h.Done {
+					tp.Highlights = append(tp.Highlights, highlight{Title: h.Title, Entry: byID[h.EntryID]})
+				}
+			}
+		}
+		if len(tp.Photos) > 0 {
+			tp.Cover = &tp.Photos[0]
+		}
+		s.Trips = append(s.Trips, tp)
+		res.Trips++
+		res.Entries += len(tp.Entries)
+	}
+	s.index()
+
+	if err := pages.write(dir, "index.html", "index.html", page{Site: s}); err != nil {
+		return res, err
+	}
+	for _, t := range s.Trips {
+		root := "../../"
+		if err := pages.write(dir, t.Path, "trip.html", page{Site: s, Title: t.Title, Root: root, Trip: t}); err != nil {
+			return res, err
+		}
+		for _, e := range t.Entries {
+			if err := pages.write(dir, e.Path, "entry.html", page{Site: s, Title: e.Title, Root: root, Trip: t, Entry: e}); err != nil {
+				return res, err
+			}
+		}
+	}
+	files := map[string][]byte{"style.css": pages.style, ".nojekyll": nil}
+	if opts.CNAME != "" {
+		files["CNAME"] = []byte(opts.CNAME + "\n")
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// index orders the site's trips the latest first and groups them by the
+// year they started and by the countries they went to.
+func (s *site) index() {
+	slices.SortStableFunc(s.Trips, func(a, b *trip) int { return b.start.Compare(a.start) })
+	byCountry := map[string]*group{}
+	var countries []string
+	for _, t := range s.Trips {
+		year := strconv.Itoa(t.start.Year())
+		if n := len(s.Years); n == 0 || s.Years[n-1].Name != year {
+			s.Years = append(s.Years, group{Name: year})
+		}
+		s.Years[len(s.Years)-1].Trips = append(s.Years[len(s.Years)-1].Trips, t)
+		for _, c := range t.Countries {
+			g, ok := byCountry[c]
+			if !ok {
+				g = &group{Name: c}
+				byCountry[c] = g
+				countries = append(countries, c)
+			}
+			g.Trips = append(g.Trips, t)
+		}
+	}
+	slices.Sort(countries)
+	for _, c := range countries {
+		s.Countries = append(s.Countries, *byCountry[c])
+	}
+}
+
+// unique returns name, or name with the record's ID when another page of
+// the same directory is already called name.
+func unique(used map[string]bool, name, id string) string {
+	if used[name] {
+		name += "-" + id
+	}
+	used[name] = true
+	return name
+}
+
+func dates(t *models.Trip, layout string) string {
+	s := t.StartDate.Format(layout)
+	if t.EndDate != nil {
+		s += " – " + t.EndDate.Format(layout)
+	}
+	return s
+}
+
+// photoExts are the attachments browsers can show, published as photos.
+var photoExts = []string{".jpg", ".jpeg", ".png", ".gif", ".webp", ".avif"}
+
+// copyPhotos copies the photos attached to e into the site's media
+// directory, named after their attachment so they never collide.
+func copyPhotos(ctx context.Context, dir string, store *storage.Store, e *models.Entry, res *Result) ([]photo, error) {
+	atts, err := store.ListAttachmentsByEntry(ctx, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	var out []photo
+	for _, a := range atts {
+		ext := strings.ToLower(filepath.Ext(a.Name))
+		if !slices.Contains(photoExts, ext) {
+			continue
+		}
+		src := "media/" + a.ID + ext
+		err := copyFile(filepath.Join(dir, filepath.FromSlash(src)), store.AttachmentPath(a))
+		if errors.Is(err, fs.ErrNotExist) {
+			res.Missing = append(res.Missing, a.Name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("publish: copy %s: %w", a.Name, err)
+		}
+		out = append(out, photo{Src: src, Name: a.Name})
+		res.Photos++
+	}
+	return out, nil
+}
+
+func copyFile(dst, src string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// excerpt is the start of the first paragraph of Markdown text, without
+// its markup, for the list of a trip's entries.
+func excerpt(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "![") || strings.HasPrefix(line, "|") {
+			continue
+		}
+		line = strings.TrimLeft(line, ">-*+ ")
+		line = strings.NewReplacer("**", "", "__", "", "`", "").Replace(line)
+		if utf8.RuneCountInString(line) > 160 {
+			line = string([]rune(line)[:159]) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// templates holds the parsed page templates of a site and its style
+// sheet.
+type templates struct {
+	pages map[string]*template.Template
+	style []byte
+}
+
+// loadTemplates reads the built-in templates, each replaced by the file of
+// the same name in dir when there is one.
+func loadTemplates(dir string) (*templates, error) {
+	read := func(name string) ([]byte, error) {
+		if dir != "" {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err == nil || !errors.Is(err, fs.ErrNotExist) {
+				return data, err
+			}
+		}
+		return builtin.ReadFile("templates/" + name)
+	}
+	layout, err := read("layout.html")
+	if err != nil {
+		return nil, err
+	}
+	t := &templates{pages: map[string]*template.Template{}}
+	for _, name := range []string{"index.html", "trip.html", "entry.html"} {
+		content, err := read(name)
+		if err != nil {
+			return nil, err
+		}
+		tmpl := template.New(name).Funcs(template.FuncMap{"join": strings.Join})
+		if _, err := tmpl.Parse(string(layout)); err != nil {
+			return nil, fmt.Errorf("publish: layout.html: %w", err)
+		}
+		if _, err := tmpl.Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("publish: %s: %w", name, err)
+		}
+		t.pages[name] = tmpl
+	}
+	if t.style, err = read("style.css"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// write executes the page template name into the file at rel, a slash
+// separated path under dir.
+func (t *templates) write(dir, rel, name string, p page) error {
+	var b bytes.Buffer
+	if err := t.pages[name].ExecuteTemplate(&b, "layout", p); err != nil {
+		return fmt.Errorf("publish: %s: %w", name, err)
+	}
+	dst := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, b.Bytes(), 0o644)
+}
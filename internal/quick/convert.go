@@ -0,0 +1,74 @@
+package quick
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Conversion is an amount to convert read from a line of text by
+// ParseConversion. From and To are empty when the line leaves them out.
+type Conversion struct {
+	Amount float64
+	From   string
+	To     string
+}
+
+// ParseConversion reads an amount to convert typed in one line, such as
+// "100 usd jpy", "€12.50 in gbp" or "3000 to eur": an amount, with the
+// currency it is in next to it as a code or sign, and the currency to
+// convert it to, after it or after to or in.
+func ParseConversion(line string) (Conversion, error) {
+	var c Conversion
+	var from, to []string
+	found, worded := false, false
+	for _, w := range strings.Fields(line) {
+		switch lower := strings.ToLower(w); {
+		case lower == "to" || lower == "in" || lower == "into" || lower == "=":
+			worded = true
+			continue
+		case !found:
+			if amount, cur, ok := parseAmount(w); ok {
+				c.Amount, found = amount, true
+				if cur != "" {
+					from = append(from, cur)
+				}
+				continue
+			}
+		}
+		cur := currencyAt([]string{w}, 0)
+		if cur == "" {
+			// Words such as "try" are codes here.
+			cur = currencyAt([]string{strings.ToUpper(w)}, 0)
+		}
+		switch {
+		case cur == "" && !found:
+			return c, fmt.Errorf("quick: no amount in %q", line)
+		case cur == "":
+			return c, fmt.Errorf("quick: %q is not a currency", w)
+		case worded:
+			to = append(to, cur)
+		default:
+			from = append(from, cur)
+		}
+	}
+	if !found {
+		return c, fmt.Errorf("quick: no amount in %q", line)
+	}
+	if c.Amount <= 0 {
+		return c, errors.New("quick: the amount must be greater than zero")
+	}
+	if len(from) == 2 && len(to) == 0 {
+		from, to = from[:1], from[1:]
+	}
+	if len(from) > 1 || len(to) > 1 {
+		return c, fmt.Errorf("quick: more than two currencies in %q", line)
+	}
+	if len(from) == 1 {
+		c.From = from[0]
+	}
+	if len(to) == 1 {
+		c.To = to[0]
+	}
+	return c, nil
+}
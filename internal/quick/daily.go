@@ -0,0 +1,53 @@
+package quick
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// Scaffold starts today's entry on trip t, as of now where the trip is,
+// leaving only the narrative to write: it is dated today, attributed to
+// the leg of the day and placed at its location, titled with the day of
+// the trip, and lists the day's itinerary. When an entry was written that
+// day already Scaffold leaves the journal alone and returns nil.
+func Scaffold(ctx context.Context, store *storage.Store, t *models.Trip, now time.Time) (*models.Entry, error) {
+	d, err := dayOf(ctx, store, t, now)
+	if err != nil || d.entry != nil {
+		return nil, err
+	}
+	items, err := store.ListItineraryByTrip(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	e := d.start(t)
+	e.Title = fmt.Sprintf("Day %d", models.CalendarDays(t.StartDate, d.now))
+	if e.Location != "" {
+		e.Title += " — " + e.Location
+	}
+	var plan []string
+	for _, it := range items {
+		if !sameDay(it.Day, d.now) {
+			continue
+		}
+		line := "- " + it.Title
+		if it.Time != "" {
+			line = "- " + it.Time + " " + it.Title
+		}
+		if it.Place != "" {
+			line += " (" + it.Place + ")"
+		}
+		plan = append(plan, line)
+	}
+	if len(plan) > 0 {
+		e.Text = "Planned for the day:\n\n" + strings.Join(plan, "\n") + "\n\n"
+	}
+	if err := store.SaveEntry(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
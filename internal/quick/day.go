@@ -0,0 +1,138 @@
+package quick
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// ParseDay reads a day typed into a form: a date in dateLayout or
+// YYYY-MM-DD, or a day relative to today, such as "today", "tomorrow",
+// "fri", "next fri", "last monday", "in 3 days", "2 weeks ago" or "next
+// month". A bare weekday is the next one on or after today, "next" skips
+// today and "last" goes back before it. Offsets written with a sign, such
+// as "+3d", "-1w", "+2m" or "+1y", count from from, the start of the range
+// the day ends, or from today when from is zero. The day returned is
+// midnight in the location of today.
+func ParseDay(v string, today, from time.Time, dateLayout string) (time.Time, error) {
+	v = strings.TrimSpace(v)
+	if d, err := time.ParseInLocation(dateLayout, v, today.Location()); err == nil {
+		return d, nil
+	}
+	if d, err := time.ParseInLocation(models.DateLayout, v, today.Location()); err == nil {
+		return d, nil
+	}
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	if from.IsZero() {
+		from = today
+	}
+	words := strings.Fields(strings.ToLower(v))
+	switch {
+	case len(words) == 0:
+	case len(words) == 1 && words[0] == "today":
+		return today, nil
+	case len(words) == 1 && words[0] == "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case len(words) == 1 && words[0] == "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case strings.HasPrefix(v, "+") || strings.HasPrefix(v, "-"):
+		if n, unit, ok := offset(strings.Join(words, "")[1:]); ok {
+			if v[0] == '-' {
+				n = -n
+			}
+			return addDays(from, n, unit), nil
+		}
+	case len(words) == 2 && (words[0] == "next" || words[0] == "last" || words[0] == "this"):
+		if wd, ok := weekdayPrefix(words[1]); ok {
+			ahead := (int(wd) - int(today.Weekday()) + 7) % 7
+			switch words[0] {
+			case "next":
+				if ahead == 0 {
+					ahead = 7
+				}
+			case "last":
+				ahead -= 7
+			}
+			return today.AddDate(0, 0, ahead), nil
+		}
+		if _, unit, ok := offset("1" + words[1]); ok && words[0] != "this" {
+			n := 1
+			if words[0] == "last" {
+				n = -1
+			}
+			return addDays(today, n, unit), nil
+		}
+	case len(words) == 1:
+		if wd, ok := weekdayPrefix(words[0]); ok {
+			return today.AddDate(0, 0, (int(wd)-int(today.Weekday())+7)%7), nil
+		}
+	case words[0] == "in":
+		if n, unit, ok := offset(strings.Join(words[1:], "")); ok {
+			return addDays(today, n, unit), nil
+		}
+	case words[len(words)-1] == "ago":
+		if n, unit, ok := offset(strings.Join(words[:len(words)-1], "")); ok {
+			return addDays(today, -n, unit), nil
+		}
+	}
+	return time.Time{}, errors.New("quick: not a day")
+}
+
+// offset reads a count of days, weeks, months or years such as "3d",
+// "2weeks" or "1m", returning the unit as its first letter. A bare number
+// counts days.
+func offset(s string) (int, byte, bool) {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	n, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return 0, 0, false
+	}
+	switch unit := s[i:]; unit {
+	case "", "d", "day", "days":
+		return n, 'd', true
+	case "w", "wk", "wks", "week", "weeks":
+		return n, 'w', true
+	case "m", "mo", "month", "months":
+		return n, 'm', true
+	case "y", "yr", "yrs", "year", "years":
+		return n, 'y', true
+	}
+	return 0, 0, false
+}
+
+// addDays moves day n of unit away. A month or year away from a day its
+// month is too short for lands on the last day of that month.
+func addDays(day time.Time, n int, unit byte) time.Time {
+	switch unit {
+	case 'w':
+		return day.AddDate(0, 0, 7*n)
+	case 'm', 'y':
+		if unit == 'y' {
+			n *= 12
+		}
+		y, m, d := day.Date()
+		first := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, day.Location())
+		return first.AddDate(0, 0, min(d, first.AddDate(0, 1, -1).Day())-1)
+	}
+	return day.AddDate(0, 0, n)
+}
+
+// weekdayPrefix reads the name of a day of the week or its first three
+// letters or more, such as "fri" or "tues".
+func weekdayPrefix(w string) (time.Weekday, bool) {
+	if len(w) < 3 {
+		return 0, false
+	}
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), w) {
+			return d, true
+		}
+	}
+	return 0, false
+}
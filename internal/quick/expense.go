@@ -0,0 +1,309 @@
+package quick
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/currency"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Expense is an expense read from a line of text by ParseExpense. What the
+// line leaves out is empty: Currency, Description, and Day when no day was
+// named.
+type Expense struct {
+	Amount      float64
+	Currency    string
+	Category    string
+	Description string
+	// Day is midnight of the day the money was spent.
+	Day time.Time
+}
+
+// symbols are the currency signs written before or after an amount.
+var symbols = map[string]string{
+	"€": "EUR", "£": "GBP", "$": "USD", "¥": "JPY", "₹": "INR", "₩": "KRW", "฿": "THB",
+	"₫": "VND", "₺": "TRY", "₽": "RUB", "₱": "PHP", "₪": "ILS", "₴": "UAH", "₦": "NGN",
+}
+
+// notCurrencies are English words that are also currency codes, read as
+// words unless written in capitals.
+var notCurrencies = map[string]bool{
+	"all": true, "bam": true, "bob": true, "cop": true, "cup": true, "gel": true,
+	"mad": true, "mop": true, "pen": true, "sos": true, "top": true, "try": true,
+}
+
+// keywords guess the category of an expense from the words describing it.
+var keywords = map[string]string{
+	"bakery": models.CategoryFood, "bar": models.CategoryFood, "beer": models.CategoryFood,
+	"beers": models.CategoryFood, "breakfast": models.CategoryFood, "brunch": models.CategoryFood,
+	"cafe": models.CategoryFood, "café": models.CategoryFood, "coffee": models.CategoryFood,
+	"dinner": models.CategoryFood, "drinks": models.CategoryFood, "groceries": models.CategoryFood,
+	"lunch": models.CategoryFood, "pizza": models.CategoryFood, "ramen": models.CategoryFood,
+	"restaurant": models.CategoryFood, "snack": models.CategoryFood, "snacks": models.CategoryFood,
+	"sushi": models.CategoryFood, "supermarket": models.CategoryFood, "tapas": models.CategoryFood,
+	"wine": models.CategoryFood,
+
+	"bike": models.CategoryTransport, "bus": models.CategoryTransport, "cab": models.CategoryTransport,
+	"ferry": models.CategoryTransport, "flight": models.CategoryTransport, "fuel": models.CategoryTransport,
+	"metro": models.CategoryTransport, "parking": models.CategoryTransport, "petrol": models.CategoryTransport,
+	"subway": models.CategoryTransport, "taxi": models.CategoryTransport, "toll": models.CategoryTransport,
+	"train": models.CategoryTransport, "tram": models.CategoryTransport, "uber": models.CategoryTransport,
+
+	"airbnb": models.CategoryLodging, "apartment": models.CategoryLodging, "camping": models.CategoryLodging,
+	"guesthouse": models.CategoryLodging, "hostel": models.CategoryLodging, "hotel": models.CategoryLodging,
+	"room": models.CategoryLodging, "ryokan": models.CategoryLodging,
+
+	"cinema": models.CategoryActivities, "concert": models.CategoryActivities, "entrance": models.CategoryActivities,
+	"gallery": models.CategoryActivities, "museum": models.CategoryActivities, "show": models.CategoryActivities,
+	"temple": models.CategoryActivities, "tickets": models.CategoryActivities, "tour": models.CategoryActivities,
+
+	"clothes": models.CategoryShopping, "gift": models.CategoryShopping, "gifts": models.CategoryShopping,
+	"market": models.CategoryShopping, "shoes": models.CategoryShopping, "shop": models.CategoryShopping,
+	"souvenir": models.CategoryShopping, "souvenirs": models.CategoryShopping,
+}
+
+// ParseExpense reads an expense typed in one line, such as "14.50 eur
+// lunch ramen yesterday": an amount, with a currency code or sign next to
+// it, a day (today, yesterday, a weekday, "3 days ago" or a date in
+// dateLayout or YYYY-MM-DD), and what it was for. A category named in the
+// line is taken; otherwise it is guessed from rules, then from common
+// words, and is other failing both. Days are read relative to now.
+func ParseExpense(line string, now time.Time, dateLayout string, rules []*models.Rule) (Expense, error) {
+	words := strings.Fields(line)
+	var x Expense
+	words, x.Day = takeDay(words, now, dateLayout)
+
+	at := -1
+	for i, w := range words {
+		amount, cur, ok := parseAmount(w)
+		if !ok {
+			continue
+		}
+		// A bare "2" in "2 beers 9.50" is a count; prefer amounts with
+		// decimals or a currency.
+		explicit := cur != "" || strings.ContainsAny(w, ".,") || currencyAt(words, i-1) != "" || currencyAt(words, i+1) != ""
+		if at < 0 || explicit {
+			at, x.Amount, x.Currency = i, amount, cur
+		}
+		if explicit {
+			break
+		}
+	}
+	if at < 0 {
+		return x, fmt.Errorf("quick: no amount in %q", line)
+	}
+	if x.Amount <= 0 {
+		return x, errors.New("quick: the amount must be greater than zero")
+	}
+	used := map[int]bool{at: true}
+	if x.Currency == "" {
+		if c := currencyAt(words, at+1); c != "" {
+			x.Currency, used[at+1] = c, true
+		} else if c := currencyAt(words, at-1); c != "" {
+			x.Currency, used[at-1] = c, true
+		}
+	}
+
+	var rest []string
+	for i, w := range words {
+		if used[i] {
+			continue
+		}
+		if c, ok := category(w); ok && x.Category == "" {
+			x.Category = c
+			continue
+		}
+		rest = append(rest, w)
+	}
+	x.Description = strings.Join(rest, " ")
+	if x.Category == "" {
+		x.Category = guessCategory(x.Description, rules)
+	}
+	return x, nil
+}
+
+// takeDay removes the words naming a day from words and returns the day,
+// or zero when none is named. "on" before a day goes with it.
+func takeDay(words []string, now time.Time, dateLayout string) ([]string, time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for i := 0; i < len(words); i++ {
+		w := strings.ToLower(words[i])
+		var (
+			day time.Time
+			n   = 1
+		)
+		switch {
+		case w == "today":
+			day = today
+		case w == "yesterday":
+			day = today.AddDate(0, 0, -1)
+		case i+2 < len(words) && isDays(words[i+1]) && strings.EqualFold(words[i+2], "ago"):
+			if k, err := strconv.Atoi(w); err == nil && k >= 0 {
+				day, n = today.AddDate(0, 0, -k), 3
+			}
+		default:
+			if wd, ok := weekday(w); ok {
+				// A weekday is the last one on or before today; "last"
+				// goes back before today.
+				back := (int(today.Weekday()) - int(wd) + 7) % 7
+				if i > 0 && strings.EqualFold(words[i-1], "last") {
+					if back == 0 {
+						back = 7
+					}
+					i--
+					n++
+				}
+				day = today.AddDate(0, 0, -back)
+			} else if d, err := time.ParseInLocation(dateLayout, words[i], now.Location()); err == nil {
+				day = d
+			} else if d, err := time.ParseInLocation("2006-01-02", words[i], now.Location()); err == nil {
+				day = d
+			}
+		}
+		if day.IsZero() {
+			continue
+		}
+		if i > 0 && strings.EqualFold(words[i-1], "on") {
+			i--
+			n++
+		}
+		return append(words[:i:i], words[i+n:]...), day
+	}
+	return words, time.Time{}
+}
+
+func isDays(w string) bool {
+	w = strings.ToLower(w)
+	return w == "day" || w == "days"
+}
+
+// weekday reads the name of a day of the week. Abbreviations are not
+// taken: "sun" and "sat" are words too.
+func weekday(w string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if w == strings.ToLower(d.String()) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// parseAmount reads an amount, perhaps with a currency sign or code
+// attached, such as "14.50", "€14,50", "1,200.00" or "12eur".
+func parseAmount(w string) (float64, string, bool) {
+	var cur string
+	for sign, code := range symbols {
+		if rest, ok := strings.CutPrefix(w, sign); ok {
+			w, cur = rest, code
+			break
+		}
+		if rest, ok := strings.CutSuffix(w, sign); ok {
+			w, cur = rest, code
+			break
+		}
+	}
+	if cur == "" && len(w) > 3 {
+		if c := currencyCode(w[len(w)-3:]); c != "" && isNumber(w[:len(w)-3]) {
+			w, cur = w[:len(w)-3], c
+		} else if c := currencyCode(w[:3]); c != "" && isNumber(w[3:]) {
+			w, cur = w[3:], c
+		}
+	}
+	if !isNumber(w) {
+		return 0, "", false
+	}
+	// The last of a comma and a point separates the decimals; before it
+	// they group thousands. A lone comma is decimal before one or two
+	// digits, as in "14,50".
+	dot, comma := strings.LastIndex(w, "."), strings.LastIndex(w, ",")
+	switch {
+	case dot >= 0 && comma >= 0 && comma > dot:
+		w = strings.ReplaceAll(w[:comma], ".", "") + "." + w[comma+1:]
+	case dot >= 0 && comma >= 0:
+		w = strings.ReplaceAll(w, ",", "")
+	case comma >= 0 && strings.Count(w, ",") == 1 && len(w)-comma-1 <= 2:
+		w = strings.Replace(w, ",", ".", 1)
+	default:
+		w = strings.ReplaceAll(w, ",", "")
+	}
+	v, err := strconv.ParseFloat(w, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return v, cur, true
+}
+
+func isNumber(w string) bool {
+	if w == "" || !unicode.IsDigit(rune(w[0])) {
+		return false
+	}
+	for _, r := range w {
+		if !unicode.IsDigit(r) && r != '.' && r != ',' {
+			return false
+		}
+	}
+	return true
+}
+
+// currencyAt returns the currency named by words[i], a code or a sign, or
+// empty.
+func currencyAt(words []string, i int) string {
+	if i < 0 || i >= len(words) {
+		return ""
+	}
+	if c, ok := symbols[words[i]]; ok {
+		return c
+	}
+	return currencyCode(words[i])
+}
+
+// currencyCode returns w as an ISO 4217 code if it is one.
+func currencyCode(w string) string {
+	if len(w) != 3 || notCurrencies[w] {
+		return ""
+	}
+	for _, r := range w {
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			return ""
+		}
+	}
+	u, err := currency.ParseISO(w)
+	if err != nil || u == currency.XXX {
+		return ""
+	}
+	return u.String()
+}
+
+// category reports whether w names a category outright.
+func category(w string) (string, bool) {
+	w = strings.ToLower(w)
+	for _, c := range models.Categories {
+		if w == c {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// guessCategory picks a category for description: a rule's, else that of
+// the first common word in it, else other.
+func guessCategory(description string, rules []*models.Rule) string {
+	if r := models.MatchRule(rules, description); r != nil {
+		return r.Category
+	}
+	for _, w := range strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}) {
+		// Built-in categories may have been renamed or merged away.
+		if c, ok := keywords[w]; ok && models.LookupCategory(c) != nil {
+			return c
+		}
+	}
+	return models.CategoryOther
+}
@@ -0,0 +1,104 @@
+package quick
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// wednesday is when the tests type their lines.
+var wednesday = time.Date(2025, 4, 9, 15, 30, 0, 0, time.UTC)
+
+func april(d int) time.Time {
+	return time.Date(2025, time.April, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseExpense(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		want Expense
+	}{
+		{"14.50 eur lunch ramen yesterday", Expense{Amount: 14.50, Currency: "EUR", Category: models.CategoryFood, Description: "lunch ramen", Day: april(8)}},
+		{"€14,50 taxi", Expense{Amount: 14.50, Currency: "EUR", Category: models.CategoryTransport, Description: "taxi"}},
+		{"1,200.00 JPY hotel 3 days ago", Expense{Amount: 1200, Currency: "JPY", Category: models.CategoryLodging, Description: "hotel", Day: april(6)}},
+		{"2 beers 9.50 on monday", Expense{Amount: 9.50, Category: models.CategoryFood, Description: "2 beers", Day: april(7)}},
+		{"museum 12 last wednesday", Expense{Amount: 12, Category: models.CategoryActivities, Description: "museum", Day: april(2)}},
+		{"museum 12 wednesday", Expense{Amount: 12, Category: models.CategoryActivities, Description: "museum", Day: april(9)}},
+		{"30usd shopping souvenirs 2025-04-01", Expense{Amount: 30, Currency: "USD", Category: models.CategoryShopping, Description: "souvenirs", Day: april(1)}},
+	} {
+		got, err := ParseExpense(tt.line, wednesday, "02/01/2006", nil)
+		if err != nil {
+			t.Errorf("%q: %v", tt.line, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseExpenseRefusesLinesWithoutAnAmount(t *testing.T) {
+	for _, line := range []string{"lunch", "0 eur lunch", ""} {
+		if _, err := ParseExpense(line, wednesday, models.DateLayout, nil); err == nil {
+			t.Errorf("%q: no error", line)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for _, tt := range []struct {
+		w        string
+		amount   float64
+		currency string
+		ok       bool
+	}{
+		{"14.50", 14.50, "", true},
+		{"€14,50", 14.50, "EUR", true},
+		{"14,50€", 14.50, "EUR", true},
+		{"1,200.00", 1200, "", true},
+		{"1.200,00", 1200, "", true},
+		{"1,200", 1200, "", true},
+		{"12eur", 12, "EUR", true},
+		{"usd30", 30, "USD", true},
+		{"¥800", 800, "JPY", true},
+		{"lunch", 0, "", false},
+		{"eur", 0, "", false},
+		{"€", 0, "", false},
+	} {
+		amount, currency, ok := parseAmount(tt.w)
+		if amount != tt.amount || currency != tt.currency || ok != tt.ok {
+			t.Errorf("parseAmount(%q) = %v, %q, %v, want %v, %q, %v", tt.w, amount, currency, ok, tt.amount, tt.currency, tt.ok)
+		}
+	}
+}
+
+func TestTakeDay(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		rest string
+		day  time.Time
+	}{
+		{"lunch today", "lunch", april(9)},
+		{"lunch yesterday", "lunch", april(8)},
+		{"lunch 3 days ago", "lunch", april(6)},
+		{"lunch 1 day ago", "lunch", april(8)},
+		{"lunch monday", "lunch", april(7)},
+		{"lunch on monday", "lunch", april(7)},
+		{"lunch last monday", "lunch", april(7)},
+		{"lunch thursday", "lunch", april(3)},
+		{"lunch wednesday", "lunch", april(9)},
+		{"lunch last wednesday", "lunch", april(2)},
+		{"lunch on last wednesday", "lunch", april(2)},
+		{"lunch 2025-04-01", "lunch", april(1)},
+		{"lunch 01/04/2025", "lunch", april(1)},
+		{"lunch sat", "lunch sat", time.Time{}},
+		{"lunch", "lunch", time.Time{}},
+	} {
+		words, got := takeDay(strings.Fields(tt.line), wednesday, "02/01/2006")
+		if rest := strings.Join(words, " "); rest != tt.rest || !got.Equal(tt.day) {
+			t.Errorf("takeDay(%q) = %q, %v, want %q, %v", tt.line, rest, got, tt.rest, tt.day)
+		}
+	}
+}
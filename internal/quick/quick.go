@@ -0,0 +1,94 @@
+// Package quick captures things on the move with as little typing as
+// possible: notes, each appended to the day's journal entry of a trip
+// without opening the editor, the day's entry started with what is known
+// of the day, expenses typed as they would be said or read from the text
+// of their receipts, and amounts to convert between currencies.
+package quick
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// Capture appends note, stamped with the time of now where the trip is,
+// to today's entry on trip t: the last one written that day there. When
+// there is none yet it starts one, titled with the day in dateLayout and
+// attributed to the leg of the day. It returns the entry and whether it
+// was new.
+func Capture(ctx context.Context, store *storage.Store, t *models.Trip, note string, now time.Time, dateLayout string) (*models.Entry, bool, error) {
+	if note == "" {
+		return nil, false, errors.New("quick: empty note")
+	}
+	d, err := dayOf(ctx, store, t, now)
+	if err != nil {
+		return nil, false, err
+	}
+	today, created := d.entry, d.entry == nil
+	if created {
+		today = d.start(t)
+		today.Title = d.now.Format(dateLayout)
+	}
+	today.AppendNote(note, d.now)
+	if err := store.SaveEntry(ctx, today); err != nil {
+		return nil, false, err
+	}
+	return today, created, nil
+}
+
+// day is a calendar day of a trip, as of a moment in it.
+type day struct {
+	// now is the moment, read where the trip is then.
+	now  time.Time
+	zone string
+	leg  *models.Leg
+	// entry is the last one written on the trip that day, nil when there
+	// is none.
+	entry *models.Entry
+}
+
+// dayOf looks up the day of trip t at now.
+func dayOf(ctx context.Context, store *storage.Store, t *models.Trip, now time.Time) (day, error) {
+	legs, err := store.ListLegsByTrip(ctx, t.ID)
+	if err != nil {
+		return day{}, err
+	}
+	d := day{zone: places.TripZone(t, legs, "", now)}
+	d.now = models.InZone(now, d.zone)
+	d.leg = models.LegOn(legs, d.now)
+	// Only the entries of the day are read, and a second either side, as
+	// timestamps are compared as text.
+	y, m, dd := d.now.Date()
+	from := time.Date(y, m, dd, 0, 0, 0, 0, time.Local)
+	q := storage.EntryQuery{TripID: t.ID, From: from.Add(-time.Second), To: from.AddDate(0, 0, 1).Add(time.Second)}
+	entries, err := store.ListEntryPage(ctx, q, 0, -1)
+	if err != nil {
+		return day{}, err
+	}
+	for _, e := range entries {
+		if sameDay(e.Timestamp, d.now) && (d.entry == nil || e.Timestamp.After(d.entry.Timestamp)) {
+			d.entry = e
+		}
+	}
+	return d, nil
+}
+
+// start begins the day's entry, attributed to the leg of the day.
+func (d day) start(t *models.Trip) *models.Entry {
+	e := models.NewEntry(t.ID, "", d.now)
+	e.TimeZone = d.zone
+	if d.leg != nil {
+		e.LegID, e.Location = d.leg.ID, d.leg.Location
+	}
+	return e
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,216 @@
+package quick
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Receipt is what ReadReceipt makes of the text of a receipt. What it
+// could not make out is empty: Amount is zero, Day is zero.
+type Receipt struct {
+	Amount   float64
+	Currency string
+	Merchant string
+	Category string
+	// Day is midnight of the day printed on the receipt.
+	Day time.Time
+}
+
+// totalWords start the line of a receipt with the amount paid, in the
+// languages receipts are most often printed in.
+var totalWords = []string{
+	"total", "totaal", "totale", "gesamt", "summe", "importe", "montant", "amount", "balance",
+	"to pay", "zu zahlen", "a pagar", "à payer", "da pagare", "合計", "合计", "총액",
+}
+
+// notTotalWords mark lines naming a total that is not the amount paid.
+var notTotalWords = []string{
+	"sub total", "sub-total", "zwischensumme", "net", "tax", "vat", "iva", "tva", "mwst",
+	"tip", "change", "cash", "saved", "items", "qty",
+}
+
+// ReadReceipt reads the amount paid, its currency, the merchant and the
+// day from the text of a receipt read by OCR, such as:
+//
+//	PINGO DOCE
+//	Rua Augusta 12, Lisboa
+//	14/10/2026 13:02
+//	Pastel de nata   1,30
+//	Café             0,90
+//	TOTAL EUR        2,20
+//
+// The amount is that of the line naming the total, or failing one the
+// largest amount with decimals; the merchant is the first line of words at
+// the top. The category is guessed from the merchant as for ParseExpense.
+// Dates are read in dateLayout or as YYYY-MM-DD.
+func ReadReceipt(text, dateLayout string, rules []*models.Rule) Receipt {
+	var lines []string
+	for _, l := range strings.Split(text, "\n") {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	var r Receipt
+	r.Merchant = merchant(lines)
+	r.Amount, r.Currency = total(lines)
+	if r.Currency == "" {
+		r.Currency = firstCurrency(lines)
+	}
+	r.Day = receiptDay(lines, dateLayout)
+	r.Category = guessCategory(r.Merchant, rules)
+	return r
+}
+
+// merchant returns the first of the top lines mostly made of letters, in
+// title case when it is printed in capitals.
+func merchant(lines []string) string {
+	for _, l := range lines[:min(len(lines), 5)] {
+		letters, others := 0, 0
+		for _, r := range l {
+			switch {
+			case unicode.IsLetter(r):
+				letters++
+			case !unicode.IsSpace(r):
+				others++
+			}
+		}
+		if letters < 3 || letters < 2*others {
+			continue
+		}
+		if strings.ToUpper(l) != l {
+			return l
+		}
+		words := strings.Fields(strings.ToLower(l))
+		for i, w := range words {
+			rs := []rune(w)
+			rs[0] = unicode.ToUpper(rs[0])
+			words[i] = string(rs)
+		}
+		return strings.Join(words, " ")
+	}
+	return ""
+}
+
+// total returns the amount of the first line naming the total paid, or of
+// the line after it when it names none, and its currency. Without such a
+// line it returns the largest amount with decimals.
+func total(lines []string) (float64, string) {
+	for i, l := range lines {
+		lower := strings.ToLower(l)
+		if !containsAny(lower, totalWords) || containsAny(lower, notTotalWords) {
+			continue
+		}
+		if amount, cur, ok := lastAmount(l, false); ok {
+			return amount, cur
+		}
+		if i+1 < len(lines) {
+			if amount, cur, ok := lastAmount(lines[i+1], false); ok {
+				return amount, cur
+			}
+		}
+	}
+	var (
+		best float64
+		cur  string
+	)
+	for _, l := range lines {
+		if amount, c, ok := lastAmount(l, true); ok && amount > best {
+			best, cur = amount, c
+		}
+	}
+	return best, cur
+}
+
+// lastAmount returns the last amount on line and the currency next to it,
+// only amounts with decimals when decimals is set, so that quantities and
+// phone numbers are not taken.
+func lastAmount(line string, decimals bool) (float64, string, bool) {
+	words := strings.Fields(line)
+	for i, w := range words {
+		words[i] = strings.Trim(w, ":*")
+	}
+	for i := len(words) - 1; i >= 0; i-- {
+		w := words[i]
+		amount, cur, ok := parseAmount(w)
+		if !ok || amount <= 0 || (decimals && !hasDecimals(w)) {
+			continue
+		}
+		if cur == "" {
+			if cur = currencyAt(words, i+1); cur == "" {
+				cur = currencyAt(words, i-1)
+			}
+		}
+		return amount, cur, true
+	}
+	return 0, "", false
+}
+
+// hasDecimals reports whether w ends in a separator and two digits, as
+// amounts of money do.
+func hasDecimals(w string) bool {
+	w = strings.TrimRightFunc(w, func(r rune) bool { return !unicode.IsDigit(r) })
+	return len(w) > 3 && strings.ContainsRune(".,", rune(w[len(w)-3])) &&
+		unicode.IsDigit(rune(w[len(w)-2])) && unicode.IsDigit(rune(w[len(w)-1]))
+}
+
+// firstCurrency returns the first currency sign or code on a receipt. Codes
+// must be in capitals, as receipts print them, so that words are not read
+// as codes.
+func firstCurrency(lines []string) string {
+	for _, l := range lines {
+		for _, w := range strings.Fields(l) {
+			if _, cur, ok := parseAmount(strings.Trim(w, ":*")); ok && cur != "" {
+				return cur
+			}
+			for sign, code := range symbols {
+				if strings.Contains(w, sign) {
+					return code
+				}
+			}
+			if w = strings.Trim(w, ":*.,()"); strings.ToUpper(w) == w {
+				if c := currencyCode(w); c != "" {
+					return c
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// receiptDay returns the first date on a receipt in dateLayout or as
+// YYYY-MM-DD, or zero.
+func receiptDay(lines []string, dateLayout string) time.Time {
+	for _, l := range lines {
+		for _, w := range strings.Fields(l) {
+			for _, layout := range []string{dateLayout, models.DateLayout} {
+				if d, err := time.ParseInLocation(layout, w, time.Local); err == nil {
+					return d
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// containsAny reports whether one of words starts a word of s, so that
+// "total" is in "totale" but not in "subtotal".
+func containsAny(s string, words []string) bool {
+	for _, w := range words {
+		for i := 0; ; {
+			j := strings.Index(s[i:], w)
+			if j < 0 {
+				break
+			}
+			i += j
+			if r, _ := utf8.DecodeLastRuneInString(s[:i]); i == 0 || !unicode.IsLetter(r) {
+				return true
+			}
+			i += len(w)
+		}
+	}
+	return false
+}
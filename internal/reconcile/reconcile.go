@@ -0,0 +1,205 @@
+// Package reconcile checks the expenses logged over a stretch of days
+// against a bank or card statement: each charge on the statement is
+// matched with the expense it was logged as, and the charges never logged,
+// the expenses the statement does not show and the gap between the two
+// totals are what is left to look into.
+package reconcile
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// window is how many days apart a charge and the expense it was logged as
+// may be, as banks post card payments a day or few late.
+const window = 3
+
+// tolerance is how far apart, as a fraction, a charge and an expense in
+// another currency may be once converted, as the bank's rate and its fees
+// differ from the day's.
+const tolerance = 0.03
+
+// Converter converts amount between currencies.
+type Converter func(amount float64, from, to string) (float64, error)
+
+// Match pairs a charge with the expense it was logged as.
+type Match struct {
+	Charge  *models.Expense
+	Expense *models.Expense
+}
+
+// Options says what to reconcile.
+type Options struct {
+	// From and To are the first and last days reconciled. A zero From or
+	// To is the day of the first or last charge.
+	From, To time.Time
+	// Currency is the statement's, which totals are in.
+	Currency string
+	// Total is the statement's total as printed on it, when it is known;
+	// else the charges are added up.
+	Total float64
+	// Convert converts expenses in other currencies, and may be nil.
+	Convert Converter
+}
+
+// Reconciliation is how the expenses logged over From to To compare with
+// the statement.
+type Reconciliation struct {
+	From, To time.Time
+	Currency string
+	// Statement is the total the statement charged over the days, and
+	// Logged the total of the expenses logged over them, both in
+	// Currency.
+	Statement float64
+	Logged    float64
+	Matched   []Match
+	// Missing are the charges with no expense logged for them.
+	Missing []*models.Expense
+	// Unbilled are the expenses logged that the statement does not show,
+	// such as those paid in cash or with another card.
+	Unbilled []*models.Expense
+	// Unconverted counts the expenses left out of Logged as they could not
+	// be converted into Currency.
+	Unconverted int
+}
+
+// Gap is what the statement charged beyond what was logged; it is
+// negative when more was logged.
+func (r Reconciliation) Gap() float64 {
+	return math.Round((r.Statement-r.Logged)*100) / 100
+}
+
+// Balanced reports whether every charge was logged, every expense is on
+// the statement and the totals agree to the cent.
+func (r Reconciliation) Balanced() bool {
+	return r.Gap() == 0 && len(r.Missing) == 0 && len(r.Unbilled) == 0 && r.Unconverted == 0
+}
+
+// Compute reconciles expenses with the charges of a statement. Expenses a
+// companion paid are not the traveler's to find on a statement and are
+// left out, as are charges outside the days reconciled and expenses that
+// are but for a charge within them.
+func Compute(charges, expenses []*models.Expense, opts Options) Reconciliation {
+	r := Reconciliation{From: day(opts.From), To: day(opts.To), Currency: opts.Currency}
+	if opts.From.IsZero() || opts.To.IsZero() {
+		for _, c := range charges {
+			d := day(c.Timestamp)
+			if opts.From.IsZero() && (r.From.IsZero() || d.Before(r.From)) {
+				r.From = d
+			}
+			if opts.To.IsZero() && d.After(r.To) {
+				r.To = d
+			}
+		}
+	}
+	within := func(x *models.Expense, slack int) bool {
+		d := day(x.Timestamp)
+		return !d.Before(r.From.AddDate(0, 0, -slack)) && !d.After(r.To.AddDate(0, 0, slack))
+	}
+
+	// Expenses up to window days outside the days reconciled may be what a
+	// charge within them was for, and count only if one is.
+	var billed, near []*models.Expense
+	for _, c := range charges {
+		if within(c, 0) {
+			billed = append(billed, c)
+			if n, err := c.In(r.Currency, opts.Convert); err == nil {
+				r.Statement += n
+			}
+		}
+	}
+	if opts.Total > 0 {
+		r.Statement = opts.Total
+	}
+	for _, x := range expenses {
+		if x.PaidBy == "" && within(x, window) {
+			near = append(near, x)
+		}
+	}
+	sort.SliceStable(billed, func(i, j int) bool { return billed[i].Timestamp.Before(billed[j].Timestamp) })
+
+	// Charges in the expense's own currency are matched to the cent first,
+	// so a converted amount that only comes close cannot take their
+	// expense.
+	taken := map[*models.Expense]bool{}
+	matched := map[*models.Expense]*models.Expense{}
+	for _, exact := range []bool{true, false} {
+		for _, c := range billed {
+			if matched[c] != nil {
+				continue
+			}
+			if x := closest(c, near, taken, exact, opts.Convert); x != nil {
+				matched[c], taken[x] = x, true
+			}
+		}
+	}
+	for _, c := range billed {
+		if x := matched[c]; x != nil {
+			r.Matched = append(r.Matched, Match{Charge: c, Expense: x})
+		} else {
+			r.Missing = append(r.Missing, c)
+		}
+	}
+	for _, x := range near {
+		switch {
+		case taken[x]:
+		case within(x, 0):
+			r.Unbilled = append(r.Unbilled, x)
+		default:
+			continue
+		}
+		n, err := x.In(r.Currency, opts.Convert)
+		if err != nil {
+			r.Unconverted++
+			continue
+		}
+		r.Logged += n
+	}
+	return r
+}
+
+// closest is the expense of those not yet taken that charge c is for: one
+// of the same amount, to the cent when exact and else once converted,
+// within window days of it, the nearest in days.
+func closest(c *models.Expense, expenses []*models.Expense, taken map[*models.Expense]bool, exact bool, convert Converter) *models.Expense {
+	var (
+		best     *models.Expense
+		bestDays int
+	)
+	for _, x := range expenses {
+		if taken[x] {
+			continue
+		}
+		days := int(math.Abs(day(x.Timestamp).Sub(day(c.Timestamp)).Hours() / 24))
+		if days > window || best != nil && days >= bestDays {
+			continue
+		}
+		if exact {
+			if x.Currency != c.Currency || cents(x.Amount) != cents(c.Amount) {
+				continue
+			}
+		} else {
+			n, err := x.In(c.Currency, convert)
+			if err != nil || math.Abs(n-c.Amount) > c.Amount*tolerance {
+				continue
+			}
+		}
+		best, bestDays = x, days
+	}
+	return best
+}
+
+// day is the calendar day of t, wherever it was.
+func day(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func cents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,78 @@
+// Package recurring records the expenses of recurrences, such as an eSIM
+// plan renewed each month, on the trips in progress as they fall due.
+package recurring
+
+import (
+	"context"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// Record records an expense for each day up to the day of now that a
+// recurrence fell due on while its trip, or any trip when it names none,
+// was in progress. Days that fell due with no trip in progress are
+// skipped. It returns the expenses recorded; running it again records
+// nothing more until the next day falls due.
+func Record(ctx context.Context, store *storage.Store, now time.Time) ([]*models.Expense, error) {
+	recurrences, err := store.ListRecurrences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	var trips []*models.Trip
+	legs := map[string][]*models.Leg{}
+	var recorded []*models.Expense
+	for _, r := range recurrences {
+		days := r.Due(today)
+		if len(days) == 0 {
+			continue
+		}
+		if trips == nil {
+			if trips, err = store.ListTrips(ctx); err != nil {
+				return recorded, err
+			}
+		}
+		var expenses []*models.Expense
+		for _, d := range days {
+			t := tripOn(r, trips, d)
+			if t == nil {
+				continue
+			}
+			l, ok := legs[t.ID]
+			if !ok {
+				if l, err = store.ListLegsByTrip(ctx, t.ID); err != nil {
+					return recorded, err
+				}
+				legs[t.ID] = l
+			}
+			x := r.Expense(t.ID, d, places.DayZone(t, l, "", d))
+			if leg := models.LegOn(l, x.Timestamp); leg != nil {
+				x.LegID = leg.ID
+			}
+			expenses = append(expenses, x)
+		}
+		r.Next = r.From(today.AddDate(0, 0, 1))
+		if err := store.RecordRecurrence(ctx, r, expenses); err != nil {
+			return recorded, err
+		}
+		recorded = append(recorded, expenses...)
+	}
+	return recorded, nil
+}
+
+// tripOn returns the trip an expense of r falling due on day goes to, or
+// nil when that trip is not in progress then.
+func tripOn(r *models.Recurrence, trips []*models.Trip, day time.Time) *models.Trip {
+	if r.TripID == "" {
+		return models.TripOn(trips, day)
+	}
+	for _, t := range trips {
+		if t.ID == r.TripID && t.InProgress(day) {
+			return t
+		}
+	}
+	return nil
+}
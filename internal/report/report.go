@@ -0,0 +1,238 @@
+// Package report totals a trip's expenses by category, by day or by leg in
+// a single currency, for the expense report and its CSV and Markdown
+// exports.
+package report
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/chart"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Converter converts amount between currencies.
+type Converter func(amount float64, from, to string) (float64, error)
+
+// Grouping is what a report totals the expenses by.
+type Grouping int
+
+const (
+	ByCategory Grouping = iota
+	ByDay
+	ByLeg
+)
+
+// Groupings lists the groupings in the order they are offered.
+var Groupings = []Grouping{ByCategory, ByDay, ByLeg}
+
+func (g Grouping) String() string {
+	switch g {
+	case ByDay:
+		return "day"
+	case ByLeg:
+		return "leg"
+	}
+	return "category"
+}
+
+// ParseGrouping reads a grouping by its name.
+func ParseGrouping(s string) (Grouping, error) {
+	for _, g := range Groupings {
+		if strings.EqualFold(strings.TrimSpace(s), g.String()) {
+			return g, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown grouping %q; use category, day or leg", s)
+}
+
+// NoLeg labels the expenses attributed to no leg.
+const NoLeg = "No leg"
+
+// Row totals the expenses of one group.
+type Row struct {
+	// Label names the group: a category, a YYYY-MM-DD day or a leg's
+	// location.
+	Label string
+	// Day is the day of the group when grouping by day.
+	Day   time.Time
+	Total float64
+	Count int
+}
+
+// Share is the row's part of total, between 0 and 1.
+func (r Row) Share(total float64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return r.Total / total
+}
+
+// Report totals a trip's expenses in Currency.
+type Report struct {
+	Currency string
+	By       Grouping
+	// Rows holds the groups with expenses: categories in
+	// models.Categories order, days in order and legs by arrival, with
+	// the expenses of no leg last.
+	Rows  []Row
+	Total float64
+	Count int
+	// Unconverted counts expenses left out because they could not be
+	// converted into Currency.
+	Unconverted int
+}
+
+// Compute builds the report of expenses grouped by by, in currency,
+// converting with convert, which may be nil to count only the expenses
+// already in currency. legs are the trip's, in order of arrival.
+func Compute(expenses []*models.Expense, legs []*models.Leg, by Grouping, currency string, convert Converter) Report {
+	r := Report{Currency: currency, By: by}
+	groups := map[string]*Row{}
+	var order []string
+	add := func(key string, row Row, amount float64) {
+		g, ok := groups[key]
+		if !ok {
+			g = &row
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.Total += amount
+		g.Count++
+	}
+	legNames := make(map[string]string, len(legs))
+	for _, l := range legs {
+		legNames[l.ID] = l.Location
+	}
+	for _, x := range expenses {
+		amount, err := x.In(currency, convert)
+		if err != nil {
+			r.Unconverted++
+			continue
+		}
+		r.Total += amount
+		r.Count++
+		switch by {
+		case ByCategory:
+			add(x.Category, Row{Label: x.Category}, amount)
+		case ByDay:
+			y, m, d := x.Timestamp.Date()
+			day := time.Date(y, m, d, 0, 0, 0, 0, x.Timestamp.Location())
+			key := day.Format(models.DateLayout)
+			add(key, Row{Label: key, Day: day}, amount)
+		case ByLeg:
+			// Expenses of legs deleted since count as of no leg.
+			key, name := x.LegID, legNames[x.LegID]
+			if name == "" {
+				key, name = "", NoLeg
+			}
+			add(key, Row{Label: name}, amount)
+		}
+	}
+
+	rank := map[string]int{}
+	switch by {
+	case ByCategory:
+		for i, c := range models.Categories {
+			rank[c] = i
+		}
+	case ByLeg:
+		for i, l := range legs {
+			rank[l.ID] = i
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		switch by {
+		case ByCategory:
+			return rank[a] < rank[b]
+		case ByLeg:
+			ra, oka := rank[a]
+			rb, okb := rank[b]
+			if oka != okb {
+				return oka
+			}
+			return ra < rb
+		}
+		return a < b
+	})
+	for _, key := range order {
+		r.Rows = append(r.Rows, *groups[key])
+	}
+	return r
+}
+
+// Daily totals a report by day for every day from its first to its last
+// with expenses, those without any at zero, for a histogram of the
+// spending. It returns nothing for reports grouped otherwise.
+func (r Report) Daily() (first time.Time, totals []float64) {
+	if r.By != ByDay || len(r.Rows) == 0 {
+		return time.Time{}, nil
+	}
+	// Counting in UTC keeps a day 24 hours long across a change of clocks.
+	utc := func(t time.Time) time.Time {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	first = r.Rows[0].Day
+	for _, row := range r.Rows {
+		i := int(utc(row.Day).Sub(utc(first)).Hours() / 24)
+		for len(totals) <= i {
+			totals = append(totals, 0)
+		}
+		totals[i] += row.Total
+	}
+	return first, totals
+}
+
+// WriteCSV writes the report as CSV: one row per group with its total,
+// share of the total and number of expenses, then the total.
+func (r Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	amount := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	records := [][]string{{r.By.String(), "total", "currency", "share", "expenses"}}
+	for _, row := range r.Rows {
+		records = append(records, []string{row.Label, amount(row.Total), r.Currency,
+			strconv.FormatFloat(row.Share(r.Total)*100, 'f', 1, 64), strconv.Itoa(row.Count)})
+	}
+	records = append(records, []string{"Total", amount(r.Total), r.Currency, "100.0", strconv.Itoa(r.Count)})
+	if err := cw.WriteAll(records); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteMarkdown writes the report as a Markdown document titled title:
+// a table of the groups with a bar for each.
+func (r Report) WriteMarkdown(w io.Writer, title string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "Expenses by %s, in %s.\n\n", r.By, r.Currency)
+	by := r.By.String()
+	fmt.Fprintf(&b, "| %s | Total | Share | Expenses | |\n", strings.ToUpper(by[:1])+by[1:])
+	b.WriteString("| --- | ---: | ---: | ---: | --- |\n")
+	var peak float64
+	for _, row := range r.Rows {
+		peak = max(peak, row.Total)
+	}
+	for _, row := range r.Rows {
+		fmt.Fprintf(&b, "| %s | %.2f %s | %.1f%% | %d | %s |\n", markdownCell(row.Label), row.Total, r.Currency,
+			row.Share(r.Total)*100, row.Count, chart.Bar(row.Total, peak, 20))
+	}
+	fmt.Fprintf(&b, "| **Total** | **%.2f %s** | 100.0%% | %d | |\n", r.Total, r.Currency, r.Count)
+	if r.Unconverted > 0 {
+		fmt.Fprintf(&b, "\n%d expenses in other currencies could not be converted and are not counted.\n", r.Unconverted)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markdownCell escapes the pipes that would end a table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,210 @@
+// Package settle works out who owes whom on a group trip from the shared
+// expenses, with as few transfers as possible.
+package settle
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// maxExact is the most people whose transfers are minimised exactly; the
+// search grows with 2^n. Larger groups are settled greedily, which takes
+// at most one transfer fewer than there are people.
+const maxExact = 16
+
+// Converter converts amount between currencies.
+type Converter func(amount float64, from, to string) (float64, error)
+
+// Balance is what one person paid for shared expenses against their
+// shares of them. A positive Net is owed to them, a negative one they owe.
+type Balance struct {
+	Name  string
+	Paid  float64
+	Share float64
+	Net   float64
+}
+
+// Transfer is a payment that settles debts.
+type Transfer struct {
+	From, To string
+	Amount   float64
+}
+
+// Settlement settles a trip's shared expenses in Currency.
+type Settlement struct {
+	Currency string
+	// Balances lists everyone on the trip, in the order given, then
+	// anyone else named by an expense.
+	Balances  []Balance
+	Transfers []Transfer
+	// Shared counts the expenses settled.
+	Shared int
+	// Unconverted counts shared expenses left out because they could not
+	// be converted into Currency.
+	Unconverted int
+}
+
+// Compute settles the shared expenses among people, converting money into
+// currency with convert, which may be nil. Expenses without shares are
+// the payer's own and are left out.
+func Compute(people []string, expenses []*models.Expense, currency string, convert Converter) Settlement {
+	s := Settlement{Currency: currency}
+	index := map[string]int{}
+	add := func(name string) {
+		if _, ok := index[name]; !ok {
+			index[name] = len(s.Balances)
+			s.Balances = append(s.Balances, Balance{Name: name})
+		}
+	}
+	for _, p := range people {
+		add(p)
+	}
+
+	paid, owed := map[string]int64{}, map[string]int64{}
+	for _, x := range expenses {
+		if !x.Shared() {
+			continue
+		}
+		rate := 1.0
+		if x.Currency != currency {
+			converted, err := x.In(currency, convert)
+			if err != nil || x.Amount == 0 {
+				s.Unconverted++
+				continue
+			}
+			rate = converted / x.Amount
+		}
+		s.Shared++
+		// The payer is credited with the rounded shares so that every
+		// expense nets out to zero.
+		var total int64
+		for _, sh := range x.Shares {
+			c := cents(sh.Amount * rate)
+			owed[sh.Name] += c
+			total += c
+			add(sh.Name)
+		}
+		paid[x.Payer()] += total
+		add(x.Payer())
+	}
+
+	nets := make([]int64, len(s.Balances))
+	for i := range s.Balances {
+		b := &s.Balances[i]
+		nets[i] = paid[b.Name] - owed[b.Name]
+		b.Paid, b.Share, b.Net = float64(paid[b.Name])/100, float64(owed[b.Name])/100, float64(nets[i])/100
+	}
+	for _, t := range transfers(nets) {
+		s.Transfers = append(s.Transfers, Transfer{
+			From:   s.Balances[t.from].Name,
+			To:     s.Balances[t.to].Name,
+			Amount: float64(t.amount) / 100,
+		})
+	}
+	return s
+}
+
+type transfer struct {
+	from, to int
+	amount   int64
+}
+
+// transfers settles the net balances, in cents, with the fewest payments.
+// People in a group whose balances sum to zero can settle among
+// themselves with one payment fewer than their number, so the fewest
+// payments come from splitting everyone into as many such groups as
+// possible. Each group is then settled greedily.
+func transfers(nets []int64) []transfer {
+	var open []int
+	for i, n := range nets {
+		if n != 0 {
+			open = append(open, i)
+		}
+	}
+	if len(open) > maxExact {
+		return greedy(nets, open)
+	}
+
+	n := len(open)
+	full := 1<<n - 1
+	sum := make([]int64, full+1)
+	// groups[mask] is the most zero-sum groups the people in mask split
+	// into, a remainder that does not sum to zero counting as none.
+	groups := make([]int, full+1)
+	for mask := 1; mask <= full; mask++ {
+		low := bits.TrailingZeros(uint(mask))
+		sum[mask] = sum[mask&^(1<<low)] + nets[open[low]]
+		best := 0
+		for rest := mask; rest != 0; rest &= rest - 1 {
+			i := bits.TrailingZeros(uint(rest))
+			best = max(best, groups[mask&^(1<<i)])
+		}
+		groups[mask] = best
+		if sum[mask] == 0 {
+			groups[mask]++
+		}
+	}
+
+	// Take people out one at a time, keeping the most groups; the masks
+	// left behind that sum to zero mark where groups end.
+	var order []int
+	for mask := full; mask != 0; {
+		pick, best := -1, -1
+		for rest := mask; rest != 0; rest &= rest - 1 {
+			i := bits.TrailingZeros(uint(rest))
+			if g := groups[mask&^(1<<i)]; g > best {
+				pick, best = i, g
+			}
+		}
+		order = append(order, pick)
+		mask &^= 1 << pick
+	}
+	var out []transfer
+	var group []int
+	prefix := 0
+	for i := len(order) - 1; i >= 0; i-- {
+		prefix |= 1 << order[i]
+		group = append(group, open[order[i]])
+		if sum[prefix] == 0 {
+			out = append(out, greedy(nets, group)...)
+			group = nil
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool { return out[a].amount > out[b].amount })
+	return out
+}
+
+// greedy settles the people in group by having the largest debtor pay the
+// largest creditor until everyone is even.
+func greedy(nets []int64, group []int) []transfer {
+	left := make(map[int]int64, len(group))
+	for _, i := range group {
+		left[i] = nets[i]
+	}
+	var out []transfer
+	for {
+		debtor, creditor := -1, -1
+		for _, i := range group {
+			if left[i] < 0 && (debtor < 0 || left[i] < left[debtor]) {
+				debtor = i
+			}
+			if left[i] > 0 && (creditor < 0 || left[i] > left[creditor]) {
+				creditor = i
+			}
+		}
+		if debtor < 0 || creditor < 0 {
+			return out
+		}
+		amount := min(-left[debtor], left[creditor])
+		out = append(out, transfer{from: debtor, to: creditor, amount: amount})
+		left[debtor] += amount
+		left[creditor] -= amount
+	}
+}
+
+func cents(v float64) int64 {
+	return int64(math.Round(v * 100))
+}
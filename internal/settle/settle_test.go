@@ -0,0 +1,185 @@
+package settle
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// toEUR converts at fixed rates.
+func toEUR(amount float64, from, to string) (float64, error) {
+	rates := map[string]float64{"USD": 0.9, "JPY": 0.0061}
+	if to != "EUR" || rates[from] == 0 {
+		return 0, fmt.Errorf("no rate from %s to %s", from, to)
+	}
+	return amount * rates[from], nil
+}
+
+func expense(amount float64, currency, paidBy string, shares ...models.Share) *models.Expense {
+	return &models.Expense{Amount: amount, Currency: currency, PaidBy: paidBy, Shares: shares}
+}
+
+func share(name string, amount float64) models.Share {
+	return models.Share{Name: name, Amount: amount}
+}
+
+// settles checks that transfers leave everyone of nets even.
+func settles(t *testing.T, nets []int64, out []transfer) {
+	t.Helper()
+	left := slices.Clone(nets)
+	for _, tr := range out {
+		if tr.amount <= 0 {
+			t.Errorf("transfer of %d from %d to %d", tr.amount, tr.from, tr.to)
+		}
+		left[tr.from] += tr.amount
+		left[tr.to] -= tr.amount
+	}
+	for i, n := range left {
+		if n != 0 {
+			t.Errorf("%d is left at %d after %v", i, n, out)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		people    []string
+		expenses  []*models.Expense
+		balances  []Balance
+		transfers []Transfer
+	}{
+		{
+			name:   "one payer",
+			people: []string{models.Me, "Ana", "Bo"},
+			expenses: []*models.Expense{
+				expense(90, "EUR", "", share(models.Me, 30), share("Ana", 30), share("Bo", 30)),
+			},
+			balances: []Balance{
+				{Name: models.Me, Paid: 90, Share: 30, Net: 60},
+				{Name: "Ana", Share: 30, Net: -30},
+				{Name: "Bo", Share: 30, Net: -30},
+			},
+			transfers: []Transfer{{From: "Ana", To: models.Me, Amount: 30}, {From: "Bo", To: models.Me, Amount: 30}},
+		},
+		{
+			name:   "paid back in kind",
+			people: []string{models.Me, "Ana"},
+			expenses: []*models.Expense{
+				expense(40, "EUR", "", share(models.Me, 20), share("Ana", 20)),
+				expense(40, "EUR", "Ana", share(models.Me, 20), share("Ana", 20)),
+			},
+			balances: []Balance{
+				{Name: models.Me, Paid: 40, Share: 40},
+				{Name: "Ana", Paid: 40, Share: 40},
+			},
+		},
+		{
+			name:   "someone else named",
+			people: []string{models.Me},
+			expenses: []*models.Expense{
+				expense(25, "EUR", "", share("Cy", 25)),
+				expense(12, "EUR", ""),
+			},
+			balances: []Balance{
+				{Name: models.Me, Paid: 25, Net: 25},
+				{Name: "Cy", Share: 25, Net: -25},
+			},
+			transfers: []Transfer{{From: "Cy", To: models.Me, Amount: 25}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Compute(tt.people, tt.expenses, "EUR", nil)
+			if !slices.Equal(s.Balances, tt.balances) {
+				t.Errorf("balances %+v, want %+v", s.Balances, tt.balances)
+			}
+			// Transfers of the same amount come in no set order.
+			byName := func(a, b Transfer) int { return strings.Compare(a.From+"→"+a.To, b.From+"→"+b.To) }
+			slices.SortFunc(s.Transfers, byName)
+			if !slices.Equal(s.Transfers, tt.transfers) {
+				t.Errorf("transfers %+v, want %+v", s.Transfers, tt.transfers)
+			}
+		})
+	}
+}
+
+func TestTransfersAreFewest(t *testing.T) {
+	for _, tt := range []struct {
+		nets []int64
+		want int
+	}{
+		{nil, 0},
+		{[]int64{0, 0}, 0},
+		{[]int64{500, -500}, 1},
+		{[]int64{1000, -300, -700}, 2},
+		// Greedily, the one owing 4 would pay the one owed 6, taking four
+		// transfers; {4, -4} and {6, -3, -3} settle apart in three.
+		{[]int64{600, 400, -300, -300, -400}, 3},
+		{[]int64{100, 200, 300, -100, -200, -300}, 3},
+		{[]int64{700, 300, -400, -600}, 3},
+	} {
+		out := transfers(tt.nets)
+		if len(out) != tt.want {
+			t.Errorf("%v settled in %d transfers %v, want %d", tt.nets, len(out), out, tt.want)
+		}
+		settles(t, tt.nets, out)
+	}
+	if out := greedy([]int64{600, 400, -300, -300, -400}, []int{0, 1, 2, 3, 4}); len(out) != 4 {
+		t.Errorf("greedily settled in %d transfers %v, want 4", len(out), out)
+	}
+}
+
+func TestTransfersOfLargeGroupsSettle(t *testing.T) {
+	nets := make([]int64, maxExact+4)
+	for i := range nets {
+		nets[i] = int64(i*37%11 - 5)
+	}
+	var sum int64
+	for _, n := range nets[1:] {
+		sum += n
+	}
+	nets[0] = -sum
+	out := transfers(nets)
+	settles(t, nets, out)
+	if len(out) >= len(nets) {
+		t.Errorf("%d transfers among %d people", len(out), len(nets))
+	}
+}
+
+func TestComputeRoundsMixedCurrenciesToZero(t *testing.T) {
+	locked := expense(10, "GBP", "Bo", share(models.Me, 5), share("Bo", 5))
+	locked.Rate, locked.RateCurrency = 1.17, "EUR"
+	expenses := []*models.Expense{
+		expense(10, "USD", "", share(models.Me, 3.33), share("Ana", 3.33), share("Bo", 3.34)),
+		expense(1000, "JPY", "Ana", share(models.Me, 333), share("Ana", 333), share("Bo", 334)),
+		locked,
+		expense(20, "CHF", "", share(models.Me, 10), share("Ana", 10)),
+	}
+	s := Compute([]string{models.Me, "Ana", "Bo"}, expenses, "EUR", toEUR)
+	if s.Shared != 3 || s.Unconverted != 1 {
+		t.Errorf("%d shared and %d unconverted, want 3 and 1", s.Shared, s.Unconverted)
+	}
+	var paid, owed, net int64
+	nets := make([]int64, len(s.Balances))
+	for i, b := range s.Balances {
+		paid, owed, net = paid+cents(b.Paid), owed+cents(b.Share), net+cents(b.Net)
+		nets[i] = cents(b.Net)
+		if cents(b.Paid)-cents(b.Share) != cents(b.Net) {
+			t.Errorf("%s paid %.2f against %.2f, but is at %.2f", b.Name, b.Paid, b.Share, b.Net)
+		}
+	}
+	// 3.00 + 3.00 + 3.01, 2.03 + 2.03 + 2.04 and 5.85 + 5.85.
+	if paid != owed || net != 0 || paid != 901+610+1170 {
+		t.Errorf("paid %d, owed %d and net %d cents, want 2681, 2681 and 0", paid, owed, net)
+	}
+	var settled []transfer
+	for _, tr := range s.Transfers {
+		from := slices.IndexFunc(s.Balances, func(b Balance) bool { return b.Name == tr.From })
+		to := slices.IndexFunc(s.Balances, func(b Balance) bool { return b.Name == tr.To })
+		settled = append(settled, transfer{from: from, to: to, amount: cents(tr.Amount)})
+	}
+	settles(t, nets, settled)
+}
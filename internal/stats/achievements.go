@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// Achievement is a milestone of the traveler's record, unlocked once its
+// goal is reached. It is worked out from the trips and entries stored, so
+// deleting them can take it away again.
+type Achievement struct {
+	// ID names the achievement for remembering it was unlocked.
+	ID    string
+	Icon  string
+	Title string
+	// Goal is the count unlocking it, of what Unit names; Progress is the
+	// count so far.
+	Goal, Progress int
+	Unit           string
+	// UnlockedAt is when it was recorded unlocked, which Achievements
+	// leaves for the caller to fill in.
+	UnlockedAt time.Time
+}
+
+// Unlocked reports whether the goal is reached.
+func (a Achievement) Unlocked() bool {
+	return a.Progress >= a.Goal
+}
+
+// Achievements measures the record of trips and journal entries against
+// each achievement, in order of their goals: countries and continents
+// visited on trips begun by now, entries written and the longest run of
+// days in a row with an entry.
+func Achievements(trips []*models.Trip, entries []*models.Entry, now time.Time) []Achievement {
+	countries, continents := map[string]bool{}, map[string]bool{}
+	for _, t := range trips {
+		if t.StartDate.After(now) {
+			continue
+		}
+		for _, c := range places.Countries(places.Resolve(t.Locations)) {
+			countries[c] = true
+			if name := places.Continent(c); name != "" {
+				continents[name] = true
+			}
+		}
+	}
+	return []Achievement{
+		{ID: "countries-10", Icon: "🌍", Title: "Globetrotter", Goal: 10, Progress: len(countries), Unit: "countries"},
+		{ID: "continents-5", Icon: "🧭", Title: "Continental", Goal: 5, Progress: len(continents), Unit: "continents"},
+		{ID: "entries-100", Icon: "📚", Title: "Chronicler", Goal: 100, Progress: len(entries), Unit: "journal entries"},
+		{ID: "streak-30", Icon: "🔥", Title: "Devoted diarist", Goal: 30, Progress: longestRun(entries), Unit: "days in a row"},
+	}
+}
+
+// longestRun counts the most days in a row with an entry, on any trip.
+// Entries count on their day where they were written.
+func longestRun(entries []*models.Entry) int {
+	written := map[time.Time]bool{}
+	for _, e := range entries {
+		y, m, d := e.Timestamp.Date()
+		written[time.Date(y, m, d, 0, 0, 0, 0, time.UTC)] = true
+	}
+	longest := 0
+	for day := range written {
+		// Count runs from their first day only.
+		if written[day.AddDate(0, 0, -1)] {
+			continue
+		}
+		n := 1
+		for written[day.AddDate(0, 0, n)] {
+			n++
+		}
+		longest = max(longest, n)
+	}
+	return longest
+}
@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"maps"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// TripFigures are the numbers of one trip compared with others'. Money is
+// in the comparison's currency.
+type TripFigures struct {
+	Trip *models.Trip
+	// Days is the trip's length, or the days traveled so far when it has
+	// no end date.
+	Days   int
+	Spend  float64
+	PerDay float64
+	// Categories is what was spent in each category, by name.
+	Categories map[string]float64
+	Entries    int
+	// Distance is the kilometres of the trip's transport segments, and
+	// Tracked the metres of its GPS tracks.
+	Distance float64
+	Tracked  float64
+	// Unconverted counts expenses that could not be converted.
+	Unconverted int
+}
+
+// Comparison sets trips side by side, as when planning the budget of the
+// next one from the last few.
+type Comparison struct {
+	Currency string
+	Trips    []TripFigures
+	// Categories are those any of the trips spent in, in models.Categories
+	// order.
+	Categories []string
+}
+
+// Compare works out the figures of each of trips, in order, from their
+// expenses, segments and tracks, picked out of those given by trip, and
+// entries, which counts the journal entries of each trip by ID. A trip
+// given twice gets the same figures in both columns. Money is converted
+// into currency with convert, which may be nil.
+func Compare(trips []*models.Trip, expenses []*models.Expense, entries map[string]int, segments []*models.Segment,
+	tracks []*models.Track, currency string, convert Converter, now time.Time) Comparison {
+	c := Comparison{Currency: currency}
+	index := make(map[string]int, len(trips))
+	for i, t := range trips {
+		if _, ok := index[t.ID]; !ok {
+			index[t.ID] = i
+		}
+		days := tripDays(t)
+		if t.EndDate == nil {
+			days = max(traveledDays(t, now), 1)
+		}
+		c.Trips = append(c.Trips, TripFigures{Trip: t, Days: days, Categories: map[string]float64{}, Entries: entries[t.ID]})
+	}
+	spent := map[string]bool{}
+	for _, x := range expenses {
+		i, ok := index[x.TripID]
+		if !ok {
+			continue
+		}
+		f := &c.Trips[i]
+		amount, err := x.In(currency, convert)
+		if err != nil {
+			f.Unconverted++
+			continue
+		}
+		f.Spend += amount
+		f.Categories[x.Category] += amount
+		spent[x.Category] = true
+	}
+	for _, s := range segments {
+		if i, ok := index[s.TripID]; ok {
+			c.Trips[i].Distance += s.Distance
+		}
+	}
+	for _, t := range tracks {
+		if i, ok := index[t.TripID]; ok {
+			c.Trips[i].Tracked += t.Distance
+		}
+	}
+	for i := range c.Trips {
+		f := &c.Trips[i]
+		f.PerDay = f.Spend / float64(f.Days)
+	}
+	for i, t := range trips {
+		if j := index[t.ID]; j != i {
+			c.Trips[i] = c.Trips[j]
+			c.Trips[i].Categories = maps.Clone(c.Trips[j].Categories)
+		}
+	}
+	for _, name := range models.Categories {
+		if spent[name] {
+			c.Categories = append(c.Categories, name)
+		}
+	}
+	return c
+}
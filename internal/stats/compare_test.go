@@ -0,0 +1,43 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+func TestCompareRepeatedTrip(t *testing.T) {
+	start := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 9)
+	tokyo := models.NewTrip("Tokyo", []string{"Tokyo"}, start)
+	tokyo.EndDate = &end
+	lisbon := models.NewTrip("Lisbon", []string{"Lisbon"}, start)
+	lisbon.EndDate = &end
+	expenses := []*models.Expense{
+		{TripID: tokyo.ID, Amount: 1000, Currency: "EUR", Category: "Lodging"},
+		{TripID: tokyo.ID, Amount: 361.5, Currency: "EUR", Category: "Food"},
+		{TripID: lisbon.ID, Amount: 500, Currency: "EUR", Category: "Food"},
+	}
+	segments := []*models.Segment{{TripID: tokyo.ID, Distance: 120}}
+	entries := map[string]int{tokyo.ID: 3, lisbon.ID: 1}
+
+	c := Compare([]*models.Trip{tokyo, lisbon, tokyo}, expenses, entries, segments, nil, "EUR", nil, end)
+	if len(c.Trips) != 3 {
+		t.Fatalf("%d columns, want 3", len(c.Trips))
+	}
+	for _, i := range []int{0, 2} {
+		f := c.Trips[i]
+		if f.Trip.ID != tokyo.ID || f.Spend != 1361.5 || f.PerDay != 136.15 || f.Categories["Food"] != 361.5 ||
+			f.Entries != 3 || f.Distance != 120 || f.Days != 10 {
+			t.Errorf("column %d: %+v, want Tokyo's figures", i, f)
+		}
+	}
+	if f := c.Trips[1]; f.Spend != 500 || f.Categories["Lodging"] != 0 {
+		t.Errorf("column 1: %+v, want Lisbon's figures", f)
+	}
+	c.Trips[0].Categories["Food"] = 0
+	if c.Trips[2].Categories["Food"] != 361.5 {
+		t.Error("the columns of a repeated trip share their categories")
+	}
+}
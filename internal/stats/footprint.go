@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"fmt"
+
+	"github.com/girdharshubham/nomadic/internal/flights"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// detours is how much longer than the great-circle distance a journey by
+// each mode runs: flights climb, stack and route around airspace, and roads
+// and rails wind. Modes not listed take 1.2.
+var detours = map[string]float64{
+	models.TransportFlight: 1.08,
+	models.TransportFerry:  1.1,
+}
+
+// EstimateDistance is the approximate distance in kilometres of a journey
+// by mode between two places of the dataset, or two airports, as "HND",
+// of a flight's. ok is false when either is not found.
+func EstimateDistance(mode, from, to string) (km float64, ok bool) {
+	a, ok := locate(from)
+	if !ok {
+		return 0, false
+	}
+	b, ok := locate(to)
+	if !ok {
+		return 0, false
+	}
+	detour, ok := detours[mode]
+	if !ok {
+		detour = 1.2
+	}
+	return places.Distance(a.Lat, a.Lon, b.Lat, b.Lon) / 1000 * detour, true
+}
+
+// locate finds the position of a city, or else of an airport.
+func locate(name string) (places.Place, bool) {
+	if p, ok := places.Lookup(name); ok {
+		return p, true
+	}
+	if ap, ok := flights.FindAirport(name); ok {
+		return places.Place{Name: ap.Name, Lat: ap.Lat, Lon: ap.Lon}, true
+	}
+	return places.Place{}, false
+}
+
+// ModeFootprint is the distance covered and emissions of one transport
+// mode.
+type ModeFootprint struct {
+	Mode     string
+	Segments int
+	Distance float64 // kilometres
+	CO2      float64 // kilograms of CO₂ equivalent
+}
+
+// Footprint totals the distance covered by transport segments and their
+// estimated emissions.
+type Footprint struct {
+	Segments int
+	Distance float64 // kilometres
+	CO2      float64 // kilograms of CO₂ equivalent
+	// ByMode is in models.Transports order, unused modes omitted.
+	ByMode []ModeFootprint
+	// Unestimated counts segments whose mode has no emission factor; their
+	// distance is counted but not their emissions.
+	Unestimated int
+}
+
+// ComputeFootprint totals segments by transport mode.
+func ComputeFootprint(segments []*models.Segment) Footprint {
+	var f Footprint
+	modes := map[string]*ModeFootprint{}
+	for _, s := range segments {
+		m := modes[s.Mode]
+		if m == nil {
+			m = &ModeFootprint{Mode: s.Mode}
+			modes[s.Mode] = m
+		}
+		co2, ok := s.CO2()
+		if !ok {
+			f.Unestimated++
+		}
+		m.Segments++
+		m.Distance += s.Distance
+		m.CO2 += co2
+		f.Segments++
+		f.Distance += s.Distance
+		f.CO2 += co2
+	}
+	for _, mode := range models.Transports {
+		if m := modes[mode]; m != nil {
+			f.ByMode = append(f.ByMode, *m)
+		}
+	}
+	return f
+}
+
+// FormatCO2 renders emissions given in kilograms, in tonnes from a tonne
+// up, e.g. "18 kg CO₂e" or "1.4 t CO₂e".
+func FormatCO2(kg float64) string {
+	if kg < 1000 {
+		return fmt.Sprintf("%.0f kg CO₂e", kg)
+	}
+	return fmt.Sprintf("%.1f t CO₂e", kg/1000)
+}
@@ -0,0 +1,164 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// How many hours a day the body clock catches up with the clock where it
+// is: about an hour after flying east, which shortens the day, and an
+// hour and a half after flying west, which lengthens it.
+const (
+	EastwardAdjustment = 1.0
+	WestwardAdjustment = 1.5
+)
+
+// DayJetlag is the jetlag expected on a day of a trip.
+type DayJetlag struct {
+	// Day is midnight of the trip's calendar day, in UTC as for DayMood.
+	Day time.Time
+	// Shift is how many hours the clock where the trip is runs ahead of
+	// the body clock that morning, behind when negative.
+	Shift float64
+	// Expected is the jetlag the shift makes for, from 1 (none) to
+	// MaxRating (badly), two hours of shift a step.
+	Expected float64
+}
+
+// JetlagCurve works out the jetlag expected on each day of trip, from its
+// first day to its last, or today for trips without an end date. The body
+// clock leaves on home's time and each day catches up with the time zone
+// of where the trip is that day, by EastwardAdjustment or
+// WestwardAdjustment hours.
+func JetlagCurve(trip *models.Trip, legs []*models.Leg, home *time.Location, now time.Time) []DayJetlag {
+	first := time.Date(trip.StartDate.Year(), trip.StartDate.Month(), trip.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	last := now
+	if trip.EndDate != nil {
+		last = *trip.EndDate
+	}
+	days := models.CalendarDays(first, last)
+	if days < 1 {
+		return nil
+	}
+	body := offset(first, home)
+	curve := make([]DayJetlag, days)
+	for i := range curve {
+		day := first.AddDate(0, 0, i)
+		shift := shortest(offset(day, models.Zone(places.DayZone(trip, legs, "", day))) - body)
+		curve[i] = DayJetlag{Day: day, Shift: shift, Expected: 1 + math.Min(models.MaxRating-1, math.Abs(shift)/2)}
+		if shift > 0 {
+			body += math.Min(shift, EastwardAdjustment)
+		} else {
+			body -= math.Min(-shift, WestwardAdjustment)
+		}
+	}
+	return curve
+}
+
+// offset is the UTC offset in hours of loc at noon on day, after the
+// night's clock changes.
+func offset(day time.Time, loc *time.Location) float64 {
+	_, secs := time.Date(day.Year(), day.Month(), day.Day(), 12, 0, 0, 0, loc).Zone()
+	return float64(secs) / 3600
+}
+
+// shortest reads a shift of hours the short way round the world: 14 hours
+// ahead is 10 behind.
+func shortest(hours float64) float64 {
+	for hours > 12 {
+		hours -= 24
+	}
+	for hours < -12 {
+		hours += 24
+	}
+	return hours
+}
+
+// DayHealth is a day of a trip as it went for the body: what was logged,
+// the jetlag expected and the mood of the day's entries.
+type DayHealth struct {
+	// Day is midnight of the trip's calendar day, in UTC as for DayMood.
+	Day time.Time
+	// Logged is what was logged for the day, or nil.
+	Logged *models.HealthDay
+	// Shift and Expected are those of the day's DayJetlag, zero on days
+	// outside the trip's dates.
+	Shift    float64
+	Expected float64
+	// Mood is the average mood of the day's entries, or 0 when none is
+	// rated.
+	Mood float64
+}
+
+// Health lines up the days of a trip's jetlag curve and the days logged,
+// in order, with the mood of each.
+func Health(curve []DayJetlag, logged []*models.HealthDay, moods Trend) []DayHealth {
+	byDay := map[string]*DayHealth{}
+	day := func(t time.Time) *DayHealth {
+		key := t.Format(models.DateLayout)
+		d, ok := byDay[key]
+		if !ok {
+			d = &DayHealth{Day: t}
+			byDay[key] = d
+		}
+		return d
+	}
+	for _, j := range curve {
+		d := day(j.Day)
+		d.Shift, d.Expected = j.Shift, j.Expected
+	}
+	for _, h := range logged {
+		t, err := time.Parse(models.DateLayout, h.Day)
+		if err != nil {
+			continue
+		}
+		day(t).Logged = h
+	}
+	for _, m := range moods {
+		if d, ok := byDay[m.Day.Format(models.DateLayout)]; ok {
+			d.Mood = m.Mood
+		}
+	}
+	out := make([]DayHealth, 0, len(byDay))
+	for _, d := range byDay {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Day.Before(out[j].Day) })
+	return out
+}
+
+// Averages are the averages of what was logged over the days logged, zero
+// for what never was.
+func Averages(days []DayHealth) (sleep, water, jetlag float64) {
+	var ns, nw, nj int
+	for _, d := range days {
+		h := d.Logged
+		if h == nil {
+			continue
+		}
+		if h.Sleep > 0 {
+			sleep += h.Sleep
+			ns++
+		}
+		if h.Water > 0 {
+			water += h.Water
+			nw++
+		}
+		if h.Jetlag > 0 {
+			jetlag += float64(h.Jetlag)
+			nj++
+		}
+	}
+	return mean(sleep, ns), mean(water, nw), mean(jetlag, nj)
+}
+
+func mean(sum float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
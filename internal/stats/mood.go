@@ -0,0 +1,101 @@
+package stats
+
+import (
+	"sort"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/chart"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// DayMood is the mood of one day of a trip: the average of its rated
+// entries.
+type DayMood struct {
+	Day     time.Time
+	Mood    float64
+	Entries int
+}
+
+// Trend is how a trip's mood went day by day, in order, over the days
+// with rated entries.
+type Trend []DayMood
+
+// MoodTrend averages the moods of entries by the calendar day they were
+// written on, where they were written. Entries without a mood are left
+// out.
+func MoodTrend(entries []*models.Entry) Trend {
+	byDay := map[string]*DayMood{}
+	for _, e := range entries {
+		if e.Mood < 1 {
+			continue
+		}
+		y, m, d := e.Timestamp.Date()
+		key := e.Timestamp.Format(models.DateLayout)
+		day, ok := byDay[key]
+		if !ok {
+			day = &DayMood{Day: time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+			byDay[key] = day
+		}
+		// Mood holds the sum until every entry is in.
+		day.Mood += float64(e.Mood)
+		day.Entries++
+	}
+	t := make(Trend, 0, len(byDay))
+	for _, day := range byDay {
+		day.Mood /= float64(day.Entries)
+		t = append(t, *day)
+	}
+	sort.Slice(t, func(i, j int) bool { return t[i].Day.Before(t[j].Day) })
+	return t
+}
+
+// Average is the mood of all the rated entries together, or 0 when there
+// are none.
+func (t Trend) Average() float64 {
+	var sum float64
+	n := 0
+	for _, d := range t {
+		sum += d.Mood * float64(d.Entries)
+		n += d.Entries
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// Sparkline draws the trend as one block per day, from ▁ for a mood of 1
+// to █ for models.MaxRating.
+func (t Trend) Sparkline() string {
+	values := make([]float64, len(t))
+	for i, d := range t {
+		values[i] = d.Mood
+	}
+	return chart.Sparkline(values, 1, models.MaxRating)
+}
+
+// TripMood is how a trip felt: its overall rating and the trend of its
+// entries' moods.
+type TripMood struct {
+	Trip  *models.Trip
+	Trend Trend
+}
+
+// Moods pairs each trip that has a rating or rated entries with the trend
+// of its moods, most recently started first.
+func Moods(trips []*models.Trip, entries []*models.Entry) []TripMood {
+	byTrip := map[string][]*models.Entry{}
+	for _, e := range entries {
+		byTrip[e.TripID] = append(byTrip[e.TripID], e)
+	}
+	var out []TripMood
+	for _, t := range trips {
+		trend := MoodTrend(byTrip[t.ID])
+		if t.Rating == 0 && len(trend) == 0 {
+			continue
+		}
+		out = append(out, TripMood{Trip: t, Trend: trend})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Trip.StartDate.After(out[j].Trip.StartDate) })
+	return out
+}
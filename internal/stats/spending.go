@@ -0,0 +1,218 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Period is the span spending is summarized over: a calendar year, one
+// month of it, or all time when Year is zero.
+type Period struct {
+	Year  int
+	Month time.Month // zero for the whole year
+}
+
+// AllTime is the period covering every expense.
+var AllTime = Period{}
+
+// Year is the period of the calendar year y.
+func Year(y int) Period { return Period{Year: y} }
+
+// ParsePeriod reads a period as a year ("2024"), a month ("2024-03") or
+// "all".
+func ParsePeriod(s string) (Period, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "all") {
+		return AllTime, nil
+	}
+	if y, err := strconv.Atoi(s); err == nil && len(s) == 4 {
+		return Year(y), nil
+	}
+	if t, err := time.Parse("2006-01", s); err == nil {
+		return Period{Year: t.Year(), Month: t.Month()}, nil
+	}
+	return Period{}, fmt.Errorf("unknown period %q; use a year (2024), a month (2024-03) or all", s)
+}
+
+// String writes the period as ParsePeriod reads it.
+func (p Period) String() string {
+	switch {
+	case p.Year == 0:
+		return "all"
+	case p.Month == 0:
+		return strconv.Itoa(p.Year)
+	}
+	return fmt.Sprintf("%04d-%02d", p.Year, p.Month)
+}
+
+// Contains reports whether t falls in the period.
+func (p Period) Contains(t time.Time) bool {
+	switch {
+	case p.Year == 0:
+		return true
+	case p.Month == 0:
+		return t.Year() == p.Year
+	}
+	return t.Year() == p.Year && t.Month() == p.Month
+}
+
+// YearBefore is the same period a year earlier. All time has none and is
+// returned as it is.
+func (p Period) YearBefore() Period {
+	if p.Year == 0 {
+		return p
+	}
+	return Period{Year: p.Year - 1, Month: p.Month}
+}
+
+// PeriodSpend is the spend of a month or a year against the same one a
+// year before.
+type PeriodSpend struct {
+	// Label is the month as YYYY-MM or the year.
+	Label    string
+	Total    float64
+	Previous float64
+	Count    int
+}
+
+// Change is how much more or less was spent than the year before, as a
+// fraction of what was. It reports false when nothing was spent then.
+func (p PeriodSpend) Change() (float64, bool) {
+	if p.Previous <= 0 {
+		return 0, false
+	}
+	return (p.Total - p.Previous) / p.Previous, true
+}
+
+// FormatChange renders the change from the year before as "+12%" or
+// "-3%", or "" when nothing was spent then.
+func (p PeriodSpend) FormatChange() string {
+	c, ok := p.Change()
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%+.0f%%", c*100)
+}
+
+// Spending summarizes what was spent across every trip over a period, in
+// Currency.
+type Spending struct {
+	Currency string
+	Period   Period
+	// Total is the spend of the period, against the same period a year
+	// before; for all time Previous is zero.
+	PeriodSpend
+	// Months holds every month of a year, each month with spending of all
+	// time, or the one month, oldest first.
+	Months []PeriodSpend
+	// Years holds each year with spending, oldest first, for all time.
+	Years []PeriodSpend
+	// Categories are the categories spent on, most first.
+	Categories []Amount
+	// Trips are the trips spent on, most first.
+	Trips []Amount
+	// Unconverted counts expenses of the period that could not be
+	// converted into Currency.
+	Unconverted int
+}
+
+// ComputeSpending summarizes the expenses of trips over period, converting
+// money into currency with convert, which may be nil.
+func ComputeSpending(trips []*models.Trip, expenses []*models.Expense, period Period, currency string,
+	convert Converter) Spending {
+	s := Spending{Currency: currency, Period: period}
+	s.Label = period.String()
+	titles := make(map[string]string, len(trips))
+	for _, t := range trips {
+		titles[t.ID] = t.Title
+	}
+	amountOf := func(x *models.Expense) (float64, bool) {
+		v, err := x.In(currency, convert)
+		return v, err == nil
+	}
+
+	before := period.YearBefore()
+	months := map[Period]*PeriodSpend{}
+	years := map[Period]*PeriodSpend{}
+	at := func(m map[Period]*PeriodSpend, p Period) *PeriodSpend {
+		if m[p] == nil {
+			m[p] = &PeriodSpend{Label: p.String()}
+		}
+		return m[p]
+	}
+	// A year shows each of its months, spent in or not.
+	if period.Year != 0 && period.Month == 0 {
+		for m := time.January; m <= time.December; m++ {
+			at(months, Period{Year: period.Year, Month: m})
+		}
+	}
+	categories := map[string]float64{}
+	byTrip := map[string]float64{}
+	for _, x := range expenses {
+		ts := x.Timestamp
+		month := Period{Year: ts.Year(), Month: ts.Month()}
+		switch {
+		case period.Contains(ts):
+			amount, ok := amountOf(x)
+			if !ok {
+				s.Unconverted++
+				continue
+			}
+			s.Total += amount
+			s.Count++
+			for _, p := range []*PeriodSpend{at(months, month), at(years, Year(ts.Year()))} {
+				p.Total += amount
+				p.Count++
+			}
+			categories[x.Category] += amount
+			byTrip[x.TripID] += amount
+		case period != AllTime && before.Contains(ts):
+			if amount, ok := amountOf(x); ok {
+				s.Previous += amount
+				at(months, Period{Year: month.Year + 1, Month: month.Month}).Previous += amount
+			}
+		}
+	}
+	// Over all time each month and year is against the one a year before
+	// it, when there was one.
+	if period == AllTime {
+		for _, m := range []map[Period]*PeriodSpend{months, years} {
+			for p, spend := range m {
+				if prev := m[p.YearBefore()]; prev != nil {
+					spend.Previous = prev.Total
+				}
+			}
+		}
+		s.Years = sortedPeriods(years)
+	}
+	s.Months = sortedPeriods(months)
+
+	for c, v := range categories {
+		s.Categories = append(s.Categories, Amount{Label: c, Value: v})
+	}
+	sortByCount(s.Categories)
+	for id, v := range byTrip {
+		title := titles[id]
+		if title == "" {
+			title = id
+		}
+		s.Trips = append(s.Trips, Amount{Label: title, Value: v})
+	}
+	sortByCount(s.Trips)
+	return s
+}
+
+// sortedPeriods lists the spend of each period of m, oldest first.
+func sortedPeriods(m map[Period]*PeriodSpend) []PeriodSpend {
+	out := make([]PeriodSpend, 0, len(m))
+	for _, p := range m {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Label < out[j].Label })
+	return out
+}
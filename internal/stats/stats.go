@@ -0,0 +1,147 @@
+// Package stats aggregates travel statistics across every trip.
+package stats
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// Converter converts amount between currencies.
+type Converter func(amount float64, from, to string) (float64, error)
+
+// Amount is a labelled total, such as the spend of one year.
+type Amount struct {
+	Label string
+	Value float64
+}
+
+// Summary holds the aggregate numbers shown on the stats screen. Money is
+// in Currency.
+type Summary struct {
+	Currency string
+
+	Trips int
+	// Destinations lists every distinct destination, most visited first.
+	Destinations []Amount
+	// Countries counts the trips to each country, most visited first.
+	// Only destinations found in the places dataset are counted.
+	Countries []Amount
+	// DaysTraveled counts trip days up to today; future days are excluded.
+	DaysTraveled int
+	Longest      *models.Trip
+	LongestDays  int
+
+	TotalSpend    float64
+	SpendByYear   []Amount // oldest year first
+	SpendCategory []Amount // in models.Categories order, empty categories omitted
+	AverageDaily  float64
+	// Unconverted counts expenses that could not be converted into Currency.
+	Unconverted int
+
+	// Footprint totals the transport segments of every trip.
+	Footprint Footprint
+}
+
+// Compute builds the summary of trips with their expenses and transport
+// segments, converting money into currency with convert, which may be nil.
+func Compute(trips []*models.Trip, expenses []*models.Expense, segments []*models.Segment, currency string,
+	convert Converter, now time.Time) Summary {
+	s := Summary{Currency: currency, Trips: len(trips), Footprint: ComputeFootprint(segments)}
+
+	visits := map[string]int{}
+	names := map[string]string{}
+	countries := map[string]int{}
+	for _, t := range trips {
+		seen := map[string]bool{}
+		for _, l := range t.Locations {
+			key := strings.ToLower(strings.TrimSpace(l))
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			visits[key]++
+			if _, ok := names[key]; !ok {
+				names[key] = strings.TrimSpace(l)
+			}
+		}
+		for _, c := range places.Countries(places.Resolve(t.Locations)) {
+			countries[c]++
+		}
+
+		if d := tripDays(t); d > s.LongestDays {
+			s.Longest, s.LongestDays = t, d
+		}
+		s.DaysTraveled += traveledDays(t, now)
+	}
+	for key, n := range visits {
+		s.Destinations = append(s.Destinations, Amount{Label: names[key], Value: float64(n)})
+	}
+	sortByCount(s.Destinations)
+	for code, n := range countries {
+		s.Countries = append(s.Countries, Amount{Label: places.CountryName(code), Value: float64(n)})
+	}
+	sortByCount(s.Countries)
+
+	years := map[int]float64{}
+	categories := map[string]float64{}
+	for _, x := range expenses {
+		amount, err := x.In(currency, convert)
+		if err != nil {
+			s.Unconverted++
+			continue
+		}
+		s.TotalSpend += amount
+		years[x.Timestamp.Year()] += amount
+		categories[x.Category] += amount
+	}
+	yearList := make([]int, 0, len(years))
+	for y := range years {
+		yearList = append(yearList, y)
+	}
+	sort.Ints(yearList)
+	for _, y := range yearList {
+		s.SpendByYear = append(s.SpendByYear, Amount{Label: strconv.Itoa(y), Value: years[y]})
+	}
+	for _, c := range models.Categories {
+		if v := categories[c]; v > 0 {
+			s.SpendCategory = append(s.SpendCategory, Amount{Label: c, Value: v})
+		}
+	}
+	if s.DaysTraveled > 0 {
+		s.AverageDaily = s.TotalSpend / float64(s.DaysTraveled)
+	}
+	return s
+}
+
+// sortByCount orders counts from the largest, breaking ties by label.
+func sortByCount(list []Amount) {
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		return strings.ToLower(a.Label) < strings.ToLower(b.Label)
+	})
+}
+
+// tripDays is the planned length of a trip; open-ended trips count one day.
+func tripDays(t *models.Trip) int {
+	if t.EndDate == nil {
+		return 1
+	}
+	return models.CalendarDays(t.StartDate, *t.EndDate)
+}
+
+// traveledDays counts the days of t that are not in the future.
+func traveledDays(t *models.Trip, now time.Time) int {
+	end := now
+	if t.EndDate != nil && t.EndDate.Before(now) {
+		end = *t.EndDate
+	}
+	return models.CalendarDays(t.StartDate, end)
+}
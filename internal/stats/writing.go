@@ -0,0 +1,79 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Writing sums up what was written in the journal.
+type Writing struct {
+	Entries int
+	Words   int
+	// ByTrip is the words written on each trip with entries, most first,
+	// and ByMonth those written each month, as 2006-01, oldest first.
+	ByTrip  []Amount
+	ByMonth []Amount
+	// Longest is the entry with the most words.
+	Longest      *models.Entry
+	LongestWords int
+}
+
+// Average is the words of an entry on average, or 0 without entries.
+func (w Writing) Average() float64 {
+	if w.Entries == 0 {
+		return 0
+	}
+	return float64(w.Words) / float64(w.Entries)
+}
+
+// ComputeWriting counts the words of the entries, by trip and by the month
+// they were written in.
+func ComputeWriting(trips []*models.Trip, entries []*models.Entry) Writing {
+	titles := make(map[string]string, len(trips))
+	for _, t := range trips {
+		titles[t.ID] = t.Title
+	}
+	w := Writing{Entries: len(entries)}
+	byTrip := map[string]int{}
+	byMonth := map[string]int{}
+	for _, e := range entries {
+		n := Words(e.Title) + Words(e.Text)
+		w.Words += n
+		if n > w.LongestWords {
+			w.Longest, w.LongestWords = e, n
+		}
+		if title, ok := titles[e.TripID]; ok {
+			byTrip[title] += n
+		}
+		byMonth[e.Timestamp.Format("2006-01")] += n
+	}
+	for title, n := range byTrip {
+		w.ByTrip = append(w.ByTrip, Amount{Label: title, Value: float64(n)})
+	}
+	sort.Slice(w.ByTrip, func(i, j int) bool {
+		if w.ByTrip[i].Value != w.ByTrip[j].Value {
+			return w.ByTrip[i].Value > w.ByTrip[j].Value
+		}
+		return w.ByTrip[i].Label < w.ByTrip[j].Label
+	})
+	for month, n := range byMonth {
+		w.ByMonth = append(w.ByMonth, Amount{Label: month, Value: float64(n)})
+	}
+	sort.Slice(w.ByMonth, func(i, j int) bool { return w.ByMonth[i].Label < w.ByMonth[j].Label })
+	return w
+}
+
+// Words counts the words of Markdown text. Markup standing on its own,
+// such as the # of a heading or a list's dash, is not a word.
+func Words(text string) int {
+	n := 0
+	for _, f := range strings.Fields(text) {
+		if strings.IndexFunc(f, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			n++
+		}
+	}
+	return n
+}
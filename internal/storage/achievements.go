@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ListUnlockedAchievements returns when each achievement was first
+// unlocked, by its ID.
+func (s *Store) ListUnlockedAchievements(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, unlocked_at FROM achievements`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list achievements: %w", err)
+	}
+	defer rows.Close()
+
+	unlocked := map[string]time.Time{}
+	for rows.Next() {
+		var id, at string
+		if err := rows.Scan(&id, &at); err != nil {
+			return nil, fmt.Errorf("storage: list achievements: %w", err)
+		}
+		if unlocked[id], err = parseTime(at); err != nil {
+			return nil, fmt.Errorf("storage: list achievements: %w", err)
+		}
+	}
+	return unlocked, rows.Err()
+}
+
+// UnlockAchievement records that the achievement with the given ID was
+// unlocked at at, unless it was before.
+func (s *Store) UnlockAchievement(ctx context.Context, id string, at time.Time) error {
+	if _, err := s.exec(ctx, `INSERT INTO achievements (id, unlocked_at) VALUES (?, ?) ON CONFLICT(id) DO NOTHING`,
+		id, formatTime(at)); err != nil {
+		return fmt.Errorf("storage: unlock achievement: %w", err)
+	}
+	return nil
+}
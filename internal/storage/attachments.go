@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// AttachmentsDir is the directory inside the data directory that holds
+// attached files, one subdirectory per entry.
+const AttachmentsDir = "attachments"
+
+const attachmentColumns = `id, entry_id, name, path, linked, size, created_at`
+
+// AttachFile attaches the file at src to an entry. The file is copied into
+// the data directory, or symlinked there when link is set so large
+// originals are not duplicated.
+func (s *Store) AttachFile(ctx context.Context, entryID, src string, link bool) (*models.Attachment, error) {
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return nil, fmt.Errorf("storage: attach %s: %w", src, err)
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, fmt.Errorf("storage: attach: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("storage: attach %s: not a regular file", src)
+	}
+
+	a := &models.Attachment{
+		ID:        models.NewID(),
+		EntryID:   entryID,
+		Name:      filepath.Base(src),
+		Linked:    link,
+		Size:      info.Size(),
+		CreatedAt: time.Now(),
+	}
+	a.Path = filepath.Join(entryID, a.ID+strings.ToLower(filepath.Ext(src)))
+	dst := s.AttachmentPath(a)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return nil, fmt.Errorf("storage: attach: %w", err)
+	}
+	if link {
+		err = os.Symlink(src, dst)
+	} else {
+		err = copyFile(src, dst)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: attach %s: %w", a.Name, err)
+	}
+
+	_, err = s.exec(ctx, `INSERT INTO attachments (`+attachmentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		a.ID, a.EntryID, a.Name, a.Path, a.Linked, a.Size, formatTime(a.CreatedAt))
+	if err != nil {
+		os.Remove(dst)
+		return nil, fmt.Errorf("storage: save attachment: %w", err)
+	}
+	return a, nil
+}
+
+// AttachmentPath returns where an attachment's file lives on disk.
+func (s *Store) AttachmentPath(a *models.Attachment) string {
+	return filepath.Join(s.dir, AttachmentsDir, a.Path)
+}
+
+// GetAttachment returns the attachment with the given ID.
+func (s *Store) GetAttachment(ctx context.Context, id string) (*models.Attachment, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+attachmentColumns+` FROM attachments WHERE id = ?`, id)
+	a, err := scanAttachment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get attachment: %w", err)
+	}
+	return a, nil
+}
+
+// ListAttachmentsByEntry returns an entry's attachments in the order they
+// were added.
+func (s *Store) ListAttachmentsByEntry(ctx context.Context, entryID string) ([]*models.Attachment, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+attachmentColumns+` FROM attachments WHERE entry_id = ? ORDER BY created_at`, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []*models.Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list attachments: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+// DeleteAttachment removes an attachment and its copy or link in the data
+// directory. Linked originals are left alone.
+func (s *Store) DeleteAttachment(ctx context.Context, id string) error {
+	a, err := s.GetAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.exec(ctx, `DELETE FROM attachments WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("storage: delete attachment: %w", err)
+	}
+	if err := os.Remove(s.AttachmentPath(a)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: delete attachment: %w", err)
+	}
+	// Drop the entry's directory once it is empty; failure just means it is not.
+	os.Remove(filepath.Dir(s.AttachmentPath(a)))
+	return nil
+}
+
+// removeAttachmentFiles deletes the files attached to an entry whose rows
+// are already gone.
+func (s *Store) removeAttachmentFiles(entryID string) error {
+	if err := os.RemoveAll(filepath.Join(s.dir, AttachmentsDir, entryID)); err != nil {
+		return fmt.Errorf("storage: delete attachments: %w", err)
+	}
+	return nil
+}
+
+func scanAttachment(sc scanner) (*models.Attachment, error) {
+	var (
+		a       models.Attachment
+		created string
+	)
+	if err := sc.Scan(&a.ID, &a.EntryID, &a.Name, &a.Path, &a.Linked, &a.Size, &created); err != nil {
+		return nil, err
+	}
+	var err error
+	if a.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// copyFile copies src to dst via a temporary file so a failed copy never
+// leaves a partial attachment behind.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
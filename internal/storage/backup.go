@@ -0,0 +1,646 @@
+package storage
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// A backup is a gzip-compressed tar archive of the data directory: a
+// consistent copy of the database, the attached files and the caches,
+// followed by a manifest with the SHA-256 of every file. It can be sealed
+// with a passphrase the same way as an encrypted database; an encrypted
+// database stays encrypted inside any backup.
+const (
+	// BackupsDir is the directory inside the data directory holding the
+	// backups taken automatically before migrations and restores.
+	BackupsDir = "backups"
+	// BackupExt is the extension of backup archives, and SealedBackupExt
+	// that of archives sealed with a passphrase.
+	BackupExt       = ".tar.gz"
+	SealedBackupExt = BackupExt + ".enc"
+
+	// KeepBackups is how many automatic backups are kept.
+	KeepBackups = 5
+
+	manifestName = "manifest.json"
+	// maxManifest is the most a manifest is read of.
+	maxManifest = 64 << 20
+	// maxSealedBackup is the size of the largest sealed backup, which is
+	// sealed and unsealed in one piece in memory.
+	maxSealedBackup = 1 << 30
+	// backupStamp is the layout of the time in backup file names.
+	backupStamp = "20060102-150405"
+)
+
+// ErrBackupSealed is returned by Restore when the backup is sealed with a
+// passphrase and none was given.
+var ErrBackupSealed = errors.New("storage: the backup is encrypted")
+
+// BackupManifest lists the files of a backup.
+type BackupManifest struct {
+	Created time.Time `json:"created"`
+	// SchemaVersion is the version of the database, or 0 when it is
+	// encrypted and was not opened to take the backup.
+	SchemaVersion int `json:"schema_version,omitempty"`
+	// DatabaseOnly is set for backups of the database alone, which leave
+	// attached files alone when restored.
+	DatabaseOnly bool         `json:"database_only,omitempty"`
+	Files        []BackupFile `json:"files"`
+}
+
+// BackupFile is a file of a backup, by its slash-separated path inside the
+// data directory.
+type BackupFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256,omitempty"`
+	// Link is the target of a symlink, as is left for attachments linked
+	// rather than copied. Only the link is backed up.
+	Link string `json:"link,omitempty"`
+}
+
+// Size returns the total size of the backed up files.
+func (m *BackupManifest) Size() int64 {
+	var n int64
+	for _, f := range m.Files {
+		n += f.Size
+	}
+	return n
+}
+
+// dbFile is a copy of the database file, plain or encrypted.
+type dbFile struct {
+	name string
+	data []byte
+}
+
+// Backup writes a backup of the data directory in dir to w, sealed with
+// passphrase unless it is empty, and returns its manifest.
+func Backup(ctx context.Context, dir string, w io.Writer, passphrase string) (*BackupManifest, error) {
+	db, version, err := snapshotDir(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	m, err := writeBackup(w, dir, db, version, false, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("storage: backup: %w", err)
+	}
+	return m, nil
+}
+
+// snapshotDir copies the database in dir, opening it briefly when it is
+// plain, and returns its schema version when known.
+func snapshotDir(ctx context.Context, dir string) (dbFile, int, error) {
+	if IsEncrypted(dir) {
+		data, err := os.ReadFile(filepath.Join(dir, EncryptedFileName))
+		if err != nil {
+			return dbFile{}, 0, fmt.Errorf("storage: backup: %w", err)
+		}
+		return dbFile{name: EncryptedFileName, data: data}, 0, nil
+	}
+	s, err := Open(ctx, dir)
+	if err != nil {
+		return dbFile{}, 0, err
+	}
+	defer s.Close()
+	version, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return dbFile{}, 0, fmt.Errorf("storage: backup: %w", err)
+	}
+	db, err := s.snapshot(ctx)
+	if err != nil {
+		return dbFile{}, 0, fmt.Errorf("storage: backup: %w", err)
+	}
+	return db, version, nil
+}
+
+// snapshot copies the database file. A plain database is copied with
+// VACUUM INTO, so the copy holds what is still in the write-ahead log;
+// an encrypted one is always complete on disk.
+func (s *Store) snapshot(ctx context.Context) (dbFile, error) {
+	if s.sealer != nil {
+		data, err := os.ReadFile(s.path)
+		return dbFile{name: EncryptedFileName, data: data}, err
+	}
+	f, err := os.CreateTemp(s.dir, FileName+".*.tmp")
+	if err != nil {
+		return dbFile{}, err
+	}
+	// VACUUM INTO wants to create the file itself.
+	tmp := f.Name()
+	f.Close()
+	os.Remove(tmp)
+	defer os.Remove(tmp)
+	if _, err := s.db.ExecContext(ctx, `VACUUM INTO ?`, tmp); err != nil {
+		return dbFile{}, err
+	}
+	data, err := os.ReadFile(tmp)
+	return dbFile{name: FileName, data: data}, err
+}
+
+// writeBackup writes the archive of db and, unless dbOnly is set, the other
+// files in dir.
+func writeBackup(w io.Writer, dir string, db dbFile, version int, dbOnly bool, passphrase string) (*BackupManifest, error) {
+	var sl *sealer
+	out := w
+	var sealed bytes.Buffer
+	if passphrase != "" {
+		var err error
+		if sl, err = newRandomSealer(passphrase); err != nil {
+			return nil, err
+		}
+		out = &sealed
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	m := &BackupManifest{Created: now, SchemaVersion: version, DatabaseOnly: dbOnly}
+
+	add := func(hdr *tar.Header, r io.Reader) error {
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		f := BackupFile{Name: hdr.Name, Link: hdr.Linkname}
+		if r != nil {
+			h := sha256.New()
+			n, err := io.Copy(io.MultiWriter(tw, h), r)
+			if err != nil {
+				return err
+			}
+			f.Size, f.SHA256 = n, hex.EncodeToString(h.Sum(nil))
+		}
+		m.Files = append(m.Files, f)
+		return nil
+	}
+
+	err := add(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     db.name,
+		Mode:     0o600,
+		Size:     int64(len(db.data)),
+		ModTime:  now,
+	}, bytes.NewReader(db.data))
+	if err != nil {
+		return nil, err
+	}
+	if !dbOnly {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, path)
+			if err != nil || rel == "." {
+				return err
+			}
+			if skipBackup(rel) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			switch {
+			case info.Mode()&fs.ModeSymlink != 0:
+				target, err := os.Readlink(path)
+				if err != nil {
+					return err
+				}
+				hdr, err := tar.FileInfoHeader(info, target)
+				if err != nil {
+					return err
+				}
+				hdr.Name = filepath.ToSlash(rel)
+				return add(hdr, nil)
+			case info.Mode().IsRegular():
+				hdr, err := tar.FileInfoHeader(info, "")
+				if err != nil {
+					return err
+				}
+				hdr.Name = filepath.ToSlash(rel)
+				f, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				return add(hdr, f)
+			}
+			// Directories are made again from the paths of their files.
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     manifestName,
+		Mode:     0o600,
+		Size:     int64(len(manifest)),
+		ModTime:  now,
+	})
+	if err == nil {
+		_, err = tw.Write(manifest)
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if sl != nil {
+		data, err := sl.seal(sealed.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxSealedBackup {
+			return nil, fmt.Errorf("the backup is over %d MB, too large to encrypt; back up without a passphrase", maxSealedBackup>>20)
+		}
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// skipBackup reports whether a path inside the data directory is left out
+// of backups: the database, which is copied on its own, SQLite's scratch
+// files, unfinished writes, the trash, drafts and the saved TUI session,
+// the backups themselves and hidden files such as the git repository of
+// sync.
+func skipBackup(rel string) bool {
+	top, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return strings.HasPrefix(top, ".") || top == TrashDir || top == DraftsDir || top == BackupsDir || rel == SessionFileName ||
+		isDatabaseFile(rel) || strings.HasSuffix(rel, ".tmp")
+}
+
+// isDatabaseFile reports whether name is the database, plain or encrypted,
+// or one of its write-ahead log files.
+func isDatabaseFile(name string) bool {
+	switch name {
+	case FileName, FileName + "-wal", FileName + "-shm", EncryptedFileName:
+		return true
+	}
+	return false
+}
+
+// IsSealedBackup reports whether the backup at path is sealed with a
+// passphrase.
+func IsSealedBackup(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("storage: restore: %w", err)
+	}
+	defer f.Close()
+	magic, err := bufio.NewReader(f).Peek(len(cryptMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("storage: restore: %w", err)
+	}
+	return string(magic) == cryptMagic, nil
+}
+
+// Restore replaces the data in dir with the backup at path, unsealing it
+// with passphrase when it is sealed. Every file is checked against the
+// manifest before anything in dir changes. A backup of the whole data
+// directory replaces everything but the automatic backups and hidden files
+// such as the git repository of sync; a database-only backup replaces the
+// database. The data replaced is first backed up into BackupsDir; Restore
+// returns the path of that backup, if there was data to back up.
+func Restore(ctx context.Context, dir, path, passphrase string) (*BackupManifest, string, error) {
+	sealed, err := IsSealedBackup(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if sealed && passphrase == "" {
+		return nil, "", ErrBackupSealed
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("storage: restore: %w", err)
+	}
+	defer f.Close()
+	var archive io.Reader = bufio.NewReader(f)
+	if sealed {
+		data, err := io.ReadAll(io.LimitReader(f, maxSealedBackup+1))
+		if err != nil {
+			return nil, "", fmt.Errorf("storage: restore: %w", err)
+		}
+		if len(data) > maxSealedBackup {
+			return nil, "", fmt.Errorf("storage: restore %s: the backup is over %d MB, larger than an encrypted backup can be", path, maxSealedBackup>>20)
+		}
+		if data, _, err = unseal(data, passphrase); err != nil {
+			return nil, "", err
+		}
+		archive = bytes.NewReader(data)
+	}
+
+	// Extract next to dir, so the files can be moved into it.
+	parent := filepath.Dir(dir)
+	if err := os.MkdirAll(parent, 0o700); err != nil {
+		return nil, "", fmt.Errorf("storage: restore: %w", err)
+	}
+	staged, err := os.MkdirTemp(parent, ".nomadic-restore-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("storage: restore: %w", err)
+	}
+	defer os.RemoveAll(staged)
+	m, err := extractBackup(archive, staged)
+	if err != nil {
+		return nil, "", fmt.Errorf("storage: restore %s: %w", path, err)
+	}
+	if latest := LatestVersion(); m.SchemaVersion > latest {
+		return nil, "", fmt.Errorf("storage: restore %s: the backup is from a newer version of nomadic (schema %d, this one knows %d)", path, m.SchemaVersion, latest)
+	}
+
+	saved := ""
+	if hasDatabase(dir) {
+		db, version, err := snapshotDir(ctx, dir)
+		if err != nil {
+			return nil, "", err
+		}
+		if saved, err = autoBackup(dir, "pre-restore", db, version, false); err != nil {
+			return nil, "", fmt.Errorf("storage: back up before restoring: %w", err)
+		}
+	}
+	// Nothing else may have the data directory open while it is replaced.
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, saved, fmt.Errorf("storage: restore: %w", err)
+	}
+	lock, err := lockDir(dir, true)
+	if err != nil {
+		return nil, saved, err
+	}
+	defer lock.release()
+	if err := replaceData(dir, staged, m.DatabaseOnly); err != nil {
+		return nil, saved, fmt.Errorf("storage: restore: %w", err)
+	}
+	return m, saved, nil
+}
+
+// mkdirInside makes the directory path inside dir, refusing to go through
+// anything on the way that is not a directory, such as a link out of dir.
+func mkdirInside(dir, path string) error {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("the archive holds an unsafe path %q", path)
+	}
+	if rel == "." {
+		return nil
+	}
+	at := dir
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		at = filepath.Join(at, name)
+		info, err := os.Lstat(at)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if err := os.Mkdir(at, 0o700); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case !info.IsDir():
+			return fmt.Errorf("the archive writes through %q, which is not a directory", filepath.ToSlash(rel))
+		}
+	}
+	return nil
+}
+
+// extractBackup unpacks an archive into dir and checks its files against
+// the manifest.
+func extractBackup(r io.Reader, dir string) (*BackupManifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.New("not a nomadic backup")
+	}
+	tr := tar.NewReader(gz)
+	var m *BackupManifest
+	got := map[string]BackupFile{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("the archive is damaged: %w", err)
+		}
+		if !filepath.IsLocal(filepath.FromSlash(hdr.Name)) {
+			return nil, fmt.Errorf("the archive holds an unsafe path %q", hdr.Name)
+		}
+		// What restoring keeps in place cannot come from the archive.
+		if !restored(hdr.Name, false) && hdr.Name != manifestName {
+			return nil, fmt.Errorf("the archive holds %q, which no backup has", hdr.Name)
+		}
+		if hdr.Name == manifestName {
+			if err := json.NewDecoder(io.LimitReader(tr, maxManifest)).Decode(&m); err != nil {
+				return nil, fmt.Errorf("the manifest is damaged: %w", err)
+			}
+			continue
+		}
+		dst := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if err := mkdirInside(dir, filepath.Dir(dst)); err != nil {
+			return nil, err
+		}
+		f := BackupFile{Name: hdr.Name}
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				return nil, err
+			}
+			h := sha256.New()
+			n, err := io.Copy(io.MultiWriter(out, h), tr)
+			if cerr := out.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return nil, fmt.Errorf("the archive is damaged: %w", err)
+			}
+			f.Size, f.SHA256 = n, hex.EncodeToString(h.Sum(nil))
+		case tar.TypeSymlink:
+			// A link is checked against the manifest but not made: the
+			// original of a linked attachment lies outside the data
+			// directory, maybe on another device, and a link made from an
+			// archive could lead the entries after it out of dir.
+			f.Link = hdr.Linkname
+		default:
+			return nil, fmt.Errorf("the archive holds %q, which is not a file", hdr.Name)
+		}
+		got[hdr.Name] = f
+	}
+	// Reading to the end checks gzip's checksum of the whole archive.
+	if _, err := io.Copy(io.Discard, gz); err != nil {
+		return nil, fmt.Errorf("the archive is damaged: %w", err)
+	}
+
+	if m == nil {
+		return nil, errors.New("the archive has no manifest; not a nomadic backup")
+	}
+	for _, want := range m.Files {
+		f, ok := got[want.Name]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("%s is missing from the archive", want.Name)
+		case f != want:
+			return nil, fmt.Errorf("%s does not match its checksum", want.Name)
+		}
+		delete(got, want.Name)
+	}
+	for name := range got {
+		return nil, fmt.Errorf("%s is not in the manifest", name)
+	}
+	if !slices.ContainsFunc(m.Files, func(f BackupFile) bool { return f.Name == FileName || f.Name == EncryptedFileName }) {
+		return nil, errors.New("the archive holds no database")
+	}
+	return m, nil
+}
+
+// hasDatabase reports whether dir holds a database, plain or encrypted.
+func hasDatabase(dir string) bool {
+	for _, name := range []string{FileName, EncryptedFileName} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// restored reports whether the entry name at the top of the data
+// directory, or a path under it, is replaced by restoring: all but the
+// automatic backups and hidden files, or only the database when dbOnly is
+// set.
+func restored(name string, dbOnly bool) bool {
+	top, _, _ := strings.Cut(name, "/")
+	return !strings.HasPrefix(top, ".") && top != BackupsDir && (!dbOnly || isDatabaseFile(name))
+}
+
+// replaceData swaps the files in dir for those staged, keeping the
+// automatic backups and hidden files, and the files around the database
+// when only the database is replaced. The files replaced are moved aside
+// and deleted once all those staged are in place, or moved back if one
+// cannot be.
+func replaceData(dir, staged string, dbOnly bool) (err error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	aside, err := os.MkdirTemp(dir, ".nomadic-replaced-*")
+	if err != nil {
+		return err
+	}
+	var movedAside, movedIn []string
+	defer func() {
+		if err == nil {
+			os.RemoveAll(aside)
+			return
+		}
+		for _, name := range movedIn {
+			os.RemoveAll(filepath.Join(dir, name))
+		}
+		for _, name := range movedAside {
+			if rerr := os.Rename(filepath.Join(aside, name), filepath.Join(dir, name)); rerr != nil {
+				err = fmt.Errorf("%w; the data it replaced is left in %s", err, aside)
+				return
+			}
+		}
+		os.Remove(aside)
+	}()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if name := e.Name(); restored(name, dbOnly) {
+			if err := os.Rename(filepath.Join(dir, name), filepath.Join(aside, name)); err != nil {
+				return err
+			}
+			movedAside = append(movedAside, name)
+		}
+	}
+	entries, err = os.ReadDir(staged)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if name := e.Name(); restored(name, dbOnly) {
+			if err := os.Rename(filepath.Join(staged, name), filepath.Join(dir, name)); err != nil {
+				return err
+			}
+			movedIn = append(movedIn, name)
+		}
+	}
+	return nil
+}
+
+// autoBackup saves a backup into BackupsDir, named after the reason it is
+// taken, and removes all but the newest KeepBackups of them. It returns
+// the path of the backup.
+func autoBackup(dir, reason string, db dbFile, version int, dbOnly bool) (string, error) {
+	backups := filepath.Join(dir, BackupsDir)
+	if err := os.MkdirAll(backups, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(backups, reason+"-"+time.Now().Format(backupStamp)+BackupExt)
+	var buf bytes.Buffer
+	if _, err := writeBackup(&buf, dir, db, version, dbOnly, ""); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return "", err
+	}
+	return path, rotateBackups(backups)
+}
+
+// rotateBackups removes all but the newest KeepBackups backups in dir.
+func rotateBackups(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	type backup struct {
+		name    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), BackupExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		backups = append(backups, backup{e.Name(), info.ModTime()})
+	}
+	slices.SortFunc(backups, func(a, b backup) int { return b.modTime.Compare(a.modTime) })
+	for _, b := range backups[min(KeepBackups, len(backups)):] {
+		if err := os.Remove(filepath.Join(dir, b.name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
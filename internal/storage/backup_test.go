@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// archive gzips a tar of the headers, with body as the content of the
+// regular files.
+func archive(t *testing.T, body string, hdrs ...*tar.Header) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, hdr := range hdrs {
+		if hdr.Typeflag == tar.TypeReg {
+			hdr.Size, hdr.Mode = int64(len(body)), 0o600
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtractBackupDoesNotWriteThroughLinks(t *testing.T) {
+	outside := t.TempDir()
+	for _, target := range []string{outside, "../" + filepath.Base(outside), "."} {
+		dir := t.TempDir()
+		r := archive(t, "pwned",
+			&tar.Header{Name: "attachments", Typeflag: tar.TypeSymlink, Linkname: target},
+			&tar.Header{Name: "attachments/x", Typeflag: tar.TypeReg},
+		)
+		extractBackup(r, dir)
+		if _, err := os.Lstat(filepath.Join(outside, "x")); err == nil {
+			t.Fatalf("link to %q: wrote outside the directory", target)
+		}
+		if info, err := os.Lstat(filepath.Join(dir, "attachments")); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			t.Errorf("link to %q: made the link", target)
+		}
+	}
+}
+
+func TestExtractBackupRefusesFilesOverDirectories(t *testing.T) {
+	r := archive(t, "x",
+		&tar.Header{Name: "attachments", Typeflag: tar.TypeReg},
+		&tar.Header{Name: "attachments/x", Typeflag: tar.TypeReg},
+	)
+	if _, err := extractBackup(r, t.TempDir()); err == nil {
+		t.Error("extracted a file under a file")
+	}
+}
+
+func TestExtractBackupRefusesWhatRestoringKeeps(t *testing.T) {
+	for _, name := range []string{BackupsDir + "/x" + BackupExt, ".git/config", LockFileName} {
+		r := archive(t, "x", &tar.Header{Name: name, Typeflag: tar.TypeReg})
+		if _, err := extractBackup(r, t.TempDir()); err == nil {
+			t.Errorf("extracted %s", name)
+		}
+	}
+}
+
+func TestReplaceData(t *testing.T) {
+	write := func(dir, name, body string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dir, staged := t.TempDir(), t.TempDir()
+	write(dir, FileName, "old")
+	write(dir, "attachments/a/photo.jpg", "old")
+	write(dir, BackupsDir+"/pre-restore"+BackupExt, "kept")
+	write(dir, ".git/HEAD", "kept")
+	write(staged, FileName, "new")
+	write(staged, "attachments/b/photo.jpg", "new")
+
+	if err := replaceData(dir, staged, false); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		FileName:                                "new",
+		"attachments/b/photo.jpg":               "new",
+		BackupsDir + "/pre-restore" + BackupExt: "kept",
+		".git/HEAD":                             "kept",
+	} {
+		if got, err := os.ReadFile(filepath.Join(dir, name)); err != nil || string(got) != want {
+			t.Errorf("%s holds %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "attachments/a")); err == nil {
+		t.Error("the attachments replaced are still there")
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".nomadic-replaced-") {
+			t.Errorf("%s is left behind", e.Name())
+		}
+	}
+}
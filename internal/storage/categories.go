@@ -0,0 +1,275 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const categoryColumns = `id, name, icon, colour, position, created_at, updated_at`
+
+// ErrBuiltIn is returned when the category models.CategoryOther is renamed
+// or removed: expenses that fit no other category go there.
+var ErrBuiltIn = errors.New("storage: other is where expenses fitting no other category go and must stay")
+
+// SaveCategory inserts the category, or updates it if one with the same
+// ID exists. Names are unique; use RenameCategory to change the name of a
+// category expenses are in. A new category goes after the others.
+func (s *Store) SaveCategory(ctx context.Context, c *models.Category) error {
+	if c.ID == "" {
+		c.ID = models.NewID()
+	}
+	now := time.Now()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	c.UpdatedAt = now
+	c.Name = strings.ToLower(strings.TrimSpace(c.Name))
+
+	if err := s.checkCategoryName(ctx, c.ID, c.Name); err != nil {
+		return err
+	}
+	_, err := s.exec(ctx, `
+INSERT INTO categories (`+categoryColumns+`)
+VALUES (?1, ?2, ?3, ?4, (SELECT coalesce(max(position) + 1, 0) FROM categories), ?5, ?6)
+ON CONFLICT(id) DO UPDATE SET
+	name = excluded.name,
+	icon = excluded.icon,
+	colour = excluded.colour,
+	updated_at = excluded.updated_at`,
+		c.ID, c.Name, c.Icon, c.Colour, formatTime(c.CreatedAt), formatTime(c.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save category: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT position FROM categories WHERE id = ?`, c.ID).Scan(&c.Position); err != nil {
+		return fmt.Errorf("storage: save category: %w", err)
+	}
+	return s.useCategories(ctx)
+}
+
+// checkCategoryName returns ErrNameTaken when a category other than the
+// one with id is called name.
+func (s *Store) checkCategoryName(ctx context.Context, id, name string) error {
+	var other string
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM categories WHERE name = ? AND id <> ?`, name, id).Scan(&other)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("storage: check category name: %w", err)
+	}
+	return fmt.Errorf("%w: there is already a category called %s", ErrNameTaken, name)
+}
+
+// GetCategoryByName returns the category called name.
+func (s *Store) GetCategoryByName(ctx context.Context, name string) (*models.Category, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+categoryColumns+` FROM categories WHERE name = ?`,
+		strings.ToLower(strings.TrimSpace(name)))
+	c, err := scanCategory(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get category: %w", err)
+	}
+	return c, nil
+}
+
+// ListCategories returns every expense category in display order.
+func (s *Store) ListCategories(ctx context.Context) ([]*models.Category, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+categoryColumns+` FROM categories ORDER BY position, name`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list categories: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []*models.Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list categories: %w", err)
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// CountByCategory returns how many expenses are in each category, by name.
+func (s *Store) CountByCategory(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT category, count(*) FROM expenses GROUP BY category`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: count by category: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var (
+			name string
+			n    int
+		)
+		if err := rows.Scan(&name, &n); err != nil {
+			return nil, fmt.Errorf("storage: count by category: %w", err)
+		}
+		counts[name] = n
+	}
+	return counts, rows.Err()
+}
+
+// useCategories puts the stored categories in use throughout nomadic.
+func (s *Store) useCategories(ctx context.Context) error {
+	categories, err := s.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+	models.UseCategories(categories)
+	return nil
+}
+
+// MoveCategory moves c to position, counted from zero among the
+// categories in display order, and closes up the others around it.
+func (s *Store) MoveCategory(ctx context.Context, c *models.Category, position int) error {
+	categories, err := s.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+	order := make([]*models.Category, 0, len(categories))
+	for _, other := range categories {
+		if other.ID != c.ID {
+			order = append(order, other)
+		}
+	}
+	position = max(0, min(position, len(order)))
+	order = append(order[:position], append([]*models.Category{c}, order[position:]...)...)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: move category: %w", err)
+	}
+	defer tx.Rollback()
+	for i, other := range order {
+		if _, err := tx.ExecContext(ctx, `UPDATE categories SET position = ? WHERE id = ?`, i, other.ID); err != nil {
+			return fmt.Errorf("storage: move category: %w", err)
+		}
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: move category: %w", err)
+	}
+	c.Position = position
+	return s.useCategories(ctx)
+}
+
+// recategorize are the statements moving everything in the category ?1 to
+// ?2 as of the time ?3: expenses, recurring expenses, categorization rules
+// and the category budgets of trips and templates, where a budget for ?1
+// is added to any for ?2.
+var recategorize = []string{
+	`UPDATE expenses SET category = ?2, updated_at = ?3 WHERE category = ?1`,
+	`UPDATE recurrences SET category = ?2, updated_at = ?3 WHERE category = ?1`,
+	`UPDATE rules SET category = ?2, updated_at = ?3 WHERE category = ?1`,
+	`UPDATE trips SET updated_at = ?3, category_budgets = json_remove(json_set(category_budgets, '$."' || ?2 || '"',
+	coalesce(json_extract(category_budgets, '$."' || ?2 || '"'), 0) + json_extract(category_budgets, '$."' || ?1 || '"')),
+	'$."' || ?1 || '"')
+WHERE json_extract(category_budgets, '$."' || ?1 || '"') IS NOT NULL`,
+	`UPDATE templates SET updated_at = ?3, category_budgets = json_remove(json_set(category_budgets, '$."' || ?2 || '"',
+	coalesce(json_extract(category_budgets, '$."' || ?2 || '"'), 0) + json_extract(category_budgets, '$."' || ?1 || '"')),
+	'$."' || ?1 || '"')
+WHERE json_extract(category_budgets, '$."' || ?1 || '"') IS NOT NULL`,
+}
+
+// RenameCategory renames c to name, and with it the category of every
+// expense, recurring expense and rule in it and its budgets on trips and
+// templates, in one transaction. Items in the trash keep the old name.
+func (s *Store) RenameCategory(ctx context.Context, c *models.Category, name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if c.Name == models.CategoryOther {
+		return ErrBuiltIn
+	}
+	if err := s.checkCategoryName(ctx, c.ID, name); err != nil {
+		return err
+	}
+	now := time.Now()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: rename category: %w", err)
+	}
+	defer tx.Rollback()
+	at := formatTime(now)
+	if _, err := tx.ExecContext(ctx, `UPDATE categories SET name = ?, updated_at = ? WHERE id = ?`, name, at, c.ID); err != nil {
+		return fmt.Errorf("storage: rename category: %w", err)
+	}
+	for _, q := range recategorize {
+		if _, err := tx.ExecContext(ctx, q, c.Name, name, at); err != nil {
+			return fmt.Errorf("storage: rename category: %w", err)
+		}
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: rename category: %w", err)
+	}
+	c.Name, c.UpdatedAt = name, now
+	return s.useCategories(ctx)
+}
+
+// MergeCategory moves every expense, recurring expense and rule in c to
+// into, adds c's budgets on trips and templates to into's, and removes c,
+// in one transaction. It returns how many expenses moved.
+func (s *Store) MergeCategory(ctx context.Context, c, into *models.Category) (int, error) {
+	switch {
+	case c.Name == models.CategoryOther:
+		return 0, ErrBuiltIn
+	case c.ID == into.ID:
+		return 0, fmt.Errorf("storage: cannot merge %s into itself", c.Name)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("storage: merge category: %w", err)
+	}
+	defer tx.Rollback()
+	var moved int
+	if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM expenses WHERE category = ?`, c.Name).Scan(&moved); err != nil {
+		return 0, fmt.Errorf("storage: merge category: %w", err)
+	}
+	at := formatTime(time.Now())
+	for _, q := range recategorize {
+		if _, err := tx.ExecContext(ctx, q, c.Name, into.Name, at); err != nil {
+			return 0, fmt.Errorf("storage: merge category: %w", err)
+		}
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = ?`, c.ID)
+	if err != nil {
+		return 0, fmt.Errorf("storage: merge category: %w", err)
+	}
+	if err := expectAffected(res); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE categories SET position = position - 1 WHERE position > ?`, c.Position); err != nil {
+		return 0, fmt.Errorf("storage: merge category: %w", err)
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return 0, fmt.Errorf("storage: merge category: %w", err)
+	}
+	return moved, s.useCategories(ctx)
+}
+
+func scanCategory(sc scanner) (*models.Category, error) {
+	var (
+		c            models.Category
+		created, upd string
+	)
+	if err := sc.Scan(&c.ID, &c.Name, &c.Icon, &c.Colour, &c.Position, &created, &upd); err != nil {
+		return nil, err
+	}
+	var err error
+	if c.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if c.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const checkInColumns = `id, trip_id, place, note, lat, lon, timestamp, time_zone, created_at`
+
+// SaveCheckIn inserts the check-in, or updates it if one with the same ID
+// exists.
+func (s *Store) SaveCheckIn(ctx context.Context, c *models.CheckIn) error {
+	if c.ID == "" {
+		c.ID = models.NewID()
+	}
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now()
+	}
+	_, err := s.exec(ctx, `
+INSERT INTO checkins (`+checkInColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	place = excluded.place,
+	note = excluded.note,
+	lat = excluded.lat,
+	lon = excluded.lon,
+	timestamp = excluded.timestamp,
+	time_zone = excluded.time_zone`,
+		c.ID, c.TripID, c.Place, c.Note, c.Lat, c.Lon, formatTime(c.Timestamp), c.TimeZone, formatTime(c.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save check-in: %w", err)
+	}
+	return nil
+}
+
+// GetCheckIn returns the check-in with the given ID.
+func (s *Store) GetCheckIn(ctx context.Context, id string) (*models.CheckIn, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+checkInColumns+` FROM checkins WHERE id = ?`, id)
+	c, err := scanCheckIn(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get check-in: %w", err)
+	}
+	return c, nil
+}
+
+// ListCheckInsByTrip returns a trip's check-ins in chronological order.
+func (s *Store) ListCheckInsByTrip(ctx context.Context, tripID string) ([]*models.CheckIn, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+checkInColumns+` FROM checkins WHERE trip_id = ? ORDER BY timestamp`, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list check-ins: %w", err)
+	}
+	defer rows.Close()
+
+	var checkIns []*models.CheckIn
+	for rows.Next() {
+		c, err := scanCheckIn(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list check-ins: %w", err)
+		}
+		checkIns = append(checkIns, c)
+	}
+	return checkIns, rows.Err()
+}
+
+// DeleteCheckIn removes a check-in.
+func (s *Store) DeleteCheckIn(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM checkins WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete check-in: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanCheckIn(sc scanner) (*models.CheckIn, error) {
+	var (
+		c           models.CheckIn
+		ts, created string
+	)
+	if err := sc.Scan(&c.ID, &c.TripID, &c.Place, &c.Note, &c.Lat, &c.Lon, &ts, &c.TimeZone, &created); err != nil {
+		return nil, err
+	}
+	var err error
+	if c.Timestamp, err = parseTime(ts); err != nil {
+		return nil, err
+	}
+	c.Timestamp = models.InZone(c.Timestamp, c.TimeZone)
+	if c.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
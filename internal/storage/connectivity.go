@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const connectivityColumns = `id, country, trip_id, leg_id, place, provider, cost, currency, speed, wifi, notes, created_at, updated_at`
+
+// SaveConnectivity inserts the note, or updates it if one with the same
+// ID exists.
+func (s *Store) SaveConnectivity(ctx context.Context, c *models.Connectivity) error {
+	if c.ID == "" {
+		c.ID = models.NewID()
+	}
+	now := time.Now()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	c.UpdatedAt = now
+	c.Country = strings.ToUpper(c.Country)
+
+	wifi, err := marshalJSON(c.WiFi, "[]")
+	if err != nil {
+		return err
+	}
+	tripID := sql.NullString{String: c.TripID, Valid: c.TripID != ""}
+	legID := sql.NullString{String: c.LegID, Valid: c.LegID != ""}
+	_, err = s.exec(ctx, `
+INSERT INTO connectivity (`+connectivityColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	country = excluded.country,
+	trip_id = excluded.trip_id,
+	leg_id = excluded.leg_id,
+	place = excluded.place,
+	provider = excluded.provider,
+	cost = excluded.cost,
+	currency = excluded.currency,
+	speed = excluded.speed,
+	wifi = excluded.wifi,
+	notes = excluded.notes,
+	updated_at = excluded.updated_at`,
+		c.ID, c.Country, tripID, legID, c.Place, c.Provider, c.Cost, c.Currency, c.Speed, wifi, c.Notes,
+		formatTime(c.CreatedAt), formatTime(c.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save connectivity: %w", err)
+	}
+	return nil
+}
+
+// GetConnectivity returns the connectivity note with the given ID.
+func (s *Store) GetConnectivity(ctx context.Context, id string) (*models.Connectivity, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+connectivityColumns+` FROM connectivity WHERE id = ?`, id)
+	c, err := scanConnectivity(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get connectivity: %w", err)
+	}
+	return c, nil
+}
+
+// ListConnectivity returns the connectivity notes ordered by country, the
+// latest first within each.
+func (s *Store) ListConnectivity(ctx context.Context) ([]*models.Connectivity, error) {
+	return s.queryConnectivity(ctx, ``)
+}
+
+// ListConnectivityFor returns the notes on the countries with the given
+// codes, in their order, the latest first within each.
+func (s *Store) ListConnectivityFor(ctx context.Context, countries []string) ([]*models.Connectivity, error) {
+	var out []*models.Connectivity
+	for _, country := range countries {
+		notes, err := s.queryConnectivity(ctx, `WHERE country = ?`, strings.ToUpper(country))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, notes...)
+	}
+	return out, nil
+}
+
+func (s *Store) queryConnectivity(ctx context.Context, where string, args ...any) ([]*models.Connectivity, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+connectivityColumns+` FROM connectivity `+where+`
+ORDER BY country, created_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list connectivity: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []*models.Connectivity
+	for rows.Next() {
+		c, err := scanConnectivity(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list connectivity: %w", err)
+		}
+		notes = append(notes, c)
+	}
+	return notes, rows.Err()
+}
+
+// DeleteConnectivity removes a connectivity note.
+func (s *Store) DeleteConnectivity(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM connectivity WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete connectivity: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanConnectivity(sc scanner) (*models.Connectivity, error) {
+	var (
+		c                  models.Connectivity
+		tripID, legID      sql.NullString
+		wifi, created, upd string
+	)
+	if err := sc.Scan(&c.ID, &c.Country, &tripID, &legID, &c.Place, &c.Provider, &c.Cost, &c.Currency, &c.Speed,
+		&wifi, &c.Notes, &created, &upd); err != nil {
+		return nil, err
+	}
+	c.TripID, c.LegID = tripID.String, legID.String
+	if err := json.Unmarshal([]byte(wifi), &c.WiFi); err != nil {
+		return nil, err
+	}
+	var err error
+	if c.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if c.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
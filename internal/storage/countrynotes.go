@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const countryNoteColumns = `country, visa, max_stay, notes, created_at, updated_at`
+
+// SaveCountryNote inserts the note, or replaces the one on the same
+// country.
+func (s *Store) SaveCountryNote(ctx context.Context, n *models.CountryNote) error {
+	now := time.Now()
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = now
+	}
+	n.UpdatedAt = now
+	n.Country = strings.ToUpper(n.Country)
+
+	_, err := s.exec(ctx, `
+INSERT INTO country_notes (`+countryNoteColumns+`) VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(country) DO UPDATE SET
+	visa = excluded.visa,
+	max_stay = excluded.max_stay,
+	notes = excluded.notes,
+	updated_at = excluded.updated_at`,
+		n.Country, n.Visa, n.MaxStay, n.Notes, formatTime(n.CreatedAt), formatTime(n.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save country note: %w", err)
+	}
+	return nil
+}
+
+// GetCountryNote returns the note on the country with an ISO 3166-1
+// alpha-2 code.
+func (s *Store) GetCountryNote(ctx context.Context, country string) (*models.CountryNote, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+countryNoteColumns+` FROM country_notes WHERE country = ?`, strings.ToUpper(country))
+	n, err := scanCountryNote(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get country note: %w", err)
+	}
+	return n, nil
+}
+
+// ListCountryNotes returns the notes ordered by country code.
+func (s *Store) ListCountryNotes(ctx context.Context) ([]*models.CountryNote, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+countryNoteColumns+` FROM country_notes ORDER BY country`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list country notes: %w", err)
+	}
+	defer rows.Close()
+
+	var notes []*models.CountryNote
+	for rows.Next() {
+		n, err := scanCountryNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list country notes: %w", err)
+		}
+		notes = append(notes, n)
+	}
+	return notes, rows.Err()
+}
+
+// ListCountryNotesFor returns the notes on the countries with the given
+// codes, in their order, skipping those without one.
+func (s *Store) ListCountryNotesFor(ctx context.Context, countries []string) ([]*models.CountryNote, error) {
+	var notes []*models.CountryNote
+	for _, c := range countries {
+		n, err := s.GetCountryNote(ctx, c)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
+
+// DeleteCountryNote removes the note on a country.
+func (s *Store) DeleteCountryNote(ctx context.Context, country string) error {
+	res, err := s.exec(ctx, `DELETE FROM country_notes WHERE country = ?`, strings.ToUpper(country))
+	if err != nil {
+		return fmt.Errorf("storage: delete country note: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanCountryNote(sc scanner) (*models.CountryNote, error) {
+	var (
+		n            models.CountryNote
+		created, upd string
+	)
+	if err := sc.Scan(&n.Country, &n.Visa, &n.MaxStay, &n.Notes, &created, &upd); err != nil {
+		return nil, err
+	}
+	var err error
+	if n.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if n.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
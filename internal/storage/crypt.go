@@ -0,0 +1,449 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlitevfs "modernc.org/sqlite/vfs"
+)
+
+// EncryptedFileName is the name of the encrypted database inside the data
+// directory. A data directory holds either it or FileName, never both.
+const EncryptedFileName = FileName + ".enc"
+
+var (
+	// ErrEncrypted is returned by Open when the database is encrypted and
+	// must be opened with OpenEncrypted instead.
+	ErrEncrypted = errors.New("storage: database is encrypted")
+	// ErrPassphrase is returned when a passphrase does not decrypt the
+	// database.
+	ErrPassphrase = errors.New("storage: wrong passphrase")
+)
+
+// The encrypted file is a header followed by the SQLite database sealed
+// with AES-256-GCM. The key is derived from the passphrase with
+// PBKDF2-SHA256; the header is authenticated along with the data.
+//
+//	magic[8] | iterations uint32 | salt[16] | nonce[12] | ciphertext
+const (
+	cryptMagic      = "NOMADIC\x01"
+	cryptIterations = 600_000
+	saltSize        = 16
+	headerSize      = len(cryptMagic) + 4 + saltSize
+)
+
+// sealer encrypts database images with a key derived once per session.
+type sealer struct {
+	aead   cipher.AEAD
+	header []byte // magic, iterations and salt
+}
+
+func newSealer(passphrase string, iterations int, salt []byte) (*sealer, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, 0, headerSize)
+	header = append(header, cryptMagic...)
+	header = binary.BigEndian.AppendUint32(header, uint32(iterations))
+	header = append(header, salt...)
+	return &sealer{aead: aead, header: header}, nil
+}
+
+// newRandomSealer derives a key from passphrase with a fresh salt.
+func newRandomSealer(passphrase string) (*sealer, error) {
+	if passphrase == "" {
+		return nil, errors.New("storage: the passphrase must not be empty")
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return newSealer(passphrase, cryptIterations, salt)
+}
+
+// unseal decrypts an encrypted database file, returning the plaintext and
+// a sealer that re-encrypts with the same key.
+func unseal(data []byte, passphrase string) ([]byte, *sealer, error) {
+	if len(data) < headerSize || string(data[:len(cryptMagic)]) != cryptMagic {
+		return nil, nil, errors.New("storage: not an encrypted nomadic database")
+	}
+	iterations := int(binary.BigEndian.Uint32(data[len(cryptMagic):]))
+	salt := data[len(cryptMagic)+4 : headerSize]
+	s, err := newSealer(passphrase, iterations, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	rest := data[headerSize:]
+	n := s.aead.NonceSize()
+	if len(rest) < n {
+		return nil, nil, errors.New("storage: encrypted database is truncated")
+	}
+	plain, err := s.aead.Open(nil, rest[:n], rest[n:], data[:headerSize])
+	if err != nil {
+		return nil, nil, ErrPassphrase
+	}
+	return plain, s, nil
+}
+
+func (s *sealer) seal(plain []byte) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := bytes.Clone(s.header)
+	out = append(out, nonce...)
+	return s.aead.Seal(out, nonce, plain, s.header), nil
+}
+
+// open decrypts data sealed with the same key.
+func (s *sealer) open(data []byte) ([]byte, error) {
+	n := s.aead.NonceSize()
+	if len(data) < headerSize+n || !bytes.Equal(data[:headerSize], s.header) {
+		return nil, ErrPassphrase
+	}
+	plain, err := s.aead.Open(nil, data[headerSize:headerSize+n], data[headerSize+n:], s.header)
+	if err != nil {
+		return nil, ErrPassphrase
+	}
+	return plain, nil
+}
+
+// IsEncrypted reports whether the database in dir is encrypted.
+func IsEncrypted(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, EncryptedFileName))
+	return err == nil
+}
+
+// OpenEncrypted decrypts the database in dir with passphrase and opens it.
+// The decrypted database lives only in memory; every change is encrypted
+// and written back before the call that made it returns.
+func OpenEncrypted(ctx context.Context, dir, passphrase string) (*Store, error) {
+	s, err := openEncrypted(ctx, dir, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.migrate(ctx); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// openEncrypted decrypts the database in dir into memory as it is, without
+// migrating.
+func openEncrypted(ctx context.Context, dir, passphrase string) (*Store, error) {
+	// Changes are written back as a whole database, so two processes with
+	// it open would each overwrite what the other wrote.
+	lock, err := lockDir(dir, true)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, EncryptedFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		lock.release()
+		return nil, fmt.Errorf("storage: open %s: %w", path, err)
+	}
+	plain, sl, err := unseal(data, passphrase)
+	if err != nil {
+		lock.release()
+		return nil, err
+	}
+	s, err := openMemory(ctx, plain)
+	if err != nil {
+		lock.release()
+		return nil, fmt.Errorf("storage: load %s: %w", path, err)
+	}
+	s.dir, s.path, s.sealer, s.lock = dir, path, sl, lock
+	return s, nil
+}
+
+// openMemory opens a database held only in memory, loaded with image
+// unless it is empty. Nothing is written back anywhere.
+func openMemory(ctx context.Context, image []byte) (*Store, error) {
+	db, err := sql.Open("sqlite", "file:nomadic?mode=memory&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+	// The in-memory database exists only on this one connection.
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	s := &Store{db: db}
+	if len(image) > 0 {
+		if err := s.load(ctx, image); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// rawConn is the part of the sqlite driver's connection used to move a
+// database image in and out of memory.
+type rawConn interface {
+	Serialize() ([]byte, error)
+	NewRestore(srcURI string) (*sqlite.Backup, error)
+}
+
+// load copies a decrypted database image into the in-memory database. The
+// image is served to SQLite from memory through a read-only VFS and
+// restored with the backup API, so it never touches the disk.
+func (s *Store) load(ctx context.Context, image []byte) error {
+	image = bytes.Clone(image)
+	if len(image) > 19 {
+		// Mark the image as a rollback-journal database: the read-only VFS
+		// cannot provide the shared memory a write-ahead log needs.
+		image[18], image[19] = 1, 1
+	}
+	name, vfs, err := sqlitevfs.New(memFS{name: FileName, data: image})
+	if err != nil {
+		return err
+	}
+	defer vfs.Close()
+	return s.raw(ctx, func(c rawConn) error {
+		b, err := c.NewRestore("file:" + FileName + "?vfs=" + name + "&mode=ro")
+		if err != nil {
+			return err
+		}
+		if _, err := b.Step(-1); err != nil {
+			b.Finish()
+			return err
+		}
+		return b.Finish()
+	})
+}
+
+// memFS is a read-only file system holding a single file in memory.
+type memFS struct {
+	name string
+	data []byte
+}
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if name != m.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{Reader: bytes.NewReader(m.data), fs: m}, nil
+}
+
+type memFile struct {
+	*bytes.Reader
+	fs memFS
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f, nil }
+func (f *memFile) Close() error               { return nil }
+func (f *memFile) Name() string               { return f.fs.name }
+func (f *memFile) Size() int64                { return int64(len(f.fs.data)) }
+func (f *memFile) Mode() fs.FileMode          { return 0o400 }
+func (f *memFile) ModTime() time.Time         { return time.Time{} }
+func (f *memFile) IsDir() bool                { return false }
+func (f *memFile) Sys() any                   { return nil }
+
+// raw runs fn on the driver's connection to the database.
+func (s *Store) raw(ctx context.Context, fn func(rawConn) error) error {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return conn.Raw(func(dc any) error {
+		c, ok := dc.(rawConn)
+		if !ok {
+			return errors.New("sqlite driver cannot serialize databases")
+		}
+		return fn(c)
+	})
+}
+
+// persist writes an encrypted store back to disk. It does nothing for
+// stores kept in a plain database file, which SQLite writes itself. The
+// change persisted is made already, so it is written even when ctx is
+// cancelled.
+func (s *Store) persist(ctx context.Context) error {
+	if s.sealer == nil {
+		return nil
+	}
+	s.persistMu.Lock()
+	defer s.persistMu.Unlock()
+	var plain []byte
+	err := s.raw(context.WithoutCancel(ctx), func(c rawConn) error {
+		var err error
+		plain, err = c.Serialize()
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("storage: save encrypted database: %w", err)
+	}
+	data, err := s.sealer.seal(plain)
+	if err != nil {
+		return fmt.Errorf("storage: save encrypted database: %w", err)
+	}
+	if err := writeFileAtomic(s.path, data); err != nil {
+		return fmt.Errorf("storage: save encrypted database: %w", err)
+	}
+	return nil
+}
+
+// EnableEncryption encrypts the plain database in dir with passphrase and
+// removes the plain file. Attached files are not encrypted.
+func EnableEncryption(ctx context.Context, dir, passphrase string) error {
+	if IsEncrypted(dir) {
+		return errors.New("storage: the database is already encrypted")
+	}
+	sl, err := newRandomSealer(passphrase)
+	if err != nil {
+		return err
+	}
+	// Opening brings the schema up to date; closing the last connection
+	// checkpoints the write-ahead log into the main file.
+	s, err := Open(ctx, dir)
+	if err != nil {
+		return err
+	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+	lock, err := lockDir(dir, true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+	if IsEncrypted(dir) {
+		return errors.New("storage: the database is already encrypted")
+	}
+	plainPath := filepath.Join(dir, FileName)
+	plain, err := os.ReadFile(plainPath)
+	if err != nil {
+		return fmt.Errorf("storage: encrypt: %w", err)
+	}
+	data, err := sl.seal(plain)
+	if err != nil {
+		return fmt.Errorf("storage: encrypt: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, EncryptedFileName), data); err != nil {
+		return fmt.Errorf("storage: encrypt: %w", err)
+	}
+	return removePlain(dir)
+}
+
+// DisableEncryption decrypts the database in dir back into a plain file.
+func DisableEncryption(dir, passphrase string) error {
+	lock, err := lockDir(dir, true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+	path := filepath.Join(dir, EncryptedFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return errors.New("storage: the database is not encrypted")
+	}
+	if err != nil {
+		return fmt.Errorf("storage: decrypt: %w", err)
+	}
+	plain, _, err := unseal(data, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, FileName), plain); err != nil {
+		return fmt.Errorf("storage: decrypt: %w", err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("storage: decrypt: %w", err)
+	}
+	return nil
+}
+
+// ChangePassphrase re-encrypts the database in dir under a key derived
+// from next, with a new salt.
+func ChangePassphrase(dir, current, next string) error {
+	lock, err := lockDir(dir, true)
+	if err != nil {
+		return err
+	}
+	defer lock.release()
+	path := filepath.Join(dir, EncryptedFileName)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return errors.New("storage: the database is not encrypted")
+	}
+	if err != nil {
+		return fmt.Errorf("storage: change passphrase: %w", err)
+	}
+	plain, _, err := unseal(data, current)
+	if err != nil {
+		return err
+	}
+	sl, err := newRandomSealer(next)
+	if err != nil {
+		return err
+	}
+	if data, err = sl.seal(plain); err != nil {
+		return fmt.Errorf("storage: change passphrase: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("storage: change passphrase: %w", err)
+	}
+	return nil
+}
+
+// removePlain deletes the plain database and its write-ahead log files.
+func removePlain(dir string) error {
+	for _, name := range []string{FileName, FileName + "-wal", FileName + "-shm"} {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("storage: remove plain database: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data so a crash never leaves a
+// partly written database behind.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,263 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// DocumentsDir is the directory inside the data directory that holds the
+// documents of trips, one subdirectory per trip.
+const DocumentsDir = "documents"
+
+const documentColumns = `id, trip_id, item_id, expense_id, title, name, content_type, path, linked, size, note, created_at`
+
+// AttachDocument keeps the file at src with a trip, and with one of its
+// itinerary items when itemID is set. The file is copied into the data
+// directory, or symlinked there when link is set. The document is titled
+// after the file until SaveDocument gives it a title.
+func (s *Store) AttachDocument(ctx context.Context, tripID, itemID, src string, link bool) (*models.Document, error) {
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return nil, fmt.Errorf("storage: attach %s: %w", src, err)
+	}
+	info, err := os.Stat(src)
+	if err != nil {
+		return nil, fmt.Errorf("storage: attach: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("storage: attach %s: not a regular file", src)
+	}
+
+	ext := strings.ToLower(filepath.Ext(src))
+	d := &models.Document{
+		ID:          models.NewID(),
+		TripID:      tripID,
+		ItemID:      itemID,
+		Title:       filepath.Base(src),
+		Name:        filepath.Base(src),
+		ContentType: strings.TrimSuffix(mime.TypeByExtension(ext), "; charset=utf-8"),
+		Linked:      link,
+		Size:        info.Size(),
+		CreatedAt:   time.Now(),
+	}
+	d.Path = filepath.Join(tripID, d.ID+ext)
+	dst := s.DocumentPath(d)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return nil, fmt.Errorf("storage: attach: %w", err)
+	}
+	if link {
+		err = os.Symlink(src, dst)
+	} else {
+		err = copyFile(src, dst)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: attach %s: %w", d.Name, err)
+	}
+	if err := s.insertDocument(ctx, d, false); err != nil {
+		os.Remove(dst)
+		return nil, fmt.Errorf("storage: save document: %w", err)
+	}
+	return d, nil
+}
+
+// AttachReceipt keeps the image at src as the receipt of expense x, a
+// document of its trip copied into the data directory and titled after
+// the expense.
+func (s *Store) AttachReceipt(ctx context.Context, x *models.Expense, src string) (*models.Document, error) {
+	d, err := s.AttachDocument(ctx, x.TripID, "", src, false)
+	if err != nil {
+		return nil, err
+	}
+	d.ExpenseID, d.Title = x.ID, "Receipt: "+x.Description
+	res, err := s.exec(ctx, `UPDATE documents SET expense_id = ?, title = ? WHERE id = ?`, d.ExpenseID, d.Title, d.ID)
+	if err == nil {
+		err = expectAffected(res)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: save receipt: %w", err)
+	}
+	return d, nil
+}
+
+// insertDocument adds the row of d, leaving one already there alone when
+// restoring.
+func (s *Store) insertDocument(ctx context.Context, d *models.Document, restoring bool) error {
+	query := `INSERT INTO documents (` + documentColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	if restoring {
+		query += ` ON CONFLICT(id) DO NOTHING`
+	}
+	itemID := sql.NullString{String: d.ItemID, Valid: d.ItemID != ""}
+	expenseID := sql.NullString{String: d.ExpenseID, Valid: d.ExpenseID != ""}
+	_, err := s.exec(ctx, query, d.ID, d.TripID, itemID, expenseID, d.Title, d.Name, d.ContentType, d.Path, d.Linked, d.Size,
+		d.Note, formatTime(d.CreatedAt))
+	return err
+}
+
+// SaveDocument updates what is recorded about a document: its title,
+// note and itinerary item.
+func (s *Store) SaveDocument(ctx context.Context, d *models.Document) error {
+	itemID := sql.NullString{String: d.ItemID, Valid: d.ItemID != ""}
+	res, err := s.exec(ctx, `UPDATE documents SET item_id = ?, title = ?, note = ? WHERE id = ?`, itemID, d.Title, d.Note, d.ID)
+	if err != nil {
+		return fmt.Errorf("storage: save document: %w", err)
+	}
+	return expectAffected(res)
+}
+
+// DocumentPath returns where a document's file lives on disk.
+func (s *Store) DocumentPath(d *models.Document) string {
+	return filepath.Join(s.dir, DocumentsDir, d.Path)
+}
+
+// GetDocument returns the document with the given ID.
+func (s *Store) GetDocument(ctx context.Context, id string) (*models.Document, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+documentColumns+` FROM documents WHERE id = ?`, id)
+	d, err := scanDocument(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get document: %w", err)
+	}
+	return d, nil
+}
+
+// ListDocumentsByTrip returns a trip's documents in the order they were
+// added, those of its itinerary items included.
+func (s *Store) ListDocumentsByTrip(ctx context.Context, tripID string) ([]*models.Document, error) {
+	return s.listDocuments(ctx, `WHERE trip_id = ?`, tripID)
+}
+
+// ListDocumentsByItem returns the documents of an itinerary item in the
+// order they were added.
+func (s *Store) ListDocumentsByItem(ctx context.Context, itemID string) ([]*models.Document, error) {
+	return s.listDocuments(ctx, `WHERE item_id = ?`, itemID)
+}
+
+// ListDocumentsByExpense returns the receipts of an expense in the order
+// they were added.
+func (s *Store) ListDocumentsByExpense(ctx context.Context, expenseID string) ([]*models.Document, error) {
+	return s.listDocuments(ctx, `WHERE expense_id = ?`, expenseID)
+}
+
+func (s *Store) listDocuments(ctx context.Context, where string, arg string) ([]*models.Document, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+documentColumns+` FROM documents `+where+` ORDER BY created_at`, arg)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list documents: %w", err)
+	}
+	defer rows.Close()
+
+	var documents []*models.Document
+	for rows.Next() {
+		d, err := scanDocument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list documents: %w", err)
+		}
+		documents = append(documents, d)
+	}
+	return documents, rows.Err()
+}
+
+// DeleteDocument removes a document and its copy or link in the data
+// directory. Linked originals are left alone.
+func (s *Store) DeleteDocument(ctx context.Context, id string) error {
+	d, err := s.GetDocument(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.exec(ctx, `DELETE FROM documents WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("storage: delete document: %w", err)
+	}
+	if err := os.Remove(s.DocumentPath(d)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: delete document: %w", err)
+	}
+	// Drop the trip's directory once it is empty; failure just means it is not.
+	os.Remove(filepath.Dir(s.DocumentPath(d)))
+	return nil
+}
+
+// TrashDocument moves a document's file into the trash, so deleting the
+// document afterwards leaves the file for RestoreDocument.
+func (s *Store) TrashDocument(d *models.Document) error {
+	dst := s.trashedDocumentPath(d)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return fmt.Errorf("storage: trash document: %w", err)
+	}
+	if err := os.Rename(s.DocumentPath(d), dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: trash document: %w", err)
+	}
+	return nil
+}
+
+// RestoreDocument brings back a deleted document: its file from the trash
+// and its row. The trip must exist; the document loses its itinerary item
+// or expense if that was deleted since.
+func (s *Store) RestoreDocument(ctx context.Context, d *models.Document) error {
+	if d.ItemID != "" {
+		if _, err := s.GetItineraryItem(ctx, d.ItemID); errors.Is(err, ErrNotFound) {
+			d.ItemID = ""
+		} else if err != nil {
+			return err
+		}
+	}
+	if d.ExpenseID != "" {
+		if _, err := s.GetExpense(ctx, d.ExpenseID); errors.Is(err, ErrNotFound) {
+			d.ExpenseID = ""
+		} else if err != nil {
+			return err
+		}
+	}
+	dst := s.DocumentPath(d)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return fmt.Errorf("storage: restore document: %w", err)
+	}
+	if err := os.Rename(s.trashedDocumentPath(d), dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: restore document: %w", err)
+	}
+	if err := s.insertDocument(ctx, d, true); err != nil {
+		return fmt.Errorf("storage: restore document: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) trashedDocumentPath(d *models.Document) string {
+	return filepath.Join(s.dir, TrashDir, DocumentsDir, d.Path)
+}
+
+// removeDocumentFiles deletes the files of a trip's documents whose rows
+// are already gone.
+func (s *Store) removeDocumentFiles(tripID string) error {
+	if err := os.RemoveAll(filepath.Join(s.dir, DocumentsDir, tripID)); err != nil {
+		return fmt.Errorf("storage: delete documents: %w", err)
+	}
+	return nil
+}
+
+func scanDocument(sc scanner) (*models.Document, error) {
+	var (
+		d         models.Document
+		itemID    sql.NullString
+		expenseID sql.NullString
+		created   string
+	)
+	if err := sc.Scan(&d.ID, &d.TripID, &itemID, &expenseID, &d.Title, &d.Name, &d.ContentType, &d.Path, &d.Linked, &d.Size,
+		&d.Note, &created); err != nil {
+		return nil, err
+	}
+	d.ItemID, d.ExpenseID = itemID.String, expenseID.String
+	var err error
+	if d.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
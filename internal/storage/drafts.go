@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// DraftsDir is the directory inside the data directory holding drafts of
+// unsaved work, one file per draft. Drafts of an encrypted store are
+// encrypted with it. Saving or deleting a draft is not a change to the
+// data: it is not counted by Changes, synced or backed up.
+const DraftsDir = "drafts"
+
+const draftExt = ".json"
+
+// SaveDraft saves d, replacing the draft with the same ID.
+func (s *Store) SaveDraft(d *models.Draft) error {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return fmt.Errorf("storage: save draft: %w", err)
+	}
+	if s.sealer != nil {
+		if data, err = s.sealer.seal(data); err != nil {
+			return fmt.Errorf("storage: save draft: %w", err)
+		}
+	}
+	path, err := s.draftPath(d.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("storage: save draft: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("storage: save draft: %w", err)
+	}
+	return nil
+}
+
+// ListDrafts returns the saved drafts, the most recent first. Drafts that
+// cannot be read, such as those encrypted before the passphrase changed,
+// are left out.
+func (s *Store) ListDrafts() ([]*models.Draft, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, DraftsDir))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: list drafts: %w", err)
+	}
+	var drafts []*models.Draft
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), draftExt) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, DraftsDir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("storage: list drafts: %w", err)
+		}
+		if s.sealer != nil {
+			if data, err = s.sealer.open(data); err != nil {
+				continue
+			}
+		}
+		var d models.Draft
+		if err := json.Unmarshal(data, &d); err != nil {
+			continue
+		}
+		drafts = append(drafts, &d)
+	}
+	slices.SortFunc(drafts, func(a, b *models.Draft) int { return b.SavedAt.Compare(a.SavedAt) })
+	return drafts, nil
+}
+
+// DeleteDraft removes a draft. Deleting a draft that does not exist is not
+// an error.
+func (s *Store) DeleteDraft(id string) error {
+	path, err := s.draftPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: delete draft: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) draftPath(id string) (string, error) {
+	if id == "" || !filepath.IsLocal(id) || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("storage: invalid draft ID %q", id)
+	}
+	return filepath.Join(s.dir, DraftsDir, id+draftExt), nil
+}
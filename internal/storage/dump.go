@@ -0,0 +1,674 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// DumpFormat is the version of the layout of a Dump, raised when a change
+// to it would mislead an older version of nomadic importing it.
+const DumpFormat = 1
+
+// importBackupReason names the backup taken before importing a dump.
+const importBackupReason = "pre-import"
+
+// Dump is everything in a store but what is in the trash: every trip with
+// what is recorded on it, what is kept across trips and the files of
+// attachments and documents. It is what `nomadic export --all` writes and
+// `nomadic import` reads, to move a journal between devices.
+type Dump struct {
+	Format int `json:"format"`
+	// Schema is the schema version of the store dumped.
+	Schema     int           `json:"schema"`
+	ExportedAt time.Time     `json:"exported_at"`
+	Trips      []*TripRecord `json:"trips,omitempty"`
+
+	Categories   []*models.Category    `json:"categories,omitempty"`
+	People       []*models.Person      `json:"people,omitempty"`
+	Templates    []*models.Template    `json:"templates,omitempty"`
+	PackingLists []*models.PackingList `json:"packing_lists,omitempty"`
+	// PrepTemplates are the tasks to prepare each type of trip.
+	PrepTemplates []*models.PrepTemplate `json:"prep_templates,omitempty"`
+	Rules         []*models.Rule         `json:"rules,omitempty"`
+	// Recurrences are the recurring expenses on no trip; those on a trip
+	// are in its record.
+	Recurrences  []*models.Recurrence  `json:"recurrences,omitempty"`
+	CountryNotes []*models.CountryNote `json:"country_notes,omitempty"`
+	// Connectivity are the notes on getting online in each country.
+	Connectivity []*models.Connectivity `json:"connectivity,omitempty"`
+	Wishes       []*models.Wish         `json:"wishes,omitempty"`
+	// Achievements are when each achievement was unlocked, by ID.
+	Achievements map[string]time.Time `json:"achievements,omitempty"`
+
+	// Files are the contents of the files of attachments and documents by
+	// their slash-separated path in the data directory, such as
+	// "attachments/<entry>/<attachment>.jpg". Those missing on disk are
+	// left out.
+	Files map[string][]byte `json:"files,omitempty"`
+}
+
+// Dump reads everything in the store.
+func (s *Store) Dump(ctx context.Context) (*Dump, error) {
+	return s.dump(ctx, true)
+}
+
+// DumpRecords reads everything in the store but the files, for what keeps
+// them another way, such as sync in git.
+func (s *Store) DumpRecords(ctx context.Context) (*Dump, error) {
+	return s.dump(ctx, false)
+}
+
+func (s *Store) dump(ctx context.Context, files bool) (*Dump, error) {
+	version, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("storage: dump: %w", err)
+	}
+	d := &Dump{Format: DumpFormat, Schema: version, ExportedAt: time.Now(), Files: map[string][]byte{}}
+	trips, err := s.ListTrips(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range trips {
+		rec, err := s.tripRecord(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		d.Trips = append(d.Trips, rec)
+		if !files {
+			continue
+		}
+		for _, a := range rec.Attachments {
+			if err := d.addFile(AttachmentsDir, a.Path, s.AttachmentPath(a)); err != nil {
+				return nil, err
+			}
+		}
+		for _, doc := range rec.Documents {
+			if err := d.addFile(DocumentsDir, doc.Path, s.DocumentPath(doc)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if d.Categories, err = s.ListCategories(ctx); err != nil {
+		return nil, err
+	}
+	if d.People, err = s.ListPeople(ctx); err != nil {
+		return nil, err
+	}
+	if d.Templates, err = s.ListTemplates(ctx); err != nil {
+		return nil, err
+	}
+	if d.PackingLists, err = s.ListPackingLists(ctx); err != nil {
+		return nil, err
+	}
+	if d.PrepTemplates, err = s.ListPrepTemplates(ctx); err != nil {
+		return nil, err
+	}
+	if d.Rules, err = s.ListRules(ctx); err != nil {
+		return nil, err
+	}
+	recurrences, err := s.ListRecurrences(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range recurrences {
+		if r.TripID == "" {
+			d.Recurrences = append(d.Recurrences, r)
+		}
+	}
+	if d.CountryNotes, err = s.ListCountryNotes(ctx); err != nil {
+		return nil, err
+	}
+	if d.Connectivity, err = s.ListConnectivity(ctx); err != nil {
+		return nil, err
+	}
+	if d.Wishes, err = s.ListWishes(ctx); err != nil {
+		return nil, err
+	}
+	if d.Achievements, err = s.ListUnlockedAchievements(ctx); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// addFile reads the file at src, kept at rel in dir of the data directory,
+// into the dump, skipping one that is missing.
+func (d *Dump) addFile(dir, rel, src string) error {
+	data, err := os.ReadFile(src)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("storage: dump: %w", err)
+	}
+	d.Files[path.Join(dir, filepath.ToSlash(rel))] = data
+	return nil
+}
+
+// dumpTables are the tables of the records of a Dump by the JSON name of
+// their list, those of a TripRecord and the rest alike.
+var dumpTables = map[string]string{
+	"trips": "trips", "legs": "legs", "entries": "entries", "attachments": "attachments", "expenses": "expenses",
+	"itinerary": "itinerary_items", "tracks": "tracks", "packing": "packing_items", "checkins": "checkins",
+	"segments": "segments", "recurrences": "recurrences", "revisions": "revisions", "documents": "documents",
+	"highlights": "highlights", "health": "health_days", "lodgings": "lodgings", "prep": "prep_tasks",
+	"categories": "categories", "people": "people", "templates": "templates", "packing_lists": "packing_lists",
+	"prep_templates": "prep_templates", "rules": "rules", "country_notes": "country_notes", "wishes": "wishes",
+	"achievements": "achievements", "connectivity": "connectivity",
+}
+
+// DeleteDumped deletes the record of a Dump listed under kind, the JSON
+// name of its list such as "entries", whose key is key: its ID, or for a
+// country note its country and for a health day its trip ID and day
+// separated by a space. Its files stay on disk, and what is recorded on it
+// goes with it. A record already gone is no error.
+func (s *Store) DeleteDumped(ctx context.Context, kind, key string) error {
+	table, ok := dumpTables[kind]
+	if !ok {
+		return fmt.Errorf("storage: delete: no records of kind %q", kind)
+	}
+	where, args := `id = ?`, []any{key}
+	switch kind {
+	case "country_notes":
+		where = `country = ?`
+	case "health":
+		trip, day, _ := strings.Cut(key, " ")
+		where, args = `trip_id = ? AND day = ?`, []any{trip, day}
+	}
+	if _, err := s.exec(ctx, `DELETE FROM `+table+` WHERE `+where, args...); err != nil {
+		return fmt.Errorf("storage: delete %s: %w", kind, err)
+	}
+	return nil
+}
+
+// ImportReport counts the records of a dump by kind, such as "entry":
+// those added, those that replaced the store's own and those the store
+// kept as it had them.
+type ImportReport struct {
+	Added, Replaced, Kept map[string]int
+}
+
+func newImportReport() *ImportReport {
+	return &ImportReport{Added: map[string]int{}, Replaced: map[string]int{}, Kept: map[string]int{}}
+}
+
+// ImportOptions sets how a dump is imported.
+type ImportOptions struct {
+	// Overwrite has the records of the dump replace those of the store
+	// with the same ID, or the same name where names are unique. Without
+	// it the store keeps its own.
+	Overwrite bool
+	// DryRun counts what would be imported without writing anything.
+	DryRun bool
+}
+
+// Import merges a dump into the store: what the store lacks is added, and
+// what it has already is kept or, with opts.Overwrite, replaced. Nothing
+// in the store is deleted, and without opts.Overwrite importing the same
+// dump again adds nothing. Records are matched by ID, and by name for
+// categories, people, templates, packing lists, rules and country notes,
+// whose names are unique, and prep templates by their type. Unless
+// opts.DryRun is set, the database is backed up into BackupsDir first; the
+// path of the backup is returned.
+func (s *Store) Import(ctx context.Context, d *Dump, opts ImportOptions) (*ImportReport, string, error) {
+	if d.Format > DumpFormat {
+		return nil, "", fmt.Errorf("storage: import: the dump is of format %d from a newer version of nomadic, which reads up to %d",
+			d.Format, DumpFormat)
+	}
+	backup := ""
+	if !opts.DryRun {
+		version, err := s.SchemaVersion(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("storage: import: %w", err)
+		}
+		db, err := s.snapshot(ctx)
+		if err == nil {
+			backup, err = autoBackup(s.dir, importBackupReason, db, version, true)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("storage: back up before importing: %w", err)
+		}
+	}
+	im := &importer{s: s, opts: opts, files: d.Files, report: newImportReport()}
+	if err := im.dump(ctx, d); err != nil {
+		return im.report, backup, err
+	}
+	return im.report, backup, nil
+}
+
+// importer adds the records of a dump to a store, counting them.
+type importer struct {
+	s      *Store
+	opts   ImportOptions
+	files  map[string][]byte
+	report *ImportReport
+}
+
+// put saves a record of kind with save, unless the store has it already,
+// as exists tells, and is to keep its own.
+func (im *importer) put(kind string, exists bool, save func() error) error {
+	switch {
+	case exists && !im.opts.Overwrite:
+		im.report.Kept[kind]++
+		return nil
+	case exists:
+		im.report.Replaced[kind]++
+	default:
+		im.report.Added[kind]++
+	}
+	if im.opts.DryRun {
+		return nil
+	}
+	if err := save(); err != nil {
+		return fmt.Errorf("storage: import %s: %w", kind, err)
+	}
+	return nil
+}
+
+// add saves a record of kind that does not change once made, such as an
+// attachment, unless the store has it already.
+func (im *importer) add(kind string, exists bool, save func() error) error {
+	if exists {
+		im.report.Kept[kind]++
+		return nil
+	}
+	return im.put(kind, false, save)
+}
+
+// lookup finds the key of the row query selects, or "" when there is none.
+func (im *importer) lookup(ctx context.Context, query string, args ...any) (string, error) {
+	var key string
+	err := im.s.db.QueryRowContext(ctx, query, args...).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("storage: import: %w", err)
+	}
+	return key, nil
+}
+
+// has reports whether table has a row with the ID id.
+func (im *importer) has(ctx context.Context, table, id string) (bool, error) {
+	key, err := im.lookup(ctx, `SELECT id FROM `+table+` WHERE id = ?`, id)
+	return key != "", err
+}
+
+// named finds the ID of the row of table with the ID id or, failing that,
+// called name in column, for records whose names are unique.
+func (im *importer) named(ctx context.Context, table, column, id, name string) (string, error) {
+	return im.lookup(ctx, `SELECT id FROM `+table+` WHERE id = ? OR `+column+` = ? ORDER BY id = ? DESC LIMIT 1`, id, name, id)
+}
+
+// dump imports d, trips first and what refers to them after.
+func (im *importer) dump(ctx context.Context, d *Dump) error {
+	s := im.s
+	for _, c := range d.Categories {
+		existing, err := im.named(ctx, "categories", "name", c.ID, strings.ToLower(strings.TrimSpace(c.Name)))
+		if err != nil {
+			return err
+		}
+		if err := im.put("category", existing != "", func() error {
+			if existing != "" {
+				c.ID = existing
+			}
+			return s.SaveCategory(ctx, c)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, p := range d.People {
+		existing, err := im.lookup(ctx, `SELECT id FROM people WHERE id = ? OR name = ? COLLATE NOCASE ORDER BY id = ? DESC LIMIT 1`,
+			p.ID, strings.TrimSpace(p.Name), p.ID)
+		if err != nil {
+			return err
+		}
+		if err := im.put("person", existing != "", func() error {
+			if existing != "" {
+				p.ID = existing
+			}
+			return s.SavePerson(ctx, p)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, rec := range d.Trips {
+		if err := im.trip(ctx, rec); err != nil {
+			return err
+		}
+	}
+	for _, t := range d.Templates {
+		existing, err := im.lookup(ctx, `SELECT id FROM templates WHERE id = ? OR name = ? COLLATE NOCASE ORDER BY id = ? DESC LIMIT 1`,
+			t.ID, t.Name, t.ID)
+		if err != nil {
+			return err
+		}
+		if err := im.put("template", existing != "", func() error {
+			if existing != "" {
+				t.ID = existing
+			}
+			return s.SaveTemplate(ctx, t)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, l := range d.PackingLists {
+		existing, err := im.lookup(ctx, `SELECT id FROM packing_lists WHERE id = ? OR name = ? COLLATE NOCASE ORDER BY id = ? DESC LIMIT 1`,
+			l.ID, l.Name, l.ID)
+		if err != nil {
+			return err
+		}
+		if err := im.put("packing list", existing != "", func() error {
+			if existing != "" {
+				l.ID = existing
+			}
+			return s.SavePackingList(ctx, l)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, t := range d.PrepTemplates {
+		existing, err := im.lookup(ctx, `SELECT id FROM prep_templates WHERE id = ? OR type = ? COLLATE NOCASE ORDER BY id = ? DESC LIMIT 1`,
+			t.ID, strings.TrimSpace(t.Type), t.ID)
+		if err != nil {
+			return err
+		}
+		if err := im.put("prep template", existing != "", func() error {
+			if existing != "" {
+				t.ID = existing
+			}
+			return s.SavePrepTemplate(ctx, t)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, r := range d.Rules {
+		existing, err := im.named(ctx, "rules", "match", r.ID, strings.ToLower(strings.TrimSpace(r.Match)))
+		if err != nil {
+			return err
+		}
+		if err := im.put("rule", existing != "", func() error {
+			if existing != "" {
+				r.ID = existing
+			}
+			return s.SaveRule(ctx, r)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, r := range d.Recurrences {
+		if err := im.recurrence(ctx, r); err != nil {
+			return err
+		}
+	}
+	for _, n := range d.CountryNotes {
+		existing, err := im.lookup(ctx, `SELECT country FROM country_notes WHERE country = ?`, strings.ToUpper(n.Country))
+		if err != nil {
+			return err
+		}
+		if err := im.put("country note", existing != "", func() error { return s.SaveCountryNote(ctx, n) }); err != nil {
+			return err
+		}
+	}
+	for _, c := range d.Connectivity {
+		exists, err := im.has(ctx, "connectivity", c.ID)
+		if err != nil {
+			return err
+		}
+		if err := im.put("connectivity note", exists, func() error {
+			// The trip or leg it was noted on may not be in the store.
+			if c.TripID != "" {
+				if ok, err := im.has(ctx, "trips", c.TripID); err != nil {
+					return err
+				} else if !ok {
+					c.TripID = ""
+				}
+			}
+			if c.LegID != "" {
+				if ok, err := im.has(ctx, "legs", c.LegID); err != nil {
+					return err
+				} else if !ok {
+					c.LegID = ""
+				}
+			}
+			return s.SaveConnectivity(ctx, c)
+		}); err != nil {
+			return err
+		}
+	}
+	for _, w := range d.Wishes {
+		exists, err := im.has(ctx, "wishes", w.ID)
+		if err != nil {
+			return err
+		}
+		if err := im.put("wish", exists, func() error {
+			if w.TripID != "" {
+				if ok, err := im.has(ctx, "trips", w.TripID); err != nil {
+					return err
+				} else if !ok {
+					w.TripID = ""
+				}
+			}
+			return s.SaveWish(ctx, w)
+		}); err != nil {
+			return err
+		}
+	}
+	for id, at := range d.Achievements {
+		exists, err := im.has(ctx, "achievements", id)
+		if err != nil {
+			return err
+		}
+		if err := im.add("achievement", exists, func() error { return s.UnlockAchievement(ctx, id, at) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trip imports a trip with everything recorded on it. What refers to
+// other records, such as an expense to its leg, is imported after them.
+func (im *importer) trip(ctx context.Context, rec *TripRecord) error {
+	s := im.s
+	if rec.Trip == nil {
+		return nil
+	}
+	exists, err := im.has(ctx, "trips", rec.Trip.ID)
+	if err != nil {
+		return err
+	}
+	if err := im.put("trip", exists, func() error { return s.SaveTrip(ctx, rec.Trip) }); err != nil {
+		return err
+	}
+	if im.opts.DryRun && !exists {
+		// Nothing of a trip not in the store can be there.
+		im.countNew(rec)
+		return nil
+	}
+	for _, l := range rec.Legs {
+		if err := im.row(ctx, "leg", "legs", l.ID, func() error { return s.SaveLeg(ctx, l) }); err != nil {
+			return err
+		}
+	}
+	for _, e := range rec.Entries {
+		if err := im.row(ctx, "entry", "entries", e.ID, func() error { return s.SaveEntry(ctx, e) }); err != nil {
+			return err
+		}
+	}
+	for _, a := range rec.Attachments {
+		exists, err := im.has(ctx, "attachments", a.ID)
+		if err != nil {
+			return err
+		}
+		if err := im.add("attachment", exists, func() error { return im.attachment(ctx, a) }); err != nil {
+			return err
+		}
+	}
+	for _, r := range rec.Revisions {
+		exists, err := im.has(ctx, "revisions", r.ID)
+		if err != nil {
+			return err
+		}
+		if err := im.add("revision", exists, func() error { return s.saveRevisions(ctx, []*models.Revision{r}) }); err != nil {
+			return err
+		}
+	}
+	for _, r := range rec.Recurrences {
+		if err := im.recurrence(ctx, r); err != nil {
+			return err
+		}
+	}
+	for _, x := range rec.Expenses {
+		if err := im.row(ctx, "expense", "expenses", x.ID, func() error { return s.SaveExpense(ctx, x) }); err != nil {
+			return err
+		}
+	}
+	for _, it := range rec.Itinerary {
+		if err := im.row(ctx, "itinerary item", "itinerary_items", it.ID, func() error { return s.SaveItineraryItem(ctx, it) }); err != nil {
+			return err
+		}
+	}
+	for _, t := range rec.Tracks {
+		if err := im.row(ctx, "track", "tracks", t.ID, func() error { return s.SaveTrack(ctx, t) }); err != nil {
+			return err
+		}
+	}
+	for _, it := range rec.Packing {
+		if err := im.row(ctx, "packing item", "packing_items", it.ID, func() error { return s.SavePackingItem(ctx, it) }); err != nil {
+			return err
+		}
+	}
+	for _, c := range rec.CheckIns {
+		if err := im.row(ctx, "check-in", "checkins", c.ID, func() error { return s.SaveCheckIn(ctx, c) }); err != nil {
+			return err
+		}
+	}
+	for _, seg := range rec.Segments {
+		if err := im.row(ctx, "segment", "segments", seg.ID, func() error { return s.SaveSegment(ctx, seg) }); err != nil {
+			return err
+		}
+	}
+	for _, doc := range rec.Documents {
+		exists, err := im.has(ctx, "documents", doc.ID)
+		if err != nil {
+			return err
+		}
+		if err := im.add("document", exists, func() error { return im.document(ctx, doc) }); err != nil {
+			return err
+		}
+	}
+	for _, h := range rec.Highlights {
+		if err := im.row(ctx, "highlight", "highlights", h.ID, func() error { return s.SaveHighlight(ctx, h) }); err != nil {
+			return err
+		}
+	}
+	for _, h := range rec.Health {
+		existing, err := im.lookup(ctx, `SELECT day FROM health_days WHERE trip_id = ? AND day = ?`, h.TripID, h.Day)
+		if err != nil {
+			return err
+		}
+		if err := im.put("health day", existing != "", func() error { return s.SaveHealthDay(ctx, h) }); err != nil {
+			return err
+		}
+	}
+	for _, l := range rec.Lodgings {
+		if err := im.row(ctx, "lodging", "lodgings", l.ID, func() error { return s.restoreLodging(ctx, l) }); err != nil {
+			return err
+		}
+	}
+	for _, p := range rec.Prep {
+		if err := im.row(ctx, "prep task", "prep_tasks", p.ID, func() error { return s.SavePrepTask(ctx, p) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// row imports a record of kind kept in table by its ID.
+func (im *importer) row(ctx context.Context, kind, table, id string, save func() error) error {
+	exists, err := im.has(ctx, table, id)
+	if err != nil {
+		return err
+	}
+	return im.put(kind, exists, save)
+}
+
+// countNew counts everything recorded on a trip that is not in the store
+// as added.
+func (im *importer) countNew(rec *TripRecord) {
+	for kind, n := range map[string]int{
+		"leg": len(rec.Legs), "entry": len(rec.Entries), "attachment": len(rec.Attachments), "revision": len(rec.Revisions),
+		"recurrence": len(rec.Recurrences), "expense": len(rec.Expenses), "itinerary item": len(rec.Itinerary),
+		"track": len(rec.Tracks), "packing item": len(rec.Packing), "check-in": len(rec.CheckIns),
+		"segment": len(rec.Segments), "document": len(rec.Documents), "highlight": len(rec.Highlights),
+		"health day": len(rec.Health), "lodging": len(rec.Lodgings), "prep task": len(rec.Prep),
+	} {
+		if n > 0 {
+			im.report.Added[kind] += n
+		}
+	}
+}
+
+func (im *importer) recurrence(ctx context.Context, r *models.Recurrence) error {
+	return im.row(ctx, "recurrence", "recurrences", r.ID, func() error { return im.s.SaveRecurrence(ctx, r) })
+}
+
+// attachment writes the file of an attachment from the dump and adds its
+// row. A file the dump holds is written as a copy, even of one that was
+// linked, whose original is on the other device.
+func (im *importer) attachment(ctx context.Context, a *models.Attachment) error {
+	written, err := im.writeFile(AttachmentsDir, a.Path, im.s.AttachmentPath(a))
+	if err != nil {
+		return err
+	}
+	if written {
+		a.Linked = false
+	}
+	_, err = im.s.exec(ctx, `INSERT INTO attachments (`+attachmentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO NOTHING`,
+		a.ID, a.EntryID, a.Name, a.Path, a.Linked, a.Size, formatTime(a.CreatedAt))
+	return err
+}
+
+// document writes the file of a document from the dump and adds its row,
+// like attachment.
+func (im *importer) document(ctx context.Context, d *models.Document) error {
+	written, err := im.writeFile(DocumentsDir, d.Path, im.s.DocumentPath(d))
+	if err != nil {
+		return err
+	}
+	if written {
+		d.Linked = false
+	}
+	return im.s.RestoreDocument(ctx, d)
+}
+
+// writeFile writes the file at rel in dir from the dump to dst, unless the
+// dump lacks it or a file is there already, and reports whether it did.
+func (im *importer) writeFile(dir, rel, dst string) (bool, error) {
+	// The paths are the store's own, of the form <id>/<id>.<ext>; any
+	// other could write outside the data directory.
+	if !filepath.IsLocal(rel) {
+		return false, fmt.Errorf("%q is not a path in the data directory", rel)
+	}
+	data, ok := im.files[path.Join(dir, filepath.ToSlash(rel))]
+	if !ok {
+		return false, nil
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return false, err
+	}
+	return true, os.WriteFile(dst, data, 0o600)
+}
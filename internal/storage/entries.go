@@ -0,0 +1,412 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const entryColumns = `id, trip_id, leg_id, title, text, tags, timestamp, time_zone, location, weather, mood, people,
+	public, private, created_at, updated_at`
+
+// briefEntryColumns are entryColumns with the text left out, as for
+// EntryQuery.Brief.
+const briefEntryColumns = `id, trip_id, leg_id, title, '' AS text, tags, timestamp, time_zone, location, weather, mood,
+	people, public, private, created_at, updated_at`
+
+// SaveEntry inserts the entry, or updates it if an entry with the same ID
+// exists. An update changing what the entry says keeps the version it
+// replaces as a revision. A store keeping notes writes the entry's note.
+func (s *Store) SaveEntry(ctx context.Context, e *models.Entry) error {
+	if err := s.saveEntry(ctx, e); err != nil {
+		return err
+	}
+	return s.saveNote(ctx, e)
+}
+
+// saveEntry saves the entry in the database alone.
+func (s *Store) saveEntry(ctx context.Context, e *models.Entry) error {
+	if e.ID == "" {
+		e.ID = models.NewID()
+	}
+	now := time.Now()
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = now
+	}
+	e.UpdatedAt = now
+
+	e.Tags = models.NormalizeTags(e.Tags)
+	tags, err := marshalTags(e.Tags)
+	if err != nil {
+		return err
+	}
+	weather, err := marshalJSON(e.Weather, "")
+	if err != nil {
+		return err
+	}
+	people, err := marshalJSON(e.People, "[]")
+	if err != nil {
+		return err
+	}
+	legID := sql.NullString{String: e.LegID, Valid: e.LegID != ""}
+
+	created := s.isNew(ctx, "entries", e.ID)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: save entry: %w", err)
+	}
+	defer tx.Rollback()
+	if err := saveRevision(ctx, tx, e, tags); err != nil {
+		return fmt.Errorf("storage: save entry: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+INSERT INTO entries (`+entryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	leg_id = excluded.leg_id,
+	title = excluded.title,
+	text = excluded.text,
+	tags = excluded.tags,
+	timestamp = excluded.timestamp,
+	time_zone = excluded.time_zone,
+	location = excluded.location,
+	weather = excluded.weather,
+	mood = excluded.mood,
+	people = excluded.people,
+	public = excluded.public,
+	private = excluded.private,
+	updated_at = excluded.updated_at`,
+		e.ID, e.TripID, legID, e.Title, e.Text, tags, formatTime(e.Timestamp), e.TimeZone, e.Location, weather,
+		e.Mood, people, e.Public, e.Private, formatTime(e.CreatedAt), formatTime(e.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save entry: %w", err)
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: save entry: %w", err)
+	}
+	s.saved(ctx, Saved{Entry: e, Created: created})
+	return nil
+}
+
+// GetEntry returns the entry with the given ID.
+func (s *Store) GetEntry(ctx context.Context, id string) (*models.Entry, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+entryColumns+` FROM entries WHERE id = ?`, id)
+	e, err := scanEntry(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get entry: %w", err)
+	}
+	return e, nil
+}
+
+// ListEntriesByTrip returns a trip's entries in chronological order.
+func (s *Store) ListEntriesByTrip(ctx context.Context, tripID string) ([]*models.Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+entryColumns+` FROM entries WHERE trip_id = ? ORDER BY timestamp`, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list entries: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ListEntries returns the entries of every trip in chronological order.
+func (s *Store) ListEntries(ctx context.Context) ([]*models.Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+entryColumns+` FROM entries ORDER BY timestamp`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list entries: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ListRatedEntries returns the entries with a mood, across all trips, for
+// charting moods without loading the whole journal.
+func (s *Store) ListRatedEntries(ctx context.Context) ([]*models.Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+entryColumns+` FROM entries WHERE mood > 0 ORDER BY timestamp`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list rated entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list rated entries: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ListEntriesOnThisDay returns the entries written in earlier years on
+// the date of now, as models.Entry.YearsAgo recalls them, the most recent
+// first, without loading the whole journal.
+func (s *Store) ListEntriesOnThisDay(ctx context.Context, now time.Time) ([]*models.Entry, error) {
+	// Timestamps are stored in UTC, so the dates around the day are read
+	// and those written on it where they were written kept.
+	day := time.Date(2000, now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	dates := []time.Time{day}
+	if day.Month() == time.March && day.Day() == 1 {
+		dates = append(dates, day.AddDate(0, 0, -1))
+	}
+	var around []string
+	for _, d := range dates {
+		for i := -1; i <= 1; i++ {
+			around = append(around, d.AddDate(0, 0, i).Format("01-02"))
+		}
+	}
+	query := `SELECT ` + entryColumns + ` FROM entries WHERE substr(timestamp, 6, 5) IN (?` +
+		strings.Repeat(", ?", len(around)-1) + `) ORDER BY timestamp DESC`
+	args := make([]any, len(around))
+	for i, d := range around {
+		args[i] = d
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list entries on this day: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list entries on this day: %w", err)
+		}
+		if e.YearsAgo(now) > 0 {
+			entries = append(entries, e)
+		}
+	}
+	return entries, rows.Err()
+}
+
+// EntrySort is the order of a page of entries.
+type EntrySort int
+
+const (
+	// SortByDate orders entries chronologically.
+	SortByDate EntrySort = iota
+	// SortByTrip groups entries by trip, from the earliest trip, each in
+	// chronological order.
+	SortByTrip
+	// SortByTitle orders entries alphabetically by title, ignoring case.
+	SortByTitle
+)
+
+func (o EntrySort) String() string {
+	switch o {
+	case SortByTrip:
+		return "trip"
+	case SortByTitle:
+		return "title"
+	}
+	return "date"
+}
+
+// EntryQuery selects the entries for ListEntryPage and CountEntries.
+type EntryQuery struct {
+	// TripID narrows the entries to a trip's, or takes every trip's when
+	// empty.
+	TripID string
+	// Tags narrows the entries to those carrying every one of them.
+	Tags []string
+	// From and To, when set, narrow the entries to those written from From
+	// up to To.
+	From, To time.Time
+	Sort     EntrySort
+	// Brief leaves out the text of the entries, which is most of a long
+	// journal, for lists showing only their titles and dates. GetEntry
+	// reads an entry whole before it is shown or saved.
+	Brief bool
+}
+
+// where returns the WHERE clause selecting the query's entries and its
+// parameters.
+func (q EntryQuery) where() (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	if q.TripID != "" {
+		conds = append(conds, `trip_id = ?`)
+		args = append(args, q.TripID)
+	}
+	if tags := models.NormalizeTags(q.Tags); len(tags) > 0 {
+		conds = append(conds, tagFilter("entries.tags", len(tags)))
+		for _, t := range tags {
+			args = append(args, t)
+		}
+	}
+	if !q.From.IsZero() {
+		conds = append(conds, `timestamp >= ?`)
+		args = append(args, formatTime(q.From))
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, `timestamp < ?`)
+		args = append(args, formatTime(q.To))
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return ` WHERE ` + strings.Join(conds, " AND "), args
+}
+
+// orderBy returns the ORDER BY clause of the query. The ID breaks ties, so
+// pages neither repeat nor skip entries.
+func (q EntryQuery) orderBy() string {
+	switch q.Sort {
+	case SortByTrip:
+		return ` ORDER BY (SELECT start_date FROM trips WHERE trips.id = entries.trip_id), trip_id, timestamp, id`
+	case SortByTitle:
+		return ` ORDER BY title COLLATE NOCASE, timestamp, id`
+	}
+	return ` ORDER BY timestamp, id`
+}
+
+// columns returns the columns the query reads.
+func (q EntryQuery) columns() string {
+	if q.Brief {
+		return briefEntryColumns
+	}
+	return entryColumns
+}
+
+// ListEntryPage returns limit of the query's entries, skipping the first
+// offset of them, so long journals can be shown a page at a time. A
+// negative limit takes every entry from offset on.
+func (s *Store) ListEntryPage(ctx context.Context, q EntryQuery, offset, limit int) ([]*models.Entry, error) {
+	where, args := q.where()
+	rows, err := s.db.QueryContext(ctx, `SELECT `+q.columns()+` FROM entries`+where+q.orderBy()+` LIMIT ? OFFSET ?`,
+		append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list entries: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ListEntryTimes returns the entries the query selects with only their
+// IDs, trips and timestamps read, for measuring streaks without loading
+// the journal.
+func (s *Store) ListEntryTimes(ctx context.Context, q EntryQuery) ([]*models.Entry, error) {
+	where, args := q.where()
+	rows, err := s.db.QueryContext(ctx, `SELECT id, trip_id, timestamp FROM entries`+where+q.orderBy(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list entry times: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*models.Entry
+	for rows.Next() {
+		var (
+			e  models.Entry
+			ts string
+		)
+		if err := rows.Scan(&e.ID, &e.TripID, &ts); err != nil {
+			return nil, fmt.Errorf("storage: list entry times: %w", err)
+		}
+		if e.Timestamp, err = parseTime(ts); err != nil {
+			return nil, fmt.Errorf("storage: list entry times: %w", err)
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
+// CountEntries returns how many entries the query selects.
+func (s *Store) CountEntries(ctx context.Context, q EntryQuery) (int, error) {
+	where, args := q.where()
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM entries`+where, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("storage: count entries: %w", err)
+	}
+	return n, nil
+}
+
+// DeleteEntry removes a journal entry.
+// Its attachments are deleted with it.
+func (s *Store) DeleteEntry(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM entries WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete entry: %w", err)
+	}
+	if err := expectAffected(res); err != nil {
+		return err
+	}
+	if err := s.removeNotes(id); err != nil {
+		return err
+	}
+	return s.removeAttachmentFiles(id)
+}
+
+func scanEntry(sc scanner) (*models.Entry, error) {
+	var (
+		e                      models.Entry
+		tags, ts, created, upd string
+		weather, people        string
+		legID                  sql.NullString
+	)
+	if err := sc.Scan(&e.ID, &e.TripID, &legID, &e.Title, &e.Text, &tags, &ts, &e.TimeZone, &e.Location, &weather, &e.Mood, &people, &e.Public, &e.Private, &created, &upd); err != nil {
+		return nil, err
+	}
+	e.LegID = legID.String
+	if err := json.Unmarshal([]byte(tags), &e.Tags); err != nil {
+		return nil, err
+	}
+	if weather != "" {
+		if err := json.Unmarshal([]byte(weather), &e.Weather); err != nil {
+			return nil, err
+		}
+	}
+	if err := json.Unmarshal([]byte(people), &e.People); err != nil {
+		return nil, err
+	}
+	var err error
+	if e.Timestamp, err = parseTime(ts); err != nil {
+		return nil, err
+	}
+	e.Timestamp = models.InZone(e.Timestamp, e.TimeZone)
+	if e.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if e.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const expenseColumns = `id, trip_id, leg_id, amount, currency, category, description, note, tags, paid_by, shares, merchant,
+	recurrence_id, location, rate, rate_currency, timestamp, time_zone, created_at, updated_at, tax, tip`
+
+const insertExpense = `
+INSERT INTO expenses (` + expenseColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	leg_id = excluded.leg_id,
+	amount = excluded.amount,
+	currency = excluded.currency,
+	category = excluded.category,
+	description = excluded.description,
+	note = excluded.note,
+	tags = excluded.tags,
+	paid_by = excluded.paid_by,
+	shares = excluded.shares,
+	merchant = excluded.merchant,
+	recurrence_id = excluded.recurrence_id,
+	location = excluded.location,
+	rate = excluded.rate,
+	rate_currency = excluded.rate_currency,
+	timestamp = excluded.timestamp,
+	time_zone = excluded.time_zone,
+	updated_at = excluded.updated_at,
+	tax = excluded.tax,
+	tip = excluded.tip`
+
+// SaveExpense inserts the expense, or updates it if one with the same ID exists.
+func (s *Store) SaveExpense(ctx context.Context, x *models.Expense) error {
+	args, err := expenseArgs(x)
+	if err != nil {
+		return err
+	}
+	created := s.isNew(ctx, "expenses", x.ID)
+	if _, err := s.exec(ctx, insertExpense, args...); err != nil {
+		return fmt.Errorf("storage: save expense: %w", err)
+	}
+	s.saved(ctx, Saved{Expense: x, Created: created})
+	return nil
+}
+
+// expenseArgs stamps x before saving and returns the values of its
+// columns.
+func expenseArgs(x *models.Expense) ([]any, error) {
+	if x.ID == "" {
+		x.ID = models.NewID()
+	}
+	now := time.Now()
+	if x.CreatedAt.IsZero() {
+		x.CreatedAt = now
+	}
+	x.UpdatedAt = now
+
+	x.Tags = models.NormalizeTags(x.Tags)
+	tags, err := marshalTags(x.Tags)
+	if err != nil {
+		return nil, err
+	}
+	shares, err := marshalJSON(x.Shares, "[]")
+	if err != nil {
+		return nil, err
+	}
+	legID := sql.NullString{String: x.LegID, Valid: x.LegID != ""}
+	return []any{x.ID, x.TripID, legID, x.Amount, x.Currency, x.Category, x.Description, x.Note, tags, x.PaidBy, shares,
+		x.Merchant, x.RecurrenceID, x.Location, x.Rate, x.RateCurrency, formatTime(x.Timestamp), x.TimeZone, formatTime(x.CreatedAt), formatTime(x.UpdatedAt),
+		x.Tax, x.Tip}, nil
+}
+
+// GetExpense returns the expense with the given ID.
+func (s *Store) GetExpense(ctx context.Context, id string) (*models.Expense, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+expenseColumns+` FROM expenses WHERE id = ?`, id)
+	x, err := scanExpense(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get expense: %w", err)
+	}
+	return x, nil
+}
+
+// ListExpensesByTrip returns a trip's expenses in chronological order.
+func (s *Store) ListExpensesByTrip(ctx context.Context, tripID string) ([]*models.Expense, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+expenseColumns+` FROM expenses WHERE trip_id = ? ORDER BY timestamp`, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var expenses []*models.Expense
+	for rows.Next() {
+		x, err := scanExpense(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list expenses: %w", err)
+		}
+		expenses = append(expenses, x)
+	}
+	return expenses, rows.Err()
+}
+
+// ListExpenses returns the expenses of every trip in chronological order.
+func (s *Store) ListExpenses(ctx context.Context) ([]*models.Expense, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+expenseColumns+` FROM expenses ORDER BY timestamp`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var expenses []*models.Expense
+	for rows.Next() {
+		x, err := scanExpense(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list expenses: %w", err)
+		}
+		expenses = append(expenses, x)
+	}
+	return expenses, rows.Err()
+}
+
+// DeleteExpense removes an expense.
+func (s *Store) DeleteExpense(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM expenses WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete expense: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanExpense(sc scanner) (*models.Expense, error) {
+	var (
+		x                models.Expense
+		tags, shares     string
+		ts, created, upd string
+		legID            sql.NullString
+	)
+	if err := sc.Scan(&x.ID, &x.TripID, &legID, &x.Amount, &x.Currency, &x.Category, &x.Description, &x.Note, &tags,
+		&x.PaidBy, &shares, &x.Merchant, &x.RecurrenceID, &x.Location, &x.Rate, &x.RateCurrency, &ts, &x.TimeZone, &created, &upd,
+		&x.Tax, &x.Tip); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(shares), &x.Shares); err != nil {
+		return nil, err
+	}
+	x.LegID = legID.String
+	if err := json.Unmarshal([]byte(tags), &x.Tags); err != nil {
+		return nil, err
+	}
+	var err error
+	if x.Timestamp, err = parseTime(ts); err != nil {
+		return nil, err
+	}
+	x.Timestamp = models.InZone(x.Timestamp, x.TimeZone)
+	if x.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if x.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &x, nil
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const healthColumns = `trip_id, day, sleep, water, jetlag, note, updated_at`
+
+// SaveHealthDay records how a day of a trip went for the body, replacing
+// what was logged for the day before.
+func (s *Store) SaveHealthDay(ctx context.Context, h *models.HealthDay) error {
+	if _, err := time.Parse(models.DateLayout, h.Day); err != nil {
+		return fmt.Errorf("storage: save health: day %q is not YYYY-MM-DD", h.Day)
+	}
+	h.UpdatedAt = time.Now()
+	_, err := s.exec(ctx, `
+INSERT INTO health_days (`+healthColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(trip_id, day) DO UPDATE SET
+	sleep = excluded.sleep,
+	water = excluded.water,
+	jetlag = excluded.jetlag,
+	note = excluded.note,
+	updated_at = excluded.updated_at`,
+		h.TripID, h.Day, h.Sleep, h.Water, h.Jetlag, h.Note, formatTime(h.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save health: %w", err)
+	}
+	return nil
+}
+
+// GetHealthDay returns what was logged for the day of a trip, a day in
+// DateLayout.
+func (s *Store) GetHealthDay(ctx context.Context, tripID, day string) (*models.HealthDay, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+healthColumns+` FROM health_days WHERE trip_id = ? AND day = ?`, tripID, day)
+	h, err := scanHealthDay(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get health: %w", err)
+	}
+	return h, nil
+}
+
+// ListHealthByTrip returns what was logged for the days of a trip, in
+// order.
+func (s *Store) ListHealthByTrip(ctx context.Context, tripID string) ([]*models.HealthDay, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+healthColumns+` FROM health_days WHERE trip_id = ? ORDER BY day`, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list health: %w", err)
+	}
+	defer rows.Close()
+
+	var days []*models.HealthDay
+	for rows.Next() {
+		h, err := scanHealthDay(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list health: %w", err)
+		}
+		days = append(days, h)
+	}
+	return days, rows.Err()
+}
+
+// DeleteHealthDay forgets what was logged for the day of a trip.
+func (s *Store) DeleteHealthDay(ctx context.Context, tripID, day string) error {
+	res, err := s.exec(ctx, `DELETE FROM health_days WHERE trip_id = ? AND day = ?`, tripID, day)
+	if err != nil {
+		return fmt.Errorf("storage: delete health: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanHealthDay(sc scanner) (*models.HealthDay, error) {
+	var (
+		h       models.HealthDay
+		updated string
+	)
+	if err := sc.Scan(&h.TripID, &h.Day, &h.Sleep, &h.Water, &h.Jetlag, &h.Note, &updated); err != nil {
+		return nil, err
+	}
+	var err error
+	if h.UpdatedAt, err = parseTime(updated); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
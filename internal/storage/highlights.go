@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const highlightColumns = `id, trip_id, title, done, entry_id, position, created_at, updated_at`
+
+// SaveHighlight inserts the highlight, or updates it if one with the same
+// ID exists. A new highlight goes after the others of its trip.
+func (s *Store) SaveHighlight(ctx context.Context, h *models.Highlight) error {
+	if h.ID == "" {
+		h.ID = models.NewID()
+	}
+	now := time.Now()
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = now
+	}
+	h.UpdatedAt = now
+
+	entryID := sql.NullString{String: h.EntryID, Valid: h.EntryID != ""}
+	_, err := s.exec(ctx, `
+INSERT INTO highlights (`+highlightColumns+`)
+VALUES (?1, ?2, ?3, ?4, ?5, (SELECT coalesce(max(position) + 1, 0) FROM highlights WHERE trip_id = ?2), ?6, ?7)
+ON CONFLICT(id) DO UPDATE SET
+	title = excluded.title,
+	done = excluded.done,
+	entry_id = excluded.entry_id,
+	updated_at = excluded.updated_at`,
+		h.ID, h.TripID, h.Title, h.Done, entryID, formatTime(h.CreatedAt), formatTime(h.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save highlight: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, `SELECT position FROM highlights WHERE id = ?`, h.ID).Scan(&h.Position); err != nil {
+		return fmt.Errorf("storage: save highlight: %w", err)
+	}
+	return nil
+}
+
+// GetHighlight returns the highlight with the given ID.
+func (s *Store) GetHighlight(ctx context.Context, id string) (*models.Highlight, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+highlightColumns+` FROM highlights WHERE id = ?`, id)
+	h, err := scanHighlight(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get highlight: %w", err)
+	}
+	return h, nil
+}
+
+// ListHighlightsByTrip returns a trip's goals and highlights in order.
+func (s *Store) ListHighlightsByTrip(ctx context.Context, tripID string) ([]*models.Highlight, error) {
+	return s.listHighlights(ctx, `trip_id = ?`, tripID)
+}
+
+// ListHighlightsByEntry returns the highlights linked to a journal entry.
+func (s *Store) ListHighlightsByEntry(ctx context.Context, entryID string) ([]*models.Highlight, error) {
+	return s.listHighlights(ctx, `entry_id = ?`, entryID)
+}
+
+func (s *Store) listHighlights(ctx context.Context, where string, arg any) ([]*models.Highlight, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+highlightColumns+` FROM highlights WHERE `+where+`
+ORDER BY position, created_at`, arg)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list highlights: %w", err)
+	}
+	defer rows.Close()
+
+	var highlights []*models.Highlight
+	for rows.Next() {
+		h, err := scanHighlight(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list highlights: %w", err)
+		}
+		highlights = append(highlights, h)
+	}
+	return highlights, rows.Err()
+}
+
+// MoveHighlight moves h to position, counted from zero among its trip's
+// highlights in order, and closes up the others around it.
+func (s *Store) MoveHighlight(ctx context.Context, h *models.Highlight, position int) error {
+	highlights, err := s.ListHighlightsByTrip(ctx, h.TripID)
+	if err != nil {
+		return err
+	}
+	order := make([]*models.Highlight, 0, len(highlights))
+	for _, other := range highlights {
+		if other.ID != h.ID {
+			order = append(order, other)
+		}
+	}
+	position = max(0, min(position, len(order)))
+	order = append(order[:position], append([]*models.Highlight{h}, order[position:]...)...)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: move highlight: %w", err)
+	}
+	defer tx.Rollback()
+	for i, other := range order {
+		if _, err := tx.ExecContext(ctx, `UPDATE highlights SET position = ? WHERE id = ?`, i, other.ID); err != nil {
+			return fmt.Errorf("storage: move highlight: %w", err)
+		}
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: move highlight: %w", err)
+	}
+	h.Position = position
+	return nil
+}
+
+// DeleteHighlight removes a highlight.
+func (s *Store) DeleteHighlight(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM highlights WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete highlight: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanHighlight(sc scanner) (*models.Highlight, error) {
+	var (
+		h            models.Highlight
+		entryID      sql.NullString
+		created, upd string
+	)
+	if err := sc.Scan(&h.ID, &h.TripID, &h.Title, &h.Done, &entryID, &h.Position, &created, &upd); err != nil {
+		return nil, err
+	}
+	h.EntryID = entryID.String
+	var err error
+	if h.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if h.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const itineraryColumns = `id, trip_id, day, time, title, place, notes, booking_ref, alarm, duration, kind, started, finished, position, created_at, updated_at`
+
+// SaveItineraryItem inserts the item, or updates it if one with the same ID exists.
+func (s *Store) SaveItineraryItem(ctx context.Context, it *models.ItineraryItem) error {
+	if it.ID == "" {
+		it.ID = models.NewID()
+	}
+	now := time.Now()
+	if it.CreatedAt.IsZero() {
+		it.CreatedAt = now
+	}
+	it.UpdatedAt = now
+
+	_, err := s.exec(ctx, `
+INSERT INTO itinerary_items (`+itineraryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	day = excluded.day,
+	time = excluded.time,
+	title = excluded.title,
+	place = excluded.place,
+	notes = excluded.notes,
+	booking_ref = excluded.booking_ref,
+	alarm = excluded.alarm,
+	duration = excluded.duration,
+	kind = excluded.kind,
+	started = excluded.started,
+	finished = excluded.finished,
+	position = excluded.position,
+	updated_at = excluded.updated_at`,
+		it.ID, it.TripID, formatTime(it.Day), it.Time, it.Title, it.Place, it.Notes, it.BookingRef, it.Alarm,
+		it.Duration, it.Kind, it.Started, it.Finished, it.Position,
+		formatTime(it.CreatedAt), formatTime(it.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save itinerary item: %w", err)
+	}
+	return nil
+}
+
+// GetItineraryItem returns the itinerary item with the given ID.
+func (s *Store) GetItineraryItem(ctx context.Context, id string) (*models.ItineraryItem, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+itineraryColumns+` FROM itinerary_items WHERE id = ?`, id)
+	it, err := scanItineraryItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get itinerary item: %w", err)
+	}
+	return it, nil
+}
+
+// ListItineraryByTrip returns a trip's itinerary ordered by day, then by
+// position within the day.
+func (s *Store) ListItineraryByTrip(ctx context.Context, tripID string) ([]*models.ItineraryItem, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+itineraryColumns+` FROM itinerary_items WHERE trip_id = ?
+ORDER BY day, position, time, created_at`, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list itinerary: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.ItineraryItem
+	for rows.Next() {
+		it, err := scanItineraryItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list itinerary: %w", err)
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// ReorderItinerary sets the position of each item to its index in ids,
+// atomically.
+func (s *Store) ReorderItinerary(ctx context.Context, ids []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: reorder itinerary: %w", err)
+	}
+	defer tx.Rollback()
+	for i, id := range ids {
+		if _, err := tx.ExecContext(ctx, `UPDATE itinerary_items SET position = ? WHERE id = ?`, i, id); err != nil {
+			return fmt.Errorf("storage: reorder itinerary: %w", err)
+		}
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: reorder itinerary: %w", err)
+	}
+	return nil
+}
+
+// DeleteItineraryItem removes an itinerary item.
+func (s *Store) DeleteItineraryItem(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM itinerary_items WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete itinerary item: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanItineraryItem(sc scanner) (*models.ItineraryItem, error) {
+	var (
+		it                models.ItineraryItem
+		day, created, upd string
+	)
+	if err := sc.Scan(&it.ID, &it.TripID, &day, &it.Time, &it.Title, &it.Place, &it.Notes, &it.BookingRef,
+		&it.Alarm, &it.Duration, &it.Kind, &it.Started, &it.Finished, &it.Position, &created, &upd); err != nil {
+		return nil, err
+	}
+	var err error
+	if it.Day, err = parseTime(day); err != nil {
+		return nil, err
+	}
+	if it.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if it.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &it, nil
+}
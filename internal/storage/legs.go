@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const legColumns = `id, trip_id, location, arrival, departure, transport, created_at, updated_at`
+
+// SaveLeg inserts the leg, or updates it if one with the same ID exists.
+func (s *Store) SaveLeg(ctx context.Context, l *models.Leg) error {
+	if l.ID == "" {
+		l.ID = models.NewID()
+	}
+	now := time.Now()
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = now
+	}
+	l.UpdatedAt = now
+
+	_, err := s.exec(ctx, `
+INSERT INTO legs (`+legColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	location = excluded.location,
+	arrival = excluded.arrival,
+	departure = excluded.departure,
+	transport = excluded.transport,
+	updated_at = excluded.updated_at`,
+		l.ID, l.TripID, l.Location, formatTime(l.Arrival), formatNullTime(l.Departure), l.Transport,
+		formatTime(l.CreatedAt), formatTime(l.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save leg: %w", err)
+	}
+	return nil
+}
+
+// GetLeg returns the leg with the given ID.
+func (s *Store) GetLeg(ctx context.Context, id string) (*models.Leg, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+legColumns+` FROM legs WHERE id = ?`, id)
+	l, err := scanLeg(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get leg: %w", err)
+	}
+	return l, nil
+}
+
+// ListLegsByTrip returns a trip's legs in the order they are reached.
+func (s *Store) ListLegsByTrip(ctx context.Context, tripID string) ([]*models.Leg, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+legColumns+` FROM legs WHERE trip_id = ? ORDER BY arrival, created_at`, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list legs: %w", err)
+	}
+	defer rows.Close()
+
+	var legs []*models.Leg
+	for rows.Next() {
+		l, err := scanLeg(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list legs: %w", err)
+		}
+		legs = append(legs, l)
+	}
+	return legs, rows.Err()
+}
+
+// DeleteLeg removes a leg. Entries and expenses attributed to it are kept
+// and belong to no leg afterwards.
+func (s *Store) DeleteLeg(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM legs WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete leg: %w", err)
+	}
+	return expectAffected(res)
+}
+
+// AttributeToLegs attributes the trip's journal entries and expenses that
+// belong to no leg to the leg visited on their day, and returns how many
+// it attributed. Entries and expenses already attributed are left alone.
+func (s *Store) AttributeToLegs(ctx context.Context, tripID string) (int, error) {
+	legs, err := s.ListLegsByTrip(ctx, tripID)
+	if err != nil || len(legs) == 0 {
+		return 0, err
+	}
+	entries, err := s.ListEntriesByTrip(ctx, tripID)
+	if err != nil {
+		return 0, err
+	}
+	expenses, err := s.ListExpensesByTrip(ctx, tripID)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, e := range entries {
+		if l := models.LegOn(legs, e.Timestamp); e.LegID == "" && l != nil {
+			e.LegID = l.ID
+			if err := s.SaveEntry(ctx, e); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	for _, x := range expenses {
+		if l := models.LegOn(legs, x.Timestamp); x.LegID == "" && l != nil {
+			x.LegID = l.ID
+			if err := s.SaveExpense(ctx, x); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+func scanLeg(sc scanner) (*models.Leg, error) {
+	var (
+		l                     models.Leg
+		arrival, created, upd string
+		departure             sql.NullString
+	)
+	if err := sc.Scan(&l.ID, &l.TripID, &l.Location, &arrival, &departure, &l.Transport, &created, &upd); err != nil {
+		return nil, err
+	}
+	var err error
+	if l.Arrival, err = parseTime(arrival); err != nil {
+		return nil, err
+	}
+	if l.Departure, err = parseNullTime(departure); err != nil {
+		return nil, err
+	}
+	if l.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if l.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LockFileName is the file in the data directory that nomadic processes
+// lock while they use it, so the TUI, the daemon and commands run from the
+// shell can work on one data directory at once. A plain database is
+// locked shared, since SQLite serialises its writers itself; an encrypted
+// one, which lives in memory and is written back whole, and the changes
+// that replace the database, such as migrating, restoring and encrypting,
+// lock it exclusively.
+const LockFileName = ".nomadic.lock"
+
+// ErrInUse is returned when another nomadic holds the data directory in a
+// way that rules out using it too.
+var ErrInUse = errors.New("storage: the data directory is in use")
+
+// lockWait is how long taking the lock waits for another nomadic to let go
+// of it, long enough for a command run alongside to finish.
+const lockWait = 5 * time.Second
+
+// dirLock is a lock on a data directory, held shared or exclusively. Its
+// methods do nothing on a nil lock, that of a store held only in memory.
+type dirLock struct {
+	dir       string
+	f         *os.File
+	exclusive bool
+}
+
+// lockDir locks the data directory dir, waiting up to lockWait for
+// another nomadic to let go of it.
+func lockDir(dir string, exclusive bool) (*dirLock, error) {
+	f, err := os.OpenFile(filepath.Join(dir, LockFileName), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("storage: lock %s: %w", dir, err)
+	}
+	l := &dirLock{dir: dir, f: f}
+	if err := l.take(exclusive, nil); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// take locks l shared or exclusively, waiting up to lockWait for as long
+// as until, when set, reports that it is still worth waiting; when it no
+// longer is, take returns errGaveUp.
+func (l *dirLock) take(exclusive bool, until func() bool) error {
+	deadline := time.Now().Add(lockWait)
+	for {
+		ok, err := tryLock(l.f, exclusive)
+		if err != nil {
+			return fmt.Errorf("storage: lock %s: %w", l.dir, err)
+		}
+		if ok {
+			l.exclusive = exclusive
+			if exclusive {
+				l.sign()
+			}
+			return nil
+		}
+		if until != nil && !until() {
+			return errGaveUp
+		}
+		if time.Now().After(deadline) {
+			return l.inUse()
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// errGaveUp is what take returns when its until gave up waiting.
+var errGaveUp = errors.New("storage: gave up waiting for the lock")
+
+// sign writes who holds the lock exclusively into the lock file, for those
+// kept waiting to be told.
+func (l *dirLock) sign() {
+	args := []string{filepath.Base(os.Args[0])}
+	args = append(args, os.Args[1:min(len(os.Args), 3)]...)
+	if err := l.f.Truncate(0); err == nil {
+		l.f.WriteAt([]byte(fmt.Sprintf("pid %d: %s", os.Getpid(), strings.Join(args, " "))), 0)
+	}
+}
+
+// inUse is the error telling what holds the directory, as far as the lock
+// file tells.
+func (l *dirLock) inUse() error {
+	holder := ""
+	if data, err := os.ReadFile(l.f.Name()); err == nil && len(data) > 0 && len(data) < 200 {
+		holder = " (" + string(data) + ")"
+	}
+	return fmt.Errorf("%w: %s is open in another nomadic%s; close it or let it finish, and try again", ErrInUse, l.dir, holder)
+}
+
+// upgrade locks l exclusively, giving up with errGaveUp when until reports
+// it is no longer needed, in which case l is held shared again.
+func (l *dirLock) upgrade(until func() bool) error {
+	if l == nil || l.exclusive {
+		return nil
+	}
+	// Two processes upgrading at once would each wait for the other to let
+	// go of its shared lock, so the shared lock is dropped first.
+	if err := unlock(l.f); err != nil {
+		return err
+	}
+	err := l.take(true, until)
+	if err != nil {
+		if terr := l.take(false, nil); terr != nil {
+			return terr
+		}
+	}
+	return err
+}
+
+// downgrade holds an exclusive lock shared instead.
+func (l *dirLock) downgrade() error {
+	if l == nil || !l.exclusive {
+		return nil
+	}
+	l.f.Truncate(0)
+	if err := unlock(l.f); err != nil {
+		return err
+	}
+	return l.take(false, nil)
+}
+
+// release lets go of the lock.
+func (l *dirLock) release() error {
+	if l == nil {
+		return nil
+	}
+	if l.exclusive {
+		l.f.Truncate(0)
+	}
+	// Closing the file releases the lock.
+	return l.f.Close()
+}
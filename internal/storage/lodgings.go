@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const lodgingColumns = `id, trip_id, leg_id, name, address, confirmation, cost, currency, check_in, check_out, time_zone,
+	notes, expense_id, created_at, updated_at`
+
+const insertLodging = `
+INSERT INTO lodgings (` + lodgingColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	leg_id = excluded.leg_id,
+	name = excluded.name,
+	address = excluded.address,
+	confirmation = excluded.confirmation,
+	cost = excluded.cost,
+	currency = excluded.currency,
+	check_in = excluded.check_in,
+	check_out = excluded.check_out,
+	time_zone = excluded.time_zone,
+	notes = excluded.notes,
+	expense_id = excluded.expense_id,
+	updated_at = excluded.updated_at`
+
+// SaveLodging inserts the lodging, or updates it if one with the same ID
+// exists, together with the expense recording its cost: the expense is
+// recorded with the first cost, changed with the lodging from then on and
+// deleted once the lodging has no cost.
+func (s *Store) SaveLodging(ctx context.Context, l *models.Lodging) error {
+	var x *models.Expense
+	if l.ExpenseID != "" {
+		var err error
+		if x, err = s.GetExpense(ctx, l.ExpenseID); errors.Is(err, ErrNotFound) {
+			// Deleted on its own; a cost records a new one.
+			l.ExpenseID = ""
+		} else if err != nil {
+			return fmt.Errorf("storage: save lodging: %w", err)
+		}
+	}
+	created := x == nil
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: save lodging: %w", err)
+	}
+	defer tx.Rollback()
+	switch {
+	case l.Cost > 0:
+		x = l.CostExpense(x)
+		args, err := expenseArgs(x)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, insertExpense, args...); err != nil {
+			return fmt.Errorf("storage: save lodging: %w", err)
+		}
+		l.ExpenseID = x.ID
+	case x != nil:
+		if _, err := tx.ExecContext(ctx, `DELETE FROM expenses WHERE id = ?`, x.ID); err != nil {
+			return fmt.Errorf("storage: save lodging: %w", err)
+		}
+		l.ExpenseID, x = "", nil
+	}
+	if _, err := tx.ExecContext(ctx, insertLodging, lodgingArgs(l)...); err != nil {
+		return fmt.Errorf("storage: save lodging: %w", err)
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: save lodging: %w", err)
+	}
+	if x != nil {
+		s.saved(ctx, Saved{Expense: x, Created: created})
+	}
+	return nil
+}
+
+// GetLodging returns the lodging with the given ID.
+func (s *Store) GetLodging(ctx context.Context, id string) (*models.Lodging, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+lodgingColumns+` FROM lodgings WHERE id = ?`, id)
+	l, err := scanLodging(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get lodging: %w", err)
+	}
+	return l, nil
+}
+
+// ListLodgingsByTrip returns a trip's lodgings in the order they are
+// checked into.
+func (s *Store) ListLodgingsByTrip(ctx context.Context, tripID string) ([]*models.Lodging, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+lodgingColumns+` FROM lodgings WHERE trip_id = ? ORDER BY check_in, created_at`, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list lodgings: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*models.Lodging
+	for rows.Next() {
+		l, err := scanLodging(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list lodgings: %w", err)
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// DeleteLodging removes a lodging with the expense of its cost.
+func (s *Store) DeleteLodging(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: delete lodging: %w", err)
+	}
+	defer tx.Rollback()
+	var expenseID sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT expense_id FROM lodgings WHERE id = ?`, id).Scan(&expenseID); errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("storage: delete lodging: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM lodgings WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("storage: delete lodging: %w", err)
+	}
+	if expenseID.Valid {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM expenses WHERE id = ?`, expenseID.String); err != nil {
+			return fmt.Errorf("storage: delete lodging: %w", err)
+		}
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: delete lodging: %w", err)
+	}
+	return nil
+}
+
+// restoreLodging saves a lodging back as it was, its expense restored
+// already.
+func (s *Store) restoreLodging(ctx context.Context, l *models.Lodging) error {
+	if _, err := s.exec(ctx, insertLodging, lodgingArgs(l)...); err != nil {
+		return fmt.Errorf("storage: restore lodging: %w", err)
+	}
+	return nil
+}
+
+// lodgingArgs stamps l before saving and returns the values of its
+// columns.
+func lodgingArgs(l *models.Lodging) []any {
+	if l.ID == "" {
+		l.ID = models.NewID()
+	}
+	now := time.Now()
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = now
+	}
+	l.UpdatedAt = now
+	legID := sql.NullString{String: l.LegID, Valid: l.LegID != ""}
+	expenseID := sql.NullString{String: l.ExpenseID, Valid: l.ExpenseID != ""}
+	return []any{l.ID, l.TripID, legID, l.Name, l.Address, l.Confirmation, l.Cost, l.Currency, formatTime(l.CheckIn),
+		formatTime(l.CheckOut), l.TimeZone, l.Notes, expenseID, formatTime(l.CreatedAt), formatTime(l.UpdatedAt)}
+}
+
+func scanLodging(sc scanner) (*models.Lodging, error) {
+	var (
+		l                               models.Lodging
+		legID, expenseID                sql.NullString
+		checkIn, checkOut, created, upd string
+	)
+	if err := sc.Scan(&l.ID, &l.TripID, &legID, &l.Name, &l.Address, &l.Confirmation, &l.Cost, &l.Currency, &checkIn,
+		&checkOut, &l.TimeZone, &l.Notes, &expenseID, &created, &upd); err != nil {
+		return nil, err
+	}
+	l.LegID, l.ExpenseID = legID.String, expenseID.String
+	var err error
+	if l.CheckIn, err = parseTime(checkIn); err != nil {
+		return nil, err
+	}
+	if l.CheckOut, err = parseTime(checkOut); err != nil {
+		return nil, err
+	}
+	if l.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if l.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	l.CheckIn, l.CheckOut = models.InZone(l.CheckIn, l.TimeZone), models.InZone(l.CheckOut, l.TimeZone)
+	return &l, nil
+}
@@ -0,0 +1,814 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// migration is a single, append-only schema change. Never edit a migration
+// once it has shipped; add a new one instead.
+type migration struct {
+	version int
+	name    string
+	up      string
+}
+
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "initial schema",
+		up: `
+CREATE TABLE trips (
+	id         TEXT PRIMARY KEY,
+	title      TEXT NOT NULL,
+	locations  TEXT NOT NULL DEFAULT '[]',
+	start_date TEXT NOT NULL,
+	end_date   TEXT,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+CREATE TABLE entries (
+	id         TEXT PRIMARY KEY,
+	trip_id    TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	text       TEXT NOT NULL DEFAULT '',
+	timestamp  TEXT NOT NULL,
+	location   TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX entries_trip_id ON entries(trip_id, timestamp);
+
+CREATE TABLE expenses (
+	id          TEXT PRIMARY KEY,
+	trip_id     TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	amount      REAL NOT NULL,
+	currency    TEXT NOT NULL,
+	category    TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	timestamp   TEXT NOT NULL,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+CREATE INDEX expenses_trip_id ON expenses(trip_id, timestamp);
+`,
+	},
+	{
+		version: 2,
+		name:    "trip budget and notes",
+		up: `
+ALTER TABLE trips ADD COLUMN budget REAL NOT NULL DEFAULT 0;
+ALTER TABLE trips ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 3,
+		name:    "entry title and tags",
+		up: `
+ALTER TABLE entries ADD COLUMN title TEXT NOT NULL DEFAULT '';
+ALTER TABLE entries ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+`,
+	},
+	{
+		version: 4,
+		name:    "expense note",
+		up: `
+ALTER TABLE expenses ADD COLUMN note TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 5,
+		name:    "itinerary",
+		up: `
+CREATE TABLE itinerary_items (
+	id          TEXT PRIMARY KEY,
+	trip_id     TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	day         TEXT NOT NULL,
+	time        TEXT NOT NULL DEFAULT '',
+	title       TEXT NOT NULL,
+	place       TEXT NOT NULL DEFAULT '',
+	notes       TEXT NOT NULL DEFAULT '',
+	booking_ref TEXT NOT NULL DEFAULT '',
+	position    INTEGER NOT NULL DEFAULT 0,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+CREATE INDEX itinerary_items_trip_id ON itinerary_items(trip_id, day, position);
+`,
+	},
+	{
+		version: 6,
+		name:    "entry full-text search",
+		up: `
+CREATE VIRTUAL TABLE entries_fts USING fts5(
+	title, text, tags,
+	content = 'entries',
+	content_rowid = 'rowid',
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+INSERT INTO entries_fts(entries_fts) VALUES ('rebuild');
+
+CREATE TRIGGER entries_fts_insert AFTER INSERT ON entries BEGIN
+	INSERT INTO entries_fts(rowid, title, text, tags) VALUES (new.rowid, new.title, new.text, new.tags);
+END;
+CREATE TRIGGER entries_fts_delete AFTER DELETE ON entries BEGIN
+	INSERT INTO entries_fts(entries_fts, rowid, title, text, tags) VALUES ('delete', old.rowid, old.title, old.text, old.tags);
+END;
+CREATE TRIGGER entries_fts_update AFTER UPDATE ON entries BEGIN
+	INSERT INTO entries_fts(entries_fts, rowid, title, text, tags) VALUES ('delete', old.rowid, old.title, old.text, old.tags);
+	INSERT INTO entries_fts(rowid, title, text, tags) VALUES (new.rowid, new.title, new.text, new.tags);
+END;
+`,
+	},
+	{
+		version: 7,
+		name:    "trip budget currency and category budgets",
+		up: `
+ALTER TABLE trips ADD COLUMN budget_currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE trips ADD COLUMN category_budgets TEXT NOT NULL DEFAULT '{}';
+`,
+	},
+	{
+		version: 8,
+		name:    "entry attachments",
+		up: `
+CREATE TABLE attachments (
+	id         TEXT PRIMARY KEY,
+	entry_id   TEXT NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+	name       TEXT NOT NULL,
+	path       TEXT NOT NULL,
+	linked     INTEGER NOT NULL DEFAULT 0,
+	size       INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX attachments_entry_id ON attachments(entry_id, created_at);
+`,
+	},
+	{
+		version: 9,
+		name:    "gps tracks",
+		up: `
+CREATE TABLE tracks (
+	id         TEXT PRIMARY KEY,
+	trip_id    TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	entry_id   TEXT REFERENCES entries(id) ON DELETE SET NULL,
+	name       TEXT NOT NULL DEFAULT '',
+	distance   REAL NOT NULL DEFAULT 0,
+	ascent     REAL NOT NULL DEFAULT 0,
+	descent    REAL NOT NULL DEFAULT 0,
+	points     INTEGER NOT NULL DEFAULT 0,
+	started_at TEXT,
+	ended_at   TEXT,
+	created_at TEXT NOT NULL
+);
+CREATE INDEX tracks_trip_id ON tracks(trip_id, started_at);
+CREATE INDEX tracks_entry_id ON tracks(entry_id);
+`,
+	},
+	{
+		version: 10,
+		name:    "trip and expense tags",
+		up: `
+ALTER TABLE trips ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE expenses ADD COLUMN tags TEXT NOT NULL DEFAULT '[]';
+UPDATE entries SET tags = lower(tags) WHERE tags <> lower(tags);
+UPDATE entries SET tags = '[]' WHERE tags = 'null';
+`,
+	},
+	{
+		version: 11,
+		name:    "trip templates",
+		up: `
+CREATE TABLE templates (
+	id               TEXT PRIMARY KEY,
+	name             TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	locations        TEXT NOT NULL DEFAULT '[]',
+	days             INTEGER NOT NULL DEFAULT 0,
+	budget           REAL NOT NULL DEFAULT 0,
+	budget_currency  TEXT NOT NULL DEFAULT '',
+	category_budgets TEXT NOT NULL DEFAULT '{}',
+	notes            TEXT NOT NULL DEFAULT '',
+	tags             TEXT NOT NULL DEFAULT '[]',
+	packing          TEXT NOT NULL DEFAULT '[]',
+	itinerary        TEXT NOT NULL DEFAULT '[]',
+	created_at       TEXT NOT NULL,
+	updated_at       TEXT NOT NULL
+);
+`,
+	},
+	{
+		version: 12,
+		name:    "packing lists",
+		up: `
+CREATE TABLE packing_items (
+	id         TEXT PRIMARY KEY,
+	trip_id    TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	category   TEXT NOT NULL DEFAULT '',
+	name       TEXT NOT NULL,
+	packed     INTEGER NOT NULL DEFAULT 0,
+	position   INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX packing_items_trip_id ON packing_items(trip_id, category, position);
+
+CREATE TABLE packing_lists (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	items      TEXT NOT NULL DEFAULT '[]',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+-- Template packing lists were plain names; they now carry a category.
+UPDATE templates SET packing = (
+	SELECT json_group_array(json_object('name', value)) FROM json_each(templates.packing)
+) WHERE packing <> '[]';
+`,
+	},
+	{
+		version: 13,
+		name:    "track places",
+		up:      `ALTER TABLE tracks ADD COLUMN place TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 14,
+		name:    "entry weather",
+		up:      `ALTER TABLE entries ADD COLUMN weather TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 15,
+		name:    "trip legs",
+		up: `
+CREATE TABLE legs (
+	id         TEXT PRIMARY KEY,
+	trip_id    TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	location   TEXT NOT NULL,
+	arrival    TEXT NOT NULL,
+	departure  TEXT,
+	transport  TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX legs_trip_id ON legs(trip_id, arrival);
+
+ALTER TABLE entries ADD COLUMN leg_id TEXT REFERENCES legs(id) ON DELETE SET NULL;
+ALTER TABLE expenses ADD COLUMN leg_id TEXT REFERENCES legs(id) ON DELETE SET NULL;
+CREATE INDEX entries_leg_id ON entries(leg_id);
+CREATE INDEX expenses_leg_id ON expenses(leg_id);
+ALTER TABLE templates ADD COLUMN legs TEXT NOT NULL DEFAULT '[]';
+`,
+	},
+	{
+		version: 16,
+		name:    "expense splitting",
+		up: `
+ALTER TABLE trips ADD COLUMN companions TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE expenses ADD COLUMN paid_by TEXT NOT NULL DEFAULT '';
+ALTER TABLE expenses ADD COLUMN shares TEXT NOT NULL DEFAULT '[]';
+`,
+	},
+	{
+		version: 17,
+		name:    "statement rules",
+		up: `
+CREATE TABLE rules (
+	id         TEXT PRIMARY KEY,
+	match      TEXT NOT NULL UNIQUE,
+	category   TEXT NOT NULL,
+	trip_id    TEXT REFERENCES trips(id) ON DELETE SET NULL,
+	learned    INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+ALTER TABLE expenses ADD COLUMN merchant TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 18,
+		name:    "itinerary alarms",
+		up: `
+ALTER TABLE itinerary_items ADD COLUMN alarm INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version: 19,
+		name:    "time zones",
+		up: `
+ALTER TABLE entries ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
+ALTER TABLE expenses ADD COLUMN time_zone TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 20,
+		name:    "archive and trash",
+		up: `
+ALTER TABLE trips ADD COLUMN archived_at TEXT;
+
+CREATE TABLE trash (
+	id         TEXT PRIMARY KEY,
+	kind       TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	trip_id    TEXT NOT NULL,
+	record     TEXT NOT NULL,
+	deleted_at TEXT NOT NULL
+);
+CREATE INDEX trash_deleted_at ON trash(deleted_at);
+`,
+	},
+	{
+		version: 21,
+		name:    "moods and ratings",
+		up: `
+ALTER TABLE entries ADD COLUMN mood INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE trips ADD COLUMN rating INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version: 22,
+		name:    "people",
+		up: `
+CREATE TABLE people (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	contact    TEXT NOT NULL DEFAULT '',
+	notes      TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+ALTER TABLE entries ADD COLUMN people TEXT NOT NULL DEFAULT '[]';
+INSERT OR IGNORE INTO people (id, name, created_at, updated_at)
+SELECT lower(hex(randomblob(8))), value, t.created_at, t.created_at
+FROM trips t, json_each(t.companions)
+ORDER BY t.created_at;
+`,
+	},
+	{
+		version: 23,
+		name:    "public trips and entries",
+		up: `
+ALTER TABLE trips ADD COLUMN public INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE entries ADD COLUMN public INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version: 24,
+		name:    "check-ins",
+		up: `
+CREATE TABLE checkins (
+	id         TEXT PRIMARY KEY,
+	trip_id    TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	place      TEXT NOT NULL,
+	note       TEXT NOT NULL DEFAULT '',
+	lat        REAL NOT NULL DEFAULT 0,
+	lon        REAL NOT NULL DEFAULT 0,
+	timestamp  TEXT NOT NULL,
+	time_zone  TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL
+);
+CREATE INDEX checkins_trip_id ON checkins(trip_id, timestamp);
+`,
+	},
+	{
+		version: 25,
+		name:    "recurring expenses",
+		up: `
+CREATE TABLE recurrences (
+	id          TEXT PRIMARY KEY,
+	trip_id     TEXT REFERENCES trips(id) ON DELETE CASCADE,
+	amount      REAL NOT NULL,
+	currency    TEXT NOT NULL,
+	category    TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	interval    TEXT NOT NULL,
+	start_date  TEXT NOT NULL,
+	end_date    TEXT,
+	next_date   TEXT,
+	paused      INTEGER NOT NULL DEFAULT 0,
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+ALTER TABLE expenses ADD COLUMN recurrence_id TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 26,
+		name:    "country notes",
+		up: `
+CREATE TABLE country_notes (
+	country    TEXT PRIMARY KEY,
+	visa       TEXT NOT NULL DEFAULT '',
+	max_stay   INTEGER NOT NULL DEFAULT 0,
+	notes      TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+`,
+	},
+	{
+		version: 27,
+		name:    "transport segments",
+		up: `
+CREATE TABLE segments (
+	id          TEXT PRIMARY KEY,
+	trip_id     TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	mode        TEXT NOT NULL,
+	origin      TEXT NOT NULL DEFAULT '',
+	destination TEXT NOT NULL DEFAULT '',
+	distance    REAL NOT NULL DEFAULT 0,
+	date        TEXT NOT NULL,
+	notes       TEXT NOT NULL DEFAULT '',
+	created_at  TEXT NOT NULL
+);
+CREATE INDEX segments_trip_id ON segments(trip_id, date);
+`,
+	},
+	{
+		version: 28,
+		name:    "entry revisions",
+		up: `
+CREATE TABLE revisions (
+	id       TEXT PRIMARY KEY,
+	entry_id TEXT NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+	title    TEXT NOT NULL DEFAULT '',
+	text     TEXT NOT NULL DEFAULT '',
+	location TEXT NOT NULL DEFAULT '',
+	tags     TEXT NOT NULL DEFAULT '[]',
+	mood     INTEGER NOT NULL DEFAULT 0,
+	saved_at TEXT NOT NULL
+);
+CREATE INDEX revisions_entry_id ON revisions(entry_id, saved_at);
+`,
+	},
+	{
+		version: 29,
+		name:    "trip per diem",
+		up:      `ALTER TABLE trips ADD COLUMN per_diem REAL NOT NULL DEFAULT 0;`,
+	},
+	{
+		version: 30,
+		name:    "trip documents",
+		up: `
+CREATE TABLE documents (
+	id           TEXT PRIMARY KEY,
+	trip_id      TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	item_id      TEXT REFERENCES itinerary_items(id) ON DELETE SET NULL,
+	title        TEXT NOT NULL,
+	name         TEXT NOT NULL,
+	content_type TEXT NOT NULL DEFAULT '',
+	path         TEXT NOT NULL,
+	linked       INTEGER NOT NULL DEFAULT 0,
+	size         INTEGER NOT NULL DEFAULT 0,
+	note         TEXT NOT NULL DEFAULT '',
+	created_at   TEXT NOT NULL
+);
+CREATE INDEX documents_trip_id ON documents(trip_id, created_at);
+CREATE INDEX documents_item_id ON documents(item_id);
+`,
+	},
+	{
+		version: 31,
+		name:    "expense location",
+		up:      `ALTER TABLE expenses ADD COLUMN location TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		version: 32,
+		name:    "expense categories",
+		up: `
+CREATE TABLE categories (
+	id         TEXT PRIMARY KEY,
+	name       TEXT NOT NULL UNIQUE,
+	icon       TEXT NOT NULL DEFAULT '',
+	colour     TEXT NOT NULL DEFAULT '',
+	position   INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+INSERT INTO categories (id, name, icon, position, created_at, updated_at)
+SELECT lower(hex(randomblob(8))), column1, column2, column3,
+	strftime('%Y-%m-%dT%H:%M:%fZ'), strftime('%Y-%m-%dT%H:%M:%fZ')
+FROM (VALUES ('food', '🍽️', 0), ('transport', '🚆', 1), ('lodging', '🏨', 2),
+	('activities', '🎟️', 3), ('shopping', '🛍️', 4), ('other', '📦', 5));
+-- Keep any category expenses carry that is not built in.
+INSERT OR IGNORE INTO categories (id, name, position, created_at, updated_at)
+SELECT lower(hex(randomblob(8))), category, 100, strftime('%Y-%m-%dT%H:%M:%fZ'), strftime('%Y-%m-%dT%H:%M:%fZ')
+FROM (SELECT DISTINCT category FROM expenses WHERE category <> '' ORDER BY category);
+`,
+	},
+	{
+		version: 33,
+		name:    "locked exchange rates",
+		up: `
+ALTER TABLE expenses ADD COLUMN rate REAL NOT NULL DEFAULT 0;
+ALTER TABLE expenses ADD COLUMN rate_currency TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 34,
+		name:    "trip highlights",
+		up: `
+CREATE TABLE highlights (
+	id         TEXT PRIMARY KEY,
+	trip_id    TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	title      TEXT NOT NULL,
+	done       INTEGER NOT NULL DEFAULT 0,
+	entry_id   TEXT REFERENCES entries(id) ON DELETE SET NULL,
+	position   INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX highlights_trip_id ON highlights(trip_id, position);
+CREATE INDEX highlights_entry_id ON highlights(entry_id);
+`,
+	},
+	{
+		version: 35,
+		name:    "expense receipts",
+		up: `
+ALTER TABLE documents ADD COLUMN expense_id TEXT REFERENCES expenses(id) ON DELETE SET NULL;
+CREATE INDEX documents_expense_id ON documents(expense_id);
+`,
+	},
+	{
+		version: 36,
+		name:    "health and jetlag",
+		up: `
+CREATE TABLE health_days (
+	trip_id    TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	day        TEXT NOT NULL,
+	sleep      REAL NOT NULL DEFAULT 0,
+	water      REAL NOT NULL DEFAULT 0,
+	jetlag     INTEGER NOT NULL DEFAULT 0,
+	note       TEXT NOT NULL DEFAULT '',
+	updated_at TEXT NOT NULL,
+	PRIMARY KEY (trip_id, day)
+);
+`,
+	},
+	{
+		version: 37,
+		name:    "yearly trips",
+		up: `
+ALTER TABLE trips ADD COLUMN yearly TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 38,
+		name:    "lodging",
+		up: `
+CREATE TABLE lodgings (
+	id           TEXT PRIMARY KEY,
+	trip_id      TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	leg_id       TEXT REFERENCES legs(id) ON DELETE SET NULL,
+	name         TEXT NOT NULL,
+	address      TEXT NOT NULL DEFAULT '',
+	confirmation TEXT NOT NULL DEFAULT '',
+	cost         REAL NOT NULL DEFAULT 0,
+	currency     TEXT NOT NULL DEFAULT '',
+	check_in     TEXT NOT NULL,
+	check_out    TEXT NOT NULL,
+	time_zone    TEXT NOT NULL DEFAULT '',
+	notes        TEXT NOT NULL DEFAULT '',
+	expense_id   TEXT REFERENCES expenses(id) ON DELETE SET NULL,
+	created_at   TEXT NOT NULL,
+	updated_at   TEXT NOT NULL
+);
+CREATE INDEX lodgings_trip_id ON lodgings(trip_id, check_in);
+CREATE INDEX lodgings_expense_id ON lodgings(expense_id);
+`,
+	},
+	{
+		version: 39,
+		name:    "wishlist",
+		up: `
+CREATE TABLE wishes (
+	id         TEXT PRIMARY KEY,
+	place      TEXT NOT NULL,
+	notes      TEXT NOT NULL DEFAULT '',
+	budget     REAL NOT NULL DEFAULT 0,
+	currency   TEXT NOT NULL DEFAULT '',
+	season     TEXT NOT NULL DEFAULT '[]',
+	priority   TEXT NOT NULL DEFAULT 'medium',
+	trip_id    TEXT REFERENCES trips(id) ON DELETE SET NULL,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX wishes_trip_id ON wishes(trip_id);
+`,
+	},
+	{
+		version: 40,
+		name:    "achievements",
+		up: `
+CREATE TABLE achievements (
+	id          TEXT PRIMARY KEY,
+	unlocked_at TEXT NOT NULL
+);
+`,
+	},
+	{
+		version: 41,
+		name:    "trip preparation",
+		up: `
+CREATE TABLE prep_tasks (
+	id         TEXT PRIMARY KEY,
+	trip_id    TEXT NOT NULL REFERENCES trips(id) ON DELETE CASCADE,
+	title      TEXT NOT NULL,
+	lead       INTEGER NOT NULL DEFAULT 0,
+	done       INTEGER NOT NULL DEFAULT 0,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX prep_tasks_trip_id ON prep_tasks(trip_id);
+CREATE TABLE prep_templates (
+	id         TEXT PRIMARY KEY,
+	type       TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	tasks      TEXT NOT NULL DEFAULT '[]',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+-- Leads are in minutes: the insurance is for every trip, the rest for trips
+-- tagged abroad or flight.
+INSERT INTO prep_templates (id, type, tasks, created_at, updated_at)
+SELECT lower(hex(randomblob(8))), column1, column2,
+	strftime('%Y-%m-%dT%H:%M:%fZ'), strftime('%Y-%m-%dT%H:%M:%fZ')
+FROM (VALUES
+	('', '[{"title":"Book travel insurance","lead":20160}]'),
+	('abroad', '[{"title":"Check the passport is valid","lead":86400},{"title":"Check the entry and visa requirements","lead":43200}]'),
+	('flight', '[{"title":"Check in online","lead":1440}]'));
+`,
+	},
+	{
+		version: 42,
+		name:    "private entries",
+		up: `
+ALTER TABLE entries ADD COLUMN private INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version: 43,
+		name:    "track paths",
+		up:      `ALTER TABLE tracks ADD COLUMN path TEXT NOT NULL DEFAULT '[]';`,
+	},
+	{
+		version: 44,
+		name:    "trip fields",
+		up:      `ALTER TABLE trips ADD COLUMN fields TEXT NOT NULL DEFAULT '{}';`,
+	},
+	{
+		version: 45,
+		name:    "entry dates",
+		up:      `CREATE INDEX entries_timestamp ON entries(timestamp, id);`,
+	},
+	{
+		version: 46,
+		name:    "expense tax and tip",
+		up: `
+ALTER TABLE expenses ADD COLUMN tax REAL NOT NULL DEFAULT 0;
+ALTER TABLE expenses ADD COLUMN tip REAL NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		version: 47,
+		name:    "vault",
+		up: `
+CREATE TABLE vault (
+	id         TEXT PRIMARY KEY,
+	kind       TEXT NOT NULL,
+	title      TEXT NOT NULL,
+	holder     TEXT NOT NULL DEFAULT '',
+	number     TEXT NOT NULL DEFAULT '',
+	issuer     TEXT NOT NULL DEFAULT '',
+	country    TEXT NOT NULL DEFAULT '',
+	issued     TEXT,
+	expires    TEXT,
+	note       TEXT NOT NULL DEFAULT '',
+	scan_name  TEXT NOT NULL DEFAULT '',
+	scan_type  TEXT NOT NULL DEFAULT '',
+	scan       BLOB,
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX vault_expires ON vault(expires);
+`,
+	},
+	{
+		version: 48,
+		name:    "itinerary timing",
+		up: `
+ALTER TABLE itinerary_items ADD COLUMN duration INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE itinerary_items ADD COLUMN kind TEXT NOT NULL DEFAULT '';
+ALTER TABLE itinerary_items ADD COLUMN started TEXT NOT NULL DEFAULT '';
+ALTER TABLE itinerary_items ADD COLUMN finished TEXT NOT NULL DEFAULT '';
+`,
+	},
+	{
+		version: 49,
+		name:    "connectivity",
+		up: `
+CREATE TABLE connectivity (
+	id         TEXT PRIMARY KEY,
+	country    TEXT NOT NULL,
+	trip_id    TEXT REFERENCES trips(id) ON DELETE SET NULL,
+	leg_id     TEXT REFERENCES legs(id) ON DELETE SET NULL,
+	place      TEXT NOT NULL DEFAULT '',
+	provider   TEXT NOT NULL DEFAULT '',
+	cost       REAL NOT NULL DEFAULT 0,
+	currency   TEXT NOT NULL DEFAULT '',
+	speed      TEXT NOT NULL DEFAULT '',
+	wifi       TEXT NOT NULL DEFAULT '[]',
+	notes      TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE INDEX connectivity_country ON connectivity(country);
+`,
+	},
+}
+
+// SchemaVersion reports the version the database is currently at.
+func (s *Store) SchemaVersion(ctx context.Context) (int, error) {
+	var v int
+	err := s.db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&v)
+	return v, err
+}
+
+// migrate brings the database up to date, backing it up first when it
+// has data, and puts its expense categories in use.
+func (s *Store) migrate(ctx context.Context) error {
+	if _, _, err := s.migrateTo(ctx, LatestVersion(), true); err != nil {
+		return err
+	}
+	return s.useCategories(ctx)
+}
+
+// migrateTo applies every migration newer than the database's user_version
+// up to target, each inside its own transaction, and returns those it
+// applied. With backup set an existing database is first backed up into
+// BackupsDir, where the last few such backups are kept, and the path of
+// the backup is returned too. A database newer than this version of
+// nomadic knows is refused with ErrNewerSchema.
+func (s *Store) migrateTo(ctx context.Context, target int, backup bool) ([]Migration, string, error) {
+	current, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("storage: read schema version: %w", err)
+	}
+	if latest := LatestVersion(); current > latest {
+		return nil, "", fmt.Errorf("%w (schema %d, this one knows %d)", ErrNewerSchema, current, latest)
+	}
+	if current < target && s.lock != nil && !s.lock.exclusive {
+		// Migrating has the data directory to itself, waiting for the
+		// others using it to finish unless one of them migrates it first.
+		err := s.lock.upgrade(func() bool {
+			v, err := s.SchemaVersion(ctx)
+			return err != nil || v < target
+		})
+		if errors.Is(err, errGaveUp) {
+			return nil, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		defer s.lock.downgrade()
+		if current, err = s.SchemaVersion(ctx); err != nil {
+			return nil, "", fmt.Errorf("storage: read schema version: %w", err)
+		}
+	}
+	saved := ""
+	if backup && current > 0 && current < target {
+		db, err := s.snapshot(ctx)
+		if err == nil {
+			saved, err = autoBackup(s.dir, fmt.Sprintf("%s%d", migrationBackupPrefix, current), db, current, true)
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("storage: back up before migrating: %w", err)
+		}
+	}
+	var applied []Migration
+	for _, m := range migrations {
+		if m.version <= current || m.version > target {
+			continue
+		}
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return applied, saved, err
+		}
+		if _, err := tx.ExecContext(ctx, m.up); err != nil {
+			tx.Rollback()
+			return applied, saved, fmt.Errorf("storage: migration %d (%s): %w", m.version, m.name, err)
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, m.version)); err != nil {
+			tx.Rollback()
+			return applied, saved, fmt.Errorf("storage: migration %d (%s): %w", m.version, m.name, err)
+		}
+		if err := s.commit(ctx, tx); err != nil {
+			return applied, saved, fmt.Errorf("storage: migration %d (%s): %w", m.version, m.name, err)
+		}
+		slog.Info("storage: migrated", "version", m.version, "name", m.name)
+		applied = append(applied, Migration{Version: m.version, Name: m.name})
+	}
+	return applied, saved, nil
+}
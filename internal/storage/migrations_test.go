@@ -0,0 +1,442 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// openAt opens a new database in a temporary directory, migrated up to
+// version.
+func openAt(t *testing.T, version int) *Store {
+	t.Helper()
+	s, err := openPlain(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	if _, _, err := s.migrateTo(t.Context(), version, false); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func mustExec(t *testing.T, s *Store, query string, args ...any) {
+	t.Helper()
+	if _, err := s.db.Exec(query, args...); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+}
+
+func mustQueryString(t *testing.T, s *Store, query string, args ...any) string {
+	t.Helper()
+	var v string
+	if err := s.db.QueryRow(query, args...).Scan(&v); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return v
+}
+
+func schemaVersion(t *testing.T, s *Store) int {
+	t.Helper()
+	v, err := s.SchemaVersion(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestMigrationsAreNumberedInOrder(t *testing.T) {
+	names := map[string]bool{}
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("migration %d (%s) is numbered %d", i+1, m.name, m.version)
+		}
+		if m.name == "" || names[m.name] {
+			t.Errorf("migration %d has an empty or repeated name %q", m.version, m.name)
+		}
+		names[m.name] = true
+		if strings.TrimSpace(m.up) == "" {
+			t.Errorf("migration %d (%s) changes nothing", m.version, m.name)
+		}
+	}
+}
+
+var (
+	createdPattern = regexp.MustCompile(`(?i)CREATE\s+(?:VIRTUAL\s+)?(TABLE|INDEX|TRIGGER)\s+(\w+)`)
+	addedPattern   = regexp.MustCompile(`(?i)ALTER\s+TABLE\s+(\w+)\s+ADD\s+COLUMN\s+(\w+)`)
+)
+
+// hasObject reports whether the schema holds a table, index or trigger.
+func hasObject(t *testing.T, s *Store, kind, name string) bool {
+	t.Helper()
+	var n int
+	err := s.db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = ? AND name = ?`, strings.ToLower(kind), name).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+// hasColumn reports whether table has column.
+func hasColumn(t *testing.T, s *Store, table, column string) bool {
+	t.Helper()
+	var n int
+	err := s.db.QueryRow(`SELECT count(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n > 0
+}
+
+// TestMigrationSteps applies each migration to a database at the version
+// before it and checks that it moves the version on by one and creates the
+// tables, indexes, triggers and columns it names, which were not there
+// before, leaving a database that passes the integrity checks.
+func TestMigrationSteps(t *testing.T) {
+	for _, m := range migrations {
+		t.Run(fmt.Sprintf("%d %s", m.version, m.name), func(t *testing.T) {
+			s := openAt(t, m.version-1)
+			created := createdPattern.FindAllStringSubmatch(m.up, -1)
+			added := addedPattern.FindAllStringSubmatch(m.up, -1)
+			for _, c := range created {
+				if hasObject(t, s, c[1], c[2]) {
+					t.Errorf("%s %s exists before the migration", c[1], c[2])
+				}
+			}
+			for _, a := range added {
+				if hasColumn(t, s, a[1], a[2]) {
+					t.Errorf("column %s.%s exists before the migration", a[1], a[2])
+				}
+			}
+
+			applied, _, err := s.migrateTo(t.Context(), m.version, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(applied) != 1 || applied[0].Version != m.version {
+				t.Fatalf("applied %v, want migration %d alone", applied, m.version)
+			}
+			if v := schemaVersion(t, s); v != m.version {
+				t.Fatalf("schema version %d after the migration, want %d", v, m.version)
+			}
+			for _, c := range created {
+				if !hasObject(t, s, c[1], c[2]) {
+					t.Errorf("%s %s missing after the migration", c[1], c[2])
+				}
+			}
+			for _, a := range added {
+				if !hasColumn(t, s, a[1], a[2]) {
+					t.Errorf("column %s.%s missing after the migration", a[1], a[2])
+				}
+			}
+			if err := s.checkIntegrity(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestMigrationLowercasesEntryTags(t *testing.T) {
+	s := openAt(t, 9)
+	mustExec(t, s, `INSERT INTO trips (id, title, start_date, created_at, updated_at) VALUES ('t', 'Japan', '2025-04-01', '', '')`)
+	mustExec(t, s, `INSERT INTO entries (id, trip_id, timestamp, created_at, updated_at, tags) VALUES
+		('a', 't', '', '', '', '["Food","Ramen"]'), ('b', 't', '', '', '', 'null')`)
+	if _, _, err := s.migrateTo(t.Context(), 10, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustQueryString(t, s, `SELECT tags FROM entries WHERE id = 'a'`); got != `["food","ramen"]` {
+		t.Errorf("tags = %s, want lower case", got)
+	}
+	if got := mustQueryString(t, s, `SELECT tags FROM entries WHERE id = 'b'`); got != `[]` {
+		t.Errorf("tags = %s, want []", got)
+	}
+}
+
+func TestMigrationGivesTemplatePackingItems(t *testing.T) {
+	s := openAt(t, 11)
+	mustExec(t, s, `INSERT INTO templates (id, name, packing, created_at, updated_at) VALUES
+		('a', 'Beach', '["Sunscreen","Towel"]', '', ''), ('b', 'City', '[]', '', '')`)
+	if _, _, err := s.migrateTo(t.Context(), 12, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustQueryString(t, s, `SELECT packing FROM templates WHERE id = 'a'`); got != `[{"name":"Sunscreen"},{"name":"Towel"}]` {
+		t.Errorf("packing = %s", got)
+	}
+	if got := mustQueryString(t, s, `SELECT packing FROM templates WHERE id = 'b'`); got != `[]` {
+		t.Errorf("packing = %s, want []", got)
+	}
+}
+
+func TestMigrationAddsCompanionsAsPeople(t *testing.T) {
+	s := openAt(t, 21)
+	mustExec(t, s, `INSERT INTO trips (id, title, start_date, created_at, updated_at, companions) VALUES
+		('a', 'Japan', '2025-04-01', '2025-01-01', '', '["Ana","Ben"]'),
+		('b', 'Peru', '2025-08-01', '2025-02-01', '', '["ana","Caro"]')`)
+	if _, _, err := s.migrateTo(t.Context(), 22, false); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := s.db.Query(`SELECT name FROM people ORDER BY name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var n string
+		if err := rows.Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, n)
+	}
+	if got := strings.Join(names, ","); got != "Ana,Ben,Caro" {
+		t.Errorf("people = %s, want Ana,Ben,Caro", got)
+	}
+}
+
+// oldDatabase writes a plain database at version into a new data
+// directory, with a trip in it, and returns the directory.
+func oldDatabase(t *testing.T, version int) string {
+	t.Helper()
+	dir := t.TempDir()
+	s, err := openPlain(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.migrateTo(t.Context(), version, false); err != nil {
+		t.Fatal(err)
+	}
+	mustExec(t, s, `INSERT INTO trips (id, title, start_date, created_at, updated_at) VALUES (?, 'Japan', ?, ?, ?)`,
+		"trip", formatTime(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)), formatTime(time.Now()), formatTime(time.Now()))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestOpenUpgradesAnOldDatabase(t *testing.T) {
+	dir := oldDatabase(t, 1)
+	s, err := Open(t.Context(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if v := schemaVersion(t, s); v != LatestVersion() {
+		t.Fatalf("schema version %d, want %d", v, LatestVersion())
+	}
+	trip, err := s.GetTrip(t.Context(), "trip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trip.Title != "Japan" || len(trip.Tags) != 0 {
+		t.Errorf("trip = %+v", trip)
+	}
+	backups, err := MigrationBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || !strings.Contains(filepath.Base(backups[0]), migrationBackupPrefix+"1-") {
+		t.Errorf("backups = %v, want one of schema 1", backups)
+	}
+}
+
+func TestOpenLeavesANewDatabaseUnbackedUp(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(t.Context(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	if backups, err := MigrationBackups(dir); err != nil || len(backups) != 0 {
+		t.Errorf("backups = %v, %v; want none", backups, err)
+	}
+}
+
+func TestOpenRefusesANewerSchema(t *testing.T) {
+	dir := oldDatabase(t, LatestVersion())
+	s, err := openPlain(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mustExec(t, s, fmt.Sprintf(`PRAGMA user_version = %d`, LatestVersion()+1))
+	s.Close()
+	if _, err := Open(t.Context(), dir); !errors.Is(err, ErrNewerSchema) {
+		t.Fatalf("Open = %v, want ErrNewerSchema", err)
+	}
+}
+
+func TestSchemaStatus(t *testing.T) {
+	version, pending, err := SchemaStatus(t.Context(), t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 0 || len(pending) != len(migrations) {
+		t.Errorf("empty directory: version %d with %d pending, want 0 with all", version, len(pending))
+	}
+
+	dir := oldDatabase(t, 20)
+	if version, pending, err = SchemaStatus(t.Context(), dir, ""); err != nil {
+		t.Fatal(err)
+	}
+	if version != 20 || len(pending) != LatestVersion()-20 || pending[0].Version != 21 {
+		t.Errorf("version %d with pending %v, want 20 with 21 on", version, pending)
+	}
+}
+
+func TestDryRunMigrationsLeavesTheDatabase(t *testing.T) {
+	dir := oldDatabase(t, 20)
+	before, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := DryRunMigrations(t.Context(), dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != LatestVersion()-20 || applied[len(applied)-1].Version != LatestVersion() {
+		t.Errorf("applied %v, want 21 to %d", applied, LatestVersion())
+	}
+	after, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("the dry run changed the database")
+	}
+	if version, _, _ := SchemaStatus(t.Context(), dir, ""); version != 20 {
+		t.Errorf("schema version %d after the dry run, want 20", version)
+	}
+	if backups, _ := MigrationBackups(dir); len(backups) != 0 {
+		t.Errorf("the dry run backed up the database: %v", backups)
+	}
+}
+
+func TestDryRunMigrationsReportsTheFailingStep(t *testing.T) {
+	last := migrations[len(migrations)-1]
+	dir := oldDatabase(t, last.version-1)
+	s, err := openPlain(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A table in the way of the last migration's makes it fail.
+	table := createdPattern.FindStringSubmatch(last.up)
+	if table == nil || !strings.EqualFold(table[1], "table") {
+		t.Skipf("migration %d creates no table to get in the way of", last.version)
+	}
+	mustExec(t, s, `CREATE TABLE `+table[2]+` (id TEXT)`)
+	s.Close()
+
+	applied, err := DryRunMigrations(t.Context(), dir, "")
+	if err == nil {
+		t.Fatal("the dry run succeeded")
+	}
+	if len(applied) != 0 || !strings.Contains(err.Error(), fmt.Sprintf("migration %d", last.version)) {
+		t.Errorf("applied %v with error %v, want the error to name migration %d", applied, err, last.version)
+	}
+	if version, _, _ := SchemaStatus(t.Context(), dir, ""); version != last.version-1 {
+		t.Errorf("schema version %d after the dry run, want %d", version, last.version-1)
+	}
+}
+
+func TestDryRunMigrationsOfAnEncryptedDatabase(t *testing.T) {
+	dir := oldDatabase(t, 20)
+	plain, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sl, err := newRandomSealer("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := sl.seal(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, EncryptedFileName), sealed, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := removePlain(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := DryRunMigrations(t.Context(), dir, ""); !errors.Is(err, ErrEncrypted) {
+		t.Fatalf("without a passphrase: %v, want ErrEncrypted", err)
+	}
+	applied, err := DryRunMigrations(t.Context(), dir, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != LatestVersion()-20 {
+		t.Errorf("applied %v, want 21 to %d", applied, LatestVersion())
+	}
+	after, err := os.ReadFile(filepath.Join(dir, EncryptedFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sealed, after) {
+		t.Error("the dry run changed the encrypted database")
+	}
+}
+
+func TestRollBackAMigration(t *testing.T) {
+	dir := oldDatabase(t, 20)
+	applied, backup, err := Migrate(t.Context(), dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != LatestVersion()-20 || backup == "" {
+		t.Fatalf("applied %v with backup %q", applied, backup)
+	}
+	s, err := Open(t.Context(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveTrip(t.Context(), models.NewTrip("Peru", []string{"Cusco"}, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	backups, err := MigrationBackups(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || backups[0] != backup {
+		t.Fatalf("backups = %v, want %s", backups, backup)
+	}
+	m, saved, err := Restore(t.Context(), dir, backups[0], "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SchemaVersion != 20 || !m.DatabaseOnly || saved == "" {
+		t.Errorf("restored %+v, replaced backed up in %q", m, saved)
+	}
+	version, _, err := SchemaStatus(t.Context(), dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 20 {
+		t.Errorf("schema version %d after rolling back, want 20", version)
+	}
+
+	// Opening it again migrates it again, with the trip from before.
+	s, err = Open(t.Context(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	trips, err := s.ListTrips(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trips) != 1 || trips[0].ID != "trip" {
+		t.Errorf("trips after rolling back = %v, want the one from before the migration", trips)
+	}
+}
@@ -0,0 +1,444 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/notes"
+)
+
+// NotesStateFile is the file in the data directory recording the notes
+// nomadic last wrote or read, to tell the notes edited or removed in their
+// folder since.
+const NotesStateFile = "notes.json"
+
+// notesTrash is the folder in the notes folder removed notes are moved
+// to, Obsidian's own trash.
+const notesTrash = ".trash"
+
+// ErrNotesEncrypted is returned by KeepNotes for an encrypted store,
+// whose entries are not to be written out in plain text.
+var ErrNotesEncrypted = errors.New("storage: an encrypted journal cannot keep its entries as Markdown notes")
+
+// noteFolder is the folder a store keeps its entries in as notes.
+type noteFolder struct {
+	dir string
+	// statePath is the file recording where in dir the note of each entry
+	// is, by entry ID, as nomadic last wrote or read it.
+	statePath string
+	mu        sync.Mutex
+}
+
+// noteStamp is a note as nomadic last wrote or read it.
+type noteStamp struct {
+	Path    string    `json:"path"` // relative to the folder
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+}
+
+// notesState is the content of NotesStateFile.
+type notesState struct {
+	Dir   string               `json:"dir"`
+	Notes map[string]noteStamp `json:"notes"`
+}
+
+// NotesSync is what SyncNotes did: the notes it imported as new entries,
+// the entries it updated from notes edited, the entries or notes it
+// removed along with the other and the notes it wrote for entries that
+// had none. Skipped names the notes it could not read, with why.
+type NotesSync struct {
+	Dir      string
+	Imported int
+	Updated  int
+	Removed  int
+	Written  int
+	Skipped  []string
+}
+
+// KeepNotes has the store keep its journal entries as Markdown notes in
+// dir too, writing them as they are saved and removing them as they are
+// deleted, and syncs the notes already there with SyncNotes.
+func (s *Store) KeepNotes(ctx context.Context, dir string) (*NotesSync, error) {
+	if s.sealer != nil {
+		return nil, ErrNotesEncrypted
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("storage: notes: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("storage: notes: %w", err)
+	}
+	s.notes = &noteFolder{dir: dir, statePath: filepath.Join(s.dir, NotesStateFile)}
+	return s.SyncNotes(ctx)
+}
+
+// SyncNotes brings the entries and their notes together: notes edited
+// since nomadic last wrote them update their entries, new notes become
+// entries of the trip they name or whose folder they are in, and notes
+// removed trash their entries; entries without a note, such as those
+// from before notes were kept, are written out, and notes of entries
+// deleted elsewhere are moved to the folder's trash.
+func (s *Store) SyncNotes(ctx context.Context) (*NotesSync, error) {
+	f := s.notes
+	if f == nil {
+		return nil, errors.New("storage: journal entries are not kept as notes")
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	report := &NotesSync{Dir: f.dir}
+	state, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	type found struct {
+		path string
+		note *notes.Note
+		info fs.FileInfo
+	}
+	byID := map[string]*found{}
+	var fresh []*found
+	err = filepath.WalkDir(f.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != f.dir {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(path) != notes.Ext {
+			return nil
+		}
+		rel, _ := filepath.Rel(f.dir, path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		n, err := notes.Unmarshal(data)
+		if err != nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: %v", rel, err))
+			return nil
+		}
+		fd := &found{path: rel, note: n, info: info}
+		if n.ID == "" || byID[n.ID] != nil {
+			// A note copied from another keeps the ID it was copied with.
+			n.ID = ""
+			fresh = append(fresh, fd)
+		} else {
+			byID[n.ID] = fd
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: notes: %w", err)
+	}
+
+	trips, err := s.ListTrips(ctx)
+	if err != nil {
+		return nil, err
+	}
+	titles := make(map[string]string, len(trips))
+	for _, t := range trips {
+		titles[t.ID] = t.Title
+	}
+	entries, err := s.ListEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		fd := byID[e.ID]
+		delete(byID, e.ID)
+		stamp, had := state.Notes[e.ID]
+		switch {
+		case fd == nil && had:
+			// The note was removed from the folder.
+			if _, err := s.trashEntry(ctx, e.ID); err != nil {
+				return report, err
+			}
+			delete(state.Notes, e.ID)
+			report.Removed++
+		case fd == nil:
+			if err := s.writeNote(ctx, state, e, titles[e.TripID], nil); err != nil {
+				return report, err
+			}
+			report.Written++
+		case stamp.Path != fd.path || !stamp.ModTime.Equal(fd.info.ModTime()) || stamp.Size != fd.info.Size():
+			if fd.note.Apply(e) {
+				if err := s.saveEntry(ctx, e); err != nil {
+					return report, err
+				}
+				report.Updated++
+			}
+			state.Notes[e.ID] = noteStamp{Path: fd.path, ModTime: fd.info.ModTime(), Size: fd.info.Size()}
+		}
+	}
+	for id, fd := range byID {
+		if _, had := state.Notes[id]; had {
+			// The entry was deleted where the note was not kept.
+			if err := f.trash(fd.path); err != nil {
+				return report, err
+			}
+			delete(state.Notes, id)
+			report.Removed++
+			continue
+		}
+		fresh = append(fresh, fd)
+	}
+
+	for _, fd := range fresh {
+		t := tripOfNote(trips, fd.note.Trip, fd.path)
+		if t == nil {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s: no trip of that name; name one with trip: or move the note into its trip's folder", fd.path))
+			continue
+		}
+		e := newEntryOfNote(fd.note, fd.path, fd.info.ModTime(), t.ID)
+		legs, err := s.ListLegsByTrip(ctx, t.ID)
+		if err != nil {
+			return report, err
+		}
+		if l := models.LegOn(legs, e.Timestamp); l != nil {
+			e.LegID = l.ID
+		}
+		if err := s.saveEntry(ctx, e); err != nil {
+			return report, err
+		}
+		// Written again, the note gains its ID and its name.
+		state.Notes[e.ID] = noteStamp{Path: fd.path}
+		if err := s.writeNote(ctx, state, e, t.Title, fd.note); err != nil {
+			return report, err
+		}
+		report.Imported++
+	}
+	return report, f.save(state)
+}
+
+// tripOfNote finds the trip a note names, by title or ID, or else the
+// trip whose folder it is in.
+func tripOfNote(trips []*models.Trip, name, path string) *models.Trip {
+	folder := strings.Split(filepath.ToSlash(path), "/")[0]
+	for _, ref := range []string{name, folder} {
+		if ref == "" {
+			continue
+		}
+		for _, t := range trips {
+			if t.ID == ref || strings.EqualFold(t.Title, ref) || strings.EqualFold(notes.Folder(t.Title), ref) {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// newEntryOfNote makes the entry of a note new to nomadic, found at path
+// and last modified at modified. A note that does not say when it was
+// written or what it is called is dated and titled by its file name, as
+// in "2025-04-03 Fushimi Inari.md", or dated by when it was modified.
+func newEntryOfNote(n *notes.Note, path string, modified time.Time, tripID string) *models.Entry {
+	name := strings.TrimSuffix(filepath.Base(path), notes.Ext)
+	day, title, _ := strings.Cut(name, " ")
+	when, err := time.ParseInLocation(models.DateLayout, day, time.Local)
+	if err != nil {
+		when, title = modified, name
+	}
+	if n.Time.IsZero() {
+		n.Time = when.Truncate(time.Second)
+	}
+	if n.Title == "" {
+		n.Title = strings.TrimSpace(title)
+	}
+	e := models.NewEntry(tripID, "", n.Time)
+	if n.ID != "" {
+		e.ID = n.ID
+	}
+	n.ID = e.ID
+	n.Apply(e)
+	return e
+}
+
+// writeNote writes the note of e, an entry of the trip called trip, into
+// the notes folder, if the store keeps one, and records it in state. A
+// note already written for it is moved along when its name changes; old,
+// when known, is what that note said.
+func (s *Store) writeNote(_ context.Context, state *notesState, e *models.Entry, trip string, old *notes.Note) error {
+	f := s.notes
+	stamp, had := state.Notes[e.ID]
+	if had && old == nil {
+		if data, err := os.ReadFile(filepath.Join(f.dir, stamp.Path)); err == nil {
+			old, _ = notes.Unmarshal(data)
+		}
+	}
+	path := notes.Path(trip, e)
+	// Another note of the same day and title takes a number.
+	ext := filepath.Ext(path)
+	for i := 2; ; i++ {
+		if path == stamp.Path || !f.taken(path, e.ID) {
+			break
+		}
+		path = strings.TrimSuffix(notes.Path(trip, e), ext) + fmt.Sprintf(" (%d)", i) + ext
+	}
+	full := filepath.Join(f.dir, path)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return fmt.Errorf("storage: write note: %w", err)
+	}
+	if err := os.WriteFile(full, notes.FromEntry(e, trip, old).Marshal(), 0o644); err != nil {
+		return fmt.Errorf("storage: write note: %w", err)
+	}
+	if had && stamp.Path != path {
+		f.remove(stamp.Path)
+	}
+	info, err := os.Stat(full)
+	if err != nil {
+		return fmt.Errorf("storage: write note: %w", err)
+	}
+	state.Notes[e.ID] = noteStamp{Path: path, ModTime: info.ModTime(), Size: info.Size()}
+	return nil
+}
+
+// taken reports whether path holds the note of an entry other than id.
+func (f *noteFolder) taken(path, id string) bool {
+	data, err := os.ReadFile(filepath.Join(f.dir, path))
+	if err != nil {
+		return false
+	}
+	n, err := notes.Unmarshal(data)
+	return err != nil || n.ID != id
+}
+
+// saveNote writes the note of an entry just saved, if the store keeps
+// notes.
+func (s *Store) saveNote(ctx context.Context, e *models.Entry) error {
+	if s.notes == nil {
+		return nil
+	}
+	t, err := s.GetTrip(ctx, e.TripID)
+	if err != nil {
+		return err
+	}
+	return s.notes.update(func(state *notesState) error {
+		return s.writeNote(ctx, state, e, t.Title, nil)
+	})
+}
+
+// renameNotes moves the notes of a trip's entries into the folder of its
+// new title.
+func (s *Store) renameNotes(ctx context.Context, t *models.Trip) error {
+	entries, err := s.ListEntriesByTrip(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	return s.notes.update(func(state *notesState) error {
+		for _, e := range entries {
+			if err := s.writeNote(ctx, state, e, t.Title, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// removeNotes moves the notes of the entries with the given IDs to the
+// notes folder's trash, if the store keeps notes.
+func (s *Store) removeNotes(ids ...string) error {
+	if s.notes == nil {
+		return nil
+	}
+	return s.notes.update(func(state *notesState) error {
+		for _, id := range ids {
+			if stamp, ok := state.Notes[id]; ok {
+				if err := s.notes.trash(stamp.Path); err != nil {
+					return err
+				}
+				delete(state.Notes, id)
+			}
+		}
+		return nil
+	})
+}
+
+// trash moves the note at path to the folder's trash, and removes its
+// trip's folder once it is empty.
+func (f *noteFolder) trash(path string) error {
+	to := filepath.Join(f.dir, notesTrash, filepath.Base(path))
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return fmt.Errorf("storage: remove note: %w", err)
+	}
+	if err := os.Rename(filepath.Join(f.dir, path), to); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: remove note: %w", err)
+	}
+	os.Remove(filepath.Join(f.dir, filepath.Dir(path)))
+	return nil
+}
+
+// remove removes the note at path, moved elsewhere, and its trip's folder
+// once it is empty.
+func (f *noteFolder) remove(path string) {
+	os.Remove(filepath.Join(f.dir, path))
+	os.Remove(filepath.Join(f.dir, filepath.Dir(path)))
+}
+
+// update changes the state of the folder as recorded on disk, which
+// another nomadic may have changed since.
+func (f *noteFolder) update(change func(*notesState) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	state, err := f.load()
+	if err != nil {
+		return err
+	}
+	if err := change(state); err != nil {
+		return err
+	}
+	return f.save(state)
+}
+
+// load reads the state of the folder. The state of another folder, from
+// before journal_dir changed, does not count.
+func (f *noteFolder) load() (*notesState, error) {
+	state := &notesState{Dir: f.dir, Notes: map[string]noteStamp{}}
+	data, err := os.ReadFile(f.statePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: notes: %w", err)
+	}
+	var saved notesState
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("storage: notes: %s: %w", f.statePath, err)
+	}
+	if saved.Dir == f.dir && saved.Notes != nil {
+		state.Notes = saved.Notes
+	}
+	return state, nil
+}
+
+func (f *noteFolder) save(state *notesState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := f.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("storage: notes: %w", err)
+	}
+	if err := os.Rename(tmp, f.statePath); err != nil {
+		return fmt.Errorf("storage: notes: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Saved tells an observer of the store of a trip, journal entry or
+// expense written to it: one of Trip, Entry and Expense is set. Created
+// is set when it was not in the store before.
+type Saved struct {
+	Trip    *models.Trip
+	Entry   *models.Entry
+	Expense *models.Expense
+	Created bool
+}
+
+// Observe has fn told of every trip, journal entry and expense saved
+// through s from then on, once it is saved and before the save returns.
+// fn must not change what it is told of.
+func (s *Store) Observe(fn func(ctx context.Context, saved Saved)) {
+	s.observer = fn
+}
+
+// saved tells the observer, if any, of what was saved.
+func (s *Store) saved(ctx context.Context, saved Saved) {
+	if s.observer != nil {
+		s.observer(ctx, saved)
+	}
+}
+
+// isNew reports whether table has no row with the ID id yet, for telling
+// the observer what was created; without one it is not worth asking.
+func (s *Store) isNew(ctx context.Context, table, id string) bool {
+	if s.observer == nil {
+		return false
+	}
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM `+table+` WHERE id = ?`, id).Scan(&n)
+	return err == nil && n == 0
+}
@@ -0,0 +1,261 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const packingColumns = `id, trip_id, category, name, packed, position, created_at, updated_at`
+
+const insertPackingItem = `
+INSERT INTO packing_items (` + packingColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	category = excluded.category,
+	name = excluded.name,
+	packed = excluded.packed,
+	position = excluded.position,
+	updated_at = excluded.updated_at`
+
+// SavePackingItem inserts the item, or updates it if one with the same ID
+// exists.
+func (s *Store) SavePackingItem(ctx context.Context, it *models.PackingItem) error {
+	touchPackingItem(it)
+	if _, err := s.exec(ctx, insertPackingItem, packingArgs(it)...); err != nil {
+		return fmt.Errorf("storage: save packing item: %w", err)
+	}
+	return nil
+}
+
+// AddPackingItems adds the items of list to a trip's packing list,
+// skipping those the trip already has in the same category. Added items
+// go after the ones already in their category. It returns how many were
+// added.
+func (s *Store) AddPackingItems(ctx context.Context, tripID string, list []models.PackingListItem) (int, error) {
+	existing, err := s.ListPackingByTrip(ctx, tripID)
+	if err != nil {
+		return 0, err
+	}
+	have := make(map[string]bool, len(existing))
+	next := make(map[string]int)
+	spelling := make(map[string]string) // categories already in use, by lowercase name
+	for _, it := range existing {
+		have[packingKey(it.Category, it.Name)] = true
+		next[it.Category] = max(next[it.Category], it.Position+1)
+		spelling[strings.ToLower(it.Category)] = it.Category
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("storage: add packing items: %w", err)
+	}
+	defer tx.Rollback()
+	added := 0
+	for _, li := range list {
+		if c, ok := spelling[strings.ToLower(li.Category)]; ok {
+			li.Category = c
+		} else {
+			spelling[strings.ToLower(li.Category)] = li.Category
+		}
+		key := packingKey(li.Category, li.Name)
+		if li.Name == "" || have[key] {
+			continue
+		}
+		have[key] = true
+		it := models.NewPackingItem(tripID, li.Category, li.Name)
+		it.Position = next[li.Category]
+		next[li.Category]++
+		touchPackingItem(it)
+		if _, err := tx.ExecContext(ctx, insertPackingItem, packingArgs(it)...); err != nil {
+			return 0, fmt.Errorf("storage: add packing items: %w", err)
+		}
+		added++
+	}
+	if added == 0 {
+		return 0, nil
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return 0, fmt.Errorf("storage: add packing items: %w", err)
+	}
+	return added, nil
+}
+
+// GetPackingItem returns the packing item with the given ID.
+func (s *Store) GetPackingItem(ctx context.Context, id string) (*models.PackingItem, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+packingColumns+` FROM packing_items WHERE id = ?`, id)
+	it, err := scanPackingItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get packing item: %w", err)
+	}
+	return it, nil
+}
+
+// ListPackingByTrip returns a trip's packing list grouped by category, in
+// alphabetical order with uncategorized items last, then by position.
+func (s *Store) ListPackingByTrip(ctx context.Context, tripID string) ([]*models.PackingItem, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+packingColumns+` FROM packing_items WHERE trip_id = ?
+ORDER BY category = '', lower(category), position, created_at`, tripID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list packing items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.PackingItem
+	for rows.Next() {
+		it, err := scanPackingItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list packing items: %w", err)
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// DeletePackingItem removes a packing item.
+func (s *Store) DeletePackingItem(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM packing_items WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete packing item: %w", err)
+	}
+	return expectAffected(res)
+}
+
+// SavePackingList inserts the master list, or updates it if one with the
+// same ID exists. Names are unique, compared without case.
+func (s *Store) SavePackingList(ctx context.Context, l *models.PackingList) error {
+	if l.ID == "" {
+		l.ID = models.NewID()
+	}
+	now := time.Now()
+	if l.CreatedAt.IsZero() {
+		l.CreatedAt = now
+	}
+	l.UpdatedAt = now
+
+	items, err := marshalJSON(l.Items, "[]")
+	if err != nil {
+		return err
+	}
+	_, err = s.exec(ctx, `
+INSERT INTO packing_lists (id, name, items, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	name = excluded.name,
+	items = excluded.items,
+	updated_at = excluded.updated_at`,
+		l.ID, l.Name, items, formatTime(l.CreatedAt), formatTime(l.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save packing list: %w", err)
+	}
+	return nil
+}
+
+// SavePackingListAs saves items as the master list called name, replacing
+// the list of that name if there is one.
+func (s *Store) SavePackingListAs(ctx context.Context, name string, items []models.PackingListItem) (*models.PackingList, error) {
+	lists, err := s.ListPackingLists(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l := &models.PackingList{Name: name}
+	for _, existing := range lists {
+		if strings.EqualFold(existing.Name, name) {
+			l = existing
+		}
+	}
+	l.Items = items
+	if err := s.SavePackingList(ctx, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// ListPackingLists returns every master list ordered by name.
+func (s *Store) ListPackingLists(ctx context.Context) ([]*models.PackingList, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, name, items, created_at, updated_at FROM packing_lists ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list packing lists: %w", err)
+	}
+	defer rows.Close()
+
+	var lists []*models.PackingList
+	for rows.Next() {
+		var (
+			l                   models.PackingList
+			items, created, upd string
+		)
+		if err := rows.Scan(&l.ID, &l.Name, &items, &created, &upd); err != nil {
+			return nil, fmt.Errorf("storage: list packing lists: %w", err)
+		}
+		if err := json.Unmarshal([]byte(items), &l.Items); err != nil {
+			return nil, fmt.Errorf("storage: list packing lists: %w", err)
+		}
+		if l.CreatedAt, err = parseTime(created); err != nil {
+			return nil, err
+		}
+		if l.UpdatedAt, err = parseTime(upd); err != nil {
+			return nil, err
+		}
+		lists = append(lists, &l)
+	}
+	return lists, rows.Err()
+}
+
+// DeletePackingList removes a master list. Items already added to trips
+// are kept.
+func (s *Store) DeletePackingList(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM packing_lists WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete packing list: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func touchPackingItem(it *models.PackingItem) {
+	if it.ID == "" {
+		it.ID = models.NewID()
+	}
+	now := time.Now()
+	if it.CreatedAt.IsZero() {
+		it.CreatedAt = now
+	}
+	it.UpdatedAt = now
+}
+
+func packingArgs(it *models.PackingItem) []any {
+	return []any{it.ID, it.TripID, it.Category, it.Name, it.Packed, it.Position,
+		formatTime(it.CreatedAt), formatTime(it.UpdatedAt)}
+}
+
+// packingKey identifies an item within a trip's list, ignoring case.
+func packingKey(category, name string) string {
+	return strings.ToLower(category) + "\x00" + strings.ToLower(name)
+}
+
+func scanPackingItem(sc scanner) (*models.PackingItem, error) {
+	var (
+		it           models.PackingItem
+		created, upd string
+	)
+	if err := sc.Scan(&it.ID, &it.TripID, &it.Category, &it.Name, &it.Packed, &it.Position,
+		&created, &upd); err != nil {
+		return nil, err
+	}
+	var err error
+	if it.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if it.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &it, nil
+}
@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const personColumns = `id, name, contact, notes, created_at, updated_at`
+
+// ErrNameTaken is returned when a person is saved or renamed with the name
+// of someone else.
+var ErrNameTaken = errors.New("storage: name taken")
+
+// SavePerson inserts the person, or updates them if a person with the same
+// ID exists. Names are unique, compared without case; use RenamePerson to
+// change the name of someone already on trips.
+func (s *Store) SavePerson(ctx context.Context, p *models.Person) error {
+	if p.ID == "" {
+		p.ID = models.NewID()
+	}
+	now := time.Now()
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+	p.UpdatedAt = now
+	p.Name = strings.TrimSpace(p.Name)
+
+	if err := s.checkName(ctx, p.ID, p.Name); err != nil {
+		return err
+	}
+	_, err := s.exec(ctx, `
+INSERT INTO people (`+personColumns+`) VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	name = excluded.name,
+	contact = excluded.contact,
+	notes = excluded.notes,
+	updated_at = excluded.updated_at`,
+		p.ID, p.Name, p.Contact, p.Notes, formatTime(p.CreatedAt), formatTime(p.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save person: %w", err)
+	}
+	return nil
+}
+
+// checkName returns ErrNameTaken when someone other than the person with
+// id goes by name.
+func (s *Store) checkName(ctx context.Context, id, name string) error {
+	var other string
+	err := s.db.QueryRowContext(ctx, `SELECT id FROM people WHERE name = ? AND id <> ?`, name, id).Scan(&other)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return fmt.Errorf("storage: check name: %w", err)
+	}
+	return fmt.Errorf("%w: there is already someone called %s", ErrNameTaken, name)
+}
+
+// addPeople makes sure there is a person for each of names, such as the
+// companions of a trip just saved.
+func (s *Store) addPeople(ctx context.Context, names []string) error {
+	list, err := marshalJSON(names, "[]")
+	if err != nil {
+		return err
+	}
+	now := formatTime(time.Now())
+	_, err = s.exec(ctx, `INSERT INTO people (`+personColumns+`)
+SELECT lower(hex(randomblob(8))), value, '', '', ?, ? FROM json_each(?) WHERE true
+ON CONFLICT(name) DO NOTHING`, now, now, list)
+	return err
+}
+
+// GetPerson returns the person with the given ID.
+func (s *Store) GetPerson(ctx context.Context, id string) (*models.Person, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+personColumns+` FROM people WHERE id = ?`, id)
+	p, err := scanPerson(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get person: %w", err)
+	}
+	return p, nil
+}
+
+// GetPersonByName returns the person going by name, compared without
+// case.
+func (s *Store) GetPersonByName(ctx context.Context, name string) (*models.Person, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+personColumns+` FROM people WHERE name = ?`, strings.TrimSpace(name))
+	p, err := scanPerson(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get person: %w", err)
+	}
+	return p, nil
+}
+
+// ListPeople returns everyone ordered by name.
+func (s *Store) ListPeople(ctx context.Context) ([]*models.Person, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+personColumns+` FROM people ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list people: %w", err)
+	}
+	defer rows.Close()
+
+	var people []*models.Person
+	for rows.Next() {
+		p, err := scanPerson(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list people: %w", err)
+		}
+		people = append(people, p)
+	}
+	return people, rows.Err()
+}
+
+// DeletePerson removes a person's contact and notes. Trips, entries and
+// expenses naming them keep the name, and saving such a trip again brings
+// the person back without them.
+func (s *Store) DeletePerson(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM people WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete person: %w", err)
+	}
+	return expectAffected(res)
+}
+
+// RenamePerson renames p to name, and with them the companions of every
+// trip, the people of every entry and the payers and shares of every
+// expense that named them, in one transaction. Items in the trash keep
+// the old name.
+func (s *Store) RenamePerson(ctx context.Context, p *models.Person, name string) error {
+	name = strings.TrimSpace(name)
+	if err := s.checkName(ctx, p.ID, name); err != nil {
+		return err
+	}
+	// Someone who is no longer a person may still be named on trips.
+	var trip string
+	err := s.db.QueryRowContext(ctx, `SELECT title FROM trips
+WHERE EXISTS (SELECT 1 FROM json_each(trips.companions) WHERE value = ?1 COLLATE NOCASE)
+AND EXISTS (SELECT 1 FROM json_each(trips.companions) WHERE value = ?2 COLLATE NOCASE AND value <> ?1 COLLATE NOCASE)
+LIMIT 1`, p.Name, name).Scan(&trip)
+	if err == nil {
+		return fmt.Errorf("%w: %s travels on %q too", ErrNameTaken, name, trip)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("storage: rename person: %w", err)
+	}
+
+	now := time.Now()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: rename person: %w", err)
+	}
+	defer tx.Rollback()
+	old, at := p.Name, formatTime(now)
+	for _, q := range []string{
+		`UPDATE people SET name = ?2, updated_at = ?3 WHERE id = ?4`,
+		`UPDATE trips SET updated_at = ?3, companions = (
+	SELECT json_group_array(CASE WHEN value = ?1 COLLATE NOCASE THEN ?2 ELSE value END) FROM json_each(trips.companions)
+) WHERE EXISTS (SELECT 1 FROM json_each(trips.companions) WHERE value = ?1 COLLATE NOCASE)`,
+		`UPDATE entries SET updated_at = ?3, people = (
+	SELECT json_group_array(CASE WHEN value = ?1 COLLATE NOCASE THEN ?2 ELSE value END) FROM json_each(entries.people)
+) WHERE EXISTS (SELECT 1 FROM json_each(entries.people) WHERE value = ?1 COLLATE NOCASE)`,
+		`UPDATE expenses SET updated_at = ?3, paid_by = ?2 WHERE paid_by = ?1 COLLATE NOCASE`,
+		`UPDATE expenses SET updated_at = ?3, shares = (
+	SELECT json_group_array(CASE WHEN json_extract(value, '$.name') = ?1 COLLATE NOCASE
+		THEN json_set(value, '$.name', ?2) ELSE json(value) END)
+	FROM json_each(expenses.shares)
+) WHERE EXISTS (SELECT 1 FROM json_each(expenses.shares) WHERE json_extract(value, '$.name') = ?1 COLLATE NOCASE)`,
+	} {
+		if _, err := tx.ExecContext(ctx, q, old, name, at, p.ID); err != nil {
+			return fmt.Errorf("storage: rename person: %w", err)
+		}
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: rename person: %w", err)
+	}
+	p.Name, p.UpdatedAt = name, now
+	return nil
+}
+
+// ListTripsWith returns the trips name travels on as a companion, the
+// latest first.
+func (s *Store) ListTripsWith(ctx context.Context, name string) ([]*models.Trip, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+tripColumns+` FROM trips
+WHERE EXISTS (SELECT 1 FROM json_each(trips.companions) WHERE value = ? COLLATE NOCASE)
+ORDER BY start_date DESC`, name)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list trips with %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	var trips []*models.Trip
+	for rows.Next() {
+		t, err := scanTrip(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list trips with %s: %w", name, err)
+		}
+		trips = append(trips, t)
+	}
+	return trips, rows.Err()
+}
+
+// ListEntriesWith returns the entries written with name, in chronological
+// order.
+func (s *Store) ListEntriesWith(ctx context.Context, name string) ([]*models.Entry, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+entryColumns+` FROM entries
+WHERE EXISTS (SELECT 1 FROM json_each(entries.people) WHERE value = ? COLLATE NOCASE)
+ORDER BY timestamp`, name)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list entries with %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	var entries []*models.Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list entries with %s: %w", name, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func scanPerson(sc scanner) (*models.Person, error) {
+	var (
+		p            models.Person
+		created, upd string
+	)
+	if err := sc.Scan(&p.ID, &p.Name, &p.Contact, &p.Notes, &created, &upd); err != nil {
+		return nil, err
+	}
+	var err error
+	if p.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if p.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const prepTaskColumns = `id, trip_id, title, lead, done, created_at, updated_at`
+
+// SavePrepTask inserts the task, or updates it if one with the same ID
+// exists.
+func (s *Store) SavePrepTask(ctx context.Context, p *models.PrepTask) error {
+	touchPrepTask(p)
+	_, err := s.exec(ctx, `
+INSERT INTO prep_tasks (`+prepTaskColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	title = excluded.title,
+	lead = excluded.lead,
+	done = excluded.done,
+	updated_at = excluded.updated_at`, prepTaskArgs(p)...)
+	if err != nil {
+		return fmt.Errorf("storage: save prep task: %w", err)
+	}
+	return nil
+}
+
+// GetPrepTask returns the task with the given ID.
+func (s *Store) GetPrepTask(ctx context.Context, id string) (*models.PrepTask, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+prepTaskColumns+` FROM prep_tasks WHERE id = ?`, id)
+	p, err := scanPrepTask(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get prep task: %w", err)
+	}
+	return p, nil
+}
+
+// ListPrepTasksByTrip returns a trip's tasks to prepare it, those due
+// first first.
+func (s *Store) ListPrepTasksByTrip(ctx context.Context, tripID string) ([]*models.PrepTask, error) {
+	return s.listPrepTasks(ctx, `WHERE trip_id = ?`, tripID)
+}
+
+// ListPrepTasks returns the tasks of every trip, for the deadlines coming
+// up.
+func (s *Store) ListPrepTasks(ctx context.Context) ([]*models.PrepTask, error) {
+	return s.listPrepTasks(ctx, ``)
+}
+
+func (s *Store) listPrepTasks(ctx context.Context, where string, args ...any) ([]*models.PrepTask, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+prepTaskColumns+` FROM prep_tasks `+where+`
+ORDER BY lead DESC, created_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list prep tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*models.PrepTask
+	for rows.Next() {
+		p, err := scanPrepTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list prep tasks: %w", err)
+		}
+		tasks = append(tasks, p)
+	}
+	return tasks, rows.Err()
+}
+
+// DeletePrepTask removes a task.
+func (s *Store) DeletePrepTask(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM prep_tasks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete prep task: %w", err)
+	}
+	return expectAffected(res)
+}
+
+// AddPrepTasks gives trip the tasks of the templates for it, but those it
+// has already, and returns them.
+func (s *Store) AddPrepTasks(ctx context.Context, trip *models.Trip) ([]*models.PrepTask, error) {
+	templates, err := s.ListPrepTemplates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.ListPrepTasksByTrip(ctx, trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	tasks := models.PrepTasksFor(trip, templates, existing)
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("storage: add prep tasks: %w", err)
+	}
+	defer tx.Rollback()
+	for _, p := range tasks {
+		touchPrepTask(p)
+		if _, err := tx.ExecContext(ctx, `INSERT INTO prep_tasks (`+prepTaskColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			prepTaskArgs(p)...); err != nil {
+			return nil, fmt.Errorf("storage: add prep tasks: %w", err)
+		}
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("storage: add prep tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// SavePrepTemplate inserts the template, or updates it if one with the
+// same ID exists. Types are unique, compared without case.
+func (s *Store) SavePrepTemplate(ctx context.Context, t *models.PrepTemplate) error {
+	if t.ID == "" {
+		t.ID = models.NewID()
+	}
+	now := time.Now()
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	t.UpdatedAt = now
+
+	t.Type = strings.ToLower(strings.TrimSpace(t.Type))
+	tasks, err := marshalJSON(t.Tasks, "[]")
+	if err != nil {
+		return err
+	}
+	_, err = s.exec(ctx, `
+INSERT INTO prep_templates (id, type, tasks, created_at, updated_at) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	type = excluded.type,
+	tasks = excluded.tasks,
+	updated_at = excluded.updated_at`,
+		t.ID, t.Type, tasks, formatTime(t.CreatedAt), formatTime(t.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save prep template: %w", err)
+	}
+	return nil
+}
+
+// ListPrepTemplates returns every template by type, the one for every
+// trip first.
+func (s *Store) ListPrepTemplates(ctx context.Context) ([]*models.PrepTemplate, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, type, tasks, created_at, updated_at FROM prep_templates ORDER BY type`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list prep templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*models.PrepTemplate
+	for rows.Next() {
+		var (
+			t                   models.PrepTemplate
+			tasks, created, upd string
+		)
+		if err := rows.Scan(&t.ID, &t.Type, &tasks, &created, &upd); err != nil {
+			return nil, fmt.Errorf("storage: list prep templates: %w", err)
+		}
+		if err := json.Unmarshal([]byte(tasks), &t.Tasks); err != nil {
+			return nil, fmt.Errorf("storage: list prep templates: %w", err)
+		}
+		if t.CreatedAt, err = parseTime(created); err != nil {
+			return nil, err
+		}
+		if t.UpdatedAt, err = parseTime(upd); err != nil {
+			return nil, err
+		}
+		templates = append(templates, &t)
+	}
+	return templates, rows.Err()
+}
+
+// DeletePrepTemplate removes a template. Tasks already added to trips are
+// kept.
+func (s *Store) DeletePrepTemplate(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM prep_templates WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete prep template: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func touchPrepTask(p *models.PrepTask) {
+	if p.ID == "" {
+		p.ID = models.NewID()
+	}
+	now := time.Now()
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = now
+	}
+	p.UpdatedAt = now
+}
+
+func prepTaskArgs(p *models.PrepTask) []any {
+	return []any{p.ID, p.TripID, p.Title, p.Lead, p.Done, formatTime(p.CreatedAt), formatTime(p.UpdatedAt)}
+}
+
+func scanPrepTask(sc scanner) (*models.PrepTask, error) {
+	var (
+		p            models.PrepTask
+		created, upd string
+	)
+	if err := sc.Scan(&p.ID, &p.TripID, &p.Title, &p.Lead, &p.Done, &created, &upd); err != nil {
+		return nil, err
+	}
+	var err error
+	if p.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if p.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Query is what to search for: the words typed, narrowed by filters such
+// as trip:japan or after:2024-05-01. ParseQuery reads it from a search
+// box or the command line.
+type Query struct {
+	// Words are searched for in entries' titles, text and tags and in
+	// expenses' descriptions, notes and merchants.
+	Words string
+	// Trip is part of the title or of a destination of the trips searched.
+	Trip string
+	// Tags are what every result is tagged with.
+	Tags []string
+	// After and Before bound the results' dates: on or after After's day,
+	// before Before's. Either is zero for no bound.
+	After, Before time.Time
+	// Currency and Category narrow the search to expenses.
+	Currency string
+	Category string
+	// Location is part of where the results were written or spent.
+	Location string
+	// Kind is KindEntry or KindExpense to search only those, or "" for
+	// both.
+	Kind string
+}
+
+// Values of the is: filter.
+const (
+	KindEntry   = "entry"
+	KindExpense = "expense"
+)
+
+// Filter is a field:value filter a query can hold.
+type Filter struct {
+	Name string
+	// Value describes what the filter takes, as help shows it.
+	Value string
+	Usage string
+}
+
+// Filters are the filters ParseQuery knows, in the order help lists them.
+var Filters = []Filter{
+	{"trip", "text", "trips whose title or a destination contains text"},
+	{"tag", "tag", "tagged with tag; repeat for several"},
+	{"after", "YYYY-MM-DD", "on or after the day"},
+	{"before", "YYYY-MM-DD", "before the day"},
+	{"on", "YYYY-MM-DD", "on the day"},
+	{"in", "text", "written or spent at a location containing text"},
+	{"currency", "code", "expenses in the currency"},
+	{"category", "category", "expenses in the category"},
+	{"is", KindEntry + "|" + KindExpense, "only journal entries or only expenses"},
+}
+
+// FilterNames lists the names of Filters, as "trip:, tag:, …".
+func FilterNames() string {
+	names := make([]string, len(Filters))
+	for i, f := range Filters {
+		names[i] = f.Name + ":"
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseQuery reads a search typed as words and field:value filters, such
+// as "ramen trip:japan tag:food after:2024-05-01". A value with spaces is
+// quoted: trip:"new york". Only tag: may be given more than once; words
+// with a colon that are not filters are searched for when quoted.
+func ParseQuery(s string) (Query, error) {
+	var (
+		q     Query
+		words []string
+		seen  = map[string]bool{}
+	)
+	for _, tok := range tokenize(s) {
+		name, value, ok := splitFilter(tok)
+		if !ok {
+			words = append(words, strings.Trim(tok, `"`))
+			continue
+		}
+		if !knownFilter(name) {
+			return Query{}, fmt.Errorf("unknown filter %q; use %s, or quote %q to search for it", name+":", FilterNames(), tok)
+		}
+		if value == "" {
+			return Query{}, fmt.Errorf("%s: needs a value", name)
+		}
+		if seen[name] && name != "tag" {
+			return Query{}, fmt.Errorf("%s: is given twice", name)
+		}
+		seen[name] = true
+		if err := q.set(name, value); err != nil {
+			return Query{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if seen["on"] && (seen["after"] || seen["before"]) {
+		return Query{}, errors.New("on: cannot be combined with after: or before:")
+	}
+	if q.Kind == KindEntry && (q.Currency != "" || q.Category != "") {
+		return Query{}, fmt.Errorf("is:%s cannot be combined with currency: or category:, which only expenses have", KindEntry)
+	}
+	if !q.After.IsZero() && !q.Before.IsZero() && !q.After.Before(q.Before) {
+		return Query{}, errors.New("after: must be a day before before:")
+	}
+	q.Words = strings.Join(words, " ")
+	if strings.HasSuffix(s, " ") && q.Words != "" {
+		// ftsQuery matches a last word being typed as a prefix.
+		q.Words += " "
+	}
+	return q, nil
+}
+
+// set sets the filter called name to value.
+func (q *Query) set(name, value string) error {
+	var err error
+	switch name {
+	case "trip":
+		q.Trip = value
+	case "tag":
+		q.Tags = append(q.Tags, value)
+	case "after":
+		q.After, err = parseDay(value)
+	case "before":
+		q.Before, err = parseDay(value)
+	case "on":
+		if q.After, err = parseDay(value); err == nil {
+			q.Before = q.After.AddDate(0, 0, 1)
+		}
+	case "in":
+		q.Location = value
+	case "currency":
+		q.Currency, err = models.ParseCurrency(value)
+	case "category":
+		q.Category, err = models.ParseCategory(value)
+	case "is":
+		switch v := strings.ToLower(value); v {
+		case KindEntry, KindExpense:
+			q.Kind = v
+		case "entries":
+			q.Kind = KindEntry
+		case "expenses":
+			q.Kind = KindExpense
+		default:
+			err = fmt.Errorf("%q is not %s or %s", value, KindEntry, KindExpense)
+		}
+	}
+	return err
+}
+
+// Empty reports whether the query has neither words nor filters.
+func (q Query) Empty() bool {
+	return strings.TrimSpace(q.Words) == "" && q.Trip == "" && len(q.Tags) == 0 && q.After.IsZero() &&
+		q.Before.IsZero() && q.Currency == "" && q.Category == "" && q.Location == "" && q.Kind == ""
+}
+
+// Entries reports whether journal entries can match the query.
+func (q Query) Entries() bool {
+	return q.Kind != KindExpense && q.Currency == "" && q.Category == ""
+}
+
+// Expenses reports whether expenses can match the query.
+func (q Query) Expenses() bool {
+	return q.Kind != KindEntry
+}
+
+func parseDay(v string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is not a date as YYYY-MM-DD", v)
+	}
+	return t, nil
+}
+
+func knownFilter(name string) bool {
+	for _, f := range Filters {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFilter splits a token of the form name:value, where name is a word
+// of letters, and lower-cases the name. Quoted tokens are never filters.
+func splitFilter(tok string) (name, value string, ok bool) {
+	name, value, ok = strings.Cut(tok, ":")
+	if !ok || name == "" || strings.HasPrefix(tok, `"`) {
+		return "", "", false
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) {
+			return "", "", false
+		}
+	}
+	return strings.ToLower(name), strings.Trim(value, `"`), true
+}
+
+// tokenize splits s on spaces, keeping quoted text, such as the value of
+// trip:"new york", in one token.
+func tokenize(s string) []string {
+	var (
+		tokens []string
+		cur    strings.Builder
+		quoted bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			cur.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const recurrenceColumns = `id, trip_id, amount, currency, category, description, interval, start_date, end_date,
+	next_date, paused, created_at, updated_at`
+
+const insertRecurrence = `
+INSERT INTO recurrences (` + recurrenceColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	amount = excluded.amount,
+	currency = excluded.currency,
+	category = excluded.category,
+	description = excluded.description,
+	interval = excluded.interval,
+	start_date = excluded.start_date,
+	end_date = excluded.end_date,
+	next_date = excluded.next_date,
+	paused = excluded.paused,
+	updated_at = excluded.updated_at`
+
+// SaveRecurrence inserts the recurrence, or updates it if one with the
+// same ID exists.
+func (s *Store) SaveRecurrence(ctx context.Context, r *models.Recurrence) error {
+	if _, err := s.exec(ctx, insertRecurrence, recurrenceArgs(r)...); err != nil {
+		return fmt.Errorf("storage: save recurrence: %w", err)
+	}
+	return nil
+}
+
+// RecordRecurrence saves the expenses a recurrence fell due for together
+// with the recurrence, whose Next has moved past them, so no day is
+// recorded twice.
+func (s *Store) RecordRecurrence(ctx context.Context, r *models.Recurrence, expenses []*models.Expense) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: record recurrence: %w", err)
+	}
+	defer tx.Rollback()
+	for _, x := range expenses {
+		args, err := expenseArgs(x)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, insertExpense, args...); err != nil {
+			return fmt.Errorf("storage: record recurrence: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, insertRecurrence, recurrenceArgs(r)...); err != nil {
+		return fmt.Errorf("storage: record recurrence: %w", err)
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: record recurrence: %w", err)
+	}
+	for _, x := range expenses {
+		s.saved(ctx, Saved{Expense: x, Created: true})
+	}
+	return nil
+}
+
+// GetRecurrence returns the recurrence with the given ID.
+func (s *Store) GetRecurrence(ctx context.Context, id string) (*models.Recurrence, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+recurrenceColumns+` FROM recurrences WHERE id = ?`, id)
+	r, err := scanRecurrence(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get recurrence: %w", err)
+	}
+	return r, nil
+}
+
+// ListRecurrences returns every recurrence in the order they start.
+func (s *Store) ListRecurrences(ctx context.Context) ([]*models.Recurrence, error) {
+	return s.listRecurrences(ctx, `SELECT `+recurrenceColumns+` FROM recurrences ORDER BY start_date, description`)
+}
+
+// ListRecurrencesByTrip returns the recurrences of a trip, not those of
+// whichever trip is in progress, in the order they start.
+func (s *Store) ListRecurrencesByTrip(ctx context.Context, tripID string) ([]*models.Recurrence, error) {
+	return s.listRecurrences(ctx, `SELECT `+recurrenceColumns+` FROM recurrences WHERE trip_id = ?
+ORDER BY start_date, description`, tripID)
+}
+
+func (s *Store) listRecurrences(ctx context.Context, query string, args ...any) ([]*models.Recurrence, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list recurrences: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*models.Recurrence
+	for rows.Next() {
+		r, err := scanRecurrence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list recurrences: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// DeleteRecurrence removes a recurrence. The expenses recorded from it are
+// kept as ordinary expenses.
+func (s *Store) DeleteRecurrence(ctx context.Context, id string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("storage: delete recurrence: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `UPDATE expenses SET recurrence_id = '' WHERE recurrence_id = ?`, id); err != nil {
+		return fmt.Errorf("storage: delete recurrence: %w", err)
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM recurrences WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete recurrence: %w", err)
+	}
+	if err := expectAffected(res); err != nil {
+		return err
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return fmt.Errorf("storage: delete recurrence: %w", err)
+	}
+	return nil
+}
+
+// recurrenceArgs stamps r before saving and returns the values of its
+// columns.
+func recurrenceArgs(r *models.Recurrence) []any {
+	if r.ID == "" {
+		r.ID = models.NewID()
+	}
+	now := time.Now()
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = now
+	}
+	r.UpdatedAt = now
+	tripID := sql.NullString{String: r.TripID, Valid: r.TripID != ""}
+	var next sql.NullString
+	if !r.Next.IsZero() {
+		next = sql.NullString{String: formatTime(r.Next), Valid: true}
+	}
+	return []any{r.ID, tripID, r.Amount, r.Currency, r.Category, r.Description, r.Interval, formatTime(r.Start),
+		formatNullTime(r.End), next, r.Paused, formatTime(r.CreatedAt), formatTime(r.UpdatedAt)}
+}
+
+func scanRecurrence(sc scanner) (*models.Recurrence, error) {
+	var (
+		r                   models.Recurrence
+		tripID, end, next   sql.NullString
+		start, created, upd string
+	)
+	if err := sc.Scan(&r.ID, &tripID, &r.Amount, &r.Currency, &r.Category, &r.Description, &r.Interval, &start, &end,
+		&next, &r.Paused, &created, &upd); err != nil {
+		return nil, err
+	}
+	r.TripID = tripID.String
+	var err error
+	if r.Start, err = parseTime(start); err != nil {
+		return nil, err
+	}
+	if r.End, err = parseNullTime(end); err != nil {
+		return nil, err
+	}
+	if n, err := parseNullTime(next); err != nil {
+		return nil, err
+	} else if n != nil {
+		r.Next = *n
+	}
+	if r.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if r.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
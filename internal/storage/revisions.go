@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// MaxRevisions is how many earlier versions of an entry are kept; saving
+// one more drops the oldest.
+const MaxRevisions = 50
+
+const revisionColumns = `id, entry_id, title, text, location, tags, mood, saved_at`
+
+// saveRevision keeps the stored version of e as a revision when e, about
+// to replace it, changes its title, text, location, tags or mood.
+func saveRevision(ctx context.Context, tx *sql.Tx, e *models.Entry, tags string) error {
+	res, err := tx.ExecContext(ctx, `
+INSERT INTO revisions (`+revisionColumns+`)
+SELECT ?, id, title, text, location, tags, mood, updated_at FROM entries
+WHERE id = ? AND (title <> ? OR text <> ? OR location <> ? OR tags <> ? OR mood <> ?)`,
+		models.NewID(), e.ID, e.Title, e.Text, e.Location, tags, e.Mood)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+DELETE FROM revisions WHERE entry_id = ? AND id NOT IN (
+	SELECT id FROM revisions WHERE entry_id = ? ORDER BY saved_at DESC LIMIT ?
+)`, e.ID, e.ID, MaxRevisions)
+	return err
+}
+
+// ListRevisions returns the earlier versions of an entry, the most recent
+// first.
+func (s *Store) ListRevisions(ctx context.Context, entryID string) ([]*models.Revision, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+revisionColumns+` FROM revisions WHERE entry_id = ? ORDER BY saved_at DESC, id`, entryID)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*models.Revision
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list revisions: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// GetRevision returns the revision with the given ID.
+func (s *Store) GetRevision(ctx context.Context, id string) (*models.Revision, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+revisionColumns+` FROM revisions WHERE id = ?`, id)
+	r, err := scanRevision(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get revision: %w", err)
+	}
+	return r, nil
+}
+
+// RestoreRevision brings an entry back to the version kept as the
+// revision with the given ID and returns it. The version it replaces is
+// kept as a revision in turn, so a restore can itself be undone.
+func (s *Store) RestoreRevision(ctx context.Context, id string) (*models.Entry, error) {
+	r, err := s.GetRevision(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	e, err := s.GetEntry(ctx, r.EntryID)
+	if err != nil {
+		return nil, err
+	}
+	r.Apply(e)
+	if err := s.SaveEntry(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// saveRevisions stores revisions as they were, as restoring an entry from
+// the trash does.
+func (s *Store) saveRevisions(ctx context.Context, revisions []*models.Revision) error {
+	for _, r := range revisions {
+		tags, err := marshalTags(r.Tags)
+		if err != nil {
+			return err
+		}
+		if _, err := s.exec(ctx, `INSERT OR IGNORE INTO revisions (`+revisionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			r.ID, r.EntryID, r.Title, r.Text, r.Location, tags, r.Mood, formatTime(r.SavedAt)); err != nil {
+			return fmt.Errorf("storage: save revision: %w", err)
+		}
+	}
+	return nil
+}
+
+func scanRevision(sc scanner) (*models.Revision, error) {
+	var (
+		r           models.Revision
+		tags, saved string
+	)
+	if err := sc.Scan(&r.ID, &r.EntryID, &r.Title, &r.Text, &r.Location, &tags, &r.Mood, &saved); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &r.Tags); err != nil {
+		return nil, err
+	}
+	var err error
+	if r.SavedAt, err = parseTime(saved); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const ruleColumns = `id, match, category, trip_id, learned, created_at, updated_at`
+
+// SaveRule inserts the rule, or updates it if one with the same ID
+// exists. A rule matching the same text as another replaces it.
+func (s *Store) SaveRule(ctx context.Context, r *models.Rule) error {
+	if r.ID == "" {
+		r.ID = models.NewID()
+	}
+	now := time.Now()
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = now
+	}
+	r.UpdatedAt = now
+	r.Match = strings.ToLower(strings.TrimSpace(r.Match))
+
+	tripID := sql.NullString{String: r.TripID, Valid: r.TripID != ""}
+	_, err := s.exec(ctx, `
+INSERT OR REPLACE INTO rules (`+ruleColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.Match, r.Category, tripID, r.Learned, formatTime(r.CreatedAt), formatTime(r.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save rule: %w", err)
+	}
+	return nil
+}
+
+// GetRule returns the rule with the given ID.
+func (s *Store) GetRule(ctx context.Context, id string) (*models.Rule, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+ruleColumns+` FROM rules WHERE id = ?`, id)
+	r, err := scanRule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get rule: %w", err)
+	}
+	return r, nil
+}
+
+// ListRules returns every categorization rule ordered by the text it
+// matches.
+func (s *Store) ListRules(ctx context.Context) ([]*models.Rule, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+ruleColumns+` FROM rules ORDER BY match`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*models.Rule
+	for rows.Next() {
+		r, err := scanRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list rules: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteRule removes a rule.
+func (s *Store) DeleteRule(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete rule: %w", err)
+	}
+	return expectAffected(res)
+}
+
+// LearnRule remembers that expenses from merchant belong in category, so
+// the next statement import categorizes them alike. It returns the rule,
+// or nil when merchant names nothing a rule could match. A rule already
+// matching the merchant's name takes the new category.
+func (s *Store) LearnRule(ctx context.Context, merchant, category string) (*models.Rule, error) {
+	key := models.MerchantKey(merchant)
+	if key == "" {
+		return nil, nil
+	}
+	row := s.db.QueryRowContext(ctx, `SELECT `+ruleColumns+` FROM rules WHERE match = ?`, key)
+	r, err := scanRule(row)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		r = models.NewRule(key, category)
+	case err != nil:
+		return nil, fmt.Errorf("storage: learn rule: %w", err)
+	case r.Category == category:
+		return r, nil
+	}
+	r.Category, r.Learned = category, true
+	if err := s.SaveRule(ctx, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func scanRule(sc scanner) (*models.Rule, error) {
+	var (
+		r            models.Rule
+		tripID       sql.NullString
+		created, upd string
+	)
+	if err := sc.Scan(&r.ID, &r.Match, &r.Category, &tripID, &r.Learned, &created, &upd); err != nil {
+		return nil, err
+	}
+	r.TripID = tripID.String
+	var err error
+	if r.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if r.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// migrationBackupPrefix starts the names of the backups taken before a
+// migration, followed by the schema version they hold.
+const migrationBackupPrefix = "pre-migration-v"
+
+// ErrNewerSchema is returned when a database is from a newer version of
+// nomadic, whose schema this one does not know.
+var ErrNewerSchema = errors.New("storage: the database is from a newer version of nomadic")
+
+// Migration is a change of the database schema, named by the version it
+// brings the database to.
+type Migration struct {
+	Version int
+	Name    string
+}
+
+// LatestVersion is the schema version this version of nomadic brings every
+// database it opens to.
+func LatestVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// pending returns the migrations a database at version still needs.
+func pending(version int) []Migration {
+	var out []Migration
+	for _, m := range migrations {
+		if m.version > version {
+			out = append(out, Migration{Version: m.version, Name: m.name})
+		}
+	}
+	return out
+}
+
+// openUnmigrated opens the database in dir as it is, decrypting it with
+// passphrase when it is encrypted.
+func openUnmigrated(ctx context.Context, dir, passphrase string) (*Store, error) {
+	if !IsEncrypted(dir) {
+		return openPlain(dir)
+	}
+	if passphrase == "" {
+		return nil, ErrEncrypted
+	}
+	return openEncrypted(ctx, dir, passphrase)
+}
+
+// SchemaStatus reports the schema version of the database in dir and the
+// migrations opening it would apply, without applying them. An encrypted
+// database is read with passphrase. A directory without a database is at
+// version 0, with every migration pending.
+func SchemaStatus(ctx context.Context, dir, passphrase string) (int, []Migration, error) {
+	if !hasDatabase(dir) {
+		return 0, pending(0), nil
+	}
+	s, err := openUnmigrated(ctx, dir, passphrase)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer s.Close()
+	version, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("storage: read schema version: %w", err)
+	}
+	return version, pending(version), nil
+}
+
+// DryRunMigrations applies the migrations pending for the database in dir
+// to a copy of it held in memory, then checks the integrity of the copy,
+// leaving the database itself as it is. It returns the migrations that
+// applied; on error, the one after the last of them failed.
+func DryRunMigrations(ctx context.Context, dir, passphrase string) ([]Migration, error) {
+	var image []byte
+	if hasDatabase(dir) {
+		s, err := openUnmigrated(ctx, dir, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		if s.sealer != nil {
+			// Already a copy in memory; unsealed, it is never written back.
+			s.sealer = nil
+			defer s.Close()
+			return s.dryRun(ctx)
+		}
+		db, err := s.snapshot(ctx)
+		s.Close()
+		if err != nil {
+			return nil, fmt.Errorf("storage: copy the database: %w", err)
+		}
+		image = db.data
+	}
+	s, err := openMemory(ctx, image)
+	if err != nil {
+		return nil, fmt.Errorf("storage: copy the database: %w", err)
+	}
+	defer s.Close()
+	return s.dryRun(ctx)
+}
+
+func (s *Store) dryRun(ctx context.Context) ([]Migration, error) {
+	applied, _, err := s.migrateTo(ctx, LatestVersion(), false)
+	if err != nil {
+		return applied, err
+	}
+	return applied, s.checkIntegrity(ctx)
+}
+
+// checkIntegrity runs SQLite's integrity and foreign key checks, returning
+// the first problem they find.
+func (s *Store) checkIntegrity(ctx context.Context) error {
+	var result string
+	if err := s.db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("storage: integrity check: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("storage: integrity check: %s", result)
+	}
+	var (
+		table, parent string
+		rowid, fkid   any
+	)
+	err := s.db.QueryRowContext(ctx, `PRAGMA foreign_key_check`).Scan(&table, &rowid, &parent, &fkid)
+	if err == nil {
+		return fmt.Errorf("storage: foreign key check: a row of %s refers to a missing row of %s", table, parent)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("storage: foreign key check: %w", err)
+	}
+	return nil
+}
+
+// Migrate brings the database in dir up to date, as opening it does, and
+// returns the migrations it applied with the path of the backup taken
+// before, if any. An encrypted database is opened with passphrase.
+func Migrate(ctx context.Context, dir, passphrase string) ([]Migration, string, error) {
+	s, err := openUnmigrated(ctx, dir, passphrase)
+	if err != nil {
+		return nil, "", err
+	}
+	defer s.Close()
+	return s.migrateTo(ctx, LatestVersion(), true)
+}
+
+// MigrationBackups returns the paths of the backups taken in dir before a
+// migration, newest first.
+func MigrationBackups(dir string) ([]string, error) {
+	backups := filepath.Join(dir, BackupsDir)
+	entries, err := os.ReadDir(backups)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: list backups: %w", err)
+	}
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	var found []backup
+	for _, e := range entries {
+		name := e.Name()
+		if !e.Type().IsRegular() || !strings.HasPrefix(name, migrationBackupPrefix) || !strings.HasSuffix(name, BackupExt) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("storage: list backups: %w", err)
+		}
+		found = append(found, backup{filepath.Join(backups, name), info.ModTime()})
+	}
+	slices.SortFunc(found, func(a, b backup) int { return b.modTime.Compare(a.modTime) })
+	paths := make([]string, len(found))
+	for i, b := range found {
+		paths[i] = b.path
+	}
+	return paths, nil
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Markers delimiting the matched terms in EntryMatch.Snippet.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// EntryMatch is a journal entry found by SearchEntries.
+type EntryMatch struct {
+	Entry     *models.Entry
+	TripTitle string
+	// Snippet is an excerpt around the match, with matched terms wrapped
+	// in HighlightStart and HighlightEnd.
+	Snippet string
+}
+
+// ExpenseMatch is an expense found by SearchExpenses.
+type ExpenseMatch struct {
+	Expense   *models.Expense
+	TripTitle string
+}
+
+// SearchEntries finds journal entries matching q: best matches first when
+// it has words, whose last also matches as a prefix so results can follow
+// the user's typing, and newest first when it only has filters.
+func (s *Store) SearchEntries(ctx context.Context, q Query, limit int) ([]EntryMatch, error) {
+	if q.Empty() || !q.Entries() {
+		return nil, nil
+	}
+	conds, args, err := s.searchConds(ctx, q, "e")
+	if err != nil {
+		return nil, err
+	}
+	cols := prefixColumns("e", entryColumns) + `, t.title`
+	var query string
+	if match := ftsQuery(q.Words); match != "" {
+		conds = append([]string{`entries_fts MATCH ?`}, conds...)
+		args = append([]any{match}, args...)
+		query = `SELECT ` + cols + `,
+	snippet(entries_fts, -1, '` + HighlightStart + `', '` + HighlightEnd + `', '…', 16)
+FROM entries_fts
+JOIN entries e ON e.rowid = entries_fts.rowid
+JOIN trips t ON t.id = e.trip_id
+WHERE ` + strings.Join(conds, " AND ") + `
+ORDER BY bm25(entries_fts, 10.0, 1.0, 5.0), e.timestamp DESC
+LIMIT ?`
+	} else {
+		query = `SELECT ` + cols + `, substr(e.text, 1, 120)
+FROM entries e
+JOIN trips t ON t.id = e.trip_id
+WHERE ` + strings.Join(conds, " AND ") + `
+ORDER BY e.timestamp DESC
+LIMIT ?`
+	}
+	rows, err := s.db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: search entries: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []EntryMatch
+	for rows.Next() {
+		var m EntryMatch
+		if m.Entry, err = scanEntry(withExtra(rows, &m.TripTitle, &m.Snippet)); err != nil {
+			return nil, fmt.Errorf("storage: search entries: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// SearchExpenses finds expenses matching q, newest first. Each of its
+// words is looked for in the description, note and merchant.
+func (s *Store) SearchExpenses(ctx context.Context, q Query, limit int) ([]ExpenseMatch, error) {
+	if q.Empty() || !q.Expenses() {
+		return nil, nil
+	}
+	conds, args, err := s.searchConds(ctx, q, "x")
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range strings.Fields(q.Words) {
+		conds = append(conds, `(instr(lower(x.description), lower(?)) > 0 OR instr(lower(x.note), lower(?)) > 0
+	OR instr(lower(x.merchant), lower(?)) > 0)`)
+		args = append(args, w, w, w)
+	}
+	if q.Currency != "" {
+		conds = append(conds, `x.currency = ?`)
+		args = append(args, q.Currency)
+	}
+	if q.Category != "" {
+		conds = append(conds, `x.category = ?`)
+		args = append(args, q.Category)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+SELECT `+prefixColumns("x", expenseColumns)+`, t.title
+FROM expenses x
+JOIN trips t ON t.id = x.trip_id
+WHERE `+strings.Join(conds, " AND ")+`
+ORDER BY x.timestamp DESC
+LIMIT ?`, append(args, limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: search expenses: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []ExpenseMatch
+	for rows.Next() {
+		var m ExpenseMatch
+		if m.Expense, err = scanExpense(withExtra(rows, &m.TripTitle)); err != nil {
+			return nil, fmt.Errorf("storage: search expenses: %w", err)
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// searchConds returns the conditions on the table aliased alias, entries
+// or expenses, of the filters both have, and their parameters. It is
+// never empty, so it can always follow WHERE.
+func (s *Store) searchConds(ctx context.Context, q Query, alias string) ([]string, []any, error) {
+	conds, args := []string{`1`}, []any{}
+	if q.Trip != "" {
+		ids, err := s.tripsMatching(ctx, q.Trip)
+		if err != nil {
+			return nil, nil, err
+		}
+		conds = append(conds, alias+`.trip_id IN (?`+strings.Repeat(`, ?`, len(ids)-1)+`)`)
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	if tags := models.NormalizeTags(q.Tags); len(tags) > 0 {
+		conds = append(conds, tagFilter(alias+".tags", len(tags)))
+		for _, t := range tags {
+			args = append(args, t)
+		}
+	}
+	if !q.After.IsZero() {
+		conds = append(conds, alias+`.timestamp >= ?`)
+		args = append(args, formatTime(q.After))
+	}
+	if !q.Before.IsZero() {
+		conds = append(conds, alias+`.timestamp < ?`)
+		args = append(args, formatTime(q.Before))
+	}
+	if q.Location != "" {
+		conds = append(conds, `instr(lower(`+alias+`.location), lower(?)) > 0`)
+		args = append(args, q.Location)
+	}
+	return conds, args, nil
+}
+
+// tripsMatching returns the IDs of the trips whose title or a destination
+// contains text, ignoring case, and an error when there are none.
+func (s *Store) tripsMatching(ctx context.Context, text string) ([]string, error) {
+	trips, err := s.ListTrips(ctx)
+	if err != nil {
+		return nil, err
+	}
+	text = strings.ToLower(text)
+	var ids []string
+	for _, t := range trips {
+		match := strings.Contains(strings.ToLower(t.Title), text)
+		for _, l := range t.Locations {
+			match = match || strings.Contains(strings.ToLower(l), text)
+		}
+		if match {
+			ids = append(ids, t.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("trip:%s matches no trip's title or destinations", text)
+	}
+	return ids, nil
+}
+
+// ftsQuery turns free text into an FTS5 query matching every word, so
+// punctuation typed by the user is never parsed as query syntax.
+func ftsQuery(q string) string {
+	words := strings.Fields(q)
+	for i, w := range words {
+		words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+	}
+	if n := len(words); n > 0 && !strings.HasSuffix(q, " ") {
+		words[n-1] += "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// prefixColumns qualifies a comma separated column list with a table alias.
+func prefixColumns(alias, columns string) string {
+	cols := strings.Split(columns, ",")
+	for i, c := range cols {
+		cols[i] = alias + "." + strings.TrimSpace(c)
+	}
+	return strings.Join(cols, ", ")
+}
+
+// extraScanner appends destinations for columns selected after a model's own.
+type extraScanner struct {
+	scanner
+	extra []any
+}
+
+func withExtra(sc scanner, extra ...any) scanner {
+	return extraScanner{sc, extra}
+}
+
+func (e extraScanner) Scan(dest ...any) error {
+	return e.scanner.Scan(append(dest, e.extra...)...)
+}
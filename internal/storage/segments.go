@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const segmentColumns = `id, trip_id, mode, origin, destination, distance, date, notes, created_at`
+
+// SaveSegment inserts the transport segment, or updates it if one with the
+// same ID exists.
+func (s *Store) SaveSegment(ctx context.Context, seg *models.Segment) error {
+	if seg.ID == "" {
+		seg.ID = models.NewID()
+	}
+	if seg.CreatedAt.IsZero() {
+		seg.CreatedAt = time.Now()
+	}
+	_, err := s.exec(ctx, `
+INSERT INTO segments (`+segmentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	mode = excluded.mode,
+	origin = excluded.origin,
+	destination = excluded.destination,
+	distance = excluded.distance,
+	date = excluded.date,
+	notes = excluded.notes`,
+		seg.ID, seg.TripID, seg.Mode, seg.Origin, seg.Destination, seg.Distance, formatTime(seg.Date), seg.Notes,
+		formatTime(seg.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save segment: %w", err)
+	}
+	return nil
+}
+
+// GetSegment returns the transport segment with the given ID.
+func (s *Store) GetSegment(ctx context.Context, id string) (*models.Segment, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+segmentColumns+` FROM segments WHERE id = ?`, id)
+	seg, err := scanSegment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get segment: %w", err)
+	}
+	return seg, nil
+}
+
+// ListSegmentsByTrip returns a trip's transport segments in the order they
+// were traveled.
+func (s *Store) ListSegmentsByTrip(ctx context.Context, tripID string) ([]*models.Segment, error) {
+	return s.listSegments(ctx, `WHERE trip_id = ?`, tripID)
+}
+
+// ListSegments returns the transport segments of every trip.
+func (s *Store) ListSegments(ctx context.Context) ([]*models.Segment, error) {
+	return s.listSegments(ctx, ``)
+}
+
+func (s *Store) listSegments(ctx context.Context, where string, args ...any) ([]*models.Segment, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+segmentColumns+` FROM segments `+where+` ORDER BY date, created_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list segments: %w", err)
+	}
+	defer rows.Close()
+
+	var segments []*models.Segment
+	for rows.Next() {
+		seg, err := scanSegment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list segments: %w", err)
+		}
+		segments = append(segments, seg)
+	}
+	return segments, rows.Err()
+}
+
+// DeleteSegment removes a transport segment.
+func (s *Store) DeleteSegment(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM segments WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete segment: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanSegment(sc scanner) (*models.Segment, error) {
+	var (
+		seg           models.Segment
+		date, created string
+	)
+	if err := sc.Scan(&seg.ID, &seg.TripID, &seg.Mode, &seg.Origin, &seg.Destination, &seg.Distance, &date,
+		&seg.Notes, &created); err != nil {
+		return nil, err
+	}
+	var err error
+	if seg.Date, err = parseTime(date); err != nil {
+		return nil, err
+	}
+	if seg.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	return &seg, nil
+}
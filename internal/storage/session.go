@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// SessionFileName is the file inside the data directory holding where the
+// TUI was left when it last closed. Like drafts it is encrypted with an
+// encrypted store, and belongs to the device: saving it is not a change to
+// the data, and it is not synced or backed up.
+const SessionFileName = "session.json"
+
+// SaveSession saves where the TUI was left, replacing what was saved
+// before.
+func (s *Store) SaveSession(sess *models.Session) error {
+	data, err := json.Marshal(sess)
+	if err != nil {
+		return fmt.Errorf("storage: save session: %w", err)
+	}
+	if s.sealer != nil {
+		if data, err = s.sealer.seal(data); err != nil {
+			return fmt.Errorf("storage: save session: %w", err)
+		}
+	}
+	if err := writeFileAtomic(filepath.Join(s.dir, SessionFileName), data); err != nil {
+		return fmt.Errorf("storage: save session: %w", err)
+	}
+	return nil
+}
+
+// LoadSession returns where the TUI was left when it last closed, or nil
+// when nothing was saved or it cannot be read, such as after the
+// passphrase changed.
+func (s *Store) LoadSession() (*models.Session, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, SessionFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: load session: %w", err)
+	}
+	if s.sealer != nil {
+		if data, err = s.sealer.open(data); err != nil {
+			return nil, nil
+		}
+	}
+	var sess models.Session
+	if err := json.Unmarshal(data, &sess); err != nil {
+		return nil, nil
+	}
+	return &sess, nil
+}
+
+// ClearSession forgets where the TUI was left.
+func (s *Store) ClearSession() error {
+	err := os.Remove(filepath.Join(s.dir, SessionFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: clear session: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+// Package storage persists trips, journal entries and expenses in SQLite,
+// and the files attached to entries alongside the database.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// FileName is the name of the database file inside the data directory.
+const FileName = "nomadic.db"
+
+// ErrNotFound is returned when a requested record does not exist.
+var ErrNotFound = errors.New("storage: not found")
+
+// Unavailable reports whether err is a failure of the database itself
+// rather than of what was asked of it: the disk is full or failing, the
+// file is damaged or was taken away, or another process kept it locked.
+// Asking again is unlikely to work until that is seen to.
+func Unavailable(err error) bool {
+	if errors.Is(err, sql.ErrConnDone) || errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EIO) ||
+		errors.Is(err, syscall.EROFS) {
+		return true
+	}
+	var serr *sqlite.Error
+	if !errors.As(err, &serr) {
+		return false
+	}
+	// Extended result codes keep the primary one in their low byte.
+	switch serr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED, sqlite3.SQLITE_IOERR, sqlite3.SQLITE_CORRUPT, sqlite3.SQLITE_FULL,
+		sqlite3.SQLITE_CANTOPEN, sqlite3.SQLITE_NOTADB:
+		return true
+	}
+	return false
+}
+
+// Store is a SQLite-backed repository for all nomadic data.
+type Store struct {
+	db   *sql.DB
+	dir  string
+	path string
+
+	// sealer is set for encrypted stores, whose database is held in
+	// memory and written back encrypted after every change.
+	sealer *sealer
+	// changes counts the writes made through this store.
+	changes atomic.Uint64
+	// persistMu keeps two writes to an encrypted store from writing it
+	// back at once.
+	persistMu sync.Mutex
+	// lock is held on the data directory while the store is open; nil for
+	// stores held only in memory.
+	lock *dirLock
+	// observer is told of the trips, entries and expenses saved.
+	observer func(context.Context, Saved)
+	// notes is set when the store keeps its entries as notes too.
+	notes *noteFolder
+}
+
+// DefaultDir returns the directory nomadic keeps its data in, honouring
+// $XDG_DATA_HOME and falling back to ~/.local/share/nomadic.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "nomadic"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "nomadic"), nil
+}
+
+// ProfileDir returns the directory the named profile keeps its data in
+// unless set otherwise: beside DefaultDir, as nomadic-work for the work
+// profile, so that syncing or backing up one journal leaves the others out.
+func ProfileDir(profile string) (string, error) {
+	dir, err := DefaultDir()
+	if err != nil {
+		return "", err
+	}
+	return dir + "-" + profile, nil
+}
+
+// Open opens (creating if necessary) the database in dir and brings its
+// schema up to date. It returns ErrEncrypted when the database is
+// encrypted; use OpenEncrypted for those.
+func Open(ctx context.Context, dir string) (*Store, error) {
+	s, err := openPlain(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.migrate(ctx); err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// openPlain opens the plain database in dir as it is, without migrating.
+func openPlain(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("storage: create data dir: %w", err)
+	}
+	if IsEncrypted(dir) {
+		return nil, ErrEncrypted
+	}
+	// SQLite lets processes share a database file; the shared lock keeps
+	// out those replacing it, such as a restore.
+	lock, err := lockDir(dir, false)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, FileName)
+	dsn := "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		lock.release()
+		return nil, fmt.Errorf("storage: open %s: %w", path, err)
+	}
+	// SQLite serialises writers anyway; a single connection keeps the
+	// pragmas above in effect for every statement.
+	db.SetMaxOpenConns(1)
+
+	return &Store{db: db, dir: dir, path: path, lock: lock}, nil
+}
+
+// Path returns the location of the database file, encrypted or not.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Changes returns how many writes have been made through the store, so
+// callers can tell whether anything changed since they last looked.
+func (s *Store) Changes() uint64 {
+	return s.changes.Load()
+}
+
+// Checkpoint copies the write-ahead log into the database file, so the
+// file on its own holds every change, e.g. before it is committed to git.
+func (s *Store) Checkpoint(ctx context.Context) error {
+	if s.sealer != nil {
+		// Encrypted stores are written whole after every change.
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return fmt.Errorf("storage: checkpoint: %w", err)
+	}
+	return nil
+}
+
+// exec runs a statement that changes data, then persists the store.
+func (s *Store) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		slog.Warn("storage: write failed", "query", firstLine(query), "err", err)
+		return nil, err
+	}
+	s.changes.Add(1)
+	slog.Debug("storage: write", "query", firstLine(query), "took", time.Since(start))
+	return res, s.persist(ctx)
+}
+
+// commit commits tx, then persists the store.
+func (s *Store) commit(ctx context.Context, tx *sql.Tx) error {
+	start := time.Now()
+	if err := tx.Commit(); err != nil {
+		slog.Warn("storage: commit failed", "err", err)
+		return err
+	}
+	s.changes.Add(1)
+	slog.Debug("storage: commit", "took", time.Since(start))
+	return s.persist(ctx)
+}
+
+// firstLine is the start of a statement, enough to tell it in the log.
+func firstLine(query string) string {
+	query = strings.TrimSpace(query)
+	if i := strings.IndexByte(query, '\n'); i >= 0 {
+		query = strings.TrimSpace(query[:i])
+	}
+	return query
+}
+
+// ReadOnly makes every later write to the database fail, for browsing the
+// journal without any chance of changing it.
+func (s *Store) ReadOnly(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		return fmt.Errorf("storage: read only: %w", err)
+	}
+	return nil
+}
+
+// Close releases the underlying database handle and the lock on the data
+// directory.
+func (s *Store) Close() error {
+	err := s.db.Close()
+	if lerr := s.lock.release(); err == nil {
+		err = lerr
+	}
+	return err
+}
+
+const timeLayout = time.RFC3339Nano
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// parseTime reads a stored timestamp back in the local zone so calendar
+// dates round-trip unchanged.
+func parseTime(s string) (time.Time, error) {
+	t, err := time.Parse(timeLayout, s)
+	return t.Local(), err
+}
+
+func formatNullTime(t *time.Time) sql.NullString {
+	if t == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(*t), Valid: true}
+}
+
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// scanner abstracts over *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
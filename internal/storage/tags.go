@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Tag is a tag in use, with how many records carry it.
+type Tag struct {
+	Name     string
+	Trips    int
+	Entries  int
+	Expenses int
+}
+
+// Total is the number of records tagged.
+func (t Tag) Total() int { return t.Trips + t.Entries + t.Expenses }
+
+// Tagged is every record carrying a tag.
+type Tagged struct {
+	Trips    []*models.Trip
+	Entries  []*models.Entry
+	Expenses []*models.Expense
+}
+
+// ListTags returns every tag used on a trip, entry or expense, most used
+// first.
+func (s *Store) ListTags(ctx context.Context) ([]Tag, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT value,
+	count(*) FILTER (WHERE kind = 'trip'),
+	count(*) FILTER (WHERE kind = 'entry'),
+	count(*) FILTER (WHERE kind = 'expense')
+FROM (
+	SELECT 'trip' AS kind, j.value FROM trips, json_each(trips.tags) j
+	UNION ALL
+	SELECT 'entry', j.value FROM entries, json_each(entries.tags) j
+	UNION ALL
+	SELECT 'expense', j.value FROM expenses, json_each(expenses.tags) j
+)
+GROUP BY value
+ORDER BY count(*) DESC, value`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []Tag
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.Name, &t.Trips, &t.Entries, &t.Expenses); err != nil {
+			return nil, fmt.Errorf("storage: list tags: %w", err)
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// ListTagged returns the trips, entries and expenses carrying every one of
+// tags, each in the order of their usual listing.
+func (s *Store) ListTagged(ctx context.Context, tags []string) (*Tagged, error) {
+	tags = models.NormalizeTags(tags)
+	if len(tags) == 0 {
+		return &Tagged{}, nil
+	}
+	var t Tagged
+	err := s.queryTagged(ctx, `SELECT `+tripColumns+` FROM trips WHERE `+tagFilter("trips.tags", len(tags))+
+		` ORDER BY start_date DESC`, tags, func(sc scanner) error {
+		trip, err := scanTrip(sc)
+		if err == nil {
+			t.Trips = append(t.Trips, trip)
+		}
+		return err
+	})
+	if err == nil {
+		err = s.queryTagged(ctx, `SELECT `+entryColumns+` FROM entries WHERE `+tagFilter("entries.tags", len(tags))+
+			` ORDER BY timestamp DESC`, tags, func(sc scanner) error {
+			e, err := scanEntry(sc)
+			if err == nil {
+				t.Entries = append(t.Entries, e)
+			}
+			return err
+		})
+	}
+	if err == nil {
+		err = s.queryTagged(ctx, `SELECT `+expenseColumns+` FROM expenses WHERE `+tagFilter("expenses.tags", len(tags))+
+			` ORDER BY timestamp DESC`, tags, func(sc scanner) error {
+			x, err := scanExpense(sc)
+			if err == nil {
+				t.Expenses = append(t.Expenses, x)
+			}
+			return err
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: list tagged: %w", err)
+	}
+	return &t, nil
+}
+
+func (s *Store) queryTagged(ctx context.Context, query string, tags []string, scan func(scanner) error) error {
+	args := make([]any, len(tags))
+	for i, t := range tags {
+		args[i] = t
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// tagFilter matches rows whose JSON tags column holds n tags bound as
+// parameters.
+func tagFilter(column string, n int) string {
+	conds := make([]string, n)
+	for i := range conds {
+		conds[i] = `EXISTS (SELECT 1 FROM json_each(` + column + `) WHERE value = ?)`
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// marshalTags encodes tags for a JSON tags column.
+func marshalTags(tags []string) (string, error) {
+	if len(tags) == 0 {
+		return "[]", nil
+	}
+	b, err := json.Marshal(tags)
+	return string(b), err
+}
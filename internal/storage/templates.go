@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const templateColumns = `id, name, locations, days, budget, budget_currency, category_budgets, notes, tags,
+	packing, itinerary, legs, created_at, updated_at`
+
+// SaveTemplate inserts the template, or updates it if a template with the
+// same ID exists. Template names are unique, compared without case.
+func (s *Store) SaveTemplate(ctx context.Context, t *models.Template) error {
+	if t.ID == "" {
+		t.ID = models.NewID()
+	}
+	now := time.Now()
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	t.UpdatedAt = now
+
+	t.Tags = models.NormalizeTags(t.Tags)
+	tags, err := marshalTags(t.Tags)
+	if err != nil {
+		return err
+	}
+	locations, err := marshalJSON(t.Locations, "[]")
+	if err != nil {
+		return err
+	}
+	categoryBudgets, err := marshalJSON(t.CategoryBudgets, "{}")
+	if err != nil {
+		return err
+	}
+	packing, err := marshalJSON(t.Packing, "[]")
+	if err != nil {
+		return err
+	}
+	itinerary, err := marshalJSON(t.Itinerary, "[]")
+	if err != nil {
+		return err
+	}
+	legs, err := marshalJSON(t.Legs, "[]")
+	if err != nil {
+		return err
+	}
+	_, err = s.exec(ctx, `
+INSERT INTO templates (`+templateColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	name = excluded.name,
+	locations = excluded.locations,
+	days = excluded.days,
+	budget = excluded.budget,
+	budget_currency = excluded.budget_currency,
+	category_budgets = excluded.category_budgets,
+	notes = excluded.notes,
+	tags = excluded.tags,
+	packing = excluded.packing,
+	itinerary = excluded.itinerary,
+	legs = excluded.legs,
+	updated_at = excluded.updated_at`,
+		t.ID, t.Name, locations, t.Days, t.Budget, t.BudgetCurrency, categoryBudgets, t.Notes, tags,
+		packing, itinerary, legs, formatTime(t.CreatedAt), formatTime(t.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save template: %w", err)
+	}
+	return nil
+}
+
+// GetTemplate returns the template with the given ID.
+func (s *Store) GetTemplate(ctx context.Context, id string) (*models.Template, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+templateColumns+` FROM templates WHERE id = ?`, id)
+	t, err := scanTemplate(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get template: %w", err)
+	}
+	return t, nil
+}
+
+// ListTemplates returns every template ordered by name.
+func (s *Store) ListTemplates(ctx context.Context) ([]*models.Template, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+templateColumns+` FROM templates ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*models.Template
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list templates: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// DeleteTemplate removes a template. Trips created from it are kept.
+func (s *Store) DeleteTemplate(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM templates WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete template: %w", err)
+	}
+	return expectAffected(res)
+}
+
+// SaveTripPlan saves a new trip followed by its legs, itinerary and
+// packing list. A trip yet to leave is given the tasks of the prep
+// templates for it too.
+func (s *Store) SaveTripPlan(ctx context.Context, p *models.TripPlan) error {
+	if err := s.SaveTrip(ctx, p.Trip); err != nil {
+		return err
+	}
+	for _, l := range p.Legs {
+		l.TripID = p.Trip.ID
+		if err := s.SaveLeg(ctx, l); err != nil {
+			return err
+		}
+	}
+	for _, it := range p.Itinerary {
+		it.TripID = p.Trip.ID
+		if err := s.SaveItineraryItem(ctx, it); err != nil {
+			return err
+		}
+	}
+	for _, it := range p.Packing {
+		it.TripID = p.Trip.ID
+		if err := s.SavePackingItem(ctx, it); err != nil {
+			return err
+		}
+	}
+	if p.Trip.StartDate.After(time.Now()) {
+		if _, err := s.AddPrepTasks(ctx, p.Trip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CloneTrip copies the trip with the given ID to a new trip called title
+// starting on start. Its end date, legs and itinerary move along with the
+// start date and its packing list starts out unpacked; journal entries,
+// expenses and tracks stay with the original.
+func (s *Store) CloneTrip(ctx context.Context, id, title string, start time.Time) (*models.Trip, error) {
+	trip, err := s.GetTrip(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.ListItineraryByTrip(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	packing, err := s.ListPackingByTrip(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	legs, err := s.ListLegsByTrip(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	plan := models.NewTemplate(trip.Title, trip, items, packing, legs).NewTrip(title, start)
+	if err := s.SaveTripPlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan.Trip, nil
+}
+
+// marshalJSON encodes v for a JSON column, storing empty as the encoding of
+// a nil value.
+func marshalJSON(v any, empty string) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if string(b) == "null" {
+		return empty, nil
+	}
+	return string(b), nil
+}
+
+func scanTemplate(sc scanner) (*models.Template, error) {
+	var (
+		t                                     models.Template
+		locations, budgets, tags, packing, it string
+		legs, created, upd                    string
+	)
+	if err := sc.Scan(&t.ID, &t.Name, &locations, &t.Days, &t.Budget, &t.BudgetCurrency, &budgets, &t.Notes, &tags,
+		&packing, &it, &legs, &created, &upd); err != nil {
+		return nil, err
+	}
+	for _, c := range []struct {
+		data string
+		dst  any
+	}{
+		{locations, &t.Locations},
+		{budgets, &t.CategoryBudgets},
+		{tags, &t.Tags},
+		{packing, &t.Packing},
+		{it, &t.Itinerary},
+		{legs, &t.Legs},
+	} {
+		if err := json.Unmarshal([]byte(c.data), c.dst); err != nil {
+			return nil, err
+		}
+	}
+	if len(t.CategoryBudgets) == 0 {
+		t.CategoryBudgets = nil
+	}
+	var err error
+	if t.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if t.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
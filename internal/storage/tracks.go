@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const trackColumns = `id, trip_id, entry_id, name, distance, ascent, descent, points, started_at, ended_at, place, path, created_at`
+
+// SaveTrack inserts the track, or updates it if one with the same ID exists.
+func (s *Store) SaveTrack(ctx context.Context, t *models.Track) error {
+	if t.ID == "" {
+		t.ID = models.NewID()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	entryID := sql.NullString{String: t.EntryID, Valid: t.EntryID != ""}
+	path, err := marshalJSON(t.Path, "[]")
+	if err != nil {
+		return fmt.Errorf("storage: save track: %w", err)
+	}
+
+	_, err = s.exec(ctx, `
+INSERT INTO tracks (`+trackColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	trip_id = excluded.trip_id,
+	entry_id = excluded.entry_id,
+	name = excluded.name,
+	distance = excluded.distance,
+	ascent = excluded.ascent,
+	descent = excluded.descent,
+	points = excluded.points,
+	started_at = excluded.started_at,
+	ended_at = excluded.ended_at,
+	place = excluded.place,
+	path = excluded.path`,
+		t.ID, t.TripID, entryID, t.Name, t.Distance, t.Ascent, t.Descent, t.Points,
+		formatNullTime(t.StartedAt), formatNullTime(t.EndedAt), t.Place, path, formatTime(t.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save track: %w", err)
+	}
+	return nil
+}
+
+// GetTrack returns the track with the given ID.
+func (s *Store) GetTrack(ctx context.Context, id string) (*models.Track, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+trackColumns+` FROM tracks WHERE id = ?`, id)
+	t, err := scanTrack(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get track: %w", err)
+	}
+	return t, nil
+}
+
+// ListTracksByTrip returns a trip's tracks in the order they were recorded.
+func (s *Store) ListTracksByTrip(ctx context.Context, tripID string) ([]*models.Track, error) {
+	return s.listTracks(ctx, `WHERE trip_id = ?`, tripID)
+}
+
+// ListTracksByEntry returns the tracks linked to a journal entry.
+func (s *Store) ListTracksByEntry(ctx context.Context, entryID string) ([]*models.Track, error) {
+	return s.listTracks(ctx, `WHERE entry_id = ?`, entryID)
+}
+
+func (s *Store) listTracks(ctx context.Context, where string, args ...any) ([]*models.Track, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+trackColumns+` FROM tracks `+where+` ORDER BY started_at, created_at`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list tracks: %w", err)
+	}
+	defer rows.Close()
+
+	var tracks []*models.Track
+	for rows.Next() {
+		t, err := scanTrack(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list tracks: %w", err)
+		}
+		tracks = append(tracks, t)
+	}
+	return tracks, rows.Err()
+}
+
+// DeleteTrack removes a track.
+func (s *Store) DeleteTrack(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM tracks WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete track: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanTrack(sc scanner) (*models.Track, error) {
+	var (
+		t              models.Track
+		entryID        sql.NullString
+		started, ended sql.NullString
+		path, created  string
+	)
+	if err := sc.Scan(&t.ID, &t.TripID, &entryID, &t.Name, &t.Distance, &t.Ascent, &t.Descent, &t.Points,
+		&started, &ended, &t.Place, &path, &created); err != nil {
+		return nil, err
+	}
+	t.EntryID = entryID.String
+	if err := json.Unmarshal([]byte(path), &t.Path); err != nil {
+		return nil, err
+	}
+	var err error
+	if t.StartedAt, err = parseNullTime(started); err != nil {
+		return nil, err
+	}
+	if t.EndedAt, err = parseNullTime(ended); err != nil {
+		return nil, err
+	}
+	if t.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
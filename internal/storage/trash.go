@@ -0,0 +1,497 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// TrashDir is the directory inside the data directory where the files of
+// attachments removed from an entry wait, laid out like AttachmentsDir,
+// until the removal can no longer be undone, and those of documents
+// removed from a trip wait in its DocumentsDir. The files of entries and
+// trips in the trash stay where they are until they are purged.
+const TrashDir = "trash"
+
+// TrashAttachment moves an attachment's file into the trash, so deleting
+// the attachment or its entry afterwards leaves the file for
+// RestoreAttachment.
+func (s *Store) TrashAttachment(a *models.Attachment) error {
+	dst := s.trashPath(a)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return fmt.Errorf("storage: trash attachment: %w", err)
+	}
+	if err := os.Rename(s.AttachmentPath(a), dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: trash attachment: %w", err)
+	}
+	return nil
+}
+
+// RestoreAttachment brings back a deleted attachment: its file from the
+// trash and its row. The entry must exist.
+func (s *Store) RestoreAttachment(ctx context.Context, a *models.Attachment) error {
+	dst := s.AttachmentPath(a)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return fmt.Errorf("storage: restore attachment: %w", err)
+	}
+	if err := os.Rename(s.trashPath(a), dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: restore attachment: %w", err)
+	}
+	_, err := s.exec(ctx, `INSERT INTO attachments (`+attachmentColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO NOTHING`,
+		a.ID, a.EntryID, a.Name, a.Path, a.Linked, a.Size, formatTime(a.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: restore attachment: %w", err)
+	}
+	return nil
+}
+
+// EmptyTrash deletes every trashed file for good.
+func (s *Store) EmptyTrash() error {
+	if err := os.RemoveAll(filepath.Join(s.dir, TrashDir)); err != nil {
+		return fmt.Errorf("storage: empty trash: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) trashPath(a *models.Attachment) string {
+	return filepath.Join(s.dir, TrashDir, a.Path)
+}
+
+const trashColumns = `id, kind, title, trip_id, deleted_at`
+
+// TripRecord is a trip with everything recorded on it, as the trash keeps
+// a deleted trip and a Dump every trip.
+type TripRecord struct {
+	Trip        *models.Trip            `json:"trip,omitempty"`
+	Legs        []*models.Leg           `json:"legs,omitempty"`
+	Entries     []*models.Entry         `json:"entries,omitempty"`
+	Attachments []*models.Attachment    `json:"attachments,omitempty"`
+	Expenses    []*models.Expense       `json:"expenses,omitempty"`
+	Itinerary   []*models.ItineraryItem `json:"itinerary,omitempty"`
+	Tracks      []*models.Track         `json:"tracks,omitempty"`
+	Packing     []*models.PackingItem   `json:"packing,omitempty"`
+	CheckIns    []*models.CheckIn       `json:"checkins,omitempty"`
+	Segments    []*models.Segment       `json:"segments,omitempty"`
+	Recurrences []*models.Recurrence    `json:"recurrences,omitempty"`
+	Revisions   []*models.Revision      `json:"revisions,omitempty"`
+	Documents   []*models.Document      `json:"documents,omitempty"`
+	Highlights  []*models.Highlight     `json:"highlights,omitempty"`
+	Health      []*models.HealthDay     `json:"health,omitempty"`
+	Lodgings    []*models.Lodging       `json:"lodgings,omitempty"`
+	Prep        []*models.PrepTask      `json:"prep,omitempty"`
+}
+
+// trashed is the record of a row in the trash: what was deleted, with what
+// was deleted along with it. A deleted entry keeps its attachments and
+// revisions in the TripRecord.
+type trashed struct {
+	TripRecord
+	Entry   *models.Entry   `json:"entry,omitempty"`
+	Expense *models.Expense `json:"expense,omitempty"`
+	// TrackIDs are the tracks recorded with a deleted entry, which are
+	// linked to it again when it is restored.
+	TrackIDs []string `json:"track_ids,omitempty"`
+	// HighlightIDs are the highlights linked to a deleted entry, likewise.
+	HighlightIDs []string `json:"highlight_ids,omitempty"`
+}
+
+// TrashTrip moves a trip with everything recorded on it to the trash.
+func (s *Store) TrashTrip(ctx context.Context, id string) (*models.Trashed, error) {
+	t, err := s.GetTrip(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.tripRecord(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	rec := trashed{TripRecord: *r}
+	trashed, err := s.trash(ctx, models.TrashTrip, t.Title, t.ID, rec, `DELETE FROM trips WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(rec.Entries))
+	for i, e := range rec.Entries {
+		ids[i] = e.ID
+	}
+	return trashed, s.removeNotes(ids...)
+}
+
+// tripRecord reads everything recorded on trip t.
+func (s *Store) tripRecord(ctx context.Context, t *models.Trip) (*TripRecord, error) {
+	id := t.ID
+	rec := &TripRecord{Trip: t}
+	var err error
+	if rec.Legs, err = s.ListLegsByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Entries, err = s.ListEntriesByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	for _, e := range rec.Entries {
+		attachments, err := s.ListAttachmentsByEntry(ctx, e.ID)
+		if err != nil {
+			return nil, err
+		}
+		rec.Attachments = append(rec.Attachments, attachments...)
+		revisions, err := s.ListRevisions(ctx, e.ID)
+		if err != nil {
+			return nil, err
+		}
+		rec.Revisions = append(rec.Revisions, revisions...)
+	}
+	if rec.Expenses, err = s.ListExpensesByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Itinerary, err = s.ListItineraryByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Tracks, err = s.ListTracksByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Packing, err = s.ListPackingByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.CheckIns, err = s.ListCheckInsByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Segments, err = s.ListSegmentsByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Recurrences, err = s.ListRecurrencesByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Documents, err = s.ListDocumentsByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Highlights, err = s.ListHighlightsByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Health, err = s.ListHealthByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Lodgings, err = s.ListLodgingsByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Prep, err = s.ListPrepTasksByTrip(ctx, id); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// TrashEntry moves a journal entry with its attachments and revisions to
+// the trash, unlinking the tracks recorded with it and the highlights
+// linked to it. Its note, if the store keeps notes, goes to the notes'
+// trash.
+func (s *Store) TrashEntry(ctx context.Context, id string) (*models.Trashed, error) {
+	t, err := s.trashEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return t, s.removeNotes(id)
+}
+
+// trashEntry moves a journal entry to the trash, leaving its note alone.
+func (s *Store) trashEntry(ctx context.Context, id string) (*models.Trashed, error) {
+	e, err := s.GetEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rec := trashed{Entry: e}
+	if rec.Attachments, err = s.ListAttachmentsByEntry(ctx, id); err != nil {
+		return nil, err
+	}
+	if rec.Revisions, err = s.ListRevisions(ctx, id); err != nil {
+		return nil, err
+	}
+	tracks, err := s.ListTracksByEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tracks {
+		rec.TrackIDs = append(rec.TrackIDs, t.ID)
+	}
+	highlights, err := s.ListHighlightsByEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range highlights {
+		rec.HighlightIDs = append(rec.HighlightIDs, h.ID)
+	}
+	return s.trash(ctx, models.TrashEntry, e.Title, e.TripID, rec, `DELETE FROM entries WHERE id = ?`, id)
+}
+
+// TrashExpense moves an expense to the trash.
+func (s *Store) TrashExpense(ctx context.Context, id string) (*models.Trashed, error) {
+	x, err := s.GetExpense(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.trash(ctx, models.TrashExpense, x.Description, x.TripID, trashed{Expense: x}, `DELETE FROM expenses WHERE id = ?`, id)
+}
+
+// trash records rec in the trash and deletes its row with del, atomically.
+func (s *Store) trash(ctx context.Context, kind, title, tripID string, rec trashed, del, id string) (*models.Trashed, error) {
+	record, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	t := &models.Trashed{ID: models.NewID(), Kind: kind, Title: title, TripID: tripID, DeletedAt: time.Now()}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("storage: trash %s: %w", kind, err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, `INSERT INTO trash (id, kind, title, trip_id, record, deleted_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		t.ID, t.Kind, t.Title, t.TripID, string(record), formatTime(t.DeletedAt)); err != nil {
+		return nil, fmt.Errorf("storage: trash %s: %w", kind, err)
+	}
+	if _, err := tx.ExecContext(ctx, del, id); err != nil {
+		return nil, fmt.Errorf("storage: trash %s: %w", kind, err)
+	}
+	if err := s.commit(ctx, tx); err != nil {
+		return nil, fmt.Errorf("storage: trash %s: %w", kind, err)
+	}
+	return t, nil
+}
+
+// ListTrash returns what is in the trash, most recently deleted first.
+func (s *Store) ListTrash(ctx context.Context) ([]*models.Trashed, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+trashColumns+` FROM trash ORDER BY deleted_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list trash: %w", err)
+	}
+	defer rows.Close()
+
+	var trash []*models.Trashed
+	for rows.Next() {
+		var (
+			t       models.Trashed
+			deleted string
+		)
+		if err := rows.Scan(&t.ID, &t.Kind, &t.Title, &t.TripID, &deleted); err != nil {
+			return nil, fmt.Errorf("storage: list trash: %w", err)
+		}
+		if t.DeletedAt, err = parseTime(deleted); err != nil {
+			return nil, fmt.Errorf("storage: list trash: %w", err)
+		}
+		trash = append(trash, &t)
+	}
+	return trash, rows.Err()
+}
+
+// RestoreTrashed takes a record out of the trash and saves it again with
+// what was deleted along with it. Entries and expenses can only be
+// restored while their trip exists; they lose the legs deleted since.
+func (s *Store) RestoreTrashed(ctx context.Context, id string) error {
+	rec, err := s.trashed(ctx, id)
+	if err != nil {
+		return err
+	}
+	switch {
+	case rec.Trip != nil:
+		err = s.restoreTrip(ctx, &rec.TripRecord)
+	case rec.Entry != nil:
+		err = s.restoreEntry(ctx, rec)
+	case rec.Expense != nil:
+		err = s.restoreExpense(ctx, rec.Expense)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := s.exec(ctx, `DELETE FROM trash WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("storage: restore: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) restoreTrip(ctx context.Context, rec *TripRecord) error {
+	if err := s.SaveTrip(ctx, rec.Trip); err != nil {
+		return err
+	}
+	for _, l := range rec.Legs {
+		if err := s.SaveLeg(ctx, l); err != nil {
+			return err
+		}
+	}
+	for _, e := range rec.Entries {
+		if err := s.SaveEntry(ctx, e); err != nil {
+			return err
+		}
+	}
+	for _, a := range rec.Attachments {
+		if err := s.RestoreAttachment(ctx, a); err != nil {
+			return err
+		}
+	}
+	if err := s.saveRevisions(ctx, rec.Revisions); err != nil {
+		return err
+	}
+	for _, r := range rec.Recurrences {
+		if err := s.SaveRecurrence(ctx, r); err != nil {
+			return err
+		}
+	}
+	for _, x := range rec.Expenses {
+		if err := s.SaveExpense(ctx, x); err != nil {
+			return err
+		}
+	}
+	for _, it := range rec.Itinerary {
+		if err := s.SaveItineraryItem(ctx, it); err != nil {
+			return err
+		}
+	}
+	for _, t := range rec.Tracks {
+		if err := s.SaveTrack(ctx, t); err != nil {
+			return err
+		}
+	}
+	for _, it := range rec.Packing {
+		if err := s.SavePackingItem(ctx, it); err != nil {
+			return err
+		}
+	}
+	for _, c := range rec.CheckIns {
+		if err := s.SaveCheckIn(ctx, c); err != nil {
+			return err
+		}
+	}
+	for _, seg := range rec.Segments {
+		if err := s.SaveSegment(ctx, seg); err != nil {
+			return err
+		}
+	}
+	for _, d := range rec.Documents {
+		if err := s.RestoreDocument(ctx, d); err != nil {
+			return err
+		}
+	}
+	for _, h := range rec.Highlights {
+		if err := s.SaveHighlight(ctx, h); err != nil {
+			return err
+		}
+	}
+	for _, h := range rec.Health {
+		if err := s.SaveHealthDay(ctx, h); err != nil {
+			return err
+		}
+	}
+	for _, l := range rec.Lodgings {
+		if err := s.restoreLodging(ctx, l); err != nil {
+			return err
+		}
+	}
+	for _, p := range rec.Prep {
+		if err := s.SavePrepTask(ctx, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) restoreEntry(ctx context.Context, rec *trashed) error {
+	e := rec.Entry
+	if err := s.restorable(ctx, e.TripID, &e.LegID); err != nil {
+		return err
+	}
+	if err := s.SaveEntry(ctx, e); err != nil {
+		return err
+	}
+	for _, a := range rec.Attachments {
+		if err := s.RestoreAttachment(ctx, a); err != nil {
+			return err
+		}
+	}
+	if err := s.saveRevisions(ctx, rec.Revisions); err != nil {
+		return err
+	}
+	for _, id := range rec.TrackIDs {
+		if _, err := s.exec(ctx, `UPDATE tracks SET entry_id = ? WHERE id = ? AND entry_id IS NULL`, e.ID, id); err != nil {
+			return fmt.Errorf("storage: restore entry: %w", err)
+		}
+	}
+	for _, id := range rec.HighlightIDs {
+		if _, err := s.exec(ctx, `UPDATE highlights SET entry_id = ? WHERE id = ? AND entry_id IS NULL`, e.ID, id); err != nil {
+			return fmt.Errorf("storage: restore entry: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) restoreExpense(ctx context.Context, x *models.Expense) error {
+	if err := s.restorable(ctx, x.TripID, &x.LegID); err != nil {
+		return err
+	}
+	return s.SaveExpense(ctx, x)
+}
+
+// restorable checks that the trip of an entry or expense still exists and
+// clears its leg if that was deleted since.
+func (s *Store) restorable(ctx context.Context, tripID string, legID *string) error {
+	if _, err := s.GetTrip(ctx, tripID); errors.Is(err, ErrNotFound) {
+		return errors.New("storage: restore: its trip was deleted; restore the trip first")
+	} else if err != nil {
+		return err
+	}
+	if *legID == "" {
+		return nil
+	}
+	if _, err := s.GetLeg(ctx, *legID); errors.Is(err, ErrNotFound) {
+		*legID = ""
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// PurgeTrashed deletes a record in the trash for good, with the files of
+// the entries and trip among it.
+func (s *Store) PurgeTrashed(ctx context.Context, id string) error {
+	rec, err := s.trashed(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.exec(ctx, `DELETE FROM trash WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("storage: purge: %w", err)
+	}
+	entries := rec.Entries
+	if rec.Entry != nil {
+		entries = append(entries, rec.Entry)
+	}
+	for _, e := range entries {
+		if err := s.removeAttachmentFiles(e.ID); err != nil {
+			return err
+		}
+	}
+	if rec.Trip != nil {
+		return s.removeDocumentFiles(rec.Trip.ID)
+	}
+	return nil
+}
+
+// trashed reads the record of a row in the trash.
+func (s *Store) trashed(ctx context.Context, id string) (*trashed, error) {
+	var record string
+	err := s.db.QueryRowContext(ctx, `SELECT record FROM trash WHERE id = ?`, id).Scan(&record)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: read trash: %w", err)
+	}
+	var rec trashed
+	if err := json.Unmarshal([]byte(record), &rec); err != nil {
+		return nil, fmt.Errorf("storage: read trash: %w", err)
+	}
+	return &rec, nil
+}
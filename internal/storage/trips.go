@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const tripColumns = `id, title, locations, start_date, end_date, budget, budget_currency, category_budgets,
+	per_diem, notes, tags, companions, archived_at, rating, public, yearly, fields, created_at, updated_at`
+
+// SaveTrip inserts the trip, or updates it if a trip with the same ID exists.
+func (s *Store) SaveTrip(ctx context.Context, t *models.Trip) error {
+	if t.ID == "" {
+		t.ID = models.NewID()
+	}
+	now := time.Now()
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	t.UpdatedAt = now
+
+	locations, err := json.Marshal(t.Locations)
+	if err != nil {
+		return err
+	}
+	categoryBudgets, err := json.Marshal(t.CategoryBudgets)
+	if err != nil {
+		return err
+	}
+	if t.CategoryBudgets == nil {
+		categoryBudgets = []byte("{}")
+	}
+	t.Tags = models.NormalizeTags(t.Tags)
+	tags, err := marshalTags(t.Tags)
+	if err != nil {
+		return err
+	}
+	companions, err := marshalJSON(t.Companions, "[]")
+	if err != nil {
+		return err
+	}
+	fields, err := marshalJSON(t.Fields, "{}")
+	if err != nil {
+		return err
+	}
+	created := s.isNew(ctx, "trips", t.ID)
+	// The notes of a trip renamed move to the folder of its new title.
+	renamed := false
+	if s.notes != nil && !created {
+		if old, err := s.GetTrip(ctx, t.ID); err == nil {
+			renamed = old.Title != t.Title
+		}
+	}
+	_, err = s.exec(ctx, `
+INSERT INTO trips (`+tripColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	title = excluded.title,
+	locations = excluded.locations,
+	start_date = excluded.start_date,
+	end_date = excluded.end_date,
+	budget = excluded.budget,
+	budget_currency = excluded.budget_currency,
+	category_budgets = excluded.category_budgets,
+	per_diem = excluded.per_diem,
+	notes = excluded.notes,
+	tags = excluded.tags,
+	companions = excluded.companions,
+	archived_at = excluded.archived_at,
+	rating = excluded.rating,
+	public = excluded.public,
+	yearly = excluded.yearly,
+	fields = excluded.fields,
+	updated_at = excluded.updated_at`,
+		t.ID, t.Title, string(locations), formatTime(t.StartDate), formatNullTime(t.EndDate), t.Budget, t.BudgetCurrency,
+		string(categoryBudgets), t.PerDiem, t.Notes, tags, companions, formatNullTime(t.ArchivedAt),
+		t.Rating, t.Public, t.Yearly, fields, formatTime(t.CreatedAt), formatTime(t.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save trip: %w", err)
+	}
+	if len(t.Companions) > 0 {
+		if err := s.addPeople(ctx, t.Companions); err != nil {
+			return fmt.Errorf("storage: save trip: %w", err)
+		}
+	}
+	s.saved(ctx, Saved{Trip: t, Created: created})
+	if renamed {
+		return s.renameNotes(ctx, t)
+	}
+	return nil
+}
+
+// GetTrip returns the trip with the given ID.
+func (s *Store) GetTrip(ctx context.Context, id string) (*models.Trip, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+tripColumns+` FROM trips WHERE id = ?`, id)
+	t, err := scanTrip(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get trip: %w", err)
+	}
+	return t, nil
+}
+
+// ListTrips returns every trip, most recent first.
+func (s *Store) ListTrips(ctx context.Context) ([]*models.Trip, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+tripColumns+` FROM trips ORDER BY start_date DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list trips: %w", err)
+	}
+	defer rows.Close()
+
+	var trips []*models.Trip
+	for rows.Next() {
+		t, err := scanTrip(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list trips: %w", err)
+		}
+		trips = append(trips, t)
+	}
+	return trips, rows.Err()
+}
+
+// DeleteTrip removes a trip together with its entries, expenses and
+// documents.
+func (s *Store) DeleteTrip(ctx context.Context, id string) error {
+	entries, err := s.ListEntriesByTrip(ctx, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete trip: %w", err)
+	}
+	res, err := s.exec(ctx, `DELETE FROM trips WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete trip: %w", err)
+	}
+	if err := expectAffected(res); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := s.removeAttachmentFiles(e.ID); err != nil {
+			return err
+		}
+		if err := s.removeNotes(e.ID); err != nil {
+			return err
+		}
+	}
+	return s.removeDocumentFiles(id)
+}
+
+func scanTrip(sc scanner) (*models.Trip, error) {
+	var (
+		t                   models.Trip
+		locations, budgets  string
+		tags, companions    string
+		fields              string
+		start, created, upd string
+		end, archived       sql.NullString
+	)
+	if err := sc.Scan(&t.ID, &t.Title, &locations, &start, &end, &t.Budget, &t.BudgetCurrency, &budgets, &t.PerDiem,
+		&t.Notes, &tags, &companions, &archived, &t.Rating, &t.Public, &t.Yearly, &fields, &created, &upd); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(companions), &t.Companions); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &t.Tags); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(locations), &t.Locations); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(budgets), &t.CategoryBudgets); err != nil {
+		return nil, err
+	}
+	if len(t.CategoryBudgets) == 0 {
+		t.CategoryBudgets = nil
+	}
+	if err := json.Unmarshal([]byte(fields), &t.Fields); err != nil {
+		return nil, err
+	}
+	if len(t.Fields) == 0 {
+		t.Fields = nil
+	}
+	var err error
+	if t.StartDate, err = parseTime(start); err != nil {
+		return nil, err
+	}
+	if t.EndDate, err = parseNullTime(end); err != nil {
+		return nil, err
+	}
+	if t.ArchivedAt, err = parseNullTime(archived); err != nil {
+		return nil, err
+	}
+	if t.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if t.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func expectAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
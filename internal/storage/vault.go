@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// vaultColumns leaves out the scan itself, read only by VaultScan, for its
+// size.
+const vaultColumns = `id, kind, title, holder, number, issuer, country, issued, expires, note, scan_name, scan_type,
+	coalesce(length(scan), 0), created_at, updated_at`
+
+// SaveVaultItem inserts the travel document, or updates it if one with
+// the same ID exists. Its scan is left as it is; SetVaultScan changes it.
+func (s *Store) SaveVaultItem(ctx context.Context, it *models.VaultItem) error {
+	if it.ID == "" {
+		it.ID = models.NewID()
+	}
+	now := time.Now()
+	if it.CreatedAt.IsZero() {
+		it.CreatedAt = now
+	}
+	it.UpdatedAt = now
+	_, err := s.exec(ctx, `
+INSERT INTO vault (id, kind, title, holder, number, issuer, country, issued, expires, note, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	kind = excluded.kind,
+	title = excluded.title,
+	holder = excluded.holder,
+	number = excluded.number,
+	issuer = excluded.issuer,
+	country = excluded.country,
+	issued = excluded.issued,
+	expires = excluded.expires,
+	note = excluded.note,
+	updated_at = excluded.updated_at`,
+		it.ID, it.Kind, it.Title, it.Holder, it.Number, it.Issuer, it.Country, formatNullTime(it.Issued),
+		formatNullTime(it.Expires), it.Note, formatTime(it.CreatedAt), formatTime(it.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save vault item: %w", err)
+	}
+	return nil
+}
+
+// SetVaultScan keeps data, the file name with its content type, as the
+// scan of the travel document with the given ID, replacing any kept
+// before. Empty data removes the scan.
+func (s *Store) SetVaultScan(ctx context.Context, id, name, contentType string, data []byte) error {
+	var scan any
+	if len(data) > 0 {
+		scan = data
+	} else {
+		name, contentType = "", ""
+	}
+	res, err := s.exec(ctx, `UPDATE vault SET scan_name = ?, scan_type = ?, scan = ?, updated_at = ? WHERE id = ?`,
+		name, contentType, scan, formatTime(time.Now()), id)
+	if err != nil {
+		return fmt.Errorf("storage: save vault scan: %w", err)
+	}
+	return expectAffected(res)
+}
+
+// VaultScan returns the scan kept with the travel document with the given
+// ID, or ErrNotFound when there is none.
+func (s *Store) VaultScan(ctx context.Context, id string) ([]byte, error) {
+	var data []byte
+	err := s.db.QueryRowContext(ctx, `SELECT scan FROM vault WHERE id = ? AND scan IS NOT NULL`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: read vault scan: %w", err)
+	}
+	return data, nil
+}
+
+// VaultScanFile writes the scan kept with a travel document to a file
+// only the user can read, in a directory of its own under the temporary
+// directory, for a viewer to open, and returns its path.
+func (s *Store) VaultScanFile(ctx context.Context, it *models.VaultItem) (string, error) {
+	data, err := s.VaultScan(ctx, it.ID)
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp("", "nomadic-vault-")
+	if err != nil {
+		return "", fmt.Errorf("storage: open vault scan: %w", err)
+	}
+	name := filepath.Base(it.ScanName)
+	if name == "." || name == string(filepath.Separator) {
+		name = "scan"
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("storage: open vault scan: %w", err)
+	}
+	return path, nil
+}
+
+// GetVaultItem returns the travel document with the given ID.
+func (s *Store) GetVaultItem(ctx context.Context, id string) (*models.VaultItem, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+vaultColumns+` FROM vault WHERE id = ?`, id)
+	it, err := scanVaultItem(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get vault item: %w", err)
+	}
+	return it, nil
+}
+
+// ListVault returns the travel documents of the vault, those running out
+// first and those that never do last.
+func (s *Store) ListVault(ctx context.Context) ([]*models.VaultItem, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+vaultColumns+` FROM vault
+ORDER BY expires IS NULL, expires, title COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list vault: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.VaultItem
+	for rows.Next() {
+		it, err := scanVaultItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list vault: %w", err)
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+// DeleteVaultItem removes a travel document and its scan.
+func (s *Store) DeleteVaultItem(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM vault WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete vault item: %w", err)
+	}
+	return expectAffected(res)
+}
+
+func scanVaultItem(sc scanner) (*models.VaultItem, error) {
+	var (
+		it              models.VaultItem
+		issued, expires sql.NullString
+		created, upd    string
+	)
+	if err := sc.Scan(&it.ID, &it.Kind, &it.Title, &it.Holder, &it.Number, &it.Issuer, &it.Country, &issued, &expires,
+		&it.Note, &it.ScanName, &it.ScanType, &it.ScanSize, &created, &upd); err != nil {
+		return nil, err
+	}
+	var err error
+	if it.Issued, err = parseNullTime(issued); err != nil {
+		return nil, err
+	}
+	if it.Expires, err = parseNullTime(expires); err != nil {
+		return nil, err
+	}
+	if it.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if it.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &it, nil
+}
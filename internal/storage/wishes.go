@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const wishColumns = `id, place, notes, budget, currency, season, priority, trip_id, created_at, updated_at`
+
+// SaveWish inserts the wish, or updates it if one with the same ID exists.
+func (s *Store) SaveWish(ctx context.Context, w *models.Wish) error {
+	if w.ID == "" {
+		w.ID = models.NewID()
+	}
+	now := time.Now()
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = now
+	}
+	w.UpdatedAt = now
+
+	season, err := marshalJSON(w.Season, "[]")
+	if err != nil {
+		return err
+	}
+	tripID := sql.NullString{String: w.TripID, Valid: w.TripID != ""}
+	_, err = s.exec(ctx, `
+INSERT INTO wishes (`+wishColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET
+	place = excluded.place,
+	notes = excluded.notes,
+	budget = excluded.budget,
+	currency = excluded.currency,
+	season = excluded.season,
+	priority = excluded.priority,
+	trip_id = excluded.trip_id,
+	updated_at = excluded.updated_at`,
+		w.ID, w.Place, w.Notes, w.Budget, w.Currency, season, w.Priority, tripID,
+		formatTime(w.CreatedAt), formatTime(w.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("storage: save wish: %w", err)
+	}
+	return nil
+}
+
+// GetWish returns the wish with the given ID.
+func (s *Store) GetWish(ctx context.Context, id string) (*models.Wish, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+wishColumns+` FROM wishes WHERE id = ?`, id)
+	w, err := scanWish(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: get wish: %w", err)
+	}
+	return w, nil
+}
+
+// ListWishes returns the wishlist, the wishes still to plan first, most
+// wanted first and then by place, followed by those planned as trips.
+func (s *Store) ListWishes(ctx context.Context) ([]*models.Wish, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+wishColumns+` FROM wishes
+ORDER BY trip_id IS NOT NULL,
+	CASE priority WHEN 'high' THEN 0 WHEN 'medium' THEN 1 ELSE 2 END,
+	place COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("storage: list wishes: %w", err)
+	}
+	defer rows.Close()
+
+	var wishes []*models.Wish
+	for rows.Next() {
+		w, err := scanWish(rows)
+		if err != nil {
+			return nil, fmt.Errorf("storage: list wishes: %w", err)
+		}
+		wishes = append(wishes, w)
+	}
+	return wishes, rows.Err()
+}
+
+// DeleteWish removes a wish. A trip planned from it is kept.
+func (s *Store) DeleteWish(ctx context.Context, id string) error {
+	res, err := s.exec(ctx, `DELETE FROM wishes WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("storage: delete wish: %w", err)
+	}
+	return expectAffected(res)
+}
+
+// PromoteWish saves p, a trip planned from the wish w, and marks w as
+// planned by it. The wish is back on the list to plan should the trip be
+// deleted.
+func (s *Store) PromoteWish(ctx context.Context, w *models.Wish, p *models.TripPlan) error {
+	if err := s.SaveTripPlan(ctx, p); err != nil {
+		return err
+	}
+	w.TripID = p.Trip.ID
+	return s.SaveWish(ctx, w)
+}
+
+func scanWish(sc scanner) (*models.Wish, error) {
+	var (
+		w                    models.Wish
+		season, created, upd string
+		tripID               sql.NullString
+	)
+	if err := sc.Scan(&w.ID, &w.Place, &w.Notes, &w.Budget, &w.Currency, &season, &w.Priority, &tripID,
+		&created, &upd); err != nil {
+		return nil, err
+	}
+	w.TripID = tripID.String
+	if err := json.Unmarshal([]byte(season), &w.Season); err != nil {
+		return nil, err
+	}
+	var err error
+	if w.CreatedAt, err = parseTime(created); err != nil {
+		return nil, err
+	}
+	if w.UpdatedAt, err = parseTime(upd); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
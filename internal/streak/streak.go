@@ -0,0 +1,79 @@
+// Package streak tracks journaling streaks: the consecutive days of a trip
+// in progress with at least one journal entry.
+package streak
+
+import (
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// Streak is the journaling streak on the trip in progress.
+type Streak struct {
+	// Trip is the trip in progress, or nil when there is none and so no
+	// streak to keep.
+	Trip *models.Trip
+	// Current counts the consecutive days with an entry up to today. While
+	// today's entry is missing the streak is still alive and counts up to
+	// yesterday.
+	Current int
+	// Longest is the longest run of days with an entry on the trip.
+	Longest int
+	// WrittenToday reports whether today already has an entry.
+	WrittenToday bool
+}
+
+// Due reports whether today's entry is missing on a trip in progress.
+func (s Streak) Due() bool {
+	return s.Trip != nil && !s.WrittenToday
+}
+
+// Active returns the trip in progress at now, the most recently started
+// one when several overlap, or nil.
+func Active(trips []*models.Trip, now time.Time) *models.Trip {
+	return models.TripOn(trips, now)
+}
+
+// Compute measures the streak of trip t from its journal entries. A nil
+// trip has no streak. Entries count on their day where they were written,
+// and today is the day of now as it is read, which should be where the
+// trip is, so a traveler far from home keeps the trip's days.
+func Compute(t *models.Trip, entries []*models.Entry, now time.Time) Streak {
+	s := Streak{Trip: t}
+	if t == nil {
+		return s
+	}
+	start := day(t.StartDate)
+	written := make(map[time.Time]bool)
+	for _, e := range entries {
+		if d := day(e.Timestamp); !d.Before(start) {
+			written[d] = true
+		}
+	}
+
+	today := day(now)
+	s.WrittenToday = written[today]
+	d := today
+	if !s.WrittenToday {
+		d = d.AddDate(0, 0, -1)
+	}
+	for ; !d.Before(start) && written[d]; d = d.AddDate(0, 0, -1) {
+		s.Current++
+	}
+
+	run := 0
+	for d := start; !d.After(today); d = d.AddDate(0, 0, 1) {
+		if written[d] {
+			run++
+			s.Longest = max(s.Longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return s
+}
+
+// day is the calendar day of t as a comparable UTC midnight.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,156 @@
+// Package textdiff compares two versions of a text line by line, as the
+// history of a journal entry shows them.
+package textdiff
+
+import "strings"
+
+// Op is what a line of a diff does to the old text.
+type Op int
+
+const (
+	// Equal lines are in both texts.
+	Equal Op = iota
+	// Insert lines are only in the new text.
+	Insert
+	// Delete lines are only in the old text.
+	Delete
+)
+
+// Line is one line of a diff.
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// maxCells bounds the table of the longest common subsequence. Texts
+// differing over more lines than fit are shown as one block replaced by
+// another.
+const maxCells = 4 << 20
+
+// Lines returns the diff turning old into new: each line of either text,
+// in order, kept, inserted or deleted, with as few inserted and deleted
+// lines as can be.
+func Lines(old, new string) []Line {
+	a, b := split(old), split(new)
+
+	// Lines the texts start and end with alike need no table.
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var diff []Line
+	for _, l := range a[:pre] {
+		diff = append(diff, Line{Equal, l})
+	}
+	diff = append(diff, middle(a[pre:len(a)-suf], b[pre:len(b)-suf])...)
+	for _, l := range a[len(a)-suf:] {
+		diff = append(diff, Line{Equal, l})
+	}
+	return diff
+}
+
+// middle diffs a and b by their longest common subsequence.
+func middle(a, b []string) []Line {
+	var diff []Line
+	if (len(a)+1)*(len(b)+1) > maxCells {
+		for _, l := range a {
+			diff = append(diff, Line{Delete, l})
+		}
+		for _, l := range b {
+			diff = append(diff, Line{Insert, l})
+		}
+		return diff
+	}
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, Line{Equal, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, Line{Delete, a[i]})
+			i++
+		default:
+			diff = append(diff, Line{Insert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, Line{Delete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, Line{Insert, b[j]})
+	}
+	return diff
+}
+
+// split cuts a text into lines, a final newline ending the last line
+// rather than starting an empty one.
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// Stat counts the lines a diff inserts and deletes.
+func Stat(diff []Line) (inserted, deleted int) {
+	for _, l := range diff {
+		switch l.Op {
+		case Insert:
+			inserted++
+		case Delete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+// Hunks cuts a diff down to its changes with up to context unchanged lines
+// around each, as runs of lines; runs of unchanged lines between two hunks
+// are left out. A diff without changes has no hunks.
+func Hunks(diff []Line, context int) [][]Line {
+	var (
+		hunks [][]Line
+		start = -1 // the first line of the hunk being gathered
+		end   int  // one past its last change
+	)
+	for i, l := range diff {
+		if l.Op == Equal {
+			continue
+		}
+		if start >= 0 && i-end > 2*context {
+			hunks = append(hunks, diff[start:min(end+context, len(diff))])
+			start = -1
+		}
+		if start < 0 {
+			start = max(i-context, 0)
+		}
+		end = i + 1
+	}
+	if start >= 0 {
+		hunks = append(hunks, diff[start:min(end+context, len(diff))])
+	}
+	return hunks
+}
@@ -0,0 +1,165 @@
+// Package theme defines the colour palettes of the terminal interface.
+package theme
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Palette names the colours used by the interface. Colours are ANSI 256
+// numbers such as "205" or hex values such as "#ff5f87"; an empty colour
+// keeps the terminal's own.
+type Palette struct {
+	// Base names the built-in palette that unset colours are taken from.
+	// It only applies to custom themes and defaults to dark.
+	Base string `toml:"base,omitempty"`
+
+	Title     string `toml:"title,omitempty"`     // screen headers
+	Label     string `toml:"label,omitempty"`     // field labels and emphasis
+	Muted     string `toml:"muted,omitempty"`     // hints and secondary text
+	Error     string `toml:"error,omitempty"`     // errors and destructive prompts
+	Warning   string `toml:"warning,omitempty"`   // limits that are close
+	Success   string `toml:"success,omitempty"`   // things within limits
+	Cursor    string `toml:"cursor,omitempty"`    // the selected menu item
+	Border    string `toml:"border,omitempty"`    // pane borders
+	Highlight string `toml:"highlight,omitempty"` // search matches
+	// Markdown is the glamour style used to render entries, e.g. dark or light.
+	Markdown string `toml:"markdown,omitempty"`
+}
+
+var builtins = map[string]Palette{
+	"dark": {
+		Title:     "205",
+		Label:     "",
+		Muted:     "241",
+		Error:     "196",
+		Warning:   "214",
+		Success:   "42",
+		Cursor:    "212",
+		Border:    "241",
+		Highlight: "212",
+		Markdown:  "dark",
+	},
+	"light": {
+		Title:     "125",
+		Label:     "",
+		Muted:     "245",
+		Error:     "160",
+		Warning:   "166",
+		Success:   "28",
+		Cursor:    "162",
+		Border:    "250",
+		Highlight: "162",
+		Markdown:  "light",
+	},
+	"high-contrast": {
+		Title:     "#ffff00",
+		Label:     "#ffffff",
+		Muted:     "#00ffff",
+		Error:     "#ff0000",
+		Warning:   "#ff8700",
+		Success:   "#00ff00",
+		Cursor:    "#ffff00",
+		Border:    "#ffffff",
+		Highlight: "#00ff00",
+		Markdown:  "dark",
+	},
+}
+
+// Builtin lists the built-in theme names.
+func Builtin() []string {
+	return []string{"dark", "light", "high-contrast"}
+}
+
+// Names lists the built-in themes followed by the custom ones, sorted.
+func Names(custom map[string]Palette) []string {
+	names := Builtin()
+	var extra []string
+	for name := range custom {
+		if _, ok := builtins[name]; !ok {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return append(names, extra...)
+}
+
+// Resolve returns the palette called name. Custom themes take precedence
+// over built-in ones and inherit unset colours from their base.
+func Resolve(name string, custom map[string]Palette) (Palette, error) {
+	if p, ok := custom[name]; ok {
+		base := p.Base
+		if base == "" {
+			base = "dark"
+		}
+		b, ok := builtins[base]
+		if !ok {
+			return Palette{}, fmt.Errorf("theme %q: base %q is not one of %s", name, base, strings.Join(Builtin(), ", "))
+		}
+		p = p.over(b)
+		if err := p.validate(); err != nil {
+			return Palette{}, fmt.Errorf("theme %q: %w", name, err)
+		}
+		return p, nil
+	}
+	if p, ok := builtins[name]; ok {
+		return p, nil
+	}
+	return Palette{}, fmt.Errorf("theme %q is not one of %s", name, strings.Join(Names(custom), ", "))
+}
+
+// over fills p's unset colours from base.
+func (p Palette) over(base Palette) Palette {
+	pick := func(v, fallback string) string {
+		if v == "" {
+			return fallback
+		}
+		return v
+	}
+	return Palette{
+		Base:      p.Base,
+		Title:     pick(p.Title, base.Title),
+		Label:     pick(p.Label, base.Label),
+		Muted:     pick(p.Muted, base.Muted),
+		Error:     pick(p.Error, base.Error),
+		Warning:   pick(p.Warning, base.Warning),
+		Success:   pick(p.Success, base.Success),
+		Cursor:    pick(p.Cursor, base.Cursor),
+		Border:    pick(p.Border, base.Border),
+		Highlight: pick(p.Highlight, base.Highlight),
+		Markdown:  pick(p.Markdown, base.Markdown),
+	}
+}
+
+func (p Palette) validate() error {
+	colours := []struct{ name, value string }{
+		{"title", p.Title}, {"label", p.Label}, {"muted", p.Muted}, {"error", p.Error},
+		{"warning", p.Warning}, {"success", p.Success},
+		{"cursor", p.Cursor}, {"border", p.Border}, {"highlight", p.Highlight},
+	}
+	for _, c := range colours {
+		if !ValidColour(c.value) {
+			return fmt.Errorf("%s colour %q is neither an ANSI number (0-255) nor #rgb/#rrggbb", c.name, c.value)
+		}
+	}
+	return nil
+}
+
+// ValidColour reports whether v is a colour palettes accept: an ANSI
+// number (0-255), #rgb or #rrggbb, or empty for none.
+func ValidColour(v string) bool {
+	if v == "" {
+		return true
+	}
+	if hex, ok := strings.CutPrefix(v, "#"); ok {
+		if len(hex) != 3 && len(hex) != 6 {
+			return false
+		}
+		_, err := strconv.ParseUint(hex, 16, 32)
+		return err == nil
+	}
+	n, err := strconv.Atoi(v)
+	return err == nil && n >= 0 && n <= 255
+}
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// accessible is set in accessible mode, for screen readers and braille
+// displays: the interface is drawn without colour, emoji or box drawing,
+// and screens laid out in columns read top to bottom instead. NewModel
+// sets it from the config before the theme.
+var accessible bool
+
+// setAccessible switches accessible mode on or off. Colour goes entirely:
+// it is the one thing plain text cannot carry, so screens must not rely
+// on it alone.
+func setAccessible(on bool) {
+	accessible = on
+	if on {
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+}
+
+// symbolLabels are the words read in place of symbols that mean something.
+// A label keeps the space after its symbol; other emoji are decoration
+// next to words saying the same, and go with theirs.
+var symbolLabels = map[string]string{
+	"👉": ">", // keeps the width of the cursor, spaces and all
+	"☐": "[ ]",
+	"☑": "[x]",
+	"✓": "OK:",
+	"⚠": "Warning:",
+	"⛔": "Alert:",
+	"🎫": "booking",
+	"🌐": "public",
+	"🙈": "private",
+	"◀": "<",
+	"▶": ">",
+	"↶": "",
+	"↷": "",
+}
+
+// plainReplacer spells out arrows and reads separators as punctuation.
+var plainReplacer = strings.NewReplacer(
+	"↑/↓", "up/down",
+	"←/→", "left/right",
+	" → ", " to ",
+	"→", " to ",
+	"↑", "up ",
+	"↓", "down ",
+	"←", "left ",
+	" • ", ", ",
+	" · ", ", ",
+	"› ", "> ",
+)
+
+var (
+	// symbolPattern matches an emoji or symbol with the spaces after it.
+	symbolPattern = regexp.MustCompile("([\U0001F000-\U0001FAFF☀-➿⬀-⯿◀▶↶↷])️?( *)")
+	// starsPattern matches a rating drawn as stars.
+	starsPattern = regexp.MustCompile("[★☆]+")
+	// drawingPattern matches box drawing and the blocks of bar charts.
+	drawingPattern = regexp.MustCompile("[─-▟]+ ?")
+)
+
+// plainText rewrites a rendered screen for accessible mode: moods and
+// ratings in words, symbols as labels, and emoji and drawing gone.
+func plainText(view string) string {
+	for mood := 1; mood <= models.MaxRating; mood++ {
+		view = strings.ReplaceAll(view, models.MoodFace(mood), fmt.Sprintf("mood %d of %d", mood, models.MaxRating))
+	}
+	view = starsPattern.ReplaceAllStringFunc(view, func(s string) string {
+		return fmt.Sprintf("%d of %d stars", strings.Count(s, "★"), utf8.RuneCountInString(s))
+	})
+	view = symbolPattern.ReplaceAllStringFunc(view, func(s string) string {
+		m := symbolPattern.FindStringSubmatch(s)
+		label, ok := symbolLabels[m[1]]
+		switch {
+		case !ok || label == "":
+			return ""
+		case m[1] == "👉":
+			return label + " " + m[2]
+		case m[2] != "":
+			return label + " "
+		}
+		return label
+	})
+	view = drawingPattern.ReplaceAllString(view, "")
+	return plainReplacer.Replace(view)
+}
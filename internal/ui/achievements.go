@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/stats"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// achievementBar is how many cells wide the progress of an achievement is
+// drawn.
+const achievementBar = 10
+
+// achievements measures the achievements against the store, with when
+// those recorded were unlocked. Like the streak they are a nicety, so a
+// store that cannot be read shows none.
+func (a *app) achievements() []stats.Achievement {
+	if a.store == nil {
+		return nil
+	}
+	trips, err := a.store.ListTrips(a.ctx)
+	if err != nil {
+		return nil
+	}
+	entries, err := a.store.ListEntryTimes(a.ctx, storage.EntryQuery{})
+	if err != nil {
+		return nil
+	}
+	unlocked, err := a.store.ListUnlockedAchievements(a.ctx)
+	if err != nil {
+		return nil
+	}
+	list := stats.Achievements(trips, entries, time.Now())
+	for i, ach := range list {
+		list[i].UnlockedAt = unlocked[ach.ID]
+	}
+	return list
+}
+
+// unlockAchievements records the achievements reached since last measured
+// and returns the toasts announcing them, telling the screens once any
+// were. In read-only mode nothing is recorded, and so nothing announced.
+func (a *app) unlockAchievements() tea.Cmd {
+	if a.readOnly() {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, ach := range a.achievements() {
+		if !ach.Unlocked() || !ach.UnlockedAt.IsZero() {
+			continue
+		}
+		if err := a.store.UnlockAchievement(a.ctx, ach.ID, time.Now()); err != nil {
+			slog.Warn("ui: record achievement", "id", ach.ID, "err", err)
+			continue
+		}
+		cmds = append(cmds, notify(toastMsg{level: toastSuccess,
+			text: ach.Icon + " " + tr("Achievement unlocked: %s — %s", tr(ach.Title), achievementGoal(ach))}))
+	}
+	if len(cmds) == 0 {
+		return nil
+	}
+	return tea.Batch(append(cmds, notify(achievementsUnlockedMsg{}))...)
+}
+
+// achievementGoal describes what unlocks ach, such as "10 countries".
+func achievementGoal(ach stats.Achievement) string {
+	return fmt.Sprintf("%d %s", ach.Goal, tr(ach.Unit))
+}
+
+// achievementsView lists the achievements for the stats screen, each with
+// its progress or the day it was unlocked.
+func (s statsScreen) achievementsView() string {
+	if len(s.achievements) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n" + labelStyle.Render(tr("Achievements")) + "\n")
+	for _, ach := range s.achievements {
+		title := fmt.Sprintf("%-16s", tr(ach.Title))
+		if !ach.Unlocked() {
+			filled := ach.Progress * achievementBar / ach.Goal
+			bar := cursorStyle.Render(strings.Repeat("█", filled)) + hintStyle.Render(strings.Repeat("░", achievementBar-filled))
+			fmt.Fprintf(&b, "  %s %s %s %s\n", ach.Icon, hintStyle.Render(title), bar,
+				hintStyle.Render(fmt.Sprintf("%d/%s", ach.Progress, achievementGoal(ach))))
+			continue
+		}
+		done := "✓ " + achievementGoal(ach)
+		if !ach.UnlockedAt.IsZero() {
+			done += " • " + tr("unlocked %s", s.app.formatDate(ach.UnlockedAt))
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ach.Icon, successStyle.Render(title), successStyle.Render(done))
+	}
+	return b.String()
+}
@@ -0,0 +1,301 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/gitsync"
+	"github.com/girdharshubham/nomadic/internal/logging"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/quick"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/theme"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+	"github.com/girdharshubham/nomadic/pkg/geocode"
+	"github.com/girdharshubham/nomadic/pkg/holidays"
+	"github.com/girdharshubham/nomadic/pkg/ocr"
+	"github.com/girdharshubham/nomadic/pkg/weather"
+)
+
+// Options configures the dependencies of the TUI.
+type Options struct {
+	// Context bounds the store operations of the TUI, and is
+	// context.Background when nil.
+	Context context.Context
+	Store   *storage.Store
+	Config  config.Config
+	// Unlock opens an encrypted store with a passphrase. When Store is nil
+	// the TUI asks for the passphrase before anything else.
+	Unlock func(passphrase string) (*storage.Store, error)
+	// Setup saves the settings chosen on the first run and opens the store
+	// in their data directory. When it is set and Store is nil, the TUI
+	// opens on the first-run wizard, which suggests the settings of Config.
+	Setup func(cfg config.Config) (*storage.Store, error)
+	// Rates converts expense totals into the home currency. It may be nil.
+	Rates currency.Provider
+	// Weather records the weather of new journal entries. It may be nil.
+	Weather weather.Provider
+	// Holidays flags the public holidays in the itinerary. It may be nil.
+	Holidays holidays.Provider
+	// Geocoder finds the position of check-ins at places missing from the
+	// places dataset. It may be nil.
+	Geocoder geocode.Geocoder
+	// OCR reads the receipts of new expenses. It may be nil.
+	OCR ocr.Reader
+	// Sync is the git repository holding the data directory, or nil when
+	// it is not synced.
+	Sync *gitsync.Repo
+	// Log keeps the latest records logged, for the debug console. It may
+	// be nil.
+	Log *logging.Logger
+	// Profile is the profile of Config and Store, empty for the default
+	// one.
+	Profile string
+	// Switch, when set, is told of the profile chosen on the Profiles
+	// screen, just before the TUI quits for the caller to start it again
+	// on that profile.
+	Switch func(profile string)
+	// SetConfig, when set, changes a setting in the config file, for the
+	// profile the TUI runs on, as nomadic config set does. The TUI sets
+	// those of the macros recorded with it.
+	SetConfig func(name, value string) error
+}
+
+// app holds what every screen needs: the store, the user's settings and
+// integrations. Screens share one *app, so it must not hold per-screen
+// state.
+type app struct {
+	// ctx is the TUI's, for the store operations screens make as they
+	// update, which have no context of their own.
+	ctx      context.Context
+	store    *storage.Store
+	cfg      config.Config
+	keys     keyMap
+	rates    currency.Provider
+	weather  weather.Provider
+	holidays holidays.Provider
+	geocoder geocode.Geocoder
+	ocr      ocr.Reader
+	// palette is the resolved colours of cfg.Theme.
+	palette theme.Palette
+	// log is read back by the debug console; nil without one.
+	log *logging.Logger
+	// profile is the profile the TUI runs on, and switchProfile starts it
+	// again on another; nil when it cannot.
+	profile       string
+	switchProfile func(string)
+	// setConfig changes a setting in the config file, or is nil when it
+	// cannot.
+	setConfig func(name, value string) error
+
+	// history is the undo stack, shared by every screen.
+	history history
+
+	sync *gitsync.Repo
+	// synced is the store's change count at the last sync commit.
+	synced uint64
+	// refused is set when a key for a change was refused in read-only mode,
+	// until the model says so.
+	refused bool
+}
+
+// ratesMsg carries exchange rates for the home currency.
+type ratesMsg struct {
+	rates *currency.Rates
+	err   error
+}
+
+func (ratesMsg) broadcast() {}
+
+// fetchRates loads exchange rates for the home currency in the background.
+func (a *app) fetchRates() tea.Cmd {
+	if a.rates == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, 15*time.Second)
+		defer cancel()
+		r, err := a.rates.Latest(ctx, a.cfg.HomeCurrency)
+		return ratesMsg{rates: r, err: err}
+	}
+}
+
+// weatherMsg carries the weather fetched for a journal entry.
+type weatherMsg struct {
+	entryID string
+	day     *weather.Day
+	err     error
+}
+
+// fetchWeather looks up the weather of an entry's day in the background,
+// at the entry's location or else the trip's first known destination. It
+// does nothing without a weather provider or a known place.
+func (a *app) fetchWeather(e *models.Entry) tea.Cmd {
+	if a.weather == nil {
+		return nil
+	}
+	names := []string{e.Location}
+	if l, err := a.store.GetLeg(a.ctx, e.LegID); err == nil {
+		names = append(names, l.Location)
+	}
+	if t, err := a.store.GetTrip(a.ctx, e.TripID); err == nil {
+		names = append(names, t.Locations...)
+	}
+	p, ok := places.First(names...)
+	if !ok {
+		return nil
+	}
+	id, date := e.ID, e.Timestamp.In(p.Location())
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, 15*time.Second)
+		defer cancel()
+		day, err := a.weather.Daily(ctx, p.Lat, p.Lon, date)
+		return weatherMsg{entryID: id, day: day, err: err}
+	}
+}
+
+// recordWeather saves fetched weather on its entry. Weather is a nicety,
+// so an entry that could not get any is left without, with a warning.
+func (a *app) recordWeather(msg weatherMsg) tea.Cmd {
+	if msg.err != nil {
+		return toast(toastWarning, "No weather for the entry: %s", msg.err)
+	}
+	e, err := a.store.GetEntry(a.ctx, msg.entryID)
+	if err != nil {
+		// Deleted while the weather was looked up.
+		return nil
+	}
+	e.Weather = msg.day
+	if err := a.store.SaveEntry(a.ctx, e); err != nil {
+		return toastErr(err)
+	}
+	return func() tea.Msg { return entrySavedMsg{entry: e} }
+}
+
+// rateMsg carries the exchange rate fetched for an expense's day.
+type rateMsg struct {
+	expenseID string
+	// from, to and day are what the rate was fetched for.
+	from, to string
+	day      string
+	rate     float64
+	err      error
+}
+
+// lockRate looks up in the background the rate into the home currency
+// that an expense's currency had on its day. It does nothing without an
+// exchange-rate provider or when the expense has its rate already.
+func (a *app) lockRate(x *models.Expense) tea.Cmd {
+	home := a.cfg.HomeCurrency
+	if a.rates == nil || x.Locked(home) {
+		return nil
+	}
+	id, from, ts := x.ID, x.Currency, x.Timestamp
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, 15*time.Second)
+		defer cancel()
+		rate, err := currency.RateOn(ctx, a.rates, from, home, ts)
+		return rateMsg{expenseID: id, from: from, to: home, day: ts.Format(currency.DayLayout), rate: rate, err: err}
+	}
+}
+
+// recordRate locks a fetched rate in on its expense, unless the expense
+// has since moved to another currency or day. One that could not get its
+// rate is left for nomadic expense rates, with a warning.
+func (a *app) recordRate(msg rateMsg) tea.Cmd {
+	if msg.err != nil {
+		return toast(toastWarning, "No %s rate locked for %s: %s", msg.from, msg.day, msg.err)
+	}
+	x, err := a.store.GetExpense(a.ctx, msg.expenseID)
+	if err != nil || x.Currency != msg.from || x.Timestamp.Format(currency.DayLayout) != msg.day {
+		return nil
+	}
+	x.Rate, x.RateCurrency = msg.rate, msg.to
+	if err := a.store.SaveExpense(a.ctx, x); err != nil {
+		return toastErr(err)
+	}
+	return func() tea.Msg { return expenseSavedMsg{expense: x} }
+}
+
+// zoneOn returns the IANA name of the time zone of a trip on the calendar
+// day of day, or of the place at when it is known; see places.DayZone. It
+// is "", for the local zone, when the trip cannot be read.
+func (a *app) zoneOn(tripID, at string, day time.Time) string {
+	t, err := a.store.GetTrip(a.ctx, tripID)
+	if err != nil {
+		return ""
+	}
+	legs, _ := a.store.ListLegsByTrip(a.ctx, tripID)
+	return places.DayZone(t, legs, at, day)
+}
+
+// tripNow returns the time now where trip is, so that today is the trip's
+// day however far it is from home. It is the local time without a trip.
+func (a *app) tripNow(trip *models.Trip) time.Time {
+	now := time.Now()
+	if trip == nil {
+		return now
+	}
+	legs, _ := a.store.ListLegsByTrip(a.ctx, trip.ID)
+	return models.InZone(now, places.TripZone(trip, legs, "", now))
+}
+
+// today is the calendar day of the trip in progress, or the local one.
+func (a *app) today() time.Time {
+	trips, _ := a.store.ListTrips(a.ctx)
+	return dateOf(a.tripNow(models.TripOn(trips, time.Now())))
+}
+
+// legOn returns the ID of the leg of a trip visited on the day of t, or ""
+// when the trip has no leg then.
+func (a *app) legOn(tripID string, t time.Time) (string, error) {
+	legs, err := a.store.ListLegsByTrip(a.ctx, tripID)
+	if err != nil {
+		return "", err
+	}
+	if l := models.LegOn(legs, t); l != nil {
+		return l.ID, nil
+	}
+	return "", nil
+}
+
+// formatDate renders t in the configured date format.
+func (a *app) formatDate(t time.Time) string {
+	return t.Format(a.cfg.Layout())
+}
+
+// parseDate reads a date typed in the configured format, as YYYY-MM-DD
+// or relative to today, such as "next fri" or "in 3 days".
+func (a *app) parseDate(v string) (time.Time, error) {
+	if t, err := time.ParseInLocation(a.cfg.Layout(), v, time.Local); err == nil {
+		return t, nil
+	}
+	return a.parseDateFrom(v, a.today(), time.Time{})
+}
+
+// parseDateFrom reads a date relative to today, offsets such as "+3d"
+// counting from from when it is set.
+func (a *app) parseDateFrom(v string, today, from time.Time) (time.Time, error) {
+	t, err := quick.ParseDay(v, today, from, a.cfg.Layout())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("enter a date as %s, or such as tomorrow, fri or +3d", a.cfg.DateFormat)
+	}
+	return t, nil
+}
+
+func (a *app) validateDate(v string) error {
+	_, err := a.parseDate(v)
+	return err
+}
+
+func (a *app) validateOptionalDate(v string) error {
+	if v == "" {
+		return nil
+	}
+	return a.validateDate(v)
+}
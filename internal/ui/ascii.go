@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// asciiEnv names the variable turning ASCII-only mode on or off when the
+// ascii setting is auto.
+const asciiEnv = "NOMADIC_ASCII"
+
+// asciiOnly is set in ASCII-only mode, for terminals that draw emoji at
+// the wrong width and so break the layout: symbols are drawn in ASCII,
+// emoji as plain-text markers or not at all, and boxes with -, | and +.
+// Unlike accessible mode it keeps colour and layout. NewModel sets it from
+// the config before the theme.
+var asciiOnly bool
+
+// useASCII reports whether to draw ASCII only by the ascii setting: on or
+// off, or with auto by $NOMADIC_ASCII when set, and else when the terminal
+// looks unable to draw emoji, as the Linux console is, or the locale does
+// not use UTF-8.
+func useASCII(setting string, getenv func(string) string) bool {
+	switch setting {
+	case config.ASCIIOn:
+		return true
+	case config.ASCIIOff:
+		return false
+	}
+	switch strings.ToLower(getenv(asciiEnv)) {
+	case "1", "on", "true", "yes":
+		return true
+	case "0", "off", "false", "no":
+		return false
+	}
+	switch getenv("TERM") {
+	case "linux", "vt100", "vt220", "dumb":
+		return true
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return !strings.Contains(v, "utf-8") && !strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
+
+// setASCII switches ASCII-only mode on or off.
+func setASCII(on bool) {
+	asciiOnly = on
+}
+
+// asciiMarkers are the markers drawn in place of emoji and symbols that
+// mean something. A marker keeps the space after its symbol; other emoji
+// are decoration next to words saying the same, and go with theirs.
+var asciiMarkers = map[string]string{
+	"👉": ">", // keeps the width of the cursor, spaces and all
+	"☐": "[ ]",
+	"☑": "[x]",
+	"✓": "ok",
+	"⚠": "!",
+	"⛔": "!!",
+	"🎫": "ref",
+	"🌐": "public",
+	"🙈": "private",
+	"📄": "[pdf]",
+	"🖼": "[img]",
+	"📎": "[file]",
+}
+
+// asciiReplacer draws the symbols of the interface in ASCII: stars,
+// arrows, separators, markers of the timeline, box drawing and the blocks
+// of bar charts. The ones drawn a column wide keep that width, so that
+// what lines up still does.
+var asciiReplacer = strings.NewReplacer(
+	"★", "*", "☆", ".",
+	"•", "-", "·", "-", "…", "...", "⋯", "...", "—", "--", "–", "-",
+	"→", "->", "←", "<-", "↑", "^", "↓", "v", "›", ">", "❯", ">",
+	"◀", "<", "▶", ">", "●", "o", "◆", "*", "▲", "^", "≈", "~",
+	"⟳ ", "", "⏸ ", "", "↶ ", "", "↷ ", "", "↶", "", "↷", "",
+	"°", "", "€", "EUR", "£", "GBP", "¥", "JPY", "₂", "2",
+	"\u00a0", " ", "\u202f", " ", "\u200d", "", "\ufe0f", "",
+	"─", "-", "━", "-", "═", "=", "│", "|", "┃", "|", "║", "|",
+	"╭", "+", "╮", "+", "╰", "+", "╯", "+", "┌", "+", "┐", "+", "└", "+", "┘", "+",
+	"├", "+", "┤", "+", "┬", "+", "┴", "+", "┼", "+", "╔", "+", "╗", "+", "╚", "+", "╝", "+",
+	"█", "#", "▉", "#", "▊", "#", "▋", "#", "▌", "#", "▍", "#", "▎", "#", "▏", "#",
+	"▓", "#", "▒", ":", "░", ".",
+	"▁", "_", "▂", ".", "▃", "-", "▄", "=", "▅", "+", "▆", "*", "▇", "#",
+)
+
+// moodFaces are the moods as ASCII emoticons, saddest first.
+var moodFaces = []string{":(", ":/", ":|", ":)", ":D"}
+
+// flagPattern matches a country flag, two regional indicator letters.
+var flagPattern = regexp.MustCompile("[\U0001F1E6-\U0001F1FF]{2}")
+
+// asciiText rewrites a rendered screen for ASCII-only mode.
+func asciiText(view string) string {
+	for mood := 1; mood <= models.MaxRating && mood <= len(moodFaces); mood++ {
+		view = strings.ReplaceAll(view, models.MoodFace(mood), moodFaces[mood-1])
+	}
+	view = flagPattern.ReplaceAllStringFunc(view, func(s string) string {
+		var code []rune
+		for _, r := range s {
+			code = append(code, 'A'+r-0x1F1E6)
+		}
+		return string(code)
+	})
+	view = asciiReplacer.Replace(view)
+	return symbolPattern.ReplaceAllStringFunc(view, func(s string) string {
+		m := symbolPattern.FindStringSubmatch(s)
+		marker, ok := asciiMarkers[m[1]]
+		switch {
+		case !ok:
+			return ""
+		case m[1] == "👉":
+			return marker + " " + m[2]
+		case m[2] != "":
+			return marker + " "
+		}
+		return marker
+	})
+}
+
+// glyphs draws s as the mode of rendering does, for laying out before the
+// screen is drawn whole: in ASCII in ASCII-only mode, as it is otherwise.
+func glyphs(s string) string {
+	if asciiOnly {
+		return asciiText(s)
+	}
+	return s
+}
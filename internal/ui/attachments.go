@@ -0,0 +1,311 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/viewer"
+)
+
+// attachmentList shows the files attached to a journal entry and opens
+// or previews them.
+type attachmentList struct {
+	app         *app
+	entry       *models.Entry
+	attachments []*models.Attachment
+	cursor      int
+
+	// adding is set while a path is being typed in.
+	adding bool
+	path   textinput.Model
+
+	width, height int
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newAttachmentList(app *app, entry *models.Entry) attachmentList {
+	in := newPathInput("")
+	in.Placeholder = "~/Pictures/IMG_0042.jpg"
+	in.Blur()
+	l := attachmentList{app: app, entry: entry, path: in}
+	l.reload()
+	return l
+}
+
+func (l attachmentList) Title() string { return tr("Attachments") }
+
+func (l attachmentList) Init() tea.Cmd {
+	return nil
+}
+
+func (l attachmentList) capturesEsc() bool { return l.confirmDelete || l.adding }
+
+func (l attachmentList) typing() bool { return l.adding }
+
+func (l attachmentList) help() []key.Binding {
+	if l.adding {
+		return []key.Binding{
+			fixed("enter", "copy the file into nomadic"),
+			l.app.bind("link", "link to the original file"),
+			fixed("esc", "cancel"),
+		}
+	}
+	return append([]key.Binding{
+		l.app.bind("up", "previous attachment"),
+		l.app.bind("down", "next attachment"),
+		l.app.bind("new", "attach a file"),
+		l.app.bind("open", "open in the default viewer"),
+		l.app.bind("preview", "preview the image in the terminal"),
+		l.app.bind("delete", "remove the attachment"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *attachmentList) reload() {
+	l.attachments, l.err = l.app.store.ListAttachmentsByEntry(l.app.ctx, l.entry.ID)
+	l.cursor = clamp(l.cursor, 0, len(l.attachments)-1)
+}
+
+func (l attachmentList) selected() *models.Attachment {
+	if len(l.attachments) == 0 {
+		return nil
+	}
+	return l.attachments[l.cursor]
+}
+
+// changed tells the screens below that the entry's attachments changed.
+func (l attachmentList) changed() tea.Cmd {
+	id := l.entry.ID
+	return func() tea.Msg { return attachmentsChangedMsg{entryID: id} }
+}
+
+func (l attachmentList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		l.width, l.height = msg.Width, msg.Height
+		return l, nil
+	case attachmentsChangedMsg:
+		if msg.entryID == l.entry.ID {
+			l.reload()
+		}
+		return l, nil
+	case historyMsg:
+		l.status = ""
+		l.reload()
+		return l, nil
+	case previewDoneMsg:
+		if msg.err != nil {
+			l.status, l.err = "", msg.err
+		}
+		return l, nil
+	case tea.KeyMsg:
+		if l.adding {
+			return l.updateAdding(msg)
+		}
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				a := l.selected()
+				if err := l.app.run(removeAttachment{attachment: a}); err != nil {
+					l.err = err
+					return l, nil
+				}
+				l.status = fmt.Sprintf("Removed %q • %s to undo", a.Name, l.app.keyHint("undo"))
+				return l, l.changed()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+
+		l.status, l.err = "", nil
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.attachments)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			l.adding = true
+			l.path.SetValue("")
+			return l, l.path.Focus()
+		case l.app.is(msg, "select"), l.app.is(msg, "open"):
+			if a := l.selected(); a != nil {
+				if err := viewer.Open(l.app.store.AttachmentPath(a)); err != nil {
+					l.err = fmt.Errorf("open %s: %w", a.Name, err)
+				} else {
+					l.status = "Opened " + a.Name
+				}
+			}
+		case l.app.is(msg, "preview"):
+			if a := l.selected(); a != nil {
+				return l, l.preview(a)
+			}
+		case l.app.is(msg, "delete"):
+			if l.selected() != nil {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+// updateAdding edits the path of a new attachment. Enter copies the file
+// into the data directory, the "link" key (ctrl+l) links to it instead.
+func (l attachmentList) updateAdding(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.String() == "esc":
+		l.adding = false
+		l.path.Blur()
+		return l, nil
+	case key.String() == "enter", l.app.is(key, "link"):
+		path := expandHome(strings.TrimSpace(l.path.Value()))
+		if path == "" {
+			l.err = errors.New("enter the path of a file to attach")
+			return l, nil
+		}
+		a, err := l.app.store.AttachFile(l.app.ctx, l.entry.ID, path, l.app.is(key, "link"))
+		if err != nil {
+			l.err = err
+			return l, nil
+		}
+		l.adding, l.err = false, nil
+		l.path.Blur()
+		l.status = "Attached " + a.Name
+		l.reload()
+		l.cursor = len(l.attachments) - 1
+		return l, l.changed()
+	}
+	var cmd tea.Cmd
+	l.path, cmd = l.path.Update(key)
+	return l, cmd
+}
+
+// preview hands the terminal to an image preview of a until Enter is
+// pressed.
+func (l attachmentList) preview(a *models.Attachment) tea.Cmd {
+	mode, err := viewer.ParseProtocol(l.app.cfg.ImagePreview)
+	if err != nil {
+		return func() tea.Msg { return previewDoneMsg{err: err} }
+	}
+	p := viewer.Detect(mode, os.Getenv)
+	if p == viewer.None {
+		return func() tea.Msg {
+			return previewDoneMsg{err: errors.New("this terminal has no known image protocol; set image_preview or press " + l.app.keyHint("open") + " to open")}
+		}
+	}
+	cmd := &previewCmd{
+		path:     l.app.store.AttachmentPath(a),
+		name:     a.Name,
+		protocol: p,
+		cols:     max(l.width-2, 20),
+		rows:     max(l.height-4, 8),
+	}
+	return tea.Exec(cmd, func(err error) tea.Msg { return previewDoneMsg{err: err} })
+}
+
+func (l attachmentList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("📎 "+l.entry.Title) + "\n\n")
+	if len(l.attachments) == 0 && !l.adding {
+		b.WriteString("No attachments yet — press " + l.app.keyHint("new") + " to attach a photo.\n")
+	}
+	for i, a := range l.attachments {
+		cursor := "  "
+		if i == l.cursor {
+			cursor = "👉"
+		}
+		note := formatSize(a.Size)
+		if a.Linked {
+			note += ", linked"
+		}
+		fmt.Fprintf(&b, "%s %s  %s\n", cursor, a.Name, hintStyle.Render(note))
+	}
+	if l.adding {
+		b.WriteString("\n" + labelStyle.Render("File to attach") + "\n" + l.path.View() + "\n")
+	}
+	if l.err != nil {
+		b.WriteString("\n" + errorStyle.Render(l.err.Error()) + "\n")
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Remove %q? y/n", l.selected().Name)) + "\n")
+	}
+	hint := l.app.keyHint("new") + " attach • enter/" + l.app.keyHint("open") + " open • " +
+		l.app.keyHint("preview") + " preview • " + l.app.keyHint("delete") + " remove • " +
+		l.app.keyHint("undo") + " undo • esc back"
+	if l.adding {
+		hint = "enter copy into nomadic • " + l.app.keyHint("link") + " link to the original • esc cancel"
+	}
+	b.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return b.String()
+}
+
+// previewDoneMsg reports how an image preview ended.
+type previewDoneMsg struct {
+	err error
+}
+
+// previewCmd draws an image straight to the terminal while the program is
+// suspended, since image escape sequences cannot live inside a view.
+type previewCmd struct {
+	path       string
+	name       string
+	protocol   viewer.Protocol
+	cols, rows int
+
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+func (c *previewCmd) SetStdin(r io.Reader)  { c.stdin = r }
+func (c *previewCmd) SetStdout(w io.Writer) { c.stdout = w }
+func (c *previewCmd) SetStderr(w io.Writer) { c.stderr = w }
+
+func (c *previewCmd) Run() error {
+	fmt.Fprint(c.stdout, "\x1b[2J\x1b[H"+c.name+"\n\n")
+	if err := viewer.Preview(c.stdout, c.path, c.protocol, c.cols, c.rows); err != nil {
+		return err
+	}
+	fmt.Fprint(c.stdout, "\nPress enter to return")
+	bufio.NewReader(c.stdin).ReadString('\n')
+	viewer.Clear(c.stdout, c.protocol)
+	return nil
+}
+
+// formatSize renders a byte count for people.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.0f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// attachmentCount describes how many files are attached to an entry, or
+// is empty when there are none.
+func attachmentCount(a *app, entryID string) string {
+	list, err := a.store.ListAttachmentsByEntry(a.ctx, entryID)
+	if err != nil || len(list) == 0 {
+		return ""
+	}
+	return "📎 " + plural(len(list), "attachment", "attachments")
+}
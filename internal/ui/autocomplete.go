@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxSuggestions is how many suggestions an autocomplete lists at once.
+const maxSuggestions = 5
+
+// suggestion is an item an autocomplete offers for what is being typed.
+type suggestion struct {
+	value  string // entered when accepted
+	detail string
+	// positions are the runes of value matched, highlighted in the list.
+	positions []int
+}
+
+// autocomplete lists suggestions under a text input for what is being
+// typed, best first, from source. With list set it completes the last of
+// comma-separated values, with words the last of space-separated words,
+// otherwise the whole value. → accepts the
+// highlighted suggestion and ctrl+n/ctrl+p move between them, as for tags.
+type autocomplete struct {
+	source func(typed string) []suggestion
+	list   bool
+	words  bool
+	pick   int
+}
+
+// partial splits value into what comes before the value being typed and
+// that value itself.
+func (c *autocomplete) partial(value string) (prefix, typed string) {
+	if c.words {
+		i := strings.LastIndex(value, " ")
+		return value[:i+1], value[i+1:]
+	}
+	if !c.list {
+		return "", value
+	}
+	if i := strings.LastIndex(value, ","); i >= 0 {
+		return strings.TrimRight(value[:i+1], " ") + " ", value[i+1:]
+	}
+	return "", value
+}
+
+// matches returns the suggestions for what is being typed, unless it is
+// already what the best of them would enter.
+func (c *autocomplete) matches(value string) []suggestion {
+	_, typed := c.partial(value)
+	typed = strings.TrimSpace(typed)
+	if typed == "" {
+		return nil
+	}
+	matches := c.source(typed)
+	if len(matches) > maxSuggestions {
+		matches = matches[:maxSuggestions]
+	}
+	if len(matches) > 0 && strings.EqualFold(matches[0].value, typed) {
+		return nil
+	}
+	return matches
+}
+
+// update handles the completion keys for in. It reports whether key was
+// one of them; other keys are left for the input.
+func (c *autocomplete) update(in *textinput.Model, key tea.KeyMsg) bool {
+	matches := c.matches(in.Value())
+	if len(matches) == 0 {
+		c.pick = 0
+		return false
+	}
+	c.pick = clamp(c.pick, 0, len(matches)-1)
+	switch key.String() {
+	case "ctrl+n":
+		c.pick = (c.pick + 1) % len(matches)
+	case "ctrl+p":
+		c.pick = (c.pick + len(matches) - 1) % len(matches)
+	case "right":
+		if in.Position() < len([]rune(in.Value())) {
+			return false
+		}
+		prefix, _ := c.partial(in.Value())
+		in.SetValue(prefix + matches[c.pick].value)
+		in.CursorEnd()
+		c.pick = 0
+	default:
+		return false
+	}
+	return true
+}
+
+// view renders the suggestions for value one per line, the highlighted
+// one marked, or nothing when there are none.
+func (c *autocomplete) view(value string) string {
+	matches := c.matches(value)
+	if len(matches) == 0 {
+		return ""
+	}
+	pick := clamp(c.pick, 0, len(matches)-1)
+	var b strings.Builder
+	for i, m := range matches {
+		style, mark := hintStyle.Render, "  "
+		if i == pick {
+			style, mark = cursorStyle.Render, cursorStyle.Render("› ")
+		}
+		b.WriteString(mark + markMatched(m.value, m.positions, 0, style))
+		if m.detail != "" {
+			b.WriteString(hintStyle.Render(", " + m.detail))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(hintStyle.Render("→ complete • ctrl+n/p choose"))
+	return b.String()
+}
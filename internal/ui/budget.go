@@ -0,0 +1,269 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/budget"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const (
+	budgetFieldTotal = iota
+	budgetFieldCurrency
+	budgetFieldPerDiem
+	// budgetFieldCategories is the first of one field per models.Categories.
+	budgetFieldCategories
+)
+
+const budgetBarWidth = 20
+
+// budgetForm edits a trip's total and per-category budgets and its per
+// diem.
+type budgetForm struct {
+	form
+	app  *app
+	trip *models.Trip
+}
+
+func newBudgetForm(app *app, trip *models.Trip) budgetForm {
+	fields := []field{
+		newField("Trip budget", "1500", "Optional. Total amount you plan to spend.", validateAmount),
+		newField("Budget currency", app.cfg.HomeCurrency, "Expenses in other currencies are converted into it.", validateCurrency),
+		newField("Per diem", "80", "Optional. A fixed daily allowance, as for work travel, to spend each day against.", validateAmount),
+	}
+	for _, c := range models.Categories {
+		fields = append(fields, newField(strings.ToUpper(c[:1])+c[1:]+" budget", "", "Optional.", validateAmount))
+	}
+	f := newForm("💰 Budget", fields...)
+	f.fields[budgetFieldTotal].input.SetValue(formatLimit(trip.Budget))
+	f.fields[budgetFieldCurrency].input.SetValue(app.budgetCurrency(trip))
+	f.fields[budgetFieldPerDiem].input.SetValue(formatLimit(trip.PerDiem))
+	for i, c := range models.Categories {
+		f.fields[budgetFieldCategories+i].input.SetValue(formatLimit(trip.CategoryBudgets[c]))
+	}
+	// Work on a copy so cancelling leaves the caller's trip untouched.
+	edited := *trip
+	return budgetForm{form: f, app: app, trip: &edited}
+}
+
+func (f budgetForm) Title() string { return tr("Budget") }
+
+func (f budgetForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f budgetForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		err := f.apply()
+		if err == nil {
+			err = f.app.store.SaveTrip(f.app.ctx, f.trip)
+		}
+		if err != nil {
+			f.err = err
+			return f, nil
+		}
+		t := f.trip
+		return f, tea.Sequence(pop, func() tea.Msg { return tripSavedMsg{trip: t} })
+	}
+	return f, cmd
+}
+
+func (f budgetForm) View() string {
+	return f.form.view(f.summary)
+}
+
+// apply copies the validated values into the trip.
+func (f budgetForm) apply() error {
+	total, err := parseLimit(f.value(budgetFieldTotal))
+	if err != nil {
+		return err
+	}
+	currency, err := models.ParseCurrency(f.value(budgetFieldCurrency))
+	if err != nil {
+		return err
+	}
+	perDiem, err := parseLimit(f.value(budgetFieldPerDiem))
+	if err != nil {
+		return err
+	}
+	categories := map[string]float64{}
+	for i, c := range models.Categories {
+		limit, err := parseLimit(f.value(budgetFieldCategories + i))
+		if err != nil {
+			return err
+		}
+		if limit > 0 {
+			categories[c] = limit
+		}
+	}
+	if len(categories) == 0 {
+		categories = nil
+	}
+	f.trip.Budget, f.trip.BudgetCurrency, f.trip.CategoryBudgets = total, currency, categories
+	f.trip.PerDiem = perDiem
+	return nil
+}
+
+func (f budgetForm) summary() string {
+	var b strings.Builder
+	currency := strings.ToUpper(f.value(budgetFieldCurrency))
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		} else {
+			value += " " + currency
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-12s", label+":")), value)
+	}
+	row("Trip", f.value(budgetFieldTotal))
+	row("Per diem", f.value(budgetFieldPerDiem))
+	for i, c := range models.Categories {
+		row(c, f.value(budgetFieldCategories+i))
+	}
+	return b.String()
+}
+
+func parseLimit(v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+func formatLimit(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// budgetCurrency is the currency a trip's budget is kept in.
+func (a *app) budgetCurrency(t *models.Trip) string {
+	if t.BudgetCurrency != "" {
+		return t.BudgetCurrency
+	}
+	return a.cfg.HomeCurrency
+}
+
+// budgetView renders budget progress, warnings and the projected spend.
+// It is empty when the trip has no budget and no end date to project to.
+func budgetView(r budget.Report, ratesNote string) string {
+	if r.Total.Limit == 0 && len(r.Categories) == 0 && !r.HasProjection {
+		return ""
+	}
+	var b strings.Builder
+	lines := r.Categories
+	if r.Total.Limit > 0 {
+		lines = append([]budget.Line{r.Total}, lines...)
+	}
+	for _, l := range lines {
+		name := l.Category
+		if name == "" {
+			name = "Budget"
+		}
+		fmt.Fprintf(&b, "%s %s %4.0f%%  %s / %s", labelStyle.Render(fmt.Sprintf("%-10s", name)),
+			budgetBar(l), l.Ratio()*100, formatAmount(l.Spent, r.Currency), formatAmount(l.Limit, r.Currency))
+		switch l.Level() {
+		case budget.Over:
+			b.WriteString("  " + errorStyle.Render("⛔ over by "+formatAmount(l.Spent-l.Limit, r.Currency)))
+		case budget.Warning:
+			b.WriteString("  " + warningStyle.Render("⚠ close to the limit"))
+		}
+		b.WriteString("\n")
+	}
+	if r.HasProjection {
+		line := fmt.Sprintf("Projected %s at %s a day", formatAmount(r.Projected, r.Currency), formatAmount(r.DailyBurn, r.Currency))
+		switch {
+		case r.Total.Limit > 0 && r.Projected > r.Total.Limit:
+			b.WriteString(warningStyle.Render("⚠ "+line+", "+formatAmount(r.Projected-r.Total.Limit, r.Currency)+" over budget") + "\n")
+		case r.Total.Limit > 0:
+			b.WriteString(successStyle.Render("✓ "+line+", within budget") + "\n")
+		default:
+			b.WriteString(hintStyle.Render(line) + "\n")
+		}
+	}
+	if r.Unconverted > 0 {
+		b.WriteString(hintStyle.Render(plural(r.Unconverted, "expense", "expenses")+" in other currencies not counted"+ratesNote) + "\n")
+	}
+	return b.String()
+}
+
+func budgetBar(l budget.Line) string {
+	filled := int(min(l.Ratio(), 1)*budgetBarWidth + 0.5)
+	style := successStyle
+	switch l.Level() {
+	case budget.Over:
+		style = errorStyle
+	case budget.Warning:
+		style = warningStyle
+	}
+	return style.Render(strings.Repeat("█", filled)) + hintStyle.Render(strings.Repeat("░", budgetBarWidth-filled))
+}
+
+// perDiemView renders how the trip stands against its per diem today and
+// so far.
+func perDiemView(p budget.PerDiem, ratesNote string) string {
+	if len(p.Days) == 0 {
+		return "\n" + hintStyle.Render("Per diem "+formatAmount(p.Allowance, p.Currency)+" a day, from the first day of the trip") + "\n"
+	}
+	today := p.Days[len(p.Days)-1]
+	line := func(label string, balance float64) string {
+		style := successStyle
+		if balance < 0 {
+			style = errorStyle
+		}
+		return style.Render(label + " " + budget.Standing(balance, p.Currency))
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n%s %s a day  %s  %s\n", labelStyle.Render(fmt.Sprintf("%-10s", "Per diem")),
+		formatAmount(p.Allowance, p.Currency), line("today", today.Balance()),
+		line(fmt.Sprintf("after %s", plural(len(p.Days), "day", "days")), p.Balance()))
+	if p.Unconverted > 0 {
+		b.WriteString(hintStyle.Render(plural(p.Unconverted, "expense", "expenses")+" in other currencies not counted"+ratesNote) + "\n")
+	}
+	return b.String()
+}
+
+// perDiemStanding renders a balance against a per diem, green and signed
+// + under it, red and - over.
+func perDiemStanding(label string, balance float64, currency string) string {
+	style, sign := successStyle, "+"
+	if balance < 0 {
+		style, sign = errorStyle, "-"
+	}
+	return style.Render(label + " " + sign + formatAmount(math.Abs(balance), currency))
+}
+
+// perDiemReport compares each day of the trip so far with its per diem,
+// converting with the loaded exchange rates when there are any.
+func (l expenseList) perDiemReport() budget.PerDiem {
+	var convert budget.Converter
+	if l.rates != nil {
+		convert = l.rates.Convert
+	}
+	return budget.ComputePerDiem(l.trip, l.expenses, l.app.budgetCurrency(l.trip), convert, l.app.tripNow(l.trip))
+}
+
+// budgetReport computes the trip's budget report, converting with the
+// loaded exchange rates when there are any.
+func (l expenseList) budgetReport() budget.Report {
+	var convert budget.Converter
+	if l.rates != nil {
+		convert = l.rates.Convert
+	}
+	return budget.Compute(l.trip, l.expenses, l.app.budgetCurrency(l.trip), convert, time.Now())
+}
@@ -0,0 +1,641 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// bulkSummaryRows is how many of the items a bulk action changes its
+// summary lists before counting the rest.
+const bulkSummaryRows = 8
+
+// marks are the IDs of the items of a list marked for a bulk action. They
+// are copied on change, as the screens holding them are values.
+type marks map[string]bool
+
+// toggle marks id, or unmarks it when it was marked.
+func (m *marks) toggle(id string) {
+	next := maps.Clone(*m)
+	if next == nil {
+		next = marks{}
+	}
+	if next[id] {
+		delete(next, id)
+	} else {
+		next[id] = true
+	}
+	*m = next
+}
+
+// all marks every one of ids, or unmarks them when they all were.
+func (m *marks) all(ids []string) {
+	if len(ids) > 0 && len(*m) == len(ids) && !slices.ContainsFunc(ids, func(id string) bool { return !(*m)[id] }) {
+		*m = nil
+		return
+	}
+	next := make(marks, len(ids))
+	for _, id := range ids {
+		next[id] = true
+	}
+	*m = next
+}
+
+// keep unmarks the items not among ids, such as those filtered out.
+func (m marks) keep(ids []string) marks {
+	if len(m) == 0 {
+		return m
+	}
+	next := marks{}
+	for _, id := range ids {
+		if m[id] {
+			next[id] = true
+		}
+	}
+	if len(next) == 0 {
+		return nil
+	}
+	return next
+}
+
+// box is drawn before a row of a list while any are marked, ticked when
+// the row is.
+func (m marks) box(id string) string {
+	switch {
+	case len(m) == 0:
+		return ""
+	case m[id]:
+		return "☑ "
+	}
+	return "☐ "
+}
+
+// bulkAppliedMsg is sent once a bulk action has been applied, for the list
+// to clear its marks.
+type bulkAppliedMsg struct {
+	status string
+}
+
+// bulkAction is what can be done at once to the items marked in a list.
+type bulkAction int
+
+const (
+	bulkDelete bulkAction = iota
+	bulkRetag
+	bulkMove
+	bulkCategory
+	bulkExport
+)
+
+func (a bulkAction) String() string {
+	return [...]string{"Delete", "Retag", "Move to another trip", "Change category", "Export"}[a]
+}
+
+// bulkEdit applies an action to the expenses or the journal entries
+// marked in a list: it deletes, retags or moves them to another trip,
+// files expenses under another category, or exports them. A summary of
+// what changes is confirmed before anything does, and the change is undone
+// as one.
+type bulkEdit struct {
+	app      *app
+	expenses []*models.Expense
+	entries  []*models.Entry
+	// trips are every trip, for moving the items and naming theirs.
+	trips []*models.Trip
+
+	actions []bulkAction
+	cursor  int
+	// action is the action chosen, once it is; confirming is set once its
+	// options are too.
+	action     *bulkAction
+	confirming bool
+
+	// pick is the trip or category under the cursor, of targets or of
+	// models.Categories.
+	pick    int
+	targets []*models.Trip
+	// input holds the tags or the file exported to, with complete
+	// completing the tags; shared are the tags every item carries.
+	input    textinput.Model
+	complete *tagCompleter
+	shared   []string
+
+	err error
+}
+
+// newExpenseBulkEdit acts on the expenses marked in a list.
+func newExpenseBulkEdit(app *app, expenses []*models.Expense) bulkEdit {
+	b := newBulkEdit(app)
+	b.expenses = expenses
+	b.actions = []bulkAction{bulkDelete, bulkRetag, bulkMove, bulkCategory, bulkExport}
+	return b.allowed()
+}
+
+// newEntryBulkEdit acts on the journal entries marked in a list.
+func newEntryBulkEdit(app *app, entries []*models.Entry) bulkEdit {
+	b := newBulkEdit(app)
+	b.entries = entries
+	b.actions = []bulkAction{bulkDelete, bulkRetag, bulkMove, bulkExport}
+	return b.allowed()
+}
+
+func newBulkEdit(app *app) bulkEdit {
+	b := bulkEdit{app: app}
+	b.trips, b.err = app.store.ListTrips(app.ctx)
+	return b
+}
+
+// allowed leaves out the actions changing the journal in read-only mode.
+func (b bulkEdit) allowed() bulkEdit {
+	if b.app.readOnly() {
+		b.actions = []bulkAction{bulkExport}
+	}
+	return b
+}
+
+func (b bulkEdit) Title() string { return tr("Bulk edit") }
+
+func (b bulkEdit) Init() tea.Cmd {
+	return nil
+}
+
+func (b bulkEdit) capturesEsc() bool { return b.action != nil }
+
+func (b bulkEdit) typing() bool {
+	return b.action != nil && !b.confirming && (*b.action == bulkRetag || *b.action == bulkExport)
+}
+
+func (b bulkEdit) help() []key.Binding {
+	switch {
+	case b.confirming:
+		return []key.Binding{fixed("y/enter", "apply"), fixed("n/esc", "go back")}
+	case b.typing():
+		keys := []key.Binding{fixed("enter", "go on to the summary"), fixed("esc", "choose another action")}
+		if *b.action == bulkRetag {
+			keys = append(keys, completionHelp()...)
+		}
+		return keys
+	case b.action != nil:
+		return []key.Binding{b.app.bind("up", "previous"), b.app.bind("down", "next"),
+			b.app.bind("select", "go on to the summary"), fixed("esc", "choose another action")}
+	}
+	return []key.Binding{b.app.bind("up", "previous action"), b.app.bind("down", "next action"),
+		b.app.bind("select", "choose the action")}
+}
+
+// count is the items acted on, such as "3 expenses".
+func (b bulkEdit) count() string {
+	if b.entries != nil {
+		return plural(len(b.entries), "entry", "entries")
+	}
+	return plural(len(b.expenses), "expense", "expenses")
+}
+
+// choose goes on from choosing action to its options, or for deleting
+// straight to the summary.
+func (b bulkEdit) choose(action bulkAction) (bulkEdit, tea.Cmd) {
+	b.action, b.pick, b.err = &action, 0, nil
+	switch action {
+	case bulkDelete:
+		b.confirming = true
+	case bulkRetag:
+		b.shared = b.sharedTags()
+		b.input = textinput.New()
+		b.input.Placeholder = "food, friends"
+		b.input.Width = 40
+		b.input.SetValue(strings.Join(b.shared, ", "))
+		b.input.CursorEnd()
+		b.complete = newTagCompleter(b.app)
+		return b, b.input.Focus()
+	case bulkMove:
+		b.targets = nil
+		from := b.tripIDs()
+		for _, t := range b.trips {
+			if len(from) != 1 || t.ID != from[0] {
+				b.targets = append(b.targets, t)
+			}
+		}
+		if len(b.targets) == 0 {
+			b.action, b.err = nil, errors.New("there is no other trip to move to")
+		}
+	case bulkCategory:
+		categories := map[string]bool{}
+		for _, x := range b.expenses {
+			categories[x.Category] = true
+		}
+		if len(categories) == 1 {
+			b.pick = max(slices.Index(models.Categories, b.expenses[0].Category), 0)
+		}
+	case bulkExport:
+		b.input = newPathInput(b.exportPath())
+		b.input.CursorEnd()
+		return b, textinput.Blink
+	}
+	return b, nil
+}
+
+func (b bulkEdit) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	k, ok := msg.(tea.KeyMsg)
+	if !ok {
+		if b.typing() {
+			var cmd tea.Cmd
+			b.input, cmd = b.input.Update(msg)
+			return b, cmd
+		}
+		return b, nil
+	}
+	switch {
+	case b.confirming:
+		switch k.String() {
+		case "y", "enter":
+			return b.apply()
+		case "n", "esc":
+			b.confirming = false
+			if *b.action == bulkDelete {
+				b.action = nil
+			}
+		}
+		return b, nil
+	case b.action == nil:
+		switch {
+		case b.app.is(k, "up"):
+			b.cursor = max(b.cursor-1, 0)
+		case b.app.is(k, "down"):
+			b.cursor = min(b.cursor+1, len(b.actions)-1)
+		case b.app.is(k, "select"):
+			return b.choose(b.actions[b.cursor])
+		}
+		return b, nil
+	case k.String() == "esc":
+		b.action, b.err = nil, nil
+		return b, nil
+	case b.typing():
+		if k.String() == "enter" {
+			b.err = b.check()
+			b.confirming = b.err == nil
+			return b, nil
+		}
+		if *b.action == bulkRetag && b.complete.update(&b.input, k) {
+			return b, nil
+		}
+		var cmd tea.Cmd
+		b.input, cmd = b.input.Update(k)
+		return b, cmd
+	}
+	n := len(b.targets)
+	if *b.action == bulkCategory {
+		n = len(models.Categories)
+	}
+	switch {
+	case b.app.is(k, "up"):
+		b.pick = max(b.pick-1, 0)
+	case b.app.is(k, "down"):
+		b.pick = min(b.pick+1, n-1)
+	case b.app.is(k, "select"):
+		b.err = b.check()
+		b.confirming = b.err == nil
+	}
+	return b, nil
+}
+
+// check reports why the options chosen would change nothing.
+func (b bulkEdit) check() error {
+	switch *b.action {
+	case bulkRetag:
+		if add, remove := b.retag(); len(add) == 0 && len(remove) == 0 {
+			return errors.New("add or remove a tag first")
+		}
+	case bulkCategory:
+		c := models.Categories[b.pick]
+		if !slices.ContainsFunc(b.expenses, func(x *models.Expense) bool { return x.Category != c }) {
+			return fmt.Errorf("every one is under %s already", c)
+		}
+	case bulkExport:
+		if strings.TrimSpace(b.input.Value()) == "" {
+			return errors.New("enter a file to export to")
+		}
+	}
+	return nil
+}
+
+// apply does the action confirmed and goes back to the list.
+func (b bulkEdit) apply() (tea.Model, tea.Cmd) {
+	status, err := b.do()
+	if err != nil {
+		b.err, b.confirming = err, false
+		if *b.action == bulkDelete {
+			b.action = nil
+		}
+		return b, nil
+	}
+	return b, tea.Sequence(pop, notify(bulkAppliedMsg{status: status}))
+}
+
+// do does the action confirmed and returns the status telling of it.
+func (b bulkEdit) do() (string, error) {
+	undo := " • " + b.app.keyHint("undo") + " to undo"
+	switch *b.action {
+	case bulkDelete:
+		c := batch{desc: "delete " + b.count()}
+		for _, x := range b.expenses {
+			c.commands = append(c.commands, &deleteExpense{expense: x})
+		}
+		for _, e := range b.entries {
+			c.commands = append(c.commands, &deleteEntry{entry: e})
+		}
+		return "Moved " + b.count() + " to the trash" + undo, b.app.run(c)
+	case bulkExport:
+		path := expandHome(strings.TrimSpace(b.input.Value()))
+		return "Wrote " + b.count() + " to " + path, b.export(path)
+	}
+	var desc, status string
+	var add, remove []string
+	var to *models.Trip
+	switch *b.action {
+	case bulkRetag:
+		add, remove = b.retag()
+		desc, status = "retag "+b.count(), "Retagged "+b.count()
+	case bulkMove:
+		to = b.targets[b.pick]
+		desc, status = fmt.Sprintf("move %s to %q", b.count(), to.Title), "Moved "+b.count()+" to "+to.Title
+	case bulkCategory:
+		c := models.Categories[b.pick]
+		desc, status = "file "+b.count()+" under "+c, "Filed "+b.count()+" under "+c
+	}
+	retagged := func(tags []string) []string {
+		return append(slices.DeleteFunc(slices.Clone(tags), func(t string) bool { return slices.Contains(remove, t) }), add...)
+	}
+	if b.entries != nil {
+		after := make([]*models.Entry, len(b.entries))
+		for i, e := range b.entries {
+			copied := *e
+			switch *b.action {
+			case bulkRetag:
+				copied.Tags = retagged(e.Tags)
+			case bulkMove:
+				// Legs belong to the trip left.
+				copied.TripID, copied.LegID = to.ID, ""
+			}
+			after[i] = &copied
+		}
+		return status + undo, b.app.run(saveEntries{before: b.entries, after: after, desc: desc})
+	}
+	after := make([]*models.Expense, len(b.expenses))
+	for i, x := range b.expenses {
+		copied := *x
+		switch *b.action {
+		case bulkRetag:
+			copied.Tags = retagged(x.Tags)
+		case bulkMove:
+			// Legs and recurring expenses belong to the trip left.
+			copied.TripID, copied.LegID, copied.RecurrenceID = to.ID, "", ""
+		case bulkCategory:
+			copied.Category = models.Categories[b.pick]
+		}
+		after[i] = &copied
+	}
+	return status + undo, b.app.run(saveExpenses{before: b.expenses, after: after, desc: desc})
+}
+
+// sharedTags are the tags every item carries.
+func (b bulkEdit) sharedTags() []string {
+	var lists [][]string
+	for _, x := range b.expenses {
+		lists = append(lists, x.Tags)
+	}
+	for _, e := range b.entries {
+		lists = append(lists, e.Tags)
+	}
+	if len(lists) == 0 {
+		return nil
+	}
+	shared := slices.Clone(lists[0])
+	for _, tags := range lists[1:] {
+		shared = slices.DeleteFunc(shared, func(t string) bool { return !slices.Contains(tags, t) })
+	}
+	return shared
+}
+
+// retag compares the tags typed in with those every item carries: those
+// added go on every item, those removed come off every item, and the tags
+// only some carry stay where they are.
+func (b bulkEdit) retag() (add, remove []string) {
+	tags := models.NormalizeTags(splitList(b.input.Value()))
+	for _, t := range tags {
+		if !slices.Contains(b.shared, t) {
+			add = append(add, t)
+		}
+	}
+	for _, t := range b.shared {
+		if !slices.Contains(tags, t) {
+			remove = append(remove, t)
+		}
+	}
+	return add, remove
+}
+
+// tripIDs are the trips of the items, in the order first met.
+func (b bulkEdit) tripIDs() []string {
+	var ids []string
+	for _, x := range b.expenses {
+		if !slices.Contains(ids, x.TripID) {
+			ids = append(ids, x.TripID)
+		}
+	}
+	for _, e := range b.entries {
+		if !slices.Contains(ids, e.TripID) {
+			ids = append(ids, e.TripID)
+		}
+	}
+	return ids
+}
+
+func (b bulkEdit) trip(id string) *models.Trip {
+	for _, t := range b.trips {
+		if t.ID == id {
+			return t
+		}
+	}
+	return &models.Trip{ID: id}
+}
+
+// exportPath is the file exported to unless another is typed in: a CSV
+// file of the expenses, or a Markdown document of the entries, in the
+// working directory.
+func (b bulkEdit) exportPath() string {
+	name := "journal"
+	if ids := b.tripIDs(); len(ids) == 1 {
+		name = export.Slug(b.trip(ids[0]).Title)
+	}
+	if b.entries == nil {
+		name += "-expenses.csv"
+	} else {
+		name += "-entries.md"
+	}
+	if wd, err := os.Getwd(); err == nil {
+		name = filepath.Join(wd, name)
+	}
+	return name
+}
+
+// export writes the expenses as CSV, or the entries as Markdown with a
+// document for each of their trips, to path.
+func (b bulkEdit) export(path string) error {
+	var trips []*export.Trip
+	for _, id := range b.tripIDs() {
+		t := &export.Trip{Trip: b.trip(id)}
+		for _, x := range b.expenses {
+			if x.TripID == id {
+				t.Expenses = append(t.Expenses, x)
+			}
+		}
+		for _, e := range b.entries {
+			if e.TripID == id {
+				t.Entries = append(t.Entries, e)
+			}
+		}
+		trips = append(trips, t)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if b.entries == nil {
+		err = export.WriteExpensesCSV(f, trips)
+	} else {
+		for i, t := range trips {
+			if i > 0 {
+				fmt.Fprintln(f)
+			}
+			if err = export.Markdown(f, t.Redact(b.app.redaction()), b.app.cfg.Layout()); err != nil {
+				break
+			}
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (b bulkEdit) View() string {
+	var s strings.Builder
+	s.WriteString(headerStyle.Render("☑ "+b.count()+" marked") + "\n\n")
+	if b.err != nil {
+		s.WriteString(errorStyle.Render(b.err.Error()) + "\n\n")
+	}
+	switch {
+	case b.confirming:
+		s.WriteString(b.summary())
+		s.WriteString("\n" + hintStyle.Render("y/enter apply • n/esc back") + "\n")
+		return s.String()
+	case b.action == nil:
+		s.WriteString(labelStyle.Render("Do what with them?") + "\n")
+		for i, a := range b.actions {
+			cursor, name := "  ", a.String()
+			if a == bulkExport && b.entries == nil {
+				name += " as CSV"
+			} else if a == bulkExport {
+				name += " as Markdown"
+			}
+			if i == b.cursor {
+				cursor, name = "👉", cursorStyle.Render(name)
+			}
+			s.WriteString(cursor + " " + name + "\n")
+		}
+		s.WriteString("\n" + hintStyle.Render("↑/↓ move • enter choose • esc back") + "\n")
+		return s.String()
+	}
+	switch *b.action {
+	case bulkRetag:
+		s.WriteString(labelStyle.Render("Tags") + "\n" + b.input.View() + "\n")
+		if c := b.complete.view(b.input.Value()); c != "" {
+			s.WriteString(c + "\n")
+		}
+		s.WriteString(hintStyle.Render("These are the tags every one carries: those added go on all of them, those removed come off. Tags only some carry stay.") + "\n")
+	case bulkMove:
+		s.WriteString(labelStyle.Render("Move to") + "\n")
+		from, to := window(len(b.targets), b.pick, bulkSummaryRows)
+		for i, t := range b.targets[from:to] {
+			cursor, title := "  ", t.Title
+			if from+i == b.pick {
+				cursor, title = "👉", cursorStyle.Render(title)
+			}
+			s.WriteString(cursor + " " + title + "  " + hintStyle.Render(tripDates(b.app, t)) + "\n")
+		}
+	case bulkCategory:
+		s.WriteString(labelStyle.Render("File under") + "\n")
+		for i, c := range models.Categories {
+			cursor := "  "
+			if i == b.pick {
+				cursor = "👉"
+			}
+			s.WriteString(cursor + " " + categoryLabel(c, 0) + "\n")
+		}
+	case bulkExport:
+		s.WriteString(labelStyle.Render("File") + "\n" + b.input.View() + "\n")
+	}
+	s.WriteString("\n" + hintStyle.Render("enter summary • esc choose another action") + "\n")
+	return s.String()
+}
+
+// summary tells what the action confirmed changes, and of which items.
+func (b bulkEdit) summary() string {
+	var s strings.Builder
+	switch *b.action {
+	case bulkDelete:
+		s.WriteString(errorStyle.Render("Move "+b.count()+" to the trash?") + "\n")
+	case bulkRetag:
+		add, remove := b.retag()
+		var changes []string
+		if len(add) > 0 {
+			changes = append(changes, "add "+formatTags(add))
+		}
+		if len(remove) > 0 {
+			changes = append(changes, "remove "+formatTags(remove))
+		}
+		s.WriteString(labelStyle.Render(fmt.Sprintf("Retag %s: %s?", b.count(), strings.Join(changes, ", "))) + "\n")
+	case bulkMove:
+		s.WriteString(labelStyle.Render(fmt.Sprintf("Move %s to %s?", b.count(), b.targets[b.pick].Title)) + "\n")
+		s.WriteString(hintStyle.Render("They leave the legs of their trip behind.") + "\n")
+	case bulkCategory:
+		s.WriteString(labelStyle.Render(fmt.Sprintf("File %s under %s?", b.count(), models.Categories[b.pick])) + "\n")
+	case bulkExport:
+		s.WriteString(labelStyle.Render(fmt.Sprintf("Write %s to %s?", b.count(), expandHome(strings.TrimSpace(b.input.Value())))) + "\n")
+	}
+	s.WriteString("\n")
+	var rows []string
+	for _, x := range b.expenses {
+		rows = append(rows, fmt.Sprintf("%s  %s %12s  %s %s", b.app.formatDate(x.Timestamp), categoryLabel(x.Category, 10),
+			formatAmount(x.Amount, x.Currency), x.Description, hintStyle.Render(formatTags(x.Tags))))
+	}
+	everyTrip := len(b.tripIDs()) > 1
+	for _, e := range b.entries {
+		extra := formatTags(e.Tags)
+		if everyTrip {
+			extra = strings.TrimSpace("🧳 " + b.trip(e.TripID).Title + "  " + extra)
+		}
+		rows = append(rows, fmt.Sprintf("%s  %s  %s", b.app.formatDate(e.Timestamp), e.Title, hintStyle.Render(extra)))
+	}
+	for _, row := range rows[:min(len(rows), bulkSummaryRows)] {
+		s.WriteString("  " + row + "\n")
+	}
+	if len(rows) > bulkSummaryRows {
+		s.WriteString(hintStyle.Render(fmt.Sprintf("  … and %d more", len(rows)-bulkSummaryRows)) + "\n")
+	}
+	if len(b.expenses) > 0 {
+		s.WriteString("\n" + labelStyle.Render("Total") + "  " + strings.Join(totalsByCurrency(b.expenses), " + ") + "\n")
+	}
+	return s.String()
+}
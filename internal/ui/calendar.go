@@ -0,0 +1,291 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// calendarView is a month calendar marking the days covered by trips and
+// the days with journal entries.
+type calendarView struct {
+	app     *app
+	day     time.Time // selected day, at midnight
+	trips   []*models.Trip
+	entries []*models.Entry
+	// pick indexes the entries and trips of the selected day, in the
+	// order they are listed under the calendar.
+	pick int
+	err  error
+}
+
+// calendarItem is something on the selected day: an entry or a trip.
+type calendarItem struct {
+	entry *models.Entry
+	trip  *models.Trip
+}
+
+func newCalendarView(app *app) calendarView {
+	c := calendarView{app: app, day: app.today()}
+	c.reload()
+	return c
+}
+
+func (c calendarView) Title() string { return tr("Calendar") }
+
+func (c calendarView) Init() tea.Cmd {
+	return nil
+}
+
+func (c calendarView) help() []key.Binding {
+	return []key.Binding{
+		c.app.bind("left", "previous day"),
+		c.app.bind("right", "next day"),
+		c.app.bind("up", "previous week"),
+		c.app.bind("down", "next week"),
+		c.app.bind("prev_month", "previous month"),
+		c.app.bind("next_month", "next month"),
+		c.app.bind("today", "go to today"),
+		fixed("tab/shift+tab", "choose an entry or trip of the day"),
+		c.app.bind("select", "open it"),
+	}
+}
+
+func (c *calendarView) reload() {
+	if c.trips, c.err = c.app.store.ListTrips(c.app.ctx); c.err != nil {
+		return
+	}
+	c.entries, c.err = c.app.store.ListEntries(c.app.ctx)
+	c.pick = clamp(c.pick, 0, len(c.items())-1)
+}
+
+// items lists the entries written on the selected day, then the trips
+// covering it.
+func (c calendarView) items() []calendarItem {
+	var items []calendarItem
+	for _, e := range c.entries {
+		if dateOf(e.Timestamp).Equal(c.day) {
+			items = append(items, calendarItem{entry: e})
+		}
+	}
+	for _, t := range c.trips {
+		if tripCovers(t, c.day) {
+			items = append(items, calendarItem{trip: t})
+		}
+	}
+	return items
+}
+
+// move selects the day n days away.
+func (c *calendarView) move(n int) {
+	c.day = c.day.AddDate(0, 0, n)
+	c.pick = 0
+}
+
+// moveMonth selects the same day n months away, or the last day of that
+// month when it is shorter.
+func (c *calendarView) moveMonth(n int) {
+	c.day = addMonths(c.day, n)
+	c.pick = 0
+}
+
+func (c calendarView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tripSavedMsg, entrySavedMsg, historyMsg:
+		c.reload()
+	case tea.KeyMsg:
+		switch {
+		case c.app.is(msg, "left"):
+			c.move(-1)
+		case c.app.is(msg, "right"):
+			c.move(1)
+		case c.app.is(msg, "up"):
+			c.move(-7)
+		case c.app.is(msg, "down"):
+			c.move(7)
+		case c.app.is(msg, "prev_month"):
+			c.moveMonth(-1)
+		case c.app.is(msg, "next_month"):
+			c.moveMonth(1)
+		case c.app.is(msg, "today"):
+			c.day, c.pick = c.app.today(), 0
+		case msg.String() == "tab":
+			if n := len(c.items()); n > 0 {
+				c.pick = (c.pick + 1) % n
+			}
+		case msg.String() == "shift+tab":
+			if n := len(c.items()); n > 0 {
+				c.pick = (c.pick + n - 1) % n
+			}
+		case c.app.is(msg, "select"):
+			items := c.items()
+			if len(items) == 0 {
+				break
+			}
+			switch it := items[clamp(c.pick, 0, len(items)-1)]; {
+			case it.entry != nil:
+				return c, push(newEntryReader(c.app, it.entry))
+			case it.trip != nil:
+				return c, push(newTripDetail(c.app, it.trip))
+			}
+		}
+	}
+	return c, nil
+}
+
+func (c calendarView) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("📅 "+loc.Date(c.day, "January 2006")) + "\n\n")
+	if c.err != nil {
+		b.WriteString(errorStyle.Render(c.err.Error()) + "\n")
+		return b.String()
+	}
+
+	written := make(map[time.Time]bool)
+	for _, e := range c.entries {
+		written[dateOf(e.Timestamp)] = true
+	}
+	today := c.app.today()
+
+	// A grid reads as a jumble of numbers, so accessible mode lists the
+	// days instead.
+	if accessible {
+		b.WriteString(c.summary(written) + "\n\n")
+	} else {
+		b.WriteString(c.grid(written, today))
+	}
+
+	b.WriteString(labelStyle.Render(c.app.formatDate(c.day)+" "+loc.Weekday(c.day.Weekday())) + "\n")
+	items := c.items()
+	if len(items) == 0 {
+		b.WriteString(hintStyle.Render(tr("Nothing on this day.")) + "\n")
+	}
+	pick := clamp(c.pick, 0, len(items)-1)
+	for i, it := range items {
+		cursor, text := "  ", ""
+		switch {
+		case it.entry != nil:
+			text = "📔 " + it.entry.Title
+		case it.trip != nil:
+			text = "🧳 " + it.trip.Title + "  " + hintStyle.Render(tripDates(c.app, it.trip))
+		}
+		if i == pick {
+			cursor = "👉"
+		}
+		b.WriteString(cursor + " " + text + "\n")
+	}
+
+	hint := tr("←/→ day • ↑/↓ week • %s/%s month • %s today",
+		c.app.keyHint("prev_month"), c.app.keyHint("next_month"), c.app.keyHint("today"))
+	if len(items) > 1 {
+		hint += " • " + tr("tab choose")
+	}
+	if len(items) > 0 {
+		hint += " • " + tr("enter open")
+	}
+	b.WriteString("\n" + hintStyle.Render(hint+" • "+tr("esc back")) + "\n")
+	return b.String()
+}
+
+// grid draws the month a week a line, marking the selected day, the days on
+// a trip and today, and the days written with a dot.
+func (c calendarView) grid(written map[time.Time]bool, today time.Time) string {
+	var b strings.Builder
+	weekdays := []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}
+	for i, d := range weekdays {
+		weekdays[i] = tr(d)
+	}
+	b.WriteString(labelStyle.Render(" "+strings.Join(weekdays, "   ")) + "\n")
+	first := time.Date(c.day.Year(), c.day.Month(), 1, 0, 0, 0, 0, time.Local)
+	// Weeks start on Monday; Go counts from Sunday.
+	offset := (int(first.Weekday()) + 6) % 7
+	b.WriteString(strings.Repeat("     ", offset))
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		mark := " "
+		if written[d] {
+			mark = "•"
+		}
+		cell := fmt.Sprintf("%2d%s", d.Day(), mark)
+		onTrip := false
+		for _, t := range c.trips {
+			if tripCovers(t, d) {
+				onTrip = true
+				break
+			}
+		}
+		switch {
+		case d.Equal(c.day):
+			cell = cursorStyle.Render("[" + cell + "]")
+		case onTrip:
+			cell = " " + highlightStyle.Render(cell) + " "
+		case d.Equal(today):
+			cell = " " + labelStyle.Render(cell) + " "
+		default:
+			cell = " " + cell + " "
+		}
+		b.WriteString(cell)
+		if (offset+d.Day())%7 == 0 {
+			b.WriteString("\n")
+		} else if d.AddDate(0, 0, 1).Month() != first.Month() {
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n" + hintStyle.Render(tr("highlighted: on a trip • •: journal entry")) + "\n\n")
+	return b.String()
+}
+
+// summary lists the days of the month on a trip and those with entries.
+func (c calendarView) summary(written map[time.Time]bool) string {
+	var onTrip, withEntries []int
+	first := time.Date(c.day.Year(), c.day.Month(), 1, 0, 0, 0, 0, time.Local)
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		for _, t := range c.trips {
+			if tripCovers(t, d) {
+				onTrip = append(onTrip, d.Day())
+				break
+			}
+		}
+		if written[d] {
+			withEntries = append(withEntries, d.Day())
+		}
+	}
+	return tr("On a trip: %s", dayRanges(onTrip)) + "\n" + tr("Journal entries: %s", dayRanges(withEntries))
+}
+
+// dayRanges writes days of a month, in order, as runs such as "3, 12-17".
+func dayRanges(days []int) string {
+	if len(days) == 0 {
+		return tr("none")
+	}
+	var runs []string
+	for i := 0; i < len(days); {
+		j := i
+		for j+1 < len(days) && days[j+1] == days[j]+1 {
+			j++
+		}
+		if j == i {
+			runs = append(runs, strconv.Itoa(days[i]))
+		} else {
+			runs = append(runs, fmt.Sprintf("%d-%d", days[i], days[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(runs, ", ")
+}
+
+// tripCovers reports whether day falls within the trip's dates. A trip
+// without an end date covers its first day only.
+func tripCovers(t *models.Trip, day time.Time) bool {
+	start := dateOf(t.StartDate)
+	end := start
+	if t.EndDate != nil {
+		end = dateOf(*t.EndDate)
+	}
+	return !day.Before(start) && !day.After(end)
+}
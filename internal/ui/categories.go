@@ -0,0 +1,346 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/theme"
+)
+
+// categoryList lists the expense categories in display order, to add,
+// edit, reorder and remove them.
+type categoryList struct {
+	app        *app
+	categories []*models.Category
+	// counts is how many expenses are in each category, by name.
+	counts map[string]int
+	cursor int
+
+	// merging is set while the category to move the expenses of the one
+	// under the cursor to is being typed in.
+	merging bool
+	into    textinput.Model
+
+	status string
+	err    error
+}
+
+func newCategoryList(app *app) categoryList {
+	into := textinput.New()
+	into.Width = 20
+	l := categoryList{app: app, into: into}
+	l.reload()
+	return l
+}
+
+func (l categoryList) Title() string { return tr("Categories") }
+
+func (l categoryList) Init() tea.Cmd {
+	return nil
+}
+
+func (l categoryList) capturesEsc() bool { return l.merging }
+
+func (l categoryList) typing() bool { return l.merging }
+
+func (l categoryList) help() []key.Binding {
+	if l.merging {
+		return []key.Binding{fixed("enter", "move the expenses and remove the category"), fixed("esc", "cancel")}
+	}
+	return []key.Binding{
+		l.app.bind("up", "previous category"),
+		l.app.bind("down", "next category"),
+		l.app.bind("new", "add a category"),
+		l.app.bind("edit", "rename the category or change its icon and colour"),
+		l.app.bind("move_up", "move the category up"),
+		l.app.bind("move_down", "move the category down"),
+		l.app.bind("delete", "merge the category into another"),
+	}
+}
+
+func (l *categoryList) reload() {
+	if l.categories, l.err = l.app.store.ListCategories(l.app.ctx); l.err != nil {
+		return
+	}
+	l.counts, l.err = l.app.store.CountByCategory(l.app.ctx)
+	l.cursor = clamp(l.cursor, 0, len(l.categories)-1)
+}
+
+func (l categoryList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case categorySavedMsg:
+		l.reload()
+		for i, c := range l.categories {
+			if c.ID == msg.category.ID {
+				l.cursor = i
+			}
+		}
+		if msg.renamed != "" {
+			return l, toast(toastSuccess, "Renamed %s to %s on every expense", msg.renamed, msg.category.Name)
+		}
+		return l, toast(toastSuccess, "Saved %s", msg.category.Label())
+	case expenseSavedMsg, expensesImportedMsg, lodgingsChangedMsg, historyMsg:
+		l.reload()
+	case tea.KeyMsg:
+		if l.merging {
+			return l.updateMerging(msg)
+		}
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.categories)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			l.status = ""
+			return l, push(newCategoryForm(l.app, nil))
+		case l.app.is(msg, "edit"):
+			if len(l.categories) > 0 {
+				l.status = ""
+				return l, push(newCategoryForm(l.app, l.categories[l.cursor]))
+			}
+		case l.app.is(msg, "move_up"):
+			return l, l.move(-1)
+		case l.app.is(msg, "move_down"):
+			return l, l.move(1)
+		case l.app.is(msg, "delete"):
+			if len(l.categories) == 0 {
+				return l, nil
+			}
+			if l.categories[l.cursor].Name == models.CategoryOther {
+				l.err = fmt.Errorf("%s is where expenses fitting no other category go; it cannot be removed", models.CategoryOther)
+				return l, nil
+			}
+			l.merging, l.status, l.err = true, "", nil
+			l.into.SetValue(models.CategoryOther)
+			l.into.CursorEnd()
+			return l, l.into.Focus()
+		}
+	}
+	return l, nil
+}
+
+// move moves the category under the cursor by delta places.
+func (l *categoryList) move(delta int) tea.Cmd {
+	to := l.cursor + delta
+	if len(l.categories) == 0 || to < 0 || to >= len(l.categories) {
+		return nil
+	}
+	c := l.categories[l.cursor]
+	if l.err = l.app.store.MoveCategory(l.app.ctx, c, to); l.err != nil {
+		return nil
+	}
+	l.cursor, l.status = to, ""
+	l.reload()
+	return func() tea.Msg { return categorySavedMsg{category: c} }
+}
+
+// updateMerging edits the category to merge the one under the cursor into
+// and merges it on Enter.
+func (l categoryList) updateMerging(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		l.merging = false
+		l.into.Blur()
+		return l, nil
+	case "enter":
+		c := l.categories[l.cursor]
+		name, err := models.ParseCategory(l.into.Value())
+		if err != nil {
+			l.err = err
+			return l, nil
+		}
+		into, err := l.app.store.GetCategoryByName(l.app.ctx, name)
+		if err != nil {
+			l.err = err
+			return l, nil
+		}
+		moved, err := l.app.store.MergeCategory(l.app.ctx, c, into)
+		if err != nil {
+			l.err = err
+			return l, nil
+		}
+		l.merging, l.err = false, nil
+		l.into.Blur()
+		l.status = fmt.Sprintf("Merged %s into %s, moving %s", c.Name, into.Name, plural(moved, "expense", "expenses"))
+		l.reload()
+		return l, func() tea.Msg { return categorySavedMsg{category: into} }
+	}
+	var cmd tea.Cmd
+	l.into, cmd = l.into.Update(key)
+	return l, cmd
+}
+
+func (l categoryList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🗂️  Categories") + "\n\n")
+	for i, c := range l.categories {
+		cursor := "  "
+		if i == l.cursor {
+			cursor = "👉"
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, categoryLabel(c.Name, 14),
+			hintStyle.Render(plural(l.counts[c.Name], "expense", "expenses")))
+	}
+	if l.merging {
+		c := l.categories[l.cursor]
+		fmt.Fprintf(&b, "\n%s %s\n", labelStyle.Render(fmt.Sprintf("Move the %s in %s to",
+			plural(l.counts[c.Name], "expense", "expenses"), c.Name)), l.into.View())
+	}
+	if l.err != nil {
+		b.WriteString("\n" + errorStyle.Render(l.err.Error()) + "\n")
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	hint := "↑/↓ move • " + l.app.keyHint("new") + " new • " + l.app.keyHint("edit") + " edit • " +
+		l.app.keyHint("move_up") + "/" + l.app.keyHint("move_down") + " reorder • " + l.app.keyHint("delete") + " merge • esc back"
+	if l.merging {
+		hint = "enter merge • esc cancel"
+	}
+	b.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return b.String()
+}
+
+// categoryLabel draws the category called name with its icon, in its
+// colour, padded to width columns.
+func categoryLabel(name string, width int) string {
+	c := models.LookupCategory(name)
+	if c == nil {
+		return fmt.Sprintf("%-*s", width, name)
+	}
+	label := fmt.Sprintf("%-*s", width, c.Name)
+	if c.Colour != "" {
+		label = lipgloss.NewStyle().Foreground(lipgloss.Color(c.Colour)).Render(label)
+	}
+	if c.Icon != "" {
+		label = c.Icon + " " + label
+	}
+	return label
+}
+
+// categoryForm adds an expense category, or edits one. Renaming a category
+// renames it on every expense in it.
+type categoryForm struct {
+	form
+	app      *app
+	category *models.Category
+	isNew    bool
+}
+
+const (
+	categoryFieldName = iota
+	categoryFieldIcon
+	categoryFieldColour
+)
+
+func newCategoryForm(app *app, c *models.Category) categoryForm {
+	title := "🗂️  New category"
+	isNew := c == nil
+	if isNew {
+		c = models.NewCategory("", "", "")
+	} else {
+		title = "🗂️  Edit " + c.Name
+	}
+	name := models.CheckCategoryName
+	if c.Name == models.CategoryOther {
+		name = func(v string) error {
+			if strings.ToLower(v) != models.CategoryOther {
+				return fmt.Errorf("%s cannot be renamed", models.CategoryOther)
+			}
+			return nil
+		}
+	}
+	f := newForm(title,
+		newField("Name", "coffee", "One word, as expenses are filed under it.", name),
+		newField("Icon", "☕", "Optional. An emoji shown before the name.", nil),
+		newField("Colour", "#a0522d", "Optional. An ANSI number (0-255) or #rgb/#rrggbb; empty for the theme's.", validateColour),
+	)
+	f.fields[categoryFieldName].input.SetValue(c.Name)
+	f.fields[categoryFieldIcon].input.SetValue(c.Icon)
+	f.fields[categoryFieldColour].input.SetValue(c.Colour)
+	// Work on a copy so cancelling leaves the caller's category untouched.
+	edited := *c
+	return categoryForm{form: f, app: app, category: &edited, isNew: isNew}
+}
+
+func validateColour(v string) error {
+	if !theme.ValidColour(v) {
+		return fmt.Errorf("%q is neither an ANSI number (0-255) nor #rgb/#rrggbb", v)
+	}
+	return nil
+}
+
+func (f categoryForm) Title() string {
+	if f.isNew {
+		return tr("New category")
+	}
+	return tr("Edit %s", f.category.Name)
+}
+
+func (f categoryForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f categoryForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		c, renamed, err := f.save()
+		if err != nil {
+			f.err = err
+			return f, nil
+		}
+		return f, tea.Sequence(pop, func() tea.Msg { return categorySavedMsg{category: c, renamed: renamed} })
+	}
+	return f, cmd
+}
+
+// save writes the category, renaming it first when its name changed. It
+// returns its name before when it did.
+func (f categoryForm) save() (*models.Category, string, error) {
+	c, name := f.category, strings.ToLower(f.value(categoryFieldName))
+	c.Icon, c.Colour = f.value(categoryFieldIcon), f.value(categoryFieldColour)
+	renamed := ""
+	if f.isNew {
+		c.Name = name
+	} else if name != c.Name {
+		renamed = c.Name
+		if err := f.app.store.RenameCategory(f.app.ctx, c, name); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := f.app.store.SaveCategory(f.app.ctx, c); err != nil {
+		return nil, "", err
+	}
+	return c, renamed, nil
+}
+
+func (f categoryForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		for i, label := range []string{"Name", "Icon", "Colour"} {
+			v := f.value(i)
+			if v == "" {
+				v = hintStyle.Render("—")
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(label+":"), v)
+		}
+		return b.String()
+	})
+}
@@ -0,0 +1,171 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+const (
+	checkInFieldPlace = iota
+	checkInFieldNote
+)
+
+// checkInForm checks in at a place on a trip, now.
+type checkInForm struct {
+	form
+	app  *app
+	trip *models.Trip
+}
+
+// checkIn opens the check-in form on the trip of the nearest screen about
+// one, or else the trip in progress.
+func (m *Model) checkIn() tea.Cmd {
+	trip, cmd := m.activeTrip("check in on")
+	if trip == nil {
+		return cmd
+	}
+	return push(newCheckInForm(m.app, trip))
+}
+
+// activeTrip returns the trip of the nearest screen about one, or else the
+// trip in progress. When there is neither it returns nil with the command
+// telling that there is no trip to do what is done to.
+func (m *Model) activeTrip(what string) (*models.Trip, tea.Cmd) {
+	for i := len(m.stack) - 1; i >= 0; i-- {
+		if t, ok := m.stack[i].(tripper); ok && t.currentTrip() != nil {
+			return t.currentTrip(), nil
+		}
+	}
+	trips, err := m.app.store.ListTrips(m.app.ctx)
+	if err != nil {
+		return nil, notify(historyMsg{err: err})
+	}
+	if trip := models.TripOn(trips, time.Now()); trip != nil {
+		return trip, nil
+	}
+	return nil, notify(historyMsg{text: "No trip in progress to " + what + "; open a trip first"})
+}
+
+func newCheckInForm(app *app, trip *models.Trip) checkInForm {
+	f := newForm("📌 Check in on "+trip.Title,
+		newField("Place", "Osaka Castle", "Where you are: a city, a landmark, an address.", required("place")),
+		newField("Note", "", "Optional.", nil),
+	)
+	f.fields[checkInFieldPlace].places = newPlaceCompleter(false)
+	// Start from the city the trip is in today, to refine or accept.
+	now := app.tripNow(trip)
+	if legs, err := app.store.ListLegsByTrip(app.ctx, trip.ID); err == nil {
+		if l := models.LegOn(legs, now); l != nil {
+			f.fields[checkInFieldPlace].input.SetValue(l.Location)
+		} else if len(trip.Locations) > 0 {
+			f.fields[checkInFieldPlace].input.SetValue(trip.Locations[0])
+		}
+	}
+	f.fields[checkInFieldPlace].input.CursorEnd()
+	return checkInForm{form: f, app: app, trip: trip}
+}
+
+func (f checkInForm) Title() string { return tr("Check in") }
+
+func (f checkInForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f checkInForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		place := f.value(checkInFieldPlace)
+		c := models.NewCheckIn(f.trip.ID, place, time.Now())
+		c.Note = f.value(checkInFieldNote)
+		c.TimeZone = f.app.zoneOn(f.trip.ID, place, f.app.tripNow(f.trip))
+		if p, ok := places.Lookup(place); ok {
+			c.Lat, c.Lon, c.TimeZone = p.Lat, p.Lon, p.Timezone
+		}
+		c.Timestamp = models.InZone(c.Timestamp, c.TimeZone)
+		if err := f.app.store.SaveCheckIn(f.app.ctx, c); err != nil {
+			f.err = err
+			return f, nil
+		}
+		saved := func() tea.Msg { return checkInSavedMsg{checkIn: c} }
+		if c.Located() {
+			return f, tea.Sequence(pop, saved)
+		}
+		return f, tea.Sequence(pop, saved, f.app.geocodeCheckIn(c))
+	}
+	return f, cmd
+}
+
+func (f checkInForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		for i, label := range []string{"Place", "Note"} {
+			v := f.value(i)
+			if v == "" {
+				v = hintStyle.Render("—")
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(label+":"), v)
+		}
+		return b.String()
+	})
+}
+
+// checkInLocatedMsg carries the position geocoded for a check-in.
+type checkInLocatedMsg struct {
+	checkInID string
+	lat, lon  float64
+	err       error
+}
+
+// geocodeCheckIn looks up the position of a check-in's place online in the
+// background. It does nothing without a geocoder.
+func (a *app) geocodeCheckIn(c *models.CheckIn) tea.Cmd {
+	if a.geocoder == nil {
+		return nil
+	}
+	id, place := c.ID, c.Place
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, 15*time.Second)
+		defer cancel()
+		p, err := a.geocoder.Search(ctx, place)
+		if err != nil {
+			return checkInLocatedMsg{checkInID: id, err: err}
+		}
+		return checkInLocatedMsg{checkInID: id, lat: p.Lat, lon: p.Lon}
+	}
+}
+
+// recordPosition saves a geocoded position on its check-in, with the time
+// zone there. As with weather, a check-in whose place could not be found
+// is left without one, with a warning.
+func (a *app) recordPosition(msg checkInLocatedMsg) tea.Cmd {
+	c, err := a.store.GetCheckIn(a.ctx, msg.checkInID)
+	if err != nil {
+		return nil
+	}
+	if msg.err != nil {
+		return toast(toastWarning, "Could not locate %q: %s", c.Place, msg.err)
+	}
+	c.Lat, c.Lon = msg.lat, msg.lon
+	if p, _, ok := places.Nearest(msg.lat, msg.lon); ok {
+		c.TimeZone = p.Timezone
+		c.Timestamp = models.InZone(c.Timestamp, c.TimeZone)
+	}
+	if err := a.store.SaveCheckIn(a.ctx, c); err != nil {
+		return toastErr(err)
+	}
+	return func() tea.Msg { return checkInSavedMsg{checkIn: c} }
+}
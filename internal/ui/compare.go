@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/gpx"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/stats"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+// tripComparison sets trips side by side: the trips picked from the list
+// each get a column of their length, spending in all, per day and by
+// category, entries and distance, in the home currency.
+type tripComparison struct {
+	app    *app
+	trips  []*models.Trip
+	picked map[string]bool
+	cursor int
+
+	expenses []*models.Expense
+	segments []*models.Segment
+	tracks   []*models.Track
+	// entries counts the journal entries of each trip, by ID.
+	entries map[string]int
+
+	rates    *currency.Rates
+	ratesErr error
+	width    int
+	height   int
+	err      error
+}
+
+func newTripComparison(app *app) tripComparison {
+	c := tripComparison{app: app, picked: map[string]bool{}}
+	c.reload()
+	return c
+}
+
+func (c *tripComparison) reload() {
+	if c.trips, c.err = c.app.store.ListTrips(c.app.ctx); c.err != nil {
+		return
+	}
+	if c.expenses, c.err = c.app.store.ListExpenses(c.app.ctx); c.err != nil {
+		return
+	}
+	if c.segments, c.err = c.app.store.ListSegments(c.app.ctx); c.err != nil {
+		return
+	}
+	c.tracks, c.entries = nil, map[string]int{}
+	for _, t := range c.trips {
+		tracks, err := c.app.store.ListTracksByTrip(c.app.ctx, t.ID)
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.tracks = append(c.tracks, tracks...)
+		if c.entries[t.ID], c.err = c.app.store.CountEntries(c.app.ctx, storage.EntryQuery{TripID: t.ID}); c.err != nil {
+			return
+		}
+	}
+	c.cursor = clamp(c.cursor, 0, len(c.trips)-1)
+}
+
+func (c tripComparison) Title() string { return tr("Compare trips") }
+
+func (c tripComparison) Init() tea.Cmd {
+	return c.app.fetchRates()
+}
+
+func (c tripComparison) help() []key.Binding {
+	return []key.Binding{
+		c.app.bind("up", "previous trip"),
+		c.app.bind("down", "next trip"),
+		c.app.bind("toggle", "compare the trip, or stop comparing it"),
+	}
+}
+
+func (c tripComparison) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		c.width, c.height = msg.Width, msg.Height
+	case ratesMsg:
+		c.rates, c.ratesErr = msg.rates, msg.err
+	case tripSavedMsg, entrySavedMsg, expenseSavedMsg, expensesImportedMsg, lodgingsChangedMsg, historyMsg:
+		c.reload()
+	case tea.KeyMsg:
+		switch {
+		case c.app.is(msg, "up"):
+			if c.cursor > 0 {
+				c.cursor--
+			}
+		case c.app.is(msg, "down"):
+			if c.cursor < len(c.trips)-1 {
+				c.cursor++
+			}
+		case c.app.is(msg, "toggle"):
+			if len(c.trips) > 0 {
+				id := c.trips[c.cursor].ID
+				c.picked[id] = !c.picked[id]
+			}
+		}
+	}
+	return c, nil
+}
+
+// compared is the comparison of the trips picked, in the list's order.
+func (c tripComparison) compared() stats.Comparison {
+	var trips []*models.Trip
+	for _, t := range c.trips {
+		if c.picked[t.ID] {
+			trips = append(trips, t)
+		}
+	}
+	var convert stats.Converter
+	if c.rates != nil {
+		convert = c.rates.Convert
+	}
+	return stats.Compare(trips, c.expenses, c.entries, c.segments, c.tracks, c.app.cfg.HomeCurrency, convert, time.Now())
+}
+
+func (c tripComparison) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("⚖️  Compare trips") + "\n\n")
+	if c.err != nil {
+		b.WriteString(errorStyle.Render(c.err.Error()) + "\n")
+		return b.String()
+	}
+	if len(c.trips) < 2 {
+		b.WriteString("Comparing takes two trips or more.\n")
+		b.WriteString("\n" + hintStyle.Render("esc back") + "\n")
+		return b.String()
+	}
+
+	var list strings.Builder
+	from, to := window(len(c.trips), c.cursor, c.height-8)
+	for i := from; i < to; i++ {
+		t := c.trips[i]
+		cursor, box := "  ", "☐"
+		if i == c.cursor {
+			cursor = "👉"
+		}
+		if c.picked[t.ID] {
+			box = successStyle.Render("☑")
+		}
+		fmt.Fprintf(&list, "%s %s %s %s\n", cursor, box, truncate(t.Title, 24), hintStyle.Render(c.app.formatDate(t.StartDate)))
+	}
+
+	cmp := c.compared()
+	table := hintStyle.Render("Pick two trips or more with "+c.app.keyHint("toggle")+" to set them side by side.") + "\n"
+	if len(cmp.Trips) >= 2 {
+		table = c.table(cmp)
+	}
+	b.WriteString(panes(c.width, list.String(), "  ", table) + "\n")
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • "+c.app.keyHint("toggle")+" pick • esc back") + "\n")
+	return b.String()
+}
+
+// table lays the comparison out in a column for each trip.
+func (c tripComparison) table(cmp stats.Comparison) string {
+	money := func(v float64) string { return formatAmount(v, cmp.Currency) }
+	rows := [][]string{{""}}
+	row := func(label string, cell func(f stats.TripFigures) string) {
+		r := []string{labelStyle.Render(label)}
+		for _, f := range cmp.Trips {
+			r = append(r, cell(f))
+		}
+		rows = append(rows, r)
+	}
+	for _, f := range cmp.Trips {
+		rows[0] = append(rows[0], headerStyle.Render(truncate(f.Trip.Title, 20)))
+	}
+	row("Days", func(f stats.TripFigures) string { return fmt.Sprint(f.Days) })
+	row("Spend", func(f stats.TripFigures) string { return money(f.Spend) })
+	row("Per day", func(f stats.TripFigures) string { return money(f.PerDay) })
+	for _, name := range cmp.Categories {
+		r := []string{"  " + categoryLabel(name, 0)}
+		for _, f := range cmp.Trips {
+			v, ok := f.Categories[name]
+			if !ok {
+				r = append(r, hintStyle.Render("—"))
+				continue
+			}
+			r = append(r, money(v))
+		}
+		rows = append(rows, r)
+	}
+	row("Entries", func(f stats.TripFigures) string { return fmt.Sprint(f.Entries) })
+	row("Distance", func(f stats.TripFigures) string { return fmt.Sprintf("%.0f km", f.Distance) })
+	row("Tracked", func(f stats.TripFigures) string { return gpx.FormatDistance(f.Tracked) })
+
+	widths := make([]int, len(rows[0]))
+	for _, r := range rows {
+		for i, cell := range r {
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+	var b strings.Builder
+	for _, r := range rows {
+		for i, cell := range r {
+			b.WriteString(cell + strings.Repeat(" ", widths[i]-lipgloss.Width(cell)+2))
+		}
+		b.WriteString("\n")
+	}
+	unconverted := 0
+	for _, f := range cmp.Trips {
+		unconverted += f.Unconverted
+	}
+	if unconverted > 0 {
+		note := plural(unconverted, "expense", "expenses") + " in other currencies not counted"
+		switch {
+		case c.ratesErr != nil:
+			note += " (exchange rates unavailable)"
+		case c.app.rates != nil && c.rates == nil:
+			note += " (loading exchange rates)"
+		}
+		b.WriteString("\n" + hintStyle.Render(note) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// consoleInterval is how often the open debug console shows the records
+// logged since.
+const consoleInterval = time.Second
+
+// consoleTickMsg redraws the debug console while it is open.
+type consoleTickMsg struct{}
+
+func consoleTick() tea.Cmd {
+	return tea.Tick(consoleInterval, func(time.Time) tea.Msg { return consoleTickMsg{} })
+}
+
+// debugConsole shows the latest records of the log over the current
+// screen, newest at the bottom, for diagnosing what nomadic did.
+type debugConsole struct {
+	// back is how many records up from the newest the console scrolled.
+	back int
+}
+
+// update takes every key while the console is open, and reports whether
+// it closed.
+func (c *debugConsole) update(a *app, msg tea.KeyMsg, height int) bool {
+	page := max(height-4, 1)
+	switch {
+	case a.is(msg, "debug"), a.is(msg, "back"), a.is(msg, "quit"):
+		return true
+	case a.is(msg, "up"):
+		c.back++
+	case a.is(msg, "down"):
+		c.back--
+	case a.is(msg, "page_up"):
+		c.back += page
+	case a.is(msg, "page_down"):
+		c.back -= page
+	case a.is(msg, "first"):
+		c.back = len(a.log.Recent())
+	case a.is(msg, "last"):
+		c.back = 0
+	}
+	c.back = clamp(c.back, 0, max(len(a.log.Recent())-1, 0))
+	return false
+}
+
+// view lists the records that fit in height lines, above what the log
+// keeps and where.
+func (c *debugConsole) view(a *app, width, height int) string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🐞 "+tr("Debug console")) + "\n\n")
+	records := a.log.Recent()
+	rows := max(height-6, 1)
+	end := max(len(records)-c.back, 0)
+	start := max(end-rows, 0)
+	if len(records) == 0 {
+		b.WriteString(hintStyle.Render(tr("Nothing logged yet.")) + "\n")
+	}
+	for _, r := range records[start:end] {
+		line := truncate(r.String(), max(width, 20))
+		switch {
+		case r.Level >= slog.LevelError:
+			line = errorStyle.Render(line)
+		case r.Level >= slog.LevelWarn:
+			line = warningStyle.Render(line)
+		case r.Level < slog.LevelInfo:
+			line = hintStyle.Render(line)
+		}
+		b.WriteString(line + "\n")
+	}
+	where := tr("kept in memory only")
+	if p := a.log.Path(); p != "" {
+		where = tr("also in %s", p)
+	}
+	if !a.log.Verbose() {
+		where += " • " + tr("run with --debug for more")
+	}
+	b.WriteString("\n" + hintStyle.Render(tr("%d of %d records, %s • ↑/↓ scroll • %s or %s close",
+		end-start, len(records), where, a.keyHint("debug"), a.keyHint("back"))))
+	return b.String()
+}
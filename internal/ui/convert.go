@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/quick"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+// converter is the currency converter open over the list of expenses,
+// converting an amount typed with its currencies, such as 100 usd jpy, as
+// it is typed. The amount is in from and converted to the home currency
+// unless the line says otherwise.
+type converter struct {
+	input textinput.Model
+	from  string
+	home  string
+}
+
+func newConverter(from, home string) *converter {
+	in := textinput.New()
+	in.Placeholder = "100 " + strings.ToLower(from) + " " + strings.ToLower(home)
+	in.CharLimit = 100
+	in.Width = 40
+	in.Focus()
+	return &converter{input: in, from: from, home: home}
+}
+
+// update takes every key while the converter is open, and reports whether
+// enter or esc closed it.
+func (c *converter) update(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if msg.Type == tea.KeyEsc || msg.Type == tea.KeyEnter {
+		return nil, true
+	}
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	return cmd, false
+}
+
+// view converts what is typed with rates, those of the home currency
+// loaded by the list, or says why it cannot.
+func (c *converter) view(rates *currency.Rates, ratesErr error, width int) string {
+	var b strings.Builder
+	b.WriteString(labelStyle.Render("💱 Convert") + "\n" + c.input.View() + "\n")
+	b.WriteString(c.result(rates, ratesErr) + "\n")
+	b.WriteString(hintStyle.Render("e.g. 25 or 100 usd jpy or €12.50 in gbp • esc close"))
+	return paneStyle.Width(max(min(width, 64)-2, 30)).Render(b.String())
+}
+
+func (c *converter) result(rates *currency.Rates, ratesErr error) string {
+	line := strings.TrimSpace(c.input.Value())
+	conv, err := quick.ParseConversion(line)
+	if conv.From == "" {
+		conv.From = c.from
+	}
+	if conv.To == "" {
+		conv.To = c.home
+	}
+	// A currency converts into itself without any rates.
+	if line != "" && err == nil && strings.EqualFold(conv.From, conv.To) {
+		return highlightStyle.Render(formatAmount(conv.Amount, conv.From) + " = " + formatAmount(conv.Amount, conv.To))
+	}
+	switch {
+	case ratesErr != nil:
+		return errorStyle.Render("Exchange rates unavailable: " + ratesErr.Error())
+	case rates == nil:
+		return hintStyle.Render("Loading exchange rates…")
+	case line == "":
+		return hintStyle.Render("Type an amount to convert from " + c.from + " to " + c.home + ".")
+	}
+	if err != nil {
+		return hintStyle.Render(strings.TrimPrefix(err.Error(), "quick: "))
+	}
+	result, err := rates.Convert(conv.Amount, conv.From, conv.To)
+	if err != nil {
+		return errorStyle.Render(strings.TrimPrefix(err.Error(), "currency: "))
+	}
+	rate, _ := rates.Convert(1, conv.From, conv.To)
+	out := highlightStyle.Render(formatAmount(conv.Amount, conv.From)+" = "+formatAmount(result, conv.To)) + "\n" +
+		hintStyle.Render("1 "+conv.From+" = "+strconv.FormatFloat(rate, 'g', 6, 64)+" "+conv.To+" • rates of "+rates.Date)
+	if rates.Stale {
+		out += warningStyle.Render(" (offline)")
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// tripStatus is what the header tells of the trip in progress, or else of
+// the next one planned.
+type tripStatus struct {
+	// active is the trip in progress, on its day-th day of days, or of an
+	// open-ended trip when days is 0, at place.
+	active    *models.Trip
+	day, days int
+	place     string
+	// next is the trip starting soonest when none is in progress, in
+	// until days.
+	next  *models.Trip
+	until int
+}
+
+// tripStatus finds the trip in progress or the next one. Like the streak
+// it is a nicety, so a store that cannot be read shows nothing.
+func (a *app) tripStatus() tripStatus {
+	if a.store == nil {
+		return tripStatus{}
+	}
+	trips, err := a.store.ListTrips(a.ctx)
+	if err != nil {
+		return tripStatus{}
+	}
+	now := time.Now()
+	if trip := models.TripOn(trips, now); trip != nil {
+		s := tripStatus{active: trip, place: trip.Title}
+		today := a.tripNow(trip)
+		s.day, s.days = trip.DayOf(today)
+		legs, _ := a.store.ListLegsByTrip(a.ctx, trip.ID)
+		if l := models.LegOn(legs, today); l != nil {
+			s.place = l.Location
+		} else if len(trip.Locations) > 0 {
+			s.place = trip.Locations[0]
+		}
+		return s
+	}
+	if trip := models.NextTrip(trips, now); trip != nil {
+		return tripStatus{next: trip, until: models.CalendarDays(now, trip.StartDate) - 1}
+	}
+	return tripStatus{}
+}
+
+// badge renders the status for the header, or nothing without a trip in
+// progress or planned.
+func (s tripStatus) badge() string {
+	switch {
+	case s.active != nil && s.days > 0:
+		return highlightStyle.Render("🧳 " + tr("Day %d of %d in %s", s.day, s.days, s.place))
+	case s.active != nil:
+		return highlightStyle.Render("🧳 " + tr("Day %d in %s", s.day, s.place))
+	case s.next == nil:
+		return ""
+	case s.until == 0:
+		return hintStyle.Render("✈️  " + tr("%s starts today", s.next.Title))
+	case s.until == 1:
+		return hintStyle.Render("✈️  " + tr("%s starts tomorrow", s.next.Title))
+	}
+	return hintStyle.Render("✈️  " + tr("%d days until %s", s.until, s.next.Title))
+}
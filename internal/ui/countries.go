@@ -0,0 +1,316 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// countryList lists the notes on countries' visa and entry rules, to add,
+// edit and delete them.
+type countryList struct {
+	app    *app
+	notes  []*models.CountryNote
+	cursor int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newCountryList(app *app) countryList {
+	l := countryList{app: app}
+	l.reload()
+	return l
+}
+
+func (l countryList) Title() string { return tr("Countries") }
+
+func (l countryList) Init() tea.Cmd {
+	return nil
+}
+
+func (l countryList) capturesEsc() bool { return l.confirmDelete }
+
+func (l countryList) help() []key.Binding {
+	return append([]key.Binding{
+		l.app.bind("up", "previous country"),
+		l.app.bind("down", "next country"),
+		l.app.bind("new", "write a note on a country"),
+		l.app.bind("edit", "edit the note"),
+		l.app.bind("delete", "delete the note"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *countryList) reload() {
+	l.notes, l.err = l.app.store.ListCountryNotes(l.app.ctx)
+	l.cursor = clamp(l.cursor, 0, len(l.notes)-1)
+}
+
+func (l countryList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case countryNoteSavedMsg:
+		l.reload()
+		for i, n := range l.notes {
+			if n.Country == msg.note.Country {
+				l.cursor = i
+			}
+		}
+		return l, toast(toastSuccess, "Saved the note on %s", places.CountryName(msg.note.Country))
+	case historyMsg:
+		l.status = ""
+		l.reload()
+	case tea.KeyMsg:
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				n := l.notes[l.cursor]
+				if err := l.app.run(deleteCountryNote{note: n}); err != nil {
+					l.err = err
+					return l, nil
+				}
+				l.status = l.app.deletedHint(places.CountryName(n.Country))
+				l.reload()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.notes)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			l.status = ""
+			return l, push(newCountryForm(l.app, nil))
+		case l.app.is(msg, "edit"), l.app.edits(msg, "select"):
+			if len(l.notes) > 0 {
+				l.status = ""
+				return l, push(newCountryForm(l.app, l.notes[l.cursor]))
+			}
+		case l.app.is(msg, "delete"):
+			if len(l.notes) > 0 {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l countryList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🛂 Countries") + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n")
+		return b.String()
+	}
+	if len(l.notes) == 0 {
+		b.WriteString("No notes yet — press " + l.app.keyHint("new") + " to note the visa rules of a country.\n")
+	}
+	for i, n := range l.notes {
+		cursor, name := "  ", places.CountryName(n.Country)
+		if i == l.cursor {
+			cursor, name = "👉", cursorStyle.Render(name)
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, name, hintStyle.Render(n.Summary()))
+	}
+	if len(l.notes) > 0 && l.notes[l.cursor].Notes != "" {
+		b.WriteString("\n" + hintStyle.Render(truncate(l.notes[l.cursor].Notes, 72)) + "\n")
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Delete the note on %s? y/n", places.CountryName(l.notes[l.cursor].Country))) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • "+l.app.keyHint("new")+" new • enter/"+l.app.keyHint("edit")+" edit • "+
+		l.app.keyHint("delete")+" delete • "+l.app.keyHint("undo")+" undo • esc back") + "\n")
+	return b.String()
+}
+
+// countryForm writes a note on a country, or edits one.
+type countryForm struct {
+	form
+	app   *app
+	note  *models.CountryNote
+	isNew bool
+}
+
+const (
+	countryFieldCountry = iota
+	countryFieldVisa
+	countryFieldMaxStay
+	countryFieldNotes
+)
+
+func newCountryForm(app *app, n *models.CountryNote) countryForm {
+	title := "🛂 New country note"
+	isNew := n == nil
+	if isNew {
+		n = models.NewCountryNote("")
+	} else {
+		title = "🛂 " + places.CountryName(n.Country)
+	}
+	f := newForm(title,
+		newField("Country", "Japan", "Its English name or two-letter code.", validateCountry),
+		newField("Visa", "Visa-free for EU passports", "Optional. Visa and entry rules.", nil),
+		newField("Max stay", "90", "Optional. The longest stay allowed, in days.", validateMaxStay),
+		newField("Notes", "SIM: IIJmio at the airport", "Optional. Anything else worth knowing.", nil),
+	)
+	if !isNew {
+		f.fields[countryFieldCountry].input.SetValue(places.CountryName(n.Country))
+		if n.MaxStay > 0 {
+			f.fields[countryFieldMaxStay].input.SetValue(strconv.Itoa(n.MaxStay))
+		}
+	}
+	f.fields[countryFieldVisa].input.SetValue(n.Visa)
+	f.fields[countryFieldNotes].input.SetValue(n.Notes)
+	// Work on a copy so cancelling leaves the caller's note untouched.
+	edited := *n
+	return countryForm{form: f, app: app, note: &edited, isNew: isNew}
+}
+
+func (f countryForm) Title() string {
+	if f.isNew {
+		return tr("New country note")
+	}
+	return places.CountryName(f.note.Country)
+}
+
+func (f countryForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f countryForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		n, err := f.save()
+		if err != nil {
+			f.err = err
+			return f, nil
+		}
+		return f, tea.Sequence(pop, func() tea.Msg { return countryNoteSavedMsg{note: n} })
+	}
+	return f, cmd
+}
+
+// save writes the note. A note moved to another country replaces the one
+// there, if any, and leaves the country it was on without one.
+func (f countryForm) save() (*models.CountryNote, error) {
+	n := f.note
+	was := n.Country
+	n.Country, _ = places.CountryCode(f.value(countryFieldCountry))
+	n.Visa, n.Notes = f.value(countryFieldVisa), f.value(countryFieldNotes)
+	n.MaxStay, _ = strconv.Atoi(f.value(countryFieldMaxStay))
+	if err := f.app.store.SaveCountryNote(f.app.ctx, n); err != nil {
+		return nil, err
+	}
+	if !f.isNew && was != n.Country {
+		if err := f.app.store.DeleteCountryNote(f.app.ctx, was); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+func (f countryForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		for i, label := range []string{"Country", "Visa", "Max stay", "Notes"} {
+			v := f.value(i)
+			switch {
+			case v == "":
+				v = hintStyle.Render("—")
+			case i == countryFieldMaxStay:
+				v += " days"
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-9s", label+":")), v)
+		}
+		return b.String()
+	})
+}
+
+func validateCountry(v string) error {
+	if _, ok := places.CountryCode(v); !ok {
+		return errors.New("enter a country's English name or two-letter code, e.g. Japan or JP")
+	}
+	return nil
+}
+
+func validateMaxStay(v string) error {
+	if v == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(v); err != nil || n < 0 {
+		return errors.New("enter a number of days, e.g. 90")
+	}
+	return nil
+}
+
+// countryNotesView shows the notes on the countries of locations, and how
+// you got online there before, in the summary of a trip or leg about to go
+// there.
+func countryNotesView(a *app, locations []string) string {
+	countries := places.Countries(places.Resolve(locations))
+	notes, err := a.store.ListCountryNotesFor(a.ctx, countries)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	if len(notes) > 0 {
+		b.WriteString("\n" + labelStyle.Render("🛂 Before you go") + "\n")
+	}
+	for _, n := range notes {
+		line := places.CountryName(n.Country)
+		if s := n.Summary(); s != "" {
+			line += ": " + s
+		}
+		b.WriteString("  " + line + "\n")
+		if n.Notes != "" {
+			b.WriteString("    " + hintStyle.Render(n.Notes) + "\n")
+		}
+	}
+	online, err := a.store.ListConnectivityFor(a.ctx, countries)
+	if err != nil || len(online) == 0 {
+		return b.String()
+	}
+	b.WriteString("\n" + labelStyle.Render("📶 Getting online") + "\n")
+	for _, c := range online {
+		line := places.CountryName(c.Country)
+		if c.Place != "" {
+			line += ", " + c.Place
+		}
+		line += " (" + c.CreatedAt.Format("2006-01") + ")"
+		if s := c.Summary(); s != "" {
+			line += ": " + s
+		}
+		b.WriteString("  " + line + "\n")
+		if len(c.WiFi) > 0 {
+			b.WriteString("    " + hintStyle.Render("Wi-Fi: "+strings.Join(c.WiFi, ", ")) + "\n")
+		}
+		if c.Notes != "" {
+			b.WriteString("    " + hintStyle.Render(c.Notes) + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,325 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// csvExport writes a trip's expenses to a CSV file.
+type csvExport struct {
+	app  *app
+	trip *models.Trip
+	path textinput.Model
+
+	status string
+	err    error
+}
+
+func newCSVExport(app *app, trip *models.Trip) csvExport {
+	name := export.Slug(trip.Title) + "-expenses.csv"
+	if wd, err := os.Getwd(); err == nil {
+		name = filepath.Join(wd, name)
+	}
+	return csvExport{app: app, trip: trip, path: newPathInput(name)}
+}
+
+func (s csvExport) Title() string { return tr("Export CSV") }
+
+func (s csvExport) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (s csvExport) typing() bool { return true }
+
+func (s csvExport) help() []key.Binding {
+	return []key.Binding{fixed("enter", "write the CSV file")}
+}
+
+func (s csvExport) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "enter" {
+		s.status, s.err = "", nil
+		path := expandHome(strings.TrimSpace(s.path.Value()))
+		if err := s.write(path); err != nil {
+			s.err = err
+		} else {
+			s.status = "Wrote " + path
+		}
+		return s, nil
+	}
+	var cmd tea.Cmd
+	s.path, cmd = s.path.Update(msg)
+	return s, cmd
+}
+
+func (s csvExport) write(path string) error {
+	if path == "" {
+		return errors.New("enter a file to export to")
+	}
+	t, err := export.Load(s.app.ctx, s.app.store, s.trip)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = export.WriteExpensesCSV(f, []*export.Trip{t})
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (s csvExport) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("💰 Export "+s.trip.Title+" expenses") + "\n\n")
+	b.WriteString(labelStyle.Render("File") + "\n")
+	b.WriteString(s.path.View() + "\n")
+	if s.err != nil {
+		b.WriteString("\n" + errorStyle.Render(s.err.Error()) + "\n")
+	}
+	if s.status != "" {
+		b.WriteString("\n" + s.status + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("enter export • esc back") + "\n")
+	return b.String()
+}
+
+// csvImport reads expenses from a CSV file, or the charges of a bank
+// statement, into a trip. Enter previews the import; it is saved only once
+// confirmed.
+type csvImport struct {
+	app     *app
+	trip    *models.Trip
+	path    textinput.Model
+	mapping textinput.Model
+	focus   int
+	// statement reads a CSV file as a bank statement; OFX files always are.
+	statement bool
+	// keepDuplicates imports rows that look like duplicates too, instead
+	// of merging them into the expenses they look like.
+	keepDuplicates bool
+
+	report *export.ImportReport // preview awaiting confirmation
+	rows   []export.ImportedRow
+	// Of a statement preview: charges a rule categorized, and credits left
+	// out.
+	categorized, credits int
+	err                  error
+}
+
+func newCSVImport(app *app, trip *models.Trip) csvImport {
+	m := newPathInput("")
+	m.Placeholder = "date=When, amount=Cost"
+	m.Blur()
+	return csvImport{app: app, trip: trip, path: newPathInput(""), mapping: m}
+}
+
+func (s csvImport) Title() string { return tr("Import CSV") }
+
+func (s csvImport) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (s csvImport) capturesEsc() bool { return s.report != nil }
+
+func (s csvImport) typing() bool { return s.report == nil }
+
+func (s csvImport) help() []key.Binding {
+	if s.report != nil {
+		return []key.Binding{
+			fixed("y", "import the expenses"),
+			fixed("d", "merge duplicates or keep both"),
+			fixed("n/esc", "change the file or mapping"),
+		}
+	}
+	return []key.Binding{
+		fixed("tab/shift+tab", "switch between the file and the mapping"),
+		fixed("ctrl+b", "read the CSV file as a bank statement or not"),
+		fixed("enter", "preview the import"),
+	}
+}
+
+func (s csvImport) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if ok && s.report != nil {
+		switch key.String() {
+		case "y":
+			report, err := export.ImportExpenses(s.app.ctx, s.app.store, s.rows, s.tripFor, s.keepDuplicates, false)
+			if err != nil {
+				s.report, s.err = nil, err
+				return s, nil
+			}
+			n := len(report.Imported)
+			return s, tea.Sequence(pop, func() tea.Msg { return expensesImportedMsg{count: n} })
+		case "d":
+			s.keepDuplicates = !s.keepDuplicates
+			report, err := export.ImportExpenses(s.app.ctx, s.app.store, s.rows, s.tripFor, s.keepDuplicates, true)
+			if err != nil {
+				s.report, s.err = nil, err
+				return s, nil
+			}
+			s.report = report
+		case "n", "esc":
+			s.report = nil
+		}
+		return s, nil
+	}
+	if ok {
+		switch key.String() {
+		case "tab", "shift+tab", "up", "down":
+			s.focus = 1 - s.focus
+			if s.focus == 0 {
+				s.mapping.Blur()
+				return s, s.path.Focus()
+			}
+			s.path.Blur()
+			return s, s.mapping.Focus()
+		case "ctrl+b":
+			s.statement = !s.statement
+			return s, nil
+		case "enter":
+			s.err = nil
+			s.rows, s.report, s.err = s.preview()
+			return s, nil
+		}
+	}
+	var cmd tea.Cmd
+	if s.focus == 0 {
+		s.path, cmd = s.path.Update(msg)
+	} else {
+		s.mapping, cmd = s.mapping.Update(msg)
+	}
+	return s, cmd
+}
+
+// tripFor puts every row into the trip being viewed, but for statement
+// charges a rule files under another trip.
+func (s csvImport) tripFor(ref string) (*models.Trip, error) {
+	if ref != "" && ref != s.trip.ID {
+		if t, err := s.app.store.GetTrip(s.app.ctx, ref); err == nil {
+			return t, nil
+		}
+	}
+	return s.trip, nil
+}
+
+// preview parses the file and dry-runs the import.
+func (s *csvImport) preview() ([]export.ImportedRow, *export.ImportReport, error) {
+	path := expandHome(strings.TrimSpace(s.path.Value()))
+	if path == "" {
+		return nil, nil, errors.New("enter the CSV file to import")
+	}
+	mapping, err := export.ParseMapping(splitList(s.mapping.Value()))
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var rows []export.ImportedRow
+	s.categorized, s.credits = 0, 0
+	if s.statement || export.IsOFX(data) {
+		st, err := export.ParseStatement(bytes.NewReader(data), export.StatementOptions{
+			Mapping:         mapping,
+			DateLayouts:     []string{s.app.cfg.Layout()},
+			DefaultCurrency: s.app.cfg.DefaultCurrency,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		rules, err := s.app.store.ListRules(s.app.ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.categorized, s.credits = st.Categorize(rules, nil, s.trip), st.Credits
+		rows = st.Rows
+	} else {
+		rows, err = export.ParseExpensesCSV(bytes.NewReader(data), export.ImportOptions{
+			Mapping:         mapping,
+			DateLayouts:     []string{s.app.cfg.Layout()},
+			DefaultCurrency: s.app.cfg.DefaultCurrency,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	report, err := export.ImportExpenses(s.app.ctx, s.app.store, rows, s.tripFor, s.keepDuplicates, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return rows, report, nil
+}
+
+func (s csvImport) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("💰 Import expenses into "+s.trip.Title) + "\n\n")
+	kind := "expenses"
+	if s.statement {
+		kind = "a bank statement"
+	}
+	b.WriteString(labelStyle.Render("CSV or OFX file") + "\n" + s.path.View() + "\n")
+	b.WriteString(hintStyle.Render("CSV is read as "+kind+", OFX always as a statement • ctrl+b switch") + "\n\n")
+	b.WriteString(labelStyle.Render("Column mapping") + "\n" + s.mapping.View() + "\n")
+	b.WriteString(hintStyle.Render("Optional. Columns named "+strings.Join(export.CSVFields, ", ")+" are found automatically.") + "\n")
+	if s.err != nil {
+		b.WriteString("\n" + errorStyle.Render(s.err.Error()) + "\n")
+	}
+	if r := s.report; r != nil {
+		fmt.Fprintf(&b, "\n%s %d new, %d duplicates merged, %d errors\n", labelStyle.Render("Preview:"),
+			len(r.Imported), len(r.Duplicates), len(r.Failed))
+		if s.keepDuplicates {
+			b.WriteString(hintStyle.Render("  rows that look like duplicates are kept as well") + "\n")
+		}
+		if s.categorized > 0 || s.credits > 0 {
+			b.WriteString(hintStyle.Render(fmt.Sprintf("  %d categorized by rules, %s left out",
+				s.categorized, plural(s.credits, "credit", "credits"))) + "\n")
+		}
+		const maxIssues = 5
+		issues := 0
+		for _, row := range r.Failed {
+			if issues++; issues <= maxIssues {
+				b.WriteString(errorStyle.Render(fmt.Sprintf("  line %d: %v", row.Line, row.Err)) + "\n")
+			}
+		}
+		for _, row := range r.Duplicates {
+			if issues++; issues <= maxIssues {
+				b.WriteString(hintStyle.Render(fmt.Sprintf("  line %d: %q looks like %q of %s", row.Line, row.Expense.Description,
+					row.DuplicateOf.Description, s.app.formatDate(row.DuplicateOf.Timestamp))) + "\n")
+			}
+		}
+		if issues > maxIssues {
+			b.WriteString(hintStyle.Render(fmt.Sprintf("  … and %d more", issues-maxIssues)) + "\n")
+		}
+		dups := "d keep duplicates too"
+		if s.keepDuplicates {
+			dups = "d merge duplicates"
+		}
+		b.WriteString("\n" + hintStyle.Render(fmt.Sprintf("Import %d expenses? y/n • %s", len(r.Imported), dups)) + "\n")
+		return b.String()
+	}
+	b.WriteString("\n" + hintStyle.Render("tab switch field • enter preview • esc back") + "\n")
+	return b.String()
+}
+
+func newPathInput(value string) textinput.Model {
+	in := textinput.New()
+	in.Placeholder = "~/Downloads/expenses.csv"
+	in.CharLimit = 512
+	in.Width = 60
+	in.SetValue(value)
+	in.Focus()
+	return in
+}
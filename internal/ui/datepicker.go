@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// datePicker helps fill in a date field. It reads days written relative to
+// today, such as "next fri" or "+3d", shows the month of the day typed
+// with it marked, moves the day with keys and, once the field is left,
+// writes it out in the configured format. A picker ending a range checks
+// the day is not before the one the range starts on, and counts its
+// offsets from there.
+type datePicker struct {
+	app *app
+	// today is the day relative dates are read from, taken when the form
+	// opens.
+	today time.Time
+	// start indexes the field starting the range this one ends, or is -1
+	// when it ends none; order is the error for a day before it.
+	start int
+	order string
+}
+
+func newDatePicker(app *app) *datePicker {
+	return &datePicker{app: app, today: app.today(), start: -1}
+}
+
+// newDateRangePicker picks the last day of a range starting on the day of
+// the field start.
+func newDateRangePicker(app *app, start int, order string) *datePicker {
+	p := newDatePicker(app)
+	p.start, p.order = start, order
+	return p
+}
+
+// parse reads v as a day, offsets counting from from when it is set.
+func (p *datePicker) parse(v string, from time.Time) (time.Time, error) {
+	return p.app.parseDateFrom(v, p.today, from)
+}
+
+// update moves the day in the input on alt+←/→ by a day, alt+↑/↓ by a
+// week and pgup/pgdown by a month, from the day typed or, with none,
+// from from or today. It reports whether it took the key.
+func (p *datePicker) update(in *textinput.Model, key tea.KeyMsg, from time.Time) bool {
+	var days, months int
+	switch key.String() {
+	case "alt+left":
+		days = -1
+	case "alt+right":
+		days = 1
+	case "alt+up":
+		days = -7
+	case "alt+down":
+		days = 7
+	case "pgup":
+		months = -1
+	case "pgdown":
+		months = 1
+	default:
+		return false
+	}
+	day, err := p.parse(strings.TrimSpace(in.Value()), from)
+	if err != nil {
+		day = p.anchor(from)
+	}
+	if months != 0 {
+		day = addMonths(day, months)
+	}
+	in.SetValue(p.app.formatDate(day.AddDate(0, 0, days)))
+	in.CursorEnd()
+	return true
+}
+
+// anchor is the day moved from when none is typed.
+func (p *datePicker) anchor(from time.Time) time.Time {
+	if !from.IsZero() {
+		return from
+	}
+	return p.today
+}
+
+// view shows the day value reads as with its weekday, the length of the
+// range it ends and, but in accessible mode, the month around it.
+func (p *datePicker) view(value string, from time.Time) string {
+	var b strings.Builder
+	day, err := p.parse(strings.TrimSpace(value), from)
+	shown := p.anchor(from)
+	if err == nil {
+		shown = day
+		line := "→ " + loc.Weekday(day.Weekday()) + " " + p.app.formatDate(day)
+		if !from.IsZero() && !day.Before(from) {
+			line += " • " + plural(int(math.Round(day.Sub(from).Hours()/24))+1, "day", "days")
+		}
+		b.WriteString(labelStyle.Render(line) + "\n")
+	} else {
+		day = time.Time{}
+	}
+	if !accessible {
+		b.WriteString(p.grid(shown, day, from))
+	}
+	b.WriteString(hintStyle.Render(tr("alt+←/→ day • alt+↑/↓ week • pgup/pgdown month • such as tomorrow, fri or +3d")))
+	return b.String()
+}
+
+// grid draws the month of shown a week a line, marking day, the days from
+// from up to it, or from alone without a day, and today.
+func (p *datePicker) grid(shown, day, from time.Time) string {
+	var b strings.Builder
+	weekdays := []string{"Mo", "Tu", "We", "Th", "Fr", "Sa", "Su"}
+	for i, d := range weekdays {
+		weekdays[i] = tr(d)
+	}
+	b.WriteString(hintStyle.Render(" "+strings.Join(weekdays, "  ")) + "\n")
+	first := time.Date(shown.Year(), shown.Month(), 1, 0, 0, 0, 0, time.Local)
+	// Weeks start on Monday; Go counts from Sunday.
+	offset := (int(first.Weekday()) + 6) % 7
+	b.WriteString(strings.Repeat("    ", offset))
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		cell := fmt.Sprintf("%2d", d.Day())
+		switch {
+		case d.Equal(day):
+			cell = cursorStyle.Render("[" + cell + "]")
+		case !from.IsZero() && (d.Equal(from) || d.After(from) && d.Before(day)):
+			cell = " " + highlightStyle.Render(cell) + " "
+		case d.Equal(p.today):
+			cell = " " + labelStyle.Render(cell) + " "
+		default:
+			cell = " " + cell + " "
+		}
+		b.WriteString(cell)
+		if (offset+d.Day())%7 == 0 || d.AddDate(0, 0, 1).Month() != first.Month() {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// datePickerHelp describes the keys of a datePicker.
+func datePickerHelp() []key.Binding {
+	return []key.Binding{
+		fixed("alt+left/alt+right", "previous or next day"),
+		fixed("alt+up/alt+down", "previous or next week"),
+		fixed("pgup/pgdown", "previous or next month"),
+	}
+}
+
+// rangeStart is the day in the field starting the range field i ends, or
+// zero when it ends none or that field holds no day.
+func (f form) rangeStart(i int) time.Time {
+	p := f.fields[i].dates
+	if p == nil || p.start < 0 {
+		return time.Time{}
+	}
+	start, err := p.parse(f.value(p.start), time.Time{})
+	if err != nil {
+		return time.Time{}
+	}
+	return start
+}
+
+// pickDate writes the day typed in the date field i in the configured
+// format, rejecting one before the start of its range. An empty field is
+// left to its validation.
+func (f *form) pickDate(i int) error {
+	p, v := f.fields[i].dates, f.value(i)
+	if p == nil || v == "" {
+		return nil
+	}
+	from := f.rangeStart(i)
+	day, err := p.parse(v, from)
+	if err != nil {
+		return err
+	}
+	if !from.IsZero() && day.Before(from) {
+		return errors.New(p.order)
+	}
+	f.fields[i].input.SetValue(p.app.formatDate(day))
+	return nil
+}
+
+// addMonths moves day n months away, to the last day of that month when
+// it is shorter.
+func addMonths(day time.Time, n int) time.Time {
+	y, m, d := day.Date()
+	first := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, day.Location())
+	return time.Date(first.Year(), first.Month(), min(d, first.AddDate(0, 1, -1).Day()), 0, 0, 0, 0, day.Location())
+}
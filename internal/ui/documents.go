@@ -0,0 +1,344 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/viewer"
+)
+
+// documentList shows the documents kept with a trip, or with one item of
+// its itinerary, and opens them.
+type documentList struct {
+	app       *app
+	trip      *models.Trip
+	item      *models.ItineraryItem // nil for every document of the trip
+	documents []*models.Document
+	items     map[string]*models.ItineraryItem
+	cursor    int
+
+	// adding is set while a path is being typed in.
+	adding bool
+	path   textinput.Model
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newDocumentList(app *app, trip *models.Trip, item *models.ItineraryItem) documentList {
+	in := newPathInput("")
+	in.Placeholder = "~/Downloads/boarding-pass.pdf"
+	in.Blur()
+	l := documentList{app: app, trip: trip, item: item, path: in}
+	l.reload()
+	return l
+}
+
+func (l documentList) Title() string { return tr("Documents") }
+
+func (l documentList) currentTrip() *models.Trip { return l.trip }
+
+func (l documentList) Init() tea.Cmd {
+	return nil
+}
+
+func (l documentList) capturesEsc() bool { return l.confirmDelete || l.adding }
+
+func (l documentList) typing() bool { return l.adding }
+
+func (l documentList) help() []key.Binding {
+	if l.adding {
+		return []key.Binding{
+			fixed("enter", "copy the file into nomadic"),
+			l.app.bind("link", "link to the original file"),
+			fixed("esc", "cancel"),
+		}
+	}
+	return append([]key.Binding{
+		l.app.bind("up", "previous document"),
+		l.app.bind("down", "next document"),
+		l.app.bind("new", "add a document"),
+		l.app.bind("open", "open in the default viewer"),
+		l.app.bind("edit", "edit the title and note"),
+		l.app.bind("delete", "remove the document"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *documentList) reload() {
+	if l.item != nil {
+		l.documents, l.err = l.app.store.ListDocumentsByItem(l.app.ctx, l.item.ID)
+	} else {
+		l.documents, l.err = l.app.store.ListDocumentsByTrip(l.app.ctx, l.trip.ID)
+	}
+	l.cursor = clamp(l.cursor, 0, len(l.documents)-1)
+	if l.err != nil || l.item != nil {
+		return
+	}
+	items, err := l.app.store.ListItineraryByTrip(l.app.ctx, l.trip.ID)
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.items = make(map[string]*models.ItineraryItem, len(items))
+	for _, it := range items {
+		l.items[it.ID] = it
+	}
+}
+
+func (l documentList) selected() *models.Document {
+	if len(l.documents) == 0 {
+		return nil
+	}
+	return l.documents[l.cursor]
+}
+
+// changed tells the screens below that the trip's documents changed.
+func (l documentList) changed() tea.Cmd {
+	id := l.trip.ID
+	return func() tea.Msg { return documentsChangedMsg{tripID: id} }
+}
+
+func (l documentList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case documentsChangedMsg:
+		if msg.tripID == l.trip.ID {
+			l.reload()
+		}
+		return l, nil
+	case historyMsg:
+		l.status = ""
+		l.reload()
+		return l, nil
+	case tea.KeyMsg:
+		if l.adding {
+			return l.updateAdding(msg)
+		}
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				d := l.selected()
+				if err := l.app.run(removeDocument{document: d}); err != nil {
+					l.err = err
+					return l, nil
+				}
+				l.status = fmt.Sprintf("Removed %q • %s to undo", d.Title, l.app.keyHint("undo"))
+				return l, l.changed()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+
+		l.status, l.err = "", nil
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.documents)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			l.adding = true
+			l.path.SetValue("")
+			return l, l.path.Focus()
+		case l.app.is(msg, "select"), l.app.is(msg, "open"):
+			if d := l.selected(); d != nil {
+				if err := viewer.Open(l.app.store.DocumentPath(d)); err != nil {
+					l.err = fmt.Errorf("open %s: %w", d.Name, err)
+				} else {
+					l.status = "Opened " + d.Title
+				}
+			}
+		case l.app.is(msg, "edit"):
+			if d := l.selected(); d != nil {
+				return l, push(newDocumentForm(l.app, d))
+			}
+		case l.app.is(msg, "delete"):
+			if l.selected() != nil {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+// updateAdding edits the path of a new document. Enter copies the file
+// into the data directory, the "link" key (ctrl+l) links to it instead.
+func (l documentList) updateAdding(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.String() == "esc":
+		l.adding = false
+		l.path.Blur()
+		return l, nil
+	case key.String() == "enter", l.app.is(key, "link"):
+		path := expandHome(strings.TrimSpace(l.path.Value()))
+		if path == "" {
+			l.err = errors.New("enter the path of a file to add")
+			return l, nil
+		}
+		var itemID string
+		if l.item != nil {
+			itemID = l.item.ID
+		}
+		d, err := l.app.store.AttachDocument(l.app.ctx, l.trip.ID, itemID, path, l.app.is(key, "link"))
+		if err != nil {
+			l.err = err
+			return l, nil
+		}
+		l.adding, l.err = false, nil
+		l.path.Blur()
+		l.status = fmt.Sprintf("Added %s • %s to give it a title", d.Name, l.app.keyHint("edit"))
+		l.reload()
+		l.cursor = len(l.documents) - 1
+		return l, l.changed()
+	}
+	var cmd tea.Cmd
+	l.path, cmd = l.path.Update(key)
+	return l, cmd
+}
+
+func (l documentList) View() string {
+	var b strings.Builder
+	title := l.trip.Title
+	if l.item != nil {
+		title = l.item.Title
+	}
+	b.WriteString(headerStyle.Render("🗂️  "+title) + "\n\n")
+	if len(l.documents) == 0 && !l.adding {
+		b.WriteString("No documents yet — press " + l.app.keyHint("new") + " to add a ticket or booking.\n")
+	}
+	for i, d := range l.documents {
+		cursor := "  "
+		if i == l.cursor {
+			cursor = "👉"
+		}
+		details := []string{formatSize(d.Size)}
+		if d.Title != d.Name {
+			details = append([]string{d.Name}, details...)
+		}
+		if d.Linked {
+			details = append(details, "linked")
+		}
+		if it := l.items[d.ItemID]; it != nil {
+			details = append(details, "for "+it.Title)
+		}
+		fmt.Fprintf(&b, "%s %s %s  %s\n", cursor, documentIcon(d), d.Title, hintStyle.Render(strings.Join(details, " · ")))
+		if d.Note != "" {
+			fmt.Fprintf(&b, "      %s\n", hintStyle.Render(d.Note))
+		}
+	}
+	if l.adding {
+		b.WriteString("\n" + labelStyle.Render("File to add") + "\n" + l.path.View() + "\n")
+	}
+	if l.err != nil {
+		b.WriteString("\n" + errorStyle.Render(l.err.Error()) + "\n")
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Remove %q? y/n", l.selected().Title)) + "\n")
+	}
+	hint := l.app.keyHint("new") + " add • enter/" + l.app.keyHint("open") + " open • " +
+		l.app.keyHint("edit") + " edit • " + l.app.keyHint("delete") + " remove • " +
+		l.app.keyHint("undo") + " undo • esc back"
+	if l.adding {
+		hint = "enter copy into nomadic • " + l.app.keyHint("link") + " link to the original • esc cancel"
+	}
+	b.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return b.String()
+}
+
+// documentIcon suggests what kind of file a document is.
+func documentIcon(d *models.Document) string {
+	switch {
+	case d.ContentType == "application/pdf":
+		return "📄"
+	case strings.HasPrefix(d.ContentType, "image/"):
+		return "🖼️"
+	}
+	return "📎"
+}
+
+// documentCount describes how many documents are kept with an itinerary
+// item, or is empty when there are none.
+func documentCount(counts map[string]int, itemID string) string {
+	if counts[itemID] == 0 {
+		return ""
+	}
+	return "🗂️  " + plural(counts[itemID], "document", "documents")
+}
+
+const (
+	documentFieldTitle = iota
+	documentFieldNote
+)
+
+// documentForm edits what is recorded about a document.
+type documentForm struct {
+	form
+	app      *app
+	document *models.Document
+}
+
+func newDocumentForm(app *app, d *models.Document) documentForm {
+	f := newForm("🗂️  "+d.Name,
+		newField("Title", "Boarding pass", "What the document is.", required("title")),
+		newField("Note", "", "Optional, e.g. a seat or when check-in opens.", nil),
+	)
+	f.fields[documentFieldTitle].input.SetValue(d.Title)
+	f.fields[documentFieldNote].input.SetValue(d.Note)
+	return documentForm{form: f, app: app, document: d}
+}
+
+func (f documentForm) Title() string { return tr("Edit Document") }
+
+func (f documentForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f documentForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		d := *f.document
+		d.Title, d.Note = f.value(documentFieldTitle), f.value(documentFieldNote)
+		if err := f.app.store.SaveDocument(f.app.ctx, &d); err != nil {
+			f.err = err
+			return f, nil
+		}
+		id := d.TripID
+		return f, tea.Sequence(pop, func() tea.Msg { return documentsChangedMsg{tripID: id} })
+	}
+	return f, cmd
+}
+
+func (f documentForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		for i, label := range []string{"Title", "Note"} {
+			v := f.value(i)
+			if v == "" {
+				v = hintStyle.Render("—")
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(label+":"), v)
+		}
+		return b.String()
+	})
+}
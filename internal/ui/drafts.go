@@ -0,0 +1,323 @@
+package ui
+
+import (
+	"context"
+
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// draftInterval is how often the work in progress on the stack is saved
+// as drafts.
+const draftInterval = 5 * time.Second
+
+// drafter is implemented by screens that edit something before saving it:
+// the journal editor and the forms. Their work is saved as a draft while
+// they are open, so it survives the terminal closing, and offered back on
+// the next start.
+type drafter interface {
+	// draft returns the work to keep, or nil while nothing was typed.
+	draft() *models.Draft
+}
+
+// draftTickMsg asks for the work in progress to be saved.
+type draftTickMsg struct{}
+
+func draftTick() tea.Cmd {
+	return tea.Tick(draftInterval, func(time.Time) tea.Msg { return draftTickMsg{} })
+}
+
+// newDraft describes the work of a screen on record, which has the given
+// ID. title is the screen's and name what was typed as the record's name.
+func newDraft(kind, id, title, name string, isNew bool, record any, values []string) *models.Draft {
+	if name = strings.TrimSpace(name); name != "" && !strings.HasSuffix(title, name) {
+		title += ": " + name
+	}
+	d := &models.Draft{ID: kind + "-" + id, Kind: kind, Title: title, IsNew: isNew, Values: values, SavedAt: time.Now()}
+	if record != nil {
+		// The records are plain data and always marshal.
+		d.Record, _ = json.Marshal(record)
+	}
+	return d
+}
+
+// saveDrafts saves the drafts of the screens on the stack that changed
+// since they were last saved, and deletes those of screens that were
+// closed, whether their work was saved or cancelled.
+func (m *Model) saveDrafts() {
+	if m.app.store == nil {
+		return
+	}
+	m.draftErr = nil
+	open := map[string]bool{}
+	for _, s := range m.stack {
+		d, ok := s.(drafter)
+		if !ok {
+			continue
+		}
+		draft := d.draft()
+		if draft == nil {
+			continue
+		}
+		open[draft.ID] = true
+		if saved, ok := m.drafts[draft.ID]; ok && slices.Equal(saved, draft.Values) {
+			continue
+		}
+		if err := m.app.store.SaveDraft(draft); err != nil {
+			m.draftErr = err
+			continue
+		}
+		m.drafts[draft.ID] = draft.Values
+	}
+	for id := range m.drafts {
+		if open[id] {
+			continue
+		}
+		if err := m.app.store.DeleteDraft(id); err != nil {
+			m.draftErr = err
+			continue
+		}
+		delete(m.drafts, id)
+	}
+}
+
+// home is the stack to start on: the home menu, with the screens it was
+// left on last time over it, or the offer to reopen them, as the resume
+// setting has it, and the drafts left unsaved last time on top when there
+// are any and they can be saved.
+func home(a *app) []screen {
+	stack := []screen{newMenu(a)}
+	if a.readOnly() {
+		return stack
+	}
+	if sess := a.lastSession(); sess != nil {
+		if a.cfg.Resume != config.ResumeOn {
+			stack = append(stack, newResumeOffer(a, sess))
+		} else if screens, err := restoreSession(a, sess); err == nil {
+			stack = append(stack, screens...)
+		} else {
+			slog.Warn("ui: resuming the session failed", "err", err)
+		}
+	}
+	if drafts, err := a.store.ListDrafts(); err == nil && len(drafts) > 0 {
+		stack = append(stack, newDraftList(a, drafts))
+	}
+	return stack
+}
+
+// restoreDraft opens the screen a draft was saved from, with what was
+// typed into it. Records that existed before are read again, so changes
+// saved since are kept outside the fields typed into.
+func restoreDraft(a *app, d *models.Draft) (screen, error) {
+	id := strings.TrimPrefix(d.ID, d.Kind+"-")
+	switch d.Kind {
+	case models.DraftEntry:
+		e, err := draftRecord(a, d, func(e *models.Entry) string { return e.TripID }, a.store.GetEntry)
+		if err != nil {
+			return nil, err
+		}
+		s := newEntryEditor(a, e, d.IsNew)
+		s.restore(d.Values)
+		return s, nil
+	case models.DraftTrip:
+		s := newTripForm(a)
+		if len(d.Record) > 0 && string(d.Record) != "null" {
+			var tpl models.Template
+			if err := json.Unmarshal(d.Record, &tpl); err != nil {
+				return nil, err
+			}
+			s = newTripFormFrom(a, &tpl)
+		}
+		s.draftID = id
+		s.restore(d.Values)
+		return s, nil
+	case models.DraftExpense:
+		x, err := draftRecord(a, d, func(x *models.Expense) string { return x.TripID }, a.store.GetExpense)
+		if err != nil {
+			return nil, err
+		}
+		s := newExpenseForm(a, x, d.IsNew)
+		s.restore(d.Values)
+		return s, nil
+	case models.DraftItinerary:
+		it, err := draftRecord(a, d, func(it *models.ItineraryItem) string { return it.TripID }, a.store.GetItineraryItem)
+		if err != nil {
+			return nil, err
+		}
+		s := newItineraryForm(a, it, d.IsNew)
+		s.restore(d.Values)
+		return s, nil
+	case models.DraftLeg:
+		l, err := draftRecord(a, d, func(l *models.Leg) string { return l.TripID }, a.store.GetLeg)
+		if err != nil {
+			return nil, err
+		}
+		t, err := a.store.GetTrip(a.ctx, l.TripID)
+		if err != nil {
+			return nil, err
+		}
+		s := newLegForm(a, t, l, d.IsNew)
+		s.restore(d.Values)
+		return s, nil
+	case models.DraftLodging:
+		l, err := draftRecord(a, d, func(l *models.Lodging) string { return l.TripID }, a.store.GetLodging)
+		if err != nil {
+			return nil, err
+		}
+		t, err := a.store.GetTrip(a.ctx, l.TripID)
+		if err != nil {
+			return nil, err
+		}
+		s := newLodgingForm(a, t, l, d.IsNew)
+		s.restore(d.Values)
+		return s, nil
+	}
+	return nil, fmt.Errorf("cannot restore a draft of a %s", d.Kind)
+}
+
+// draftRecord reads the record of a draft: as it is now for records that
+// existed, or as it was drafted for new ones, whose trip must still exist.
+func draftRecord[T any](a *app, d *models.Draft, tripID func(*T) string, get func(context.Context, string) (*T, error)) (*T, error) {
+	rec := new(T)
+	if err := json.Unmarshal(d.Record, rec); err != nil {
+		return nil, fmt.Errorf("the draft is damaged: %w", err)
+	}
+	var err error
+	if d.IsNew {
+		_, err = a.store.GetTrip(a.ctx, tripID(rec))
+	} else {
+		rec, err = get(a.ctx, strings.TrimPrefix(d.ID, d.Kind+"-"))
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, errors.New("what this draft was for has been deleted since")
+	}
+	return rec, err
+}
+
+// draftList offers back the drafts of work left unsaved when nomadic last
+// closed, to finish or discard.
+type draftList struct {
+	app    *app
+	drafts []*models.Draft
+	cursor int
+
+	confirmDiscard bool
+	err            error
+}
+
+func newDraftList(app *app, drafts []*models.Draft) draftList {
+	return draftList{app: app, drafts: drafts}
+}
+
+func (l draftList) Title() string { return tr("Drafts") }
+
+func (l draftList) Init() tea.Cmd {
+	return nil
+}
+
+func (l draftList) capturesEsc() bool { return l.confirmDiscard }
+
+func (l draftList) help() []key.Binding {
+	return []key.Binding{
+		l.app.bind("up", "previous draft"),
+		l.app.bind("down", "next draft"),
+		l.app.bind("select", "pick up where you left off"),
+		l.app.bind("delete", "discard the draft"),
+		fixed("esc", "keep the drafts for later"),
+	}
+}
+
+func (l draftList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return l, nil
+	}
+	if l.confirmDiscard {
+		l.confirmDiscard = false
+		if key.String() == "y" {
+			if err := l.app.store.DeleteDraft(l.drafts[l.cursor].ID); err != nil {
+				l.err = err
+				return l, nil
+			}
+			l.remove()
+			if len(l.drafts) == 0 {
+				return l, pop
+			}
+		}
+		return l, nil
+	}
+	switch {
+	case l.app.is(key, "up"):
+		if l.cursor > 0 {
+			l.cursor--
+		}
+	case l.app.is(key, "down"):
+		if l.cursor < len(l.drafts)-1 {
+			l.cursor++
+		}
+	case l.app.is(key, "select"):
+		if len(l.drafts) == 0 {
+			return l, nil
+		}
+		s, err := restoreDraft(l.app, l.drafts[l.cursor])
+		if err != nil {
+			l.err = err
+			return l, nil
+		}
+		l.err = nil
+		// The restored screen saves the draft again as its own.
+		l.remove()
+		if len(l.drafts) == 0 {
+			return l, tea.Sequence(pop, push(s))
+		}
+		return l, push(s)
+	case l.app.is(key, "delete"):
+		if len(l.drafts) > 0 {
+			l.confirmDiscard = true
+		}
+	}
+	return l, nil
+}
+
+// remove takes the selected draft off the list.
+func (l *draftList) remove() {
+	l.drafts = slices.Delete(l.drafts, l.cursor, l.cursor+1)
+	l.cursor = clamp(l.cursor, 0, len(l.drafts)-1)
+}
+
+func (l draftList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("📝 Unsaved drafts") + "\n\n")
+	if len(l.drafts) == 0 {
+		b.WriteString("No drafts left.\n")
+	} else {
+		b.WriteString("nomadic closed before this work was saved.\n\n")
+	}
+	for i, d := range l.drafts {
+		cursor, title := "  ", d.Title
+		if i == l.cursor {
+			cursor, title = "👉", cursorStyle.Render(title)
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, title, hintStyle.Render(l.app.formatDate(d.SavedAt)+" "+d.SavedAt.Format("15:04")))
+	}
+	if l.err != nil {
+		b.WriteString("\n" + errorStyle.Render(l.err.Error()) + "\n")
+	}
+	if l.confirmDiscard {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Discard %q? It cannot be undone. y/n", l.drafts[l.cursor].Title)) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • enter restore • "+l.app.keyHint("delete")+" discard • esc later") + "\n")
+	return b.String()
+}
@@ -0,0 +1,422 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const (
+	editorFocusTitle = iota
+	editorFocusDate
+	editorFocusLocation
+	editorFocusTags
+	editorFocusWith
+	editorFocusMood
+	editorFocusBody
+	editorFocusCount
+)
+
+// entryEditor edits a journal entry's title, date, location, tags, the
+// companions it was written with, mood and Markdown body, with a live rendered preview of the body beside it.
+// The "save" key (ctrl+s) saves the entry and returns to the previous
+// screen.
+type entryEditor struct {
+	app   *app
+	entry *models.Entry
+	isNew bool
+
+	title    textinput.Model
+	date     textinput.Model
+	location textinput.Model
+	// places suggests city and country names for the location.
+	places *autocomplete
+	tags   textinput.Model
+	// complete suggests tags already in use.
+	complete *tagCompleter
+	with     textinput.Model
+	// companions are the trip's, who the entry can be written with.
+	companions []string
+	people     *tagCompleter
+	mood       textinput.Model
+	body       textarea.Model
+	focus      int
+
+	preview *markdownRenderer
+	// dirty is set once anything was typed, so there is work to keep as
+	// a draft.
+	dirty bool
+
+	width, height int
+	err           error
+}
+
+func newEntryEditor(app *app, entry *models.Entry, isNew bool) entryEditor {
+	// Work on a copy so cancelling leaves the caller's entry untouched.
+	edited := *entry
+	e := entryEditor{app: app, entry: &edited, isNew: isNew, preview: newMarkdownRenderer(app)}
+
+	e.title = textinput.New()
+	e.title.Placeholder = "A day in Shibuya"
+	e.title.SetValue(entry.Title)
+
+	e.date = textinput.New()
+	e.date.Placeholder = app.cfg.DateFormat
+	e.date.SetValue(app.formatDate(entry.Timestamp))
+
+	e.location = textinput.New()
+	e.location.Placeholder = "Kyoto"
+	e.location.SetValue(entry.Location)
+	e.places = newPlaceCompleter(false)
+
+	e.tags = textinput.New()
+	e.tags.Placeholder = "food, friends"
+	e.tags.SetValue(strings.Join(entry.Tags, ", "))
+	e.complete = newTagCompleter(app)
+
+	e.with = textinput.New()
+	e.with.Placeholder = "Ana, Ben"
+	e.with.SetValue(strings.Join(entry.People, ", "))
+	if trip, err := app.store.GetTrip(app.ctx, entry.TripID); err == nil {
+		e.companions = trip.Companions
+	}
+	e.people = newNameCompleter(e.companions)
+
+	e.mood = textinput.New()
+	e.mood.Placeholder = fmt.Sprintf("1–%d", models.MaxRating)
+	e.mood.CharLimit = 1
+	if entry.Mood > 0 {
+		e.mood.SetValue(strconv.Itoa(entry.Mood))
+	}
+
+	e.body = textarea.New()
+	e.body.Placeholder = "Write in Markdown…"
+	e.body.CharLimit = 0
+	e.body.ShowLineNumbers = false
+	e.body.SetValue(entry.Text)
+
+	e.title.Focus()
+	e.resize(80, 24)
+	return e
+}
+
+func (e entryEditor) Title() string {
+	if e.isNew {
+		return tr("New entry")
+	}
+	return tr("Edit %s", e.entry.Title)
+}
+
+func (e entryEditor) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (e entryEditor) typing() bool { return true }
+
+func (e entryEditor) help() []key.Binding {
+	bindings := []key.Binding{
+		fixed("tab/shift+tab", "next or previous field"),
+		e.app.bind("save", "save the entry"),
+		fixed("esc", "cancel"),
+	}
+	if e.focus == editorFocusLocation || e.focus == editorFocusTags || e.focus == editorFocusWith {
+		bindings = append(bindings, completionHelp()...)
+	}
+	return bindings
+}
+
+func (e *entryEditor) resize(width, height int) {
+	e.width, e.height = width, height
+	pane := e.paneWidth()
+	e.title.Width = pane - 4
+	e.date.Width = pane - 4
+	e.location.Width = pane - 4
+	e.tags.Width = pane - 4
+	e.with.Width = pane - 4
+	e.mood.Width = 3
+	e.body.SetWidth(pane - 2)
+	// One line is kept free for place or tag suggestions.
+	h := height - 21
+	if e.width < narrowWidth {
+		h -= e.previewHeight() + 2
+	}
+	if h < 5 {
+		h = 5
+	}
+	e.body.SetHeight(h)
+}
+
+// paneWidth is the width of the editor and preview panes: half the
+// terminal each, or all of it when narrow and the preview goes below.
+func (e entryEditor) paneWidth() int {
+	if e.width < narrowWidth {
+		return max(e.width-2, 20)
+	}
+	w := e.width/2 - 2
+	if w < 30 {
+		w = 30
+	}
+	return w
+}
+
+// previewHeight is how many lines the preview shows below the editor on
+// narrow terminals.
+func (e entryEditor) previewHeight() int {
+	return max(e.height/4, 3)
+}
+
+func (e *entryEditor) setFocus(i int) tea.Cmd {
+	e.title.Blur()
+	e.date.Blur()
+	e.location.Blur()
+	e.tags.Blur()
+	e.with.Blur()
+	e.mood.Blur()
+	e.body.Blur()
+	e.focus = (i + editorFocusCount) % editorFocusCount
+	switch e.focus {
+	case editorFocusTitle:
+		return e.title.Focus()
+	case editorFocusDate:
+		return e.date.Focus()
+	case editorFocusLocation:
+		return e.location.Focus()
+	case editorFocusTags:
+		return e.tags.Focus()
+	case editorFocusWith:
+		return e.with.Focus()
+	case editorFocusMood:
+		return e.mood.Focus()
+	default:
+		return e.body.Focus()
+	}
+}
+
+func (e entryEditor) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		e.resize(msg.Width, msg.Height)
+		return e, nil
+	case tea.KeyMsg:
+		switch {
+		case e.app.is(msg, "save"):
+			before := *e.entry
+			if err := e.apply(); err != nil {
+				e.err = err
+				return e, nil
+			}
+			// Weather belongs to a day and a place; when either changes
+			// it is looked up again.
+			refetch := e.isNew || !dateOf(before.Timestamp).Equal(dateOf(e.entry.Timestamp)) ||
+				before.Location != e.entry.Location
+			if refetch {
+				e.entry.Weather = nil
+			}
+			if err := e.app.store.SaveEntry(e.app.ctx, e.entry); err != nil {
+				e.err = err
+				return e, nil
+			}
+			entry := e.entry
+			var weather tea.Cmd
+			if refetch {
+				weather = e.app.fetchWeather(entry)
+			}
+			return e, tea.Sequence(pop, func() tea.Msg { return entrySavedMsg{entry: entry} }, weather)
+		case msg.String() == "tab":
+			return e, e.setFocus(e.focus + 1)
+		case msg.String() == "shift+tab":
+			return e, e.setFocus(e.focus - 1)
+		}
+	}
+
+	var before []string
+	if !e.dirty {
+		before = e.values()
+	}
+	cmd := e.edit(msg)
+	if !e.dirty && !slices.Equal(before, e.values()) {
+		e.dirty = true
+	}
+	return e, cmd
+}
+
+// edit passes msg to the focused input.
+func (e *entryEditor) edit(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch e.focus {
+	case editorFocusTitle:
+		e.title, cmd = e.title.Update(msg)
+	case editorFocusDate:
+		e.date, cmd = e.date.Update(msg)
+	case editorFocusLocation:
+		if key, ok := msg.(tea.KeyMsg); ok && e.places.update(&e.location, key) {
+			return nil
+		}
+		e.location, cmd = e.location.Update(msg)
+	case editorFocusTags:
+		if key, ok := msg.(tea.KeyMsg); ok && e.complete.update(&e.tags, key) {
+			return nil
+		}
+		e.tags, cmd = e.tags.Update(msg)
+	case editorFocusWith:
+		if key, ok := msg.(tea.KeyMsg); ok && e.people.update(&e.with, key) {
+			return nil
+		}
+		e.with, cmd = e.with.Update(msg)
+	case editorFocusMood:
+		e.mood, cmd = e.mood.Update(msg)
+	default:
+		e.body, cmd = e.body.Update(msg)
+	}
+	e.err = nil
+	return cmd
+}
+
+// values returns what is in the inputs, for drafts. The mood and the
+// companions come last, after the body, in the order they were added to
+// the editor, so older drafts still restore.
+func (e entryEditor) values() []string {
+	return []string{e.title.Value(), e.date.Value(), e.location.Value(), e.tags.Value(), e.body.Value(), e.mood.Value(), e.with.Value()}
+}
+
+// restore fills the inputs with the values of a draft.
+func (e *entryEditor) restore(values []string) {
+	inputs := []*textinput.Model{&e.title, &e.date, &e.location, &e.tags}
+	for i, in := range inputs {
+		if i < len(values) {
+			in.SetValue(values[i])
+		}
+	}
+	if len(values) > len(inputs) {
+		e.body.SetValue(values[len(inputs)])
+	}
+	if len(values) > len(inputs)+1 {
+		e.mood.SetValue(values[len(inputs)+1])
+	}
+	if len(values) > len(inputs)+2 {
+		e.with.SetValue(values[len(inputs)+2])
+	}
+	e.dirty = true
+}
+
+func (e entryEditor) draft() *models.Draft {
+	if !e.dirty {
+		return nil
+	}
+	return newDraft(models.DraftEntry, e.entry.ID, e.Title(), e.title.Value(), e.isNew, e.entry, e.values())
+}
+
+// apply validates the inputs and copies them into the entry.
+func (e *entryEditor) apply() error {
+	title := strings.TrimSpace(e.title.Value())
+	if title == "" {
+		return errors.New("title is required")
+	}
+	date, err := e.app.parseDate(strings.TrimSpace(e.date.Value()))
+	if err != nil {
+		return err
+	}
+	mood, err := parseRating(e.mood.Value())
+	if err != nil {
+		return fmt.Errorf("mood: %w", err)
+	}
+	with, err := models.ParseWith(splitList(e.with.Value()), e.companions)
+	if err != nil {
+		return err
+	}
+	location := strings.TrimSpace(e.location.Value())
+	// Keep the time of day of an existing entry when only the date
+	// changes, on the clock of where it was written. Entries from before
+	// time zones were recorded stay in the local zone.
+	ts := e.entry.Timestamp
+	if e.isNew || e.entry.TimeZone != "" {
+		e.entry.TimeZone = e.app.zoneOn(e.entry.TripID, location, date)
+	}
+	date = time.Date(date.Year(), date.Month(), date.Day(), ts.Hour(), ts.Minute(), ts.Second(), 0, models.Zone(e.entry.TimeZone))
+	// An entry moved to another day belongs to the leg of that day.
+	if e.isNew || !dateOf(date).Equal(dateOf(ts)) {
+		if e.entry.LegID, err = e.app.legOn(e.entry.TripID, date); err != nil {
+			return err
+		}
+	}
+
+	e.entry.Title = title
+	e.entry.Timestamp = date
+	e.entry.Location = location
+	e.entry.Tags = splitList(e.tags.Value())
+	e.entry.People = with
+	e.entry.Mood = mood
+	e.entry.Text = e.body.Value()
+	return nil
+}
+
+func (e entryEditor) View() string {
+	pane := e.paneWidth()
+
+	var left strings.Builder
+	label := func(text string, focused bool) string {
+		if focused {
+			return headerStyle.Render(text)
+		}
+		return labelStyle.Render(text)
+	}
+	left.WriteString(label("Title", e.focus == editorFocusTitle) + "\n" + e.title.View() + "\n")
+	left.WriteString(label("Date", e.focus == editorFocusDate) + "\n" + e.date.View() + "\n")
+	left.WriteString(label("Location", e.focus == editorFocusLocation) + "\n" + e.location.View() + "\n")
+	if e.focus == editorFocusLocation {
+		if s := e.places.view(e.location.Value()); s != "" {
+			left.WriteString(s + "\n")
+		} else if d := placeDetails(e.location.Value()); d != "" {
+			left.WriteString(hintStyle.Render(d) + "\n")
+		}
+	}
+	left.WriteString(label("Tags", e.focus == editorFocusTags) + "\n" + e.tags.View() + "\n")
+	if e.focus == editorFocusTags {
+		if s := e.complete.view(e.tags.Value()); s != "" {
+			left.WriteString(s + "\n")
+		}
+	}
+	left.WriteString(label("With", e.focus == editorFocusWith) + "\n" + e.with.View() + "\n")
+	if e.focus == editorFocusWith {
+		if len(e.companions) == 0 {
+			left.WriteString(hintStyle.Render("Add companions to the trip to note who you were with.") + "\n")
+		} else if s := e.people.view(e.with.Value()); s != "" {
+			left.WriteString(s + "\n")
+		}
+	}
+	left.WriteString(label("Mood", e.focus == editorFocusMood) + "\n" + e.mood.View())
+	if mood, err := parseRating(e.mood.Value()); err == nil && mood > 0 {
+		left.WriteString(" " + models.MoodFace(mood))
+	} else {
+		left.WriteString(hintStyle.Render(fmt.Sprintf(" 1 %s to %d %s, optional", models.MoodFace(1), models.MaxRating, models.MoodFace(models.MaxRating))))
+	}
+	left.WriteString("\n")
+	left.WriteString(label("Entry", e.focus == editorFocusBody) + "\n" + e.body.View())
+
+	right := labelStyle.Render("Preview") + "\n" + e.preview.render(e.body.Value(), pane-4)
+
+	editor := paneStyle.Width(pane).Render(left.String())
+	height := lipgloss.Height(editor) - 2
+	if e.width < narrowWidth {
+		height = e.previewHeight()
+	}
+	preview := paneStyle.Width(pane).Height(height).MaxHeight(height + 2).Render(right)
+
+	out := panes(e.width, editor, preview) + "\n"
+	if e.err != nil {
+		out += errorStyle.Render(e.err.Error()) + "\n"
+	}
+	out += hintStyle.Render("tab next field • "+e.app.keyHint("save")+" save • esc cancel") + "\n"
+	return out
+}
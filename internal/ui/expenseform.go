@@ -0,0 +1,410 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/inbox"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const (
+	expenseFieldAmount = iota
+	expenseFieldTax
+	expenseFieldTip
+	expenseFieldCurrency
+	expenseFieldCategory
+	expenseFieldDate
+	expenseFieldDescription
+	expenseFieldLocation
+	expenseFieldNote
+	expenseFieldPaidBy
+	expenseFieldSplit
+	expenseFieldTags
+)
+
+// expenseForm adds or edits a single expense and saves it on confirmation.
+type expenseForm struct {
+	form
+	app     *app
+	expense *models.Expense
+	isNew   bool
+	// people are who can pay for and share the expense.
+	people []string
+	// duplicate is an expense already recorded that a new one looks like,
+	// awaiting the choice to merge into it or keep both; keepBoth is that
+	// choice made.
+	duplicate *models.Expense
+	keepBoth  bool
+	// receipt is the photo of the receipt the expense was read from, kept
+	// with it once saved. inboxed is set when it is a file of the receipts
+	// inbox, filed away then.
+	receipt string
+	inboxed bool
+}
+
+func newExpenseForm(app *app, x *models.Expense, isNew bool) expenseForm {
+	title := "💰 Edit Expense"
+	if isNew {
+		title = "💰 New Expense"
+	}
+	people := []string{models.Me}
+	if t, err := app.store.GetTrip(app.ctx, x.TripID); err == nil {
+		people = t.People()
+	}
+	who := "Optional. Add companions to the trip to share expenses with them."
+	if len(people) > 1 {
+		who = "Optional. One of " + strings.Join(people, ", ") + "."
+	}
+	f := newForm(title,
+		newField("Amount", "12.50", "", validatePositiveAmount),
+		newField("Tax", "0", "Optional. The part of the amount that went on tax.", validatePart),
+		newField("Tip", "0", "Optional. The part of the amount that went on a tip.", validatePart),
+		newField("Currency", "EUR", "Three-letter ISO code.", validateCurrency),
+		newField("Category", models.CategoryFood, strings.Join(models.Categories, " • "), validateCategory),
+		newField("Date", app.cfg.DateFormat, "", app.validateDate),
+		newField("Description", "Ramen at Ichiran", "", required("description")),
+		newField("Location", "Kyoto", "Optional. Where the money was spent, if not where the trip was that day.", nil),
+		newField("Note", "", "Optional.", nil),
+		newField("Paid by", models.Me, who, validatePerson(people)),
+		newField("Split", "all", "Optional. all, names sharing equally, or name=amount, e.g. me=10, Ana.", nil),
+		newTagsField(app, x.Tags),
+	)
+	if x.Amount != 0 {
+		f.fields[expenseFieldAmount].input.SetValue(strconv.FormatFloat(x.Amount, 'f', -1, 64))
+	}
+	if x.Tax != 0 {
+		f.fields[expenseFieldTax].input.SetValue(strconv.FormatFloat(x.Tax, 'f', -1, 64))
+	}
+	if x.Tip != 0 {
+		f.fields[expenseFieldTip].input.SetValue(strconv.FormatFloat(x.Tip, 'f', -1, 64))
+	}
+	f.fields[expenseFieldCurrency].input.SetValue(x.Currency)
+	f.fields[expenseFieldCategory].input.SetValue(x.Category)
+	f.fields[expenseFieldDate].dates = newDatePicker(app)
+	f.fields[expenseFieldDate].input.SetValue(app.formatDate(x.Timestamp))
+	f.fields[expenseFieldDescription].input.SetValue(x.Description)
+	f.fields[expenseFieldLocation].input.SetValue(x.Location)
+	f.fields[expenseFieldLocation].places = newPlaceCompleter(false)
+	f.fields[expenseFieldNote].input.SetValue(x.Note)
+	f.fields[expenseFieldPaidBy].input.SetValue(x.PaidBy)
+	f.fields[expenseFieldSplit].input.SetValue(models.FormatSplit(x.Shares))
+	// Work on a copy so cancelling leaves the caller's expense untouched.
+	edited := *x
+	return expenseForm{form: f, app: app, expense: &edited, isNew: isNew, people: people}
+}
+
+func (f expenseForm) Title() string {
+	if f.isNew {
+		return tr("New expense")
+	}
+	return tr("Edit %s", f.expense.Description)
+}
+
+func (f expenseForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f expenseForm) draft() *models.Draft {
+	if !f.dirty {
+		return nil
+	}
+	return newDraft(models.DraftExpense, f.expense.ID, f.Title(), f.value(expenseFieldDescription), f.isNew, f.expense, f.values())
+}
+
+func (f expenseForm) capturesEsc() bool { return f.duplicate != nil }
+
+func (f expenseForm) help() []key.Binding {
+	if f.duplicate != nil {
+		return []key.Binding{fixed("m", "merge into the expense already recorded"), fixed("k", "keep both"), fixed("esc", "back")}
+	}
+	return f.form.help()
+}
+
+func (f expenseForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && f.duplicate != nil {
+		switch key.String() {
+		case "m":
+			dup := f.duplicate
+			models.MergeExpense(dup, f.expense)
+			err := f.app.store.SaveExpense(f.app.ctx, dup)
+			if err == nil {
+				err = f.keepReceipt(dup)
+			}
+			if err != nil {
+				f.err, f.duplicate = err, nil
+				return f, nil
+			}
+			return f, tea.Sequence(pop, func() tea.Msg { return expenseSavedMsg{expense: dup, merged: true} })
+		case "k":
+			f.duplicate, f.keepBoth = nil, true
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			f.duplicate = nil
+			return f, nil
+		default:
+			return f, nil
+		}
+	}
+	// The tip is checked with the tax against the amount entered before
+	// them, as is the split.
+	if key, ok := msg.(tea.KeyMsg); ok && f.step == expenseFieldTip && isAdvance(key) {
+		if err := f.breakdown(); err != nil {
+			f.err = err
+			return f, nil
+		}
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && f.step == expenseFieldSplit && isAdvance(key) {
+		amount, _ := strconv.ParseFloat(f.value(expenseFieldAmount), 64)
+		if _, err := models.ParseSplit(f.value(expenseFieldSplit), amount, f.people); err != nil {
+			f.err = err
+			return f, nil
+		}
+	}
+
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		category := f.expense.Category
+		err := f.apply()
+		if err == nil && f.isNew && !f.keepBoth {
+			if f.duplicate, err = f.app.duplicateOf(f.expense); f.duplicate != nil {
+				return f, nil
+			}
+		}
+		if err == nil {
+			err = f.app.store.SaveExpense(f.app.ctx, f.expense)
+			if err == nil {
+				if err = f.keepReceipt(f.expense); err != nil {
+					// Saving again edits the expense saved.
+					f.isNew = false
+				}
+			}
+		}
+		// A corrected category of a statement charge teaches a rule for
+		// the next import.
+		var learned *models.Rule
+		if err == nil && !f.isNew && f.expense.Merchant != "" && f.expense.Category != category {
+			learned, err = f.app.store.LearnRule(f.app.ctx, f.expense.Merchant, f.expense.Category)
+		}
+		if err != nil {
+			f.err = err
+			return f, nil
+		}
+		x := f.expense
+		return f, tea.Batch(tea.Sequence(pop, func() tea.Msg { return expenseSavedMsg{expense: x, learned: learned} }), f.app.lockRate(x))
+	}
+	return f, cmd
+}
+
+func (f expenseForm) View() string {
+	if d := f.duplicate; d != nil {
+		var b strings.Builder
+		b.WriteString(headerStyle.Render(f.title) + "\n\n")
+		b.WriteString(warningStyle.Render("This looks like an expense already recorded:") + "\n\n")
+		fmt.Fprintf(&b, "  %s  %.2f %s  %s\n", f.app.formatDate(d.Timestamp), d.Amount, d.Currency, d.Description)
+		if d.Note != "" {
+			b.WriteString("  " + hintStyle.Render(truncate(d.Note, 60)) + "\n")
+		}
+		b.WriteString("\n" + hintStyle.Render("m merge into it • k keep both • esc back") + "\n")
+		return b.String()
+	}
+	return f.form.view(f.summary)
+}
+
+// keepReceipt keeps the receipt the expense was read from, if any, as a
+// document of its trip.
+func (f *expenseForm) keepReceipt(x *models.Expense) error {
+	if f.receipt == "" {
+		return nil
+	}
+	if _, err := f.app.store.AttachReceipt(f.app.ctx, x, f.receipt); err != nil {
+		return err
+	}
+	path := f.receipt
+	f.receipt = ""
+	if f.inboxed {
+		_, err := inbox.File(f.app.inboxDir(), path)
+		return err
+	}
+	return nil
+}
+
+// duplicateOf returns an expense already on x's trip that x looks like a
+// duplicate of, or nil.
+func (a *app) duplicateOf(x *models.Expense) (*models.Expense, error) {
+	expenses, err := a.store.ListExpensesByTrip(a.ctx, x.TripID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range expenses {
+		if models.IsLikelyDuplicate(e, x) {
+			return e, nil
+		}
+	}
+	return nil, nil
+}
+
+// breakdown checks the tax and tip entered against the amount.
+func (f expenseForm) breakdown() error {
+	amount, _ := strconv.ParseFloat(f.value(expenseFieldAmount), 64)
+	tax, _ := parsePart(f.value(expenseFieldTax))
+	tip, _ := parsePart(f.value(expenseFieldTip))
+	return models.CheckBreakdown(amount, tax, tip)
+}
+
+// apply copies the validated values into the expense.
+func (f expenseForm) apply() error {
+	amount, err := strconv.ParseFloat(f.value(expenseFieldAmount), 64)
+	if err != nil {
+		return err
+	}
+	category, err := models.ParseCategory(f.value(expenseFieldCategory))
+	if err != nil {
+		return err
+	}
+	date, err := f.app.parseDate(f.value(expenseFieldDate))
+	if err != nil {
+		return err
+	}
+	if err := f.breakdown(); err != nil {
+		return err
+	}
+	ts := f.expense.Timestamp
+	f.expense.Amount = amount
+	f.expense.Tax, _ = parsePart(f.value(expenseFieldTax))
+	f.expense.Tip, _ = parsePart(f.value(expenseFieldTip))
+	currency, err := models.ParseCurrency(f.value(expenseFieldCurrency))
+	if err != nil {
+		return err
+	}
+	// The rate locked in is the old currency's, or the old day's.
+	if currency != f.expense.Currency || !dateOf(date).Equal(dateOf(ts)) {
+		f.expense.Rate, f.expense.RateCurrency = 0, ""
+	}
+	f.expense.Currency = currency
+	f.expense.Category = category
+	f.expense.Location = f.value(expenseFieldLocation)
+	// Expenses from before time zones were recorded stay in the local
+	// zone, like entries, until given a location.
+	if f.isNew || f.expense.TimeZone != "" || f.expense.Location != "" {
+		f.expense.TimeZone = f.app.zoneOn(f.expense.TripID, f.expense.Location, date)
+	}
+	f.expense.Timestamp = time.Date(date.Year(), date.Month(), date.Day(), ts.Hour(), ts.Minute(), ts.Second(), 0, models.Zone(f.expense.TimeZone))
+	// An expense moved to another day belongs to the leg of that day.
+	if f.isNew || !dateOf(date).Equal(dateOf(ts)) {
+		if f.expense.LegID, err = f.app.legOn(f.expense.TripID, date); err != nil {
+			return err
+		}
+	}
+	f.expense.Description = f.value(expenseFieldDescription)
+	f.expense.Note = f.value(expenseFieldNote)
+	f.expense.PaidBy = ""
+	if v := f.value(expenseFieldPaidBy); v != "" {
+		payer, err := models.ResolvePerson(v, f.people)
+		if err != nil {
+			return err
+		}
+		if payer != models.Me {
+			f.expense.PaidBy = payer
+		}
+	}
+	if f.expense.Shares, err = models.ParseSplit(f.value(expenseFieldSplit), amount, f.people); err != nil {
+		return err
+	}
+	f.expense.Tags = splitList(f.value(expenseFieldTags))
+	return nil
+}
+
+func (f expenseForm) summary() string {
+	var b strings.Builder
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-12s", label+":")), value)
+	}
+	category, _ := models.ParseCategory(f.value(expenseFieldCategory))
+	row("Amount", f.value(expenseFieldAmount)+" "+strings.ToUpper(f.value(expenseFieldCurrency)))
+	tax, _ := parsePart(f.value(expenseFieldTax))
+	tip, _ := parsePart(f.value(expenseFieldTip))
+	if tax+tip > 0 {
+		amount, _ := strconv.ParseFloat(f.value(expenseFieldAmount), 64)
+		row("Breakdown", fmt.Sprintf("%.2f + %.2f tax + %.2f tip", amount-tax-tip, tax, tip))
+	}
+	row("Category", category)
+	row("Date", f.value(expenseFieldDate))
+	row("Description", f.value(expenseFieldDescription))
+	row("Location", f.value(expenseFieldLocation))
+	row("Note", f.value(expenseFieldNote))
+	if split := f.value(expenseFieldSplit); split != "" {
+		payer := f.value(expenseFieldPaidBy)
+		if payer == "" {
+			payer = models.Me
+		}
+		row("Paid by", payer)
+		row("Split", split)
+	}
+	row("Tags", formatTags(models.NormalizeTags(splitList(f.value(expenseFieldTags)))))
+	return b.String()
+}
+
+func validatePositiveAmount(v string) error {
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n <= 0 {
+		return errors.New("enter an amount greater than zero, e.g. 12.50")
+	}
+	return nil
+}
+
+// validatePart checks a tax or tip, which may be left blank for none.
+func validatePart(v string) error {
+	if _, err := parsePart(v); err != nil {
+		return errors.New("enter an amount of zero or more, e.g. 2.40, or leave it blank")
+	}
+	return nil
+}
+
+// parsePart reads a tax or tip, blank for none.
+func parsePart(v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("not an amount of zero or more")
+	}
+	return n, nil
+}
+
+func validateCurrency(v string) error {
+	_, err := models.ParseCurrency(v)
+	return err
+}
+
+func validateCategory(v string) error {
+	_, err := models.ParseCategory(v)
+	return err
+}
+
+func validatePerson(people []string) func(string) error {
+	return func(v string) error {
+		if v == "" {
+			return nil
+		}
+		_, err := models.ResolvePerson(v, people)
+		return err
+	}
+}
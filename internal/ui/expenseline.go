@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/quick"
+)
+
+// expenseLine records an expense typed in one line, such as "14.50 eur
+// lunch ramen yesterday", showing what it reads as it is typed. Enter opens
+// the expense form on its confirmation page to check and save it.
+type expenseLine struct {
+	app      *app
+	trip     *models.Trip
+	currency string // when the line names none
+	rules    []*models.Rule
+	input    textinput.Model
+	parsed   quick.Expense
+	err      error
+}
+
+func newExpenseLine(app *app, trip *models.Trip, currency string) expenseLine {
+	in := textinput.New()
+	in.Placeholder = "14.50 eur lunch ramen yesterday"
+	in.CharLimit = 256
+	in.Width = 50
+	in.Focus()
+	// Without rules the common words still guess a category.
+	rules, _ := app.store.ListRules(app.ctx)
+	return expenseLine{app: app, trip: trip, currency: currency, rules: rules, input: in}
+}
+
+func (l expenseLine) Title() string { return tr("Quick expense") }
+
+func (l expenseLine) currentTrip() *models.Trip { return l.trip }
+
+func (l expenseLine) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (l expenseLine) typing() bool { return true }
+
+func (l expenseLine) help() []key.Binding {
+	return []key.Binding{
+		fixed("enter", "check the expense read and save it"),
+		fixed("esc", "cancel"),
+	}
+}
+
+func (l *expenseLine) parse() {
+	l.parsed, l.err = quick.ParseExpense(l.input.Value(), l.app.tripNow(l.trip), l.app.cfg.Layout(), l.rules)
+	if l.parsed.Currency == "" {
+		l.parsed.Currency = l.currency
+	}
+}
+
+// expense is the expense read, stamped with the time of day now on the
+// day named.
+func (l expenseLine) expense() *models.Expense {
+	p, ts := l.parsed, l.app.tripNow(l.trip)
+	if !p.Day.IsZero() {
+		ts = time.Date(p.Day.Year(), p.Day.Month(), p.Day.Day(), ts.Hour(), ts.Minute(), ts.Second(), 0, ts.Location())
+	}
+	return models.NewExpense(l.trip.ID, p.Amount, p.Currency, p.Category, p.Description, ts)
+}
+
+func (l expenseLine) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && key.Type == tea.KeyEnter {
+		if strings.TrimSpace(l.input.Value()) == "" {
+			return l, nil
+		}
+		if l.err != nil {
+			return l, nil
+		}
+		f := newExpenseForm(l.app, l.expense(), true)
+		f.review()
+		return l, tea.Sequence(pop, push(f))
+	}
+	before := l.input.Value()
+	var cmd tea.Cmd
+	l.input, cmd = l.input.Update(msg)
+	if l.input.Value() != before {
+		l.parse()
+	}
+	return l, cmd
+}
+
+func (l expenseLine) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("💸 Quick expense on "+l.trip.Title) + "\n\n")
+	b.WriteString(l.input.View() + "\n\n")
+	switch {
+	case strings.TrimSpace(l.input.Value()) == "":
+		b.WriteString(hintStyle.Render("Type the amount, what it was for and, unless it was today, the day.") + "\n")
+	case l.err != nil:
+		b.WriteString(hintStyle.Render("Add the amount, e.g. 12.50 or €12,50.") + "\n")
+	default:
+		p := l.parsed
+		day := l.app.tripNow(l.trip)
+		if !p.Day.IsZero() {
+			day = p.Day
+		}
+		description := p.Description
+		if description == "" {
+			description = hintStyle.Render("—")
+		}
+		for _, r := range [][2]string{
+			{"Amount", formatAmount(p.Amount, p.Currency)},
+			{"Category", p.Category},
+			{"Date", l.app.formatDate(day)},
+			{"Description", description},
+		} {
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-12s", r[0]+":")), r[1])
+		}
+	}
+	b.WriteString("\n" + hintStyle.Render("enter check and save • esc cancel") + "\n")
+	return b.String()
+}
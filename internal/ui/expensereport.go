@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/chart"
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/report"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+// expenseReport totals a trip's expenses by category, day or leg in the
+// home currency, with a bar for each, and exports the report as CSV or
+// Markdown.
+type expenseReport struct {
+	app      *app
+	trip     *models.Trip
+	tags     []string
+	expenses []*models.Expense
+	legs     []*models.Leg
+	by       report.Grouping
+
+	rates    *currency.Rates
+	ratesErr error
+
+	// exporting is set while the file to export to is typed into path.
+	exporting bool
+	path      textinput.Model
+
+	status string
+	err    error
+}
+
+// newExpenseReport reports on the trip's expenses carrying every one of
+// tags, the ones its expense list is filtered to.
+func newExpenseReport(app *app, trip *models.Trip, tags []string, rates *currency.Rates) expenseReport {
+	r := expenseReport{app: app, trip: trip, tags: tags, rates: rates}
+	r.reload()
+	return r
+}
+
+func (r expenseReport) Title() string { return tr("Report") }
+
+func (r expenseReport) currentTrip() *models.Trip { return r.trip }
+
+func (r expenseReport) Init() tea.Cmd {
+	if r.rates != nil {
+		return nil
+	}
+	return r.app.fetchRates()
+}
+
+func (r expenseReport) typing() bool { return r.exporting }
+
+func (r expenseReport) capturesEsc() bool { return r.exporting }
+
+func (r expenseReport) help() []key.Binding {
+	if r.exporting {
+		return []key.Binding{
+			fixed("enter", "write the report, as Markdown to a .md file or else as CSV"),
+			fixed("esc", "cancel"),
+		}
+	}
+	return []key.Binding{
+		r.app.bind("left", "previous grouping"),
+		r.app.bind("right", "next grouping"),
+		r.app.bind("export", "export the report as CSV or Markdown"),
+		r.app.bind("quit", "close"),
+	}
+}
+
+func (r *expenseReport) reload() {
+	expenses, err := r.app.store.ListExpensesByTrip(r.app.ctx, r.trip.ID)
+	if err != nil {
+		r.err = err
+		return
+	}
+	r.expenses = expenses[:0]
+	for _, x := range expenses {
+		if models.HasTags(x.Tags, r.tags) {
+			r.expenses = append(r.expenses, x)
+		}
+	}
+	r.legs, r.err = r.app.store.ListLegsByTrip(r.app.ctx, r.trip.ID)
+}
+
+// report computes the report in the home currency, converting with the
+// loaded exchange rates when there are any.
+func (r expenseReport) report() report.Report {
+	var convert report.Converter
+	if r.rates != nil {
+		convert = r.rates.Convert
+	}
+	return report.Compute(r.expenses, r.legs, r.by, r.app.cfg.HomeCurrency, convert)
+}
+
+func (r expenseReport) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ratesMsg:
+		r.rates, r.ratesErr = msg.rates, msg.err
+		return r, nil
+	case expenseSavedMsg, expensesImportedMsg, lodgingsChangedMsg, historyMsg:
+		r.reload()
+		return r, nil
+	case tea.KeyMsg:
+		if r.exporting {
+			switch msg.String() {
+			case "esc":
+				r.exporting = false
+				return r, nil
+			case "enter":
+				path := expandHome(strings.TrimSpace(r.path.Value()))
+				if err := r.write(path); err != nil {
+					r.err = err
+					return r, nil
+				}
+				r.exporting, r.err, r.status = false, nil, "Wrote "+path
+				return r, nil
+			}
+			var cmd tea.Cmd
+			r.path, cmd = r.path.Update(msg)
+			return r, cmd
+		}
+		n := len(report.Groupings)
+		switch {
+		case r.app.is(msg, "quit"):
+			return r, pop
+		case r.app.is(msg, "left"):
+			r.by = report.Groupings[(int(r.by)+n-1)%n]
+		case r.app.is(msg, "right"):
+			r.by = report.Groupings[(int(r.by)+1)%n]
+		case r.app.is(msg, "export"):
+			name := export.Slug(r.trip.Title) + "-expenses-by-" + r.by.String() + ".csv"
+			if wd, err := os.Getwd(); err == nil {
+				name = filepath.Join(wd, name)
+			}
+			r.exporting, r.status, r.path = true, "", newPathInput(name)
+			return r, textinput.Blink
+		}
+	}
+	return r, nil
+}
+
+// write exports the report to path: as Markdown when it ends in .md or
+// .markdown, or else as CSV.
+func (r expenseReport) write(path string) error {
+	if path == "" {
+		return errors.New("enter a file to export to")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	rep := r.report()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		err = rep.WriteMarkdown(f, r.trip.Title+" expenses")
+	default:
+		err = rep.WriteCSV(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (r expenseReport) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("📊 "+r.trip.Title+" expenses") + "\n\n")
+	if r.err != nil {
+		b.WriteString(errorStyle.Render(r.err.Error()) + "\n\n")
+	}
+	if len(r.tags) > 0 {
+		b.WriteString(hintStyle.Render("Filtered by ") + highlightStyle.Render(formatTags(r.tags)) + "\n")
+	}
+	tabs := make([]string, len(report.Groupings))
+	for i, g := range report.Groupings {
+		tabs[i] = hintStyle.Render(g.String())
+		if g == r.by {
+			tabs[i] = cursorStyle.Render("[" + g.String() + "]")
+		}
+	}
+	b.WriteString(labelStyle.Render("By") + " " + strings.Join(tabs, "  ") + "\n\n")
+
+	rep := r.report()
+	if len(rep.Rows) == 0 {
+		b.WriteString("No expenses to report on.\n")
+	}
+	if first, days := rep.Daily(); len(days) > 1 {
+		h := chart.Histogram{
+			Values: days,
+			Format: func(v float64) string { return formatAmount(v, rep.Currency) },
+			First:  r.app.formatDate(first),
+			Last:   r.app.formatDate(first.AddDate(0, 0, len(days)-1)),
+			Paint:  func(s string) string { return cursorStyle.Render(s) },
+		}
+		b.WriteString(labelStyle.Render(tr("Daily spend")) + "\n")
+		for _, line := range h.Lines() {
+			b.WriteString("  " + line + "\n")
+		}
+		b.WriteString("\n")
+	}
+	labels := make([]string, len(rep.Rows))
+	width := 0
+	var peak float64
+	for i, row := range rep.Rows {
+		labels[i] = row.Label
+		if r.by == report.ByDay {
+			labels[i] = r.app.formatDate(row.Day)
+		}
+		labels[i] = truncate(labels[i], maxChartLabel)
+		width = max(width, lipgloss.Width(labels[i]))
+		peak = max(peak, row.Total)
+	}
+	for i, row := range rep.Rows {
+		bar := chart.Bar(row.Total, peak, chartWidth)
+		fmt.Fprintf(&b, "  %s%s %s%s %12s %5.1f%%  %s\n", labels[i], strings.Repeat(" ", width-lipgloss.Width(labels[i])),
+			cursorStyle.Render(bar), strings.Repeat(" ", chartWidth-lipgloss.Width(bar)), formatAmount(row.Total, rep.Currency),
+			row.Share(rep.Total)*100, hintStyle.Render(plural(row.Count, "expense", "expenses")))
+	}
+	if len(rep.Rows) > 0 {
+		b.WriteString("\n" + labelStyle.Render("Total") + "  " + formatAmount(rep.Total, rep.Currency) + "  " +
+			hintStyle.Render(plural(rep.Count, "expense", "expenses")) + "\n")
+	}
+	if rep.Unconverted > 0 {
+		b.WriteString(hintStyle.Render(plural(rep.Unconverted, "expense", "expenses")+" in other currencies not counted"+r.ratesNote()) + "\n")
+	}
+	if r.exporting {
+		b.WriteString("\n" + labelStyle.Render("File") + " " + hintStyle.Render("(.md for Markdown, else CSV)") + "\n")
+		b.WriteString(r.path.View() + "\n")
+		b.WriteString("\n" + hintStyle.Render("enter export • esc cancel") + "\n")
+		return b.String()
+	}
+	if r.status != "" {
+		b.WriteString("\n" + r.status + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("←/→ group by • "+r.app.keyHint("export")+" export CSV or Markdown • esc back") + "\n")
+	return b.String()
+}
+
+// ratesNote explains why expenses could not be converted.
+func (r expenseReport) ratesNote() string {
+	switch {
+	case r.app.rates == nil:
+		return ""
+	case r.ratesErr != nil:
+		return " (exchange rates unavailable)"
+	case r.rates == nil:
+		return " (loading exchange rates)"
+	}
+	return ""
+}
@@ -0,0 +1,557 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/budget"
+	"github.com/girdharshubham/nomadic/internal/inbox"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+// expenseList shows a trip's expenses with per-currency totals.
+type expenseList struct {
+	app      *app
+	trip     *models.Trip
+	expenses []*models.Expense
+	cursor   int
+	filter   tagFilter
+	// marked are the expenses marked for a bulk action.
+	marked marks
+
+	// rates converts the totals into the home currency once loaded.
+	rates    *currency.Rates
+	ratesErr error
+
+	// convert is the currency converter, while it is open.
+	convert *converter
+
+	// inbox are the receipts waiting in the receipts inbox.
+	inbox []inbox.Receipt
+
+	confirmDelete bool
+	status        string
+	err           error
+
+	width, height int
+}
+
+func newExpenseList(app *app, trip *models.Trip) expenseList {
+	l := expenseList{app: app, trip: trip, filter: newTagFilter()}
+	l.reload()
+	return l
+}
+
+func (l expenseList) Title() string { return l.trip.Title }
+
+func (l expenseList) currentTrip() *models.Trip { return l.trip }
+
+func (l expenseList) resume() (models.SessionScreen, bool) {
+	return models.SessionScreen{Kind: models.SessionExpenses, Title: l.Title(), ID: l.trip.ID, Cursor: l.cursor}, true
+}
+
+func (l expenseList) Init() tea.Cmd {
+	return tea.Batch(l.app.fetchRates(), l.app.scanInbox())
+}
+
+func (l expenseList) capturesEsc() bool {
+	return l.confirmDelete || l.filter.editing || l.convert != nil || len(l.marked) > 0
+}
+
+func (l expenseList) typing() bool { return l.filter.editing || l.convert != nil }
+
+func (l expenseList) help() []key.Binding {
+	if l.filter.editing {
+		return l.filter.help()
+	}
+	if l.convert != nil {
+		return []key.Binding{fixed("esc", "close the converter")}
+	}
+	if len(l.marked) > 0 {
+		return []key.Binding{
+			l.app.bind("up", "previous expense"),
+			l.app.bind("down", "next expense"),
+			l.app.bind("mark", "mark or unmark the expense"),
+			l.app.bind("mark_all", "mark every expense, or none"),
+			l.app.bind("select", "delete, retag, move, recategorize or export the expenses marked"),
+			l.app.bind("delete", "delete the expenses marked"),
+			fixed("esc", "unmark them all"),
+		}
+	}
+	return append([]key.Binding{
+		l.app.bind("up", "previous expense"),
+		l.app.bind("down", "next expense"),
+		l.app.bind("mark", "mark the expense for a bulk action"),
+		l.app.bind("select", "show the expense"),
+		l.app.bind("new", "record an expense"),
+		l.app.bind("add", "type an expense in one line, e.g. 14.50 eur lunch ramen yesterday"),
+		l.app.bind("receipt", "record an expense from a photo of its receipt"),
+		l.app.bind("inbox", "turn the receipts in the receipts inbox into expenses"),
+		l.app.bind("edit", "edit the expense"),
+		l.app.bind("delete", "delete the expense"),
+		l.app.bind("filter", "filter by tag"),
+		l.app.bind("budget", "edit the budget"),
+		l.app.bind("settle", "settle up with companions"),
+		l.app.bind("report", "report the expenses by category, day or leg"),
+		l.app.bind("import", "import expenses from CSV"),
+		l.app.bind("export", "export expenses to CSV"),
+		l.app.bind("reconcile", "compare the expenses with a bank or card statement"),
+		l.app.bind("recurring", "manage recurring expenses"),
+		l.app.bind("convert", "convert an amount between currencies"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *expenseList) reload() {
+	expenses, err := l.app.store.ListExpensesByTrip(l.app.ctx, l.trip.ID)
+	l.expenses, l.err = expenses[:0], err
+	for _, x := range expenses {
+		if l.filter.match(x.Tags) {
+			l.expenses = append(l.expenses, x)
+		}
+	}
+	l.cursor = clamp(l.cursor, 0, len(l.expenses)-1)
+	// Only the expenses shown stay marked.
+	l.marked = l.marked.keep(expenseIDs(l.expenses))
+}
+
+// markedExpenses are the expenses marked, in the order listed.
+func (l expenseList) markedExpenses() []*models.Expense {
+	var marked []*models.Expense
+	for _, x := range l.expenses {
+		if l.marked[x.ID] {
+			marked = append(marked, x)
+		}
+	}
+	return marked
+}
+
+func expenseIDs(expenses []*models.Expense) []string {
+	ids := make([]string, len(expenses))
+	for i, x := range expenses {
+		ids[i] = x.ID
+	}
+	return ids
+}
+
+func (l expenseList) selected() *models.Expense {
+	if len(l.expenses) == 0 {
+		return nil
+	}
+	return l.expenses[l.cursor]
+}
+
+func (l expenseList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		l.width, l.height = msg.Width, msg.Height
+		return l, nil
+	case ratesMsg:
+		l.rates, l.ratesErr = msg.rates, msg.err
+		return l, nil
+	case inboxMsg:
+		// A folder that cannot be read is reported on the inbox's screen.
+		l.inbox = msg.receipts
+		return l, nil
+	case tripSavedMsg:
+		var cmd tea.Cmd
+		if msg.trip.ID == l.trip.ID {
+			// The settle screen saves the trip's companions.
+			if slices.Equal(msg.trip.Companions, l.trip.Companions) {
+				cmd = toast(toastSuccess, "Saved budget")
+			}
+			l.trip = msg.trip
+		}
+		return l, cmd
+	case expenseSavedMsg:
+		text := fmt.Sprintf("Saved %q", msg.expense.Description)
+		if msg.merged {
+			text = fmt.Sprintf("Merged into %q", msg.expense.Description)
+		}
+		if r := msg.learned; r != nil {
+			text += fmt.Sprintf(" • charges from %q will be filed under %s", r.Match, r.Category)
+		}
+		l.reload()
+		return l, toast(toastSuccess, "%s", text)
+	case recurrenceSavedMsg, categorySavedMsg, lodgingsChangedMsg:
+		l.reload()
+		return l, nil
+	case expensesImportedMsg:
+		l.reload()
+		return l, toast(toastSuccess, "Imported %s", plural(msg.count, "expense", "expenses"))
+	case bulkAppliedMsg:
+		l.status, l.marked = msg.status, nil
+		l.reload()
+		return l, nil
+	case historyMsg:
+		l.status = ""
+		l.reload()
+		return l, nil
+	case tea.KeyMsg:
+		if l.filter.editing {
+			cmd, changed := l.filter.update(msg)
+			if changed {
+				l.reload()
+			}
+			return l, cmd
+		}
+		if l.convert != nil {
+			cmd, closed := l.convert.update(msg)
+			if closed {
+				l.convert = nil
+			}
+			return l, cmd
+		}
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				x := l.selected()
+				if err := l.app.run(&deleteExpense{expense: x}); err != nil {
+					l.err = err
+				} else {
+					l.status = l.app.trashedHint(x.Description)
+				}
+				l.reload()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.expenses)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "mark"):
+			if x := l.selected(); x != nil {
+				l.marked.toggle(x.ID)
+				l.cursor = min(l.cursor+1, len(l.expenses)-1)
+			}
+		case len(l.marked) > 0 && l.app.is(msg, "mark_all"):
+			l.marked.all(expenseIDs(l.expenses))
+		case len(l.marked) > 0 && l.app.is(msg, "back"):
+			l.marked = nil
+		case len(l.marked) > 0 && l.app.is(msg, "select"):
+			return l, push(newExpenseBulkEdit(l.app, l.markedExpenses()))
+		case len(l.marked) > 0 && l.app.is(msg, "delete"):
+			b, _ := newExpenseBulkEdit(l.app, l.markedExpenses()).choose(bulkDelete)
+			return l, push(b)
+		case l.app.is(msg, "new"):
+			x := models.NewExpense(l.trip.ID, 0, l.lastCurrency(), "", "", l.app.tripNow(l.trip))
+			return l, push(newExpenseForm(l.app, x, true))
+		case l.app.is(msg, "add"):
+			return l, push(newExpenseLine(l.app, l.trip, l.lastCurrency()))
+		case l.app.is(msg, "receipt"):
+			return l, push(newReceiptScan(l.app, l.trip, l.lastCurrency()))
+		case l.app.is(msg, "inbox"):
+			if l.app.inboxDir() == "" {
+				l.status = "No receipts inbox — set receipt_inbox to a folder receipts are saved into."
+				return l, nil
+			}
+			return l, push(newReceiptInbox(l.app, l.trip, l.lastCurrency(), l.inbox))
+		case l.app.is(msg, "select"):
+			if x := l.selected(); x != nil {
+				return l, push(newExpenseDetail(l.app, l.trip, x))
+			}
+		case l.app.is(msg, "edit"):
+			if x := l.selected(); x != nil {
+				return l, push(newExpenseForm(l.app, x, false))
+			}
+		case l.app.is(msg, "delete"):
+			if l.selected() != nil {
+				l.confirmDelete = true
+			}
+		case l.app.is(msg, "filter"):
+			return l, l.filter.edit(l.app)
+		case l.app.is(msg, "budget"):
+			return l, push(newBudgetForm(l.app, l.trip))
+		case l.app.is(msg, "settle"):
+			return l, push(newSettleView(l.app, l.trip, l.rates))
+		case l.app.is(msg, "report"):
+			return l, push(newExpenseReport(l.app, l.trip, l.filter.tags, l.rates))
+		case l.app.is(msg, "import"):
+			return l, push(newCSVImport(l.app, l.trip))
+		case l.app.is(msg, "export"):
+			return l, push(newCSVExport(l.app, l.trip))
+		case l.app.is(msg, "reconcile"):
+			return l, push(newReconcileView(l.app, l.trip, l.rates))
+		case l.app.is(msg, "recurring"):
+			return l, push(newRecurrenceList(l.app, l.trip))
+		case l.app.is(msg, "convert"):
+			l.convert, l.status = newConverter(l.lastCurrency(), l.app.cfg.HomeCurrency), ""
+			return l, textinput.Blink
+		}
+	}
+	return l, nil
+}
+
+// lastCurrency pre-fills new expenses with the currency used most recently,
+// or the configured default for a trip's first expense.
+func (l expenseList) lastCurrency() string {
+	if len(l.expenses) == 0 {
+		return l.app.cfg.DefaultCurrency
+	}
+	return l.expenses[len(l.expenses)-1].Currency
+}
+
+// convertedTotal renders the trip total in the home currency, or why it
+// is unavailable. It is empty when conversion is disabled.
+func (l expenseList) convertedTotal() string {
+	home := l.app.cfg.HomeCurrency
+	switch {
+	case l.app.rates == nil:
+		return ""
+	case l.ratesErr != nil:
+		return "≈ ? " + home + " (exchange rates unavailable)"
+	case l.rates == nil:
+		return "≈ … " + home + " (loading exchange rates)"
+	}
+	total, err := convertTotal(l.expenses, l.rates, home)
+	if err != nil {
+		return "≈ ? " + home + " (" + err.Error() + ")"
+	}
+	line := "≈ " + formatAmount(total, home)
+	if l.rates.Stale {
+		line += " (offline, rates from " + l.rates.Date + ")"
+	}
+	return line
+}
+
+// ratesNote explains why expenses could not be converted.
+func (l expenseList) ratesNote() string {
+	switch {
+	case l.app.rates == nil:
+		return ""
+	case l.ratesErr != nil:
+		return " (exchange rates unavailable)"
+	case l.rates == nil:
+		return " (loading exchange rates)"
+	}
+	return ""
+}
+
+func (l expenseList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("💰 "+l.trip.Title) + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n\n")
+	}
+	b.WriteString(l.filter.view())
+	switch {
+	case len(l.expenses) == 0 && l.filter.active():
+		b.WriteString("No expenses carry these tags.\n")
+	case len(l.expenses) == 0:
+		b.WriteString("No expenses yet — press " + l.app.keyHint("new") + " to record one.\n")
+	}
+	var foot strings.Builder
+	if len(l.expenses) > 0 {
+		foot.WriteString("\n" + labelStyle.Render("Total") + "  " + strings.Join(totalsByCurrency(l.expenses), " + ") + "\n")
+		if line := l.convertedTotal(); line != "" {
+			foot.WriteString(hintStyle.Render(line) + "\n")
+		}
+	}
+	// The budget covers the whole trip, not the expenses filtered in.
+	var days map[string]budget.Day
+	if v := budgetView(l.budgetReport(), l.ratesNote()); v != "" && !l.filter.active() {
+		foot.WriteString("\n" + v)
+	}
+	if l.trip.PerDiem > 0 && !l.filter.active() {
+		p := l.perDiemReport()
+		foot.WriteString(perDiemView(p, l.ratesNote()))
+		days = make(map[string]budget.Day, len(p.Days))
+		for _, d := range p.Days {
+			days[d.Date.Format(models.DateLayout)] = d
+		}
+	}
+	if n := len(l.inbox); n > 0 {
+		foot.WriteString("\n" + fmt.Sprintf("🧾 %s in the inbox — press %s to turn them into expenses", plural(n, "receipt", "receipts"), l.app.keyHint("inbox")) + "\n")
+	}
+	if l.status != "" {
+		foot.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		foot.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Move %q to the trash? y/n", l.selected().Description)) + "\n")
+	}
+	if l.convert != nil {
+		foot.WriteString("\n" + l.convert.view(l.rates, l.ratesErr, l.width) + "\n")
+	}
+	a := l.app
+	if len(l.marked) > 0 {
+		foot.WriteString("\n" + highlightStyle.Render(plural(len(l.marked), "expense", "expenses")+" marked") + hintStyle.Render(" • "+a.keyHint("mark")+" mark • "+
+			a.keyHint("mark_all")+" all • enter act on them • "+a.keyHint("delete")+" delete them • esc unmark") + "\n")
+	}
+	foot.WriteString("\n" + hintStyle.Render(a.keyHint("new")+" new • "+a.keyHint("add")+" quick • enter details • "+a.keyHint("edit")+" edit • "+
+		a.keyHint("delete")+" delete • "+a.keyHint("undo")+" undo • "+a.keyHint("filter")+" filter by tag • "+
+		a.keyHint("budget")+" budget • "+a.keyHint("settle")+" settle up • "+a.keyHint("report")+" report • "+a.keyHint("import")+" import CSV • "+a.keyHint("export")+" export CSV • "+a.keyHint("reconcile")+" reconcile • "+a.keyHint("recurring")+" recurring • "+a.keyHint("convert")+" convert • esc back") + "\n")
+
+	// The expenses take the lines left, less two telling of those
+	// scrolled out of view above and below.
+	size := 0
+	if l.height > 0 {
+		size = max(l.height-lipgloss.Height(b.String())-lipgloss.Height(foot.String())-2, 3)
+	}
+	from, to := window(len(l.expenses), l.cursor, size)
+	if from > 0 {
+		b.WriteString(hintStyle.Render(fmt.Sprintf("   ↑ %d more", from)) + "\n")
+	}
+	for i, x := range l.expenses[from:to] {
+		cursor := "  "
+		if from+i == l.cursor {
+			cursor = "👉"
+		}
+		marks := ""
+		if x.Shared() {
+			marks = " 👥"
+		}
+		if x.RecurrenceID != "" {
+			marks += " 🔁"
+		}
+		// The last expense of a day shows how the day and the trip so
+		// far stand against the per diem.
+		standing := ""
+		day := x.Timestamp.Format(models.DateLayout)
+		if d, ok := days[day]; ok && (from+i+1 == len(l.expenses) || l.expenses[from+i+1].Timestamp.Format(models.DateLayout) != day) {
+			cur := l.app.budgetCurrency(l.trip)
+			standing = "  " + perDiemStanding("day", d.Balance(), cur) + hintStyle.Render(" · ") +
+				perDiemStanding("so far", d.Cumulative, cur)
+		}
+		fmt.Fprintf(&b, "%s %s%s  %s %12s  %s%s %s%s\n", cursor, l.marked.box(x.ID), l.app.formatDate(x.Timestamp),
+			categoryLabel(x.Category, 10), formatAmount(x.Amount, x.Currency), x.Description, marks, hintStyle.Render(formatTags(x.Tags)), standing)
+	}
+	if to < len(l.expenses) {
+		b.WriteString(hintStyle.Render(fmt.Sprintf("   ↓ %d more", len(l.expenses)-to)) + "\n")
+	}
+	return b.String() + foot.String()
+}
+
+// expenseDetail shows every field of a single expense.
+type expenseDetail struct {
+	app     *app
+	trip    *models.Trip
+	expense *models.Expense
+}
+
+func newExpenseDetail(app *app, trip *models.Trip, x *models.Expense) expenseDetail {
+	return expenseDetail{app: app, trip: trip, expense: x}
+}
+
+func (d expenseDetail) Title() string { return d.expense.Description }
+
+func (d expenseDetail) Init() tea.Cmd {
+	return nil
+}
+
+func (d expenseDetail) help() []key.Binding {
+	return []key.Binding{d.app.bind("edit", "edit the expense"), d.app.bind("quit", "close")}
+}
+
+func (d expenseDetail) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case expenseSavedMsg:
+		d.expense = msg.expense
+	case tea.KeyMsg:
+		switch {
+		case d.app.is(msg, "quit"):
+			return d, pop
+		case d.app.is(msg, "edit"):
+			return d, push(newExpenseForm(d.app, d.expense, false))
+		}
+	}
+	return d, nil
+}
+
+func (d expenseDetail) View() string {
+	x := d.expense
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("💰 "+x.Description) + "\n\n")
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-10s", label+":")), value)
+	}
+	row("Amount", formatAmount(x.Amount, x.Currency))
+	if x.Tax > 0 || x.Tip > 0 {
+		row("Base", formatAmount(x.Base(), x.Currency))
+		row("Tax", formatAmount(x.Tax, x.Currency))
+		row("Tip", formatAmount(x.Tip, x.Currency))
+	}
+	if x.Rate > 0 {
+		row("Worth", formatAmount(x.Amount*x.Rate, x.RateCurrency)+hintStyle.Render(" at the day's rate"))
+	}
+	row("Category", categoryLabel(x.Category, 0))
+	row("Date", d.app.formatDate(x.Timestamp))
+	row("Trip", d.trip.Title)
+	if x.Location != "" {
+		row("Location", x.Location)
+	}
+	if leg := legName(d.app, x.LegID); leg != "" {
+		row("Leg", leg)
+	}
+	if x.Shared() || x.PaidBy != "" {
+		row("Paid by", x.Payer())
+	}
+	if x.Shared() {
+		shares := make([]string, len(x.Shares))
+		for i, sh := range x.Shares {
+			shares[i] = sh.Name + " " + formatAmount(sh.Amount, x.Currency)
+		}
+		row("Split", strings.Join(shares, ", "))
+	}
+	if x.Merchant != "" && x.Merchant != x.Description {
+		row("Merchant", x.Merchant)
+	}
+	row("Note", x.Note)
+	row("Tags", formatTags(x.Tags))
+	b.WriteString("\n" + hintStyle.Render(d.app.keyHint("edit")+" edit • esc back") + "\n")
+	return b.String()
+}
+
+func formatAmount(amount float64, currency string) string {
+	return loc.Amount(amount, currency)
+}
+
+// convertTotal sums expenses in the home currency, at their locked rates
+// or converted with rates.
+func convertTotal(expenses []*models.Expense, rates *currency.Rates, home string) (float64, error) {
+	var total float64
+	for _, x := range expenses {
+		v, err := x.In(home, rates.Convert)
+		if err != nil {
+			return 0, err
+		}
+		total += v
+	}
+	return total, nil
+}
+
+// totalsByCurrency sums expenses per currency, sorted by currency code.
+func totalsByCurrency(expenses []*models.Expense) []string {
+	sums := map[string]float64{}
+	for _, x := range expenses {
+		sums[x.Currency] += x.Amount
+	}
+	currencies := make([]string, 0, len(sums))
+	for c := range sums {
+		currencies = append(currencies, c)
+	}
+	sort.Strings(currencies)
+	out := make([]string, len(currencies))
+	for i, c := range currencies {
+		out[i] = formatAmount(sums[c], c)
+	}
+	return out
+}
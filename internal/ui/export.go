@@ -0,0 +1,198 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// exportFormat is a kind of file a trip can be exported as.
+type exportFormat struct {
+	name  string
+	ext   string
+	about string
+	write func(dir string, trips []*export.Trip, dateLayout string) ([]string, error)
+}
+
+var exportFormats = []exportFormat{
+	{"Markdown", ".md", "with its itinerary, journal and expenses", export.WriteMarkdownFiles},
+	{"PDF report", ".pdf", "with its itinerary, journal and expenses", export.WritePDFFiles},
+	{"iCal", ".ics", "with an event for each itinerary item", func(dir string, trips []*export.Trip, _ string) ([]string, error) {
+		return export.WriteICalFiles(dir, trips)
+	}},
+	{"GeoJSON", ".geojson", "mapping its route, check-ins and GPS tracks", func(dir string, trips []*export.Trip, _ string) ([]string, error) {
+		return export.WriteGeoJSONFiles(dir, trips)
+	}},
+	{"KML", ".kml", "mapping its route, check-ins and GPS tracks", func(dir string, trips []*export.Trip, _ string) ([]string, error) {
+		return export.WriteKMLFiles(dir, trips)
+	}},
+}
+
+// redactions are what the export screen can do with private entries and
+// passages.
+var redactions = []struct {
+	name   string
+	redact models.Redaction
+}{
+	{"Keep", models.RedactNone},
+	{"Strip", models.RedactStrip},
+	{"Mask", models.RedactMask},
+}
+
+// redaction is what exports from the TUI do with what is private, by the
+// redact setting.
+func (a *app) redaction() models.Redaction {
+	r, _ := models.ParseRedaction(a.cfg.Redact)
+	return r
+}
+
+// exportScreen writes a trip as a Markdown document, a PDF report, an
+// iCal calendar or a GeoJSON or KML map into a chosen directory.
+type exportScreen struct {
+	app    *app
+	trip   *models.Trip
+	dir    textinput.Model
+	format int // index into exportFormats
+	redact int // index into redactions
+
+	status string
+	err    error
+}
+
+func newExportScreen(app *app, trip *models.Trip) exportScreen {
+	in := textinput.New()
+	in.Placeholder = "~/Documents/trips"
+	in.CharLimit = 512
+	in.Width = 60
+	if wd, err := os.Getwd(); err == nil {
+		in.SetValue(wd)
+	}
+	in.Focus()
+	s := exportScreen{app: app, trip: trip, dir: in}
+	for i, r := range redactions {
+		if r.redact == app.redaction() {
+			s.redact = i
+		}
+	}
+	return s
+}
+
+func (s exportScreen) Title() string { return s.trip.Title }
+
+func (s exportScreen) currentTrip() *models.Trip { return s.trip }
+
+func (s exportScreen) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (s exportScreen) typing() bool { return true }
+
+func (s exportScreen) help() []key.Binding {
+	return []key.Binding{fixed("tab", "switch between Markdown, PDF, iCal, GeoJSON and KML"),
+		fixed("shift+tab", "keep, strip or mask what is private"), fixed("enter", "export the trip")}
+}
+
+func (s exportScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "tab" {
+		s.format = (s.format + 1) % len(exportFormats)
+		s.status, s.err = "", nil
+		return s, nil
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "shift+tab" {
+		s.redact = (s.redact + 1) % len(redactions)
+		s.status, s.err = "", nil
+		return s, nil
+	}
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "enter" {
+		s.status, s.err = "", nil
+		path, err := s.write()
+		if err != nil {
+			s.err = err
+		} else {
+			s.status = "Wrote " + path
+		}
+		return s, nil
+	}
+	var cmd tea.Cmd
+	s.dir, cmd = s.dir.Update(msg)
+	return s, cmd
+}
+
+// write exports the trip and returns the path of the file written.
+func (s exportScreen) write() (string, error) {
+	dir := expandHome(strings.TrimSpace(s.dir.Value()))
+	if dir == "" {
+		return "", fmt.Errorf("enter a directory to export to")
+	}
+	t, err := export.Load(s.app.ctx, s.app.store, s.trip)
+	if err != nil {
+		return "", err
+	}
+	t.FieldLabels = s.app.cfg.TripFields
+	paths, err := exportFormats[s.format].write(dir, []*export.Trip{t.Redact(redactions[s.redact].redact)}, s.app.cfg.Layout())
+	if err != nil {
+		return "", err
+	}
+	return paths[0], nil
+}
+
+func (s exportScreen) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("📤 Export "+s.trip.Title) + "\n\n")
+	b.WriteString(labelStyle.Render("Format") + "\n")
+	for i, f := range exportFormats {
+		if i > 0 {
+			b.WriteString(hintStyle.Render(" / "))
+		}
+		if i == s.format {
+			b.WriteString(highlightStyle.Render(f.name))
+		} else {
+			b.WriteString(hintStyle.Render(f.name))
+		}
+	}
+	b.WriteString("\n\n")
+	b.WriteString(labelStyle.Render("Private entries and passages") + "\n")
+	for i, r := range redactions {
+		if i > 0 {
+			b.WriteString(hintStyle.Render(" / "))
+		}
+		if i == s.redact {
+			b.WriteString(highlightStyle.Render(r.name))
+		} else {
+			b.WriteString(hintStyle.Render(r.name))
+		}
+	}
+	b.WriteString("\n\n")
+	b.WriteString(labelStyle.Render("Directory") + "\n")
+	b.WriteString(s.dir.View() + "\n")
+	f := exportFormats[s.format]
+	b.WriteString(hintStyle.Render("The trip is written as "+export.Slug(s.trip.Title)+f.ext+", "+f.about+".") + "\n")
+	if s.err != nil {
+		b.WriteString("\n" + errorStyle.Render(s.err.Error()) + "\n")
+	}
+	if s.status != "" {
+		b.WriteString("\n" + s.status + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("tab format • shift+tab private • enter export • esc back") + "\n")
+	return b.String()
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// failedMsg opens the error screen over the current one: what could not
+// be done, such as "load the trip", failed with err. retry, when set, is
+// what to try again.
+type failedMsg struct {
+	what  string
+	err   error
+	retry tea.Cmd
+}
+
+// fail returns a command opening the error screen for err, or nil when err
+// is nil.
+func fail(what string, err error, retry tea.Cmd) tea.Cmd {
+	if err == nil {
+		return nil
+	}
+	return notify(failedMsg{what: what, err: err, retry: retry})
+}
+
+// failure is the error screen: what went wrong, where its details are
+// logged and, when it may help, the key to try again. Going back returns
+// to the screen it opened over, so a failure of the store or an
+// integration costs the user what they were doing, not the whole session.
+type failure struct {
+	app *app
+	failedMsg
+}
+
+func newFailure(app *app, msg failedMsg) failure {
+	slog.Error("ui: failed", "what", msg.what, "err", msg.err, "unavailable", storage.Unavailable(msg.err))
+	return failure{app: app, failedMsg: msg}
+}
+
+func (f failure) Title() string { return tr("Error") }
+
+func (f failure) Init() tea.Cmd {
+	return nil
+}
+
+func (f failure) help() []key.Binding {
+	bindings := []key.Binding{f.app.bind("back", "go back to what you were doing")}
+	if f.retry != nil {
+		bindings = append(bindings, f.app.bind("select", "try again"))
+	}
+	return append(bindings, f.app.bind("debug", "open the debug console"), f.app.bind("quit", "quit nomadic"))
+}
+
+func (f failure) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch {
+		case f.app.is(msg, "select") && f.retry != nil:
+			slog.Info("ui: trying again", "what", f.what)
+			return f, tea.Sequence(pop, f.retry)
+		case f.app.is(msg, "quit"):
+			return f, quit
+		}
+	}
+	return f, nil
+}
+
+func (f failure) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("⚠ "+tr("Something went wrong")) + "\n\n")
+	if f.what != "" {
+		b.WriteString(tr("Could not %s:", f.what) + "\n")
+	}
+	b.WriteString(errorStyle.Render(f.err.Error()) + "\n")
+	if storage.Unavailable(f.err) {
+		b.WriteString("\n" + tr("The journal could not be read or written. Check that the disk has room left and that no other program holds the data directory, then try again.") + "\n")
+	}
+	if f.app.log != nil && f.app.log.Path() != "" {
+		b.WriteString("\n" + hintStyle.Render(tr("The details are in %s, and in the debug console (%s).", f.app.log.Path(), f.app.keyHint("debug"))) + "\n")
+	}
+	hint := f.app.keyHint("back") + " back"
+	if f.retry != nil {
+		hint += " • " + f.app.keyHint("select") + " try again"
+	}
+	hint += " • " + f.app.keyHint("quit") + " quit"
+	b.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return b.String()
+}
+
+// panicked is the error of a screen that panicked, with the stack logged
+// for the bug report.
+func panicked(title string, r any) error {
+	slog.Error("ui: panic", "screen", title, "panic", r, "stack", string(debug.Stack()))
+	return fmt.Errorf("%s stopped on an unexpected error: %v", title, r)
+}
+
+// updateScreen updates s with msg. A screen panicking is replaced by the
+// error screen rather than taking the TUI down with it.
+func (m Model) updateScreen(s screen, msg tea.Msg) (updated screen, cmd tea.Cmd) {
+	defer func() {
+		if r := recover(); r != nil {
+			updated, cmd = failure{app: m.app, failedMsg: failedMsg{err: panicked(s.Title(), r)}}, nil
+		}
+	}()
+	next, cmd := s.Update(msg)
+	return next.(screen), cmd
+}
+
+// viewScreen draws s, or the error screen in its place when it panics,
+// noting the panic in crash for the next update to replace s.
+func (m Model) viewScreen(s screen) (view string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if m.crash.err == nil {
+				m.crash.err = panicked(s.Title(), r)
+			}
+			view = failure{app: m.app, failedMsg: failedMsg{err: m.crash.err}}.View()
+		}
+	}()
+	return s.View()
+}
+
+// crash is the error of the screen on top that panicked as it was drawn,
+// until the next update replaces it with the error screen.
+type crash struct {
+	err error
+}
+
+// repair replaces the screen on top with the error screen when it
+// panicked as it was drawn, and puts the home menu back under an error
+// screen that took its place, for going back to.
+func (m *Model) repair() {
+	if m.crash.err != nil {
+		m.setTop(failure{app: m.app, failedMsg: failedMsg{err: m.crash.err}})
+		m.crash.err = nil
+	}
+	if _, ok := m.stack[0].(failure); ok && m.app.store != nil {
+		m.stack = append([]screen{newMenu(m.app)}, m.stack...)
+	}
+}
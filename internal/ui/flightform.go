@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/flights"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const (
+	flightFieldDay = iota
+	flightFieldFrom
+	flightFieldTo
+	flightFieldDeparts
+	flightFieldArrives
+	flightFieldNumber
+	flightFieldBooking
+	flightFieldAlarm
+)
+
+// flightForm adds a flight to a trip's itinerary by hand: its airports,
+// found by code or name, fill in the rest.
+type flightForm struct {
+	form
+	app  *app
+	trip *models.Trip
+}
+
+func newFlightForm(app *app, trip *models.Trip, day time.Time) flightForm {
+	f := newForm("✈️  New Flight",
+		newField("Day", app.cfg.DateFormat, "The day of departure.", app.validateDate),
+		newField("From", "LIS or Lisbon", "Airport code or name, e.g. HND or Haneda.", validateAirport),
+		newField("To", "HND or Haneda", "Airport code or name.", validateAirport),
+		newField("Departs", "13:25", "Optional. 24-hour clock, local time.", validateOptionalTime),
+		newField("Arrives", "+1 09:40", `Optional. Local time, with "+1" for the next day.`, validateArrival),
+		newField("Flight number", "TP 1234", "Optional.", validateDesignator),
+		newField("Booking reference", "", "Optional. Confirmation code.", nil),
+		newField("Alarm", "3h", "Optional. How long before to be reminded in your calendar, e.g. 3h or 1d.", validateAlarm),
+	)
+	f.fields[flightFieldDay].dates = newDatePicker(app)
+	f.fields[flightFieldDay].input.SetValue(app.formatDate(day))
+	f.fields[flightFieldFrom].places = newAirportCompleter()
+	f.fields[flightFieldTo].places = newAirportCompleter()
+	return flightForm{form: f, app: app, trip: trip}
+}
+
+func (f flightForm) Title() string { return tr("New flight") }
+
+func (f flightForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f flightForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		it, err := f.item()
+		if err == nil {
+			err = f.app.store.SaveItineraryItem(f.app.ctx, it)
+		}
+		if err != nil {
+			f.err = err
+			return f, nil
+		}
+		return f, tea.Sequence(pop, func() tea.Msg { return itinerarySavedMsg{item: it} })
+	}
+	return f, cmd
+}
+
+func (f flightForm) View() string {
+	return f.form.view(f.summary)
+}
+
+// flight reads the flight out of the validated values.
+func (f flightForm) flight() (flights.Flight, error) {
+	var fl flights.Flight
+	day, err := f.app.parseDate(f.value(flightFieldDay))
+	if err != nil {
+		return fl, err
+	}
+	from, _ := flights.FindAirport(f.value(flightFieldFrom))
+	to, _ := flights.FindAirport(f.value(flightFieldTo))
+	if from.Code == to.Code {
+		return fl, errors.New("the flight leaves from and arrives at the same airport")
+	}
+	fl.Departure = flights.Stop{Airport: from.Code, Name: from.Name, City: from.City, Day: day}
+	fl.Arrival = flights.Stop{Airport: to.Code, Name: to.Name, City: to.City}
+	if fl.Departure.Time, err = parseOptionalTime(f.value(flightFieldDeparts)); err != nil {
+		return fl, err
+	}
+	if v := f.value(flightFieldArrives); v != "" {
+		at, days, err := flights.ParseArrival(v)
+		if err != nil {
+			return fl, err
+		}
+		fl.Arrival.Time, fl.Arrival.Day = at, day.AddDate(0, 0, days)
+	}
+	if code, number, ok := flights.ParseDesignator(f.value(flightFieldNumber)); ok {
+		fl.Code, fl.Number, fl.Airline = code, number, code
+		if name, ok := flights.Airline(code); ok {
+			fl.Airline = name
+		}
+	}
+	fl.Reference = strings.ToUpper(f.value(flightFieldBooking))
+	return fl, nil
+}
+
+// item makes the flight an item at the end of its day, unless the
+// itinerary has it already.
+func (f flightForm) item() (*models.ItineraryItem, error) {
+	fl, err := f.flight()
+	if err != nil {
+		return nil, err
+	}
+	alarm, err := models.ParseAlarm(f.value(flightFieldAlarm))
+	if err != nil {
+		return nil, err
+	}
+	existing, err := f.app.store.ListItineraryByTrip(f.app.ctx, f.trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	items, _ := flights.Items(f.trip.ID, []flights.Flight{fl}, existing)
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%s is in the itinerary already", fl.Item(f.trip.ID).Title)
+	}
+	items[0].Alarm = alarm
+	return items[0], nil
+}
+
+func (f flightForm) summary() string {
+	var b strings.Builder
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-10s", label+":")), value)
+	}
+	airport := func(label string, i int) {
+		a, ok := flights.FindAirport(f.value(i))
+		if !ok {
+			row(label, "")
+			return
+		}
+		row(label, a.String())
+		fmt.Fprintf(&b, "%10s %s\n", "", hintStyle.Render(airportDetails(a)))
+	}
+	row("Day", f.value(flightFieldDay))
+	airport("From", flightFieldFrom)
+	airport("To", flightFieldTo)
+	fl, _ := f.flight()
+	row("Departs", fl.Departure.Time)
+	arrives := fl.Arrival.Time
+	if !fl.Arrival.Day.IsZero() && !fl.Arrival.Day.Equal(fl.Departure.Day) {
+		arrives += " on " + f.app.formatDate(fl.Arrival.Day)
+	}
+	row("Arrives", arrives)
+	distance := ""
+	if km, ok := fl.Distance(); ok {
+		distance = fmt.Sprintf("%.0f km", km)
+	}
+	row("Distance", distance)
+	row("Flight", fl.Designator())
+	if fl.Airline != "" && fl.Airline != fl.Code {
+		row("Airline", fl.Airline)
+	}
+	row("Booking", fl.Reference)
+	alarm := ""
+	if m, _ := models.ParseAlarm(f.value(flightFieldAlarm)); m > 0 {
+		alarm = models.FormatAlarm(m) + " before"
+	}
+	row("Alarm", alarm)
+	return b.String()
+}
+
+// newAirportCompleter completes airports from the offline airports
+// dataset by their codes, names and cities.
+func newAirportCompleter() *autocomplete {
+	return &autocomplete{source: func(typed string) []suggestion {
+		var out []suggestion
+		for _, a := range flights.SearchAirports(typed, maxSuggestions) {
+			out = append(out, suggestion{value: a.String(), detail: a.City + ", " + a.Country})
+		}
+		return out
+	}}
+}
+
+// airportDetails describes an airport as "Tokyo, Japan · 35.55°N 139.78°E
+// · Asia/Tokyo".
+func airportDetails(a flights.Airport) string {
+	return a.City + ", " + a.CountryName() + " · " + a.Coordinates() + " · " + a.Timezone
+}
+
+func validateAirport(v string) error {
+	if v == "" {
+		return errors.New("airport is required")
+	}
+	if _, ok := flights.FindAirport(v); !ok {
+		return fmt.Errorf("no single airport called %q: pick one from the list", v)
+	}
+	return nil
+}
+
+func validateArrival(v string) error {
+	if v == "" {
+		return nil
+	}
+	_, _, err := flights.ParseArrival(v)
+	return err
+}
+
+func validateDesignator(v string) error {
+	if v == "" {
+		return nil
+	}
+	if _, _, ok := flights.ParseDesignator(v); !ok {
+		return errors.New("enter the airline code and number, e.g. NH 278")
+	}
+	return nil
+}
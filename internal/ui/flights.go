@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/flights"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// flightImport reads the flights of a booking confirmation pasted into it
+// and adds them to a trip's itinerary once their preview is confirmed.
+type flightImport struct {
+	app  *app
+	trip *models.Trip
+	text textarea.Model
+
+	// previewing is set once the flights were read, until the text is
+	// edited again.
+	previewing        bool
+	items, duplicates []*models.ItineraryItem
+	err               error
+}
+
+func newFlightImport(app *app, trip *models.Trip) flightImport {
+	s := flightImport{app: app, trip: trip, text: textarea.New()}
+	s.text.Placeholder = "Paste the booking confirmation here…"
+	s.text.CharLimit = 0
+	s.text.ShowLineNumbers = false
+	s.text.Focus()
+	s.resize(80, 24)
+	return s
+}
+
+func (s flightImport) Title() string { return tr("Import flights") }
+
+func (s flightImport) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+func (s flightImport) typing() bool { return !s.previewing }
+
+func (s flightImport) capturesEsc() bool { return s.previewing }
+
+func (s flightImport) help() []key.Binding {
+	if s.previewing {
+		return []key.Binding{
+			s.app.bind("select", "add the flights to the itinerary"),
+			fixed("esc", "back to the confirmation"),
+		}
+	}
+	return []key.Binding{
+		s.app.bind("save", "read the flights"),
+		fixed("esc", "cancel"),
+	}
+}
+
+func (s *flightImport) resize(width, height int) {
+	s.text.SetWidth(max(width-2, 20))
+	s.text.SetHeight(max(height-10, 5))
+}
+
+func (s flightImport) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.resize(msg.Width, msg.Height)
+		return s, nil
+	case tea.KeyMsg:
+		if s.previewing {
+			switch {
+			case msg.String() == "esc":
+				s.previewing = false
+				return s, s.text.Focus()
+			case s.app.is(msg, "select"):
+				return s.save()
+			}
+			return s, nil
+		}
+		if s.app.is(msg, "save") {
+			s.read()
+			return s, nil
+		}
+	}
+	var cmd tea.Cmd
+	s.text, cmd = s.text.Update(msg)
+	return s, cmd
+}
+
+// read parses the pasted text into the flights to preview.
+func (s *flightImport) read() {
+	s.err = nil
+	found, err := flights.Parse(s.text.Value(), s.trip.StartDate)
+	if err != nil {
+		s.err = err
+		return
+	}
+	existing, err := s.app.store.ListItineraryByTrip(s.app.ctx, s.trip.ID)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.items, s.duplicates = flights.Items(s.trip.ID, found, existing)
+	s.previewing = true
+	s.text.Blur()
+}
+
+func (s flightImport) save() (tea.Model, tea.Cmd) {
+	if len(s.items) == 0 {
+		return s, pop
+	}
+	for _, it := range s.items {
+		if err := s.app.store.SaveItineraryItem(s.app.ctx, it); err != nil {
+			s.err = err
+			return s, nil
+		}
+	}
+	first := s.items[0]
+	return s, tea.Sequence(pop, func() tea.Msg { return itinerarySavedMsg{item: first} })
+}
+
+func (s flightImport) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("✈️  Import flights into "+s.trip.Title) + "\n\n")
+	if !s.previewing {
+		b.WriteString("Paste a booking confirmation from the airline or travel agent.\n\n")
+		b.WriteString(s.text.View() + "\n")
+		if s.err != nil {
+			b.WriteString("\n" + errorStyle.Render(s.err.Error()) + "\n")
+		}
+		b.WriteString("\n" + hintStyle.Render(s.app.keyHint("save")+" read flights • esc cancel") + "\n")
+		return b.String()
+	}
+	if len(s.items) == 0 {
+		b.WriteString("These flights are all in the itinerary already.\n")
+	}
+	for _, it := range s.items {
+		ref := ""
+		if it.BookingRef != "" {
+			ref = hintStyle.Render(" · 🎫 " + it.BookingRef)
+		}
+		fmt.Fprintf(&b, "%s %s%s\n", labelStyle.Render(s.app.formatDate(it.Day)+" "+it.Time), it.Title, ref)
+		fmt.Fprintf(&b, "  %s\n", hintStyle.Render(it.Notes))
+	}
+	for _, it := range s.duplicates {
+		fmt.Fprintf(&b, "%s %s\n", hintStyle.Render(s.app.formatDate(it.Day)+" "+it.Time), hintStyle.Render(it.Title+" (already planned)"))
+	}
+	if s.err != nil {
+		b.WriteString("\n" + errorStyle.Render(s.err.Error()) + "\n")
+	}
+	hint := "enter add " + plural(len(s.items), "flight", "flights") + " • esc edit"
+	if len(s.items) == 0 {
+		hint = "enter close • esc edit"
+	}
+	b.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return b.String()
+}
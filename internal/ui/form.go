@@ -0,0 +1,268 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// field is one question of a multi-step form.
+type field struct {
+	label    string
+	hint     string
+	input    textinput.Model
+	validate func(string) error
+	// tags, when set, completes the field's comma-separated tags.
+	tags *tagCompleter
+	// places, when set, completes city and country names.
+	places *autocomplete
+	// dates, when set, picks the field's date.
+	dates *datePicker
+}
+
+func newField(label, placeholder, hint string, validate func(string) error) field {
+	in := textinput.New()
+	in.Placeholder = placeholder
+	in.CharLimit = 256
+	in.Width = 40
+	return field{label: label, hint: hint, input: in, validate: validate}
+}
+
+// form walks the user through its fields one at a time and ends on a
+// confirmation step. It is embedded by concrete screens which decide what
+// to do with the values once confirmed.
+type form struct {
+	title  string
+	fields []field
+	step   int
+	err    error
+	// dirty is set once anything was typed, so there is work to keep as
+	// a draft.
+	dirty bool
+}
+
+// formResult tells the embedding screen how the last key press affected the form.
+type formResult int
+
+const (
+	formEditing formResult = iota
+	formCancelled
+	formConfirmed
+)
+
+func newForm(title string, fields ...field) form {
+	f := form{title: title, fields: fields}
+	f.fields[0].input.Focus()
+	return f
+}
+
+func (f form) value(i int) string {
+	return strings.TrimSpace(f.fields[i].input.Value())
+}
+
+// values returns what is in the fields, untrimmed, for drafts.
+func (f form) values() []string {
+	values := make([]string, len(f.fields))
+	for i, fl := range f.fields {
+		values[i] = fl.input.Value()
+	}
+	return values
+}
+
+// restore fills the fields with the values of a draft.
+func (f *form) restore(values []string) {
+	for i := range min(len(values), len(f.fields)) {
+		f.fields[i].input.SetValue(values[i])
+	}
+	f.dirty = true
+}
+
+func (f form) confirming() bool {
+	return f.step == len(f.fields)
+}
+
+// typing reports whether a field has focus; the confirmation step takes
+// single keys.
+func (f form) typing() bool {
+	return !f.confirming()
+}
+
+func (f form) help() []key.Binding {
+	if f.confirming() {
+		return []key.Binding{
+			fixed("enter/y", "save"),
+			fixed("n", "start over"),
+			fixed("shift+tab", "back to the last field"),
+			fixed("esc", "cancel"),
+		}
+	}
+	bindings := []key.Binding{
+		fixed("enter/tab", "next field"),
+		fixed("shift+tab", "previous field"),
+		fixed("esc", "cancel"),
+	}
+	if cur := f.fields[f.step]; cur.tags != nil || cur.places != nil {
+		bindings = append(bindings, completionHelp()...)
+	}
+	if f.fields[f.step].dates != nil {
+		bindings = append(bindings, datePickerHelp()...)
+	}
+	return bindings
+}
+
+func (f *form) goTo(step int) tea.Cmd {
+	if f.step < len(f.fields) {
+		f.fields[f.step].input.Blur()
+	}
+	f.step = step
+	f.err = nil
+	if step < len(f.fields) {
+		return f.fields[step].input.Focus()
+	}
+	return nil
+}
+
+// review moves a form filled in beforehand to the first field whose value
+// does not validate, or else straight to the confirmation step.
+func (f *form) review() {
+	f.dirty = true
+	for i := range f.fields {
+		if v := f.fields[i].validate; v != nil && v(f.value(i)) != nil {
+			f.goTo(i)
+			return
+		}
+		if err := f.pickDate(i); err != nil {
+			f.goTo(i)
+			f.err = err
+			return
+		}
+	}
+	f.goTo(len(f.fields))
+}
+
+func (f form) update(msg tea.Msg) (form, tea.Cmd, formResult) {
+	step := f.step
+	var before string
+	if step < len(f.fields) {
+		before = f.fields[step].input.Value()
+	}
+	f, cmd, result := f.handle(msg)
+	if step < len(f.fields) && f.fields[step].input.Value() != before {
+		f.dirty = true
+	}
+	return f, cmd, result
+}
+
+func (f form) handle(msg tea.Msg) (form, tea.Cmd, formResult) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "esc":
+			return f, nil, formCancelled
+		case "shift+tab", "up":
+			if f.step > 0 {
+				return f, f.goTo(f.step - 1), formEditing
+			}
+			return f, nil, formEditing
+		case "enter", "tab", "down":
+			if f.confirming() {
+				if key.String() == "enter" {
+					return f, nil, formConfirmed
+				}
+				return f, nil, formEditing
+			}
+			cur := f.fields[f.step]
+			if cur.validate != nil {
+				if err := cur.validate(f.value(f.step)); err != nil {
+					f.err = err
+					return f, nil, formEditing
+				}
+			}
+			if err := f.pickDate(f.step); err != nil {
+				f.err = err
+				return f, nil, formEditing
+			}
+			return f, f.goTo(f.step + 1), formEditing
+		}
+		if f.confirming() {
+			switch key.String() {
+			case "y":
+				return f, nil, formConfirmed
+			case "n":
+				return f, f.goTo(0), formEditing
+			}
+			return f, nil, formEditing
+		}
+	}
+	if f.confirming() {
+		return f, nil, formEditing
+	}
+	if key, ok := msg.(tea.KeyMsg); ok {
+		if c := f.fields[f.step].tags; c != nil && c.update(&f.fields[f.step].input, key) {
+			return f, nil, formEditing
+		}
+		if c := f.fields[f.step].places; c != nil && c.update(&f.fields[f.step].input, key) {
+			return f, nil, formEditing
+		}
+		if p := f.fields[f.step].dates; p != nil && p.update(&f.fields[f.step].input, key, f.rangeStart(f.step)) {
+			f.err = nil
+			return f, nil, formEditing
+		}
+	}
+	var cmd tea.Cmd
+	f.fields[f.step].input, cmd = f.fields[f.step].input.Update(msg)
+	f.err = nil
+	return f, cmd, formEditing
+}
+
+// isAdvance reports whether key moves a form to its next step.
+func isAdvance(key tea.KeyMsg) bool {
+	switch key.String() {
+	case "enter", "tab", "down":
+		return true
+	}
+	return false
+}
+
+// view renders the current step. summary renders the confirmation page.
+func (f form) view(summary func() string) string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(f.title) + "\n\n")
+
+	if f.confirming() {
+		b.WriteString(summary())
+		b.WriteString("\n" + hintStyle.Render("enter/y save • n start over • shift+tab back • esc cancel") + "\n")
+		if f.err != nil {
+			b.WriteString("\n" + errorStyle.Render(f.err.Error()) + "\n")
+		}
+		return b.String()
+	}
+
+	cur := f.fields[f.step]
+	b.WriteString(hintStyle.Render(fmt.Sprintf("Step %d of %d", f.step+1, len(f.fields))) + "\n\n")
+	b.WriteString(labelStyle.Render(cur.label) + "\n")
+	b.WriteString(cur.input.View() + "\n")
+	if cur.tags != nil {
+		if s := cur.tags.view(cur.input.Value()); s != "" {
+			b.WriteString(s + "\n")
+		}
+	}
+	if cur.places != nil {
+		if s := cur.places.view(cur.input.Value()); s != "" {
+			b.WriteString(s + "\n")
+		}
+	}
+	if cur.dates != nil {
+		b.WriteString(cur.dates.view(cur.input.Value(), f.rangeStart(f.step)) + "\n")
+	}
+	if cur.hint != "" {
+		b.WriteString(hintStyle.Render(cur.hint) + "\n")
+	}
+	if f.err != nil {
+		b.WriteString("\n" + errorStyle.Render(f.err.Error()) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("enter next • shift+tab back • esc cancel") + "\n")
+	return b.String()
+}
@@ -0,0 +1,154 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+const (
+	healthFieldDate = iota
+	healthFieldSleep
+	healthFieldWater
+	healthFieldJetlag
+	healthFieldNote
+)
+
+// healthForm logs how a day of a trip went for the body, today's by
+// default, over what was logged for it.
+type healthForm struct {
+	form
+	app  *app
+	trip *models.Trip
+}
+
+func newHealthForm(app *app, trip *models.Trip) healthForm {
+	today := dateOf(app.tripNow(trip))
+	f := newForm("💤 Sleep, water and jetlag",
+		newField("Date", app.cfg.DateFormat, "The day of the trip to log.", app.validateDate),
+		newField("Sleep", "7.5", "Optional. Hours slept the night before.", validateHours),
+		newField("Water", "2", "Optional. Litres of water drunk.", validateLitres),
+		newField("Jetlag", "3", fmt.Sprintf("Optional. How jetlagged the day felt, from 1 (not at all) to %d (badly).", models.MaxRating), validateRating),
+		newField("Note", "Woke at 4am", "Optional.", nil),
+	)
+	f.fields[healthFieldDate].input.SetValue(app.formatDate(today))
+	if h, err := app.store.GetHealthDay(app.ctx, trip.ID, today.Format(models.DateLayout)); err == nil {
+		if h.Sleep > 0 {
+			f.fields[healthFieldSleep].input.SetValue(strconv.FormatFloat(h.Sleep, 'f', -1, 64))
+		}
+		if h.Water > 0 {
+			f.fields[healthFieldWater].input.SetValue(strconv.FormatFloat(h.Water, 'f', -1, 64))
+		}
+		if h.Jetlag > 0 {
+			f.fields[healthFieldJetlag].input.SetValue(strconv.Itoa(h.Jetlag))
+		}
+		f.fields[healthFieldNote].input.SetValue(h.Note)
+	}
+	return healthForm{form: f, app: app, trip: trip}
+}
+
+func (f healthForm) Title() string { return tr("Sleep and jetlag") }
+
+func (f healthForm) currentTrip() *models.Trip { return f.trip }
+
+func (f healthForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f healthForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		if err := f.save(); err != nil {
+			f.err = err
+			return f, nil
+		}
+		id := f.trip.ID
+		return f, tea.Sequence(pop, func() tea.Msg { return healthChangedMsg{tripID: id} })
+	}
+	return f, cmd
+}
+
+// save stores the day as typed in, or forgets it when everything was
+// cleared.
+func (f healthForm) save() error {
+	day, err := f.app.parseDate(f.value(healthFieldDate))
+	if err != nil {
+		return err
+	}
+	h := &models.HealthDay{TripID: f.trip.ID, Day: day.Format(models.DateLayout), Note: strings.TrimSpace(f.value(healthFieldNote))}
+	h.Sleep, _ = parseNumber(f.value(healthFieldSleep))
+	h.Water, _ = parseNumber(f.value(healthFieldWater))
+	if h.Jetlag, err = parseRating(f.value(healthFieldJetlag)); err != nil {
+		return err
+	}
+	if err := models.CheckHealth(h.Sleep, h.Water, h.Jetlag); err != nil {
+		return err
+	}
+	if h.Empty() {
+		err := f.app.store.DeleteHealthDay(f.app.ctx, h.TripID, h.Day)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return f.app.store.SaveHealthDay(f.app.ctx, h)
+}
+
+func (f healthForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		units := []string{"", " h", " l", fmt.Sprintf(" of %d", models.MaxRating), ""}
+		for i, label := range []string{"Date", "Sleep", "Water", "Jetlag", "Note"} {
+			v := f.value(i)
+			if v == "" {
+				v = hintStyle.Render("—")
+			} else {
+				v += units[i]
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-7s", label+":")), v)
+		}
+		return b.String()
+	})
+}
+
+// parseNumber reads a number typed in, with a comma or a point for
+// decimals; empty is zero.
+func parseNumber(v string) (float64, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(strings.ReplaceAll(v, ",", "."), 64)
+}
+
+func validateHours(v string) error {
+	if n, err := parseNumber(v); err != nil || n < 0 || n > 24 {
+		return errors.New("enter the hours from 0 to 24, e.g. 7.5, or leave it empty")
+	}
+	return nil
+}
+
+func validateLitres(v string) error {
+	if n, err := parseNumber(v); err != nil || n < 0 || n > 20 {
+		return errors.New("enter the litres from 0 to 20, e.g. 2, or leave it empty")
+	}
+	return nil
+}
+
+func validateRating(v string) error {
+	_, err := parseRating(v)
+	return err
+}
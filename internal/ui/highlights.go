@@ -0,0 +1,417 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// highlightsMode is what the highlights screen is doing besides showing
+// the list.
+type highlightsMode int
+
+const (
+	highlightsBrowse highlightsMode = iota
+	highlightsAdd                   // typing a new goal
+	highlightsEdit                  // retitling the selected one
+	highlightsLink                  // choosing the journal entry it happened in
+)
+
+// highlightsView is a trip's list of goals, checked off as they happen
+// to become its highlights, each perhaps linked to the journal entry that
+// tells of it.
+type highlightsView struct {
+	app        *app
+	trip       *models.Trip
+	highlights []*models.Highlight
+	cursor     int
+	// entries are the trip's journal entries, newest first, and byID the
+	// same by ID.
+	entries []*models.Entry
+	byID    map[string]*models.Entry
+
+	mode        highlightsMode
+	input       textinput.Model
+	entryCursor int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newHighlightsView(app *app, trip *models.Trip) highlightsView {
+	in := textinput.New()
+	in.Width = 50
+	v := highlightsView{app: app, trip: trip, input: in}
+	v.reload()
+	return v
+}
+
+func (v highlightsView) Title() string { return tr("Highlights") }
+
+func (v highlightsView) currentTrip() *models.Trip { return v.trip }
+
+func (v highlightsView) Init() tea.Cmd {
+	return nil
+}
+
+func (v highlightsView) capturesEsc() bool { return v.confirmDelete || v.mode != highlightsBrowse }
+
+func (v highlightsView) typing() bool { return v.mode == highlightsAdd || v.mode == highlightsEdit }
+
+func (v highlightsView) help() []key.Binding {
+	switch v.mode {
+	case highlightsAdd:
+		return []key.Binding{fixed("enter", "add the goal"), fixed("esc", "done")}
+	case highlightsEdit:
+		return []key.Binding{fixed("enter", "save the title"), fixed("esc", "cancel")}
+	case highlightsLink:
+		return []key.Binding{
+			v.app.bind("up", "previous entry"),
+			v.app.bind("down", "next entry"),
+			v.app.bind("select", "link to the entry"),
+			fixed("esc", "cancel"),
+		}
+	}
+	return append([]key.Binding{
+		v.app.bind("up", "previous goal"),
+		v.app.bind("down", "next goal"),
+		v.app.bind("toggle", "check the goal off, or make it a goal again"),
+		v.app.bind("add", "add goals"),
+		v.app.bind("edit", "change the title"),
+		v.app.bind("link", "link to the journal entry where it happened"),
+		v.app.bind("open", "read the linked entry"),
+		v.app.bind("move_up", "move the goal up"),
+		v.app.bind("move_down", "move the goal down"),
+		v.app.bind("delete", "remove the goal"),
+	}, v.app.undoHelp()...)
+}
+
+func (v *highlightsView) reload() {
+	if v.highlights, v.err = v.app.store.ListHighlightsByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	if v.entries, v.err = v.app.store.ListEntriesByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	v.byID = make(map[string]*models.Entry, len(v.entries))
+	for _, e := range v.entries {
+		v.byID[e.ID] = e
+	}
+	v.cursor = clamp(v.cursor, 0, len(v.highlights)-1)
+}
+
+func (v highlightsView) selected() *models.Highlight {
+	if len(v.highlights) == 0 {
+		return nil
+	}
+	return v.highlights[v.cursor]
+}
+
+// changed reloads the list and tells the other screens about it.
+func (v *highlightsView) changed() tea.Cmd {
+	v.reload()
+	id := v.trip.ID
+	return func() tea.Msg { return highlightsChangedMsg{tripID: id} }
+}
+
+// save writes h, a changed copy of the selected goal, and reloads.
+func (v *highlightsView) save(h *models.Highlight) tea.Cmd {
+	if err := v.app.store.SaveHighlight(v.app.ctx, h); err != nil {
+		v.err = err
+		return nil
+	}
+	v.err = nil
+	return v.changed()
+}
+
+func (v highlightsView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case entrySavedMsg, historyMsg:
+		v.reload()
+		return v, nil
+	case tea.KeyMsg:
+		switch v.mode {
+		case highlightsAdd, highlightsEdit:
+			return v.updateInput(msg)
+		case highlightsLink:
+			return v.updateLink(msg)
+		}
+		if v.confirmDelete {
+			v.confirmDelete = false
+			if msg.String() == "y" {
+				h := v.selected()
+				if err := v.app.run(deleteHighlight{highlight: h}); err != nil {
+					v.err = err
+				} else {
+					v.status = v.app.deletedHint(h.Title)
+				}
+				return v, v.changed()
+			}
+			return v, nil
+		}
+		if cmd, ok := v.app.undoKeys(msg); ok {
+			return v, cmd
+		}
+
+		h := v.selected()
+		switch {
+		case v.app.is(msg, "up"):
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case v.app.is(msg, "down"):
+			if v.cursor < len(v.highlights)-1 {
+				v.cursor++
+			}
+		case v.app.edits(msg, "toggle"), v.app.edits(msg, "select"):
+			if h == nil {
+				break
+			}
+			toggled := *h
+			toggled.Done = !toggled.Done
+			v.status = ""
+			return v, v.save(&toggled)
+		case v.app.is(msg, "add"):
+			v.mode, v.status, v.err = highlightsAdd, "", nil
+			v.input.Placeholder = "Climb Mount Fuji"
+			v.input.SetValue("")
+			return v, v.input.Focus()
+		case v.app.is(msg, "edit"):
+			if h == nil {
+				break
+			}
+			v.mode, v.status, v.err = highlightsEdit, "", nil
+			v.input.Placeholder = ""
+			v.input.SetValue(h.Title)
+			v.input.CursorEnd()
+			return v, v.input.Focus()
+		case v.app.is(msg, "link"):
+			if h == nil {
+				break
+			}
+			if len(v.entries) == 0 {
+				v.status = "No journal entries on this trip to link to yet."
+				break
+			}
+			v.mode, v.status, v.err = highlightsLink, "", nil
+			v.entryCursor = 0
+			for i, e := range v.entries {
+				if e.ID == h.EntryID {
+					v.entryCursor = i + 1
+				}
+			}
+		case v.app.is(msg, "open"):
+			if h == nil {
+				break
+			}
+			if e := v.byID[h.EntryID]; e != nil {
+				v.status = ""
+				return v, push(newEntryReader(v.app, e))
+			}
+			v.status = "Not linked to a journal entry — press " + v.app.keyHint("link") + " to link one."
+		case v.app.is(msg, "move_up"):
+			return v, v.move(-1)
+		case v.app.is(msg, "move_down"):
+			return v, v.move(1)
+		case v.app.is(msg, "delete"):
+			if h != nil {
+				v.confirmDelete = true
+			}
+		}
+	}
+	return v, nil
+}
+
+// move moves the goal under the cursor by delta places.
+func (v *highlightsView) move(delta int) tea.Cmd {
+	to := v.cursor + delta
+	if len(v.highlights) == 0 || to < 0 || to >= len(v.highlights) {
+		return nil
+	}
+	if v.err = v.app.store.MoveHighlight(v.app.ctx, v.highlights[v.cursor], to); v.err != nil {
+		return nil
+	}
+	v.cursor, v.status = to, ""
+	return v.changed()
+}
+
+// updateInput edits a new goal or the title of the selected one, and saves
+// it on Enter. Adding keeps the input open for the next goal.
+func (v highlightsView) updateInput(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		v.mode = highlightsBrowse
+		v.input.Blur()
+		return v, nil
+	case "enter":
+		title := strings.TrimSpace(v.input.Value())
+		if title == "" {
+			v.err = errors.New("enter something to do on the trip")
+			return v, nil
+		}
+		if v.mode == highlightsEdit {
+			h := *v.selected()
+			h.Title = title
+			v.mode = highlightsBrowse
+			v.input.Blur()
+			return v, v.save(&h)
+		}
+		h := models.NewHighlight(v.trip.ID, title)
+		cmd := v.save(h)
+		if v.err != nil {
+			return v, nil
+		}
+		v.input.SetValue("")
+		v.status = fmt.Sprintf("Added %q", h.Title)
+		v.cursor = len(v.highlights) - 1
+		return v, cmd
+	}
+	var cmd tea.Cmd
+	v.input, cmd = v.input.Update(key)
+	return v, cmd
+}
+
+// updateLink chooses the journal entry the selected goal happened in, or
+// none, the first choice, and links it on Enter. Linking checks it off.
+func (v highlightsView) updateLink(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.String() == "esc":
+		v.mode = highlightsBrowse
+	case v.app.is(key, "up"):
+		if v.entryCursor > 0 {
+			v.entryCursor--
+		}
+	case v.app.is(key, "down"):
+		if v.entryCursor < len(v.entries) {
+			v.entryCursor++
+		}
+	case v.app.is(key, "select"):
+		h := *v.selected()
+		h.EntryID = ""
+		v.status = fmt.Sprintf("Unlinked %q", h.Title)
+		if v.entryCursor > 0 {
+			e := v.entries[v.entryCursor-1]
+			h.EntryID, h.Done = e.ID, true
+			v.status = fmt.Sprintf("Linked %q to %s", h.Title, v.entryName(e))
+		}
+		v.mode = highlightsBrowse
+		return v, v.save(&h)
+	}
+	return v, nil
+}
+
+// entryName names a journal entry by its title, or its date when it has
+// none.
+func (v highlightsView) entryName(e *models.Entry) string {
+	if e.Title == "" {
+		return v.app.formatDate(e.Timestamp)
+	}
+	return fmt.Sprintf("%q", e.Title)
+}
+
+func (v highlightsView) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🌟 Highlights • "+v.trip.Title) + "\n\n")
+	if len(v.highlights) > 0 {
+		b.WriteString(highlightProgress(v.highlights) + "\n\n")
+	} else {
+		b.WriteString("No goals yet — press " + v.app.keyHint("add") + " to add the things to do on this trip.\n")
+	}
+
+	for i, h := range v.highlights {
+		cursor, box, title := "  ", "☐", h.Title
+		if h.Done {
+			box = successStyle.Render("☑")
+		}
+		if i == v.cursor {
+			cursor, title = "👉", cursorStyle.Render(title)
+		}
+		line := fmt.Sprintf("%s %s %s", cursor, box, title)
+		if e := v.byID[h.EntryID]; e != nil {
+			line += hintStyle.Render("  📔 " + truncate(v.entryName(e), 30) + " · " + v.app.formatDate(e.Timestamp))
+		}
+		b.WriteString(line + "\n")
+	}
+
+	switch v.mode {
+	case highlightsAdd:
+		b.WriteString("\n" + labelStyle.Render("Add goal") + "\n" + v.input.View() + "\n")
+	case highlightsEdit:
+		b.WriteString("\n" + labelStyle.Render("Title") + "\n" + v.input.View() + "\n")
+	case highlightsLink:
+		b.WriteString("\n" + labelStyle.Render("Where it happened") + "\n")
+		choices := []string{hintStyle.Render("no entry")}
+		for _, e := range v.entries {
+			choices = append(choices, v.app.formatDate(e.Timestamp)+"  "+truncate(e.Title, 40))
+		}
+		from, to := window(len(choices), v.entryCursor, 10)
+		for i := from; i < to; i++ {
+			cursor, choice := "  ", choices[i]
+			if i == v.entryCursor {
+				cursor, choice = "👉", cursorStyle.Render(choice)
+			}
+			fmt.Fprintf(&b, "%s %s\n", cursor, choice)
+		}
+	}
+	if v.err != nil {
+		b.WriteString("\n" + errorStyle.Render(v.err.Error()) + "\n")
+	}
+	if v.status != "" {
+		b.WriteString("\n" + v.status + "\n")
+	}
+	if v.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Remove %q? y/n", v.selected().Title)) + "\n")
+	}
+	a := v.app
+	hint := a.keyHint("toggle") + " done • " + a.keyHint("add") + " add • " + a.keyHint("edit") + " edit • " +
+		a.keyHint("link") + " link entry • " + a.keyHint("open") + " read entry • " +
+		a.keyHint("move_up") + "/" + a.keyHint("move_down") + " reorder • " + a.keyHint("delete") + " remove • " +
+		a.keyHint("undo") + " undo • esc back"
+	switch v.mode {
+	case highlightsAdd:
+		hint = "enter add • esc done"
+	case highlightsEdit:
+		hint = "enter save • esc cancel"
+	case highlightsLink:
+		hint = "↑/↓ move • enter link • esc cancel"
+	}
+	b.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return b.String()
+}
+
+// highlightProgress renders how many of a trip's goals are done as a bar.
+func highlightProgress(highlights []*models.Highlight) string {
+	done, total := models.HighlightProgress(highlights)
+	if total == 0 {
+		return ""
+	}
+	ratio := float64(done) / float64(total)
+	filled := int(ratio*budgetBarWidth + 0.5)
+	style := warningStyle
+	if done == total {
+		style = successStyle
+	}
+	bar := style.Render(strings.Repeat("█", filled)) + hintStyle.Render(strings.Repeat("░", budgetBarWidth-filled))
+	return fmt.Sprintf("%s %3.0f%%  %d of %d done", bar, ratio*100, done, total)
+}
+
+// highlightSummary names the highlights linked to a journal entry, for
+// its reader.
+func highlightSummary(a *app, entryID string) string {
+	highlights, err := a.store.ListHighlightsByEntry(a.ctx, entryID)
+	if err != nil || len(highlights) == 0 {
+		return ""
+	}
+	titles := make([]string, len(highlights))
+	for i, h := range highlights {
+		titles[i] = h.Title
+	}
+	return "🌟 " + truncate(strings.Join(titles, ", "), 40)
+}
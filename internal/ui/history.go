@@ -0,0 +1,596 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// historyLimit is how many changes can be undone.
+const historyLimit = 50
+
+// command is a reversible change to the store. Destructive actions run as
+// commands through app.run so they land on the undo stack.
+type command interface {
+	do(ctx context.Context, s *storage.Store) error
+	undo(ctx context.Context, s *storage.Store) error
+	// String describes the change, e.g. `delete entry "Tsukiji"`.
+	String() string
+}
+
+// history holds the commands that can be undone, most recent last, and
+// those undone since, which can be redone.
+type history struct {
+	done, undone []command
+}
+
+// historyMsg reports an undo or redo. Every screen reloads on it, since
+// the change may have been made anywhere.
+type historyMsg struct {
+	text string
+	err  error
+}
+
+func (historyMsg) broadcast() {}
+
+// run applies c and records it so it can be undone. A new change discards
+// whatever was undone before it.
+func (a *app) run(c command) error {
+	if err := c.do(a.ctx, a.store); err != nil {
+		return err
+	}
+	a.history.done = append(a.history.done, c)
+	if len(a.history.done) > historyLimit {
+		a.history.done = a.history.done[1:]
+	}
+	a.history.undone = nil
+	return nil
+}
+
+// undo reverts the most recent command.
+func (a *app) undo() tea.Cmd {
+	h := &a.history
+	if len(h.done) == 0 {
+		return notify(historyMsg{text: "Nothing to undo"})
+	}
+	c := h.done[len(h.done)-1]
+	if err := c.undo(a.ctx, a.store); err != nil {
+		return notify(historyMsg{err: fmt.Errorf("undo %s: %w", c, err)})
+	}
+	h.done = h.done[:len(h.done)-1]
+	h.undone = append(h.undone, c)
+	return notify(historyMsg{text: "↶ Undid " + c.String() + " • " + a.keyHint("redo") + " to redo"})
+}
+
+// redo applies the most recently undone command again.
+func (a *app) redo() tea.Cmd {
+	h := &a.history
+	if len(h.undone) == 0 {
+		return notify(historyMsg{text: "Nothing to redo"})
+	}
+	c := h.undone[len(h.undone)-1]
+	if err := c.do(a.ctx, a.store); err != nil {
+		return notify(historyMsg{err: fmt.Errorf("redo %s: %w", c, err)})
+	}
+	h.undone = h.undone[:len(h.undone)-1]
+	h.done = append(h.done, c)
+	return notify(historyMsg{text: "↷ Redid " + c.String()})
+}
+
+// undoKeys handles the undo and redo keys for screens that offer them. It
+// reports whether key was one of them.
+func (a *app) undoKeys(key tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case a.is(key, "undo"):
+		return a.undo(), true
+	case a.is(key, "redo"):
+		return a.redo(), true
+	}
+	return nil, false
+}
+
+// deletedHint is the status shown after a deletion that can be undone.
+func (a *app) deletedHint(name string) string {
+	return fmt.Sprintf("Deleted %q • %s to undo", name, a.keyHint("undo"))
+}
+
+// trashedHint is the status shown after moving something to the trash.
+func (a *app) trashedHint(name string) string {
+	return fmt.Sprintf("Moved %q to the trash • %s to undo", name, a.keyHint("undo"))
+}
+
+func notify(msg tea.Msg) tea.Cmd {
+	return func() tea.Msg { return msg }
+}
+
+// deleteTrip moves a trip with everything recorded on it to the trash.
+type deleteTrip struct {
+	trip    *models.Trip
+	trashed *models.Trashed
+}
+
+func (c *deleteTrip) do(ctx context.Context, s *storage.Store) (err error) {
+	c.trashed, err = s.TrashTrip(ctx, c.trip.ID)
+	return err
+}
+
+func (c *deleteTrip) undo(ctx context.Context, s *storage.Store) error {
+	return s.RestoreTrashed(ctx, c.trashed.ID)
+}
+
+func (c *deleteTrip) String() string { return fmt.Sprintf("delete trip %q", c.trip.Title) }
+
+// deleteEntry moves a journal entry with its attachments to the trash,
+// unlinking the tracks recorded with it.
+type deleteEntry struct {
+	entry   *models.Entry
+	trashed *models.Trashed
+}
+
+func (c *deleteEntry) do(ctx context.Context, s *storage.Store) (err error) {
+	c.trashed, err = s.TrashEntry(ctx, c.entry.ID)
+	return err
+}
+
+func (c *deleteEntry) undo(ctx context.Context, s *storage.Store) error {
+	return s.RestoreTrashed(ctx, c.trashed.ID)
+}
+
+func (c *deleteEntry) String() string { return fmt.Sprintf("delete entry %q", c.entry.Title) }
+
+// archiveTrip archives a trip, or with archive unset takes it out of the
+// archive.
+type archiveTrip struct {
+	trip    *models.Trip
+	archive bool
+}
+
+func (c archiveTrip) do(ctx context.Context, s *storage.Store) error { return c.set(ctx, s, c.archive) }
+func (c archiveTrip) undo(ctx context.Context, s *storage.Store) error {
+	return c.set(ctx, s, !c.archive)
+}
+
+func (c archiveTrip) set(ctx context.Context, s *storage.Store, archived bool) error {
+	c.trip.ArchivedAt = nil
+	if archived {
+		now := time.Now()
+		c.trip.ArchivedAt = &now
+	}
+	return s.SaveTrip(ctx, c.trip)
+}
+
+func (c archiveTrip) String() string {
+	if c.archive {
+		return fmt.Sprintf("archive trip %q", c.trip.Title)
+	}
+	return fmt.Sprintf("unarchive trip %q", c.trip.Title)
+}
+
+// deleteLeg deletes a leg of a trip, leaving the entries, expenses and
+// lodgings attributed to it without a leg.
+type deleteLeg struct {
+	leg      *models.Leg
+	entries  []*models.Entry
+	expenses []*models.Expense
+	lodgings []*models.Lodging
+}
+
+// newDeleteLeg snapshots what is attributed to the leg so that deleting it
+// can be undone.
+func newDeleteLeg(ctx context.Context, s *storage.Store, l *models.Leg) (*deleteLeg, error) {
+	c := &deleteLeg{leg: l}
+	entries, err := s.ListEntriesByTrip(ctx, l.TripID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.LegID == l.ID {
+			c.entries = append(c.entries, e)
+		}
+	}
+	expenses, err := s.ListExpensesByTrip(ctx, l.TripID)
+	if err != nil {
+		return nil, err
+	}
+	for _, x := range expenses {
+		if x.LegID == l.ID {
+			c.expenses = append(c.expenses, x)
+		}
+	}
+	lodgings, err := s.ListLodgingsByTrip(ctx, l.TripID)
+	if err != nil {
+		return nil, err
+	}
+	for _, lg := range lodgings {
+		if lg.LegID == l.ID {
+			c.lodgings = append(c.lodgings, lg)
+		}
+	}
+	return c, nil
+}
+
+func (c *deleteLeg) do(ctx context.Context, s *storage.Store) error {
+	return s.DeleteLeg(ctx, c.leg.ID)
+}
+
+func (c *deleteLeg) undo(ctx context.Context, s *storage.Store) error {
+	if err := s.SaveLeg(ctx, c.leg); err != nil {
+		return err
+	}
+	for _, e := range c.entries {
+		if err := s.SaveEntry(ctx, e); err != nil {
+			return err
+		}
+	}
+	for _, x := range c.expenses {
+		if err := s.SaveExpense(ctx, x); err != nil {
+			return err
+		}
+	}
+	for _, lg := range c.lodgings {
+		if err := s.SaveLodging(ctx, lg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *deleteLeg) String() string { return fmt.Sprintf("delete leg %q", c.leg.Location) }
+
+// removeAttachment removes a file from a journal entry.
+type removeAttachment struct {
+	attachment *models.Attachment
+}
+
+func (c removeAttachment) do(ctx context.Context, s *storage.Store) error {
+	if err := s.TrashAttachment(c.attachment); err != nil {
+		return err
+	}
+	return s.DeleteAttachment(ctx, c.attachment.ID)
+}
+
+func (c removeAttachment) undo(ctx context.Context, s *storage.Store) error {
+	return s.RestoreAttachment(ctx, c.attachment)
+}
+
+func (c removeAttachment) String() string {
+	return fmt.Sprintf("remove attachment %q", c.attachment.Name)
+}
+
+// removeDocument removes a document from a trip.
+type removeDocument struct {
+	document *models.Document
+}
+
+func (c removeDocument) do(ctx context.Context, s *storage.Store) error {
+	if err := s.TrashDocument(c.document); err != nil {
+		return err
+	}
+	return s.DeleteDocument(ctx, c.document.ID)
+}
+
+func (c removeDocument) undo(ctx context.Context, s *storage.Store) error {
+	return s.RestoreDocument(ctx, c.document)
+}
+
+func (c removeDocument) String() string {
+	return fmt.Sprintf("remove document %q", c.document.Title)
+}
+
+// deleteExpense moves an expense to the trash.
+type deleteExpense struct {
+	expense *models.Expense
+	trashed *models.Trashed
+}
+
+func (c *deleteExpense) do(ctx context.Context, s *storage.Store) (err error) {
+	c.trashed, err = s.TrashExpense(ctx, c.expense.ID)
+	return err
+}
+
+func (c *deleteExpense) undo(ctx context.Context, s *storage.Store) error {
+	return s.RestoreTrashed(ctx, c.trashed.ID)
+}
+
+func (c *deleteExpense) String() string {
+	return fmt.Sprintf("delete expense %q", c.expense.Description)
+}
+
+// deleteItineraryItem deletes an itinerary item. Its documents stay with
+// the trip, and are the item's again on undo.
+type deleteItineraryItem struct {
+	item      *models.ItineraryItem
+	documents []*models.Document
+}
+
+func (c *deleteItineraryItem) do(ctx context.Context, s *storage.Store) (err error) {
+	if c.documents, err = s.ListDocumentsByItem(ctx, c.item.ID); err != nil {
+		return err
+	}
+	return s.DeleteItineraryItem(ctx, c.item.ID)
+}
+
+func (c *deleteItineraryItem) undo(ctx context.Context, s *storage.Store) error {
+	if err := s.SaveItineraryItem(ctx, c.item); err != nil {
+		return err
+	}
+	for _, d := range c.documents {
+		if err := s.SaveDocument(ctx, d); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *deleteItineraryItem) String() string {
+	return fmt.Sprintf("delete itinerary item %q", c.item.Title)
+}
+
+// deleteTrack deletes a GPS track.
+type deleteTrack struct {
+	track *models.Track
+}
+
+func (c deleteTrack) do(ctx context.Context, s *storage.Store) error {
+	return s.DeleteTrack(ctx, c.track.ID)
+}
+func (c deleteTrack) undo(ctx context.Context, s *storage.Store) error {
+	return s.SaveTrack(ctx, c.track)
+}
+func (c deleteTrack) String() string { return fmt.Sprintf("delete track %q", c.track.Name) }
+
+// deleteTemplate deletes a trip template.
+type deleteTemplate struct {
+	template *models.Template
+}
+
+func (c deleteTemplate) do(ctx context.Context, s *storage.Store) error {
+	return s.DeleteTemplate(ctx, c.template.ID)
+}
+func (c deleteTemplate) undo(ctx context.Context, s *storage.Store) error {
+	return s.SaveTemplate(ctx, c.template)
+}
+func (c deleteTemplate) String() string {
+	return fmt.Sprintf("delete template %q", c.template.Name)
+}
+
+// deleteHighlight removes a goal or highlight from its trip.
+type deleteHighlight struct {
+	highlight *models.Highlight
+}
+
+func (c deleteHighlight) do(ctx context.Context, s *storage.Store) error {
+	return s.DeleteHighlight(ctx, c.highlight.ID)
+}
+
+// undo saves the highlight back, which puts it last, and then moves it to
+// where it was.
+func (c deleteHighlight) undo(ctx context.Context, s *storage.Store) error {
+	h := *c.highlight
+	if err := s.SaveHighlight(ctx, &h); err != nil {
+		return err
+	}
+	return s.MoveHighlight(ctx, &h, c.highlight.Position)
+}
+func (c deleteHighlight) String() string {
+	return fmt.Sprintf("remove %q from the highlights", c.highlight.Title)
+}
+
+// deletePrepTask removes a task from a trip's checklist.
+type deletePrepTask struct {
+	task *models.PrepTask
+}
+
+func (c deletePrepTask) do(ctx context.Context, s *storage.Store) error {
+	return s.DeletePrepTask(ctx, c.task.ID)
+}
+func (c deletePrepTask) undo(ctx context.Context, s *storage.Store) error {
+	p := *c.task
+	return s.SavePrepTask(ctx, &p)
+}
+func (c deletePrepTask) String() string {
+	return fmt.Sprintf("remove %q from the checklist", c.task.Title)
+}
+
+// deleteLodging removes a lodging with the expense of its cost.
+type deleteLodging struct {
+	lodging *models.Lodging
+	expense *models.Expense
+}
+
+// newDeleteLodging snapshots the expense of the lodging's cost so that
+// deleting it can be undone.
+func newDeleteLodging(ctx context.Context, s *storage.Store, l *models.Lodging) (*deleteLodging, error) {
+	c := &deleteLodging{lodging: l}
+	if l.ExpenseID != "" {
+		x, err := s.GetExpense(ctx, l.ExpenseID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		c.expense = x
+	}
+	return c, nil
+}
+
+func (c *deleteLodging) do(ctx context.Context, s *storage.Store) error {
+	return s.DeleteLodging(ctx, c.lodging.ID)
+}
+
+// undo saves the expense back before the lodging, which then keeps it.
+func (c *deleteLodging) undo(ctx context.Context, s *storage.Store) error {
+	if c.expense != nil {
+		if err := s.SaveExpense(ctx, c.expense); err != nil {
+			return err
+		}
+	}
+	return s.SaveLodging(ctx, c.lodging)
+}
+
+func (c *deleteLodging) String() string { return fmt.Sprintf("remove lodging %q", c.lodging.Name) }
+
+// deletePerson deletes a person, leaving their name on trips.
+type deletePerson struct {
+	person *models.Person
+}
+
+func (c deletePerson) do(ctx context.Context, s *storage.Store) error {
+	return s.DeletePerson(ctx, c.person.ID)
+}
+func (c deletePerson) undo(ctx context.Context, s *storage.Store) error {
+	return s.SavePerson(ctx, c.person)
+}
+func (c deletePerson) String() string { return "delete " + c.person.Name }
+
+// deleteCountryNote deletes the note on a country.
+type deleteCountryNote struct {
+	note *models.CountryNote
+}
+
+func (c deleteCountryNote) do(ctx context.Context, s *storage.Store) error {
+	return s.DeleteCountryNote(ctx, c.note.Country)
+}
+func (c deleteCountryNote) undo(ctx context.Context, s *storage.Store) error {
+	return s.SaveCountryNote(ctx, c.note)
+}
+func (c deleteCountryNote) String() string {
+	return "delete the note on " + places.CountryName(c.note.Country)
+}
+
+// deleteWish takes a place off the wishlist.
+type deleteWish struct {
+	wish *models.Wish
+}
+
+func (c deleteWish) do(ctx context.Context, s *storage.Store) error {
+	return s.DeleteWish(ctx, c.wish.ID)
+}
+func (c deleteWish) undo(ctx context.Context, s *storage.Store) error {
+	return s.SaveWish(ctx, c.wish)
+}
+func (c deleteWish) String() string {
+	return fmt.Sprintf("take %s off the wishlist", c.wish.Place)
+}
+
+// deletePackingItem removes an item from a trip's packing list.
+type deletePackingItem struct {
+	item *models.PackingItem
+}
+
+func (c deletePackingItem) do(ctx context.Context, s *storage.Store) error {
+	return s.DeletePackingItem(ctx, c.item.ID)
+}
+func (c deletePackingItem) undo(ctx context.Context, s *storage.Store) error {
+	return s.SavePackingItem(ctx, c.item)
+}
+func (c deletePackingItem) String() string {
+	return fmt.Sprintf("remove %q from the packing list", c.item.Name)
+}
+
+// deletePackingList deletes a master packing list.
+type deletePackingList struct {
+	list *models.PackingList
+}
+
+func (c deletePackingList) do(ctx context.Context, s *storage.Store) error {
+	return s.DeletePackingList(ctx, c.list.ID)
+}
+func (c deletePackingList) undo(ctx context.Context, s *storage.Store) error {
+	return s.SavePackingList(ctx, c.list)
+}
+func (c deletePackingList) String() string {
+	return fmt.Sprintf("delete packing list %q", c.list.Name)
+}
+
+// deleteRecurrence deletes a recurring expense. The expenses it recorded
+// stay, no longer linked to it when it is restored.
+type deleteRecurrence struct {
+	recurrence *models.Recurrence
+}
+
+func (c deleteRecurrence) do(ctx context.Context, s *storage.Store) error {
+	return s.DeleteRecurrence(ctx, c.recurrence.ID)
+}
+func (c deleteRecurrence) undo(ctx context.Context, s *storage.Store) error {
+	return s.SaveRecurrence(ctx, c.recurrence)
+}
+func (c deleteRecurrence) String() string {
+	return fmt.Sprintf("delete recurring expense %q", c.recurrence.Description)
+}
+
+// batch applies several commands as one change, such as deleting every
+// item marked in a list, and undoes them together.
+type batch struct {
+	commands []command
+	desc     string
+}
+
+func (c batch) do(ctx context.Context, s *storage.Store) error {
+	for i, cmd := range c.commands {
+		if err := cmd.do(ctx, s); err != nil {
+			// What was done is undone, for the batch not to land halfway.
+			return errors.Join(err, batch{commands: c.commands[:i]}.undo(ctx, s))
+		}
+	}
+	return nil
+}
+
+func (c batch) undo(ctx context.Context, s *storage.Store) error {
+	for i := len(c.commands) - 1; i >= 0; i-- {
+		if err := c.commands[i].undo(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c batch) String() string { return c.desc }
+
+// saveExpenses saves changed copies of expenses, and the expenses as they
+// were again on undo.
+type saveExpenses struct {
+	before, after []*models.Expense
+	desc          string
+}
+
+func (c saveExpenses) do(ctx context.Context, s *storage.Store) error {
+	return saveEach(ctx, c.after, s.SaveExpense)
+}
+
+func (c saveExpenses) undo(ctx context.Context, s *storage.Store) error {
+	return saveEach(ctx, c.before, s.SaveExpense)
+}
+
+func (c saveExpenses) String() string { return c.desc }
+
+// saveEntries saves changed copies of journal entries, and the entries as
+// they were again on undo.
+type saveEntries struct {
+	before, after []*models.Entry
+	desc          string
+}
+
+func (c saveEntries) do(ctx context.Context, s *storage.Store) error {
+	return saveEach(ctx, c.after, s.SaveEntry)
+}
+
+func (c saveEntries) undo(ctx context.Context, s *storage.Store) error {
+	return saveEach(ctx, c.before, s.SaveEntry)
+}
+
+func (c saveEntries) String() string { return c.desc }
+
+func saveEach[T any](ctx context.Context, items []T, save func(context.Context, T) error) error {
+	for _, item := range items {
+		if err := save(ctx, item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
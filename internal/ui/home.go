@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/budget"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+// homeRecentEntries is how many of the latest journal entries the home
+// screen lists.
+const homeRecentEntries = 3
+
+// homeOnThisDay is how many of the entries written on this day in earlier
+// years the home screen recalls.
+const homeOnThisDay = 3
+
+// homeWideWidth is the width from which the home screen sets its
+// dashboard beside the menu rather than under it.
+const homeWideWidth = 96
+
+// dashboard is what the home screen shows beside its menu, assembled from
+// the other screens' records: the trip in progress or coming up, the
+// latest journal entries and how that trip's spending stands against its
+// budget. Each part that cannot be read is left empty, as the header's
+// trip status is.
+type dashboard struct {
+	status tripStatus
+	// trips is every trip, to name those of the entries.
+	trips   map[string]*models.Trip
+	entries []*models.Entry
+	// onThisDay are the entries written on today's date in earlier years,
+	// the most recent first.
+	onThisDay []*models.Entry
+	// expenses are those of the trip in progress or coming up.
+	expenses []*models.Expense
+	rates    *currency.Rates
+	ratesErr error
+	// noTrips is set when there are no trips at all yet.
+	noTrips bool
+}
+
+// trip is the trip the dashboard is about: the one in progress, else the
+// next one planned, or nil.
+func (d dashboard) trip() *models.Trip {
+	if d.status.active != nil {
+		return d.status.active
+	}
+	return d.status.next
+}
+
+// loadDashboard reads what the home screen shows of trips.
+func (a *app) loadDashboard(trips []*models.Trip) dashboard {
+	d := dashboard{status: a.tripStatus(), trips: map[string]*models.Trip{}, noTrips: len(trips) == 0}
+	for _, t := range trips {
+		d.trips[t.ID] = t
+	}
+	if n, err := a.store.CountEntries(a.ctx, storage.EntryQuery{}); err == nil && n > 0 {
+		entries, err := a.store.ListEntryPage(a.ctx, storage.EntryQuery{}, max(n-homeRecentEntries, 0), homeRecentEntries)
+		if err == nil {
+			// Newest first.
+			for i := len(entries) - 1; i >= 0; i-- {
+				d.entries = append(d.entries, entries[i])
+			}
+		}
+	}
+	if entries, err := a.store.ListEntriesOnThisDay(a.ctx, time.Now()); err == nil {
+		d.onThisDay = entries[:min(len(entries), homeOnThisDay)]
+	}
+	if t := d.trip(); t != nil {
+		d.expenses, _ = a.store.ListExpensesByTrip(a.ctx, t.ID)
+	}
+	return d
+}
+
+// budgetReport is the spending of the dashboard's trip, converted with the
+// exchange rates once loaded.
+func (d dashboard) budgetReport(a *app) budget.Report {
+	var convert budget.Converter
+	if d.rates != nil {
+		convert = d.rates.Convert
+	}
+	return budget.Compute(d.trip(), d.expenses, a.budgetCurrency(d.trip()), convert, time.Now())
+}
+
+// needsRates reports whether the trip's spending has expenses in other
+// currencies that the exchange rates would count.
+func (d dashboard) needsRates(a *app) bool {
+	return d.rates == nil && d.ratesErr == nil && d.trip() != nil && d.budgetReport(a).Unconverted > 0
+}
+
+// countdownView tells of the trip in progress or how long until the next.
+func (d dashboard) countdownView(a *app) string {
+	var b strings.Builder
+	heading := tr("Next trip")
+	if d.status.active != nil {
+		heading = tr("Trip in progress")
+	}
+	b.WriteString(labelStyle.Render(heading) + "\n")
+	t := d.trip()
+	switch {
+	case d.noTrips:
+		b.WriteString(hintStyle.Render(tr("No trips yet — choose New Trip to plan your first.")) + "\n")
+		return b.String()
+	case t == nil:
+		b.WriteString(hintStyle.Render(tr("Nothing planned — choose New Trip to plan the next one.")) + "\n")
+		return b.String()
+	}
+	b.WriteString(d.status.badge() + "\n")
+	dates := a.formatDate(t.StartDate)
+	if t.EndDate != nil {
+		dates += " → " + a.formatDate(*t.EndDate)
+	}
+	line := t.Title + " · " + dates
+	if d.status.active != nil && d.status.days > 0 {
+		if left := d.status.days - d.status.day; left > 0 {
+			line += " · " + tr("%s to go", plural(left, "day", "days"))
+		} else {
+			line += " · " + tr("last day")
+		}
+	}
+	b.WriteString(hintStyle.Render(line) + "\n")
+	return b.String()
+}
+
+// entriesView lists the latest journal entries.
+func (d dashboard) entriesView(a *app, width int) string {
+	var b strings.Builder
+	b.WriteString(labelStyle.Render(tr("Recent entries")) + "\n")
+	if len(d.entries) == 0 {
+		b.WriteString(hintStyle.Render(tr("No journal entries yet — write one from View Journal.")) + "\n")
+		return b.String()
+	}
+	for _, e := range d.entries {
+		title := e.Title
+		if title == "" {
+			title = tr("Untitled")
+		}
+		trip := ""
+		if t := d.trips[e.TripID]; t != nil {
+			trip = " · " + t.Title
+		}
+		day := a.formatDate(e.Timestamp)
+		title = truncate(title, max(width-len(day)-len(trip)-4, 10))
+		fmt.Fprintf(&b, "📔 %s %s%s\n", hintStyle.Render(day), title, hintStyle.Render(trip))
+	}
+	return b.String()
+}
+
+// onThisDayView recalls the entries written on today's date in earlier
+// years, with the first lines of each.
+func (d dashboard) onThisDayView(a *app, width int) string {
+	var b strings.Builder
+	b.WriteString(labelStyle.Render(tr("On this day")) + "\n")
+	now := time.Now()
+	for _, e := range d.onThisDay {
+		title := e.Title
+		if title == "" {
+			title = tr("Untitled")
+		}
+		trip := ""
+		if t := d.trips[e.TripID]; t != nil {
+			trip = " · " + t.Title
+		}
+		ago := tr("%d years ago", e.YearsAgo(now))
+		if e.YearsAgo(now) == 1 {
+			ago = tr("a year ago")
+		}
+		title = truncate(title, max(width-len(ago)-len(trip)-4, 10))
+		fmt.Fprintf(&b, "🕰️  %s %s%s\n", hintStyle.Render(ago), title, hintStyle.Render(trip))
+		if line := firstLine(e.Text); line != "" {
+			b.WriteString(hintStyle.Render("   "+truncate(line, max(width-4, 10))) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// spendView shows the spending of the trip in progress or coming up
+// against its budget.
+func (d dashboard) spendView(a *app) string {
+	var b strings.Builder
+	b.WriteString(labelStyle.Render(tr("Spending")) + "\n")
+	t := d.trip()
+	if t == nil {
+		b.WriteString(hintStyle.Render(tr("Shows here for the trip in progress or coming up.")) + "\n")
+		return b.String()
+	}
+	r := d.budgetReport(a)
+	switch {
+	case len(d.expenses) == 0 && r.Total.Limit > 0:
+		b.WriteString(tr("Nothing spent yet of %s", formatAmount(r.Total.Limit, r.Currency)) + "\n")
+	case len(d.expenses) == 0:
+		b.WriteString(hintStyle.Render(tr("Nothing spent yet, and no budget set.")) + "\n")
+	case r.Total.Limit > 0:
+		line := fmt.Sprintf("%s %3.0f%%  %s / %s", budgetBar(r.Total), r.Total.Ratio()*100,
+			formatAmount(r.Total.Spent, r.Currency), formatAmount(r.Total.Limit, r.Currency))
+		b.WriteString(line + "\n")
+		switch r.Total.Level() {
+		case budget.Over:
+			b.WriteString(errorStyle.Render("⛔ "+tr("over by %s", formatAmount(r.Total.Spent-r.Total.Limit, r.Currency))) + "\n")
+		case budget.Warning:
+			b.WriteString(warningStyle.Render("⚠ "+tr("close to the limit")) + "\n")
+		}
+	default:
+		b.WriteString(tr("%s spent, no budget set", formatAmount(r.Total.Spent, r.Currency)) + "\n")
+	}
+	if r.Unconverted > 0 {
+		note := tr("%s in other currencies not counted", plural(r.Unconverted, "expense", "expenses"))
+		if d.rates == nil && d.ratesErr == nil && a.rates != nil {
+			note = tr("%s in other currencies, loading exchange rates…", plural(r.Unconverted, "expense", "expenses"))
+		}
+		b.WriteString(hintStyle.Render(note) + "\n")
+	}
+	return b.String()
+}
+
+// firstLine is the first line of the text of Markdown text, past its
+// headings, images and tables, without the markup leading it.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "![") || strings.HasPrefix(line, "|") {
+			continue
+		}
+		return strings.TrimLeft(line, ">-*+ ")
+	}
+	return ""
+}
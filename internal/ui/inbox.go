@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/inbox"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/viewer"
+)
+
+// inboxDir is the receipts inbox configured, or "" without one.
+func (a *app) inboxDir() string {
+	return expandHome(strings.TrimSpace(a.cfg.ReceiptInbox))
+}
+
+// scanInbox looks in the receipts inbox in the background for the
+// receipts waiting there. It is looked at again with every draft tick, so
+// files dropped in show up in the expense list within seconds.
+func (a *app) scanInbox() tea.Cmd {
+	dir := a.inboxDir()
+	if dir == "" || a.store == nil || a.readOnly() {
+		return nil
+	}
+	return func() tea.Msg {
+		receipts, err := inbox.List(dir)
+		return inboxMsg{receipts: receipts, err: err}
+	}
+}
+
+// receiptInbox is the queue of receipts waiting in the receipts inbox.
+// Each is turned into an expense of the trip, read with OCR when a reader
+// is set up and kept with the expense once saved, or dismissed; either way
+// the file is moved out of the inbox into one of its subfolders.
+type receiptInbox struct {
+	app      *app
+	trip     *models.Trip
+	currency string // when a receipt names none
+	receipts []inbox.Receipt
+	cursor   int
+
+	// reading is the receipt being read with OCR.
+	reading string
+	status  string
+	err     error
+}
+
+func newReceiptInbox(app *app, trip *models.Trip, currency string, receipts []inbox.Receipt) receiptInbox {
+	return receiptInbox{app: app, trip: trip, currency: currency, receipts: receipts}
+}
+
+func (q receiptInbox) Title() string { return tr("Receipts inbox") }
+
+func (q receiptInbox) currentTrip() *models.Trip { return q.trip }
+
+func (q receiptInbox) Init() tea.Cmd {
+	return q.app.scanInbox()
+}
+
+func (q receiptInbox) help() []key.Binding {
+	return []key.Binding{
+		q.app.bind("up", "previous receipt"),
+		q.app.bind("down", "next receipt"),
+		q.app.bind("select", "turn the receipt into an expense"),
+		q.app.bind("open", "open in the default viewer"),
+		q.app.bind("delete", "dismiss the receipt"),
+	}
+}
+
+func (q receiptInbox) selected() *inbox.Receipt {
+	if len(q.receipts) == 0 {
+		return nil
+	}
+	return &q.receipts[q.cursor]
+}
+
+func (q receiptInbox) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case inboxMsg:
+		q.receipts, q.err = msg.receipts, msg.err
+		q.cursor = clamp(q.cursor, 0, len(q.receipts)-1)
+		return q, nil
+	case expenseSavedMsg:
+		// The receipt kept with it has been filed away.
+		return q, q.app.scanInbox()
+	case receiptReadMsg:
+		if msg.path != q.reading {
+			return q, nil
+		}
+		q.reading = ""
+		if msg.err != nil {
+			// The expense is typed in by hand, the receipt still kept.
+			return q, tea.Batch(push(q.form(q.blank(), msg.path)),
+				toast(toastWarning, "Could not read %s: %v", filepath.Base(msg.path), msg.err))
+		}
+		return q, push(q.form(q.app.receiptExpense(q.trip, q.currency, msg.text), msg.path))
+	case tea.KeyMsg:
+		if q.reading != "" {
+			return q, nil
+		}
+		q.status, q.err = "", nil
+		switch {
+		case q.app.is(msg, "up"):
+			if q.cursor > 0 {
+				q.cursor--
+			}
+		case q.app.is(msg, "down"):
+			if q.cursor < len(q.receipts)-1 {
+				q.cursor++
+			}
+		case q.app.is(msg, "select"):
+			if r := q.selected(); r != nil {
+				return q.turn(*r)
+			}
+		case q.app.is(msg, "open"):
+			if r := q.selected(); r != nil {
+				if err := viewer.Open(r.Path); err != nil {
+					q.err = fmt.Errorf("open %s: %w", r.Name, err)
+				} else {
+					q.status = "Opened " + r.Name
+				}
+			}
+		case q.app.is(msg, "delete"):
+			if r := q.selected(); r != nil {
+				if _, err := inbox.Dismiss(q.app.inboxDir(), r.Path); err != nil {
+					q.err = err
+					return q, nil
+				}
+				q.status = fmt.Sprintf("Dismissed %s into %s", r.Name, filepath.Join(q.app.inboxDir(), inbox.DismissedDir))
+				return q, q.app.scanInbox()
+			}
+		}
+	}
+	return q, nil
+}
+
+// turn opens the expense form on the receipt r, read in the background
+// first when there is an OCR reader.
+func (q receiptInbox) turn(r inbox.Receipt) (tea.Model, tea.Cmd) {
+	if q.app.ocr == nil {
+		return q, push(q.form(q.blank(), r.Path))
+	}
+	q.reading = r.Path
+	reader, language, ctx := q.app.ocr, q.app.cfg.OCRLanguage, q.app.ctx
+	return q, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		text, err := reader.Read(ctx, r.Path, language)
+		return receiptReadMsg{path: r.Path, text: text, err: err}
+	}
+}
+
+// blank is a new expense to type in by hand from a receipt not read.
+func (q receiptInbox) blank() *models.Expense {
+	return models.NewExpense(q.trip.ID, 0, q.currency, "", "", q.app.tripNow(q.trip))
+}
+
+// form is the expense form on x from the receipt at path, filed away out
+// of the inbox once the expense is saved.
+func (q receiptInbox) form(x *models.Expense, path string) expenseForm {
+	f := newReceiptForm(q.app, x, path)
+	f.inboxed = true
+	return f
+}
+
+func (q receiptInbox) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🧾 "+tr("Receipts inbox")) + "\n")
+	b.WriteString(hintStyle.Render(q.app.inboxDir()) + "\n\n")
+	if len(q.receipts) == 0 {
+		b.WriteString("No receipts waiting — photos and PDFs saved into the folder show up here.\n")
+	}
+	for i, r := range q.receipts {
+		cursor := "  "
+		if i == q.cursor {
+			cursor = "👉"
+		}
+		note := formatSize(r.Size) + ", " + q.app.formatDate(r.Modified)
+		if r.Path == q.reading {
+			note += ", reading…"
+		}
+		fmt.Fprintf(&b, "%s %s  %s\n", cursor, r.Name, hintStyle.Render(note))
+	}
+	if q.err != nil {
+		b.WriteString("\n" + errorStyle.Render(q.err.Error()) + "\n")
+	}
+	if q.status != "" {
+		b.WriteString("\n" + q.status + "\n")
+	}
+	hint := "enter turn into an expense • " + q.app.keyHint("open") + " open • " +
+		q.app.keyHint("delete") + " dismiss • esc back"
+	b.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return b.String()
+}
@@ -0,0 +1,565 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/pkg/holidays"
+)
+
+// maxItineraryDays bounds the day strip for trips with far-off items.
+const maxItineraryDays = 366
+
+// itineraryView is a day-by-day timeline of a trip's planned activities.
+type itineraryView struct {
+	app      *app
+	trip     *models.Trip
+	items    []*models.ItineraryItem
+	checkIns []*models.CheckIn
+	legs     []*models.Leg
+	// lodgings are checked into and out of on the days they are due.
+	lodgings []*models.Lodging
+	days     []time.Time
+	day      int // index into days
+	// cursor indexes the items of the current day.
+	cursor int
+	// documents counts the documents kept with each item.
+	documents map[string]int
+	// holidays are the public holidays where the trip is, by date.
+	holidays map[string][]holidays.Holiday
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newItineraryView(app *app, trip *models.Trip) itineraryView {
+	v := itineraryView{app: app, trip: trip}
+	v.reload()
+	if today := dateOf(app.tripNow(trip)); len(v.days) > 0 {
+		for i, d := range v.days {
+			if d.Equal(today) {
+				v.day = i
+			}
+		}
+	}
+	return v
+}
+
+func (v itineraryView) Title() string { return v.trip.Title }
+
+func (v itineraryView) currentTrip() *models.Trip { return v.trip }
+
+func (v itineraryView) resume() (models.SessionScreen, bool) {
+	s := models.SessionScreen{Kind: models.SessionItinerary, Title: v.Title(), ID: v.trip.ID, Cursor: v.cursor}
+	if len(v.days) > 0 {
+		s.Day = v.days[v.day].Format(time.DateOnly)
+	}
+	return s, true
+}
+
+func (v itineraryView) Init() tea.Cmd {
+	return v.fetchHolidays()
+}
+
+// holidaysMsg carries the public holidays on the days of a trip, by date.
+type holidaysMsg struct {
+	tripID string
+	days   map[string][]holidays.Holiday
+}
+
+// fetchHolidays looks up in the background the public holidays of the
+// country the trip is in on each of its days, asking the provider once a
+// country and year. Holidays are a nicety, so a country the provider does
+// not know simply has none flagged.
+func (v itineraryView) fetchHolidays() tea.Cmd {
+	if v.app.holidays == nil || len(v.days) == 0 {
+		return nil
+	}
+	countries := make([]string, len(v.days))
+	for i, d := range v.days {
+		if p, ok := places.DayPlace(v.trip, v.legs, "", d); ok {
+			countries[i] = p.Country
+		}
+	}
+	id, days, provider, ctx := v.trip.ID, v.days, v.app.holidays, v.app.ctx
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		years := map[string][]holidays.Holiday{}
+		found := map[string][]holidays.Holiday{}
+		for i, d := range days {
+			if countries[i] == "" {
+				continue
+			}
+			key := fmt.Sprintf("%s@%d", countries[i], d.Year())
+			all, ok := years[key]
+			if !ok {
+				all, _ = provider.Year(ctx, countries[i], d.Year())
+				years[key] = all
+			}
+			date := d.Format(holidays.DayLayout)
+			for _, h := range all {
+				if h.Date == date {
+					found[date] = append(found[date], h)
+				}
+			}
+		}
+		return holidaysMsg{tripID: id, days: found}
+	}
+}
+
+func (v itineraryView) capturesEsc() bool { return v.confirmDelete }
+
+func (v itineraryView) help() []key.Binding {
+	return append([]key.Binding{
+		v.app.bind("left", "previous day"),
+		v.app.bind("right", "next day"),
+		v.app.bind("up", "previous item"),
+		v.app.bind("down", "next item"),
+		v.app.bind("new", "plan an item on this day"),
+		v.app.bind("flight", "add a flight between two airports on this day"),
+		v.app.bind("import", "import flights from a booking confirmation"),
+		v.app.bind("edit", "edit the item"),
+		v.app.bind("delete", "delete the item"),
+		v.app.bind("attachments", "the item's tickets and bookings"),
+		v.app.bind("move_up", "move the item earlier"),
+		v.app.bind("move_down", "move the item later"),
+		v.app.bind("checkin", "check in at a place now"),
+		v.app.bind("toggle", "start the item now, or finish it once started"),
+	}, v.app.undoHelp()...)
+}
+
+func (v *itineraryView) reload() {
+	if v.items, v.err = v.app.store.ListItineraryByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	if v.checkIns, v.err = v.app.store.ListCheckInsByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	if v.legs, v.err = v.app.store.ListLegsByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	if v.lodgings, v.err = v.app.store.ListLodgingsByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	documents, err := v.app.store.ListDocumentsByTrip(v.app.ctx, v.trip.ID)
+	if err != nil {
+		v.err = err
+		return
+	}
+	v.documents = map[string]int{}
+	for _, d := range documents {
+		if d.ItemID != "" {
+			v.documents[d.ItemID]++
+		}
+	}
+	v.days = itineraryDays(v.trip, v.items, v.checkIns)
+	v.day = clamp(v.day, 0, len(v.days)-1)
+	v.cursor = clamp(v.cursor, 0, len(v.today())-1)
+}
+
+// today returns the items of the selected day in display order.
+func (v itineraryView) today() []*models.ItineraryItem {
+	if len(v.days) == 0 {
+		return nil
+	}
+	return itemsOn(v.items, v.days[v.day])
+}
+
+func (v itineraryView) selected() *models.ItineraryItem {
+	items := v.today()
+	if len(items) == 0 {
+		return nil
+	}
+	return items[v.cursor]
+}
+
+// showDay selects the given calendar day if the trip covers it.
+func (v *itineraryView) showDay(day time.Time) {
+	for i, d := range v.days {
+		if d.Equal(dateOf(day)) {
+			v.day = i
+		}
+	}
+}
+
+func (v itineraryView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case holidaysMsg:
+		if msg.tripID == v.trip.ID {
+			v.holidays = msg.days
+		}
+		return v, nil
+	case itinerarySavedMsg:
+		v.reload()
+		v.showDay(msg.item.Day)
+		for i, it := range v.today() {
+			if it.ID == msg.item.ID {
+				v.cursor = i
+			}
+		}
+		// The item may have widened the trip by a day or more.
+		return v, tea.Batch(toast(toastSuccess, "Saved %q", msg.item.Title), v.fetchHolidays())
+	case checkInSavedMsg:
+		if msg.checkIn.TripID == v.trip.ID {
+			v.status = fmt.Sprintf("Checked in at %q", msg.checkIn.Place)
+			v.reload()
+			v.showDay(msg.checkIn.Timestamp)
+			return v, v.fetchHolidays()
+		}
+		return v, nil
+	case historyMsg:
+		v.status = ""
+		v.reload()
+		return v, v.fetchHolidays()
+	case documentsChangedMsg:
+		if msg.tripID == v.trip.ID {
+			v.reload()
+		}
+		return v, nil
+	case lodgingsChangedMsg:
+		if msg.tripID == v.trip.ID {
+			v.reload()
+		}
+		return v, nil
+	case tea.KeyMsg:
+		if v.confirmDelete {
+			v.confirmDelete = false
+			if msg.String() == "y" {
+				it := v.selected()
+				if err := v.app.run(&deleteItineraryItem{item: it}); err != nil {
+					v.err = err
+				} else {
+					v.status = v.app.deletedHint(it.Title)
+				}
+				v.reload()
+			}
+			return v, nil
+		}
+		if cmd, ok := v.app.undoKeys(msg); ok {
+			return v, cmd
+		}
+
+		switch {
+		case v.app.is(msg, "left"):
+			if v.day > 0 {
+				v.day--
+				v.cursor = 0
+			}
+		case v.app.is(msg, "right"):
+			if v.day < len(v.days)-1 {
+				v.day++
+				v.cursor = 0
+			}
+		case v.app.is(msg, "up"):
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case v.app.is(msg, "down"):
+			if v.cursor < len(v.today())-1 {
+				v.cursor++
+			}
+		case v.app.is(msg, "move_up"):
+			v.move(-1)
+		case v.app.is(msg, "move_down"):
+			v.move(1)
+		case v.app.is(msg, "new"):
+			it := models.NewItineraryItem(v.trip.ID, v.days[v.day], "")
+			return v, push(newItineraryForm(v.app, it, true))
+		case v.app.is(msg, "flight"):
+			return v, push(newFlightForm(v.app, v.trip, v.days[v.day]))
+		case v.app.is(msg, "import"):
+			return v, push(newFlightImport(v.app, v.trip))
+		case v.app.edits(msg, "select"), v.app.is(msg, "edit"):
+			if it := v.selected(); it != nil {
+				return v, push(newItineraryForm(v.app, it, false))
+			}
+		case v.app.is(msg, "attachments"):
+			if it := v.selected(); it != nil {
+				return v, push(newDocumentList(v.app, v.trip, it))
+			}
+		case v.app.is(msg, "delete"):
+			if v.selected() != nil {
+				v.confirmDelete = true
+			}
+		case v.app.edits(msg, "toggle"):
+			if it := v.selected(); it != nil {
+				v.clock(it)
+			}
+		}
+	}
+	return v, nil
+}
+
+// clock marks it started now on the clock of its place, or finished once
+// it has started. Only the items of today are timed so; the form logs
+// when the others started and finished after the fact.
+func (v *itineraryView) clock(it *models.ItineraryItem) {
+	now := models.InZone(time.Now(), places.DayZone(v.trip, v.legs, it.Place, it.Day))
+	switch {
+	case !dateOf(now).Equal(dateOf(it.Day)):
+		v.status = tr("%q is not today: press %s to log when it started and finished", it.Title, v.app.keyHint("edit"))
+		return
+	case it.Finished != "":
+		v.status = tr("%q finished at %s: press %s to change when", it.Title, it.Finished, v.app.keyHint("edit"))
+		return
+	}
+	timed := *it
+	if timed.Started == "" {
+		timed.Started = now.Format(models.TimeLayout)
+		v.status = tr("Started %q at %s", it.Title, timed.Started)
+	} else {
+		timed.Finished = now.Format(models.TimeLayout)
+		took, _ := timed.Took()
+		v.status = tr("Finished %q at %s, after %s", it.Title, timed.Finished, models.FormatDuration(took))
+	}
+	if v.err = v.app.store.SaveItineraryItem(v.app.ctx, &timed); v.err != nil {
+		v.status = ""
+		return
+	}
+	v.reload()
+}
+
+// move shifts the selected item by delta places within its day.
+func (v *itineraryView) move(delta int) {
+	items := v.today()
+	to := v.cursor + delta
+	if len(items) == 0 || to < 0 || to >= len(items) {
+		return
+	}
+	items[v.cursor], items[to] = items[to], items[v.cursor]
+	ids := make([]string, len(items))
+	for i, it := range items {
+		ids[i] = it.ID
+	}
+	if err := v.app.store.ReorderItinerary(v.app.ctx, ids); err != nil {
+		v.err = err
+		return
+	}
+	v.cursor = to
+	v.reload()
+}
+
+func (v itineraryView) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🗺️  "+v.trip.Title) + "\n\n")
+	if v.err != nil {
+		b.WriteString(errorStyle.Render(v.err.Error()) + "\n\n")
+	}
+	if len(v.days) > 0 {
+		day := v.days[v.day]
+		prev, next := "  ", "  "
+		if v.day > 0 {
+			prev = "◀ "
+		}
+		if v.day < len(v.days)-1 {
+			next = " ▶"
+		}
+		fmt.Fprintf(&b, "%s%s %s%s\n\n", prev, labelStyle.Render(tr("Day %d of %d", v.day+1, len(v.days))),
+			hintStyle.Render("· "+loc.Date(day, "Mon")+" "+v.app.formatDate(day)), next)
+		if hs := v.holidays[day.Format(holidays.DayLayout)]; len(hs) > 0 {
+			for _, h := range hs {
+				b.WriteString(warningStyle.Render("⚠ "+tr("%s, a public holiday in %s: museums and shops may close and transport be crowded",
+					h.Name, places.CountryName(h.Country))) + "\n")
+			}
+			b.WriteString("\n")
+		}
+		if notices := models.LodgingNotices(v.lodgings, day); len(notices) > 0 {
+			today := day.Equal(dateOf(v.app.tripNow(v.trip)))
+			for _, n := range notices {
+				b.WriteString(labelStyle.Render(lodgingNotice(n, today)) + "\n")
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	items := v.today()
+	if len(items) == 0 {
+		b.WriteString("Nothing planned — press " + v.app.keyHint("new") + " to add an activity.\n")
+	}
+	for i, it := range items {
+		cursor := "  "
+		if i == v.cursor {
+			cursor = "👉"
+		}
+		at := it.Time
+		if at == "" {
+			at = "     "
+		}
+		fmt.Fprintf(&b, "%s %s ● %s\n", cursor, at, it.Title)
+		var details []string
+		if it.Place != "" {
+			details = append(details, "📍 "+it.Place)
+		}
+		if it.BookingRef != "" {
+			details = append(details, "🎫 "+it.BookingRef)
+		}
+		if it.Alarm > 0 {
+			details = append(details, "⏰ "+models.FormatAlarm(it.Alarm))
+		}
+		if c := documentCount(v.documents, it.ID); c != "" {
+			details = append(details, c)
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(&b, "         │ %s\n", hintStyle.Render(strings.Join(details, " · ")))
+		}
+		if it.Notes != "" {
+			fmt.Fprintf(&b, "         │ %s\n", hintStyle.Render(it.Notes))
+		}
+		if timing := itemTiming(it); timing != "" {
+			fmt.Fprintf(&b, "         │ %s\n", timing)
+		}
+		if i < len(items)-1 {
+			b.WriteString("         │\n")
+		}
+	}
+	if total := dayTiming(items); total != "" {
+		b.WriteString("\n" + total + "\n")
+	}
+	if checkIns := v.checkInsOn(); len(checkIns) > 0 {
+		b.WriteString("\n" + labelStyle.Render("Check-ins") + "\n")
+		for _, c := range checkIns {
+			var details []string
+			if c.Located() {
+				details = append(details, c.Coordinates())
+			}
+			if c.Note != "" {
+				details = append(details, c.Note)
+			}
+			fmt.Fprintf(&b, "   %s 📌 %s %s\n", c.Timestamp.Format("15:04"), c.Place, hintStyle.Render(strings.Join(details, " · ")))
+		}
+	}
+	if v.status != "" {
+		b.WriteString("\n" + v.status + "\n")
+	}
+	if v.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Delete %q? y/n", v.selected().Title)) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("←/→ day • "+v.app.keyHint("new")+" new • "+v.app.keyHint("flight")+" flight • "+v.app.keyHint("import")+" import flights • "+v.app.keyHint("edit")+" edit • "+
+		v.app.keyHint("attachments")+" documents • "+v.app.keyHint("delete")+" delete • "+v.app.keyHint("undo")+" undo • "+
+		v.app.keyHint("move_up")+"/"+v.app.keyHint("move_down")+" reorder • "+v.app.keyHint("toggle")+" start/finish • "+v.app.keyHint("checkin")+" check in • esc back") + "\n")
+	return b.String()
+}
+
+// itemTiming describes how long it is planned to take against how long it
+// took, as "⏱ 09:40–11:55 · took 2h15m of 1h30m (+45m)", running over in
+// the warning style.
+func itemTiming(it *models.ItineraryItem) string {
+	planned := models.FormatDuration(it.Duration)
+	switch {
+	case it.Started == "" && it.Duration > 0:
+		return hintStyle.Render("⏱ " + tr("%s planned", planned))
+	case it.Started == "":
+		return ""
+	case it.Finished == "":
+		return labelStyle.Render("▶ " + tr("started at %s", it.Started))
+	}
+	took, _ := it.Took()
+	s := "⏱ " + it.Started + "–" + it.Finished + " · "
+	over, ok := it.Overran()
+	if !ok {
+		return hintStyle.Render(s + tr("took %s", models.FormatDuration(took)))
+	}
+	s += tr("took %s of %s", models.FormatDuration(took), planned)
+	if over > 0 {
+		return warningStyle.Render(s + " (+" + models.FormatDuration(over) + ")")
+	}
+	return hintStyle.Render(s + " (" + models.FormatDuration(over) + ")")
+}
+
+// dayTiming sums up how long the timed items of a day were planned to take
+// against how long they took, or is empty when none is.
+func dayTiming(items []*models.ItineraryItem) string {
+	var n, planned, took int
+	for _, it := range items {
+		if _, ok := it.Overran(); ok {
+			t, _ := it.Took()
+			n++
+			planned += it.Duration
+			took += t
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	over := took - planned
+	s := tr("Planned %s, took %s", models.FormatDuration(planned), models.FormatDuration(took))
+	if over > 0 {
+		s += " (+" + models.FormatDuration(over) + ")"
+	} else {
+		s += " (" + models.FormatDuration(over) + ")"
+	}
+	return labelStyle.Render(s) + hintStyle.Render(" · "+plural(n, "timed activity", "timed activities"))
+}
+
+// checkInsOn returns the check-ins of the selected day, in the order they
+// were made.
+func (v itineraryView) checkInsOn() []*models.CheckIn {
+	if len(v.days) == 0 {
+		return nil
+	}
+	var out []*models.CheckIn
+	for _, c := range v.checkIns {
+		if dateOf(c.Timestamp).Equal(v.days[v.day]) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// itineraryDays lists every calendar day from the trip's start to its end,
+// widened to cover items planned and check-ins made outside those dates.
+func itineraryDays(trip *models.Trip, items []*models.ItineraryItem, checkIns []*models.CheckIn) []time.Time {
+	first := dateOf(trip.StartDate)
+	last := first
+	if trip.EndDate != nil {
+		last = dateOf(*trip.EndDate)
+	}
+	seen := make([]time.Time, 0, len(items)+len(checkIns))
+	for _, it := range items {
+		seen = append(seen, dateOf(it.Day))
+	}
+	for _, c := range checkIns {
+		seen = append(seen, dateOf(c.Timestamp))
+	}
+	for _, d := range seen {
+		if d.Before(first) {
+			first = d
+		}
+		if d.After(last) {
+			last = d
+		}
+	}
+	var days []time.Time
+	for d := first; !d.After(last) && len(days) < maxItineraryDays; d = d.AddDate(0, 0, 1) {
+		days = append(days, d)
+	}
+	return days
+}
+
+// itemsOn returns the items scheduled on day, keeping their order.
+func itemsOn(items []*models.ItineraryItem, day time.Time) []*models.ItineraryItem {
+	var out []*models.ItineraryItem
+	for _, it := range items {
+		if dateOf(it.Day).Equal(day) {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// dateOf returns the calendar day of t, in its own time zone, at midnight
+// local time. Entries and expenses so fall on the day of the trip where
+// they happened.
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const (
+	itineraryFieldDay = iota
+	itineraryFieldTime
+	itineraryFieldTitle
+	itineraryFieldPlace
+	itineraryFieldBooking
+	itineraryFieldAlarm
+	itineraryFieldNotes
+	itineraryFieldDuration
+	itineraryFieldKind
+	itineraryFieldStarted
+	itineraryFieldFinished
+)
+
+// itineraryForm adds or edits a planned activity and saves it on confirmation.
+type itineraryForm struct {
+	form
+	app   *app
+	item  *models.ItineraryItem
+	isNew bool
+}
+
+func newItineraryForm(app *app, it *models.ItineraryItem, isNew bool) itineraryForm {
+	title := "🗺️  Edit Activity"
+	if isNew {
+		title = "🗺️  New Activity"
+	}
+	f := newForm(title,
+		newField("Day", app.cfg.DateFormat, "", app.validateDate),
+		newField("Time", "09:30", "Optional. 24-hour clock.", validateOptionalTime),
+		newField("Activity", "Fushimi Inari hike", "", required("activity")),
+		newField("Place", "Fushimi Inari Taisha", "Optional.", nil),
+		newField("Booking reference", "", "Optional. Confirmation or ticket number.", nil),
+		newField("Alarm", "30m", "Optional. How long before to be reminded in your calendar, e.g. 30m, 2h or 1d.", validateAlarm),
+		newField("Notes", "", "Optional.", nil),
+		newField("Duration", "1h30m", "Optional. How long it is planned to take, e.g. 45m or 2h.", validateDuration),
+		newField("Kind", "museum", "Optional. Such as museum, hike or meal, to compare how long each kind takes.", nil),
+		newField("Started", "09:40", "Optional. When it actually started, 24-hour clock.", validateOptionalTime),
+		newField("Finished", "11:55", "Optional. When it actually finished, 24-hour clock.", validateOptionalTime),
+	)
+	f.fields[itineraryFieldDay].dates = newDatePicker(app)
+	f.fields[itineraryFieldDay].input.SetValue(app.formatDate(it.Day))
+	f.fields[itineraryFieldTime].input.SetValue(it.Time)
+	f.fields[itineraryFieldTitle].input.SetValue(it.Title)
+	f.fields[itineraryFieldPlace].input.SetValue(it.Place)
+	f.fields[itineraryFieldBooking].input.SetValue(it.BookingRef)
+	f.fields[itineraryFieldAlarm].input.SetValue(models.FormatAlarm(it.Alarm))
+	f.fields[itineraryFieldNotes].input.SetValue(it.Notes)
+	f.fields[itineraryFieldDuration].input.SetValue(models.FormatAlarm(it.Duration))
+	f.fields[itineraryFieldKind].input.SetValue(it.Kind)
+	f.fields[itineraryFieldStarted].input.SetValue(it.Started)
+	f.fields[itineraryFieldFinished].input.SetValue(it.Finished)
+	// Work on a copy so cancelling leaves the caller's item untouched.
+	edited := *it
+	return itineraryForm{form: f, app: app, item: &edited, isNew: isNew}
+}
+
+func (f itineraryForm) Title() string {
+	if f.isNew {
+		return tr("New activity")
+	}
+	return tr("Edit %s", f.item.Title)
+}
+
+func (f itineraryForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f itineraryForm) draft() *models.Draft {
+	if !f.dirty {
+		return nil
+	}
+	return newDraft(models.DraftItinerary, f.item.ID, f.Title(), f.value(itineraryFieldTitle), f.isNew, f.item, f.values())
+}
+
+func (f itineraryForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		err := f.apply()
+		if err == nil {
+			err = f.app.store.SaveItineraryItem(f.app.ctx, f.item)
+		}
+		if err != nil {
+			f.err = err
+			return f, nil
+		}
+		it := f.item
+		return f, tea.Sequence(pop, func() tea.Msg { return itinerarySavedMsg{item: it} })
+	}
+	return f, cmd
+}
+
+func (f itineraryForm) View() string {
+	return f.form.view(f.summary)
+}
+
+// apply copies the validated values into the item. New items, and items
+// moved to another day, go to the end of that day.
+func (f itineraryForm) apply() error {
+	day, err := f.app.parseDate(f.value(itineraryFieldDay))
+	if err != nil {
+		return err
+	}
+	at, err := parseOptionalTime(f.value(itineraryFieldTime))
+	if err != nil {
+		return err
+	}
+	alarm, err := models.ParseAlarm(f.value(itineraryFieldAlarm))
+	if err != nil {
+		return err
+	}
+	duration, err := models.ParseDuration(f.value(itineraryFieldDuration))
+	if err != nil {
+		return err
+	}
+	started, err := parseOptionalTime(f.value(itineraryFieldStarted))
+	if err != nil {
+		return err
+	}
+	finished, err := parseOptionalTime(f.value(itineraryFieldFinished))
+	if err != nil {
+		return err
+	}
+	if f.isNew || !dateOf(day).Equal(dateOf(f.item.Day)) {
+		items, err := f.app.store.ListItineraryByTrip(f.app.ctx, f.item.TripID)
+		if err != nil {
+			return err
+		}
+		f.item.Position = len(itemsOn(items, dateOf(day)))
+	}
+	f.item.Day = day
+	f.item.Time = at
+	f.item.Title = f.value(itineraryFieldTitle)
+	f.item.Place = f.value(itineraryFieldPlace)
+	f.item.BookingRef = f.value(itineraryFieldBooking)
+	f.item.Alarm = alarm
+	f.item.Notes = f.value(itineraryFieldNotes)
+	f.item.Duration = duration
+	f.item.Kind = models.NormalizeKind(f.value(itineraryFieldKind))
+	f.item.Started = started
+	f.item.Finished = finished
+	return nil
+}
+
+func (f itineraryForm) summary() string {
+	var b strings.Builder
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-10s", label+":")), value)
+	}
+	at, _ := parseOptionalTime(f.value(itineraryFieldTime))
+	row("Day", f.value(itineraryFieldDay))
+	row("Time", at)
+	row("Activity", f.value(itineraryFieldTitle))
+	row("Place", f.value(itineraryFieldPlace))
+	row("Booking", f.value(itineraryFieldBooking))
+	alarm := ""
+	if m, _ := models.ParseAlarm(f.value(itineraryFieldAlarm)); m > 0 {
+		alarm = models.FormatAlarm(m) + " before"
+	}
+	row("Alarm", alarm)
+	row("Notes", f.value(itineraryFieldNotes))
+	planned := ""
+	if m, _ := models.ParseDuration(f.value(itineraryFieldDuration)); m > 0 {
+		planned = models.FormatDuration(m)
+	}
+	row("Planned", planned)
+	row("Kind", models.NormalizeKind(f.value(itineraryFieldKind)))
+	started, _ := parseOptionalTime(f.value(itineraryFieldStarted))
+	finished, _ := parseOptionalTime(f.value(itineraryFieldFinished))
+	row("Started", started)
+	row("Finished", finished)
+	return b.String()
+}
+
+// parseOptionalTime normalises a time of day such as "9:30" to "09:30".
+func parseOptionalTime(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+	t, err := time.Parse(models.TimeLayout, v)
+	if err != nil {
+		return "", errors.New("enter a time as HH:MM, e.g. 09:30")
+	}
+	return t.Format(models.TimeLayout), nil
+}
+
+func validateOptionalTime(v string) error {
+	_, err := parseOptionalTime(v)
+	return err
+}
+
+func validateAlarm(v string) error {
+	_, err := models.ParseAlarm(v)
+	return err
+}
+
+func validateDuration(v string) error {
+	_, err := models.ParseDuration(v)
+	return err
+}
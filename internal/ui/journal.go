@@ -0,0 +1,678 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/quick"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// entryList shows a trip's journal entries, or every trip's, a page at a
+// time so that journals of thousands of entries open as quickly as short
+// ones. The pages are loaded brief, without the entries' text, which is
+// read only for the entry under the cursor.
+type entryList struct {
+	app       *app
+	trip      *models.Trip
+	everyTrip bool
+	sort      storage.EntrySort
+	entries   pager[*models.Entry]
+	// whole holds the entries read whole, with their text.
+	whole  *entryCache
+	filter tagFilter
+	// marked are the entries marked for a bulk action, on any page.
+	marked marks
+
+	// trips holds the titles of the trips while every trip's entries are
+	// shown.
+	trips map[string]string
+	// markdown renders the entry under the cursor beside the list on wide
+	// terminals.
+	markdown *markdownRenderer
+	width    int
+
+	// started is the entry of the day started on opening the journal, if
+	// any.
+	started *models.Entry
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+// entryListChrome is how many lines of an entry list are not entries.
+const entryListChrome = 12
+
+func newEntryList(app *app, trip *models.Trip) entryList {
+	l := entryList{app: app, trip: trip, filter: newTagFilter(), entries: newPager[*models.Entry](20),
+		whole: newEntryCache(app), markdown: newMarkdownRenderer(app)}
+	l.start()
+	l.reload()
+	if l.started != nil {
+		l.err = errors.Join(l.err, l.entries.last())
+	}
+	return l
+}
+
+// start starts the day's entry on a trip in progress when there is none
+// yet and the daily_entry setting is on; see quick.Scaffold.
+func (l *entryList) start() {
+	if l.app.cfg.DailyEntry != config.DailyEntryOn || !l.trip.InProgress(time.Now()) {
+		return
+	}
+	l.started, l.err = quick.Scaffold(l.app.ctx, l.app.store, l.trip, time.Now())
+}
+
+func (l entryList) Title() string {
+	if l.everyTrip {
+		return tr("Every trip")
+	}
+	return l.trip.Title
+}
+
+func (l entryList) currentTrip() *models.Trip { return l.trip }
+
+func (l entryList) resume() (models.SessionScreen, bool) {
+	return models.SessionScreen{Kind: models.SessionJournal, Title: l.Title(), ID: l.trip.ID, Cursor: l.entries.cursor,
+		All: l.everyTrip, Sort: l.sort.String()}, true
+}
+
+func (l entryList) Init() tea.Cmd {
+	if l.started == nil {
+		return nil
+	}
+	// The weather of the day is looked up once the entry is in the store,
+	// as the editor does for entries it saves.
+	return tea.Batch(notify(entrySavedMsg{entry: l.started}), l.app.fetchWeather(l.started))
+}
+
+func (l entryList) capturesEsc() bool {
+	return l.confirmDelete || l.filter.editing || len(l.marked) > 0
+}
+
+func (l entryList) typing() bool { return l.filter.editing }
+
+func (l entryList) help() []key.Binding {
+	if l.filter.editing {
+		return l.filter.help()
+	}
+	scope := "show every trip's entries"
+	if l.everyTrip {
+		scope = "show only " + l.trip.Title + "'s entries"
+	}
+	if len(l.marked) > 0 {
+		return []key.Binding{
+			l.app.bind("up", "previous entry"),
+			l.app.bind("down", "next entry"),
+			l.app.bind("page_up", "previous page"),
+			l.app.bind("page_down", "next page"),
+			l.app.bind("mark", "mark or unmark the entry"),
+			l.app.bind("mark_all", "mark every entry listed, or none"),
+			l.app.bind("select", "delete, retag, move or export the entries marked"),
+			l.app.bind("delete", "delete the entries marked"),
+			fixed("esc", "unmark them all"),
+		}
+	}
+	return append([]key.Binding{
+		l.app.bind("up", "previous entry"),
+		l.app.bind("down", "next entry"),
+		l.app.bind("page_up", "previous page"),
+		l.app.bind("page_down", "next page"),
+		l.app.bind("first", "first entry"),
+		l.app.bind("last", "last entry"),
+		l.app.bind("select", "read the entry"),
+		l.app.bind("mark", "mark the entry for a bulk action"),
+		l.app.bind("mark_all", "mark every entry listed"),
+		l.app.bind("new", "write an entry"),
+		l.app.bind("edit", "edit the entry"),
+		l.app.bind("delete", "delete the entry"),
+		l.app.bind("public", "publish the entry with nomadic publish, or take it off"),
+		l.app.bind("private", "make the entry private, kept out of redacted exports, or shared again"),
+		l.app.bind("sort", "sort by "+l.nextSort().String()),
+		l.app.bind("all_trips", scope),
+		l.app.bind("filter", "filter by tag"),
+		l.app.bind("search", "search every entry"),
+	}, l.app.undoHelp()...)
+}
+
+// reload counts and loads the entries again, after they changed or what
+// the list shows did.
+func (l *entryList) reload() {
+	q := storage.EntryQuery{Tags: l.filter.tags, Sort: l.sort, Brief: true}
+	if !l.everyTrip {
+		q.TripID = l.trip.ID
+	}
+	store := l.app.store
+	l.whole.reset()
+	l.err = l.entries.source(
+		func() (int, error) { return store.CountEntries(l.app.ctx, q) },
+		func(offset, limit int) ([]*models.Entry, error) {
+			return store.ListEntryPage(l.app.ctx, q, offset, limit)
+		})
+	if l.err == nil && len(l.marked) > 0 {
+		// Only the entries listed stay marked.
+		var entries []*models.Entry
+		entries, l.err = l.entries.all()
+		l.marked = l.marked.keep(entryIDs(entries))
+	}
+	if l.err != nil || !l.everyTrip {
+		return
+	}
+	trips, err := store.ListTrips(l.app.ctx)
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.trips = make(map[string]string, len(trips))
+	for _, t := range trips {
+		l.trips[t.ID] = t.Title
+	}
+}
+
+// nextSort is the order the sort key switches to. Sorting one trip's
+// entries by trip would sort them by date.
+func (l entryList) nextSort() storage.EntrySort {
+	switch l.sort {
+	case storage.SortByDate:
+		if l.everyTrip {
+			return storage.SortByTrip
+		}
+		return storage.SortByTitle
+	case storage.SortByTrip:
+		return storage.SortByTitle
+	}
+	return storage.SortByDate
+}
+
+// selected returns the entry under the cursor as listed, without its
+// text; see read.
+func (l entryList) selected() *models.Entry {
+	e, _ := l.entries.selected()
+	return e
+}
+
+// read returns the entry under the cursor whole, or nil when there is
+// none.
+func (l entryList) read() (*models.Entry, error) {
+	e := l.selected()
+	if e == nil {
+		return nil, nil
+	}
+	return l.whole.get(e.ID)
+}
+
+// markedEntries reads the entries marked whole, in the order listed.
+func (l entryList) markedEntries() ([]*models.Entry, error) {
+	entries, err := l.entries.all()
+	if err != nil {
+		return nil, err
+	}
+	var marked []*models.Entry
+	for _, e := range entries {
+		if !l.marked[e.ID] {
+			continue
+		}
+		if e, err = l.whole.get(e.ID); err != nil {
+			return nil, err
+		}
+		marked = append(marked, e)
+	}
+	return marked, nil
+}
+
+func entryIDs(entries []*models.Entry) []string {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func (l entryList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		l.width = msg.Width
+		l.err = l.entries.resize(msg.Height - entryListChrome)
+		return l, nil
+	case entrySavedMsg:
+		l.reload()
+		// Until it is written in, the started entry is only saved again
+		// with its weather.
+		if e := l.started; e != nil && msg.entry.ID == e.ID && msg.entry.Title == e.Title && msg.entry.Text == e.Text {
+			l.status = fmt.Sprintf("Started today's entry, %q; %s to write it", msg.entry.Title, l.app.keyHint("edit"))
+			return l, nil
+		}
+		return l, toast(toastSuccess, "Saved %q", msg.entry.Title)
+	case historyMsg:
+		l.status = ""
+		l.reload()
+		return l, nil
+	case bulkAppliedMsg:
+		l.status, l.marked = msg.status, nil
+		l.reload()
+		return l, nil
+	case tea.KeyMsg:
+		if l.filter.editing {
+			cmd, changed := l.filter.update(msg)
+			if changed {
+				l.reload()
+			}
+			return l, cmd
+		}
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				e, err := l.read()
+				if err == nil {
+					err = l.app.run(&deleteEntry{entry: e})
+				}
+				if err != nil {
+					l.err = err
+				} else {
+					l.status = l.app.trashedHint(e.Title)
+				}
+				l.reload()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+
+		switch {
+		case l.app.is(msg, "up"):
+			l.err = l.entries.move(-1)
+		case l.app.is(msg, "down"):
+			l.err = l.entries.move(1)
+		case l.app.is(msg, "page_up"):
+			l.err = l.entries.pageUp()
+		case l.app.is(msg, "page_down"):
+			l.err = l.entries.pageDown()
+		case l.app.is(msg, "first"):
+			l.err = l.entries.first()
+		case l.app.is(msg, "last"):
+			l.err = l.entries.last()
+		case l.app.is(msg, "mark"):
+			if e := l.selected(); e != nil {
+				l.marked.toggle(e.ID)
+				l.err = l.entries.move(1)
+			}
+		case l.app.is(msg, "mark_all"):
+			var entries []*models.Entry
+			if entries, l.err = l.entries.all(); l.err == nil {
+				l.marked.all(entryIDs(entries))
+			}
+		case len(l.marked) > 0 && l.app.is(msg, "back"):
+			l.marked = nil
+		case len(l.marked) > 0 && (l.app.is(msg, "select") || l.app.is(msg, "delete")):
+			entries, err := l.markedEntries()
+			if err != nil {
+				l.err = err
+				return l, nil
+			}
+			b := newEntryBulkEdit(l.app, entries)
+			if l.app.is(msg, "delete") {
+				b, _ = b.choose(bulkDelete)
+			}
+			return l, push(b)
+		case l.app.is(msg, "sort"):
+			l.sort = l.nextSort()
+			l.reload()
+			l.err = errors.Join(l.err, l.entries.first())
+		case l.app.is(msg, "all_trips"):
+			l.everyTrip = !l.everyTrip
+			if !l.everyTrip && l.sort == storage.SortByTrip {
+				l.sort = storage.SortByDate
+			}
+			l.reload()
+			l.err = errors.Join(l.err, l.entries.first())
+		case l.app.is(msg, "search"):
+			return l, push(newSearch(l.app))
+		case l.app.is(msg, "new"):
+			return l, push(newEntryEditor(l.app, models.NewEntry(l.trip.ID, "", l.app.tripNow(l.trip)), true))
+		case l.app.is(msg, "select"):
+			e, err := l.read()
+			if e != nil {
+				return l, push(newEntryReader(l.app, e))
+			}
+			l.err = err
+		case l.app.is(msg, "edit"):
+			e, err := l.read()
+			if e != nil {
+				return l, push(newEntryEditor(l.app, e, false))
+			}
+			l.err = err
+		case l.app.is(msg, "delete"):
+			if l.selected() != nil {
+				l.confirmDelete = true
+			}
+		case l.app.is(msg, "filter"):
+			return l, l.filter.edit(l.app)
+		case l.app.is(msg, "public"):
+			if e, err := l.read(); err != nil {
+				l.err = err
+			} else if e != nil {
+				l.togglePublic(e)
+			}
+		case l.app.is(msg, "private"):
+			if e, err := l.read(); err != nil {
+				l.err = err
+			} else if e != nil {
+				l.togglePrivate(e)
+			}
+		}
+	}
+	return l, nil
+}
+
+// togglePublic marks e public for the website of nomadic publish, or
+// takes it off again.
+func (l *entryList) togglePublic(e *models.Entry) {
+	entry := *e
+	if entry.Public {
+		entry.SetPrivacy(models.PrivacyShared)
+	} else {
+		entry.SetPrivacy(models.PrivacyPublic)
+	}
+	if l.err = l.app.store.SaveEntry(l.app.ctx, &entry); l.err != nil {
+		return
+	}
+	l.status = fmt.Sprintf("%q is no longer public", entry.Title)
+	if entry.Public {
+		l.status = fmt.Sprintf("%q is public; run nomadic publish to update the website", entry.Title)
+	}
+	l.reload()
+}
+
+// togglePrivate marks e private, left out of the website and redacted
+// exports, or shared again.
+func (l *entryList) togglePrivate(e *models.Entry) {
+	entry := *e
+	if entry.Private {
+		entry.SetPrivacy(models.PrivacyShared)
+	} else {
+		entry.SetPrivacy(models.PrivacyPrivate)
+	}
+	if l.err = l.app.store.SaveEntry(l.app.ctx, &entry); l.err != nil {
+		return
+	}
+	l.status = fmt.Sprintf("%q is shared again", entry.Title)
+	if entry.Private {
+		l.status = fmt.Sprintf("%q is private; redacted exports leave it out", entry.Title)
+	}
+	l.reload()
+}
+
+func (l entryList) View() string {
+	var b strings.Builder
+	header := "📔 " + l.trip.Title
+	if l.everyTrip {
+		header = "📔 Every trip"
+	}
+	b.WriteString(headerStyle.Render(header) + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n\n")
+	}
+	b.WriteString(l.filter.view())
+	entries := l.entries.page()
+	switch {
+	case len(entries) == 0 && l.filter.active():
+		b.WriteString("No entries carry these tags.\n")
+	case len(entries) == 0:
+		b.WriteString("No entries yet — press " + l.app.keyHint("new") + " to write the first one.\n")
+	}
+	var rows strings.Builder
+	for i, e := range entries {
+		cursor, title := "  ", e.Title
+		if l.entries.at(i) {
+			cursor, title = "👉", cursorStyle.Render(title)
+		}
+		var extra []string
+		if e.Mood > 0 {
+			extra = append(extra, models.MoodFace(e.Mood))
+		}
+		if e.Public {
+			extra = append(extra, "🌐")
+		}
+		if e.Private {
+			extra = append(extra, "🙈")
+		}
+		if l.everyTrip {
+			extra = append(extra, "🧳 "+l.trips[e.TripID])
+		}
+		if len(e.Tags) > 0 {
+			extra = append(extra, formatTags(e.Tags))
+		}
+		fmt.Fprintf(&rows, "%s %s%s  %s  %s\n", cursor, l.marked.box(e.ID), l.app.formatDate(e.Timestamp), title, hintStyle.Render(strings.Join(extra, "  ")))
+	}
+	b.WriteString(masterDetail(l.width, rows.String(), l.preview()))
+	b.WriteString("\n" + labelStyle.Render(l.entries.status()) + hintStyle.Render(" · sorted by "+l.sort.String()) + "\n")
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Move %q to the trash? y/n", l.selected().Title)) + "\n")
+	}
+	a := l.app
+	if len(l.marked) > 0 {
+		b.WriteString("\n" + highlightStyle.Render(plural(len(l.marked), "entry", "entries")+" marked") + hintStyle.Render(" • "+a.keyHint("mark")+" mark • "+
+			a.keyHint("mark_all")+" all • enter act on them • "+a.keyHint("delete")+" delete them • esc unmark") + "\n")
+	}
+	scope := "every trip"
+	if l.everyTrip {
+		scope = "this trip"
+	}
+	b.WriteString("\n" + hintStyle.Render(a.keyHint("new")+" new • enter read • "+a.keyHint("edit")+" edit • "+
+		a.keyHint("delete")+" delete • "+a.keyHint("filter")+" filter by tag • "+a.keyHint("undo")+" undo • "+
+		a.keyHint("search")+" search • esc back") + "\n")
+	b.WriteString(hintStyle.Render(a.keyHint("page_up")+"/"+a.keyHint("page_down")+" page • "+a.keyHint("first")+"/"+
+		a.keyHint("last")+" first/last • "+a.keyHint("sort")+" sort by "+l.nextSort().String()+" • "+
+		a.keyHint("all_trips")+" "+scope+" • "+a.keyHint("public")+" public • "+a.keyHint("private")+" private") + "\n")
+	return b.String()
+}
+
+// preview shows the entry under the cursor, for beside the list on wide
+// terminals.
+func (l entryList) preview() string {
+	if l.width < wideWidth {
+		return ""
+	}
+	e, err := l.read()
+	if err != nil {
+		return errorStyle.Render(err.Error())
+	}
+	if e == nil {
+		return ""
+	}
+	w := detailWidth(l.width)
+	return headerStyle.Render(e.Title) + "\n" + hintStyle.Width(w).Render(entryMeta(l.app, e)) + "\n" +
+		l.markdown.render(e.Text, w)
+}
+
+// entryCacheSize is how many entries an entryCache holds before it starts
+// over.
+const entryCacheSize = 64
+
+// entryCache reads entries whole for a list that loads them brief, and
+// keeps the last few read so moving the cursor back and forth does not
+// read them again. It is shared by pointer, as screens are copied on each
+// update.
+type entryCache struct {
+	app     *app
+	entries map[string]*models.Entry
+}
+
+func newEntryCache(a *app) *entryCache {
+	return &entryCache{app: a, entries: map[string]*models.Entry{}}
+}
+
+// get returns the entry id whole.
+func (c *entryCache) get(id string) (*models.Entry, error) {
+	if e, ok := c.entries[id]; ok {
+		return e, nil
+	}
+	e, err := c.app.store.GetEntry(c.app.ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(c.entries) >= entryCacheSize {
+		clear(c.entries)
+	}
+	c.entries[id] = e
+	return e, nil
+}
+
+// reset forgets the entries read, for when they may have changed.
+func (c *entryCache) reset() { clear(c.entries) }
+
+// entryReader shows an entry with its Markdown rendered.
+type entryReader struct {
+	app      *app
+	entry    *models.Entry
+	viewport viewport.Model
+	markdown *markdownRenderer
+	width    int
+
+	// attachments summarises the files attached to the entry, tracks the
+	// GPS tracks linked to it and highlights the trip highlights.
+	attachments string
+	tracks      string
+	highlights  string
+}
+
+func newEntryReader(app *app, entry *models.Entry) entryReader {
+	r := entryReader{app: app, entry: entry, markdown: newMarkdownRenderer(app), viewport: viewport.New(80, 20)}
+	r.attachments = attachmentCount(app, entry.ID)
+	r.tracks = trackSummary(app, entry.ID)
+	r.highlights = highlightSummary(app, entry.ID)
+	r.refresh()
+	return r
+}
+
+func (r entryReader) Title() string { return r.entry.Title }
+
+func (r entryReader) resume() (models.SessionScreen, bool) {
+	return models.SessionScreen{Kind: models.SessionEntry, Title: r.Title(), ID: r.entry.ID}, true
+}
+
+func (r entryReader) Init() tea.Cmd {
+	return nil
+}
+
+func (r entryReader) help() []key.Binding {
+	return []key.Binding{
+		fixed("↑/↓", "scroll"),
+		fixed("pgup/pgdown", "scroll a page"),
+		r.app.bind("edit", "edit the entry"),
+		r.app.bind("attachments", "attachments"),
+		r.app.bind("history", "earlier versions"),
+		r.app.bind("quit", "close"),
+	}
+}
+
+func (r *entryReader) refresh() {
+	r.viewport.SetContent(r.markdown.render(r.entry.Text, min(r.viewport.Width, 100)-2))
+}
+
+func (r entryReader) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		r.viewport.Width, r.viewport.Height = msg.Width, max(msg.Height-4, 5)
+		r.refresh()
+		return r, nil
+	case entrySavedMsg:
+		if msg.entry.ID == r.entry.ID {
+			r.entry = msg.entry
+			r.refresh()
+		}
+		return r, nil
+	case themeChangedMsg:
+		r.refresh()
+		return r, nil
+	case attachmentsChangedMsg:
+		if msg.entryID == r.entry.ID {
+			r.attachments = attachmentCount(r.app, r.entry.ID)
+		}
+		return r, nil
+	case historyMsg:
+		r.attachments = attachmentCount(r.app, r.entry.ID)
+		return r, nil
+	case tea.KeyMsg:
+		switch {
+		case r.app.is(msg, "quit"):
+			return r, pop
+		case r.app.is(msg, "edit"):
+			return r, push(newEntryEditor(r.app, r.entry, false))
+		case r.app.is(msg, "attachments"):
+			return r, push(newAttachmentList(r.app, r.entry))
+		case r.app.is(msg, "history"):
+			return r, push(newRevisionList(r.app, r.entry))
+		}
+	}
+	var cmd tea.Cmd
+	r.viewport, cmd = r.viewport.Update(msg)
+	return r, cmd
+}
+
+func (r entryReader) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(r.entry.Title) + "\n")
+	meta := entryMeta(r.app, r.entry)
+	for _, extra := range []string{r.attachments, r.tracks, r.highlights} {
+		if extra != "" {
+			meta += "  " + extra
+		}
+	}
+	b.WriteString(hintStyle.Render(meta) + "\n")
+	b.WriteString(r.viewport.View() + "\n")
+	b.WriteString(hintStyle.Render("↑/↓ scroll • "+r.app.keyHint("edit")+" edit • "+r.app.keyHint("attachments")+" attachments • "+
+		r.app.keyHint("history")+" history • esc back") + "\n")
+	return b.String()
+}
+
+func entryMeta(a *app, e *models.Entry) string {
+	meta := a.formatDate(e.Timestamp)
+	if e.Location != "" {
+		meta += "  📍 " + e.Location
+		if p, ok := places.Lookup(e.Location); ok {
+			meta += ", " + p.CountryName + " · " + e.Timestamp.In(p.Location()).Format("15:04 MST") + " local"
+		}
+	}
+	if leg := legName(a, e.LegID); leg != "" && !strings.EqualFold(leg, e.Location) {
+		meta += "  🧭 " + leg
+	}
+	if e.Weather != nil {
+		meta += "  " + e.Weather.Summary()
+	}
+	if e.Mood > 0 {
+		meta += fmt.Sprintf("  %s %d/%d", models.MoodFace(e.Mood), e.Mood, models.MaxRating)
+	}
+	if len(e.People) > 0 {
+		meta += "  👥 " + strings.Join(e.People, ", ")
+	}
+	if len(e.Tags) > 0 {
+		meta += "  " + formatTags(e.Tags)
+	}
+	return meta
+}
+
+// clamp limits v to [lo, hi], preferring lo when the range is empty.
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
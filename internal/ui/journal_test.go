@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// benchEntries is how many entries the journal benchmarks keep: years of
+// daily journaling.
+const benchEntries = 10_000
+
+// frameBudget is how long a key press may take to handle and draw for the
+// TUI to keep up at 60 frames a second.
+const frameBudget = time.Second / 60
+
+// startupBudget is how long the TUI may take to start for it to feel
+// instant.
+const startupBudget = 100 * time.Millisecond
+
+// benchJournal opens a store holding a trip of benchEntries entries, each
+// a few paragraphs long, and the app of a TUI on it.
+func benchJournal(b *testing.B) (*app, *models.Trip) {
+	b.Helper()
+	ctx := b.Context()
+	store, err := storage.Open(ctx, b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { store.Close() })
+	start := time.Date(2015, 1, 1, 9, 0, 0, 0, time.UTC)
+	trip := models.NewTrip("Around the world", []string{"Lisbon", "Tokyo"}, start)
+	rec := &storage.TripRecord{Trip: trip}
+	text := strings.Repeat("Walked the old town from the harbour up to the castle, then **lunch** by the river.\n\n", 12)
+	for i := range benchEntries {
+		e := models.NewEntry(trip.ID, fmt.Sprintf("# Day %d\n\n%s", i+1, text), start.AddDate(0, 0, i))
+		e.Title = fmt.Sprintf("Day %d", i+1)
+		e.Tags = []string{"walk"}
+		rec.Entries = append(rec.Entries, e)
+	}
+	dump := &storage.Dump{Format: storage.DumpFormat, Trips: []*storage.TripRecord{rec}}
+	if _, _, err := store.Import(ctx, dump, storage.ImportOptions{}); err != nil {
+		b.Fatal(err)
+	}
+	m := NewModel(Options{Context: ctx, Store: store, Config: config.Default()})
+	return m.app, trip
+}
+
+// benchKeys measures handling key and drawing the screen after it, over
+// and over, on a journal open on a wide terminal with the entry under the
+// cursor beside the list, and fails when a press takes longer than a
+// frame.
+func benchKeys(b *testing.B, key tea.KeyMsg) {
+	a, trip := benchJournal(b)
+	var s tea.Model = newEntryList(a, trip)
+	s, _ = s.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+	s, _ = s.Update(tea.KeyMsg{Type: tea.KeyHome})
+	n := 0
+	for b.Loop() {
+		s, _ = s.Update(key)
+		s.View()
+		n++
+	}
+	if per := b.Elapsed() / time.Duration(n); per > frameBudget {
+		b.Fatalf("a key press took %v, over the frame budget of %v", per, frameBudget)
+	}
+}
+
+func BenchmarkEntryListDown(b *testing.B) {
+	benchKeys(b, tea.KeyMsg{Type: tea.KeyDown})
+}
+
+func BenchmarkEntryListPageDown(b *testing.B) {
+	benchKeys(b, tea.KeyMsg{Type: tea.KeyPgDown})
+}
+
+// BenchmarkEntryListOpen measures opening the journal of every trip and
+// drawing it.
+func BenchmarkEntryListOpen(b *testing.B) {
+	a, trip := benchJournal(b)
+	n := 0
+	for b.Loop() {
+		l := newEntryList(a, trip)
+		l.everyTrip = true
+		l.reload()
+		l.View()
+		n++
+	}
+	if per := b.Elapsed() / time.Duration(n); per > frameBudget {
+		b.Fatalf("opening the journal took %v, over the frame budget of %v", per, frameBudget)
+	}
+}
+
+// BenchmarkStartup measures starting the TUI on the home screen.
+func BenchmarkStartup(b *testing.B) {
+	a, _ := benchJournal(b)
+	n := 0
+	for b.Loop() {
+		m := NewModel(Options{Context: a.ctx, Store: a.store, Config: a.cfg})
+		m.View()
+		n++
+	}
+	if per := b.Elapsed() / time.Duration(n); per > startupBudget {
+		b.Fatalf("starting took %v, over the budget of %v", per, startupBudget)
+	}
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+)
+
+// keyMap holds the binding of every action in config.DefaultKeys, with the
+// keys the config file assigns to it.
+type keyMap map[string]key.Binding
+
+func newKeyMap(cfg config.Config) keyMap {
+	m := make(keyMap)
+	for action := range config.DefaultKeys() {
+		keys := cfg.KeysFor(action)
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			// Bubbletea spells the space bar " ", which a comma-separated
+			// setting cannot hold.
+			if k == "space" {
+				keys[i] = " "
+			}
+			names[i] = k
+		}
+		m[action] = key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(names, "/"), action))
+	}
+	return m
+}
+
+// helper is implemented by screens to list the keys they respond to in
+// their current state, for the help overlay.
+type helper interface {
+	help() []key.Binding
+}
+
+// typist is implemented by screens that sometimes type what is pressed
+// into a text input. Meanwhile printable keys go to the screen rather than
+// to global actions such as help.
+type typist interface {
+	typing() bool
+}
+
+// keyHint names the first key bound to action, for help lines.
+func (a *app) keyHint(action string) string {
+	if keys := a.cfg.KeysFor(action); len(keys) > 0 {
+		return keys[0]
+	}
+	return action
+}
+
+// editActions are the actions that change the journal wherever they are
+// bound, which read-only mode turns off.
+var editActions = map[string]bool{
+	"new": true, "add": true, "edit": true, "delete": true, "archive": true, "restore": true,
+	"empty": true, "move_up": true, "move_down": true, "link": true, "save": true, "rate": true,
+	"public": true, "private": true, "yearly": true, "receipt": true, "tags": true, "import": true, "clone": true,
+	"template": true, "health": true, "budget": true, "save_list": true, "lists": true, "generate": true,
+	"flight": true, "inbox": true, "undo": true, "redo": true,
+	"checkin": true, "quick": true, "record": true,
+}
+
+// is reports whether key is bound to the named action in the config. In
+// read-only mode it refuses the editActions, as edits does.
+func (a *app) is(msg tea.KeyMsg, action string) bool {
+	if editActions[action] {
+		return a.edits(msg, action)
+	}
+	return key.Matches(msg, a.keys[action])
+}
+
+// edits is is for an action that changes the journal where it is bound,
+// such as select ticking an item off: in read-only mode it refuses the key,
+// and the app says why.
+func (a *app) edits(msg tea.KeyMsg, action string) bool {
+	if !key.Matches(msg, a.keys[action]) {
+		return false
+	}
+	if a.readOnly() {
+		a.refused = true
+		return false
+	}
+	return true
+}
+
+// refusedText says why a change was refused in read-only mode.
+const refusedText = "🔒 Read-only: nothing can be changed"
+
+// readOnly reports whether the TUI only browses the journal.
+func (a *app) readOnly() bool {
+	return a.cfg.ReadOnly == config.ReadOnlyOn
+}
+
+// bind returns the binding of action described as desc, for the help
+// overlay, which leaves out the editActions in read-only mode.
+func (a *app) bind(action, desc string) key.Binding {
+	b := a.keys[action]
+	b.SetHelp(b.Help().Key, desc)
+	b.SetEnabled(!editActions[action] || !a.readOnly())
+	return b
+}
+
+// fixed describes keys that are not configurable, such as Tab between form
+// fields, for the help overlay.
+func fixed(keys, desc string) key.Binding {
+	return key.NewBinding(key.WithKeys(strings.Split(keys, "/")...), key.WithHelp(keys, desc))
+}
+
+// undoHelp describes the undo and redo keys of screens that offer them.
+func (a *app) undoHelp() []key.Binding {
+	return []key.Binding{a.bind("undo", "undo"), a.bind("redo", "redo")}
+}
+
+// helpView lists the keys of the screen on top of the stack and the keys
+// that work everywhere.
+func (m Model) helpView() string {
+	var screen []key.Binding
+	if h, ok := m.top().(helper); ok {
+		screen = h.help()
+	}
+	global := []key.Binding{m.app.bind("help", "show or hide this help")}
+	if len(m.stack) > 1 {
+		global = append(global, m.app.bind("back", "go back"))
+	}
+	global = append(global,
+		m.app.bind("theme", "switch theme"),
+		m.app.bind("checkin", "check in at a place on this trip or the one in progress"),
+		m.app.bind("quick", "jot a note down in today's entry of this trip or the one in progress"),
+		m.app.bind("palette", "go to any trip, entry, expense or action by name"),
+		m.app.bind("debug", "show the debug console of what nomadic logged"),
+		m.app.bind("record", "record a macro, or stop recording and name it"),
+		fixed("ctrl+c", "quit nomadic"))
+	global = append(global, m.app.macroHelp()...)
+	if m.vim != nil {
+		global = append(global, vimHelp()...)
+	}
+
+	width := 0
+	for _, b := range append(screen, global...) {
+		width = max(width, lipgloss.Width(b.Help().Key))
+	}
+	var b strings.Builder
+	section := func(title string, bindings []key.Binding) {
+		b.WriteString(labelStyle.Render(title) + "\n")
+		for _, k := range bindings {
+			if !k.Enabled() {
+				continue
+			}
+			h := k.Help()
+			pad := strings.Repeat(" ", width-lipgloss.Width(h.Key))
+			fmt.Fprintf(&b, "  %s%s  %s\n", highlightStyle.Render(h.Key), pad, tr(h.Desc))
+		}
+	}
+	b.WriteString(headerStyle.Render("⌨️  "+tr("Keys")) + "\n\n")
+	if len(screen) > 0 {
+		section(m.top().Title(), screen)
+		b.WriteString("\n")
+	}
+	section(tr("Everywhere"), global)
+	b.WriteString("\n" + hintStyle.Render(tr("Change keys with nomadic config set keys.<action> • %s or %s close",
+		m.app.keyHint("help"), m.app.keyHint("back"))) + "\n")
+	return b.String()
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Terminal widths at which screens change their layout. Below narrowWidth
+// panes that sit side by side stack vertically instead; from wideWidth
+// lists show the item under the cursor in a pane beside them.
+const (
+	narrowWidth = 80
+	wideWidth   = 120
+)
+
+// fit keeps view within width columns and height lines, so that nothing
+// wraps or scrolls past the terminal and throws the redraw off: longer
+// lines are cut with an ellipsis, and lines past height are dropped for an
+// ellipsis of their own. A width or height of zero, before the terminal
+// reported its size, leaves it unbounded.
+func fit(view string, width, height int) string {
+	ellipsis := glyphs("…")
+	lines := strings.Split(view, "\n")
+	if height > 0 && len(lines) > height {
+		lines = append(lines[:height-1], hintStyle.Render(ellipsis))
+	}
+	if width > 0 {
+		for i, line := range lines {
+			lines[i] = ansi.Truncate(line, width, ellipsis)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// window returns the rows from and to, exclusive, of a list of n rows that
+// fit in size lines with the cursor in view, kept in the middle once the
+// list scrolls. A size of zero shows every row.
+func window(n, cursor, size int) (from, to int) {
+	if size <= 0 || n <= size {
+		return 0, n
+	}
+	from = clamp(cursor-size/2, 0, n-size)
+	return from, from + size
+}
+
+// masterDetail lays out list with the detail of its selected item in a
+// pane to its right, on terminals at least wideWidth wide. Narrower ones,
+// and accessible mode, show the list alone, the detail a key away.
+func masterDetail(width int, list, detail string) string {
+	if width < wideWidth || detail == "" || accessible {
+		return list
+	}
+	list, detail = glyphs(list), glyphs(detail)
+	listWidth := width - detailWidth(width) - 5
+	left := lipgloss.NewStyle().Width(listWidth).Render(fit(strings.TrimRight(list, "\n"), listWidth, 0))
+	inner := detailWidth(width)
+	pane := paneStyle.Width(inner + 2).Render(fit(strings.TrimRight(detail, "\n"), inner, max(lipgloss.Height(left)-2, 3)))
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, " ", pane) + "\n"
+}
+
+// detailWidth is how wide the detail of a master/detail layout is inside
+// its pane, whose border and padding take four more columns and a space
+// parts from the list.
+func detailWidth(width int) int {
+	return width*2/5 - 5
+}
+
+// panes lays views out side by side, or one above the other on terminals
+// narrower than narrowWidth and in accessible mode.
+func panes(width int, views ...string) string {
+	if width < narrowWidth || accessible {
+		return lipgloss.JoinVertical(lipgloss.Left, views...)
+	}
+	for i := range views {
+		views[i] = glyphs(views[i])
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, views...)
+}
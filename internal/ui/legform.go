@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const (
+	legFieldLocation = iota
+	legFieldArrival
+	legFieldDeparture
+	legFieldTransport
+)
+
+// legForm adds or edits a leg of a trip and saves it on confirmation.
+type legForm struct {
+	form
+	app   *app
+	trip  *models.Trip
+	leg   *models.Leg
+	isNew bool
+}
+
+func newLegForm(app *app, trip *models.Trip, l *models.Leg, isNew bool) legForm {
+	title := "🧭 Edit Leg"
+	if isNew {
+		title = "🧭 New Leg"
+	}
+	f := newForm(title,
+		newField("Location", "Kyoto", "", required("location")),
+		newField("Arrival", app.cfg.DateFormat, "", app.validateDate),
+		newField("Departure", app.cfg.DateFormat, "Optional.", app.validateOptionalDate),
+		newField("Transport", models.TransportTrain, "Optional. "+strings.Join(models.Transports, " • "), validateTransport),
+	)
+	f.fields[legFieldLocation].places = newPlaceCompleter(false)
+	f.fields[legFieldArrival].dates = newDatePicker(app)
+	f.fields[legFieldDeparture].dates = newDateRangePicker(app, legFieldArrival, "departure must not be before arrival")
+	f.fields[legFieldLocation].input.SetValue(l.Location)
+	f.fields[legFieldArrival].input.SetValue(app.formatDate(l.Arrival))
+	if l.Departure != nil {
+		f.fields[legFieldDeparture].input.SetValue(app.formatDate(*l.Departure))
+	}
+	f.fields[legFieldTransport].input.SetValue(l.Transport)
+	// Work on a copy so cancelling leaves the caller's leg untouched.
+	edited := *l
+	return legForm{form: f, app: app, trip: trip, leg: &edited, isNew: isNew}
+}
+
+func (f legForm) Title() string {
+	if f.isNew {
+		return tr("New leg")
+	}
+	return tr("Edit %s", f.leg.Location)
+}
+
+func (f legForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f legForm) draft() *models.Draft {
+	if !f.dirty {
+		return nil
+	}
+	return newDraft(models.DraftLeg, f.leg.ID, f.Title(), f.value(legFieldLocation), f.isNew, f.leg, f.values())
+}
+
+func (f legForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		trip, err := f.save()
+		if err != nil {
+			f.err = err
+			return f, nil
+		}
+		l := f.leg
+		saved := func() tea.Msg { return legSavedMsg{leg: l} }
+		if trip != nil {
+			return f, tea.Sequence(pop, func() tea.Msg { return tripSavedMsg{trip: trip} }, saved)
+		}
+		return f, tea.Sequence(pop, saved)
+	}
+	return f, cmd
+}
+
+func (f legForm) View() string {
+	return f.form.view(f.summary)
+}
+
+// save stores the leg and attributes the trip's entries and expenses of
+// its days to it. A location new to the trip joins its destinations, and
+// the trip is returned when that changed it.
+func (f legForm) save() (*models.Trip, error) {
+	if err := f.apply(); err != nil {
+		return nil, err
+	}
+	if err := f.app.store.SaveLeg(f.app.ctx, f.leg); err != nil {
+		return nil, err
+	}
+	if _, err := f.app.store.AttributeToLegs(f.app.ctx, f.leg.TripID); err != nil {
+		return nil, err
+	}
+	trip := *f.trip
+	trip.Locations = append([]string(nil), f.trip.Locations...)
+	if !trip.AddLocation(f.leg.Location) {
+		return nil, nil
+	}
+	if err := f.app.store.SaveTrip(f.app.ctx, &trip); err != nil {
+		return nil, err
+	}
+	return &trip, nil
+}
+
+// apply copies the validated values into the leg.
+func (f legForm) apply() error {
+	arrival, err := f.app.parseDate(f.value(legFieldArrival))
+	if err != nil {
+		return err
+	}
+	if err := f.checkDeparture(); err != nil {
+		return err
+	}
+	transport, err := models.ParseTransport(f.value(legFieldTransport))
+	if err != nil {
+		return err
+	}
+	f.leg.Location = f.value(legFieldLocation)
+	f.leg.Arrival = arrival
+	f.leg.Departure = nil
+	if v := f.value(legFieldDeparture); v != "" {
+		departure, err := f.app.parseDate(v)
+		if err != nil {
+			return err
+		}
+		f.leg.Departure = &departure
+	}
+	f.leg.Transport = transport
+	return nil
+}
+
+// checkDeparture rejects a departure before the arrival.
+func (f legForm) checkDeparture() error {
+	v := f.value(legFieldDeparture)
+	if v == "" {
+		return nil
+	}
+	arrival, err := f.app.parseDate(f.value(legFieldArrival))
+	if err != nil {
+		return err
+	}
+	departure, err := f.app.parseDate(v)
+	if err != nil {
+		return err
+	}
+	if departure.Before(arrival) {
+		return errors.New("departure must not be before arrival")
+	}
+	return nil
+}
+
+func (f legForm) summary() string {
+	var b strings.Builder
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-10s", label+":")), value)
+	}
+	transport, _ := models.ParseTransport(f.value(legFieldTransport))
+	row("Location", f.value(legFieldLocation))
+	if d := placeDetails(f.value(legFieldLocation)); d != "" {
+		b.WriteString(strings.Repeat(" ", 11) + hintStyle.Render(d) + "\n")
+	}
+	row("Arrival", f.value(legFieldArrival))
+	row("Departure", f.value(legFieldDeparture))
+	row("Transport", transport)
+	b.WriteString(countryNotesView(f.app, []string{f.value(legFieldLocation)}))
+	return b.String()
+}
+
+func validateTransport(v string) error {
+	_, err := models.ParseTransport(v)
+	return err
+}
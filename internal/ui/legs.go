@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// legList lists the legs of a multi-destination trip in the order they are
+// reached.
+type legList struct {
+	app  *app
+	trip *models.Trip
+	legs []*models.Leg
+	// counts describes what is attributed to each leg, by leg ID.
+	counts map[string]string
+	cursor int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newLegList(app *app, trip *models.Trip) legList {
+	l := legList{app: app, trip: trip}
+	l.reload()
+	return l
+}
+
+func (l legList) Title() string { return "Legs of " + l.trip.Title }
+
+func (l legList) currentTrip() *models.Trip { return l.trip }
+
+func (l legList) Init() tea.Cmd {
+	return nil
+}
+
+func (l legList) capturesEsc() bool { return l.confirmDelete }
+
+func (l legList) help() []key.Binding {
+	return append([]key.Binding{
+		l.app.bind("up", "previous leg"),
+		l.app.bind("down", "next leg"),
+		l.app.bind("new", "add a leg"),
+		l.app.bind("edit", "edit the leg"),
+		l.app.bind("delete", "delete the leg"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *legList) reload() {
+	l.legs, l.err = l.app.store.ListLegsByTrip(l.app.ctx, l.trip.ID)
+	l.cursor = clamp(l.cursor, 0, len(l.legs)-1)
+	if l.err != nil {
+		return
+	}
+	entries, err := l.app.store.ListEntriesByTrip(l.app.ctx, l.trip.ID)
+	if err != nil {
+		l.err = err
+		return
+	}
+	expenses, err := l.app.store.ListExpensesByTrip(l.app.ctx, l.trip.ID)
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.counts = legCounts(entries, expenses)
+}
+
+func (l legList) selected() *models.Leg {
+	if len(l.legs) == 0 {
+		return nil
+	}
+	return l.legs[l.cursor]
+}
+
+func (l legList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tripSavedMsg:
+		if msg.trip.ID == l.trip.ID {
+			l.trip = msg.trip
+		}
+		return l, nil
+	case legSavedMsg:
+		l.reload()
+		for i, leg := range l.legs {
+			if leg.ID == msg.leg.ID {
+				l.cursor = i
+			}
+		}
+		return l, toast(toastSuccess, "Saved %s", msg.leg.Location)
+	case entrySavedMsg, expenseSavedMsg, expensesImportedMsg, lodgingsChangedMsg:
+		l.reload()
+		return l, nil
+	case historyMsg:
+		l.status = ""
+		l.reload()
+		return l, nil
+	case tea.KeyMsg:
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				leg := l.selected()
+				c, err := newDeleteLeg(l.app.ctx, l.app.store, leg)
+				if err == nil {
+					err = l.app.run(c)
+				}
+				if err != nil {
+					l.err = err
+				} else {
+					l.status = l.app.deletedHint(leg.Location)
+				}
+				l.reload()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.legs)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			// A new leg starts where the last one ends.
+			arrival := l.trip.StartDate
+			if n := len(l.legs); n > 0 {
+				last := l.legs[n-1]
+				arrival = last.Arrival
+				if last.Departure != nil {
+					arrival = *last.Departure
+				}
+			}
+			return l, push(newLegForm(l.app, l.trip, models.NewLeg(l.trip.ID, "", arrival), true))
+		case l.app.edits(msg, "select"), l.app.is(msg, "edit"):
+			if leg := l.selected(); leg != nil {
+				return l, push(newLegForm(l.app, l.trip, leg, false))
+			}
+		case l.app.is(msg, "delete"):
+			if l.selected() != nil {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l legList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🧭 "+l.trip.Title) + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n\n")
+	}
+	if len(l.legs) == 0 {
+		b.WriteString("No legs yet — press " + l.app.keyHint("new") + " to add the first stop.\n")
+	}
+	for i, leg := range l.legs {
+		cursor := "  "
+		if i == l.cursor {
+			cursor = "👉"
+		}
+		fmt.Fprintf(&b, "%s %d. %-20s %s\n", cursor, i+1, truncate(leg.Location, 20), legDates(l.app, leg))
+		var details []string
+		if leg.Transport != "" {
+			details = append(details, transportIcon(leg.Transport)+" by "+leg.Transport)
+		}
+		if c := l.counts[leg.ID]; c != "" {
+			details = append(details, c)
+		}
+		if len(details) > 0 {
+			fmt.Fprintf(&b, "      %s\n", hintStyle.Render(strings.Join(details, " · ")))
+		}
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Delete the leg to %s? y/n", l.selected().Location)) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render(l.app.keyHint("new")+" new • "+l.app.keyHint("edit")+" edit • "+
+		l.app.keyHint("delete")+" delete • "+l.app.keyHint("undo")+" undo • esc back") + "\n")
+	return b.String()
+}
+
+// legDates renders the days of a leg, such as "1 Apr → 5 Apr".
+func legDates(a *app, l *models.Leg) string {
+	if l.Departure == nil {
+		return a.formatDate(l.Arrival) + " →"
+	}
+	return a.formatDate(l.Arrival) + " → " + a.formatDate(*l.Departure)
+}
+
+// legName names the leg with the given ID by its location, or is empty
+// for no leg.
+func legName(a *app, id string) string {
+	if id == "" {
+		return ""
+	}
+	l, err := a.store.GetLeg(a.ctx, id)
+	if err != nil {
+		return ""
+	}
+	return l.Location
+}
+
+// legCounts describes how many journal entries and expenses are attributed
+// to each leg, by leg ID.
+func legCounts(entries []*models.Entry, expenses []*models.Expense) map[string]string {
+	nEntries, nExpenses := map[string]int{}, map[string]int{}
+	for _, e := range entries {
+		if e.LegID != "" {
+			nEntries[e.LegID]++
+		}
+	}
+	for _, x := range expenses {
+		if x.LegID != "" {
+			nExpenses[x.LegID]++
+		}
+	}
+	counts := make(map[string]string)
+	for id, n := range nEntries {
+		counts[id] = plural(n, "entry", "entries")
+	}
+	for id, n := range nExpenses {
+		if c := counts[id]; c != "" {
+			counts[id] = c + " • " + plural(n, "expense", "expenses")
+		} else {
+			counts[id] = plural(n, "expense", "expenses")
+		}
+	}
+	return counts
+}
+
+// transportIcon returns the emoji of a transport mode.
+func transportIcon(mode string) string {
+	switch mode {
+	case models.TransportFlight:
+		return "✈️"
+	case models.TransportTrain:
+		return "🚆"
+	case models.TransportBus:
+		return "🚌"
+	case models.TransportCar:
+		return "🚗"
+	case models.TransportFerry:
+		return "⛴️"
+	case models.TransportBike:
+		return "🚲"
+	case models.TransportWalk:
+		return "🚶"
+	}
+	return "🧭"
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"os"
+
+	"github.com/girdharshubham/nomadic/internal/i18n"
+)
+
+// loc is the locale the interface speaks, and writes numbers and dates in.
+// Like the styles it is shared by every screen; NewModel sets it from the
+// config.
+var loc = i18n.English
+
+// setLocale switches the interface to the locale setting v, falling back
+// to English when it cannot be read.
+func setLocale(v string) {
+	l, err := i18n.Parse(v, os.Getenv)
+	if err != nil {
+		l = i18n.English
+	}
+	loc = l
+}
+
+// tr translates an interface string, written as an English fmt format,
+// into the configured language.
+func tr(key string, args ...any) string {
+	return loc.T(key, args...)
+}
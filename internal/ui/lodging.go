@@ -0,0 +1,204 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// lodgingList lists the hotels and rentals of a trip in the order they are
+// checked into, with the check-ins and check-outs due today.
+type lodgingList struct {
+	app      *app
+	trip     *models.Trip
+	lodgings []*models.Lodging
+	legs     []*models.Leg
+	cursor   int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newLodgingList(app *app, trip *models.Trip) lodgingList {
+	l := lodgingList{app: app, trip: trip}
+	l.reload()
+	return l
+}
+
+func (l lodgingList) Title() string { return tr("Lodging") }
+
+func (l lodgingList) currentTrip() *models.Trip { return l.trip }
+
+func (l lodgingList) Init() tea.Cmd {
+	return nil
+}
+
+func (l lodgingList) capturesEsc() bool { return l.confirmDelete }
+
+func (l lodgingList) help() []key.Binding {
+	return append([]key.Binding{
+		l.app.bind("up", "previous lodging"),
+		l.app.bind("down", "next lodging"),
+		l.app.bind("new", "add a hotel or rental"),
+		l.app.bind("edit", "edit the lodging"),
+		l.app.bind("delete", "remove the lodging with its expense"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *lodgingList) reload() {
+	if l.lodgings, l.err = l.app.store.ListLodgingsByTrip(l.app.ctx, l.trip.ID); l.err != nil {
+		return
+	}
+	l.legs, l.err = l.app.store.ListLegsByTrip(l.app.ctx, l.trip.ID)
+	l.cursor = clamp(l.cursor, 0, len(l.lodgings)-1)
+}
+
+func (l lodgingList) selected() *models.Lodging {
+	if len(l.lodgings) == 0 {
+		return nil
+	}
+	return l.lodgings[l.cursor]
+}
+
+func (l lodgingList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tripSavedMsg:
+		if msg.trip.ID == l.trip.ID {
+			l.trip = msg.trip
+		}
+		return l, nil
+	case lodgingsChangedMsg, legSavedMsg:
+		l.reload()
+		return l, nil
+	case historyMsg:
+		l.status = ""
+		l.reload()
+		return l, nil
+	case tea.KeyMsg:
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				lodging := l.selected()
+				c, err := newDeleteLodging(l.app.ctx, l.app.store, lodging)
+				if err == nil {
+					err = l.app.run(c)
+				}
+				if err != nil {
+					l.err = err
+				} else {
+					l.status = l.app.deletedHint(lodging.Name)
+				}
+				l.reload()
+				id := l.trip.ID
+				return l, func() tea.Msg { return lodgingsChangedMsg{tripID: id} }
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.lodgings)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			// A new stay starts where the last one ends.
+			day := l.trip.StartDate
+			for _, lodging := range l.lodgings {
+				if out := dateOf(lodging.CheckOut); out.After(day) {
+					day = out
+				}
+			}
+			return l, push(newLodgingForm(l.app, l.trip, newLodgingAt(l.trip, l.legs, day), true))
+		case l.app.edits(msg, "select"), l.app.is(msg, "edit"):
+			if lodging := l.selected(); lodging != nil {
+				return l, push(newLodgingForm(l.app, l.trip, lodging, false))
+			}
+		case l.app.is(msg, "delete"):
+			if l.selected() != nil {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l lodgingList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🏨 "+l.trip.Title) + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n\n")
+	}
+	if notices := models.LodgingNotices(l.lodgings, l.app.tripNow(l.trip)); len(notices) > 0 {
+		for _, n := range notices {
+			b.WriteString(warningStyle.Render(lodgingNotice(n, true)) + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if len(l.lodgings) == 0 {
+		b.WriteString("No lodging yet — press " + l.app.keyHint("new") + " to add where you stay.\n")
+	}
+	for i, lodging := range l.lodgings {
+		cursor := "  "
+		if i == l.cursor {
+			cursor = "👉"
+		}
+		fmt.Fprintf(&b, "%s %-24s %s\n", cursor, truncate(lodging.Name, 24), lodgingDates(l.app, lodging))
+		details := []string{plural(lodging.Nights(), "night", "nights")}
+		if lodging.Cost > 0 {
+			details = append(details, fmt.Sprintf("%.2f %s", lodging.Cost, lodging.Currency))
+		}
+		if lodging.Confirmation != "" {
+			details = append(details, "# "+lodging.Confirmation)
+		}
+		if lodging.Address != "" {
+			details = append(details, lodging.Address)
+		}
+		fmt.Fprintf(&b, "   %s\n", hintStyle.Render(strings.Join(details, " · ")))
+		if lodging.Notes != "" {
+			fmt.Fprintf(&b, "   %s\n", hintStyle.Render(truncate(lodging.Notes, 60)))
+		}
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Remove %s and the expense of its cost? y/n", l.selected().Name)) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render(l.app.keyHint("new")+" new • "+l.app.keyHint("edit")+" edit • "+
+		l.app.keyHint("delete")+" remove • "+l.app.keyHint("undo")+" undo • esc back") + "\n")
+	return b.String()
+}
+
+// lodgingDates renders the stay of a lodging, such as "1 Apr 15:00 → 4 Apr
+// 11:00".
+func lodgingDates(a *app, l *models.Lodging) string {
+	return a.formatDate(l.CheckIn) + " " + l.CheckIn.Format(models.TimeLayout) + " → " +
+		a.formatDate(l.CheckOut) + " " + l.CheckOut.Format(models.TimeLayout)
+}
+
+// lodgingNotice tells the check-in or check-out due, adding that it is due
+// today when it is.
+func lodgingNotice(n models.LodgingNotice, today bool) string {
+	name, at := n.Lodging.Name, n.At().Format(models.TimeLayout)
+	switch {
+	case n.CheckOut && today:
+		return "🏨 " + tr("Check out of %s by %s today", name, at)
+	case n.CheckOut:
+		return "🏨 " + tr("Check out of %s by %s", name, at)
+	case today:
+		return "🏨 " + tr("Check in at %s from %s today", name, at)
+	}
+	return "🏨 " + tr("Check in at %s from %s", name, at)
+}
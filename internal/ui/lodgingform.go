@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+const (
+	lodgingFieldName = iota
+	lodgingFieldAddress
+	lodgingFieldConfirmation
+	lodgingFieldCheckIn
+	lodgingFieldCheckInTime
+	lodgingFieldCheckOut
+	lodgingFieldCheckOutTime
+	lodgingFieldCost
+	lodgingFieldCurrency
+	lodgingFieldNotes
+)
+
+// lodgingForm adds or edits a lodging of a trip and saves it, with the
+// expense of its cost, on confirmation.
+type lodgingForm struct {
+	form
+	app     *app
+	trip    *models.Trip
+	lodging *models.Lodging
+	isNew   bool
+}
+
+func newLodgingForm(app *app, trip *models.Trip, l *models.Lodging, isNew bool) lodgingForm {
+	title := "🏨 Edit Lodging"
+	if isNew {
+		title = "🏨 New Lodging"
+	}
+	f := newForm(title,
+		newField("Name", "Hotel Gracery", "", required("name")),
+		newField("Address", "1-19-1 Kabukicho, Shinjuku", "Optional.", nil),
+		newField("Confirmation", "HX42QK", "Optional. The code of the booking.", nil),
+		newField("Check-in", app.cfg.DateFormat, "", app.validateDate),
+		newField("Check-in time", models.DefaultCheckInTime, "HH:MM, in the time zone of the place.", validateClock),
+		newField("Check-out", app.cfg.DateFormat, "", app.validateDate),
+		newField("Check-out time", models.DefaultCheckOutTime, "HH:MM, in the time zone of the place.", validateClock),
+		newField("Cost", "420", "Optional. The whole stay, recorded as a lodging expense.", validateAmount),
+		newField("Currency", "EUR", "Three-letter ISO code of the cost.", validateOptionalCurrency),
+		newField("Notes", "", "Optional. Such as how to get the keys.", nil),
+	)
+	f.fields[lodgingFieldCheckIn].dates = newDatePicker(app)
+	f.fields[lodgingFieldCheckOut].dates = newDateRangePicker(app, lodgingFieldCheckIn, "check-out must not be before check-in")
+	f.fields[lodgingFieldName].input.SetValue(l.Name)
+	f.fields[lodgingFieldAddress].input.SetValue(l.Address)
+	f.fields[lodgingFieldConfirmation].input.SetValue(l.Confirmation)
+	f.fields[lodgingFieldCheckIn].input.SetValue(app.formatDate(l.CheckIn))
+	f.fields[lodgingFieldCheckInTime].input.SetValue(l.CheckIn.Format(models.TimeLayout))
+	f.fields[lodgingFieldCheckOut].input.SetValue(app.formatDate(l.CheckOut))
+	f.fields[lodgingFieldCheckOutTime].input.SetValue(l.CheckOut.Format(models.TimeLayout))
+	if l.Cost != 0 {
+		f.fields[lodgingFieldCost].input.SetValue(strconv.FormatFloat(l.Cost, 'f', -1, 64))
+	}
+	currency := l.Currency
+	if currency == "" {
+		currency = app.cfg.DefaultCurrency
+	}
+	f.fields[lodgingFieldCurrency].input.SetValue(currency)
+	f.fields[lodgingFieldNotes].input.SetValue(l.Notes)
+	// Work on a copy so cancelling leaves the caller's lodging untouched.
+	edited := *l
+	return lodgingForm{form: f, app: app, trip: trip, lodging: &edited, isNew: isNew}
+}
+
+// newLodgingAt starts a lodging of trip checked into on day, until the
+// departure of the leg visited then or else the end of the trip.
+func newLodgingAt(trip *models.Trip, legs []*models.Leg, day time.Time) *models.Lodging {
+	var out time.Time
+	if trip.EndDate != nil {
+		out = *trip.EndDate
+	}
+	if leg := models.LegOn(legs, day); leg != nil && leg.Departure != nil {
+		out = *leg.Departure
+	}
+	if !out.After(day) {
+		out = day.AddDate(0, 0, 1)
+	}
+	checkIn, _ := models.LodgingTime(day, models.DefaultCheckInTime, "")
+	checkOut, _ := models.LodgingTime(out, models.DefaultCheckOutTime, "")
+	return models.NewLodging(trip.ID, "", checkIn, checkOut)
+}
+
+func (f lodgingForm) Title() string {
+	if f.isNew {
+		return tr("New lodging")
+	}
+	return tr("Edit %s", f.lodging.Name)
+}
+
+func (f lodgingForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f lodgingForm) draft() *models.Draft {
+	if !f.dirty {
+		return nil
+	}
+	return newDraft(models.DraftLodging, f.lodging.ID, f.Title(), f.value(lodgingFieldName), f.isNew, f.lodging, f.values())
+}
+
+func (f lodgingForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		if err := f.apply(); err != nil {
+			f.err = err
+			return f, nil
+		}
+		if err := f.app.store.SaveLodging(f.app.ctx, f.lodging); err != nil {
+			f.err = err
+			return f, nil
+		}
+		id, name := f.trip.ID, f.lodging.Name
+		return f, tea.Batch(tea.Sequence(pop, func() tea.Msg { return lodgingsChangedMsg{tripID: id} }),
+			toast(toastSuccess, "Saved %s", name))
+	}
+	return f, cmd
+}
+
+func (f lodgingForm) View() string {
+	return f.form.view(f.summary)
+}
+
+// apply copies the validated values into the lodging. It is stayed at on
+// the leg visited on the day of check-in, and its times are read in the
+// time zone of that leg or, without one, of the trip that day.
+func (f lodgingForm) apply() error {
+	inDay, err := f.app.parseDate(f.value(lodgingFieldCheckIn))
+	if err != nil {
+		return err
+	}
+	outDay, err := f.app.parseDate(f.value(lodgingFieldCheckOut))
+	if err != nil {
+		return err
+	}
+	legs, err := f.app.store.ListLegsByTrip(f.app.ctx, f.trip.ID)
+	if err != nil {
+		return err
+	}
+	l := f.lodging
+	at := ""
+	if leg := models.LegOn(legs, inDay); leg != nil {
+		l.LegID, at = leg.ID, leg.Location
+	}
+	l.TimeZone = places.DayZone(f.trip, legs, at, inDay)
+	if l.CheckIn, err = models.LodgingTime(inDay, f.value(lodgingFieldCheckInTime), l.TimeZone); err != nil {
+		return err
+	}
+	if l.CheckOut, err = models.LodgingTime(outDay, f.value(lodgingFieldCheckOutTime), l.TimeZone); err != nil {
+		return err
+	}
+	l.Cost = 0
+	if v := f.value(lodgingFieldCost); v != "" {
+		if l.Cost, err = strconv.ParseFloat(v, 64); err != nil {
+			return err
+		}
+	}
+	currency := f.value(lodgingFieldCurrency)
+	if currency == "" {
+		currency = f.app.cfg.DefaultCurrency
+	}
+	if l.Cost > 0 || currency != "" {
+		if l.Currency, err = models.ParseCurrency(currency); err != nil {
+			return err
+		}
+	}
+	l.Name = f.value(lodgingFieldName)
+	l.Address = f.value(lodgingFieldAddress)
+	l.Confirmation = f.value(lodgingFieldConfirmation)
+	l.Notes = f.value(lodgingFieldNotes)
+	return l.Validate()
+}
+
+func (f lodgingForm) summary() string {
+	var b strings.Builder
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-13s", label+":")), value)
+	}
+	row("Name", f.value(lodgingFieldName))
+	row("Address", f.value(lodgingFieldAddress))
+	row("Confirmation", f.value(lodgingFieldConfirmation))
+	row("Check-in", strings.TrimSpace(f.value(lodgingFieldCheckIn)+" "+f.value(lodgingFieldCheckInTime)))
+	row("Check-out", strings.TrimSpace(f.value(lodgingFieldCheckOut)+" "+f.value(lodgingFieldCheckOutTime)))
+	cost := ""
+	if v := f.value(lodgingFieldCost); v != "" && v != "0" {
+		cost = v + " " + strings.ToUpper(f.value(lodgingFieldCurrency))
+	}
+	row("Cost", cost)
+	row("Notes", f.value(lodgingFieldNotes))
+	return b.String()
+}
+
+func validateClock(v string) error {
+	_, err := models.LodgingTime(time.Now(), v, "")
+	return err
+}
+
+func validateOptionalCurrency(v string) error {
+	if v == "" {
+		return nil
+	}
+	return validateCurrency(v)
+}
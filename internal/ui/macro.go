@@ -0,0 +1,322 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+)
+
+// macroRecording is a macro being recorded: the keys pressed since the
+// record key was, on the screen it was pressed on.
+type macroRecording struct {
+	on    string
+	steps []config.MacroStep
+	// palette is how many steps there were when the palette was last
+	// opened, or -1.
+	palette int
+}
+
+// add records k, pressed while the screen was typing when typing is set:
+// text typed is kept as text, joining what was typed just before.
+func (r *macroRecording) add(k tea.KeyMsg, typing bool) {
+	text := ""
+	switch {
+	case k.Type == tea.KeyRunes && !k.Alt:
+		text = string(k.Runes)
+	case k.Type == tea.KeySpace && typing:
+		text = " "
+	}
+	if text == "" || !typing && utf8.RuneCountInString(text) == 1 && text != `"` {
+		name := k.String()
+		if k.Type == tea.KeySpace {
+			name = "space"
+		}
+		if text != "" {
+			name = text
+		}
+		r.steps = append(r.steps, config.MacroStep{Key: name})
+		return
+	}
+	if n := len(r.steps); n > 0 && r.steps[n-1].Key == "" {
+		r.steps[n-1].Text += text
+		return
+	}
+	r.steps = append(r.steps, config.MacroStep{Text: text})
+}
+
+// screenName names the kind of screen s for the screen a macro replays
+// on, such as "expenseList".
+func screenName(s screen) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", s), "ui.")
+}
+
+// keyTypes are the keys bubbletea names, such as "enter" or "ctrl+s", by
+// their names.
+var keyTypes = func() map[string]tea.KeyType {
+	types := map[string]tea.KeyType{}
+	for t := tea.KeyType(-200); t <= 200; t++ {
+		if name := t.String(); name != "" && t != tea.KeyRunes {
+			types[name] = t
+		}
+	}
+	return types
+}()
+
+// macroKeys turns the steps of a macro into the keys to press.
+func macroKeys(steps []config.MacroStep) ([]tea.KeyMsg, error) {
+	var keys []tea.KeyMsg
+	for _, st := range steps {
+		if st.Key == "" {
+			for _, r := range st.Text {
+				keys = append(keys, runeKey(r))
+			}
+			continue
+		}
+		k, err := parseKey(st.Key)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// parseKey reads the key named name, as tea.KeyMsg.String spells it but
+// for "space".
+func parseKey(name string) (tea.KeyMsg, error) {
+	k := tea.KeyMsg{}
+	if rest, ok := strings.CutPrefix(name, "alt+"); ok && rest != "" {
+		k.Alt, name = true, rest
+	}
+	if name == "space" {
+		k.Type, k.Runes = tea.KeySpace, []rune{' '}
+		return k, nil
+	}
+	if t, ok := keyTypes[name]; ok {
+		k.Type = t
+		return k, nil
+	}
+	if utf8.RuneCountInString(name) == 1 {
+		k.Type, k.Runes = tea.KeyRunes, []rune(name)
+		return k, nil
+	}
+	return k, fmt.Errorf("unknown key %q", name)
+}
+
+func runeKey(r rune) tea.KeyMsg {
+	if r == ' ' {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+// macroStepDelay is how long a macro waits between the keys it presses,
+// for the screens a key opens to be open for the next.
+const macroStepDelay = 20 * time.Millisecond
+
+// macroStepMsg presses the next key of the macro replaying.
+type macroStepMsg struct{}
+
+func macroStep() tea.Cmd {
+	return tea.Tick(macroStepDelay, func(time.Time) tea.Msg { return macroStepMsg{} })
+}
+
+// toggleRecording starts recording a macro on the screen on top, or stops
+// it and asks what to call the macro.
+func (m *Model) toggleRecording() tea.Cmd {
+	r := m.recording
+	if r == nil {
+		m.recording = &macroRecording{on: screenName(m.top()), palette: -1}
+		return nil
+	}
+	m.recording = nil
+	if len(r.steps) == 0 {
+		return toast(toastWarning, "%s", tr("Nothing recorded"))
+	}
+	return push(newMacroForm(m.app, r))
+}
+
+// runMacro replays the named macro on the screen on top.
+func (m *Model) runMacro(name string) tea.Cmd {
+	mc, ok := m.app.cfg.Macros[name]
+	if !ok {
+		return nil
+	}
+	if mc.On != "" && mc.On != screenName(m.top()) {
+		return toast(toastWarning, "%s", tr("Macro %s was recorded on another screen", name))
+	}
+	steps, err := config.SplitMacro(mc.Keys)
+	if err == nil {
+		m.replay, err = macroKeys(steps)
+	}
+	if err != nil {
+		return toast(toastError, "%s", tr("Macro %s: %s", name, err.Error()))
+	}
+	return macroStep()
+}
+
+// stepMacro presses the next key of the macro replaying.
+func (m Model) stepMacro() (Model, tea.Cmd) {
+	if len(m.replay) == 0 {
+		return m, nil
+	}
+	k := m.replay[0]
+	m.replay, m.stepping = m.replay[1:], true
+	m, cmd := m.update(k)
+	m.stepping = false
+	if len(m.replay) > 0 {
+		cmd = tea.Batch(cmd, macroStep())
+	}
+	return m, cmd
+}
+
+// macroFor is the name of the macro whose key k is, if any.
+func (a *app) macroFor(k tea.KeyMsg) (string, bool) {
+	for _, name := range a.cfg.MacroNames() {
+		bound := a.cfg.Macros[name].Key
+		if bound == "space" {
+			bound = " "
+		}
+		if bound != "" && k.String() == bound {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// macroHelp lists the macros with keys, for the help overlay.
+func (a *app) macroHelp() []key.Binding {
+	var out []key.Binding
+	for _, name := range a.cfg.MacroNames() {
+		if k := a.cfg.Macros[name].Key; k != "" {
+			out = append(out, fixed(k, tr("run macro %s", name)))
+		}
+	}
+	return out
+}
+
+// macroSavedMsg is sent once a macro has been saved in the config.
+type macroSavedMsg struct {
+	name string
+}
+
+// macroForm names a macro just recorded and gives it a key.
+type macroForm struct {
+	form
+	app       *app
+	recording *macroRecording
+}
+
+const (
+	macroFieldName = iota
+	macroFieldKey
+	macroFieldScreen
+)
+
+func newMacroForm(app *app, r *macroRecording) macroForm {
+	f := newForm("⏺ New macro",
+		newField("Name", "lunch", "Lower-case letters, digits and underscores; an existing macro is replaced.", validateMacroName),
+		newField("Key", "ctrl+l", "Optional. The key replaying it; else run it from the palette.", validateMacroKey),
+		newField("Only here", "yes", fmt.Sprintf("yes to replay it only on the screen it was recorded on (%s), no for any.", r.on), validateYesNo),
+	)
+	f.fields[macroFieldScreen].input.SetValue("yes")
+	return macroForm{form: f, app: app, recording: r}
+}
+
+func (f macroForm) Title() string { return tr("New macro") }
+
+func (f macroForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f macroForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		name := strings.ToLower(f.value(macroFieldName))
+		on := ""
+		if yes(f.value(macroFieldScreen)) {
+			on = f.recording.on
+		}
+		next := f.app.cfg
+		settings := []struct{ name, value string }{
+			{"macros." + name + ".keys", config.JoinMacro(f.recording.steps)},
+			{"macros." + name + ".key", f.value(macroFieldKey)},
+			{"macros." + name + ".on", on},
+		}
+		for _, s := range settings {
+			if err := next.Set(s.name, s.value); err != nil {
+				f.err = err
+				return f, nil
+			}
+		}
+		if f.app.setConfig != nil {
+			for _, s := range settings {
+				if err := f.app.setConfig(s.name, s.value); err != nil {
+					f.err = err
+					return f, nil
+				}
+			}
+		}
+		f.app.cfg = next
+		return f, tea.Sequence(pop, func() tea.Msg { return macroSavedMsg{name: name} })
+	}
+	return f, cmd
+}
+
+func (f macroForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		row := func(label, value string) {
+			if value == "" {
+				value = hintStyle.Render("—")
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-10s", label+":")), value)
+		}
+		on := tr("any screen")
+		if yes(f.value(macroFieldScreen)) {
+			on = f.recording.on
+		}
+		row("Name", strings.ToLower(f.value(macroFieldName)))
+		row("Key", f.value(macroFieldKey))
+		row("Replays on", on)
+		row("Presses", truncate(config.JoinMacro(f.recording.steps), 60))
+		return b.String()
+	})
+}
+
+func validateMacroName(v string) error {
+	if v == "" {
+		return errors.New("name is required")
+	}
+	for i, r := range strings.ToLower(v) {
+		if !(r >= 'a' && r <= 'z' || i > 0 && (r >= '0' && r <= '9' || r == '_')) {
+			return errors.New("use lower-case letters, digits and underscores, starting with a letter")
+		}
+	}
+	return nil
+}
+
+func validateMacroKey(v string) error {
+	if v == "" {
+		return nil
+	}
+	if strings.Contains(v, ",") {
+		return errors.New("name a single key, such as ctrl+l or f5")
+	}
+	_, err := parseKey(v)
+	return err
+}
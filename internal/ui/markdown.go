@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// markdownCacheSize is how many rendered texts a markdownRenderer keeps
+// before it starts over.
+const markdownCacheSize = 64
+
+// markdownRenderer renders entry bodies, rebuilding the glamour renderer
+// only when the wrap width or theme changes and remembering what it
+// rendered, so the texts of entries moved back and forth over are not
+// rendered again on every frame. It is shared by pointer because Bubbletea
+// models are copied on each update.
+type markdownRenderer struct {
+	app      *app
+	style    string
+	width    int
+	renderer *glamour.TermRenderer
+
+	// rendered holds the output of each text rendered at width in style.
+	rendered map[string]string
+}
+
+func (r *markdownRenderer) render(src string, width int) string {
+	if width < 20 {
+		width = 20
+	}
+	style := r.app.palette.Markdown
+	if r.renderer == nil || r.width != width || r.style != style {
+		tr, err := glamour.NewTermRenderer(
+			glamour.WithStandardStyle(style),
+			glamour.WithWordWrap(width),
+		)
+		if err != nil {
+			return src
+		}
+		r.renderer, r.width, r.style = tr, width, style
+		clear(r.rendered)
+	}
+	if out, ok := r.rendered[src]; ok {
+		return out
+	}
+	out, err := r.renderer.Render(src)
+	if err != nil {
+		return src
+	}
+	if len(r.rendered) >= markdownCacheSize {
+		clear(r.rendered)
+	}
+	r.rendered[src] = strings.TrimRight(out, "\n")
+	return r.rendered[src]
+}
+
+func newMarkdownRenderer(a *app) *markdownRenderer {
+	return &markdownRenderer{app: a, rendered: map[string]string{}}
+}
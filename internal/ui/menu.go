@@ -0,0 +1,323 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// menu is the home screen: a dashboard of the trip coming up, the latest
+// journal entries and the trip's spending, beside the top-level features.
+type menu struct {
+	choices  []string
+	cursor   int
+	selected map[int]struct{}
+	app      *app
+	// occasions are the days trips marked yearly come round in the next
+	// month.
+	occasions []models.Occasion
+	// deadlines are the tasks to prepare trips yet to leave due in the
+	// next two weeks, or overdue.
+	deadlines []models.PrepDeadline
+	// expiring are the travel documents in the vault running out soon or
+	// before a trip, or run out already.
+	expiring []models.ExpiryAlert
+	dash     dashboard
+	width    int
+
+	status string
+}
+
+// menuEdits are the choices that change the journal, greyed out in
+// read-only mode.
+var menuEdits = map[string]bool{"✈️  New Trip": true}
+
+// menuOccasionDays is how many days ahead the home screen looks for trips
+// coming round.
+const menuOccasionDays = 30
+
+// menuDeadlineDays is how many days ahead the home screen looks for tasks
+// to prepare trips falling due.
+const menuDeadlineDays = 14
+
+func newMenu(app *app) menu {
+	m := menu{
+		app: app,
+		choices: []string{
+			"✈️  New Trip",
+			"🧳 Trips",
+			"📋 Templates",
+			"🌠 Wishlist",
+			"📔 View Journal",
+			"📅 Calendar",
+			"💰 Expenses",
+			"🗺️  Itinerary",
+			"🎒 Packing",
+			"📤 Export",
+			"🏷️  Tags",
+			"👥 People",
+			"🛂 Countries",
+			"🔐 Vault",
+			"📊 Stats",
+			"⚖️  Compare Trips",
+			"🗺️  Map",
+			"🗑️  Trash",
+			"🛑 Quit",
+		},
+	}
+	m.reload()
+	return m
+}
+
+// reload looks up the dashboard, the trips coming round, the deadlines to
+// prepare them and the travel documents running out, leaving none when
+// they cannot be listed.
+func (m *menu) reload() {
+	m.occasions, m.deadlines, m.expiring = nil, nil, nil
+	trips, err := m.app.store.ListTrips(m.app.ctx)
+	if err != nil {
+		m.dash = dashboard{}
+		return
+	}
+	rates, ratesErr := m.dash.rates, m.dash.ratesErr
+	m.dash = m.app.loadDashboard(trips)
+	m.dash.rates, m.dash.ratesErr = rates, ratesErr
+	now := time.Now()
+	m.occasions = models.Occasions(trips, now, menuOccasionDays)
+	if tasks, err := m.app.store.ListPrepTasks(m.app.ctx); err == nil {
+		m.deadlines = models.PrepDeadlines(trips, tasks, now, menuDeadlineDays)
+	}
+	if items, err := m.app.store.ListVault(m.app.ctx); err == nil {
+		m.expiring = m.app.expiryAlerts(items, trips)
+	}
+}
+
+func (m menu) Title() string { return tr("Nomadic") }
+
+func (m menu) Init() tea.Cmd {
+	if m.dash.needsRates(m.app) {
+		return m.app.fetchRates()
+	}
+	return nil
+}
+
+func (m menu) help() []key.Binding {
+	return append([]key.Binding{
+		m.app.bind("up", "previous item"),
+		m.app.bind("down", "next item"),
+		m.app.bind("select", "open the item"),
+		m.app.bind("search", "search the journal"),
+		m.app.bind("quit", "quit nomadic"),
+	}, m.app.undoHelp()...)
+}
+
+func (m menu) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case tripSavedMsg:
+		m.reload()
+		return m, tea.Batch(toast(toastSuccess, "%s", tr("Saved trip %q", msg.trip.Title)), m.Init())
+	case historyMsg, prepChangedMsg, entrySavedMsg, expenseSavedMsg, expensesImportedMsg, vaultSavedMsg:
+		m.reload()
+		return m, m.Init()
+	case ratesMsg:
+		m.dash.rates, m.dash.ratesErr = msg.rates, msg.err
+	case themeChangedMsg:
+		m.status = tr("Theme: %s", msg.name)
+	case tea.KeyMsg:
+		switch {
+		case m.app.is(msg, "quit"):
+			return m, quit
+		case m.app.is(msg, "search"):
+			m.status = ""
+			return m, push(newSearch(m.app))
+		case m.app.is(msg, "undo"):
+			m.status = ""
+			return m, m.app.undo()
+		case m.app.is(msg, "redo"):
+			m.status = ""
+			return m, m.app.redo()
+		case m.app.is(msg, "up"):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case m.app.is(msg, "down"):
+			if m.cursor < len(m.choices)-1 {
+				m.cursor++
+			}
+		case m.app.is(msg, "select"):
+			m.status = ""
+			selected := m.choices[m.cursor]
+			if menuEdits[selected] && m.app.readOnly() {
+				m.status = tr(refusedText)
+				break
+			}
+			switch selected {
+			case "✈️  New Trip":
+				return m, push(newTripForm(m.app))
+			case "🧳 Trips":
+				return m, push(newTripBrowser(m.app))
+			case "📋 Templates":
+				return m, push(newTemplateList(m.app))
+			case "🌠 Wishlist":
+				return m, push(newWishList(m.app))
+			case "📔 View Journal":
+				return m, push(newMenuPicker(m.app, "Journal"))
+			case "📅 Calendar":
+				return m, push(newCalendarView(m.app))
+			case "💰 Expenses":
+				return m, push(newMenuPicker(m.app, "Expenses"))
+			case "🗺️  Itinerary":
+				return m, push(newMenuPicker(m.app, "Itinerary"))
+			case "🎒 Packing":
+				return m, push(newMenuPicker(m.app, "Packing"))
+			case "📤 Export":
+				return m, push(newMenuPicker(m.app, "Export"))
+			case "🏷️  Tags":
+				return m, push(newTagBrowser(m.app))
+			case "👥 People":
+				return m, push(newPeopleList(m.app))
+			case "🛂 Countries":
+				return m, push(newCountryList(m.app))
+			case "🔐 Vault":
+				return m, push(newVaultList(m.app))
+			case "📊 Stats":
+				return m, push(newStatsScreen(m.app))
+			case "⚖️  Compare Trips":
+				return m, push(newTripComparison(m.app))
+			case "🗺️  Map":
+				return m, push(newMapView(m.app))
+			case "🗑️  Trash":
+				return m, push(newTrashList(m.app))
+			case "🛑 Quit":
+				return m, quit
+			}
+		}
+	}
+	return m, nil
+}
+
+// tripScreens are the screens of a trip the menu opens once it is chosen,
+// by the title of the trip picker choosing it.
+var tripScreens = map[string]func(*app, *models.Trip) screen{
+	"Journal":   func(a *app, t *models.Trip) screen { return newEntryList(a, t) },
+	"Expenses":  func(a *app, t *models.Trip) screen { return newExpenseList(a, t) },
+	"Itinerary": func(a *app, t *models.Trip) screen { return newItineraryView(a, t) },
+	"Packing":   func(a *app, t *models.Trip) screen { return newPackingView(a, t) },
+	"Export":    func(a *app, t *models.Trip) screen { return newExportScreen(a, t) },
+}
+
+// newMenuPicker is the trip picker fronting the screen of tripScreens
+// with the title.
+func newMenuPicker(a *app, title string) tripPicker {
+	open := tripScreens[title]
+	return newTripPicker(a, title, func(t *models.Trip) screen { return open(a, t) })
+}
+
+func (m menu) View() string {
+	title := headerStyle.
+		Align(lipgloss.Center).
+		Render(tr("Nomadic – Your Travel Journal Companion"))
+
+	var choices strings.Builder
+	for i, choice := range m.choices {
+		// Choices are an icon and a name; only the name is translated.
+		_, name, _ := strings.Cut(choice, " ")
+		name = strings.TrimLeft(name, " ")
+		choice = choice[:len(choice)-len(name)] + tr(name)
+		cursor := ""
+		switch {
+		case menuEdits[m.choices[i]] && m.app.readOnly():
+			if m.cursor == i {
+				cursor = "👉"
+			}
+			choice = hintStyle.Render(choice)
+		case m.cursor == i:
+			cursor = "👉"
+			choice = cursorStyle.Render(choice)
+		}
+		fmt.Fprintf(&choices, "%s %s\n", cursor, choice)
+	}
+
+	var body string
+	if m.width >= homeWideWidth {
+		menuColumn := lipgloss.NewStyle().PaddingRight(4).Render(strings.TrimRight(choices.String(), "\n"))
+		dash := m.dashboardView(m.width - lipgloss.Width(menuColumn))
+		body = lipgloss.JoinHorizontal(lipgloss.Top, menuColumn, dash) + "\n"
+	} else {
+		body = choices.String() + "\n" + m.dashboardView(max(m.width, 40))
+	}
+
+	view := title + "\n" + body
+	if m.status != "" {
+		view += "\n" + m.status + "\n"
+	}
+	view += "\n" + hintStyle.Render(tr("%s search journal • %s undo • %s switch theme • %s quit",
+		m.app.keyHint("search"), m.app.keyHint("undo"), m.app.keyHint("theme"), m.app.keyHint("quit"))) + "\n"
+	return view
+}
+
+// dashboardView renders the dashboard in width columns: the trip coming
+// up, its spending, the latest entries, and the entries written on this
+// day in earlier years, the trips coming round, deadlines and travel
+// documents running out when there are any. Before the first trip there is only the invitation to plan one.
+func (m menu) dashboardView(width int) string {
+	parts := []string{m.dash.countdownView(m.app)}
+	if !m.dash.noTrips {
+		parts = append(parts, m.dash.spendView(m.app), m.dash.entriesView(m.app, width))
+	}
+	if len(m.dash.onThisDay) > 0 {
+		parts = append(parts, m.dash.onThisDayView(m.app, width))
+	}
+	if len(m.occasions) > 0 {
+		var b strings.Builder
+		b.WriteString(labelStyle.Render(tr("Coming up")) + "\n")
+		for _, o := range m.occasions {
+			icon := "🔁"
+			if o.Anniversary() {
+				icon = "🎉"
+			}
+			fmt.Fprintf(&b, "%s %s %s\n", icon, o, hintStyle.Render(occasionWhen(o.In)))
+		}
+		parts = append(parts, b.String())
+	}
+	if len(m.deadlines) > 0 {
+		var b strings.Builder
+		b.WriteString(labelStyle.Render(tr("Deadlines")) + "\n")
+		for _, d := range m.deadlines {
+			when := hintStyle.Render(prepWhen(d.In))
+			if d.In < 0 {
+				when = errorStyle.Render(prepWhen(d.In))
+			}
+			fmt.Fprintf(&b, "📋 %s: %s %s\n", d.Trip.Title, d.Task.Title, when)
+		}
+		parts = append(parts, b.String())
+	}
+	if len(m.expiring) > 0 {
+		var b strings.Builder
+		b.WriteString(labelStyle.Render(tr("Documents")) + "\n")
+		for _, a := range m.expiring {
+			fmt.Fprintf(&b, "%s %s %s\n", models.VaultIcon(a.Item.Kind), a.Item.Title, expiryStyle(a).Render(expiryWhen(a)))
+		}
+		parts = append(parts, b.String())
+	}
+	return strings.Join(parts, "\n")
+}
+
+// occasionWhen says how soon a day in days from today is.
+func occasionWhen(in int) string {
+	switch in {
+	case 0:
+		return tr("today")
+	case 1:
+		return tr("tomorrow")
+	}
+	return tr("in %d days", in)
+}
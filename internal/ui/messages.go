@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"github.com/girdharshubham/nomadic/internal/inbox"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// tripSavedMsg is sent once a trip has been written to the store.
+type tripSavedMsg struct {
+	trip *models.Trip
+}
+
+// entrySavedMsg is sent once a journal entry has been written to the store.
+type entrySavedMsg struct {
+	entry *models.Entry
+}
+
+// expenseSavedMsg is sent once an expense has been written to the store.
+type expenseSavedMsg struct {
+	expense *models.Expense
+	// learned is the rule remembered from correcting the category of an
+	// expense imported from a statement, if any.
+	learned *models.Rule
+	// merged is set when the expense typed in looked like a duplicate of
+	// expense and was merged into it.
+	merged bool
+}
+
+// itinerarySavedMsg is sent once an itinerary item has been written to the store.
+type itinerarySavedMsg struct {
+	item *models.ItineraryItem
+}
+
+// legSavedMsg is sent once a leg of a trip has been written to the store.
+type legSavedMsg struct {
+	leg *models.Leg
+}
+
+// templateSavedMsg is sent once a trip template has been written to the store.
+type templateSavedMsg struct {
+	template *models.Template
+}
+
+// packingChangedMsg is sent once a trip's packing list has changed.
+type packingChangedMsg struct {
+	tripID string
+}
+
+// highlightsChangedMsg is sent once a trip's goals and highlights have
+// changed.
+type highlightsChangedMsg struct {
+	tripID string
+}
+
+// prepChangedMsg is sent once a trip's checklist of things to do before
+// it leaves has changed.
+type prepChangedMsg struct {
+	tripID string
+}
+
+// lodgingsChangedMsg is sent once a trip's lodgings, and so perhaps its
+// expenses, have changed.
+type lodgingsChangedMsg struct {
+	tripID string
+}
+
+// healthChangedMsg is sent once what was logged of a day of a trip's
+// sleep, water or jetlag has changed.
+type healthChangedMsg struct {
+	tripID string
+}
+
+// expensesImportedMsg is sent once a CSV import has been saved.
+type expensesImportedMsg struct {
+	count int
+}
+
+// attachmentsChangedMsg is sent once files have been attached to or
+// removed from a journal entry.
+type attachmentsChangedMsg struct {
+	entryID string
+}
+
+// documentsChangedMsg is sent once documents have been added to, edited
+// on or removed from a trip.
+type documentsChangedMsg struct {
+	tripID string
+}
+
+// personSavedMsg is sent once a person has been written to the store.
+// renamed holds their name from before, when it changed.
+type personSavedMsg struct {
+	person  *models.Person
+	renamed string
+}
+
+// categorySavedMsg is sent once an expense category has been written to
+// the store, reordered or had another merged into it. renamed holds its
+// name from before, when it changed.
+type categorySavedMsg struct {
+	category *models.Category
+	renamed  string
+}
+
+// countryNoteSavedMsg is sent once a note on a country has been written to
+// the store.
+type countryNoteSavedMsg struct {
+	note *models.CountryNote
+}
+
+// wishSavedMsg is sent once a wish has been written to the store.
+type wishSavedMsg struct {
+	wish *models.Wish
+}
+
+// vaultSavedMsg is sent once a travel document has been written to the
+// vault.
+type vaultSavedMsg struct {
+	item *models.VaultItem
+}
+
+// achievementsUnlockedMsg is sent once achievements newly reached have
+// been recorded in the store.
+type achievementsUnlockedMsg struct{}
+
+// inboxMsg carries the receipts waiting in the receipts inbox, as it was
+// last looked at, or why it could not be.
+type inboxMsg struct {
+	receipts []inbox.Receipt
+	err      error
+}
+
+// recurrenceSavedMsg reports a recurring expense saved, with how many
+// expenses fell due and were recorded on saving it.
+type recurrenceSavedMsg struct {
+	recurrence *models.Recurrence
+	recorded   int
+}
+
+// checkInSavedMsg reports a check-in saved, or its position found.
+type checkInSavedMsg struct {
+	checkIn *models.CheckIn
+}
+
+func (tripSavedMsg) broadcast()            {}
+func (entrySavedMsg) broadcast()           {}
+func (expenseSavedMsg) broadcast()         {}
+func (itinerarySavedMsg) broadcast()       {}
+func (legSavedMsg) broadcast()             {}
+func (templateSavedMsg) broadcast()        {}
+func (packingChangedMsg) broadcast()       {}
+func (highlightsChangedMsg) broadcast()    {}
+func (prepChangedMsg) broadcast()          {}
+func (lodgingsChangedMsg) broadcast()      {}
+func (healthChangedMsg) broadcast()        {}
+func (expensesImportedMsg) broadcast()     {}
+func (attachmentsChangedMsg) broadcast()   {}
+func (documentsChangedMsg) broadcast()     {}
+func (personSavedMsg) broadcast()          {}
+func (categorySavedMsg) broadcast()        {}
+func (countryNoteSavedMsg) broadcast()     {}
+func (wishSavedMsg) broadcast()            {}
+func (vaultSavedMsg) broadcast()           {}
+func (checkInSavedMsg) broadcast()         {}
+func (recurrenceSavedMsg) broadcast()      {}
+func (achievementsUnlockedMsg) broadcast() {}
+func (inboxMsg) broadcast()                {}
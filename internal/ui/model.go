@@ -0,0 +1,580 @@
+// Package ui implements the nomadic terminal interface with Bubbletea.
+package ui
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/streak"
+)
+
+// screen is a tea.Model that can be pushed onto the navigation stack.
+// Title is shown in the breadcrumb trail.
+type screen interface {
+	tea.Model
+	Title() string
+}
+
+// escCapturer is implemented by screens that sometimes need Esc for
+// themselves, e.g. to dismiss a confirmation prompt, instead of going back.
+type escCapturer interface {
+	capturesEsc() bool
+}
+
+// broadcaster is implemented by messages that every screen on the stack
+// should see, such as notifications that a record changed, so screens
+// further down are already current when they are popped back to.
+type broadcaster interface {
+	broadcast()
+}
+
+// pushMsg opens a screen on top of the current one.
+type pushMsg struct {
+	screen screen
+}
+
+// popMsg closes the current screen and returns to the one below it.
+type popMsg struct{}
+
+func push(s screen) tea.Cmd {
+	return func() tea.Msg { return pushMsg{screen: s} }
+}
+
+func pop() tea.Msg { return popMsg{} }
+
+// Model is the top-level Bubbletea model. It owns a stack of screens:
+// messages go to the screen on top, Esc (the "back" key) pops it, ? (the
+// "help" key) lists the keys of the screen on top, and the header shows
+// the path from the home menu to the current screen, the trip in progress
+// or the next one, and the journaling streak.
+type Model struct {
+	app   *app
+	stack []screen
+
+	// sync is the last reported state of git sync, shown in the footer
+	// when the data directory is synced.
+	sync *syncStatusMsg
+	// toasts confirm changes and report failures in the footer, one at a
+	// time.
+	toasts toasts
+	// help shows the keys of the current screen in place of it.
+	help bool
+	// capture is quick capture, open over the current screen, or nil.
+	capture *quickCapture
+	// console is the debug console, open in place of the current screen,
+	// or nil.
+	console *debugConsole
+	// vim is the vim-style input layer, or nil unless the vim setting is
+	// on.
+	vim *vimMode
+	// recording is the macro being recorded, or nil. replay are the keys
+	// a macro replaying has yet to press, and stepping is set while it
+	// presses one.
+	recording *macroRecording
+	replay    []tea.KeyMsg
+	stepping  bool
+	// drafts holds the values last saved of each draft of the work in
+	// progress on the stack; draftErr is why saving one failed.
+	drafts   map[string][]string
+	draftErr error
+	// saved is where the TUI was when that was last saved, for the next
+	// start to offer to resume.
+	saved *models.Session
+	// streak is the journaling streak on the trip in progress and trip
+	// what the header tells of it, both measured when the store had
+	// streakAt changes.
+	streak   streak.Streak
+	trip     tripStatus
+	streakAt uint64
+	// crash is the panic of the screen on top as it was drawn, if any.
+	crash *crash
+
+	width, height int
+}
+
+// NewModel creates the application model with the home menu at the bottom
+// of the stack.
+func NewModel(opts Options) *Model {
+	a := &app{ctx: opts.Context, store: opts.Store, cfg: opts.Config, rates: opts.Rates, weather: opts.Weather, holidays: opts.Holidays,
+		geocoder: opts.Geocoder, ocr: opts.OCR, sync: opts.Sync, log: opts.Log, profile: opts.Profile, switchProfile: opts.Switch,
+		setConfig: opts.SetConfig}
+	if a.ctx == nil {
+		a.ctx = context.Background()
+	}
+	a.keys = newKeyMap(a.cfg)
+	if a.store != nil {
+		a.synced = a.store.Changes()
+	}
+	setAccessible(a.cfg.Accessible == config.AccessibleOn)
+	setASCII(!accessible && useASCII(a.cfg.ASCII, os.Getenv))
+	if err := a.setTheme(a.cfg.Theme); err != nil {
+		// The config was validated on load; fall back rather than fail.
+		a.setTheme("dark")
+	}
+	setLocale(a.cfg.Locale)
+	var vim *vimMode
+	if a.cfg.Vim == config.VimOn {
+		vim = newVimMode()
+	}
+	drafts := map[string][]string{}
+	if a.store == nil && opts.Setup != nil {
+		return &Model{app: a, stack: []screen{newSetup(a, opts.Setup)}, vim: vim, drafts: drafts, crash: &crash{}}
+	}
+	if a.store == nil && opts.Unlock != nil {
+		return &Model{app: a, stack: []screen{newUnlock(a, opts.Unlock)}, vim: vim, drafts: drafts, crash: &crash{}}
+	}
+	return &Model{app: a, stack: home(a), streak: a.streak(), trip: a.tripStatus(), streakAt: a.synced, vim: vim,
+		drafts: drafts, crash: &crash{}}
+}
+
+func (m Model) Init() tea.Cmd {
+	cmds := []tea.Cmd{m.app.syncStatus(), draftTick(), m.app.unlockAchievements()}
+	for _, s := range m.stack {
+		cmds = append(cmds, s.Init())
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m Model) top() screen {
+	return m.stack[len(m.stack)-1]
+}
+
+func (m *Model) setTop(s screen) {
+	m.stack[len(m.stack)-1] = s
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(syncStatusMsg); ok {
+		cmd := m.syncToast(msg)
+		m.sync = &msg
+		return m, cmd
+	}
+	if m.crash.err != nil {
+		m.repair()
+		// The key pressed was meant for the screen that failed.
+		if _, ok := msg.(tea.KeyMsg); ok {
+			return m, nil
+		}
+	}
+	depth := len(m.stack)
+	updated, cmd := m.update(msg)
+	updated.repair()
+	if len(updated.stack) != depth {
+		// Save the draft of a screen as soon as it opens, and drop it as
+		// soon as it closes.
+		updated.saveDrafts()
+	}
+	unlocked := updated.measureStreak()
+	// Whatever the message, commit what it saved.
+	return updated, tea.Batch(cmd, unlocked, m.app.autoSync())
+}
+
+// syncToast reports a sync that failed or diverged from the remote when
+// the last one had not, and one working again after failing: the footer
+// shows the state of sync only while no toast is on screen. A sync failing
+// for the store itself opens the error screen.
+func (m *Model) syncToast(msg syncStatusMsg) tea.Cmd {
+	last := m.sync
+	switch {
+	case msg.err != nil && (last == nil || last.err == nil || last.err.Error() != msg.err.Error()):
+		if storage.Unavailable(msg.err) {
+			return fail(tr("save the changes to git"), msg.err, nil)
+		}
+		return m.toasts.add(toastMsg{level: toastError, text: tr("Sync failed: %s", msg.err.Error())})
+	case msg.err == nil && msg.status.Diverged() && (last == nil || !last.status.Diverged()):
+		return m.toasts.add(toastMsg{level: toastWarning, text: tr("Diverged from the remote — run nomadic sync")})
+	case msg.err == nil && last != nil && last.err != nil:
+		return m.toasts.add(toastMsg{level: toastSuccess, text: tr("Sync is working again")})
+	}
+	return nil
+}
+
+// measureStreak measures the journaling streak and the trip status again
+// once something was saved or deleted, and returns the toasts of the
+// achievements that unlocked.
+func (m *Model) measureStreak() tea.Cmd {
+	if m.app.store == nil || m.app.store.Changes() == m.streakAt {
+		return nil
+	}
+	m.streak, m.trip = m.app.streak(), m.app.tripStatus()
+	cmd := m.app.unlockAchievements()
+	// Recording achievements is a change of its own, already measured.
+	m.streakAt = m.app.store.Changes()
+	return cmd
+}
+
+func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		slog.Debug("ui: resized", "width", msg.Width, "height", msg.Height)
+		// Every screen keeps its layout current so popping back never
+		// shows a stale size.
+		var cmds []tea.Cmd
+		for i, s := range m.stack {
+			var cmd tea.Cmd
+			m.stack[i], cmd = m.updateScreen(s, m.contentSize())
+			cmds = append(cmds, cmd)
+		}
+		return m, tea.Batch(cmds...)
+
+	case pushMsg:
+		m.help = false
+		if m.vim != nil {
+			m.vim.normal = false
+		}
+		m.stack = append(m.stack, msg.screen)
+		slog.Debug("ui: opened", "screen", msg.screen.Title(), "depth", len(m.stack))
+		init := msg.screen.Init()
+		updated, cmd := m.updateScreen(msg.screen, m.contentSize())
+		m.setTop(updated)
+		return m, tea.Batch(init, cmd)
+
+	case failedMsg:
+		m.help = false
+		f := newFailure(m.app, msg)
+		if _, ok := m.top().(failure); ok {
+			m.setTop(f)
+		} else {
+			m.stack = append(m.stack, f)
+		}
+		return m, nil
+
+	case historyMsg:
+		if storage.Unavailable(msg.err) {
+			return m, fail(tr("undo or redo"), msg.err, nil)
+		}
+		t := toastMsg{level: toastSuccess, text: msg.text}
+		if msg.err != nil {
+			t = toastMsg{level: toastError, text: msg.err.Error()}
+		}
+		show := m.toasts.add(t)
+		updated, cmd := m.broadcast(msg)
+		return updated, tea.Batch(cmd, show)
+
+	case toastMsg:
+		return m, m.toasts.add(msg)
+
+	case toastExpiredMsg:
+		return m, m.toasts.expire(msg.seq)
+
+	case broadcaster:
+		return m.broadcast(msg)
+
+	case draftTickMsg:
+		m.saveDrafts()
+		m.saveSession()
+		return m, tea.Batch(draftTick(), m.app.scanInbox())
+
+	case quitMsg:
+		m.saveDrafts()
+		m.saveSession()
+		return m, tea.Quit
+
+	case consoleTickMsg:
+		if m.console == nil {
+			return m, nil
+		}
+		return m, consoleTick()
+
+	case weatherMsg:
+		return m, m.app.recordWeather(msg)
+
+	case rateMsg:
+		return m, m.app.recordRate(msg)
+
+	case checkInLocatedMsg:
+		return m, m.app.recordPosition(msg)
+
+	case unlockedMsg:
+		return m.opened(msg.store)
+
+	case setupDoneMsg:
+		m.app.cfg, m.app.keys = msg.cfg, newKeyMap(msg.cfg)
+		m, cmd := m.opened(msg.store)
+		if msg.firstTrip {
+			cmd = tea.Batch(cmd, push(newTripForm(m.app)))
+		}
+		return m, cmd
+
+	case switchProfileMsg:
+		m.saveDrafts()
+		m.saveSession()
+		slog.Info("ui: switching profile", "from", m.app.profileName(), "to", msg.profile)
+		m.app.switchProfile(msg.profile)
+		return m, tea.Quit
+
+	case macroStepMsg:
+		return m.stepMacro()
+
+	case macroSavedMsg:
+		return m, toast(toastSuccess, "%s", tr("Saved macro %s", msg.name))
+
+	case paletteRunMsg:
+		if _, ok := m.top().(palette); ok {
+			m.stack = m.stack[:len(m.stack)-1]
+		}
+		return m, msg.run(&m)
+
+	case popMsg:
+		m.help = false
+		if m.vim != nil {
+			m.vim.normal = false
+		}
+		if len(m.stack) > 1 {
+			slog.Debug("ui: closed", "screen", m.top().Title(), "depth", len(m.stack)-1)
+			m.stack = m.stack[:len(m.stack)-1]
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			m.saveDrafts()
+			m.saveSession()
+			return m, tea.Quit
+		}
+		// A key pressed stops a macro replaying, and one being recorded
+		// records it.
+		if !m.stepping && len(m.replay) > 0 {
+			m.replay = nil
+			return m, toast(toastWarning, "%s", tr("Stopped the macro"))
+		}
+		typedRecord := m.typing() && (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace)
+		if !typedRecord && !m.stepping && m.app.store != nil && m.app.is(msg, "record") {
+			return m, m.toggleRecording()
+		}
+		if m.recording != nil && !m.stepping {
+			m.recording.add(msg, m.typing())
+		}
+		if m.capture != nil {
+			return m.updateCapture(msg)
+		}
+		if m.console != nil {
+			if m.console.update(m.app, msg, m.height) {
+				m.console = nil
+			}
+			return m, nil
+		}
+		if m.help {
+			if m.app.is(msg, "help") || m.app.is(msg, "back") || m.app.is(msg, "quit") {
+				m.help = false
+			}
+			return m, nil
+		}
+		if m.vim != nil {
+			if cmd, done := m.vimKey(msg); done {
+				return m, cmd
+			}
+		}
+		// Letters typed into a text input are text, not actions.
+		if m.typing() && (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace) {
+			break
+		}
+		if m.app.is(msg, "help") {
+			m.help = true
+			return m, nil
+		}
+		if m.app.is(msg, "debug") && m.app.log != nil {
+			m.console = &debugConsole{}
+			return m, consoleTick()
+		}
+		if m.app.is(msg, "theme") {
+			return m, m.app.nextTheme()
+		}
+		if name, ok := m.app.macroFor(msg); ok && !m.stepping && m.app.store != nil {
+			return m, m.runMacro(name)
+		}
+		// Confirmation prompts take any key as an answer, and there is
+		// nothing to check in to or capture into before the store is open.
+		if c, ok := m.top().(escCapturer); m.app.is(msg, "checkin") && (!ok || !c.capturesEsc()) && m.app.store != nil {
+			return m, m.checkIn()
+		}
+		if c, ok := m.top().(escCapturer); m.app.is(msg, "quick") && (!ok || !c.capturesEsc()) && m.app.store != nil {
+			return m, m.openCapture()
+		}
+		// The palette and search move through their results with it.
+		if c, ok := m.top().(escCapturer); m.app.is(msg, "palette") && (!ok || !c.capturesEsc()) && m.app.store != nil {
+			switch m.top().(type) {
+			case palette, search:
+			default:
+				return m, m.openPalette()
+			}
+		}
+		if m.app.is(msg, "back") && len(m.stack) > 1 {
+			if c, ok := m.top().(escCapturer); !ok || !c.capturesEsc() {
+				return m, pop
+			}
+		}
+	}
+
+	updated, cmd := m.updateScreen(m.top(), msg)
+	m.setTop(updated)
+	if m.app.refused {
+		m.app.refused = false
+		cmd = tea.Batch(cmd, notify(historyMsg{text: tr(refusedText)}))
+	}
+	return m, cmd
+}
+
+// opened replaces the unlock screen or the first-run wizard with the home
+// menu once the store is open.
+func (m Model) opened(store *storage.Store) (Model, tea.Cmd) {
+	m.app.store, m.app.synced = store, store.Changes()
+	m.stack = home(m.app)
+	m.streak, m.trip, m.streakAt = m.app.streak(), m.app.tripStatus(), m.app.synced
+	var cmds []tea.Cmd
+	for i, s := range m.stack {
+		updated, cmd := s.Update(m.contentSize())
+		m.stack[i] = updated.(screen)
+		cmds = append(cmds, s.Init(), cmd)
+	}
+	return m, tea.Batch(append(cmds, m.app.unlockAchievements())...)
+}
+
+// typing reports whether printable keys are text for the screen on top
+// rather than actions: it has a text input focused and, in vim mode, is
+// not in normal mode.
+func (m Model) typing() bool {
+	t, ok := m.top().(typist)
+	return ok && t.typing() && (m.vim == nil || !m.vim.normal)
+}
+
+// broadcast sends msg to every screen on the stack.
+func (m Model) broadcast(msg tea.Msg) (Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	for i, s := range m.stack {
+		var cmd tea.Cmd
+		m.stack[i], cmd = m.updateScreen(s, msg)
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// contentSize is the space left for screens between the breadcrumb
+// header and the footer.
+func (m Model) contentSize() tea.WindowSizeMsg {
+	return tea.WindowSizeMsg{Width: m.width, Height: max(m.height-4, 0)}
+}
+
+// slowRender is how long drawing a screen may take before it is logged.
+const slowRender = 50 * time.Millisecond
+
+func (m Model) View() string {
+	start := time.Now()
+	view := m.viewScreen(m.top())
+	if took := time.Since(start); took > slowRender {
+		slog.Debug("ui: slow render", "screen", m.top().Title(), "took", took)
+	}
+	switch {
+	case m.console != nil:
+		view = m.console.view(m.app, m.width, m.contentSize().Height)
+	case m.help:
+		view = m.helpView()
+	}
+	header := m.trip.badge()
+	if badge := streakBadge(m.streak); badge != "" && header != "" {
+		header += "  " + badge
+	} else if badge != "" {
+		header = badge
+	}
+	if len(m.stack) > 1 {
+		crumbs := m.breadcrumbs()
+		if header != "" {
+			crumbs += "  " + header
+		}
+		header = crumbs
+	}
+	footer := m.footer()
+	if header != "" {
+		view = header + "\n\n" + view
+	}
+	// Quick capture goes between the screen and the footer, taking lines
+	// from the end of the screen when short of room.
+	if m.capture != nil {
+		footer = m.capture.view(m.width) + "\n" + footer
+	}
+	// Past the height of the terminal the renderer would drop the header;
+	// drop the end of the screen instead, keeping the footer. The final
+	// newline takes a line of its own.
+	height := 0
+	if m.height > 0 {
+		height = max(m.height-lipgloss.Height(footer)-1, 1)
+	}
+	switch {
+	case accessible:
+		view, footer = plainText(view), plainText(footer)
+	case asciiOnly:
+		view, footer = asciiText(view), asciiText(footer)
+	}
+	view = fit(view, m.width, height)
+	return view + "\n" + fit(footer, m.width, 0) + "\n"
+}
+
+// footer shows the toast on screen, or else the state of
+// git sync or read-only mode, next to the key for help. In vim mode the command line, or the
+// outcome of the last command, comes first.
+func (m Model) footer() string {
+	if m.vim != nil && m.vim.prompting {
+		return m.vimStatus()
+	}
+	var help string
+	if k := m.helpKey(); k != "" && !m.help {
+		help = hintStyle.Render(tr("%s help", k))
+	}
+	var status string
+	switch {
+	case m.draftErr != nil:
+		status = errorStyle.Render(tr("Autosave failed: %s", m.draftErr.Error()))
+	case len(m.toasts.queue) > 0:
+		status = m.toasts.view()
+	case m.app.sync != nil:
+		status = syncIndicator(m.sync)
+	case m.app.readOnly():
+		status = hintStyle.Render(tr("🔒 Read-only"))
+	}
+	parts := []string{status, help}
+	if p := m.app.profileName(); p != config.DefaultProfile {
+		parts = append([]string{hintStyle.Render("👤 " + p)}, parts...)
+	}
+	if m.app.cfg.Offline == config.OfflineOn {
+		parts = append([]string{hintStyle.Render("📴 " + tr("Offline"))}, parts...)
+	}
+	if m.recording != nil {
+		parts = append([]string{warningStyle.Render(tr("⏺ Recording a macro, %s to stop", m.app.keyHint("record")))}, parts...)
+	}
+	if m.vim != nil {
+		parts = append([]string{m.vimStatus()}, parts...)
+	}
+	return strings.Join(slices.DeleteFunc(parts, func(p string) bool { return p == "" }), "  ")
+}
+
+// helpKey names a key that opens help on the current screen: while it is
+// typing, printable keys are text, so only keys such as F1 do.
+func (m Model) helpKey() string {
+	for _, k := range m.app.cfg.KeysFor("help") {
+		if !m.typing() || (len([]rune(k)) > 1 && k != "space") {
+			return k
+		}
+	}
+	return ""
+}
+
+func (m Model) breadcrumbs() string {
+	parts := make([]string, len(m.stack))
+	for i, s := range m.stack {
+		parts[i] = s.Title()
+	}
+	last := len(parts) - 1
+	trail := hintStyle.Render(strings.Join(parts[:last], " › ") + " › ")
+	return trail + labelStyle.Render(parts[last])
+}
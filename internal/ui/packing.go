@@ -0,0 +1,383 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// packingMode is what the packing screen is doing besides showing the list.
+type packingMode int
+
+const (
+	packingBrowse packingMode = iota
+	packingAdd                // typing a new item
+	packingSave               // typing the name of a master list
+	packingApply              // choosing a master list to add
+)
+
+// packingView is a trip's packing checklist, grouped by category.
+type packingView struct {
+	app    *app
+	trip   *models.Trip
+	items  []*models.PackingItem
+	cursor int
+
+	mode  packingMode
+	input textinput.Model
+	// lists are the master lists offered while applying one.
+	lists      []*models.PackingList
+	listCursor int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newPackingView(app *app, trip *models.Trip) packingView {
+	in := textinput.New()
+	in.Width = 40
+	v := packingView{app: app, trip: trip, input: in}
+	v.reload()
+	return v
+}
+
+func (v packingView) Title() string { return tr("Packing") }
+
+func (v packingView) currentTrip() *models.Trip { return v.trip }
+
+func (v packingView) Init() tea.Cmd {
+	return nil
+}
+
+func (v packingView) capturesEsc() bool { return v.confirmDelete || v.mode != packingBrowse }
+
+func (v packingView) typing() bool { return v.mode == packingAdd || v.mode == packingSave }
+
+func (v packingView) help() []key.Binding {
+	switch v.mode {
+	case packingAdd:
+		return []key.Binding{fixed("enter", "add the item, as category: name or just a name"), fixed("esc", "done")}
+	case packingSave:
+		return []key.Binding{fixed("enter", "save the master list"), fixed("esc", "cancel")}
+	case packingApply:
+		return []key.Binding{
+			v.app.bind("up", "previous list"),
+			v.app.bind("down", "next list"),
+			v.app.bind("select", "add the list to this trip"),
+			v.app.bind("delete", "delete the list"),
+			fixed("esc", "cancel"),
+		}
+	}
+	return append([]key.Binding{
+		v.app.bind("up", "previous item"),
+		v.app.bind("down", "next item"),
+		v.app.bind("toggle", "pack or unpack the item"),
+		v.app.bind("add", "add items"),
+		v.app.bind("delete", "remove the item"),
+		v.app.bind("lists", "add a master list"),
+		v.app.bind("save_list", "save as a master list"),
+	}, v.app.undoHelp()...)
+}
+
+func (v *packingView) reload() {
+	v.items, v.err = v.app.store.ListPackingByTrip(v.app.ctx, v.trip.ID)
+	v.cursor = clamp(v.cursor, 0, len(v.items)-1)
+}
+
+func (v packingView) selected() *models.PackingItem {
+	if len(v.items) == 0 {
+		return nil
+	}
+	return v.items[v.cursor]
+}
+
+// changed reloads the list and tells the other screens about it.
+func (v *packingView) changed() tea.Cmd {
+	v.reload()
+	id := v.trip.ID
+	return func() tea.Msg { return packingChangedMsg{tripID: id} }
+}
+
+func (v packingView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case historyMsg:
+		v.status = ""
+		v.reload()
+		return v, nil
+	case tea.KeyMsg:
+		switch v.mode {
+		case packingAdd, packingSave:
+			return v.updateInput(msg)
+		case packingApply:
+			return v.updateApply(msg)
+		}
+		if v.confirmDelete {
+			v.confirmDelete = false
+			if msg.String() == "y" {
+				it := v.selected()
+				if err := v.app.run(deletePackingItem{item: it}); err != nil {
+					v.err = err
+				} else {
+					v.status = v.app.deletedHint(it.Name)
+				}
+				return v, v.changed()
+			}
+			return v, nil
+		}
+		if cmd, ok := v.app.undoKeys(msg); ok {
+			return v, cmd
+		}
+
+		switch {
+		case v.app.is(msg, "up"):
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case v.app.is(msg, "down"):
+			if v.cursor < len(v.items)-1 {
+				v.cursor++
+			}
+		case v.app.edits(msg, "toggle"), v.app.edits(msg, "select"):
+			it := v.selected()
+			if it == nil {
+				break
+			}
+			toggled := *it
+			toggled.Packed = !toggled.Packed
+			if err := v.app.store.SavePackingItem(v.app.ctx, &toggled); err != nil {
+				v.err = err
+				return v, nil
+			}
+			v.status, v.err = "", nil
+			return v, v.changed()
+		case v.app.is(msg, "add"):
+			v.mode, v.status, v.err = packingAdd, "", nil
+			v.input.Placeholder = "Clothes: rain jacket"
+			v.input.SetValue("")
+			return v, v.input.Focus()
+		case v.app.is(msg, "delete"):
+			if v.selected() != nil {
+				v.confirmDelete = true
+			}
+		case v.app.is(msg, "save_list"):
+			v.mode, v.status, v.err = packingSave, "", nil
+			v.input.Placeholder = "Essentials"
+			v.input.SetValue("")
+			return v, v.input.Focus()
+		case v.app.is(msg, "lists"):
+			lists, err := v.app.store.ListPackingLists(v.app.ctx)
+			if err != nil {
+				v.err = err
+				return v, nil
+			}
+			if len(lists) == 0 {
+				v.status = "No master lists yet — press " + v.app.keyHint("save_list") + " to save this list as one."
+				return v, nil
+			}
+			v.mode, v.lists, v.listCursor, v.status, v.err = packingApply, lists, 0, "", nil
+		}
+	}
+	return v, nil
+}
+
+// updateInput edits a new item or the name of a master list, and acts on
+// it on Enter.
+func (v packingView) updateInput(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		v.mode = packingBrowse
+		v.input.Blur()
+		return v, nil
+	case "enter":
+		value := strings.TrimSpace(v.input.Value())
+		if v.mode == packingAdd {
+			return v.add(value)
+		}
+		return v.saveList(value)
+	}
+	var cmd tea.Cmd
+	v.input, cmd = v.input.Update(key)
+	return v, cmd
+}
+
+// add puts the item typed in value on the list. Without a category it
+// joins the category of the selected item. The input stays open for the
+// next item.
+func (v packingView) add(value string) (tea.Model, tea.Cmd) {
+	li := models.ParsePackingItem(value)
+	if li.Name == "" {
+		v.err = errors.New("enter something to pack, optionally as category: name")
+		return v, nil
+	}
+	if !strings.Contains(value, ":") {
+		if it := v.selected(); it != nil {
+			li.Category = it.Category
+		}
+	}
+	n, err := v.app.store.AddPackingItems(v.app.ctx, v.trip.ID, []models.PackingListItem{li})
+	if err != nil {
+		v.err = err
+		return v, nil
+	}
+	v.err = nil
+	v.input.SetValue("")
+	if n == 0 {
+		v.status = fmt.Sprintf("%q is already on the list", li.Name)
+		return v, nil
+	}
+	v.status = fmt.Sprintf("Added %q", li.Name)
+	cmd := v.changed()
+	for i, it := range v.items {
+		if strings.EqualFold(it.Name, li.Name) && strings.EqualFold(it.Category, li.Category) {
+			v.cursor = i
+		}
+	}
+	return v, cmd
+}
+
+// saveList saves the trip's list as the master list called name.
+func (v packingView) saveList(name string) (tea.Model, tea.Cmd) {
+	if name == "" {
+		v.err = errors.New("enter a name for the master list")
+		return v, nil
+	}
+	if len(v.items) == 0 {
+		v.err = errors.New("add some items before saving the list")
+		return v, nil
+	}
+	l, err := v.app.store.SavePackingListAs(v.app.ctx, name, models.PackingListItems(v.items))
+	if err != nil {
+		v.err = err
+		return v, nil
+	}
+	v.mode, v.err = packingBrowse, nil
+	v.input.Blur()
+	return v, toast(toastSuccess, "Saved master list %q", l.Name)
+}
+
+// updateApply chooses a master list and adds its items on Enter.
+func (v packingView) updateApply(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.String() == "esc":
+		v.mode = packingBrowse
+	case v.app.is(key, "up"):
+		if v.listCursor > 0 {
+			v.listCursor--
+		}
+	case v.app.is(key, "down"):
+		if v.listCursor < len(v.lists)-1 {
+			v.listCursor++
+		}
+	case v.app.is(key, "delete"):
+		l := v.lists[v.listCursor]
+		if err := v.app.run(deletePackingList{list: l}); err != nil {
+			v.err = err
+			return v, nil
+		}
+		v.mode, v.status = packingBrowse, v.app.deletedHint(l.Name)
+	case v.app.is(key, "select"):
+		l := v.lists[v.listCursor]
+		n, err := v.app.store.AddPackingItems(v.app.ctx, v.trip.ID, l.Items)
+		if err != nil {
+			v.err = err
+			return v, nil
+		}
+		v.mode = packingBrowse
+		v.status = fmt.Sprintf("Added %s from %q", plural(n, "item", "items"), l.Name)
+		return v, v.changed()
+	}
+	return v, nil
+}
+
+func (v packingView) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🎒 Packing • "+v.trip.Title) + "\n\n")
+	if len(v.items) > 0 {
+		b.WriteString(packingProgress(v.items) + "\n")
+	} else {
+		b.WriteString("Nothing to pack yet — press " + v.app.keyHint("add") + " to add items or " +
+			v.app.keyHint("lists") + " to add a master list.\n")
+	}
+
+	category := "\x00"
+	for i, it := range v.items {
+		if it.Category != category {
+			category = it.Category
+			name := category
+			if name == "" {
+				name = models.UncategorizedPacking
+			}
+			b.WriteString("\n" + labelStyle.Render(name) + "\n")
+		}
+		cursor, box, name := "  ", "☐", it.Name
+		if it.Packed {
+			box, name = successStyle.Render("☑"), hintStyle.Render(name)
+		}
+		if i == v.cursor {
+			cursor, name = "👉", cursorStyle.Render(it.Name)
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, box, name)
+	}
+
+	switch v.mode {
+	case packingAdd:
+		b.WriteString("\n" + labelStyle.Render("Add item") + "\n" + v.input.View() + "\n")
+	case packingSave:
+		b.WriteString("\n" + labelStyle.Render("Save as master list") + "\n" + v.input.View() + "\n")
+	case packingApply:
+		b.WriteString("\n" + labelStyle.Render("Add a master list") + "\n")
+		for i, l := range v.lists {
+			cursor, name := "  ", l.Name
+			if i == v.listCursor {
+				cursor, name = "👉", cursorStyle.Render(name)
+			}
+			fmt.Fprintf(&b, "%s %s %s\n", cursor, name, hintStyle.Render(plural(len(l.Items), "item", "items")))
+		}
+	}
+	if v.err != nil {
+		b.WriteString("\n" + errorStyle.Render(v.err.Error()) + "\n")
+	}
+	if v.status != "" {
+		b.WriteString("\n" + v.status + "\n")
+	}
+	if v.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Remove %q? y/n", v.selected().Name)) + "\n")
+	}
+	a := v.app
+	hint := a.keyHint("toggle") + " pack/unpack • " + a.keyHint("add") + " add • " + a.keyHint("delete") + " remove • " +
+		a.keyHint("lists") + " add master list • " + a.keyHint("save_list") + " save as master list • " +
+		a.keyHint("undo") + " undo • esc back"
+	switch v.mode {
+	case packingAdd:
+		hint = "enter add (category: name, or just a name for the current category) • esc done"
+	case packingSave:
+		hint = "enter save • esc cancel"
+	case packingApply:
+		hint = "↑/↓ move • enter add to this trip • " + a.keyHint("delete") + " delete list • esc cancel"
+	}
+	b.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return b.String()
+}
+
+// packingProgress renders how much of items is packed as a bar.
+func packingProgress(items []*models.PackingItem) string {
+	packed, total := models.PackingProgress(items)
+	if total == 0 {
+		return ""
+	}
+	ratio := float64(packed) / float64(total)
+	filled := int(ratio*budgetBarWidth + 0.5)
+	style := warningStyle
+	if packed == total {
+		style = successStyle
+	}
+	bar := style.Render(strings.Repeat("█", filled)) + hintStyle.Render(strings.Repeat("░", budgetBarWidth-filled))
+	return fmt.Sprintf("%s %3.0f%%  %d of %d packed", bar, ratio*100, packed, total)
+}
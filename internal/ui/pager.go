@@ -0,0 +1,123 @@
+package ui
+
+import "fmt"
+
+// pager moves a cursor through a list too long to hold at once, such as a
+// journal of thousands of entries. It knows how many rows there are and
+// loads only the page on screen, again whenever the cursor leaves it.
+//
+// The functions counting and loading the rows are set with source, and
+// set again whenever what the list shows changes: screens are values, so
+// the functions must not hold on to the screen itself.
+type pager[T any] struct {
+	count func() (int, error)
+	load  func(offset, limit int) ([]T, error)
+
+	// rows is the page on screen, starting at offset in the list.
+	rows   []T
+	offset int
+	cursor int
+	total  int
+	size   int
+}
+
+func newPager[T any](size int) pager[T] {
+	return pager[T]{size: max(size, 1)}
+}
+
+// source sets how the rows are counted and loaded and reloads the page,
+// keeping the cursor where it was as far as the rows allow.
+func (p *pager[T]) source(count func() (int, error), load func(offset, limit int) ([]T, error)) error {
+	p.count, p.load = count, load
+	return p.reload()
+}
+
+// reload counts the rows again and loads the page with the cursor, for
+// when rows were added, changed or deleted.
+func (p *pager[T]) reload() error {
+	total, err := p.count()
+	if err != nil {
+		p.rows, p.total = nil, 0
+		return err
+	}
+	p.total = total
+	return p.moveTo(p.cursor, true)
+}
+
+// resize sets how many rows a page holds.
+func (p *pager[T]) resize(size int) error {
+	if size = max(size, 1); size == p.size {
+		return nil
+	}
+	p.size = size
+	return p.moveTo(p.cursor, true)
+}
+
+// move moves the cursor by delta rows.
+func (p *pager[T]) move(delta int) error { return p.moveTo(p.cursor+delta, false) }
+
+// pageUp and pageDown move the cursor by a page.
+func (p *pager[T]) pageUp() error   { return p.move(-p.size) }
+func (p *pager[T]) pageDown() error { return p.move(p.size) }
+
+// first and last move the cursor to the ends of the list.
+func (p *pager[T]) first() error { return p.moveTo(0, false) }
+func (p *pager[T]) last() error  { return p.moveTo(p.total-1, false) }
+
+// moveTo puts the cursor on row i, loading its page unless it is on the
+// one loaded already or force is set.
+func (p *pager[T]) moveTo(i int, force bool) error {
+	p.cursor = clamp(i, 0, p.total-1)
+	offset := p.cursor / p.size * p.size
+	if !force && offset == p.offset && p.rows != nil {
+		return nil
+	}
+	rows, err := p.load(offset, p.size)
+	if err != nil {
+		return err
+	}
+	p.rows, p.offset = rows, offset
+	// The rows may have changed since they were counted.
+	if len(rows) == 0 && p.total > 0 {
+		p.total, p.cursor = offset, max(offset-1, 0)
+		if offset > 0 {
+			return p.moveTo(p.cursor, true)
+		}
+	}
+	p.cursor = clamp(p.cursor, offset, offset+len(rows)-1)
+	return nil
+}
+
+// page returns the rows on screen.
+func (p pager[T]) page() []T { return p.rows }
+
+// at reports whether row i of the page is under the cursor.
+func (p pager[T]) at(i int) bool { return p.offset+i == p.cursor }
+
+// selected returns the row under the cursor, if there are any rows.
+func (p pager[T]) selected() (T, bool) {
+	var zero T
+	if i := p.cursor - p.offset; i >= 0 && i < len(p.rows) {
+		return p.rows[i], true
+	}
+	return zero, false
+}
+
+// all loads every row, not only the page on screen, for acting on rows
+// of other pages, such as those marked for a bulk action.
+func (p pager[T]) all() ([]T, error) {
+	if p.total == 0 {
+		return nil, nil
+	}
+	return p.load(0, p.total)
+}
+
+// status describes where the cursor is, such as "41 of 4210 · page 3 of
+// 211".
+func (p pager[T]) status() string {
+	if p.total == 0 {
+		return "0 of 0"
+	}
+	pages := (p.total + p.size - 1) / p.size
+	return fmt.Sprintf("%d of %d · page %d of %d", p.cursor+1, p.total, p.cursor/p.size+1, pages)
+}
@@ -0,0 +1,370 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/fuzzy"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// paletteItem is one thing the command palette reaches: an action, or a
+// trip, entry or expense to open.
+type paletteItem struct {
+	icon   string
+	name   string
+	detail string
+	run    func(m *Model) tea.Cmd
+	// edits is set on actions that change the journal, left out in
+	// read-only mode.
+	edits bool
+}
+
+// paletteMatch is an item matching the query, with the positions of the
+// letters matched in its name and detail.
+type paletteMatch struct {
+	item      int
+	score     int
+	positions []int
+}
+
+// paletteRunMsg closes the palette and does what the item chosen does,
+// from the screen the palette was opened over.
+type paletteRunMsg struct {
+	run func(m *Model) tea.Cmd
+}
+
+// palette finds any trip, entry, expense or action by a few letters of its
+// name, over whichever screen is open.
+type palette struct {
+	app     *app
+	input   textinput.Model
+	items   []paletteItem
+	matches []paletteMatch
+	cursor  int
+	height  int
+	err     error
+}
+
+// openPalette opens the palette, with the actions on the trip of the
+// nearest screen about one first.
+func (m *Model) openPalette() tea.Cmd {
+	var trip *models.Trip
+	for i := len(m.stack) - 1; i >= 0 && trip == nil; i-- {
+		if t, ok := m.stack[i].(tripper); ok {
+			trip = t.currentTrip()
+		}
+	}
+	if r := m.recording; r != nil {
+		// The key opening the palette is recorded already.
+		r.palette = len(r.steps) - 1
+	}
+	return push(newPalette(m.app, trip))
+}
+
+func newPalette(app *app, current *models.Trip) palette {
+	in := textinput.New()
+	in.Placeholder = tr("new expense, export lisbon, ramen")
+	in.Prompt = "❯ "
+	in.CharLimit = 256
+	in.Width = 50
+	in.Focus()
+	p := palette{app: app, input: in}
+	p.err = p.load(current)
+	p.filter()
+	return p
+}
+
+// load gathers the items: the actions on the current trip, the actions
+// on no trip in particular, then every trip, the actions on the others,
+// and the entries and expenses, newest first.
+func (p *palette) load(current *models.Trip) error {
+	trips, err := p.app.store.ListTrips(p.app.ctx)
+	if err != nil {
+		return err
+	}
+	entries, err := p.app.store.ListEntryPage(p.app.ctx, storage.EntryQuery{Brief: true}, 0, -1)
+	if err != nil {
+		return err
+	}
+	expenses, err := p.app.store.ListExpenses(p.app.ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*models.Trip, len(trips))
+	for _, t := range trips {
+		byID[t.ID] = t
+	}
+	if current != nil {
+		p.items = append(p.items, p.tripActions(current)...)
+	}
+	p.items = append(p.items, p.actions()...)
+	for _, t := range trips {
+		detail := strings.Join(t.Locations, ", ")
+		if t.Archived() {
+			detail = strings.TrimPrefix(detail+" · "+tr("archived"), " · ")
+		}
+		p.items = append(p.items, paletteItem{
+			icon: "🧳", name: t.Title, detail: detail,
+			run: func(*Model) tea.Cmd { return push(newTripDetail(p.app, t)) },
+		})
+	}
+	for _, t := range trips {
+		if current == nil || t.ID != current.ID {
+			p.items = append(p.items, p.tripActions(t)...)
+		}
+	}
+	for _, e := range slices.Backward(entries) {
+		t := byID[e.TripID]
+		if t == nil {
+			continue
+		}
+		name := e.Title
+		if name == "" {
+			name = tr("Untitled")
+		}
+		p.items = append(p.items, paletteItem{
+			icon: "📔", name: name, detail: t.Title + " · " + p.app.formatDate(e.Timestamp),
+			run: func(*Model) tea.Cmd {
+				e, err := p.app.store.GetEntry(p.app.ctx, e.ID)
+				if err != nil {
+					return toastErr(err)
+				}
+				return push(newEntryReader(p.app, e))
+			},
+		})
+	}
+	for _, x := range slices.Backward(expenses) {
+		t := byID[x.TripID]
+		if t == nil {
+			continue
+		}
+		p.items = append(p.items, paletteItem{
+			icon: "💰", name: x.Description,
+			detail: formatAmount(x.Amount, x.Currency) + " · " + t.Title + " · " + p.app.formatDate(x.Timestamp),
+			run:    func(*Model) tea.Cmd { return push(newExpenseDetail(p.app, t, x)) },
+		})
+	}
+	if p.app.readOnly() {
+		p.items = slices.DeleteFunc(p.items, func(it paletteItem) bool { return it.edits })
+	}
+	return nil
+}
+
+// actions are the commands on no trip in particular.
+func (p *palette) actions() []paletteItem {
+	a := p.app
+	open := func(icon, name string, s func() screen) paletteItem {
+		return paletteItem{icon: icon, name: tr(name), run: func(*Model) tea.Cmd { return push(s()) }}
+	}
+	edit := func(icon, name string, s func() screen) paletteItem {
+		it := open(icon, name, s)
+		it.edits = true
+		return it
+	}
+	items := []paletteItem{
+		edit("✈️", "New trip", func() screen { return newTripForm(a) }),
+		open("🔍", "Search the journal", func() screen { return newSearch(a) }),
+		open("📅", "Calendar", func() screen { return newCalendarView(a) }),
+		open("📋", "Templates", func() screen { return newTemplateList(a) }),
+		open("🌠", "Wishlist", func() screen { return newWishList(a) }),
+		open("🏷️", "Tags", func() screen { return newTagBrowser(a) }),
+		open("👥", "People", func() screen { return newPeopleList(a) }),
+		open("🗂️", "Expense categories", func() screen { return newCategoryList(a) }),
+		open("🛂", "Countries", func() screen { return newCountryList(a) }),
+		open("🔐", "Vault", func() screen { return newVaultList(a) }),
+		open("📊", "Stats", func() screen { return newStatsScreen(a) }),
+		open("⚖️", "Compare trips", func() screen { return newTripComparison(a) }),
+		open("🗺️", "Map", func() screen { return newMapView(a) }),
+		open("🗑️", "Trash", func() screen { return newTrashList(a) }),
+		{icon: "✏️", name: tr("Quick note"), run: func(m *Model) tea.Cmd { return m.openCapture() }, edits: true},
+		{icon: "🎨", name: tr("Switch theme"), run: func(m *Model) tea.Cmd { return m.app.nextTheme() }},
+	}
+	if a.switchProfile != nil {
+		items = append(items, open("👤", "Profiles", func() screen { return newProfileList(a) }))
+	}
+	items = append(items, paletteItem{icon: "⏺", name: tr("Record a macro, or stop recording"), edits: true, run: func(m *Model) tea.Cmd {
+		// Stopping from the palette leaves out the keys choosing to.
+		if r := m.recording; r != nil && r.palette >= 0 {
+			r.steps = r.steps[:r.palette]
+		}
+		return m.toggleRecording()
+	}})
+	for _, name := range a.cfg.MacroNames() {
+		items = append(items, paletteItem{icon: "▶️", name: tr("Run macro %s", name), detail: a.cfg.Macros[name].Key,
+			run: func(m *Model) tea.Cmd { return m.runMacro(name) }})
+	}
+	return items
+}
+
+// tripActions are the commands on trip t.
+func (p *palette) tripActions(t *models.Trip) []paletteItem {
+	a := p.app
+	open := func(icon, name string, s func() screen) paletteItem {
+		return paletteItem{icon: icon, name: tr(name), detail: t.Title, run: func(*Model) tea.Cmd { return push(s()) }}
+	}
+	edit := func(icon, name string, s func() screen) paletteItem {
+		it := open(icon, name, s)
+		it.edits = true
+		return it
+	}
+	items := []paletteItem{
+		edit("📝", "New entry", func() screen {
+			return newEntryEditor(a, models.NewEntry(t.ID, "", a.tripNow(t)), true)
+		}),
+		edit("💸", "New expense", func() screen {
+			return newExpenseForm(a, models.NewExpense(t.ID, 0, a.cfg.DefaultCurrency, "", "", a.tripNow(t)), true)
+		}),
+		edit("💸", "Quick expense", func() screen { return newExpenseLine(a, t, a.cfg.DefaultCurrency) }),
+		open("📔", "Journal", func() screen { return newEntryList(a, t) }),
+		open("💰", "Expenses", func() screen { return newExpenseList(a, t) }),
+		open("🗺️", "Itinerary", func() screen { return newItineraryView(a, t) }),
+		open("🎒", "Packing", func() screen { return newPackingView(a, t) }),
+		open("🕰️", "Timeline", func() screen { return newTimelineView(a, t) }),
+		open("🌟", "Highlights", func() screen { return newHighlightsView(a, t) }),
+		open("📋", "Preparation", func() screen { return newPrepView(a, t) }),
+		open("🏨", "Lodging", func() screen { return newLodgingList(a, t) }),
+		edit("💤", "Sleep and jetlag", func() screen { return newHealthForm(a, t) }),
+		edit("💼", "Budget", func() screen { return newBudgetForm(a, t) }),
+		open("🗂️", "Documents", func() screen { return newDocumentList(a, t, nil) }),
+		open("📤", "Export trip", func() screen { return newExportScreen(a, t) }),
+		{icon: "📌", name: tr("Check in"), detail: t.Title, run: func(*Model) tea.Cmd { return push(newCheckInForm(a, t)) }, edits: true},
+	}
+	if len(a.cfg.TripFields) > 0 {
+		items = append(items, edit("🪪", "Trip fields", func() screen { return newTripFieldsForm(a, t) }))
+	}
+	return items
+}
+
+func (p palette) Title() string { return tr("Go to") }
+
+func (p palette) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (p palette) typing() bool { return true }
+
+func (p palette) help() []key.Binding {
+	return []key.Binding{
+		fixed("↑/ctrl+p", "previous match"),
+		fixed("↓/ctrl+n", "next match"),
+		fixed("enter", "open it or do it"),
+	}
+}
+
+// filter ranks the items matching the query, best first; items matching
+// alike keep the order they were gathered in.
+func (p *palette) filter() {
+	query := p.input.Value()
+	p.matches = nil
+	for i, it := range p.items {
+		score, positions, ok := fuzzy.Match(query, it.name+" "+it.detail)
+		if ok {
+			p.matches = append(p.matches, paletteMatch{item: i, score: score, positions: positions})
+		}
+	}
+	slices.SortStableFunc(p.matches, func(a, b paletteMatch) int { return cmp.Compare(b.score, a.score) })
+	p.cursor = 0
+}
+
+func (p palette) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.height = msg.Height
+		p.input.Width = max(min(msg.Width, 100)-6, 20)
+		return p, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "ctrl+p":
+			if p.cursor > 0 {
+				p.cursor--
+			}
+			return p, nil
+		case "down", "ctrl+n":
+			if p.cursor < len(p.matches)-1 {
+				p.cursor++
+			}
+			return p, nil
+		case "enter":
+			if len(p.matches) == 0 {
+				return p, nil
+			}
+			run := p.items[p.matches[p.cursor].item].run
+			return p, notify(paletteRunMsg{run: run})
+		}
+	}
+	before := p.input.Value()
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	if p.input.Value() != before {
+		p.filter()
+	}
+	return p, cmd
+}
+
+func (p palette) View() string {
+	var b strings.Builder
+	b.WriteString(p.input.View() + "\n\n")
+	if p.err != nil {
+		b.WriteString(errorStyle.Render(p.err.Error()) + "\n")
+	}
+	if len(p.matches) == 0 && p.err == nil {
+		b.WriteString(tr("Nothing matches.") + "\n")
+	}
+
+	visible := len(p.matches)
+	if p.height > 0 {
+		visible = max(p.height-6, 1)
+	}
+	from, to := window(len(p.matches), p.cursor, visible)
+	for i := from; i < to; i++ {
+		m := p.matches[i]
+		it := p.items[m.item]
+		cursor := "  "
+		if i == p.cursor {
+			cursor = "👉"
+		}
+		name := markMatched(it.name, m.positions, 0, labelStyle.Render)
+		line := fmt.Sprintf("%s %s %s", cursor, it.icon, name)
+		if it.detail != "" {
+			offset := len([]rune(it.name)) + 1
+			line += "  " + markMatched(it.detail, m.positions, offset, hintStyle.Render)
+		}
+		b.WriteString(line + "\n")
+	}
+	if len(p.matches) > 0 {
+		b.WriteString("\n" + hintStyle.Render(tr("%d of %d", p.cursor+1, len(p.matches))) + "\n")
+	}
+	b.WriteString(hintStyle.Render(tr("type to find • ↑/↓ move • enter open • esc back")) + "\n")
+	return b.String()
+}
+
+// markMatched renders s with style, and the letters at positions, less
+// offset, highlighted.
+func markMatched(s string, positions []int, offset int, style func(...string) string) string {
+	var b strings.Builder
+	var run []rune
+	flush := func() {
+		if len(run) > 0 {
+			b.WriteString(style(string(run)))
+			run = run[:0]
+		}
+	}
+	for i, r := range []rune(s) {
+		if slices.Contains(positions, i+offset) {
+			flush()
+			b.WriteString(highlightStyle.Render(string(r)))
+			continue
+		}
+		run = append(run, r)
+	}
+	flush()
+	return b.String()
+}
@@ -0,0 +1,330 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// peopleList lists the people the traveler goes on trips with, to add,
+// edit and delete them.
+type peopleList struct {
+	app    *app
+	people []*models.Person
+	cursor int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newPeopleList(app *app) peopleList {
+	l := peopleList{app: app}
+	l.reload()
+	return l
+}
+
+func (l peopleList) Title() string { return tr("People") }
+
+func (l peopleList) Init() tea.Cmd {
+	return nil
+}
+
+func (l peopleList) capturesEsc() bool { return l.confirmDelete }
+
+func (l peopleList) help() []key.Binding {
+	return append([]key.Binding{
+		l.app.bind("up", "previous person"),
+		l.app.bind("down", "next person"),
+		l.app.bind("select", "show their trips and entries"),
+		l.app.bind("new", "add a person"),
+		l.app.bind("edit", "edit the person"),
+		l.app.bind("delete", "delete the person"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *peopleList) reload() {
+	l.people, l.err = l.app.store.ListPeople(l.app.ctx)
+	l.cursor = clamp(l.cursor, 0, len(l.people)-1)
+}
+
+func (l peopleList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case personSavedMsg:
+		l.reload()
+		for i, p := range l.people {
+			if p.ID == msg.person.ID {
+				l.cursor = i
+			}
+		}
+		if msg.renamed != "" {
+			return l, toast(toastSuccess, "Renamed %s to %s on every trip", msg.renamed, msg.person.Name)
+		}
+		return l, toast(toastSuccess, "Saved %s", msg.person.Name)
+	case historyMsg:
+		l.status = ""
+		l.reload()
+	case tea.KeyMsg:
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				p := l.people[l.cursor]
+				if err := l.app.run(deletePerson{person: p}); err != nil {
+					l.err = err
+					return l, nil
+				}
+				l.status = l.app.deletedHint(p.Name)
+				l.reload()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.people)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			l.status = ""
+			return l, push(newPersonForm(l.app, nil))
+		case l.app.is(msg, "select"):
+			if len(l.people) > 0 {
+				l.status = ""
+				return l, push(newPersonDetail(l.app, l.people[l.cursor]))
+			}
+		case l.app.is(msg, "edit"):
+			if len(l.people) > 0 {
+				l.status = ""
+				return l, push(newPersonForm(l.app, l.people[l.cursor]))
+			}
+		case l.app.is(msg, "delete"):
+			if len(l.people) > 0 {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l peopleList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("👥 People") + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n")
+		return b.String()
+	}
+	if len(l.people) == 0 {
+		b.WriteString("Nobody yet — press " + l.app.keyHint("new") + " to add someone, or add companions to a trip.\n")
+	}
+	for i, p := range l.people {
+		cursor, name := "  ", p.Name
+		if i == l.cursor {
+			cursor, name = "👉", cursorStyle.Render(name)
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, name, hintStyle.Render(p.Contact))
+	}
+	if len(l.people) > 0 && l.people[l.cursor].Notes != "" {
+		b.WriteString("\n" + hintStyle.Render(truncate(l.people[l.cursor].Notes, 72)) + "\n")
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		p := l.people[l.cursor]
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Delete %s? Trips and expenses keep the name. y/n", p.Name)) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • enter show • "+l.app.keyHint("new")+" new • "+l.app.keyHint("edit")+" edit • "+
+		l.app.keyHint("delete")+" delete • "+l.app.keyHint("undo")+" undo • esc back") + "\n")
+	return b.String()
+}
+
+// personDetail shows a person's contact and notes with the trips they
+// traveled on and the entries written with them.
+type personDetail struct {
+	app     *app
+	person  *models.Person
+	trips   []*models.Trip
+	entries []*models.Entry
+	err     error
+}
+
+func newPersonDetail(app *app, p *models.Person) personDetail {
+	d := personDetail{app: app, person: p}
+	d.reload()
+	return d
+}
+
+func (d personDetail) Title() string { return d.person.Name }
+
+func (d personDetail) Init() tea.Cmd {
+	return nil
+}
+
+func (d personDetail) help() []key.Binding {
+	return []key.Binding{d.app.bind("edit", "edit the person"), d.app.bind("quit", "close")}
+}
+
+func (d *personDetail) reload() {
+	if d.trips, d.err = d.app.store.ListTripsWith(d.app.ctx, d.person.Name); d.err != nil {
+		return
+	}
+	d.entries, d.err = d.app.store.ListEntriesWith(d.app.ctx, d.person.Name)
+}
+
+func (d personDetail) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case personSavedMsg:
+		if msg.person.ID == d.person.ID {
+			d.person = msg.person
+			d.reload()
+		}
+	case tripSavedMsg, entrySavedMsg, historyMsg:
+		d.reload()
+	case tea.KeyMsg:
+		switch {
+		case d.app.is(msg, "quit"):
+			return d, pop
+		case d.app.is(msg, "edit"):
+			return d, push(newPersonForm(d.app, d.person))
+		}
+	}
+	return d, nil
+}
+
+func (d personDetail) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("👤 "+d.person.Name) + "\n\n")
+	if d.err != nil {
+		b.WriteString(errorStyle.Render(d.err.Error()) + "\n\n")
+	}
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-8s", label+":")), value)
+	}
+	row("Contact", d.person.Contact)
+	row("Notes", d.person.Notes)
+
+	b.WriteString("\n" + labelStyle.Render("Trips") + " " + hintStyle.Render(plural(len(d.trips), "trip", "trips")) + "\n")
+	for _, t := range d.trips {
+		fmt.Fprintf(&b, "  %s %s\n", t.Title, hintStyle.Render(d.app.formatDate(t.StartDate)))
+	}
+	b.WriteString("\n" + labelStyle.Render("Entries") + " " + hintStyle.Render(plural(len(d.entries), "entry", "entries")) + "\n")
+	for _, e := range d.entries {
+		fmt.Fprintf(&b, "  %s %s\n", hintStyle.Render(d.app.formatDate(e.Timestamp)), e.Title)
+	}
+	b.WriteString("\n" + hintStyle.Render(d.app.keyHint("edit")+" edit • esc back") + "\n")
+	return b.String()
+}
+
+// personForm adds a person, or edits one. Renaming someone renames them on
+// every trip, entry and expense naming them.
+type personForm struct {
+	form
+	app    *app
+	person *models.Person
+	isNew  bool
+}
+
+const (
+	personFieldName = iota
+	personFieldContact
+	personFieldNotes
+)
+
+func newPersonForm(app *app, p *models.Person) personForm {
+	title := "👤 New person"
+	isNew := p == nil
+	if isNew {
+		p = models.NewPerson("")
+	} else {
+		title = "👤 Edit " + p.Name
+	}
+	f := newForm(title,
+		newField("Name", "Ana", "How trips and expenses name them.", models.CheckPersonName),
+		newField("Contact", "ana@example.com, +351 912 345 678", "Optional. A phone number, email or handle.", nil),
+		newField("Notes", "", "Optional.", nil),
+	)
+	f.fields[personFieldName].input.SetValue(p.Name)
+	f.fields[personFieldContact].input.SetValue(p.Contact)
+	f.fields[personFieldNotes].input.SetValue(p.Notes)
+	// Work on a copy so cancelling leaves the caller's person untouched.
+	edited := *p
+	return personForm{form: f, app: app, person: &edited, isNew: isNew}
+}
+
+func (f personForm) Title() string {
+	if f.isNew {
+		return tr("New person")
+	}
+	return tr("Edit %s", f.person.Name)
+}
+
+func (f personForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f personForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		p, renamed, err := f.save()
+		if err != nil {
+			f.err = err
+			return f, nil
+		}
+		return f, tea.Sequence(pop, func() tea.Msg { return personSavedMsg{person: p, renamed: renamed} })
+	}
+	return f, cmd
+}
+
+// save writes the person, renaming them first when their name changed. It
+// returns their name before when it did.
+func (f personForm) save() (*models.Person, string, error) {
+	p, name := f.person, f.value(personFieldName)
+	p.Contact, p.Notes = f.value(personFieldContact), f.value(personFieldNotes)
+	renamed := ""
+	if f.isNew {
+		p.Name = name
+	} else if name != p.Name {
+		renamed = p.Name
+		if err := f.app.store.RenamePerson(f.app.ctx, p, name); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := f.app.store.SavePerson(f.app.ctx, p); err != nil {
+		return nil, "", err
+	}
+	return p, renamed, nil
+}
+
+func (f personForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		for i, label := range []string{"Name", "Contact", "Notes"} {
+			v := f.value(i)
+			if v == "" {
+				v = hintStyle.Render("—")
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(label+":"), v)
+		}
+		return b.String()
+	})
+}
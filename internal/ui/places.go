@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+// newPlaceCompleter completes the names of cities and countries from the
+// offline places dataset, matching loosely so "kyt" finds Kyoto. With list
+// set it completes the last of comma-separated names.
+func newPlaceCompleter(list bool) *autocomplete {
+	return &autocomplete{list: list, source: func(typed string) []suggestion {
+		var out []suggestion
+		for _, p := range places.Suggest(typed, maxSuggestions) {
+			out = append(out, suggestion{value: p.Name, detail: p.Country, positions: p.Positions})
+		}
+		return out
+	}}
+}
+
+// placeDetails describes a known place as "Japan · 35.01°N 135.77°E ·
+// Asia/Tokyo", or returns "" for a name not in the dataset.
+func placeDetails(name string) string {
+	p, ok := places.Lookup(name)
+	if !ok {
+		return ""
+	}
+	return p.CountryName + " · " + p.Coordinates() + " · " + p.Timezone
+}
+
+// tripCountries lists the countries of a trip's known destinations.
+func tripCountries(t *models.Trip) string {
+	codes := places.Countries(places.Resolve(t.Locations))
+	names := make([]string, len(codes))
+	for i, c := range codes {
+		names[i] = places.CountryName(c)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,402 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// prepMode is what the preparation screen is doing besides showing the
+// checklist.
+type prepMode int
+
+const (
+	prepBrowse prepMode = iota
+	prepAdd             // typing a new task
+	prepEdit            // changing the selected one
+)
+
+// prepView is the checklist of things to get done before a trip leaves,
+// each due a lead time before departure.
+type prepView struct {
+	app   *app
+	trip  *models.Trip
+	tasks []*models.PrepTask
+	// templates is how many tasks the templates for the trip would add.
+	templates int
+	cursor    int
+
+	mode prepMode
+	// title and lead are the task being added or edited; focus is 1 while
+	// typing the lead.
+	title textinput.Model
+	lead  textinput.Model
+	focus int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newPrepView(app *app, trip *models.Trip) prepView {
+	title := textinput.New()
+	title.Width = 40
+	title.Placeholder = "Book travel insurance"
+	lead := textinput.New()
+	lead.Width = 10
+	lead.Placeholder = "14d"
+	v := prepView{app: app, trip: trip, title: title, lead: lead}
+	v.reload()
+	return v
+}
+
+func (v prepView) Title() string { return tr("Preparation") }
+
+func (v prepView) currentTrip() *models.Trip { return v.trip }
+
+func (v prepView) Init() tea.Cmd {
+	return nil
+}
+
+func (v prepView) capturesEsc() bool { return v.confirmDelete || v.mode != prepBrowse }
+
+func (v prepView) typing() bool { return v.mode != prepBrowse }
+
+func (v prepView) help() []key.Binding {
+	if v.mode != prepBrowse {
+		return []key.Binding{
+			fixed("tab", "switch between the task and when it is due"),
+			fixed("enter", "save the task"),
+			fixed("esc", "cancel"),
+		}
+	}
+	return append([]key.Binding{
+		v.app.bind("up", "previous task"),
+		v.app.bind("down", "next task"),
+		v.app.bind("toggle", "check the task off, or make it to do again"),
+		v.app.bind("add", "add tasks"),
+		v.app.bind("edit", "change the task or when it is due"),
+		v.app.bind("generate", "add the tasks of the templates for the trip's type"),
+		v.app.bind("delete", "remove the task"),
+	}, v.app.undoHelp()...)
+}
+
+func (v *prepView) reload() {
+	if v.tasks, v.err = v.app.store.ListPrepTasksByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	templates, err := v.app.store.ListPrepTemplates(v.app.ctx)
+	if err != nil {
+		v.err = err
+		return
+	}
+	v.templates = len(models.PrepTasksFor(v.trip, templates, v.tasks))
+	v.cursor = clamp(v.cursor, 0, len(v.tasks)-1)
+}
+
+func (v prepView) selected() *models.PrepTask {
+	if len(v.tasks) == 0 {
+		return nil
+	}
+	return v.tasks[v.cursor]
+}
+
+// changed reloads the checklist and tells the other screens about it.
+func (v *prepView) changed() tea.Cmd {
+	v.reload()
+	id := v.trip.ID
+	return func() tea.Msg { return prepChangedMsg{tripID: id} }
+}
+
+// save writes p, a new task or a changed copy of the selected one, and
+// reloads.
+func (v *prepView) save(p *models.PrepTask) tea.Cmd {
+	if err := v.app.store.SavePrepTask(v.app.ctx, p); err != nil {
+		v.err = err
+		return nil
+	}
+	v.err = nil
+	return v.changed()
+}
+
+func (v prepView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tripSavedMsg:
+		if msg.trip.ID == v.trip.ID {
+			v.trip = msg.trip
+			v.reload()
+		}
+		return v, nil
+	case historyMsg:
+		v.reload()
+		return v, nil
+	case tea.KeyMsg:
+		if v.mode != prepBrowse {
+			return v.updateInput(msg)
+		}
+		if v.confirmDelete {
+			v.confirmDelete = false
+			if msg.String() == "y" {
+				p := v.selected()
+				if err := v.app.run(deletePrepTask{task: p}); err != nil {
+					v.err = err
+				} else {
+					v.status = v.app.deletedHint(p.Title)
+				}
+				return v, v.changed()
+			}
+			return v, nil
+		}
+		if cmd, ok := v.app.undoKeys(msg); ok {
+			return v, cmd
+		}
+
+		p := v.selected()
+		switch {
+		case v.app.is(msg, "up"):
+			if v.cursor > 0 {
+				v.cursor--
+			}
+		case v.app.is(msg, "down"):
+			if v.cursor < len(v.tasks)-1 {
+				v.cursor++
+			}
+		case v.app.edits(msg, "toggle"), v.app.edits(msg, "select"):
+			if p == nil {
+				break
+			}
+			toggled := *p
+			toggled.Done = !toggled.Done
+			v.status = ""
+			return v, v.save(&toggled)
+		case v.app.is(msg, "add"):
+			v.title.SetValue("")
+			v.lead.SetValue("")
+			return v, v.startInput(prepAdd)
+		case v.app.is(msg, "edit"):
+			if p == nil {
+				break
+			}
+			v.title.SetValue(p.Title)
+			v.title.CursorEnd()
+			v.lead.SetValue(models.FormatAlarm(p.Lead))
+			return v, v.startInput(prepEdit)
+		case v.app.is(msg, "generate"):
+			added, err := v.app.store.AddPrepTasks(v.app.ctx, v.trip)
+			if err != nil {
+				v.err = err
+				break
+			}
+			v.err = nil
+			v.status = "Added " + plural(len(added), "task", "tasks") + " from the templates"
+			return v, v.changed()
+		case v.app.is(msg, "delete"):
+			if p != nil {
+				v.confirmDelete = true
+			}
+		}
+	}
+	return v, nil
+}
+
+// startInput opens the inputs in mode with the title focused.
+func (v *prepView) startInput(mode prepMode) tea.Cmd {
+	v.mode, v.status, v.err = mode, "", nil
+	v.focus = 0
+	v.lead.Blur()
+	return v.title.Focus()
+}
+
+// updateInput edits a new task or the selected one, and saves it on Enter.
+// Adding keeps the inputs open for the next task, with the same lead.
+func (v prepView) updateInput(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		v.mode = prepBrowse
+		v.title.Blur()
+		v.lead.Blur()
+		return v, nil
+	case "tab", "shift+tab":
+		v.focus = 1 - v.focus
+		if v.focus == 1 {
+			v.title.Blur()
+			return v, v.lead.Focus()
+		}
+		v.lead.Blur()
+		return v, v.title.Focus()
+	case "enter":
+		title := strings.TrimSpace(v.title.Value())
+		if title == "" {
+			v.err = errors.New("enter something to do before the trip")
+			return v, nil
+		}
+		lead, err := models.ParseLead(v.lead.Value())
+		if err != nil {
+			v.err = err
+			return v, nil
+		}
+		if v.mode == prepEdit {
+			p := *v.selected()
+			p.Title, p.Lead = title, lead
+			v.mode = prepBrowse
+			v.title.Blur()
+			v.lead.Blur()
+			return v, v.save(&p)
+		}
+		p := models.NewPrepTask(v.trip.ID, title, lead)
+		cmd := v.save(p)
+		if v.err != nil {
+			return v, nil
+		}
+		v.status = fmt.Sprintf("Added %q, due %s", p.Title, v.app.formatDate(p.Due(v.trip)))
+		for i, t := range v.tasks {
+			if t.ID == p.ID {
+				v.cursor = i
+			}
+		}
+		v.title.SetValue("")
+		v.focus = 0
+		v.lead.Blur()
+		return v, tea.Batch(cmd, v.title.Focus())
+	}
+	var cmd tea.Cmd
+	if v.focus == 1 {
+		v.lead, cmd = v.lead.Update(key)
+	} else {
+		v.title, cmd = v.title.Update(key)
+	}
+	return v, cmd
+}
+
+func (v prepView) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("📋 Preparation • "+v.trip.Title) + "\n\n")
+	left := calendarDay(v.trip.StartDate).Sub(calendarDay(time.Now())).Hours() / 24
+	if len(v.tasks) > 0 {
+		b.WriteString(prepProgress(v.tasks) + "\n")
+		if left > 0 {
+			b.WriteString(hintStyle.Render(fmt.Sprintf("Leaves %s, %s", v.app.formatDate(v.trip.StartDate), occasionWhen(int(left)))) + "\n")
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("Nothing to do before leaving yet — press " + v.app.keyHint("add") + " to add a task.\n")
+	}
+	if v.templates > 0 {
+		b.WriteString(hintStyle.Render(fmt.Sprintf("The templates for this trip have %s more — press %s to add them.",
+			plural(v.templates, "task", "tasks"), v.app.keyHint("generate"))) + "\n\n")
+	}
+
+	today := calendarDay(time.Now())
+	for i, p := range v.tasks {
+		cursor, box, title := "  ", "☐", p.Title
+		if p.Done {
+			box = successStyle.Render("☑")
+		}
+		if i == v.cursor {
+			cursor, title = "👉", cursorStyle.Render(title)
+		}
+		due := p.Due(v.trip)
+		when := prepWhen(int(due.Sub(today).Hours() / 24))
+		switch {
+		case p.Done:
+			when = hintStyle.Render(when)
+		case due.Before(today):
+			when = errorStyle.Render(when)
+		case !due.After(today.AddDate(0, 0, 2)):
+			when = warningStyle.Render(when)
+		default:
+			when = hintStyle.Render(when)
+		}
+		fmt.Fprintf(&b, "%s %s %s  %s %s\n", cursor, box, title,
+			hintStyle.Render(v.app.formatDate(due)+" · "+models.FormatLead(p.Lead)+" ·"), when)
+	}
+
+	if v.mode != prepBrowse {
+		label := "Add task"
+		if v.mode == prepEdit {
+			label = "Task"
+		}
+		b.WriteString("\n" + labelStyle.Render(label) + "\n" + v.title.View() + "\n")
+		b.WriteString(labelStyle.Render("Before departure") + hintStyle.Render(" such as 60d, 2w or 24h") + "\n" + v.lead.View() + "\n")
+	}
+	if v.err != nil {
+		b.WriteString("\n" + errorStyle.Render(v.err.Error()) + "\n")
+	}
+	if v.status != "" {
+		b.WriteString("\n" + v.status + "\n")
+	}
+	if v.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Remove %q? y/n", v.selected().Title)) + "\n")
+	}
+	a := v.app
+	hint := a.keyHint("toggle") + " done • " + a.keyHint("add") + " add • " + a.keyHint("edit") + " edit • " +
+		a.keyHint("generate") + " from templates • " + a.keyHint("delete") + " remove • " +
+		a.keyHint("undo") + " undo • esc back"
+	switch v.mode {
+	case prepAdd:
+		hint = "tab task/due • enter add • esc done"
+	case prepEdit:
+		hint = "tab task/due • enter save • esc cancel"
+	}
+	b.WriteString("\n" + hintStyle.Render(hint) + "\n")
+	return b.String()
+}
+
+// prepProgress renders how many of a trip's tasks to prepare it are done
+// as a bar.
+func prepProgress(tasks []*models.PrepTask) string {
+	done := 0
+	for _, p := range tasks {
+		if p.Done {
+			done++
+		}
+	}
+	ratio := float64(done) / float64(len(tasks))
+	filled := int(ratio*budgetBarWidth + 0.5)
+	style := warningStyle
+	if done == len(tasks) {
+		style = successStyle
+	}
+	bar := style.Render(strings.Repeat("█", filled)) + hintStyle.Render(strings.Repeat("░", budgetBarWidth-filled))
+	return fmt.Sprintf("%s %3.0f%%  %d of %d done", bar, ratio*100, done, len(tasks))
+}
+
+// prepWhen says how soon a task due in days from today is, or how long
+// overdue.
+func prepWhen(in int) string {
+	switch {
+	case in < -1:
+		return tr("overdue %d days", -in)
+	case in == -1:
+		return tr("overdue 1 day")
+	}
+	return occasionWhen(in)
+}
+
+// prepSummary says how much of a trip's checklist is done and what is due
+// next, for the trip detail.
+func prepSummary(trip *models.Trip, tasks []*models.PrepTask) string {
+	done := 0
+	var next *models.PrepTask
+	for _, p := range tasks {
+		switch {
+		case p.Done:
+			done++
+		case next == nil || p.Due(trip).Before(next.Due(trip)):
+			next = p
+		}
+	}
+	s := fmt.Sprintf("%d of %d done", done, len(tasks))
+	today := calendarDay(time.Now())
+	if next != nil && calendarDay(trip.StartDate).After(today) {
+		s += " • next: " + next.Title + " " + hintStyle.Render(prepWhen(int(next.Due(trip).Sub(today).Hours()/24)))
+	}
+	return s
+}
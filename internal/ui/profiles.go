@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+)
+
+// switchProfileMsg has the TUI quit, for it to start again on profile.
+type switchProfileMsg struct {
+	profile string
+}
+
+// profileList lists the profiles of the config file, each with the
+// settings it overrides, and switches to the one chosen. Every profile
+// keeps a journal of its own, so switching starts the TUI again on it.
+type profileList struct {
+	app      *app
+	profiles []string
+	cursor   int
+	status   string
+}
+
+func newProfileList(app *app) profileList {
+	l := profileList{app: app, profiles: app.cfg.ProfileNames()}
+	l.cursor = max(slices.Index(l.profiles, app.profileName()), 0)
+	return l
+}
+
+func (l profileList) Title() string { return tr("Profiles") }
+
+func (l profileList) Init() tea.Cmd {
+	return nil
+}
+
+func (l profileList) help() []key.Binding {
+	return []key.Binding{
+		l.app.bind("up", "previous profile"),
+		l.app.bind("down", "next profile"),
+		l.app.bind("select", "switch to the profile"),
+	}
+}
+
+func (l profileList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return l, nil
+	}
+	switch {
+	case l.app.is(key, "up"):
+		if l.cursor > 0 {
+			l.cursor--
+		}
+	case l.app.is(key, "down"):
+		if l.cursor < len(l.profiles)-1 {
+			l.cursor++
+		}
+	case l.app.is(key, "select"):
+		p := l.profiles[l.cursor]
+		if p == l.app.profileName() {
+			l.status = tr("Already on %s", p)
+			return l, nil
+		}
+		return l, func() tea.Msg { return switchProfileMsg{profile: p} }
+	}
+	return l, nil
+}
+
+func (l profileList) View() string {
+	var b strings.Builder
+	b.WriteString(labelStyle.Render(tr("Choose a profile")) + "\n")
+	for i, p := range l.profiles {
+		cursor, name := "  ", p
+		if i == l.cursor {
+			cursor, name = "👉", cursorStyle.Render(name)
+		}
+		hint := profileOverrides(l.app.cfg.Profiles[p])
+		if p == l.app.profileName() {
+			hint = strings.TrimSpace(tr("current") + "  " + hint)
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, name, hintStyle.Render(hint))
+	}
+	if len(l.profiles) == 1 {
+		b.WriteString("\n" + hintStyle.Render(tr("Add another with nomadic profile add work.")) + "\n")
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render(tr("↑/↓ move • enter switch, starting again on its journal • esc back")) + "\n")
+	return b.String()
+}
+
+// profileOverrides lists the settings a profile overrides, by name.
+func profileOverrides(overrides map[string]string) string {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		names[i] = name + "=" + overrides[name]
+	}
+	return strings.Join(names, ", ")
+}
+
+// profileName is the profile the TUI runs on.
+func (a *app) profileName() string {
+	if a.profile == "" {
+		return config.DefaultProfile
+	}
+	return a.profile
+}
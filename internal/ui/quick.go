@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/quick"
+)
+
+// quickCapture is the overlay jotting a one-line note down in today's
+// entry of a trip, over whichever screen is open, without leaving it.
+type quickCapture struct {
+	trip  *models.Trip
+	input textinput.Model
+	err   error
+}
+
+// openCapture opens quick capture on the trip of the nearest screen about
+// one, or else the trip in progress.
+func (m *Model) openCapture() tea.Cmd {
+	trip, cmd := m.activeTrip("jot a note down on")
+	if trip == nil {
+		return cmd
+	}
+	in := textinput.New()
+	in.Placeholder = "amazing ramen at Ichiran"
+	in.CharLimit = 500
+	in.Width = max(min(m.width, 100)-6, 20)
+	in.Focus()
+	m.capture = &quickCapture{trip: trip, input: in}
+	return nil
+}
+
+// updateCapture takes every key while quick capture is open: enter saves
+// the note and esc drops it.
+func (m Model) updateCapture(msg tea.KeyMsg) (Model, tea.Cmd) {
+	c := *m.capture
+	switch {
+	case msg.Type == tea.KeyEsc:
+		m.capture = nil
+		return m, nil
+	case msg.Type == tea.KeyEnter:
+		note := strings.TrimSpace(c.input.Value())
+		if note == "" {
+			m.capture = nil
+			return m, nil
+		}
+		e, created, err := quick.Capture(m.app.ctx, m.app.store, c.trip, note, time.Now(), m.app.cfg.Layout())
+		if err != nil {
+			c.err = err
+			m.capture = &c
+			return m, nil
+		}
+		m.capture = nil
+		text := fmt.Sprintf("Added to %q", e.Title)
+		if created {
+			text = fmt.Sprintf("Started %q", e.Title)
+		}
+		return m, tea.Batch(notify(entrySavedMsg{entry: e}), notify(historyMsg{text: text}))
+	}
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	m.capture = &c
+	return m, cmd
+}
+
+func (c *quickCapture) view(width int) string {
+	var b strings.Builder
+	b.WriteString(labelStyle.Render("✏️  Quick note on "+c.trip.Title) + "\n" + c.input.View() + "\n")
+	if c.err != nil {
+		b.WriteString(errorStyle.Render(c.err.Error()) + "\n")
+	}
+	b.WriteString(hintStyle.Render("enter add to today's entry • esc cancel"))
+	return paneStyle.Width(max(min(width, 100)-2, 20)).Render(b.String())
+}
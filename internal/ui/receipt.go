@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/quick"
+)
+
+// receiptScan reads a photo of a receipt with OCR and opens the expense
+// form on what it read, the amount, currency and merchant, to check and
+// save it with the receipt kept as a document of the trip.
+type receiptScan struct {
+	app      *app
+	trip     *models.Trip
+	currency string // when the receipt names none
+	path     textinput.Model
+	reading  bool
+	err      error
+}
+
+// receiptReadMsg carries the text read from the receipt at path.
+type receiptReadMsg struct {
+	path string
+	text string
+	err  error
+}
+
+// receiptRetryMsg reads the receipt again, from the error screen of the
+// reader failing.
+type receiptRetryMsg struct{}
+
+func newReceiptScan(app *app, trip *models.Trip, currency string) receiptScan {
+	in := newPathInput("")
+	in.Placeholder = "~/Pictures/receipt.jpg"
+	return receiptScan{app: app, trip: trip, currency: currency, path: in}
+}
+
+func (s receiptScan) Title() string { return tr("Receipt") }
+
+func (s receiptScan) currentTrip() *models.Trip { return s.trip }
+
+func (s receiptScan) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (s receiptScan) typing() bool { return !s.reading }
+
+func (s receiptScan) help() []key.Binding {
+	return []key.Binding{
+		fixed("enter", "read the receipt and check the expense"),
+		fixed("esc", "cancel"),
+	}
+}
+
+func (s receiptScan) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case receiptReadMsg:
+		s.reading = false
+		if msg.err != nil {
+			// The path was good; the reader, its service or the network
+			// failed.
+			return s, fail(tr("read the receipt"), msg.err, notify(receiptRetryMsg{}))
+		}
+		f := newReceiptForm(s.app, s.app.receiptExpense(s.trip, s.currency, msg.text), msg.path)
+		return s, tea.Sequence(pop, push(f))
+	case receiptRetryMsg:
+		return s, s.read()
+	case tea.KeyMsg:
+		if s.reading {
+			return s, nil
+		}
+		if msg.Type == tea.KeyEnter {
+			return s, s.read()
+		}
+	}
+	var cmd tea.Cmd
+	s.path, cmd = s.path.Update(msg)
+	return s, cmd
+}
+
+// read reads the receipt in the background.
+func (s *receiptScan) read() tea.Cmd {
+	path := expandHome(strings.TrimSpace(s.path.Value()))
+	if path == "" {
+		s.err = errors.New("enter the path of a photo of the receipt")
+		return nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		s.err = err
+		return nil
+	}
+	if s.app.ocr == nil {
+		s.err = errors.New("receipts cannot be read; set $NOMADIC_OCR_KEY to the key of the OCR API, or ocr to tesseract")
+		return nil
+	}
+	s.reading, s.err = true, nil
+	reader, language, ctx := s.app.ocr, s.app.cfg.OCRLanguage, s.app.ctx
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		text, err := reader.Read(ctx, path, language)
+		return receiptReadMsg{path: path, text: text, err: err}
+	}
+}
+
+// receiptExpense is the expense on trip read from the text of a receipt,
+// in currency when the receipt names none, stamped with the time of day
+// now on the day printed on it.
+func (a *app) receiptExpense(trip *models.Trip, currency, text string) *models.Expense {
+	// Without rules the common words still guess a category.
+	rules, _ := a.store.ListRules(a.ctx)
+	r := quick.ReadReceipt(text, a.cfg.Layout(), rules)
+	if r.Currency == "" {
+		r.Currency = currency
+	}
+	ts := a.tripNow(trip)
+	if !r.Day.IsZero() {
+		ts = time.Date(r.Day.Year(), r.Day.Month(), r.Day.Day(), ts.Hour(), ts.Minute(), ts.Second(), 0, ts.Location())
+	}
+	x := models.NewExpense(trip.ID, r.Amount, r.Currency, r.Category, r.Merchant, ts)
+	x.Merchant = r.Merchant
+	return x
+}
+
+// newReceiptForm opens the expense form on x, read from the receipt at
+// path, which is kept with it once saved. With an amount read it opens on
+// the review of what was read.
+func newReceiptForm(a *app, x *models.Expense, path string) expenseForm {
+	f := newExpenseForm(a, x, true)
+	f.title = "🧾 New Expense from a Receipt"
+	f.receipt = path
+	if f.expense.Amount > 0 {
+		f.review()
+	}
+	return f
+}
+
+func (s receiptScan) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🧾 Expense from a receipt on "+s.trip.Title) + "\n\n")
+	b.WriteString(labelStyle.Render("Photo or scan of the receipt") + "\n" + s.path.View() + "\n")
+	if s.reading {
+		b.WriteString("\n" + hintStyle.Render("Reading the receipt…") + "\n")
+	} else {
+		b.WriteString(hintStyle.Render("The amount, currency and merchant read are shown to check before saving.") + "\n")
+	}
+	if s.err != nil {
+		b.WriteString("\n" + errorStyle.Render(s.err.Error()) + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,222 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/reconcile"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+// reconcileView compares a trip's expenses with a bank or card statement,
+// or with the total printed on one, and lists what does not match.
+type reconcileView struct {
+	app   *app
+	trip  *models.Trip
+	path  textinput.Model
+	total textinput.Model
+	focus int
+
+	rates  *currency.Rates
+	result *reconcile.Reconciliation
+	// charged tells whether the result matched charges, rather than only
+	// a total.
+	charged bool
+	err     error
+}
+
+func newReconcileView(app *app, trip *models.Trip, rates *currency.Rates) reconcileView {
+	total := newPathInput("")
+	total.Placeholder = "1234.50"
+	total.Width = 20
+	total.Blur()
+	path := newPathInput("")
+	path.Placeholder = "~/Downloads/statement.ofx"
+	return reconcileView{app: app, trip: trip, path: path, total: total, rates: rates}
+}
+
+func (v reconcileView) Title() string { return tr("Reconcile") }
+
+func (v reconcileView) currentTrip() *models.Trip { return v.trip }
+
+func (v reconcileView) Init() tea.Cmd {
+	if v.rates != nil {
+		return textinput.Blink
+	}
+	return tea.Batch(textinput.Blink, v.app.fetchRates())
+}
+
+func (v reconcileView) typing() bool { return true }
+
+func (v reconcileView) help() []key.Binding {
+	return []key.Binding{
+		fixed("tab/shift+tab", "switch between the statement and its total"),
+		fixed("enter", "compare the expenses with the statement"),
+	}
+}
+
+func (v reconcileView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ratesMsg:
+		v.rates = msg.rates
+	case expenseSavedMsg, historyMsg:
+		if v.result != nil {
+			v.compare()
+		}
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab", "shift+tab", "up", "down":
+			v.focus = 1 - v.focus
+			if v.focus == 0 {
+				v.total.Blur()
+				return v, v.path.Focus()
+			}
+			v.path.Blur()
+			return v, v.total.Focus()
+		case "enter":
+			v.compare()
+			return v, nil
+		}
+	}
+	var cmd tea.Cmd
+	if v.focus == 0 {
+		v.path, cmd = v.path.Update(msg)
+	} else {
+		v.total, cmd = v.total.Update(msg)
+	}
+	return v, cmd
+}
+
+// compare reconciles the trip's expenses with the statement entered: over
+// the days it covers, or over the trip's when only a total is.
+func (v *reconcileView) compare() {
+	v.result, v.err = nil, nil
+	path := expandHome(strings.TrimSpace(v.path.Value()))
+	opts := reconcile.Options{Currency: v.trip.BudgetCurrency}
+	if s := strings.TrimSpace(v.total.Value()); s != "" {
+		n, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", ""), 64)
+		if err != nil || n <= 0 {
+			v.err = errors.New("enter the statement's total as an amount, e.g. 1234.50")
+			return
+		}
+		opts.Total = n
+	}
+	if path == "" && opts.Total == 0 {
+		v.err = errors.New("enter a statement file, or its total")
+		return
+	}
+	var charges []*models.Expense
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			v.err = err
+			return
+		}
+		st, err := export.ParseStatement(bytes.NewReader(data), export.StatementOptions{
+			DateLayouts:     []string{v.app.cfg.Layout()},
+			DefaultCurrency: v.app.cfg.DefaultCurrency,
+		})
+		if err != nil {
+			v.err = err
+			return
+		}
+		for _, row := range st.Rows {
+			if row.Err != nil {
+				v.err = fmt.Errorf("line %d: %w", row.Line, row.Err)
+				return
+			}
+			charges = append(charges, row.Expense)
+		}
+	} else {
+		opts.From, opts.To = v.trip.StartDate, time.Now()
+		if v.trip.EndDate != nil {
+			opts.To = *v.trip.EndDate
+		}
+	}
+	switch {
+	case len(charges) > 0:
+		opts.Currency = charges[0].Currency
+	case opts.Currency == "":
+		opts.Currency = v.app.cfg.HomeCurrency
+	}
+	if v.rates != nil {
+		opts.Convert = v.rates.Convert
+	}
+	expenses, err := v.app.store.ListExpensesByTrip(v.app.ctx, v.trip.ID)
+	if err != nil {
+		v.err = err
+		return
+	}
+	r := reconcile.Compute(charges, expenses, opts)
+	v.result, v.charged = &r, len(charges) > 0
+}
+
+func (v reconcileView) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🧾 Reconcile "+v.trip.Title+" with a statement") + "\n\n")
+	b.WriteString(labelStyle.Render("Statement file") + "\n" + v.path.View() + "\n")
+	b.WriteString(hintStyle.Render("OFX, QFX or CSV, as for importing a statement. Optional with a total.") + "\n\n")
+	b.WriteString(labelStyle.Render("Statement total") + "\n" + v.total.View() + "\n")
+	b.WriteString(hintStyle.Render("Optional. The total printed on it; else its charges are added up.") + "\n")
+	if v.err != nil {
+		b.WriteString("\n" + errorStyle.Render(v.err.Error()) + "\n")
+	}
+	if r := v.result; r != nil {
+		b.WriteString("\n" + v.summary(*r))
+	}
+	b.WriteString("\n" + hintStyle.Render("tab switch field • enter compare • esc back") + "\n")
+	return b.String()
+}
+
+// summary shows how the totals compare and what is left unmatched.
+func (v reconcileView) summary(r reconcile.Reconciliation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s – %s\n", labelStyle.Render("Days:     "), v.app.formatDate(r.From), v.app.formatDate(r.To))
+	fmt.Fprintf(&b, "%s %s\n", labelStyle.Render("Statement:"), formatAmount(r.Statement, r.Currency))
+	fmt.Fprintf(&b, "%s %s\n", labelStyle.Render("Logged:   "), formatAmount(r.Logged, r.Currency))
+	gap := formatAmount(r.Gap(), r.Currency)
+	if r.Gap() == 0 {
+		gap = successStyle.Render(gap)
+	} else {
+		gap = warningStyle.Render(gap)
+	}
+	fmt.Fprintf(&b, "%s %s\n", labelStyle.Render("Gap:      "), gap)
+	if r.Unconverted > 0 {
+		b.WriteString(hintStyle.Render(fmt.Sprintf("%s left out, with no exchange rate into %s",
+			plural(r.Unconverted, "expense", "expenses"), r.Currency)) + "\n")
+	}
+	const maxListed = 8
+	list := func(title string, expenses []*models.Expense) {
+		if len(expenses) == 0 {
+			return
+		}
+		b.WriteString("\n" + warningStyle.Render(fmt.Sprintf("%s (%d)", title, len(expenses))) + "\n")
+		for i, x := range expenses {
+			if i == maxListed {
+				b.WriteString(hintStyle.Render(fmt.Sprintf("  … and %d more", len(expenses)-maxListed)) + "\n")
+				break
+			}
+			fmt.Fprintf(&b, "  %s  %s  %s\n", v.app.formatDate(x.Timestamp), formatAmount(x.Amount, x.Currency), truncate(x.Description, 40))
+		}
+	}
+	list("On the statement, not logged", r.Missing)
+	list("Logged, not on the statement", r.Unbilled)
+	if v.charged {
+		b.WriteString("\n" + hintStyle.Render(plural(len(r.Matched), "charge", "charges")+" matched") + "\n")
+	}
+	if r.Balanced() {
+		b.WriteString("\n" + successStyle.Render("Everything is accounted for") + "\n")
+	}
+	return b.String()
+}
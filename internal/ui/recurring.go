@@ -0,0 +1,398 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/recurring"
+)
+
+// recurrenceList shows the recurring expenses recorded on a trip: its own
+// and those of whichever trip is in progress. They can be paused and
+// resumed, edited and deleted.
+type recurrenceList struct {
+	app         *app
+	trip        *models.Trip
+	recurrences []*models.Recurrence
+	cursor      int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newRecurrenceList(app *app, trip *models.Trip) recurrenceList {
+	l := recurrenceList{app: app, trip: trip}
+	l.reload()
+	return l
+}
+
+func (l recurrenceList) Title() string { return tr("Recurring") }
+
+func (l recurrenceList) currentTrip() *models.Trip { return l.trip }
+
+func (l recurrenceList) Init() tea.Cmd {
+	return nil
+}
+
+func (l recurrenceList) capturesEsc() bool { return l.confirmDelete }
+
+func (l recurrenceList) help() []key.Binding {
+	return append([]key.Binding{
+		l.app.bind("up", "previous recurring expense"),
+		l.app.bind("down", "next recurring expense"),
+		l.app.bind("new", "add a recurring expense"),
+		l.app.bind("edit", "edit the recurring expense"),
+		l.app.bind("toggle", "pause or resume it"),
+		l.app.bind("delete", "delete it, keeping the expenses it recorded"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *recurrenceList) reload() {
+	all, err := l.app.store.ListRecurrences(l.app.ctx)
+	l.recurrences, l.err = nil, err
+	for _, r := range all {
+		if r.TripID == "" || r.TripID == l.trip.ID {
+			l.recurrences = append(l.recurrences, r)
+		}
+	}
+	l.cursor = clamp(l.cursor, 0, len(l.recurrences)-1)
+}
+
+func (l recurrenceList) selected() *models.Recurrence {
+	if len(l.recurrences) == 0 {
+		return nil
+	}
+	return l.recurrences[l.cursor]
+}
+
+func (l recurrenceList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case recurrenceSavedMsg:
+		l.reload()
+		for i, r := range l.recurrences {
+			if r.ID == msg.recurrence.ID {
+				l.cursor = i
+			}
+		}
+		if msg.recorded > 0 {
+			return l, toast(toastSuccess, "Saved %q • recorded %s", msg.recurrence.Description, plural(msg.recorded, "expense", "expenses"))
+		}
+		return l, toast(toastSuccess, "Saved %q", msg.recurrence.Description)
+	case historyMsg:
+		l.status = ""
+		l.reload()
+	case tea.KeyMsg:
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				r := l.selected()
+				if err := l.app.run(deleteRecurrence{recurrence: r}); err != nil {
+					l.err = err
+					return l, nil
+				}
+				l.status = l.app.deletedHint(r.Description)
+				l.reload()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.recurrences)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			l.status = ""
+			return l, push(newRecurrenceForm(l.app, l.trip, nil))
+		case l.app.edits(msg, "select"), l.app.is(msg, "edit"):
+			if r := l.selected(); r != nil {
+				l.status = ""
+				return l, push(newRecurrenceForm(l.app, l.trip, r))
+			}
+		case l.app.edits(msg, "toggle"):
+			if r := l.selected(); r != nil {
+				return l, l.togglePaused(r)
+			}
+		case l.app.is(msg, "delete"):
+			if l.selected() != nil {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+// togglePaused pauses r, or resumes it from today, recording what falls
+// due today at once.
+func (l *recurrenceList) togglePaused(r *models.Recurrence) tea.Cmd {
+	if r.Paused {
+		r.Resume(dateOf(time.Now()))
+	} else {
+		r.Paused = true
+	}
+	if err := l.app.store.SaveRecurrence(l.app.ctx, r); err != nil {
+		l.err = err
+		return nil
+	}
+	if r.Paused {
+		l.status = fmt.Sprintf("Paused %q", r.Description)
+		return nil
+	}
+	return l.app.recordRecurring(r)
+}
+
+func (l recurrenceList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🔁 Recurring on "+l.trip.Title) + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n\n")
+	}
+	if len(l.recurrences) == 0 {
+		b.WriteString("No recurring expenses — press " + l.app.keyHint("new") + " to add one, such as travel insurance or an eSIM plan.\n")
+	}
+	for i, r := range l.recurrences {
+		cursor, name := "  ", r.Description
+		if i == l.cursor {
+			cursor, name = "👉", cursorStyle.Render(name)
+		}
+		next := "next " + l.app.formatDate(r.Next)
+		switch {
+		case r.Paused:
+			next = "⏸ paused"
+		case r.Ended():
+			next = "ended"
+		}
+		scope := "this trip"
+		if r.TripID == "" {
+			scope = "any trip"
+		}
+		fmt.Fprintf(&b, "%s %s %s %s\n", cursor, name, formatAmount(r.Amount, r.Currency),
+			hintStyle.Render(r.Interval+" · "+next+" · "+scope))
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Delete %q? The expenses it recorded stay. y/n", l.selected().Description)) + "\n")
+	}
+	a := l.app
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • "+a.keyHint("new")+" new • "+a.keyHint("edit")+" edit • "+
+		a.keyHint("toggle")+" pause/resume • "+a.keyHint("delete")+" delete • "+a.keyHint("undo")+" undo • esc back") + "\n")
+	return b.String()
+}
+
+// recordRecurring records the expenses that fell due, and reports r saved
+// along with how many were recorded.
+func (a *app) recordRecurring(r *models.Recurrence) tea.Cmd {
+	recorded, err := recurring.Record(a.ctx, a.store, time.Now())
+	if err != nil {
+		return notify(historyMsg{err: err})
+	}
+	if saved, err := a.store.GetRecurrence(a.ctx, r.ID); err == nil {
+		r = saved
+	}
+	return notify(recurrenceSavedMsg{recurrence: r, recorded: len(recorded)})
+}
+
+const (
+	recurrenceFieldDescription = iota
+	recurrenceFieldAmount
+	recurrenceFieldCurrency
+	recurrenceFieldCategory
+	recurrenceFieldInterval
+	recurrenceFieldStart
+	recurrenceFieldEnd
+	recurrenceFieldAnyTrip
+)
+
+// recurrenceForm adds a recurring expense to a trip, or edits one.
+type recurrenceForm struct {
+	form
+	app        *app
+	trip       *models.Trip
+	recurrence *models.Recurrence
+	isNew      bool
+}
+
+func newRecurrenceForm(app *app, trip *models.Trip, r *models.Recurrence) recurrenceForm {
+	title := "🔁 New recurring expense"
+	isNew := r == nil
+	if isNew {
+		r = models.NewRecurrence(0, app.cfg.DefaultCurrency, models.CategoryOther, "", models.IntervalMonthly,
+			dateOf(app.tripNow(trip)))
+		r.TripID = trip.ID
+	} else {
+		title = "🔁 Edit " + r.Description
+	}
+	f := newForm(title,
+		newField("Description", "eSIM plan", "", required("description")),
+		newField("Amount", "15", "Of each expense.", validatePositiveAmount),
+		newField("Currency", "EUR", "Three-letter ISO code.", validateCurrency),
+		newField("Category", models.CategoryOther, strings.Join(models.Categories, " • "), validateCategory),
+		newField("Every", models.IntervalMonthly, strings.Join(models.Intervals, " • "), validateInterval),
+		newField("Start", app.cfg.DateFormat, "The first day it falls due.", app.validateDate),
+		newField("End", app.cfg.DateFormat, "Optional. The last day it may fall due.", app.validateOptionalDate),
+		newField("Any trip", "no", "yes to record it on whichever trip is in progress, not only this one.", validateYesNo),
+	)
+	f.fields[recurrenceFieldDescription].input.SetValue(r.Description)
+	if r.Amount != 0 {
+		f.fields[recurrenceFieldAmount].input.SetValue(strconv.FormatFloat(r.Amount, 'f', -1, 64))
+	}
+	f.fields[recurrenceFieldCurrency].input.SetValue(r.Currency)
+	f.fields[recurrenceFieldCategory].input.SetValue(r.Category)
+	f.fields[recurrenceFieldInterval].input.SetValue(r.Interval)
+	f.fields[recurrenceFieldStart].dates = newDatePicker(app)
+	f.fields[recurrenceFieldEnd].dates = newDateRangePicker(app, recurrenceFieldStart, "the end must not be before the start")
+	f.fields[recurrenceFieldStart].input.SetValue(app.formatDate(r.Start))
+	if r.End != nil {
+		f.fields[recurrenceFieldEnd].input.SetValue(app.formatDate(*r.End))
+	}
+	if r.TripID == "" {
+		f.fields[recurrenceFieldAnyTrip].input.SetValue("yes")
+	}
+	// Work on a copy so cancelling leaves the caller's recurrence untouched.
+	edited := *r
+	return recurrenceForm{form: f, app: app, trip: trip, recurrence: &edited, isNew: isNew}
+}
+
+func (f recurrenceForm) Title() string {
+	if f.isNew {
+		return tr("New recurring expense")
+	}
+	return tr("Edit %s", f.recurrence.Description)
+}
+
+func (f recurrenceForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f recurrenceForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		if err := f.apply(); err != nil {
+			f.err = err
+			return f, nil
+		}
+		if err := f.app.store.SaveRecurrence(f.app.ctx, f.recurrence); err != nil {
+			f.err = err
+			return f, nil
+		}
+		return f, tea.Sequence(pop, f.app.recordRecurring(f.recurrence))
+	}
+	return f, cmd
+}
+
+// apply copies the validated values into the recurrence. A new one falls
+// due from its start, back to a start in the past; an edited one keeps
+// the days it has recorded.
+func (f recurrenceForm) apply() error {
+	r := f.recurrence
+	amount, err := strconv.ParseFloat(f.value(recurrenceFieldAmount), 64)
+	if err != nil {
+		return err
+	}
+	currency, err := models.ParseCurrency(f.value(recurrenceFieldCurrency))
+	if err != nil {
+		return err
+	}
+	category, err := models.ParseCategory(f.value(recurrenceFieldCategory))
+	if err != nil {
+		return err
+	}
+	interval, err := models.ParseInterval(f.value(recurrenceFieldInterval))
+	if err != nil {
+		return err
+	}
+	start, err := f.app.parseDate(f.value(recurrenceFieldStart))
+	if err != nil {
+		return err
+	}
+	var end *time.Time
+	if v := f.value(recurrenceFieldEnd); v != "" {
+		d, err := f.app.parseDate(v)
+		if err != nil {
+			return err
+		}
+		if d.Before(start) {
+			return errors.New("the end must not be before the start")
+		}
+		end = &d
+	}
+	r.Description, r.Amount, r.Currency, r.Category = f.value(recurrenceFieldDescription), amount, currency, category
+	r.Interval, r.Start, r.End = interval, start, end
+	r.TripID = f.trip.ID
+	if yes(f.value(recurrenceFieldAnyTrip)) {
+		r.TripID = ""
+	}
+	if f.isNew {
+		r.Next = r.From(start)
+	} else {
+		r.Reschedule(dateOf(time.Now()))
+	}
+	return nil
+}
+
+func (f recurrenceForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		row := func(label, value string) {
+			if value == "" {
+				value = hintStyle.Render("—")
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-12s", label+":")), value)
+		}
+		row("Description", f.value(recurrenceFieldDescription))
+		row("Amount", f.value(recurrenceFieldAmount)+" "+strings.ToUpper(f.value(recurrenceFieldCurrency)))
+		row("Category", f.value(recurrenceFieldCategory))
+		interval, _ := models.ParseInterval(f.value(recurrenceFieldInterval))
+		row("Every", interval)
+		row("Start", f.value(recurrenceFieldStart))
+		row("End", f.value(recurrenceFieldEnd))
+		trip := f.trip.Title
+		if yes(f.value(recurrenceFieldAnyTrip)) {
+			trip = "whichever is in progress"
+		}
+		row("Trip", trip)
+		return b.String()
+	})
+}
+
+func validateInterval(v string) error {
+	_, err := models.ParseInterval(v)
+	return err
+}
+
+func validateYesNo(v string) error {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "", "y", "yes", "n", "no":
+		return nil
+	}
+	return errors.New("enter yes or no")
+}
+
+// yes reports whether v, checked by validateYesNo, answers yes.
+func yes(v string) bool {
+	v = strings.ToLower(strings.TrimSpace(v))
+	return v == "y" || v == "yes"
+}
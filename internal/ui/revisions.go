@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/textdiff"
+)
+
+// revisionRows is how many versions the history lists at once, leaving
+// the rest of the screen to the diff.
+const revisionRows = 6
+
+// diffContext is how many unchanged lines are shown around each change.
+const diffContext = 2
+
+// revisionList shows the earlier versions of a journal entry with what
+// each edit changed, and restores one.
+type revisionList struct {
+	app       *app
+	entry     *models.Entry
+	revisions []*models.Revision
+	cursor    int
+	// sinceCurrent compares the selected version with the entry as it is
+	// now rather than with the version saved after it.
+	sinceCurrent bool
+	// changes counts the lines each version inserted and deleted, in the
+	// order of versions.
+	changes  [][2]int
+	viewport viewport.Model
+
+	status string
+	err    error
+}
+
+func newRevisionList(app *app, entry *models.Entry) revisionList {
+	l := revisionList{app: app, entry: entry, viewport: viewport.New(80, 10)}
+	l.reload()
+	return l
+}
+
+func (l revisionList) Title() string { return tr("History") }
+
+func (l revisionList) Init() tea.Cmd {
+	return nil
+}
+
+func (l revisionList) help() []key.Binding {
+	return []key.Binding{
+		l.app.bind("up", "newer version"),
+		l.app.bind("down", "older version"),
+		l.app.bind("page_up", "scroll the changes up"),
+		l.app.bind("page_down", "scroll the changes down"),
+		l.app.bind("toggle", "compare with the version after it or with now"),
+		l.app.bind("restore", "restore the version"),
+		fixed("esc", "back to the entry"),
+	}
+}
+
+func (l *revisionList) reload() {
+	l.revisions, l.err = l.app.store.ListRevisions(l.app.ctx, l.entry.ID)
+	l.cursor = clamp(l.cursor, 0, len(l.revisions))
+	versions := l.versions()
+	l.changes = make([][2]int, len(versions))
+	for i, v := range versions {
+		var old *models.Revision
+		if i+1 < len(versions) {
+			old = versions[i+1]
+		}
+		inserted, deleted := textdiff.Stat(textdiff.Lines(old.Document(), v.Document()))
+		l.changes[i] = [2]int{inserted, deleted}
+	}
+	l.refresh()
+}
+
+// versions lists the entry as it is now followed by its revisions, the
+// most recent first.
+func (l revisionList) versions() []*models.Revision {
+	return append([]*models.Revision{models.CurrentRevision(l.entry)}, l.revisions...)
+}
+
+// compared returns the versions the diff shows as old and new: the
+// selected one and the version saved after it, or the entry as it is now.
+// The oldest version kept is compared with nothing.
+func (l revisionList) compared() (old, new *models.Revision) {
+	versions := l.versions()
+	if l.sinceCurrent {
+		return versions[l.cursor], versions[0]
+	}
+	if l.cursor+1 < len(versions) {
+		return versions[l.cursor+1], versions[l.cursor]
+	}
+	return nil, versions[l.cursor]
+}
+
+func (l *revisionList) refresh() {
+	old, new := l.compared()
+	if old == new {
+		l.viewport.SetContent(hintStyle.Render("This is the entry as it is now."))
+		return
+	}
+	diff := textdiff.Lines(old.Document(), new.Document())
+	l.viewport.SetContent(renderDiff(diff, l.viewport.Width))
+	l.viewport.GotoTop()
+}
+
+// renderDiff shows the changes of a diff with a little context, inserted
+// lines marked + and deleted ones -, and the unchanged stretches between
+// them left out.
+func renderDiff(diff []textdiff.Line, width int) string {
+	hunks := textdiff.Hunks(diff, diffContext)
+	if len(hunks) == 0 {
+		return hintStyle.Render("No changes.")
+	}
+	var b strings.Builder
+	for i, hunk := range hunks {
+		if i > 0 {
+			b.WriteString(hintStyle.Render("⋯") + "\n")
+		}
+		for _, line := range hunk {
+			text := truncate(line.Text, max(width-2, 10))
+			switch line.Op {
+			case textdiff.Insert:
+				b.WriteString(successStyle.Render("+ "+text) + "\n")
+			case textdiff.Delete:
+				b.WriteString(errorStyle.Render("- "+text) + "\n")
+			default:
+				b.WriteString("  " + text + "\n")
+			}
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (l revisionList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// The header, the versions and the hints take the rest.
+		l.viewport.Width, l.viewport.Height = msg.Width, max(msg.Height-revisionRows-8, 5)
+		l.refresh()
+		return l, nil
+	case entrySavedMsg:
+		if msg.entry.ID == l.entry.ID {
+			l.entry = msg.entry
+			l.reload()
+		}
+		return l, nil
+	case themeChangedMsg:
+		l.refresh()
+		return l, nil
+	case tea.KeyMsg:
+		l.status = ""
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+				l.refresh()
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.revisions) {
+				l.cursor++
+				l.refresh()
+			}
+		case l.app.is(msg, "page_up"):
+			l.viewport.HalfViewUp()
+		case l.app.is(msg, "page_down"):
+			l.viewport.HalfViewDown()
+		case l.app.is(msg, "toggle"):
+			l.sinceCurrent = !l.sinceCurrent
+			l.refresh()
+		case l.app.is(msg, "restore"):
+			if l.cursor == 0 {
+				return l, nil
+			}
+			r := l.revisions[l.cursor-1]
+			e, err := l.app.store.RestoreRevision(l.app.ctx, r.ID)
+			if err != nil {
+				l.err = err
+				return l, nil
+			}
+			l.entry, l.cursor = e, 0
+			l.reload()
+			l.status = "Restored the version of " + l.savedAt(r) + "; the one it replaced is kept below"
+			return l, notify(entrySavedMsg{entry: e})
+		}
+	}
+	return l, nil
+}
+
+// savedAt says when a version was saved.
+func (l revisionList) savedAt(r *models.Revision) string {
+	return l.app.formatDate(r.SavedAt) + " " + r.SavedAt.Format("15:04")
+}
+
+func (l revisionList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🕘 History of "+l.entry.Title) + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n\n")
+	}
+	if len(l.revisions) == 0 {
+		b.WriteString("The entry has not been edited since it was written.\n")
+		b.WriteString("\n" + hintStyle.Render("esc back") + "\n")
+		return b.String()
+	}
+	versions := l.versions()
+	from, to := window(len(versions), l.cursor, revisionRows)
+	for i := from; i < to; i++ {
+		v := versions[i]
+		label := l.savedAt(v)
+		if i == 0 {
+			label = fmt.Sprintf("%-*s", len(l.savedAt(versions[1])), "Now")
+		}
+		cursor := "  "
+		if i == l.cursor {
+			cursor, label = "👉", cursorStyle.Render(label)
+		}
+		fmt.Fprintf(&b, "%s %s  %s %s  %s\n", cursor, label, successStyle.Render(fmt.Sprintf("+%d", l.changes[i][0])),
+			errorStyle.Render(fmt.Sprintf("-%d", l.changes[i][1])), hintStyle.Render(truncate(v.Title, 40)))
+	}
+
+	old, new := l.compared()
+	switch {
+	case old == new:
+		b.WriteString("\n" + labelStyle.Render("Now") + "\n")
+	case l.sinceCurrent:
+		b.WriteString("\n" + labelStyle.Render("Changed since "+l.savedAt(old)) + "\n")
+	case l.cursor == len(l.revisions):
+		b.WriteString("\n" + labelStyle.Render("The oldest version kept") + "\n")
+	default:
+		b.WriteString("\n" + labelStyle.Render("Changed on "+l.savedAt(new)) + "\n")
+	}
+	b.WriteString(l.viewport.View() + "\n")
+	if l.status != "" {
+		b.WriteString("\n" + successStyle.Render(l.status) + "\n")
+	}
+	compare := "compare with now"
+	if l.sinceCurrent {
+		compare = "compare with the next version"
+	}
+	b.WriteString("\n" + hintStyle.Render("↑/↓ version • "+l.app.keyHint("page_up")+"/"+l.app.keyHint("page_down")+
+		" scroll • "+l.app.keyHint("toggle")+" "+compare+" • "+l.app.keyHint("restore")+" restore • esc back") + "\n")
+	return b.String()
+}
@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+const maxSearchResults = 50
+
+// search finds journal entries and expenses across all trips as the user
+// types words and filters such as trip:japan; see storage.ParseQuery.
+type search struct {
+	app      *app
+	input    textinput.Model
+	complete *autocomplete
+	// results are the entries found. The cursor moves through them, then
+	// through the expenses.
+	results  []storage.EntryMatch
+	expenses []storage.ExpenseMatch
+	cursor   int
+	height   int
+	err      error
+}
+
+func newSearch(app *app) search {
+	in := textinput.New()
+	in.Placeholder = "temple sunrise trip:japan"
+	in.Prompt = "🔍 "
+	in.CharLimit = 256
+	in.Width = 50
+	in.Focus()
+	return search{app: app, input: in, complete: newQueryCompleter(app)}
+}
+
+// newQueryCompleter completes the word being typed in a search to the name
+// of a filter, and the value of a trip:, tag:, category: or is: filter to
+// one that finds something.
+func newQueryCompleter(app *app) *autocomplete {
+	values := map[string][]string{
+		"category": models.Categories,
+		"is":       {storage.KindEntry, storage.KindExpense},
+	}
+	if tags, err := app.store.ListTags(app.ctx); err == nil {
+		for _, t := range tags {
+			values["tag"] = append(values["tag"], t.Name)
+		}
+	}
+	if trips, err := app.store.ListTrips(app.ctx); err == nil {
+		for _, t := range trips {
+			title := strings.ToLower(t.Title)
+			if strings.Contains(title, " ") {
+				title = `"` + title + `"`
+			}
+			values["trip"] = append(values["trip"], title)
+		}
+	}
+	return &autocomplete{words: true, source: func(typed string) []suggestion {
+		var out []suggestion
+		name, value, ok := strings.Cut(strings.ToLower(typed), ":")
+		if !ok {
+			for _, f := range storage.Filters {
+				if strings.HasPrefix(f.Name, name) {
+					out = append(out, suggestion{value: f.Name + ":", detail: f.Usage, positions: prefixPositions(name)})
+				}
+			}
+			return out
+		}
+		for _, v := range values[name] {
+			if strings.HasPrefix(strings.TrimPrefix(v, `"`), strings.TrimPrefix(value, `"`)) {
+				out = append(out, suggestion{value: name + ":" + v, positions: prefixPositions(typed)})
+			}
+		}
+		return out
+	}}
+}
+
+// prefixPositions are the positions of the runes of typed, matched at the
+// start of a suggestion.
+func prefixPositions(typed string) []int {
+	positions := make([]int, len([]rune(typed)))
+	for i := range positions {
+		positions[i] = i
+	}
+	return positions
+}
+
+func (s search) Title() string { return tr("Search") }
+
+func (s search) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (s search) typing() bool { return true }
+
+func (s search) help() []key.Binding {
+	return append([]key.Binding{
+		fixed("↑/ctrl+p", "previous result"),
+		fixed("↓/ctrl+n", "next result"),
+		fixed("enter", "read the entry or open the expense"),
+	}, completionHelp()...)
+}
+
+// found is how many entries and expenses were found.
+func (s search) found() int {
+	return len(s.results) + len(s.expenses)
+}
+
+func (s *search) run() {
+	s.results, s.expenses = nil, nil
+	q, err := storage.ParseQuery(s.input.Value())
+	if s.err = err; err != nil {
+		return
+	}
+	if s.results, s.err = s.app.store.SearchEntries(s.app.ctx, q, maxSearchResults); s.err != nil {
+		return
+	}
+	s.expenses, s.err = s.app.store.SearchExpenses(s.app.ctx, q, maxSearchResults)
+	s.cursor = clamp(s.cursor, 0, s.found()-1)
+}
+
+func (s search) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.height = msg.Height
+		return s, nil
+	case entrySavedMsg:
+		s.run()
+		return s, nil
+	case expenseSavedMsg:
+		s.run()
+		return s, nil
+	case tea.KeyMsg:
+		before := s.input.Value()
+		if s.complete.update(&s.input, msg) {
+			if s.input.Value() != before {
+				s.run()
+			}
+			return s, nil
+		}
+		switch msg.String() {
+		case "up", "ctrl+p":
+			if s.cursor > 0 {
+				s.cursor--
+			}
+			return s, nil
+		case "down", "ctrl+n":
+			if s.cursor < s.found()-1 {
+				s.cursor++
+			}
+			return s, nil
+		case "enter":
+			return s, s.open()
+		}
+	}
+	before := s.input.Value()
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	if s.input.Value() != before {
+		s.cursor = 0
+		s.run()
+	}
+	return s, cmd
+}
+
+// open reads the entry under the cursor, or shows the expense.
+func (s search) open() tea.Cmd {
+	if s.cursor < len(s.results) {
+		return push(newEntryReader(s.app, s.results[s.cursor].Entry))
+	}
+	if i := s.cursor - len(s.results); i < len(s.expenses) {
+		x := s.expenses[i].Expense
+		trip, err := s.app.store.GetTrip(s.app.ctx, x.TripID)
+		if err != nil {
+			return nil
+		}
+		return push(newExpenseDetail(s.app, trip, x))
+	}
+	return nil
+}
+
+func (s search) View() string {
+	var b strings.Builder
+	b.WriteString(s.input.View() + "\n")
+	if c := s.complete.view(s.input.Value()); c != "" {
+		b.WriteString(c + "\n")
+	}
+	b.WriteString("\n")
+	if s.err != nil {
+		b.WriteString(errorStyle.Render(s.err.Error()) + "\n")
+	}
+	switch {
+	case strings.TrimSpace(s.input.Value()) == "":
+		b.WriteString(hintStyle.Render("Search journal titles, text and tags, and expenses, across every trip.") + "\n")
+		b.WriteString(hintStyle.Render("Narrow it with "+storage.FilterNames()+", as in ramen trip:japan after:2024-05-01.") + "\n")
+	case s.found() == 0 && s.err == nil:
+		b.WriteString("Nothing matches.\n")
+	}
+
+	// Each result takes three lines; keep the selection on screen.
+	visible := s.found()
+	if s.height > 0 {
+		visible = max((s.height-6)/3, 1)
+	}
+	first := max(0, s.cursor-visible+1)
+	for i := first; i < s.found() && i < first+visible; i++ {
+		cursor := "  "
+		if i == s.cursor {
+			cursor = "👉"
+		}
+		if i >= len(s.results) {
+			m := s.expenses[i-len(s.results)]
+			x := m.Expense
+			fmt.Fprintf(&b, "%s %s %s\n", cursor, labelStyle.Render("💰 "+x.Description),
+				hintStyle.Render(m.TripTitle+" · "+s.app.formatDate(x.Timestamp)))
+			fmt.Fprintf(&b, "   %s %s\n\n", formatAmount(x.Amount, x.Currency), categoryLabel(x.Category, 0))
+			continue
+		}
+		r := s.results[i]
+		title := r.Entry.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, labelStyle.Render(title),
+			hintStyle.Render(r.TripTitle+" · "+s.app.formatDate(r.Entry.Timestamp)))
+		fmt.Fprintf(&b, "   %s\n\n", highlight(r.Snippet))
+	}
+	if s.found() > 0 {
+		b.WriteString(hintStyle.Render(fmt.Sprintf("%d of %s, %s", s.cursor+1,
+			plural(len(s.results), "entry", "entries"), plural(len(s.expenses), "expense", "expenses"))) + "\n")
+	}
+	b.WriteString(hintStyle.Render("type to search • → complete a filter • ↑/↓ move • enter open • esc back") + "\n")
+	return b.String()
+}
+
+// highlight flattens a snippet onto one line and styles its matched terms.
+func highlight(snippet string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+	var b strings.Builder
+	for {
+		start := strings.Index(snippet, storage.HighlightStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(snippet[start:], storage.HighlightEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(snippet[:start])
+		b.WriteString(highlightStyle.Render(snippet[start+len(storage.HighlightStart) : end]))
+		snippet = snippet[end+len(storage.HighlightEnd):]
+	}
+	b.WriteString(snippet)
+	return b.String()
+}
@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// resumer is implemented by screens that can be reopened as they were
+// left: the lists of trips and a trip's screens reached from them. Where
+// the TUI was left is saved with the drafts, so the next start can offer
+// to reopen the screens over the home menu, as far up the stack as the
+// first that cannot be.
+type resumer interface {
+	// resume describes the screen to reopen it, or reports false when it
+	// cannot be.
+	resume() (models.SessionScreen, bool)
+}
+
+// session describes the screens open over the home menu, up to the first
+// that cannot be reopened.
+func (m Model) session() *models.Session {
+	sess := &models.Session{Screens: []models.SessionScreen{}}
+	for _, s := range m.stack[1:] {
+		r, ok := s.(resumer)
+		if !ok {
+			break
+		}
+		screen, ok := r.resume()
+		if !ok {
+			break
+		}
+		sess.Screens = append(sess.Screens, screen)
+	}
+	return sess
+}
+
+// saveSession saves where the TUI is, unless it is the same as when last
+// saved, the resume setting is off, or the offer to resume is still open:
+// the session it offers is kept until it is taken up or turned down.
+func (m *Model) saveSession() {
+	if m.app.store == nil || m.app.readOnly() || m.app.cfg.Resume == config.ResumeOff {
+		return
+	}
+	for _, s := range m.stack {
+		if _, ok := s.(resumeOffer); ok {
+			return
+		}
+	}
+	sess := m.session()
+	if sess.Equal(m.saved) {
+		return
+	}
+	sess.SavedAt = time.Now()
+	var err error
+	if len(sess.Screens) == 0 {
+		err = m.app.store.ClearSession()
+	} else {
+		err = m.app.store.SaveSession(sess)
+	}
+	if err != nil {
+		slog.Warn("ui: saving the session failed", "err", err)
+		return
+	}
+	m.saved = sess
+}
+
+// quitMsg quits nomadic once the drafts and where the TUI was left are
+// saved.
+type quitMsg struct{}
+
+func quit() tea.Msg { return quitMsg{} }
+
+// lastSession reads where the TUI was left when it last closed, if it is
+// to be reopened.
+func (a *app) lastSession() *models.Session {
+	if a.readOnly() || a.cfg.Resume == config.ResumeOff {
+		return nil
+	}
+	sess, err := a.store.LoadSession()
+	if err != nil {
+		slog.Warn("ui: loading the session failed", "err", err)
+		return nil
+	}
+	if sess == nil || len(sess.Screens) == 0 {
+		return nil
+	}
+	return sess
+}
+
+// restoreSession reopens the screens of a session, from the bottom up.
+// Records deleted since stop it there, keeping the screens below.
+func restoreSession(a *app, sess *models.Session) ([]screen, error) {
+	var screens []screen
+	for _, s := range sess.Screens {
+		sc, err := restoreScreen(a, s)
+		if errors.Is(err, storage.ErrNotFound) {
+			if len(screens) == 0 {
+				return nil, errors.New("what was open has been deleted since")
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		screens = append(screens, sc)
+	}
+	return screens, nil
+}
+
+// restoreScreen reopens one screen of a session, with its cursor where it
+// was as far as what it lists now allows.
+func restoreScreen(a *app, s models.SessionScreen) (screen, error) {
+	switch s.Kind {
+	case models.SessionTrips:
+		b := newTripBrowser(a)
+		b.list.pick(s.ID)
+		b.sync()
+		return b, nil
+	case models.SessionPicker:
+		if _, ok := tripScreens[s.Title]; !ok {
+			return nil, fmt.Errorf("cannot reopen a trip picker for %s", s.Title)
+		}
+		p := newMenuPicker(a, s.Title)
+		p.pick(s.ID)
+		return p, nil
+	case models.SessionEntry:
+		e, err := a.store.GetEntry(a.ctx, s.ID)
+		if err != nil {
+			return nil, err
+		}
+		return newEntryReader(a, e), nil
+	}
+	t, err := a.store.GetTrip(a.ctx, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch s.Kind {
+	case models.SessionTrip:
+		d := newTripDetail(a, t)
+		d.cursor = clamp(s.Cursor, 0, len(d.tracks)-1)
+		return d, nil
+	case models.SessionJournal:
+		l := newEntryList(a, t)
+		if s.All || s.Sort != l.sort.String() {
+			l.everyTrip = s.All
+			l.sort = parseEntrySort(s.Sort)
+			l.reload()
+		}
+		l.err = errors.Join(l.err, l.entries.moveTo(s.Cursor, true))
+		return l, nil
+	case models.SessionExpenses:
+		l := newExpenseList(a, t)
+		l.cursor = clamp(s.Cursor, 0, len(l.expenses)-1)
+		return l, nil
+	case models.SessionItinerary:
+		v := newItineraryView(a, t)
+		if day, err := time.Parse(time.DateOnly, s.Day); err == nil {
+			v.showDay(day)
+		}
+		v.cursor = clamp(s.Cursor, 0, len(v.today())-1)
+		return v, nil
+	}
+	return nil, fmt.Errorf("cannot reopen a %s screen", s.Kind)
+}
+
+// parseEntrySort reads the order of a journal as EntrySort.String names
+// it.
+func parseEntrySort(s string) storage.EntrySort {
+	for _, o := range []storage.EntrySort{storage.SortByDate, storage.SortByTrip, storage.SortByTitle} {
+		if o.String() == s {
+			return o
+		}
+	}
+	return storage.SortByDate
+}
+
+// resumeOffer offers to reopen the screens the TUI was left on when it
+// last closed, in place of itself.
+type resumeOffer struct {
+	app     *app
+	session *models.Session
+	err     error
+}
+
+func newResumeOffer(app *app, sess *models.Session) resumeOffer {
+	return resumeOffer{app: app, session: sess}
+}
+
+func (o resumeOffer) Title() string { return tr("Resume") }
+
+func (o resumeOffer) Init() tea.Cmd {
+	return nil
+}
+
+func (o resumeOffer) help() []key.Binding {
+	return []key.Binding{
+		o.app.bind("select", "pick up where you left off"),
+		fixed("esc", "start from the home menu"),
+	}
+}
+
+func (o resumeOffer) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok || !o.app.is(key, "select") {
+		return o, nil
+	}
+	screens, err := restoreSession(o.app, o.session)
+	if err != nil {
+		o.err = err
+		return o, nil
+	}
+	cmds := []tea.Cmd{pop}
+	for _, s := range screens {
+		cmds = append(cmds, push(s))
+	}
+	return o, tea.Sequence(cmds...)
+}
+
+// path names the screens of the session, as the breadcrumbs do.
+func (o resumeOffer) path() string {
+	titles := make([]string, len(o.session.Screens))
+	for i, s := range o.session.Screens {
+		titles[i] = s.Title
+	}
+	return strings.Join(titles, " › ")
+}
+
+func (o resumeOffer) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("↩️  "+tr("Pick up where you left off")) + "\n\n")
+	at := o.session.SavedAt
+	b.WriteString(tr("When nomadic closed on %s at %s, you were at:", o.app.formatDate(at), at.Format("15:04")) + "\n\n")
+	b.WriteString("   " + cursorStyle.Render(o.path()) + "\n")
+	if o.err != nil {
+		b.WriteString("\n" + errorStyle.Render(o.err.Error()) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render(tr("enter resume • esc start from home")) + "\n")
+	return b.String()
+}
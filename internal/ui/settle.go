@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/settle"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+// settleView shows who owes whom for a trip's shared expenses, in the
+// budget currency.
+type settleView struct {
+	app      *app
+	trip     *models.Trip
+	expenses []*models.Expense
+
+	rates    *currency.Rates
+	ratesErr error
+
+	status string
+	err    error
+}
+
+func newSettleView(app *app, trip *models.Trip, rates *currency.Rates) settleView {
+	v := settleView{app: app, trip: trip, rates: rates}
+	v.reload()
+	return v
+}
+
+func (v settleView) Title() string { return tr("Settle up") }
+
+func (v settleView) currentTrip() *models.Trip { return v.trip }
+
+func (v settleView) Init() tea.Cmd {
+	if v.rates != nil {
+		return nil
+	}
+	return v.app.fetchRates()
+}
+
+func (v settleView) help() []key.Binding {
+	return []key.Binding{v.app.bind("edit", "change the companions"), v.app.bind("quit", "close")}
+}
+
+func (v *settleView) reload() {
+	v.expenses, v.err = v.app.store.ListExpensesByTrip(v.app.ctx, v.trip.ID)
+}
+
+func (v settleView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ratesMsg:
+		v.rates, v.ratesErr = msg.rates, msg.err
+	case tripSavedMsg:
+		if msg.trip.ID == v.trip.ID {
+			v.trip = msg.trip
+			return v, toast(toastSuccess, "Saved companions")
+		}
+	case expenseSavedMsg, lodgingsChangedMsg, historyMsg:
+		v.reload()
+	case tea.KeyMsg:
+		switch {
+		case v.app.is(msg, "quit"):
+			return v, pop
+		case v.app.is(msg, "edit"):
+			return v, push(newCompanionsForm(v.app, v.trip))
+		}
+	}
+	return v, nil
+}
+
+func (v settleView) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🤝 Settle up: "+v.trip.Title) + "\n\n")
+	if v.err != nil {
+		b.WriteString(errorStyle.Render(v.err.Error()) + "\n\n")
+	}
+	var convert settle.Converter
+	if v.rates != nil {
+		convert = v.rates.Convert
+	}
+	cur := v.app.budgetCurrency(v.trip)
+	s := settle.Compute(v.trip.People(), v.expenses, cur, convert)
+
+	if len(v.trip.Companions) == 0 {
+		b.WriteString("Nobody travels along yet — press " + v.app.keyHint("edit") + " to add companions.\n")
+	}
+	if s.Shared == 0 && s.Unconverted == 0 {
+		if len(v.trip.Companions) > 0 {
+			b.WriteString("No shared expenses yet — fill in Split when recording one.\n")
+		}
+	} else {
+		b.WriteString(labelStyle.Render(fmt.Sprintf("%-16s %12s %12s %14s", "Person", "Paid", "Share", "Balance")) + "\n")
+		for _, bal := range s.Balances {
+			net := fmt.Sprintf("%+14.2f", bal.Net)
+			switch {
+			case bal.Net > 0:
+				net = successStyle.Render(net)
+			case bal.Net < 0:
+				net = errorStyle.Render(net)
+			}
+			fmt.Fprintf(&b, "%-16s %12.2f %12.2f %s\n", truncate(bal.Name, 16), bal.Paid, bal.Share, net)
+		}
+		b.WriteString(hintStyle.Render(fmt.Sprintf("%s settled in %s", plural(s.Shared, "shared expense", "shared expenses"), cur)) + "\n")
+		if s.Unconverted > 0 {
+			b.WriteString(warningStyle.Render(plural(s.Unconverted, "expense", "expenses")+" in other currencies not counted"+v.ratesNote()) + "\n")
+		}
+
+		b.WriteString("\n" + labelStyle.Render("Transfers") + "\n")
+		if len(s.Transfers) == 0 {
+			b.WriteString(successStyle.Render("Everyone is settled up.") + "\n")
+		}
+		for _, t := range s.Transfers {
+			fmt.Fprintf(&b, "  %s\n", transferLine(t, cur))
+		}
+	}
+	if v.status != "" {
+		b.WriteString("\n" + v.status + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render(v.app.keyHint("edit")+" companions • esc back") + "\n")
+	return b.String()
+}
+
+// ratesNote explains why expenses could not be converted.
+func (v settleView) ratesNote() string {
+	switch {
+	case v.app.rates == nil:
+		return ""
+	case v.ratesErr != nil:
+		return " (exchange rates unavailable)"
+	case v.rates == nil:
+		return " (loading exchange rates)"
+	}
+	return ""
+}
+
+// transferLine describes a transfer, speaking to the traveler as "you".
+func transferLine(t settle.Transfer, currency string) string {
+	amount := highlightStyle.Render(formatAmount(t.Amount, currency))
+	switch {
+	case t.From == models.Me:
+		return fmt.Sprintf("You pay %s %s", t.To, amount)
+	case t.To == models.Me:
+		return fmt.Sprintf("%s pays you %s", t.From, amount)
+	}
+	return fmt.Sprintf("%s pays %s %s", t.From, t.To, amount)
+}
+
+// companionsForm changes who travels along on a trip.
+type companionsForm struct {
+	form
+	app  *app
+	trip *models.Trip
+}
+
+func newCompanionsForm(app *app, trip *models.Trip) companionsForm {
+	f := newForm("🤝 Companions",
+		newField("Companions", "Ana, Ben", "Separate names with commas; you are \"me\".", validateCompanions))
+	f.fields[0].input.SetValue(strings.Join(trip.Companions, ", "))
+	f.fields[0].tags = newPeopleCompleter(app)
+	// Work on a copy so cancelling leaves the caller's trip untouched.
+	edited := *trip
+	return companionsForm{form: f, app: app, trip: &edited}
+}
+
+func (f companionsForm) Title() string { return tr("Companions") }
+
+func (f companionsForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f companionsForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		names, err := models.ParseCompanions(splitList(f.value(0)))
+		if err == nil {
+			f.trip.Companions = names
+			err = f.app.store.SaveTrip(f.app.ctx, f.trip)
+		}
+		if err != nil {
+			f.err = err
+			return f, nil
+		}
+		t := f.trip
+		return f, tea.Sequence(pop, func() tea.Msg { return tripSavedMsg{trip: t} })
+	}
+	return f, cmd
+}
+
+func (f companionsForm) View() string {
+	return f.form.view(func() string {
+		names := strings.Join(splitList(f.value(0)), ", ")
+		if names == "" {
+			names = hintStyle.Render("—")
+		}
+		return fmt.Sprintf("%s %s\n", labelStyle.Render("Companions:"), names)
+	})
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/theme"
+)
+
+const (
+	setupFieldDataDir = iota
+	setupFieldCurrency
+	setupFieldDateFormat
+	setupFieldTheme
+	setupFieldFirstTrip
+)
+
+// setup is the wizard shown on the first run, before there is a data
+// directory: it settles where the journal is kept and the settings most
+// worth choosing up front, then opens the store there.
+type setup struct {
+	form
+	app  *app
+	open func(config.Config) (*storage.Store, error)
+	busy bool
+}
+
+// setupDoneMsg replaces the wizard with the home menu once the settings
+// are saved and the store is open, followed by the New Trip screen when
+// firstTrip is set.
+type setupDoneMsg struct {
+	store     *storage.Store
+	cfg       config.Config
+	firstTrip bool
+}
+
+// setupFailedMsg reports settings that could not be saved or a store that
+// could not be opened.
+type setupFailedMsg struct {
+	err error
+}
+
+func newSetup(app *app, open func(config.Config) (*storage.Store, error)) setup {
+	s := setup{app: app, open: open}
+	s.form = newForm("👋 Welcome to Nomadic",
+		newField("Data directory", "~/.local/share/nomadic", "Where your trips, journal and expenses are kept. ~ is your home directory.", validateDataDir),
+		newField("Home currency", "EUR", "Totals and stats are shown in it, and new expenses default to it.", validateCurrency),
+		newField("Date format", "YYYY-MM-DD", "How dates are shown and typed, written with YYYY, MM and DD, e.g. DD/MM/YYYY.", validateDateFormat),
+		newField("Theme", "dark", "One of "+strings.Join(theme.Names(app.cfg.CustomThemes), ", ")+
+			". Press "+app.keyHint("theme")+" anywhere to switch later.", app.validateTheme),
+		newField("Plan your first trip now?", "yes", "yes or no.", validateYesNo),
+	)
+	s.fields[setupFieldDataDir].input.SetValue(app.cfg.DataDir)
+	s.fields[setupFieldCurrency].input.SetValue(app.cfg.HomeCurrency)
+	s.fields[setupFieldDateFormat].input.SetValue(app.cfg.DateFormat)
+	s.fields[setupFieldTheme].input.SetValue(app.cfg.Theme)
+	s.fields[setupFieldFirstTrip].input.SetValue("yes")
+	s.fields[setupFieldDataDir].input.CursorEnd()
+	return s
+}
+
+func (s setup) Title() string { return tr("Welcome") }
+
+func (s setup) Init() tea.Cmd {
+	return nil
+}
+
+func (s setup) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case setupFailedMsg:
+		s.busy, s.err = false, msg.err
+		return s, nil
+	case tea.KeyMsg:
+		if s.busy {
+			return s, nil
+		}
+		// Show the theme chosen as soon as it is.
+		if s.step == setupFieldTheme && isAdvance(msg) && s.app.validateTheme(s.value(setupFieldTheme)) == nil {
+			s.app.setTheme(s.value(setupFieldTheme))
+		}
+	}
+
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	s.form, cmd, result = s.form.update(msg)
+	switch result {
+	case formCancelled:
+		return s, tea.Quit
+	case formConfirmed:
+		cfg := s.config()
+		if err := cfg.Validate(); err != nil {
+			s.err = err
+			return s, nil
+		}
+		s.busy, s.err = true, nil
+		open, firstTrip := s.open, yes(s.value(setupFieldFirstTrip))
+		return s, func() tea.Msg {
+			store, err := open(cfg)
+			if err != nil {
+				return setupFailedMsg{err: err}
+			}
+			return setupDoneMsg{store: store, cfg: cfg, firstTrip: firstTrip}
+		}
+	}
+	return s, cmd
+}
+
+// config is the settings with the answers of the wizard applied.
+func (s setup) config() config.Config {
+	cfg := s.app.cfg
+	cfg.DataDir = expandHome(s.value(setupFieldDataDir))
+	currency := strings.ToUpper(s.value(setupFieldCurrency))
+	cfg.HomeCurrency, cfg.DefaultCurrency = currency, currency
+	cfg.DateFormat = s.value(setupFieldDateFormat)
+	cfg.Theme = s.value(setupFieldTheme)
+	return cfg
+}
+
+func (s setup) View() string {
+	view := s.view(s.summary)
+	if s.busy {
+		view += "\n" + hintStyle.Render("Setting up…") + "\n"
+	}
+	return view
+}
+
+func (s setup) summary() string {
+	cfg := s.config()
+	var b strings.Builder
+	row := func(label, value string) {
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-15s", label+":")), value)
+	}
+	row("Data directory", cfg.DataDir)
+	row("Home currency", cfg.HomeCurrency)
+	row("Date format", fmt.Sprintf("%s (today is %s)", cfg.DateFormat, time.Now().Format(cfg.Layout())))
+	row("Theme", cfg.Theme)
+	if yes(s.value(setupFieldFirstTrip)) {
+		b.WriteString("\nNext you'll plan your first trip.\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("Change any of these later with `nomadic config set`.") + "\n")
+	return b.String()
+}
+
+func validateDataDir(v string) error {
+	if !filepath.IsAbs(expandHome(v)) {
+		return errors.New("enter a full path, e.g. ~/Travel")
+	}
+	return nil
+}
+
+func validateDateFormat(v string) error {
+	_, err := config.GoLayout(v)
+	return err
+}
+
+func (a *app) validateTheme(v string) error {
+	_, err := theme.Resolve(v, a.cfg.CustomThemes)
+	return err
+}
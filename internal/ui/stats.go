@@ -0,0 +1,364 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/chart"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/stats"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+const (
+	chartWidth        = 30
+	maxChartLabel     = 14
+	maxDestinationBar = 8
+	// writingMonths is how many of the last months written in the
+	// words by month lists.
+	writingMonths = 12
+)
+
+// statsScreen shows aggregate numbers across all trips.
+type statsScreen struct {
+	app      *app
+	trips    []*models.Trip
+	expenses []*models.Expense
+	segments []*models.Segment
+	// entries are every trip's, for the moods and what was written.
+	entries []*models.Entry
+	// achievements are measured against the trips and entries.
+	achievements []stats.Achievement
+	// period is the year, or all time, whose spending is summarized.
+	period stats.Period
+
+	rates    *currency.Rates
+	ratesErr error
+	err      error
+}
+
+func newStatsScreen(app *app) statsScreen {
+	s := statsScreen{app: app, period: stats.Year(time.Now().Year())}
+	s.reload()
+	return s
+}
+
+func (s *statsScreen) reload() {
+	if s.trips, s.err = s.app.store.ListTrips(s.app.ctx); s.err != nil {
+		return
+	}
+	if s.expenses, s.err = s.app.store.ListExpenses(s.app.ctx); s.err != nil {
+		return
+	}
+	if s.segments, s.err = s.app.store.ListSegments(s.app.ctx); s.err != nil {
+		return
+	}
+	if s.entries, s.err = s.app.store.ListEntries(s.app.ctx); s.err != nil {
+		return
+	}
+	s.achievements = s.app.achievements()
+}
+
+func (s statsScreen) Title() string { return tr("Stats") }
+
+func (s statsScreen) Init() tea.Cmd {
+	return s.app.fetchRates()
+}
+
+func (s statsScreen) help() []key.Binding {
+	return []key.Binding{
+		s.app.bind("prev_month", "spending of the year before"),
+		s.app.bind("next_month", "spending of the year after, then of all time"),
+		s.app.bind("quit", "close"),
+	}
+}
+
+// periods lists what the spending summary steps through: each year from
+// the first expense to this one, then all time.
+func (s statsScreen) periods() []stats.Period {
+	first := time.Now().Year()
+	for _, x := range s.expenses {
+		first = min(first, x.Timestamp.Year())
+	}
+	var out []stats.Period
+	for y := first; y <= time.Now().Year(); y++ {
+		out = append(out, stats.Year(y))
+	}
+	return append(out, stats.AllTime)
+}
+
+func (s statsScreen) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case ratesMsg:
+		s.rates, s.ratesErr = msg.rates, msg.err
+	case tripSavedMsg, entrySavedMsg, expenseSavedMsg, expensesImportedMsg, lodgingsChangedMsg, historyMsg,
+		achievementsUnlockedMsg:
+		s.reload()
+	case tea.KeyMsg:
+		switch {
+		case s.app.is(msg, "quit"):
+			return s, pop
+		case s.app.is(msg, "prev_month"), s.app.is(msg, "next_month"):
+			periods := s.periods()
+			i := slices.Index(periods, s.period)
+			if s.app.is(msg, "prev_month") {
+				i--
+			} else {
+				i++
+			}
+			s.period = periods[clamp(i, 0, len(periods)-1)]
+		}
+	}
+	return s, nil
+}
+
+func (s statsScreen) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("📊 Stats") + "\n\n")
+	if s.err != nil {
+		b.WriteString(errorStyle.Render(s.err.Error()) + "\n")
+		return b.String()
+	}
+	if len(s.trips) == 0 {
+		b.WriteString("No trips yet — create one from ✈️  New Trip.\n")
+		b.WriteString("\n" + hintStyle.Render("esc back") + "\n")
+		return b.String()
+	}
+
+	var convert stats.Converter
+	if s.rates != nil {
+		convert = s.rates.Convert
+	}
+	home := s.app.cfg.HomeCurrency
+	sum := stats.Compute(s.trips, s.expenses, s.segments, home, convert, time.Now())
+
+	row := func(label, value string) {
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-18s", label)), value)
+	}
+	row("Trips", fmt.Sprint(sum.Trips))
+	row("Destinations", fmt.Sprint(len(sum.Destinations)))
+	if len(sum.Countries) > 0 {
+		row("Countries", fmt.Sprint(len(sum.Countries)))
+	}
+	row("Days traveled", fmt.Sprint(sum.DaysTraveled))
+	if sum.Longest != nil {
+		row("Longest trip", fmt.Sprintf("%s %s", sum.Longest.Title, hintStyle.Render(fmt.Sprintf("(%d days)", sum.LongestDays))))
+	}
+	row("Total spend", formatAmount(sum.TotalSpend, home))
+	if sum.DaysTraveled > 0 {
+		row("Average per day", formatAmount(sum.AverageDaily, home))
+	}
+	if sum.Unconverted > 0 {
+		b.WriteString(hintStyle.Render(plural(sum.Unconverted, "expense", "expenses")+" in other currencies not counted"+s.ratesNote()) + "\n")
+	}
+
+	if f := sum.Footprint; f.Segments > 0 {
+		row("Distance", fmt.Sprintf("%.0f km", f.Distance)+hintStyle.Render(" over "+plural(f.Segments, "journey", "journeys")))
+		row("Carbon footprint", "~"+stats.FormatCO2(f.CO2))
+	}
+	b.WriteString(s.spendingView(convert))
+	if len(sum.SpendByYear) > 0 {
+		b.WriteString("\n" + labelStyle.Render("Spend by year") + "\n")
+		b.WriteString(barChart(sum.SpendByYear, func(v float64) string { return formatAmount(v, home) }))
+	}
+	if len(sum.SpendCategory) > 0 {
+		b.WriteString("\n" + labelStyle.Render("Spend by category") + "\n")
+		b.WriteString(barChart(sum.SpendCategory, func(v float64) string { return formatAmount(v, home) }))
+	}
+	if len(sum.Destinations) > 0 {
+		b.WriteString("\n" + labelStyle.Render("Most visited") + "\n")
+		top := sum.Destinations[:min(len(sum.Destinations), maxDestinationBar)]
+		b.WriteString(barChart(top, func(v float64) string { return plural(int(v), "trip", "trips") }))
+	}
+	if len(sum.Countries) > 0 {
+		b.WriteString("\n" + labelStyle.Render("Countries") + "\n")
+		top := sum.Countries[:min(len(sum.Countries), maxDestinationBar)]
+		b.WriteString(barChart(top, func(v float64) string { return plural(int(v), "trip", "trips") }))
+	}
+	if f := sum.Footprint; f.Segments > f.Unestimated {
+		b.WriteString("\n" + labelStyle.Render("Emissions by transport") + "\n")
+		var rows []stats.Amount
+		for _, m := range f.ByMode {
+			if _, ok := models.EmissionFactors[m.Mode]; ok {
+				rows = append(rows, stats.Amount{Label: transportIcon(m.Mode) + " " + m.Mode, Value: m.CO2})
+			}
+		}
+		b.WriteString(barChart(rows, stats.FormatCO2))
+	}
+	if moods := stats.Moods(s.trips, s.entries); len(moods) > 0 {
+		b.WriteString("\n" + labelStyle.Render("Mood by trip") + "\n")
+		b.WriteString(moodChart(moods[:min(len(moods), maxDestinationBar)]))
+	}
+	b.WriteString(s.writingView())
+	b.WriteString(s.achievementsView())
+	b.WriteString("\n" + hintStyle.Render(s.app.keyHint("prev_month")+"/"+s.app.keyHint("next_month")+" spending by year • esc back") + "\n")
+	return b.String()
+}
+
+// spendingView summarizes the spending of the period: its total against
+// the year before, the months (or years) spent in and the top categories.
+func (s statsScreen) spendingView(convert stats.Converter) string {
+	home := s.app.cfg.HomeCurrency
+	sp := stats.ComputeSpending(s.trips, s.expenses, s.period, home, convert)
+	var b strings.Builder
+	title := "Spending in " + s.period.String()
+	if s.period == stats.AllTime {
+		title = "Spending of all time"
+	}
+	b.WriteString("\n" + labelStyle.Render(title) + " " + formatAmount(sp.Total, home))
+	if c := sp.FormatChange(); c != "" {
+		b.WriteString(hintStyle.Render(fmt.Sprintf(" %s on %s", c, s.period.YearBefore())))
+	}
+	b.WriteString("\n")
+	if sp.Count == 0 {
+		b.WriteString(hintStyle.Render("  Nothing spent.") + "\n")
+		return b.String()
+	}
+	if s.period != stats.AllTime && s.period.Month == 0 {
+		totals := make([]float64, len(sp.Months))
+		for i, m := range sp.Months {
+			totals[i] = m.Total
+		}
+		h := chart.Histogram{Values: totals, Width: 3, First: tr("Jan"), Last: tr("Dec"),
+			Format: func(v float64) string { return formatAmount(v, home) },
+			Paint:  func(s string) string { return cursorStyle.Render(s) }}
+		for _, line := range h.Lines() {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	// Each month or year is labeled with its change on the year before.
+	var rows []stats.Amount
+	spends := sp.Months
+	if s.period == stats.AllTime {
+		spends = sp.Years
+	}
+	for _, p := range spends {
+		if p.Count == 0 {
+			continue
+		}
+		label := p.Label
+		if s.period != stats.AllTime {
+			if t, err := time.Parse("2006-01", p.Label); err == nil {
+				label = loc.Month(t.Month())
+			}
+		}
+		if c := p.FormatChange(); c != "" {
+			label += " " + c
+		}
+		rows = append(rows, stats.Amount{Label: label, Value: p.Total})
+	}
+	b.WriteString(barChart(rows, func(v float64) string { return formatAmount(v, home) }))
+	top := sp.Categories[:min(len(sp.Categories), 3)]
+	parts := make([]string, len(top))
+	for i, c := range top {
+		parts[i] = fmt.Sprintf("%s %.0f%%", c.Label, c.Value/sp.Total*100)
+	}
+	b.WriteString(hintStyle.Render("  Top: "+strings.Join(parts, " • ")) + "\n")
+	return b.String()
+}
+
+// writingView sums up the journal: the words written, an entry's length
+// on average, and the words of each trip and of the last months written
+// in.
+func (s statsScreen) writingView() string {
+	w := stats.ComputeWriting(s.trips, s.entries)
+	if w.Words == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n" + labelStyle.Render("Writing") + " " + plural(w.Words, "word", "words") +
+		hintStyle.Render(fmt.Sprintf(" in %s, %s on average", plural(w.Entries, "entry", "entries"), plural(int(w.Average()+0.5), "word", "words"))) + "\n")
+	if e := w.Longest; e != nil {
+		title := e.Title
+		if title == "" {
+			title = tr("Untitled")
+		}
+		b.WriteString(hintStyle.Render(fmt.Sprintf("  Longest: %s, %s, %s", title, s.app.formatDate(e.Timestamp), plural(w.LongestWords, "word", "words"))) + "\n")
+	}
+	words := func(v float64) string { return plural(int(v), "word", "words") }
+	b.WriteString("\n" + labelStyle.Render("Words by trip") + "\n")
+	b.WriteString(barChart(w.ByTrip[:min(len(w.ByTrip), maxDestinationBar)], words))
+	months := w.ByMonth[max(len(w.ByMonth)-writingMonths, 0):]
+	rows := make([]stats.Amount, len(months))
+	for i, m := range months {
+		rows[i] = m
+		if t, err := time.Parse("2006-01", m.Label); err == nil {
+			rows[i].Label = loc.Month(t.Month()) + " " + t.Format("2006")
+		}
+	}
+	b.WriteString("\n" + labelStyle.Render("Words by month") + "\n")
+	b.WriteString(barChart(rows, words))
+	return b.String()
+}
+
+// moodChart renders a line per trip with its rating and the sparkline of
+// its mood, day by day.
+func moodChart(moods []stats.TripMood) string {
+	labelWidth := 0
+	for _, m := range moods {
+		labelWidth = max(labelWidth, lipgloss.Width(truncate(m.Trip.Title, maxChartLabel)))
+	}
+	var b strings.Builder
+	for _, m := range moods {
+		label := truncate(m.Trip.Title, maxChartLabel)
+		stars := models.Stars(m.Trip.Rating)
+		if stars == "" {
+			stars = strings.Repeat(" ", models.MaxRating)
+		}
+		line := ""
+		if len(m.Trend) > 0 {
+			avg := m.Trend.Average()
+			line = cursorStyle.Render(m.Trend.Sparkline()) + " " + models.MoodFace(int(avg+0.5)) + hintStyle.Render(fmt.Sprintf(" %.1f", avg))
+		}
+		fmt.Fprintf(&b, "  %s%s %s %s\n", label, strings.Repeat(" ", labelWidth-lipgloss.Width(label)), highlightStyle.Render(stars), line)
+	}
+	return b.String()
+}
+
+func (s statsScreen) ratesNote() string {
+	switch {
+	case s.app.rates == nil:
+		return ""
+	case s.ratesErr != nil:
+		return " (exchange rates unavailable)"
+	case s.rates == nil:
+		return " (loading exchange rates)"
+	}
+	return ""
+}
+
+// barChart renders one horizontal bar per row, scaled to the largest value.
+func barChart(rows []stats.Amount, format func(float64) string) string {
+	cols := make([]chart.Row, len(rows))
+	for i, r := range rows {
+		cols[i] = chart.Row{Label: truncate(r.Label, maxChartLabel), Value: r.Value}
+	}
+	var b strings.Builder
+	for _, l := range chart.Bars(cols, chartWidth, format) {
+		fmt.Fprintf(&b, "  %s %s %s\n", l.Label, cursorStyle.Render(l.Bar), hintStyle.Render(l.Value))
+	}
+	return b.String()
+}
+
+// plural formats a count with the singular or plural noun.
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + tr(one)
+	}
+	return loc.Number(float64(n), 0) + " " + tr(many)
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
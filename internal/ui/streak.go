@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/streak"
+)
+
+// streak measures the journaling streak on the trip in progress. It is a
+// nicety, so a store that cannot be read simply shows none.
+func (a *app) streak() streak.Streak {
+	if a.store == nil {
+		return streak.Streak{}
+	}
+	trips, err := a.store.ListTrips(a.ctx)
+	if err != nil {
+		return streak.Streak{}
+	}
+	trip := streak.Active(trips, time.Now())
+	var entries []*models.Entry
+	if trip != nil {
+		if entries, err = a.store.ListEntryTimes(a.ctx, storage.EntryQuery{TripID: trip.ID}); err != nil {
+			return streak.Streak{}
+		}
+	}
+	return streak.Compute(trip, entries, a.tripNow(trip))
+}
+
+// streakBadge renders the streak for the header, or nothing when no trip
+// is in progress.
+func streakBadge(s streak.Streak) string {
+	switch {
+	case s.Trip == nil:
+		return ""
+	case s.WrittenToday:
+		return successStyle.Render("🔥 " + tr("%d-day streak", s.Current))
+	case s.Current > 0:
+		return warningStyle.Render("🔥 " + tr("%d-day streak · write today's entry to keep it", s.Current))
+	}
+	return hintStyle.Render("✍️  " + tr("No journal entry today"))
+}
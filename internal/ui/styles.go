@@ -0,0 +1,91 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/theme"
+)
+
+// Styles shared by every screen. They are rebuilt by applyTheme, so
+// screens must look them up when rendering rather than keep copies.
+var (
+	headerStyle    lipgloss.Style
+	labelStyle     lipgloss.Style
+	hintStyle      lipgloss.Style
+	errorStyle     lipgloss.Style
+	warningStyle   lipgloss.Style
+	successStyle   lipgloss.Style
+	cursorStyle    lipgloss.Style
+	paneStyle      lipgloss.Style
+	focusStyle     lipgloss.Style
+	highlightStyle lipgloss.Style
+)
+
+func init() {
+	p, _ := theme.Resolve("dark", nil)
+	applyTheme(p)
+}
+
+// applyTheme rebuilds the shared styles from a palette.
+func applyTheme(p theme.Palette) {
+	fg := func(c string) lipgloss.Style {
+		s := lipgloss.NewStyle()
+		if c != "" {
+			s = s.Foreground(lipgloss.Color(c))
+		}
+		return s
+	}
+	headerStyle = fg(p.Title).Bold(true)
+	labelStyle = fg(p.Label).Bold(true)
+	hintStyle = fg(p.Muted)
+	errorStyle = fg(p.Error)
+	warningStyle = fg(p.Warning)
+	successStyle = fg(p.Success)
+	cursorStyle = fg(p.Cursor).Bold(true)
+	paneStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(p.Border)).
+		Padding(0, 1)
+	focusStyle = paneStyle.BorderForeground(lipgloss.Color(p.Cursor))
+	highlightStyle = fg(p.Highlight).Bold(true)
+	switch {
+	case accessible:
+		paneStyle, focusStyle = lipgloss.NewStyle(), lipgloss.NewStyle()
+	case asciiOnly:
+		paneStyle, focusStyle = paneStyle.Border(lipgloss.ASCIIBorder()), focusStyle.Border(lipgloss.ASCIIBorder())
+	}
+}
+
+// themeChangedMsg tells screens that cache rendered output to redraw it.
+type themeChangedMsg struct {
+	name string
+}
+
+func (themeChangedMsg) broadcast() {}
+
+// setTheme switches the interface to the named theme for this session.
+func (a *app) setTheme(name string) error {
+	p, err := theme.Resolve(name, a.cfg.CustomThemes)
+	if err != nil {
+		return err
+	}
+	a.cfg.Theme, a.palette = name, p
+	applyTheme(p)
+	return nil
+}
+
+// nextTheme switches to the theme after the current one and reports it.
+func (a *app) nextTheme() tea.Cmd {
+	names := theme.Names(a.cfg.CustomThemes)
+	next := names[0]
+	for i, n := range names {
+		if n == a.cfg.Theme {
+			next = names[(i+1)%len(names)]
+		}
+	}
+	if err := a.setTheme(next); err != nil {
+		return nil
+	}
+	return func() tea.Msg { return themeChangedMsg{name: next} }
+}
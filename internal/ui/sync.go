@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/gitsync"
+	"github.com/girdharshubham/nomadic/internal/oplog"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// syncStatusMsg carries the state of the data directory's repository.
+type syncStatusMsg struct {
+	status gitsync.Status
+	err    error
+}
+
+// syncStatus inspects the repository in the background.
+func (a *app) syncStatus() tea.Cmd {
+	if a.sync == nil {
+		return nil
+	}
+	repo := a.sync
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, 10*time.Second)
+		defer cancel()
+		s, err := repo.Status(ctx)
+		return syncStatusMsg{status: s, err: err}
+	}
+}
+
+// autoSync commits, and with auto_sync = push pushes, once the store has
+// changed since the last commit, then reports the repository's state. The
+// changes are logged first when the data directory keeps operation logs.
+func (a *app) autoSync() tea.Cmd {
+	if a.sync == nil || a.store == nil || a.cfg.AutoSync == config.SyncOff {
+		return nil
+	}
+	changes := a.store.Changes()
+	if changes == a.synced {
+		return nil
+	}
+	a.synced = changes
+	repo, store, push := a.sync, a.store, a.cfg.AutoSync == config.SyncPush
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(a.ctx, time.Minute)
+		defer cancel()
+		var err error
+		if oplog.Enabled(repo.Dir()) && !storage.IsEncrypted(repo.Dir()) {
+			var log *oplog.Log
+			if log, err = oplog.Open(repo.Dir()); err == nil {
+				_, err = log.Record(ctx, store)
+			}
+		}
+		if err == nil {
+			err = store.Checkpoint(ctx)
+		}
+		if err == nil {
+			_, err = repo.Commit(ctx, "Save changes from the nomadic TUI")
+		}
+		if err == nil && push {
+			err = repo.Push(ctx)
+		}
+		s, serr := repo.Status(ctx)
+		if err == nil {
+			err = serr
+		}
+		return syncStatusMsg{status: s, err: err}
+	}
+}
+
+// syncIndicator renders the sync state for the footer.
+func syncIndicator(msg *syncStatusMsg) string {
+	if msg == nil {
+		return hintStyle.Render("⟳ checking sync…")
+	}
+	s := msg.status
+	switch {
+	case msg.err != nil:
+		return errorStyle.Render("⚠ sync: " + msg.err.Error())
+	case s.Diverged():
+		return warningStyle.Render("⚠ diverged from the remote — run nomadic sync")
+	case s.Dirty:
+		return warningStyle.Render("● unsaved to git")
+	case s.Ahead > 0:
+		return warningStyle.Render("↑ " + plural(s.Ahead, "commit", "commits") + " to push")
+	case s.Behind > 0:
+		return warningStyle.Render("↓ " + plural(s.Behind, "commit", "commits") + " to pull — run nomadic sync")
+	case !s.HasRemote:
+		return hintStyle.Render("✓ committed (no remote)")
+	}
+	return successStyle.Render("✓ synced")
+}
@@ -0,0 +1,468 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// maxTagSuggestions is how many completions are offered at once.
+const maxTagSuggestions = 5
+
+// tagCompleter completes the last of the comma-separated tags typed into a
+// text input from the tags already in use. → accepts the highlighted
+// suggestion and ctrl+n/ctrl+p move between them.
+type tagCompleter struct {
+	known []string // most used first
+	pick  int
+	// names is set when completing people's names, which keep their case,
+	// rather than tags.
+	names bool
+}
+
+func newTagCompleter(a *app) *tagCompleter {
+	c := &tagCompleter{}
+	if tags, err := a.store.ListTags(a.ctx); err == nil {
+		for _, t := range tags {
+			c.known = append(c.known, t.Name)
+		}
+	}
+	return c
+}
+
+// newNameCompleter completes the comma-separated names of known people.
+func newNameCompleter(known []string) *tagCompleter {
+	return &tagCompleter{known: known, names: true}
+}
+
+// newPeopleCompleter completes the names of everyone the traveler has been
+// on trips with.
+func newPeopleCompleter(a *app) *tagCompleter {
+	c := newNameCompleter(nil)
+	if people, err := a.store.ListPeople(a.ctx); err == nil {
+		for _, p := range people {
+			c.known = append(c.known, p.Name)
+		}
+	}
+	return c
+}
+
+// matches returns the known tags starting with the tag being typed, leaving
+// out those already entered.
+func (c *tagCompleter) matches(value string) []string {
+	entered := splitList(value)
+	partial := ""
+	if !strings.HasSuffix(strings.TrimSpace(value), ",") && len(entered) > 0 {
+		partial = entered[len(entered)-1]
+		entered = entered[:len(entered)-1]
+	}
+	known, used := c.known, entered
+	if c.names {
+		// Names match without regard to case.
+		known = make([]string, len(c.known))
+		for i, n := range c.known {
+			known[i] = strings.ToLower(n)
+		}
+		for i, n := range used {
+			used[i] = strings.ToLower(n)
+		}
+		partial = strings.ToLower(partial)
+	} else {
+		partial = strings.ToLower(strings.TrimPrefix(partial, "#"))
+		used = models.NormalizeTags(entered)
+	}
+
+	var out []string
+	for i, t := range known {
+		if strings.HasPrefix(t, partial) && t != partial && !slices.Contains(used, t) {
+			t = c.known[i]
+			out = append(out, t)
+			if len(out) == maxTagSuggestions {
+				break
+			}
+		}
+	}
+	return out
+}
+
+// update handles the completion keys for in. It reports whether key was
+// one of them; other keys are left for the input.
+func (c *tagCompleter) update(in *textinput.Model, key tea.KeyMsg) bool {
+	matches := c.matches(in.Value())
+	if len(matches) == 0 {
+		c.pick = 0
+		return false
+	}
+	c.pick = clamp(c.pick, 0, len(matches)-1)
+	switch key.String() {
+	case "ctrl+n":
+		c.pick = (c.pick + 1) % len(matches)
+	case "ctrl+p":
+		c.pick = (c.pick + len(matches) - 1) % len(matches)
+	case "right":
+		value := in.Value()
+		if in.Position() < len([]rune(value)) {
+			return false
+		}
+		prefix := ""
+		if i := strings.LastIndex(value, ","); i >= 0 {
+			prefix = strings.TrimRight(value[:i+1], " ") + " "
+		}
+		in.SetValue(prefix + matches[c.pick] + ", ")
+		in.CursorEnd()
+		c.pick = 0
+	default:
+		return false
+	}
+	return true
+}
+
+// view renders the suggestions for value, or nothing when there are none.
+func (c *tagCompleter) view(value string) string {
+	matches := c.matches(value)
+	if len(matches) == 0 {
+		return ""
+	}
+	pick := clamp(c.pick, 0, len(matches)-1)
+	mark := "#"
+	if c.names {
+		mark = ""
+	}
+	parts := make([]string, len(matches))
+	for i, t := range matches {
+		if i == pick {
+			parts[i] = cursorStyle.Render(mark + t)
+		} else {
+			parts[i] = hintStyle.Render(mark + t)
+		}
+	}
+	return strings.Join(parts, " ") + hintStyle.Render("  → complete • ctrl+n/p choose")
+}
+
+// newTagsField is a form field for tags with completion.
+func newTagsField(a *app, tags []string) field {
+	f := newField("Tags", "food, friends", "Optional. Separate tags with commas.", nil)
+	f.input.SetValue(strings.Join(tags, ", "))
+	f.tags = newTagCompleter(a)
+	return f
+}
+
+// formatTags renders tags as "#food #friends".
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	return "#" + strings.Join(tags, " #")
+}
+
+// tagFilter narrows a list view to records carrying every one of the tags
+// the user picks. Screens embed it, open it with t and route keys to it
+// while it is editing.
+type tagFilter struct {
+	tags     []string
+	editing  bool
+	input    textinput.Model
+	complete *tagCompleter
+}
+
+func newTagFilter() tagFilter {
+	in := textinput.New()
+	in.Placeholder = "food, friends"
+	in.Width = 40
+	return tagFilter{input: in}
+}
+
+// edit opens the filter for editing, starting from the current tags.
+func (f *tagFilter) edit(a *app) tea.Cmd {
+	f.editing = true
+	f.complete = newTagCompleter(a)
+	f.input.SetValue(strings.Join(f.tags, ", "))
+	f.input.CursorEnd()
+	return f.input.Focus()
+}
+
+// update edits the filter. It reports whether the tags changed, so the
+// screen knows to refilter.
+func (f *tagFilter) update(key tea.KeyMsg) (tea.Cmd, bool) {
+	switch key.String() {
+	case "esc":
+		f.editing = false
+		f.input.Blur()
+		return nil, false
+	case "enter":
+		f.editing = false
+		f.input.Blur()
+		f.tags = models.NormalizeTags(splitList(f.input.Value()))
+		return nil, true
+	}
+	if f.complete.update(&f.input, key) {
+		return nil, false
+	}
+	var cmd tea.Cmd
+	f.input, cmd = f.input.Update(key)
+	return cmd, false
+}
+
+// help describes the keys of the filter while it is being edited.
+func (f tagFilter) help() []key.Binding {
+	return append([]key.Binding{fixed("enter", "apply the filter, empty clears it"), fixed("esc", "cancel")},
+		completionHelp()...)
+}
+
+// completionHelp describes the keys of a tagCompleter or autocomplete.
+func completionHelp() []key.Binding {
+	return []key.Binding{
+		fixed("ctrl+n/ctrl+p", "next or previous suggestion"),
+		fixed("right", "accept the suggestion"),
+	}
+}
+
+// match reports whether tags pass the filter.
+func (f tagFilter) match(tags []string) bool {
+	return models.HasTags(tags, f.tags)
+}
+
+func (f tagFilter) active() bool { return len(f.tags) > 0 }
+
+// view renders the filter input while editing, or the tags filtered by.
+func (f tagFilter) view() string {
+	if f.editing {
+		s := labelStyle.Render("Filter by tags") + "\n" + f.input.View() + "\n"
+		if c := f.complete.view(f.input.Value()); c != "" {
+			s += c + "\n"
+		}
+		return s + hintStyle.Render("enter apply (empty clears) • esc cancel") + "\n"
+	}
+	if f.active() {
+		return hintStyle.Render("Filtered by ") + highlightStyle.Render(formatTags(f.tags)) + "\n"
+	}
+	return ""
+}
+
+// tagBrowser lists every tag in use with how often it appears.
+type tagBrowser struct {
+	app    *app
+	tags   []storage.Tag
+	cursor int
+	err    error
+}
+
+func newTagBrowser(app *app) tagBrowser {
+	b := tagBrowser{app: app}
+	b.reload()
+	return b
+}
+
+func (b tagBrowser) Title() string { return tr("Tags") }
+
+func (b tagBrowser) Init() tea.Cmd {
+	return nil
+}
+
+func (b tagBrowser) help() []key.Binding {
+	return []key.Binding{
+		b.app.bind("up", "previous tag"),
+		b.app.bind("down", "next tag"),
+		b.app.bind("select", "show what carries the tag"),
+	}
+}
+
+func (b *tagBrowser) reload() {
+	b.tags, b.err = b.app.store.ListTags(b.app.ctx)
+	b.cursor = clamp(b.cursor, 0, len(b.tags)-1)
+}
+
+func (b tagBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tripSavedMsg, entrySavedMsg, expenseSavedMsg, expensesImportedMsg, lodgingsChangedMsg, historyMsg:
+		b.reload()
+	case tea.KeyMsg:
+		switch {
+		case b.app.is(msg, "up"):
+			if b.cursor > 0 {
+				b.cursor--
+			}
+		case b.app.is(msg, "down"):
+			if b.cursor < len(b.tags)-1 {
+				b.cursor++
+			}
+		case b.app.is(msg, "select"):
+			if len(b.tags) > 0 {
+				return b, push(newTaggedList(b.app, b.tags[b.cursor].Name))
+			}
+		}
+	}
+	return b, nil
+}
+
+func (b tagBrowser) View() string {
+	var s strings.Builder
+	s.WriteString(headerStyle.Render("🏷️  Tags") + "\n\n")
+	if b.err != nil {
+		s.WriteString(errorStyle.Render(b.err.Error()) + "\n")
+		return s.String()
+	}
+	if len(b.tags) == 0 {
+		s.WriteString("No tags yet — add some to a trip, journal entry or expense.\n")
+	}
+	for i, t := range b.tags {
+		cursor, name := "  ", fmt.Sprintf("%-20s", "#"+t.Name)
+		if i == b.cursor {
+			cursor, name = "👉", cursorStyle.Render(name)
+		}
+		var counts []string
+		if t.Trips > 0 {
+			counts = append(counts, plural(t.Trips, "trip", "trips"))
+		}
+		if t.Entries > 0 {
+			counts = append(counts, plural(t.Entries, "entry", "entries"))
+		}
+		if t.Expenses > 0 {
+			counts = append(counts, plural(t.Expenses, "expense", "expenses"))
+		}
+		fmt.Fprintf(&s, "%s %s %s\n", cursor, name, hintStyle.Render(strings.Join(counts, " • ")))
+	}
+	s.WriteString("\n" + hintStyle.Render("↑/↓ move • enter show tagged • esc back") + "\n")
+	return s.String()
+}
+
+// taggedItem is one row of a taggedList: a trip, entry or expense.
+type taggedItem struct {
+	trip    *models.Trip
+	entry   *models.Entry
+	expense *models.Expense
+}
+
+// taggedList shows the trips, entries and expenses carrying a tag.
+type taggedList struct {
+	app    *app
+	tag    string
+	items  []taggedItem
+	trips  map[string]*models.Trip // every trip, for expense details
+	cursor int
+	err    error
+}
+
+func newTaggedList(app *app, tag string) taggedList {
+	l := taggedList{app: app, tag: tag}
+	l.reload()
+	return l
+}
+
+func (l taggedList) Title() string { return "#" + l.tag }
+
+func (l taggedList) Init() tea.Cmd {
+	return nil
+}
+
+func (l taggedList) help() []key.Binding {
+	return []key.Binding{
+		l.app.bind("up", "previous item"),
+		l.app.bind("down", "next item"),
+		l.app.bind("select", "open the item"),
+	}
+}
+
+func (l *taggedList) reload() {
+	tagged, err := l.app.store.ListTagged(l.app.ctx, []string{l.tag})
+	if err != nil {
+		l.err = err
+		return
+	}
+	trips, err := l.app.store.ListTrips(l.app.ctx)
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.trips = make(map[string]*models.Trip, len(trips))
+	for _, t := range trips {
+		l.trips[t.ID] = t
+	}
+	l.items = l.items[:0]
+	for _, t := range tagged.Trips {
+		l.items = append(l.items, taggedItem{trip: t})
+	}
+	for _, e := range tagged.Entries {
+		l.items = append(l.items, taggedItem{entry: e})
+	}
+	for _, x := range tagged.Expenses {
+		l.items = append(l.items, taggedItem{expense: x})
+	}
+	l.cursor = clamp(l.cursor, 0, len(l.items)-1)
+}
+
+func (l taggedList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tripSavedMsg, entrySavedMsg, expenseSavedMsg, expensesImportedMsg, lodgingsChangedMsg, historyMsg:
+		l.reload()
+	case tea.KeyMsg:
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.items)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "select"):
+			if len(l.items) == 0 {
+				break
+			}
+			switch it := l.items[l.cursor]; {
+			case it.trip != nil:
+				return l, push(newTripDetail(l.app, it.trip))
+			case it.entry != nil:
+				return l, push(newEntryReader(l.app, it.entry))
+			case it.expense != nil:
+				if t, ok := l.trips[it.expense.TripID]; ok {
+					return l, push(newExpenseDetail(l.app, t, it.expense))
+				}
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l taggedList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🏷️  #"+l.tag) + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n")
+		return b.String()
+	}
+	if len(l.items) == 0 {
+		b.WriteString("Nothing carries this tag any more.\n")
+	}
+	for i, it := range l.items {
+		cursor := "  "
+		if i == l.cursor {
+			cursor = "👉"
+		}
+		var icon, date, text string
+		var tags []string
+		switch {
+		case it.trip != nil:
+			icon, date, text, tags = "🧳", l.app.formatDate(it.trip.StartDate), it.trip.Title, it.trip.Tags
+		case it.entry != nil:
+			icon, date, text, tags = "📔", l.app.formatDate(it.entry.Timestamp), it.entry.Title, it.entry.Tags
+		case it.expense != nil:
+			x := it.expense
+			icon, date, tags = "💰", l.app.formatDate(x.Timestamp), x.Tags
+			text = x.Description + "  " + formatAmount(x.Amount, x.Currency)
+		}
+		if i == l.cursor {
+			text = cursorStyle.Render(text)
+		}
+		fmt.Fprintf(&b, "%s %s %s  %s %s\n", cursor, icon, date, text, hintStyle.Render(formatTags(tags)))
+	}
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • enter open • esc back") + "\n")
+	return b.String()
+}
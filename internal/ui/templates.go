@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// templateList lists the trip templates and starts a new trip from the one
+// the user chooses.
+type templateList struct {
+	app       *app
+	templates []*models.Template
+	cursor    int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newTemplateList(app *app) templateList {
+	l := templateList{app: app}
+	l.reload()
+	return l
+}
+
+func (l templateList) Title() string { return tr("Templates") }
+
+func (l templateList) Init() tea.Cmd {
+	return nil
+}
+
+func (l templateList) capturesEsc() bool { return l.confirmDelete }
+
+func (l templateList) help() []key.Binding {
+	return append([]key.Binding{
+		l.app.bind("up", "previous template"),
+		l.app.bind("down", "next template"),
+		l.app.bind("select", "plan a trip from the template"),
+		l.app.bind("delete", "delete the template"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *templateList) reload() {
+	l.templates, l.err = l.app.store.ListTemplates(l.app.ctx)
+	l.cursor = clamp(l.cursor, 0, len(l.templates)-1)
+}
+
+func (l templateList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case templateSavedMsg:
+		l.reload()
+	case tripSavedMsg:
+		l.status = fmt.Sprintf("Created trip %q", msg.trip.Title)
+	case historyMsg:
+		l.status = ""
+		l.reload()
+	case tea.KeyMsg:
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				t := l.templates[l.cursor]
+				if err := l.app.run(deleteTemplate{template: t}); err != nil {
+					l.err = err
+					return l, nil
+				}
+				l.status = l.app.deletedHint(t.Name)
+				l.reload()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.templates)-1 {
+				l.cursor++
+			}
+		case l.app.edits(msg, "select"):
+			if len(l.templates) > 0 {
+				l.status = ""
+				return l, push(newTripFormFrom(l.app, l.templates[l.cursor]))
+			}
+		case l.app.is(msg, "delete"):
+			if len(l.templates) > 0 {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l templateList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("📋 Templates") + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n")
+		return b.String()
+	}
+	if len(l.templates) == 0 {
+		b.WriteString("No templates yet — open a trip and press " + l.app.keyHint("template") + " to save it as one.\n")
+	}
+	for i, t := range l.templates {
+		cursor, name := "  ", t.Name
+		if i == l.cursor {
+			cursor, name = "👉", cursorStyle.Render(name)
+		}
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, name, hintStyle.Render(strings.Join(t.Locations, ", ")))
+	}
+	if len(l.templates) > 0 {
+		b.WriteString("\n" + hintStyle.Render(templateSummary(l.app, l.templates[l.cursor])) + "\n")
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Delete template %q? y/n", l.templates[l.cursor].Name)) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • enter new trip • "+l.app.keyHint("delete")+" delete • "+l.app.keyHint("undo")+" undo • esc back") + "\n")
+	return b.String()
+}
+
+// templateSummary describes what a new trip gets from t.
+func templateSummary(a *app, t *models.Template) string {
+	var parts []string
+	if t.Days > 0 {
+		parts = append(parts, plural(t.Days, "day", "days"))
+	}
+	if t.Budget > 0 {
+		currency := t.BudgetCurrency
+		if currency == "" {
+			currency = a.cfg.HomeCurrency
+		}
+		parts = append(parts, "budget "+formatAmount(t.Budget, currency))
+	}
+	if n := len(t.CategoryBudgets); n > 0 {
+		parts = append(parts, plural(n, "category budget", "category budgets"))
+	}
+	if n := len(t.Legs); n > 0 {
+		parts = append(parts, plural(n, "leg", "legs"))
+	}
+	if n := len(t.Itinerary); n > 0 {
+		parts = append(parts, plural(n, "itinerary item", "itinerary items"))
+	}
+	if n := len(t.Packing); n > 0 {
+		parts = append(parts, plural(n, "thing to pack", "things to pack"))
+	}
+	if len(t.Tags) > 0 {
+		parts = append(parts, formatTags(t.Tags))
+	}
+	return strings.Join(parts, " • ")
+}
@@ -0,0 +1,450 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+const (
+	timelineDayWidth   = 3 // characters a day takes
+	timelineLabelWidth = 13
+	minTimelineDays    = 7
+)
+
+// timelineView draws a trip along a line of days: its legs as bars and
+// its itinerary items and check-ins as markers under them. Left and right
+// move a cursor from day to day, scrolling the line, and what is on the
+// cursor's day is listed below it.
+type timelineView struct {
+	app      *app
+	trip     *models.Trip
+	legs     []*models.Leg
+	items    []*models.ItineraryItem
+	checkIns []*models.CheckIn
+
+	// first is the first day drawn, a UTC midnight, and days how many
+	// there are: the trip's, widened to whatever falls outside it.
+	first  time.Time
+	days   int
+	cursor int
+	// offset is the first day in view.
+	offset int
+	width  int
+	err    error
+}
+
+func newTimelineView(app *app, trip *models.Trip) timelineView {
+	v := timelineView{app: app, trip: trip}
+	v.reload()
+	v.cursor = clamp(v.dayIndex(app.tripNow(trip)), 0, v.days-1)
+	return v
+}
+
+func (v *timelineView) reload() {
+	if v.legs, v.err = v.app.store.ListLegsByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	if v.items, v.err = v.app.store.ListItineraryByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	if v.checkIns, v.err = v.app.store.ListCheckInsByTrip(v.app.ctx, v.trip.ID); v.err != nil {
+		return
+	}
+	first := calendarDay(v.trip.StartDate)
+	last := first
+	if v.trip.EndDate != nil {
+		last = calendarDay(*v.trip.EndDate)
+	} else if today := calendarDay(v.app.tripNow(v.trip)); today.After(last) {
+		last = today
+	}
+	widen := func(t time.Time) {
+		day := calendarDay(t)
+		if day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+	for _, l := range v.legs {
+		widen(l.Arrival)
+		if l.Departure != nil {
+			widen(*l.Departure)
+		}
+	}
+	for _, it := range v.items {
+		widen(it.Day)
+	}
+	for _, c := range v.checkIns {
+		widen(checkInDay(c))
+	}
+	v.first, v.days = first, models.CalendarDays(first, last)
+	v.cursor = clamp(v.cursor, 0, v.days-1)
+}
+
+func (v timelineView) Title() string { return tr("Timeline") }
+
+func (v timelineView) currentTrip() *models.Trip { return v.trip }
+
+func (v timelineView) Init() tea.Cmd {
+	return nil
+}
+
+func (v timelineView) help() []key.Binding {
+	return []key.Binding{
+		v.app.bind("left", "previous day"),
+		v.app.bind("right", "next day"),
+		v.app.bind("page_up", "a screen of days back"),
+		v.app.bind("page_down", "a screen of days on"),
+		v.app.bind("first", "first day"),
+		v.app.bind("last", "last day"),
+		v.app.bind("today", "today"),
+		v.app.bind("quit", "close"),
+	}
+}
+
+func (v timelineView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width = msg.Width
+	case tripSavedMsg:
+		if msg.trip.ID == v.trip.ID {
+			v.trip = msg.trip
+		}
+		v.reload()
+	case legSavedMsg, itinerarySavedMsg, checkInSavedMsg, historyMsg:
+		v.reload()
+	case tea.KeyMsg:
+		switch {
+		case v.app.is(msg, "left"):
+			v.cursor--
+		case v.app.is(msg, "right"):
+			v.cursor++
+		case v.app.is(msg, "page_up"):
+			v.cursor -= v.visible()
+		case v.app.is(msg, "page_down"):
+			v.cursor += v.visible()
+		case v.app.is(msg, "first"):
+			v.cursor = 0
+		case v.app.is(msg, "last"):
+			v.cursor = v.days - 1
+		case v.app.is(msg, "today"):
+			v.cursor = v.dayIndex(v.app.tripNow(v.trip))
+		case v.app.is(msg, "quit"):
+			return v, pop
+		}
+		v.cursor = clamp(v.cursor, 0, v.days-1)
+	}
+	// Scroll just enough to keep the cursor in view.
+	n := v.visible()
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	} else if v.cursor >= v.offset+n {
+		v.offset = v.cursor - n + 1
+	}
+	v.offset = clamp(v.offset, 0, max(v.days-n, 0))
+	return v, nil
+}
+
+// visible is how many days fit across the screen.
+func (v timelineView) visible() int {
+	if v.width == 0 {
+		return minTimelineDays * 4
+	}
+	return max((v.width-timelineLabelWidth)/timelineDayWidth, minTimelineDays)
+}
+
+// dayIndex is how many days t's calendar day is after the first drawn.
+func (v timelineView) dayIndex(t time.Time) int {
+	return int(calendarDay(t).Sub(v.first).Hours() / 24)
+}
+
+// day is the calendar day of index i.
+func (v timelineView) day(i int) time.Time {
+	return v.first.AddDate(0, 0, i)
+}
+
+// legSpan is the first and last day index of the i-th leg, which runs
+// until the next leg is reached, or the trip ends, when it has no
+// departure.
+func (v timelineView) legSpan(i int) (from, to int) {
+	l := v.legs[i]
+	from, to = v.dayIndex(l.Arrival), v.days-1
+	if v.trip.EndDate != nil {
+		to = max(v.dayIndex(*v.trip.EndDate), from)
+	}
+	if l.Departure != nil {
+		to = v.dayIndex(*l.Departure)
+	} else if i+1 < len(v.legs) {
+		to = max(v.dayIndex(v.legs[i+1].Arrival), from)
+	}
+	return from, to
+}
+
+// lanes stacks the legs into as few rows as they need: a leg goes on the
+// first row free by its arrival, a travel day shared with the leg before
+// it counting as free.
+func (v timelineView) lanes() [][]int {
+	var lanes [][]int
+	var ends []int
+	for i := range v.legs {
+		from, to := v.legSpan(i)
+		lane := -1
+		for j, end := range ends {
+			if from >= end {
+				lane = j
+				break
+			}
+		}
+		if lane < 0 {
+			lanes, ends = append(lanes, nil), append(ends, 0)
+			lane = len(lanes) - 1
+		}
+		lanes[lane] = append(lanes[lane], i)
+		ends[lane] = to
+	}
+	return lanes
+}
+
+func (v timelineView) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🕰️  Timeline of "+v.trip.Title) + "\n\n")
+	if v.err != nil {
+		b.WriteString(errorStyle.Render(v.err.Error()) + "\n")
+		return b.String()
+	}
+	if v.days < 1 {
+		b.WriteString("The trip has no days to draw.\n")
+		return b.String()
+	}
+
+	from := v.offset
+	to := min(from+v.visible(), v.days)
+	label := func(s string) string {
+		return labelStyle.Render(s + strings.Repeat(" ", max(timelineLabelWidth-lipgloss.Width(s), 0)))
+	}
+	today := v.dayIndex(v.app.tripNow(v.trip))
+
+	// Months over the days they begin on, and over the first day in view
+	// when there is room before the next.
+	var starts []int
+	for i := from; i < to; i++ {
+		if i == from || v.day(i).Day() == 1 {
+			starts = append(starts, i)
+		}
+	}
+	months := []rune(strings.Repeat(" ", (to-from)*timelineDayWidth))
+	for n, i := range starts {
+		end := len(months)
+		if n+1 < len(starts) {
+			end = (starts[n+1]-from)*timelineDayWidth - 1
+		}
+		name := []rune(v.day(i).Format("Jan 2006"))
+		if len(name) > end-(i-from)*timelineDayWidth {
+			name = []rune(v.day(i).Format("Jan"))
+		}
+		if pos := (i - from) * timelineDayWidth; end-pos >= 3 {
+			copy(months[pos:end], name)
+		}
+	}
+	b.WriteString(label("") + hintStyle.Render(string(months)) + "\n")
+
+	var days strings.Builder
+	for i := from; i < to; i++ {
+		n := fmt.Sprintf("%2d ", v.day(i).Day())
+		switch {
+		case i == v.cursor:
+			days.WriteString(cursorStyle.Render(n))
+		case i == today:
+			days.WriteString(highlightStyle.Render(n))
+		case v.outside(i):
+			days.WriteString(hintStyle.Render(n))
+		default:
+			days.WriteString(n)
+		}
+	}
+	b.WriteString(label("") + days.String() + "\n")
+
+	lanes := v.lanes()
+	if len(lanes) == 0 {
+		b.WriteString(label("🧭 Legs") + hintStyle.Render("none planned") + "\n")
+	}
+	for n, lane := range lanes {
+		name := ""
+		if n == 0 {
+			name = "🧭 Legs"
+		}
+		b.WriteString(label(name) + v.drawLane(lane, from, to) + "\n")
+	}
+
+	plans := make([]int, v.days)
+	for _, it := range v.items {
+		plans[clamp(v.dayIndex(it.Day), 0, v.days-1)]++
+	}
+	b.WriteString(label("📅 Plans") + drawMarkers(plans, from, to, "◆", highlightStyle) + "\n")
+	visits := make([]int, v.days)
+	for _, c := range v.checkIns {
+		visits[clamp(v.dayIndex(checkInDay(c)), 0, v.days-1)]++
+	}
+	b.WriteString(label("📍 Check-ins") + drawMarkers(visits, from, to, "●", successStyle) + "\n")
+
+	var pointer strings.Builder
+	for i := from; i < to; i++ {
+		if i == v.cursor {
+			pointer.WriteString(" ▲ ")
+		} else {
+			pointer.WriteString(strings.Repeat(" ", timelineDayWidth))
+		}
+	}
+	b.WriteString(label("") + cursorStyle.Render(pointer.String()) + "\n")
+	if from > 0 || to < v.days {
+		b.WriteString(hintStyle.Render(fmt.Sprintf("Days %d–%d of %d", from+1, to, v.days)) + "\n")
+	}
+
+	b.WriteString("\n" + v.dayView(v.cursor))
+	b.WriteString("\n" + hintStyle.Render(v.app.keyHint("left")+"/"+v.app.keyHint("right")+" day • "+
+		v.app.keyHint("page_up")+"/"+v.app.keyHint("page_down")+" scroll • "+v.app.keyHint("today")+" today • esc back") + "\n")
+	return b.String()
+}
+
+// outside reports whether day index i falls outside the trip's dates.
+func (v timelineView) outside(i int) bool {
+	d := v.day(i)
+	if d.Before(calendarDay(v.trip.StartDate)) {
+		return true
+	}
+	return v.trip.EndDate != nil && d.After(calendarDay(*v.trip.EndDate))
+}
+
+// drawLane draws the legs of a lane from day index from to to as bars
+// named after their locations, the later leg taking a travel day they
+// share.
+func (v timelineView) drawLane(lane []int, from, to int) string {
+	owner := make([]int, to-from)
+	for i := range owner {
+		owner[i] = -1
+	}
+	for _, l := range lane {
+		a, z := v.legSpan(l)
+		for i := max(a, from); i <= min(z, to-1); i++ {
+			owner[i-from] = l
+		}
+	}
+	styles := []lipgloss.Style{cursorStyle, successStyle, highlightStyle, warningStyle}
+	var b strings.Builder
+	for i := 0; i < len(owner); {
+		l := owner[i]
+		j := i
+		for j < len(owner) && owner[j] == l {
+			j++
+		}
+		width := (j - i) * timelineDayWidth
+		if l < 0 {
+			b.WriteString(strings.Repeat(" ", width))
+			i = j
+			continue
+		}
+		// A bar leaves a gap before the next one.
+		bar := width - 1
+		name := []rune(v.legs[l].Location)
+		text := string(name[:min(1, len(name))])
+		if bar > 2 {
+			text = truncate(string(name), bar-1)
+		}
+		fill := max(bar-lipgloss.Width(text), 0)
+		b.WriteString(styles[l%len(styles)].Render(text+strings.Repeat("━", fill)) + " ")
+		i = j
+	}
+	return b.String()
+}
+
+// drawMarkers marks the days with something on them from day index from
+// to to, with how many when there is more than one.
+func drawMarkers(counts []int, from, to int, mark string, style lipgloss.Style) string {
+	var b strings.Builder
+	for i := from; i < to; i++ {
+		switch n := counts[i]; {
+		case n == 0:
+			b.WriteString(hintStyle.Render(" · "))
+		case n == 1:
+			b.WriteString(style.Render(mark) + "  ")
+		case n < 10:
+			b.WriteString(style.Render(fmt.Sprintf("%s%d", mark, n)) + " ")
+		default:
+			b.WriteString(style.Render(mark+"+") + " ")
+		}
+	}
+	return b.String()
+}
+
+// dayView lists what is on day index i: where the trip is, what is
+// planned and the places checked in at.
+func (v timelineView) dayView(i int) string {
+	var b strings.Builder
+	day := v.day(i)
+	head := v.app.formatDate(day) + " " + day.Weekday().String()
+	if !v.outside(i) {
+		head += fmt.Sprintf(" • day %d of the trip", models.CalendarDays(v.trip.StartDate, day))
+	}
+	b.WriteString(labelStyle.Render(head) + "\n")
+	empty := true
+	for l := range v.legs {
+		if a, z := v.legSpan(l); a <= i && i <= z {
+			leg := v.legs[l]
+			transport := ""
+			if leg.Transport != "" {
+				transport = " " + transportIcon(leg.Transport) + " " + leg.Transport
+			}
+			fmt.Fprintf(&b, "   🧭 %s %s\n", leg.Location, hintStyle.Render(legDates(v.app, leg)+transport))
+			empty = false
+		}
+	}
+	items := make([]*models.ItineraryItem, 0)
+	for _, it := range v.items {
+		if calendarDay(it.Day).Equal(day) {
+			items = append(items, it)
+		}
+	}
+	sort.SliceStable(items, func(a, b int) bool { return items[a].Time < items[b].Time })
+	for _, it := range items {
+		when := it.Time
+		if when == "" {
+			when = "     "
+		}
+		place := ""
+		if it.Place != "" {
+			place = hintStyle.Render(" 📍 " + it.Place)
+		}
+		fmt.Fprintf(&b, "   📅 %s %s%s\n", when, it.Title, place)
+		empty = false
+	}
+	for _, c := range v.checkIns {
+		if checkInDay(c).Equal(day) {
+			at := c.Timestamp.In(models.Zone(c.TimeZone)).Format(models.TimeLayout)
+			fmt.Fprintf(&b, "   📍 %s %s\n", at, c.Place)
+			empty = false
+		}
+	}
+	if empty {
+		b.WriteString(hintStyle.Render("   Nothing on this day.") + "\n")
+	}
+	return b.String()
+}
+
+// calendarDay is the calendar day of t as a comparable UTC midnight.
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// checkInDay is the calendar day of check-in c where it was made.
+func checkInDay(c *models.CheckIn) time.Time {
+	return calendarDay(c.Timestamp.In(models.Zone(c.TimeZone)))
+}
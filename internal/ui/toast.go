@@ -0,0 +1,136 @@
+package ui
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// toastLevel is what a toast reports, which sets its colour and how long
+// it stays on screen.
+type toastLevel int
+
+const (
+	toastSuccess toastLevel = iota
+	toastWarning
+	toastError
+)
+
+// toastDurations are how long a toast of each level stays, failures
+// longest so there is time to read them.
+var toastDurations = map[toastLevel]time.Duration{
+	toastSuccess: 4 * time.Second,
+	toastWarning: 6 * time.Second,
+	toastError:   8 * time.Second,
+}
+
+// maxToasts bounds the toasts waiting their turn; past it the oldest
+// waiting is dropped.
+const maxToasts = 5
+
+// toastMsg shows a toast in the footer: the confirmation of a change, or a
+// failure that would otherwise go unnoticed, such as of a background
+// lookup. Screens and background commands alike send one.
+type toastMsg struct {
+	level toastLevel
+	text  string
+}
+
+// toast returns a command showing a toast of level.
+func toast(level toastLevel, format string, args ...any) tea.Cmd {
+	return notify(toastMsg{level: level, text: fmt.Sprintf(format, args...)})
+}
+
+// toastErr returns a command showing err as an error toast, or nil when
+// err is nil. A failure of the store itself opens the error screen
+// instead, as what comes next is likely to fail too.
+func toastErr(err error) tea.Cmd {
+	if err == nil {
+		return nil
+	}
+	if storage.Unavailable(err) {
+		return fail("", err, nil)
+	}
+	return notify(toastMsg{level: toastError, text: err.Error()})
+}
+
+// toastExpiredMsg hides the toast numbered seq.
+type toastExpiredMsg struct {
+	seq int
+}
+
+// toasts is the queue of toasts: the first is on screen until it expires,
+// then the next takes its place. seq numbers the toasts shown so only the
+// latest expiry hides one.
+type toasts struct {
+	queue []toastMsg
+	seq   int
+}
+
+// add queues msg, showing it at once when nothing else is, and returns
+// the command expiring it then. A toast saying the same as one already
+// queued is dropped, as when a background lookup saves an entry again.
+func (t *toasts) add(msg toastMsg) tea.Cmd {
+	switch msg.level {
+	case toastError:
+		slog.Warn("ui: reported", "err", msg.text)
+	case toastWarning:
+		slog.Info("ui: warned", "text", msg.text)
+	}
+	for _, q := range t.queue {
+		if q == msg {
+			return nil
+		}
+	}
+	if len(t.queue) > maxToasts {
+		t.queue = append(t.queue[:1], t.queue[2:]...)
+	}
+	t.queue = append(t.queue, msg)
+	if len(t.queue) > 1 {
+		return nil
+	}
+	return t.show()
+}
+
+// expire hides the toast on screen if it is the one numbered seq, and
+// shows the next.
+func (t *toasts) expire(seq int) tea.Cmd {
+	if seq != t.seq || len(t.queue) == 0 {
+		return nil
+	}
+	t.queue = t.queue[1:]
+	if len(t.queue) == 0 {
+		return nil
+	}
+	return t.show()
+}
+
+// show starts the timeout of the toast now first in the queue.
+func (t *toasts) show() tea.Cmd {
+	t.seq++
+	seq := t.seq
+	return tea.Tick(toastDurations[t.queue[0].level], func(time.Time) tea.Msg { return toastExpiredMsg{seq: seq} })
+}
+
+// view renders the toast on screen, with how many more wait, or "".
+func (t toasts) view() string {
+	if len(t.queue) == 0 {
+		return ""
+	}
+	msg := t.queue[0]
+	text := msg.text
+	if waiting := len(t.queue) - 1; waiting > 0 {
+		text += fmt.Sprintf(" (+%d)", waiting)
+	}
+	switch msg.level {
+	case toastError:
+		return errorStyle.Render("⚠ " + text)
+	case toastWarning:
+		return warningStyle.Render(text)
+	}
+	return successStyle.Render(text)
+}
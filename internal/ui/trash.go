@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// trashList shows the trips, journal entries and expenses that were
+// deleted, to restore them or purge them for good.
+type trashList struct {
+	app    *app
+	trash  []*models.Trashed
+	trips  map[string]string
+	cursor int
+
+	// confirm is "purge" or "empty" while asking before purging.
+	confirm string
+	status  string
+	err     error
+}
+
+func newTrashList(app *app) trashList {
+	l := trashList{app: app}
+	l.reload()
+	return l
+}
+
+func (l trashList) Title() string { return tr("Trash") }
+
+func (l trashList) Init() tea.Cmd {
+	return nil
+}
+
+func (l trashList) capturesEsc() bool { return l.confirm != "" }
+
+func (l trashList) help() []key.Binding {
+	return []key.Binding{
+		l.app.bind("up", "previous item"),
+		l.app.bind("down", "next item"),
+		l.app.bind("restore", "restore the item"),
+		l.app.bind("delete", "purge the item for good"),
+		l.app.bind("empty", "empty the trash"),
+	}
+}
+
+func (l *trashList) reload() {
+	l.trash, l.err = l.app.store.ListTrash(l.app.ctx)
+	if l.err != nil {
+		return
+	}
+	trips, err := l.app.store.ListTrips(l.app.ctx)
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.trips = make(map[string]string, len(trips))
+	for _, t := range trips {
+		l.trips[t.ID] = t.Title
+	}
+	l.cursor = clamp(l.cursor, 0, len(l.trash)-1)
+}
+
+func (l trashList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg.(type) {
+	case historyMsg:
+		l.status = ""
+		l.reload()
+		return l, nil
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return l, nil
+	}
+	if l.confirm != "" {
+		confirm := l.confirm
+		l.confirm = ""
+		if key.String() == "y" {
+			l.purge(confirm == "empty")
+		}
+		return l, nil
+	}
+	switch {
+	case l.app.is(key, "up"):
+		if l.cursor > 0 {
+			l.cursor--
+		}
+	case l.app.is(key, "down"):
+		if l.cursor < len(l.trash)-1 {
+			l.cursor++
+		}
+	case l.app.is(key, "restore"), l.app.is(key, "select"):
+		if len(l.trash) == 0 {
+			return l, nil
+		}
+		t := l.trash[l.cursor]
+		if err := l.app.store.RestoreTrashed(l.app.ctx, t.ID); err != nil {
+			l.err = err
+			return l, nil
+		}
+		l.err, l.status = nil, fmt.Sprintf("Restored %s %q", t.Kind, t.Title)
+		l.reload()
+	case l.app.is(key, "delete"):
+		if len(l.trash) > 0 {
+			l.confirm = "purge"
+		}
+	case l.app.is(key, "empty"):
+		if len(l.trash) > 0 {
+			l.confirm = "empty"
+		}
+	}
+	return l, nil
+}
+
+// purge deletes the selected item for good, or with all set everything in
+// the trash.
+func (l *trashList) purge(all bool) {
+	purge := l.trash[l.cursor : l.cursor+1]
+	if all {
+		purge = l.trash
+	}
+	for _, t := range purge {
+		if err := l.app.store.PurgeTrashed(l.app.ctx, t.ID); err != nil {
+			l.err = err
+			l.reload()
+			return
+		}
+	}
+	l.err, l.status = nil, fmt.Sprintf("Purged %q for good", purge[0].Title)
+	if all {
+		l.status = "Emptied the trash"
+	}
+	l.reload()
+}
+
+func (l trashList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🗑️  Trash") + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n\n")
+	}
+	if len(l.trash) == 0 {
+		b.WriteString("The trash is empty.\n")
+	} else {
+		b.WriteString("Deleted trips, entries and expenses wait here until restored or purged.\n\n")
+	}
+	for i, t := range l.trash {
+		cursor, title := "  ", t.Title
+		if i == l.cursor {
+			cursor, title = "👉", cursorStyle.Render(title)
+		}
+		hint := t.Kind
+		if trip, ok := l.trips[t.TripID]; ok && t.Kind != models.TrashTrip {
+			hint += " in " + trip
+		} else if t.Kind != models.TrashTrip {
+			hint += " of a deleted trip"
+		}
+		hint += " · deleted " + l.app.formatDate(t.DeletedAt) + " " + t.DeletedAt.Format("15:04")
+		fmt.Fprintf(&b, "%s %s %s\n", cursor, title, hintStyle.Render(hint))
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	switch l.confirm {
+	case "purge":
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Purge %q for good? It cannot be undone. y/n", l.trash[l.cursor].Title)) + "\n")
+	case "empty":
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Purge %s for good? It cannot be undone. y/n",
+			plural(len(l.trash), "item", "items"))) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • "+l.app.keyHint("restore")+" restore • "+l.app.keyHint("delete")+" purge • "+
+		l.app.keyHint("empty")+" empty trash • esc back") + "\n")
+	return b.String()
+}
@@ -0,0 +1,411 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/budget"
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/pkg/currency"
+)
+
+const (
+	tripFieldName = iota
+	tripFieldDestinations
+	tripFieldStart
+	tripFieldEnd
+	tripFieldBudget
+	tripFieldNotes
+	tripFieldCompanions
+	tripFieldTags
+	// tripFieldCustom is the first of one field per trip field set up in
+	// the config.
+	tripFieldCustom
+)
+
+// tripForm is the "New Trip" screen.
+type tripForm struct {
+	form
+	app *app
+	// template, when set, is the template the trip is created from.
+	template *models.Template
+	// wish, when set, is the wish the trip is planned from.
+	wish *models.Wish
+	// draftID names the form's draft, as the trip has no ID yet.
+	draftID string
+	// forecast is the budget suggested from past trips like this one,
+	// worked out on reaching the budget; nil when there is none.
+	forecast *budget.Forecast
+	rates    *currency.Rates
+	// custom are the trip fields set up in the config, asked for last.
+	custom []config.TripField
+}
+
+const budgetHint = "Optional. Total amount you plan to spend."
+
+func newTripForm(app *app) tripForm {
+	t := tripForm{app: app, draftID: models.NewID(), custom: app.cfg.TripFieldList()}
+	fields := []field{
+		newField("Trip name", "Cherry blossoms in Japan", "", required("trip name")),
+		newField("Destinations", "Tokyo, Kyoto, Osaka", "Separate multiple destinations with commas.", validateDestinations),
+		newField("Start date", app.cfg.DateFormat, "", app.validateDate),
+		newField("End date", app.cfg.DateFormat, "Optional.", app.validateOptionalDate),
+		newField("Budget", "1500", budgetHint, validateAmount),
+		newField("Notes", "", "Optional.", nil),
+		newField("Companions", "Ana, Ben", "Optional. People traveling along who share expenses.", validateCompanions),
+		newTagsField(app, nil),
+	}
+	for _, tf := range t.custom {
+		fields = append(fields, newField(tf.Label, "", "Optional.", nil))
+	}
+	t.form = newForm("✈️  New Trip", fields...)
+	t.fields[tripFieldDestinations].places = newPlaceCompleter(true)
+	t.fields[tripFieldStart].dates = newDatePicker(app)
+	t.fields[tripFieldEnd].dates = newDateRangePicker(app, tripFieldStart, "end date must not be before the start date")
+	t.fields[tripFieldCompanions].tags = newPeopleCompleter(app)
+	return t
+}
+
+// newTripFormFrom is the "New Trip" screen filled in from a template. The
+// end date may be left empty to keep the template's length.
+func newTripFormFrom(app *app, tpl *models.Template) tripForm {
+	t := newTripForm(app)
+	t.template = tpl
+	t.form.title = "✈️  New Trip from " + tpl.Name
+	t.fields[tripFieldDestinations].input.SetValue(strings.Join(tpl.Locations, ", "))
+	if tpl.Days > 0 {
+		t.fields[tripFieldEnd].hint = fmt.Sprintf("Optional. Leave empty for a %d-day trip.", tpl.Days)
+	}
+	if tpl.Budget > 0 {
+		t.fields[tripFieldBudget].input.SetValue(strconv.FormatFloat(tpl.Budget, 'f', -1, 64))
+	}
+	t.fields[tripFieldNotes].input.SetValue(tpl.Notes)
+	t.fields[tripFieldTags].input.SetValue(strings.Join(tpl.Tags, ", "))
+	return t
+}
+
+// newTripFormFromWish is the "New Trip" screen planning a trip to the
+// place of a wish, filled in with its notes and budget.
+func newTripFormFromWish(app *app, w *models.Wish) tripForm {
+	t := newTripForm(app)
+	// Work on a copy so cancelling leaves the caller's wish untouched.
+	wish := *w
+	t.wish = &wish
+	t.form.title = "✈️  New Trip to " + w.Place
+	t.fields[tripFieldName].input.SetValue(w.Place)
+	t.fields[tripFieldDestinations].input.SetValue(w.Place)
+	if len(w.Season) > 0 {
+		t.fields[tripFieldStart].hint = "Best in " + models.FormatSeason(w.Season) + "."
+	}
+	if w.Budget > 0 {
+		t.fields[tripFieldBudget].input.SetValue(strconv.FormatFloat(w.Budget, 'f', -1, 64))
+	}
+	t.fields[tripFieldNotes].input.SetValue(w.Notes)
+	return t
+}
+
+func (t tripForm) Title() string { return tr("New Trip") }
+
+func (t tripForm) Init() tea.Cmd {
+	return t.app.fetchRates()
+}
+
+func (t tripForm) draft() *models.Draft {
+	if !t.dirty {
+		return nil
+	}
+	return newDraft(models.DraftTrip, t.draftID, t.Title(), t.value(tripFieldName), true, t.template, t.values())
+}
+
+func (t tripForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(ratesMsg); ok {
+		if msg.err == nil {
+			t.rates = msg.rates
+		}
+		return t, nil
+	}
+	// Like a completion, tab on an empty budget takes the suggested one.
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "tab" && t.step == tripFieldBudget && t.value(tripFieldBudget) == "" && t.forecast != nil {
+		t.fields[tripFieldBudget].input.SetValue(suggestedAmount(t.forecast.Total.Expected))
+		t.fields[tripFieldBudget].input.CursorEnd()
+		t.dirty = true
+		return t, nil
+	}
+
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	step := t.step
+	t.form, cmd, result = t.form.update(msg)
+	if t.step == tripFieldBudget && step != tripFieldBudget {
+		t.suggest()
+	}
+	switch result {
+	case formCancelled:
+		return t, pop
+	case formConfirmed:
+		plan, err := t.plan()
+		switch {
+		case err != nil:
+		case t.wish != nil:
+			err = t.app.store.PromoteWish(t.app.ctx, t.wish, plan)
+		default:
+			err = t.app.store.SaveTripPlan(t.app.ctx, plan)
+		}
+		if err != nil {
+			t.err = err
+			return t, nil
+		}
+		return t, tea.Sequence(pop, func() tea.Msg { return tripSavedMsg{trip: plan.Trip} })
+	}
+	return t, cmd
+}
+
+func (t tripForm) View() string {
+	return t.view(t.summary)
+}
+
+// plan assembles a models.Trip from the validated field values, together
+// with the itinerary and packing list of the template it is created from,
+// if any.
+func (t tripForm) plan() (*models.TripPlan, error) {
+	start, err := t.app.parseDate(t.value(tripFieldStart))
+	if err != nil {
+		return nil, err
+	}
+	plan := &models.TripPlan{Trip: models.NewTrip(t.value(tripFieldName), nil, start)}
+	if t.template != nil {
+		plan = t.template.NewTrip(plan.Trip.Title, start)
+	}
+	trip := plan.Trip
+	trip.Locations = splitList(t.value(tripFieldDestinations))
+	if v := t.value(tripFieldEnd); v != "" {
+		end, err := t.app.parseDate(v)
+		if err != nil {
+			return nil, err
+		}
+		trip.EndDate = &end
+	}
+	trip.Budget = 0
+	if v := t.value(tripFieldBudget); v != "" {
+		if trip.Budget, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, err
+		}
+	}
+	if t.wish != nil && trip.Budget > 0 {
+		trip.BudgetCurrency = t.wish.Currency
+	}
+	trip.Notes = t.value(tripFieldNotes)
+	if trip.Companions, err = models.ParseCompanions(splitList(t.value(tripFieldCompanions))); err != nil {
+		return nil, err
+	}
+	trip.Tags = splitList(t.value(tripFieldTags))
+	for i, tf := range t.custom {
+		trip.SetField(tf.Key, t.value(tripFieldCustom+i))
+	}
+	return plan, nil
+}
+
+func (t tripForm) summary() string {
+	var b strings.Builder
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-13s", label+":")), value)
+	}
+	row("Name", t.value(tripFieldName))
+	row("Destinations", strings.Join(splitList(t.value(tripFieldDestinations)), ", "))
+	for _, l := range splitList(t.value(tripFieldDestinations)) {
+		if d := placeDetails(l); d != "" {
+			b.WriteString(strings.Repeat(" ", 14) + hintStyle.Render(l+": "+d) + "\n")
+		}
+	}
+	row("Start", t.value(tripFieldStart))
+	end := t.value(tripFieldEnd)
+	if start, err := t.app.parseDate(t.value(tripFieldStart)); err == nil && end == "" && t.template != nil {
+		if d, ok := t.template.EndDate(start); ok {
+			end = t.app.formatDate(d)
+		}
+	}
+	row("End", end)
+	budget := t.value(tripFieldBudget)
+	if budget != "" && t.wish != nil && t.wish.Currency != "" {
+		budget += " " + t.wish.Currency
+	}
+	row("Budget", budget)
+	if t.forecast != nil {
+		row("Suggested", forecastView(t.forecast))
+	}
+	row("Notes", t.value(tripFieldNotes))
+	row("Companions", strings.Join(splitList(t.value(tripFieldCompanions)), ", "))
+	row("Tags", formatTags(models.NormalizeTags(splitList(t.value(tripFieldTags)))))
+	for i, tf := range t.custom {
+		fieldRow(&b, tf.Label, t.value(tripFieldCustom+i))
+	}
+	if t.template != nil && len(t.template.Itinerary) > 0 {
+		row("Itinerary", plural(len(t.template.Itinerary), "item", "items")+" from "+t.template.Name)
+	}
+	if t.template != nil && len(t.template.Packing) > 0 {
+		row("Packing", plural(len(t.template.Packing), "item", "items")+" from "+t.template.Name)
+	}
+	b.WriteString(countryNotesView(t.app, splitList(t.value(tripFieldDestinations))))
+	return b.String()
+}
+
+// suggest works out the budget of a trip to the destinations over the
+// dates entered from the past trips like it, and offers it in the budget
+// field.
+func (t *tripForm) suggest() {
+	t.forecast = nil
+	in := &t.fields[tripFieldBudget]
+	in.input.Placeholder, in.hint = "1500", budgetHint
+	days := t.days()
+	if days == 0 {
+		return
+	}
+	trips, err := t.app.store.ListTrips(t.app.ctx)
+	if err != nil {
+		return
+	}
+	expenses, err := t.app.store.ListExpenses(t.app.ctx)
+	if err != nil {
+		return
+	}
+	var convert budget.Converter
+	if t.rates != nil {
+		convert = t.rates.Convert
+	}
+	f, ok := budget.Suggest(splitList(t.value(tripFieldDestinations)), days, trips, expenses, t.app.cfg.HomeCurrency, convert, time.Now())
+	if !ok {
+		return
+	}
+	t.forecast = &f
+	in.input.Placeholder = suggestedAmount(f.Total.Expected)
+	in.hint = fmt.Sprintf("Optional. Suggested %s (%s), %s; tab to use it.",
+		formatAmount(f.Total.Expected, f.Currency), estimateRange(f.Total, f.Currency), forecastSource(f))
+}
+
+// days is how many calendar days the trip entered lasts, or 0 before its
+// end is known.
+func (t tripForm) days() int {
+	start, err := t.app.parseDate(t.value(tripFieldStart))
+	if err != nil {
+		return 0
+	}
+	if v := t.value(tripFieldEnd); v != "" {
+		end, err := t.app.parseDate(v)
+		if err != nil {
+			return 0
+		}
+		return models.CalendarDays(start, end)
+	}
+	if t.template != nil {
+		if end, ok := t.template.EndDate(start); ok {
+			return models.CalendarDays(start, end)
+		}
+	}
+	return 0
+}
+
+// forecastView shows the suggested budget by category, per day, below the
+// whole.
+func forecastView(f *budget.Forecast) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s), %s\n", formatAmount(f.Total.Expected, f.Currency), estimateRange(f.Total, f.Currency), forecastSource(*f))
+	for _, c := range f.Categories {
+		day := c.PerDay(f.Days)
+		b.WriteString(strings.Repeat(" ", 14) + hintStyle.Render(fmt.Sprintf("%s: %s a day (%s)",
+			c.Category, formatAmount(day.Expected, f.Currency), estimateRange(day, f.Currency))) + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func estimateRange(e budget.Estimate, currency string) string {
+	return formatAmount(e.Low, currency) + "–" + formatAmount(e.High, currency)
+}
+
+func forecastSource(f budget.Forecast) string {
+	if f.Similar {
+		return "from " + plural(len(f.Trips), "similar trip", "similar trips")
+	}
+	return "from " + plural(len(f.Trips), "past trip", "past trips") + ", none to these places"
+}
+
+// suggestedAmount rounds a suggested budget to a figure worth typing.
+func suggestedAmount(amount float64) string {
+	step := 1.0
+	switch {
+	case amount >= 1000:
+		step = 50
+	case amount >= 100:
+		step = 10
+	}
+	return strconv.FormatFloat(math.Round(amount/step)*step, 'f', -1, 64)
+}
+
+// checkEnd validates the end date against the start date already entered.
+func required(name string) func(string) error {
+	return func(v string) error {
+		if v == "" {
+			return fmt.Errorf("%s is required", name)
+		}
+		return nil
+	}
+}
+
+func validateDestinations(v string) error {
+	if len(splitList(v)) == 0 {
+		return errors.New("at least one destination is required")
+	}
+	return nil
+}
+
+func validateCompanions(v string) error {
+	_, err := models.ParseCompanions(splitList(v))
+	return err
+}
+
+func validateAmount(v string) error {
+	if v == "" {
+		return nil
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return errors.New("enter a positive number, e.g. 1500 or 249.90")
+	}
+	return nil
+}
+
+// parseRating reads a mood or rating typed in, which may be left empty for
+// none.
+func parseRating(v string) (int, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > models.MaxRating {
+		return 0, fmt.Errorf("enter a number from 1 to %d, or leave it empty", models.MaxRating)
+	}
+	return n, nil
+}
+
+// splitList splits a comma separated value, dropping empty items.
+func splitList(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,224 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// tripBrowser is the Trips screen. On terminals at least wideWidth wide it
+// splits in two, the list of trips on the left and the detail of the trip
+// under the cursor on the right, and tab moves the focus between them.
+// Narrower terminals, and accessible mode, show the list alone and open
+// the detail as a screen of its own.
+type tripBrowser struct {
+	app  *app
+	list tripPicker
+	// detail is the trip under the list's cursor, nil when there are no
+	// trips.
+	detail *tripDetail
+	// onDetail is set while keys go to the detail rather than the list.
+	onDetail bool
+
+	width, height int
+}
+
+func newTripBrowser(app *app) tripBrowser {
+	b := tripBrowser{app: app}
+	b.list = newTripPicker(app, "Trips", func(t *models.Trip) screen { return newTripDetail(app, t) })
+	b.sync()
+	return b
+}
+
+func (b tripBrowser) Title() string { return b.list.Title() }
+
+func (b tripBrowser) resume() (models.SessionScreen, bool) {
+	s := models.SessionScreen{Kind: models.SessionTrips, Title: b.Title()}
+	if len(b.list.trips) > 0 {
+		s.ID = b.list.trips[b.list.cursor].ID
+	}
+	return s, true
+}
+
+func (b tripBrowser) Init() tea.Cmd {
+	return nil
+}
+
+// split reports whether the list and detail are side by side.
+func (b tripBrowser) split() bool {
+	return b.width >= wideWidth && !accessible
+}
+
+// focused reports whether keys go to the detail.
+func (b tripBrowser) focused() bool {
+	return b.split() && b.onDetail && b.detail != nil
+}
+
+func (b tripBrowser) currentTrip() *models.Trip {
+	if !b.split() || b.detail == nil {
+		return nil
+	}
+	return b.detail.trip
+}
+
+// capturesEsc is set while the detail has the focus, as esc takes it back
+// to the list.
+func (b tripBrowser) capturesEsc() bool {
+	return b.focused() || b.list.capturesEsc()
+}
+
+func (b tripBrowser) typing() bool {
+	if b.focused() {
+		return b.detail.typing()
+	}
+	return b.list.typing()
+}
+
+func (b tripBrowser) help() []key.Binding {
+	if !b.split() {
+		return b.list.help()
+	}
+	if b.focused() {
+		if b.detail.typing() {
+			return b.detail.help()
+		}
+		return append([]key.Binding{
+			fixed("tab", "back to the list of trips"),
+			b.app.bind("select", "open the trip full screen"),
+		}, b.detail.help()...)
+	}
+	help := b.list.help()
+	if b.list.typing() {
+		return help
+	}
+	return append([]key.Binding{fixed("tab", "move to the trip's detail")}, help...)
+}
+
+// sync shows the trip under the list's cursor in the detail, keeping the
+// detail as it is while that trip stays the same.
+func (b *tripBrowser) sync() {
+	if len(b.list.trips) == 0 {
+		b.detail, b.onDetail = nil, false
+		return
+	}
+	t := b.list.trips[b.list.cursor]
+	if b.detail == nil || b.detail.trip.ID != t.ID {
+		d := newTripDetail(b.app, t)
+		d.embedded = true
+		b.detail = &d
+	}
+}
+
+// paneWidths returns how wide the list's and the detail's panes are,
+// borders included, with a column between them.
+func (b tripBrowser) paneWidths() (list, detail int) {
+	list = b.width * 2 / 5
+	return list, b.width - list - 1
+}
+
+// paneHeight is how tall both panes are, borders included, leaving room
+// for the key hints under them.
+func (b tripBrowser) paneHeight() int {
+	return max(b.height-2, 5)
+}
+
+// resize tells the list and the detail how much room they have.
+func (b *tripBrowser) resize() tea.Cmd {
+	size := tea.WindowSizeMsg{Width: b.width, Height: b.height}
+	if b.split() {
+		list, _ := b.paneWidths()
+		size = tea.WindowSizeMsg{Width: list - 4, Height: b.paneHeight() - 2}
+	}
+	updated, cmd := b.list.Update(size)
+	b.list = updated.(tripPicker)
+	return cmd
+}
+
+func (b tripBrowser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		b.width, b.height = msg.Width, msg.Height
+		cmd := b.resize()
+		b.sync()
+		return b, cmd
+	case tea.KeyMsg:
+		if b.focused() {
+			return b.updateDetail(msg)
+		}
+		if b.split() && b.detail != nil && !b.list.typing() && !b.list.capturesEsc() &&
+			(msg.String() == "tab" || b.app.is(msg, "select")) {
+			b.onDetail = true
+			return b, nil
+		}
+		updated, cmd := b.list.Update(msg)
+		b.list = updated.(tripPicker)
+		if b.split() {
+			b.sync()
+		}
+		return b, cmd
+	}
+	// Whatever changed may be in the list and the detail both.
+	updated, cmd := b.list.Update(msg)
+	b.list = updated.(tripPicker)
+	cmds := []tea.Cmd{cmd}
+	if b.detail != nil {
+		updated, cmd := b.detail.Update(msg)
+		d := updated.(tripDetail)
+		b.detail = &d
+		cmds = append(cmds, cmd)
+	}
+	b.sync()
+	return b, tea.Batch(cmds...)
+}
+
+// updateDetail hands key to the detail, unless it moves the focus back to
+// the list or opens the trip full screen.
+func (b tripBrowser) updateDetail(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if !b.detail.capturesEsc() {
+		switch {
+		case key.String() == "tab" || b.app.is(key, "back"):
+			b.onDetail = false
+			return b, nil
+		case b.app.is(key, "select"):
+			return b, push(newTripDetail(b.app, b.detail.trip))
+		}
+	}
+	updated, cmd := b.detail.Update(key)
+	d := updated.(tripDetail)
+	b.detail = &d
+	return b, cmd
+}
+
+func (b tripBrowser) View() string {
+	if !b.split() || b.detail == nil {
+		return b.list.View()
+	}
+	list := b.list
+	list.embedded = true
+	listWidth, detailWidth := b.paneWidths()
+	height := b.paneHeight()
+	left := framed(list.View(), listWidth, height, !b.onDetail)
+	right := framed(b.detail.View(), detailWidth, height, b.onDetail)
+	hint := "tab details • " + list.hint()
+	if b.onDetail {
+		hint = b.detail.hint()
+		if !b.detail.typing() {
+			hint = "tab trips • enter full screen • " + strings.TrimSuffix(hint, "esc back") + "esc trips"
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, " ", right) + "\n\n" + hintStyle.Render(hint) + "\n"
+}
+
+// framed draws view in a pane width columns wide and height lines tall,
+// borders included, its border standing out when it has the focus.
+func framed(view string, width, height int, focus bool) string {
+	style := paneStyle
+	if focus {
+		style = focusStyle
+	}
+	return style.Width(width - 2).Height(height - 2).Render(fit(strings.TrimRight(glyphs(view), "\n"), width-4, height-2))
+}
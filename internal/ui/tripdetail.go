@@ -0,0 +1,858 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/chart"
+	"github.com/girdharshubham/nomadic/internal/gpx"
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/stats"
+)
+
+// tripDetail shows a trip's details, its legs, what is coming up on its
+// itinerary, its latest journal entries, the transport segments logged and
+// the GPS tracks recorded on it.
+type tripDetail struct {
+	app      *app
+	trip     *models.Trip
+	legs     []*models.Leg
+	segments []*models.Segment
+	tracks   []*models.Track
+	cursor   int
+
+	// entries names the journal entries tracks are linked to.
+	entries map[string]string
+	moods   stats.Trend
+	counts  string
+	// legCounts describes what is attributed to each leg, by leg ID.
+	legCounts map[string]string
+	packing   []*models.PackingItem
+	// highlights are the trip's goals and highlights.
+	highlights []*models.Highlight
+	// prep is the trip's checklist of things to do before it leaves.
+	prep []*models.PrepTask
+	// lodgings are the hotels and rentals booked for the trip.
+	lodgings []*models.Lodging
+	// health lines up the trip's days with the jetlag expected and what
+	// was logged of them.
+	health []stats.DayHealth
+	// spent sums the trip's expenses per currency.
+	spent string
+	// upcoming holds the next itinerary items from today on, and recent
+	// the latest journal entries, newest first; a few of each.
+	upcoming []*models.ItineraryItem
+	recent   []*models.Entry
+
+	// importing is set while the path of a GPX file is being typed in.
+	importing bool
+	path      textinput.Model
+
+	// tagging is set while the trip's tags are being edited.
+	tagging  bool
+	tags     textinput.Model
+	complete *tagCompleter
+
+	// asking is set while the name of a template, the start date of a
+	// clone or the trip's rating is being typed in.
+	asking tripQuestion
+	answer textinput.Model
+
+	// embedded is set while a tripBrowser shows the detail beside the
+	// list of trips, and hints at the keys itself.
+	embedded bool
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+// tripQuestion is what tripDetail is asking for in its answer input.
+type tripQuestion int
+
+const (
+	askNothing tripQuestion = iota
+	askTemplateName
+	askCloneStart
+	askRating
+)
+
+func newTripDetail(app *app, trip *models.Trip) tripDetail {
+	in := newPathInput("")
+	in.Placeholder = "~/Downloads/activity.gpx"
+	in.Blur()
+	tags := textinput.New()
+	tags.Placeholder = "food, friends"
+	tags.Width = 40
+	answer := textinput.New()
+	answer.Width = 40
+	d := tripDetail{app: app, trip: trip, path: in, tags: tags, answer: answer}
+	d.reload()
+	return d
+}
+
+func (d tripDetail) Title() string { return d.trip.Title }
+
+func (d tripDetail) currentTrip() *models.Trip { return d.trip }
+
+func (d tripDetail) resume() (models.SessionScreen, bool) {
+	return models.SessionScreen{Kind: models.SessionTrip, Title: d.Title(), ID: d.trip.ID, Cursor: d.cursor}, true
+}
+
+func (d tripDetail) Init() tea.Cmd {
+	return nil
+}
+
+func (d tripDetail) capturesEsc() bool {
+	return d.confirmDelete || d.importing || d.tagging || d.asking != askNothing
+}
+
+func (d tripDetail) typing() bool {
+	return d.importing || d.tagging || d.asking != askNothing
+}
+
+func (d tripDetail) help() []key.Binding {
+	if d.typing() {
+		return []key.Binding{fixed("enter", "save"), fixed("esc", "cancel")}
+	}
+	return append([]key.Binding{
+		d.app.bind("up", "previous track"),
+		d.app.bind("down", "next track"),
+		d.app.bind("import", "import a GPX track"),
+		d.app.bind("delete", "delete the track"),
+		d.app.bind("tags", "edit the trip's tags"),
+		d.app.bind("edit", "edit the trip's own fields, such as a visa number"),
+		d.app.bind("legs", "plan the trip's legs"),
+		d.app.bind("timeline", "the trip's legs, plans and check-ins along a timeline"),
+		d.app.bind("packing", "packing list"),
+		d.app.bind("highlights", "goals and highlights"),
+		d.app.bind("prep", "things to do before the trip leaves"),
+		d.app.bind("lodging", "hotels and rentals"),
+		d.app.bind("health", "log today's sleep, water and jetlag"),
+		d.app.bind("attachments", "tickets, bookings and other documents"),
+		d.app.bind("template", "save the trip as a template"),
+		d.app.bind("clone", "copy the trip to new dates"),
+		d.app.bind("rate", "rate the trip"),
+		d.app.bind("public", "publish the trip with nomadic publish, or make it private"),
+		d.app.bind("yearly", "mark the trip recurring, keep its anniversaries, or neither"),
+	}, d.app.undoHelp()...)
+}
+
+func (d *tripDetail) reload() {
+	d.tracks, d.err = d.app.store.ListTracksByTrip(d.app.ctx, d.trip.ID)
+	d.cursor = clamp(d.cursor, 0, len(d.tracks)-1)
+	if d.err != nil {
+		return
+	}
+	entries, err := d.app.store.ListEntriesByTrip(d.app.ctx, d.trip.ID)
+	if err != nil {
+		d.err = err
+		return
+	}
+	d.entries = make(map[string]string, len(entries))
+	for _, e := range entries {
+		d.entries[e.ID] = e.Title
+	}
+	d.recent = d.recent[:0]
+	for i := len(entries) - 1; i >= 0 && len(d.recent) < tripDetailPeek; i-- {
+		d.recent = append(d.recent, entries[i])
+	}
+	d.moods = stats.MoodTrend(entries)
+	expenses, err := d.app.store.ListExpensesByTrip(d.app.ctx, d.trip.ID)
+	if err != nil {
+		d.err = err
+		return
+	}
+	d.spent = strings.Join(totalsByCurrency(expenses), " + ")
+	d.counts = plural(len(entries), "journal entry", "journal entries") + " • " +
+		plural(len(expenses), "expense", "expenses")
+	documents, err := d.app.store.ListDocumentsByTrip(d.app.ctx, d.trip.ID)
+	if err != nil {
+		d.err = err
+		return
+	}
+	if len(documents) > 0 {
+		d.counts += " • " + plural(len(documents), "document", "documents")
+	}
+	d.legCounts = legCounts(entries, expenses)
+	if d.legs, d.err = d.app.store.ListLegsByTrip(d.app.ctx, d.trip.ID); d.err != nil {
+		return
+	}
+	logged, err := d.app.store.ListHealthByTrip(d.app.ctx, d.trip.ID)
+	if err != nil {
+		d.err = err
+		return
+	}
+	curve := stats.JetlagCurve(d.trip, d.legs, models.Zone(d.app.cfg.HomeZone), time.Now())
+	d.health = stats.Health(curve, logged, d.moods)
+	if d.segments, d.err = d.app.store.ListSegmentsByTrip(d.app.ctx, d.trip.ID); d.err != nil {
+		return
+	}
+	items, err := d.app.store.ListItineraryByTrip(d.app.ctx, d.trip.ID)
+	if err != nil {
+		d.err = err
+		return
+	}
+	today := dateOf(d.app.tripNow(d.trip))
+	d.upcoming = d.upcoming[:0]
+	for _, it := range items {
+		if !it.Day.Before(today) && len(d.upcoming) < tripDetailPeek {
+			d.upcoming = append(d.upcoming, it)
+		}
+	}
+	if d.packing, d.err = d.app.store.ListPackingByTrip(d.app.ctx, d.trip.ID); d.err != nil {
+		return
+	}
+	if d.highlights, d.err = d.app.store.ListHighlightsByTrip(d.app.ctx, d.trip.ID); d.err != nil {
+		return
+	}
+	if d.prep, d.err = d.app.store.ListPrepTasksByTrip(d.app.ctx, d.trip.ID); d.err != nil {
+		return
+	}
+	d.lodgings, d.err = d.app.store.ListLodgingsByTrip(d.app.ctx, d.trip.ID)
+}
+
+// healthLines draws the jetlag felt and expected on each of days as two
+// sparklines lined up day by day, a dot for a day without one. Either is
+// empty when no day has one.
+func healthLines(days []stats.DayHealth) (felt, expected string) {
+	var f, e strings.Builder
+	var anyFelt, anyExpected bool
+	for _, d := range days {
+		if d.Logged != nil && d.Logged.Jetlag > 0 {
+			f.WriteString(chart.Sparkline([]float64{float64(d.Logged.Jetlag)}, 1, models.MaxRating))
+			anyFelt = true
+		} else {
+			f.WriteString("·")
+		}
+		if d.Expected > 0 {
+			e.WriteString(chart.Sparkline([]float64{d.Expected}, 1, models.MaxRating))
+			anyExpected = anyExpected || d.Shift != 0
+		} else {
+			e.WriteString("·")
+		}
+	}
+	if anyFelt {
+		felt = f.String()
+	}
+	if anyExpected {
+		expected = e.String()
+	}
+	return felt, expected
+}
+
+// shiftToday describes how far the body clock is behind or ahead of the
+// trip's clock today, when the trip is on.
+func (d tripDetail) shiftToday() string {
+	today := dateOf(d.app.tripNow(d.trip))
+	for _, h := range d.health {
+		if h.Day.Equal(time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)) && h.Expected > 0 {
+			if h.Shift == 0 {
+				return ", adjusted today"
+			}
+			return fmt.Sprintf(", %+gh today", h.Shift)
+		}
+	}
+	return ""
+}
+
+// tripDetailPeek is how many upcoming itinerary items and recent entries
+// the trip detail shows.
+const tripDetailPeek = 3
+
+func (d tripDetail) selected() *models.Track {
+	if len(d.tracks) == 0 {
+		return nil
+	}
+	return d.tracks[d.cursor]
+}
+
+func (d tripDetail) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tripSavedMsg:
+		if msg.trip.ID == d.trip.ID {
+			d.trip = msg.trip
+		}
+		return d, nil
+	case entrySavedMsg, expenseSavedMsg, expensesImportedMsg, packingChangedMsg, highlightsChangedMsg, prepChangedMsg, lodgingsChangedMsg, healthChangedMsg, legSavedMsg, documentsChangedMsg, historyMsg:
+		d.reload()
+		return d, nil
+	case tea.KeyMsg:
+		if d.importing {
+			return d.updateImporting(msg)
+		}
+		if d.tagging {
+			return d.updateTagging(msg)
+		}
+		if d.asking != askNothing {
+			return d.updateAsking(msg)
+		}
+		if d.confirmDelete {
+			d.confirmDelete = false
+			if msg.String() == "y" {
+				t := d.selected()
+				if err := d.app.run(deleteTrack{track: t}); err != nil {
+					d.err = err
+				} else {
+					d.status = d.app.deletedHint(t.Name)
+				}
+				d.reload()
+			}
+			return d, nil
+		}
+		if cmd, ok := d.app.undoKeys(msg); ok {
+			return d, cmd
+		}
+
+		switch {
+		case d.app.is(msg, "up"):
+			if d.cursor > 0 {
+				d.cursor--
+			}
+		case d.app.is(msg, "down"):
+			if d.cursor < len(d.tracks)-1 {
+				d.cursor++
+			}
+		case d.app.is(msg, "import"):
+			d.importing, d.status, d.err = true, "", nil
+			d.path.SetValue("")
+			return d, d.path.Focus()
+		case d.app.is(msg, "delete"):
+			if d.selected() != nil {
+				d.confirmDelete = true
+			}
+		case d.app.is(msg, "tags"):
+			d.tagging, d.status, d.err = true, "", nil
+			d.complete = newTagCompleter(d.app)
+			d.tags.SetValue(strings.Join(d.trip.Tags, ", "))
+			d.tags.CursorEnd()
+			return d, d.tags.Focus()
+		case d.app.is(msg, "edit"):
+			if len(d.app.cfg.TripFields) == 0 {
+				d.status = noTripFields
+				return d, nil
+			}
+			d.status = ""
+			return d, push(newTripFieldsForm(d.app, d.trip))
+		case d.app.is(msg, "legs"):
+			d.status = ""
+			return d, push(newLegList(d.app, d.trip))
+		case d.app.is(msg, "timeline"):
+			d.status = ""
+			return d, push(newTimelineView(d.app, d.trip))
+		case d.app.is(msg, "packing"):
+			d.status = ""
+			return d, push(newPackingView(d.app, d.trip))
+		case d.app.is(msg, "lodging"):
+			d.status = ""
+			return d, push(newLodgingList(d.app, d.trip))
+		case d.app.is(msg, "highlights"):
+			d.status = ""
+			return d, push(newHighlightsView(d.app, d.trip))
+		case d.app.is(msg, "prep"):
+			d.status = ""
+			return d, push(newPrepView(d.app, d.trip))
+		case d.app.is(msg, "health"):
+			d.status = ""
+			return d, push(newHealthForm(d.app, d.trip))
+		case d.app.is(msg, "attachments"):
+			d.status = ""
+			return d, push(newDocumentList(d.app, d.trip, nil))
+		case d.app.is(msg, "template"):
+			return d, d.ask(askTemplateName, d.trip.Title)
+		case d.app.is(msg, "clone"):
+			return d, d.ask(askCloneStart, "")
+		case d.app.is(msg, "rate"):
+			rating := ""
+			if d.trip.Rating > 0 {
+				rating = strconv.Itoa(d.trip.Rating)
+			}
+			return d, d.ask(askRating, rating)
+		case d.app.is(msg, "public"):
+			cmd, err := d.togglePublic()
+			d.err = err
+			return d, cmd
+		case d.app.is(msg, "yearly"):
+			cmd, err := d.cycleYearly()
+			d.err = err
+			return d, cmd
+		}
+	}
+	return d, nil
+}
+
+func (d *tripDetail) ask(q tripQuestion, value string) tea.Cmd {
+	d.asking, d.status, d.err = q, "", nil
+	d.answer.Placeholder = ""
+	switch q {
+	case askCloneStart:
+		d.answer.Placeholder = d.app.cfg.DateFormat
+	case askRating:
+		d.answer.Placeholder = fmt.Sprintf("1–%d, empty for none", models.MaxRating)
+	}
+	d.answer.SetValue(value)
+	d.answer.CursorEnd()
+	return d.answer.Focus()
+}
+
+// updateAsking edits the answer to the question being asked and acts on it
+// on Enter: saving the trip as a template or cloning it.
+func (d tripDetail) updateAsking(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		d.asking = askNothing
+		d.answer.Blur()
+		return d, nil
+	case "enter":
+		value := strings.TrimSpace(d.answer.Value())
+		var cmd tea.Cmd
+		var err error
+		switch d.asking {
+		case askTemplateName:
+			cmd, err = d.saveTemplate(value)
+		case askCloneStart:
+			cmd, err = d.clone(value)
+		case askRating:
+			cmd, err = d.rate(value)
+		}
+		if err != nil {
+			d.err = err
+			return d, nil
+		}
+		d.asking, d.err = askNothing, nil
+		d.answer.Blur()
+		return d, cmd
+	}
+	var cmd tea.Cmd
+	d.answer, cmd = d.answer.Update(key)
+	return d, cmd
+}
+
+// saveTemplate saves the trip as the template called name, replacing a
+// template of that name.
+func (d *tripDetail) saveTemplate(name string) (tea.Cmd, error) {
+	if name == "" {
+		return nil, errors.New("enter a name for the template")
+	}
+	items, err := d.app.store.ListItineraryByTrip(d.app.ctx, d.trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	packing, err := d.app.store.ListPackingByTrip(d.app.ctx, d.trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	legs, err := d.app.store.ListLegsByTrip(d.app.ctx, d.trip.ID)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := d.app.store.ListTemplates(d.app.ctx)
+	if err != nil {
+		return nil, err
+	}
+	tpl := models.NewTemplate(name, d.trip, items, packing, legs)
+	for _, t := range templates {
+		if strings.EqualFold(t.Name, name) {
+			tpl.ID, tpl.CreatedAt = t.ID, t.CreatedAt
+		}
+	}
+	if err := d.app.store.SaveTemplate(d.app.ctx, tpl); err != nil {
+		return nil, err
+	}
+	return tea.Batch(notify(templateSavedMsg{template: tpl}), toast(toastSuccess, "Saved template %q", tpl.Name)), nil
+}
+
+// clone copies the trip to new dates starting on the date in value.
+func (d *tripDetail) clone(value string) (tea.Cmd, error) {
+	start, err := d.app.parseDate(value)
+	if err != nil {
+		return nil, err
+	}
+	trip, err := d.app.store.CloneTrip(d.app.ctx, d.trip.ID, d.trip.Title+" (copy)", start)
+	if err != nil {
+		return nil, err
+	}
+	d.status = fmt.Sprintf("Created %q starting %s", trip.Title, d.app.formatDate(start))
+	return func() tea.Msg { return tripSavedMsg{trip: trip} }, nil
+}
+
+// rate sets the trip's rating to the one in value, or clears it when value
+// is empty.
+func (d *tripDetail) rate(value string) (tea.Cmd, error) {
+	rating, err := parseRating(value)
+	if err != nil {
+		return nil, err
+	}
+	trip := *d.trip
+	trip.Rating = rating
+	if err := d.app.store.SaveTrip(d.app.ctx, &trip); err != nil {
+		return nil, err
+	}
+	d.trip = &trip
+	return func() tea.Msg { return tripSavedMsg{trip: &trip} }, nil
+}
+
+// togglePublic marks the trip public for the website of nomadic publish,
+// or private again.
+func (d *tripDetail) togglePublic() (tea.Cmd, error) {
+	trip := *d.trip
+	trip.Public = !trip.Public
+	if err := d.app.store.SaveTrip(d.app.ctx, &trip); err != nil {
+		return nil, err
+	}
+	d.trip = &trip
+	d.status = fmt.Sprintf("%q is private; entries marked public are still published", trip.Title)
+	if trip.Public {
+		d.status = fmt.Sprintf("%q is public; run nomadic publish to update the website", trip.Title)
+	}
+	return func() tea.Msg { return tripSavedMsg{trip: &trip} }, nil
+}
+
+// cycleYearly marks the trip recurring, keeps its anniversaries instead, or
+// neither, in turn.
+func (d *tripDetail) cycleYearly() (tea.Cmd, error) {
+	trip := *d.trip
+	switch trip.Yearly {
+	case "":
+		trip.Yearly = models.YearlyRecurring
+	case models.YearlyRecurring:
+		trip.Yearly = models.YearlyAnniversary
+	default:
+		trip.Yearly = ""
+	}
+	if err := d.app.store.SaveTrip(d.app.ctx, &trip); err != nil {
+		return nil, err
+	}
+	d.trip = &trip
+	day := trip.StartDate.Format("January 2")
+	switch trip.Yearly {
+	case models.YearlyRecurring:
+		d.status = fmt.Sprintf("%q comes round every %s", trip.Title, day)
+	case models.YearlyAnniversary:
+		d.status = fmt.Sprintf("Keeping the anniversaries of %q every %s", trip.Title, day)
+	default:
+		d.status = fmt.Sprintf("%q no longer comes round yearly", trip.Title)
+	}
+	return func() tea.Msg { return tripSavedMsg{trip: &trip} }, nil
+}
+
+// updateTagging edits the trip's tags and saves them on Enter.
+func (d tripDetail) updateTagging(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		d.tagging = false
+		d.tags.Blur()
+		return d, nil
+	case "enter":
+		trip := *d.trip
+		trip.Tags = splitList(d.tags.Value())
+		if err := d.app.store.SaveTrip(d.app.ctx, &trip); err != nil {
+			d.err = err
+			return d, nil
+		}
+		d.tagging = false
+		d.tags.Blur()
+		d.trip = &trip
+		return d, func() tea.Msg { return tripSavedMsg{trip: &trip} }
+	}
+	if d.complete.update(&d.tags, key) {
+		return d, nil
+	}
+	var cmd tea.Cmd
+	d.tags, cmd = d.tags.Update(key)
+	return d, cmd
+}
+
+// updateImporting edits the path of a GPX file and imports it on Enter.
+func (d tripDetail) updateImporting(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		d.importing = false
+		d.path.Blur()
+		return d, nil
+	case "enter":
+		path := expandHome(strings.TrimSpace(d.path.Value()))
+		if path == "" {
+			d.err = errors.New("enter the path of a GPX file")
+			return d, nil
+		}
+		track, err := gpx.ReadTrack(path, d.trip.ID)
+		if err == nil {
+			err = d.app.store.SaveTrack(d.app.ctx, track)
+		}
+		if err != nil {
+			d.err = err
+			return d, nil
+		}
+		d.importing, d.err = false, nil
+		d.path.Blur()
+		d.status = fmt.Sprintf("Imported %q", track.Name)
+		d.reload()
+		for i, t := range d.tracks {
+			if t.ID == track.ID {
+				d.cursor = i
+			}
+		}
+		return d, nil
+	}
+	var cmd tea.Cmd
+	d.path, cmd = d.path.Update(key)
+	return d, cmd
+}
+
+func (d tripDetail) View() string {
+	t := d.trip
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🧳 "+t.Title) + "\n\n")
+	row := func(label, value string) {
+		if value == "" {
+			value = hintStyle.Render("—")
+		}
+		fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-13s", label+":")), value)
+	}
+	row("Destinations", strings.Join(t.Locations, ", "))
+	if t.Archived() {
+		row("Archived", d.app.formatDate(*t.ArchivedAt))
+	}
+	if countries := tripCountries(t); countries != "" {
+		row("Countries", countries)
+	}
+	row("Dates", tripDates(d.app, t))
+	budget := ""
+	if t.Budget > 0 {
+		budget = formatAmount(t.Budget, d.app.budgetCurrency(t))
+	}
+	row("Budget", budget)
+	if d.spent != "" {
+		row("Spent", d.spent)
+	}
+	row("Notes", t.Notes)
+	if d.asking == askRating {
+		b.WriteString(labelStyle.Render(fmt.Sprintf("%-13s", "Rating:")) + " " + d.answer.View() + "\n")
+	} else {
+		row("Rating", models.Stars(t.Rating))
+	}
+	if t.Public {
+		row("Public", "🌐 on the website of nomadic publish")
+	}
+	switch t.Yearly {
+	case models.YearlyRecurring:
+		row("Yearly", "🔁 comes round every "+t.StartDate.Format("January 2"))
+	case models.YearlyAnniversary:
+		row("Yearly", "🎉 anniversaries every "+t.StartDate.Format("January 2"))
+	}
+	if len(d.moods) > 0 {
+		avg := d.moods.Average()
+		row("Mood", cursorStyle.Render(d.moods.Sparkline())+" "+models.MoodFace(int(avg+0.5))+
+			hintStyle.Render(fmt.Sprintf(" %.1f on average over %s", avg, plural(len(d.moods), "day", "days"))))
+	}
+	if felt, expected := healthLines(d.health); felt != "" || expected != "" {
+		var parts []string
+		if felt != "" {
+			parts = append(parts, cursorStyle.Render(felt)+hintStyle.Render(" felt"))
+		}
+		if expected != "" {
+			parts = append(parts, expected+hintStyle.Render(" expected"+d.shiftToday()))
+		}
+		row("Jetlag", strings.Join(parts, "  "))
+	}
+	if sleep, water, _ := stats.Averages(d.health); sleep > 0 || water > 0 {
+		var parts []string
+		if sleep > 0 {
+			parts = append(parts, fmt.Sprintf("%.1f h a night", sleep))
+		}
+		if water > 0 {
+			parts = append(parts, fmt.Sprintf("%.1f l of water a day", water))
+		}
+		row("Sleep", strings.Join(parts, " • "))
+	}
+	if d.tagging {
+		b.WriteString(labelStyle.Render(fmt.Sprintf("%-13s", "Tags:")) + " " + d.tags.View() + "\n")
+		if s := d.complete.view(d.tags.Value()); s != "" {
+			b.WriteString(strings.Repeat(" ", 14) + s + "\n")
+		}
+	} else {
+		row("Tags", formatTags(t.Tags))
+	}
+	for _, tf := range d.app.cfg.TripFieldList() {
+		fieldRow(&b, tf.Label, t.Fields[tf.Key])
+	}
+	if len(d.packing) > 0 {
+		row("Packing", packingProgress(d.packing))
+	}
+	if len(d.highlights) > 0 {
+		row("Highlights", highlightProgress(d.highlights))
+	}
+	if len(d.prep) > 0 {
+		row("Preparation", prepSummary(d.trip, d.prep))
+	}
+	if len(d.lodgings) > 0 {
+		nights := 0
+		for _, l := range d.lodgings {
+			nights += l.Nights()
+		}
+		row("Lodging", plural(len(d.lodgings), "stay", "stays")+" • "+plural(nights, "night", "nights"))
+		for _, n := range models.LodgingNotices(d.lodgings, d.app.tripNow(t)) {
+			b.WriteString(strings.Repeat(" ", 14) + warningStyle.Render(lodgingNotice(n, true)) + "\n")
+		}
+	}
+	if d.counts != "" {
+		b.WriteString(hintStyle.Render(d.counts) + "\n")
+	}
+
+	if len(d.legs) > 0 {
+		b.WriteString("\n" + labelStyle.Render("🧭 Legs") + "\n")
+		for i, l := range d.legs {
+			transport := ""
+			if l.Transport != "" {
+				transport = transportIcon(l.Transport) + " " + l.Transport
+			}
+			fmt.Fprintf(&b, "   %d. %-20s %-25s %s\n", i+1, truncate(l.Location, 20), legDates(d.app, l), transport)
+			if c := d.legCounts[l.ID]; c != "" {
+				fmt.Fprintf(&b, "      %s\n", hintStyle.Render(c))
+			}
+		}
+	}
+
+	if len(d.upcoming) > 0 {
+		b.WriteString("\n" + labelStyle.Render("📅 Coming up") + "\n")
+		for _, it := range d.upcoming {
+			when := d.app.formatDate(it.Day)
+			if it.Time != "" {
+				when += " " + it.Time
+			}
+			place := ""
+			if it.Place != "" {
+				place = hintStyle.Render("📍 " + it.Place)
+			}
+			fmt.Fprintf(&b, "   %-17s %-28s %s\n", when, truncate(it.Title, 28), place)
+		}
+	}
+
+	if len(d.recent) > 0 {
+		b.WriteString("\n" + labelStyle.Render("📔 Recent entries") + "\n")
+		for _, e := range d.recent {
+			title := truncate(e.Title, 40)
+			if title == "" {
+				title = "Untitled"
+			}
+			if face := models.MoodFace(e.Mood); face != "" {
+				title = face + " " + title
+			}
+			fmt.Fprintf(&b, "   %s  %s\n", d.app.formatDate(e.Timestamp), title)
+		}
+	}
+
+	if len(d.segments) > 0 {
+		b.WriteString("\n" + labelStyle.Render("🚆 Transport") + "\n")
+		for _, seg := range d.segments {
+			co2 := "—"
+			if kg, ok := seg.CO2(); ok {
+				co2 = stats.FormatCO2(kg)
+			}
+			route := seg.Route()
+			if route == "" {
+				route = seg.Mode
+			}
+			fmt.Fprintf(&b, "   %s  %s %-28s %7.0f km  %s\n", d.app.formatDate(seg.Date), transportIcon(seg.Mode),
+				truncate(route, 28), seg.Distance, hintStyle.Render(co2))
+		}
+		f := stats.ComputeFootprint(d.segments)
+		fmt.Fprintf(&b, "   %s  %.0f km, ~%s\n", labelStyle.Render("Total"), f.Distance, stats.FormatCO2(f.CO2))
+	}
+
+	b.WriteString("\n" + labelStyle.Render("🥾 Tracks") + "\n")
+	if len(d.tracks) == 0 && !d.importing {
+		b.WriteString("No tracks yet — press " + d.app.keyHint("import") + " to import a GPX file.\n")
+	}
+	var distance, ascent, descent float64
+	for i, tr := range d.tracks {
+		cursor := "  "
+		if i == d.cursor {
+			cursor = "👉"
+		}
+		date := "          "
+		if tr.StartedAt != nil {
+			date = d.app.formatDate(*tr.StartedAt)
+		}
+		fmt.Fprintf(&b, "%s %s  %-24s %9s  ↑%5.0f m  ↓%5.0f m  %6s\n", cursor, date, truncate(tr.Name, 24),
+			gpx.FormatDistance(tr.Distance), tr.Ascent, tr.Descent, gpx.FormatDuration(tr.Duration()))
+		if tr.Place != "" {
+			fmt.Fprintf(&b, "   %s\n", hintStyle.Render("📍 near "+tr.Place))
+		}
+		if title, ok := d.entries[tr.EntryID]; ok {
+			fmt.Fprintf(&b, "   %s\n", hintStyle.Render("📔 "+title))
+		}
+		distance, ascent, descent = distance+tr.Distance, ascent+tr.Ascent, descent+tr.Descent
+	}
+	if len(d.tracks) > 1 {
+		fmt.Fprintf(&b, "\n%s  %s, ↑%.0f m ↓%.0f m\n", labelStyle.Render("Total"),
+			gpx.FormatDistance(distance), ascent, descent)
+	}
+	if d.importing {
+		b.WriteString("\n" + labelStyle.Render("GPX file") + "\n" + d.path.View() + "\n")
+	}
+	switch d.asking {
+	case askTemplateName:
+		b.WriteString("\n" + labelStyle.Render("Template name") + "\n" + d.answer.View() + "\n")
+	case askCloneStart:
+		b.WriteString("\n" + labelStyle.Render("Start date of the copy") + "\n" + d.answer.View() + "\n")
+	}
+	if d.err != nil {
+		b.WriteString("\n" + errorStyle.Render(d.err.Error()) + "\n")
+	}
+	if d.status != "" {
+		b.WriteString("\n" + d.status + "\n")
+	}
+	if d.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Delete %q? y/n", d.selected().Name)) + "\n")
+	}
+	if !d.embedded {
+		b.WriteString("\n" + hintStyle.Render(d.hint()) + "\n")
+	}
+	return b.String()
+}
+
+// hint names the keys of what the detail is doing.
+func (d tripDetail) hint() string {
+	hint := d.app.keyHint("import") + " import GPX • " + d.app.keyHint("delete") + " delete track • " +
+		d.app.keyHint("tags") + " edit tags • " + d.app.keyHint("legs") + " legs • " + d.app.keyHint("timeline") + " timeline • " + d.app.keyHint("packing") + " packing • " +
+		d.app.keyHint("highlights") + " highlights • " + d.app.keyHint("prep") + " preparation • " + d.app.keyHint("lodging") + " lodging • " + d.app.keyHint("health") + " sleep and jetlag • " +
+		d.app.keyHint("attachments") + " documents • " +
+		d.app.keyHint("template") + " save as template • " + d.app.keyHint("clone") + " clone • " + d.app.keyHint("rate") + " rate • " +
+		d.app.keyHint("public") + " public • " + d.app.keyHint("yearly") + " yearly • " + d.app.keyHint("undo") + " undo • " + "esc back"
+	switch {
+	case d.importing:
+		hint = "enter import • esc cancel"
+	case d.tagging:
+		hint = "enter save tags • esc cancel"
+	case d.asking == askTemplateName:
+		hint = "enter save template • esc cancel"
+	case d.asking == askCloneStart:
+		hint = "enter clone • esc cancel"
+	case d.asking == askRating:
+		hint = "enter save rating • esc cancel"
+	}
+	return hint
+}
+
+// trackSummary describes the tracks linked to an entry, or is empty when
+// there are none.
+func trackSummary(a *app, entryID string) string {
+	tracks, err := a.store.ListTracksByEntry(a.ctx, entryID)
+	if err != nil || len(tracks) == 0 {
+		return ""
+	}
+	var distance, ascent float64
+	for _, t := range tracks {
+		distance, ascent = distance+t.Distance, ascent+t.Ascent
+	}
+	return fmt.Sprintf("🥾 %s ↑%.0f m", gpx.FormatDistance(distance), ascent)
+}
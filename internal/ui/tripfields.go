@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/config"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// noTripFields is shown when a trip's fields are to be edited but none
+// are set up.
+const noTripFields = `No trip fields set up; add one with nomadic config set trip_fields.<key> "<label>"`
+
+// tripFieldsForm edits the values of the fields trips have of their own,
+// as set up in the config.
+type tripFieldsForm struct {
+	form
+	app    *app
+	trip   *models.Trip
+	fields []config.TripField
+}
+
+func newTripFieldsForm(app *app, trip *models.Trip) tripFieldsForm {
+	fields := app.cfg.TripFieldList()
+	inputs := make([]field, len(fields))
+	for i, tf := range fields {
+		inputs[i] = newField(tf.Label, "", "Optional.", nil)
+		inputs[i].input.SetValue(trip.Fields[tf.Key])
+	}
+	// Work on a copy so cancelling leaves the caller's trip untouched.
+	edited := *trip
+	edited.Fields = maps.Clone(trip.Fields)
+	return tripFieldsForm{form: newForm("🪪 Trip fields", inputs...), app: app, trip: &edited, fields: fields}
+}
+
+func (f tripFieldsForm) Title() string { return tr("Trip fields") }
+
+func (f tripFieldsForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f tripFieldsForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		for i, tf := range f.fields {
+			f.trip.SetField(tf.Key, f.value(i))
+		}
+		if err := f.app.store.SaveTrip(f.app.ctx, f.trip); err != nil {
+			f.err = err
+			return f, nil
+		}
+		t := f.trip
+		return f, tea.Sequence(pop, func() tea.Msg { return tripSavedMsg{trip: t} })
+	}
+	return f, cmd
+}
+
+func (f tripFieldsForm) View() string {
+	return f.form.view(f.summary)
+}
+
+func (f tripFieldsForm) summary() string {
+	var b strings.Builder
+	for i, tf := range f.fields {
+		fieldRow(&b, tf.Label, f.value(i))
+	}
+	return b.String()
+}
+
+// fieldRow writes a trip field's row of a summary or the trip detail.
+func fieldRow(b *strings.Builder, label, value string) {
+	if value == "" {
+		value = hintStyle.Render("—")
+	}
+	fmt.Fprintf(b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-13s", label+":")), value)
+}
@@ -0,0 +1,303 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// tripPicker lists trips and opens next for the one the user chooses. It
+// fronts every screen that operates on a single trip, and starts on the
+// trip in progress, so new entries and expenses go to it by default.
+type tripPicker struct {
+	app   *app
+	title string
+	next  func(*models.Trip) screen
+
+	trips  []*models.Trip
+	cursor int
+	filter tagFilter
+	// showArchived lists the archived trips too; archived counts those
+	// left out while it is unset.
+	showArchived bool
+	archived     int
+
+	width, height int
+	// embedded is set while a tripBrowser shows the picker beside the
+	// detail of its trip, and hints at the keys itself.
+	embedded bool
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newTripPicker(app *app, title string, next func(*models.Trip) screen) tripPicker {
+	p := tripPicker{app: app, title: title, next: next, filter: newTagFilter()}
+	p.reload()
+	if active := models.TripOn(p.trips, time.Now()); active != nil {
+		p.cursor = slices.Index(p.trips, active)
+	}
+	return p
+}
+
+func (p tripPicker) Title() string { return p.title }
+
+// pick puts the cursor on the trip with the ID, if it is listed.
+func (p *tripPicker) pick(id string) {
+	for i, t := range p.trips {
+		if t.ID == id {
+			p.cursor = i
+		}
+	}
+}
+
+// resume keeps the trip under the cursor. Only the pickers of the menu
+// can be reopened: the others choose what to open another way.
+func (p tripPicker) resume() (models.SessionScreen, bool) {
+	if _, ok := tripScreens[p.title]; !ok {
+		return models.SessionScreen{}, false
+	}
+	s := models.SessionScreen{Kind: models.SessionPicker, Title: p.title}
+	if len(p.trips) > 0 {
+		s.ID = p.trips[p.cursor].ID
+	}
+	return s, true
+}
+
+func (p tripPicker) Init() tea.Cmd {
+	return nil
+}
+
+func (p tripPicker) capturesEsc() bool { return p.confirmDelete || p.filter.editing }
+
+func (p tripPicker) typing() bool { return p.filter.editing }
+
+func (p tripPicker) help() []key.Binding {
+	if p.filter.editing {
+		return p.filter.help()
+	}
+	return append([]key.Binding{
+		p.app.bind("up", "previous trip"),
+		p.app.bind("down", "next trip"),
+		p.app.bind("select", "open the trip"),
+		p.app.bind("delete", "move the trip to the trash"),
+		p.app.bind("archive", "archive the trip, or take it out of the archive"),
+		p.app.bind("all_trips", "show or hide the archived trips"),
+		p.app.bind("filter", "filter by tag"),
+	}, p.app.undoHelp()...)
+}
+
+func (p *tripPicker) reload() {
+	trips, err := p.app.store.ListTrips(p.app.ctx)
+	p.trips, p.err, p.archived = trips[:0], err, 0
+	for _, t := range trips {
+		switch {
+		case !p.filter.match(t.Tags):
+		case t.Archived() && !p.showArchived:
+			p.archived++
+		default:
+			p.trips = append(p.trips, t)
+		}
+	}
+	p.cursor = clamp(p.cursor, 0, len(p.trips)-1)
+}
+
+// tripPickerChrome is how many lines of a trip picker are not trips, at
+// most, counting those telling of the trips scrolled out of view.
+const tripPickerChrome = 12
+
+func (p tripPicker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.width, p.height = msg.Width, msg.Height
+		return p, nil
+	case tripSavedMsg, historyMsg:
+		p.status = ""
+		p.reload()
+		return p, nil
+	}
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+	if p.filter.editing {
+		cmd, changed := p.filter.update(key)
+		if changed {
+			p.reload()
+		}
+		return p, cmd
+	}
+	if p.confirmDelete {
+		p.confirmDelete = false
+		if key.String() == "y" {
+			t := p.trips[p.cursor]
+			if err := p.app.run(&deleteTrip{trip: t}); err != nil {
+				p.err = err
+				return p, nil
+			}
+			p.status = p.app.trashedHint(t.Title)
+			p.reload()
+		}
+		return p, nil
+	}
+	if cmd, ok := p.app.undoKeys(key); ok {
+		return p, cmd
+	}
+	switch {
+	case p.app.is(key, "up"):
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case p.app.is(key, "down"):
+		if p.cursor < len(p.trips)-1 {
+			p.cursor++
+		}
+	case p.app.is(key, "select"):
+		if len(p.trips) > 0 {
+			return p, push(p.next(p.trips[p.cursor]))
+		}
+	case p.app.is(key, "delete"):
+		if len(p.trips) > 0 {
+			p.confirmDelete = true
+		}
+	case p.app.is(key, "archive"):
+		if len(p.trips) == 0 {
+			return p, nil
+		}
+		t := p.trips[p.cursor]
+		c := archiveTrip{trip: t, archive: !t.Archived()}
+		if err := p.app.run(c); err != nil {
+			p.err = err
+			return p, nil
+		}
+		done := "Archived %q"
+		if !c.archive {
+			done = "Took %q out of the archive"
+		}
+		p.status = fmt.Sprintf(done+" • %s to undo", t.Title, p.app.keyHint("undo"))
+		p.reload()
+	case p.app.is(key, "all_trips"):
+		p.showArchived = !p.showArchived
+		p.reload()
+	case p.app.is(key, "filter"):
+		return p, p.filter.edit(p.app)
+	}
+	return p, nil
+}
+
+func (p tripPicker) View() string {
+	var b strings.Builder
+	if p.err != nil {
+		b.WriteString(errorStyle.Render(p.err.Error()) + "\n")
+		return b.String()
+	}
+	if len(p.trips) == 0 && (p.filter.active() || p.filter.editing) {
+		b.WriteString(p.filter.view())
+		if !p.filter.editing {
+			b.WriteString("No trips carry these tags.\n")
+			b.WriteString("\n" + hintStyle.Render(p.app.keyHint("filter")+" change filter • esc back") + "\n")
+		}
+		return b.String()
+	}
+	if len(p.trips) == 0 && p.archived > 0 {
+		b.WriteString("Every trip is archived.\n")
+		b.WriteString("\n" + hintStyle.Render(p.app.keyHint("all_trips")+" show archived • esc back") + "\n")
+		return b.String()
+	}
+	if len(p.trips) == 0 {
+		b.WriteString("No trips yet — create one from ✈️  New Trip.\n")
+		if p.status != "" {
+			b.WriteString("\n" + p.status + "\n")
+		}
+		b.WriteString("\n" + hintStyle.Render(p.app.keyHint("undo")+" undo • esc back") + "\n")
+		return b.String()
+	}
+	b.WriteString(p.filter.view())
+	b.WriteString(labelStyle.Render("Choose a trip") + "\n")
+	var rows strings.Builder
+	from, to := window(len(p.trips), p.cursor, max(p.height-tripPickerChrome, 3))
+	if from > 0 {
+		rows.WriteString(hintStyle.Render(fmt.Sprintf("   ↑ %d more", from)) + "\n")
+	}
+	for i, t := range p.trips[from:to] {
+		cursor, title := "  ", t.Title
+		if from+i == p.cursor {
+			cursor, title = "👉", cursorStyle.Render(title)
+		}
+		hint := tripDates(p.app, t) + "  " + formatTags(t.Tags)
+		if t.Archived() {
+			hint = "🗄️  archived  " + hint
+		}
+		fmt.Fprintf(&rows, "%s %s %s\n", cursor, title, hintStyle.Render(strings.TrimSpace(hint)))
+	}
+	if to < len(p.trips) {
+		rows.WriteString(hintStyle.Render(fmt.Sprintf("   ↓ %d more", len(p.trips)-to)) + "\n")
+	}
+	b.WriteString(masterDetail(p.width, rows.String(), p.preview()))
+	if p.archived > 0 {
+		b.WriteString(hintStyle.Render(fmt.Sprintf("%s hidden • %s shows them", plural(p.archived, "archived trip", "archived trips"),
+			p.app.keyHint("all_trips"))) + "\n")
+	}
+	if p.status != "" {
+		b.WriteString("\n" + p.status + "\n")
+	}
+	if p.confirmDelete {
+		t := p.trips[p.cursor]
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Move %q with all its entries, expenses and tracks to the trash? y/n", t.Title)) + "\n")
+	}
+	if !p.embedded {
+		b.WriteString("\n" + hintStyle.Render(p.hint()) + "\n")
+	}
+	return b.String()
+}
+
+// hint names the keys of the list under it.
+func (p tripPicker) hint() string {
+	return "↑/↓ move • enter open • " + p.app.keyHint("delete") + " delete • " + p.app.keyHint("archive") + " archive • " +
+		p.app.keyHint("filter") + " filter by tag • " + p.app.keyHint("undo") + " undo • esc back"
+}
+
+// preview sums up the trip under the cursor, for beside the list on wide
+// terminals.
+func (p tripPicker) preview() string {
+	if p.width < wideWidth || p.embedded {
+		return ""
+	}
+	t := p.trips[p.cursor]
+	var b strings.Builder
+	b.WriteString(headerStyle.Render(t.Title) + "\n")
+	b.WriteString(hintStyle.Render(tripDates(p.app, t)) + "\n\n")
+	lines := [][2]string{
+		{"📍", strings.Join(t.Locations, ", ")},
+		{"🌍", tripCountries(t)},
+		{"👥", strings.Join(t.Companions, ", ")},
+		{"⭐", models.Stars(t.Rating)},
+		{"🏷️ ", formatTags(t.Tags)},
+	}
+	for _, l := range lines {
+		if l[1] != "" {
+			b.WriteString(l[0] + " " + l[1] + "\n")
+		}
+	}
+	if t.Notes != "" {
+		b.WriteString("\n" + lipgloss.NewStyle().Width(detailWidth(p.width)).Render(t.Notes) + "\n")
+	}
+	return b.String()
+}
+
+// tripDates formats a trip's date range for list views.
+func tripDates(a *app, t *models.Trip) string {
+	s := a.formatDate(t.StartDate)
+	if t.EndDate != nil {
+		s += " → " + a.formatDate(*t.EndDate)
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/storage"
+)
+
+// unlock asks for the passphrase of an encrypted database on startup.
+type unlock struct {
+	app   *app
+	open  func(passphrase string) (*storage.Store, error)
+	input textinput.Model
+	busy  bool
+	err   error
+}
+
+// unlockedMsg replaces the unlock screen with the home menu once the
+// store is open.
+type unlockedMsg struct {
+	store *storage.Store
+}
+
+// unlockFailedMsg reports a passphrase that did not open the store.
+type unlockFailedMsg struct {
+	err error
+}
+
+func newUnlock(app *app, open func(string) (*storage.Store, error)) unlock {
+	in := textinput.New()
+	in.Placeholder = "passphrase"
+	in.EchoMode = textinput.EchoPassword
+	in.EchoCharacter = '•'
+	in.Width = 40
+	in.Focus()
+	return unlock{app: app, open: open, input: in}
+}
+
+func (u unlock) Title() string { return tr("Unlock") }
+
+func (u unlock) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (u unlock) typing() bool { return true }
+
+func (u unlock) help() []key.Binding {
+	return []key.Binding{fixed("enter", "unlock the journal"), fixed("esc", "quit nomadic")}
+}
+
+func (u unlock) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case unlockFailedMsg:
+		u.busy, u.err = false, msg.err
+		if errors.Is(msg.err, storage.ErrPassphrase) {
+			u.err = errors.New("wrong passphrase, try again")
+		}
+		u.input.SetValue("")
+		return u, nil
+	case tea.KeyMsg:
+		if u.busy {
+			return u, nil
+		}
+		switch msg.String() {
+		case "esc":
+			return u, tea.Quit
+		case "enter":
+			passphrase := u.input.Value()
+			if passphrase == "" {
+				return u, nil
+			}
+			u.busy, u.err = true, nil
+			open := u.open
+			return u, func() tea.Msg {
+				store, err := open(passphrase)
+				if err != nil {
+					return unlockFailedMsg{err: err}
+				}
+				return unlockedMsg{store: store}
+			}
+		}
+	}
+	var cmd tea.Cmd
+	u.input, cmd = u.input.Update(msg)
+	return u, cmd
+}
+
+func (u unlock) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🔒 Your journal is encrypted") + "\n\n")
+	b.WriteString(labelStyle.Render("Passphrase") + "\n")
+	b.WriteString(u.input.View() + "\n")
+	if u.busy {
+		b.WriteString("\n" + hintStyle.Render("Unlocking…") + "\n")
+	}
+	if u.err != nil {
+		b.WriteString("\n" + errorStyle.Render(u.err.Error()) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("enter unlock • esc quit") + "\n")
+	return b.String()
+}
@@ -0,0 +1,525 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+	"github.com/girdharshubham/nomadic/internal/storage"
+	"github.com/girdharshubham/nomadic/internal/viewer"
+)
+
+// vaultList is the vault of travel documents, those running out first, to
+// add, edit and delete them and to open their scans.
+type vaultList struct {
+	app    *app
+	items  []*models.VaultItem
+	alerts map[string]models.ExpiryAlert
+	cursor int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newVaultList(app *app) vaultList {
+	l := vaultList{app: app}
+	l.reload()
+	return l
+}
+
+func (l vaultList) Title() string { return tr("Vault") }
+
+func (l vaultList) Init() tea.Cmd {
+	return nil
+}
+
+func (l vaultList) capturesEsc() bool { return l.confirmDelete }
+
+func (l vaultList) help() []key.Binding {
+	return append([]key.Binding{
+		l.app.bind("up", "previous document"),
+		l.app.bind("down", "next document"),
+		l.app.bind("new", "add a document"),
+		l.app.bind("edit", "edit the document"),
+		l.app.bind("open", "open its scan in the default viewer"),
+		l.app.bind("delete", "delete the document and its scan"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *vaultList) reload() {
+	if l.items, l.err = l.app.store.ListVault(l.app.ctx); l.err != nil {
+		return
+	}
+	trips, err := l.app.store.ListTrips(l.app.ctx)
+	if err != nil {
+		l.err = err
+		return
+	}
+	alerts := l.app.expiryAlerts(l.items, trips)
+	l.alerts = make(map[string]models.ExpiryAlert, len(alerts))
+	for _, a := range alerts {
+		l.alerts[a.Item.ID] = a
+	}
+	l.cursor = clamp(l.cursor, 0, len(l.items)-1)
+}
+
+// expiryAlerts lists the travel documents of items running out within the
+// configured window, or before the end of one of trips coming up.
+func (a *app) expiryAlerts(items []*models.VaultItem, trips []*models.Trip) []models.ExpiryAlert {
+	countries := func(t *models.Trip) []string { return places.Countries(places.Resolve(t.Locations)) }
+	return models.ExpiryAlerts(items, trips, countries, time.Now(), a.cfg.ExpiryWindow())
+}
+
+func (l vaultList) selected() *models.VaultItem {
+	if len(l.items) == 0 {
+		return nil
+	}
+	return l.items[l.cursor]
+}
+
+func (l vaultList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case vaultSavedMsg:
+		l.reload()
+		for i, it := range l.items {
+			if it.ID == msg.item.ID {
+				l.cursor = i
+			}
+		}
+		return l, toast(toastSuccess, "Saved %s", msg.item.Title)
+	case tripSavedMsg:
+		l.reload()
+	case historyMsg:
+		l.status = ""
+		l.reload()
+	case tea.KeyMsg:
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				it := l.selected()
+				if err := l.app.run(&deleteVaultItem{item: it}); err != nil {
+					l.err = err
+					return l, nil
+				}
+				l.status = l.app.deletedHint(it.Title)
+				l.reload()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.items)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			l.status = ""
+			return l, push(newVaultForm(l.app, nil))
+		case l.app.is(msg, "edit"), l.app.edits(msg, "select"):
+			if it := l.selected(); it != nil {
+				l.status = ""
+				return l, push(newVaultForm(l.app, it))
+			}
+		case l.app.is(msg, "open"):
+			it := l.selected()
+			if it == nil {
+				break
+			}
+			l.status = ""
+			path, err := l.app.store.VaultScanFile(l.app.ctx, it)
+			switch {
+			case errors.Is(err, storage.ErrNotFound):
+				l.status = hintStyle.Render("No scan is kept with " + it.Title)
+			case err != nil:
+				l.err = err
+			default:
+				if err := viewer.Open(path); err != nil {
+					l.err = fmt.Errorf("open %s: %w", it.ScanName, err)
+				} else {
+					l.status = "Opened the scan of " + it.Title
+				}
+			}
+		case l.app.is(msg, "delete"):
+			if l.selected() != nil {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l vaultList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🔐 "+tr("Vault")) + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n")
+		return b.String()
+	}
+	if len(l.items) == 0 {
+		b.WriteString("No documents yet — press " + l.app.keyHint("new") + " to keep a passport, visa, insurance policy or vaccination record.\n")
+	}
+	for i, it := range l.items {
+		cursor, title := "  ", it.Title
+		if i == l.cursor {
+			cursor, title = "👉", cursorStyle.Render(title)
+		}
+		var details []string
+		if it.Holder != "" {
+			details = append(details, it.Holder)
+		}
+		if it.Expires != nil {
+			details = append(details, tr("expires %s", l.app.formatDate(*it.Expires)))
+		}
+		if it.ScanName != "" {
+			details = append(details, "📎")
+		}
+		line := fmt.Sprintf("%s %s %s %s", cursor, models.VaultIcon(it.Kind), title, hintStyle.Render(strings.Join(details, " · ")))
+		if a, ok := l.alerts[it.ID]; ok {
+			line += " " + expiryStyle(a).Render(expiryWhen(a))
+		}
+		b.WriteString(line + "\n")
+	}
+	if it := l.selected(); it != nil {
+		b.WriteString("\n" + l.details(it))
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Delete %s and its scan? y/n", l.selected().Title)) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • "+l.app.keyHint("new")+" new • enter/"+l.app.keyHint("edit")+" edit • "+
+		l.app.keyHint("open")+" open scan • "+l.app.keyHint("delete")+" delete • "+l.app.keyHint("undo")+" undo • esc back") + "\n")
+	return b.String()
+}
+
+// details shows the selected document's number, issue and the rest.
+func (l vaultList) details(it *models.VaultItem) string {
+	var b strings.Builder
+	row := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-9s", tr(label)+":")), value)
+		}
+	}
+	row("Number", it.Number)
+	row("Issuer", it.Issuer)
+	if it.Country != "" {
+		row("For", places.CountryName(it.Country))
+	}
+	if it.Issued != nil {
+		row("Issued", l.app.formatDate(*it.Issued))
+	}
+	if it.ScanName != "" {
+		row("Scan", fmt.Sprintf("%s (%s)", it.ScanName, formatSize(it.ScanSize)))
+	}
+	row("Note", truncate(it.Note, 72))
+	return b.String()
+}
+
+// expiryWhen says when the document of an alert runs out, and the trip it
+// runs out before the end of.
+func expiryWhen(a models.ExpiryAlert) string {
+	var when string
+	switch {
+	case a.In < -1:
+		when = tr("expired %d days ago", -a.In)
+	case a.In == -1:
+		when = tr("expired yesterday")
+	case a.In == 0:
+		when = tr("expires today")
+	case a.In == 1:
+		when = tr("expires tomorrow")
+	default:
+		when = tr("expires in %d days", a.In)
+	}
+	if a.Trip != nil && !a.Expired() {
+		when += " " + tr("before %s ends", a.Trip.Title)
+	}
+	return when
+}
+
+// expiryStyle is errorStyle for a document run out or running out before a
+// trip, and warningStyle for one only running out soon.
+func expiryStyle(a models.ExpiryAlert) lipgloss.Style {
+	if a.Expired() || a.Trip != nil {
+		return errorStyle
+	}
+	return warningStyle
+}
+
+// deleteVaultItem deletes a travel document, keeping its scan to put back
+// on undo.
+type deleteVaultItem struct {
+	item *models.VaultItem
+	scan []byte
+}
+
+func (c *deleteVaultItem) do(ctx context.Context, s *storage.Store) error {
+	if c.item.ScanName != "" {
+		scan, err := s.VaultScan(ctx, c.item.ID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+		c.scan = scan
+	}
+	return s.DeleteVaultItem(ctx, c.item.ID)
+}
+func (c *deleteVaultItem) undo(ctx context.Context, s *storage.Store) error {
+	if err := s.SaveVaultItem(ctx, c.item); err != nil {
+		return err
+	}
+	if len(c.scan) == 0 {
+		return nil
+	}
+	return s.SetVaultScan(ctx, c.item.ID, c.item.ScanName, c.item.ScanType, c.scan)
+}
+func (c *deleteVaultItem) String() string {
+	return fmt.Sprintf("delete %s from the vault", c.item.Title)
+}
+
+// vaultForm adds a travel document to the vault, or edits one.
+type vaultForm struct {
+	form
+	app   *app
+	item  *models.VaultItem
+	isNew bool
+}
+
+const (
+	vaultFieldKind = iota
+	vaultFieldTitle
+	vaultFieldHolder
+	vaultFieldNumber
+	vaultFieldIssuer
+	vaultFieldCountry
+	vaultFieldIssued
+	vaultFieldExpires
+	vaultFieldNote
+	vaultFieldScan
+)
+
+func newVaultForm(app *app, it *models.VaultItem) vaultForm {
+	title := "🛂 New document"
+	isNew := it == nil
+	if isNew {
+		it = models.NewVaultItem(models.VaultPassport, "")
+	} else {
+		title = models.VaultIcon(it.Kind) + " " + it.Title
+	}
+	scanHint := "Optional. A PDF or photo to keep with it, encrypted with the database."
+	if it.ScanName != "" {
+		scanHint = fmt.Sprintf("Optional. Replaces %s, kept now; \"-\" removes it.", it.ScanName)
+	}
+	f := newForm(title,
+		newField("Kind", models.VaultPassport, strings.Join(models.VaultKinds, " • "), validateVaultKind),
+		newField("Title", "Passport", "", required("title")),
+		newField("Holder", models.Me, "Optional. The companion it is for, if not you.", nil),
+		newField("Number", "X1234567", "Optional. Document, visa or policy number.", nil),
+		newField("Issuer", "Germany", "Optional. The country, authority or insurer that issued it.", nil),
+		newField("Country", "VN or Vietnam", "Optional. For a visa, the country it lets you into.", validateOptionalCountry),
+		newField("Issued", app.cfg.DateFormat, "Optional.", app.validateOptionalDate),
+		newField("Expires", app.cfg.DateFormat, "Optional. You are warned on the home screen as it nears.", app.validateOptionalDate),
+		newField("Note", "Emergency line +44 20 7946 0000", "Optional.", nil),
+		newField("Scan", "~/scans/passport.pdf", scanHint, validateScan),
+	)
+	f.fields[vaultFieldIssued].dates = newDatePicker(app)
+	f.fields[vaultFieldExpires].dates = newDatePicker(app)
+	f.fields[vaultFieldKind].input.SetValue(it.Kind)
+	f.fields[vaultFieldTitle].input.SetValue(it.Title)
+	f.fields[vaultFieldHolder].input.SetValue(it.Holder)
+	f.fields[vaultFieldNumber].input.SetValue(it.Number)
+	f.fields[vaultFieldIssuer].input.SetValue(it.Issuer)
+	if it.Country != "" {
+		f.fields[vaultFieldCountry].input.SetValue(places.CountryName(it.Country))
+	}
+	if it.Issued != nil {
+		f.fields[vaultFieldIssued].input.SetValue(app.formatDate(*it.Issued))
+	}
+	if it.Expires != nil {
+		f.fields[vaultFieldExpires].input.SetValue(app.formatDate(*it.Expires))
+	}
+	f.fields[vaultFieldNote].input.SetValue(it.Note)
+	// Work on a copy so cancelling leaves the caller's document untouched.
+	edited := *it
+	return vaultForm{form: f, app: app, item: &edited, isNew: isNew}
+}
+
+func (f vaultForm) Title() string {
+	if f.isNew {
+		return tr("New document")
+	}
+	return f.item.Title
+}
+
+func (f vaultForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f vaultForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		if err := f.apply(); err != nil {
+			f.err = err
+			return f, nil
+		}
+		if err := f.app.store.SaveVaultItem(f.app.ctx, f.item); err != nil {
+			f.err = err
+			return f, nil
+		}
+		if err := f.saveScan(); err != nil {
+			f.err = err
+			return f, nil
+		}
+		it := f.item
+		return f, tea.Sequence(pop, func() tea.Msg { return vaultSavedMsg{item: it} })
+	}
+	return f, cmd
+}
+
+// apply copies the validated values into the document.
+func (f vaultForm) apply() error {
+	it := f.item
+	var err error
+	if it.Kind, err = models.ParseVaultKind(f.value(vaultFieldKind)); err != nil {
+		return err
+	}
+	it.Title = f.value(vaultFieldTitle)
+	it.Holder = f.value(vaultFieldHolder)
+	if strings.EqualFold(it.Holder, models.Me) {
+		it.Holder = ""
+	}
+	it.Number = f.value(vaultFieldNumber)
+	it.Issuer = f.value(vaultFieldIssuer)
+	it.Country = ""
+	if v := f.value(vaultFieldCountry); v != "" {
+		it.Country, _ = places.CountryCode(v)
+	}
+	for _, d := range []struct {
+		field int
+		day   **time.Time
+	}{{vaultFieldIssued, &it.Issued}, {vaultFieldExpires, &it.Expires}} {
+		*d.day = nil
+		if v := f.value(d.field); v != "" {
+			t, err := f.app.parseDate(v)
+			if err != nil {
+				return err
+			}
+			*d.day = &t
+		}
+	}
+	if it.Issued != nil && it.Expires != nil && it.Expires.Before(*it.Issued) {
+		return errors.New("a document cannot run out before it was issued")
+	}
+	it.Note = f.value(vaultFieldNote)
+	return nil
+}
+
+// saveScan keeps the file entered as the document's scan, or removes the
+// scan for "-".
+func (f vaultForm) saveScan() error {
+	v := f.value(vaultFieldScan)
+	switch v {
+	case "":
+		return nil
+	case "-":
+		f.item.ScanName, f.item.ScanType, f.item.ScanSize = "", "", 0
+		return f.app.store.SetVaultScan(f.app.ctx, f.item.ID, "", "", nil)
+	}
+	path := expandHome(v)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(path)
+	contentType := strings.TrimSuffix(mime.TypeByExtension(strings.ToLower(filepath.Ext(name))), "; charset=utf-8")
+	if err := f.app.store.SetVaultScan(f.app.ctx, f.item.ID, name, contentType, data); err != nil {
+		return err
+	}
+	f.item.ScanName, f.item.ScanType, f.item.ScanSize = name, contentType, int64(len(data))
+	return nil
+}
+
+func (f vaultForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		row := func(label, value string) {
+			if value == "" {
+				value = hintStyle.Render("—")
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-9s", label+":")), value)
+		}
+		kind, _ := models.ParseVaultKind(f.value(vaultFieldKind))
+		scan := f.item.ScanName
+		switch v := f.value(vaultFieldScan); v {
+		case "":
+		case "-":
+			scan = ""
+		default:
+			scan = filepath.Base(v)
+		}
+		row("Kind", kind)
+		row("Title", f.value(vaultFieldTitle))
+		row("Holder", f.value(vaultFieldHolder))
+		row("Number", f.value(vaultFieldNumber))
+		row("Issuer", f.value(vaultFieldIssuer))
+		row("Country", f.value(vaultFieldCountry))
+		row("Issued", f.value(vaultFieldIssued))
+		row("Expires", f.value(vaultFieldExpires))
+		row("Note", f.value(vaultFieldNote))
+		row("Scan", scan)
+		return b.String()
+	})
+}
+
+func validateVaultKind(v string) error {
+	_, err := models.ParseVaultKind(v)
+	return err
+}
+
+func validateOptionalCountry(v string) error {
+	if v == "" {
+		return nil
+	}
+	return validateCountry(v)
+}
+
+func validateScan(v string) error {
+	if v == "" || v == "-" {
+		return nil
+	}
+	info, err := os.Stat(expandHome(v))
+	switch {
+	case err != nil:
+		return errors.New("no such file")
+	case info.IsDir():
+		return errors.New("choose a file, not a directory")
+	case info.Size() == 0:
+		return errors.New("the file is empty")
+	}
+	return nil
+}
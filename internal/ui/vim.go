@@ -0,0 +1,284 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/export"
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// tripper is implemented by screens about one trip, so commands such as
+// :export know which trip to act on.
+type tripper interface {
+	currentTrip() *models.Trip
+}
+
+// vimMode is the vim-style input layer the vim setting puts over every
+// screen. Screens that type into a text input start in insert mode, as
+// without it; Esc, where it would leave the screen, switches to normal
+// mode instead, where h, j, k and l move, i switches back and other
+// letters type nothing. Everywhere, : opens a command line in the footer
+// and the search key searches the journal.
+type vimMode struct {
+	normal bool
+	// prompting is set while the command line is open.
+	prompting bool
+	command   textinput.Model
+	// status and err report the last command until the next key.
+	status string
+	err    error
+}
+
+func newVimMode() *vimMode {
+	in := textinput.New()
+	in.Prompt = ":"
+	in.CharLimit = 256
+	return &vimMode{command: in}
+}
+
+// vimCommand is a command of the : command line.
+type vimCommand struct {
+	names []string
+	args  string
+	desc  string
+	run   func(m *Model, args []string) tea.Cmd
+}
+
+var vimCommands = []vimCommand{
+	{names: []string{"q", "quit"}, desc: "close the screen, or quit on the home screen", run: func(m *Model, _ []string) tea.Cmd {
+		if len(m.stack) > 1 {
+			return pop
+		}
+		return quit
+	}},
+	{names: []string{"qa", "q!", "qall", "quitall"}, desc: "quit nomadic", run: func(*Model, []string) tea.Cmd {
+		return quit
+	}},
+	{names: []string{"home"}, desc: "go back to the home menu", run: func(m *Model, _ []string) tea.Cmd {
+		m.stack = m.stack[:1]
+		m.vim.normal = false
+		return nil
+	}},
+	{names: []string{"trip"}, args: "[new]", desc: "list the trips, or create one", run: (*Model).vimTrip},
+	{names: []string{"export"}, args: "md|pdf|ics|geojson|kml [dir]", desc: "export this screen's trip into dir (default: the current directory)", run: (*Model).vimExport},
+	{names: []string{"search"}, args: "[words]", desc: "search the journal", run: func(m *Model, args []string) tea.Cmd {
+		s := newSearch(m.app)
+		if len(args) > 0 {
+			s.input.SetValue(strings.Join(args, " "))
+			s.run()
+		}
+		return push(s)
+	}},
+	{names: []string{"theme"}, args: "[name]", desc: "switch theme", run: func(m *Model, args []string) tea.Cmd {
+		if len(args) == 0 {
+			return m.app.nextTheme()
+		}
+		if err := m.app.setTheme(args[0]); err != nil {
+			m.vim.err = err
+			return nil
+		}
+		name := args[0]
+		return func() tea.Msg { return themeChangedMsg{name: name} }
+	}},
+	{names: []string{"undo", "u"}, desc: "undo", run: func(m *Model, _ []string) tea.Cmd { return m.app.undo() }},
+	{names: []string{"redo", "red"}, desc: "redo", run: func(m *Model, _ []string) tea.Cmd { return m.app.redo() }},
+	{names: []string{"help", "h"}, desc: "show the keys and commands", run: func(m *Model, _ []string) tea.Cmd {
+		m.help = true
+		return nil
+	}},
+}
+
+// vimHelp describes the keys and commands of vim mode, for the help
+// overlay.
+func vimHelp() []key.Binding {
+	bindings := []key.Binding{
+		fixed("esc", "normal mode: h/j/k/l move, i types again"),
+		fixed(":", "open the command line"),
+	}
+	for _, c := range vimCommands {
+		usage := ":" + c.names[0]
+		if c.args != "" {
+			usage += " " + c.args
+		}
+		bindings = append(bindings, fixed(usage, c.desc))
+	}
+	return bindings
+}
+
+// vimKey handles a key press in vim mode. It reports whether the key was
+// used up; otherwise it goes on as usual.
+func (m *Model) vimKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	v := m.vim
+	v.status, v.err = "", nil
+	if v.prompting {
+		switch msg.Type {
+		case tea.KeyEsc:
+			v.prompting = false
+			return nil, true
+		case tea.KeyEnter:
+			v.prompting = false
+			return m.runVimCommand(v.command.Value()), true
+		case tea.KeyBackspace:
+			if v.command.Value() == "" {
+				v.prompting = false
+				return nil, true
+			}
+		}
+		var cmd tea.Cmd
+		v.command, cmd = v.command.Update(msg)
+		return cmd, true
+	}
+
+	t, ok := m.top().(typist)
+	typing := ok && t.typing()
+	if typing && !v.normal {
+		c, ok := m.top().(escCapturer)
+		if msg.Type == tea.KeyEsc && (!ok || !c.capturesEsc()) {
+			v.normal = true
+			return nil, true
+		}
+		return nil, false
+	}
+	if msg.Type != tea.KeyRunes {
+		return nil, false
+	}
+	switch {
+	case msg.String() == ":":
+		v.prompting = true
+		v.command.SetValue("")
+		return v.command.Focus(), true
+	case m.app.is(msg, "search"):
+		return push(newSearch(m.app)), true
+	case !typing, m.app.is(msg, "help"):
+		return nil, false
+	}
+	switch msg.String() {
+	case "i", "a":
+		v.normal = false
+		return nil, true
+	case "h":
+		return m.forward(tea.KeyMsg{Type: tea.KeyLeft}), true
+	case "j":
+		return m.forward(tea.KeyMsg{Type: tea.KeyDown}), true
+	case "k":
+		return m.forward(tea.KeyMsg{Type: tea.KeyUp}), true
+	case "l":
+		return m.forward(tea.KeyMsg{Type: tea.KeyRight}), true
+	}
+	return nil, true
+}
+
+// forward sends msg straight to the screen on top.
+func (m *Model) forward(msg tea.Msg) tea.Cmd {
+	updated, cmd := m.top().Update(msg)
+	m.setTop(updated.(screen))
+	return cmd
+}
+
+// runVimCommand runs a line typed on the command line.
+func (m *Model) runVimCommand(line string) tea.Cmd {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	for _, c := range vimCommands {
+		for _, name := range c.names {
+			if name == fields[0] {
+				return c.run(m, fields[1:])
+			}
+		}
+	}
+	m.vim.err = fmt.Errorf("not a command: %s", fields[0])
+	return nil
+}
+
+func (m *Model) vimTrip(args []string) tea.Cmd {
+	switch {
+	case len(args) == 0:
+		return push(newTripBrowser(m.app))
+	case args[0] == "new" && m.app.readOnly():
+		m.vim.err = errors.New("read-only: nothing can be changed")
+		return nil
+	case args[0] == "new":
+		return push(newTripForm(m.app))
+	}
+	m.vim.err = errors.New("usage: :trip [new]")
+	return nil
+}
+
+// vimExport writes the trip of the nearest screen about one, in the
+// format named by args[0], into the directory args[1].
+func (m *Model) vimExport(args []string) tea.Cmd {
+	if len(args) == 0 || len(args) > 2 {
+		m.vim.err = errors.New("usage: :export md|pdf|ics|geojson|kml [dir]")
+		return nil
+	}
+	format := -1
+	for i, f := range exportFormats {
+		if strings.EqualFold(args[0], strings.TrimPrefix(f.ext, ".")) || strings.EqualFold(args[0], strings.Fields(f.name)[0]) {
+			format = i
+		}
+	}
+	if format < 0 {
+		m.vim.err = fmt.Errorf("cannot export as %s; use md, pdf, ics, geojson or kml", args[0])
+		return nil
+	}
+	var trip *models.Trip
+	for i := len(m.stack) - 1; i >= 0 && trip == nil; i-- {
+		if t, ok := m.stack[i].(tripper); ok {
+			trip = t.currentTrip()
+		}
+	}
+	if trip == nil {
+		m.vim.err = errors.New("open a trip to export it first")
+		return nil
+	}
+	dir := "."
+	if len(args) == 2 {
+		dir = expandHome(args[1])
+	}
+	paths, err := func() ([]string, error) {
+		t, err := m.app.store.GetTrip(m.app.ctx, trip.ID)
+		if err != nil {
+			return nil, err
+		}
+		x, err := export.Load(m.app.ctx, m.app.store, t)
+		if err != nil {
+			return nil, err
+		}
+		x.FieldLabels = m.app.cfg.TripFields
+		return exportFormats[format].write(dir, []*export.Trip{x.Redact(m.app.redaction())}, m.app.cfg.Layout())
+	}()
+	if err != nil {
+		m.vim.err = err
+		return nil
+	}
+	m.vim.status = "Wrote " + paths[0]
+	return nil
+}
+
+// vimStatus is the footer's report of vim mode: the open command line,
+// the outcome of the last command, or the mode of a screen that types.
+func (m Model) vimStatus() string {
+	v := m.vim
+	t, ok := m.top().(typist)
+	switch {
+	case v.prompting:
+		return v.command.View()
+	case v.err != nil:
+		return errorStyle.Render(v.err.Error())
+	case v.status != "":
+		return successStyle.Render(v.status)
+	case !ok || !t.typing():
+		return ""
+	case v.normal:
+		return labelStyle.Render("-- NORMAL --")
+	}
+	return hintStyle.Render("-- INSERT --")
+}
@@ -0,0 +1,356 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+)
+
+// wishList is the wishlist of places to go some day, most wanted first,
+// to add, edit and delete them and to plan trips from them.
+type wishList struct {
+	app    *app
+	wishes []*models.Wish
+	// trips are the titles of the trips planned from wishes, by ID.
+	trips  map[string]string
+	cursor int
+
+	confirmDelete bool
+	status        string
+	err           error
+}
+
+func newWishList(app *app) wishList {
+	l := wishList{app: app}
+	l.reload()
+	return l
+}
+
+func (l wishList) Title() string { return tr("Wishlist") }
+
+func (l wishList) Init() tea.Cmd {
+	return nil
+}
+
+func (l wishList) capturesEsc() bool { return l.confirmDelete }
+
+func (l wishList) help() []key.Binding {
+	return append([]key.Binding{
+		l.app.bind("up", "previous wish"),
+		l.app.bind("down", "next wish"),
+		l.app.bind("new", "add a place to go"),
+		l.app.bind("edit", "edit the wish"),
+		l.app.bind("promote", "plan a trip from the wish, or open the trip planned"),
+		l.app.bind("delete", "take the wish off the list"),
+	}, l.app.undoHelp()...)
+}
+
+func (l *wishList) reload() {
+	if l.wishes, l.err = l.app.store.ListWishes(l.app.ctx); l.err != nil {
+		return
+	}
+	trips, err := l.app.store.ListTrips(l.app.ctx)
+	if err != nil {
+		l.err = err
+		return
+	}
+	l.trips = make(map[string]string, len(trips))
+	for _, t := range trips {
+		l.trips[t.ID] = t.Title
+	}
+	l.cursor = clamp(l.cursor, 0, len(l.wishes)-1)
+}
+
+func (l wishList) selected() *models.Wish {
+	if len(l.wishes) == 0 {
+		return nil
+	}
+	return l.wishes[l.cursor]
+}
+
+func (l wishList) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case wishSavedMsg:
+		l.reload()
+		for i, w := range l.wishes {
+			if w.ID == msg.wish.ID {
+				l.cursor = i
+			}
+		}
+		return l, toast(toastSuccess, "Saved the wish to go to %s", msg.wish.Place)
+	case tripSavedMsg:
+		l.reload()
+	case historyMsg:
+		l.status = ""
+		l.reload()
+	case tea.KeyMsg:
+		if l.confirmDelete {
+			l.confirmDelete = false
+			if msg.String() == "y" {
+				w := l.selected()
+				if err := l.app.run(deleteWish{wish: w}); err != nil {
+					l.err = err
+					return l, nil
+				}
+				l.status = l.app.deletedHint(w.Place)
+				l.reload()
+			}
+			return l, nil
+		}
+		if cmd, ok := l.app.undoKeys(msg); ok {
+			return l, cmd
+		}
+		switch {
+		case l.app.is(msg, "up"):
+			if l.cursor > 0 {
+				l.cursor--
+			}
+		case l.app.is(msg, "down"):
+			if l.cursor < len(l.wishes)-1 {
+				l.cursor++
+			}
+		case l.app.is(msg, "new"):
+			l.status = ""
+			return l, push(newWishForm(l.app, nil))
+		case l.app.is(msg, "edit"), l.app.edits(msg, "select"):
+			if w := l.selected(); w != nil {
+				l.status = ""
+				return l, push(newWishForm(l.app, w))
+			}
+		case l.app.is(msg, "promote"):
+			w := l.selected()
+			if w == nil {
+				break
+			}
+			l.status = ""
+			if w.TripID != "" {
+				t, err := l.app.store.GetTrip(l.app.ctx, w.TripID)
+				if err != nil {
+					l.err = err
+					return l, nil
+				}
+				return l, push(newTripDetail(l.app, t))
+			}
+			if l.app.readOnly() {
+				l.status = tr(refusedText)
+				break
+			}
+			return l, push(newTripFormFromWish(l.app, w))
+		case l.app.is(msg, "delete"):
+			if l.selected() != nil {
+				l.confirmDelete = true
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l wishList) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🌠 "+tr("Wishlist")) + "\n\n")
+	if l.err != nil {
+		b.WriteString(errorStyle.Render(l.err.Error()) + "\n")
+		return b.String()
+	}
+	if len(l.wishes) == 0 {
+		b.WriteString("Nowhere on the list yet — press " + l.app.keyHint("new") + " to add a place to go some day.\n")
+	}
+	now := time.Now().Month()
+	planned := false
+	for i, w := range l.wishes {
+		if w.TripID != "" && !planned {
+			planned = true
+			b.WriteString("\n" + labelStyle.Render(tr("Planned")) + "\n")
+		}
+		cursor, place := "  ", w.Place
+		if i == l.cursor {
+			cursor, place = "👉", cursorStyle.Render(place)
+		}
+		details := []string{tr(w.Priority)}
+		if len(w.Season) > 0 {
+			details = append(details, tr("best %s", models.FormatSeason(w.Season)))
+		}
+		if w.Budget > 0 {
+			details = append(details, fmt.Sprintf("~%.0f %s", w.Budget, w.Currency))
+		}
+		if w.TripID != "" {
+			details = append(details, "✈️  "+l.trips[w.TripID])
+		}
+		line := fmt.Sprintf("%s %s %s", cursor, place, hintStyle.Render(strings.Join(details, " · ")))
+		if w.TripID == "" && w.InSeason(now) {
+			line += " " + highlightStyle.Render(tr("in season"))
+		}
+		b.WriteString(line + "\n")
+	}
+	if w := l.selected(); w != nil && w.Notes != "" {
+		b.WriteString("\n" + hintStyle.Render(truncate(w.Notes, 72)) + "\n")
+	}
+	if l.status != "" {
+		b.WriteString("\n" + l.status + "\n")
+	}
+	if l.confirmDelete {
+		b.WriteString("\n" + errorStyle.Render(fmt.Sprintf("Take %s off the wishlist? y/n", l.selected().Place)) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render("↑/↓ move • "+l.app.keyHint("new")+" new • enter/"+l.app.keyHint("edit")+" edit • "+
+		l.app.keyHint("promote")+" plan trip • "+l.app.keyHint("delete")+" delete • "+l.app.keyHint("undo")+" undo • esc back") + "\n")
+	return b.String()
+}
+
+// wishForm adds a place to the wishlist, or edits a wish.
+type wishForm struct {
+	form
+	app   *app
+	wish  *models.Wish
+	isNew bool
+}
+
+const (
+	wishFieldPlace = iota
+	wishFieldPriority
+	wishFieldSeason
+	wishFieldBudget
+	wishFieldCurrency
+	wishFieldNotes
+)
+
+func newWishForm(app *app, w *models.Wish) wishForm {
+	title := "🌠 New wish"
+	isNew := w == nil
+	if isNew {
+		w = models.NewWish("")
+	} else {
+		title = "🌠 " + w.Place
+	}
+	f := newForm(title,
+		newField("Place", "Patagonia", "A city, a country or a region.", required("place")),
+		newField("Priority", models.PriorityMedium, strings.Join(models.Priorities, " • "), validatePriority),
+		newField("Best season", "apr-may, oct", "Optional. The months best to go in.", validateSeason),
+		newField("Budget", "2500", "Optional. Roughly what the trip would cost.", validateAmount),
+		newField("Currency", "EUR", "Three-letter ISO code of the budget.", validateOptionalCurrency),
+		newField("Notes", "W trek, book refugios early", "Optional. Carried over to the trip once planned.", nil),
+	)
+	f.fields[wishFieldPlace].places = newPlaceCompleter(false)
+	f.fields[wishFieldPlace].input.SetValue(w.Place)
+	f.fields[wishFieldPriority].input.SetValue(w.Priority)
+	f.fields[wishFieldSeason].input.SetValue(models.FormatSeason(w.Season))
+	if w.Budget != 0 {
+		f.fields[wishFieldBudget].input.SetValue(strconv.FormatFloat(w.Budget, 'f', -1, 64))
+	}
+	currency := w.Currency
+	if currency == "" {
+		currency = app.cfg.DefaultCurrency
+	}
+	f.fields[wishFieldCurrency].input.SetValue(currency)
+	f.fields[wishFieldNotes].input.SetValue(w.Notes)
+	// Work on a copy so cancelling leaves the caller's wish untouched.
+	edited := *w
+	return wishForm{form: f, app: app, wish: &edited, isNew: isNew}
+}
+
+func (f wishForm) Title() string {
+	if f.isNew {
+		return tr("New wish")
+	}
+	return f.wish.Place
+}
+
+func (f wishForm) Init() tea.Cmd {
+	return nil
+}
+
+func (f wishForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var (
+		cmd    tea.Cmd
+		result formResult
+	)
+	f.form, cmd, result = f.form.update(msg)
+	switch result {
+	case formCancelled:
+		return f, pop
+	case formConfirmed:
+		if err := f.apply(); err != nil {
+			f.err = err
+			return f, nil
+		}
+		if err := f.app.store.SaveWish(f.app.ctx, f.wish); err != nil {
+			f.err = err
+			return f, nil
+		}
+		w := f.wish
+		return f, tea.Sequence(pop, func() tea.Msg { return wishSavedMsg{wish: w} })
+	}
+	return f, cmd
+}
+
+// apply copies the validated values into the wish.
+func (f wishForm) apply() error {
+	w := f.wish
+	var err error
+	w.Place = f.value(wishFieldPlace)
+	if w.Priority, err = models.ParsePriority(f.value(wishFieldPriority)); err != nil {
+		return err
+	}
+	if w.Season, err = models.ParseSeason(f.value(wishFieldSeason)); err != nil {
+		return err
+	}
+	w.Budget = 0
+	if v := f.value(wishFieldBudget); v != "" {
+		if w.Budget, err = strconv.ParseFloat(v, 64); err != nil {
+			return err
+		}
+	}
+	currency := f.value(wishFieldCurrency)
+	if currency == "" {
+		currency = f.app.cfg.DefaultCurrency
+	}
+	if w.Budget > 0 || currency != "" {
+		if w.Currency, err = models.ParseCurrency(currency); err != nil {
+			return err
+		}
+	}
+	w.Notes = f.value(wishFieldNotes)
+	return w.Validate()
+}
+
+func (f wishForm) View() string {
+	return f.form.view(func() string {
+		var b strings.Builder
+		row := func(label, value string) {
+			if value == "" {
+				value = hintStyle.Render("—")
+			}
+			fmt.Fprintf(&b, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-12s", label+":")), value)
+		}
+		priority, _ := models.ParsePriority(f.value(wishFieldPriority))
+		season, _ := models.ParseSeason(f.value(wishFieldSeason))
+		budget := ""
+		if v := f.value(wishFieldBudget); v != "" && v != "0" {
+			budget = v + " " + strings.ToUpper(f.value(wishFieldCurrency))
+		}
+		row("Place", f.value(wishFieldPlace))
+		row("Priority", priority)
+		row("Best season", models.FormatSeason(season))
+		row("Budget", budget)
+		row("Notes", f.value(wishFieldNotes))
+		return b.String()
+	})
+}
+
+func validatePriority(v string) error {
+	_, err := models.ParsePriority(v)
+	return err
+}
+
+func validateSeason(v string) error {
+	if _, err := models.ParseSeason(v); err != nil {
+		return errors.New("name the months like apr-may, oct")
+	}
+	return nil
+}
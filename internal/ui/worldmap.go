@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/girdharshubham/nomadic/internal/models"
+	"github.com/girdharshubham/nomadic/internal/places"
+)
+
+const (
+	maxMapWidth = 120 // characters
+	minMapWidth = 40
+)
+
+// brailleDots are the bits of the dots of a braille character, by column
+// and then row of its two by four grid.
+var brailleDots = [2][4]rune{{0x01, 0x02, 0x04, 0x40}, {0x08, 0x10, 0x20, 0x80}}
+
+// mapView is a world map with the countries of past trips highlighted,
+// either every trip or this year's.
+type mapView struct {
+	app      *app
+	trips    []*models.Trip
+	thisYear bool
+
+	world         *places.Map
+	width, height int
+	err           error
+}
+
+func newMapView(app *app) mapView {
+	v := mapView{app: app}
+	v.reload()
+	return v
+}
+
+func (v *mapView) reload() {
+	v.trips, v.err = v.app.store.ListTrips(v.app.ctx)
+}
+
+func (v mapView) Title() string { return tr("Map") }
+
+func (v mapView) Init() tea.Cmd {
+	return nil
+}
+
+func (v mapView) help() []key.Binding {
+	return []key.Binding{
+		v.app.bind("toggle", "switch between every trip and this year's"),
+		v.app.bind("quit", "close"),
+	}
+}
+
+func (v mapView) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.width, v.height = msg.Width, msg.Height
+		if cols, rows := v.mapSize(); v.world == nil || v.world.Cols != cols || v.world.Rows != rows {
+			v.world = places.WorldMap(cols, rows)
+		}
+	case tripSavedMsg, historyMsg:
+		v.reload()
+	case tea.KeyMsg:
+		switch {
+		case v.app.is(msg, "toggle"):
+			v.thisYear = !v.thisYear
+		case v.app.is(msg, "quit"):
+			return v, pop
+		}
+	}
+	return v, nil
+}
+
+// mapSize picks the map's size in braille dots, as wide as the screen
+// allows. Dots are about square, so the map keeps the 5:2 shape of the
+// world between places.MapNorth and places.MapSouth.
+func (v mapView) mapSize() (cols, rows int) {
+	width := min(v.width, maxMapWidth)
+	if v.height > 0 {
+		// A line of the map is five characters of width. Leave lines for
+		// the title, the counter and the key hints.
+		width = min(width, (v.height-10)*5)
+	}
+	cols = max(width, minMapWidth) * 2
+	return cols, cols * 2 / 5 / 4 * 4
+}
+
+// visited returns the countries of the trips that began by now, or by now
+// this year, most visited first.
+func (v mapView) visited(now time.Time) []string {
+	count := map[string]int{}
+	for _, t := range v.trips {
+		if t.StartDate.After(now) {
+			continue
+		}
+		end := t.StartDate
+		if t.EndDate != nil {
+			end = *t.EndDate
+		}
+		if v.thisYear && end.Year() < now.Year() {
+			continue
+		}
+		for _, c := range places.Countries(places.Resolve(t.Locations)) {
+			count[c]++
+		}
+	}
+	codes := make([]string, 0, len(count))
+	for c := range count {
+		codes = append(codes, c)
+	}
+	slices.SortFunc(codes, func(a, b string) int {
+		if count[a] != count[b] {
+			return count[b] - count[a]
+		}
+		return strings.Compare(places.CountryName(a), places.CountryName(b))
+	})
+	return codes
+}
+
+func (v mapView) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("🗺️  Map") + "\n\n")
+	if v.err != nil {
+		b.WriteString(errorStyle.Render(v.err.Error()) + "\n")
+		return b.String()
+	}
+
+	now := time.Now()
+	modes := []string{"Every trip", fmt.Sprintf("This year (%d)", now.Year())}
+	for i, mode := range modes {
+		if i > 0 {
+			b.WriteString(hintStyle.Render(" / "))
+		}
+		if (i == 1) == v.thisYear && accessible {
+			b.WriteString("[" + mode + "]")
+		} else if (i == 1) == v.thisYear {
+			b.WriteString(highlightStyle.Render(mode))
+		} else {
+			b.WriteString(hintStyle.Render(mode))
+		}
+	}
+	b.WriteString("\n\n")
+
+	codes := v.visited(now)
+	// Braille art reads as noise; the countries are listed below it anyway.
+	if v.world != nil && !accessible {
+		b.WriteString(v.render(codes))
+	}
+
+	b.WriteString("\n")
+	switch {
+	case len(codes) == 0 && v.thisYear:
+		b.WriteString(fmt.Sprintf("No countries visited in %d yet.\n", now.Year()))
+	case len(codes) == 0:
+		b.WriteString("No countries visited yet — the destinations of your trips fill in the map.\n")
+	default:
+		names := make([]string, len(codes))
+		for i, c := range codes {
+			names[i] = places.CountryName(c)
+		}
+		count := plural(len(codes), "country", "countries") + " visited"
+		if v.thisYear {
+			count += fmt.Sprintf(" in %d", now.Year())
+		}
+		b.WriteString(labelStyle.Render(count) + "\n")
+		b.WriteString(lipgloss.NewStyle().Width(clamp(v.width, minMapWidth, maxMapWidth)).Render(strings.Join(names, " · ")) + "\n")
+	}
+	b.WriteString("\n" + hintStyle.Render(v.app.keyHint("toggle")+" every trip / this year • esc back") + "\n")
+	return b.String()
+}
+
+// render draws the map in braille characters, each covering two by four
+// cells, highlighting those with land in a visited country.
+func (v mapView) render(visited []string) string {
+	w := v.world
+	var b strings.Builder
+	for row := 0; row < w.Rows; row += 4 {
+		var line strings.Builder
+		var run []rune
+		runVisited := false
+		flush := func() {
+			if len(run) == 0 {
+				return
+			}
+			if runVisited {
+				line.WriteString(successStyle.Render(string(run)))
+			} else {
+				line.WriteString(hintStyle.Render(string(run)))
+			}
+			run = run[:0]
+		}
+		for col := 0; col < w.Cols; col += 2 {
+			var dots rune
+			hit := false
+			for dx := range 2 {
+				for dy := range 4 {
+					if c := w.At(col+dx, row+dy); c != "" {
+						dots |= brailleDots[dx][dy]
+						hit = hit || slices.Contains(visited, c)
+					}
+				}
+			}
+			if hit != runVisited {
+				flush()
+				runVisited = hit
+			}
+			run = append(run, 0x2800+dots)
+		}
+		flush()
+		b.WriteString(line.String() + "\n")
+	}
+	return b.String()
+}
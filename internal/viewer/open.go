@@ -0,0 +1,27 @@
+// Package viewer opens attachments with the system viewer and draws
+// low-resolution previews with terminal image protocols.
+package viewer
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// Open opens path with the desktop's default application without waiting
+// for it to close.
+func Open(path string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", path)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
@@ -0,0 +1,164 @@
+package viewer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif" // decoders for Preview
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+// Protocol is a terminal graphics protocol.
+type Protocol string
+
+const (
+	None   Protocol = "off"
+	Kitty  Protocol = "kitty"
+	ITerm2 Protocol = "iterm2"
+	Sixel  Protocol = "sixel"
+	// Auto detects the protocol from the environment.
+	Auto Protocol = "auto"
+)
+
+// Protocols lists the accepted values of the image_preview setting.
+var Protocols = []Protocol{Auto, None, Kitty, ITerm2, Sixel}
+
+// ParseProtocol reads an image_preview setting.
+func ParseProtocol(v string) (Protocol, error) {
+	for _, p := range Protocols {
+		if string(p) == v {
+			return p, nil
+		}
+	}
+	names := make([]string, len(Protocols))
+	for i, p := range Protocols {
+		names[i] = string(p)
+	}
+	return None, fmt.Errorf("image_preview %q is not one of %s", v, strings.Join(names, ", "))
+}
+
+// Detect resolves Auto to the protocol the terminal most likely speaks,
+// judging by the variables terminals set. Sixel cannot be detected
+// reliably and is only chosen for terminals known to support it.
+func Detect(p Protocol, getenv func(string) string) Protocol {
+	if p != Auto {
+		return p
+	}
+	term, program := getenv("TERM"), getenv("TERM_PROGRAM")
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", program == "ghostty":
+		return Kitty
+	case program == "iTerm.app", program == "WezTerm":
+		return ITerm2
+	case strings.HasPrefix(term, "foot"), term == "mlterm", strings.Contains(term, "sixel"):
+		return Sixel
+	}
+	return None
+}
+
+// Cell size assumed when turning terminal cells into pixels.
+const (
+	cellWidth  = 8
+	cellHeight = 16
+	// maxPixels bounds the longer side of a preview to keep it low-res.
+	maxPixels = 480
+)
+
+// Preview writes the image at path to w using p, scaled to fit within
+// cols by rows terminal cells.
+func Preview(w io.Writer, path string, p Protocol, cols, rows int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	src, _, err := image.Decode(f)
+	if err != nil {
+		return fmt.Errorf("viewer: %s is not a supported image: %w", path, err)
+	}
+	img := fit(src, min(cols*cellWidth, maxPixels), min(rows*cellHeight, maxPixels))
+
+	switch p {
+	case Kitty, ITerm2:
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		data := base64.StdEncoding.EncodeToString(buf.Bytes())
+		c := (img.Bounds().Dx() + cellWidth - 1) / cellWidth
+		r := (img.Bounds().Dy() + cellHeight - 1) / cellHeight
+		if p == Kitty {
+			return writeKitty(w, data, c, r)
+		}
+		_, err := fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a\n",
+			buf.Len(), c, r, data)
+		return err
+	case Sixel:
+		return writeSixel(w, img)
+	}
+	return fmt.Errorf("viewer: no image protocol available")
+}
+
+// Clear removes images left on screen by Preview, where the protocol
+// keeps them beyond the text they were drawn with.
+func Clear(w io.Writer, p Protocol) {
+	if p == Kitty {
+		io.WriteString(w, "\x1b_Ga=d\x1b\\")
+	}
+}
+
+// writeKitty sends a PNG in the chunks the kitty graphics protocol requires.
+func writeKitty(w io.Writer, data string, cols, rows int) error {
+	const chunk = 4096
+	for i := 0; i < len(data); i += chunk {
+		end := min(i+chunk, len(data))
+		more := 0
+		if end < len(data) {
+			more = 1
+		}
+		var err error
+		if i == 0 {
+			_, err = fmt.Fprintf(w, "\x1b_Gf=100,a=T,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, data[i:end])
+		} else {
+			_, err = fmt.Fprintf(w, "\x1b_Gm=%d;%s\x1b\\", more, data[i:end])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// fit scales img down, never up, to fit within width by height using box
+// sampling, which is plenty for a preview.
+func fit(img image.Image, width, height int) *image.RGBA {
+	b := img.Bounds()
+	scale := min(float64(width)/float64(b.Dx()), float64(height)/float64(b.Dy()), 1)
+	w, h := max(int(float64(b.Dx())*scale), 1), max(int(float64(b.Dy())*scale), 1)
+	out := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+(y+1)*b.Dy()/h
+		for x := 0; x < w; x++ {
+			x0, x1 := b.Min.X+x*b.Dx()/w, b.Min.X+(x+1)*b.Dx()/w
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < max(y1, y0+1); sy++ {
+				for sx := x0; sx < max(x1, x0+1); sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+cr, g+cg, bl+cb, a+ca, n+1
+				}
+			}
+			i := out.PixOffset(x, y)
+			out.Pix[i+0] = uint8(r / n >> 8)
+			out.Pix[i+1] = uint8(g / n >> 8)
+			out.Pix[i+2] = uint8(bl / n >> 8)
+			out.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package viewer
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+)
+
+// writeSixel encodes img as sixel graphics using a fixed 6×6×6 colour
+// cube, which is coarse but needs no quantisation pass.
+func writeSixel(w io.Writer, img *image.RGBA) error {
+	bw := bufio.NewWriter(w)
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+
+	index := make([]int, width*height)
+	used := map[int]bool{}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			i := img.PixOffset(x, y)
+			p := img.Pix[i : i+4]
+			c := -1 // transparent
+			if p[3] >= 128 {
+				c = cube(p[0])*36 + cube(p[1])*6 + cube(p[2])
+				used[c] = true
+			}
+			index[y*width+x] = c
+		}
+	}
+
+	fmt.Fprintf(bw, "\x1bPq\"1;1;%d;%d", width, height)
+	for c := range 216 {
+		if used[c] {
+			r, g, bl := c/36, c/6%6, c%6
+			fmt.Fprintf(bw, "#%d;2;%d;%d;%d", c, r*20, g*20, bl*20)
+		}
+	}
+	row := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		colours := map[int]bool{}
+		for y := band; y < min(band+6, height); y++ {
+			for x := 0; x < width; x++ {
+				if c := index[y*width+x]; c >= 0 {
+					colours[c] = true
+				}
+			}
+		}
+		first := true
+		for c := range 216 {
+			if !colours[c] {
+				continue
+			}
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < height; dy++ {
+					if index[(band+dy)*width+x] == c {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+			}
+			if !first {
+				bw.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(bw, "#%d", c)
+			writeRuns(bw, row)
+		}
+		bw.WriteByte('-')
+	}
+	bw.WriteString("\x1b\\\n")
+	return bw.Flush()
+}
+
+// cube maps a channel value to one of six levels.
+func cube(v uint8) int {
+	return (int(v)*5 + 127) / 255
+}
+
+// writeRuns writes sixel characters with run-length encoding.
+func writeRuns(w *bufio.Writer, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(w, "!%d%c", n, row[i])
+		} else {
+			for ; i < j; i++ {
+				w.WriteByte(row[i])
+			}
+		}
+		i = j
+	}
+}
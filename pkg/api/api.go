@@ -0,0 +1,1229 @@
+// Package api defines the JSON documents nomadic commands print with
+// --output json and `nomadic serve` answers with. The schemas are stable: within a Version fields are only
+// ever added, never renamed or removed, so scripts can rely on them.
+//
+// Calendar days, such as a trip's start, are "YYYY-MM-DD" strings whatever
+// the configured date format; moments, such as when an entry was written,
+// are RFC 3339 timestamps. Money is a number in the currency named next to
+// it.
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/girdharshubham/nomadic/pkg/weather"
+)
+
+// Version is the version of the schemas in this package.
+const Version = 1
+
+// Trip is a trip, as listed by `nomadic trip list`.
+type Trip struct {
+	ID              string             `json:"id"`
+	Title           string             `json:"title"`
+	Locations       []string           `json:"locations"`
+	StartDate       string             `json:"start_date"`
+	EndDate         string             `json:"end_date,omitempty"`
+	Days            int                `json:"days,omitempty"` // length in calendar days when it has an end
+	Budget          float64            `json:"budget,omitempty"`
+	BudgetCurrency  string             `json:"budget_currency,omitempty"`
+	CategoryBudgets map[string]float64 `json:"category_budgets,omitempty"`
+	PerDiem         float64            `json:"per_diem,omitempty"` // daily allowance in BudgetCurrency
+	Notes           string             `json:"notes,omitempty"`
+	Tags            []string           `json:"tags"`
+	Companions      []string           `json:"companions"`
+	ArchivedAt      *time.Time         `json:"archived_at,omitempty"`
+	Rating          int                `json:"rating,omitempty"` // 1 to 5
+	Public          bool               `json:"public,omitempty"` // published by nomadic publish
+	Yearly          string             `json:"yearly,omitempty"` // recurring or anniversary
+	Fields          map[string]string  `json:"fields,omitempty"` // custom trip fields by key
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// Occasion is a day a trip marked yearly comes round, as listed by
+// `nomadic upcoming`: an anniversary of it, Years years on, or the time to
+// take a recurring trip again.
+type Occasion struct {
+	TripID string `json:"trip_id"`
+	Title  string `json:"title"`
+	Kind   string `json:"kind"` // recurring or anniversary
+	Day    string `json:"day"`
+	In     int    `json:"in_days"`
+	Years  int    `json:"years"`
+	Text   string `json:"text"`
+}
+
+// Leg is a stop of a multi-destination trip: where the traveler arrives,
+// when, and how.
+type Leg struct {
+	ID        string `json:"id"`
+	TripID    string `json:"trip_id"`
+	Location  string `json:"location"`
+	Arrival   string `json:"arrival"`
+	Departure string `json:"departure,omitempty"`
+	Transport string `json:"transport,omitempty"`
+}
+
+// ItineraryItem is an activity planned on a day of a trip.
+type ItineraryItem struct {
+	ID         string `json:"id"`
+	TripID     string `json:"trip_id"`
+	Day        string `json:"day"`
+	Time       string `json:"time,omitempty"`
+	Title      string `json:"title"`
+	Place      string `json:"place,omitempty"`
+	Notes      string `json:"notes,omitempty"`
+	BookingRef string `json:"booking_ref,omitempty"`
+	Alarm      int    `json:"alarm,omitempty"`    // minutes before Time
+	Duration   int    `json:"duration,omitempty"` // minutes planned
+	Kind       string `json:"kind,omitempty"`
+	Started    string `json:"started,omitempty"`
+	Finished   string `json:"finished,omitempty"`
+	// Took is how many minutes the activity took, once started and
+	// finished, and Overran how many more than Duration.
+	Took     *int `json:"took,omitempty"`
+	Overran  *int `json:"overran,omitempty"`
+	Position int  `json:"position"`
+}
+
+// KindTiming sums up the timed activities of a kind, as listed by
+// `nomadic itinerary timing`: how many minutes in all they were planned
+// to take and took, how many ran over, and how many minutes late in all
+// the Scheduled ones, those with a time, started.
+type KindTiming struct {
+	Kind      string `json:"kind"`
+	Items     int    `json:"items"`
+	Planned   int    `json:"planned"`
+	Took      int    `json:"took"`
+	Over      int    `json:"over"`
+	Scheduled int    `json:"scheduled"`
+	Late      int    `json:"late"`
+}
+
+// Expense is money spent on a trip. Date and Timestamp are in TimeZone,
+// the IANA time zone where it was spent, if known.
+type Expense struct {
+	ID        string    `json:"id"`
+	TripID    string    `json:"trip_id"`
+	LegID     string    `json:"leg_id,omitempty"`
+	Date      string    `json:"date"`
+	Timestamp time.Time `json:"timestamp"`
+	TimeZone  string    `json:"time_zone,omitempty"`
+	Amount    float64   `json:"amount"`
+	Currency  string    `json:"currency"`
+	// Tax and Tip are the parts of Amount that went on tax and on a tip,
+	// omitted when not broken down.
+	Tax         float64  `json:"tax,omitempty"`
+	Tip         float64  `json:"tip,omitempty"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Note        string   `json:"note,omitempty"`
+	Tags        []string `json:"tags"`
+	// Location is the city where the money was spent, if noted.
+	Location string `json:"location,omitempty"`
+	// PaidBy is the companion who paid, omitted when the traveler did.
+	PaidBy string `json:"paid_by,omitempty"`
+	// Shares splits the amount among the people it was spent on, omitted
+	// when the expense is the payer's own.
+	Shares []Share `json:"shares,omitempty"`
+	// Merchant is the transaction text of an expense imported from a bank
+	// statement.
+	Merchant string `json:"merchant,omitempty"`
+	// RecurrenceID is the recurring expense it was recorded from, if any.
+	RecurrenceID string `json:"recurrence_id,omitempty"`
+	// Rate is how many units of RateCurrency one unit of Currency bought
+	// on the expense's day, omitted while none is locked in.
+	Rate         float64 `json:"rate,omitempty"`
+	RateCurrency string  `json:"rate_currency,omitempty"`
+}
+
+// Recurrence is a recurring expense, recorded on the trip TripID, or the
+// trip in progress when omitted, each day it falls due. Interval is
+// "daily", "weekly", "monthly" or "yearly". NextDate is when it next
+// falls due, omitted once it has ended.
+type Recurrence struct {
+	ID          string  `json:"id"`
+	TripID      string  `json:"trip_id,omitempty"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+	Category    string  `json:"category"`
+	Description string  `json:"description"`
+	Interval    string  `json:"interval"`
+	StartDate   string  `json:"start_date"`
+	EndDate     string  `json:"end_date,omitempty"`
+	NextDate    string  `json:"next_date,omitempty"`
+	Paused      bool    `json:"paused"`
+}
+
+// Share is one person's part of a shared expense, in the expense's
+// currency. The traveler is "me".
+type Share struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// Entry is a journal entry. Text is Markdown. Date and Timestamp are in
+// TimeZone, the IANA time zone where it was written, if known.
+type Entry struct {
+	ID        string       `json:"id"`
+	TripID    string       `json:"trip_id"`
+	LegID     string       `json:"leg_id,omitempty"`
+	Title     string       `json:"title"`
+	Date      string       `json:"date"`
+	Timestamp time.Time    `json:"timestamp"`
+	TimeZone  string       `json:"time_zone,omitempty"`
+	Location  string       `json:"location,omitempty"`
+	Weather   *weather.Day `json:"weather,omitempty"`
+	Mood      int          `json:"mood,omitempty"` // 1 (awful) to 5 (great)
+	People    []string     `json:"people,omitempty"`
+	Public    bool         `json:"public,omitempty"`
+	Private   bool         `json:"private,omitempty"`
+	Tags      []string     `json:"tags"`
+	Text      string       `json:"text"`
+}
+
+// Revision is an earlier version of a journal entry, kept when it was
+// edited. Inserted and Deleted count the lines the edit saving it changed
+// from the version before.
+type Revision struct {
+	ID       string    `json:"id"`
+	EntryID  string    `json:"entry_id"`
+	Title    string    `json:"title"`
+	SavedAt  time.Time `json:"saved_at"`
+	Location string    `json:"location,omitempty"`
+	Mood     int       `json:"mood,omitempty"`
+	Tags     []string  `json:"tags"`
+	Text     string    `json:"text"`
+	Inserted int       `json:"inserted"`
+	Deleted  int       `json:"deleted"`
+}
+
+// Attachment is a file attached to a journal entry. Path is where it can
+// be read: the copy in the data directory, or the original when linked.
+type Attachment struct {
+	ID      string `json:"id"`
+	EntryID string `json:"entry_id"`
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Linked  bool   `json:"linked"`
+	Size    int64  `json:"size"`
+}
+
+// Document is a file kept with a trip, such as a boarding pass or a booking
+// confirmation, and with one of its itinerary items when ItemID is set or
+// as the receipt of one of its expenses when ExpenseID is. Path is where it
+// can be read, as for an Attachment.
+type Document struct {
+	ID          string    `json:"id"`
+	TripID      string    `json:"trip_id"`
+	ItemID      string    `json:"item_id,omitempty"`
+	Item        string    `json:"item,omitempty"` // the item's title
+	ExpenseID   string    `json:"expense_id,omitempty"`
+	Title       string    `json:"title"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type,omitempty"`
+	Path        string    `json:"path"`
+	Linked      bool      `json:"linked"`
+	Size        int64     `json:"size"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Track is an imported GPS track. Distances are in metres and the
+// duration in seconds.
+type Track struct {
+	ID        string     `json:"id"`
+	TripID    string     `json:"trip_id"`
+	EntryID   string     `json:"entry_id,omitempty"`
+	Name      string     `json:"name"`
+	Distance  float64    `json:"distance_m"`
+	Ascent    float64    `json:"ascent_m"`
+	Descent   float64    `json:"descent_m"`
+	Duration  float64    `json:"duration_s"`
+	Points    int        `json:"points"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Place     string     `json:"place,omitempty"`
+}
+
+// CheckIn records being at a place during a trip. Lat and Lon are left
+// out when the position is not known.
+type CheckIn struct {
+	ID        string    `json:"id"`
+	TripID    string    `json:"trip_id"`
+	Place     string    `json:"place"`
+	Note      string    `json:"note,omitempty"`
+	Lat       float64   `json:"lat,omitempty"`
+	Lon       float64   `json:"lon,omitempty"`
+	Date      string    `json:"date"`
+	Timestamp time.Time `json:"timestamp"`
+	TimeZone  string    `json:"time_zone,omitempty"`
+}
+
+// Segment is a journey of a trip by one transport mode. CO2 is the
+// estimated emissions in kilograms of CO₂ equivalent, left out when the
+// mode has no emission factor.
+type Segment struct {
+	ID          string   `json:"id"`
+	TripID      string   `json:"trip_id"`
+	Mode        string   `json:"mode"`
+	Origin      string   `json:"origin,omitempty"`
+	Destination string   `json:"destination,omitempty"`
+	Distance    float64  `json:"distance_km"`
+	CO2         *float64 `json:"co2_kg,omitempty"`
+	Date        string   `json:"date"`
+	Notes       string   `json:"notes,omitempty"`
+}
+
+// Footprint totals the distance covered by transport segments, in
+// kilometres, and their estimated emissions, in kilograms of CO₂
+// equivalent.
+type Footprint struct {
+	Segments    int             `json:"segments"`
+	Distance    float64         `json:"distance_km"`
+	CO2         float64         `json:"co2_kg"`
+	ByMode      []ModeFootprint `json:"by_mode"`
+	Unestimated int             `json:"unestimated"`
+}
+
+// ModeFootprint is the part of a footprint traveled by one mode.
+type ModeFootprint struct {
+	Mode     string  `json:"mode"`
+	Segments int     `json:"segments"`
+	Distance float64 `json:"distance_km"`
+	CO2      float64 `json:"co2_kg"`
+}
+
+// Packing is a trip's packing list and how much of it is packed.
+type Packing struct {
+	TripID string        `json:"trip_id"`
+	Packed int           `json:"packed"`
+	Total  int           `json:"total"`
+	Items  []PackingItem `json:"items"`
+}
+
+// PackingItem is one thing to pack for a trip.
+type PackingItem struct {
+	ID       string `json:"id"`
+	Category string `json:"category,omitempty"`
+	Name     string `json:"name"`
+	Packed   bool   `json:"packed"`
+}
+
+// PackingList is a master packing list.
+type PackingList struct {
+	ID    string            `json:"id"`
+	Name  string            `json:"name"`
+	Items []PackingListItem `json:"items"`
+}
+
+// PackingListItem is an item of a master list or template.
+type PackingListItem struct {
+	Category string `json:"category,omitempty"`
+	Name     string `json:"name"`
+}
+
+// Highlights are a trip's goals, things to do there, and highlights, the
+// goals done: things that happened.
+type Highlights struct {
+	TripID     string      `json:"trip_id"`
+	Done       int         `json:"done"`
+	Total      int         `json:"total"`
+	Highlights []Highlight `json:"highlights"`
+}
+
+// Highlight is a goal of a trip, or once done a highlight of it, with the
+// journal entry that tells of it when linked.
+type Highlight struct {
+	ID         string `json:"id"`
+	Title      string `json:"title"`
+	Done       bool   `json:"done"`
+	EntryID    string `json:"entry_id,omitempty"`
+	EntryTitle string `json:"entry_title,omitempty"`
+}
+
+// PrepTask is something to get done before a trip leaves, due Lead
+// before its first day, such as "T-14d", on Due. In counts the days until
+// then, below zero once overdue.
+type PrepTask struct {
+	ID          string `json:"id"`
+	TripID      string `json:"trip_id"`
+	TripTitle   string `json:"trip_title,omitempty"`
+	Title       string `json:"title"`
+	Lead        string `json:"lead"`
+	LeadMinutes int    `json:"lead_minutes"`
+	Due         string `json:"due"`
+	In          int    `json:"in_days"`
+	Done        bool   `json:"done"`
+}
+
+// PrepTemplate is the preparation a type of trip takes: the tasks added
+// to the trips tagged with Type, or to every trip when it is empty.
+type PrepTemplate struct {
+	ID    string             `json:"id"`
+	Type  string             `json:"type"`
+	Tasks []PrepTemplateTask `json:"tasks"`
+}
+
+// PrepTemplateTask is a task of a prep template.
+type PrepTemplateTask struct {
+	Title       string `json:"title"`
+	Lead        string `json:"lead"`
+	LeadMinutes int    `json:"lead_minutes"`
+}
+
+// Lodging is somewhere stayed on a trip, from check-in to check-out, read
+// in TimeZone. ExpenseID is the expense recording its cost.
+type Lodging struct {
+	ID           string    `json:"id"`
+	TripID       string    `json:"trip_id"`
+	LegID        string    `json:"leg_id,omitempty"`
+	Name         string    `json:"name"`
+	Address      string    `json:"address,omitempty"`
+	Confirmation string    `json:"confirmation,omitempty"`
+	Cost         float64   `json:"cost,omitempty"`
+	Currency     string    `json:"currency,omitempty"`
+	CheckIn      time.Time `json:"check_in"`
+	CheckOut     time.Time `json:"check_out"`
+	TimeZone     string    `json:"time_zone,omitempty"`
+	Nights       int       `json:"nights"`
+	Notes        string    `json:"notes,omitempty"`
+	ExpenseID    string    `json:"expense_id,omitempty"`
+}
+
+// Health is how the days of a trip went for the body, with the jetlag
+// expected from the time zones crossed since leaving HomeZone. Averages
+// are over the days logged.
+type Health struct {
+	TripID        string      `json:"trip_id"`
+	HomeZone      string      `json:"home_zone,omitempty"` // empty for the local zone
+	AverageSleep  float64     `json:"average_sleep,omitempty"`
+	AverageWater  float64     `json:"average_water,omitempty"`
+	AverageJetlag float64     `json:"average_jetlag,omitempty"`
+	Days          []HealthDay `json:"days"`
+}
+
+// HealthDay is a day of a trip: the hours slept, litres drunk and jetlag
+// felt logged for it, from 1 (none) to 5 (badly), the jetlag expected on
+// the same scale from Shift, the hours the clock there runs ahead of the
+// body clock, and the day's average mood.
+type HealthDay struct {
+	Day            string  `json:"day"`
+	Sleep          float64 `json:"sleep,omitempty"`
+	Water          float64 `json:"water,omitempty"`
+	Jetlag         int     `json:"jetlag,omitempty"`
+	ExpectedJetlag float64 `json:"expected_jetlag,omitempty"`
+	Shift          float64 `json:"shift"`
+	Mood           float64 `json:"mood,omitempty"`
+	Note           string  `json:"note,omitempty"`
+}
+
+// HookEvent is the document a command of the [hooks] config table reads
+// on its standard input when its event happens: what the event happened
+// to, with the trip of a journal entry or an expense.
+type HookEvent struct {
+	Event   string    `json:"event"` // entry_saved, trip_created or expense_added
+	Time    time.Time `json:"time"`
+	Trip    *Trip     `json:"trip,omitempty"`
+	Entry   *Entry    `json:"entry,omitempty"`
+	Expense *Expense  `json:"expense,omitempty"`
+	Created bool      `json:"created"` // the entry is new rather than edited
+}
+
+// Template is a saved trip template. Itinerary days count from zero, the
+// first day of the trip.
+type Template struct {
+	ID              string             `json:"id"`
+	Name            string             `json:"name"`
+	Locations       []string           `json:"locations"`
+	Days            int                `json:"days,omitempty"`
+	Budget          float64            `json:"budget,omitempty"`
+	BudgetCurrency  string             `json:"budget_currency,omitempty"`
+	CategoryBudgets map[string]float64 `json:"category_budgets,omitempty"`
+	Notes           string             `json:"notes,omitempty"`
+	Tags            []string           `json:"tags"`
+	Packing         []PackingListItem  `json:"packing"`
+	Itinerary       []TemplateItem     `json:"itinerary"`
+	Legs            []TemplateLeg      `json:"legs"`
+}
+
+// TemplateLeg is a leg of a template, arriving on Day and staying Days.
+type TemplateLeg struct {
+	Day       int    `json:"day"`
+	Days      int    `json:"days,omitempty"`
+	Location  string `json:"location"`
+	Transport string `json:"transport,omitempty"`
+}
+
+// TemplateItem is an itinerary item of a template.
+type TemplateItem struct {
+	Day      int    `json:"day"`
+	Time     string `json:"time,omitempty"`
+	Title    string `json:"title"`
+	Place    string `json:"place,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+	Alarm    int    `json:"alarm,omitempty"`
+	Duration int    `json:"duration,omitempty"`
+	Kind     string `json:"kind,omitempty"`
+}
+
+// Person is someone the traveler goes on trips with. Trips and Entries,
+// the trips they traveled on and the entries written with them, are filled
+// in by `nomadic people show`.
+type Person struct {
+	ID      string  `json:"id"`
+	Name    string  `json:"name"`
+	Contact string  `json:"contact,omitempty"`
+	Notes   string  `json:"notes,omitempty"`
+	Trips   []Trip  `json:"trips,omitempty"`
+	Entries []Entry `json:"entries,omitempty"`
+}
+
+// CountryNote is what to know before going to a country. Country is its
+// ISO 3166-1 alpha-2 code and Name its English name; MaxStay is in days.
+type CountryNote struct {
+	Country string `json:"country"`
+	Name    string `json:"name"`
+	Visa    string `json:"visa,omitempty"`
+	MaxStay int    `json:"max_stay,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+	// Connectivity are the notes on getting online there, as shown by
+	// `nomadic country show`.
+	Connectivity []Connectivity `json:"connectivity,omitempty"`
+}
+
+// Connectivity is a note on getting online in a country, on a leg of a
+// trip or on the country alone: the eSIM or SIM card used, its cost, how
+// fast it was and the Wi-Fi spots worth knowing.
+type Connectivity struct {
+	ID        string    `json:"id"`
+	Country   string    `json:"country"`
+	Name      string    `json:"name"`
+	TripID    string    `json:"trip_id,omitempty"`
+	LegID     string    `json:"leg_id,omitempty"`
+	Place     string    `json:"place,omitempty"`
+	Provider  string    `json:"provider,omitempty"`
+	Cost      float64   `json:"cost,omitempty"`
+	Currency  string    `json:"currency,omitempty"`
+	Speed     string    `json:"speed,omitempty"`
+	WiFi      []string  `json:"wifi,omitempty"`
+	Notes     string    `json:"notes,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Wish is a place on the wishlist. Season lists the months best to go
+// in, such as "Apr-May, Oct", and TripID the trip planned from it, once
+// promoted.
+type Wish struct {
+	ID       string  `json:"id"`
+	Place    string  `json:"place"`
+	Notes    string  `json:"notes,omitempty"`
+	Budget   float64 `json:"budget,omitempty"`
+	Currency string  `json:"currency,omitempty"`
+	Season   string  `json:"season,omitempty"`
+	Priority string  `json:"priority"`
+	TripID   string  `json:"trip_id,omitempty"`
+}
+
+// VaultItem is a travel document kept in the vault. Country is the ISO
+// code of the country a visa is for, and Scan the file name of the scan
+// kept with it, if any. Alert is set when it runs out soon or before a
+// trip.
+type VaultItem struct {
+	ID        string       `json:"id"`
+	Kind      string       `json:"kind"`
+	Title     string       `json:"title"`
+	Holder    string       `json:"holder,omitempty"`
+	Number    string       `json:"number,omitempty"`
+	Issuer    string       `json:"issuer,omitempty"`
+	Country   string       `json:"country,omitempty"`
+	Issued    *time.Time   `json:"issued,omitempty"`
+	Expires   *time.Time   `json:"expires,omitempty"`
+	Note      string       `json:"note,omitempty"`
+	Scan      string       `json:"scan,omitempty"`
+	ScanType  string       `json:"scan_type,omitempty"`
+	ScanSize  int64        `json:"scan_size,omitempty"`
+	Alert     *ExpiryAlert `json:"alert,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// ExpiryAlert says when a travel document runs out: in how many days,
+// below zero once it has, and the trip it runs out before the end of, if
+// any.
+type ExpiryAlert struct {
+	InDays  int    `json:"in_days"`
+	Expired bool   `json:"expired"`
+	TripID  string `json:"trip_id,omitempty"`
+	Trip    string `json:"trip,omitempty"`
+}
+
+// Tag is a tag with how often it is used.
+type Tag struct {
+	Name     string `json:"name"`
+	Trips    int    `json:"trips"`
+	Entries  int    `json:"entries"`
+	Expenses int    `json:"expenses"`
+}
+
+// Tagged holds what carries a set of tags.
+type Tagged struct {
+	Tags     []string  `json:"tags"`
+	Trips    []Trip    `json:"trips"`
+	Entries  []Entry   `json:"entries"`
+	Expenses []Expense `json:"expenses"`
+}
+
+// Budget is a trip's spending against its budgets, in Currency.
+type Budget struct {
+	TripID   string `json:"trip_id"`
+	Currency string `json:"currency"`
+	// Total is the trip budget; its limit is zero when none is set.
+	Total      BudgetLine   `json:"total"`
+	Categories []BudgetLine `json:"categories"`
+	// Projected is the expected spend at the end of the trip at DailyBurn,
+	// omitted when there is too little spending to tell.
+	Projected *float64 `json:"projected,omitempty"`
+	DailyBurn *float64 `json:"daily_burn,omitempty"`
+	// Unconverted counts expenses left out because they could not be
+	// converted into Currency.
+	Unconverted int `json:"unconverted"`
+	// PerDiem is the spending against the daily allowance, omitted when
+	// the trip has none.
+	PerDiem *PerDiem `json:"per_diem,omitempty"`
+}
+
+// PerDiem is a trip's spending day by day against its daily allowance,
+// from its first day through its last or today. Balances above zero are
+// under the allowance, below zero over it.
+type PerDiem struct {
+	TripID    string       `json:"trip_id"`
+	Currency  string       `json:"currency"`
+	Allowance float64      `json:"allowance"`
+	Balance   float64      `json:"balance"`
+	Days      []PerDiemDay `json:"days"`
+	// Unconverted counts expenses left out because they could not be
+	// converted into Currency, Outside those dated on none of Days.
+	Unconverted int `json:"unconverted"`
+	Outside     int `json:"outside"`
+}
+
+// PerDiemDay is the spending of one day against the per diem. Cumulative
+// is the balance of the days up to this one.
+type PerDiemDay struct {
+	Date       string  `json:"date"`
+	Spent      float64 `json:"spent"`
+	Allowance  float64 `json:"allowance"`
+	Balance    float64 `json:"balance"`
+	Cumulative float64 `json:"cumulative"`
+}
+
+// BudgetLine is the spending against one budget. Level is "ok", "warning"
+// or "over".
+type BudgetLine struct {
+	Category string  `json:"category,omitempty"`
+	Limit    float64 `json:"limit"`
+	Spent    float64 `json:"spent"`
+	Used     float64 `json:"used"` // share of the limit spent, 1 is all of it
+	Level    string  `json:"level"`
+}
+
+// ExpenseReport totals a trip's expenses in Currency by category, day or
+// leg, as shown by `nomadic expense report`.
+type ExpenseReport struct {
+	TripID   string              `json:"trip_id"`
+	Currency string              `json:"currency"`
+	By       string              `json:"by"` // "category", "day" or "leg"
+	Rows     []ExpenseReportLine `json:"rows"`
+	Total    float64             `json:"total"`
+	Count    int                 `json:"count"`
+	// Unconverted counts expenses left out because they could not be
+	// converted into Currency.
+	Unconverted int `json:"unconverted"`
+}
+
+// ExpenseReportLine totals the expenses of one category, day or leg. Day
+// is set when grouping by day.
+type ExpenseReportLine struct {
+	Label string     `json:"label"`
+	Day   *time.Time `json:"day,omitempty"`
+	Total float64    `json:"total"`
+	Share float64    `json:"share"` // part of the report's total, 1 is all of it
+	Count int        `json:"count"`
+}
+
+// Settlement settles a trip's shared expenses in Currency, as shown by
+// `nomadic expense settle`.
+type Settlement struct {
+	TripID    string     `json:"trip_id"`
+	Currency  string     `json:"currency"`
+	Balances  []Balance  `json:"balances"`
+	Transfers []Transfer `json:"transfers"`
+	Shared    int        `json:"shared"` // shared expenses counted
+	// Unconverted counts shared expenses left out because they could not
+	// be converted into Currency.
+	Unconverted int `json:"unconverted"`
+}
+
+// Reconciliation compares the expenses logged over From to To with a
+// statement, as shown by `nomadic expense reconcile`. Totals are in
+// Currency; Gap is what the statement charged beyond what was logged.
+type Reconciliation struct {
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Currency  string  `json:"currency"`
+	Statement float64 `json:"statement"`
+	Logged    float64 `json:"logged"`
+	Gap       float64 `json:"gap"`
+	Balanced  bool    `json:"balanced"`
+	Matched   []Match `json:"matched"`
+	// Missing are the charges with no expense logged for them.
+	Missing []Expense `json:"missing"`
+	// Unbilled are the expenses logged that the statement does not show.
+	Unbilled []Expense `json:"unbilled"`
+	// Unconverted counts expenses left out of Logged because they could
+	// not be converted into Currency.
+	Unconverted int `json:"unconverted"`
+}
+
+// Match pairs a statement charge with the expense it was logged as.
+type Match struct {
+	Charge  Expense `json:"charge"`
+	Expense Expense `json:"expense"`
+}
+
+// Balance is what one person paid for shared expenses against their
+// shares of them. A positive net is owed to them, a negative one they owe.
+type Balance struct {
+	Name  string  `json:"name"`
+	Paid  float64 `json:"paid"`
+	Share float64 `json:"share"`
+	Net   float64 `json:"net"`
+}
+
+// Transfer is a payment that settles debts.
+type Transfer struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Amount float64 `json:"amount"`
+}
+
+// Stats are the numbers across every trip shown by `nomadic stats`, with
+// money in Currency.
+type Stats struct {
+	Currency     string   `json:"currency"`
+	Trips        int      `json:"trips"`
+	Destinations []Count  `json:"destinations"` // most visited first
+	Countries    []Count  `json:"countries"`    // most visited first
+	DaysTraveled int      `json:"days_traveled"`
+	Longest      *TripRef `json:"longest_trip,omitempty"`
+
+	TotalSpend      float64  `json:"total_spend"`
+	AverageDaily    float64  `json:"average_daily"`
+	SpendByYear     []Amount `json:"spend_by_year"`
+	SpendByCategory []Amount `json:"spend_by_category"`
+	Unconverted     int      `json:"unconverted"`
+
+	Footprint Footprint `json:"footprint"`
+
+	// Moods lists the trips with a rating or rated entries, most recently
+	// started first.
+	Moods []TripMood `json:"moods"`
+
+	Writing Writing `json:"writing"`
+
+	Achievements []Achievement `json:"achievements"`
+}
+
+// TripComparison sets trips side by side, as `nomadic trip compare` shows
+// them, with money in Currency.
+type TripComparison struct {
+	Currency string        `json:"currency"`
+	Trips    []TripFigures `json:"trips"`
+}
+
+// TripFigures are the numbers of one trip of a TripComparison. Trip.Days
+// is its length, or the days traveled so far when it has no end date.
+type TripFigures struct {
+	Trip   TripRef `json:"trip"`
+	Spend  float64 `json:"spend"`
+	PerDay float64 `json:"per_day"`
+	// Categories lists what was spent in each category, in their order,
+	// categories nothing was spent in omitted.
+	Categories  []Amount `json:"categories"`
+	Entries     int      `json:"entries"`
+	DistanceKm  float64  `json:"distance_km"`
+	TrackedM    float64  `json:"tracked_m"`
+	Unconverted int      `json:"unconverted"`
+}
+
+// Spending is what was spent across every trip over a period, shown by
+// `nomadic report`, with money in Currency.
+type Spending struct {
+	Currency string `json:"currency"`
+	// Period is a year (2024), a month (2024-03) or all.
+	Period string  `json:"period"`
+	Total  float64 `json:"total"`
+	// Previous is the spend of the same period a year before, omitted
+	// for all.
+	Previous float64       `json:"previous,omitempty"`
+	Expenses int           `json:"expenses"`
+	Months   []PeriodSpend `json:"months"`
+	// Years is set for all.
+	Years       []PeriodSpend `json:"years,omitempty"`
+	Categories  []Amount      `json:"categories"` // most first
+	Trips       []Amount      `json:"trips"`      // most first
+	Unconverted int           `json:"unconverted"`
+}
+
+// Digest sums up the journal for the people at home, as compiled by
+// `nomadic digest`: the entries written from Since up to Until, what was
+// spent then in Currency, and the itinerary from Until up to Ahead.
+type Digest struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	Ahead time.Time `json:"ahead"`
+	// Entries leave out the private ones and private passages.
+	Entries     []Entry         `json:"entries"`
+	Currency    string          `json:"currency"`
+	Total       float64         `json:"total"`
+	Expenses    int             `json:"expenses"`
+	Categories  []Amount        `json:"categories"` // most first
+	Unconverted int             `json:"unconverted"`
+	Upcoming    []ItineraryItem `json:"upcoming"`
+	// Sent lists who the digest was mailed to, with --send.
+	Sent []string `json:"sent,omitempty"`
+}
+
+// PeriodSpend is the spend of a month (YYYY-MM) or a year against the same
+// one a year before.
+type PeriodSpend struct {
+	Period   string  `json:"period"`
+	Total    float64 `json:"total"`
+	Previous float64 `json:"previous"`
+	Expenses int     `json:"expenses"`
+}
+
+// TripMood is how a trip felt: its rating and the mood of each day with
+// rated entries, averaged over the day's entries.
+type TripMood struct {
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	Rating  int       `json:"rating,omitempty"`
+	Average float64   `json:"average_mood,omitempty"`
+	Days    []DayMood `json:"days"`
+}
+
+// DayMood is the average mood of one day's rated entries.
+type DayMood struct {
+	Date    string  `json:"date"`
+	Mood    float64 `json:"mood"`
+	Entries int     `json:"entries"`
+}
+
+// Writing sums up what was written in the journal, in words.
+type Writing struct {
+	Entries      int     `json:"entries"`
+	Words        int     `json:"words"`
+	AverageWords float64 `json:"average_words"`
+	ByTrip       []Words `json:"by_trip"`  // most first
+	ByMonth      []Words `json:"by_month"` // as 2006-01, oldest first
+	Longest      *Words  `json:"longest_entry,omitempty"`
+}
+
+// Words is how many words were written on a trip, in a month or in one
+// entry, which ID is set for.
+type Words struct {
+	ID    string `json:"id,omitempty"`
+	Label string `json:"label"`
+	Words int    `json:"words"`
+}
+
+// Achievement is a milestone of the traveler's record, such as 10
+// countries visited: Progress counts toward Goal, in Unit. UnlockedAt is
+// set once the TUI announced it unlocked.
+type Achievement struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	Goal       int        `json:"goal"`
+	Progress   int        `json:"progress"`
+	Unit       string     `json:"unit"`
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// Count is how many trips went somewhere.
+type Count struct {
+	Name  string `json:"name"`
+	Trips int    `json:"trips"`
+}
+
+// Amount is a labelled sum of money.
+type Amount struct {
+	Label  string  `json:"label"`
+	Amount float64 `json:"amount"`
+}
+
+// TripRef names a trip.
+type TripRef struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Days  int    `json:"days"`
+}
+
+// Streak is the journaling streak on the trip in progress, as reported by
+// `nomadic remind`. The trip is omitted when none is in progress. Current
+// counts the consecutive days with an entry up to today, or up to
+// yesterday while Due.
+type Streak struct {
+	TripID       string `json:"trip_id,omitempty"`
+	TripTitle    string `json:"trip_title,omitempty"`
+	Current      int    `json:"current"`
+	Longest      int    `json:"longest"`
+	WrittenToday bool   `json:"written_today"`
+	Due          bool   `json:"due"` // today's entry is missing
+}
+
+// Place is a city of the offline places dataset. Distance, in metres, is
+// only set when looking up the place nearest to a position.
+type Place struct {
+	Name        string   `json:"name"`
+	Country     string   `json:"country"` // ISO 3166-1 alpha-2 code
+	CountryName string   `json:"country_name"`
+	Lat         float64  `json:"lat"`
+	Lon         float64  `json:"lon"`
+	Timezone    string   `json:"timezone"`
+	Distance    *float64 `json:"distance_m,omitempty"`
+}
+
+// Airport is an airport of the offline airports dataset. Distance, in
+// kilometres, is only set when looking up the flight between two.
+type Airport struct {
+	Code        string   `json:"code"` // IATA code
+	Name        string   `json:"name"`
+	City        string   `json:"city"`
+	Country     string   `json:"country"` // ISO 3166-1 alpha-2 code
+	CountryName string   `json:"country_name"`
+	Lat         float64  `json:"lat"`
+	Lon         float64  `json:"lon"`
+	Timezone    string   `json:"timezone"`
+	Distance    *float64 `json:"distance_km,omitempty"`
+}
+
+// Import reports an expense import. With DryRun set nothing was saved.
+type Import struct {
+	DryRun     bool         `json:"dry_run"`
+	Imported   []Expense    `json:"imported"`
+	Duplicates []ImportLine `json:"duplicates"`
+	Failed     []ImportLine `json:"failed"`
+	// Statement imports only: charges a rule categorized and credits, such
+	// as refunds, left out.
+	Categorized int `json:"categorized,omitempty"`
+	Credits     int `json:"credits,omitempty"`
+}
+
+// DumpImport reports a dump imported by `nomadic import`: how many
+// records of each kind, such as "entry", were added, replaced the data
+// directory's own or were kept as it had them. Backup is the backup of the
+// database taken first.
+type DumpImport struct {
+	DryRun    bool           `json:"dry_run"`
+	Overwrite bool           `json:"overwrite"`
+	Backup    string         `json:"backup,omitempty"`
+	Added     map[string]int `json:"added"`
+	Replaced  map[string]int `json:"replaced"`
+	Kept      map[string]int `json:"kept"`
+}
+
+// Demo reports a journal made up by `nomadic demo --dir`: where it is, the
+// seed it was made up from and how many records of each kind it has.
+type Demo struct {
+	Dir   string         `json:"dir"`
+	Seed  int64          `json:"seed"`
+	Added map[string]int `json:"added"`
+}
+
+// Duplicates lists expenses that look like duplicates of each other, as
+// found by `nomadic expense duplicates`. With Merged set each group was
+// merged into its Kept expense and the others moved to the trash.
+type Duplicates struct {
+	Merged bool             `json:"merged"`
+	Groups []DuplicateGroup `json:"groups"`
+}
+
+// DuplicateGroup is expenses on one trip that look like the same purchase,
+// the one recorded first, Kept, first.
+type DuplicateGroup struct {
+	Trip     string    `json:"trip"`
+	Kept     string    `json:"kept"`
+	Expenses []Expense `json:"expenses"`
+}
+
+// Flights reports flights read from a booking confirmation into a trip's
+// itinerary. With DryRun set nothing was saved.
+type Flights struct {
+	DryRun bool            `json:"dry_run"`
+	Added  []ItineraryItem `json:"added"`
+	// Duplicates are flights already in the itinerary.
+	Duplicates []ItineraryItem `json:"duplicates"`
+}
+
+// ImportLine is a CSV line that was not imported, with the expense it
+// duplicates or the reason it failed.
+type ImportLine struct {
+	Line    int      `json:"line"`
+	Expense *Expense `json:"expense,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	// DuplicateOf is the ID of the expense a duplicate line is merged into.
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+}
+
+// Rule categorizes statement transactions whose merchant text contains
+// Match. Learned rules were remembered from a corrected category.
+type Rule struct {
+	ID       string `json:"id"`
+	Match    string `json:"match"`
+	Category string `json:"category"`
+	TripID   string `json:"trip_id,omitempty"`
+	Learned  bool   `json:"learned"`
+}
+
+// Category is an expense category, with how many expenses are in it.
+// Colour is an ANSI number (0-255) or #rgb/#rrggbb; Position counts from
+// zero in display order.
+type Category struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Icon     string `json:"icon,omitempty"`
+	Colour   string `json:"colour,omitempty"`
+	Position int    `json:"position"`
+	Expenses int    `json:"expenses"`
+}
+
+// CategoryMerge is the result of merging the category From into Into:
+// Moved expenses changed category, and From is gone.
+type CategoryMerge struct {
+	From  string `json:"from"`
+	Into  string `json:"into"`
+	Moved int    `json:"moved"`
+}
+
+// RateLocking reports `nomadic expense rates`: the expenses whose rate into
+// Currency, the home currency, on their day was locked in, and those whose
+// rate could not be had.
+type RateLocking struct {
+	Currency string       `json:"currency"`
+	Locked   []Expense    `json:"locked"`
+	Failed   []RateFailed `json:"failed"`
+}
+
+// RateFailed is an expense whose rate could not be locked, and why.
+type RateFailed struct {
+	Expense Expense `json:"expense"`
+	Error   string  `json:"error"`
+}
+
+// Conversion reports `nomadic convert`: Amount of From converted to To at
+// Rate, from the exchange rates published on Date. Stale is set when they
+// were cached and could not be refreshed.
+type Conversion struct {
+	Amount float64 `json:"amount"`
+	From   string  `json:"from"`
+	Result float64 `json:"result"`
+	To     string  `json:"to"`
+	Rate   float64 `json:"rate"`
+	Date   string  `json:"date"`
+	Stale  bool    `json:"stale,omitempty"`
+}
+
+// SearchResults is what `nomadic search` found: the journal entries, best
+// matches first, and the expenses, newest first.
+type SearchResults struct {
+	Entries  []Entry   `json:"entries"`
+	Expenses []Expense `json:"expenses"`
+}
+
+// Deleted names something a command deleted. Kind is "track", "leg",
+// "rule", "template", "packing_list", "person", "checkin", "recurrence",
+// "segment" or "country_note", whose ID is the country code.
+type Deleted struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// Trashed is a deleted trip, journal entry or expense in the trash, as
+// listed by `nomadic trash list`. Kind is "trip", "entry" or "expense".
+type Trashed struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Title     string    `json:"title"`
+	TripID    string    `json:"trip_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// NotesSync reports how the journal's Markdown notes in Dir, kept with
+// journal_storage = markdown, were last brought together with its entries.
+type NotesSync struct {
+	Dir      string   `json:"dir"`
+	Imported int      `json:"imported"` // new notes made entries
+	Updated  int      `json:"updated"`  // entries updated from notes edited
+	Removed  int      `json:"removed"`  // entries or notes removed along with the other
+	Written  int      `json:"written"`  // notes written for entries without one
+	Skipped  []string `json:"skipped"`  // notes that could not be read, with why
+}
+
+// Setting is a config setting.
+type Setting struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Config is every setting with where it is stored.
+type Config struct {
+	Path     string    `json:"path"`
+	Settings []Setting `json:"settings,omitempty"` // omitted by `config path`
+}
+
+// Profile is a profile: settings overriding those of the config file,
+// with a journal of its own in DataDir.
+type Profile struct {
+	Name     string    `json:"name"`
+	DataDir  string    `json:"data_dir"`
+	Current  bool      `json:"current"`
+	Settings []Setting `json:"settings,omitempty"` // those the profile overrides
+}
+
+// Encryption tells whether the database in DataDir is encrypted.
+type Encryption struct {
+	DataDir   string `json:"data_dir"`
+	Encrypted bool   `json:"encrypted"`
+}
+
+// Backup is an archive written by `nomadic backup` or restored by `nomadic
+// restore`.
+type Backup struct {
+	Path    string    `json:"path"`
+	Created time.Time `json:"created"`
+	// SchemaVersion is 0 when the database in the backup is encrypted.
+	SchemaVersion int   `json:"schema_version,omitempty"`
+	Encrypted     bool  `json:"encrypted"` // sealed with a passphrase
+	DatabaseOnly  bool  `json:"database_only,omitempty"`
+	Files         int   `json:"files"`
+	Size          int64 `json:"size"`
+	// Replaced is the backup restore made of the data it replaced.
+	Replaced string `json:"replaced,omitempty"`
+	// Removed are the older remote backups removed to keep backup_keep.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// RemoteBackup is a backup uploaded to backup_remote.
+type RemoteBackup struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// Schema is the schema version of the database and the migrations that
+// bring it up to the latest this version of nomadic knows, as reported by
+// `nomadic migrate`. Applied lists the migrations run, on a copy of the
+// database when DryRun is set.
+type Schema struct {
+	Version int         `json:"version"`
+	Latest  int         `json:"latest"`
+	Pending []Migration `json:"pending"`
+	Applied []Migration `json:"applied,omitempty"`
+	DryRun  bool        `json:"dry_run,omitempty"`
+	// Backup is the backup of the database taken before migrating it.
+	Backup string `json:"backup,omitempty"`
+}
+
+// Migration is a change of the database schema, by the version it brings
+// the database to.
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+}
+
+// SyncResult is what `nomadic sync` did.
+type SyncResult struct {
+	Committed bool `json:"committed"`
+	Pulled    int  `json:"pulled"`
+	Pushed    int  `json:"pushed"`
+}
+
+// SyncMerge is what `nomadic sync merge` did.
+type SyncMerge struct {
+	Device string `json:"device"`
+	// Recorded counts the records changed on this device since the last
+	// sync, and Changed and Deleted those the merge wrote.
+	Recorded  int            `json:"recorded"`
+	Pulled    int            `json:"pulled"`
+	Changed   int            `json:"changed"`
+	Deleted   int            `json:"deleted"`
+	Pushed    bool           `json:"pushed"`
+	Conflicts []SyncConflict `json:"conflicts"`
+}
+
+// SyncConflict is a change a sync merge lost to another device's: to a
+// field both changed, or to a record one of them deleted.
+type SyncConflict struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Field is empty when the record was deleted.
+	Field  string          `json:"field,omitempty"`
+	Kept   json.RawMessage `json:"kept,omitempty"`
+	Lost   json.RawMessage `json:"lost,omitempty"`
+	KeptBy string          `json:"kept_by"`
+	LostBy string          `json:"lost_by"`
+	At     time.Time       `json:"at"`
+}
+
+// SyncStatus is what waits to be synced.
+type SyncStatus struct {
+	Dir        string     `json:"dir"`
+	AutoSync   string     `json:"auto_sync"`
+	HasRemote  bool       `json:"has_remote"`
+	Ahead      int        `json:"ahead"`
+	Behind     int        `json:"behind"`
+	Dirty      bool       `json:"dirty"`
+	LastCommit *time.Time `json:"last_commit,omitempty"`
+}
+
+// DaemonStatus is what `nomadic daemon` is doing, or last did.
+type DaemonStatus struct {
+	Running   bool        `json:"running"`
+	PID       int         `json:"pid"`
+	Started   time.Time   `json:"started"`
+	Heartbeat time.Time   `json:"heartbeat"`
+	Stopped   *time.Time  `json:"stopped,omitempty"`
+	Jobs      []DaemonJob `json:"jobs"`
+}
+
+// DaemonJob is how a job of the daemon last went.
+type DaemonJob struct {
+	Name    string     `json:"name"`
+	Every   string     `json:"every"` // a Go duration, such as "15m0s"
+	LastRun *time.Time `json:"last_run,omitempty"`
+	Result  string     `json:"result,omitempty"`
+	Error   string     `json:"error,omitempty"`
+	NextRun *time.Time `json:"next_run,omitempty"`
+}
+
+// Site is the website written by `nomadic publish`.
+type Site struct {
+	Dir     string   `json:"dir"`
+	Trips   int      `json:"trips"`
+	Entries int      `json:"entries"`
+	Photos  int      `json:"photos"`
+	Missing []string `json:"missing"` // photos left out because their file is gone
+}
+
+// Error is the body of a `nomadic serve` response that failed, with the
+// reason.
+type Error struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,109 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Cache wraps a Provider with an on-disk JSON cache. Fresh cached rates are
+// served without touching the network; expired ones are refreshed, and
+// served marked Stale if the refresh fails, so conversion keeps working
+// offline.
+type Cache struct {
+	Path     string
+	TTL      time.Duration
+	Provider Provider
+}
+
+// NewCache returns a Cache storing its rates file at path.
+func NewCache(path string, ttl time.Duration, p Provider) *Cache {
+	return &Cache{Path: path, TTL: ttl, Provider: p}
+}
+
+// Latest implements Provider.
+func (c *Cache) Latest(ctx context.Context, base string) (*Rates, error) {
+	base = strings.ToUpper(base)
+	cached, _ := c.load()
+	r, hit := cached[base]
+	if hit && time.Since(r.Fetched) < c.TTL {
+		return r, nil
+	}
+
+	fresh, err := c.Provider.Latest(ctx, base)
+	if err != nil {
+		if hit {
+			r.Stale = true
+			return r, nil
+		}
+		return nil, err
+	}
+	if cached == nil {
+		cached = map[string]*Rates{}
+	}
+	cached[base] = fresh
+	// A cache that cannot be written only costs a refetch next time.
+	_ = c.save(cached)
+	return fresh, nil
+}
+
+// On implements Historical. Past rates do not change, so once fetched they
+// are served from the cache for good.
+func (c *Cache) On(ctx context.Context, base string, day time.Time) (*Rates, error) {
+	h, ok := c.Provider.(Historical)
+	if !ok {
+		return nil, fmt.Errorf("currency: no rates for %s", day.Format(DayLayout))
+	}
+	base = strings.ToUpper(base)
+	key := base + "@" + day.Format(DayLayout)
+	cached, _ := c.load()
+	if r, hit := cached[key]; hit {
+		return r, nil
+	}
+	r, err := h.On(ctx, base, day)
+	if err != nil {
+		return nil, err
+	}
+	if cached == nil {
+		cached = map[string]*Rates{}
+	}
+	cached[key] = r
+	_ = c.save(cached)
+	return r, nil
+}
+
+func (c *Cache) load() (map[string]*Rates, error) {
+	data, err := os.ReadFile(c.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var rates map[string]*Rates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+func (c *Cache) save(rates map[string]*Rates) error {
+	data, err := json.MarshalIndent(rates, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o700); err != nil {
+		return err
+	}
+	tmp := c.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.Path)
+}
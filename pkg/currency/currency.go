@@ -0,0 +1,93 @@
+// Package currency converts amounts between currencies using exchange
+// rates from a pluggable Provider.
+package currency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rates is a table of exchange rates relative to Base: one unit of Base
+// buys Rates[code] units of code.
+type Rates struct {
+	Base    string             `json:"base"`
+	Date    string             `json:"date"`
+	Rates   map[string]float64 `json:"rates"`
+	Fetched time.Time          `json:"fetched"`
+
+	// Stale is set when the rates come from an expired cache because the
+	// provider could not be reached.
+	Stale bool `json:"-"`
+}
+
+// Provider fetches the latest exchange rates for base.
+type Provider interface {
+	Latest(ctx context.Context, base string) (*Rates, error)
+}
+
+// Historical is a Provider that can also fetch the rates for base as they
+// were on a past day.
+type Historical interface {
+	Provider
+	On(ctx context.Context, base string, day time.Time) (*Rates, error)
+}
+
+// DayLayout is how the day of historical rates is written.
+const DayLayout = "2006-01-02"
+
+// RateOn returns how many units of to one unit of from bought on day, the
+// date of day as written in its own location. Days from today on are given
+// the latest rates; earlier ones need p to be Historical.
+func RateOn(ctx context.Context, p Provider, from, to string, day time.Time) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return 1, nil
+	}
+	var (
+		r   *Rates
+		err error
+	)
+	if day.Format(DayLayout) >= time.Now().In(day.Location()).Format(DayLayout) {
+		r, err = p.Latest(ctx, from)
+	} else if h, ok := p.(Historical); ok {
+		r, err = h.On(ctx, from, day)
+	} else {
+		return 0, fmt.Errorf("currency: no rates for %s", day.Format(DayLayout))
+	}
+	if err != nil {
+		return 0, err
+	}
+	if r.Stale {
+		// Rates cached days ago are not the day's.
+		return 0, fmt.Errorf("currency: no rates for %s could be fetched", day.Format(DayLayout))
+	}
+	return r.Convert(1, from, to)
+}
+
+// rate returns how many units of code one unit of Base buys.
+func (r *Rates) rate(code string) (float64, bool) {
+	code = strings.ToUpper(code)
+	if code == strings.ToUpper(r.Base) {
+		return 1, true
+	}
+	v, ok := r.Rates[code]
+	return v, ok && v > 0
+}
+
+// Convert converts amount from one currency to another, crossing through
+// the table's base currency when neither side is the base.
+func (r *Rates) Convert(amount float64, from, to string) (float64, error) {
+	if strings.EqualFold(from, to) {
+		return amount, nil
+	}
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, fmt.Errorf("currency: no rate for %s", from)
+	}
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, fmt.Errorf("currency: no rate for %s", to)
+	}
+	return amount / fromRate * toRate, nil
+}
@@ -0,0 +1,67 @@
+package currency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultFrankfurterURL is the public endpoint of the free Frankfurter API,
+// which republishes European Central Bank reference rates.
+const DefaultFrankfurterURL = "https://api.frankfurter.app"
+
+// Frankfurter is a Provider backed by the Frankfurter API.
+type Frankfurter struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewFrankfurter returns a provider using the public Frankfurter endpoint.
+func NewFrankfurter() *Frankfurter {
+	return &Frankfurter{
+		BaseURL: DefaultFrankfurterURL,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Latest implements Provider.
+func (f *Frankfurter) Latest(ctx context.Context, base string) (*Rates, error) {
+	return f.fetch(ctx, "latest", base)
+}
+
+// On implements Historical. A day the European Central Bank published no
+// rates on, such as a weekend, has those of the working day before.
+func (f *Frankfurter) On(ctx context.Context, base string, day time.Time) (*Rates, error) {
+	return f.fetch(ctx, day.Format(DayLayout), base)
+}
+
+// fetch gets the rates for base at path, "latest" or a day.
+func (f *Frankfurter) fetch(ctx context.Context, path, base string) (*Rates, error) {
+	u := strings.TrimRight(f.BaseURL, "/") + "/" + path + "?from=" + url.QueryEscape(strings.ToUpper(base))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("currency: fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("currency: fetch rates: %s", resp.Status)
+	}
+
+	var body struct {
+		Base  string             `json:"base"`
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("currency: decode rates: %w", err)
+	}
+	return &Rates{Base: body.Base, Date: body.Date, Rates: body.Rates, Fetched: time.Now()}, nil
+}
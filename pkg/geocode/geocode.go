@@ -0,0 +1,25 @@
+// Package geocode finds the coordinates of a named place, such as a
+// landmark or an address, from a pluggable Geocoder.
+package geocode
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when no place matches the query.
+var ErrNotFound = errors.New("geocode: no place found")
+
+// Place is the best match for a query.
+type Place struct {
+	// Name is the place's full name as the geocoder knows it, as in
+	// "Osaka Castle, Chuo Ward, Osaka, Japan".
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// Geocoder looks up the place best matching query.
+type Geocoder interface {
+	Search(ctx context.Context, query string) (*Place, error)
+}
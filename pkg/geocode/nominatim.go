@@ -0,0 +1,74 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DefaultNominatimURL is the search endpoint of OpenStreetMap's public
+// Nominatim service, which needs no key but asks for at most one request
+// a second from an identified client.
+const DefaultNominatimURL = "https://nominatim.openstreetmap.org/search"
+
+// NominatimInterval is the least time between requests to the public
+// Nominatim service that its usage policy allows.
+const NominatimInterval = time.Second
+
+// userAgent identifies nomadic to Nominatim, as its usage policy requires.
+const userAgent = "nomadic (https://github.com/girdharshubham/nomadic)"
+
+// Nominatim is a Geocoder backed by a Nominatim search API.
+type Nominatim struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewNominatim returns a geocoder using the public Nominatim service.
+func NewNominatim() *Nominatim {
+	return &Nominatim{URL: DefaultNominatimURL, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Search implements Geocoder.
+func (n *Nominatim) Search(ctx context.Context, query string) (*Place, error) {
+	q := url.Values{"q": {query}, "format": {"jsonv2"}, "limit": {"1"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.URL+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("geocode: search: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geocode: search: %s", resp.Status)
+	}
+
+	var results []struct {
+		Name string `json:"display_name"`
+		Lat  string `json:"lat"`
+		Lon  string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("geocode: decode: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, ErrNotFound
+	}
+	r := results[0]
+	lat, err := strconv.ParseFloat(r.Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("geocode: decode: latitude %q", r.Lat)
+	}
+	lon, err := strconv.ParseFloat(r.Lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("geocode: decode: longitude %q", r.Lon)
+	}
+	return &Place{Name: r.Name, Lat: lat, Lon: lon}, nil
+}
@@ -0,0 +1,189 @@
+package holidays
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// holidaysCSV holds the rules of the national holidays of the countries
+// travelled to most, one per line as country,rule,name. A rule is one of
+//
+//	MM-DD          a fixed date
+//	easter+N       N days after Easter Sunday, or before with -N
+//	orthodox+N     the same from Orthodox Easter
+//	MM:wd:N        the Nth weekday wd (mon…sun) of the month, the last with -1
+//	MM:wd:>=DD     the first weekday wd on or after day DD of the month
+//	vernal         the day of the March equinox in Japan
+//	autumnal       the day of the September equinox in Japan
+//
+// Holidays following the moon, such as the Lunar New Year, Diwali or Eid,
+// and the days off given in lieu of holidays falling on a weekend, are
+// left to the online calendar.
+//
+//go:embed holidays.csv
+var holidaysCSV []byte
+
+type rule struct {
+	country, rule, name string
+}
+
+var (
+	loadOnce sync.Once
+	rules    map[string][]rule // by country
+)
+
+// load parses the embedded rules. The file is part of the binary, so a
+// malformed rule is a programming error.
+func load() {
+	r := csv.NewReader(bytes.NewReader(holidaysCSV))
+	recs, err := r.ReadAll()
+	if err != nil {
+		panic("holidays: " + err.Error())
+	}
+	rules = map[string][]rule{}
+	for _, rec := range recs[1:] { // header
+		ru := rule{country: rec[0], rule: rec[1], name: rec[2]}
+		if _, err := ru.date(2000); err != nil {
+			panic(err.Error())
+		}
+		rules[ru.country] = append(rules[ru.country], ru)
+	}
+}
+
+// Bundled is the Provider of the rules bundled with nomadic, which needs
+// no network but knows only the holidays kept on the same days, or
+// reckoned from Easter, every year.
+type Bundled struct{}
+
+// Year implements Provider.
+func (Bundled) Year(_ context.Context, country string, year int) ([]Holiday, error) {
+	loadOnce.Do(load)
+	country = normalize(country)
+	rs, ok := rules[country]
+	if !ok {
+		return nil, fmt.Errorf("holidays: none bundled for %s", country)
+	}
+	out := make([]Holiday, 0, len(rs))
+	for _, r := range rs {
+		d, _ := r.date(year)
+		out = append(out, Holiday{Date: d.Format(DayLayout), Country: country, Name: r.name})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Date < out[j].Date })
+	return out, nil
+}
+
+// BundledCountries lists the countries whose holidays are bundled, by
+// code.
+func BundledCountries() []string {
+	loadOnce.Do(load)
+	codes := make([]string, 0, len(rules))
+	for code := range rules {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+var weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// date is the day the rule falls on in year.
+func (r rule) date(year int) (time.Time, error) {
+	bad := fmt.Errorf("holidays: bad rule %q for %s", r.rule, r.country)
+	day := func(m time.Month, d int) time.Time { return time.Date(year, m, d, 0, 0, 0, 0, time.UTC) }
+	switch {
+	case r.rule == "vernal", r.rule == "autumnal":
+		// The days of the equinoxes of the Japanese calendar, good from
+		// 1980 to 2099.
+		base, m := 20.8431, time.March
+		if r.rule == "autumnal" {
+			base, m = 23.2488, time.September
+		}
+		y := year - 1980
+		return day(m, int(base+0.242194*float64(y))-y/4), nil
+	case strings.HasPrefix(r.rule, "easter"), strings.HasPrefix(r.rule, "orthodox"):
+		name, offset, _ := strings.Cut(strings.NewReplacer("+", " +", "-", " -").Replace(r.rule), " ")
+		n := 0
+		if offset != "" {
+			var err error
+			if n, err = strconv.Atoi(offset); err != nil {
+				return time.Time{}, bad
+			}
+		}
+		if name == "easter" {
+			return easter(year).AddDate(0, 0, n), nil
+		}
+		if name == "orthodox" {
+			return orthodoxEaster(year).AddDate(0, 0, n), nil
+		}
+		return time.Time{}, bad
+	case strings.Contains(r.rule, ":"):
+		parts := strings.Split(r.rule, ":")
+		if len(parts) != 3 {
+			return time.Time{}, bad
+		}
+		m, err := strconv.Atoi(parts[0])
+		wd := slices.Index(weekdays, parts[1])
+		if err != nil || m < 1 || m > 12 || wd < 0 {
+			return time.Time{}, bad
+		}
+		if from, ok := strings.CutPrefix(parts[2], ">="); ok {
+			d, err := strconv.Atoi(from)
+			if err != nil {
+				return time.Time{}, bad
+			}
+			first := day(time.Month(m), d)
+			return first.AddDate(0, 0, (wd-int(first.Weekday())+7)%7), nil
+		}
+		n, err := strconv.Atoi(parts[2])
+		if err != nil || n == 0 || n < -1 || n > 5 {
+			return time.Time{}, bad
+		}
+		if n == -1 {
+			last := day(time.Month(m)+1, 0)
+			return last.AddDate(0, 0, -((int(last.Weekday()) - wd + 7) % 7)), nil
+		}
+		first := day(time.Month(m), 1)
+		return first.AddDate(0, 0, (wd-int(first.Weekday())+7)%7+7*(n-1)), nil
+	}
+	t, err := time.Parse("01-02", r.rule)
+	if err != nil {
+		return time.Time{}, bad
+	}
+	return day(t.Month(), t.Day()), nil
+}
+
+// easter is Easter Sunday of year in the Gregorian calendar.
+func easter(year int) time.Time {
+	a, b, c := year%19, year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// orthodoxEaster is Easter Sunday of year as the Orthodox churches keep
+// it, reckoned in the Julian calendar and given in the Gregorian one,
+// thirteen days ahead from 1900 to 2099.
+func orthodoxEaster(year int) time.Time {
+	a, b, c := year%4, year%7, year%19
+	d := (19*c + 15) % 30
+	e := (2*a + 4*b - d + 34) % 7
+	month := (d + e + 114) / 31
+	day := (d+e+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, 13)
+}
@@ -0,0 +1,92 @@
+package holidays
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache wraps a Provider with an on-disk JSON cache. A year's holidays are
+// refetched after TTL, as governments add and move some, and served from
+// the cache if the refetch fails, so the calendar keeps working offline.
+type Cache struct {
+	Path     string
+	TTL      time.Duration
+	Provider Provider
+
+	mu sync.Mutex
+}
+
+type cached struct {
+	Holidays []Holiday `json:"holidays"`
+	Fetched  time.Time `json:"fetched"`
+}
+
+// NewCache returns a Cache storing its file at path.
+func NewCache(path string, ttl time.Duration, p Provider) *Cache {
+	return &Cache{Path: path, TTL: ttl, Provider: p}
+}
+
+// Year implements Provider.
+func (c *Cache) Year(ctx context.Context, country string, year int) ([]Holiday, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := fmt.Sprintf("%s@%d", normalize(country), year)
+	entries, _ := c.load()
+	e, hit := entries[key]
+	if hit && time.Since(e.Fetched) < c.TTL {
+		return e.Holidays, nil
+	}
+
+	fresh, err := c.Provider.Year(ctx, country, year)
+	if err != nil {
+		if hit {
+			return e.Holidays, nil
+		}
+		return nil, err
+	}
+	if entries == nil {
+		entries = map[string]cached{}
+	}
+	entries[key] = cached{Holidays: fresh, Fetched: time.Now()}
+	// A cache that cannot be written only costs a refetch next time.
+	_ = c.save(entries)
+	return fresh, nil
+}
+
+func (c *Cache) load() (map[string]cached, error) {
+	data, err := os.ReadFile(c.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]cached
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (c *Cache) save(entries map[string]cached) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o700); err != nil {
+		return err
+	}
+	tmp := c.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.Path)
+}
@@ -0,0 +1,51 @@
+// Package holidays tells the public holidays of a country in a year, from
+// a pluggable Provider: the rules bundled with nomadic, or an online
+// calendar.
+package holidays
+
+import (
+	"context"
+	"strings"
+)
+
+// DayLayout is how the date of a holiday is written.
+const DayLayout = "2006-01-02"
+
+// Holiday is a public holiday kept across the whole of a country.
+type Holiday struct {
+	Date    string `json:"date"` // YYYY-MM-DD
+	Country string `json:"country"`
+	// Name is the holiday's name in English, and LocalName in the
+	// country's language when the provider knows it.
+	Name      string `json:"name"`
+	LocalName string `json:"local_name,omitempty"`
+}
+
+// Provider lists the public holidays of country, an ISO 3166-1 alpha-2
+// code, in year, by date.
+type Provider interface {
+	Year(ctx context.Context, country string, year int) ([]Holiday, error)
+}
+
+// Fallback is a Provider asking Primary first, and Secondary for the
+// countries and years Primary cannot tell, such as while offline.
+type Fallback struct {
+	Primary, Secondary Provider
+}
+
+// Year implements Provider.
+func (f Fallback) Year(ctx context.Context, country string, year int) ([]Holiday, error) {
+	h, err := f.Primary.Year(ctx, country, year)
+	if err == nil {
+		return h, nil
+	}
+	if fallback, ferr := f.Secondary.Year(ctx, country, year); ferr == nil {
+		return fallback, nil
+	}
+	return nil, err
+}
+
+// normalize upper-cases a country code.
+func normalize(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
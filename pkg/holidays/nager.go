@@ -0,0 +1,68 @@
+package holidays
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultNagerURL is the public endpoint of the free Nager.Date API, which
+// needs no key.
+const DefaultNagerURL = "https://date.nager.at/api/v3"
+
+// Nager is a Provider backed by the Nager.Date API, which knows the
+// holidays of about a hundred countries, those following the moon too.
+type Nager struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewNager returns a provider using the public Nager.Date endpoint.
+func NewNager() *Nager {
+	return &Nager{
+		BaseURL: DefaultNagerURL,
+		Client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Year implements Provider. Holidays of some regions of a country only
+// are left out.
+func (n *Nager) Year(ctx context.Context, country string, year int) ([]Holiday, error) {
+	country = normalize(country)
+	u := strings.TrimRight(n.BaseURL, "/") + "/PublicHolidays/" + strconv.Itoa(year) + "/" + url.PathEscape(country)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("holidays: fetch %s %d: %w", country, year, err)
+	}
+	defer resp.Body.Close()
+	// Countries without data are answered with no content, or not found.
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("holidays: fetch %s %d: %s", country, year, resp.Status)
+	}
+
+	var body []struct {
+		Date      string `json:"date"`
+		LocalName string `json:"localName"`
+		Name      string `json:"name"`
+		Global    bool   `json:"global"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("holidays: decode %s %d: %w", country, year, err)
+	}
+	out := []Holiday{}
+	for _, h := range body {
+		if h.Global {
+			out = append(out, Holiday{Date: h.Date, Country: country, Name: h.Name, LocalName: h.LocalName})
+		}
+	}
+	return out, nil
+}
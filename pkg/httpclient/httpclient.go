@@ -0,0 +1,240 @@
+// Package httpclient is the HTTP client the integrations share: exchange
+// rates, weather, holidays, geocoding and the rest. Requests through it
+// are given up on when they take too long, retried with backoff when the
+// network or the server fails them for a moment, spaced out per host as
+// the services ask, and refused at once in offline mode, so a flaky
+// connection costs seconds rather than hanging whatever waits on it.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrOffline is returned for every request while offline mode is on.
+var ErrOffline = errors.New("offline mode is on")
+
+// Defaults of a Transport's retries.
+const (
+	DefaultRetries = 2
+	DefaultBackoff = 500 * time.Millisecond
+	// MaxBackoff caps the wait before a retry, including one a server
+	// asks for with Retry-After.
+	MaxBackoff = 5 * time.Second
+)
+
+// Transport sends the requests of the clients made from it. Its limits
+// are shared by them all, so two integrations calling the same service
+// keep to its rate between them.
+type Transport struct {
+	// Base sends each attempt; nil is http.DefaultTransport.
+	Base http.RoundTripper
+	// Retries is how many times a request that failed for a moment is
+	// tried again, and Backoff how long is waited before the first retry,
+	// doubling for each one after it.
+	Retries int
+	Backoff time.Duration
+	// Limits is the least time between the requests to a host, by host
+	// name, such as one second for a service that asks for at most one
+	// request a second.
+	Limits map[string]time.Duration
+	// Offline refuses every request with ErrOffline.
+	Offline bool
+
+	mu sync.Mutex
+	// next is when each host limited may be sent its next request.
+	next map[string]time.Time
+}
+
+// New returns a transport with the default retries, on http.DefaultTransport.
+func New() *Transport {
+	return &Transport{Retries: DefaultRetries, Backoff: DefaultBackoff}
+}
+
+// Client returns a client sending its requests through t, giving each
+// attempt at a request at most timeout, reading the response included.
+func (t *Transport) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: &attempts{t: t, timeout: timeout}}
+}
+
+// attempts is the RoundTripper of a client of t, with its timeout.
+type attempts struct {
+	t       *Transport
+	timeout time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (a *attempts) RoundTrip(req *http.Request) (*http.Response, error) {
+	t := a.t
+	if t.Offline {
+		closeBody(req)
+		return nil, ErrOffline
+	}
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(ctx, req.URL.Hostname()); err != nil {
+			closeBody(req)
+			return nil, err
+		}
+		try := req
+		if attempt > 0 {
+			var err error
+			if try, err = rewind(req); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := a.send(try)
+		last := attempt >= t.Retries || !replayable(req) || ctx.Err() != nil
+		if err == nil && !retryStatus(resp.StatusCode) || last {
+			return resp, err
+		}
+		wait := t.Backoff << attempt
+		wait += rand.N(wait/2 + 1)
+		if err == nil {
+			if after, ok := retryAfter(resp); ok {
+				wait = after
+			}
+			// The response is dropped for the retry's.
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, min(wait, MaxBackoff)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send makes one attempt at req within the client's timeout, which runs
+// until the response's body is closed.
+func (a *attempts) send(req *http.Request) (*http.Response, error) {
+	base := a.t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if a.timeout <= 0 {
+		return base.RoundTrip(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), a.timeout)
+	resp, err := base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// wait holds a request to a limited host until its turn, and books the
+// turn after it.
+func (t *Transport) wait(ctx context.Context, host string) error {
+	every, ok := t.Limits[host]
+	if !ok || every <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	if t.next == nil {
+		t.next = map[string]time.Time{}
+	}
+	at := later(t.next[host], time.Now())
+	t.next[host] = at.Add(every)
+	t.mu.Unlock()
+	return sleep(ctx, time.Until(at))
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// replayable reports whether req may be sent again: it changes nothing
+// more the second time, and its body, if any, can be read again.
+func replayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns req with its body from the start, to send it again.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	return r, nil
+}
+
+// retryStatus reports whether a response with the status is worth trying
+// again after a moment: the server is overloaded, or asks to slow down.
+func retryStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter reads how long the server asks to wait, in seconds or until
+// a time.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// closeBody closes the body of a request not sent, as RoundTrip must.
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// cancelBody ends the attempt when the response's body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,125 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the endpoint of the API reader: OCR.space's. Services
+// with the same API work by changing it.
+const DefaultAPIURL = "https://api.ocr.space/parse/image"
+
+// API is a Reader uploading the image to an OCR service with OCR.space's
+// API.
+type API struct {
+	URL    string
+	Key    string
+	Client *http.Client
+}
+
+// NewAPI returns a reader posting to url, authenticated with key. An empty
+// url takes the default.
+func NewAPI(url, key string) *API {
+	if url == "" {
+		url = DefaultAPIURL
+	}
+	return &API{URL: url, Key: key, Client: &http.Client{Timeout: 2 * time.Minute}}
+}
+
+// Read implements Reader.
+func (a *API) Read(ctx context.Context, path, language string) (string, error) {
+	image, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("ocr: %w", err)
+	}
+	defer image.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	// The table engine keeps the lines of receipts together.
+	fields := map[string]string{"isTable": "true", "scale": "true", "OCREngine": "2"}
+	if language != "" {
+		fields["language"] = language
+	}
+	for name, value := range fields {
+		if err := mw.WriteField(name, value); err != nil {
+			return "", err
+		}
+	}
+	part, err := mw.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, image); err != nil {
+		return "", fmt.Errorf("ocr: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if a.Key != "" {
+		req.Header.Set("apikey", a.Key)
+	}
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ocr: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		ParsedResults []struct {
+			ParsedText string `json:"ParsedText"`
+		} `json:"ParsedResults"`
+		IsErroredOnProcessing bool `json:"IsErroredOnProcessing"`
+		// ErrorMessage is a string or a list of them.
+		ErrorMessage json.RawMessage `json:"ErrorMessage"`
+	}
+	err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out)
+	msg := errorMessage(out.ErrorMessage)
+	switch {
+	case resp.StatusCode != http.StatusOK && msg != "":
+		return "", fmt.Errorf("ocr: %s: %s", resp.Status, msg)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("ocr: %s", resp.Status)
+	case err != nil:
+		return "", fmt.Errorf("ocr: decoding response: %w", err)
+	case out.IsErroredOnProcessing && msg != "":
+		return "", fmt.Errorf("ocr: %s", msg)
+	case out.IsErroredOnProcessing:
+		return "", errors.New("ocr: the image could not be read")
+	}
+	var text []string
+	for _, r := range out.ParsedResults {
+		text = append(text, r.ParsedText)
+	}
+	return clean(strings.Join(text, "\n")), nil
+}
+
+// errorMessage reads the error message of a response, a string or a list
+// of them.
+func errorMessage(raw json.RawMessage) string {
+	var one string
+	if json.Unmarshal(raw, &one) == nil {
+		return one
+	}
+	var many []string
+	if json.Unmarshal(raw, &many) == nil {
+		return strings.Join(many, "; ")
+	}
+	return ""
+}
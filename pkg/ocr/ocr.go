@@ -0,0 +1,31 @@
+// Package ocr reads the text in images, such as photos of receipts, with a
+// pluggable Reader: the tesseract binary on this machine or an OCR API.
+package ocr
+
+import (
+	"context"
+	"strings"
+)
+
+// Reader reads the text in the image at path. language is the language of
+// the text as a three-letter code such as eng or por, or empty for the
+// backend's default.
+type Reader interface {
+	Read(ctx context.Context, path, language string) (string, error)
+}
+
+// clean trims the spaces ending the lines of text and the blank lines
+// around it, keeping the lines, which on a receipt are its items.
+func clean(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " \t\f")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// lastLine returns the last line of s, where tools put the error that
+// stopped them.
+func lastLine(s string) string {
+	return s[strings.LastIndexByte(s, '\n')+1:]
+}
@@ -0,0 +1,55 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DefaultTesseractCommand is the name of the Tesseract command-line tool.
+const DefaultTesseractCommand = "tesseract"
+
+// Tesseract is a Reader running Tesseract on this machine, so images never
+// leave it and no connection is needed.
+type Tesseract struct {
+	// Command is the tesseract binary, with any extra arguments, such as
+	// "tesseract --psm 4".
+	Command string
+}
+
+// NewTesseract returns a reader running command.
+func NewTesseract(command string) *Tesseract {
+	if command == "" {
+		command = DefaultTesseractCommand
+	}
+	return &Tesseract{Command: command}
+}
+
+// Read implements Reader.
+func (t *Tesseract) Read(ctx context.Context, path, language string) (string, error) {
+	parts := strings.Fields(t.Command)
+	if len(parts) == 0 {
+		return "", errors.New("ocr: empty tesseract command")
+	}
+	// Writing to stdout, tesseract prints just the text.
+	args := append(parts[1:], path, "stdout")
+	if language != "" {
+		args = append(args, "-l", language)
+	}
+	cmd := exec.CommandContext(ctx, parts[0], args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("ocr: %s not found; install tesseract or set ocr_command", parts[0])
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("ocr: %s: %s", parts[0], lastLine(msg))
+		}
+		return "", fmt.Errorf("ocr: %s: %w", parts[0], err)
+	}
+	return clean(stdout.String()), nil
+}
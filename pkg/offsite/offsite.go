@@ -0,0 +1,47 @@
+// Package offsite keeps files away from this machine, in a bucket of an
+// S3-compatible object store or a directory of a WebDAV server, so that
+// backups survive losing it.
+package offsite
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when the file asked for is not in the store.
+var ErrNotFound = errors.New("offsite: no such file")
+
+// File is a file kept in a Store.
+type File struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+}
+
+// Store holds files by name, flat, in one bucket prefix or directory.
+type Store interface {
+	// Put uploads the content of r as the file name, replacing any file of
+	// that name. r is read twice by stores that sign what they send.
+	Put(ctx context.Context, name string, r io.ReadSeeker) error
+	// Get downloads the file name. The caller closes it.
+	Get(ctx context.Context, name string) (io.ReadCloser, error)
+	// List returns the files of the store, sorted by name. A store that
+	// was never written to lists nothing.
+	List(ctx context.Context) ([]File, error)
+	// Delete removes the file name.
+	Delete(ctx context.Context, name string) error
+	// String names the store for people, as in "s3://bucket/prefix".
+	String() string
+}
+
+// size returns how many bytes r holds from its start, leaving it there.
+func size(r io.Seeker) (int64, error) {
+	n, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = r.Seek(0, io.SeekStart)
+	return n, err
+}
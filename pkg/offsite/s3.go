@@ -0,0 +1,284 @@
+package offsite
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultS3Region is the region of buckets whose region is not given.
+const DefaultS3Region = "us-east-1"
+
+// S3 is a Store in a bucket of Amazon S3 or a service with its API, such as
+// MinIO, Backblaze B2 or Cloudflare R2. Requests are signed with AWS
+// Signature Version 4 and address the bucket by path, which every such
+// service accepts.
+type S3 struct {
+	// Endpoint is the service's URL, such as https://s3.eu-central-1.amazonaws.com.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string // of the keys of the files, without slashes around it
+	AccessKey string
+	SecretKey string
+	Client    *http.Client
+}
+
+// NewS3 returns the store of bucket under prefix. An empty endpoint is
+// Amazon's in region, and an empty region DefaultS3Region.
+func NewS3(endpoint, region, bucket, prefix, accessKey, secretKey string) *S3 {
+	if region == "" {
+		region = DefaultS3Region
+	}
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	return &S3{
+		Endpoint:  strings.TrimRight(endpoint, "/"),
+		Region:    region,
+		Bucket:    bucket,
+		Prefix:    strings.Trim(prefix, "/"),
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		Client:    &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+func (s *S3) String() string {
+	if s.Prefix == "" {
+		return "s3://" + s.Bucket
+	}
+	return "s3://" + s.Bucket + "/" + s.Prefix
+}
+
+func (s *S3) key(name string) string {
+	if s.Prefix == "" {
+		return name
+	}
+	return s.Prefix + "/" + name
+}
+
+// Put implements Store.
+func (s *S3) Put(ctx context.Context, name string, r io.ReadSeeker) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return fmt.Errorf("offsite: %w", err)
+	}
+	n, err := size(r)
+	if err != nil {
+		return fmt.Errorf("offsite: %w", err)
+	}
+	req, err := s.request(ctx, http.MethodPut, s.key(name), nil, io.NopCloser(r), hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = n
+	resp, err := s.do(req, name)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Get implements Store.
+func (s *S3) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, s.key(name), nil, nil, emptyHash)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, name)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete implements Store.
+func (s *S3) Delete(ctx context.Context, name string) error {
+	req, err := s.request(ctx, http.MethodDelete, s.key(name), nil, nil, emptyHash)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, name)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// List implements Store.
+func (s *S3) List(ctx context.Context) ([]File, error) {
+	prefix := s.key("")
+	var files []File
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}, "delimiter": {"/"}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := s.request(ctx, http.MethodGet, "", query, nil, emptyHash)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req, "")
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Contents []struct {
+				Key          string    `xml:"Key"`
+				Size         int64     `xml:"Size"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("offsite: decoding the list of %s: %w", s, err)
+		}
+		for _, c := range page.Contents {
+			if name := strings.TrimPrefix(c.Key, prefix); name != "" {
+				files = append(files, File{Name: name, Size: c.Size, Modified: c.LastModified})
+			}
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			break
+		}
+		token = page.NextContinuationToken
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// emptyHash is the SHA-256 of no bytes, the payload hash of requests
+// without a body.
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// request builds a signed request for key, or for the bucket itself when
+// key is empty.
+func (s *S3) request(ctx context.Context, method, key string, query url.Values, body io.ReadCloser, payloadHash string) (*http.Request, error) {
+	path := "/" + escape(s.Bucket, false)
+	if key != "" {
+		path += "/" + escape(key, false)
+	}
+	rawQuery := canonicalQuery(query)
+	u, err := url.Parse(s.Endpoint + path)
+	if err != nil {
+		return nil, fmt.Errorf("offsite: endpoint %q: %w", s.Endpoint, err)
+	}
+	u.RawQuery = rawQuery
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	s.sign(req, path, rawQuery, payloadHash, time.Now().UTC())
+	return req, nil
+}
+
+// sign adds the headers of AWS Signature Version 4 to req.
+func (s *S3) sign(req *http.Request, path, rawQuery, payloadHash string, now time.Time) {
+	stamp := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signed = "host;x-amz-content-sha256;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		rawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + stamp,
+		"",
+		signed,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	sum := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	key := []byte("AWS4" + s.SecretKey)
+	for _, part := range []string{day, s.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.AccessKey+"/"+scope+
+		", SignedHeaders="+signed+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// do sends req, turning S3's error responses into errors. A missing name
+// is ErrNotFound.
+func (s *S3) do(req *http.Request, name string) (*http.Response, error) {
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("offsite: %w", err)
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	xml.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&e)
+	switch {
+	case resp.StatusCode == http.StatusNotFound && e.Code != "NoSuchBucket" && name != "":
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	case e.Message != "":
+		return nil, fmt.Errorf("offsite: %s: %s: %s", s, resp.Status, e.Message)
+	}
+	return nil, fmt.Errorf("offsite: %s: %s", s, resp.Status)
+}
+
+// canonicalQuery encodes query as signatures expect it: sorted by key,
+// with every reserved character escaped.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, escape(k, true)+"="+escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escape percent-encodes everything in s but unreserved characters and,
+// unless slashes is set, slashes.
+func escape(s string, slashes bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !slashes:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
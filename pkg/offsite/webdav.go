@@ -0,0 +1,241 @@
+package offsite
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebDAV is a Store in a directory of a WebDAV server, such as Nextcloud,
+// ownCloud or a NAS, authenticated with basic auth.
+type WebDAV struct {
+	// URL is the directory's, ending in a slash.
+	URL      string
+	User     string
+	Password string
+	Client   *http.Client
+}
+
+// NewWebDAV returns the store of the directory at dirURL.
+func NewWebDAV(dirURL, user, password string) *WebDAV {
+	return &WebDAV{
+		URL:      strings.TrimRight(dirURL, "/") + "/",
+		User:     user,
+		Password: password,
+		Client:   &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+func (w *WebDAV) String() string {
+	if u, err := url.Parse(w.URL); err == nil {
+		u.User = nil
+		return strings.TrimSuffix(u.String(), "/")
+	}
+	return strings.TrimSuffix(w.URL, "/")
+}
+
+// Put implements Store. The directory is made when it is missing.
+func (w *WebDAV) Put(ctx context.Context, name string, r io.ReadSeeker) error {
+	n, err := size(r)
+	if err != nil {
+		return fmt.Errorf("offsite: %w", err)
+	}
+	put := func() (*http.Response, error) {
+		req, err := w.request(ctx, http.MethodPut, name, io.NopCloser(r))
+		if err != nil {
+			return nil, err
+		}
+		req.ContentLength = n
+		return w.send(req)
+	}
+	resp, err := put()
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	// Missing parents answer 409 Conflict.
+	if resp.StatusCode == http.StatusConflict {
+		if err := w.mkcol(ctx); err != nil {
+			return err
+		}
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("offsite: %w", err)
+		}
+		if resp, err = put(); err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	return w.check(resp, name)
+}
+
+// mkcol makes the directory and those above it that are missing.
+func (w *WebDAV) mkcol(ctx context.Context) error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return fmt.Errorf("offsite: %w", err)
+	}
+	dir := *u
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := range segments {
+		dir.Path = "/" + strings.Join(segments[:i+1], "/") + "/"
+		dir.RawPath = ""
+		req, err := http.NewRequestWithContext(ctx, "MKCOL", dir.String(), nil)
+		if err != nil {
+			return fmt.Errorf("offsite: %w", err)
+		}
+		if w.User != "" || w.Password != "" {
+			req.SetBasicAuth(w.User, w.Password)
+		}
+		resp, err := w.send(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// Directories already there answer 405 Method Not Allowed.
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			if err := w.check(resp, ""); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Get implements Store.
+func (w *WebDAV) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := w.request(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := w.send(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.check(resp, name); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete implements Store.
+func (w *WebDAV) Delete(ctx context.Context, name string) error {
+	req, err := w.request(ctx, http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := w.send(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return w.check(resp, name)
+}
+
+// propfind asks for what List needs of each file.
+const propfind = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:resourcetype/><d:getcontentlength/><d:getlastmodified/></d:prop></d:propfind>`
+
+// List implements Store.
+func (w *WebDAV) List(ctx context.Context) ([]File, error) {
+	req, err := w.request(ctx, "PROPFIND", "", io.NopCloser(strings.NewReader(propfind)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "1")
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	resp, err := w.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err := w.check(resp, ""); err != nil {
+		return nil, err
+	}
+	var ms struct {
+		Responses []struct {
+			Href string `xml:"DAV: href"`
+			// Properties the server lacks come in a propstat of their own,
+			// with status 404.
+			Propstats []struct {
+				Status        string    `xml:"DAV: status"`
+				Collection    *struct{} `xml:"DAV: prop>resourcetype>collection"`
+				ContentLength string    `xml:"DAV: prop>getcontentlength"`
+				LastModified  string    `xml:"DAV: prop>getlastmodified"`
+			} `xml:"DAV: propstat"`
+		} `xml:"DAV: response"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("offsite: decoding the list of %s: %w", w, err)
+	}
+	var files []File
+	for _, r := range ms.Responses {
+		href, err := url.PathUnescape(r.Href)
+		if err != nil {
+			href = r.Href
+		}
+		f, dir := File{Name: path.Base(href)}, false
+		for _, p := range r.Propstats {
+			if !strings.Contains(p.Status, " 200 ") {
+				continue
+			}
+			dir = dir || p.Collection != nil
+			if n, err := strconv.ParseInt(p.ContentLength, 10, 64); err == nil {
+				f.Size = n
+			}
+			if t, err := http.ParseTime(p.LastModified); err == nil {
+				f.Modified = t
+			}
+		}
+		if !dir {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// request builds a request for name in the directory, or for the
+// directory itself when name is empty.
+func (w *WebDAV) request(ctx context.Context, method, name string, body io.ReadCloser) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, w.URL+url.PathEscape(name), body)
+	if err != nil {
+		return nil, fmt.Errorf("offsite: %w", err)
+	}
+	if w.User != "" || w.Password != "" {
+		req.SetBasicAuth(w.User, w.Password)
+	}
+	return req, nil
+}
+
+func (w *WebDAV) send(req *http.Request) (*http.Response, error) {
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("offsite: %w", err)
+	}
+	return resp, nil
+}
+
+// check turns a response that failed into an error. A missing name is
+// ErrNotFound.
+func (w *WebDAV) check(resp *http.Response, name string) error {
+	switch {
+	case resp.StatusCode/100 == 2:
+		return nil
+	case resp.StatusCode == http.StatusNotFound && name != "":
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return fmt.Errorf("offsite: %s: %s", w, resp.Status)
+}
@@ -0,0 +1,105 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Defaults of the API transcriber: OpenAI's transcription endpoint and
+// model. Other services with the same API, such as Groq or a local
+// faster-whisper server, work by changing them.
+const (
+	DefaultAPIURL   = "https://api.openai.com/v1/audio/transcriptions"
+	DefaultAPIModel = "whisper-1"
+)
+
+// API is a Transcriber uploading the recording to a speech-to-text
+// service with OpenAI's audio transcription API.
+type API struct {
+	URL    string
+	Model  string
+	Key    string
+	Client *http.Client
+}
+
+// NewAPI returns a transcriber posting to url with model, authenticated
+// with key. Empty url and model take the defaults.
+func NewAPI(url, model, key string) *API {
+	if url == "" {
+		url = DefaultAPIURL
+	}
+	if model == "" {
+		model = DefaultAPIModel
+	}
+	return &API{URL: url, Model: model, Key: key, Client: &http.Client{Timeout: 2 * time.Minute}}
+}
+
+// Transcribe implements Transcriber.
+func (a *API) Transcribe(ctx context.Context, path, language string) (string, error) {
+	audio, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("transcribe: %w", err)
+	}
+	defer audio.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fields := map[string]string{"model": a.Model, "response_format": "json"}
+	if language != "" {
+		fields["language"] = language
+	}
+	for name, value := range fields {
+		if err := mw.WriteField(name, value); err != nil {
+			return "", err
+		}
+	}
+	part, err := mw.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return "", fmt.Errorf("transcribe: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	if a.Key != "" {
+		req.Header.Set("Authorization", "Bearer "+a.Key)
+	}
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("transcribe: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		Text  string `json:"text"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	err = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out)
+	switch {
+	case resp.StatusCode != http.StatusOK && out.Error.Message != "":
+		return "", fmt.Errorf("transcribe: %s: %s", resp.Status, out.Error.Message)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("transcribe: %s", resp.Status)
+	case err != nil:
+		return "", fmt.Errorf("transcribe: decoding response: %w", err)
+	}
+	return clean(out.Text), nil
+}
@@ -0,0 +1,91 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// wavHeader is the size of the header of a WAV file, which holds no audio.
+const wavHeader = 44
+
+// recorders are the tools Record tries in turn, each given the file to
+// write a 16 kHz mono WAV to, the rate speech models work at.
+var recorders = []struct {
+	name string
+	args func(path string) []string
+}{
+	{"arecord", func(path string) []string {
+		return []string{"-q", "-f", "S16_LE", "-r", "16000", "-c", "1", "-t", "wav", path}
+	}},
+	{"rec", func(path string) []string {
+		return []string{"-q", "-r", "16000", "-c", "1", "-b", "16", path}
+	}},
+	{"ffmpeg", func(path string) []string {
+		input := []string{"-f", "pulse", "-i", "default"}
+		if runtime.GOOS == "darwin" {
+			input = []string{"-f", "avfoundation", "-i", ":default"}
+		}
+		return append(append([]string{"-loglevel", "error", "-y"}, input...), "-ar", "16000", "-ac", "1", path)
+	}},
+}
+
+// Record records from the default microphone into a WAV file at path until
+// ctx is done, with the first of arecord (ALSA), rec (SoX) or ffmpeg
+// found on the system. It stops the tool with an interrupt so the file is
+// finished properly; the tool stopping by itself, as it does on ctrl+c
+// from the terminal, ends the recording too.
+func Record(ctx context.Context, path string) error {
+	for _, r := range recorders {
+		bin, err := exec.LookPath(r.name)
+		if err != nil {
+			continue
+		}
+		// The recording is stopped, not killed, when ctx is done.
+		cmd := exec.Command(bin, r.args(path)...)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("record: %w", err)
+		}
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		select {
+		case err = <-done:
+			// The tool stopped by itself, as on ctrl+c from the terminal.
+		case <-ctx.Done():
+			if cmd.Process.Signal(os.Interrupt) != nil {
+				cmd.Process.Kill()
+			}
+			select {
+			case err = <-done:
+			case <-time.After(5 * time.Second):
+				cmd.Process.Kill()
+				err = <-done
+			}
+		}
+		// Interrupted tools exit with an error though the file is fine, so
+		// what was recorded is what counts.
+		if info, serr := os.Stat(path); serr != nil || info.Size() <= wavHeader {
+			if err == nil {
+				err = errors.New("nothing was recorded")
+			}
+			return recordError(r.name, err, stderr.String())
+		}
+		return nil
+	}
+	return errors.New("record: no recording tool found; install alsa-utils (arecord), sox or ffmpeg, or pass a recording with --file")
+}
+
+func recordError(name string, err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("record: %s: %s", name, lastLine(msg))
+	}
+	return fmt.Errorf("record: %s: %w", name, err)
+}
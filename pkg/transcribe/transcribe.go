@@ -0,0 +1,27 @@
+// Package transcribe turns recorded speech into text with a pluggable
+// Transcriber, such as a local whisper.cpp binary or a speech-to-text API,
+// and records speech from the system microphone.
+package transcribe
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// Transcriber transcribes the speech in the audio file at path, such as a
+// WAV recording written by Record. language is an ISO 639-1 code, or
+// empty to detect the language spoken.
+type Transcriber interface {
+	Transcribe(ctx context.Context, path, language string) (string, error)
+}
+
+// markers are what speech models write for sounds that are not speech,
+// such as "[BLANK_AUDIO]" or "(wind blowing)".
+var markers = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)`)
+
+// clean drops the markers from a transcript and joins its lines, which
+// follow the pauses in speech rather than its sentences.
+func clean(text string) string {
+	return strings.Join(strings.Fields(markers.ReplaceAllString(text, " ")), " ")
+}
@@ -0,0 +1,67 @@
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DefaultWhisperCommand is the name of the whisper.cpp command-line tool.
+const DefaultWhisperCommand = "whisper-cli"
+
+// Whisper is a Transcriber running whisper.cpp on this machine, so speech
+// never leaves it and no connection is needed.
+type Whisper struct {
+	// Command is the whisper.cpp binary, with any extra arguments, such as
+	// "whisper-cli --threads 8".
+	Command string
+	// Model is the path of a ggml model file, such as ggml-base.bin.
+	Model string
+}
+
+// NewWhisper returns a transcriber running command with the model at
+// model.
+func NewWhisper(command, model string) *Whisper {
+	if command == "" {
+		command = DefaultWhisperCommand
+	}
+	return &Whisper{Command: command, Model: model}
+}
+
+// Transcribe implements Transcriber.
+func (w *Whisper) Transcribe(ctx context.Context, path, language string) (string, error) {
+	if w.Model == "" {
+		return "", errors.New("transcribe: no whisper model")
+	}
+	if language == "" {
+		language = "auto"
+	}
+	parts := strings.Fields(w.Command)
+	if len(parts) == 0 {
+		return "", errors.New("transcribe: empty whisper command")
+	}
+	// Without timestamps or progress, whisper.cpp prints just the text.
+	args := append(parts[1:], "--model", w.Model, "--language", language, "--no-timestamps", "--no-prints", "--file", path)
+	cmd := exec.CommandContext(ctx, parts[0], args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			return "", fmt.Errorf("transcribe: %s not found; install whisper.cpp or set whisper_command", parts[0])
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("transcribe: %s: %s", parts[0], lastLine(msg))
+		}
+		return "", fmt.Errorf("transcribe: %s: %w", parts[0], err)
+	}
+	return clean(stdout.String()), nil
+}
+
+// lastLine returns the last line of s, where tools put the error that
+// stopped them.
+func lastLine(s string) string {
+	return s[strings.LastIndexByte(s, '\n')+1:]
+}
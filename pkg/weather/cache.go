@@ -0,0 +1,97 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Cache wraps a Provider with an on-disk JSON cache. The weather of a day
+// that is over no longer changes, so it is kept for good; days still to
+// come or just past are refetched after TTL, and served from the cache if
+// the refetch fails.
+type Cache struct {
+	Path     string
+	TTL      time.Duration
+	Provider Provider
+
+	mu sync.Mutex
+}
+
+type cached struct {
+	Day     *Day      `json:"day"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// settled is how long after a day's end its weather is taken as final.
+const settled = 48 * time.Hour
+
+// NewCache returns a Cache storing its file at path.
+func NewCache(path string, ttl time.Duration, p Provider) *Cache {
+	return &Cache{Path: path, TTL: ttl, Provider: p}
+}
+
+// Daily implements Provider.
+func (c *Cache) Daily(ctx context.Context, lat, lon float64, date time.Time) (*Day, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Positions are rounded to about a kilometre, finer than any provider's grid.
+	key := fmt.Sprintf("%.2f,%.2f@%s", lat, lon, date.Format("2006-01-02"))
+	entries, _ := c.load()
+	e, hit := entries[key]
+	if hit && (time.Since(date) > settled || time.Since(e.Fetched) < c.TTL) {
+		return e.Day, nil
+	}
+
+	fresh, err := c.Provider.Daily(ctx, lat, lon, date)
+	if err != nil {
+		if hit {
+			return e.Day, nil
+		}
+		return nil, err
+	}
+	if entries == nil {
+		entries = map[string]cached{}
+	}
+	entries[key] = cached{Day: fresh, Fetched: time.Now()}
+	// A cache that cannot be written only costs a refetch next time.
+	_ = c.save(entries)
+	return fresh, nil
+}
+
+func (c *Cache) load() (map[string]cached, error) {
+	data, err := os.ReadFile(c.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries map[string]cached
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (c *Cache) save(entries map[string]cached) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0o700); err != nil {
+		return err
+	}
+	tmp := c.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.Path)
+}
@@ -0,0 +1,98 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default endpoints of the free Open-Meteo APIs, which need no key. The
+// forecast API covers the last few months and the next two weeks; the
+// archive API covers everything older.
+const (
+	DefaultOpenMeteoURL        = "https://api.open-meteo.com/v1/forecast"
+	DefaultOpenMeteoArchiveURL = "https://archive-api.open-meteo.com/v1/archive"
+)
+
+// archiveDelay is how old a day must be to be asked of the archive, which
+// lags a few days behind.
+const archiveDelay = 7 * 24 * time.Hour
+
+// OpenMeteo is a Provider backed by the Open-Meteo APIs.
+type OpenMeteo struct {
+	ForecastURL string
+	ArchiveURL  string
+	Client      *http.Client
+}
+
+// NewOpenMeteo returns a provider using the public Open-Meteo endpoints.
+func NewOpenMeteo() *OpenMeteo {
+	return &OpenMeteo{
+		ForecastURL: DefaultOpenMeteoURL,
+		ArchiveURL:  DefaultOpenMeteoArchiveURL,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Daily implements Provider.
+func (o *OpenMeteo) Daily(ctx context.Context, lat, lon float64, date time.Time) (*Day, error) {
+	endpoint := o.ForecastURL
+	if time.Since(date) > archiveDelay {
+		endpoint = o.ArchiveURL
+	}
+	day := date.Format("2006-01-02")
+	q := url.Values{
+		"latitude":   {strconv.FormatFloat(lat, 'f', 4, 64)},
+		"longitude":  {strconv.FormatFloat(lon, 'f', 4, 64)},
+		"daily":      {"weather_code,temperature_2m_max,temperature_2m_min,precipitation_sum"},
+		"timezone":   {"auto"},
+		"start_date": {day},
+		"end_date":   {day},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("weather: fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Reason string `json:"reason"`
+		Daily  struct {
+			Time          []string   `json:"time"`
+			Code          []*int     `json:"weather_code"`
+			TempMax       []*float64 `json:"temperature_2m_max"`
+			TempMin       []*float64 `json:"temperature_2m_min"`
+			Precipitation []*float64 `json:"precipitation_sum"`
+		} `json:"daily"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("weather: decode: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if body.Reason != "" {
+			return nil, fmt.Errorf("weather: fetch: %s", strings.TrimSuffix(body.Reason, "."))
+		}
+		return nil, fmt.Errorf("weather: fetch: %s", resp.Status)
+	}
+
+	d := body.Daily
+	if len(d.Time) == 0 || len(d.Code) == 0 || len(d.TempMax) == 0 || len(d.TempMin) == 0 ||
+		d.Code[0] == nil || d.TempMax[0] == nil || d.TempMin[0] == nil {
+		return nil, errors.New("weather: no data for " + day)
+	}
+	w := &Day{Date: d.Time[0], Code: *d.Code[0], TempMin: *d.TempMin[0], TempMax: *d.TempMax[0]}
+	if len(d.Precipitation) > 0 && d.Precipitation[0] != nil {
+		w.Precipitation = *d.Precipitation[0]
+	}
+	return w, nil
+}
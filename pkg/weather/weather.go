@@ -0,0 +1,82 @@
+// Package weather looks up the weather of a day at a position from a
+// pluggable Provider.
+package weather
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Day is the weather of one calendar day at one place.
+type Day struct {
+	Date string `json:"date"` // YYYY-MM-DD, local to the place
+	// Code is the WMO weather interpretation code of the day's most
+	// significant weather.
+	Code          int     `json:"code"`
+	TempMin       float64 `json:"temp_min"`                // °C
+	TempMax       float64 `json:"temp_max"`                // °C
+	Precipitation float64 `json:"precipitation,omitempty"` // mm
+}
+
+// Provider fetches the weather of date, a day local to the position.
+type Provider interface {
+	Daily(ctx context.Context, lat, lon float64, date time.Time) (*Day, error)
+}
+
+// Conditions describes the day's weather code in words.
+func (d *Day) Conditions() string {
+	if c, ok := codes[d.Code]; ok {
+		return c.text
+	}
+	return fmt.Sprintf("weather code %d", d.Code)
+}
+
+// Icon is an emoji for the day's weather code.
+func (d *Day) Icon() string {
+	if c, ok := codes[d.Code]; ok {
+		return c.icon
+	}
+	return "🌡️"
+}
+
+// Summary renders the day as "⛅ 9–18°C, partly cloudy".
+func (d *Day) Summary() string {
+	return fmt.Sprintf("%s %.0f–%.0f°C, %s", d.Icon(), math.Round(d.TempMin), math.Round(d.TempMax), d.Conditions())
+}
+
+type code struct{ icon, text string }
+
+// codes are the WMO weather interpretation codes reported by most
+// providers.
+var codes = map[int]code{
+	0:  {"☀️", "clear sky"},
+	1:  {"🌤️", "mainly clear"},
+	2:  {"⛅", "partly cloudy"},
+	3:  {"☁️", "overcast"},
+	45: {"🌫️", "fog"},
+	48: {"🌫️", "freezing fog"},
+	51: {"🌦️", "light drizzle"},
+	53: {"🌦️", "drizzle"},
+	55: {"🌦️", "heavy drizzle"},
+	56: {"🌧️", "freezing drizzle"},
+	57: {"🌧️", "heavy freezing drizzle"},
+	61: {"🌧️", "light rain"},
+	63: {"🌧️", "rain"},
+	65: {"🌧️", "heavy rain"},
+	66: {"🌧️", "freezing rain"},
+	67: {"🌧️", "heavy freezing rain"},
+	71: {"🌨️", "light snow"},
+	73: {"🌨️", "snow"},
+	75: {"❄️", "heavy snow"},
+	77: {"🌨️", "snow grains"},
+	80: {"🌦️", "light showers"},
+	81: {"🌦️", "showers"},
+	82: {"⛈️", "violent showers"},
+	85: {"🌨️", "snow showers"},
+	86: {"🌨️", "heavy snow showers"},
+	95: {"⛈️", "thunderstorm"},
+	96: {"⛈️", "thunderstorm with hail"},
+	99: {"⛈️", "thunderstorm with heavy hail"},
+}